@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+)
+
+var (
+	engineConfigSubgraphs []string
+	engineConfigFormat    string
+	engineConfigOutFile   string
+)
+
+// engineConfigCmd represents the engineConfig command
+var engineConfigCmd = &cobra.Command{
+	Use:   "engineConfig",
+	Short: "Generates the engine configuration of federation subgraphs",
+	Long: `engineConfig generates the configuration of the data sources and fields of federation subgraphs from their SDLs:
+the root and child nodes of each subgraph, the fields required by the federation directives and the arguments of the fields.
+The configuration is printed as JSON or YAML, see plan.FederationConfiguration.`,
+	Example: `graphql-go-tools gen engineConfig -s accounts,http://localhost:4001/graphql,./accounts.graphql -s reviews,http://localhost:4002/graphql,./reviews.graphql -f yaml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		subgraphs := make([]plan.Subgraph, 0, len(engineConfigSubgraphs))
+		for _, value := range engineConfigSubgraphs {
+			subgraph, err := parseEngineConfigSubgraph(value)
+			if err != nil {
+				return err
+			}
+			subgraphs = append(subgraphs, subgraph)
+		}
+
+		config, err := plan.GenerateFederationConfiguration(subgraphs...)
+		if err != nil {
+			return err
+		}
+
+		var out io.Writer
+		if engineConfigOutFile == "" {
+			out = os.Stdout
+		} else {
+			o, err := os.Create(engineConfigOutFile)
+			if err != nil {
+				return err
+			}
+			defer o.Close()
+			out = o
+		}
+
+		switch engineConfigFormat {
+		case "json":
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(config)
+		case "yaml":
+			return yaml.NewEncoder(out).Encode(config)
+		default:
+			return fmt.Errorf("unsupported format %q, expected json or yaml", engineConfigFormat)
+		}
+	},
+}
+
+// parseEngineConfigSubgraph parses a subgraph flag of the form name,url,sdlFile
+func parseEngineConfigSubgraph(value string) (plan.Subgraph, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != 3 {
+		return plan.Subgraph{}, fmt.Errorf("invalid subgraph %q, expected name,url,sdlFile", value)
+	}
+	sdl, err := os.ReadFile(parts[2])
+	if err != nil {
+		return plan.Subgraph{}, err
+	}
+	return plan.Subgraph{
+		Name: parts[0],
+		URL:  parts[1],
+		SDL:  string(sdl),
+	}, nil
+}
+
+func init() {
+	genCmd.AddCommand(engineConfigCmd)
+
+	engineConfigCmd.Flags().StringArrayVarP(&engineConfigSubgraphs, "subgraph", "s", nil, "subgraph is a subgraph of the form name,url,sdlFile, it can be repeated (required)")
+	_ = engineConfigCmd.MarkFlagRequired("subgraph")
+
+	engineConfigCmd.Flags().StringVarP(&engineConfigFormat, "format", "f", "json", "format is the format of the configuration, json or yaml (optional)")
+
+	engineConfigCmd.Flags().StringVarP(&engineConfigOutFile, "outFile", "o", "", "outFile is a flag to redirect the output directly into a file (optional)")
+}
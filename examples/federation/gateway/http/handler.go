@@ -4,9 +4,9 @@ import (
 	"net/http"
 
 	"github.com/gobwas/ws"
-	log "github.com/jensneuse/abstractlogger"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 const (
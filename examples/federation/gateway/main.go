@@ -8,13 +8,12 @@ import (
 	"time"
 
 	"github.com/gobwas/ws"
-	log "github.com/jensneuse/abstractlogger"
 	"go.uber.org/zap"
 
+	http2 "github.com/wundergraph/graphql-go-tools/examples/federation/gateway/http"
 	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
 	"github.com/wundergraph/graphql-go-tools/pkg/playground"
-
-	http2 "github.com/wundergraph/graphql-go-tools/examples/federation/gateway/http"
 )
 
 // It's just a simple example of graphql federation gateway server, it's NOT a production ready code.
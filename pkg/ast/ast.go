@@ -53,6 +53,10 @@ type Document struct {
 	Refs                         [][8]int
 	RefIndex                     int
 	Index                        Index
+	// refArena backs the refs which outgrow the pooled Refs, it's reused after Reset
+	refArena []int
+	// sealed marks the document as read-only, see Seal
+	sealed bool
 }
 
 func NewDocument() *Document {
@@ -107,6 +111,7 @@ func NewDocument() *Document {
 }
 
 func (d *Document) Reset() {
+	d.assertNotSealed()
 	d.RootNodes = d.RootNodes[:0]
 	d.SchemaDefinitions = d.SchemaDefinitions[:0]
 	d.SchemaExtensions = d.SchemaExtensions[:0]
@@ -149,11 +154,13 @@ func (d *Document) Reset() {
 	d.FragmentDefinitions = d.FragmentDefinitions[:0]
 
 	d.RefIndex = -1
+	d.refArena = d.refArena[:0]
 	d.Index.Reset()
 	d.Input.Reset()
 }
 
 func (d *Document) NextRefIndex() int {
+	d.assertNotSealed()
 	d.RefIndex++
 	if d.RefIndex == len(d.Refs) {
 		d.Refs = append(d.Refs, [8]int{})
@@ -165,6 +172,33 @@ func (d *Document) NewEmptyRefs() []int {
 	return d.Refs[d.NextRefIndex()][:0]
 }
 
+// appendRefs appends to refs, refs which have to grow are moved to the pooled Refs or the ref arena of the document,
+// so that appending to the refs of a reused document doesn't allocate
+func (d *Document) appendRefs(refs []int, appendRefs ...int) []int {
+	d.assertNotSealed()
+	length := len(refs) + len(appendRefs)
+	switch {
+	case length <= cap(refs):
+	case length <= len([8]int{}):
+		refs = append(d.NewEmptyRefs(), refs...)
+	default:
+		// leave room to grow, so that refs which are appended to repeatedly are moved once
+		grown := length * 2
+		if len(d.refArena)+grown > cap(d.refArena) {
+			arenaSize := cap(d.refArena) * 2
+			if arenaSize < grown {
+				arenaSize = grown
+			}
+			// refs moved to the previous arena stay valid, the previous arena is released with them
+			d.refArena = make([]int, 0, arenaSize)
+		}
+		start := len(d.refArena)
+		d.refArena = d.refArena[:start+grown]
+		refs = append(d.refArena[start:start:start+grown], refs...)
+	}
+	return append(refs, appendRefs...)
+}
+
 func (d *Document) copyByteSliceReference(ref ByteSliceReference) ByteSliceReference {
 	if ref.Length() == 0 {
 		return ByteSliceReference{}
@@ -176,6 +210,7 @@ func (d *Document) copyByteSliceReference(ref ByteSliceReference) ByteSliceRefer
 }
 
 func (d *Document) AddRootNode(node Node) {
+	d.assertNotSealed()
 	d.RootNodes = append(d.RootNodes, node)
 	d.Index.AddNodeStr(d.NodeNameUnsafeString(node), node)
 }
@@ -225,6 +260,17 @@ func (d *Document) NodeByNameStr(name string) (Node, bool) {
 	return d.Index.FirstNodeByNameStr(name)
 }
 
+// NodesByName returns all nodes with the given name, e.g. a type definition together with all of its extensions.
+// Unlike NodeByName, which only returns the first node, it doesn't hide extensions defined before or after the definition.
+func (d *Document) NodesByName(name ByteSlice) ([]Node, bool) {
+	return d.Index.NodesByNameBytes(name)
+}
+
+// NodesByNameStr returns all nodes with the given name, see NodesByName.
+func (d *Document) NodesByNameStr(name string) ([]Node, bool) {
+	return d.Index.NodesByNameStr(name)
+}
+
 func (d *Document) TypeDefinitionContainsImplementsInterface(typeName, interfaceName ByteSlice) bool {
 	typeDefinition, exists := d.Index.FirstNodeByNameBytes(typeName)
 	if !exists {
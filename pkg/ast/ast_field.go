@@ -16,9 +16,23 @@ type Field struct {
 	Directives    DirectiveList // optional
 	SelectionSet  int           // optional
 	HasSelections bool
+	Nullability   Nullability // optional, client controlled nullability designator, e.g. ! or ?
 	Position      position.Position
 }
 
+// Nullability is the client controlled nullability designator of a field,
+// it's only parsed when enabled, see astparser.Parser.EnableClientControlledNullability
+type Nullability int
+
+const (
+	// NullabilityUnspecified keeps the nullability of the schema
+	NullabilityUnspecified Nullability = iota
+	// NullabilityRequired is denoted by ! after the field, a null value is an error propagated to the parent
+	NullabilityRequired
+	// NullabilityOptional is denoted by ? after the field, errors of the field and its children are caught as null
+	NullabilityOptional
+)
+
 func (d *Document) CopyField(ref int) int {
 	var arguments ArgumentList
 	var directives DirectiveList
@@ -40,6 +54,7 @@ func (d *Document) CopyField(ref int) int {
 		HasDirectives: d.Fields[ref].HasDirectives,
 		Directives:    directives,
 		HasSelections: d.Fields[ref].HasSelections,
+		Nullability:   d.Fields[ref].Nullability,
 		SelectionSet:  selectionSet,
 	}).Ref
 }
@@ -121,10 +136,13 @@ func (d *Document) FieldHasDirectives(ref int) bool {
 	return d.Fields[ref].HasDirectives
 }
 
+// FieldsAreEqualFlat compares two fields without selections by name, response key, arguments and directives.
+// The response key is the alias if defined, otherwise the field name, so that `id` and `id: id` are equal.
 func (d *Document) FieldsAreEqualFlat(left, right int) bool {
 	return bytes.Equal(d.FieldNameBytes(left), d.FieldNameBytes(right)) && // name
-		bytes.Equal(d.FieldAliasBytes(left), d.FieldAliasBytes(right)) && // alias
+		bytes.Equal(d.FieldAliasOrNameBytes(left), d.FieldAliasOrNameBytes(right)) && // response key
 		!d.FieldHasSelections(left) && !d.FieldHasSelections(right) && // selections
 		d.ArgumentSetsAreEquals(d.FieldArguments(left), d.FieldArguments(right)) && // arguments
-		d.DirectiveSetsAreEqual(d.FieldDirectives(left), d.FieldDirectives(right)) // directives
+		d.DirectiveSetsAreEqual(d.FieldDirectives(left), d.FieldDirectives(right)) && // directives
+		d.Fields[left].Nullability == d.Fields[right].Nullability // nullability designator
 }
@@ -23,12 +23,14 @@ type TypeCondition struct {
 //  profilePic(size: 50)
 // }
 type FragmentDefinition struct {
-	FragmentLiteral position.Position  // fragment
-	Name            ByteSliceReference // Name but not on, e.g. friendFields
-	TypeCondition   TypeCondition      // e.g. on User
-	Directives      DirectiveList      // optional, e.g. @foo
-	SelectionSet    int                // e.g. { id }
-	HasSelections   bool
+	FragmentLiteral        position.Position  // fragment
+	Name                   ByteSliceReference // Name but not on, e.g. friendFields
+	HasVariableDefinitions bool
+	VariableDefinitions    VariableDefinitionList // optional, experimental fragment arguments, e.g. ($size: Int = 50)
+	TypeCondition          TypeCondition          // e.g. on User
+	Directives             DirectiveList          // optional, e.g. @foo
+	SelectionSet           int                    // e.g. { id }
+	HasSelections          bool
 }
 
 func (d *Document) FragmentDefinitionRef(byName ByteSlice) (ref int, exists bool) {
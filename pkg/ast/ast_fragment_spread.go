@@ -11,17 +11,25 @@ import (
 type FragmentSpread struct {
 	Spread        position.Position  // ...
 	FragmentName  ByteSliceReference // Name but not on, e.g. MyFragment
+	HasArguments  bool
+	Arguments     ArgumentList // optional, experimental fragment arguments, e.g. (size: 100)
 	HasDirectives bool
 	Directives    DirectiveList // optional, e.g. @foo
 }
 
 func (d *Document) CopyFragmentSpread(ref int) int {
+	var arguments ArgumentList
 	var directives DirectiveList
+	if d.FragmentSpreads[ref].HasArguments {
+		arguments = d.CopyArgumentList(d.FragmentSpreads[ref].Arguments)
+	}
 	if d.FragmentSpreads[ref].HasDirectives {
 		directives = d.CopyDirectiveList(d.FragmentSpreads[ref].Directives)
 	}
 	return d.AddFragmentSpread(FragmentSpread{
 		FragmentName:  d.copyByteSliceReference(d.FragmentSpreads[ref].FragmentName),
+		HasArguments:  d.FragmentSpreads[ref].HasArguments,
+		Arguments:     arguments,
 		HasDirectives: d.FragmentSpreads[ref].HasDirectives,
 		Directives:    directives,
 	})
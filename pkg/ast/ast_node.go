@@ -60,6 +60,10 @@ func (d *Document) NodeNameBytes(node Node) ByteSlice {
 		ref = d.UnionTypeExtensions[node.Ref].Name
 	case NodeKindEnumTypeExtension:
 		ref = d.EnumTypeExtensions[node.Ref].Name
+	case NodeKindInputObjectTypeExtension:
+		ref = d.InputObjectTypeExtensions[node.Ref].Name
+	case NodeKindScalarTypeExtension:
+		ref = d.ScalarTypeExtensions[node.Ref].Name
 	}
 
 	return d.Input.ByteSlice(ref)
@@ -290,6 +294,16 @@ func (d *Document) NodeFieldDefinitions(node Node) []int {
 	}
 }
 
+// NodeFieldDefinitionNames returns the names of the field definitions of the node, e.g. to suggest fields of misspelled fields
+func (d *Document) NodeFieldDefinitionNames(node Node) []string {
+	fields := d.NodeFieldDefinitions(node)
+	names := make([]string, 0, len(fields))
+	for _, ref := range fields {
+		names = append(names, d.FieldDefinitionNameString(ref))
+	}
+	return names
+}
+
 func (d *Document) NodeInputFieldDefinitions(node Node) []int {
 	switch node.Kind {
 	case NodeKindInputObjectTypeDefinition:
@@ -360,6 +374,29 @@ func (d *Document) NodeInputValueDefinitions(node Node) []int {
 	}
 }
 
+// NodeInputValueDefinitionNames returns the names of the input value definitions of the node, e.g. to suggest arguments of misspelled arguments
+func (d *Document) NodeInputValueDefinitionNames(node Node) []string {
+	inputValues := d.NodeInputValueDefinitions(node)
+	names := make([]string, 0, len(inputValues))
+	for _, ref := range inputValues {
+		names = append(names, d.InputValueDefinitionNameString(ref))
+	}
+	return names
+}
+
+// TypeDefinitionNames returns the names of all type definitions of the document, e.g. to suggest types of misspelled types
+func (d *Document) TypeDefinitionNames() []string {
+	names := make([]string, 0, len(d.RootNodes))
+	for _, node := range d.RootNodes {
+		switch node.Kind {
+		case NodeKindObjectTypeDefinition, NodeKindInterfaceTypeDefinition, NodeKindUnionTypeDefinition,
+			NodeKindEnumTypeDefinition, NodeKindScalarTypeDefinition, NodeKindInputObjectTypeDefinition:
+			names = append(names, d.NodeNameString(node))
+		}
+	}
+	return names
+}
+
 func (d *Document) InputValueDefinitionIsFirst(inputValue int, ancestor Node) bool {
 	inputValues := d.NodeInputValueDefinitions(ancestor)
 	return inputValues != nil && inputValues[0] == inputValue
@@ -443,14 +480,18 @@ func (d *Document) RemoveNodeFromSelectionSet(set int, node Node) {
 }
 
 // NodeInterfaceRefs returns the interfaces implemented by the given node (this is
-// only applicable to object kinds).
-// Returns nil if node kind is not an object kind.
+// only applicable to object and interface kinds).
+// Returns nil if node kind is not an object or interface kind.
 func (d *Document) NodeInterfaceRefs(node Node) (refs []int) {
 	switch node.Kind {
 	case NodeKindObjectTypeDefinition:
 		return d.ObjectTypeDefinitions[node.Ref].ImplementsInterfaces.Refs
 	case NodeKindObjectTypeExtension:
 		return d.ObjectTypeExtensions[node.Ref].ImplementsInterfaces.Refs
+	case NodeKindInterfaceTypeDefinition:
+		return d.InterfaceTypeDefinitions[node.Ref].ImplementsInterfaces.Refs
+	case NodeKindInterfaceTypeExtension:
+		return d.InterfaceTypeExtensions[node.Ref].ImplementsInterfaces.Refs
 	default:
 		return nil
 	}
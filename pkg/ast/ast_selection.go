@@ -144,7 +144,7 @@ func (d *Document) EmptySelectionSet(ref int) {
 }
 
 func (d *Document) AppendSelectionSet(ref int, appendRef int) {
-	d.SelectionSets[ref].SelectionRefs = append(d.SelectionSets[ref].SelectionRefs, d.SelectionSets[appendRef].SelectionRefs...)
+	d.SelectionSets[ref].SelectionRefs = d.appendRefs(d.SelectionSets[ref].SelectionRefs, d.SelectionSets[appendRef].SelectionRefs...)
 }
 
 func (d *Document) ReplaceSelectionOnSelectionSet(ref, replace, with int) {
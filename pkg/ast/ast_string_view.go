@@ -0,0 +1,108 @@
+package ast
+
+import (
+	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafebytes"
+)
+
+// The accessors in this file return string views over Document.Input.RawBytes.
+// No bytes are copied, so they are cheap to call on hot paths and on big schemas.
+//
+// Lifetime: a string view is only valid as long as the underlying bytes stay untouched.
+// Appending to the Input is fine, but once the Document (or its Input) is reset and reused,
+// e.g. after returning it to a pool, every view obtained before the reset points to foreign content.
+// Copy the string (strings.Clone or string([]byte)) if you need to keep it beyond that point.
+
+// InputValueDefinitionNameUnsafeString returns the name of the input value definition as unsafe string view
+func (d *Document) InputValueDefinitionNameUnsafeString(ref int) string {
+	return unsafebytes.BytesToString(d.Input.ByteSlice(d.InputValueDefinitions[ref].Name))
+}
+
+// FieldDefinitionNameUnsafeString returns the name of the field definition as unsafe string view
+func (d *Document) FieldDefinitionNameUnsafeString(ref int) string {
+	return unsafebytes.BytesToString(d.Input.ByteSlice(d.FieldDefinitions[ref].Name))
+}
+
+// FieldAliasOrNameUnsafeString returns the alias of the field, or its name if no alias is defined, as unsafe string view
+func (d *Document) FieldAliasOrNameUnsafeString(ref int) string {
+	return unsafebytes.BytesToString(d.FieldAliasOrNameBytes(ref))
+}
+
+// TypeNameUnsafeString returns the name of the named type as unsafe string view
+func (d *Document) TypeNameUnsafeString(ref int) string {
+	return unsafebytes.BytesToString(d.Input.ByteSlice(d.Types[ref].Name))
+}
+
+// ResolveTypeNameUnsafeString returns the name of the underlying named type, unwrapping list and non null types, as unsafe string view
+func (d *Document) ResolveTypeNameUnsafeString(ref int) string {
+	return unsafebytes.BytesToString(d.ResolveTypeNameBytes(ref))
+}
+
+// RootNodeNamesUnsafeString appends the names of all root nodes which carry a name to dst and returns the extended slice.
+// Nodes without a name (e.g. schema definitions or operations without a name) are skipped.
+func (d *Document) RootNodeNamesUnsafeString(dst []string) []string {
+	for i := range d.RootNodes {
+		name := d.NodeNameBytes(d.RootNodes[i])
+		if len(name) == 0 {
+			continue
+		}
+		dst = append(dst, unsafebytes.BytesToString(name))
+	}
+	return dst
+}
+
+// NodeNamesUnsafeString appends the names of the given nodes to dst and returns the extended slice.
+func (d *Document) NodeNamesUnsafeString(nodes []Node, dst []string) []string {
+	for i := range nodes {
+		dst = append(dst, d.NodeNameUnsafeString(nodes[i]))
+	}
+	return dst
+}
+
+// FieldDefinitionNamesUnsafeString appends the names of the field definitions to dst and returns the extended slice.
+func (d *Document) FieldDefinitionNamesUnsafeString(refs []int, dst []string) []string {
+	for _, ref := range refs {
+		dst = append(dst, d.FieldDefinitionNameUnsafeString(ref))
+	}
+	return dst
+}
+
+// NodeFieldDefinitionNamesUnsafeString appends the names of all field definitions of the node to dst and returns the extended slice.
+func (d *Document) NodeFieldDefinitionNamesUnsafeString(node Node, dst []string) []string {
+	return d.FieldDefinitionNamesUnsafeString(d.NodeFieldDefinitions(node), dst)
+}
+
+// InputValueDefinitionNamesUnsafeString appends the names of the input value definitions to dst and returns the extended slice.
+func (d *Document) InputValueDefinitionNamesUnsafeString(refs []int, dst []string) []string {
+	for _, ref := range refs {
+		dst = append(dst, d.InputValueDefinitionNameUnsafeString(ref))
+	}
+	return dst
+}
+
+// EnumValueDefinitionNamesUnsafeString appends the names of the enum value definitions to dst and returns the extended slice.
+func (d *Document) EnumValueDefinitionNamesUnsafeString(refs []int, dst []string) []string {
+	for _, ref := range refs {
+		dst = append(dst, unsafebytes.BytesToString(d.EnumValueDefinitionNameBytes(ref)))
+	}
+	return dst
+}
+
+// FieldNamesUnsafeString appends the names of the fields to dst and returns the extended slice.
+func (d *Document) FieldNamesUnsafeString(refs []int, dst []string) []string {
+	for _, ref := range refs {
+		dst = append(dst, d.FieldNameUnsafeString(ref))
+	}
+	return dst
+}
+
+// SelectionSetFieldAliasOrNamesUnsafeString appends the alias or name of every field selection
+// in the selection set to dst and returns the extended slice. Fragment spreads and inline fragments are skipped.
+func (d *Document) SelectionSetFieldAliasOrNamesUnsafeString(set int, dst []string) []string {
+	for _, selectionRef := range d.SelectionSets[set].SelectionRefs {
+		if d.Selections[selectionRef].Kind != SelectionKindField {
+			continue
+		}
+		dst = append(dst, d.FieldAliasOrNameUnsafeString(d.Selections[selectionRef].Ref))
+	}
+	return dst
+}
@@ -0,0 +1,59 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+)
+
+func TestDocument_UnsafeStringViews(t *testing.T) {
+	doc := unsafeparser.ParseGraphqlDocumentString(`
+		schema { query: Query }
+		type Query { user(id: ID!, locale: String): User }
+		type User { id: ID! name: String friends: [User!]! }
+		enum Role { ADMIN USER }
+	`)
+
+	t.Run("root node names", func(t *testing.T) {
+		assert.Equal(t, []string{"Query", "User", "Role"}, doc.RootNodeNamesUnsafeString(nil))
+	})
+
+	t.Run("field definition names of node", func(t *testing.T) {
+		node, ok := doc.NodeByNameStr("User")
+		assert.True(t, ok)
+		assert.Equal(t, []string{"id", "name", "friends"}, doc.NodeFieldDefinitionNamesUnsafeString(node, nil))
+	})
+
+	t.Run("argument names and resolved types", func(t *testing.T) {
+		node, _ := doc.NodeByNameStr("Query")
+		fieldRef := doc.NodeFieldDefinitions(node)[0]
+		args := doc.FieldDefinitions[fieldRef].ArgumentsDefinition.Refs
+		assert.Equal(t, []string{"id", "locale"}, doc.InputValueDefinitionNamesUnsafeString(args, nil))
+
+		userNode, _ := doc.NodeByNameStr("User")
+		friendsRef := doc.NodeFieldDefinitions(userNode)[2]
+		assert.Equal(t, "User", doc.ResolveTypeNameUnsafeString(doc.FieldDefinitions[friendsRef].Type))
+	})
+
+	t.Run("enum value names", func(t *testing.T) {
+		node, _ := doc.NodeByNameStr("Role")
+		refs := doc.EnumTypeDefinitions[node.Ref].EnumValuesDefinition.Refs
+		assert.Equal(t, []string{"ADMIN", "USER"}, doc.EnumValueDefinitionNamesUnsafeString(refs, nil))
+	})
+
+	t.Run("appends to dst", func(t *testing.T) {
+		dst := make([]string, 0, 4)
+		dst = append(dst, "existing")
+		dst = doc.NodeNamesUnsafeString([]ast.Node{doc.RootNodes[1]}, dst)
+		assert.Equal(t, []string{"existing", "Query"}, dst)
+	})
+
+	t.Run("selection set field names", func(t *testing.T) {
+		operation := unsafeparser.ParseGraphqlDocumentString(`{ me: user(id: 1) { id name } ... on Query { other } }`)
+		set := operation.OperationDefinitions[0].SelectionSet
+		assert.Equal(t, []string{"me"}, operation.SelectionSetFieldAliasOrNamesUnsafeString(set, nil))
+	})
+}
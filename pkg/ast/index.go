@@ -90,13 +90,17 @@ func (i *Index) FirstNodeByNameBytes(name []byte) (Node, bool) {
 	return node[0], true
 }
 
+// FirstNonExtensionNodeByNameStr returns the first node registered for the name which is not an extension,
+// e.g. the object type definition of a type which might be extended before being defined.
+func (i *Index) FirstNonExtensionNodeByNameStr(name string) (Node, bool) {
+	return i.firstNonExtensionNode(i.nodes[xxhash.Sum64String(name)])
+}
+
 func (i *Index) FirstNonExtensionNodeByNameBytes(name []byte) (Node, bool) {
-	hash := xxhash.Sum64(name)
-	nodes, exists := i.nodes[hash]
-	if !exists || len(nodes) == 0 {
-		return InvalidNode, false
-	}
+	return i.firstNonExtensionNode(i.nodes[xxhash.Sum64(name)])
+}
 
+func (i *Index) firstNonExtensionNode(nodes []Node) (Node, bool) {
 	for j := range nodes {
 		if nodes[j].IsExtensionKind() {
 			continue
@@ -141,6 +145,23 @@ func (i *Index) ReplaceNode(name []byte, oldNode Node, newNode Node) {
 	}
 }
 
+// RootOperationTypeNames returns the names of the root operation types.
+// Root operation types declared by the document, e.g. schema { query: MyQuery }, take precedence.
+// Root operation types which are not declared fall back to their implicit names Query, Mutation and Subscription.
+func (i *Index) RootOperationTypeNames() (query, mutation, subscription ByteSlice) {
+	query, mutation, subscription = i.QueryTypeName, i.MutationTypeName, i.SubscriptionTypeName
+	if len(query) == 0 {
+		query = DefaultQueryTypeName
+	}
+	if len(mutation) == 0 {
+		mutation = DefaultMutationTypeName
+	}
+	if len(subscription) == 0 {
+		subscription = DefaultSubscriptionTypeName
+	}
+	return query, mutation, subscription
+}
+
 func (i *Index) IsRootOperationTypeNameBytes(typeName []byte) bool {
 	if len(typeName) == 0 {
 		return false
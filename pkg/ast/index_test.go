@@ -223,3 +223,32 @@ func TestIndex_ReplaceNode(t *testing.T) {
 		assert.Equal(t, expectedIndexAfter, idx)
 	})
 }
+
+func TestIndex_FirstNonExtensionNodeByName(t *testing.T) {
+	extension := Node{Kind: NodeKindObjectTypeExtension, Ref: 0}
+	definition := Node{Kind: NodeKindObjectTypeDefinition, Ref: 1}
+
+	idx := emptyIndex()
+	idx.AddNodeStr("User", extension)
+	idx.AddNodeStr("User", definition)
+
+	nodes, ok := idx.NodesByNameStr("User")
+	assert.True(t, ok)
+	assert.Equal(t, []Node{extension, definition}, nodes)
+
+	first, ok := idx.FirstNodeByNameStr("User")
+	assert.True(t, ok)
+	assert.Equal(t, extension, first)
+
+	node, ok := idx.FirstNonExtensionNodeByNameStr("User")
+	assert.True(t, ok)
+	assert.Equal(t, definition, node)
+
+	t.Run("only extensions", func(t *testing.T) {
+		idx := emptyIndex()
+		idx.AddNodeStr("User", extension)
+
+		_, ok := idx.FirstNonExtensionNodeByNameStr("User")
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,29 @@
+package ast
+
+// errSealedDocumentModified is the panic value of modifications of a sealed document
+const errSealedDocumentModified = "ast: modification of sealed document"
+
+// Seal marks the document as read-only, e.g. once a schema is parsed, merged and normalized.
+//
+// A sealed document is safe for concurrent use by multiple goroutines as long as they only read it:
+// the helpers of the document looking up nodes, names, types and values, the Index and the Input don't modify the document,
+// so one sealed schema can be shared by validating, normalizing and planning operations concurrently without copying it.
+//
+// Modifying a sealed document is a programming error. Reset and the helpers which add root nodes or refs,
+// which all adding helpers of nodes with lists of children use, panic if the document is sealed.
+// Writes to the exported fields and appending to the Input directly aren't detected.
+// A sealed document can't be unsealed, a copy has to be parsed to modify it.
+func (d *Document) Seal() {
+	d.sealed = true
+}
+
+// Sealed returns true if the document is read-only, see Seal
+func (d *Document) Sealed() bool {
+	return d.sealed
+}
+
+func (d *Document) assertNotSealed() {
+	if d.sealed {
+		panic(errSealedDocumentModified)
+	}
+}
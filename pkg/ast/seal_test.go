@@ -0,0 +1,133 @@
+package ast_test
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astnormalization"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+	"github.com/wundergraph/graphql-go-tools/pkg/asttransform"
+	"github.com/wundergraph/graphql-go-tools/pkg/astvalidation"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+const sealTestSchema = `
+	type Query {
+		user(id: ID!): User
+		search(term: String!): [SearchResult!]!
+	}
+	interface Node { id: ID! }
+	type User implements Node { id: ID! name: String friends(first: Int = 10): [User!]! }
+	type Post implements Node { id: ID! title: String author: User }
+	union SearchResult = User | Post`
+
+func sealedSchema(t *testing.T) *ast.Document {
+	definition := unsafeparser.ParseGraphqlDocumentString(sealTestSchema)
+	require.NoError(t, asttransform.MergeDefinitionWithBaseSchema(&definition))
+	report := operationreport.Report{}
+	astnormalization.NormalizeDefinition(&definition, &report)
+	require.False(t, report.HasErrors(), report.Error())
+	definition.Seal()
+	return &definition
+}
+
+func TestDocument_Seal(t *testing.T) {
+	definition := sealedSchema(t)
+	assert.True(t, definition.Sealed())
+	assert.False(t, ast.NewDocument().Sealed())
+
+	t.Run("allows reads", func(t *testing.T) {
+		node, ok := definition.Index.FirstNodeByNameStr("User")
+		require.True(t, ok)
+		assert.Equal(t, ast.NodeKindObjectTypeDefinition, node.Kind)
+		assert.Equal(t, "User", definition.NodeNameString(node))
+
+		out, err := astprinter.PrintString(definition, nil)
+		require.NoError(t, err)
+		assert.Contains(t, out, "union SearchResult = User | Post")
+	})
+
+	t.Run("panics on modifications", func(t *testing.T) {
+		const sealed = "ast: modification of sealed document"
+		assert.PanicsWithValue(t, sealed, func() { definition.Reset() })
+		assert.PanicsWithValue(t, sealed, func() { definition.NewEmptyRefs() })
+		assert.PanicsWithValue(t, sealed, func() {
+			definition.AddRootNode(ast.Node{Kind: ast.NodeKindScalarTypeDefinition, Ref: 0})
+		})
+		assert.PanicsWithValue(t, sealed, func() { definition.AddSelectionSet() })
+		_, ok := definition.Index.FirstNodeByNameStr("Node")
+		assert.True(t, ok)
+	})
+}
+
+// TestDocument_Seal_ConcurrentReads shares one sealed schema by goroutines normalizing, validating and printing operations,
+// data races are reported when running the tests with the race detector
+func TestDocument_Seal_ConcurrentReads(t *testing.T) {
+	definition := sealedSchema(t)
+	before, err := astprinter.PrintString(definition, nil)
+	require.NoError(t, err)
+
+	operations := []struct {
+		operation string
+		expected  string
+		valid     bool
+	}{
+		{
+			operation: `query User { user(id: "1") { ...UserFields friends { name } } } fragment UserFields on User { id name }`,
+			expected:  `query User {user(id: "1"){id name friends {name}}}`,
+			valid:     true,
+		},
+		{
+			operation: `query Search { search(term: "graphql") { __typename ... on Node { id } ... on Post { title author { name } } } }`,
+			expected:  `query Search {search(term: "graphql"){__typename ... on Node {id} ... on Post {title author {name}}}}`,
+			valid:     true,
+		},
+		{
+			operation: `query Invalid { user(id: "1") { age } }`,
+			valid:     false,
+		},
+	}
+
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			normalizer := astnormalization.NewNormalizer(true, false)
+			validator := astvalidation.DefaultOperationValidator()
+			for j := 0; j < 25; j++ {
+				for _, op := range operations {
+					operation := unsafeparser.ParseGraphqlDocumentString(op.operation)
+					report := operationreport.Report{}
+					normalizer.NormalizeOperation(&operation, definition, &report)
+					state := validator.Validate(&operation, definition, &report)
+					if !op.valid {
+						assert.Equal(t, astvalidation.Invalid, state)
+						continue
+					}
+					if !assert.Equal(t, astvalidation.Valid, state, report.Error()) {
+						return
+					}
+					out, err := astprinter.PrintString(&operation, definition)
+					assert.NoError(t, err)
+					assert.Equal(t, op.expected, out)
+
+					node, ok := definition.Index.FirstNodeByNameStr("SearchResult")
+					assert.True(t, ok)
+					assert.Equal(t, "SearchResult", definition.UnionTypeDefinitionNameString(node.Ref))
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	after := &bytes.Buffer{}
+	require.NoError(t, astprinter.Print(definition, nil, after))
+	assert.Equal(t, before, after.String())
+}
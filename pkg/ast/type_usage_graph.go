@@ -0,0 +1,358 @@
+package ast
+
+import (
+	"sort"
+	"strings"
+)
+
+// TypeUsageGraph describes which named types of a schema reference which other named types,
+// e.g. through the types of fields, arguments and input fields, implemented interfaces and union members.
+// Extensions of a type are part of the type itself.
+// The graph is a snapshot of the document, it doesn't reflect modifications of the document after it was built.
+type TypeUsageGraph struct {
+	names         []string
+	ids           map[string]int
+	defined       []bool
+	references    [][]int
+	referencedBy  [][]int
+	implementedBy [][]int
+	roots         []int
+}
+
+// TypeUsageGraph builds the usage graph of the types of the schema.
+// The root operation types of the schema definition, or Query, Mutation and Subscription without a schema definition,
+// and the types of the arguments of directive definitions are the roots of the graph.
+func (d *Document) TypeUsageGraph() *TypeUsageGraph {
+	g := &TypeUsageGraph{
+		ids: map[string]int{},
+	}
+
+	hasSchemaDefinition := false
+	for _, node := range d.RootNodes {
+		switch node.Kind {
+		case NodeKindSchemaDefinition:
+			hasSchemaDefinition = true
+			g.addRootOperationTypes(d, d.SchemaDefinitions[node.Ref].RootOperationTypeDefinitions.Refs)
+		case NodeKindSchemaExtension:
+			hasSchemaDefinition = true
+			g.addRootOperationTypes(d, d.SchemaExtensions[node.Ref].RootOperationTypeDefinitions.Refs)
+		case NodeKindObjectTypeDefinition:
+			g.addObjectType(d, d.ObjectTypeDefinitions[node.Ref])
+		case NodeKindObjectTypeExtension:
+			g.addObjectType(d, d.ObjectTypeExtensions[node.Ref].ObjectTypeDefinition)
+		case NodeKindInterfaceTypeDefinition:
+			g.addInterfaceType(d, d.InterfaceTypeDefinitions[node.Ref])
+		case NodeKindInterfaceTypeExtension:
+			g.addInterfaceType(d, d.InterfaceTypeExtensions[node.Ref].InterfaceTypeDefinition)
+		case NodeKindUnionTypeDefinition:
+			g.addUnionType(d, d.UnionTypeDefinitions[node.Ref])
+		case NodeKindUnionTypeExtension:
+			g.addUnionType(d, d.UnionTypeExtensions[node.Ref].UnionTypeDefinition)
+		case NodeKindInputObjectTypeDefinition:
+			g.addInputObjectType(d, d.InputObjectTypeDefinitions[node.Ref])
+		case NodeKindInputObjectTypeExtension:
+			g.addInputObjectType(d, d.InputObjectTypeExtensions[node.Ref].InputObjectTypeDefinition)
+		case NodeKindEnumTypeDefinition:
+			g.define(d.EnumTypeDefinitionNameString(node.Ref))
+		case NodeKindEnumTypeExtension:
+			g.define(d.Input.ByteSliceString(d.EnumTypeExtensions[node.Ref].Name))
+		case NodeKindScalarTypeDefinition:
+			g.define(d.ScalarTypeDefinitionNameString(node.Ref))
+		case NodeKindScalarTypeExtension:
+			g.define(d.Input.ByteSliceString(d.ScalarTypeExtensions[node.Ref].Name))
+		case NodeKindDirectiveDefinition:
+			for _, ref := range d.DirectiveDefinitions[node.Ref].ArgumentsDefinition.Refs {
+				g.roots = append(g.roots, g.id(d.ResolveTypeNameString(d.InputValueDefinitions[ref].Type)))
+			}
+		}
+	}
+
+	if !hasSchemaDefinition {
+		for _, name := range []string{string(DefaultQueryTypeName), string(DefaultMutationTypeName), string(DefaultSubscriptionTypeName)} {
+			if id, ok := g.ids[name]; ok && g.defined[id] {
+				g.roots = append(g.roots, id)
+			}
+		}
+	}
+
+	return g
+}
+
+func (g *TypeUsageGraph) id(typeName string) int {
+	if id, ok := g.ids[typeName]; ok {
+		return id
+	}
+	id := len(g.names)
+	g.ids[typeName] = id
+	g.names = append(g.names, typeName)
+	g.defined = append(g.defined, false)
+	g.references = append(g.references, nil)
+	g.referencedBy = append(g.referencedBy, nil)
+	g.implementedBy = append(g.implementedBy, nil)
+	return id
+}
+
+func (g *TypeUsageGraph) define(typeName string) int {
+	id := g.id(typeName)
+	g.defined[id] = true
+	return id
+}
+
+func (g *TypeUsageGraph) addReference(from int, typeName string) int {
+	to := g.id(typeName)
+	for _, existing := range g.references[from] {
+		if existing == to {
+			return to
+		}
+	}
+	g.references[from] = append(g.references[from], to)
+	g.referencedBy[to] = append(g.referencedBy[to], from)
+	return to
+}
+
+func (g *TypeUsageGraph) addRootOperationTypes(d *Document, refs []int) {
+	for _, ref := range refs {
+		g.roots = append(g.roots, g.id(d.Input.ByteSliceString(d.RootOperationTypeDefinitions[ref].NamedType.Name)))
+	}
+}
+
+func (g *TypeUsageGraph) addFields(d *Document, from int, refs []int) {
+	for _, ref := range refs {
+		g.addReference(from, d.ResolveTypeNameString(d.FieldDefinitions[ref].Type))
+		g.addInputValues(d, from, d.FieldDefinitions[ref].ArgumentsDefinition.Refs)
+	}
+}
+
+func (g *TypeUsageGraph) addInputValues(d *Document, from int, refs []int) {
+	for _, ref := range refs {
+		g.addReference(from, d.ResolveTypeNameString(d.InputValueDefinitions[ref].Type))
+	}
+}
+
+func (g *TypeUsageGraph) addImplementedInterfaces(d *Document, from int, refs []int) {
+	for _, ref := range refs {
+		to := g.addReference(from, d.ResolveTypeNameString(ref))
+		g.implementedBy[to] = append(g.implementedBy[to], from)
+	}
+}
+
+func (g *TypeUsageGraph) addObjectType(d *Document, definition ObjectTypeDefinition) {
+	id := g.define(d.Input.ByteSliceString(definition.Name))
+	g.addImplementedInterfaces(d, id, definition.ImplementsInterfaces.Refs)
+	g.addFields(d, id, definition.FieldsDefinition.Refs)
+}
+
+func (g *TypeUsageGraph) addInterfaceType(d *Document, definition InterfaceTypeDefinition) {
+	id := g.define(d.Input.ByteSliceString(definition.Name))
+	g.addImplementedInterfaces(d, id, definition.ImplementsInterfaces.Refs)
+	g.addFields(d, id, definition.FieldsDefinition.Refs)
+}
+
+func (g *TypeUsageGraph) addUnionType(d *Document, definition UnionTypeDefinition) {
+	id := g.define(d.Input.ByteSliceString(definition.Name))
+	for _, ref := range definition.UnionMemberTypes.Refs {
+		g.addReference(id, d.ResolveTypeNameString(ref))
+	}
+}
+
+func (g *TypeUsageGraph) addInputObjectType(d *Document, definition InputObjectTypeDefinition) {
+	id := g.define(d.Input.ByteSliceString(definition.Name))
+	g.addInputValues(d, id, definition.InputFieldsDefinition.Refs)
+}
+
+func (g *TypeUsageGraph) typeNames(ids []int) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+	names := make([]string, len(ids))
+	for i := range ids {
+		names[i] = g.names[ids[i]]
+	}
+	return names
+}
+
+// TypeNames returns the names of all defined types in the order of their first appearance in the document
+func (g *TypeUsageGraph) TypeNames() []string {
+	names := make([]string, 0, len(g.names))
+	for id, name := range g.names {
+		if g.defined[id] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// References returns the names of the types directly referenced by the type
+func (g *TypeUsageGraph) References(typeName string) []string {
+	id, ok := g.ids[typeName]
+	if !ok {
+		return nil
+	}
+	return g.typeNames(g.references[id])
+}
+
+// ReferencedBy returns the names of the types directly referencing the type
+func (g *TypeUsageGraph) ReferencedBy(typeName string) []string {
+	id, ok := g.ids[typeName]
+	if !ok {
+		return nil
+	}
+	return g.typeNames(g.referencedBy[id])
+}
+
+// UndefinedTypes returns the names of the types which are referenced but not defined, except the built-in scalars
+func (g *TypeUsageGraph) UndefinedTypes() []string {
+	var names []string
+	for id, name := range g.names {
+		if !g.defined[id] && !isBuiltInScalar(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Reachable returns the names of the defined types reachable from the roots of the graph in the order of their first appearance in the document.
+// The object types implementing a reachable interface are reachable as well, because they can be returned for the interface.
+// The additional roots are treated like roots of the graph, e.g. to keep types which are used outside of the schema.
+func (g *TypeUsageGraph) Reachable(additionalRoots ...string) []string {
+	reachable := g.reachable(additionalRoots)
+	names := make([]string, 0, len(g.names))
+	for id, name := range g.names {
+		if reachable[id] && g.defined[id] {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// IsReachable reports whether the type is reachable from the roots of the graph, see Reachable
+func (g *TypeUsageGraph) IsReachable(typeName string) bool {
+	id, ok := g.ids[typeName]
+	if !ok {
+		return false
+	}
+	return g.reachable(nil)[id]
+}
+
+// Orphans returns the names of the defined types which aren't reachable from the roots of the graph.
+// Introspection types and the built-in scalars are never orphans, neither are the types reachable from the additional roots.
+func (g *TypeUsageGraph) Orphans(additionalRoots ...string) []string {
+	reachable := g.reachable(additionalRoots)
+	var names []string
+	for id, name := range g.names {
+		if !g.defined[id] || reachable[id] || strings.HasPrefix(name, "__") || isBuiltInScalar(name) {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func (g *TypeUsageGraph) reachable(additionalRoots []string) []bool {
+	reachable := make([]bool, len(g.names))
+	stack := append([]int(nil), g.roots...)
+	for _, typeName := range additionalRoots {
+		if id, ok := g.ids[typeName]; ok {
+			stack = append(stack, id)
+		}
+	}
+	for len(stack) != 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if reachable[id] {
+			continue
+		}
+		reachable[id] = true
+		stack = append(stack, g.references[id]...)
+		stack = append(stack, g.implementedBy[id]...)
+	}
+	return reachable
+}
+
+// Cycles returns the groups of types referencing each other directly or indirectly, including types referencing themselves.
+// Each group is a strongly connected component of the graph, sorted in the order of first appearance like the groups themselves.
+func (g *TypeUsageGraph) Cycles() [][]string {
+	var (
+		index    = 0
+		indices  = make([]int, len(g.names))
+		lowLinks = make([]int, len(g.names))
+		onStack  = make([]bool, len(g.names))
+		stack    []int
+		cycles   [][]int
+	)
+	for i := range indices {
+		indices[i] = -1
+	}
+
+	var connect func(id int)
+	connect = func(id int) {
+		indices[id], lowLinks[id] = index, index
+		index++
+		stack = append(stack, id)
+		onStack[id] = true
+
+		for _, to := range g.references[id] {
+			if indices[to] == -1 {
+				connect(to)
+				if lowLinks[to] < lowLinks[id] {
+					lowLinks[id] = lowLinks[to]
+				}
+			} else if onStack[to] && indices[to] < lowLinks[id] {
+				lowLinks[id] = indices[to]
+			}
+		}
+
+		if lowLinks[id] != indices[id] {
+			return
+		}
+		var component []int
+		for {
+			member := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[member] = false
+			component = append(component, member)
+			if member == id {
+				break
+			}
+		}
+		if len(component) > 1 || g.referencesItself(id) {
+			sort.Ints(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for id := range g.names {
+		if indices[id] == -1 {
+			connect(id)
+		}
+	}
+
+	if len(cycles) == 0 {
+		return nil
+	}
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i][0] < cycles[j][0]
+	})
+	names := make([][]string, len(cycles))
+	for i := range cycles {
+		names[i] = g.typeNames(cycles[i])
+	}
+	return names
+}
+
+func (g *TypeUsageGraph) referencesItself(id int) bool {
+	for _, to := range g.references[id] {
+		if to == id {
+			return true
+		}
+	}
+	return false
+}
+
+func isBuiltInScalar(typeName string) bool {
+	switch typeName {
+	case "Int", "Float", "String", "Boolean", "ID":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package ast_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeparser"
+)
+
+func TestDocument_TypeUsageGraph(t *testing.T) {
+	doc := unsafeparser.ParseGraphqlDocumentString(`
+		directive @limit(options: LimitOptions) on FIELD
+		type Query {
+			user(filter: UserFilter): User
+			search: SearchResult
+			node: Node
+		}
+		interface Node { id: ID! }
+		type User implements Node { id: ID! friends: [User!]! posts: [Post] }
+		type Post { author: Author }
+		type Author { posts: [Post] }
+		type Photo implements Node { id: ID! }
+		union SearchResult = User | Post
+		input UserFilter { name: String and: [UserFilter!] role: Role }
+		input LimitOptions { max: Int }
+		enum Role { ADMIN USER }
+		type Unused { value: Orphaned }
+		type Orphaned { unused: Unused missing: Undefined }
+		extend type Post { tags: [Tag] }
+		scalar Tag
+	`)
+	graph := doc.TypeUsageGraph()
+
+	t.Run("references", func(t *testing.T) {
+		assert.Equal(t, []string{"User", "UserFilter", "SearchResult", "Node"}, graph.References("Query"))
+		assert.Equal(t, []string{"Node", "ID", "User", "Post"}, graph.References("User"))
+		assert.Equal(t, []string{"Author", "Tag"}, graph.References("Post"))
+		assert.Nil(t, graph.References("Role"))
+		assert.Nil(t, graph.References("NotExisting"))
+	})
+
+	t.Run("referenced by", func(t *testing.T) {
+		assert.Equal(t, []string{"Query", "User", "SearchResult"}, graph.ReferencedBy("User"))
+		assert.Equal(t, []string{"Query", "User", "Photo"}, graph.ReferencedBy("Node"))
+	})
+
+	t.Run("reachability", func(t *testing.T) {
+		assert.True(t, graph.IsReachable("Photo"), "implementations of reachable interfaces are reachable")
+		assert.True(t, graph.IsReachable("LimitOptions"), "argument types of directives are reachable")
+		assert.True(t, graph.IsReachable("Tag"), "types referenced by extensions are reachable")
+		assert.False(t, graph.IsReachable("Unused"))
+		assert.Equal(t, []string{"Unused", "Orphaned"}, graph.Orphans())
+		assert.Equal(t, []string{"Undefined"}, graph.UndefinedTypes())
+	})
+
+	t.Run("cycles", func(t *testing.T) {
+		assert.Equal(t, [][]string{
+			{"User"},
+			{"UserFilter"},
+			{"Post", "Author"},
+			{"Unused", "Orphaned"},
+		}, graph.Cycles())
+	})
+
+	t.Run("default root operation types", func(t *testing.T) {
+		doc := unsafeparser.ParseGraphqlDocumentString(`
+			type Query { hello: String }
+			type Mutation { greet: Greeting }
+			type Greeting { text: String }
+			type Other { text: String }`)
+		graph := doc.TypeUsageGraph()
+		assert.Equal(t, []string{"Query", "Mutation", "Greeting", "Other"}, graph.TypeNames())
+		assert.Equal(t, []string{"Query", "Mutation", "Greeting"}, graph.Reachable())
+		assert.Equal(t, []string{"Other"}, graph.Orphans())
+		assert.Nil(t, graph.Cycles())
+	})
+
+	t.Run("schema definition", func(t *testing.T) {
+		doc := unsafeparser.ParseGraphqlDocumentString(`
+			schema { query: RootQuery }
+			type RootQuery { hello: String }
+			type Query { hello: String }`)
+		assert.Equal(t, []string{"Query"}, doc.TypeUsageGraph().Orphans())
+	})
+}
@@ -227,3 +227,99 @@ func (i *Importer) ImportField(ref int, from, to *ast.Document) int {
 	to.Fields = append(to.Fields, field)
 	return len(to.Fields) - 1
 }
+
+// ImportFragmentDefinition imports the fragment definition including its selections and adds it to the root nodes,
+// e.g. to add a fragment to an operation without printing and re-parsing the operation
+func (i *Importer) ImportFragmentDefinition(ref int, from, to *ast.Document) int {
+	fragment := from.FragmentDefinitions[ref]
+	definition := ast.FragmentDefinition{
+		Name:          to.Input.AppendInputBytes(from.FragmentDefinitionNameBytes(ref)),
+		TypeCondition: ast.TypeCondition{Type: i.ImportType(fragment.TypeCondition.Type, from, to)},
+		Directives:    ast.DirectiveList{Refs: i.importDirectives(fragment.Directives.Refs, from, to)},
+		SelectionSet:  -1,
+	}
+	if fragment.HasVariableDefinitions {
+		definition.HasVariableDefinitions = true
+		definition.VariableDefinitions.Refs = i.ImportVariableDefinitions(fragment.VariableDefinitions.Refs, from, to)
+	}
+	if fragment.HasSelections {
+		definition.HasSelections = true
+		definition.SelectionSet = i.ImportSelectionSet(fragment.SelectionSet, from, to)
+	}
+	to.FragmentDefinitions = append(to.FragmentDefinitions, definition)
+	definitionRef := len(to.FragmentDefinitions) - 1
+	to.ImportRootNode(definitionRef, ast.NodeKindFragmentDefinition)
+	return definitionRef
+}
+
+// ImportSelectionSet imports the selection set with all fields, inline fragments and fragment spreads recursively
+func (i *Importer) ImportSelectionSet(ref int, from, to *ast.Document) int {
+	selectionRefs := make([]int, 0, len(from.SelectionSets[ref].SelectionRefs))
+	for _, selectionRef := range from.SelectionSets[ref].SelectionRefs {
+		selection := from.Selections[selectionRef]
+		imported := ast.Selection{Kind: selection.Kind}
+		switch selection.Kind {
+		case ast.SelectionKindField:
+			imported.Ref = i.importSelectionField(selection.Ref, from, to)
+		case ast.SelectionKindInlineFragment:
+			imported.Ref = i.importInlineFragment(selection.Ref, from, to)
+		case ast.SelectionKindFragmentSpread:
+			imported.Ref = i.importFragmentSpread(selection.Ref, from, to)
+		}
+		selectionRefs = append(selectionRefs, to.AddSelectionToDocument(imported))
+	}
+	return to.AddSelectionSetToDocument(ast.SelectionSet{SelectionRefs: selectionRefs})
+}
+
+// importSelectionField imports a field like ImportField including its directives, nullability designator and selections
+func (i *Importer) importSelectionField(ref int, from, to *ast.Document) int {
+	fieldRef := i.ImportField(ref, from, to)
+	field := from.Fields[ref]
+	to.Fields[fieldRef].Nullability = field.Nullability
+	if field.HasDirectives {
+		to.Fields[fieldRef].HasDirectives = true
+		to.Fields[fieldRef].Directives.Refs = i.importDirectives(field.Directives.Refs, from, to)
+	}
+	if field.HasSelections {
+		selectionSet := i.ImportSelectionSet(field.SelectionSet, from, to)
+		to.Fields[fieldRef].HasSelections = true
+		to.Fields[fieldRef].SelectionSet = selectionSet
+	}
+	return fieldRef
+}
+
+func (i *Importer) importInlineFragment(ref int, from, to *ast.Document) int {
+	fragment := from.InlineFragments[ref]
+	inlineFragment := ast.InlineFragment{
+		TypeCondition: ast.TypeCondition{Type: -1},
+		SelectionSet:  -1,
+	}
+	if fragment.TypeCondition.Type != -1 {
+		inlineFragment.TypeCondition.Type = i.ImportType(fragment.TypeCondition.Type, from, to)
+	}
+	if fragment.HasDirectives {
+		inlineFragment.HasDirectives = true
+		inlineFragment.Directives.Refs = i.importDirectives(fragment.Directives.Refs, from, to)
+	}
+	if fragment.HasSelections {
+		inlineFragment.HasSelections = true
+		inlineFragment.SelectionSet = i.ImportSelectionSet(fragment.SelectionSet, from, to)
+	}
+	return to.AddInlineFragment(inlineFragment)
+}
+
+func (i *Importer) importFragmentSpread(ref int, from, to *ast.Document) int {
+	spread := from.FragmentSpreads[ref]
+	fragmentSpread := ast.FragmentSpread{
+		FragmentName: to.Input.AppendInputBytes(from.FragmentSpreadNameBytes(ref)),
+	}
+	if spread.HasArguments {
+		fragmentSpread.HasArguments = true
+		fragmentSpread.Arguments.Refs = i.ImportArguments(spread.Arguments.Refs, from, to)
+	}
+	if spread.HasDirectives {
+		fragmentSpread.HasDirectives = true
+		fragmentSpread.Directives.Refs = i.importDirectives(spread.Directives.Refs, from, to)
+	}
+	return to.AddFragmentSpread(fragmentSpread)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
 	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
 	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
 )
 
@@ -318,3 +319,32 @@ func TestImporter_ImportVariableDefinitions(t *testing.T) {
 		[]int{0, 1},
 	))
 }
+
+func TestImporter_ImportFragmentDefinition(t *testing.T) {
+	fragment := `fragment UserCard($size: Int = 50) on User {id! name? ... on Admin @include(if: true) {role} ...Pic(size: $size) friends(first: 1){id}}`
+
+	from := ast.NewDocument()
+	from.Input.ResetInputString(fragment)
+	report := &operationreport.Report{}
+	parser := astparser.NewParser()
+	parser.EnableFragmentArguments()
+	parser.EnableClientControlledNullability()
+	parser.Parse(from, report)
+	require.False(t, report.HasErrors(), report.Error())
+
+	to := ast.NewDocument()
+	to.Input.ResetInputString(`{user {...UserCard}}`)
+	parser.Parse(to, report)
+	require.False(t, report.HasErrors(), report.Error())
+
+	importer := &Importer{}
+	ref := importer.ImportFragmentDefinition(0, from, to)
+
+	_, exists := to.FragmentDefinitionRef([]byte("UserCard"))
+	assert.True(t, exists)
+	assert.Equal(t, ast.Node{Kind: ast.NodeKindFragmentDefinition, Ref: ref}, to.RootNodes[len(to.RootNodes)-1])
+
+	out, err := astprinter.PrintString(to, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `{user {...UserCard}} `+fragment, out)
+}
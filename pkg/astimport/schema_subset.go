@@ -0,0 +1,387 @@
+package astimport
+
+import (
+	"fmt"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+)
+
+// RootFields selects fields of a root operation type, e.g. {TypeName: "Query", FieldNames: []string{"user", "users"}}
+type RootFields struct {
+	TypeName   string
+	FieldNames []string
+}
+
+// ImportSchemaSubset imports the definitions reachable from the root fields into the document to,
+// e.g. to generate the slice of a schema owned by a team or a focused test fixture.
+// Reachable are the types of the fields, arguments and input fields of imported types, the interfaces, implementations
+// and union members of imported types, and the definitions of the directives used on imported definitions.
+// Root operation types only contain the selected fields, unless they are reachable from another imported field.
+//
+// The definition must not contain type extensions, e.g. it's normalized with astnormalization.NormalizeDefinition.
+// Types without definition in the document, e.g. built-in scalars when the base schema isn't merged, are skipped.
+func (i *Importer) ImportSchemaSubset(from, to *ast.Document, rootFields []RootFields) error {
+	subset := &schemaSubset{
+		from:                 from,
+		types:                map[string]bool{},
+		directives:           map[string]bool{},
+		partialTypes:         map[string]map[string]bool{},
+		directiveDefinitions: map[string]int{},
+	}
+	for _, node := range from.RootNodes {
+		if node.Kind == ast.NodeKindDirectiveDefinition {
+			subset.directiveDefinitions[from.DirectiveDefinitionNameString(node.Ref)] = node.Ref
+		}
+	}
+	if err := subset.selectRootFields(rootFields); err != nil {
+		return err
+	}
+	subset.walk()
+
+	for _, node := range from.RootNodes {
+		switch node.Kind {
+		case ast.NodeKindSchemaDefinition:
+			i.importSchemaDefinition(subset, from, to)
+		case ast.NodeKindDirectiveDefinition:
+			if subset.directives[from.DirectiveDefinitionNameString(node.Ref)] {
+				i.importDirectiveDefinition(node.Ref, from, to)
+			}
+		case ast.NodeKindObjectTypeDefinition, ast.NodeKindInterfaceTypeDefinition, ast.NodeKindUnionTypeDefinition,
+			ast.NodeKindEnumTypeDefinition, ast.NodeKindInputObjectTypeDefinition, ast.NodeKindScalarTypeDefinition:
+			if subset.types[from.NodeNameString(node)] {
+				i.importTypeDefinition(node, subset, from, to)
+			}
+		}
+	}
+	return nil
+}
+
+// schemaSubset collects the names of the types and directives reachable from the root fields
+type schemaSubset struct {
+	from       *ast.Document
+	types      map[string]bool
+	directives map[string]bool
+	// partialTypes are the selected fields of the root operation types which aren't reachable from other fields
+	partialTypes         map[string]map[string]bool
+	directiveDefinitions map[string]int
+	queue                []string
+}
+
+func (s *schemaSubset) selectRootFields(rootFields []RootFields) error {
+	query, mutation, subscription := s.from.Index.RootOperationTypeNames()
+	rootTypeNames := map[string]bool{
+		query.String():        true,
+		mutation.String():     true,
+		subscription.String(): true,
+	}
+	for _, root := range rootFields {
+		node, ok := s.from.Index.FirstNonExtensionNodeByNameStr(root.TypeName)
+		if !ok || node.Kind != ast.NodeKindObjectTypeDefinition || !rootTypeNames[root.TypeName] {
+			return fmt.Errorf("astimport: %s is not a root operation type", root.TypeName)
+		}
+		fields, ok := s.partialTypes[root.TypeName]
+		if !ok {
+			fields = map[string]bool{}
+			s.partialTypes[root.TypeName] = fields
+			s.types[root.TypeName] = true
+			s.queue = append(s.queue, root.TypeName)
+		}
+		for _, fieldName := range root.FieldNames {
+			if _, ok := s.from.NodeFieldDefinitionByName(node, []byte(fieldName)); !ok {
+				return fmt.Errorf("astimport: field %s.%s is not defined", root.TypeName, fieldName)
+			}
+			fields[fieldName] = true
+		}
+	}
+	return nil
+}
+
+// reachType adds the type to the subset, partially imported root operation types are imported completely once reached
+func (s *schemaSubset) reachType(name string) {
+	if _, ok := s.partialTypes[name]; ok {
+		delete(s.partialTypes, name)
+		s.queue = append(s.queue, name)
+		return
+	}
+	if s.types[name] {
+		return
+	}
+	s.types[name] = true
+	s.queue = append(s.queue, name)
+}
+
+func (s *schemaSubset) reachDirectives(refs []int) {
+	for _, ref := range refs {
+		name := s.from.DirectiveNameString(ref)
+		if s.directives[name] {
+			continue
+		}
+		s.directives[name] = true
+		if definition, ok := s.directiveDefinitions[name]; ok {
+			s.reachInputValues(s.from.DirectiveDefinitions[definition].ArgumentsDefinition.Refs)
+		}
+	}
+}
+
+func (s *schemaSubset) reachInputValues(refs []int) {
+	for _, ref := range refs {
+		s.reachType(s.from.ResolveTypeNameString(s.from.InputValueDefinitions[ref].Type))
+		s.reachDirectives(s.from.InputValueDefinitions[ref].Directives.Refs)
+	}
+}
+
+func (s *schemaSubset) walk() {
+	for len(s.queue) != 0 {
+		name := s.queue[0]
+		s.queue = s.queue[1:]
+		node, ok := s.from.Index.FirstNonExtensionNodeByNameStr(name)
+		if !ok {
+			continue
+		}
+		s.reachDirectives(s.from.NodeDirectives(node))
+
+		switch node.Kind {
+		case ast.NodeKindObjectTypeDefinition, ast.NodeKindInterfaceTypeDefinition:
+			for _, ref := range s.fieldDefinitions(node) {
+				s.reachType(s.from.ResolveTypeNameString(s.from.FieldDefinitions[ref].Type))
+				s.reachInputValues(s.from.FieldDefinitions[ref].ArgumentsDefinition.Refs)
+				s.reachDirectives(s.from.FieldDefinitions[ref].Directives.Refs)
+			}
+			for _, ref := range s.implementedInterfaces(node) {
+				s.reachType(s.from.TypeNameString(ref))
+			}
+			if node.Kind == ast.NodeKindInterfaceTypeDefinition {
+				s.reachImplementations(name)
+			}
+		case ast.NodeKindUnionTypeDefinition:
+			for _, ref := range s.from.UnionTypeDefinitions[node.Ref].UnionMemberTypes.Refs {
+				s.reachType(s.from.TypeNameString(ref))
+			}
+		case ast.NodeKindEnumTypeDefinition:
+			for _, ref := range s.from.EnumTypeDefinitions[node.Ref].EnumValuesDefinition.Refs {
+				s.reachDirectives(s.from.EnumValueDefinitions[ref].Directives.Refs)
+			}
+		case ast.NodeKindInputObjectTypeDefinition:
+			s.reachInputValues(s.from.InputObjectTypeDefinitions[node.Ref].InputFieldsDefinition.Refs)
+		}
+	}
+}
+
+// reachImplementations adds the types implementing the interface, as they can be selected by fragments on the interface
+func (s *schemaSubset) reachImplementations(interfaceName string) {
+	for _, node := range s.from.RootNodes {
+		if node.Kind != ast.NodeKindObjectTypeDefinition && node.Kind != ast.NodeKindInterfaceTypeDefinition {
+			continue
+		}
+		for _, ref := range s.implementedInterfaces(node) {
+			if s.from.TypeNameString(ref) == interfaceName {
+				s.reachType(s.from.NodeNameString(node))
+				break
+			}
+		}
+	}
+}
+
+func (s *schemaSubset) implementedInterfaces(node ast.Node) []int {
+	switch node.Kind {
+	case ast.NodeKindObjectTypeDefinition:
+		return s.from.ObjectTypeDefinitions[node.Ref].ImplementsInterfaces.Refs
+	case ast.NodeKindInterfaceTypeDefinition:
+		return s.from.InterfaceTypeDefinitions[node.Ref].ImplementsInterfaces.Refs
+	default:
+		return nil
+	}
+}
+
+// fieldDefinitions returns the field definitions of the node, they are limited to the selected fields of partially imported root operation types
+func (s *schemaSubset) fieldDefinitions(node ast.Node) []int {
+	refs := s.from.NodeFieldDefinitions(node)
+	selected, ok := s.partialTypes[s.from.NodeNameString(node)]
+	if !ok {
+		return refs
+	}
+	fields := make([]int, 0, len(selected))
+	for _, ref := range refs {
+		if selected[s.from.FieldDefinitionNameString(ref)] {
+			fields = append(fields, ref)
+		}
+	}
+	return fields
+}
+
+func (i *Importer) importSchemaDefinition(subset *schemaSubset, from, to *ast.Document) {
+	rootTypeName := func(name ast.ByteSlice) string {
+		if !subset.types[string(name)] {
+			return ""
+		}
+		return string(name)
+	}
+	to.ImportSchemaDefinition(
+		rootTypeName(from.Index.QueryTypeName),
+		rootTypeName(from.Index.MutationTypeName),
+		rootTypeName(from.Index.SubscriptionTypeName),
+	)
+}
+
+func (i *Importer) importTypeDefinition(node ast.Node, subset *schemaSubset, from, to *ast.Document) {
+	switch node.Kind {
+	case ast.NodeKindObjectTypeDefinition:
+		definition := from.ObjectTypeDefinitions[node.Ref]
+		fields := i.importFieldDefinitions(subset.fieldDefinitions(node), from, to)
+		ref := to.AddObjectTypeDefinition(ast.ObjectTypeDefinition{
+			Description:          i.importDescription(definition.Description, from, to),
+			Name:                 to.Input.AppendInputBytes(from.Input.ByteSlice(definition.Name)),
+			ImplementsInterfaces: ast.TypeList{Refs: i.importTypes(definition.ImplementsInterfaces.Refs, from, to)},
+			HasDirectives:        definition.HasDirectives,
+			Directives:           ast.DirectiveList{Refs: i.importDirectives(definition.Directives.Refs, from, to)},
+			HasFieldDefinitions:  len(fields) != 0,
+			FieldsDefinition:     ast.FieldDefinitionList{Refs: fields},
+		})
+		to.ImportRootNode(ref, ast.NodeKindObjectTypeDefinition)
+	case ast.NodeKindInterfaceTypeDefinition:
+		definition := from.InterfaceTypeDefinitions[node.Ref]
+		fields := i.importFieldDefinitions(definition.FieldsDefinition.Refs, from, to)
+		ref := to.AddInterfaceTypeDefinition(ast.InterfaceTypeDefinition{
+			Description:          i.importDescription(definition.Description, from, to),
+			Name:                 to.Input.AppendInputBytes(from.Input.ByteSlice(definition.Name)),
+			ImplementsInterfaces: ast.TypeList{Refs: i.importTypes(definition.ImplementsInterfaces.Refs, from, to)},
+			HasDirectives:        definition.HasDirectives,
+			Directives:           ast.DirectiveList{Refs: i.importDirectives(definition.Directives.Refs, from, to)},
+			HasFieldDefinitions:  len(fields) != 0,
+			FieldsDefinition:     ast.FieldDefinitionList{Refs: fields},
+		})
+		to.ImportRootNode(ref, ast.NodeKindInterfaceTypeDefinition)
+	case ast.NodeKindUnionTypeDefinition:
+		definition := from.UnionTypeDefinitions[node.Ref]
+		ref := to.AddUnionTypeDefinition(ast.UnionTypeDefinition{
+			Description:         i.importDescription(definition.Description, from, to),
+			Name:                to.Input.AppendInputBytes(from.Input.ByteSlice(definition.Name)),
+			HasDirectives:       definition.HasDirectives,
+			Directives:          ast.DirectiveList{Refs: i.importDirectives(definition.Directives.Refs, from, to)},
+			HasUnionMemberTypes: definition.HasUnionMemberTypes,
+			UnionMemberTypes:    ast.TypeList{Refs: i.importTypes(definition.UnionMemberTypes.Refs, from, to)},
+		})
+		to.ImportRootNode(ref, ast.NodeKindUnionTypeDefinition)
+	case ast.NodeKindEnumTypeDefinition:
+		definition := from.EnumTypeDefinitions[node.Ref]
+		values := make([]int, 0, len(definition.EnumValuesDefinition.Refs))
+		for _, valueRef := range definition.EnumValuesDefinition.Refs {
+			value := from.EnumValueDefinitions[valueRef]
+			values = append(values, to.AddEnumValueDefinition(ast.EnumValueDefinition{
+				Description:   i.importDescription(value.Description, from, to),
+				EnumValue:     to.Input.AppendInputBytes(from.Input.ByteSlice(value.EnumValue)),
+				HasDirectives: value.HasDirectives,
+				Directives:    ast.DirectiveList{Refs: i.importDirectives(value.Directives.Refs, from, to)},
+			}))
+		}
+		ref := to.AddEnumTypeDefinition(ast.EnumTypeDefinition{
+			Description:             i.importDescription(definition.Description, from, to),
+			Name:                    to.Input.AppendInputBytes(from.Input.ByteSlice(definition.Name)),
+			HasDirectives:           definition.HasDirectives,
+			Directives:              ast.DirectiveList{Refs: i.importDirectives(definition.Directives.Refs, from, to)},
+			HasEnumValuesDefinition: len(values) != 0,
+			EnumValuesDefinition:    ast.EnumValueDefinitionList{Refs: values},
+		})
+		to.ImportRootNode(ref, ast.NodeKindEnumTypeDefinition)
+	case ast.NodeKindInputObjectTypeDefinition:
+		definition := from.InputObjectTypeDefinitions[node.Ref]
+		fields := i.importInputValueDefinitions(definition.InputFieldsDefinition.Refs, from, to)
+		ref := to.AddInputObjectTypeDefinition(ast.InputObjectTypeDefinition{
+			Description:              i.importDescription(definition.Description, from, to),
+			Name:                     to.Input.AppendInputBytes(from.Input.ByteSlice(definition.Name)),
+			HasDirectives:            definition.HasDirectives,
+			Directives:               ast.DirectiveList{Refs: i.importDirectives(definition.Directives.Refs, from, to)},
+			HasInputFieldsDefinition: len(fields) != 0,
+			InputFieldsDefinition:    ast.InputValueDefinitionList{Refs: fields},
+		})
+		to.ImportRootNode(ref, ast.NodeKindInputObjectTypeDefinition)
+	case ast.NodeKindScalarTypeDefinition:
+		definition := from.ScalarTypeDefinitions[node.Ref]
+		ref := to.AddScalarTypeDefinition(ast.ScalarTypeDefinition{
+			Description:   i.importDescription(definition.Description, from, to),
+			Name:          to.Input.AppendInputBytes(from.Input.ByteSlice(definition.Name)),
+			HasDirectives: definition.HasDirectives,
+			Directives:    ast.DirectiveList{Refs: i.importDirectives(definition.Directives.Refs, from, to)},
+		})
+		to.ImportRootNode(ref, ast.NodeKindScalarTypeDefinition)
+	}
+}
+
+func (i *Importer) importDirectiveDefinition(ref int, from, to *ast.Document) {
+	definition := from.DirectiveDefinitions[ref]
+	arguments := i.importInputValueDefinitions(definition.ArgumentsDefinition.Refs, from, to)
+	ref = to.AddDirectiveDefinition(ast.DirectiveDefinition{
+		Description:             i.importDescription(definition.Description, from, to),
+		Name:                    to.Input.AppendInputBytes(from.Input.ByteSlice(definition.Name)),
+		HasArgumentsDefinitions: len(arguments) != 0,
+		ArgumentsDefinition:     ast.InputValueDefinitionList{Refs: arguments},
+		DirectiveLocations:      definition.DirectiveLocations,
+		Repeatable:              ast.Repeatable{IsRepeatable: definition.Repeatable.IsRepeatable},
+	})
+	to.ImportRootNode(ref, ast.NodeKindDirectiveDefinition)
+}
+
+func (i *Importer) importFieldDefinitions(refs []int, from, to *ast.Document) []int {
+	fields := make([]int, 0, len(refs))
+	for _, ref := range refs {
+		definition := from.FieldDefinitions[ref]
+		arguments := i.importInputValueDefinitions(definition.ArgumentsDefinition.Refs, from, to)
+		fields = append(fields, to.AddFieldDefinition(ast.FieldDefinition{
+			Description:             i.importDescription(definition.Description, from, to),
+			Name:                    to.Input.AppendInputBytes(from.Input.ByteSlice(definition.Name)),
+			HasArgumentsDefinitions: len(arguments) != 0,
+			ArgumentsDefinition:     ast.InputValueDefinitionList{Refs: arguments},
+			Type:                    i.ImportType(definition.Type, from, to),
+			HasDirectives:           definition.HasDirectives,
+			Directives:              ast.DirectiveList{Refs: i.importDirectives(definition.Directives.Refs, from, to)},
+		}))
+	}
+	return fields
+}
+
+func (i *Importer) importInputValueDefinitions(refs []int, from, to *ast.Document) []int {
+	values := make([]int, 0, len(refs))
+	for _, ref := range refs {
+		definition := from.InputValueDefinitions[ref]
+		defaultValue := ast.DefaultValue{IsDefined: definition.DefaultValue.IsDefined}
+		if defaultValue.IsDefined {
+			defaultValue.Value = i.ImportValue(definition.DefaultValue.Value, from, to)
+		}
+		values = append(values, to.AddInputValueDefinition(ast.InputValueDefinition{
+			Description:   i.importDescription(definition.Description, from, to),
+			Name:          to.Input.AppendInputBytes(from.Input.ByteSlice(definition.Name)),
+			Type:          i.ImportType(definition.Type, from, to),
+			DefaultValue:  defaultValue,
+			HasDirectives: definition.HasDirectives,
+			Directives:    ast.DirectiveList{Refs: i.importDirectives(definition.Directives.Refs, from, to)},
+		}))
+	}
+	return values
+}
+
+func (i *Importer) importTypes(refs []int, from, to *ast.Document) []int {
+	types := make([]int, 0, len(refs))
+	for _, ref := range refs {
+		types = append(types, i.ImportType(ref, from, to))
+	}
+	return types
+}
+
+func (i *Importer) importDirectives(refs []int, from, to *ast.Document) []int {
+	directives := make([]int, 0, len(refs))
+	for _, ref := range refs {
+		directives = append(directives, i.ImportDirective(ref, from, to))
+	}
+	return directives
+}
+
+func (i *Importer) importDescription(description ast.Description, from, to *ast.Document) ast.Description {
+	if !description.IsDefined {
+		return ast.Description{}
+	}
+	return ast.Description{
+		IsDefined:     true,
+		IsBlockString: description.IsBlockString,
+		Content:       to.Input.AppendInputBytes(from.Input.ByteSlice(description.Content)),
+	}
+}
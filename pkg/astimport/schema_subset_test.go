@@ -0,0 +1,116 @@
+package astimport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+)
+
+func TestImporter_ImportSchemaSubset(t *testing.T) {
+	const definition = `
+		schema { query: Query mutation: Mutation }
+		directive @owner(team: Team!) on OBJECT | FIELD_DEFINITION
+		directive @unused on FIELD_DEFINITION
+		enum Team { USERS PRODUCTS }
+		type Query {
+			"the user by id"
+			user(id: ID!): User @owner(team: USERS)
+			search(filter: SearchFilter): [SearchResult!]!
+			product(upc: String!): Product
+		}
+		type Mutation { createUser(name: String!): CreateUserPayload }
+		type CreateUserPayload { user: User query: Query }
+		interface Node { id: ID! }
+		type User implements Node @owner(team: USERS) { id: ID! name: String pet: Pet }
+		interface Pet { name: String }
+		type Dog implements Pet { name: String barks: Boolean }
+		type Cat implements Pet { name: String lives: Int }
+		union SearchResult = User | Product
+		input SearchFilter { term: String limit: Int = 10 }
+		type Product implements Node @owner(team: PRODUCTS) { id: ID! upc: String! }
+	`
+
+	importSubset := func(t *testing.T, rootFields []RootFields) string {
+		from := unsafeparser.ParseGraphqlDocumentString(definition)
+		to := ast.NewDocument()
+		require.NoError(t, (&Importer{}).ImportSchemaSubset(&from, to, rootFields))
+		out, err := astprinter.PrintString(to, nil)
+		require.NoError(t, err)
+		return out
+	}
+
+	expected := func(t *testing.T, sdl string) string {
+		doc, report := astparser.ParseGraphqlDocumentString(sdl)
+		require.False(t, report.HasErrors(), report.Error())
+		out, err := astprinter.PrintString(&doc, nil)
+		require.NoError(t, err)
+		return out
+	}
+
+	t.Run("imports the definitions reachable from the root fields", func(t *testing.T) {
+		assert.Equal(t, expected(t, `
+			schema { query: Query }
+			directive @owner(team: Team!) on OBJECT | FIELD_DEFINITION
+			enum Team { USERS PRODUCTS }
+			type Query {
+				"the user by id"
+				user(id: ID!): User @owner(team: USERS)
+			}
+			interface Node { id: ID! }
+			type User implements Node @owner(team: USERS) { id: ID! name: String pet: Pet }
+			interface Pet { name: String }
+			type Dog implements Pet { name: String barks: Boolean }
+			type Cat implements Pet { name: String lives: Int }
+			type Product implements Node @owner(team: PRODUCTS) { id: ID! upc: String! }
+		`), importSubset(t, []RootFields{{TypeName: "Query", FieldNames: []string{"user"}}}))
+	})
+
+	t.Run("imports unions and input types", func(t *testing.T) {
+		assert.Equal(t, expected(t, `
+			schema { query: Query }
+			directive @owner(team: Team!) on OBJECT | FIELD_DEFINITION
+			enum Team { USERS PRODUCTS }
+			type Query { search(filter: SearchFilter): [SearchResult!]! }
+			interface Node { id: ID! }
+			type User implements Node @owner(team: USERS) { id: ID! name: String pet: Pet }
+			interface Pet { name: String }
+			type Dog implements Pet { name: String barks: Boolean }
+			type Cat implements Pet { name: String lives: Int }
+			union SearchResult = User | Product
+			input SearchFilter { term: String limit: Int = 10 }
+			type Product implements Node @owner(team: PRODUCTS) { id: ID! upc: String! }
+		`), importSubset(t, []RootFields{{TypeName: "Query", FieldNames: []string{"search"}}}))
+	})
+
+	t.Run("imports root types completely when they are reachable from other fields", func(t *testing.T) {
+		out := importSubset(t, []RootFields{{TypeName: "Mutation", FieldNames: []string{"createUser"}}})
+		assert.Contains(t, out, "schema {query: Query mutation: Mutation}")
+		assert.Contains(t, out, "type CreateUserPayload {user: User query: Query}")
+		assert.Contains(t, out, "product(upc: String!): Product")
+		assert.Contains(t, out, "input SearchFilter")
+	})
+
+	t.Run("imports definitions without schema definition", func(t *testing.T) {
+		from := unsafeparser.ParseGraphqlDocumentString(`type Query { hello: Greeting } type Greeting { text: String } type Unused { id: ID }`)
+		to := ast.NewDocument()
+		require.NoError(t, (&Importer{}).ImportSchemaSubset(&from, to, []RootFields{{TypeName: "Query", FieldNames: []string{"hello"}}}))
+		out, err := astprinter.PrintString(to, nil)
+		require.NoError(t, err)
+		assert.Equal(t, "type Query {hello: Greeting} type Greeting {text: String}", out)
+	})
+
+	t.Run("fails for unknown root fields", func(t *testing.T) {
+		from := unsafeparser.ParseGraphqlDocumentString(definition)
+		err := (&Importer{}).ImportSchemaSubset(&from, ast.NewDocument(), []RootFields{{TypeName: "User", FieldNames: []string{"id"}}})
+		assert.EqualError(t, err, "astimport: User is not a root operation type")
+
+		err = (&Importer{}).ImportSchemaSubset(&from, ast.NewDocument(), []RootFields{{TypeName: "Query", FieldNames: []string{"users"}}})
+		assert.EqualError(t, err, "astimport: field Query.users is not defined")
+	})
+}
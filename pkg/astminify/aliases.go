@@ -0,0 +1,129 @@
+package astminify
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/buger/jsonparser"
+)
+
+// RestoreAliases replaces the shortened aliases in the response of an operation minified WithShortAliases
+// with the original aliases, both in the data and in the paths of errors.
+// Object keys inside of custom scalars which equal a shortened alias are replaced as well.
+func RestoreAliases(response []byte, aliases map[string]string) ([]byte, error) {
+	if len(aliases) == 0 {
+		return response, nil
+	}
+
+	data, dataType, _, err := jsonparser.Get(response, "data")
+	if err == nil && dataType == jsonparser.Object {
+		buf := &bytes.Buffer{}
+		if err = restoreKeys(data, dataType, aliases, buf); err != nil {
+			return nil, err
+		}
+		if response, err = jsonparser.Set(response, buf.Bytes(), "data"); err != nil {
+			return nil, err
+		}
+	}
+
+	errors, errorsType, _, err := jsonparser.Get(response, "errors")
+	if err != nil || errorsType != jsonparser.Array {
+		return response, nil
+	}
+	restoredErrors := &bytes.Buffer{}
+	restoredErrors.WriteByte('[')
+	var restoreErr error
+	_, _ = jsonparser.ArrayEach(errors, func(graphqlError []byte, _ jsonparser.ValueType, _ int, _ error) {
+		if restoreErr != nil {
+			return
+		}
+		if restoredErrors.Len() > 1 {
+			restoredErrors.WriteByte(',')
+		}
+		graphqlError, restoreErr = restorePath(graphqlError, aliases)
+		restoredErrors.Write(graphqlError)
+	})
+	if restoreErr != nil {
+		return nil, restoreErr
+	}
+	restoredErrors.WriteByte(']')
+	return jsonparser.Set(response, restoredErrors.Bytes(), "errors")
+}
+
+func restorePath(graphqlError []byte, aliases map[string]string) ([]byte, error) {
+	path, pathType, _, err := jsonparser.Get(graphqlError, "path")
+	if err != nil || pathType != jsonparser.Array {
+		return graphqlError, nil
+	}
+	buf := &bytes.Buffer{}
+	buf.WriteByte('[')
+	_, err = jsonparser.ArrayEach(path, func(element []byte, elementType jsonparser.ValueType, _ int, _ error) {
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		if elementType != jsonparser.String {
+			buf.Write(element)
+			return
+		}
+		writeKey(element, aliases, buf)
+	})
+	if err != nil {
+		return nil, err
+	}
+	buf.WriteByte(']')
+	return jsonparser.Set(graphqlError, buf.Bytes(), "path")
+}
+
+func restoreKeys(value []byte, valueType jsonparser.ValueType, aliases map[string]string, buf *bytes.Buffer) (err error) {
+	switch valueType {
+	case jsonparser.Object:
+		buf.WriteByte('{')
+		first := true
+		err = jsonparser.ObjectEach(value, func(key []byte, fieldValue []byte, fieldType jsonparser.ValueType, _ int) error {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			writeKey(key, aliases, buf)
+			buf.WriteByte(':')
+			return restoreKeys(fieldValue, fieldType, aliases, buf)
+		})
+		buf.WriteByte('}')
+	case jsonparser.Array:
+		buf.WriteByte('[')
+		first := true
+		var itemErr error
+		_, err = jsonparser.ArrayEach(value, func(item []byte, itemType jsonparser.ValueType, _ int, _ error) {
+			if itemErr != nil {
+				return
+			}
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			itemErr = restoreKeys(item, itemType, aliases, buf)
+		})
+		if err == nil {
+			err = itemErr
+		}
+		buf.WriteByte(']')
+	case jsonparser.String:
+		buf.WriteByte('"')
+		buf.Write(value)
+		buf.WriteByte('"')
+	default:
+		buf.Write(value)
+	}
+	return err
+}
+
+// writeKey writes the escaped key as JSON string, replaced by the original alias if it's a shortened alias
+func writeKey(key []byte, aliases map[string]string, buf *bytes.Buffer) {
+	if alias, ok := aliases[string(key)]; ok {
+		buf.WriteString(strconv.Quote(alias))
+		return
+	}
+	buf.WriteByte('"')
+	buf.Write(key)
+	buf.WriteByte('"')
+}
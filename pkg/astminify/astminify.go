@@ -0,0 +1,393 @@
+// Package astminify minifies GraphQL operations, e.g. for persisted operations or to reduce the size of upstream requests.
+//
+// Minification keeps the semantics of the operation:
+//   - insignificant whitespace, commas and comments are removed
+//   - fragment definitions with identical type condition and selections are deduplicated
+//   - aliases which equal the field name are removed
+//   - inline fragments on the enclosing type are flattened if the schema is provided
+//
+// WithShortAliases additionally shortens aliases, which changes the response keys.
+// The returned alias map restores the original keys of a response with RestoreAliases.
+package astminify
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+	"github.com/wundergraph/graphql-go-tools/pkg/astvisitor"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+type options struct {
+	shortAliases bool
+}
+
+type Option func(options *options)
+
+// WithShortAliases replaces aliases with the shortest names not used as field name or alias in the operation.
+func WithShortAliases() Option {
+	return func(options *options) {
+		options.shortAliases = true
+	}
+}
+
+// Result is the minified operation.
+type Result struct {
+	Operation string
+	// Aliases maps the shortened aliases to the original aliases, it's empty without WithShortAliases
+	Aliases map[string]string
+}
+
+// Minify minifies the operation. The operation document is modified in place.
+// The definition is optional, it's required to flatten inline fragments on the enclosing type.
+func Minify(operation, definition *ast.Document, opts ...Option) (Result, error) {
+	var config options
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	if definition != nil {
+		if err := flattenInlineFragments(operation, definition); err != nil {
+			return Result{}, err
+		}
+	}
+
+	m := &minifier{operation: operation}
+	m.deduplicateFragments()
+	m.removeSelfAliases()
+
+	result := Result{
+		Aliases: map[string]string{},
+	}
+	if config.shortAliases {
+		result.Aliases = m.shortenAliases()
+	}
+
+	printed, err := astprinter.PrintString(operation, definition)
+	if err != nil {
+		return Result{}, err
+	}
+	result.Operation = string(minifyWhitespace([]byte(printed)))
+	return result, nil
+}
+
+type minifier struct {
+	operation *ast.Document
+}
+
+// walkFields calls fn for all fields of the printed root nodes
+func (m *minifier) walkFields(fn func(field int)) {
+	for _, rootNode := range m.operation.RootNodes {
+		switch rootNode.Kind {
+		case ast.NodeKindOperationDefinition:
+			if m.operation.OperationDefinitions[rootNode.Ref].HasSelections {
+				m.walkSelectionSetFields(m.operation.OperationDefinitions[rootNode.Ref].SelectionSet, fn)
+			}
+		case ast.NodeKindFragmentDefinition:
+			if m.operation.FragmentDefinitions[rootNode.Ref].HasSelections {
+				m.walkSelectionSetFields(m.operation.FragmentDefinitions[rootNode.Ref].SelectionSet, fn)
+			}
+		}
+	}
+}
+
+func (m *minifier) walkSelectionSetFields(set int, fn func(field int)) {
+	for _, selectionRef := range m.operation.SelectionSets[set].SelectionRefs {
+		selection := m.operation.Selections[selectionRef]
+		switch selection.Kind {
+		case ast.SelectionKindField:
+			fn(selection.Ref)
+			if m.operation.Fields[selection.Ref].HasSelections {
+				m.walkSelectionSetFields(m.operation.Fields[selection.Ref].SelectionSet, fn)
+			}
+		case ast.SelectionKindInlineFragment:
+			if m.operation.InlineFragments[selection.Ref].HasSelections {
+				m.walkSelectionSetFields(m.operation.InlineFragments[selection.Ref].SelectionSet, fn)
+			}
+		}
+	}
+}
+
+func (m *minifier) removeSelfAliases() {
+	m.walkFields(func(field int) {
+		if m.operation.FieldAliasIsDefined(field) && bytes.Equal(m.operation.FieldAliasBytes(field), m.operation.FieldNameBytes(field)) {
+			m.operation.RemoveFieldAlias(field)
+		}
+	})
+}
+
+// shortenAliases renames every alias consistently, so that fields sharing an alias are still merged,
+// and returns the map of the shortened to the original aliases
+func (m *minifier) shortenAliases() map[string]string {
+	reserved := map[string]struct{}{}
+	var aliases []string
+	m.walkFields(func(field int) {
+		reserved[m.operation.FieldNameString(field)] = struct{}{}
+		if !m.operation.FieldAliasIsDefined(field) {
+			return
+		}
+		alias := m.operation.FieldAliasString(field)
+		if _, ok := reserved[alias]; !ok {
+			aliases = append(aliases, alias)
+		}
+		reserved[alias] = struct{}{}
+	})
+
+	renamed := make(map[string]string, len(aliases))
+	restore := make(map[string]string, len(aliases))
+	next := 0
+	for _, alias := range aliases {
+		var short string
+		for {
+			short = shortName(next)
+			if _, ok := reserved[short]; !ok {
+				break
+			}
+			next++
+		}
+		if len(short) >= len(alias) {
+			continue
+		}
+		next++
+		renamed[alias] = short
+		restore[short] = alias
+	}
+
+	m.walkFields(func(field int) {
+		if !m.operation.FieldAliasIsDefined(field) {
+			return
+		}
+		if short, ok := renamed[m.operation.FieldAliasString(field)]; ok {
+			m.operation.Fields[field].Alias.Name = m.operation.Input.AppendInputString(short)
+		}
+	})
+
+	return restore
+}
+
+const (
+	nameStartChars    = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	nameContinueChars = nameStartChars + "0123456789_"
+)
+
+// shortName returns the i-th shortest GraphQL name: a, b, ..., Z, a0, a1, ...
+func shortName(i int) string {
+	if i < len(nameStartChars) {
+		return nameStartChars[i : i+1]
+	}
+	i -= len(nameStartChars)
+	return shortName(i/len(nameContinueChars)) + nameContinueChars[i%len(nameContinueChars):i%len(nameContinueChars)+1]
+}
+
+// deduplicateFragments removes fragment definitions with the same type condition, directives and selections
+// as a previous fragment definition and replaces their spreads.
+// It's repeated until no duplicates are left, because replacing spreads can make further fragments identical.
+func (m *minifier) deduplicateFragments() {
+	for {
+		canonical := map[string]int{}
+		replacements := map[string]int{}
+		rootNodes := m.operation.RootNodes[:0]
+		for _, rootNode := range m.operation.RootNodes {
+			if rootNode.Kind != ast.NodeKindFragmentDefinition {
+				rootNodes = append(rootNodes, rootNode)
+				continue
+			}
+			key := m.fragmentKey(rootNode.Ref)
+			if ref, ok := canonical[key]; ok {
+				replacements[m.operation.FragmentDefinitionNameString(rootNode.Ref)] = ref
+				continue
+			}
+			canonical[key] = rootNode.Ref
+			rootNodes = append(rootNodes, rootNode)
+		}
+		m.operation.RootNodes = rootNodes
+
+		if len(replacements) == 0 {
+			return
+		}
+		for i := range m.operation.FragmentSpreads {
+			if ref, ok := replacements[m.operation.FragmentSpreadNameString(i)]; ok {
+				m.operation.FragmentSpreads[i].FragmentName = m.operation.FragmentDefinitions[ref].Name
+			}
+		}
+	}
+}
+
+func (m *minifier) fragmentKey(ref int) string {
+	buf := &bytes.Buffer{}
+	fragment := m.operation.FragmentDefinitions[ref]
+	buf.Write(m.operation.FragmentDefinitionTypeName(ref))
+	m.writeDirectives(fragment.Directives.Refs, buf)
+	if fragment.HasSelections {
+		m.writeSelectionSet(fragment.SelectionSet, buf)
+	}
+	return buf.String()
+}
+
+func (m *minifier) writeSelectionSet(set int, buf *bytes.Buffer) {
+	buf.WriteByte('{')
+	for _, selectionRef := range m.operation.SelectionSets[set].SelectionRefs {
+		selection := m.operation.Selections[selectionRef]
+		switch selection.Kind {
+		case ast.SelectionKindField:
+			field := m.operation.Fields[selection.Ref]
+			if field.Alias.IsDefined {
+				buf.Write(m.operation.FieldAliasBytes(selection.Ref))
+				buf.WriteByte(':')
+			}
+			buf.Write(m.operation.FieldNameBytes(selection.Ref))
+			if field.HasArguments {
+				_ = m.operation.PrintArguments(field.Arguments.Refs, buf)
+			}
+			m.writeDirectives(field.Directives.Refs, buf)
+			if field.HasSelections {
+				m.writeSelectionSet(field.SelectionSet, buf)
+			}
+		case ast.SelectionKindInlineFragment:
+			inlineFragment := m.operation.InlineFragments[selection.Ref]
+			buf.WriteString("...")
+			if m.operation.InlineFragmentHasTypeCondition(selection.Ref) {
+				buf.WriteString("on ")
+				buf.Write(m.operation.InlineFragmentTypeConditionName(selection.Ref))
+			}
+			m.writeDirectives(inlineFragment.Directives.Refs, buf)
+			if inlineFragment.HasSelections {
+				m.writeSelectionSet(inlineFragment.SelectionSet, buf)
+			}
+		case ast.SelectionKindFragmentSpread:
+			buf.WriteString("...")
+			buf.Write(m.operation.FragmentSpreadNameBytes(selection.Ref))
+			m.writeDirectives(m.operation.FragmentSpreads[selection.Ref].Directives.Refs, buf)
+		}
+		buf.WriteByte(' ')
+	}
+	buf.WriteByte('}')
+}
+
+func (m *minifier) writeDirectives(refs []int, buf *bytes.Buffer) {
+	for _, ref := range refs {
+		_ = m.operation.PrintDirective(ref, buf)
+	}
+}
+
+// flattenInlineFragments replaces inline fragments without directives on the enclosing type,
+// or an interface it implements, with their selections
+func flattenInlineFragments(operation, definition *ast.Document) error {
+	walker := astvisitor.NewWalker(48)
+	visitor := &flattenInlineFragmentsVisitor{
+		Walker:     &walker,
+		operation:  operation,
+		definition: definition,
+	}
+	walker.RegisterEnterSelectionSetVisitor(visitor)
+
+	report := &operationreport.Report{}
+	walker.Walk(operation, definition, report)
+	if report.HasErrors() {
+		return fmt.Errorf("unable to minify operation: %w", report)
+	}
+	return nil
+}
+
+type flattenInlineFragmentsVisitor struct {
+	*astvisitor.Walker
+	operation, definition *ast.Document
+}
+
+func (f *flattenInlineFragmentsVisitor) EnterSelectionSet(ref int) {
+	for index, selection := range f.operation.SelectionSets[ref].SelectionRefs {
+		if f.operation.Selections[selection].Kind != ast.SelectionKindInlineFragment {
+			continue
+		}
+		inlineFragment := f.operation.Selections[selection].Ref
+		if !f.canFlatten(inlineFragment) {
+			continue
+		}
+		f.operation.ReplaceSelectionOnSelectionSet(ref, index, f.operation.InlineFragments[inlineFragment].SelectionSet)
+		f.RevisitNode()
+		return
+	}
+}
+
+func (f *flattenInlineFragmentsVisitor) canFlatten(inlineFragment int) bool {
+	if f.operation.InlineFragmentHasDirectives(inlineFragment) || !f.operation.InlineFragments[inlineFragment].HasSelections {
+		return false
+	}
+	if !f.operation.InlineFragmentHasTypeCondition(inlineFragment) {
+		return true
+	}
+	typeConditionName := f.operation.InlineFragmentTypeConditionName(inlineFragment)
+	enclosingTypeName := f.definition.NodeNameBytes(f.EnclosingTypeDefinition)
+	if bytes.Equal(typeConditionName, enclosingTypeName) {
+		return true
+	}
+	return f.definition.TypeDefinitionContainsImplementsInterface(enclosingTypeName, typeConditionName)
+}
+
+// minifyWhitespace removes all insignificant whitespace, commas and comments of a GraphQL document.
+// Whitespace is only kept between tokens which would otherwise be read as a single token.
+func minifyWhitespace(input []byte) []byte {
+	out := make([]byte, 0, len(input))
+	separated := false
+	for i := 0; i < len(input); i++ {
+		c := input[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			separated = true
+			continue
+		case c == '#':
+			for i < len(input) && input[i] != '\n' && input[i] != '\r' {
+				i++
+			}
+			separated = true
+			continue
+		}
+
+		if separated && len(out) != 0 && isNameByte(out[len(out)-1]) && (isNameByte(c) || c == '-' || c == '.' || c == '"') {
+			out = append(out, ' ')
+		}
+		separated = false
+
+		if c != '"' {
+			out = append(out, c)
+			continue
+		}
+
+		// strings are copied as is
+		end := stringEnd(input, i)
+		out = append(out, input[i:end]...)
+		i = end - 1
+	}
+	return out
+}
+
+// stringEnd returns the index after the string or block string starting at start
+func stringEnd(input []byte, start int) int {
+	if bytes.HasPrefix(input[start:], []byte(`"""`)) {
+		for i := start + 3; i < len(input); i++ {
+			if input[i] == '\\' && bytes.HasPrefix(input[i+1:], []byte(`"""`)) {
+				i += 3
+				continue
+			}
+			if bytes.HasPrefix(input[i:], []byte(`"""`)) {
+				return i + 3
+			}
+		}
+		return len(input)
+	}
+	for i := start + 1; i < len(input); i++ {
+		switch input[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return len(input)
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,148 @@
+package astminify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astnormalization"
+	"github.com/wundergraph/graphql-go-tools/pkg/asttransform"
+	"github.com/wundergraph/graphql-go-tools/pkg/astvalidation"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+const testDefinition = `
+	schema { query: Query }
+
+	type Query {
+		user(id: ID!): User
+		node(id: ID!): Node
+		search(text: String!, limit: Int): [User]
+	}
+
+	interface Node {
+		id: ID!
+	}
+
+	type User implements Node {
+		id: ID!
+		name: String
+		friends: [User]
+		avatar(size: Int): String
+	}
+`
+
+func TestMinify(t *testing.T) {
+	run := func(t *testing.T, operation string, expected string, expectedAliases map[string]string, opts ...Option) {
+		t.Helper()
+		definition := unsafeparser.ParseGraphqlDocumentString(testDefinition)
+		require.NoError(t, asttransform.MergeDefinitionWithBaseSchema(&definition))
+		operationDocument := unsafeparser.ParseGraphqlDocumentString(operation)
+
+		result, err := Minify(&operationDocument, &definition, opts...)
+		require.NoError(t, err)
+		assert.Equal(t, expected, result.Operation)
+		assert.Equal(t, expectedAliases, result.Aliases)
+
+		minified := unsafeparser.ParseGraphqlDocumentString(result.Operation)
+		report := operationreport.Report{}
+		astnormalization.NormalizeOperation(&minified, &definition, &report)
+		astvalidation.DefaultOperationValidator().Validate(&minified, &definition, &report)
+		assert.False(t, report.HasErrors(), report.Error())
+	}
+
+	t.Run("whitespace, commas and comments", func(t *testing.T) {
+		run(t, `
+			# fetches a user
+			query User($id: ID!, $size: Int = 10) {
+				user(id: $id) {
+					id,
+					name
+					avatar(size: $size) @include(if: true)
+					friends { ... on User { id } }
+				}
+				search(text: "a \"quoted\", text", limit: -1) { id }
+			}`,
+			`query User($id:ID!$size:Int=10){user(id:$id){id name avatar(size:$size)@include(if:true)friends{id}}search(text:"a \"quoted\", text"limit:-1){id}}`,
+			map[string]string{},
+		)
+	})
+
+	t.Run("deduplicates fragments", func(t *testing.T) {
+		run(t, `
+			query {
+				user(id: 1) { ...UserFields friends { ...FriendFields } }
+				node(id: 2) { ... on User { ...NestedUserFields } ...NestedFriendFields }
+			}
+			fragment UserFields on User { id name avatar(size: 1) }
+			fragment FriendFields on User { id name avatar(size: 1) }
+			fragment NestedUserFields on User { ...UserFields }
+			fragment NestedFriendFields on User { ...FriendFields }`,
+			`{user(id:1){...UserFields friends{...UserFields}}node(id:2){...on User{...NestedUserFields}...NestedUserFields}}fragment UserFields on User{id name avatar(size:1)}fragment NestedUserFields on User{...UserFields}`,
+			map[string]string{},
+		)
+	})
+
+	t.Run("removes self aliases and flattens inline fragments on the enclosing type", func(t *testing.T) {
+		run(t, `
+			query {
+				user(id: 1) {
+					id: id
+					... on User { name }
+					... on Node { id }
+					... @skip(if: false) { friends { id } }
+				}
+			}`,
+			`{user(id:1){id name id ...@skip(if:false){friends{id}}}}`,
+			map[string]string{},
+		)
+	})
+
+	t.Run("shortens aliases", func(t *testing.T) {
+		run(t, `
+			query {
+				firstUser: user(id: 1) { ...UserFields }
+				secondUser: user(id: 2) { ...UserFields b: id }
+				x: search(text: "x") { id }
+			}
+			fragment UserFields on User { smallAvatar: avatar(size: 1) largeAvatar: avatar(size: 2) name }`,
+			`{a:user(id:1){...UserFields}c:user(id:2){...UserFields b:id}x:search(text:"x"){id}}fragment UserFields on User{d:avatar(size:1)e:avatar(size:2)name}`,
+			map[string]string{"a": "firstUser", "c": "secondUser", "d": "smallAvatar", "e": "largeAvatar"},
+			WithShortAliases(),
+		)
+	})
+}
+
+func TestMinify_WithoutDefinition(t *testing.T) {
+	operation := unsafeparser.ParseGraphqlDocumentString(`query { user(id: 1) { ... on User { id } } }`)
+	result, err := Minify(&operation, nil)
+	require.NoError(t, err)
+	assert.Equal(t, `{user(id:1){...on User{id}}}`, result.Operation)
+}
+
+func TestMinifyWhitespace(t *testing.T) {
+	assert.Equal(t, `{a(b:"""block "" \""" string"""c:1.5e3 d:[1 -2 3])}`, string(minifyWhitespace([]byte(`{ a(b: """block "" \""" string""", c: 1.5e3, d: [1, -2, 3]) }`))))
+	assert.Equal(t, `query($a:[Int!]!@dir){...F ...on T{a}}`, string(minifyWhitespace([]byte("query ( $a : [ Int! ]! @dir ) {\n ...F # comment\n ... on T { a } }"))))
+}
+
+func TestShortName(t *testing.T) {
+	assert.Equal(t, "a", shortName(0))
+	assert.Equal(t, "Z", shortName(51))
+	assert.Equal(t, "aa", shortName(52))
+	assert.Equal(t, "a_", shortName(52+62))
+	assert.Equal(t, "ba", shortName(52+63))
+}
+
+func TestRestoreAliases(t *testing.T) {
+	aliases := map[string]string{"a": "firstUser", "c": "secondUser"}
+
+	restored, err := RestoreAliases([]byte(`{"errors":[{"message":"failed","path":["c",0,"name"]},{"message":"no path"}],"data":{"a":{"id":"1","name":"a \"quoted\" name","friends":[{"id":"2"}]},"c":null}}`), aliases)
+	require.NoError(t, err)
+	assert.Equal(t, `{"errors":[{"message":"failed","path":["secondUser",0,"name"]},{"message":"no path"}],"data":{"firstUser":{"id":"1","name":"a \"quoted\" name","friends":[{"id":"2"}]},"secondUser":null}}`, string(restored))
+
+	restored, err = RestoreAliases([]byte(`{"data":{"a":1}}`), map[string]string{})
+	require.NoError(t, err)
+	assert.Equal(t, `{"data":{"a":1}}`, string(restored))
+}
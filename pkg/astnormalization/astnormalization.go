@@ -129,6 +129,7 @@ type options struct {
 	extractVariables          bool
 	removeUnusedVariables     bool
 	normalizeDefinition       bool
+	fragmentRegistry          *FragmentRegistry
 }
 
 type Option func(options *options)
@@ -157,6 +158,14 @@ func WithNormalizeDefinition() Option {
 	}
 }
 
+// WithFragmentRegistry injects the definitions of the registered fragments spread by an operation
+// which the operation doesn't define itself before the operation is normalized
+func WithFragmentRegistry(registry *FragmentRegistry) Option {
+	return func(options *options) {
+		options.fragmentRegistry = registry
+	}
+}
+
 func (o *OperationNormalizer) setupOperationWalkers() {
 	o.operationWalkers = make([]*astvisitor.Walker, 0, 4)
 
@@ -201,8 +210,24 @@ func (o *OperationNormalizer) prepareDefinition(definition *ast.Document, report
 	}
 }
 
+func (o *OperationNormalizer) injectRegisteredFragments(operation *ast.Document) {
+	if o.options.fragmentRegistry != nil {
+		o.options.fragmentRegistry.injectFragments(operation)
+	}
+}
+
+// Reset resets the state of the last normalization, e.g. the operation name of NormalizeNamedOperation.
+// The walkers and their buffers are kept, so that normalizing operations with a reused normalizer doesn't allocate.
+// It's called by the normalize methods, so a normalizer can be reused for any number of operations, e.g. from a sync.Pool.
+func (o *OperationNormalizer) Reset() {
+	if o.variablesExtraction != nil {
+		o.variablesExtraction.operationName = nil
+	}
+}
+
 // NormalizeOperation applies all registered rules to the AST
 func (o *OperationNormalizer) NormalizeOperation(operation, definition *ast.Document, report *operationreport.Report) {
+	o.Reset()
 	if o.options.normalizeDefinition {
 		o.prepareDefinition(definition, report)
 		if report.HasErrors() {
@@ -210,6 +235,13 @@ func (o *OperationNormalizer) NormalizeOperation(operation, definition *ast.Docu
 		}
 	}
 
+	o.injectRegisteredFragments(operation)
+
+	inlineFragmentArguments(operation, report)
+	if report.HasErrors() {
+		return
+	}
+
 	for i := range o.operationWalkers {
 		o.operationWalkers[i].Walk(operation, definition, report)
 		if report.HasErrors() {
@@ -220,6 +252,7 @@ func (o *OperationNormalizer) NormalizeOperation(operation, definition *ast.Docu
 
 // NormalizeNamedOperation applies all registered rules to one specific named operation in the AST
 func (o *OperationNormalizer) NormalizeNamedOperation(operation, definition *ast.Document, operationName []byte, report *operationreport.Report) {
+	o.Reset()
 	if o.options.normalizeDefinition {
 		o.prepareDefinition(definition, report)
 		if report.HasErrors() {
@@ -227,6 +260,13 @@ func (o *OperationNormalizer) NormalizeNamedOperation(operation, definition *ast
 		}
 	}
 
+	o.injectRegisteredFragments(operation)
+
+	inlineFragmentArguments(operation, report)
+	if report.HasErrors() {
+		return
+	}
+
 	if o.variablesExtraction != nil {
 		o.variablesExtraction.operationName = operationName
 	}
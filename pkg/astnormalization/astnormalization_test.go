@@ -1,7 +1,9 @@
 package astnormalization
 
 import (
+	"flag"
 	"fmt"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -9,6 +11,8 @@ import (
 
 	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeparser"
 	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeprinter"
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
 	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
 	"github.com/wundergraph/graphql-go-tools/pkg/asttransform"
 	"github.com/wundergraph/graphql-go-tools/pkg/astvisitor"
@@ -288,6 +292,51 @@ schema {
 	})
 }
 
+func TestOperationNormalizer_Reset(t *testing.T) {
+	definition := unsafeparser.ParseGraphqlDocumentString(`type Query { dog(name: String): Dog } type Dog { name: String }`)
+	require.NoError(t, asttransform.MergeDefinitionWithBaseSchema(&definition))
+	normalizer := NewNormalizer(true, true)
+
+	normalize := func(input, operationName string) string {
+		operation := unsafeparser.ParseGraphqlDocumentString(input)
+		report := operationreport.Report{}
+		if operationName == "" {
+			normalizer.NormalizeOperation(&operation, &definition, &report)
+		} else {
+			normalizer.NormalizeNamedOperation(&operation, &definition, []byte(operationName), &report)
+		}
+		require.False(t, report.HasErrors(), report.Error())
+		return string(operation.Input.Variables)
+	}
+
+	assert.Equal(t, `{"a":"Rex"}`, normalize(`query A { dog(name: "Lassie") { name } } query B { dog(name: "Rex") { name } }`, "B"))
+	// the operation name of the previous normalization doesn't apply anymore
+	assert.Equal(t, `{"a":"Lassie"}`, normalize(`query A { dog(name: "Lassie") { name } }`, ""))
+}
+
+func TestOperationNormalizer_Allocations(t *testing.T) {
+	definition := unsafeparser.ParseGraphqlDocumentString(testDefinition)
+	require.NoError(t, asttransform.MergeDefinitionWithBaseSchema(&definition))
+	operation := ast.NewDocument()
+	parser := astparser.NewParser()
+	report := operationreport.Report{}
+	normalizer := NewWithOpts(WithExtractVariables(), WithRemoveFragmentDefinitions(), WithRemoveUnusedVariables())
+
+	normalize := func() {
+		operation.Reset()
+		operation.Input.ResetInputString(testOperation)
+		report.Reset()
+		parser.Parse(operation, &report)
+		normalizer.NormalizeOperation(operation, &definition, &report)
+	}
+	// the first normalizations grow the buffers of the document and the normalizer
+	normalize()
+	require.False(t, report.HasErrors(), report.Error())
+	normalize()
+
+	assert.Equal(t, float64(0), testing.AllocsPerRun(100, normalize), "normalizing with a reused normalizer and document must not allocate")
+}
+
 func TestNewNormalizer(t *testing.T) {
 	schema := `
 scalar String
@@ -351,6 +400,62 @@ func BenchmarkAstNormalization(b *testing.B) {
 	}
 }
 
+var (
+	benchmarkSchema    = flag.String("normalization.schema", "", "path of the schema normalized operations are benchmarked against")
+	benchmarkOperation = flag.String("normalization.operation", "", "path of the operation to benchmark the normalization of")
+)
+
+// BenchmarkOperationNormalizer benchmarks the normalization of an operation parsed again for each iteration.
+// Run it with your schema and operation to validate the throughput at your schema size:
+//
+//	go test ./pkg/astnormalization -run xxx -bench BenchmarkOperationNormalizer -args -normalization.schema=schema.graphql -normalization.operation=operation.graphql
+func BenchmarkOperationNormalizer(b *testing.B) {
+	schema, operationInput := testDefinition, testOperation
+	if *benchmarkSchema != "" {
+		content, err := os.ReadFile(*benchmarkSchema)
+		require.NoError(b, err)
+		schema = string(content)
+	}
+	if *benchmarkOperation != "" {
+		content, err := os.ReadFile(*benchmarkOperation)
+		require.NoError(b, err)
+		operationInput = string(content)
+	}
+
+	definition := unsafeparser.ParseGraphqlDocumentString(schema)
+	require.NoError(b, asttransform.MergeDefinitionWithBaseSchema(&definition))
+
+	benchmark := func(b *testing.B, normalizer *OperationNormalizer) {
+		operation := ast.NewDocument()
+		parser := astparser.NewParser()
+		report := operationreport.Report{}
+
+		b.ReportAllocs()
+		b.SetBytes(int64(len(operationInput)))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			operation.Reset()
+			operation.Input.ResetInputString(operationInput)
+			report.Reset()
+			parser.Parse(operation, &report)
+			b.StartTimer()
+
+			normalizer.NormalizeOperation(operation, &definition, &report)
+			if report.HasErrors() {
+				b.Fatal(report.Error())
+			}
+		}
+	}
+
+	b.Run("default", func(b *testing.B) {
+		benchmark(b, NewNormalizer(false, false))
+	})
+	b.Run("extract variables", func(b *testing.B) {
+		benchmark(b, NewWithOpts(WithExtractVariables(), WithRemoveFragmentDefinitions(), WithRemoveUnusedVariables()))
+	})
+}
+
 var mustString = func(str string, err error) string {
 	if err != nil {
 		panic(err)
@@ -35,4 +35,20 @@ func TestDeDuplicateFields(t *testing.T) {
 						doesKnowCommand(dogCommand: 0)
 					}`)
 	})
+	t.Run("self aliased field", func(t *testing.T) {
+		run(deduplicateFields, testDefinition, `
+					query selfAliased {
+						dog {
+							name
+							name: name
+							nickname: name
+						}
+					}`, `
+					query selfAliased {
+						dog {
+							name
+							nickname: name
+						}
+					}`)
+	})
 }
@@ -27,10 +27,15 @@ func (f *fieldSelectionMergeVisitor) EnterDocument(operation, definition *ast.Do
 func (f *fieldSelectionMergeVisitor) fieldsCanMerge(left, right int) bool {
 	leftName := f.operation.FieldNameBytes(left)
 	rightName := f.operation.FieldNameBytes(right)
-	leftAlias := f.operation.FieldAliasBytes(left)
-	rightAlias := f.operation.FieldAliasBytes(right)
+	leftResponseKey := f.operation.FieldAliasOrNameBytes(left)
+	rightResponseKey := f.operation.FieldAliasOrNameBytes(right)
 
-	if !bytes.Equal(leftName, rightName) || !bytes.Equal(leftAlias, rightAlias) {
+	// fields are merged by their response key, e.g. `me` and `me: me` would otherwise both be written to "me"
+	if !bytes.Equal(leftName, rightName) || !bytes.Equal(leftResponseKey, rightResponseKey) {
+		return false
+	}
+
+	if f.operation.Fields[left].Nullability != f.operation.Fields[right].Nullability {
 		return false
 	}
 
@@ -55,7 +60,7 @@ func (f *fieldSelectionMergeVisitor) removeSelection(set, i int) {
 func (f *fieldSelectionMergeVisitor) mergeFields(left, right int) {
 	leftSet := f.operation.Fields[left].SelectionSet
 	rightSet := f.operation.Fields[right].SelectionSet
-	f.operation.SelectionSets[leftSet].SelectionRefs = append(f.operation.SelectionSets[leftSet].SelectionRefs, f.operation.SelectionSets[rightSet].SelectionRefs...)
+	f.operation.AppendSelectionSet(leftSet, rightSet)
 	f.operation.Fields[left].Directives.Refs = append(f.operation.Fields[left].Directives.Refs, f.operation.Fields[right].Directives.Refs...)
 }
 
@@ -79,4 +79,23 @@ func TestMergeFieldSelections(t *testing.T) {
 						}
 					}`)
 	})
+	t.Run("self aliased field merges with non aliased field", func(t *testing.T) {
+		run(mergeFieldSelections, testDefinition, `
+					query selfAliased {
+						dog {
+							extra { string }
+							extra: extra { noString: string }
+							other: extra { bool }
+						}
+					}`, `
+					query selfAliased {
+						dog {
+							extra {
+								string
+								noString: string
+							}
+							other: extra { bool }
+						}
+					}`)
+	})
 }
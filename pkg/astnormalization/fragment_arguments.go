@@ -0,0 +1,242 @@
+package astnormalization
+
+import (
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+// inlineFragmentArguments replaces the spreads of fragments with arguments, see astparser.Parser.EnableFragmentArguments,
+// with inline fragments on the type condition of the fragment, containing a copy of the selections of the fragment
+// in which the fragment variables are substituted with the arguments of the spread or their default values.
+// Fragment variables which are neither passed nor have a default value are unset, so arguments set to them are removed.
+// It's applied before the fragment spreads are inlined, so that the other rules never see fragment arguments.
+func inlineFragmentArguments(operation *ast.Document, report *operationreport.Report) {
+	if !hasFragmentArguments(operation) {
+		return
+	}
+
+	inliner := fragmentArgumentsInliner{
+		operation: operation,
+		report:    report,
+	}
+	for i := range operation.OperationDefinitions {
+		if operation.OperationDefinitions[i].HasSelections {
+			inliner.inlineSelectionSet(operation.OperationDefinitions[i].SelectionSet, nil)
+		}
+	}
+	for i := range operation.FragmentDefinitions {
+		if operation.FragmentDefinitions[i].HasSelections && !operation.FragmentDefinitions[i].HasVariableDefinitions {
+			inliner.inlineSelectionSet(operation.FragmentDefinitions[i].SelectionSet, []string{operation.FragmentDefinitionNameString(i)})
+		}
+	}
+}
+
+func hasFragmentArguments(operation *ast.Document) bool {
+	for i := range operation.FragmentDefinitions {
+		if operation.FragmentDefinitions[i].HasVariableDefinitions {
+			return true
+		}
+	}
+	for i := range operation.FragmentSpreads {
+		if operation.FragmentSpreads[i].HasArguments {
+			return true
+		}
+	}
+	return false
+}
+
+type fragmentArgumentsInliner struct {
+	operation *ast.Document
+	report    *operationreport.Report
+}
+
+// fragmentVariable is the value of a fragment variable within an inlined fragment, unset variables have no value
+type fragmentVariable struct {
+	value ast.Value
+	isSet bool
+}
+
+// inlineSelectionSet inlines the spreads of fragments with arguments, the path contains the inlined fragments to detect cycles
+func (f *fragmentArgumentsInliner) inlineSelectionSet(set int, path []string) {
+	for _, selectionRef := range f.operation.SelectionSets[set].SelectionRefs {
+		if f.report.HasErrors() {
+			return
+		}
+		selection := f.operation.Selections[selectionRef]
+		switch selection.Kind {
+		case ast.SelectionKindField:
+			if f.operation.Fields[selection.Ref].HasSelections {
+				f.inlineSelectionSet(f.operation.Fields[selection.Ref].SelectionSet, path)
+			}
+		case ast.SelectionKindInlineFragment:
+			if f.operation.InlineFragments[selection.Ref].HasSelections {
+				f.inlineSelectionSet(f.operation.InlineFragments[selection.Ref].SelectionSet, path)
+			}
+		case ast.SelectionKindFragmentSpread:
+			fragmentName := f.operation.FragmentSpreadNameString(selection.Ref)
+			fragmentRef, exists := f.operation.FragmentDefinitionRef(f.operation.FragmentSpreadNameBytes(selection.Ref))
+			if !exists {
+				// reported when the fragment spreads are inlined
+				continue
+			}
+			if !f.operation.FragmentDefinitions[fragmentRef].HasVariableDefinitions && !f.operation.FragmentSpreads[selection.Ref].HasArguments {
+				continue
+			}
+			for i := range path {
+				if path[i] == fragmentName {
+					f.report.AddExternalError(operationreport.ErrFragmentSpreadFormsCycle(f.operation.FragmentSpreadNameBytes(selection.Ref)))
+					return
+				}
+			}
+			inlineFragment, ok := f.inlineFragmentSpread(selection.Ref, fragmentRef)
+			if !ok {
+				return
+			}
+			f.operation.Selections[selectionRef] = ast.Selection{
+				Kind: ast.SelectionKindInlineFragment,
+				Ref:  inlineFragment,
+			}
+			if f.operation.InlineFragments[inlineFragment].HasSelections {
+				f.inlineSelectionSet(f.operation.InlineFragments[inlineFragment].SelectionSet, append(path[:len(path):len(path)], fragmentName))
+			}
+		}
+	}
+}
+
+func (f *fragmentArgumentsInliner) inlineFragmentSpread(spread, fragment int) (inlineFragment int, ok bool) {
+	variables, ok := f.fragmentVariables(spread, fragment)
+	if !ok {
+		return ast.InvalidRef, false
+	}
+
+	definition := f.operation.FragmentDefinitions[fragment]
+	inline := ast.InlineFragment{
+		TypeCondition: definition.TypeCondition,
+		HasDirectives: f.operation.FragmentSpreads[spread].HasDirectives,
+		Directives:    f.operation.FragmentSpreads[spread].Directives,
+		HasSelections: definition.HasSelections,
+		SelectionSet:  ast.InvalidRef,
+	}
+	if definition.HasSelections {
+		inline.SelectionSet = f.operation.CopySelectionSet(definition.SelectionSet)
+		f.substituteSelectionSet(inline.SelectionSet, variables)
+	}
+	return f.operation.AddInlineFragment(inline), true
+}
+
+// fragmentVariables validates the arguments of the spread against the variable definitions of the fragment
+// and returns the values of the fragment variables
+func (f *fragmentArgumentsInliner) fragmentVariables(spread, fragment int) (map[string]fragmentVariable, bool) {
+	fragmentName := f.operation.FragmentDefinitionNameBytes(fragment)
+	variables := make(map[string]fragmentVariable, len(f.operation.FragmentDefinitions[fragment].VariableDefinitions.Refs))
+	for _, ref := range f.operation.FragmentDefinitions[fragment].VariableDefinitions.Refs {
+		variable := fragmentVariable{}
+		if f.operation.VariableDefinitions[ref].DefaultValue.IsDefined {
+			variable.value = f.operation.VariableDefinitions[ref].DefaultValue.Value
+			variable.isSet = true
+		}
+		variables[f.operation.VariableDefinitionNameString(ref)] = variable
+	}
+
+	passed := make(map[string]struct{}, len(f.operation.FragmentSpreads[spread].Arguments.Refs))
+	for _, ref := range f.operation.FragmentSpreads[spread].Arguments.Refs {
+		argumentName := f.operation.ArgumentNameString(ref)
+		if _, ok := passed[argumentName]; ok {
+			f.report.AddExternalError(operationreport.ErrArgumentMustBeUnique(f.operation.ArgumentNameBytes(ref)))
+			return nil, false
+		}
+		passed[argumentName] = struct{}{}
+		if _, ok := variables[argumentName]; !ok {
+			f.report.AddExternalError(operationreport.ErrFragmentArgumentNotDefined(f.operation.ArgumentNameBytes(ref), fragmentName))
+			return nil, false
+		}
+		variables[argumentName] = fragmentVariable{
+			value: f.operation.Arguments[ref].Value,
+			isSet: true,
+		}
+	}
+
+	for _, ref := range f.operation.FragmentDefinitions[fragment].VariableDefinitions.Refs {
+		variableName := f.operation.VariableDefinitionNameString(ref)
+		if !variables[variableName].isSet && f.operation.Types[f.operation.VariableDefinitions[ref].Type].TypeKind == ast.TypeKindNonNull {
+			f.report.AddExternalError(operationreport.ErrFragmentArgumentRequired(f.operation.VariableDefinitionNameBytes(ref), fragmentName))
+			return nil, false
+		}
+	}
+	return variables, true
+}
+
+func (f *fragmentArgumentsInliner) substituteSelectionSet(set int, variables map[string]fragmentVariable) {
+	for _, selectionRef := range f.operation.SelectionSets[set].SelectionRefs {
+		selection := f.operation.Selections[selectionRef]
+		switch selection.Kind {
+		case ast.SelectionKindField:
+			field := &f.operation.Fields[selection.Ref]
+			field.Arguments.Refs = f.substituteArguments(field.Arguments.Refs, variables)
+			field.HasArguments = len(field.Arguments.Refs) != 0
+			f.substituteDirectives(field.Directives.Refs, variables)
+			if field.HasSelections {
+				f.substituteSelectionSet(field.SelectionSet, variables)
+			}
+		case ast.SelectionKindInlineFragment:
+			f.substituteDirectives(f.operation.InlineFragments[selection.Ref].Directives.Refs, variables)
+			if f.operation.InlineFragments[selection.Ref].HasSelections {
+				f.substituteSelectionSet(f.operation.InlineFragments[selection.Ref].SelectionSet, variables)
+			}
+		case ast.SelectionKindFragmentSpread:
+			spread := &f.operation.FragmentSpreads[selection.Ref]
+			spread.Arguments.Refs = f.substituteArguments(spread.Arguments.Refs, variables)
+			spread.HasArguments = len(spread.Arguments.Refs) != 0
+			f.substituteDirectives(spread.Directives.Refs, variables)
+		}
+	}
+}
+
+func (f *fragmentArgumentsInliner) substituteDirectives(refs []int, variables map[string]fragmentVariable) {
+	for _, ref := range refs {
+		directive := &f.operation.Directives[ref]
+		directive.Arguments.Refs = f.substituteArguments(directive.Arguments.Refs, variables)
+		directive.HasArguments = len(directive.Arguments.Refs) != 0
+	}
+}
+
+// substituteArguments substitutes the fragment variables in the values of the arguments
+// and removes the arguments set to unset fragment variables
+func (f *fragmentArgumentsInliner) substituteArguments(refs []int, variables map[string]fragmentVariable) []int {
+	substituted := refs[:0]
+	for _, ref := range refs {
+		value := f.operation.Arguments[ref].Value
+		if value.Kind == ast.ValueKindVariable {
+			if variable, ok := variables[f.operation.VariableValueNameString(value.Ref)]; ok && !variable.isSet {
+				continue
+			}
+		}
+		f.operation.Arguments[ref].Value = f.substituteValue(value, variables)
+		substituted = append(substituted, ref)
+	}
+	return substituted
+}
+
+func (f *fragmentArgumentsInliner) substituteValue(value ast.Value, variables map[string]fragmentVariable) ast.Value {
+	switch value.Kind {
+	case ast.ValueKindVariable:
+		variable, ok := variables[f.operation.VariableValueNameString(value.Ref)]
+		if !ok {
+			// an operation variable
+			return value
+		}
+		if !variable.isSet {
+			return ast.Value{Kind: ast.ValueKindNull, Ref: ast.InvalidRef}
+		}
+		return f.operation.Values[f.operation.CopyValue(f.operation.AddValue(variable.value))]
+	case ast.ValueKindList:
+		for _, ref := range f.operation.ListValues[value.Ref].Refs {
+			f.operation.Values[ref] = f.substituteValue(f.operation.Values[ref], variables)
+		}
+	case ast.ValueKindObject:
+		for _, ref := range f.operation.ObjectValues[value.Ref].Refs {
+			f.operation.ObjectFields[ref].Value = f.substituteValue(f.operation.ObjectFields[ref].Value, variables)
+		}
+	}
+	return value
+}
@@ -0,0 +1,155 @@
+package astnormalization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+	"github.com/wundergraph/graphql-go-tools/pkg/asttransform"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+const fragmentArgumentsDefinition = `
+	type Query {
+		user(id: ID!): User
+	}
+	type User {
+		id: ID!
+		profilePic(size: Int): String
+		friends(first: Int, filter: FriendsFilter): [User]
+	}
+	input FriendsFilter {
+		name: String
+	}
+	schema {
+		query: Query
+	}
+`
+
+func TestInlineFragmentArguments(t *testing.T) {
+	parseOperation := func(t *testing.T, operation string) ast.Document {
+		t.Helper()
+		operationDocument := ast.NewDocument()
+		operationDocument.Input.ResetInputString(operation)
+		parser := astparser.NewParser()
+		parser.EnableFragmentArguments()
+		report := operationreport.Report{}
+		parser.Parse(operationDocument, &report)
+		require.False(t, report.HasErrors(), report.Error())
+		return *operationDocument
+	}
+
+	normalize := func(t *testing.T, operation string) (ast.Document, ast.Document, operationreport.Report) {
+		t.Helper()
+		definitionDocument := unsafeparser.ParseGraphqlDocumentString(fragmentArgumentsDefinition)
+		require.NoError(t, asttransform.MergeDefinitionWithBaseSchema(&definitionDocument))
+
+		operationDocument := parseOperation(t, operation)
+		report := operationreport.Report{}
+		normalizer := NewWithOpts(WithRemoveFragmentDefinitions())
+		normalizer.NormalizeOperation(&operationDocument, &definitionDocument, &report)
+		return operationDocument, definitionDocument, report
+	}
+
+	run := func(t *testing.T, operation, expectedOutput string) {
+		t.Helper()
+		operationDocument, definitionDocument, report := normalize(t, operation)
+		require.False(t, report.HasErrors(), report.Error())
+
+		expectedOutputDocument := unsafeparser.ParseGraphqlDocumentString(expectedOutput)
+		got := mustString(astprinter.PrintString(&operationDocument, &definitionDocument))
+		want := mustString(astprinter.PrintString(&expectedOutputDocument, &definitionDocument))
+		assert.Equal(t, want, got)
+	}
+
+	runWithError := func(t *testing.T, operation, expectedError string) {
+		t.Helper()
+		_, _, report := normalize(t, operation)
+		assert.EqualError(t, report, expectedError)
+	}
+
+	t.Run("parses and prints fragment arguments", func(t *testing.T) {
+		operation := `{user(id: "1"){...picture(size: 100)}} fragment picture($size: Int = 50) on User {profilePic(size: $size)}`
+		operationDocument := parseOperation(t, operation)
+		assert.Equal(t, operation, mustString(astprinter.PrintString(&operationDocument, nil)))
+	})
+
+	t.Run("substitutes the arguments and default values", func(t *testing.T) {
+		run(t, `
+			query {
+				user(id: "1") { ...picture(size: 100) friends { ...picture } }
+			}
+			fragment picture($size: Int = 50) on User { profilePic(size: $size) }`, `
+			query {
+				user(id: "1") { profilePic(size: 100) friends { profilePic(size: 50) } }
+			}`)
+	})
+
+	t.Run("removes arguments of unset variables", func(t *testing.T) {
+		run(t, `
+			query {
+				user(id: "1") { ...friends }
+			}
+			fragment friends($first: Int, $name: String) on User { friends(first: $first, filter: {name: $name}) { id } }`, `
+			query {
+				user(id: "1") { friends(filter: {name: null}) { id } }
+			}`)
+	})
+
+	t.Run("passes arguments to nested fragments and keeps operation variables", func(t *testing.T) {
+		run(t, `
+			query User($size: Int) {
+				user(id: "1") { ...outer(size: $size) }
+			}
+			fragment outer($size: Int) on User { ...picture(size: $size) friends { ...plain } }
+			fragment picture($size: Int = 50) on User { profilePic(size: $size) }
+			fragment plain on User { ...picture(size: 10) }`, `
+			query User($size: Int) {
+				user(id: "1") { profilePic(size: $size) friends { profilePic(size: 10) } }
+			}`)
+	})
+
+	t.Run("spreads of the same fragment with different arguments", func(t *testing.T) {
+		run(t, `
+			query {
+				user(id: "1") { ...picture(size: 10) friends { ...picture(size: 20) } }
+			}
+			fragment picture($size: Int) on User { profilePic(size: $size) }`, `
+			query {
+				user(id: "1") { profilePic(size: 10) friends { profilePic(size: 20) } }
+			}`)
+	})
+
+	t.Run("undefined argument", func(t *testing.T) {
+		runWithError(t, `
+			query { user(id: "1") { ...picture(width: 10) } }
+			fragment picture($size: Int) on User { profilePic(size: $size) }`,
+			"external: argument: width is not defined on fragment: picture, locations: [], path: []")
+	})
+
+	t.Run("missing required argument", func(t *testing.T) {
+		runWithError(t, `
+			query { user(id: "1") { ...picture } }
+			fragment picture($size: Int!) on User { profilePic(size: $size) }`,
+			"external: argument: size is required on fragment: picture but missing, locations: [], path: []")
+	})
+
+	t.Run("duplicate argument", func(t *testing.T) {
+		runWithError(t, `
+			query { user(id: "1") { ...picture(size: 1, size: 2) } }
+			fragment picture($size: Int) on User { profilePic(size: $size) }`,
+			"external: argument: size must be unique, locations: [], path: []")
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		runWithError(t, `
+			query { user(id: "1") { ...picture(size: 1) } }
+			fragment picture($size: Int) on User { profilePic(size: $size) friends { ...picture(size: $size) } }`,
+			"external: fragment spread: picture forms fragment cycle, locations: [], path: []")
+	})
+}
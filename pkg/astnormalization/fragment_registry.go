@@ -0,0 +1,179 @@
+package astnormalization
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astimport"
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+var ErrFragmentRegistryOnlyAcceptsFragments = errors.New("fragment registry only accepts fragment definitions")
+
+// FragmentRegistry is a server-side library of named fragments.
+// Operations can spread registered fragments without sending their definitions,
+// the normalizer injects the definitions of all registered fragments spread by an operation,
+// including the registered fragments they spread themselves.
+// Fragments defined by the operation take precedence over registered fragments with the same name.
+type FragmentRegistry struct {
+	mu        sync.RWMutex
+	fragments map[string]registeredFragment
+}
+
+type registeredFragment struct {
+	document *ast.Document
+	ref      int
+	spreads  []string
+}
+
+func NewFragmentRegistry() *FragmentRegistry {
+	return &FragmentRegistry{
+		fragments: map[string]registeredFragment{},
+	}
+}
+
+// Register parses the fragment definitions and adds them to the registry,
+// an already registered fragment with the same name is replaced.
+// Fragment arguments and client controlled nullability designators are allowed in the definitions.
+func (r *FragmentRegistry) Register(fragments string) error {
+	document := ast.NewDocument()
+	document.Input.ResetInputString(fragments)
+	report := operationreport.Report{}
+	parser := astparser.NewParser()
+	parser.EnableFragmentArguments()
+	parser.EnableClientControlledNullability()
+	parser.Parse(document, &report)
+	if report.HasErrors() {
+		return report
+	}
+
+	parsed := make(map[string]registeredFragment, len(document.FragmentDefinitions))
+	for _, node := range document.RootNodes {
+		if node.Kind != ast.NodeKindFragmentDefinition {
+			return ErrFragmentRegistryOnlyAcceptsFragments
+		}
+		name := document.FragmentDefinitionNameString(node.Ref)
+		if _, exists := parsed[name]; exists {
+			return fmt.Errorf("fragment %s is defined more than once", name)
+		}
+		var spreads []string
+		fragment := document.FragmentDefinitions[node.Ref]
+		if fragment.HasSelections {
+			spreads = selectionSetFragmentSpreadNames(document, fragment.SelectionSet, spreads)
+		}
+		parsed[name] = registeredFragment{
+			document: document,
+			ref:      node.Ref,
+			spreads:  spreads,
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, fragment := range parsed {
+		r.fragments[name] = fragment
+	}
+	return nil
+}
+
+// Unregister removes the fragments from the registry
+func (r *FragmentRegistry) Unregister(names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range names {
+		delete(r.fragments, name)
+	}
+}
+
+// Fragments returns the names of all registered fragments in alphabetical order
+func (r *FragmentRegistry) Fragments() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.fragments))
+	for name := range r.fragments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// missingFragments returns the registered fragments which are spread but not defined in the operation
+func (r *FragmentRegistry) missingFragments(operation *ast.Document) []registeredFragment {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var (
+		missing []registeredFragment
+		visited = map[string]struct{}{}
+		queue   = fragmentSpreadNames(operation)
+	)
+	for len(queue) != 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if _, ok := visited[name]; ok {
+			continue
+		}
+		visited[name] = struct{}{}
+		if _, defined := operation.FragmentDefinitionRef([]byte(name)); defined {
+			continue
+		}
+		fragment, ok := r.fragments[name]
+		if !ok {
+			// unknown fragments are reported by the validation
+			continue
+		}
+		missing = append(missing, fragment)
+		queue = append(queue, fragment.spreads...)
+	}
+	return missing
+}
+
+// injectFragments imports the definitions of the registered fragments spread by the operation into the operation.
+// Registered documents are never modified after Register, so they can be read without holding the lock.
+func (r *FragmentRegistry) injectFragments(operation *ast.Document) {
+	importer := astimport.Importer{}
+	for _, fragment := range r.missingFragments(operation) {
+		importer.ImportFragmentDefinition(fragment.ref, fragment.document, operation)
+	}
+}
+
+// fragmentSpreadNames returns the distinct names of all fragment spreads of the document
+func fragmentSpreadNames(document *ast.Document) []string {
+	var names []string
+	for i := range document.FragmentSpreads {
+		names = appendDistinct(names, document.FragmentSpreadNameString(i))
+	}
+	return names
+}
+
+func selectionSetFragmentSpreadNames(document *ast.Document, selectionSet int, names []string) []string {
+	for _, selectionRef := range document.SelectionSets[selectionSet].SelectionRefs {
+		selection := document.Selections[selectionRef]
+		switch selection.Kind {
+		case ast.SelectionKindField:
+			if document.Fields[selection.Ref].HasSelections {
+				names = selectionSetFragmentSpreadNames(document, document.Fields[selection.Ref].SelectionSet, names)
+			}
+		case ast.SelectionKindInlineFragment:
+			if document.InlineFragments[selection.Ref].HasSelections {
+				names = selectionSetFragmentSpreadNames(document, document.InlineFragments[selection.Ref].SelectionSet, names)
+			}
+		case ast.SelectionKindFragmentSpread:
+			names = appendDistinct(names, document.FragmentSpreadNameString(selection.Ref))
+		}
+	}
+	return names
+}
+
+func appendDistinct(names []string, name string) []string {
+	for i := range names {
+		if names[i] == name {
+			return names
+		}
+	}
+	return append(names, name)
+}
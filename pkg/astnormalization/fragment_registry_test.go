@@ -0,0 +1,148 @@
+package astnormalization
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+	"github.com/wundergraph/graphql-go-tools/pkg/asttransform"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+const fragmentRegistryDefinition = `
+	type Query {
+		user(id: ID!): User
+	}
+	type User {
+		id: ID!
+		name: String
+		friends: [User]
+	}
+	schema {
+		query: Query
+	}
+`
+
+func TestFragmentRegistry(t *testing.T) {
+	newRegistry := func(t *testing.T) *FragmentRegistry {
+		t.Helper()
+		registry := NewFragmentRegistry()
+		require.NoError(t, registry.Register(`
+			fragment UserFields on User { id name }
+			fragment UserWithFriends on User { ...UserFields friends { ...UserFields } }
+		`))
+		return registry
+	}
+
+	run := func(t *testing.T, registry *FragmentRegistry, operation, expectedOutput string) {
+		t.Helper()
+
+		definitionDocument := unsafeparser.ParseGraphqlDocumentString(fragmentRegistryDefinition)
+		require.NoError(t, asttransform.MergeDefinitionWithBaseSchema(&definitionDocument))
+
+		operationDocument := unsafeparser.ParseGraphqlDocumentString(operation)
+		operationDocument.Input.Variables = []byte(`{"id":"1"}`)
+		expectedOutputDocument := unsafeparser.ParseGraphqlDocumentString(expectedOutput)
+		report := operationreport.Report{}
+
+		normalizer := NewWithOpts(
+			WithExtractVariables(),
+			WithRemoveFragmentDefinitions(),
+			WithRemoveUnusedVariables(),
+			WithFragmentRegistry(registry),
+		)
+		normalizer.NormalizeOperation(&operationDocument, &definitionDocument, &report)
+		require.False(t, report.HasErrors(), report.Error())
+
+		got := mustString(astprinter.PrintString(&operationDocument, &definitionDocument))
+		want := mustString(astprinter.PrintString(&expectedOutputDocument, &definitionDocument))
+		assert.Equal(t, want, got)
+		assert.Equal(t, `{"id":"1"}`, string(operationDocument.Input.Variables), "variables are kept when fragments are injected")
+	}
+
+	t.Run("injects registered fragments and the fragments they spread", func(t *testing.T) {
+		run(t, newRegistry(t), `
+			query User($id: ID!) {
+				user(id: $id) { ...UserWithFriends }
+			}`, `
+			query User($id: ID!) {
+				user(id: $id) { id name friends { id name } }
+			}`)
+	})
+
+	t.Run("fragments of the operation take precedence", func(t *testing.T) {
+		run(t, newRegistry(t), `
+			query User($id: ID!) {
+				user(id: $id) { ...UserWithFriends }
+			}
+			fragment UserFields on User { id }`, `
+			query User($id: ID!) {
+				user(id: $id) { id friends { id } }
+			}`)
+	})
+
+	t.Run("unknown fragments are reported", func(t *testing.T) {
+		definitionDocument := unsafeparser.ParseGraphqlDocumentString(fragmentRegistryDefinition)
+		require.NoError(t, asttransform.MergeDefinitionWithBaseSchema(&definitionDocument))
+		operationDocument := unsafeparser.ParseGraphqlDocumentString(`{ user(id: "1") { ...Unknown } }`)
+		report := operationreport.Report{}
+
+		NewWithOpts(WithFragmentRegistry(newRegistry(t))).NormalizeOperation(&operationDocument, &definitionDocument, &report)
+		assert.True(t, report.HasErrors())
+	})
+
+	t.Run("injects registered fragments into operations with fragment arguments and nullability designators", func(t *testing.T) {
+		registry := newRegistry(t)
+		require.NoError(t, registry.Register(`
+			fragment UserCard($withFriends: Boolean = false) on User { name! friends @include(if: $withFriends) { ...UserFields } }
+		`))
+
+		definitionDocument := unsafeparser.ParseGraphqlDocumentString(fragmentRegistryDefinition)
+		require.NoError(t, asttransform.MergeDefinitionWithBaseSchema(&definitionDocument))
+
+		operationDocument := ast.NewDocument()
+		operationDocument.Input.ResetInputString(`
+			query User($id: ID!) {
+				user(id: $id)? { id! ...UserCard(withFriends: true) }
+			}`)
+		report := operationreport.Report{}
+		parser := astparser.NewParser()
+		parser.EnableFragmentArguments()
+		parser.EnableClientControlledNullability()
+		parser.Parse(operationDocument, &report)
+		require.False(t, report.HasErrors(), report.Error())
+
+		normalizer := NewWithOpts(
+			WithRemoveFragmentDefinitions(),
+			WithFragmentRegistry(registry),
+		)
+		normalizer.NormalizeOperation(operationDocument, &definitionDocument, &report)
+		require.False(t, report.HasErrors(), report.Error())
+
+		got := mustString(astprinter.PrintString(operationDocument, &definitionDocument))
+		assert.Equal(t, `query User($id: ID!){user(id: $id)?{id! name! friends {id name}}}`, got)
+	})
+
+	t.Run("register", func(t *testing.T) {
+		registry := newRegistry(t)
+		assert.Equal(t, []string{"UserFields", "UserWithFriends"}, registry.Fragments())
+
+		require.NoError(t, registry.Register(`fragment UserFields on User { id }`))
+		fragment := registry.fragments["UserFields"]
+		printed := *fragment.document
+		printed.RootNodes = []ast.Node{{Kind: ast.NodeKindFragmentDefinition, Ref: fragment.ref}}
+		assert.Equal(t, "fragment UserFields on User {id}", mustString(astprinter.PrintString(&printed, nil)))
+
+		assert.ErrorIs(t, registry.Register(`query { user(id: "1") { id } }`), ErrFragmentRegistryOnlyAcceptsFragments)
+		assert.EqualError(t, registry.Register(`fragment A on User { id } fragment A on User { name }`), "fragment A is defined more than once")
+		assert.Error(t, registry.Register(`fragment A on User {`))
+
+		registry.Unregister("UserWithFriends")
+		assert.Equal(t, []string{"UserFields"}, registry.Fragments())
+	})
+}
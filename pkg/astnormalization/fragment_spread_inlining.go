@@ -55,7 +55,8 @@ func (f *fragmentSpreadInlineVisitor) EnterFragmentSpread(ref int) {
 	fragmentTypeName := f.operation.FragmentDefinitionTypeName(fragmentDefinitionRef)
 	fragmentNode, exists := f.definition.NodeByName(fragmentTypeName)
 	if !exists {
-		f.StopWithExternalErr(operationreport.ErrTypeUndefined(fragmentTypeName))
+		f.StopWithExternalErr(operationreport.ErrTypeUndefined(fragmentTypeName).
+			WithSuggestions(string(fragmentTypeName), f.definition.TypeDefinitionNames()))
 		return
 	}
 
@@ -1,6 +1,8 @@
 package astnormalization
 
 import (
+	"bytes"
+
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
 	"github.com/wundergraph/graphql-go-tools/pkg/astvisitor"
 )
@@ -17,6 +19,7 @@ extend type Mutation {...}
 extend type Subscription {...}
 
 this also works if root types are defined in schema{...} with other names.
+in that case, a type named e.g. Query is only extended if the query root type is not defined with another name.
 root types are left unmodified if they have no fields, directives or implements any interface.
 */
 type implicitExtendRootOperationVisitor struct {
@@ -38,17 +41,27 @@ func (v *implicitExtendRootOperationVisitor) EnterObjectTypeDefinition(ref int)
 	if !(node.HasFieldDefinitions || node.HasDirectives) {
 		return
 	}
-	switch v.operation.ObjectTypeDefinitionNameString(ref) {
-	case implicitQueryTypeName, implicitMutationTypeName, implicitSubscriptionTypeName,
-		v.operation.Index.QueryTypeName.String(), v.operation.Index.MutationTypeName.String(), v.operation.Index.SubscriptionTypeName.String():
-		for i := range v.operation.RootNodes {
-			if v.operation.RootNodes[i].Ref == ref && v.operation.RootNodes[i].Kind == ast.NodeKindObjectTypeDefinition {
-				// give this node a new NodeKind of ObjectTypeExtension
-				newRef := v.operation.AddObjectTypeDefinitionExtension(ast.ObjectTypeExtension{ObjectTypeDefinition: node})
-				// reflect changes inside the root nodes
-				v.operation.UpdateRootNode(i, newRef, ast.NodeKindObjectTypeExtension)
-				break
-			}
+	if !v.isRootOperationTypeName(v.operation.ObjectTypeDefinitionNameBytes(ref)) {
+		return
+	}
+	for i := range v.operation.RootNodes {
+		if v.operation.RootNodes[i].Ref == ref && v.operation.RootNodes[i].Kind == ast.NodeKindObjectTypeDefinition {
+			// give this node a new NodeKind of ObjectTypeExtension
+			newRef := v.operation.AddObjectTypeDefinitionExtension(ast.ObjectTypeExtension{ObjectTypeDefinition: node})
+			// reflect changes inside the root nodes
+			v.operation.UpdateRootNode(i, newRef, ast.NodeKindObjectTypeExtension)
+			break
 		}
 	}
 }
+
+func (v *implicitExtendRootOperationVisitor) isRootOperationTypeName(typeName ast.ByteSlice) bool {
+	queryTypeName, mutationTypeName, subscriptionTypeName := v.operation.Index.RootOperationTypeNames()
+	switch {
+	case bytes.Equal(typeName, queryTypeName),
+		bytes.Equal(typeName, mutationTypeName),
+		bytes.Equal(typeName, subscriptionTypeName):
+		return true
+	}
+	return false
+}
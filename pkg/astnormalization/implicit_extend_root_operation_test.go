@@ -63,5 +63,21 @@ func TestImplicitExtendRootOperation(t *testing.T) {
 		extend type Subscription @directive { newUser: ID! }
 	`, registerNormalizeFunc(implicitExtendRootOperation))
 	})
-
+	t.Run("don't implicitly extend types with default names when root operation uses a custom name", func(_ *testing.T) {
+		runManyOnDefinition(`
+		schema {
+			query: QueryName
+		}
+		type QueryName {
+			query: Query
+		}
+		type Query {
+			field: String!
+		}
+		`, `
+		schema { query: QueryName }
+		extend type QueryName { query: Query }
+		type Query { field: String! }
+	`, registerNormalizeFunc(implicitExtendRootOperation))
+	})
 }
@@ -45,6 +45,8 @@ type Parser struct {
 	tokenizer            *Tokenizer
 	shouldIndex          bool
 	reportInternalErrors bool
+	fragmentArguments    bool
+	clientNullability    bool
 }
 
 // NewParser returns a new parser with all values properly initialized
@@ -56,6 +58,28 @@ func NewParser() *Parser {
 	}
 }
 
+// EnableFragmentArguments enables the experimental parsing of fragment arguments,
+// i.e. variable definitions of fragment definitions and arguments of fragment spreads:
+//
+//	fragment picture($size: Int = 50) on User { profilePic(size: $size) }
+//	{ user { ...picture(size: 100) } }
+//
+// The arguments are substituted when the fragments are inlined on normalization.
+func (p *Parser) EnableFragmentArguments() {
+	p.fragmentArguments = true
+}
+
+// EnableClientControlledNullability enables the experimental parsing of client controlled nullability designators,
+// as proposed in https://github.com/graphql/graphql-spec/issues/867, after the name and arguments of fields:
+//
+//	{ user(id: 1)! { name? } }
+//
+// A field marked with ! is non-null, a field marked with ? is nullable, regardless of the nullability in the schema.
+// List item designators, e.g. [!], are not supported.
+func (p *Parser) EnableClientControlledNullability() {
+	p.clientNullability = true
+}
+
 // PrepareImport prepares the Parser for importing new Nodes into an AST without directly parsing the content
 func (p *Parser) PrepareImport(document *ast.Document, report *operationreport.Report) {
 	p.document = document
@@ -1379,6 +1403,16 @@ func (p *Parser) parseField() int {
 		field.Arguments = p.parseArgumentList()
 		field.HasArguments = len(field.Arguments.Refs) > 0
 	}
+	if p.clientNullability {
+		switch p.peek() {
+		case keyword.BANG:
+			p.read()
+			field.Nullability = ast.NullabilityRequired
+		case keyword.QUESTIONMARK:
+			p.read()
+			field.Nullability = ast.NullabilityOptional
+		}
+	}
 	if p.peekEquals(keyword.AT) {
 		field.Directives = p.parseDirectiveList()
 		field.HasDirectives = len(field.Directives.Refs) > 0
@@ -1395,6 +1429,10 @@ func (p *Parser) parseFragmentSpread(spread position.Position) int {
 	var fragmentSpread ast.FragmentSpread
 	fragmentSpread.Spread = spread
 	fragmentSpread.FragmentName = p.mustReadExceptIdentKey(identkeyword.ON).Literal
+	if p.fragmentArguments && p.peekEquals(keyword.LPAREN) {
+		fragmentSpread.Arguments = p.parseArgumentList()
+		fragmentSpread.HasArguments = len(fragmentSpread.Arguments.Refs) > 0
+	}
 	if p.peekEquals(keyword.AT) {
 		fragmentSpread.Directives = p.parseDirectiveList()
 		fragmentSpread.HasDirectives = len(fragmentSpread.Directives.Refs) > 0
@@ -1555,6 +1593,10 @@ func (p *Parser) parseFragmentDefinition() {
 	var fragmentDefinition ast.FragmentDefinition
 	fragmentDefinition.FragmentLiteral = p.mustReadIdentKey(identkeyword.FRAGMENT).TextPosition
 	fragmentDefinition.Name = p.mustRead(keyword.IDENT).Literal
+	if p.fragmentArguments && p.peekEquals(keyword.LPAREN) {
+		fragmentDefinition.VariableDefinitions = p.parseVariableDefinitionList()
+		fragmentDefinition.HasVariableDefinitions = len(fragmentDefinition.VariableDefinitions.Refs) > 0
+	}
 	fragmentDefinition.TypeCondition = p.parseTypeCondition()
 	if p.peekEquals(keyword.AT) {
 		fragmentDefinition.Directives = p.parseDirectiveList()
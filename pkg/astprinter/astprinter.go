@@ -43,7 +43,10 @@ func PrintStringIndent(document, definition *ast.Document, indent string) (strin
 
 // Printer walks a GraphQL document and prints it as a string
 type Printer struct {
-	indent     []byte
+	indent []byte
+	// chunkSize enables printing in chunks of root nodes, see Stream
+	chunkSize  int
+	chunks     chunkWriter
 	visitor    printVisitor
 	walker     astvisitor.SimpleWalker
 	registered bool
@@ -56,11 +59,23 @@ func (p *Printer) Print(document, definition *ast.Document, out io.Writer) error
 	p.visitor.err = nil
 	p.visitor.document = document
 	p.visitor.out = out
+	p.visitor.chunks = nil
+	if p.chunkSize > 0 {
+		p.chunks.reset(out, p.chunkSize)
+		p.visitor.out = &p.chunks
+		p.visitor.chunks = &p.chunks
+	}
 	p.visitor.SimpleWalker = &p.walker
 	if !p.registered {
 		p.walker.SetVisitor(&p.visitor)
 	}
-	return p.walker.Walk(p.visitor.document, definition)
+	if err := p.walker.Walk(p.visitor.document, definition); err != nil {
+		return err
+	}
+	if p.visitor.chunks != nil {
+		p.visitor.must(p.chunks.flush())
+	}
+	return p.visitor.err
 }
 
 type printVisitor struct {
@@ -68,6 +83,8 @@ type printVisitor struct {
 	document *ast.Document
 	out      io.Writer
 	err      error
+	// chunks is set when printing in chunks, out is the chunk writer then
+	chunks *chunkWriter
 
 	indent                     []byte
 	inputValueDefinitionOpener []byte
@@ -82,6 +99,21 @@ func (p *printVisitor) write(data []byte) {
 	_, p.err = p.out.Write(data)
 }
 
+// leaveRootNode separates the root node from the next one and ends the chunk in case the chunk size is reached
+func (p *printVisitor) leaveRootNode(node ast.Node) {
+	if !p.document.NodeIsLastRootNode(node) {
+		if p.indent != nil {
+			p.write(literal.LINETERMINATOR)
+			p.write(literal.LINETERMINATOR)
+		} else {
+			p.write(literal.SPACE)
+		}
+	}
+	if p.chunks != nil {
+		p.must(p.chunks.endRootNode())
+	}
+}
+
 func (p *printVisitor) indentationDepth() (depth int) {
 
 	if len(p.Ancestors) == 0 {
@@ -224,6 +256,9 @@ func (p *printVisitor) EnterArgument(ref int) {
 func (p *printVisitor) LeaveArgument(ref int) {
 	if len(p.document.ArgumentsAfter(p.Ancestors[len(p.Ancestors)-1], ref)) == 0 {
 		p.write(literal.RPAREN)
+		if p.Ancestors[len(p.Ancestors)-1].Kind == ast.NodeKindField {
+			p.writeFieldNullability(p.Ancestors[len(p.Ancestors)-1].Ref)
+		}
 	}
 }
 
@@ -256,14 +291,7 @@ func (p *printVisitor) EnterOperationDefinition(ref int) {
 }
 
 func (p *printVisitor) LeaveOperationDefinition(ref int) {
-	if !p.document.NodeIsLastRootNode(ast.Node{Kind: ast.NodeKindOperationDefinition, Ref: ref}) {
-		if p.indent != nil {
-			p.write(literal.LINETERMINATOR)
-			p.write(literal.LINETERMINATOR)
-		} else {
-			p.write(literal.SPACE)
-		}
-	}
+	p.leaveRootNode(ast.Node{Kind: ast.NodeKindOperationDefinition, Ref: ref})
 }
 
 func (p *printVisitor) EnterSelectionSet(ref int) {
@@ -289,11 +317,24 @@ func (p *printVisitor) EnterField(ref int) {
 	} else {
 		p.writeIndented(p.document.Input.ByteSlice(p.document.Fields[ref].Name))
 	}
+	if !p.document.FieldHasArguments(ref) {
+		p.writeFieldNullability(ref)
+	}
 	if !p.document.FieldHasArguments(ref) && (p.document.FieldHasSelections(ref) || p.document.FieldHasDirectives(ref)) {
 		p.write(literal.SPACE)
 	}
 }
 
+// writeFieldNullability writes the client controlled nullability designator of the field, if any
+func (p *printVisitor) writeFieldNullability(ref int) {
+	switch p.document.Fields[ref].Nullability {
+	case ast.NullabilityRequired:
+		p.write(literal.BANG)
+	case ast.NullabilityOptional:
+		p.write(literal.QUESTIONMARK)
+	}
+}
+
 func (p *printVisitor) LeaveField(ref int) {
 	if !p.document.FieldHasDirectives(ref) && len(p.SelectionsAfter) != 0 {
 		if p.indent != nil {
@@ -307,6 +348,9 @@ func (p *printVisitor) LeaveField(ref int) {
 func (p *printVisitor) EnterFragmentSpread(ref int) {
 	p.writeIndented(literal.SPREAD)
 	p.write(p.document.Input.ByteSlice(p.document.FragmentSpreads[ref].FragmentName))
+	if p.document.FragmentSpreads[ref].HasArguments {
+		p.printFragmentArguments(p.document.FragmentSpreads[ref].Arguments.Refs)
+	}
 }
 
 func (p *printVisitor) LeaveFragmentSpread(ref int) {
@@ -349,6 +393,9 @@ func (p *printVisitor) EnterFragmentDefinition(ref int) {
 	p.write(literal.FRAGMENT)
 	p.write(literal.SPACE)
 	p.write(p.document.Input.ByteSlice(p.document.FragmentDefinitions[ref].Name))
+	if p.document.FragmentDefinitions[ref].HasVariableDefinitions {
+		p.printFragmentVariableDefinitions(p.document.FragmentDefinitions[ref].VariableDefinitions.Refs)
+	}
 	p.write(literal.SPACE)
 	p.write(literal.ON)
 	p.write(literal.SPACE)
@@ -357,15 +404,42 @@ func (p *printVisitor) EnterFragmentDefinition(ref int) {
 
 }
 
-func (p *printVisitor) LeaveFragmentDefinition(ref int) {
-	if !p.document.NodeIsLastRootNode(ast.Node{Kind: ast.NodeKindFragmentDefinition, Ref: ref}) {
-		if p.indent != nil {
-			p.write(literal.LINETERMINATOR)
-			p.write(literal.LINETERMINATOR)
-		} else {
+// printFragmentVariableDefinitions prints the experimental fragment arguments, which aren't walked
+func (p *printVisitor) printFragmentVariableDefinitions(refs []int) {
+	p.write(literal.LPAREN)
+	for i, ref := range refs {
+		if i != 0 {
+			p.write(literal.COMMA)
 			p.write(literal.SPACE)
 		}
+		p.must(p.document.PrintValue(p.document.VariableDefinitions[ref].VariableValue, p.out))
+		p.write(literal.COLON)
+		p.write(literal.SPACE)
+		p.must(p.document.PrintType(p.document.VariableDefinitions[ref].Type, p.out))
+		if p.document.VariableDefinitions[ref].DefaultValue.IsDefined {
+			p.write(literal.SPACE)
+			p.write(literal.EQUALS)
+			p.write(literal.SPACE)
+			p.must(p.document.PrintValue(p.document.VariableDefinitions[ref].DefaultValue.Value, p.out))
+		}
 	}
+	p.write(literal.RPAREN)
+}
+
+func (p *printVisitor) printFragmentArguments(refs []int) {
+	p.write(literal.LPAREN)
+	for i, ref := range refs {
+		if i != 0 {
+			p.write(literal.COMMA)
+			p.write(literal.SPACE)
+		}
+		p.must(p.document.PrintArgument(ref, p.out))
+	}
+	p.write(literal.RPAREN)
+}
+
+func (p *printVisitor) LeaveFragmentDefinition(ref int) {
+	p.leaveRootNode(ast.Node{Kind: ast.NodeKindFragmentDefinition, Ref: ref})
 }
 
 func (p *printVisitor) EnterObjectTypeDefinition(ref int) {
@@ -399,14 +473,7 @@ func (p *printVisitor) EnterObjectTypeDefinition(ref int) {
 }
 
 func (p *printVisitor) LeaveObjectTypeDefinition(ref int) {
-	if !p.document.NodeIsLastRootNode(ast.Node{Kind: ast.NodeKindObjectTypeDefinition, Ref: ref}) {
-		if p.indent != nil {
-			p.write(literal.LINETERMINATOR)
-			p.write(literal.LINETERMINATOR)
-		} else {
-			p.write(literal.SPACE)
-		}
-	}
+	p.leaveRootNode(ast.Node{Kind: ast.NodeKindObjectTypeDefinition, Ref: ref})
 }
 
 func (p *printVisitor) EnterObjectTypeExtension(ref int) {
@@ -428,14 +495,7 @@ func (p *printVisitor) EnterObjectTypeExtension(ref int) {
 }
 
 func (p *printVisitor) LeaveObjectTypeExtension(ref int) {
-	if !p.document.NodeIsLastRootNode(ast.Node{Kind: ast.NodeKindObjectTypeExtension, Ref: ref}) {
-		if p.indent != nil {
-			p.write(literal.LINETERMINATOR)
-			p.write(literal.LINETERMINATOR)
-		} else {
-			p.write(literal.SPACE)
-		}
-	}
+	p.leaveRootNode(ast.Node{Kind: ast.NodeKindObjectTypeExtension, Ref: ref})
 }
 
 func (p *printVisitor) EnterFieldDefinition(ref int) {
@@ -542,14 +602,7 @@ func (p *printVisitor) EnterInterfaceTypeDefinition(ref int) {
 }
 
 func (p *printVisitor) LeaveInterfaceTypeDefinition(ref int) {
-	if !p.document.NodeIsLastRootNode(ast.Node{Kind: ast.NodeKindInterfaceTypeDefinition, Ref: ref}) {
-		if p.indent != nil {
-			p.write(literal.LINETERMINATOR)
-			p.write(literal.LINETERMINATOR)
-		} else {
-			p.write(literal.SPACE)
-		}
-	}
+	p.leaveRootNode(ast.Node{Kind: ast.NodeKindInterfaceTypeDefinition, Ref: ref})
 }
 
 func (p *printVisitor) EnterInterfaceTypeExtension(ref int) {
@@ -571,14 +624,7 @@ func (p *printVisitor) EnterInterfaceTypeExtension(ref int) {
 }
 
 func (p *printVisitor) LeaveInterfaceTypeExtension(ref int) {
-	if !p.document.NodeIsLastRootNode(ast.Node{Kind: ast.NodeKindInterfaceTypeExtension, Ref: ref}) {
-		if p.indent != nil {
-			p.write(literal.LINETERMINATOR)
-			p.write(literal.LINETERMINATOR)
-		} else {
-			p.write(literal.SPACE)
-		}
-	}
+	p.leaveRootNode(ast.Node{Kind: ast.NodeKindInterfaceTypeExtension, Ref: ref})
 }
 
 func (p *printVisitor) EnterScalarTypeDefinition(ref int) {
@@ -597,14 +643,7 @@ func (p *printVisitor) EnterScalarTypeDefinition(ref int) {
 }
 
 func (p *printVisitor) LeaveScalarTypeDefinition(ref int) {
-	if !p.document.NodeIsLastRootNode(ast.Node{Kind: ast.NodeKindScalarTypeDefinition, Ref: ref}) {
-		if p.indent != nil {
-			p.write(literal.LINETERMINATOR)
-			p.write(literal.LINETERMINATOR)
-		} else {
-			p.write(literal.SPACE)
-		}
-	}
+	p.leaveRootNode(ast.Node{Kind: ast.NodeKindScalarTypeDefinition, Ref: ref})
 }
 
 func (p *printVisitor) EnterScalarTypeExtension(ref int) {
@@ -625,14 +664,7 @@ func (p *printVisitor) EnterScalarTypeExtension(ref int) {
 }
 
 func (p *printVisitor) LeaveScalarTypeExtension(ref int) {
-	if !p.document.NodeIsLastRootNode(ast.Node{Kind: ast.NodeKindScalarTypeExtension, Ref: ref}) {
-		if p.indent != nil {
-			p.write(literal.LINETERMINATOR)
-			p.write(literal.LINETERMINATOR)
-		} else {
-			p.write(literal.SPACE)
-		}
-	}
+	p.leaveRootNode(ast.Node{Kind: ast.NodeKindScalarTypeExtension, Ref: ref})
 }
 
 func (p *printVisitor) EnterUnionTypeDefinition(ref int) {
@@ -651,14 +683,7 @@ func (p *printVisitor) EnterUnionTypeDefinition(ref int) {
 }
 
 func (p *printVisitor) LeaveUnionTypeDefinition(ref int) {
-	if !p.document.NodeIsLastRootNode(ast.Node{Kind: ast.NodeKindUnionTypeDefinition, Ref: ref}) {
-		if p.indent != nil {
-			p.write(literal.LINETERMINATOR)
-			p.write(literal.LINETERMINATOR)
-		} else {
-			p.write(literal.SPACE)
-		}
-	}
+	p.leaveRootNode(ast.Node{Kind: ast.NodeKindUnionTypeDefinition, Ref: ref})
 }
 
 func (p *printVisitor) EnterUnionTypeExtension(ref int) {
@@ -679,14 +704,7 @@ func (p *printVisitor) EnterUnionTypeExtension(ref int) {
 }
 
 func (p *printVisitor) LeaveUnionTypeExtension(ref int) {
-	if !p.document.NodeIsLastRootNode(ast.Node{Kind: ast.NodeKindUnionTypeExtension, Ref: ref}) {
-		if p.indent != nil {
-			p.write(literal.LINETERMINATOR)
-			p.write(literal.LINETERMINATOR)
-		} else {
-			p.write(literal.SPACE)
-		}
-	}
+	p.leaveRootNode(ast.Node{Kind: ast.NodeKindUnionTypeExtension, Ref: ref})
 }
 
 func (p *printVisitor) EnterUnionMemberType(ref int) {
@@ -723,14 +741,7 @@ func (p *printVisitor) EnterEnumTypeDefinition(ref int) {
 }
 
 func (p *printVisitor) LeaveEnumTypeDefinition(ref int) {
-	if !p.document.NodeIsLastRootNode(ast.Node{Kind: ast.NodeKindEnumTypeDefinition, Ref: ref}) {
-		if p.indent != nil {
-			p.write(literal.LINETERMINATOR)
-			p.write(literal.LINETERMINATOR)
-		} else {
-			p.write(literal.SPACE)
-		}
-	}
+	p.leaveRootNode(ast.Node{Kind: ast.NodeKindEnumTypeDefinition, Ref: ref})
 }
 
 func (p *printVisitor) EnterEnumTypeExtension(ref int) {
@@ -751,14 +762,7 @@ func (p *printVisitor) EnterEnumTypeExtension(ref int) {
 }
 
 func (p *printVisitor) LeaveEnumTypeExtension(ref int) {
-	if !p.document.NodeIsLastRootNode(ast.Node{Kind: ast.NodeKindEnumTypeExtension, Ref: ref}) {
-		if p.indent != nil {
-			p.write(literal.LINETERMINATOR)
-			p.write(literal.LINETERMINATOR)
-		} else {
-			p.write(literal.SPACE)
-		}
-	}
+	p.leaveRootNode(ast.Node{Kind: ast.NodeKindEnumTypeExtension, Ref: ref})
 }
 
 func (p *printVisitor) EnterEnumValueDefinition(ref int) {
@@ -810,14 +814,7 @@ func (p *printVisitor) EnterInputObjectTypeDefinition(ref int) {
 }
 
 func (p *printVisitor) LeaveInputObjectTypeDefinition(ref int) {
-	if !p.document.NodeIsLastRootNode(ast.Node{Kind: ast.NodeKindInputObjectTypeDefinition, Ref: ref}) {
-		if p.indent != nil {
-			p.write(literal.LINETERMINATOR)
-			p.write(literal.LINETERMINATOR)
-		} else {
-			p.write(literal.SPACE)
-		}
-	}
+	p.leaveRootNode(ast.Node{Kind: ast.NodeKindInputObjectTypeDefinition, Ref: ref})
 }
 
 func (p *printVisitor) EnterInputObjectTypeExtension(ref int) {
@@ -841,14 +838,7 @@ func (p *printVisitor) EnterInputObjectTypeExtension(ref int) {
 }
 
 func (p *printVisitor) LeaveInputObjectTypeExtension(ref int) {
-	if !p.document.NodeIsLastRootNode(ast.Node{Kind: ast.NodeKindInputObjectTypeExtension, Ref: ref}) {
-		if p.indent != nil {
-			p.write(literal.LINETERMINATOR)
-			p.write(literal.LINETERMINATOR)
-		} else {
-			p.write(literal.SPACE)
-		}
-	}
+	p.leaveRootNode(ast.Node{Kind: ast.NodeKindInputObjectTypeExtension, Ref: ref})
 }
 
 func (p *printVisitor) EnterDirectiveDefinition(ref int) {
@@ -869,14 +859,7 @@ func (p *printVisitor) EnterDirectiveDefinition(ref int) {
 }
 
 func (p *printVisitor) LeaveDirectiveDefinition(ref int) {
-	if !p.document.NodeIsLastRootNode(ast.Node{Kind: ast.NodeKindDirectiveDefinition, Ref: ref}) {
-		if p.indent != nil {
-			p.write(literal.LINETERMINATOR)
-			p.write(literal.LINETERMINATOR)
-		} else {
-			p.write(literal.SPACE)
-		}
-	}
+	p.leaveRootNode(ast.Node{Kind: ast.NodeKindDirectiveDefinition, Ref: ref})
 }
 
 func (p *printVisitor) EnterDirectiveLocation(location ast.DirectiveLocation) {
@@ -948,14 +931,7 @@ func (p *printVisitor) LeaveSchemaDefinition(ref int) {
 		p.write(literal.LINETERMINATOR)
 	}
 	p.write(literal.RBRACE)
-	if !p.document.NodeIsLastRootNode(ast.Node{Kind: ast.NodeKindSchemaDefinition, Ref: ref}) {
-		if p.indent != nil {
-			p.write(literal.LINETERMINATOR)
-			p.write(literal.LINETERMINATOR)
-		} else {
-			p.write(literal.SPACE)
-		}
-	}
+	p.leaveRootNode(ast.Node{Kind: ast.NodeKindSchemaDefinition, Ref: ref})
 }
 
 func (p *printVisitor) EnterSchemaExtension(ref int) {
@@ -970,14 +946,7 @@ func (p *printVisitor) LeaveSchemaExtension(ref int) {
 		p.write(literal.LINETERMINATOR)
 	}
 	p.write(literal.RBRACE)
-	if !p.document.NodeIsLastRootNode(ast.Node{Kind: ast.NodeKindSchemaExtension, Ref: ref}) {
-		if p.indent != nil {
-			p.write(literal.LINETERMINATOR)
-			p.write(literal.LINETERMINATOR)
-		} else {
-			p.write(literal.SPACE)
-		}
-	}
+	p.leaveRootNode(ast.Node{Kind: ast.NodeKindSchemaExtension, Ref: ref})
 }
 
 func (p *printVisitor) EnterRootOperationTypeDefinition(ref int) {
@@ -2,7 +2,9 @@ package astprinter
 
 import (
 	"bytes"
+	"errors"
 	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/jensneuse/diffview"
@@ -10,6 +12,8 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
 	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
 	"github.com/wundergraph/graphql-go-tools/pkg/testing/goldie"
 )
@@ -145,6 +149,20 @@ vary: [String]! = []) on QUERY`)
 		run(t, `query($representations: [_Any!]!){_entities (representations: $representations){... on User {reviews {body product {upc __typename}}}}}`,
 			`query($representations: [_Any!]!){_entities(representations: $representations){... on User {reviews {body product {upc __typename}}}}}`)
 	})
+	t.Run("client controlled nullability", func(t *testing.T) {
+		doc := ast.NewDocument()
+		doc.Input.ResetInputString(`{dog! {name? doesKnowCommand(dogCommand: SIT)!@include(if: true) owner? @skip(if: false) {name}}}`)
+		parser := astparser.NewParser()
+		parser.EnableClientControlledNullability()
+		report := operationreport.Report{}
+		parser.Parse(doc, &report)
+		require.False(t, report.HasErrors(), report.Error())
+
+		buff := &bytes.Buffer{}
+		printer := Printer{}
+		must(t, printer.Print(doc, nil, buff))
+		assert.Equal(t, `{dog! {name? doesKnowCommand(dogCommand: SIT)!@include(if: true) owner? @skip(if: false) {name}}}`, buff.String())
+	})
 	t.Run("directives", func(t *testing.T) {
 		t.Run("on field", func(t *testing.T) {
 			run(t, `
@@ -526,6 +544,62 @@ func TestPrintOperationDefinition(t *testing.T) {
 	}
 }
 
+type chunkRecorder struct {
+	chunks []string
+	err    error
+}
+
+func (c *chunkRecorder) Write(p []byte) (n int, err error) {
+	if c.err != nil {
+		return 0, c.err
+	}
+	c.chunks = append(c.chunks, string(p))
+	return len(p), nil
+}
+
+func TestStream(t *testing.T) {
+	doc := unsafeparser.ParseGraphqlDocumentFile("./testdata/starwars.schema.graphql")
+	expected, err := PrintStringIndent(&doc, nil, "  ")
+	require.NoError(t, err)
+
+	t.Run("writes whole definitions in chunks", func(t *testing.T) {
+		out := &chunkRecorder{}
+		require.NoError(t, Stream(&doc, nil, out, WithIndent([]byte("  ")), WithChunkSize(256)))
+
+		assert.Equal(t, expected, strings.Join(out.chunks, ""))
+		require.Greater(t, len(out.chunks), 1)
+		for i, chunk := range out.chunks {
+			if i != len(out.chunks)-1 {
+				assert.GreaterOrEqual(t, len(chunk), 256)
+			}
+			_, report := astparser.ParseGraphqlDocumentString(chunk)
+			assert.False(t, report.HasErrors(), "chunk %d isn't a document of whole definitions: %s", i, report.Error())
+		}
+	})
+
+	t.Run("writes definitions bigger than the chunk size as a chunk", func(t *testing.T) {
+		out := &chunkRecorder{}
+		require.NoError(t, Stream(&doc, nil, out, WithChunkSize(1)))
+		assert.Len(t, out.chunks, len(doc.RootNodes))
+
+		expected, err := PrintString(&doc, nil)
+		require.NoError(t, err)
+		assert.Equal(t, expected, strings.Join(out.chunks, ""))
+	})
+
+	t.Run("writes documents smaller than the default chunk size at once", func(t *testing.T) {
+		out := &chunkRecorder{}
+		require.NoError(t, Stream(&doc, nil, out, WithIndent([]byte("  "))))
+		assert.Equal(t, []string{expected}, out.chunks)
+	})
+
+	t.Run("returns write errors", func(t *testing.T) {
+		out := &chunkRecorder{err: errors.New("connection reset")}
+		assert.EqualError(t, Stream(&doc, nil, out, WithChunkSize(256)), "connection reset")
+		assert.EqualError(t, Stream(&doc, nil, out), "connection reset")
+	})
+}
+
 func BenchmarkPrint(b *testing.B) {
 
 	must := func(err error) {
@@ -0,0 +1,76 @@
+package astprinter
+
+import (
+	"bytes"
+	"io"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+)
+
+// DefaultChunkSize is the chunk size of Stream if no chunk size is set with WithChunkSize
+const DefaultChunkSize = 64 * 1024
+
+// StreamOption configures the printer of Stream
+type StreamOption func(printer *Printer)
+
+// WithIndent sets the indentation of the printed document, see PrintIndent
+func WithIndent(indent []byte) StreamOption {
+	return func(printer *Printer) {
+		printer.indent = indent
+	}
+}
+
+// WithChunkSize sets the minimum size of the chunks in bytes, except for the last chunk
+func WithChunkSize(size int) StreamOption {
+	return func(printer *Printer) {
+		printer.chunkSize = size
+	}
+}
+
+// Stream prints the document to the io.Writer in chunks of whole root definitions, e.g. to serve or upload schemas of multiple MB.
+// The printed definitions are buffered until the chunk size is reached, so that only the chunk is held in memory
+// instead of the whole output, the printed document is the same as with Print.
+// Each chunk is written with a single call of Write, a definition bigger than the chunk size is written as a chunk on its own.
+func Stream(document, definition *ast.Document, out io.Writer, options ...StreamOption) error {
+	printer := Printer{
+		chunkSize: DefaultChunkSize,
+	}
+	for _, option := range options {
+		option(&printer)
+	}
+	return printer.Print(document, definition, out)
+}
+
+// chunkWriter buffers the output of the printer until the chunk size is reached at the end of a root node
+type chunkWriter struct {
+	out  io.Writer
+	size int
+	buf  bytes.Buffer
+}
+
+func (c *chunkWriter) reset(out io.Writer, size int) {
+	c.out = out
+	c.size = size
+	c.buf.Reset()
+}
+
+func (c *chunkWriter) Write(p []byte) (n int, err error) {
+	return c.buf.Write(p)
+}
+
+// endRootNode writes the buffered root nodes as a chunk in case the chunk size is reached
+func (c *chunkWriter) endRootNode() error {
+	if c.buf.Len() < c.size {
+		return nil
+	}
+	return c.flush()
+}
+
+func (c *chunkWriter) flush() error {
+	if c.buf.Len() == 0 {
+		return nil
+	}
+	_, err := c.out.Write(c.buf.Bytes())
+	c.buf.Reset()
+	return err
+}
@@ -7,7 +7,6 @@
 // The right order to not mess things up is from the deepest level up to the root.
 // Therefore this package is used to register transformations while walking an AST in order to bring all transformations in the right order.
 // Only then, when all transformations are in the right order according to depth, it's possible to safely apply them.
-//
 package asttransform
 
 import (
@@ -31,9 +30,6 @@ type (
 		// ReplaceFragmentSpreadWithInlineFragment marks a fragment spread to be replaces with an inline fragment
 		ReplaceFragmentSpreadWithInlineFragment(selectionSet int, spreadRef int, replaceWithSelectionSet int, typeCondition ast.TypeCondition)
 	}
-	transformation interface {
-		apply(transformable Transformable)
-	}
 	// Precedence defines Depth and Order of each transformation
 	Precedence struct {
 		Depth int
@@ -43,9 +39,12 @@ type (
 		precedence     Precedence
 		transformation transformation
 	}
+	// actions are sorted by their precedence, the methods are implemented on the pointer,
+	// so that sorting them doesn't allocate
+	actions []action
 	// Transformer takes transformation registrations and applies them
 	Transformer struct {
-		actions []action
+		actions actions
 	}
 )
 
@@ -57,12 +56,7 @@ func (t *Transformer) Reset() {
 // ApplyTransformations applies all registered transformations to a transformable
 func (t *Transformer) ApplyTransformations(transformable Transformable) {
 
-	sort.Slice(t.actions, func(i, j int) bool {
-		if t.actions[i].precedence.Depth != t.actions[j].precedence.Depth {
-			return t.actions[i].precedence.Depth > t.actions[j].precedence.Depth
-		}
-		return t.actions[i].precedence.Order < t.actions[j].precedence.Order
-	})
+	sort.Sort(&t.actions)
 
 	for i := range t.actions {
 		t.actions[i].transformation.apply(transformable)
@@ -72,16 +66,22 @@ func (t *Transformer) ApplyTransformations(transformable Transformable) {
 // DeleteRootNode registers an action to delete a root node
 func (t *Transformer) DeleteRootNode(precedence Precedence, node ast.Node) {
 	t.actions = append(t.actions, action{
-		precedence:     precedence,
-		transformation: deleteRootNode{node: node},
+		precedence: precedence,
+		transformation: transformation{
+			kind: deleteRootNode,
+			node: node,
+		},
 	})
 }
 
 // EmptySelectionSet registers an actions to empty a selectionset
 func (t *Transformer) EmptySelectionSet(precedence Precedence, ref int) {
 	t.actions = append(t.actions, action{
-		precedence:     precedence,
-		transformation: emptySelectionSet{ref: ref},
+		precedence: precedence,
+		transformation: transformation{
+			kind:         emptySelectionSet,
+			selectionSet: ref,
+		},
 	})
 }
 
@@ -89,9 +89,10 @@ func (t *Transformer) EmptySelectionSet(precedence Precedence, ref int) {
 func (t *Transformer) AppendSelectionSet(precedence Precedence, ref int, appendRef int) {
 	t.actions = append(t.actions, action{
 		precedence: precedence,
-		transformation: appendSelectionSet{
-			ref:       ref,
-			appendRef: appendRef,
+		transformation: transformation{
+			kind:         appendSelectionSet,
+			selectionSet: ref,
+			ref:          appendRef,
 		},
 	})
 }
@@ -100,9 +101,10 @@ func (t *Transformer) AppendSelectionSet(precedence Precedence, ref int, appendR
 func (t *Transformer) ReplaceFragmentSpread(precedence Precedence, selectionSet int, spreadRef int, replaceWithSelectionSet int) {
 	t.actions = append(t.actions, action{
 		precedence: precedence,
-		transformation: replaceFragmentSpread{
+		transformation: transformation{
+			kind:                    replaceFragmentSpread,
 			selectionSet:            selectionSet,
-			spreadRef:               spreadRef,
+			ref:                     spreadRef,
 			replaceWithSelectionSet: replaceWithSelectionSet,
 		},
 	})
@@ -112,57 +114,63 @@ func (t *Transformer) ReplaceFragmentSpread(precedence Precedence, selectionSet
 func (t *Transformer) ReplaceFragmentSpreadWithInlineFragment(precedence Precedence, selectionSet int, spreadRef int, replaceWithSelectionSet int, typeCondition ast.TypeCondition) {
 	t.actions = append(t.actions, action{
 		precedence: precedence,
-		transformation: replaceFragmentSpreadWithInlineFragment{
+		transformation: transformation{
+			kind:                    replaceFragmentSpreadWithInlineFragment,
 			selectionSet:            selectionSet,
-			spreadRef:               spreadRef,
+			ref:                     spreadRef,
 			replaceWithSelectionSet: replaceWithSelectionSet,
 			typeCondition:           typeCondition,
 		},
 	})
 }
 
-type replaceFragmentSpread struct {
-	selectionSet            int
-	spreadRef               int
-	replaceWithSelectionSet int
-}
-
-func (r replaceFragmentSpread) apply(transformable Transformable) {
-	transformable.ReplaceFragmentSpread(r.selectionSet, r.spreadRef, r.replaceWithSelectionSet)
+func (a *actions) Len() int {
+	return len(*a)
 }
 
-type replaceFragmentSpreadWithInlineFragment struct {
-	selectionSet            int
-	spreadRef               int
-	replaceWithSelectionSet int
-	typeCondition           ast.TypeCondition
-}
-
-func (r replaceFragmentSpreadWithInlineFragment) apply(transformable Transformable) {
-	transformable.ReplaceFragmentSpreadWithInlineFragment(r.selectionSet, r.spreadRef, r.replaceWithSelectionSet, r.typeCondition)
-}
-
-type deleteRootNode struct {
-	node ast.Node
+func (a *actions) Less(i, j int) bool {
+	if (*a)[i].precedence.Depth != (*a)[j].precedence.Depth {
+		return (*a)[i].precedence.Depth > (*a)[j].precedence.Depth
+	}
+	return (*a)[i].precedence.Order < (*a)[j].precedence.Order
 }
 
-func (d deleteRootNode) apply(transformable Transformable) {
-	transformable.DeleteRootNode(d.node)
+func (a *actions) Swap(i, j int) {
+	(*a)[i], (*a)[j] = (*a)[j], (*a)[i]
 }
 
-type emptySelectionSet struct {
-	ref int
-}
+type transformationKind int
 
-func (e emptySelectionSet) apply(transformable Transformable) {
-	transformable.EmptySelectionSet(e.ref)
-}
+const (
+	deleteRootNode transformationKind = iota
+	emptySelectionSet
+	appendSelectionSet
+	replaceFragmentSpread
+	replaceFragmentSpreadWithInlineFragment
+)
 
-type appendSelectionSet struct {
-	ref       int
-	appendRef int
+// transformation is a plain struct instead of an interface, so that registering transformations doesn't allocate
+type transformation struct {
+	kind         transformationKind
+	node         ast.Node
+	selectionSet int
+	// ref is the appended selection set or the replaced fragment spread
+	ref                     int
+	replaceWithSelectionSet int
+	typeCondition           ast.TypeCondition
 }
 
-func (a appendSelectionSet) apply(transformable Transformable) {
-	transformable.AppendSelectionSet(a.ref, a.appendRef)
+func (t transformation) apply(transformable Transformable) {
+	switch t.kind {
+	case deleteRootNode:
+		transformable.DeleteRootNode(t.node)
+	case emptySelectionSet:
+		transformable.EmptySelectionSet(t.selectionSet)
+	case appendSelectionSet:
+		transformable.AppendSelectionSet(t.selectionSet, t.ref)
+	case replaceFragmentSpread:
+		transformable.ReplaceFragmentSpread(t.selectionSet, t.ref, t.replaceWithSelectionSet)
+	case replaceFragmentSpreadWithInlineFragment:
+		transformable.ReplaceFragmentSpreadWithInlineFragment(t.selectionSet, t.ref, t.replaceWithSelectionSet, t.typeCondition)
+	}
 }
@@ -0,0 +1,147 @@
+package asttransform
+
+import (
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+)
+
+// PruneSchemaConfig configures the PruneSchema transform
+type PruneSchemaConfig struct {
+	// PinDirectives keeps the types annotated with one of the directives and the types they reference,
+	// e.g. "keep" for @keep on types which are only used outside of the schema
+	PinDirectives []string
+	// PinnedTypes are the names of types which are kept like the types annotated with one of the PinDirectives
+	PinnedTypes []string
+	// RemoveUnusedInputFields removes the optional input fields of the input object types in UsedInputFields which aren't listed as used.
+	// Required input fields without default values are always kept, removing them would break the operations of clients.
+	// Input object types without usage data are kept completely, as are input object types without any used field.
+	RemoveUnusedInputFields bool
+	// UsedInputFields maps the names of input object types to the names of their input fields used by clients,
+	// e.g. collected from the persisted operations and variables of the clients
+	UsedInputFields map[string][]string
+}
+
+// PruneSchema removes the types which aren't reachable from the root operation types, see ast.TypeUsageGraph,
+// together with their extensions. Introspection types, built-in scalars and directive definitions are kept.
+// Unused input fields are removed before the types, so that types only referenced by removed input fields are removed as well.
+func PruneSchema(config PruneSchemaConfig) SchemaTransform {
+	pinDirectives := make(map[string]bool, len(config.PinDirectives))
+	for _, name := range config.PinDirectives {
+		pinDirectives[name] = true
+	}
+	usedInputFields := make(map[string]map[string]bool, len(config.UsedInputFields))
+	for typeName, fieldNames := range config.UsedInputFields {
+		used := make(map[string]bool, len(fieldNames))
+		for _, fieldName := range fieldNames {
+			used[fieldName] = true
+		}
+		usedInputFields[typeName] = used
+	}
+
+	return SchemaTransformFunc(func(definition *ast.Document) error {
+		if config.RemoveUnusedInputFields {
+			removeUnusedInputFields(definition, usedInputFields)
+		}
+
+		pinnedTypes := append([]string(nil), config.PinnedTypes...)
+		for _, node := range definition.RootNodes {
+			if isPinned(definition, node, pinDirectives) {
+				pinnedTypes = append(pinnedTypes, definition.NodeNameString(node))
+			}
+		}
+
+		orphans := make(map[string]bool)
+		for _, typeName := range definition.TypeUsageGraph().Orphans(pinnedTypes...) {
+			orphans[typeName] = true
+		}
+		if len(orphans) == 0 {
+			return nil
+		}
+
+		rootNodes := definition.RootNodes[:0]
+		for _, node := range definition.RootNodes {
+			switch node.Kind {
+			case ast.NodeKindSchemaDefinition, ast.NodeKindSchemaExtension, ast.NodeKindDirectiveDefinition:
+			default:
+				if orphans[definition.NodeNameString(node)] {
+					continue
+				}
+			}
+			rootNodes = append(rootNodes, node)
+		}
+		definition.RootNodes = rootNodes
+		return nil
+	})
+}
+
+func removeUnusedInputFields(definition *ast.Document, usedInputFields map[string]map[string]bool) {
+	removeInputFields := func(typeName string, refs []int) []int {
+		filtered := refs[:0]
+		for _, ref := range refs {
+			inputValue := definition.InputValueDefinitions[ref]
+			required := definition.TypeIsNonNull(inputValue.Type) && !inputValue.DefaultValue.IsDefined
+			if required || usedInputFields[typeName][definition.InputValueDefinitionNameString(ref)] {
+				filtered = append(filtered, ref)
+			}
+		}
+		return filtered
+	}
+
+	for _, node := range definition.RootNodes {
+		var inputObject *ast.InputObjectTypeDefinition
+		switch node.Kind {
+		case ast.NodeKindInputObjectTypeDefinition:
+			inputObject = &definition.InputObjectTypeDefinitions[node.Ref]
+		case ast.NodeKindInputObjectTypeExtension:
+			inputObject = &definition.InputObjectTypeExtensions[node.Ref].InputObjectTypeDefinition
+		default:
+			continue
+		}
+		typeName := definition.Input.ByteSliceString(inputObject.Name)
+		if _, ok := usedInputFields[typeName]; !ok {
+			continue
+		}
+		// input objects must define at least one input field
+		if refs := removeInputFields(typeName, append([]int(nil), inputObject.InputFieldsDefinition.Refs...)); len(refs) != 0 {
+			inputObject.InputFieldsDefinition.Refs = refs
+		}
+	}
+}
+
+func isPinned(definition *ast.Document, node ast.Node, pinDirectives map[string]bool) bool {
+	if len(pinDirectives) == 0 {
+		return false
+	}
+	var directives []int
+	switch node.Kind {
+	case ast.NodeKindObjectTypeDefinition:
+		directives = definition.ObjectTypeDefinitions[node.Ref].Directives.Refs
+	case ast.NodeKindObjectTypeExtension:
+		directives = definition.ObjectTypeExtensions[node.Ref].Directives.Refs
+	case ast.NodeKindInterfaceTypeDefinition:
+		directives = definition.InterfaceTypeDefinitions[node.Ref].Directives.Refs
+	case ast.NodeKindInterfaceTypeExtension:
+		directives = definition.InterfaceTypeExtensions[node.Ref].Directives.Refs
+	case ast.NodeKindUnionTypeDefinition:
+		directives = definition.UnionTypeDefinitions[node.Ref].Directives.Refs
+	case ast.NodeKindUnionTypeExtension:
+		directives = definition.UnionTypeExtensions[node.Ref].Directives.Refs
+	case ast.NodeKindInputObjectTypeDefinition:
+		directives = definition.InputObjectTypeDefinitions[node.Ref].Directives.Refs
+	case ast.NodeKindInputObjectTypeExtension:
+		directives = definition.InputObjectTypeExtensions[node.Ref].Directives.Refs
+	case ast.NodeKindEnumTypeDefinition:
+		directives = definition.EnumTypeDefinitions[node.Ref].Directives.Refs
+	case ast.NodeKindEnumTypeExtension:
+		directives = definition.EnumTypeExtensions[node.Ref].Directives.Refs
+	case ast.NodeKindScalarTypeDefinition:
+		directives = definition.ScalarTypeDefinitions[node.Ref].Directives.Refs
+	case ast.NodeKindScalarTypeExtension:
+		directives = definition.ScalarTypeExtensions[node.Ref].Directives.Refs
+	}
+	for _, ref := range directives {
+		if pinDirectives[definition.DirectiveNameString(ref)] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,91 @@
+package asttransform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+)
+
+func TestPruneSchema(t *testing.T) {
+	const schema = `
+		directive @keep on OBJECT | SCALAR
+		directive @limit(options: LimitOptions) on FIELD
+		type Query { user(filter: UserFilter): User node: Node }
+		interface Node { id: ID! }
+		type User implements Node { id: ID! name: String }
+		type Photo implements Node { id: ID! }
+		input UserFilter { id: ID! name: String role: Role sort: SortOrder = ASC }
+		input LimitOptions { max: Int }
+		enum Role { ADMIN USER }
+		enum SortOrder { ASC DESC }
+		type Unused { value: Orphaned }
+		type Orphaned { unused: Unused }
+		extend type Unused { other: String }
+		type Event @keep { payload: Payload }
+		scalar Payload
+		scalar Time @keep
+		scalar Date
+		scalar Float`
+
+	run := func(t *testing.T, config PruneSchemaConfig, expected string) {
+		t.Helper()
+		definition := unsafeparser.ParseGraphqlDocumentString(schema)
+		derived, err := NewSchemaTransformPipeline(PruneSchema(config)).Transform(&definition)
+		require.NoError(t, err)
+		actual, err := astprinter.PrintString(derived, nil)
+		require.NoError(t, err)
+		assert.Equal(t, unsafeprint(expected), actual)
+	}
+
+	t.Run("removes unreachable types", func(t *testing.T) {
+		run(t, PruneSchemaConfig{}, `
+			directive @keep on OBJECT | SCALAR
+			directive @limit(options: LimitOptions) on FIELD
+			type Query { user(filter: UserFilter): User node: Node }
+			interface Node { id: ID! }
+			type User implements Node { id: ID! name: String }
+			type Photo implements Node { id: ID! }
+			input UserFilter { id: ID! name: String role: Role sort: SortOrder = ASC }
+			input LimitOptions { max: Int }
+			enum Role { ADMIN USER }
+			enum SortOrder { ASC DESC }
+			scalar Float`)
+	})
+
+	t.Run("keeps pinned types", func(t *testing.T) {
+		run(t, PruneSchemaConfig{PinDirectives: []string{"keep"}, PinnedTypes: []string{"Date"}}, `
+			directive @keep on OBJECT | SCALAR
+			directive @limit(options: LimitOptions) on FIELD
+			type Query { user(filter: UserFilter): User node: Node }
+			interface Node { id: ID! }
+			type User implements Node { id: ID! name: String }
+			type Photo implements Node { id: ID! }
+			input UserFilter { id: ID! name: String role: Role sort: SortOrder = ASC }
+			input LimitOptions { max: Int }
+			enum Role { ADMIN USER }
+			enum SortOrder { ASC DESC }
+			type Event @keep { payload: Payload }
+			scalar Payload
+			scalar Time @keep
+			scalar Date
+			scalar Float`)
+	})
+
+	t.Run("removes unused input fields", func(t *testing.T) {
+		run(t, PruneSchemaConfig{RemoveUnusedInputFields: true, UsedInputFields: map[string][]string{"UserFilter": {"role"}}}, `
+			directive @keep on OBJECT | SCALAR
+			directive @limit(options: LimitOptions) on FIELD
+			type Query { user(filter: UserFilter): User node: Node }
+			interface Node { id: ID! }
+			type User implements Node { id: ID! name: String }
+			type Photo implements Node { id: ID! }
+			input UserFilter { id: ID! role: Role }
+			input LimitOptions { max: Int }
+			enum Role { ADMIN USER }
+			scalar Float`)
+	})
+}
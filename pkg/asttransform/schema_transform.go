@@ -0,0 +1,309 @@
+package asttransform
+
+import (
+	"fmt"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+// SchemaTransform transforms a schema definition in place, e.g. to build a variant of a schema
+type SchemaTransform interface {
+	TransformSchema(definition *ast.Document) error
+}
+
+type SchemaTransformFunc func(definition *ast.Document) error
+
+func (f SchemaTransformFunc) TransformSchema(definition *ast.Document) error {
+	return f(definition)
+}
+
+// SchemaTransformPipeline applies schema transforms in order to derive a new schema from a definition.
+// The definition is re-parsed after each transform, so every transform sees a consistent document with a valid index.
+type SchemaTransformPipeline struct {
+	transforms []SchemaTransform
+}
+
+func NewSchemaTransformPipeline(transforms ...SchemaTransform) *SchemaTransformPipeline {
+	return &SchemaTransformPipeline{
+		transforms: transforms,
+	}
+}
+
+// Add appends transforms to the pipeline
+func (p *SchemaTransformPipeline) Add(transforms ...SchemaTransform) *SchemaTransformPipeline {
+	p.transforms = append(p.transforms, transforms...)
+	return p
+}
+
+// Transform returns the document derived by applying all transforms, the definition itself isn't modified
+func (p *SchemaTransformPipeline) Transform(definition *ast.Document) (*ast.Document, error) {
+	derived := ast.NewDocument()
+	if err := copySchema(definition, derived, ""); err != nil {
+		return nil, err
+	}
+	for i := range p.transforms {
+		if err := p.transforms[i].TransformSchema(derived); err != nil {
+			return nil, err
+		}
+		if err := copySchema(derived, derived, ""); err != nil {
+			return nil, err
+		}
+	}
+	return derived, nil
+}
+
+// copySchema prints the definition, appends the additional type system definitions and parses the result into the target,
+// which may be the definition itself
+func copySchema(definition, target *ast.Document, additionalDefinitions string) error {
+	printed, err := astprinter.PrintString(definition, nil)
+	if err != nil {
+		return err
+	}
+	if additionalDefinitions != "" {
+		printed += "\n" + additionalDefinitions
+	}
+	target.Reset()
+	target.Input.ResetInputString(printed)
+	report := operationreport.Report{}
+	astparser.NewParser().Parse(target, &report)
+	if report.HasErrors() {
+		return report
+	}
+	return nil
+}
+
+// RemoveFieldsByDirective removes all fields, input fields and arguments annotated with one of the directives,
+// e.g. "internal" for @internal. The definitions of the directives are kept.
+func RemoveFieldsByDirective(directiveNames ...string) SchemaTransform {
+	directives := make(map[string]bool, len(directiveNames))
+	for _, name := range directiveNames {
+		directives[name] = true
+	}
+	return SchemaTransformFunc(func(definition *ast.Document) error {
+		hasDirective := func(refs []int) bool {
+			for _, ref := range refs {
+				if directives[definition.DirectiveNameString(ref)] {
+					return true
+				}
+			}
+			return false
+		}
+		removeInputValues := func(refs []int) []int {
+			filtered := refs[:0]
+			for _, ref := range refs {
+				if !hasDirective(definition.InputValueDefinitions[ref].Directives.Refs) {
+					filtered = append(filtered, ref)
+				}
+			}
+			return filtered
+		}
+		removeFields := func(refs []int) []int {
+			filtered := refs[:0]
+			for _, ref := range refs {
+				field := &definition.FieldDefinitions[ref]
+				if hasDirective(field.Directives.Refs) {
+					continue
+				}
+				field.ArgumentsDefinition.Refs = removeInputValues(field.ArgumentsDefinition.Refs)
+				field.HasArgumentsDefinitions = len(field.ArgumentsDefinition.Refs) != 0
+				filtered = append(filtered, ref)
+			}
+			return filtered
+		}
+
+		for i := range definition.ObjectTypeDefinitions {
+			fields := &definition.ObjectTypeDefinitions[i].FieldsDefinition
+			fields.Refs = removeFields(fields.Refs)
+			definition.ObjectTypeDefinitions[i].HasFieldDefinitions = len(fields.Refs) != 0
+		}
+		for i := range definition.ObjectTypeExtensions {
+			fields := &definition.ObjectTypeExtensions[i].FieldsDefinition
+			fields.Refs = removeFields(fields.Refs)
+			definition.ObjectTypeExtensions[i].HasFieldDefinitions = len(fields.Refs) != 0
+		}
+		for i := range definition.InterfaceTypeDefinitions {
+			fields := &definition.InterfaceTypeDefinitions[i].FieldsDefinition
+			fields.Refs = removeFields(fields.Refs)
+			definition.InterfaceTypeDefinitions[i].HasFieldDefinitions = len(fields.Refs) != 0
+		}
+		for i := range definition.InterfaceTypeExtensions {
+			fields := &definition.InterfaceTypeExtensions[i].FieldsDefinition
+			fields.Refs = removeFields(fields.Refs)
+			definition.InterfaceTypeExtensions[i].HasFieldDefinitions = len(fields.Refs) != 0
+		}
+		for i := range definition.InputObjectTypeDefinitions {
+			fields := &definition.InputObjectTypeDefinitions[i].InputFieldsDefinition
+			fields.Refs = removeInputValues(fields.Refs)
+			definition.InputObjectTypeDefinitions[i].HasInputFieldsDefinition = len(fields.Refs) != 0
+		}
+		for i := range definition.InputObjectTypeExtensions {
+			fields := &definition.InputObjectTypeExtensions[i].InputFieldsDefinition
+			fields.Refs = removeInputValues(fields.Refs)
+			definition.InputObjectTypeExtensions[i].HasInputFieldsDefinition = len(fields.Refs) != 0
+		}
+		return nil
+	})
+}
+
+// RenameTypes renames types and all references to them, the keys of renames are the current names of the types
+func RenameTypes(renames map[string]string) SchemaTransform {
+	return SchemaTransformFunc(func(definition *ast.Document) error {
+		newNames := make(map[string]ast.ByteSliceReference, len(renames))
+		for from, to := range renames {
+			newNames[from] = definition.Input.AppendInputString(to)
+		}
+		rename := func(name *ast.ByteSliceReference) {
+			if newName, ok := newNames[definition.Input.ByteSliceString(*name)]; ok {
+				*name = newName
+			}
+		}
+
+		for i := range definition.Types {
+			if definition.Types[i].TypeKind == ast.TypeKindNamed {
+				rename(&definition.Types[i].Name)
+			}
+		}
+		for i := range definition.RootOperationTypeDefinitions {
+			rename(&definition.RootOperationTypeDefinitions[i].NamedType.Name)
+		}
+		for _, node := range definition.RootNodes {
+			switch node.Kind {
+			case ast.NodeKindObjectTypeDefinition:
+				rename(&definition.ObjectTypeDefinitions[node.Ref].Name)
+			case ast.NodeKindObjectTypeExtension:
+				rename(&definition.ObjectTypeExtensions[node.Ref].Name)
+			case ast.NodeKindInterfaceTypeDefinition:
+				rename(&definition.InterfaceTypeDefinitions[node.Ref].Name)
+			case ast.NodeKindInterfaceTypeExtension:
+				rename(&definition.InterfaceTypeExtensions[node.Ref].Name)
+			case ast.NodeKindUnionTypeDefinition:
+				rename(&definition.UnionTypeDefinitions[node.Ref].Name)
+			case ast.NodeKindUnionTypeExtension:
+				rename(&definition.UnionTypeExtensions[node.Ref].Name)
+			case ast.NodeKindInputObjectTypeDefinition:
+				rename(&definition.InputObjectTypeDefinitions[node.Ref].Name)
+			case ast.NodeKindInputObjectTypeExtension:
+				rename(&definition.InputObjectTypeExtensions[node.Ref].Name)
+			case ast.NodeKindEnumTypeDefinition:
+				rename(&definition.EnumTypeDefinitions[node.Ref].Name)
+			case ast.NodeKindEnumTypeExtension:
+				rename(&definition.EnumTypeExtensions[node.Ref].Name)
+			case ast.NodeKindScalarTypeDefinition:
+				rename(&definition.ScalarTypeDefinitions[node.Ref].Name)
+			case ast.NodeKindScalarTypeExtension:
+				rename(&definition.ScalarTypeExtensions[node.Ref].Name)
+			}
+		}
+		return nil
+	})
+}
+
+// MakeFieldsNonNull makes all fields annotated with the directive non-null, e.g. "nonNull" for @nonNull.
+// The directive is removed from the fields, its definition is kept.
+func MakeFieldsNonNull(directiveName string) SchemaTransform {
+	return SchemaTransformFunc(func(definition *ast.Document) error {
+		for i := range definition.FieldDefinitions {
+			field := &definition.FieldDefinitions[i]
+			directives := field.Directives.Refs[:0]
+			annotated := false
+			for _, ref := range field.Directives.Refs {
+				if definition.DirectiveNameString(ref) == directiveName {
+					annotated = true
+					continue
+				}
+				directives = append(directives, ref)
+			}
+			if !annotated {
+				continue
+			}
+			field.Directives.Refs = directives
+			field.HasDirectives = len(directives) != 0
+			if definition.Types[field.Type].TypeKind != ast.TypeKindNonNull {
+				field.Type = definition.AddNonNullType(field.Type)
+			}
+		}
+		return nil
+	})
+}
+
+// InjectInterface adds the interface definition, e.g. "interface Node { id: ID! }", to the schema unless it's defined already
+// and lets the object types implement it. Without type names all object types defining the fields of the interface implement it.
+// Named types which don't define all fields of the interface are an error.
+func InjectInterface(interfaceDefinition string, typeNames ...string) SchemaTransform {
+	return SchemaTransformFunc(func(definition *ast.Document) error {
+		injected := ast.NewDocument()
+		injected.Input.ResetInputString(interfaceDefinition)
+		report := operationreport.Report{}
+		astparser.NewParser().Parse(injected, &report)
+		if report.HasErrors() {
+			return report
+		}
+		if len(injected.RootNodes) != 1 || injected.RootNodes[0].Kind != ast.NodeKindInterfaceTypeDefinition {
+			return fmt.Errorf("expected a single interface definition, got: %s", interfaceDefinition)
+		}
+		interfaceName := injected.InterfaceTypeDefinitionNameString(injected.RootNodes[0].Ref)
+
+		existing, exists := definition.Index.FirstNodeByNameStr(interfaceName)
+		if exists && existing.Kind != ast.NodeKindInterfaceTypeDefinition {
+			return fmt.Errorf("type %s isn't an interface", interfaceName)
+		}
+		if !exists {
+			if err := copySchema(definition, definition, interfaceDefinition); err != nil {
+				return err
+			}
+			existing, _ = definition.Index.FirstNodeByNameStr(interfaceName)
+		}
+		interfaceFields := definition.InterfaceTypeDefinitions[existing.Ref].FieldsDefinition.Refs
+
+		implementsInterface := func(ref int) (missingField string) {
+			for _, interfaceField := range interfaceFields {
+				fieldName := definition.FieldDefinitionNameString(interfaceField)
+				implemented := false
+				for _, field := range definition.ObjectTypeDefinitions[ref].FieldsDefinition.Refs {
+					if definition.FieldDefinitionNameString(field) == fieldName &&
+						definition.TypesAreEqualDeep(definition.FieldDefinitions[field].Type, definition.FieldDefinitions[interfaceField].Type) {
+						implemented = true
+						break
+					}
+				}
+				if !implemented {
+					return fieldName
+				}
+			}
+			return ""
+		}
+		implement := func(ref int) {
+			for _, typeRef := range definition.ObjectTypeDefinitions[ref].ImplementsInterfaces.Refs {
+				if definition.ResolveTypeNameString(typeRef) == interfaceName {
+					return
+				}
+			}
+			definition.ObjectTypeDefinitions[ref].ImplementsInterfaces.Refs = append(definition.ObjectTypeDefinitions[ref].ImplementsInterfaces.Refs,
+				definition.AddNamedType([]byte(interfaceName)))
+		}
+
+		if len(typeNames) == 0 {
+			for _, node := range definition.RootNodes {
+				if node.Kind == ast.NodeKindObjectTypeDefinition && implementsInterface(node.Ref) == "" {
+					implement(node.Ref)
+				}
+			}
+			return nil
+		}
+
+		for _, typeName := range typeNames {
+			node, ok := definition.Index.FirstNodeByNameStr(typeName)
+			if !ok || node.Kind != ast.NodeKindObjectTypeDefinition {
+				return fmt.Errorf("object type %s not found", typeName)
+			}
+			if missingField := implementsInterface(node.Ref); missingField != "" {
+				return fmt.Errorf("type %s doesn't define the field %s of interface %s", typeName, missingField, interfaceName)
+			}
+			implement(node.Ref)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,124 @@
+package asttransform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+)
+
+func TestSchemaTransformPipeline(t *testing.T) {
+	run := func(t *testing.T, schema, expected string, transforms ...SchemaTransform) {
+		t.Helper()
+		definition := unsafeparser.ParseGraphqlDocumentString(schema)
+		before, err := astprinter.PrintString(&definition, nil)
+		require.NoError(t, err)
+
+		derived, err := NewSchemaTransformPipeline(transforms...).Transform(&definition)
+		require.NoError(t, err)
+		actual, err := astprinter.PrintString(derived, nil)
+		require.NoError(t, err)
+		assert.Equal(t, unsafeprint(expected), actual)
+
+		after, err := astprinter.PrintString(&definition, nil)
+		require.NoError(t, err)
+		assert.Equal(t, before, after, "the definition must not be modified")
+	}
+
+	t.Run("remove fields by directive", func(t *testing.T) {
+		run(t, `
+			type Query { user(id: ID!, debug: Boolean @internal): User secret: String @internal }
+			type User { name: String ssn: String @internal }
+			input UserFilter { name: String ssn: String @internal }`, `
+			type Query { user(id: ID!): User }
+			type User { name: String }
+			input UserFilter { name: String }`,
+			RemoveFieldsByDirective("internal"))
+	})
+
+	t.Run("rename types", func(t *testing.T) {
+		run(t, `
+			schema { query: Query }
+			type Query { user: User users: [User!]! search: SearchResult }
+			type User implements Node { id: ID! }
+			interface Node { id: ID! }
+			union SearchResult = User
+			extend type User { name: String }`, `
+			schema { query: Query }
+			type Query { user: Account users: [Account!]! search: SearchResult }
+			type Account implements Entity { id: ID! }
+			interface Entity { id: ID! }
+			union SearchResult = Account
+			extend type Account { name: String }`,
+			RenameTypes(map[string]string{"User": "Account", "Node": "Entity"}))
+	})
+
+	t.Run("make fields non-null", func(t *testing.T) {
+		run(t, `
+			type Query { user: User @nonNull users: [User] @nonNull @deprecated name: String! @nonNull other: String }`, `
+			type Query { user: User! users: [User]! @deprecated name: String! other: String }`,
+			MakeFieldsNonNull("nonNull"))
+	})
+
+	t.Run("inject interface into types defining its fields", func(t *testing.T) {
+		run(t, `
+			type Query { user: User }
+			type User { id: ID! name: String }
+			type Post implements Node { id: ID! }
+			type Comment { id: ID }`, `
+			type Query { user: User }
+			type User implements Node { id: ID! name: String }
+			type Post implements Node { id: ID! }
+			type Comment { id: ID }
+			interface Node { id: ID! }`,
+			InjectInterface(`interface Node { id: ID! }`))
+	})
+
+	t.Run("inject interface into named types", func(t *testing.T) {
+		definition := unsafeparser.ParseGraphqlDocumentString(`
+			type Query { user: User }
+			type User { id: ID! }
+			type Post { title: String }`)
+
+		_, err := NewSchemaTransformPipeline(InjectInterface(`interface Node { id: ID! }`, "Post")).Transform(&definition)
+		assert.EqualError(t, err, "type Post doesn't define the field id of interface Node")
+
+		_, err = NewSchemaTransformPipeline(InjectInterface(`type Node { id: ID! }`)).Transform(&definition)
+		assert.Error(t, err)
+
+		derived, err := NewSchemaTransformPipeline(InjectInterface(`interface Node { id: ID! }`, "User")).Transform(&definition)
+		require.NoError(t, err)
+		actual, err := astprinter.PrintString(derived, nil)
+		require.NoError(t, err)
+		assert.Equal(t, unsafeprint(`
+			type Query { user: User }
+			type User implements Node { id: ID! }
+			type Post { title: String }
+			interface Node { id: ID! }`), actual)
+	})
+
+	t.Run("composed transforms", func(t *testing.T) {
+		run(t, `
+			type Query { user: User @nonNull }
+			type User { id: ID! ssn: String @internal }`, `
+			type Query { user: Account! }
+			type Account implements Node { id: ID! }
+			interface Node { id: ID! }`,
+			RemoveFieldsByDirective("internal"),
+			MakeFieldsNonNull("nonNull"),
+			InjectInterface(`interface Node { id: ID! }`),
+			RenameTypes(map[string]string{"User": "Account"}))
+	})
+}
+
+func unsafeprint(schema string) string {
+	definition := unsafeparser.ParseGraphqlDocumentString(schema)
+	printed, err := astprinter.PrintString(&definition, nil)
+	if err != nil {
+		panic(err)
+	}
+	return printed
+}
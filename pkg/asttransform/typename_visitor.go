@@ -58,8 +58,8 @@ func (v *TypeNameVisitor) LeaveInterfaceTypeDefinition(ref int) {
 
 func (v *TypeNameVisitor) LeaveObjectTypeDefinition(ref int) {
 	objectTypeDefName := v.definition.ObjectTypeDefinitionNameBytes(ref)
-	if bytes.Equal(objectTypeDefName, v.definition.Index.SubscriptionTypeName) ||
-		bytes.Equal(objectTypeDefName, ast.DefaultSubscriptionTypeName) {
+	_, _, subscriptionTypeName := v.definition.Index.RootOperationTypeNames()
+	if bytes.Equal(objectTypeDefName, subscriptionTypeName) {
 		return
 	}
 
@@ -6,19 +6,54 @@ import (
 	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
 )
 
+// Codes of the definition rules, they identify the rule which reported an error, e.g. in a validation report.
+const (
+	DefinitionRuleCodePopulatedTypeBodies              = "POPULATED_TYPE_BODIES"
+	DefinitionRuleCodeUniqueOperationTypes             = "UNIQUE_OPERATION_TYPES"
+	DefinitionRuleCodeUniqueTypeNames                  = "UNIQUE_TYPE_NAMES"
+	DefinitionRuleCodeUniqueFieldDefinitionNames       = "UNIQUE_FIELD_DEFINITION_NAMES"
+	DefinitionRuleCodeUniqueEnumValueNames             = "UNIQUE_ENUM_VALUE_NAMES"
+	DefinitionRuleCodeUniqueUnionMemberTypes           = "UNIQUE_UNION_MEMBER_TYPES"
+	DefinitionRuleCodeKnownTypeNames                   = "KNOWN_TYPE_NAMES"
+	DefinitionRuleCodeRequireDefinedTypesForExtensions = "REQUIRE_DEFINED_TYPES_FOR_EXTENSIONS"
+	DefinitionRuleCodeImplementTransitiveInterfaces    = "IMPLEMENT_TRANSITIVE_INTERFACES"
+	DefinitionRuleCodeImplementingTypesAreSupersets    = "IMPLEMENTING_TYPES_ARE_SUPERSETS"
+	DefinitionRuleCodeRelayConnections                 = "RELAY_CONNECTIONS"
+)
+
+// DefinitionRule is a definition validation rule together with its code
+type DefinitionRule struct {
+	Code string
+	Rule Rule
+}
+
+// DefaultDefinitionRules returns the rules of DefaultDefinitionValidator
+func DefaultDefinitionRules() []DefinitionRule {
+	return []DefinitionRule{
+		{Code: DefinitionRuleCodePopulatedTypeBodies, Rule: PopulatedTypeBodies()},
+		{Code: DefinitionRuleCodeUniqueOperationTypes, Rule: UniqueOperationTypes()},
+		{Code: DefinitionRuleCodeUniqueTypeNames, Rule: UniqueTypeNames()},
+		{Code: DefinitionRuleCodeUniqueFieldDefinitionNames, Rule: UniqueFieldDefinitionNames()},
+		{Code: DefinitionRuleCodeUniqueEnumValueNames, Rule: UniqueEnumValueNames()},
+		{Code: DefinitionRuleCodeUniqueUnionMemberTypes, Rule: UniqueUnionMemberTypes()},
+		{Code: DefinitionRuleCodeKnownTypeNames, Rule: KnownTypeNames()},
+		{Code: DefinitionRuleCodeRequireDefinedTypesForExtensions, Rule: RequireDefinedTypesForExtensions()},
+		{Code: DefinitionRuleCodeImplementTransitiveInterfaces, Rule: ImplementTransitiveInterfaces()},
+		{Code: DefinitionRuleCodeImplementingTypesAreSupersets, Rule: ImplementingTypesAreSupersets()},
+	}
+}
+
 func DefaultDefinitionValidator() *DefinitionValidator {
-	return NewDefinitionValidator(
-		PopulatedTypeBodies(),
-		UniqueOperationTypes(),
-		UniqueTypeNames(),
-		UniqueFieldDefinitionNames(),
-		UniqueEnumValueNames(),
-		UniqueUnionMemberTypes(),
-		KnownTypeNames(),
-		RequireDefinedTypesForExtensions(),
-		ImplementTransitiveInterfaces(),
-		ImplementingTypesAreSupersets(),
-	)
+	return NewDefinitionValidatorFromRules(DefaultDefinitionRules()...)
+}
+
+// NewDefinitionValidatorFromRules returns a validator running the given definition rules
+func NewDefinitionValidatorFromRules(rules ...DefinitionRule) *DefinitionValidator {
+	validator := NewDefinitionValidator()
+	for i := range rules {
+		validator.RegisterRule(rules[i].Rule)
+	}
+	return validator
 }
 
 func NewDefinitionValidator(rules ...Rule) *DefinitionValidator {
@@ -32,6 +32,7 @@ type fieldSelectionMergingVisitor struct {
 type nonScalarRequirement struct {
 	path                    ast.Path
 	objectName              ast.ByteSlice
+	fieldRef                int
 	fieldTypeRef            int
 	fieldTypeDefinitionNode ast.Node
 }
@@ -97,7 +98,8 @@ func (f *fieldSelectionMergingVisitor) EnterField(ref int) {
 	definition, ok := f.definition.NodeFieldDefinitionByName(f.EnclosingTypeDefinition, fieldName)
 	if !ok {
 		enclosingTypeName := f.definition.NodeNameBytes(f.EnclosingTypeDefinition)
-		f.StopWithExternalErr(operationreport.ErrFieldUndefinedOnType(fieldName, enclosingTypeName))
+		f.StopWithExternalErr(operationreport.ErrFieldUndefinedOnType(fieldName, enclosingTypeName).
+			WithSuggestions(string(fieldName), f.definition.NodeFieldDefinitionNames(f.EnclosingTypeDefinition)))
 		return
 	}
 
@@ -127,6 +129,9 @@ func (f *fieldSelectionMergingVisitor) EnterField(ref int) {
 				}
 				f.StopWithExternalErr(operationreport.ErrTypesForFieldMismatch(objectName, left, right))
 				return
+			} else if f.operation.Fields[f.nonScalarRequirements[i].fieldRef].Nullability != f.operation.Fields[ref].Nullability {
+				f.StopWithExternalErr(operationreport.ErrDifferingFieldsOnPotentiallySameType(objectName))
+				return
 			}
 
 			if fieldDefinitionTypeNode.Kind != f.nonScalarRequirements[i].fieldTypeDefinitionNode.Kind {
@@ -150,6 +155,7 @@ func (f *fieldSelectionMergingVisitor) EnterField(ref int) {
 		f.nonScalarRequirements = append(f.nonScalarRequirements, nonScalarRequirement{
 			path:                    path,
 			objectName:              objectName,
+			fieldRef:                ref,
 			fieldTypeRef:            fieldType,
 			fieldTypeDefinitionNode: fieldDefinitionTypeNode,
 		})
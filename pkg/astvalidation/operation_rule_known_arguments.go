@@ -55,8 +55,20 @@ func (v *knownArgumentsVisitor) EnterArgument(ref int) {
 	case ast.NodeKindField:
 		objectTypeDefName := v.definition.ObjectTypeDefinitionNameBytes(v.enclosingNode.Ref)
 
-		v.Report.AddExternalError(operationreport.ErrArgumentNotDefinedOnField(argumentName, objectTypeDefName, ancestorName, argumentPosition))
+		var argumentNames []string
+		if fieldDefinition, ok := v.definition.NodeFieldDefinitionByName(v.enclosingNode, ancestorName); ok {
+			argumentNames = v.definition.NodeInputValueDefinitionNames(ast.Node{Kind: ast.NodeKindFieldDefinition, Ref: fieldDefinition})
+		}
+
+		v.Report.AddExternalError(operationreport.ErrArgumentNotDefinedOnField(argumentName, objectTypeDefName, ancestorName, argumentPosition).
+			WithSuggestions(string(argumentName), argumentNames))
 	case ast.NodeKindDirective:
-		v.Report.AddExternalError(operationreport.ErrArgumentNotDefinedOnDirective(argumentName, ancestorName, argumentPosition))
+		var argumentNames []string
+		if directiveDefinition, ok := v.definition.DirectiveDefinitionByName(string(ancestorName)); ok {
+			argumentNames = v.definition.NodeInputValueDefinitionNames(ast.Node{Kind: ast.NodeKindDirectiveDefinition, Ref: directiveDefinition})
+		}
+
+		v.Report.AddExternalError(operationreport.ErrArgumentNotDefinedOnDirective(argumentName, ancestorName, argumentPosition).
+			WithSuggestions(string(argumentName), argumentNames))
 	}
 }
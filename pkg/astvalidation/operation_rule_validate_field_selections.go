@@ -64,7 +64,8 @@ func (f *fieldDefined) ValidateInterfaceObjectTypeField(ref int, enclosingTypeDe
 		}
 	}
 
-	f.StopWithExternalErr(operationreport.ErrFieldUndefinedOnType(fieldName, typeName))
+	f.StopWithExternalErr(operationreport.ErrFieldUndefinedOnType(fieldName, typeName).
+		WithSuggestions(string(fieldName), f.definition.NodeFieldDefinitionNames(f.EnclosingTypeDefinition)))
 }
 
 func (f *fieldDefined) ValidateScalarField(ref int, enclosingTypeDefinition ast.Node) {
@@ -435,7 +435,9 @@ func (v *valuesVisitor) valueSatisfiesInputObjectTypeDefinition(value ast.Value,
 			objectFieldName := v.operation.ObjectFieldNameBytes(i)
 			def := v.definition.Input.ByteSlice(v.definition.InputObjectTypeDefinitions[inputObjectTypeDefinition].Name)
 
-			v.Report.AddExternalError(operationreport.ErrUnknownFieldOfInputObject(objectFieldName, def, v.operation.ObjectField(i).Position))
+			inputObjectTypeDefinitionNode := ast.Node{Kind: ast.NodeKindInputObjectTypeDefinition, Ref: inputObjectTypeDefinition}
+			v.Report.AddExternalError(operationreport.ErrUnknownFieldOfInputObject(objectFieldName, def, v.operation.ObjectField(i).Position).
+				WithSuggestions(string(objectFieldName), v.definition.NodeInputValueDefinitionNames(inputObjectTypeDefinitionNode)))
 			valid = false
 		}
 	}
@@ -32,7 +32,8 @@ func (v *variablesAreInputTypesVisitor) EnterVariableDefinition(ref int) {
 	typeName := v.operation.ResolveTypeNameBytes(v.operation.VariableDefinitions[ref].Type)
 	typeDefinitionNode, ok := v.definition.Index.FirstNodeByNameBytes(typeName)
 	if !ok {
-		v.Report.AddExternalError(operationreport.ErrUnknownType(typeName, v.operation.Types[v.operation.VariableDefinitions[ref].Type].Position))
+		v.Report.AddExternalError(operationreport.ErrUnknownType(typeName, v.operation.Types[v.operation.VariableDefinitions[ref].Type].Position).
+			WithSuggestions(string(typeName), v.definition.TypeDefinitionNames()))
 		return
 	}
 
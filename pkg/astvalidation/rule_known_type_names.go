@@ -44,7 +44,8 @@ func (u *knownTypeNamesVisitor) EnterDocument(operation, _ *ast.Document) {
 func (u *knownTypeNamesVisitor) LeaveDocument(_, _ *ast.Document) {
 	for referencedTypeNameHash, referencedTypeName := range u.referencedTypeNames {
 		if !u.definedTypeNameHashs[referencedTypeNameHash] {
-			u.Report.AddExternalError(operationreport.ErrTypeUndefined(referencedTypeName))
+			u.Report.AddExternalError(operationreport.ErrTypeUndefined(referencedTypeName).
+				WithSuggestions(string(referencedTypeName), u.definition.TypeDefinitionNames()))
 			continue
 		}
 	}
@@ -0,0 +1,204 @@
+package astvalidation
+
+import (
+	"strings"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astvisitor"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+const relayConnectionSuffix = "Connection"
+
+// RelayConnections validates the shape of the types and fields of the Relay cursor connections spec:
+// connection types (object types named *Connection) need edges and pageInfo: PageInfo!,
+// their edge types node and cursor, PageInfo the page flags and cursors, and fields returning a connection
+// the forward (first, after) and/or backward (last, before) pagination arguments.
+// The rule isn't part of DefaultDefinitionValidator as it's only meaningful for schemas following the spec.
+func RelayConnections() Rule {
+	return func(walker *astvisitor.Walker) {
+		visitor := &relayConnectionsVisitor{
+			Walker: walker,
+		}
+
+		walker.RegisterEnterDocumentVisitor(visitor)
+		walker.RegisterEnterObjectTypeDefinitionVisitor(visitor)
+		walker.RegisterEnterFieldDefinitionVisitor(visitor)
+	}
+}
+
+type relayConnectionsVisitor struct {
+	*astvisitor.Walker
+	definition *ast.Document
+}
+
+func (r *relayConnectionsVisitor) EnterDocument(operation, _ *ast.Document) {
+	r.definition = operation
+}
+
+func (r *relayConnectionsVisitor) EnterObjectTypeDefinition(ref int) {
+	typeName := r.definition.ObjectTypeDefinitionNameString(ref)
+	switch {
+	case typeName == "PageInfo":
+		r.validatePageInfo(ref)
+	case r.isConnectionType(typeName):
+		r.validateConnection(ref, typeName)
+	}
+}
+
+func (r *relayConnectionsVisitor) EnterFieldDefinition(ref int) {
+	if !r.isConnectionType(r.definition.ResolveTypeNameString(r.definition.FieldDefinitionType(ref))) {
+		return
+	}
+	if len(r.Ancestors) == 0 || r.Ancestors[len(r.Ancestors)-1].Kind != ast.NodeKindObjectTypeDefinition && r.Ancestors[len(r.Ancestors)-1].Kind != ast.NodeKindInterfaceTypeDefinition {
+		return
+	}
+	coordinate := r.definition.NodeNameString(r.Ancestors[len(r.Ancestors)-1]) + "." + r.definition.FieldDefinitionNameString(ref)
+
+	first, hasFirst := r.argumentType(ref, "first")
+	after, hasAfter := r.argumentType(ref, "after")
+	last, hasLast := r.argumentType(ref, "last")
+	before, hasBefore := r.argumentType(ref, "before")
+
+	switch {
+	case !hasFirst && !hasLast:
+		r.report(coordinate, "fields returning a connection must have the arguments first and after, last and before, or both")
+	case hasFirst != hasAfter:
+		r.report(coordinate, "the arguments first and after must be used together")
+	case hasLast != hasBefore:
+		r.report(coordinate, "the arguments last and before must be used together")
+	}
+	if hasFirst && !r.isNamedType(first, "Int") {
+		r.report(coordinate, "the argument first must be of type Int")
+	}
+	if hasLast && !r.isNamedType(last, "Int") {
+		r.report(coordinate, "the argument last must be of type Int")
+	}
+	if hasAfter && !r.isCursorType(after) {
+		r.report(coordinate, "the argument after must be of a cursor type, e.g. String")
+	}
+	if hasBefore && !r.isCursorType(before) {
+		r.report(coordinate, "the argument before must be of a cursor type, e.g. String")
+	}
+}
+
+func (r *relayConnectionsVisitor) validateConnection(ref int, typeName string) {
+	edges, ok := r.fieldType(ref, "edges")
+	if !ok {
+		r.report(typeName, "connection types must have the field edges")
+	} else if edgeType, ok := r.listItemTypeName(edges); !ok {
+		r.report(typeName+".edges", "the field edges must return a list of edge types")
+	} else if node, ok := r.definition.Index.FirstNonExtensionNodeByNameStr(edgeType); ok && node.Kind == ast.NodeKindObjectTypeDefinition {
+		r.validateEdge(node.Ref, edgeType)
+	} else {
+		r.report(typeName+".edges", "the field edges must return a list of object types")
+	}
+
+	pageInfo, ok := r.fieldType(ref, "pageInfo")
+	if !ok {
+		r.report(typeName, "connection types must have the field pageInfo")
+	} else if !r.definition.TypeIsNonNull(pageInfo) || !r.isNamedType(pageInfo, "PageInfo") {
+		r.report(typeName+".pageInfo", "the field pageInfo must return PageInfo!")
+	}
+}
+
+func (r *relayConnectionsVisitor) validateEdge(ref int, typeName string) {
+	node, ok := r.fieldType(ref, "node")
+	if !ok {
+		r.report(typeName, "edge types must have the field node")
+	} else if r.definition.TypeIsList(node) {
+		r.report(typeName+".node", "the field node must not return a list")
+	}
+
+	cursor, ok := r.fieldType(ref, "cursor")
+	if !ok {
+		r.report(typeName, "edge types must have the field cursor")
+	} else if !r.isCursorType(cursor) {
+		r.report(typeName+".cursor", "the field cursor must return a cursor type, e.g. String!")
+	}
+}
+
+func (r *relayConnectionsVisitor) validatePageInfo(ref int) {
+	for _, fieldName := range []string{"hasPreviousPage", "hasNextPage"} {
+		fieldType, ok := r.fieldType(ref, fieldName)
+		if !ok {
+			r.report("PageInfo", "PageInfo must have the field "+fieldName)
+		} else if !r.definition.TypeIsNonNull(fieldType) || !r.isNamedType(fieldType, "Boolean") {
+			r.report("PageInfo."+fieldName, "the field "+fieldName+" must return Boolean!")
+		}
+	}
+	for _, fieldName := range []string{"startCursor", "endCursor"} {
+		fieldType, ok := r.fieldType(ref, fieldName)
+		if !ok {
+			r.report("PageInfo", "PageInfo must have the field "+fieldName)
+		} else if !r.isCursorType(fieldType) {
+			r.report("PageInfo."+fieldName, "the field "+fieldName+" must return a cursor type, e.g. String")
+		}
+	}
+}
+
+func (r *relayConnectionsVisitor) report(coordinate, violation string) {
+	r.Report.AddExternalError(operationreport.ErrRelayConnectionSpecViolation(coordinate, violation))
+}
+
+// isConnectionType returns true for object types named *Connection, except the type Connection itself
+func (r *relayConnectionsVisitor) isConnectionType(typeName string) bool {
+	if len(typeName) <= len(relayConnectionSuffix) || !strings.HasSuffix(typeName, relayConnectionSuffix) {
+		return false
+	}
+	node, ok := r.definition.Index.FirstNonExtensionNodeByNameStr(typeName)
+	return ok && node.Kind == ast.NodeKindObjectTypeDefinition
+}
+
+func (r *relayConnectionsVisitor) fieldType(objectTypeDefinition int, fieldName string) (typeRef int, ok bool) {
+	for _, ref := range r.definition.ObjectTypeDefinitions[objectTypeDefinition].FieldsDefinition.Refs {
+		if r.definition.FieldDefinitionNameString(ref) == fieldName {
+			return r.definition.FieldDefinitionType(ref), true
+		}
+	}
+	return -1, false
+}
+
+func (r *relayConnectionsVisitor) argumentType(fieldDefinition int, argumentName string) (typeRef int, ok bool) {
+	for _, ref := range r.definition.FieldDefinitions[fieldDefinition].ArgumentsDefinition.Refs {
+		if r.definition.InputValueDefinitionNameString(ref) == argumentName {
+			return r.definition.InputValueDefinitionType(ref), true
+		}
+	}
+	return -1, false
+}
+
+// listItemTypeName returns the name of the item type of a (non-null) list of named types
+func (r *relayConnectionsVisitor) listItemTypeName(typeRef int) (string, bool) {
+	if r.definition.Types[typeRef].TypeKind == ast.TypeKindNonNull {
+		typeRef = r.definition.Types[typeRef].OfType
+	}
+	if r.definition.Types[typeRef].TypeKind != ast.TypeKindList {
+		return "", false
+	}
+	itemRef := r.definition.Types[typeRef].OfType
+	if r.definition.TypeIsList(itemRef) {
+		return "", false
+	}
+	return r.definition.ResolveTypeNameString(itemRef), true
+}
+
+func (r *relayConnectionsVisitor) isNamedType(typeRef int, typeName string) bool {
+	return !r.definition.TypeIsList(typeRef) && r.definition.ResolveTypeNameString(typeRef) == typeName
+}
+
+// isCursorType returns true for scalars which aren't lists, the spec requires cursors to serialize as strings
+func (r *relayConnectionsVisitor) isCursorType(typeRef int) bool {
+	if r.definition.TypeIsList(typeRef) {
+		return false
+	}
+	switch typeName := r.definition.ResolveTypeNameString(typeRef); typeName {
+	case "String", "ID":
+		return true
+	case "Int", "Float", "Boolean":
+		return false
+	default:
+		node, ok := r.definition.Index.FirstNonExtensionNodeByNameStr(typeName)
+		return ok && node.Kind == ast.NodeKindScalarTypeDefinition
+	}
+}
@@ -0,0 +1,197 @@
+package astvalidation
+
+import (
+	"testing"
+)
+
+func TestRelayConnections(t *testing.T) {
+	const pageInfo = `
+		type PageInfo {
+			hasPreviousPage: Boolean!
+			hasNextPage: Boolean!
+			startCursor: String
+			endCursor: String
+		}
+	`
+
+	t.Run("Definition", func(t *testing.T) {
+		t.Run("Connections following the spec are valid", func(t *testing.T) {
+			runDefinitionValidation(t, `
+					scalar Cursor
+					type Query {
+						users(first: Int, after: String): UserConnection!
+						friends(first: Int!, after: Cursor, last: Int, before: Cursor): UserConnection
+						user: User
+					}
+					type UserConnection {
+						edges: [UserEdge]
+						pageInfo: PageInfo!
+						totalCount: Int
+					}
+					type UserEdge {
+						node: User!
+						cursor: Cursor!
+					}
+					type User {
+						name: String
+					}
+					type Connection {
+						name: String
+					}
+				`+pageInfo, Valid, RelayConnections(),
+			)
+		})
+
+		t.Run("Connection without edges is invalid", func(t *testing.T) {
+			runDefinitionValidation(t, `
+					type Query {
+						users(first: Int, after: String): UserConnection
+					}
+					type UserConnection {
+						pageInfo: PageInfo!
+					}
+				`+pageInfo, Invalid, RelayConnections(),
+			)
+		})
+
+		t.Run("Connection with edges which aren't a list is invalid", func(t *testing.T) {
+			runDefinitionValidation(t, `
+					type Query {
+						users(first: Int, after: String): UserConnection
+					}
+					type UserConnection {
+						edges: UserEdge
+						pageInfo: PageInfo!
+					}
+					type UserEdge {
+						node: String
+						cursor: String!
+					}
+				`+pageInfo, Invalid, RelayConnections(),
+			)
+		})
+
+		t.Run("Connection with a nullable pageInfo is invalid", func(t *testing.T) {
+			runDefinitionValidation(t, `
+					type Query {
+						users(first: Int, after: String): UserConnection
+					}
+					type UserConnection {
+						edges: [UserEdge]
+						pageInfo: PageInfo
+					}
+					type UserEdge {
+						node: String
+						cursor: String!
+					}
+				`+pageInfo, Invalid, RelayConnections(),
+			)
+		})
+
+		t.Run("Edge without cursor is invalid", func(t *testing.T) {
+			runDefinitionValidation(t, `
+					type Query {
+						users(first: Int, after: String): UserConnection
+					}
+					type UserConnection {
+						edges: [UserEdge]
+						pageInfo: PageInfo!
+					}
+					type UserEdge {
+						node: String
+					}
+				`+pageInfo, Invalid, RelayConnections(),
+			)
+		})
+
+		t.Run("Edge with a list node is invalid", func(t *testing.T) {
+			runDefinitionValidation(t, `
+					type Query {
+						users(first: Int, after: String): UserConnection
+					}
+					type UserConnection {
+						edges: [UserEdge]
+						pageInfo: PageInfo!
+					}
+					type UserEdge {
+						node: [String]
+						cursor: String!
+					}
+				`+pageInfo, Invalid, RelayConnections(),
+			)
+		})
+
+		t.Run("PageInfo with nullable page flags is invalid", func(t *testing.T) {
+			runDefinitionValidation(t, `
+					type PageInfo {
+						hasPreviousPage: Boolean
+						hasNextPage: Boolean!
+						startCursor: String
+						endCursor: String
+					}
+				`, Invalid, RelayConnections(),
+			)
+		})
+
+		t.Run("PageInfo without cursors is invalid", func(t *testing.T) {
+			runDefinitionValidation(t, `
+					type PageInfo {
+						hasPreviousPage: Boolean!
+						hasNextPage: Boolean!
+					}
+				`, Invalid, RelayConnections(),
+			)
+		})
+
+		t.Run("Connection field without pagination arguments is invalid", func(t *testing.T) {
+			runDefinitionValidation(t, `
+					type Query {
+						users: UserConnection
+					}
+					type UserConnection {
+						edges: [UserEdge]
+						pageInfo: PageInfo!
+					}
+					type UserEdge {
+						node: String
+						cursor: String!
+					}
+				`+pageInfo, Invalid, RelayConnections(),
+			)
+		})
+
+		t.Run("Connection field with half an argument pair is invalid", func(t *testing.T) {
+			runDefinitionValidation(t, `
+					type Query {
+						users(first: Int, after: String, last: Int): UserConnection
+					}
+					type UserConnection {
+						edges: [UserEdge]
+						pageInfo: PageInfo!
+					}
+					type UserEdge {
+						node: String
+						cursor: String!
+					}
+				`+pageInfo, Invalid, RelayConnections(),
+			)
+		})
+
+		t.Run("Connection field with wrongly typed arguments is invalid", func(t *testing.T) {
+			runDefinitionValidation(t, `
+					type Query {
+						users(first: String, after: Int): UserConnection
+					}
+					type UserConnection {
+						edges: [UserEdge]
+						pageInfo: PageInfo!
+					}
+					type UserEdge {
+						node: String
+						cursor: String!
+					}
+				`+pageInfo, Invalid, RelayConnections(),
+			)
+		})
+	})
+}
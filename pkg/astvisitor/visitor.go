@@ -1379,8 +1379,10 @@ func (w *Walker) appendAncestor(ref int, kind ast.NodeKind) {
 			}
 		}
 		if typeName == nil {
-			typeName := w.definition.NodeNameBytes(w.typeDefinitions[len(w.typeDefinitions)-1])
-			w.StopWithExternalErr(operationreport.ErrFieldUndefinedOnType(fieldName, typeName))
+			enclosingTypeDefinition := w.typeDefinitions[len(w.typeDefinitions)-1]
+			typeName := w.definition.NodeNameBytes(enclosingTypeDefinition)
+			w.StopWithExternalErr(operationreport.ErrFieldUndefinedOnType(fieldName, typeName).
+				WithSuggestions(string(fieldName), w.definition.NodeFieldDefinitionNames(enclosingTypeDefinition)))
 			return
 		}
 	case ast.NodeKindObjectTypeDefinition, ast.NodeKindInterfaceTypeDefinition, ast.NodeKindUnionTypeDefinition:
@@ -1396,7 +1398,8 @@ func (w *Walker) appendAncestor(ref int, kind ast.NodeKind) {
 	var exists bool
 	w.EnclosingTypeDefinition, exists = w.definition.Index.FirstNonExtensionNodeByNameBytes(typeName)
 	if !exists {
-		w.StopWithExternalErr(operationreport.ErrTypeUndefined(typeName))
+		w.StopWithExternalErr(operationreport.ErrTypeUndefined(typeName).
+			WithSuggestions(string(typeName), w.definition.TypeDefinitionNames()))
 		return
 	}
 
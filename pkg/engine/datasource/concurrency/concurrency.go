@@ -0,0 +1,342 @@
+// Package concurrency limits the number of concurrent fetches to an upstream with a limit adapting to the observed
+// latency and errors of the upstream, so that the fetches back off once the upstream is overloaded
+// instead of piling up until they time out.
+// Fetches exceeding the limit wait in a queue for a bounded duration or are rejected with ErrLimitExceeded.
+package concurrency
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+)
+
+const (
+	defaultInitialLimit = 20
+	defaultMinLimit     = 1
+	defaultMaxLimit     = 200
+	defaultBackoffRatio = 0.9
+	defaultTolerance    = 1.5
+	defaultWindowSize   = 100
+	// gradientSmoothing is the weight of a new limit of the gradient algorithm, smoothing the limit over multiple samples
+	gradientSmoothing = 0.2
+)
+
+var ErrLimitExceeded = errors.New("concurrency limit of the upstream exceeded")
+
+// Algorithm decides how the limit adapts to the fetches
+type Algorithm string
+
+const (
+	// AlgorithmAIMD increases the limit additively by 1 per limit fetches and decreases it multiplicatively by the BackoffRatio
+	// once a fetch fails, is rejected by the upstream with 429 Too Many Requests or responds slower than the LatencyThreshold.
+	AlgorithmAIMD Algorithm = "aimd"
+	// AlgorithmGradient adjusts the limit to the gradient of the latency without load, the minimum latency of the previous window of fetches,
+	// and the latency of the fetches, so that the limit decreases as soon as the latency increases with the load.
+	// Like AlgorithmAIMD it decreases the limit by the BackoffRatio for failed fetches.
+	AlgorithmGradient Algorithm = "gradient"
+)
+
+// Configuration is the adaptive concurrency limit of an upstream
+type Configuration struct {
+	// Algorithm enables the limit, it's disabled if empty
+	Algorithm Algorithm
+	// Name identifies the limit, fetches of upstreams with the same name share the limit.
+	// The data sources default it to the URL of their fetch configuration.
+	Name string
+	// InitialLimit is the number of concurrent fetches before the limit adapted, it defaults to 20
+	InitialLimit int
+	// MinLimit is the lower bound of the limit, it defaults to 1
+	MinLimit int
+	// MaxLimit is the upper bound of the limit, it defaults to 200
+	MaxLimit int
+	// BackoffRatio is the ratio the limit is multiplied with to back off, between 0 and 1, it defaults to 0.9
+	BackoffRatio float64
+	// LatencyThreshold is the latency of AlgorithmAIMD above which a fetch backs off like a failed fetch, 0 only backs off for failures
+	LatencyThreshold time.Duration
+	// Tolerance is the factor of the latency without load which AlgorithmGradient tolerates before decreasing the limit, it defaults to 1.5
+	Tolerance float64
+	// WindowSize is the number of fetches of which AlgorithmGradient takes the minimum latency as latency without load, it defaults to 100
+	WindowSize int
+	// MaxQueueWait is the maximum duration a fetch waits for the limit, 0 rejects fetches exceeding the limit immediately
+	MaxQueueWait time.Duration
+}
+
+func (c *Configuration) IsEnabled() bool {
+	return c.Algorithm != ""
+}
+
+func (c *Configuration) applyDefaults() {
+	if c.MinLimit <= 0 {
+		c.MinLimit = defaultMinLimit
+	}
+	if c.MaxLimit <= 0 {
+		c.MaxLimit = defaultMaxLimit
+	}
+	if c.MaxLimit < c.MinLimit {
+		c.MaxLimit = c.MinLimit
+	}
+	if c.InitialLimit <= 0 {
+		c.InitialLimit = defaultInitialLimit
+	}
+	if c.InitialLimit < c.MinLimit {
+		c.InitialLimit = c.MinLimit
+	}
+	if c.InitialLimit > c.MaxLimit {
+		c.InitialLimit = c.MaxLimit
+	}
+	if c.BackoffRatio <= 0 || c.BackoffRatio >= 1 {
+		c.BackoffRatio = defaultBackoffRatio
+	}
+	if c.Tolerance < 1 {
+		c.Tolerance = defaultTolerance
+	}
+	if c.WindowSize <= 0 {
+		c.WindowSize = defaultWindowSize
+	}
+}
+
+// Stats is the current state of the limit of an upstream
+type Stats struct {
+	Limit    int
+	InFlight int
+	Queued   int
+	// Rejected is the number of fetches rejected with ErrLimitExceeded
+	Rejected uint64
+}
+
+// Limiters keeps track of the limits of the upstreams by their name.
+// It should be shared by all fetches of a datasource factory.
+type Limiters struct {
+	mu       sync.Mutex
+	limiters map[string]*limiter
+	now      func() time.Time
+}
+
+func NewLimiters() *Limiters {
+	return &Limiters{
+		limiters: map[string]*limiter{},
+		now:      time.Now,
+	}
+}
+
+// Upstream returns the limit of a fetch, it returns nil if the limit is disabled.
+// The limit of an upstream is created with the configuration of its first fetch.
+func (l *Limiters) Upstream(config Configuration) *Upstream {
+	if l == nil || !config.IsEnabled() {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	existing, ok := l.limiters[config.Name]
+	if !ok {
+		config.applyDefaults()
+		existing = newLimiter(config)
+		l.limiters[config.Name] = existing
+	}
+	return &Upstream{
+		limiters: l,
+		limiter:  existing,
+	}
+}
+
+// Stats returns the stats of the limits by the names of the upstreams
+func (l *Limiters) Stats() map[string]Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	stats := make(map[string]Stats, len(l.limiters))
+	for name, limiter := range l.limiters {
+		stats[name] = limiter.stats()
+	}
+	return stats
+}
+
+// Upstream is the limit of a single fetch
+type Upstream struct {
+	limiters *Limiters
+	limiter  *limiter
+}
+
+// Load sends the fetch once the number of concurrent fetches of the upstream is below its limit,
+// and adapts the limit to the latency and the outcome of the fetch.
+// It returns ErrLimitExceeded if the fetch exceeded the limit for longer than the MaxQueueWait.
+// It loads the input as is if the upstream has no limit.
+func (u *Upstream) Load(ctx context.Context, input []byte, out io.Writer, load httpclient.LoadFunc) (statusCode int, err error) {
+	if u == nil {
+		return load(ctx, input, out)
+	}
+	if err = u.limiter.acquire(ctx); err != nil {
+		return 0, err
+	}
+	start := u.limiters.now()
+	statusCode, err = load(ctx, input, out)
+	latency := u.limiters.now().Sub(start)
+
+	switch {
+	case ctx.Err() != nil:
+		// cancelled fetches don't tell anything about the upstream
+		u.limiter.release(outcomeIgnored, latency)
+	case err != nil || statusCode >= http.StatusInternalServerError || statusCode == http.StatusTooManyRequests:
+		u.limiter.release(outcomeDropped, latency)
+	default:
+		u.limiter.release(outcomeSucceeded, latency)
+	}
+	return statusCode, err
+}
+
+type outcome int
+
+const (
+	outcomeSucceeded outcome = iota
+	outcomeDropped
+	outcomeIgnored
+)
+
+type limiter struct {
+	mu       sync.Mutex
+	config   Configuration
+	limit    float64
+	inFlight int
+	// waiters are the fetches waiting for the limit in their order, a slot is granted by sending to the channel
+	waiters  []chan struct{}
+	rejected uint64
+
+	// the latency without load of AlgorithmGradient
+	noLoadLatency time.Duration
+	windowMin     time.Duration
+	windowCount   int
+}
+
+func newLimiter(config Configuration) *limiter {
+	return &limiter{
+		config: config,
+		limit:  float64(config.InitialLimit),
+	}
+}
+
+func (l *limiter) acquire(ctx context.Context) error {
+	l.mu.Lock()
+	if l.inFlight < int(l.limit) {
+		l.inFlight++
+		l.mu.Unlock()
+		return nil
+	}
+	if l.config.MaxQueueWait <= 0 {
+		l.rejected++
+		l.mu.Unlock()
+		return ErrLimitExceeded
+	}
+	granted := make(chan struct{}, 1)
+	l.waiters = append(l.waiters, granted)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.config.MaxQueueWait)
+	defer timer.Stop()
+
+	var err error
+	select {
+	case <-granted:
+		return nil
+	case <-timer.C:
+		err = ErrLimitExceeded
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i := range l.waiters {
+		if l.waiters[i] == granted {
+			l.waiters = append(l.waiters[:i], l.waiters[i+1:]...)
+			if err == ErrLimitExceeded {
+				l.rejected++
+			}
+			return err
+		}
+	}
+	// the slot was granted concurrently to the timeout, it's passed on to the next waiter
+	l.inFlight--
+	l.grant()
+	return err
+}
+
+func (l *limiter) release(result outcome, latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	// the limit only increases if it's utilized, otherwise it would grow without bounds while the upstream isn't loaded
+	utilized := float64(l.inFlight)*2 >= l.limit
+	l.inFlight--
+
+	switch result {
+	case outcomeSucceeded:
+		l.succeeded(latency, utilized)
+	case outcomeDropped:
+		l.limit *= l.config.BackoffRatio
+	}
+	l.limit = math.Max(float64(l.config.MinLimit), math.Min(float64(l.config.MaxLimit), l.limit))
+	l.grant()
+}
+
+func (l *limiter) succeeded(latency time.Duration, utilized bool) {
+	switch l.config.Algorithm {
+	case AlgorithmGradient:
+		l.sampleLatency(latency)
+		if l.noLoadLatency == 0 || latency <= 0 {
+			return
+		}
+		gradient := math.Max(0.5, math.Min(1, l.config.Tolerance*float64(l.noLoadLatency)/float64(latency)))
+		// the square root of the limit is the queue tolerated on top of the limit at the latency without load
+		newLimit := l.limit*gradient + math.Sqrt(l.limit)
+		if newLimit > l.limit && !utilized {
+			return
+		}
+		l.limit = l.limit*(1-gradientSmoothing) + newLimit*gradientSmoothing
+	default:
+		if l.config.LatencyThreshold > 0 && latency > l.config.LatencyThreshold {
+			l.limit *= l.config.BackoffRatio
+			return
+		}
+		if utilized {
+			l.limit += 1 / l.limit
+		}
+	}
+}
+
+// sampleLatency tracks the minimum latency of the current window, the minimum of the previous window is the latency without load
+func (l *limiter) sampleLatency(latency time.Duration) {
+	if l.windowCount == 0 || latency < l.windowMin {
+		l.windowMin = latency
+	}
+	l.windowCount++
+	if l.noLoadLatency == 0 || l.windowCount >= l.config.WindowSize {
+		l.noLoadLatency = l.windowMin
+	}
+	if l.windowCount >= l.config.WindowSize {
+		l.windowCount = 0
+	}
+}
+
+// grant passes the free slots to the waiting fetches
+func (l *limiter) grant() {
+	for len(l.waiters) != 0 && l.inFlight < int(l.limit) {
+		l.inFlight++
+		l.waiters[0] <- struct{}{}
+		l.waiters = l.waiters[1:]
+	}
+}
+
+func (l *limiter) stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return Stats{
+		Limit:    int(l.limit),
+		InFlight: l.inFlight,
+		Queued:   len(l.waiters),
+		Rejected: l.rejected,
+	}
+}
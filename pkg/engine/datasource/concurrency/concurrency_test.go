@@ -0,0 +1,241 @@
+package concurrency
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+)
+
+func TestLimiters_Upstream(t *testing.T) {
+	limiters := NewLimiters()
+	assert.Nil(t, limiters.Upstream(Configuration{Name: "users"}))
+	assert.Nil(t, (*Limiters)(nil).Upstream(Configuration{Algorithm: AlgorithmAIMD, Name: "users"}))
+
+	users := limiters.Upstream(Configuration{Algorithm: AlgorithmAIMD, Name: "users", InitialLimit: 5})
+	require.NotNil(t, users)
+	assert.Same(t, users.limiter, limiters.Upstream(Configuration{Algorithm: AlgorithmAIMD, Name: "users"}).limiter)
+	assert.NotSame(t, users.limiter, limiters.Upstream(Configuration{Algorithm: AlgorithmAIMD, Name: "products"}).limiter)
+	assert.Equal(t, map[string]Stats{"users": {Limit: 5}, "products": {Limit: defaultInitialLimit}}, limiters.Stats())
+}
+
+func TestUpstream_Load(t *testing.T) {
+	const input = `{"method":"POST","url":"https://service/graphql","body":{"query":"{me}"}}`
+
+	respond := func(statusCode int, err error) httpclient.LoadFunc {
+		return func(ctx context.Context, input []byte, out io.Writer) (int, error) {
+			_, _ = out.Write([]byte("response"))
+			return statusCode, err
+		}
+	}
+
+	// block returns a LoadFunc responding once unblocked, started receives a value once the fetch is sent
+	block := func() (load httpclient.LoadFunc, started chan struct{}, unblock chan struct{}) {
+		started, unblock = make(chan struct{}, 10), make(chan struct{})
+		return func(ctx context.Context, input []byte, out io.Writer) (int, error) {
+			started <- struct{}{}
+			<-unblock
+			return http.StatusOK, nil
+		}, started, unblock
+	}
+
+	t.Run("loads the input as is without limit", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		statusCode, err := (*Upstream)(nil).Load(context.Background(), []byte(input), out, respond(http.StatusOK, nil))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, "response", out.String())
+	})
+
+	t.Run("rejects fetches exceeding the limit", func(t *testing.T) {
+		limiters := NewLimiters()
+		upstream := limiters.Upstream(Configuration{Algorithm: AlgorithmAIMD, Name: "users", InitialLimit: 1})
+
+		load, started, unblock := block()
+		done := make(chan error)
+		go func() {
+			_, err := upstream.Load(context.Background(), []byte(input), io.Discard, load)
+			done <- err
+		}()
+		<-started
+
+		_, err := upstream.Load(context.Background(), []byte(input), io.Discard, respond(http.StatusOK, nil))
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+		assert.Equal(t, Stats{Limit: 1, InFlight: 1, Rejected: 1}, limiters.Stats()["users"])
+
+		close(unblock)
+		require.NoError(t, <-done)
+		assert.Equal(t, Stats{Limit: 2, Rejected: 1}, limiters.Stats()["users"], "the utilized limit increased")
+	})
+
+	t.Run("queued fetches wait for a free slot", func(t *testing.T) {
+		limiters := NewLimiters()
+		upstream := limiters.Upstream(Configuration{Algorithm: AlgorithmAIMD, Name: "users", InitialLimit: 1, MaxQueueWait: time.Second})
+
+		load, started, unblock := block()
+		go func() {
+			_, _ = upstream.Load(context.Background(), []byte(input), io.Discard, load)
+		}()
+		<-started
+
+		queued := make(chan error)
+		go func() {
+			_, err := upstream.Load(context.Background(), []byte(input), io.Discard, respond(http.StatusOK, nil))
+			queued <- err
+		}()
+		assert.Eventually(t, func() bool {
+			return limiters.Stats()["users"].Queued == 1
+		}, time.Second, time.Millisecond)
+
+		close(unblock)
+		require.NoError(t, <-queued)
+		assert.Equal(t, 0, limiters.Stats()["users"].InFlight)
+	})
+
+	t.Run("queued fetches are rejected after the max queue wait", func(t *testing.T) {
+		limiters := NewLimiters()
+		upstream := limiters.Upstream(Configuration{Algorithm: AlgorithmAIMD, Name: "users", InitialLimit: 1, MaxQueueWait: 10 * time.Millisecond})
+
+		load, started, unblock := block()
+		defer close(unblock)
+		go func() {
+			_, _ = upstream.Load(context.Background(), []byte(input), io.Discard, load)
+		}()
+		<-started
+
+		_, err := upstream.Load(context.Background(), []byte(input), io.Discard, respond(http.StatusOK, nil))
+		assert.ErrorIs(t, err, ErrLimitExceeded)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = upstream.Load(ctx, []byte(input), io.Discard, respond(http.StatusOK, nil))
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, Stats{Limit: 1, InFlight: 1, Rejected: 1}, limiters.Stats()["users"])
+	})
+
+	t.Run("failures back off", func(t *testing.T) {
+		limiters := NewLimiters()
+		upstream := limiters.Upstream(Configuration{Algorithm: AlgorithmAIMD, Name: "users", InitialLimit: 10, BackoffRatio: 0.5})
+
+		statusCode, err := upstream.Load(context.Background(), []byte(input), io.Discard, respond(http.StatusServiceUnavailable, nil))
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+		assert.Equal(t, 5, limiters.Stats()["users"].Limit)
+
+		_, err = upstream.Load(context.Background(), []byte(input), io.Discard, respond(0, errors.New("connection refused")))
+		assert.EqualError(t, err, "connection refused")
+		assert.Equal(t, 2, limiters.Stats()["users"].Limit)
+
+		_, _ = upstream.Load(context.Background(), []byte(input), io.Discard, respond(http.StatusTooManyRequests, nil))
+		assert.Equal(t, 1, limiters.Stats()["users"].Limit, "the limit doesn't fall below the min limit")
+
+		_, _ = upstream.Load(context.Background(), []byte(input), io.Discard, respond(http.StatusNotFound, nil))
+		assert.Equal(t, 2, limiters.Stats()["users"].Limit, "client errors don't back off")
+	})
+}
+
+func TestLimiter_AIMD(t *testing.T) {
+	aimdLimiter := func(config Configuration) *limiter {
+		config.Algorithm = AlgorithmAIMD
+		config.applyDefaults()
+		return newLimiter(config)
+	}
+	// fetch samples a fetch with the given number of fetches in flight
+	fetch := func(l *limiter, inFlight int, result outcome, latency time.Duration) {
+		l.inFlight = inFlight
+		l.release(result, latency)
+	}
+
+	t.Run("increases the limit by 1 per limit utilized fetches", func(t *testing.T) {
+		l := aimdLimiter(Configuration{InitialLimit: 4})
+		for i := 0; i < 4; i++ {
+			fetch(l, 4, outcomeSucceeded, time.Millisecond)
+		}
+		assert.InDelta(t, 5, l.limit, 0.1)
+	})
+
+	t.Run("doesn't increase the limit if it isn't utilized", func(t *testing.T) {
+		l := aimdLimiter(Configuration{InitialLimit: 10})
+		for i := 0; i < 100; i++ {
+			fetch(l, 1, outcomeSucceeded, time.Millisecond)
+		}
+		assert.Equal(t, 10.0, l.limit)
+	})
+
+	t.Run("doesn't exceed the max limit", func(t *testing.T) {
+		l := aimdLimiter(Configuration{InitialLimit: 2, MaxLimit: 3})
+		for i := 0; i < 100; i++ {
+			fetch(l, 3, outcomeSucceeded, time.Millisecond)
+		}
+		assert.Equal(t, 3.0, l.limit)
+	})
+
+	t.Run("slow fetches back off", func(t *testing.T) {
+		l := aimdLimiter(Configuration{InitialLimit: 10, LatencyThreshold: 100 * time.Millisecond})
+		fetch(l, 10, outcomeSucceeded, 200*time.Millisecond)
+		assert.Equal(t, 9.0, l.limit)
+	})
+
+	t.Run("ignored fetches don't change the limit", func(t *testing.T) {
+		l := aimdLimiter(Configuration{InitialLimit: 10})
+		fetch(l, 10, outcomeIgnored, time.Millisecond)
+		assert.Equal(t, 10.0, l.limit)
+	})
+}
+
+func TestLimiter_Gradient(t *testing.T) {
+	gradientLimiter := func(config Configuration) *limiter {
+		config.Algorithm = AlgorithmGradient
+		config.applyDefaults()
+		return newLimiter(config)
+	}
+	fetch := func(l *limiter, inFlight int, result outcome, latency time.Duration) {
+		l.inFlight = inFlight
+		l.release(result, latency)
+	}
+
+	t.Run("increases the limit while the latency doesn't increase", func(t *testing.T) {
+		l := gradientLimiter(Configuration{InitialLimit: 10})
+		for i := 0; i < 10; i++ {
+			fetch(l, int(l.limit), outcomeSucceeded, 10*time.Millisecond)
+		}
+		assert.Greater(t, l.limit, 15.0)
+	})
+
+	t.Run("decreases the limit once the latency increases", func(t *testing.T) {
+		l := gradientLimiter(Configuration{InitialLimit: 50})
+		fetch(l, 50, outcomeSucceeded, 10*time.Millisecond)
+		limit := l.limit
+		for i := 0; i < 10; i++ {
+			fetch(l, int(l.limit), outcomeSucceeded, 100*time.Millisecond)
+		}
+		assert.Less(t, l.limit, limit)
+	})
+
+	t.Run("the latency without load is the minimum of the previous window", func(t *testing.T) {
+		l := gradientLimiter(Configuration{WindowSize: 3})
+		fetch(l, 1, outcomeSucceeded, 20*time.Millisecond)
+		assert.Equal(t, 20*time.Millisecond, l.noLoadLatency)
+		fetch(l, 1, outcomeSucceeded, 10*time.Millisecond)
+		fetch(l, 1, outcomeSucceeded, 30*time.Millisecond)
+		assert.Equal(t, 10*time.Millisecond, l.noLoadLatency)
+		for i := 0; i < 3; i++ {
+			fetch(l, 1, outcomeSucceeded, 40*time.Millisecond)
+		}
+		assert.Equal(t, 40*time.Millisecond, l.noLoadLatency)
+	})
+
+	t.Run("failures back off", func(t *testing.T) {
+		l := gradientLimiter(Configuration{InitialLimit: 10})
+		fetch(l, 10, outcomeDropped, 10*time.Millisecond)
+		assert.Equal(t, 9.0, l.limit)
+	})
+}
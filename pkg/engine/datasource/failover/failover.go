@@ -0,0 +1,279 @@
+// Package failover sends the fetches of datasources with multiple endpoints to one of the endpoints,
+// chosen by a load balancing strategy, and fails over to the next endpoint if a fetch fails.
+// Each endpoint has a circuit breaker, which opens after consecutive failures,
+// so that fetches skip the endpoint until the circuit closes again.
+package failover
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+)
+
+const (
+	defaultFailureThreshold = 3
+	defaultOpenDuration     = 30 * time.Second
+)
+
+var (
+	ErrAllCircuitsOpen = errors.New("circuits of all endpoints are open")
+	// ErrCombinedWithReplication is returned by datasources configured with failover and replication,
+	// as both replace scheme and host of the fetch URL and failover would override the replica chosen by replication
+	ErrCombinedWithReplication = errors.New("failover can't be combined with replication")
+)
+
+// Strategy decides the order in which the endpoints are tried
+type Strategy string
+
+const (
+	// StrategyPriority tries the endpoints in the configured order, the first endpoint is the primary
+	StrategyPriority Strategy = "priority"
+	// StrategyRoundRobin starts at the next endpoint for each fetch
+	StrategyRoundRobin Strategy = "round_robin"
+	// StrategyWeighted starts at the endpoints proportionally to their weights
+	StrategyWeighted Strategy = "weighted"
+)
+
+// Endpoint is a base URL of an upstream, e.g. https://eu.example.com,
+// of which scheme and host replace the ones of the fetch URL.
+type Endpoint struct {
+	URL string
+	// Weight is the relative share of the fetches of StrategyWeighted, it defaults to 1
+	Weight int
+}
+
+// Configuration is the failover configuration of an upstream
+type Configuration struct {
+	Endpoints []Endpoint
+	// Strategy defaults to StrategyPriority
+	Strategy Strategy
+	// FailureThreshold is the number of consecutive failures opening the circuit of an endpoint, it defaults to 3
+	FailureThreshold int
+	// OpenDuration is the duration an open circuit skips its endpoint, it defaults to 30 seconds.
+	// Afterwards the endpoint is tried again and the circuit opens again on the next failure.
+	OpenDuration time.Duration
+}
+
+func (c *Configuration) IsEnabled() bool {
+	return len(c.Endpoints) != 0
+}
+
+type circuit struct {
+	failures  int
+	openUntil time.Time
+}
+
+// Balancer chooses the endpoints of fetches and keeps track of the circuits of the endpoints.
+// It should be shared by all fetches of a datasource factory.
+type Balancer struct {
+	mu       sync.Mutex
+	circuits map[string]*circuit
+	next     map[string]int
+	now      func() time.Time
+}
+
+func NewBalancer() *Balancer {
+	return &Balancer{
+		circuits: map[string]*circuit{},
+		next:     map[string]int{},
+		now:      time.Now,
+	}
+}
+
+// Upstream returns the failover of a fetch, it returns nil if the upstream has no endpoints.
+// idempotent is false for mutations and all fetches of a mutation, they only fail over if the request never reached the endpoint, see Upstream.Load.
+func (b *Balancer) Upstream(config Configuration, idempotent bool) *Upstream {
+	if b == nil || !config.IsEnabled() {
+		return nil
+	}
+	if config.Strategy == "" {
+		config.Strategy = StrategyPriority
+	}
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = defaultFailureThreshold
+	}
+	if config.OpenDuration <= 0 {
+		config.OpenDuration = defaultOpenDuration
+	}
+	return &Upstream{
+		balancer:   b,
+		config:     config,
+		idempotent: idempotent,
+	}
+}
+
+// Endpoints returns the URLs of the endpoints in the order a fetch tries them, skipping endpoints with open circuits
+func (b *Balancer) Endpoints(config Configuration) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ordered := b.order(config)
+	now := b.now()
+	available := ordered[:0]
+	for _, endpoint := range ordered {
+		if state, ok := b.circuits[endpoint]; ok && now.Before(state.openUntil) {
+			continue
+		}
+		available = append(available, endpoint)
+	}
+	return available
+}
+
+func (b *Balancer) order(config Configuration) []string {
+	endpoints := make([]string, len(config.Endpoints))
+	for i := range config.Endpoints {
+		endpoints[i] = config.Endpoints[i].URL
+	}
+
+	var start int
+	switch config.Strategy {
+	case StrategyRoundRobin:
+		key := strings.Join(endpoints, ",")
+		start = b.next[key] % len(endpoints)
+		b.next[key] = start + 1
+	case StrategyWeighted:
+		key := strings.Join(endpoints, ",")
+		totalWeight := 0
+		for i := range config.Endpoints {
+			totalWeight += weight(config.Endpoints[i])
+		}
+		position := b.next[key] % totalWeight
+		b.next[key] = position + 1
+		for i := range config.Endpoints {
+			position -= weight(config.Endpoints[i])
+			if position < 0 {
+				start = i
+				break
+			}
+		}
+	}
+	return append(endpoints[start:], endpoints[:start]...)
+}
+
+func weight(endpoint Endpoint) int {
+	if endpoint.Weight <= 0 {
+		return 1
+	}
+	return endpoint.Weight
+}
+
+// RecordSuccess closes the circuit of the endpoint
+func (b *Balancer) RecordSuccess(endpoint string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.circuits, endpoint)
+}
+
+// RecordFailure opens the circuit of the endpoint once the failure threshold is reached
+func (b *Balancer) RecordFailure(config Configuration, endpoint string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	state, ok := b.circuits[endpoint]
+	if !ok {
+		state = &circuit{}
+		b.circuits[endpoint] = state
+	}
+	state.failures++
+	if state.failures >= config.FailureThreshold {
+		state.openUntil = b.now().Add(config.OpenDuration)
+	}
+}
+
+// Upstream is the failover of a single fetch of an upstream with multiple endpoints
+type Upstream struct {
+	balancer   *Balancer
+	config     Configuration
+	idempotent bool
+}
+
+// Load sends the fetch to the endpoints, replacing scheme and host of the URL of the httpclient input,
+// until an endpoint responds without error and without a 5xx status code.
+// A 5xx response of the last available endpoint is written as is.
+// Fetches which aren't idempotent only fail over if dialing the endpoint failed, so that e.g. a mutation isn't executed twice,
+// otherwise the failure is returned and a 5xx response is written as is.
+// It loads the input as is if the upstream has no endpoints.
+func (u *Upstream) Load(ctx context.Context, input []byte, out io.Writer, load httpclient.LoadFunc) error {
+	if u == nil {
+		_, err := load(ctx, input, out)
+		return err
+	}
+	fetchURL, err := jsonparser.GetString(input, httpclient.URL)
+	if err != nil {
+		_, err = load(ctx, input, out)
+		return err
+	}
+
+	endpoints := u.balancer.Endpoints(u.config)
+	if len(endpoints) == 0 {
+		return ErrAllCircuitsOpen
+	}
+
+	var response bytes.Buffer
+	for i, endpoint := range endpoints {
+		endpointInput, err := routeInput(input, fetchURL, endpoint)
+		if err != nil {
+			return err
+		}
+		response.Reset()
+		statusCode, err := load(ctx, endpointInput, &response)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil && statusCode < http.StatusInternalServerError {
+			u.balancer.RecordSuccess(endpoint)
+			_, err = out.Write(response.Bytes())
+			return err
+		}
+		u.balancer.RecordFailure(u.config, endpoint)
+		if i == len(endpoints)-1 || !u.retryable(err) {
+			if err != nil {
+				return err
+			}
+			_, err = out.Write(response.Bytes())
+			return err
+		}
+	}
+	return nil
+}
+
+// retryable returns true if the failed attempt may be sent to the next endpoint.
+// Attempts of fetches which aren't idempotent are only retried if they provably never reached the endpoint.
+func (u *Upstream) retryable(err error) bool {
+	if u.idempotent {
+		return true
+	}
+	var opError *net.OpError
+	return errors.As(err, &opError) && opError.Op == "dial"
+}
+
+func routeInput(input []byte, fetchURL, endpoint string) ([]byte, error) {
+	routed, err := url.Parse(fetchURL)
+	if err != nil {
+		return nil, err
+	}
+	endpointURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid endpoint url %s: %w", endpoint, err)
+	}
+	routed.Scheme = endpointURL.Scheme
+	routed.Host = endpointURL.Host
+	value, err := json.Marshal(routed.String())
+	if err != nil {
+		return nil, err
+	}
+	return jsonparser.Set(append([]byte(nil), input...), value, httpclient.URL)
+}
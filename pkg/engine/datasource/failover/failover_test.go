@@ -0,0 +1,221 @@
+package failover
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+)
+
+func TestBalancer_Endpoints(t *testing.T) {
+	endpoints := []Endpoint{
+		{URL: "https://a.service", Weight: 2},
+		{URL: "https://b.service"},
+		{URL: "https://c.service"},
+	}
+
+	t.Run("priority", func(t *testing.T) {
+		balancer := NewBalancer()
+		upstream := balancer.Upstream(Configuration{Endpoints: endpoints}, true)
+		assert.Equal(t, []string{"https://a.service", "https://b.service", "https://c.service"}, balancer.Endpoints(upstream.config))
+		assert.Equal(t, []string{"https://a.service", "https://b.service", "https://c.service"}, balancer.Endpoints(upstream.config))
+	})
+
+	t.Run("round robin", func(t *testing.T) {
+		balancer := NewBalancer()
+		upstream := balancer.Upstream(Configuration{Endpoints: endpoints, Strategy: StrategyRoundRobin}, true)
+		assert.Equal(t, []string{"https://a.service", "https://b.service", "https://c.service"}, balancer.Endpoints(upstream.config))
+		assert.Equal(t, []string{"https://b.service", "https://c.service", "https://a.service"}, balancer.Endpoints(upstream.config))
+		assert.Equal(t, []string{"https://c.service", "https://a.service", "https://b.service"}, balancer.Endpoints(upstream.config))
+		assert.Equal(t, []string{"https://a.service", "https://b.service", "https://c.service"}, balancer.Endpoints(upstream.config))
+	})
+
+	t.Run("weighted", func(t *testing.T) {
+		balancer := NewBalancer()
+		upstream := balancer.Upstream(Configuration{Endpoints: endpoints, Strategy: StrategyWeighted}, true)
+		var first []string
+		for i := 0; i < 8; i++ {
+			first = append(first, balancer.Endpoints(upstream.config)[0])
+		}
+		assert.Equal(t, []string{
+			"https://a.service", "https://a.service", "https://b.service", "https://c.service",
+			"https://a.service", "https://a.service", "https://b.service", "https://c.service",
+		}, first)
+	})
+
+	t.Run("not enabled", func(t *testing.T) {
+		assert.Nil(t, NewBalancer().Upstream(Configuration{}, true))
+	})
+}
+
+func TestUpstream_Load(t *testing.T) {
+	const input = `{"method":"POST","url":"https://primary.service/graphql?a=b","body":{"query":"{me}"}}`
+
+	type response struct {
+		statusCode int
+		body       string
+		err        error
+	}
+
+	newUpstreamWithIdempotency := func(strategy Strategy, idempotent bool) (*Upstream, *time.Time) {
+		balancer := NewBalancer()
+		now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		balancer.now = func() time.Time {
+			return now
+		}
+		upstream := balancer.Upstream(Configuration{
+			Endpoints:        []Endpoint{{URL: "https://a.service"}, {URL: "http://b.service:8080"}},
+			Strategy:         strategy,
+			FailureThreshold: 2,
+			OpenDuration:     time.Minute,
+		}, idempotent)
+		return upstream, &now
+	}
+	newUpstream := func(strategy Strategy) (*Upstream, *time.Time) {
+		return newUpstreamWithIdempotency(strategy, true)
+	}
+
+	load := func(t *testing.T, upstream *Upstream, responses map[string]response) (hosts []string, out string, err error) {
+		t.Helper()
+		buf := &bytes.Buffer{}
+		err = upstream.Load(context.Background(), []byte(input), buf, func(ctx context.Context, input []byte, out io.Writer) (int, error) {
+			fetchURL, err := jsonparser.GetString(input, httpclient.URL)
+			require.NoError(t, err)
+			parsed, err := url.Parse(fetchURL)
+			require.NoError(t, err)
+			assert.Equal(t, "/graphql", parsed.Path)
+			assert.Equal(t, "a=b", parsed.RawQuery)
+			hosts = append(hosts, parsed.Host)
+			res := responses[parsed.Host]
+			_, _ = out.Write([]byte(res.body))
+			return res.statusCode, res.err
+		})
+		return hosts, buf.String(), err
+	}
+
+	healthy := map[string]response{
+		"a.service":      {statusCode: 200, body: "a"},
+		"b.service:8080": {statusCode: 200, body: "b"},
+	}
+	primaryDown := map[string]response{
+		"a.service":      {err: errors.New("connection refused")},
+		"b.service:8080": {statusCode: 200, body: "b"},
+	}
+
+	t.Run("sends the fetch to the primary", func(t *testing.T) {
+		upstream, _ := newUpstream(StrategyPriority)
+		hosts, out, err := load(t, upstream, healthy)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.service"}, hosts)
+		assert.Equal(t, "a", out)
+	})
+
+	t.Run("fails over on errors and 5xx responses", func(t *testing.T) {
+		upstream, _ := newUpstream(StrategyPriority)
+		hosts, out, err := load(t, upstream, primaryDown)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.service", "b.service:8080"}, hosts)
+		assert.Equal(t, "b", out)
+
+		hosts, out, err = load(t, upstream, map[string]response{
+			"a.service":      {statusCode: 503, body: "unavailable"},
+			"b.service:8080": {statusCode: 200, body: "b"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.service", "b.service:8080"}, hosts)
+		assert.Equal(t, "b", out, "responses of failed endpoints are discarded")
+	})
+
+	t.Run("writes the 5xx response of the last endpoint", func(t *testing.T) {
+		upstream, _ := newUpstream(StrategyPriority)
+		_, out, err := load(t, upstream, map[string]response{
+			"a.service":      {statusCode: 500, body: "a"},
+			"b.service:8080": {statusCode: 502, body: "b"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "b", out)
+	})
+
+	t.Run("fetches which aren't idempotent only fail over on dial errors", func(t *testing.T) {
+		upstream, _ := newUpstreamWithIdempotency(StrategyPriority, false)
+		hosts, out, err := load(t, upstream, map[string]response{
+			"a.service":      {statusCode: 503, body: "unavailable"},
+			"b.service:8080": {statusCode: 200, body: "b"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.service"}, hosts)
+		assert.Equal(t, "unavailable", out)
+
+		upstream, _ = newUpstreamWithIdempotency(StrategyPriority, false)
+		hosts, _, err = load(t, upstream, primaryDown)
+		assert.EqualError(t, err, "connection refused")
+		assert.Equal(t, []string{"a.service"}, hosts, "the request might have reached the endpoint")
+
+		upstream, _ = newUpstreamWithIdempotency(StrategyPriority, false)
+		hosts, out, err = load(t, upstream, map[string]response{
+			"a.service":      {err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}},
+			"b.service:8080": {statusCode: 200, body: "b"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.service", "b.service:8080"}, hosts)
+		assert.Equal(t, "b", out)
+	})
+
+	t.Run("circuit opens after consecutive failures", func(t *testing.T) {
+		upstream, now := newUpstream(StrategyPriority)
+		load(t, upstream, primaryDown)
+		load(t, upstream, primaryDown)
+
+		hosts, _, err := load(t, upstream, healthy)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b.service:8080"}, hosts, "the circuit of the primary is open")
+
+		*now = now.Add(time.Minute)
+		hosts, _, err = load(t, upstream, primaryDown)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.service", "b.service:8080"}, hosts, "the primary is tried again after the open duration")
+
+		hosts, _, err = load(t, upstream, healthy)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"b.service:8080"}, hosts, "the circuit opens again on the next failure")
+
+		*now = now.Add(time.Minute)
+		load(t, upstream, healthy)
+		hosts, _, err = load(t, upstream, healthy)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"a.service"}, hosts, "a success closes the circuit")
+	})
+
+	t.Run("all circuits open", func(t *testing.T) {
+		upstream, _ := newUpstream(StrategyRoundRobin)
+		down := map[string]response{
+			"a.service":      {err: errors.New("connection refused")},
+			"b.service:8080": {err: errors.New("connection refused")},
+		}
+		_, _, err := load(t, upstream, down)
+		assert.EqualError(t, err, "connection refused")
+		load(t, upstream, down)
+
+		hosts, _, err := load(t, upstream, healthy)
+		assert.ErrorIs(t, err, ErrAllCircuitsOpen)
+		assert.Empty(t, hosts)
+	})
+
+	t.Run("without endpoints the input is loaded as is", func(t *testing.T) {
+		var upstream *Upstream
+		hosts, out, err := load(t, upstream, map[string]response{"primary.service": {statusCode: 200, body: "primary"}})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"primary.service"}, hosts)
+		assert.Equal(t, "primary", out)
+	})
+}
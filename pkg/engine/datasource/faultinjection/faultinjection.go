@@ -0,0 +1,209 @@
+// Package faultinjection injects faults into the requests of data sources to their upstreams,
+// e.g. latency, errors, malformed responses and connection resets, to test the resilience of the gateway,
+// like the timeouts, failover and circuit breakers of the data sources.
+// Faults are injected by the transport of the HTTP client of a data source, so that they pass all resilience layers of the data source.
+// The faults of each data source can be changed and the injection can be toggled at runtime.
+package faultinjection
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ErrConnectionReset is returned for requests with an injected connection reset, it wraps syscall.ECONNRESET
+var ErrConnectionReset = fmt.Errorf("injected connection reset: %w", syscall.ECONNRESET)
+
+const defaultErrorStatusCode = http.StatusServiceUnavailable
+
+// Faults configures the faults injected into the requests of a data source, rates are between 0 (never) and 1 (always)
+type Faults struct {
+	// Latency delays every request, requests cancelled while delayed fail with the error of their context
+	Latency time.Duration
+	// LatencyJitter adds a random delay between 0 and LatencyJitter to the Latency
+	LatencyJitter time.Duration
+	// ErrorRate is the rate of requests answered with ErrorStatusCode without calling the upstream
+	ErrorRate float64
+	// ErrorStatusCode is the status code of injected errors, defaults to 503 Service Unavailable
+	ErrorStatusCode int
+	// MalformedResponseRate is the rate of responses of the upstream which are truncated, so that they are no valid JSON
+	MalformedResponseRate float64
+	// ConnectionResetRate is the rate of requests failing with ErrConnectionReset without calling the upstream
+	ConnectionResetRate float64
+}
+
+// Injector holds the faults of the data sources by their name, it's disabled until enabled with Enable
+type Injector struct {
+	enabled int32
+	mu      sync.RWMutex
+	faults  map[string]Faults
+	randMu  sync.Mutex
+	rand    *rand.Rand
+}
+
+func NewInjector() *Injector {
+	return &Injector{
+		faults: map[string]Faults{},
+		rand:   rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Enable toggles the injection of faults for all data sources
+func (i *Injector) Enable(enable bool) {
+	var enabled int32
+	if enable {
+		enabled = 1
+	}
+	atomic.StoreInt32(&i.enabled, enabled)
+}
+
+func (i *Injector) IsEnabled() bool {
+	return atomic.LoadInt32(&i.enabled) == 1
+}
+
+// SetFaults sets the faults injected into the requests of the data source, replacing its previous faults
+func (i *Injector) SetFaults(dataSource string, faults Faults) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.faults[dataSource] = faults
+}
+
+// RemoveFaults stops injecting faults into the requests of the data source
+func (i *Injector) RemoveFaults(dataSource string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.faults, dataSource)
+}
+
+func (i *Injector) dataSourceFaults(dataSource string) (Faults, bool) {
+	if !i.IsEnabled() {
+		return Faults{}, false
+	}
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	faults, ok := i.faults[dataSource]
+	return faults, ok
+}
+
+// Client returns a copy of the client injecting the faults of the data source, nil uses http.DefaultClient,
+// e.g. the HTTPClient of a graphql_datasource.Factory or the Client of a rest_datasource.Factory
+func (i *Injector) Client(dataSource string, client *http.Client) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	injecting := *client
+	injecting.Transport = i.Transport(dataSource, client.Transport)
+	return &injecting
+}
+
+// Transport returns a transport injecting the faults of the data source into the requests sent with the transport next,
+// nil uses http.DefaultTransport
+func (i *Injector) Transport(dataSource string, next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &transport{
+		injector:   i,
+		dataSource: dataSource,
+		next:       next,
+	}
+}
+
+// happens returns true with the probability of the rate
+func (i *Injector) happens(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	if rate >= 1 {
+		return true
+	}
+	i.randMu.Lock()
+	defer i.randMu.Unlock()
+	return i.rand.Float64() < rate
+}
+
+func (i *Injector) jitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	i.randMu.Lock()
+	defer i.randMu.Unlock()
+	return time.Duration(i.rand.Int63n(int64(max)))
+}
+
+type transport struct {
+	injector   *Injector
+	dataSource string
+	next       http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	faults, ok := t.injector.dataSourceFaults(t.dataSource)
+	if !ok {
+		return t.next.RoundTrip(req)
+	}
+
+	if latency := faults.Latency + t.injector.jitter(faults.LatencyJitter); latency > 0 {
+		timer := time.NewTimer(latency)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+
+	if t.injector.happens(faults.ConnectionResetRate) {
+		return nil, ErrConnectionReset
+	}
+
+	if t.injector.happens(faults.ErrorRate) {
+		statusCode := faults.ErrorStatusCode
+		if statusCode == 0 {
+			statusCode = defaultErrorStatusCode
+		}
+		return &http.Response{
+			Status:     fmt.Sprintf("%d %s", statusCode, http.StatusText(statusCode)),
+			StatusCode: statusCode,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{"Content-Type": []string{"text/plain; charset=utf-8"}},
+			Body:       ioutil.NopCloser(strings.NewReader("injected fault")),
+			Request:    req,
+		}, nil
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || !t.injector.happens(faults.MalformedResponseRate) {
+		return resp, err
+	}
+	return malformResponse(resp)
+}
+
+// malformResponse truncates the body of the response to half of its length, responses with an empty body get an invalid body
+func malformResponse(resp *http.Response) (*http.Response, error) {
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	if len(body) == 0 {
+		body = []byte("{")
+	} else {
+		body = body[:len(body)/2]
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Del("Content-Length")
+	return resp, nil
+}
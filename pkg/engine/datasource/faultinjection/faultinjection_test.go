@@ -0,0 +1,101 @@
+package faultinjection
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInjector(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"data":{"hello":"world"}}`))
+	}))
+	defer upstream.Close()
+
+	injector := NewInjector()
+	client := injector.Client("users", nil)
+
+	get := func(t *testing.T, ctx context.Context) (int, string, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, upstream.URL, nil)
+		require.NoError(t, err)
+		resp, err := client.Do(req)
+		if err != nil {
+			return 0, "", err
+		}
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(body), nil
+	}
+
+	t.Run("doesn't inject faults while disabled", func(t *testing.T) {
+		injector.SetFaults("users", Faults{ErrorRate: 1})
+		statusCode, body, err := get(t, context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, `{"data":{"hello":"world"}}`, body)
+	})
+
+	injector.Enable(true)
+
+	t.Run("injects errors", func(t *testing.T) {
+		injector.SetFaults("users", Faults{ErrorRate: 1, ErrorStatusCode: http.StatusBadGateway})
+		statusCode, _, err := get(t, context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadGateway, statusCode)
+
+		injector.SetFaults("users", Faults{ErrorRate: 1})
+		statusCode, _, err = get(t, context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+	})
+
+	t.Run("injects connection resets", func(t *testing.T) {
+		injector.SetFaults("users", Faults{ConnectionResetRate: 1})
+		_, _, err := get(t, context.Background())
+		assert.True(t, errors.Is(err, syscall.ECONNRESET))
+	})
+
+	t.Run("injects malformed responses", func(t *testing.T) {
+		injector.SetFaults("users", Faults{MalformedResponseRate: 1})
+		statusCode, body, err := get(t, context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, `{"data":{"hel`, body)
+	})
+
+	t.Run("injects latency", func(t *testing.T) {
+		injector.SetFaults("users", Faults{Latency: 20 * time.Millisecond})
+		start := time.Now()
+		_, _, err := get(t, context.Background())
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+		injector.SetFaults("users", Faults{Latency: time.Minute})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		_, _, err = get(t, ctx)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("injects faults per data source", func(t *testing.T) {
+		injector.SetFaults("users", Faults{ErrorRate: 1})
+		statusCode, _, err := get(t, context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+
+		injector.RemoveFaults("users")
+		injector.SetFaults("products", Faults{ErrorRate: 1})
+		statusCode, _, err = get(t, context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+	})
+}
@@ -0,0 +1,322 @@
+package graphql_datasource
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/buger/jsonparser"
+	"nhooyr.io/websocket"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+)
+
+const (
+	// SubscriptionProtocolSSE is the SubscriptionProtocol of upstreams streaming subscriptions as server-sent events
+	SubscriptionProtocolSSE = "sse"
+
+	wsSubProtocolInputKey          = "ws_sub_protocol"
+	persistedQueryNotFound         = "PersistedQueryNotFound"
+	persistedQueryNotFoundCode     = "PERSISTED_QUERY_NOT_FOUND"
+	maxCapabilitiesResponseSize    = 1 << 20
+	capabilitiesIntrospectionQuery = `{"query":"{ __schema { subscriptionType { name } directives { name } } }"}`
+	capabilitiesProbeQuery         = "{__typename}"
+)
+
+// Capabilities are the features supported by an upstream, see DetectCapabilities
+type Capabilities struct {
+	// PersistedQueries is true if the upstream supports automatic persisted queries (APQ)
+	PersistedQueries bool
+	// Defer is true if the upstream schema defines the @defer directive
+	Defer bool
+	// BatchRequests is true if the upstream accepts a JSON array of requests and responds with an array of responses
+	BatchRequests bool
+	// SubscriptionProtocol is ProtocolGraphQLTWS, ProtocolGraphQLWS or SubscriptionProtocolSSE, empty if the upstream has no subscriptions
+	SubscriptionProtocol string
+}
+
+// CapabilitiesConfiguration configures which features of the upstream are used by the data source.
+// The overrides take precedence over the detected capabilities, e.g. to turn off a feature the detection found.
+// Without detection and overrides the data source behaves as if the upstream supported none of the features,
+// except for @defer and @stream, which are sent to the upstream if its schema allows them.
+type CapabilitiesConfiguration struct {
+	// Detect probes the upstream when the engine is created, see DetectCapabilities.
+	// The data source falls back to the overrides if the detection fails.
+	Detect bool
+	// Detected are the capabilities set by the detection
+	Detected *Capabilities
+	// PersistedQueries sends queries as sha256 hash first and only with the query text if the upstream doesn't know the hash yet
+	PersistedQueries *bool
+	// Defer sends @defer and @stream to the upstream, otherwise the engine resolves them itself
+	Defer *bool
+	// BatchRequests sends the split queries of FetchConfiguration.MaxFieldsPerRequest in a single batch request
+	BatchRequests *bool
+	// SubscriptionProtocol is the protocol of subscriptions, it takes precedence over SubscriptionConfiguration.UseSSE.
+	// For websockets the protocol is negotiated with the upstream if it's empty.
+	SubscriptionProtocol string
+}
+
+func (c *CapabilitiesConfiguration) persistedQueries() bool {
+	if c.PersistedQueries != nil {
+		return *c.PersistedQueries
+	}
+	return c.Detected != nil && c.Detected.PersistedQueries
+}
+
+func (c *CapabilitiesConfiguration) deferSupported() bool {
+	if c.Defer != nil {
+		return *c.Defer
+	}
+	return c.Detected == nil || c.Detected.Defer
+}
+
+func (c *CapabilitiesConfiguration) batchRequests() bool {
+	if c.BatchRequests != nil {
+		return *c.BatchRequests
+	}
+	return c.Detected != nil && c.Detected.BatchRequests
+}
+
+// subscriptionProtocol returns the protocol of subscriptions, the configured Subscription.UseSSE takes precedence over the detected protocol
+func (c *Configuration) subscriptionProtocol() string {
+	if c.Capabilities.SubscriptionProtocol != "" {
+		return c.Capabilities.SubscriptionProtocol
+	}
+	if c.Subscription.UseSSE {
+		return SubscriptionProtocolSSE
+	}
+	if c.Capabilities.Detected != nil {
+		return c.Capabilities.Detected.SubscriptionProtocol
+	}
+	return ""
+}
+
+// DetectCapabilities probes the features supported by the upstream of the configuration:
+// @defer and subscriptions are looked up by introspection, persisted queries and batching by sending probe requests.
+// The subscription protocol is negotiated via websocket with Subscription.URL, it's SubscriptionProtocolSSE
+// if the upstream doesn't accept websockets but responds to subscriptions with an event stream.
+// An error is returned only if the upstream can't be introspected.
+func DetectCapabilities(ctx context.Context, client *http.Client, config Configuration) (Capabilities, error) {
+	config.ApplyDefaults()
+	if client == nil {
+		client = http.DefaultClient
+	}
+	header := capabilitiesProbeHeader(config.Fetch)
+
+	var capabilities Capabilities
+	introspection, err := sendCapabilitiesProbe(ctx, client, config.Fetch.URL, header, []byte(capabilitiesIntrospectionQuery))
+	if err != nil {
+		return capabilities, fmt.Errorf("introspection of upstream %s failed: %w", config.Fetch.URL, err)
+	}
+	if _, dataType, _, err := jsonparser.Get(introspection, "data", "__schema"); err != nil || dataType != jsonparser.Object {
+		return capabilities, fmt.Errorf("introspection of upstream %s failed: %s", config.Fetch.URL, introspection)
+	}
+	hasSubscriptionType := false
+	if name, err := jsonparser.GetString(introspection, "data", "__schema", "subscriptionType", "name"); err == nil && name != "" {
+		hasSubscriptionType = true
+	}
+	_, _ = jsonparser.ArrayEach(introspection, func(value []byte, _ jsonparser.ValueType, _ int, _ error) {
+		if name, err := jsonparser.GetString(value, "name"); err == nil && name == "defer" {
+			capabilities.Defer = true
+		}
+	}, "data", "__schema", "directives")
+
+	persistedQuery := []byte(`{"extensions":{"persistedQuery":{"version":1,"sha256Hash":"` + persistedQueryHash([]byte(capabilitiesProbeQuery)) + `"}}}`)
+	if response, err := sendCapabilitiesProbe(ctx, client, config.Fetch.URL, header, persistedQuery); err == nil {
+		capabilities.PersistedQueries = isPersistedQueryNotFound(response) || hasData(response)
+	}
+
+	batch := []byte(`[{"query":"` + capabilitiesProbeQuery + `"},{"query":"` + capabilitiesProbeQuery + `"}]`)
+	if response, err := sendCapabilitiesProbe(ctx, client, config.Fetch.URL, header, batch); err == nil {
+		count := 0
+		_, arrayErr := jsonparser.ArrayEach(response, func(value []byte, dataType jsonparser.ValueType, _ int, _ error) {
+			if dataType == jsonparser.Object {
+				count++
+			}
+		})
+		capabilities.BatchRequests = arrayErr == nil && count == 2
+	}
+
+	if hasSubscriptionType && config.Subscription.URL != "" {
+		capabilities.SubscriptionProtocol = detectSubscriptionProtocol(ctx, client, config.Subscription.URL, header)
+	}
+
+	return capabilities, nil
+}
+
+// capabilitiesProbeHeader returns the headers of the fetch configuration which don't depend on the client request
+func capabilitiesProbeHeader(fetch FetchConfiguration) http.Header {
+	header := http.Header{}
+	for name, values := range fetch.Header {
+		for _, value := range values {
+			if !strings.Contains(value, "{{") {
+				header.Add(name, value)
+			}
+		}
+	}
+	for name, values := range fetch.StaticHeaders {
+		header[http.CanonicalHeaderKey(name)] = values
+	}
+	if fetch.UserAgent != "" {
+		header.Set("User-Agent", fetch.UserAgent)
+	}
+	return header
+}
+
+// sendCapabilitiesProbe posts the body to the upstream and returns the response regardless of the status code
+func sendCapabilitiesProbe(ctx context.Context, client *http.Client, url string, header http.Header, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	return ioutil.ReadAll(io.LimitReader(res.Body, maxCapabilitiesResponseSize))
+}
+
+// detectSubscriptionProtocol negotiates the websocket protocol with the upstream and falls back to probing for server-sent events
+func detectSubscriptionProtocol(ctx context.Context, client *http.Client, subscriptionURL string, header http.Header) string {
+	conn, _, err := websocket.Dial(ctx, subscriptionURL, &websocket.DialOptions{
+		HTTPClient:      client,
+		HTTPHeader:      header,
+		CompressionMode: websocket.CompressionDisabled,
+		Subprotocols:    []string{ProtocolGraphQLTWS, ProtocolGraphQLWS},
+	})
+	if err == nil {
+		protocol := conn.Subprotocol()
+		_ = conn.Close(websocket.StatusNormalClosure, "")
+		return protocol
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, subscriptionURL+"?query="+url.QueryEscape("subscription{__typename}"), nil)
+	if err != nil {
+		return ""
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	res, err := client.Do(req)
+	if err != nil {
+		return ""
+	}
+	_ = res.Body.Close()
+	if strings.HasPrefix(res.Header.Get("Content-Type"), "text/event-stream") {
+		return SubscriptionProtocolSSE
+	}
+	return ""
+}
+
+func persistedQueryHash(query []byte) string {
+	hash := sha256.Sum256(query)
+	return hex.EncodeToString(hash[:])
+}
+
+// isPersistedQueryNotFound returns true if the upstream responded that it doesn't know the hash of a persisted query
+func isPersistedQueryNotFound(response []byte) bool {
+	notFound := false
+	_, _ = jsonparser.ArrayEach(response, func(value []byte, _ jsonparser.ValueType, _ int, _ error) {
+		if message, _ := jsonparser.GetString(value, "message"); message == persistedQueryNotFound {
+			notFound = true
+		}
+		if code, _ := jsonparser.GetString(value, "extensions", "code"); code == persistedQueryNotFoundCode {
+			notFound = true
+		}
+	}, "errors")
+	return notFound
+}
+
+func hasData(response []byte) bool {
+	_, dataType, _, err := jsonparser.Get(response, "data")
+	return err == nil && dataType == jsonparser.Object
+}
+
+// loadPersisted sends the query as persisted query hash and resends it including the query text if the upstream doesn't know the hash
+func (s *Source) loadPersisted(ctx context.Context, input []byte, header http.Header, out io.Writer) (int, error) {
+	query, err := jsonparser.GetString(input, httpclient.BODY, "query")
+	if err != nil || query == "" {
+		return httpclient.DoWithStatusCode(s.httpClient, ctx, input, header, out)
+	}
+	extension := []byte(`{"version":1,"sha256Hash":"` + persistedQueryHash([]byte(query)) + `"}`)
+	withExtension, err := jsonparser.Set(append([]byte(nil), input...), extension, httpclient.BODY, "extensions", "persistedQuery")
+	if err != nil {
+		return 0, err
+	}
+
+	response := &bytes.Buffer{}
+	statusCode, err := httpclient.DoWithStatusCode(s.httpClient, ctx, jsonparser.Delete(append([]byte(nil), withExtension...), httpclient.BODY, "query"), header, response)
+	if err != nil || !isPersistedQueryNotFound(response.Bytes()) {
+		if err == nil {
+			_, err = out.Write(response.Bytes())
+		}
+		return statusCode, err
+	}
+	return httpclient.DoWithStatusCode(s.httpClient, ctx, withExtension, header, out)
+}
+
+var errBatchResponse = errors.New("upstream batch response doesn't match the batch request")
+
+// loadBatch sends the split queries in a single batch request and writes the merged responses
+func (s *Source) loadBatch(ctx context.Context, input, splitQueries []byte, header http.Header, writer io.Writer) (int, error) {
+	body, _, _, err := jsonparser.Get(input, httpclient.BODY)
+	if err != nil {
+		return 0, err
+	}
+	batch := &bytes.Buffer{}
+	batch.WriteByte('[')
+	count := 0
+	_, err = jsonparser.ArrayEach(splitQueries, func(query []byte, dataType jsonparser.ValueType, _ int, _ error) {
+		if dataType != jsonparser.String {
+			return
+		}
+		part, _ := jsonparser.Set(append([]byte(nil), body...), append(append([]byte{'"'}, query...), '"'), "query")
+		if count != 0 {
+			batch.WriteByte(',')
+		}
+		batch.Write(part)
+		count++
+	})
+	if err != nil {
+		return 0, err
+	}
+	batch.WriteByte(']')
+
+	batchInput, err := jsonparser.Set(append([]byte(nil), input...), batch.Bytes(), httpclient.BODY)
+	if err != nil {
+		return 0, err
+	}
+	response := &bytes.Buffer{}
+	statusCode, err := httpclient.DoWithStatusCode(s.httpClient, ctx, batchInput, header, response)
+	if err != nil {
+		return statusCode, err
+	}
+
+	var responses []bytes.Buffer
+	_, err = jsonparser.ArrayEach(response.Bytes(), func(value []byte, dataType jsonparser.ValueType, _ int, _ error) {
+		var part bytes.Buffer
+		part.Write(value)
+		responses = append(responses, part)
+	})
+	if err != nil || len(responses) != count {
+		return statusCode, errBatchResponse
+	}
+	_, err = writer.Write(mergeSplitResponses(responses))
+	return statusCode, err
+}
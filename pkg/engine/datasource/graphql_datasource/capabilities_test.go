@@ -0,0 +1,224 @@
+package graphql_datasource
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"nhooyr.io/websocket"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+)
+
+// capabilitiesUpstream answers the probes of DetectCapabilities, unsupported features respond like most servers without them
+type capabilitiesUpstream struct {
+	persistedQueries bool
+	batchRequests    bool
+	directives       string
+	subscriptions    string
+
+	mu       sync.Mutex
+	requests []string
+	hashes   map[string]bool
+}
+
+func (u *capabilitiesUpstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/subscriptions" {
+		switch u.subscriptions {
+		case ProtocolGraphQLTWS, ProtocolGraphQLWS:
+			conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{Subprotocols: []string{u.subscriptions}})
+			if err == nil {
+				_ = conn.Close(websocket.StatusNormalClosure, "")
+			}
+		case SubscriptionProtocolSSE:
+			w.Header().Set("Content-Type", "text/event-stream")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.requests = append(u.requests, string(body))
+
+	switch {
+	case bytes.HasPrefix(body, []byte("[")):
+		if !u.batchRequests {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":[{"message":"invalid request body"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`[{"data":{"a":"a"}},{"data":{"b":"b"}}]`))
+	case bytes.Contains(body, []byte("__schema")):
+		subscriptionType := "null"
+		if u.subscriptions != "" {
+			subscriptionType = `{"name":"Subscription"}`
+		}
+		_, _ = w.Write([]byte(`{"data":{"__schema":{"subscriptionType":` + subscriptionType + `,"directives":[` + u.directives + `]}}}`))
+	case bytes.Contains(body, []byte("persistedQuery")):
+		if !u.persistedQueries {
+			_, _ = w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotSupported"}]}`))
+			return
+		}
+		hash := string(body[bytes.Index(body, []byte(`"sha256Hash":"`))+14:][:64])
+		if bytes.Contains(body, []byte(`"query"`)) {
+			u.hashes[hash] = true
+		}
+		if !u.hashes[hash] {
+			_, _ = w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"hello":"world"}}`))
+	default:
+		_, _ = w.Write([]byte(`{"data":{"hello":"world"}}`))
+	}
+}
+
+func newCapabilitiesUpstream(t *testing.T, upstream *capabilitiesUpstream) (*httptest.Server, Configuration) {
+	upstream.hashes = map[string]bool{}
+	server := httptest.NewServer(upstream)
+	t.Cleanup(server.Close)
+	return server, Configuration{
+		Fetch:        FetchConfiguration{URL: server.URL},
+		Subscription: SubscriptionConfiguration{URL: server.URL + "/subscriptions"},
+	}
+}
+
+func TestDetectCapabilities(t *testing.T) {
+	t.Run("detects all capabilities", func(t *testing.T) {
+		_, config := newCapabilitiesUpstream(t, &capabilitiesUpstream{
+			persistedQueries: true,
+			batchRequests:    true,
+			directives:       `{"name":"include"},{"name":"defer"}`,
+			subscriptions:    ProtocolGraphQLTWS,
+		})
+
+		capabilities, err := DetectCapabilities(context.Background(), nil, config)
+		require.NoError(t, err)
+		assert.Equal(t, Capabilities{
+			PersistedQueries:     true,
+			Defer:                true,
+			BatchRequests:        true,
+			SubscriptionProtocol: ProtocolGraphQLTWS,
+		}, capabilities)
+	})
+
+	t.Run("detects server-sent events if the upstream doesn't accept websockets", func(t *testing.T) {
+		_, config := newCapabilitiesUpstream(t, &capabilitiesUpstream{subscriptions: SubscriptionProtocolSSE})
+
+		capabilities, err := DetectCapabilities(context.Background(), nil, config)
+		require.NoError(t, err)
+		assert.Equal(t, Capabilities{SubscriptionProtocol: SubscriptionProtocolSSE}, capabilities)
+	})
+
+	t.Run("detects no capabilities", func(t *testing.T) {
+		_, config := newCapabilitiesUpstream(t, &capabilitiesUpstream{directives: `{"name":"include"}`})
+
+		capabilities, err := DetectCapabilities(context.Background(), nil, config)
+		require.NoError(t, err)
+		assert.Equal(t, Capabilities{}, capabilities)
+	})
+
+	t.Run("fails if the upstream can't be introspected", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+
+		_, err := DetectCapabilities(context.Background(), nil, Configuration{Fetch: FetchConfiguration{URL: server.URL}})
+		assert.Error(t, err)
+	})
+}
+
+func TestCapabilitiesConfiguration(t *testing.T) {
+	enabled, disabled := true, false
+
+	t.Run("overrides take precedence over detected capabilities", func(t *testing.T) {
+		config := Configuration{Capabilities: CapabilitiesConfiguration{
+			Detected:             &Capabilities{PersistedQueries: true, Defer: true, SubscriptionProtocol: ProtocolGraphQLTWS},
+			PersistedQueries:     &disabled,
+			Defer:                &disabled,
+			BatchRequests:        &enabled,
+			SubscriptionProtocol: SubscriptionProtocolSSE,
+		}}
+		assert.False(t, config.Capabilities.persistedQueries())
+		assert.False(t, config.Capabilities.deferSupported())
+		assert.True(t, config.Capabilities.batchRequests())
+		assert.Equal(t, SubscriptionProtocolSSE, config.subscriptionProtocol())
+	})
+
+	t.Run("without detection the upstream is assumed to support no capabilities but @defer", func(t *testing.T) {
+		config := Configuration{}
+		assert.False(t, config.Capabilities.persistedQueries())
+		assert.True(t, config.Capabilities.deferSupported())
+		assert.False(t, config.Capabilities.batchRequests())
+		assert.Equal(t, "", config.subscriptionProtocol())
+
+		config.Subscription.UseSSE = true
+		assert.Equal(t, SubscriptionProtocolSSE, config.subscriptionProtocol())
+	})
+}
+
+func TestSource_Capabilities(t *testing.T) {
+	t.Run("persisted queries", func(t *testing.T) {
+		upstream := &capabilitiesUpstream{persistedQueries: true}
+		server, _ := newCapabilitiesUpstream(t, upstream)
+		src := &Source{httpClient: http.DefaultClient, persistedQueries: true}
+
+		input := httpclient.SetInputBodyWithPath(nil, []byte(`"{hello}"`), "query")
+		input = httpclient.SetInputURL(input, []byte(server.URL))
+		input = httpclient.SetInputMethod(input, []byte(http.MethodPost))
+
+		for i := 0; i < 2; i++ {
+			buf := &bytes.Buffer{}
+			require.NoError(t, src.Load(context.Background(), input, buf))
+			assert.Equal(t, `{"data":{"hello":"world"}}`, buf.String())
+		}
+
+		hash := persistedQueryHash([]byte("{hello}"))
+		assert.Equal(t, []string{
+			`{"extensions":{"persistedQuery":{"version":1,"sha256Hash":"` + hash + `"}}}`,
+			`{"query":"{hello}","extensions":{"persistedQuery":{"version":1,"sha256Hash":"` + hash + `"}}}`,
+			`{"extensions":{"persistedQuery":{"version":1,"sha256Hash":"` + hash + `"}}}`,
+		}, upstream.requests)
+	})
+
+	t.Run("batch requests of split queries", func(t *testing.T) {
+		upstream := &capabilitiesUpstream{batchRequests: true}
+		server, _ := newCapabilitiesUpstream(t, upstream)
+		src := &Source{httpClient: http.DefaultClient, batchRequests: true}
+
+		input := httpclient.SetInputBodyWithPath(nil, []byte(`"{a b}"`), "query")
+		input = httpclient.SetInputURL(input, []byte(server.URL))
+		input = httpclient.SetInputMethod(input, []byte(http.MethodPost))
+		input = setInputSplitQueries(input, [][]byte{[]byte("{a}"), []byte("{b}")})
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, src.Load(context.Background(), input, buf))
+		assert.Equal(t, `{"data":{"a":"a","b":"b"}}`, buf.String())
+		assert.Equal(t, []string{`[{"query":"{a}"},{"query":"{b}"}]`}, upstream.requests)
+	})
+
+	t.Run("batch requests fail for responses not matching the batch", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"errors":[{"message":"invalid request body"}]}`))
+		}))
+		defer server.Close()
+		src := &Source{httpClient: http.DefaultClient, batchRequests: true}
+
+		input := httpclient.SetInputBodyWithPath(nil, []byte(`"{a b}"`), "query")
+		input = httpclient.SetInputURL(input, []byte(server.URL))
+		input = setInputSplitQueries(input, [][]byte{[]byte("{a}"), []byte("{b}")})
+		err := src.Load(context.Background(), input, &strings.Builder{})
+		assert.ErrorIs(t, err, errBatchResponse)
+	})
+}
@@ -19,7 +19,12 @@ import (
 	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
 	"github.com/wundergraph/graphql-go-tools/pkg/asttransform"
 	"github.com/wundergraph/graphql-go-tools/pkg/astvalidation"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/concurrency"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/failover"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/hedging"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/replication"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/secrets"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
 	"github.com/wundergraph/graphql-go-tools/pkg/federation"
@@ -46,6 +51,12 @@ type Planner struct {
 	federationDepth                    int
 	extractEntities                    bool
 	fetchClient                        *http.Client
+	secretProvider                     secrets.SecretProvider
+	replicaRouter                      *replication.Router
+	failoverBalancer                   *failover.Balancer
+	concurrencyLimiters                *concurrency.Limiters
+	schemaBuilder                      *federation.BaseSchemaBuilder
+	isMutation                         bool // isMutation - flags that the operation is a mutation, so that all fetches are sent to the primary of replicated upstreams
 	subscriptionClient                 GraphQLSubscriptionClient
 	isNested                           bool   // isNested - flags that datasource is nested e.g. field with datasource is not on a query type
 	rootTypeName                       string // rootTypeName - holds name of top level type
@@ -62,6 +73,18 @@ type Planner struct {
 	unnulVariables          bool
 
 	parentTypeNodes []ast.Node
+
+	namespaceFieldRef int              // namespaceFieldRef - holds ref of the namespace field being walked, its selections are root selections of the upstream operation
+	namespaceFields   []namespaceField // namespaceFields - holds the response keys of the namespace fields to wrap the response data into
+
+	flattenedFieldNodes map[int]int // flattenedFieldNodes - holds the number of upstream nodes enclosing flattened fields, which are removed with the field
+
+	splitQueries [][]byte // splitQueries - holds the parts of the upstream query when it exceeds Fetch.MaxFieldsPerRequest
+
+	inputValueMappings   map[string]*valueMapping // inputValueMappings - holds the translations of the gateway input types by type name
+	responseValueMapping *valueMapping            // responseValueMapping - holds the translation of the upstream response, nil if there is nothing to translate
+
+	variableDefaults map[string]ast.Value // variableDefaults - holds the default values of the upstream variables by name, see Configuration.InjectArgumentDefaults
 }
 
 func (p *Planner) parentNodeIsAbstract() bool {
@@ -72,6 +95,10 @@ func (p *Planner) parentNodeIsAbstract() bool {
 	return parentTypeNode.Kind.IsAbstractType()
 }
 
+func (p *Planner) enclosingTypeIsAbstract() bool {
+	return p.visitor.Walker.EnclosingTypeDefinition.Kind.IsAbstractType()
+}
+
 func (p *Planner) EnterVariableDefinition(ref int) {
 	p.currentVariableDefinition = ref
 }
@@ -98,6 +125,14 @@ func (p *Planner) addDirectiveToNode(directiveRef int, node ast.Node) {
 	if !p.visitor.Definition.DirectiveIsAllowedOnNodeKind(directiveName, node.Kind, operationType) {
 		return
 	}
+	if (directiveName == "defer" || directiveName == "stream") && !p.config.Capabilities.deferSupported() {
+		// resolved by the engine
+		return
+	}
+	if node.Kind == ast.NodeKindField && p.visitor.Config.IsResolvedDirective(directiveName) {
+		// applied when the field is resolved, see plan.Configuration.ResolvedDirectives
+		return
+	}
 	upstreamDirectiveName := p.dataSourceConfig.Directives.RenameTypeNameOnMatchStr(directiveName)
 	if p.upstreamDefinition != nil && !p.upstreamDefinition.DirectiveIsAllowedOnNodeKind(upstreamDirectiveName, node.Kind, operationType) {
 		return
@@ -113,11 +148,7 @@ func (p *Planner) addDirectiveToNode(directiveRef int, node ast.Node) {
 	// Collect all the variable arguments.
 	if directive.HasArguments {
 		for _, argument := range directive.Arguments.Refs {
-			value := p.visitor.Operation.ArgumentValue(argument)
-			// TODO: also handle literal values that CONTAIN variables
-			if value.Kind == ast.ValueKindVariable {
-				variables = append(variables, value)
-			}
+			variables = p.appendValueVariables(variables, p.visitor.Operation.ArgumentValue(argument))
 		}
 	}
 
@@ -163,7 +194,7 @@ func (p *Planner) addDirectiveToNode(directiveRef int, node ast.Node) {
 			// Add the variable to the upstream operation. Be sure to map the
 			// downstream type to the upstream type, if needed.
 			upstreamVariable := p.upstreamOperation.ImportVariableValue(variableName)
-			upstreamTypeName := p.visitor.Config.Types.RenameTypeNameOnMatchStr(typeName)
+			upstreamTypeName := p.upstreamTypeName(typeName)
 			importedType := p.visitor.Importer.ImportTypeWithRename(p.visitor.Operation.VariableDefinitions[i].Type, p.visitor.Operation, p.upstreamOperation, upstreamTypeName)
 			p.upstreamOperation.AddVariableDefinitionToOperationDefinition(p.nodes[0].Ref, upstreamVariable, importedType)
 
@@ -181,6 +212,23 @@ func (p *Planner) addDirectiveToNode(directiveRef int, node ast.Node) {
 	}
 }
 
+// appendValueVariables appends the variables of the value, including the variables nested in list and object values
+func (p *Planner) appendValueVariables(variables []ast.Value, value ast.Value) []ast.Value {
+	switch value.Kind {
+	case ast.ValueKindVariable:
+		variables = append(variables, value)
+	case ast.ValueKindList:
+		for _, ref := range p.visitor.Operation.ListValues[value.Ref].Refs {
+			variables = p.appendValueVariables(variables, p.visitor.Operation.Values[ref])
+		}
+	case ast.ValueKindObject:
+		for _, ref := range p.visitor.Operation.ObjectValues[value.Ref].Refs {
+			variables = p.appendValueVariables(variables, p.visitor.Operation.ObjectFieldValue(ref))
+		}
+	}
+	return variables
+}
+
 func (p *Planner) DownstreamResponseFieldAlias(downstreamFieldRef int) (alias string, exists bool) {
 	// If there's no alias but the downstream Query re-uses the same path on different root fields,
 	// we rewrite the downstream Query using an alias so that we can have an aliased Query to the upstream
@@ -222,6 +270,20 @@ type Configuration struct {
 	Federation             FederationConfiguration
 	UpstreamSchema         string
 	CustomScalarTypeFields []SingleTypeField
+	// Namespace wraps the upstream schema under a namespace field and/or prefixes its type names,
+	// it requires UpstreamSchema and shouldn't be combined with Federation. Subscription responses aren't transformed.
+	Namespace NamespaceConfiguration
+	// SchemaMapping renames types and fields of the upstream schema, hides fields and flattens nested fields,
+	// it requires UpstreamSchema. Input fields and enum values are renamed with ValueMapping. Subscription responses aren't transformed.
+	SchemaMapping SchemaMappingConfiguration
+	// Capabilities configures the features of the upstream used by the data source, e.g. persisted queries and batching
+	Capabilities CapabilitiesConfiguration
+	// ValueMapping translates enum values and input fields of the gateway schema to the upstream schema and back
+	ValueMapping ValueMappingConfiguration
+	// InjectArgumentDefaults sends the default values of arguments in the gateway schema explicitly to the upstream
+	// as defaults of the upstream variables, so that upstreams with different or missing defaults for the arguments
+	// behave like the gateway schema if the variables of the arguments aren't provided
+	InjectArgumentDefaults bool
 }
 
 type SingleTypeField struct {
@@ -243,12 +305,42 @@ type SubscriptionConfiguration struct {
 	URL           string
 	UseSSE        bool
 	SSEMethodPost bool
+	// Coalescing sends at most one event per window to the client, e.g. the latest event of every 100ms
+	Coalescing resolve.SubscriptionCoalescing
 }
 
 type FetchConfiguration struct {
 	URL    string
 	Method string
 	Header http.Header
+	// MaxFieldsPerRequest is the maximum number of fields selected by a single upstream query.
+	// Queries selecting more fields are split by their root fields into multiple requests,
+	// of which the responses are merged before resolving. 0 disables splitting.
+	MaxFieldsPerRequest int
+	// UserAgent is sent as User-Agent header to the upstream, overriding forwarded client headers.
+	UserAgent string
+	// StaticHeaders are sent to the upstream independent of the client request, overriding forwarded client headers.
+	// Unlike Header, the values are sent as is without rendering them as templates.
+	StaticHeaders http.Header
+	// SecretHeaders maps header names to names of secrets, e.g. API keys, of which the values are looked up
+	// by Factory.SecretProvider for each request, so they are never part of the configuration, the plan or logs.
+	SecretHeaders map[string]string
+	// Replication configures read replicas of the upstream, URL is the primary.
+	// Queries are sent to the replicas, mutations including all their fetches are sent to the primary.
+	Replication replication.Configuration
+	// Failover configures multiple endpoints of the upstream, of which scheme and host replace the ones of URL.
+	// Fetches fail over to the next endpoint on errors, 5xx responses and open circuits. It can't be combined with Replication.
+	// Mutations and all fetches of a mutation only fail over if dialing the endpoint failed, so that they aren't executed twice.
+	Failover failover.Configuration
+	// Hedging sends a second attempt of queries which didn't respond within a delay, the first success wins.
+	// Mutations and all fetches of a mutation aren't hedged.
+	Hedging hedging.Configuration
+	// Concurrency limits the concurrent requests to the upstream, the limit adapts to the latency and the errors of the upstream.
+	// Requests of failover endpoints are limited each, hedged attempts count as a single request.
+	Concurrency concurrency.Configuration
+	// AcceptEncodings are the encodings accepted for compressed responses, e.g. gzip and zstd, see httpclient.RegisterContentDecoder.
+	// If empty, the http client requests and decompresses gzip transparently.
+	AcceptEncodings []string
 }
 
 func (c *Configuration) ApplyDefaults() {
@@ -277,9 +369,22 @@ func (p *Planner) Register(visitor *plan.Visitor, configuration plan.DataSourceC
 	p.config.ApplyDefaults()
 	p.isNested = isNested
 
+	if p.config.Fetch.Failover.IsEnabled() && p.config.Fetch.Replication.IsEnabled() {
+		return failover.ErrCombinedWithReplication
+	}
+
 	return nil
 }
 
+// concurrencyConfiguration returns the concurrency limit of the upstream, upstreams are identified by their URL unless they're named
+func (p *Planner) concurrencyConfiguration() concurrency.Configuration {
+	config := p.config.Fetch.Concurrency
+	if config.Name == "" {
+		config.Name = p.config.Fetch.URL
+	}
+	return config
+}
+
 func (p *Planner) ConfigureFetch() plan.FetchConfiguration {
 	var input []byte
 	input = httpclient.SetInputBodyWithPath(input, p.upstreamVariables, "variables")
@@ -289,6 +394,10 @@ func (p *Planner) ConfigureFetch() plan.FetchConfiguration {
 		input = httpclient.SetInputFlag(input, httpclient.UNNULLVARIABLES)
 	}
 
+	if len(p.splitQueries) != 0 {
+		input = setInputSplitQueries(input, p.splitQueries)
+	}
+
 	header, err := json.Marshal(p.config.Fetch.Header)
 	if err == nil && len(header) != 0 && !bytes.Equal(header, literal.NULL) {
 		input = httpclient.SetInputHeader(input, header)
@@ -296,6 +405,7 @@ func (p *Planner) ConfigureFetch() plan.FetchConfiguration {
 
 	input = httpclient.SetInputURL(input, []byte(p.config.Fetch.URL))
 	input = httpclient.SetInputMethod(input, []byte(p.config.Fetch.Method))
+	input = httpclient.SetInputAcceptEncoding(input, p.config.Fetch.AcceptEncodings)
 
 	var batchConfig plan.BatchConfig
 	// Allow batch query for fetching entities.
@@ -310,6 +420,17 @@ func (p *Planner) ConfigureFetch() plan.FetchConfiguration {
 		Input: string(input),
 		DataSource: &Source{
 			httpClient: p.fetchClient,
+			headers:    newUpstreamHeaders(p.config.Fetch, p.secretProvider),
+			upstream:   p.replicaRouter.Upstream(p.config.Fetch.Replication, p.isMutation),
+			failover:   p.failoverBalancer.Upstream(p.config.Fetch.Failover, !p.isMutation),
+			hedging:    hedging.NewUpstream(p.config.Fetch.Hedging, !p.isMutation),
+			limit:      p.concurrencyLimiters.Upstream(p.concurrencyConfiguration()),
+			namespace:  p.namespaceResponse(),
+			mapping:    p.schemaMappingResponse(),
+
+			persistedQueries: p.config.Capabilities.persistedQueries(),
+			batchRequests:    p.config.Capabilities.batchRequests(),
+			valueMapping:     p.responseValueMapping,
 		},
 		Variables:            p.variables,
 		DisallowSingleFlight: p.disallowSingleFlight,
@@ -326,11 +447,14 @@ func (p *Planner) ConfigureSubscription() plan.SubscriptionConfiguration {
 	input := httpclient.SetInputBodyWithPath(nil, p.upstreamVariables, "variables")
 	input = httpclient.SetInputBodyWithPath(input, p.printOperation(), "query")
 	input = httpclient.SetInputURL(input, []byte(p.config.Subscription.URL))
-	if p.config.Subscription.UseSSE {
+	switch protocol := p.config.subscriptionProtocol(); protocol {
+	case SubscriptionProtocolSSE:
 		input = httpclient.SetInputFlag(input, httpclient.USESSE)
 		if p.config.Subscription.SSEMethodPost {
 			input = httpclient.SetInputFlag(input, httpclient.SSEMETHODPOST)
 		}
+	case ProtocolGraphQLWS, ProtocolGraphQLTWS:
+		input, _ = sjson.SetBytes(input, wsSubProtocolInputKey, protocol)
 	}
 
 	header, err := json.Marshal(p.config.Fetch.Header)
@@ -341,9 +465,11 @@ func (p *Planner) ConfigureSubscription() plan.SubscriptionConfiguration {
 	return plan.SubscriptionConfiguration{
 		Input: string(input),
 		DataSource: &SubscriptionSource{
-			client: p.subscriptionClient,
+			client:  p.subscriptionClient,
+			headers: newUpstreamHeaders(p.config.Fetch, p.secretProvider),
 		},
-		Variables: p.variables,
+		Variables:  p.variables,
+		Coalescing: p.config.Subscription.Coalescing,
 	}
 }
 
@@ -355,6 +481,7 @@ func (p *Planner) EnterOperationDefinition(ref int) {
 	}
 
 	operationType := p.visitor.Operation.OperationDefinitions[ref].OperationType
+	p.isMutation = operationType == ast.OperationTypeMutation
 	if p.isNested {
 		operationType = ast.OperationTypeQuery
 	}
@@ -375,6 +502,12 @@ func (p *Planner) EnterSelectionSet(ref int) {
 		return
 	}
 
+	if p.isNamespaceSelectionSet(ref) {
+		// the selections of the namespace field are added to the root selection set of the upstream operation
+		p.namespaceFields[len(p.namespaceFields)-1].typeName = p.visitor.Walker.EnclosingTypeDefinition.NameString(p.visitor.Definition)
+		return
+	}
+
 	parent := p.nodes[len(p.nodes)-1]
 	set := p.upstreamOperation.AddSelectionSet()
 	switch parent.Kind {
@@ -406,6 +539,10 @@ func (p *Planner) EnterSelectionSet(ref int) {
 		p.addTypenameToSelectionSet(set.Ref)
 		return
 	}
+	if p.isRootOperationType(p.visitor.Walker.EnclosingTypeDefinition) {
+		// the __typename of root operation types is resolved by the planner without fetching it
+		return
+	}
 
 	for _, selectionRef := range p.visitor.Operation.SelectionSets[ref].SelectionRefs {
 		if p.visitor.Operation.Selections[selectionRef].Kind == ast.SelectionKindField {
@@ -416,6 +553,12 @@ func (p *Planner) EnterSelectionSet(ref int) {
 	}
 }
 
+func (p *Planner) isRootOperationType(node ast.Node) bool {
+	typeName := node.NameBytes(p.visitor.Definition)
+	index := &p.visitor.Definition.Index
+	return bytes.Equal(typeName, index.QueryTypeName) || bytes.Equal(typeName, index.MutationTypeName) || bytes.Equal(typeName, index.SubscriptionTypeName)
+}
+
 func (p *Planner) addTypenameToSelectionSet(selectionSet int) {
 	field := p.upstreamOperation.AddField(ast.Field{
 		Name: p.upstreamOperation.Input.AppendInputString("__typename"),
@@ -426,9 +569,9 @@ func (p *Planner) addTypenameToSelectionSet(selectionSet int) {
 	})
 }
 
-func (p *Planner) LeaveSelectionSet(_ int) {
+func (p *Planner) LeaveSelectionSet(ref int) {
 	p.parentTypeNodes = p.parentTypeNodes[:len(p.parentTypeNodes)-1]
-	if p.insideCustomScalarField {
+	if p.insideCustomScalarField || p.isNamespaceSelectionSet(ref) {
 		return
 	}
 
@@ -458,7 +601,7 @@ func (p *Planner) EnterInlineFragment(ref int) {
 
 	fragmentType := -1
 	if typeCondition != nil {
-		fragmentType = p.upstreamOperation.AddNamedType(p.visitor.Config.Types.RenameTypeNameOnMatchBytes(typeCondition))
+		fragmentType = p.upstreamOperation.AddNamedType(p.upstreamTypeNameBytes(typeCondition))
 	}
 
 	inlineFragment := p.upstreamOperation.AddInlineFragment(ast.InlineFragment{
@@ -498,6 +641,14 @@ func (p *Planner) EnterField(ref int) {
 		return
 	}
 
+	if p.isNamespaceField(ref) {
+		p.namespaceFieldRef = ref
+		p.namespaceFields = append(p.namespaceFields, namespaceField{
+			responseKey: p.visitor.Operation.FieldAliasOrNameString(ref),
+		})
+		return
+	}
+
 	fieldName := p.visitor.Operation.FieldNameString(ref)
 	enclosingTypeName := p.visitor.Walker.EnclosingTypeDefinition.NameString(p.visitor.Definition)
 
@@ -567,7 +718,13 @@ func (p *Planner) LeaveField(ref int) {
 		return
 	}
 
-	p.nodes = p.nodes[:len(p.nodes)-1]
+	if p.namespaceFieldRef == ref {
+		p.namespaceFieldRef = -1
+		return
+	}
+
+	p.nodes = p.nodes[:len(p.nodes)-1-p.flattenedFieldNodes[ref]]
+	delete(p.flattenedFieldNodes, ref)
 }
 
 func (p *Planner) EnterArgument(_ int) {
@@ -584,6 +741,12 @@ func (p *Planner) EnterDocument(_, _ *ast.Document) {
 	}
 	p.nodes = p.nodes[:0]
 	p.parentTypeNodes = p.parentTypeNodes[:0]
+	p.namespaceFieldRef = -1
+	p.namespaceFields = nil
+	p.flattenedFieldNodes = map[int]int{}
+	p.inputValueMappings = nil
+	p.responseValueMapping = nil
+	p.variableDefaults = nil
 	p.upstreamVariables = nil
 	p.variables = p.variables[:0]
 	p.representationsJson = p.representationsJson[:0]
@@ -685,7 +848,9 @@ func (p *Planner) updateRepresentationsVariable(fieldConfig *plan.FieldConfigura
 	if len(p.representationsJson) == 0 {
 		// If the parent is an abstract type, i.e., an interface or union,
 		// the representation typename must come from a parent fetch response.
-		if p.parentNodeIsAbstract() {
+		// The same applies to fields of interface entities, as the representation
+		// of an entity has the typename of its concrete type.
+		if p.parentNodeIsAbstract() || p.enclosingTypeIsAbstract() {
 			objectVariable := &resolve.ObjectVariable{
 				Path: []string{"__typename"},
 			}
@@ -694,7 +859,7 @@ func (p *Planner) updateRepresentationsVariable(fieldConfig *plan.FieldConfigura
 				p.representationsJson, _ = sjson.SetRawBytes(p.representationsJson, "__typename", []byte(variable))
 			}
 		} else { // otherwise use the concrete typename
-			onTypeName := p.visitor.Config.Types.RenameTypeNameOnMatchStr(p.lastFieldEnclosingTypeName)
+			onTypeName := p.upstreamTypeName(p.lastFieldEnclosingTypeName)
 			p.representationsJson, _ = sjson.SetRawBytes(nil, "__typename", []byte("\""+onTypeName+"\""))
 		}
 	}
@@ -724,21 +889,21 @@ func (p *Planner) updateRepresentationsVariable(fieldConfig *plan.FieldConfigura
 }
 
 func (p *Planner) fieldDefinition(fieldName, typeName string) *ast.FieldDefinition {
-	node, ok := p.visitor.Definition.Index.FirstNodeByNameStr(typeName)
-	if !ok {
-		return nil
-	}
-	definition, ok := p.visitor.Definition.NodeFieldDefinitionByName(node, []byte(fieldName))
-	if !ok {
-		return nil
+	// the field might be defined on the type definition or on one of its extensions
+	nodes, _ := p.visitor.Definition.NodesByNameStr(typeName)
+	for i := range nodes {
+		definition, ok := p.visitor.Definition.NodeFieldDefinitionByName(nodes[i], []byte(fieldName))
+		if ok {
+			return &p.visitor.Definition.FieldDefinitions[definition]
+		}
 	}
-	return &p.visitor.Definition.FieldDefinitions[definition]
+	return nil
 }
 
 func (p *Planner) addOnTypeInlineFragment() {
 	selectionSet := p.upstreamOperation.AddSelectionSet()
 	p.addTypenameToSelectionSet(p.nodes[len(p.nodes)-1].Ref)
-	onTypeName := p.visitor.Config.Types.RenameTypeNameOnMatchBytes([]byte(p.lastFieldEnclosingTypeName))
+	onTypeName := p.upstreamTypeNameBytes([]byte(p.lastFieldEnclosingTypeName))
 	typeRef := p.upstreamOperation.AddNamedType(onTypeName)
 	inlineFragment := p.upstreamOperation.AddInlineFragment(ast.InlineFragment{
 		HasSelections: true,
@@ -815,16 +980,15 @@ func (p *Planner) isNestedRequest() bool {
 }
 
 func (p *Planner) storeArgType(typeName, fieldName, argName string) {
-	typeNode, _ := p.visitor.Definition.Index.FirstNodeByNameStr(typeName)
-
-	for _, fieldDefRef := range p.visitor.Definition.ObjectTypeDefinitions[typeNode.Ref].FieldsDefinition.Refs {
-		if bytes.Equal(p.visitor.Definition.FieldDefinitionNameBytes(fieldDefRef), []byte(fieldName)) {
-			for _, argDefRef := range p.visitor.Definition.FieldDefinitions[fieldDefRef].ArgumentsDefinition.Refs {
-				if bytes.Equal(p.visitor.Definition.InputValueDefinitionNameBytes(argDefRef), []byte(argName)) {
-					p.argTypeRef = p.visitor.Definition.ResolveListOrNameType(p.visitor.Definition.InputValueDefinitions[argDefRef].Type)
-					return
-				}
-			}
+	fieldDefinition := p.fieldDefinition(fieldName, typeName)
+	if fieldDefinition == nil {
+		return
+	}
+
+	for _, argDefRef := range fieldDefinition.ArgumentsDefinition.Refs {
+		if bytes.Equal(p.visitor.Definition.InputValueDefinitionNameBytes(argDefRef), []byte(argName)) {
+			p.argTypeRef = p.visitor.Definition.ResolveListOrNameType(p.visitor.Definition.InputValueDefinitions[argDefRef].Type)
+			return
 		}
 	}
 }
@@ -871,7 +1035,7 @@ func (p *Planner) configureFieldArgumentSource(upstreamFieldRef, downstreamField
 
 	contextVariable := &resolve.ContextVariable{
 		Path:     []string{variableNameStr},
-		Renderer: renderer,
+		Renderer: p.mapVariableRenderer(renderer, p.visitor.Definition, argumentType),
 	}
 
 	contextVariableName, exists := p.variables.AddVariable(contextVariable)
@@ -888,12 +1052,15 @@ func (p *Planner) configureFieldArgumentSource(upstreamFieldRef, downstreamField
 			continue
 		}
 		typeName := p.visitor.Operation.ResolveTypeNameString(p.visitor.Operation.VariableDefinitions[i].Type)
-		typeName = p.visitor.Config.Types.RenameTypeNameOnMatchStr(typeName)
+		typeName = p.upstreamTypeName(typeName)
 		if argumentConfiguration.RenameTypeTo != "" {
 			typeName = argumentConfiguration.RenameTypeTo
 		}
 		importedType := p.visitor.Importer.ImportTypeWithRename(p.visitor.Operation.VariableDefinitions[i].Type, p.visitor.Operation, p.upstreamOperation, typeName)
 		p.upstreamOperation.AddVariableDefinitionToOperationDefinition(p.nodes[0].Ref, variableValueRef, importedType)
+		if p.config.InjectArgumentDefaults {
+			p.addVariableDefault(variableNameStr, i, argumentDefinition)
+		}
 
 		if add, ok := p.addDirectivesToVariableDefinitions[i]; ok {
 			for _, directive := range add {
@@ -905,6 +1072,42 @@ func (p *Planner) configureFieldArgumentSource(upstreamFieldRef, downstreamField
 	p.upstreamVariables, _ = sjson.SetRawBytes(p.upstreamVariables, variableNameStr, []byte(contextVariableName))
 }
 
+// addVariableDefault adds the default value of the argument in the gateway schema as default value of the upstream variable,
+// so that the upstream applies the default of the gateway schema if the variable isn't provided, see Configuration.InjectArgumentDefaults.
+// Arguments with a default which aren't selected by the operation don't need it, as the normalization extracts their default into the variables.
+func (p *Planner) addVariableDefault(variableName string, downstreamVariableDefinition, argumentDefinition int) {
+	if !p.visitor.Definition.InputValueDefinitionHasDefaultValue(argumentDefinition) ||
+		p.visitor.Operation.VariableDefinitionHasDefaultValue(downstreamVariableDefinition) {
+		return
+	}
+	if p.visitor.Operation.Types[p.visitor.Operation.VariableDefinitions[downstreamVariableDefinition].Type].TypeKind == ast.TypeKindNonNull {
+		// non null variables are always provided
+		return
+	}
+	value := p.visitor.Importer.ImportValue(p.visitor.Definition.InputValueDefinitionDefaultValue(argumentDefinition), p.visitor.Definition, p.upstreamOperation)
+	argumentType := p.visitor.Definition.InputValueDefinitionType(argumentDefinition)
+	p.mapInlineValue(value, p.inputValueMapping(p.visitor.Definition.ResolveTypeNameString(argumentType)))
+	if p.variableDefaults == nil {
+		p.variableDefaults = map[string]ast.Value{}
+	}
+	p.variableDefaults[variableName] = value
+}
+
+// applyVariableDefaults sets the default values of the variables of the normalized upstream operation,
+// they are applied after the normalization as it would extract them like the defaults of the client operation
+func (p *Planner) applyVariableDefaults(operation *ast.Document) {
+	for i := range operation.VariableDefinitions {
+		value, ok := p.variableDefaults[operation.VariableDefinitionNameString(i)]
+		if !ok {
+			continue
+		}
+		operation.VariableDefinitions[i].DefaultValue = ast.DefaultValue{
+			IsDefined: true,
+			Value:     p.visitor.Importer.ImportValue(value, p.upstreamOperation, operation),
+		}
+	}
+}
+
 // applyInlineFieldArgument - configures arguments for a complex argument of a list or input object type
 func (p *Planner) applyInlineFieldArgument(upstreamField, downstreamField int, argumentName string, sourcePath []string) {
 	fieldArgument, ok := p.visitor.Operation.FieldArgument(downstreamField, []byte(argumentName))
@@ -913,6 +1116,9 @@ func (p *Planner) applyInlineFieldArgument(upstreamField, downstreamField int, a
 	}
 	value := p.visitor.Operation.ArgumentValue(fieldArgument)
 	importedValue := p.visitor.Importer.ImportValue(value, p.visitor.Operation, p.upstreamOperation)
+	if p.argTypeRef != -1 {
+		p.mapInlineValue(importedValue, p.inputValueMapping(p.visitor.Definition.ResolveTypeNameString(p.argTypeRef)))
+	}
 	argRef := p.upstreamOperation.AddArgument(ast.Argument{
 		Name:  p.upstreamOperation.Input.AppendInputString(argumentName),
 		Value: importedValue,
@@ -930,7 +1136,7 @@ func (p *Planner) resolveNestedArgumentType(fieldName []byte) (fieldTypeRef int)
 	}
 
 	argTypeName := p.visitor.Definition.ResolveTypeNameString(p.argTypeRef)
-	argTypeNode, _ := p.visitor.Definition.Index.FirstNodeByNameStr(argTypeName)
+	argTypeNode, _ := p.visitor.Definition.Index.FirstNonExtensionNodeByNameStr(argTypeName)
 
 	for _, inputFieldDefRef := range p.visitor.Definition.InputObjectTypeDefinitions[argTypeNode.Ref].InputFieldsDefinition.Refs {
 		if bytes.Equal(p.visitor.Definition.InputValueDefinitionNameBytes(inputFieldDefRef), fieldName) {
@@ -972,7 +1178,7 @@ func (p *Planner) addVariableDefinitionsRecursively(value ast.Value, sourcePath
 
 	variableDefinitionTypeRef := p.visitor.Operation.VariableDefinitions[variableDefinition].Type
 	variableDefinitionTypeName := p.visitor.Operation.ResolveTypeNameString(variableDefinitionTypeRef)
-	variableDefinitionTypeName = p.visitor.Config.Types.RenameTypeNameOnMatchStr(variableDefinitionTypeName)
+	variableDefinitionTypeName = p.upstreamTypeName(variableDefinitionTypeName)
 
 	contextVariable := &resolve.ContextVariable{
 		Path: append(sourcePath, variableNameStr),
@@ -981,7 +1187,7 @@ func (p *Planner) addVariableDefinitionsRecursively(value ast.Value, sourcePath
 	if err != nil {
 		return
 	}
-	contextVariable.Renderer = renderer
+	contextVariable.Renderer = p.mapVariableRenderer(renderer, p.visitor.Operation, variableDefinitionTypeRef)
 	contextVariableName, variableExists := p.variables.AddVariable(contextVariable)
 	if variableExists {
 		return
@@ -1020,7 +1226,7 @@ func (p *Planner) configureObjectFieldSource(upstreamFieldRef, downstreamFieldRe
 	p.upstreamOperation.AddArgumentToField(upstreamFieldRef, argument)
 
 	typeName := p.visitor.Operation.ResolveTypeNameString(argumentType)
-	typeName = p.visitor.Config.Types.RenameTypeNameOnMatchStr(typeName)
+	typeName = p.upstreamTypeName(typeName)
 	if argumentConfiguration.RenameTypeTo != "" {
 		typeName = argumentConfiguration.RenameTypeTo
 	}
@@ -1035,7 +1241,7 @@ func (p *Planner) configureObjectFieldSource(upstreamFieldRef, downstreamFieldRe
 
 	variable := &resolve.ObjectVariable{
 		Path:     argumentConfiguration.SourcePath,
-		Renderer: renderer,
+		Renderer: p.mapVariableRenderer(renderer, p.visitor.Definition, argumentType),
 	}
 
 	objectVariableName, exists := p.variables.AddVariable(variable)
@@ -1060,12 +1266,10 @@ func (p *Planner) printOperation() []byte {
 
 	rawQuery := buf.Bytes()
 
-	// create empty operation and definition documents
+	// create empty operation document
 	operation := ast.NewDocument()
-	definition := ast.NewDocument()
 	report := &operationreport.Report{}
 	operationParser := astparser.NewParser()
-	definitionParser := astparser.NewParser()
 
 	operation.Input.ResetInputBytes(rawQuery)
 	operationParser.Parse(operation, report)
@@ -1074,6 +1278,16 @@ func (p *Planner) printOperation() []byte {
 		return nil
 	}
 
+	if p.config.UpstreamSchema == "" && p.config.Namespace.IsEnabled() {
+		p.visitor.Walker.StopWithInternalErr(ErrNamespaceRequiresUpstreamSchema)
+		return nil
+	}
+
+	if p.config.UpstreamSchema == "" && p.config.SchemaMapping.IsEnabled() {
+		p.visitor.Walker.StopWithInternalErr(ErrSchemaMappingRequiresUpstreamSchema)
+		return nil
+	}
+
 	if p.config.UpstreamSchema == "" {
 		p.config.UpstreamSchema, err = astprinter.PrintString(p.visitor.Definition, nil)
 		if err != nil {
@@ -1082,30 +1296,27 @@ func (p *Planner) printOperation() []byte {
 		}
 	}
 
+	var definition *ast.Document
 	if p.config.Federation.Enabled {
-		federationSchema, err := federation.BuildFederationSchema(p.config.UpstreamSchema, p.config.Federation.ServiceSDL)
+		// the federation schema is built once for all fetches of the upstream,
+		// it's shared and not modified below as replaceQueryType skips federated upstreams
+		definition, err = p.federationSchemaDocument()
 		if err != nil {
 			p.visitor.Walker.StopWithInternalErr(err)
 			return nil
 		}
-		definition.Input.ResetInputString(federationSchema)
-		definitionParser.Parse(definition, report)
-		if report.HasErrors() {
-			p.stopWithError(parseDocumentFailedErrMsg, "definition")
-			return nil
-		}
 	} else {
+		definition = ast.NewDocument()
 		definition.Input.ResetInputString(p.config.UpstreamSchema)
-		definitionParser.Parse(definition, report)
+		astparser.NewParser().Parse(definition, report)
 		if report.HasErrors() {
 			p.stopWithError("unable to parse upstream schema")
 			return nil
 		}
-	}
-
-	if err := asttransform.MergeDefinitionWithBaseSchema(definition); err != nil {
-		p.stopWithError("unable to merge upstream schema with base schema")
-		return nil
+		if err := asttransform.MergeDefinitionWithBaseSchema(definition); err != nil {
+			p.stopWithError("unable to merge upstream schema with base schema")
+			return nil
+		}
 	}
 
 	// When datasource is nested and definition query type do not contain operation field
@@ -1117,6 +1328,7 @@ func (p *Planner) printOperation() []byte {
 		p.stopWithError(normalizationFailedErrMsg)
 		return nil
 	}
+	p.applyVariableDefaults(operation)
 
 	validator := astvalidation.DefaultOperationValidator()
 	validator.Validate(operation, definition, report)
@@ -1125,6 +1337,14 @@ func (p *Planner) printOperation() []byte {
 		return nil
 	}
 
+	if p.config.Fetch.MaxFieldsPerRequest > 0 {
+		p.splitQueries, err = splitOperation(operation, p.config.Fetch.MaxFieldsPerRequest)
+		if err != nil {
+			p.stopWithError("splitting operation failed: %s", err)
+			return nil
+		}
+	}
+
 	buf.Reset()
 
 	// print upstream operation
@@ -1137,6 +1357,14 @@ func (p *Planner) printOperation() []byte {
 	return buf.Bytes()
 }
 
+// federationSchemaDocument returns the federation schema of the upstream merged with the base schema
+func (p *Planner) federationSchemaDocument() (*ast.Document, error) {
+	if p.schemaBuilder == nil {
+		p.schemaBuilder = federation.NewBaseSchemaBuilder()
+	}
+	return p.schemaBuilder.FederationSchemaDocument(p.config.UpstreamSchema, p.config.Federation.ServiceSDL)
+}
+
 func (p *Planner) stopWithError(msg string, args ...interface{}) {
 	p.visitor.Walker.StopWithInternalErr(fmt.Errorf(msg, args...))
 }
@@ -1258,6 +1486,10 @@ func (p *Planner) addField(ref int) {
 	}
 
 	typeName := p.visitor.Walker.EnclosingTypeDefinition.NameString(p.visitor.Definition)
+	if path := p.schemaMappingFieldPath(typeName, fieldName); len(path) != 0 {
+		p.addMappedField(ref, alias, path)
+		return
+	}
 	for i := range p.visitor.Config.Fields {
 		isDesiredField := p.visitor.Config.Fields[i].TypeName == typeName &&
 			p.visitor.Config.Fields[i].FieldName == fieldName
@@ -1295,6 +1527,7 @@ func (p *Planner) addField(ref int) {
 
 	p.upstreamOperation.AddSelection(p.nodes[len(p.nodes)-1].Ref, selection)
 	p.nodes = append(p.nodes, field)
+	p.addResponseValueMapping(ref)
 }
 
 type OnWsConnectionInitCallback func(ctx context.Context, url string, header http.Header) (json.RawMessage, error)
@@ -1305,6 +1538,19 @@ type Factory struct {
 	StreamingClient            *http.Client
 	OnWsConnectionInitCallback *OnWsConnectionInitCallback
 	SubscriptionClient         *SubscriptionClient
+	// SecretProvider looks up the values of FetchConfiguration.SecretHeaders, defaults to secrets.EnvProvider
+	SecretProvider secrets.SecretProvider
+	// ReplicaRouter routes the fetches of upstreams with FetchConfiguration.Replication
+	// and keeps track of the stickiness of clients to the primaries, it's created if not set
+	ReplicaRouter *replication.Router
+	// FailoverBalancer chooses the endpoints of upstreams with FetchConfiguration.Failover
+	// and keeps track of the circuits of the endpoints, it's created if not set
+	FailoverBalancer *failover.Balancer
+	// ConcurrencyLimiters keeps track of the limits of upstreams with FetchConfiguration.Concurrency, it's created if not set
+	ConcurrencyLimiters *concurrency.Limiters
+	// SchemaBuilder builds the federation schemas of upstreams with FederationConfiguration.Enabled once for all their fetches,
+	// it's created if not set. It can be shared by the factories of all subgraphs.
+	SchemaBuilder *federation.BaseSchemaBuilder
 }
 
 func (f *Factory) Planner(ctx context.Context) plan.DataSourcePlanner {
@@ -1318,15 +1564,43 @@ func (f *Factory) Planner(ctx context.Context) plan.DataSourcePlanner {
 	} else if f.SubscriptionClient.engineCtx == nil {
 		f.SubscriptionClient.engineCtx = ctx
 	}
+	if f.ReplicaRouter == nil {
+		f.ReplicaRouter = replication.NewRouter()
+	}
+	if f.FailoverBalancer == nil {
+		f.FailoverBalancer = failover.NewBalancer()
+	}
+	if f.ConcurrencyLimiters == nil {
+		f.ConcurrencyLimiters = concurrency.NewLimiters()
+	}
+	if f.SchemaBuilder == nil {
+		f.SchemaBuilder = federation.NewBaseSchemaBuilder()
+	}
 	return &Planner{
-		batchFactory:       f.BatchFactory,
-		fetchClient:        f.HTTPClient,
-		subscriptionClient: f.SubscriptionClient,
+		batchFactory:        f.BatchFactory,
+		fetchClient:         f.HTTPClient,
+		subscriptionClient:  f.SubscriptionClient,
+		secretProvider:      f.SecretProvider,
+		replicaRouter:       f.ReplicaRouter,
+		failoverBalancer:    f.FailoverBalancer,
+		concurrencyLimiters: f.ConcurrencyLimiters,
+		schemaBuilder:       f.SchemaBuilder,
 	}
 }
 
 type Source struct {
 	httpClient *http.Client
+	headers    upstreamHeaders
+	upstream   *replication.Upstream
+	failover   *failover.Upstream
+	hedging    *hedging.Upstream
+	limit      *concurrency.Upstream
+	namespace  *namespaceResponse
+	mapping    *schemaMappingResponse
+	// persistedQueries and batchRequests are the capabilities of the upstream, see CapabilitiesConfiguration
+	persistedQueries bool
+	batchRequests    bool
+	valueMapping     *valueMapping // valueMapping - translates the enum values of the response to the gateway schema
 }
 
 func (s *Source) compactAndUnNullVariables(input []byte, undefinedVariables []string) []byte {
@@ -1412,7 +1686,56 @@ func (s *Source) Load(ctx context.Context, input []byte, writer io.Writer) (err
 	undefinedVariables := httpclient.CtxGetUndefinedVariables(ctx)
 
 	input = s.compactAndUnNullVariables(input, undefinedVariables)
-	return httpclient.Do(s.httpClient, ctx, input, writer)
+	input, err = s.upstream.RouteInput(ctx, input)
+	if err != nil {
+		return err
+	}
+	header, err := s.headers.header(ctx)
+	if err != nil {
+		return err
+	}
+	if s.namespace != nil || s.mapping != nil || s.valueMapping != nil {
+		response := &bytes.Buffer{}
+		if err = s.load(ctx, input, header, response); err != nil {
+			return err
+		}
+		data := response.Bytes()
+		if s.valueMapping != nil {
+			data = s.valueMapping.mapJSON(data)
+		}
+		if s.mapping != nil {
+			data = s.mapping.mapTypeNames(data)
+		}
+		if s.namespace != nil {
+			data = s.namespace.wrap(data)
+		}
+		_, err = writer.Write(data)
+		return err
+	}
+	return s.load(ctx, input, header, writer)
+}
+
+func (s *Source) load(ctx context.Context, input []byte, header http.Header, writer io.Writer) error {
+	return s.failover.Load(ctx, input, writer, func(ctx context.Context, input []byte, out io.Writer) (int, error) {
+		return s.limit.Load(ctx, input, out, func(ctx context.Context, input []byte, out io.Writer) (int, error) {
+			return s.hedging.Load(ctx, input, out, func(ctx context.Context, input []byte, out io.Writer) (int, error) {
+				return s.loadAttempt(ctx, input, header, out)
+			})
+		})
+	})
+}
+
+func (s *Source) loadAttempt(ctx context.Context, input []byte, header http.Header, out io.Writer) (int, error) {
+	if splitQueries, _, _, err := jsonparser.Get(input, splitQueriesInputKey); err == nil {
+		if s.batchRequests {
+			return s.loadBatch(ctx, jsonparser.Delete(input, splitQueriesInputKey), splitQueries, header, out)
+		}
+		return 0, s.loadSplit(ctx, jsonparser.Delete(input, splitQueriesInputKey), splitQueries, header, out)
+	}
+	if s.persistedQueries {
+		return s.loadPersisted(ctx, input, header, out)
+	}
+	return httpclient.DoWithStatusCode(s.httpClient, ctx, input, header, out)
 }
 
 type GraphQLSubscriptionClient interface {
@@ -1425,6 +1748,8 @@ type GraphQLSubscriptionOptions struct {
 	Header        http.Header `json:"header"`
 	UseSSE        bool        `json:"use_sse"`
 	SSEMethodPost bool        `json:"sse_method_post"`
+	// WSSubProtocol is the websocket protocol of the upstream, it's negotiated if empty
+	WSSubProtocol string `json:"ws_sub_protocol,omitempty"`
 }
 
 type GraphQLBody struct {
@@ -1435,7 +1760,8 @@ type GraphQLBody struct {
 }
 
 type SubscriptionSource struct {
-	client GraphQLSubscriptionClient
+	client  GraphQLSubscriptionClient
+	headers upstreamHeaders
 }
 
 func (s *SubscriptionSource) Start(ctx context.Context, input []byte, next chan<- []byte) error {
@@ -1447,5 +1773,9 @@ func (s *SubscriptionSource) Start(ctx context.Context, input []byte, next chan<
 	if options.Body.Query == "" {
 		return resolve.ErrUnableToResolve
 	}
+	options.Header, err = s.headers.apply(ctx, options.Header)
+	if err != nil {
+		return err
+	}
 	return s.client.Subscribe(ctx, options, next)
 }
@@ -13,10 +13,12 @@ import (
 	"testing"
 	"time"
 
+	"github.com/buger/jsonparser"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/secrets"
 	. "github.com/wundergraph/graphql-go-tools/pkg/engine/datasourcetesting"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
@@ -96,6 +98,272 @@ func TestGraphQLDataSource(t *testing.T) {
 		DisableResolveFieldPositions: true,
 	}))
 
+	t.Run("query exceeding max fields per request is split by root fields", RunTest(`
+		schema {
+			query: Query
+		}
+
+		type Query {
+			hero(a: String): String
+			villain(b: String): String
+			stats: Stats
+		}
+
+		type Stats {
+			wins: Int
+			losses: Int
+		}`, `
+		query MyQuery($a: String, $b: String) {
+			hero(a: $a)
+			villain(b: $b)
+			stats {
+				wins
+				losses
+			}
+		}
+	`, "MyQuery", &plan.SynchronousResponsePlan{
+		Response: &resolve.GraphQLResponse{
+			Data: &resolve.Object{
+				Fetch: &resolve.SingleFetch{
+					DataSource: &Source{},
+					BufferId:   0,
+					Input:      `{"method":"POST","url":"https://swapi.com/graphql","body":{"query":"query($a: String, $b: String){hero(a: $a) villain(b: $b) stats {wins losses}}","variables":{"b":$$1$$,"a":$$0$$}},"split_queries":["query($a: String){hero(a: $a)}","query($b: String){villain(b: $b)}","{stats {wins losses}}"]}`,
+					Variables: resolve.NewVariables(
+						&resolve.ContextVariable{
+							Path:     []string{"a"},
+							Renderer: resolve.NewJSONVariableRendererWithValidation(`{"type":["string","null"]}`),
+						},
+						&resolve.ContextVariable{
+							Path:     []string{"b"},
+							Renderer: resolve.NewJSONVariableRendererWithValidation(`{"type":["string","null"]}`),
+						},
+					),
+					DataSourceIdentifier:  []byte("graphql_datasource.Source"),
+					ProcessResponseConfig: resolve.ProcessResponseConfig{ExtractGraphqlResponse: true},
+				},
+				Fields: []*resolve.Field{
+					{
+						HasBuffer: true,
+						BufferID:  0,
+						Name:      []byte("hero"),
+						Value: &resolve.String{
+							Path:     []string{"hero"},
+							Nullable: true,
+						},
+					},
+					{
+						HasBuffer: true,
+						BufferID:  0,
+						Name:      []byte("villain"),
+						Value: &resolve.String{
+							Path:     []string{"villain"},
+							Nullable: true,
+						},
+					},
+					{
+						HasBuffer: true,
+						BufferID:  0,
+						Name:      []byte("stats"),
+						Value: &resolve.Object{
+							Path:     []string{"stats"},
+							Nullable: true,
+							Fields: []*resolve.Field{
+								{
+									Name: []byte("wins"),
+									Value: &resolve.Integer{
+										Path:     []string{"wins"},
+										Nullable: true,
+									},
+								},
+								{
+									Name: []byte("losses"),
+									Value: &resolve.Integer{
+										Path:     []string{"losses"},
+										Nullable: true,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, plan.Configuration{
+		DataSources: []plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{
+					{
+						TypeName:   "Query",
+						FieldNames: []string{"hero", "villain", "stats"},
+					},
+				},
+				ChildNodes: []plan.TypeField{
+					{
+						TypeName:   "Stats",
+						FieldNames: []string{"wins", "losses"},
+					},
+				},
+				Factory: &Factory{},
+				Custom: ConfigJson(Configuration{
+					Fetch: FetchConfiguration{
+						URL:                 "https://swapi.com/graphql",
+						MaxFieldsPerRequest: 1,
+					},
+				}),
+			},
+		},
+		Fields: []plan.FieldConfiguration{
+			{
+				TypeName:  "Query",
+				FieldName: "hero",
+				Arguments: []plan.ArgumentConfiguration{
+					{
+						Name:       "a",
+						SourceType: plan.FieldArgumentSource,
+					},
+				},
+			},
+			{
+				TypeName:  "Query",
+				FieldName: "villain",
+				Arguments: []plan.ArgumentConfiguration{
+					{
+						Name:       "b",
+						SourceType: plan.FieldArgumentSource,
+					},
+				},
+			},
+		},
+		DisableResolveFieldPositions: true,
+	}))
+
+	t.Run("root field with skip fetch conditions is planned as a separate fetch", RunTest(`
+		schema {
+			query: Query
+		}
+
+		type Query {
+			hero: String
+			recommendations(userId: String, mode: String): [String]
+		}`, `
+		query MyQuery($userId: String) {
+			hero
+			recommendations(userId: $userId, mode: "fast")
+		}
+	`, "MyQuery", &plan.SynchronousResponsePlan{
+		Response: &resolve.GraphQLResponse{
+			Data: &resolve.Object{
+				Fetch: &resolve.ParallelFetch{
+					Fetches: []resolve.Fetch{
+						&resolve.SingleFetch{
+							DataSource:            &Source{},
+							BufferId:              0,
+							Input:                 `{"method":"POST","url":"https://swapi.com/graphql","body":{"query":"{hero}"}}`,
+							DataSourceIdentifier:  []byte("graphql_datasource.Source"),
+							ProcessResponseConfig: resolve.ProcessResponseConfig{ExtractGraphqlResponse: true},
+						},
+						&resolve.SingleFetch{
+							DataSource: &Source{},
+							BufferId:   1,
+							Input:      `{"method":"POST","url":"https://swapi.com/graphql","body":{"query":"query($userId: String, $a: String){recommendations(userId: $userId, mode: $a)}","variables":{"a":$$1$$,"userId":$$0$$}}}`,
+							Variables: resolve.NewVariables(
+								&resolve.ContextVariable{
+									Path:     []string{"userId"},
+									Renderer: resolve.NewJSONVariableRendererWithValidation(`{"type":["string","null"]}`),
+								},
+								&resolve.ContextVariable{
+									Path:     []string{"a"},
+									Renderer: resolve.NewJSONVariableRendererWithValidation(`{"type":["string","null"]}`),
+								},
+							),
+							DataSourceIdentifier:  []byte("graphql_datasource.Source"),
+							ProcessResponseConfig: resolve.ProcessResponseConfig{ExtractGraphqlResponse: true},
+							SkipConditions: resolve.SkipConditions{
+								{
+									VariableName: "userId",
+								},
+								{
+									VariableName: "a",
+									Equals:       []byte(`"none"`),
+								},
+							},
+						},
+					},
+				},
+				Fields: []*resolve.Field{
+					{
+						HasBuffer: true,
+						BufferID:  0,
+						Name:      []byte("hero"),
+						Value: &resolve.String{
+							Path:     []string{"hero"},
+							Nullable: true,
+						},
+					},
+					{
+						HasBuffer: true,
+						BufferID:  1,
+						Name:      []byte("recommendations"),
+						Value: &resolve.Array{
+							Path:     []string{"recommendations"},
+							Nullable: true,
+							Item: &resolve.String{
+								Nullable: true,
+							},
+						},
+						FetchSkippedValue: []byte(`[]`),
+					},
+				},
+			},
+		},
+	}, plan.Configuration{
+		DataSources: []plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{
+					{
+						TypeName:   "Query",
+						FieldNames: []string{"hero", "recommendations"},
+					},
+				},
+				Factory: &Factory{},
+				Custom: ConfigJson(Configuration{
+					Fetch: FetchConfiguration{
+						URL: "https://swapi.com/graphql",
+					},
+				}),
+			},
+		},
+		Fields: []plan.FieldConfiguration{
+			{
+				TypeName:  "Query",
+				FieldName: "recommendations",
+				Arguments: []plan.ArgumentConfiguration{
+					{
+						Name:       "userId",
+						SourceType: plan.FieldArgumentSource,
+					},
+					{
+						Name:       "mode",
+						SourceType: plan.FieldArgumentSource,
+					},
+				},
+				SkipFetch: &plan.SkipFetchConfiguration{
+					Conditions: []plan.SkipFetchCondition{
+						{
+							ArgumentName: "userId",
+						},
+						{
+							ArgumentName: "mode",
+							Equals:       []byte(`"none"`),
+						},
+					},
+					DefaultValue: []byte(`[]`),
+				},
+			},
+		},
+		DisableResolveFieldPositions: true,
+	}))
+
 	t.Run("simple named Query", RunTest(starWarsSchema, `
 		query MyQuery($id: ID!) {
 			droid(id: $id){
@@ -665,16 +933,154 @@ func TestGraphQLDataSource(t *testing.T) {
 									Value: &resolve.String{
 										Path: []string{"id"},
 									},
-									IncludeDirectiveDefined: true,
-									IncludeVariableName:     "include",
-								},
-								{
-									Name: []byte("displayName"),
-									Value: &resolve.String{
-										Path: []string{"displayName"},
-									},
-									IncludeDirectiveDefined: true,
-									IncludeVariableName:     "include",
+									IncludeDirectiveDefined: true,
+									IncludeVariableName:     "include",
+								},
+								{
+									Name: []byte("displayName"),
+									Value: &resolve.String{
+										Path: []string{"displayName"},
+									},
+									IncludeDirectiveDefined: true,
+									IncludeVariableName:     "include",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, plan.Configuration{
+		DataSources: []plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{
+					{
+						TypeName:   "Query",
+						FieldNames: []string{"user"},
+					},
+				},
+				ChildNodes: []plan.TypeField{
+					{
+						TypeName:   "User",
+						FieldNames: []string{"id", "displayName", "isLoggedIn"},
+					},
+					{
+						TypeName:   "RegisteredUser",
+						FieldNames: []string{"id", "displayName", "isLoggedIn"},
+					},
+				},
+				Factory: &Factory{},
+				Custom: ConfigJson(Configuration{
+					Fetch: FetchConfiguration{
+						URL: "https://swapi.com/graphql",
+					},
+				}),
+			},
+		},
+		Fields:                       []plan.FieldConfiguration{},
+		DisableResolveFieldPositions: true,
+	}))
+
+	t.Run("skip directive with inline value true", RunTest(interfaceSelectionSchema, `
+		query MyQuery {
+			user {
+				id
+				displayName @skip(if: true)
+			}
+		}
+	`, "MyQuery", &plan.SynchronousResponsePlan{
+		Response: &resolve.GraphQLResponse{
+			Data: &resolve.Object{
+				Fetch: &resolve.SingleFetch{
+					DataSource:            &Source{},
+					BufferId:              0,
+					Input:                 `{"method":"POST","url":"https://swapi.com/graphql","body":{"query":"{user {__typename id}}"}}`,
+					DataSourceIdentifier:  []byte("graphql_datasource.Source"),
+					ProcessResponseConfig: resolve.ProcessResponseConfig{ExtractGraphqlResponse: true},
+				},
+				Fields: []*resolve.Field{
+					{
+						HasBuffer: true,
+						BufferID:  0,
+						Name:      []byte("user"),
+						Value: &resolve.Object{
+							Path:     []string{"user"},
+							Nullable: true,
+							Fields: []*resolve.Field{
+								{
+									Name: []byte("id"),
+									Value: &resolve.String{
+										Path: []string{"id"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}, plan.Configuration{
+		DataSources: []plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{
+					{
+						TypeName:   "Query",
+						FieldNames: []string{"user"},
+					},
+				},
+				ChildNodes: []plan.TypeField{
+					{
+						TypeName:   "User",
+						FieldNames: []string{"id", "displayName", "isLoggedIn"},
+					},
+					{
+						TypeName:   "RegisteredUser",
+						FieldNames: []string{"id", "displayName", "isLoggedIn"},
+					},
+				},
+				Factory: &Factory{},
+				Custom: ConfigJson(Configuration{
+					Fetch: FetchConfiguration{
+						URL: "https://swapi.com/graphql",
+					},
+				}),
+			},
+		},
+		Fields:                       []plan.FieldConfiguration{},
+		DisableResolveFieldPositions: true,
+	}))
+
+	t.Run("skip directive with variable default value folded at planning time", RunTest(interfaceSelectionSchema, `
+		query MyQuery ($skip: Boolean! = true) {
+			user {
+				id
+				displayName @skip(if: $skip)
+			}
+		}
+	`, "MyQuery", &plan.SynchronousResponsePlan{
+		Response: &resolve.GraphQLResponse{
+			Data: &resolve.Object{
+				Fetch: &resolve.SingleFetch{
+					DataSource:            &Source{},
+					BufferId:              0,
+					Input:                 `{"method":"POST","url":"https://swapi.com/graphql","body":{"query":"{user {__typename id}}"}}`,
+					DataSourceIdentifier:  []byte("graphql_datasource.Source"),
+					ProcessResponseConfig: resolve.ProcessResponseConfig{ExtractGraphqlResponse: true},
+				},
+				Fields: []*resolve.Field{
+					{
+						HasBuffer: true,
+						BufferID:  0,
+						Name:      []byte("user"),
+						Value: &resolve.Object{
+							Path:     []string{"user"},
+							Nullable: true,
+							Fields: []*resolve.Field{
+								{
+									Name: []byte("id"),
+									Value: &resolve.String{
+										Path: []string{"id"},
+									},
 								},
 							},
 						},
@@ -711,13 +1117,16 @@ func TestGraphQLDataSource(t *testing.T) {
 		},
 		Fields:                       []plan.FieldConfiguration{},
 		DisableResolveFieldPositions: true,
+		FoldSkipIncludeVariables:     true,
 	}))
 
-	t.Run("skip directive with inline value true", RunTest(interfaceSelectionSchema, `
-		query MyQuery {
+	t.Run("include directive on inline fragment with variable default value folded at planning time", RunTest(interfaceSelectionSchema, `
+		query MyQuery ($include: Boolean! = true) {
 			user {
 				id
-				displayName @skip(if: true)
+				... @include(if: $include) {
+					displayName
+				}
 			}
 		}
 	`, "MyQuery", &plan.SynchronousResponsePlan{
@@ -726,7 +1135,7 @@ func TestGraphQLDataSource(t *testing.T) {
 				Fetch: &resolve.SingleFetch{
 					DataSource:            &Source{},
 					BufferId:              0,
-					Input:                 `{"method":"POST","url":"https://swapi.com/graphql","body":{"query":"{user {__typename id}}"}}`,
+					Input:                 `{"method":"POST","url":"https://swapi.com/graphql","body":{"query":"{user {__typename id displayName}}"}}`,
 					DataSourceIdentifier:  []byte("graphql_datasource.Source"),
 					ProcessResponseConfig: resolve.ProcessResponseConfig{ExtractGraphqlResponse: true},
 				},
@@ -745,6 +1154,12 @@ func TestGraphQLDataSource(t *testing.T) {
 										Path: []string{"id"},
 									},
 								},
+								{
+									Name: []byte("displayName"),
+									Value: &resolve.String{
+										Path: []string{"displayName"},
+									},
+								},
 							},
 						},
 					},
@@ -780,6 +1195,7 @@ func TestGraphQLDataSource(t *testing.T) {
 		},
 		Fields:                       []plan.FieldConfiguration{},
 		DisableResolveFieldPositions: true,
+		FoldSkipIncludeVariables:     true,
 	}))
 
 	t.Run("skip directive with inline value false", RunTest(interfaceSelectionSchema, `
@@ -3406,7 +3822,7 @@ func TestGraphQLDataSource(t *testing.T) {
 				Data: &resolve.Object{
 					Fetch: &resolve.SingleFetch{
 						BufferId:   0,
-						Input:      `{"method":"POST","url":"http://api.com","body":{"query":"mutation($name: String!, $personal: Boolean!){__typename namespaceCreate(input: {name: $name,personal: $personal}){__typename ... on NamespaceCreated {namespace {id name}} ... on Error {code message}}}","variables":{"personal":$$1$$,"name":$$0$$}}}`,
+						Input:      `{"method":"POST","url":"http://api.com","body":{"query":"mutation($name: String!, $personal: Boolean!){namespaceCreate(input: {name: $name,personal: $personal}){__typename ... on NamespaceCreated {namespace {id name}} ... on Error {code message}}}","variables":{"personal":$$1$$,"name":$$0$$}}}`,
 						DataSource: &Source{},
 						Variables: resolve.NewVariables(
 							&resolve.ContextVariable{
@@ -3425,10 +3841,9 @@ func TestGraphQLDataSource(t *testing.T) {
 					Fields: []*resolve.Field{
 						{
 							Name: []byte("__typename"),
-							Value: &resolve.String{
-								Path:       []string{"__typename"},
-								Nullable:   false,
-								IsTypeName: true,
+							Value: &resolve.Computed{
+								Segments:       []resolve.ComputedSegment{{Data: []byte("Mutation")}},
+								RenderAsString: true,
 							},
 						},
 						{
@@ -3664,7 +4079,7 @@ func TestGraphQLDataSource(t *testing.T) {
 			Trigger: resolve.GraphQLSubscriptionTrigger{
 				Input: []byte(`{"url":"wss://swapi.com/graphql","body":{"query":"subscription{remainingJedis}"}}`),
 				Source: &SubscriptionSource{
-					NewGraphQLSubscriptionClient(http.DefaultClient, http.DefaultClient, ctx),
+					client: NewGraphQLSubscriptionClient(http.DefaultClient, http.DefaultClient, ctx),
 				},
 			},
 			Response: &resolve.GraphQLResponse{
@@ -4156,7 +4571,7 @@ func TestGraphQLDataSource(t *testing.T) {
 				Data: &resolve.Object{
 					Fetch: &resolve.SingleFetch{
 						BufferId:   0,
-						Input:      `{"method":"POST","url":"http://user.service","body":{"query":"query($a: ID!){user(id: $a){id name {first last} username birthDate __typename ssn}}","variables":{"a":$$0$$}}}`,
+						Input:      `{"method":"POST","url":"http://user.service","body":{"query":"query($a: ID!){user(id: $a){id name {first last} username birthDate ssn}}","variables":{"a":$$0$$}}}`,
 						DataSource: &Source{},
 						Variables: resolve.NewVariables(
 							&resolve.ObjectVariable{
@@ -4385,7 +4800,7 @@ func TestGraphQLDataSource(t *testing.T) {
 						},
 						Federation: FederationConfiguration{
 							Enabled:    true,
-							ServiceSDL: "extend type Query { me: User user(id: ID!): User} extend type Mutation { login( username: String! password: String! ): User} type User @key(fields: \"id\") { id: ID! name: Name username: String birthDate(locale: String): String account: AccountType metadata: [UserMetadata] ssn: String} type Name { first: String last: String } type PasswordAccount @key(fields: \"email\") { email: String! } type SMSAccount @key(fields: \"number\") { number: String } union AccountType = PasswordAccount | SMSAccounttype UserMetadata { name: String address: String description: String }",
+							ServiceSDL: "extend type Query { me: User user(id: ID!): User} extend type Mutation { login( username: String! password: String! ): User} type User @key(fields: \"id\") { id: ID! name: Name username: String birthDate(locale: String): String account: AccountType metadata: [UserMetadata] ssn: String} type Name { first: String last: String } type PasswordAccount @key(fields: \"email\") { email: String! } type SMSAccount @key(fields: \"number\") { number: String } union AccountType = PasswordAccount | SMSAccount type UserMetadata { name: String address: String description: String }",
 						},
 					}),
 					Factory: federationFactory,
@@ -4473,7 +4888,7 @@ func TestGraphQLDataSource(t *testing.T) {
 				Data: &resolve.Object{
 					Fetch: &resolve.SingleFetch{
 						BufferId:   0,
-						Input:      `{"method":"POST","url":"http://user.service","body":{"query":"query($a: ID!){user(id: $a){id name {first last} username birthDate __typename ssn}}","variables":{"a":$$0$$}}}`,
+						Input:      `{"method":"POST","url":"http://user.service","body":{"query":"query($a: ID!){user(id: $a){id name {first last} username birthDate ssn}}","variables":{"a":$$0$$}}}`,
 						DataSource: &Source{},
 						Variables: resolve.NewVariables(
 							&resolve.ObjectVariable{
@@ -4702,7 +5117,7 @@ func TestGraphQLDataSource(t *testing.T) {
 						},
 						Federation: FederationConfiguration{
 							Enabled:    true,
-							ServiceSDL: "extend type Query { me: User user(id: ID!): User} extend type Mutation { login( username: String! password: String! ): User} type User @key(fields: \"id\") { id: ID! name: Name username: String birthDate(locale: String): String account: AccountType metadata: [UserMetadata] ssn: String} type Name { first: String last: String } type PasswordAccount @key(fields: \"email\") { email: String! } type SMSAccount @key(fields: \"number\") { number: String } union AccountType = PasswordAccount | SMSAccounttype UserMetadata { name: String address: String description: String }",
+							ServiceSDL: "extend type Query { me: User user(id: ID!): User} extend type Mutation { login( username: String! password: String! ): User} type User @key(fields: \"id\") { id: ID! name: Name username: String birthDate(locale: String): String account: AccountType metadata: [UserMetadata] ssn: String} type Name { first: String last: String } type PasswordAccount @key(fields: \"email\") { email: String! } type SMSAccount @key(fields: \"number\") { number: String } union AccountType = PasswordAccount | SMSAccount type UserMetadata { name: String address: String description: String }",
 						},
 					}),
 					Factory: federationFactory,
@@ -5877,6 +6292,7 @@ func TestGraphQLDataSource(t *testing.T) {
 											ExtractFederationEntities: true,
 										},
 										SetTemplateOutputToNullOnVariableNull: true,
+										OnTypeNames:                           [][]byte{[]byte("User")},
 									},
 									BatchFactory: batchFactory,
 								},
@@ -6072,6 +6488,7 @@ func TestGraphQLDataSource(t *testing.T) {
 											ExtractFederationEntities: true,
 										},
 										SetTemplateOutputToNullOnVariableNull: true,
+										OnTypeNames:                           [][]byte{[]byte("User")},
 									},
 									BatchFactory: batchFactory,
 								},
@@ -7289,6 +7706,7 @@ func TestGraphQLDataSource(t *testing.T) {
 															ExtractFederationEntities: true,
 														},
 														SetTemplateOutputToNullOnVariableNull: true,
+														OnTypeNames:                           [][]byte{[]byte("Cat"), []byte("Dog")},
 													},
 													BatchFactory: batchFactory,
 												},
@@ -7440,6 +7858,246 @@ func TestGraphQLDataSource(t *testing.T) {
 			DisableResolveFieldPositions: true,
 		}))
 
+	t.Run("Federation with union list members resolved by different subgraphs", RunTest(
+		`
+        type Query {
+            search: [SearchResult!]!
+        }
+        type Cat {
+            id: ID!
+            name: String!
+        }
+        type Dog {
+            id: ID!
+            bark: String!
+        }
+        union SearchResult = Cat | Dog
+        `,
+		`
+        query SearchQuery {
+            search {
+                __typename
+                ... on Cat {
+                    name
+                }
+                ... on Dog {
+                    bark
+                }
+            }
+        }
+        `,
+		"SearchQuery",
+		&plan.SynchronousResponsePlan{
+			Response: &resolve.GraphQLResponse{
+				Data: &resolve.Object{
+					Fetch: &resolve.SingleFetch{
+						BufferId:              0,
+						Input:                 `{"method":"POST","url":"http://search.service","body":{"query":"{search {__typename ... on Cat {id} ... on Dog {id}}}"}}`,
+						DataSource:            &Source{},
+						DataSourceIdentifier:  []byte("graphql_datasource.Source"),
+						ProcessResponseConfig: resolve.ProcessResponseConfig{ExtractGraphqlResponse: true},
+					},
+					Fields: []*resolve.Field{
+						{
+							HasBuffer: true,
+							BufferID:  0,
+							Name:      []byte("search"),
+							Value: &resolve.Array{
+								Path:     []string{"search"},
+								Nullable: false,
+								Item: &resolve.Object{
+									Fetch: &resolve.ParallelFetch{
+										Fetches: []resolve.Fetch{
+											&resolve.BatchFetch{
+												Fetch: &resolve.SingleFetch{
+													BufferId: 1,
+													Input:    `{"method":"POST","url":"http://cat.service","body":{"query":"query($representations: [_Any!]!){_entities(representations: $representations){__typename ... on Cat {name}}}","variables":{"representations":[{"id":$$1$$,"__typename":$$0$$}]}}}`,
+													Variables: resolve.NewVariables(
+														&resolve.ObjectVariable{
+															Path:     []string{"__typename"},
+															Renderer: resolve.NewJSONVariableRendererWithValidation(`{"type":"string"}`),
+														},
+														&resolve.ObjectVariable{
+															Path:     []string{"id"},
+															Renderer: resolve.NewJSONVariableRendererWithValidation(`{"type":["string","integer"]}`),
+														},
+													),
+													DataSource:           &Source{},
+													DataSourceIdentifier: []byte("graphql_datasource.Source"),
+													ProcessResponseConfig: resolve.ProcessResponseConfig{
+														ExtractGraphqlResponse:    true,
+														ExtractFederationEntities: true,
+													},
+													SetTemplateOutputToNullOnVariableNull: true,
+													OnTypeNames:                           [][]byte{[]byte("Cat")},
+												},
+												BatchFactory: batchFactory,
+											},
+											&resolve.BatchFetch{
+												Fetch: &resolve.SingleFetch{
+													BufferId: 2,
+													Input:    `{"method":"POST","url":"http://dog.service","body":{"query":"query($representations: [_Any!]!){_entities(representations: $representations){__typename ... on Dog {bark}}}","variables":{"representations":[{"id":$$1$$,"__typename":$$0$$}]}}}`,
+													Variables: resolve.NewVariables(
+														&resolve.ObjectVariable{
+															Path:     []string{"__typename"},
+															Renderer: resolve.NewJSONVariableRendererWithValidation(`{"type":"string"}`),
+														},
+														&resolve.ObjectVariable{
+															Path:     []string{"id"},
+															Renderer: resolve.NewJSONVariableRendererWithValidation(`{"type":["string","integer"]}`),
+														},
+													),
+													DataSource:           &Source{},
+													DataSourceIdentifier: []byte("graphql_datasource.Source"),
+													ProcessResponseConfig: resolve.ProcessResponseConfig{
+														ExtractGraphqlResponse:    true,
+														ExtractFederationEntities: true,
+													},
+													SetTemplateOutputToNullOnVariableNull: true,
+													OnTypeNames:                           [][]byte{[]byte("Dog")},
+												},
+												BatchFactory: batchFactory,
+											},
+										},
+									},
+									Nullable: false,
+									Fields: []*resolve.Field{
+										{
+											Name: []byte("__typename"),
+											Value: &resolve.String{
+												Path:       []string{"__typename"},
+												IsTypeName: true,
+											},
+										},
+										{
+											HasBuffer: true,
+											BufferID:  1,
+											Name:      []byte("name"),
+											Value: &resolve.String{
+												Path: []string{"name"},
+											},
+											OnTypeName: []byte("Cat"),
+										},
+										{
+											HasBuffer: true,
+											BufferID:  2,
+											Name:      []byte("bark"),
+											Value: &resolve.String{
+												Path: []string{"bark"},
+											},
+											OnTypeName: []byte("Dog"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		plan.Configuration{
+			DataSources: []plan.DataSourceConfiguration{
+				{
+					RootNodes: []plan.TypeField{
+						{
+							TypeName:   "Query",
+							FieldNames: []string{"search"},
+						},
+					},
+					ChildNodes: []plan.TypeField{
+						{
+							TypeName:   "Cat",
+							FieldNames: []string{"id"},
+						},
+						{
+							TypeName:   "Dog",
+							FieldNames: []string{"id"},
+						},
+					},
+					Custom: ConfigJson(Configuration{
+						Fetch: FetchConfiguration{
+							URL: "http://search.service",
+						},
+						Federation: FederationConfiguration{
+							Enabled: true,
+							ServiceSDL: `
+                                extend type Query {
+                                    search: [SearchResult!]!
+                                }
+                                union SearchResult = Cat | Dog
+                                extend type Cat @key(fields: "id") {
+                                    id: ID! @external
+                                }
+                                extend type Dog @key(fields: "id") {
+                                    id: ID! @external
+                                }
+                            `,
+						},
+					}),
+					Factory: federationFactory,
+				},
+				{
+					RootNodes: []plan.TypeField{
+						{
+							TypeName:   "Cat",
+							FieldNames: []string{"id", "name"},
+						},
+					},
+					Custom: ConfigJson(Configuration{
+						Fetch: FetchConfiguration{
+							URL: "http://cat.service",
+						},
+						Federation: FederationConfiguration{
+							Enabled: true,
+							ServiceSDL: `
+                                type Cat @key(fields: "id") {
+                                    id: ID!
+                                    name: String!
+                                }
+                            `,
+						},
+					}),
+					Factory: federationFactory,
+				},
+				{
+					RootNodes: []plan.TypeField{
+						{
+							TypeName:   "Dog",
+							FieldNames: []string{"id", "bark"},
+						},
+					},
+					Custom: ConfigJson(Configuration{
+						Fetch: FetchConfiguration{
+							URL: "http://dog.service",
+						},
+						Federation: FederationConfiguration{
+							Enabled: true,
+							ServiceSDL: `
+                                type Dog @key(fields: "id") {
+                                    id: ID!
+                                    bark: String!
+                                }
+                            `,
+						},
+					}),
+					Factory: federationFactory,
+				},
+			},
+			Fields: []plan.FieldConfiguration{
+				{
+					TypeName:       "Cat",
+					FieldName:      "name",
+					RequiresFields: []string{"id"},
+				},
+				{
+					TypeName:       "Dog",
+					FieldName:      "bark",
+					RequiresFields: []string{"id"},
+				},
+			},
+			DisableResolveFieldPositions: true,
+		}))
+
 	t.Run("custom scalar replacement query", RunTest(starWarsSchema, `
 		query MyQuery($droidId: ID!, $reviewId: ID!){
 			droid(id: $droidId){
@@ -7745,6 +8403,15 @@ func (f FailingSubscriptionClient) Subscribe(_ context.Context, _ GraphQLSubscri
 	return errSubscriptionClientFail
 }
 
+type optionsCapturingSubscriptionClient struct {
+	options GraphQLSubscriptionOptions
+}
+
+func (o *optionsCapturingSubscriptionClient) Subscribe(_ context.Context, options GraphQLSubscriptionOptions, _ chan<- []byte) error {
+	o.options = options
+	return nil
+}
+
 func TestSubscriptionSource_Start(t *testing.T) {
 	chatServer := httptest.NewServer(subscriptiontesting.ChatGraphQLEndpointHandler())
 	defer chatServer.Close()
@@ -7792,6 +8459,22 @@ func TestSubscriptionSource_Start(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("should add upstream headers to the subscription options", func(t *testing.T) {
+		client := &optionsCapturingSubscriptionClient{}
+		source := SubscriptionSource{
+			client: client,
+			headers: newUpstreamHeaders(FetchConfiguration{
+				UserAgent:     "graphql-go-tools",
+				SecretHeaders: map[string]string{"X-Api-Key": "UPSTREAM_API_KEY"},
+			}, secrets.SecretProviderFunc(func(_ context.Context, _ string) (string, error) {
+				return "secret", nil
+			})),
+		}
+		err := source.Start(context.Background(), []byte(`{"url":"wss://example.com","body":{"query":"subscription{counter}"},"header":{"user-agent":["forwarded"]}}`), nil)
+		require.NoError(t, err)
+		assert.Equal(t, http.Header{"User-Agent": []string{"graphql-go-tools"}, "X-Api-Key": []string{"secret"}}, client.options.Header)
+	})
+
 	t.Run("should return error when subscription client returns an error", func(t *testing.T) {
 		source := SubscriptionSource{client: FailingSubscriptionClient{}}
 		err := source.Start(context.Background(), []byte(`{"url": "", "body": {}, "header": null}`), nil)
@@ -8165,6 +8848,157 @@ func TestSource_Load(t *testing.T) {
 			assert.Equal(t, `{"variables":{"b":null}}`, buf.String())
 		})
 	})
+	t.Run("upstream headers", func(t *testing.T) {
+		headerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprintf(w, `{"userAgent":"%s","apiKey":"%s","authorization":"%s"}`, r.Header.Get("User-Agent"), r.Header.Get("X-Api-Key"), r.Header.Get("Authorization"))
+		}))
+		defer headerServer.Close()
+
+		secretProvider := secrets.SecretProviderFunc(func(_ context.Context, secretName string) (string, error) {
+			if secretName == "UPSTREAM_API_KEY" {
+				return "secret", nil
+			}
+			return "", errors.New("unknown secret")
+		})
+
+		var input []byte
+		input = httpclient.SetInputHeader(input, []byte(`{"Authorization":["forwarded"]}`))
+		input = httpclient.SetInputURL(input, []byte(headerServer.URL))
+
+		t.Run("should send static and secret headers overriding forwarded headers", func(t *testing.T) {
+			src := &Source{
+				httpClient: &http.Client{},
+				headers: newUpstreamHeaders(FetchConfiguration{
+					UserAgent:     "graphql-go-tools",
+					StaticHeaders: http.Header{"Authorization": []string{"static"}},
+					SecretHeaders: map[string]string{"X-Api-Key": "UPSTREAM_API_KEY"},
+				}, secretProvider),
+			}
+			buf := bytes.NewBuffer(nil)
+
+			require.NoError(t, src.Load(context.Background(), input, buf))
+			assert.Equal(t, `{"userAgent":"graphql-go-tools","apiKey":"secret","authorization":"static"}`, buf.String())
+		})
+
+		t.Run("should not send the request if a secret can't be resolved", func(t *testing.T) {
+			src := &Source{
+				httpClient: &http.Client{},
+				headers: newUpstreamHeaders(FetchConfiguration{
+					SecretHeaders: map[string]string{"X-Api-Key": "NOT_EXISTING"},
+				}, secretProvider),
+			}
+			buf := bytes.NewBuffer(nil)
+
+			err := src.Load(context.Background(), input, buf)
+			assert.EqualError(t, err, "unable to resolve secret header X-Api-Key: unknown secret")
+			assert.Equal(t, "", buf.String())
+		})
+
+		t.Run("should read secrets from environment variables by default", func(t *testing.T) {
+			t.Setenv("UPSTREAM_API_KEY", "from-env")
+			src := &Source{
+				httpClient: &http.Client{},
+				headers: newUpstreamHeaders(FetchConfiguration{
+					SecretHeaders: map[string]string{"X-Api-Key": "UPSTREAM_API_KEY"},
+				}, nil),
+			}
+			buf := bytes.NewBuffer(nil)
+
+			require.NoError(t, src.Load(context.Background(), input, buf))
+			assert.Equal(t, `{"userAgent":"Go-http-client/1.1","apiKey":"from-env","authorization":"forwarded"}`, buf.String())
+		})
+	})
+	t.Run("split queries", func(t *testing.T) {
+		splitServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			query, _ := jsonparser.GetString(body, "query")
+			switch query {
+			case "query($a: String){hero(a: $a)}":
+				a, _ := jsonparser.GetString(body, "variables", "a")
+				_, _ = fmt.Fprintf(w, `{"data":{"hero":"%s"}}`, a)
+			case "{stats {wins losses}}":
+				_, _ = fmt.Fprint(w, `{"data":{"stats":{"wins":1,"losses":2}}}`)
+			case "{quote}":
+				_, _ = fmt.Fprint(w, `{"data":{"quote":"\"I am your father\""}}`)
+			case "{nothing}":
+				_, _ = fmt.Fprint(w, `{"data":null}`)
+			case "{broken}":
+				conn, _, _ := w.(http.Hijacker).Hijack()
+				_ = conn.Close()
+			default:
+				_, _ = fmt.Fprint(w, `{"data":null,"errors":[{"message":"unexpected query"}]}`)
+			}
+		}))
+		defer splitServer.Close()
+
+		src := &Source{httpClient: &http.Client{}}
+
+		t.Run("should send a request per split query and merge the responses", func(t *testing.T) {
+			var input []byte
+			input = httpclient.SetInputBodyWithPath(input, []byte(`{"a":"Luke"}`), "variables")
+			input = httpclient.SetInputBodyWithPath(input, []byte(`query($a: String){hero(a: $a) stats {wins losses}}`), "query")
+			input = httpclient.SetInputURL(input, []byte(splitServer.URL))
+			input = setInputSplitQueries(input, [][]byte{[]byte(`query($a: String){hero(a: $a)}`), []byte(`{stats {wins losses}}`)})
+			buf := bytes.NewBuffer(nil)
+
+			require.NoError(t, src.Load(context.Background(), input, buf))
+			assert.Equal(t, `{"data":{"hero":"Luke","stats":{"wins":1,"losses":2}}}`, buf.String())
+		})
+
+		t.Run("should merge errors of all responses", func(t *testing.T) {
+			var input []byte
+			input = httpclient.SetInputBodyWithPath(input, []byte(`{"a":"Luke"}`), "variables")
+			input = httpclient.SetInputURL(input, []byte(splitServer.URL))
+			input = setInputSplitQueries(input, [][]byte{[]byte(`query($a: String){hero(a: $a)}`), []byte(`{villain}`)})
+			buf := bytes.NewBuffer(nil)
+
+			require.NoError(t, src.Load(context.Background(), input, buf))
+			assert.Equal(t, `{"data":{"hero":"Luke"},"errors":[{"message":"unexpected query"}]}`, buf.String())
+		})
+
+		t.Run("should keep string values as they are", func(t *testing.T) {
+			var input []byte
+			input = httpclient.SetInputURL(input, []byte(splitServer.URL))
+			input = setInputSplitQueries(input, [][]byte{[]byte(`{quote}`), []byte(`{stats {wins losses}}`)})
+			buf := bytes.NewBuffer(nil)
+
+			require.NoError(t, src.Load(context.Background(), input, buf))
+			assert.Equal(t, `{"data":{"quote":"\"I am your father\"","stats":{"wins":1,"losses":2}}}`, buf.String())
+		})
+
+		t.Run("should write null data if the data of all responses is null", func(t *testing.T) {
+			var input []byte
+			input = httpclient.SetInputURL(input, []byte(splitServer.URL))
+			input = setInputSplitQueries(input, [][]byte{[]byte(`{nothing}`), []byte(`{nothing}`)})
+			buf := bytes.NewBuffer(nil)
+
+			require.NoError(t, src.Load(context.Background(), input, buf))
+			assert.Equal(t, `{"data":null}`, buf.String())
+		})
+
+		t.Run("should keep the data of the succeeded parts if a part fails", func(t *testing.T) {
+			var input []byte
+			input = httpclient.SetInputURL(input, []byte(splitServer.URL))
+			input = setInputSplitQueries(input, [][]byte{[]byte(`{stats {wins losses}}`), []byte(`{broken}`)})
+			buf := bytes.NewBuffer(nil)
+
+			require.NoError(t, src.Load(context.Background(), input, buf))
+			data, _, _, err := jsonparser.Get(buf.Bytes(), "data")
+			require.NoError(t, err)
+			assert.Equal(t, `{"stats":{"wins":1,"losses":2}}`, string(data))
+			message, err := jsonparser.GetString(buf.Bytes(), "errors", "[0]", "message")
+			require.NoError(t, err)
+			assert.Contains(t, message, "EOF")
+		})
+
+		t.Run("should return the error if all parts fail", func(t *testing.T) {
+			var input []byte
+			input = httpclient.SetInputURL(input, []byte(splitServer.URL))
+			input = setInputSplitQueries(input, [][]byte{[]byte(`{broken}`), []byte(`{broken}`)})
+
+			assert.Error(t, src.Load(context.Background(), input, bytes.NewBuffer(nil)))
+		})
+	})
 }
 
 func TestUnNullVariables(t *testing.T) {
@@ -10,8 +10,9 @@ import (
 	"net/http"
 
 	"github.com/buger/jsonparser"
-	log "github.com/jensneuse/abstractlogger"
 	"github.com/r3labs/sse/v2"
+
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 var (
@@ -43,20 +44,31 @@ func (h *gqlSSEConnectionHandler) StartBlocking(sub Subscription) {
 
 	dataCh := make(chan []byte)
 	errCh := make(chan []byte)
-	defer func() {
-		close(dataCh)
-		close(errCh)
-		close(sub.next)
+	// completed is closed once the upstream completed the subscription or closed the event stream,
+	// closing next completes the subscription of the client
+	completed := make(chan struct{})
+	defer close(sub.next)
+
+	go func() {
+		defer close(completed)
+		h.subscribe(reqCtx, sub, dataCh, errCh)
 	}()
 
-	go h.subscribe(reqCtx, sub, dataCh, errCh)
-
 	for {
 		select {
+		case <-completed:
+			return
 		case data := <-dataCh:
-			sub.next <- data
+			select {
+			case sub.next <- data:
+			case <-reqCtx.Done():
+				return
+			}
 		case err := <-errCh:
-			sub.next <- err
+			select {
+			case sub.next <- err:
+			case <-reqCtx.Done():
+			}
 			return
 		case <-reqCtx.Done():
 			return
@@ -97,7 +109,7 @@ func (h *gqlSSEConnectionHandler) subscribe(ctx context.Context, sub Subscriptio
 
 			h.log.Error("failed to read event", log.Error(err))
 
-			errCh <- []byte(internalError)
+			sendSSEMessage(ctx, errCh, []byte(internalError))
 			return
 		}
 
@@ -117,7 +129,7 @@ func (h *gqlSSEConnectionHandler) subscribe(ctx context.Context, sub Subscriptio
 					continue
 				}
 
-				dataCh <- data
+				sendSSEMessage(ctx, dataCh, data)
 			case bytes.HasPrefix(line, headerEvent):
 				event := trim(line[len(headerEvent):])
 
@@ -151,11 +163,11 @@ func (h *gqlSSEConnectionHandler) subscribe(ctx context.Context, sub Subscriptio
 						if err != nil {
 							h.log.Error("failed to set errors", log.Error(err))
 
-							errCh <- []byte(internalError)
+							sendSSEMessage(ctx, errCh, []byte(internalError))
 							return
 						}
 
-						errCh <- response
+						sendSSEMessage(ctx, errCh, response)
 						return
 					} else if valueType == jsonparser.Object {
 						response := []byte(`{"errors":[]}`)
@@ -163,17 +175,17 @@ func (h *gqlSSEConnectionHandler) subscribe(ctx context.Context, sub Subscriptio
 						if err != nil {
 							h.log.Error("failed to set errors", log.Error(err))
 
-							errCh <- []byte(internalError)
+							sendSSEMessage(ctx, errCh, []byte(internalError))
 							return
 						}
 
-						errCh <- response
+						sendSSEMessage(ctx, errCh, response)
 						return
 					}
 
 				default:
 					h.log.Error("failed to parse errors", log.Error(err))
-					errCh <- []byte(internalError)
+					sendSSEMessage(ctx, errCh, []byte(internalError))
 					return
 				}
 			}
@@ -279,3 +291,11 @@ func (h *gqlSSEConnectionHandler) buildPOSTRequest(ctx context.Context) (*http.R
 
 	return req, nil
 }
+
+// sendSSEMessage forwards the message to StartBlocking unless the subscription was cancelled, StartBlocking doesn't receive anymore then
+func sendSSEMessage(ctx context.Context, ch chan []byte, msg []byte) {
+	select {
+	case ch <- msg:
+	case <-ctx.Done():
+	}
+}
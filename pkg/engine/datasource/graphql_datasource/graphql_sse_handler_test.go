@@ -223,6 +223,54 @@ func TestGraphQLSubscriptionClientSubscribe_SSE_WithEvents(t *testing.T) {
 	serverCancel()
 }
 
+func TestGraphQLSubscriptionClientSubscribe_SSE_Complete(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		_, _ = fmt.Fprintf(w, "event: next\ndata: %s\n\n", `{"data":{"messageAdded":{"text":"first"}}}`)
+		flusher.Flush()
+
+		_, _ = fmt.Fprintf(w, "event: complete\n\n")
+		flusher.Flush()
+
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	serverCtx, serverCancel := context.WithCancel(context.Background())
+	defer serverCancel()
+
+	ctx, clientCancel := context.WithCancel(context.Background())
+	defer clientCancel()
+
+	client := NewGraphQLSubscriptionClient(http.DefaultClient, http.DefaultClient, serverCtx,
+		WithReadTimeout(time.Millisecond),
+		WithLogger(logger()),
+	)
+
+	next := make(chan []byte)
+	err := client.Subscribe(ctx, GraphQLSubscriptionOptions{
+		URL: server.URL,
+		Body: GraphQLBody{
+			Query: `subscription {messageAdded(roomName: "room"){text}}`,
+		},
+		UseSSE: true,
+	}, next)
+	assert.NoError(t, err)
+
+	assert.Equal(t, `{"data":{"messageAdded":{"text":"first"}}}`, string(<-next))
+
+	select {
+	case _, ok := <-next:
+		assert.False(t, ok, "the complete event of the upstream must close next")
+	case <-time.After(time.Second):
+		t.Fatal("the subscription was not completed")
+	}
+}
+
 func TestGraphQLSubscriptionClientSubscribe_SSE_Error(t *testing.T) {
 	serverDone := make(chan struct{})
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
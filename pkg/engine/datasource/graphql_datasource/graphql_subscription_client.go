@@ -10,8 +10,10 @@ import (
 
 	"github.com/buger/jsonparser"
 	"github.com/cespare/xxhash/v2"
-	"github.com/jensneuse/abstractlogger"
 	"nhooyr.io/websocket"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+	"github.com/wundergraph/graphql-go-tools/pkg/tracing"
 )
 
 const ackWaitTimeout = 30 * time.Second
@@ -23,19 +25,20 @@ type SubscriptionClient struct {
 	streamingClient            *http.Client
 	httpClient                 *http.Client
 	engineCtx                  context.Context
-	log                        abstractlogger.Logger
+	log                        logging.Logger
 	hashPool                   sync.Pool
 	handlers                   map[uint64]ConnectionHandler
 	handlersMu                 sync.Mutex
 	wsSubProtocol              string
 	onWsConnectionInitCallback *OnWsConnectionInitCallback
+	reconnectPolicy            *ReconnectPolicy
 
 	readTimeout time.Duration
 }
 
 type Options func(options *opts)
 
-func WithLogger(log abstractlogger.Logger) Options {
+func WithLogger(log logging.Logger) Options {
 	return func(options *opts) {
 		options.log = log
 	}
@@ -59,11 +62,20 @@ func WithOnWsConnectionInitCallback(callback *OnWsConnectionInitCallback) Option
 	}
 }
 
+// WithReconnectPolicy reconnects WebSocket connections to upstreams which were lost and resubscribes their subscriptions,
+// see ReconnectPolicy. Without a policy, the subscriptions of lost connections fail.
+func WithReconnectPolicy(policy ReconnectPolicy) Options {
+	return func(options *opts) {
+		options.reconnectPolicy = &policy
+	}
+}
+
 type opts struct {
 	readTimeout                time.Duration
-	log                        abstractlogger.Logger
+	log                        logging.Logger
 	wsSubProtocol              string
 	onWsConnectionInitCallback *OnWsConnectionInitCallback
+	reconnectPolicy            *ReconnectPolicy
 }
 
 // GraphQLSubscriptionClientFactory abstracts the way of creating a new GraphQLSubscriptionClient.
@@ -81,7 +93,7 @@ func (d *DefaultSubscriptionClientFactory) NewSubscriptionClient(httpClient, str
 func NewGraphQLSubscriptionClient(httpClient, streamingClient *http.Client, engineCtx context.Context, options ...Options) *SubscriptionClient {
 	op := &opts{
 		readTimeout: time.Second,
-		log:         abstractlogger.NoopLogger,
+		log:         logging.NoopLogger,
 	}
 	for _, option := range options {
 		option(op)
@@ -100,6 +112,7 @@ func NewGraphQLSubscriptionClient(httpClient, streamingClient *http.Client, engi
 		},
 		wsSubProtocol:              op.wsSubProtocol,
 		onWsConnectionInitCallback: op.onWsConnectionInitCallback,
+		reconnectPolicy:            op.reconnectPolicy,
 	}
 }
 
@@ -126,6 +139,7 @@ func (c *SubscriptionClient) subscribeSSE(reqCtx context.Context, options GraphQ
 		next:    next,
 	}
 
+	options.Header = headerWithTraceContext(reqCtx, options.Header)
 	handler := newSSEConnectionHandler(reqCtx, c.streamingClient, options, c.log)
 
 	go func() {
@@ -202,54 +216,98 @@ func (c *SubscriptionClient) generateHandlerIDHash(options GraphQLSubscriptionOp
 }
 
 func (c *SubscriptionClient) newWSConnectionHandler(reqCtx context.Context, options GraphQLSubscriptionOptions) (ConnectionHandler, error) {
+	conn, protocol, err := c.dialWS(reqCtx, options, headerWithTraceContext(reqCtx, options.Header))
+	if err != nil {
+		return nil, err
+	}
+
+	var reconnector *wsReconnector
+	if c.reconnectPolicy != nil {
+		reconnector = newWSReconnector(*c.reconnectPolicy, options.URL, func(ctx context.Context) (*websocket.Conn, error) {
+			conn, _, err := c.dialWS(ctx, options, options.Header)
+			return conn, err
+		})
+	}
+
+	switch protocol {
+	case ProtocolGraphQLWS:
+		handler := newGQLWSConnectionHandler(c.engineCtx, conn, c.readTimeout, c.log)
+		handler.reconnector = reconnector
+		return handler, nil
+	case ProtocolGraphQLTWS:
+		handler := newGQLTWSConnectionHandler(c.engineCtx, conn, c.readTimeout, c.log)
+		handler.reconnector = reconnector
+		return handler, nil
+	default:
+		return nil, fmt.Errorf("unknown protocol %s", conn.Subprotocol())
+	}
+}
+
+// dialWS opens a WebSocket connection to the origin and waits for the acknowledgement of its initialization
+func (c *SubscriptionClient) dialWS(ctx context.Context, options GraphQLSubscriptionOptions, header http.Header) (conn *websocket.Conn, protocol string, err error) {
 	subProtocols := []string{ProtocolGraphQLWS, ProtocolGraphQLTWS}
-	if c.wsSubProtocol != "" {
+	if options.WSSubProtocol != "" {
+		subProtocols = []string{options.WSSubProtocol}
+	} else if c.wsSubProtocol != "" {
 		subProtocols = []string{c.wsSubProtocol}
 	}
 
-	conn, upgradeResponse, err := websocket.Dial(reqCtx, options.URL, &websocket.DialOptions{
+	conn, upgradeResponse, err := websocket.Dial(ctx, options.URL, &websocket.DialOptions{
 		HTTPClient:      c.httpClient,
-		HTTPHeader:      options.Header,
+		HTTPHeader:      header,
 		CompressionMode: websocket.CompressionDisabled,
 		Subprotocols:    subProtocols,
 	})
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	// Disable the maximum message size limit. Don't use MaxInt64 since
 	// the nhooyr.io/websocket doesn't handle it correctly on 32 bit systems.
 	conn.SetReadLimit(math.MaxInt32)
 	if upgradeResponse.StatusCode != http.StatusSwitchingProtocols {
-		return nil, fmt.Errorf("upgrade unsuccessful")
+		return nil, "", fmt.Errorf("upgrade unsuccessful")
 	}
 
-	connectionInitMessage, err := c.getConnectionInitMessage(reqCtx, options.URL, options.Header)
+	connectionInitMessage, err := c.getConnectionInitMessage(ctx, options.URL, options.Header)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
 	// init + ack
-	err = conn.Write(reqCtx, websocket.MessageText, connectionInitMessage)
+	err = conn.Write(ctx, websocket.MessageText, connectionInitMessage)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	if c.wsSubProtocol == "" {
-		c.wsSubProtocol = conn.Subprotocol()
+	protocol = options.WSSubProtocol
+	if protocol == "" {
+		if c.wsSubProtocol == "" {
+			c.wsSubProtocol = conn.Subprotocol()
+		}
+		protocol = c.wsSubProtocol
 	}
 
-	if err := waitForAck(reqCtx, conn); err != nil {
-		return nil, err
+	if err := waitForAck(ctx, conn); err != nil {
+		return nil, "", err
 	}
 
-	switch c.wsSubProtocol {
-	case ProtocolGraphQLWS:
-		return newGQLWSConnectionHandler(c.engineCtx, conn, c.readTimeout, c.log), nil
-	case ProtocolGraphQLTWS:
-		return newGQLTWSConnectionHandler(c.engineCtx, conn, c.readTimeout, c.log), nil
-	default:
-		return nil, fmt.Errorf("unknown protocol %s", conn.Subprotocol())
+	return conn, protocol, nil
+}
+
+// headerWithTraceContext returns a copy of the header with the trace headers of the request context injected.
+// The header is returned as is if the request context carries no trace.
+// Connections are de-duplicated by their headers, therefore the trace headers must not be injected before hashing.
+func headerWithTraceContext(reqCtx context.Context, header http.Header) http.Header {
+	traceContext, ok := tracing.FromContext(reqCtx)
+	if !ok {
+		return header
+	}
+	header = header.Clone()
+	if header == nil {
+		header = make(http.Header)
 	}
+	traceContext.Inject(header)
+	return header
 }
 
 func (c *SubscriptionClient) getConnectionInitMessage(ctx context.Context, url string, header http.Header) ([]byte, error) {
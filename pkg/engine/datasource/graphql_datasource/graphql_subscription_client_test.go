@@ -14,11 +14,13 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/buger/jsonparser"
-	ll "github.com/jensneuse/abstractlogger"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/atomic"
 	"go.uber.org/zap"
 	"nhooyr.io/websocket"
+
+	ll "github.com/wundergraph/graphql-go-tools/pkg/logging"
+	"github.com/wundergraph/graphql-go-tools/pkg/tracing"
 )
 
 func logger() ll.Logger {
@@ -64,6 +66,24 @@ func TestGetConnectionInitMessageHelper(t *testing.T) {
 	}
 }
 
+func TestHeaderWithTraceContext(t *testing.T) {
+	header := http.Header{"Authorization": []string{"secret"}}
+
+	t.Run("without trace", func(t *testing.T) {
+		assert.Equal(t, header, headerWithTraceContext(context.Background(), header))
+	})
+
+	t.Run("with trace", func(t *testing.T) {
+		ctx := tracing.NewContext(context.Background(), tracing.TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7"})
+		got := headerWithTraceContext(ctx, header)
+		assert.Equal(t, "secret", got.Get("Authorization"))
+		assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", got.Get("traceparent"))
+		assert.Empty(t, header.Get("traceparent"), "original header must not be modified")
+
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", headerWithTraceContext(ctx, nil).Get("X-B3-TraceId"))
+	})
+}
+
 func TestWebsocketSubscriptionClientDeDuplication(t *testing.T) {
 	serverDone := &sync.WaitGroup{}
 	connectedClients := atomic.NewInt64(0)
@@ -0,0 +1,261 @@
+package graphql_datasource
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"math/rand"
+	"sort"
+	"strconv"
+	"time"
+
+	"nhooyr.io/websocket"
+)
+
+const (
+	defaultReconnectInitialBackoff = 100 * time.Millisecond
+	defaultReconnectMaxBackoff     = 10 * time.Second
+	defaultReconnectMultiplier     = 2
+)
+
+// PermanentFailureBehavior defines how the subscriptions of a connection end once reconnecting failed permanently
+type PermanentFailureBehavior int
+
+const (
+	// PermanentFailureSendError sends the error of the last attempt to the subscriptions before completing them, it's the default
+	PermanentFailureSendError PermanentFailureBehavior = iota
+	// PermanentFailureComplete completes the subscriptions without an error, e.g. for clients resubscribing on completion
+	PermanentFailureComplete
+)
+
+// ConnectionState is the state of an upstream subscription connection reported to ReconnectPolicy.OnStateChange
+type ConnectionState int
+
+const (
+	// ConnectionStateReconnecting - the connection was lost, the next attempt to reconnect is made after the backoff
+	ConnectionStateReconnecting ConnectionState = iota
+	// ConnectionStateReconnected - the connection was re-established and the active subscriptions were resubscribed
+	ConnectionStateReconnected
+	// ConnectionStateFailed - reconnecting failed permanently, the subscriptions are ended, see PermanentFailureBehavior
+	ConnectionStateFailed
+)
+
+func (s ConnectionState) String() string {
+	switch s {
+	case ConnectionStateReconnecting:
+		return "reconnecting"
+	case ConnectionStateReconnected:
+		return "reconnected"
+	case ConnectionStateFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// ConnectionStateChange is a state transition of an upstream subscription connection
+type ConnectionStateChange struct {
+	URL   string
+	State ConnectionState
+	// Attempt is the number of the reconnection attempt, starting at 1
+	Attempt int
+	// Backoff is the time waited before the attempt, it's only set when reconnecting
+	Backoff time.Duration
+	// Err is the error of the lost connection or of the failed attempt, it's nil when reconnected
+	Err error
+	// Subscriptions is the number of active subscriptions of the connection
+	Subscriptions int
+}
+
+// ReconnectPolicy defines how lost WebSocket connections to upstreams are re-established, see WithReconnectPolicy.
+// The active subscriptions of a re-established connection are resubscribed, events of the upstream
+// between losing the connection and resubscribing are missed.
+// Connections which are closed by the upstream with a connection error are not re-established.
+type ReconnectPolicy struct {
+	// MaxAttempts is the max number of consecutive attempts to reconnect, negative values attempt to reconnect forever
+	// and 0 doesn't reconnect at all.
+	MaxAttempts int
+	// InitialBackoff is the time waited before the first attempt, it defaults to 100ms
+	InitialBackoff time.Duration
+	// MaxBackoff is the max time waited between attempts, it defaults to 10s
+	MaxBackoff time.Duration
+	// Multiplier is the factor the backoff grows by per attempt, it defaults to 2
+	Multiplier float64
+	// Jitter is the fraction of the backoff which is randomized, between 0 and 1,
+	// e.g. 0.2 waits between 80% and 100% of the backoff, so that upstreams aren't hit by all clients at once
+	Jitter float64
+	// DeduplicateResubscriptions resubscribes subscriptions with identical requests with a single upstream subscription,
+	// whose events are sent to each of the subscriptions
+	DeduplicateResubscriptions bool
+	// OnPermanentFailure defines how the subscriptions end once all attempts failed
+	OnPermanentFailure PermanentFailureBehavior
+	// OnStateChange is called on each state transition of the connection, it's called from the event loop of the connection and must not block
+	OnStateChange func(change ConnectionStateChange)
+}
+
+// backoff returns the time to wait before the attempt, random is a number in [0,1) used for the jitter
+func (p *ReconnectPolicy) backoff(attempt int, random float64) time.Duration {
+	initial, maxBackoff, multiplier := p.InitialBackoff, p.MaxBackoff, p.Multiplier
+	if initial <= 0 {
+		initial = defaultReconnectInitialBackoff
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = defaultReconnectMaxBackoff
+	}
+	if multiplier < 1 {
+		multiplier = defaultReconnectMultiplier
+	}
+	backoff := math.Min(float64(initial)*math.Pow(multiplier, float64(attempt-1)), float64(maxBackoff))
+	if jitter := math.Max(0, math.Min(1, p.Jitter)); jitter > 0 {
+		backoff -= backoff * jitter * random
+	}
+	return time.Duration(backoff)
+}
+
+// wsReconnector re-establishes the lost WebSocket connection of a connection handler with its ReconnectPolicy
+type wsReconnector struct {
+	policy ReconnectPolicy
+	url    string
+	dial   func(ctx context.Context) (*websocket.Conn, error)
+	random func() float64
+}
+
+func newWSReconnector(policy ReconnectPolicy, url string, dial func(ctx context.Context) (*websocket.Conn, error)) *wsReconnector {
+	return &wsReconnector{
+		policy: policy,
+		url:    url,
+		dial:   dial,
+		random: rand.Float64,
+	}
+}
+
+// reconnect attempts to re-establish the connection lost because of cause until an attempt succeeds,
+// the attempts are exhausted, the context is done or activeSubscriptions reports no active subscriptions.
+// It returns nil if the connection couldn't be re-established.
+func (r *wsReconnector) reconnect(ctx context.Context, cause error, activeSubscriptions func() int) *websocket.Conn {
+	err := cause
+	for attempt := 1; r.policy.MaxAttempts < 0 || attempt <= r.policy.MaxAttempts; attempt++ {
+		subscriptions := activeSubscriptions()
+		if subscriptions == 0 {
+			return nil
+		}
+		backoff := r.policy.backoff(attempt, r.random())
+		r.stateChanged(ConnectionStateChange{State: ConnectionStateReconnecting, Attempt: attempt, Backoff: backoff, Err: err, Subscriptions: subscriptions})
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+
+		var conn *websocket.Conn
+		if conn, err = r.dial(ctx); err == nil {
+			r.stateChanged(ConnectionStateChange{State: ConnectionStateReconnected, Attempt: attempt, Subscriptions: activeSubscriptions()})
+			return conn
+		}
+	}
+	r.stateChanged(ConnectionStateChange{State: ConnectionStateFailed, Err: err, Subscriptions: activeSubscriptions()})
+	return nil
+}
+
+func (r *wsReconnector) stateChanged(change ConnectionStateChange) {
+	if r.policy.OnStateChange == nil {
+		return
+	}
+	change.URL = r.url
+	r.policy.OnStateChange(change)
+}
+
+// sendsErrorOnFailure returns true if the subscriptions of a lost connection receive the error, the reconnector may be nil
+func (r *wsReconnector) sendsErrorOnFailure() bool {
+	return r == nil || r.policy.OnPermanentFailure == PermanentFailureSendError
+}
+
+// resubscriptions returns the subscriptions to resubscribe on the re-established connection in the order of their ids,
+// subscriptions with identical requests are merged if DeduplicateResubscriptions is enabled
+func (r *wsReconnector) resubscriptions(subscriptions map[string]Subscription) []Subscription {
+	ids := make([]string, 0, len(subscriptions))
+	for id, sub := range subscriptions {
+		if sub.ctx.Err() != nil {
+			close(sub.next)
+			continue
+		}
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		left, _ := strconv.Atoi(ids[i])
+		right, _ := strconv.Atoi(ids[j])
+		return left < right
+	})
+
+	resubscriptions := make([]Subscription, 0, len(ids))
+	if !r.policy.DeduplicateResubscriptions {
+		for _, id := range ids {
+			resubscriptions = append(resubscriptions, subscriptions[id])
+		}
+		return resubscriptions
+	}
+
+	groups := make(map[string][]Subscription, len(ids))
+	var order []string
+	for _, id := range ids {
+		body, err := json.Marshal(subscriptions[id].options.Body)
+		if err != nil {
+			resubscriptions = append(resubscriptions, subscriptions[id])
+			continue
+		}
+		if _, ok := groups[string(body)]; !ok {
+			order = append(order, string(body))
+		}
+		groups[string(body)] = append(groups[string(body)], subscriptions[id])
+	}
+	for _, body := range order {
+		if group := groups[body]; len(group) == 1 {
+			resubscriptions = append(resubscriptions, group[0])
+		} else {
+			resubscriptions = append(resubscriptions, fanOutSubscription(group))
+		}
+	}
+	return resubscriptions
+}
+
+// fanOutSubscription merges the subscriptions into a single subscription sending its events to each of them.
+// The merged subscription is done once all subscriptions are done, completing it completes all subscriptions.
+func fanOutSubscription(subscriptions []Subscription) Subscription {
+	ctx, cancel := context.WithCancel(context.Background())
+	next := make(chan []byte)
+
+	go func() {
+		for _, sub := range subscriptions {
+			select {
+			case <-sub.ctx.Done():
+			case <-ctx.Done():
+				return
+			}
+		}
+		cancel()
+	}()
+
+	go func() {
+		defer cancel()
+		for data := range next {
+			for _, sub := range subscriptions {
+				select {
+				case sub.next <- data:
+				case <-sub.ctx.Done():
+				}
+			}
+		}
+		for _, sub := range subscriptions {
+			close(sub.next)
+		}
+	}()
+
+	return Subscription{
+		ctx:     ctx,
+		options: subscriptions[0].options,
+		next:    next,
+	}
+}
@@ -0,0 +1,250 @@
+package graphql_datasource
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+	"nhooyr.io/websocket"
+)
+
+func TestReconnectPolicy_Backoff(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		policy := ReconnectPolicy{}
+		assert.Equal(t, 100*time.Millisecond, policy.backoff(1, 0))
+		assert.Equal(t, 200*time.Millisecond, policy.backoff(2, 0))
+		assert.Equal(t, 400*time.Millisecond, policy.backoff(3, 0))
+		assert.Equal(t, 10*time.Second, policy.backoff(20, 0))
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		policy := ReconnectPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second, Multiplier: 3}
+		assert.Equal(t, time.Second, policy.backoff(1, 0))
+		assert.Equal(t, 3*time.Second, policy.backoff(2, 0))
+		assert.Equal(t, 5*time.Second, policy.backoff(3, 0))
+	})
+
+	t.Run("jitter", func(t *testing.T) {
+		policy := ReconnectPolicy{InitialBackoff: time.Second, Jitter: 0.2}
+		assert.Equal(t, time.Second, policy.backoff(1, 0))
+		assert.Equal(t, 900*time.Millisecond, policy.backoff(1, 0.5))
+		assert.Equal(t, 800*time.Millisecond, policy.backoff(1, 1))
+	})
+}
+
+func TestWebsocketSubscriptionClientReconnect(t *testing.T) {
+	acceptInit := func(t *testing.T, ctx context.Context, conn *websocket.Conn) {
+		_, data, err := conn.Read(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, `{"type":"connection_init"}`, string(data))
+		require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte(`{"type":"connection_ack"}`)))
+	}
+	readStart := func(t *testing.T, ctx context.Context, conn *websocket.Conn) string {
+		_, data, err := conn.Read(ctx)
+		require.NoError(t, err)
+		return string(data)
+	}
+	sendData := func(t *testing.T, ctx context.Context, conn *websocket.Conn, id, text string) {
+		require.NoError(t, conn.Write(ctx, websocket.MessageText, []byte(`{"type":"data","id":"`+id+`","payload":{"data":{"messageAdded":{"text":"`+text+`"}}}}`)))
+	}
+
+	// newServer serves the connections with the handlers in order, connections without handler are rejected
+	newServer := func(t *testing.T, handlers ...func(ctx context.Context, conn *websocket.Conn)) *httptest.Server {
+		connections := atomic.NewInt64(0)
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			i := int(connections.Inc()) - 1
+			if i >= len(handlers) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			conn, err := websocket.Accept(w, r, nil)
+			require.NoError(t, err)
+			acceptInit(t, r.Context(), conn)
+			handlers[i](r.Context(), conn)
+		}))
+	}
+
+	subscribe := func(t *testing.T, client *SubscriptionClient, ctx context.Context, url string) chan []byte {
+		next := make(chan []byte)
+		err := client.Subscribe(ctx, GraphQLSubscriptionOptions{
+			URL: url,
+			Body: GraphQLBody{
+				Query: `subscription {messageAdded(roomName: "room"){text}}`,
+			},
+		}, next)
+		require.NoError(t, err)
+		return next
+	}
+
+	type stateChanges struct {
+		mu      sync.Mutex
+		changes []ConnectionStateChange
+	}
+	recordStateChanges := func(policy *ReconnectPolicy) *stateChanges {
+		recorded := &stateChanges{}
+		policy.OnStateChange = func(change ConnectionStateChange) {
+			recorded.mu.Lock()
+			defer recorded.mu.Unlock()
+			recorded.changes = append(recorded.changes, change)
+		}
+		return recorded
+	}
+	states := func(recorded *stateChanges) []ConnectionState {
+		recorded.mu.Lock()
+		defer recorded.mu.Unlock()
+		var states []ConnectionState
+		for _, change := range recorded.changes {
+			states = append(states, change.State)
+		}
+		return states
+	}
+
+	const start = `{"type":"start","id":"%s","payload":{"query":"subscription {messageAdded(roomName: \"room\"){text}}"}}`
+
+	t.Run("resubscribes the subscriptions after reconnecting", func(t *testing.T) {
+		serverDone := make(chan struct{})
+		server := newServer(t,
+			func(ctx context.Context, conn *websocket.Conn) {
+				assert.Equal(t, `{"type":"start","id":"1","payload":{"query":"subscription {messageAdded(roomName: \"room\"){text}}"}}`, readStart(t, ctx, conn))
+				sendData(t, ctx, conn, "1", "first")
+				_ = conn.Close(websocket.StatusInternalError, "restart")
+			},
+			func(ctx context.Context, conn *websocket.Conn) {
+				defer close(serverDone)
+				assert.Equal(t, `{"type":"start","id":"1","payload":{"query":"subscription {messageAdded(roomName: \"room\"){text}}"}}`, readStart(t, ctx, conn))
+				sendData(t, ctx, conn, "1", "second")
+				_, _, _ = conn.Read(ctx)
+			},
+		)
+		defer server.Close()
+
+		policy := ReconnectPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+		recorded := recordStateChanges(&policy)
+		engineCtx, engineCancel := context.WithCancel(context.Background())
+		defer engineCancel()
+		client := NewGraphQLSubscriptionClient(http.DefaultClient, http.DefaultClient, engineCtx,
+			WithReadTimeout(time.Millisecond),
+			WithWSSubProtocol(ProtocolGraphQLWS),
+			WithReconnectPolicy(policy),
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		next := subscribe(t, client, ctx, server.URL)
+		assert.Equal(t, `{"data":{"messageAdded":{"text":"first"}}}`, string(<-next))
+		assert.Equal(t, `{"data":{"messageAdded":{"text":"second"}}}`, string(<-next))
+		cancel()
+		<-serverDone
+
+		assert.Equal(t, []ConnectionState{ConnectionStateReconnecting, ConnectionStateReconnected}, states(recorded))
+		recorded.mu.Lock()
+		assert.Equal(t, server.URL, recorded.changes[0].URL)
+		assert.Equal(t, 1, recorded.changes[0].Attempt)
+		assert.Equal(t, 1, recorded.changes[0].Subscriptions)
+		assert.Error(t, recorded.changes[0].Err)
+		recorded.mu.Unlock()
+	})
+
+	t.Run("deduplicates resubscriptions", func(t *testing.T) {
+		serverDone := make(chan struct{})
+		firstSubscribed := make(chan struct{})
+		server := newServer(t,
+			func(ctx context.Context, conn *websocket.Conn) {
+				readStart(t, ctx, conn)
+				readStart(t, ctx, conn)
+				close(firstSubscribed)
+				sendData(t, ctx, conn, "1", "first")
+				sendData(t, ctx, conn, "2", "first")
+				time.Sleep(10 * time.Millisecond)
+				_ = conn.Close(websocket.StatusInternalError, "restart")
+			},
+			func(ctx context.Context, conn *websocket.Conn) {
+				defer close(serverDone)
+				assert.Equal(t, `{"type":"start","id":"1","payload":{"query":"subscription {messageAdded(roomName: \"room\"){text}}"}}`, readStart(t, ctx, conn))
+				sendData(t, ctx, conn, "1", "second")
+				_, data, err := conn.Read(ctx)
+				require.NoError(t, err)
+				assert.Equal(t, `{"type":"stop","id":"1"}`, string(data), "the subscription is only resubscribed once")
+			},
+		)
+		defer server.Close()
+
+		engineCtx, engineCancel := context.WithCancel(context.Background())
+		defer engineCancel()
+		client := NewGraphQLSubscriptionClient(http.DefaultClient, http.DefaultClient, engineCtx,
+			WithReadTimeout(time.Millisecond),
+			WithWSSubProtocol(ProtocolGraphQLWS),
+			WithReconnectPolicy(ReconnectPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, DeduplicateResubscriptions: true}),
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		first := subscribe(t, client, ctx, server.URL)
+		second := subscribe(t, client, ctx, server.URL)
+		<-firstSubscribed
+		assert.Equal(t, `{"data":{"messageAdded":{"text":"first"}}}`, string(<-first))
+		assert.Equal(t, `{"data":{"messageAdded":{"text":"first"}}}`, string(<-second))
+		assert.Equal(t, `{"data":{"messageAdded":{"text":"second"}}}`, string(<-first))
+		assert.Equal(t, `{"data":{"messageAdded":{"text":"second"}}}`, string(<-second))
+		cancel()
+		<-serverDone
+
+		_, open := <-first
+		assert.False(t, open)
+		_, open = <-second
+		assert.False(t, open)
+	})
+
+	lostConnection := func(t *testing.T, behavior PermanentFailureBehavior) (chan []byte, *stateChanges, func()) {
+		server := newServer(t,
+			func(ctx context.Context, conn *websocket.Conn) {
+				readStart(t, ctx, conn)
+				sendData(t, ctx, conn, "1", "first")
+				_ = conn.Close(websocket.StatusInternalError, "shutdown")
+			},
+		)
+
+		policy := ReconnectPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, OnPermanentFailure: behavior}
+		recorded := recordStateChanges(&policy)
+		engineCtx, engineCancel := context.WithCancel(context.Background())
+		client := NewGraphQLSubscriptionClient(http.DefaultClient, http.DefaultClient, engineCtx,
+			WithReadTimeout(time.Millisecond),
+			WithWSSubProtocol(ProtocolGraphQLWS),
+			WithReconnectPolicy(policy),
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		next := subscribe(t, client, ctx, server.URL)
+		assert.Equal(t, `{"data":{"messageAdded":{"text":"first"}}}`, string(<-next))
+		return next, recorded, func() {
+			cancel()
+			engineCancel()
+			server.Close()
+		}
+	}
+
+	t.Run("sends the error once reconnecting failed permanently", func(t *testing.T) {
+		next, recorded, done := lostConnection(t, PermanentFailureSendError)
+		defer done()
+
+		message, open := <-next
+		assert.True(t, open)
+		assert.Contains(t, string(message), `{"errors":[{"message":"`)
+		_, open = <-next
+		assert.False(t, open)
+		assert.Equal(t, []ConnectionState{ConnectionStateReconnecting, ConnectionStateReconnecting, ConnectionStateFailed}, states(recorded))
+	})
+
+	t.Run("completes the subscriptions once reconnecting failed permanently", func(t *testing.T) {
+		next, recorded, done := lostConnection(t, PermanentFailureComplete)
+		defer done()
+
+		_, open := <-next
+		assert.False(t, open)
+		assert.Equal(t, []ConnectionState{ConnectionStateReconnecting, ConnectionStateReconnecting, ConnectionStateFailed}, states(recorded))
+	})
+}
@@ -8,8 +8,9 @@ import (
 	"time"
 
 	"github.com/buger/jsonparser"
-	log "github.com/jensneuse/abstractlogger"
 	"nhooyr.io/websocket"
+
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 // gqlTWSConnectionHandler is responsible for handling a connection to an origin
@@ -23,6 +24,8 @@ type gqlTWSConnectionHandler struct {
 	nextSubscriptionID int
 	subscriptions      map[string]Subscription
 	readTimeout        time.Duration
+	// reconnector re-establishes the connection once it's lost, it's nil if the client has no ReconnectPolicy
+	reconnector *wsReconnector
 }
 
 func newGQLTWSConnectionHandler(ctx context.Context, conn *websocket.Conn, rt time.Duration, l log.Logger) *gqlTWSConnectionHandler {
@@ -64,8 +67,14 @@ func (h *gqlTWSConnectionHandler) StartBlocking(sub Subscription) {
 		case sub = <-h.subscribeCh:
 			h.subscribe(sub)
 		case err := <-errCh:
+			if h.reconnect(err) {
+				go h.readBlocking(readCtx, dataCh, errCh)
+				continue
+			}
 			h.log.Error("gqlWSConnectionHandler.StartBlocking", log.Error(err))
-			h.broadcastErrorMessage(err)
+			if h.reconnector.sendsErrorOnFailure() {
+				h.broadcastErrorMessage(err)
+			}
 			return
 		case data := <-dataCh:
 			messageType, err := jsonparser.GetString(data, "type")
@@ -91,6 +100,31 @@ func (h *gqlTWSConnectionHandler) StartBlocking(sub Subscription) {
 	}
 }
 
+// reconnect re-establishes the lost connection and resubscribes the active subscriptions, see ReconnectPolicy.
+// It returns false if the connection isn't re-established.
+func (h *gqlTWSConnectionHandler) reconnect(cause error) bool {
+	if h.reconnector == nil || h.ctx.Err() != nil {
+		return false
+	}
+	_ = h.conn.Close(websocket.StatusGoingAway, "")
+	conn := h.reconnector.reconnect(h.ctx, cause, func() int {
+		h.hasActiveSubscriptions()
+		return len(h.subscriptions)
+	})
+	if conn == nil {
+		return false
+	}
+	h.conn = conn
+	// the ids of the subscriptions start over on the new connection
+	h.nextSubscriptionID = 0
+	subscriptions := h.subscriptions
+	h.subscriptions = map[string]Subscription{}
+	for _, sub := range h.reconnector.resubscriptions(subscriptions) {
+		h.subscribe(sub)
+	}
+	return true
+}
+
 func (h *gqlTWSConnectionHandler) unsubscribeAllAndCloseConn() {
 	for id := range h.subscriptions {
 		h.unsubscribe(id)
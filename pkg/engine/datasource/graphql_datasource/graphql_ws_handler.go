@@ -8,8 +8,9 @@ import (
 	"time"
 
 	"github.com/buger/jsonparser"
-	"github.com/jensneuse/abstractlogger"
 	"nhooyr.io/websocket"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 // gqlWSConnectionHandler is responsible for handling a connection to an origin
@@ -18,14 +19,16 @@ import (
 type gqlWSConnectionHandler struct {
 	conn               *websocket.Conn
 	ctx                context.Context
-	log                abstractlogger.Logger
+	log                logging.Logger
 	subscribeCh        chan Subscription
 	nextSubscriptionID int
 	subscriptions      map[string]Subscription
 	readTimeout        time.Duration
+	// reconnector re-establishes the connection once it's lost, it's nil if the client has no ReconnectPolicy
+	reconnector *wsReconnector
 }
 
-func newGQLWSConnectionHandler(ctx context.Context, conn *websocket.Conn, readTimeout time.Duration, log abstractlogger.Logger) *gqlWSConnectionHandler {
+func newGQLWSConnectionHandler(ctx context.Context, conn *websocket.Conn, readTimeout time.Duration, log logging.Logger) *gqlWSConnectionHandler {
 	return &gqlWSConnectionHandler{
 		conn:               conn,
 		ctx:                ctx,
@@ -56,7 +59,7 @@ func (h *gqlWSConnectionHandler) StartBlocking(sub Subscription) {
 	for {
 		err := h.ctx.Err()
 		if err != nil {
-			h.log.Error("gqlWSConnectionHandler.StartBlocking", abstractlogger.Error(err))
+			h.log.Error("gqlWSConnectionHandler.StartBlocking", logging.Error(err))
 			h.broadcastErrorMessage(err)
 			return
 		}
@@ -70,8 +73,14 @@ func (h *gqlWSConnectionHandler) StartBlocking(sub Subscription) {
 		case sub = <-h.subscribeCh:
 			h.subscribe(sub)
 		case err = <-errCh:
-			h.log.Error("gqlWSConnectionHandler.StartBlocking", abstractlogger.Error(err))
-			h.broadcastErrorMessage(err)
+			if h.reconnect(err) {
+				go h.readBlocking(readCtx, dataCh, errCh)
+				continue
+			}
+			h.log.Error("gqlWSConnectionHandler.StartBlocking", logging.Error(err))
+			if h.reconnector.sendsErrorOnFailure() {
+				h.broadcastErrorMessage(err)
+			}
 			return
 		case data := <-dataCh:
 			messageType, err := jsonparser.GetString(data, "type")
@@ -121,6 +130,31 @@ func (h *gqlWSConnectionHandler) readBlocking(ctx context.Context, dataCh chan [
 	}
 }
 
+// reconnect re-establishes the lost connection and resubscribes the active subscriptions, see ReconnectPolicy.
+// It returns false if the connection isn't re-established.
+func (h *gqlWSConnectionHandler) reconnect(cause error) bool {
+	if h.reconnector == nil || h.ctx.Err() != nil {
+		return false
+	}
+	_ = h.conn.Close(websocket.StatusGoingAway, "")
+	conn := h.reconnector.reconnect(h.ctx, cause, func() int {
+		h.checkActiveSubscriptions()
+		return len(h.subscriptions)
+	})
+	if conn == nil {
+		return false
+	}
+	h.conn = conn
+	// the ids of the subscriptions start over on the new connection
+	h.nextSubscriptionID = 0
+	subscriptions := h.subscriptions
+	h.subscriptions = map[string]Subscription{}
+	for _, sub := range h.reconnector.resubscriptions(subscriptions) {
+		h.subscribe(sub)
+	}
+	return true
+}
+
 func (h *gqlWSConnectionHandler) unsubscribeAllAndCloseConn() {
 	for id := range h.subscriptions {
 		h.unsubscribe(id)
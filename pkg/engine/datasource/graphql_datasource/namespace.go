@@ -0,0 +1,266 @@
+package graphql_datasource
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+)
+
+var ErrNamespaceRequiresUpstreamSchema = errors.New("namespaced upstreams require the upstream schema")
+
+// NamespaceConfiguration wraps an upstream schema under a namespace field and/or prefixes its type names,
+// so that multiple upstreams with overlapping root fields and type names can be composed into a single schema.
+// The gateway schema contains the namespaced upstream schema, see NamespaceSchema,
+// while Configuration.UpstreamSchema has to be the original upstream schema.
+// Upstream queries are unwrapped, and the responses are wrapped again, automatically.
+type NamespaceConfiguration struct {
+	// FieldName is the name of the namespace field on the root types, e.g. users_service for Query.users_service,
+	// of which the selections are sent to the upstream as root selections. Subscriptions are not wrapped.
+	FieldName string
+	// TypePrefix is prepended to all type names of the upstream except the built-in scalars, e.g. Users_ for Users_User
+	TypePrefix string
+}
+
+func (n *NamespaceConfiguration) IsEnabled() bool {
+	return n.FieldName != "" || n.TypePrefix != ""
+}
+
+// namespaceTypeName returns the name of the type of the namespace field, which is the renamed upstream root type
+func (n *NamespaceConfiguration) namespaceTypeName(rootTypeName string) string {
+	if n.TypePrefix == "" {
+		return n.FieldName + "_" + rootTypeName
+	}
+	return n.TypePrefix + rootTypeName
+}
+
+// NamespaceSchema returns the upstream schema with prefixed type names.
+// If the namespace has a field name, the query and mutation types of the upstream become the namespace types
+// and the returned schema defines the Query and Mutation types with the namespace field only.
+// The returned schema can be merged with the schemas of other upstreams.
+func NamespaceSchema(upstreamSchema string, namespace NamespaceConfiguration) (string, error) {
+	doc, report := astparser.ParseGraphqlDocumentString(upstreamSchema)
+	if report.HasErrors() {
+		return "", report
+	}
+
+	queryTypeName, mutationTypeName, subscriptionTypeName := doc.Index.RootOperationTypeNames()
+	rootTypeNames := map[string]ast.OperationType{
+		queryTypeName.String():        ast.OperationTypeQuery,
+		mutationTypeName.String():     ast.OperationTypeMutation,
+		subscriptionTypeName.String(): ast.OperationTypeSubscription,
+	}
+
+	renames := map[string]string{}
+	// wrapped holds the namespace types by the operation type they are wrapped for
+	wrapped := map[ast.OperationType]string{}
+	for _, node := range doc.RootNodes {
+		if node.Kind == ast.NodeKindDirectiveDefinition {
+			continue
+		}
+		name := doc.NodeNameString(node)
+		if name == "" || isBuiltInScalar(name) {
+			continue
+		}
+		if _, ok := renames[name]; ok {
+			continue
+		}
+		if operationType, isRootType := rootTypeNames[name]; isRootType && node.Kind == ast.NodeKindObjectTypeDefinition {
+			if namespace.FieldName == "" || operationType == ast.OperationTypeSubscription {
+				continue
+			}
+			renames[name] = namespace.namespaceTypeName(name)
+			wrapped[operationType] = renames[name]
+			continue
+		}
+		if namespace.TypePrefix != "" {
+			renames[name] = namespace.TypePrefix + name
+		}
+	}
+	for name, newName := range renames {
+		renameNamespacedType(&doc, name, newName)
+	}
+
+	if namespace.FieldName != "" {
+		// the namespace types are no root types anymore
+		doc.SchemaDefinitions = doc.SchemaDefinitions[:0]
+		doc.RootOperationTypeDefinitions = doc.RootOperationTypeDefinitions[:0]
+		rootNodes := doc.RootNodes[:0]
+		for _, node := range doc.RootNodes {
+			if node.Kind != ast.NodeKindSchemaDefinition && node.Kind != ast.NodeKindSchemaExtension {
+				rootNodes = append(rootNodes, node)
+			}
+		}
+		doc.RootNodes = rootNodes
+	}
+
+	printed, err := astprinter.PrintString(&doc, nil)
+	if err != nil {
+		return "", err
+	}
+
+	// the namespaced schema has no schema definition, so the namespace fields are added to the implicit root types
+	schema := strings.Builder{}
+	schema.WriteString(printed)
+	for _, operationType := range []ast.OperationType{ast.OperationTypeQuery, ast.OperationTypeMutation} {
+		namespaceTypeName, ok := wrapped[operationType]
+		if !ok {
+			continue
+		}
+		rootTypeName := ast.DefaultQueryTypeName
+		if operationType == ast.OperationTypeMutation {
+			rootTypeName = ast.DefaultMutationTypeName
+		}
+		schema.WriteString(" type " + string(rootTypeName) + " {" + namespace.FieldName + ": " + namespaceTypeName + "!}")
+	}
+
+	namespaced, report := astparser.ParseGraphqlDocumentString(schema.String())
+	if report.HasErrors() {
+		return "", report
+	}
+	return astprinter.PrintStringIndent(&namespaced, nil, "  ")
+}
+
+func isBuiltInScalar(typeName string) bool {
+	switch typeName {
+	case "String", "Int", "Float", "Boolean", "ID":
+		return true
+	}
+	return false
+}
+
+func renameNamespacedType(doc *ast.Document, name, newName string) {
+	renamed := doc.Input.AppendInputString(newName)
+	for _, node := range doc.RootNodes {
+		if doc.NodeNameString(node) != name {
+			continue
+		}
+		switch node.Kind {
+		case ast.NodeKindObjectTypeDefinition:
+			doc.ObjectTypeDefinitions[node.Ref].Name = renamed
+		case ast.NodeKindObjectTypeExtension:
+			doc.ObjectTypeExtensions[node.Ref].Name = renamed
+		case ast.NodeKindInterfaceTypeDefinition:
+			doc.InterfaceTypeDefinitions[node.Ref].Name = renamed
+		case ast.NodeKindInterfaceTypeExtension:
+			doc.InterfaceTypeExtensions[node.Ref].Name = renamed
+		case ast.NodeKindInputObjectTypeDefinition:
+			doc.InputObjectTypeDefinitions[node.Ref].Name = renamed
+		case ast.NodeKindEnumTypeDefinition:
+			doc.EnumTypeDefinitions[node.Ref].Name = renamed
+		case ast.NodeKindEnumTypeExtension:
+			doc.EnumTypeExtensions[node.Ref].Name = renamed
+		case ast.NodeKindUnionTypeDefinition:
+			doc.UnionTypeDefinitions[node.Ref].Name = renamed
+		case ast.NodeKindUnionTypeExtension:
+			doc.UnionTypeExtensions[node.Ref].Name = renamed
+		case ast.NodeKindScalarTypeDefinition:
+			doc.ScalarTypeDefinitions[node.Ref].Name = renamed
+		}
+	}
+	for i := range doc.InputObjectTypeExtensions {
+		if doc.InputObjectTypeExtensionNameString(i) == name {
+			doc.InputObjectTypeExtensions[i].Name = renamed
+		}
+	}
+	for i := range doc.ScalarTypeExtensions {
+		if doc.ScalarTypeExtensionNameString(i) == name {
+			doc.ScalarTypeExtensions[i].Name = renamed
+		}
+	}
+	for i := range doc.Types {
+		if doc.Types[i].TypeKind == ast.TypeKindNamed && doc.TypeNameString(i) == name {
+			doc.Types[i].Name = renamed
+		}
+	}
+}
+
+// upstreamTypeName maps a type name of the gateway schema to the type name of the upstream schema
+func (p *Planner) upstreamTypeName(typeName string) string {
+	typeName = p.visitor.Config.Types.RenameTypeNameOnMatchStr(typeName)
+	if p.config.Namespace.TypePrefix != "" && !isBuiltInScalar(typeName) {
+		typeName = strings.TrimPrefix(typeName, p.config.Namespace.TypePrefix)
+	}
+	return p.config.SchemaMapping.upstreamTypeName(typeName)
+}
+
+func (p *Planner) upstreamTypeNameBytes(typeName []byte) []byte {
+	return []byte(p.upstreamTypeName(string(typeName)))
+}
+
+// isNamespaceField returns true if the field is the namespace field on the root type of the operation
+func (p *Planner) isNamespaceField(ref int) bool {
+	if p.config.Namespace.FieldName == "" || p.isNested || len(p.nodes) < 2 {
+		return false
+	}
+	if p.nodes[len(p.nodes)-2].Kind != ast.NodeKindOperationDefinition {
+		return false
+	}
+	return p.visitor.Operation.FieldNameUnsafeString(ref) == p.config.Namespace.FieldName
+}
+
+// isNamespaceSelectionSet returns true if the selection set is the one of the namespace field being walked
+func (p *Planner) isNamespaceSelectionSet(ref int) bool {
+	return p.namespaceFieldRef != -1 && p.visitor.Operation.Fields[p.namespaceFieldRef].SelectionSet == ref
+}
+
+func (p *Planner) namespaceResponse() *namespaceResponse {
+	if !p.config.Namespace.IsEnabled() {
+		return nil
+	}
+	return &namespaceResponse{
+		typePrefix: p.config.Namespace.TypePrefix,
+		fields:     p.namespaceFields,
+	}
+}
+
+type namespaceField struct {
+	responseKey string
+	typeName    string
+}
+
+// namespaceResponse wraps the data of upstream responses into the namespace fields
+// and prefixes the __typename values
+type namespaceResponse struct {
+	typePrefix string
+	fields     []namespaceField
+}
+
+func (n *namespaceResponse) wrap(response []byte) []byte {
+	data, dataType, _, err := jsonparser.Get(response, "data")
+	if err != nil || dataType != jsonparser.Object {
+		return response
+	}
+
+	if n.typePrefix != "" {
+		buf := &bytes.Buffer{}
+		n.prefixTypeNames(buf, data, dataType)
+		data = buf.Bytes()
+	}
+
+	if len(n.fields) == 0 {
+		out, _ := jsonparser.Set(response, data, "data")
+		return out
+	}
+
+	wrapped := []byte(`{}`)
+	for _, field := range n.fields {
+		value := append([]byte(nil), data...)
+		value, _ = jsonparser.Set(value, []byte(strconv.Quote(field.typeName)), "__typename")
+		wrapped, _ = jsonparser.Set(wrapped, value, field.responseKey)
+	}
+	out, _ := jsonparser.Set(response, wrapped, "data")
+	return out
+}
+
+func (n *namespaceResponse) prefixTypeNames(buf *bytes.Buffer, value []byte, dataType jsonparser.ValueType) {
+	writeRenamedTypeNames(buf, value, dataType, func(typeName []byte) []byte {
+		return append([]byte(n.typePrefix), typeName...)
+	})
+}
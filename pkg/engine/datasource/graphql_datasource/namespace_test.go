@@ -0,0 +1,119 @@
+package graphql_datasource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+)
+
+func TestNamespaceSchema(t *testing.T) {
+	const upstreamSchema = `
+		type Query { users: [User] }
+		type Mutation { rename(name: String!): User }
+		type Subscription { userRenamed: User }
+		interface Node { id: ID! }
+		type User implements Node { id: ID! name: String role: Role }
+		enum Role { ADMIN USER }
+		directive @internal on FIELD_DEFINITION
+	`
+
+	run := func(t *testing.T, namespace NamespaceConfiguration, expectedSchema string) {
+		t.Helper()
+		schema, err := NamespaceSchema(upstreamSchema, namespace)
+		require.NoError(t, err)
+
+		expected, report := astparser.ParseGraphqlDocumentString(expectedSchema)
+		require.False(t, report.HasErrors(), report.Error())
+		expectedPrinted, err := astprinter.PrintStringIndent(&expected, nil, "  ")
+		require.NoError(t, err)
+		assert.Equal(t, expectedPrinted, schema)
+	}
+
+	t.Run("namespace field and type prefix", func(t *testing.T) {
+		run(t, NamespaceConfiguration{FieldName: "users_service", TypePrefix: "Users_"}, `
+			type Users_Query { users: [Users_User] }
+			type Users_Mutation { rename(name: String!): Users_User }
+			type Subscription { userRenamed: Users_User }
+			interface Users_Node { id: ID! }
+			type Users_User implements Users_Node { id: ID! name: String role: Users_Role }
+			enum Users_Role { ADMIN USER }
+			directive @internal on FIELD_DEFINITION
+			type Query { users_service: Users_Query! }
+			type Mutation { users_service: Users_Mutation! }
+		`)
+	})
+
+	t.Run("type prefix only", func(t *testing.T) {
+		run(t, NamespaceConfiguration{TypePrefix: "Users_"}, `
+			type Query { users: [Users_User] }
+			type Mutation { rename(name: String!): Users_User }
+			type Subscription { userRenamed: Users_User }
+			interface Users_Node { id: ID! }
+			type Users_User implements Users_Node { id: ID! name: String role: Users_Role }
+			enum Users_Role { ADMIN USER }
+			directive @internal on FIELD_DEFINITION
+		`)
+	})
+
+	t.Run("namespace field only", func(t *testing.T) {
+		run(t, NamespaceConfiguration{FieldName: "users_service"}, `
+			type users_service_Query { users: [User] }
+			type users_service_Mutation { rename(name: String!): User }
+			type Subscription { userRenamed: User }
+			interface Node { id: ID! }
+			type User implements Node { id: ID! name: String role: Role }
+			enum Role { ADMIN USER }
+			directive @internal on FIELD_DEFINITION
+			type Query { users_service: users_service_Query! }
+			type Mutation { users_service: users_service_Mutation! }
+		`)
+	})
+}
+
+func TestNamespaceSchema_RootOperationTypes(t *testing.T) {
+	schema, err := NamespaceSchema(`
+		schema { query: RootQuery mutation: RootMutation }
+		type RootQuery { users: [User] }
+		type RootMutation { rename(name: String!): User }
+		type Query { name: String }
+		type User { name: String }
+	`, NamespaceConfiguration{FieldName: "users_service", TypePrefix: "Users_"})
+	require.NoError(t, err)
+
+	expected, report := astparser.ParseGraphqlDocumentString(`
+		type Users_RootQuery { users: [Users_User] }
+		type Users_RootMutation { rename(name: String!): Users_User }
+		type Users_Query { name: String }
+		type Users_User { name: String }
+		type Query { users_service: Users_RootQuery! }
+		type Mutation { users_service: Users_RootMutation! }
+	`)
+	require.False(t, report.HasErrors(), report.Error())
+	expectedPrinted, err := astprinter.PrintStringIndent(&expected, nil, "  ")
+	require.NoError(t, err)
+	assert.Equal(t, expectedPrinted, schema)
+}
+
+func TestNamespaceResponse(t *testing.T) {
+	namespace := &namespaceResponse{
+		typePrefix: "Users_",
+		fields: []namespaceField{
+			{responseKey: "users_service", typeName: "Users_Query"},
+			{responseKey: "admins", typeName: "Users_Query"},
+		},
+	}
+
+	t.Run("wraps the data and prefixes type names", func(t *testing.T) {
+		response := namespace.wrap([]byte(`{"data":{"users":[{"__typename":"User","name":"Jens"}]}}`))
+		assert.Equal(t, `{"data":{"users_service":{"users":[{"__typename":"Users_User","name":"Jens"}],"__typename":"Users_Query"},"admins":{"users":[{"__typename":"Users_User","name":"Jens"}],"__typename":"Users_Query"}}}`, string(response))
+	})
+
+	t.Run("keeps responses without data", func(t *testing.T) {
+		response := namespace.wrap([]byte(`{"errors":[{"message":"unavailable"}],"data":null}`))
+		assert.Equal(t, `{"errors":[{"message":"unavailable"}],"data":null}`, string(response))
+	})
+}
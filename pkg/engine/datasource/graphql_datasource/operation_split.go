@@ -0,0 +1,298 @@
+package graphql_datasource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+)
+
+const splitQueriesInputKey = "split_queries"
+
+// splitOperation splits the root fields of a normalized query into multiple queries,
+// each selecting at most maxFields fields unless a single root field selects more.
+// Variable definitions not used by a part are removed from it.
+// Returns nil if the query doesn't exceed maxFields, can't be split or isn't a query operation,
+// as splitting mutations would execute them independently of each other.
+func splitOperation(operation *ast.Document, maxFields int) ([][]byte, error) {
+	if len(operation.RootNodes) != 1 || operation.RootNodes[0].Kind != ast.NodeKindOperationDefinition {
+		return nil, nil
+	}
+	operationDefinitionRef := operation.RootNodes[0].Ref
+	operationDefinition := operation.OperationDefinitions[operationDefinitionRef]
+	if operationDefinition.OperationType != ast.OperationTypeQuery || !operationDefinition.HasSelections {
+		return nil, nil
+	}
+
+	selectionSet := operationDefinition.SelectionSet
+	rootSelections := operation.SelectionSets[selectionSet].SelectionRefs
+	if len(rootSelections) < 2 {
+		return nil, nil
+	}
+
+	var (
+		parts      [][]int
+		part       []int
+		partFields int
+		allFields  int
+	)
+	for _, selection := range rootSelections {
+		fields := selectionFieldCount(operation, selection)
+		allFields += fields
+		if len(part) != 0 && partFields+fields > maxFields {
+			parts = append(parts, part)
+			part, partFields = nil, 0
+		}
+		part = append(part, selection)
+		partFields += fields
+	}
+	parts = append(parts, part)
+
+	if allFields <= maxFields || len(parts) < 2 {
+		return nil, nil
+	}
+
+	variableDefinitions := operationDefinition.VariableDefinitions.Refs
+	hasVariableDefinitions := operationDefinition.HasVariableDefinitions
+	defer func() {
+		operation.SelectionSets[selectionSet].SelectionRefs = rootSelections
+		operation.OperationDefinitions[operationDefinitionRef].VariableDefinitions.Refs = variableDefinitions
+		operation.OperationDefinitions[operationDefinitionRef].HasVariableDefinitions = hasVariableDefinitions
+	}()
+
+	queries := make([][]byte, 0, len(parts))
+	for _, part := range parts {
+		used := map[string]struct{}{}
+		for _, selection := range part {
+			selectionVariables(operation, selection, used)
+		}
+		partVariableDefinitions := make([]int, 0, len(variableDefinitions))
+		for _, variableDefinition := range variableDefinitions {
+			if _, ok := used[operation.VariableDefinitionNameString(variableDefinition)]; ok {
+				partVariableDefinitions = append(partVariableDefinitions, variableDefinition)
+			}
+		}
+
+		operation.SelectionSets[selectionSet].SelectionRefs = part
+		operation.OperationDefinitions[operationDefinitionRef].VariableDefinitions.Refs = partVariableDefinitions
+		operation.OperationDefinitions[operationDefinitionRef].HasVariableDefinitions = len(partVariableDefinitions) != 0
+
+		buf := &bytes.Buffer{}
+		if err := astprinter.Print(operation, nil, buf); err != nil {
+			return nil, err
+		}
+		queries = append(queries, buf.Bytes())
+	}
+	return queries, nil
+}
+
+// selectionVariables adds the names of the variables used by the arguments and directives of the selection
+// and its nested selections to used
+func selectionVariables(operation *ast.Document, selection int, used map[string]struct{}) {
+	var (
+		directives   []int
+		selectionSet = ast.InvalidRef
+	)
+	switch operation.Selections[selection].Kind {
+	case ast.SelectionKindField:
+		field := operation.Fields[operation.Selections[selection].Ref]
+		for _, argument := range field.Arguments.Refs {
+			valueVariables(operation, operation.ArgumentValue(argument), used)
+		}
+		directives = field.Directives.Refs
+		if field.HasSelections {
+			selectionSet = field.SelectionSet
+		}
+	case ast.SelectionKindInlineFragment:
+		inlineFragment := operation.InlineFragments[operation.Selections[selection].Ref]
+		directives = inlineFragment.Directives.Refs
+		if inlineFragment.HasSelections {
+			selectionSet = inlineFragment.SelectionSet
+		}
+	case ast.SelectionKindFragmentSpread:
+		directives = operation.FragmentSpreads[operation.Selections[selection].Ref].Directives.Refs
+	}
+	for _, directive := range directives {
+		for _, argument := range operation.Directives[directive].Arguments.Refs {
+			valueVariables(operation, operation.ArgumentValue(argument), used)
+		}
+	}
+	if selectionSet == ast.InvalidRef {
+		return
+	}
+	for _, nested := range operation.SelectionSets[selectionSet].SelectionRefs {
+		selectionVariables(operation, nested, used)
+	}
+}
+
+func valueVariables(operation *ast.Document, value ast.Value, used map[string]struct{}) {
+	switch value.Kind {
+	case ast.ValueKindVariable:
+		used[operation.VariableValueNameString(value.Ref)] = struct{}{}
+	case ast.ValueKindList:
+		for _, ref := range operation.ListValues[value.Ref].Refs {
+			valueVariables(operation, operation.Value(ref), used)
+		}
+	case ast.ValueKindObject:
+		for _, ref := range operation.ObjectValues[value.Ref].Refs {
+			valueVariables(operation, operation.ObjectFields[ref].Value, used)
+		}
+	}
+}
+
+// selectionFieldCount returns the number of fields of the selection including all nested fields
+func selectionFieldCount(operation *ast.Document, selection int) int {
+	var (
+		count        int
+		selectionSet int
+	)
+	switch operation.Selections[selection].Kind {
+	case ast.SelectionKindField:
+		count = 1
+		field := operation.Fields[operation.Selections[selection].Ref]
+		if !field.HasSelections {
+			return count
+		}
+		selectionSet = field.SelectionSet
+	case ast.SelectionKindInlineFragment:
+		inlineFragment := operation.InlineFragments[operation.Selections[selection].Ref]
+		if !inlineFragment.HasSelections {
+			return count
+		}
+		selectionSet = inlineFragment.SelectionSet
+	default:
+		return count
+	}
+	for _, nested := range operation.SelectionSets[selectionSet].SelectionRefs {
+		count += selectionFieldCount(operation, nested)
+	}
+	return count
+}
+
+func setInputSplitQueries(input []byte, queries [][]byte) []byte {
+	quoted := make([]string, len(queries))
+	for i := range queries {
+		quoted[i] = string(queries[i])
+	}
+	splitQueries, err := json.Marshal(quoted)
+	if err != nil {
+		return input
+	}
+	input, _ = jsonparser.Set(input, splitQueries, splitQueriesInputKey)
+	return input
+}
+
+// loadSplit sends one request per split query concurrently and writes a single response
+// containing the merged data and the errors of all responses.
+// The data of the succeeded parts is kept when a part fails, its error is added to the errors of the response.
+func (s *Source) loadSplit(ctx context.Context, input, splitQueries []byte, header http.Header, writer io.Writer) error {
+	var inputs [][]byte
+	_, err := jsonparser.ArrayEach(splitQueries, func(query []byte, dataType jsonparser.ValueType, _ int, _ error) {
+		if dataType != jsonparser.String {
+			return
+		}
+		quoted := make([]byte, 0, len(query)+2)
+		quoted = append(quoted, '"')
+		quoted = append(quoted, query...)
+		quoted = append(quoted, '"')
+		partInput, _ := jsonparser.Set(append([]byte(nil), input...), quoted, httpclient.BODY, "query")
+		inputs = append(inputs, partInput)
+	})
+	if err != nil {
+		return err
+	}
+
+	responses := make([]bytes.Buffer, len(inputs))
+	errs := make([]error, len(inputs))
+	wg := &sync.WaitGroup{}
+	wg.Add(len(inputs))
+	for i := range inputs {
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = httpclient.DoWithHeader(s.httpClient, ctx, inputs[i], header, &responses[i])
+		}(i)
+	}
+	wg.Wait()
+
+	failed := 0
+	for i := range errs {
+		if errs[i] == nil {
+			continue
+		}
+		failed++
+		responses[i].Reset()
+		responses[i].Write(splitErrorResponse(errs[i]))
+	}
+	if failed == len(errs) {
+		return errs[0]
+	}
+
+	_, err = writer.Write(mergeSplitResponses(responses))
+	return err
+}
+
+// splitErrorResponse returns a response without data containing the error of a failed split query
+func splitErrorResponse(err error) []byte {
+	message, _ := json.Marshal(err.Error())
+	response := make([]byte, 0, len(message)+32)
+	response = append(response, `{"errors":[{"message":`...)
+	response = append(response, message...)
+	return append(response, `}]}`...)
+}
+
+// mergeSplitResponses merges the root fields of the data and the errors of the responses of split queries.
+// The root fields of split queries are disjoint, so the data objects can be merged without conflicts.
+// The data is null if it's null in all responses.
+func mergeSplitResponses(responses []bytes.Buffer) []byte {
+	var (
+		data    = &bytes.Buffer{}
+		errs    = &bytes.Buffer{}
+		hasData bool
+	)
+	for i := range responses {
+		response := responses[i].Bytes()
+		if object, dataType, _, err := jsonparser.Get(response, "data"); err == nil && dataType == jsonparser.Object {
+			hasData = true
+			// the root fields are written raw, without the braces of the data object
+			fields := bytes.TrimSpace(object[1 : len(object)-1])
+			if len(fields) != 0 {
+				if data.Len() != 0 {
+					data.WriteByte(',')
+				}
+				data.Write(fields)
+			}
+		}
+		_, _ = jsonparser.ArrayEach(response, func(value []byte, dataType jsonparser.ValueType, _ int, _ error) {
+			if errs.Len() != 0 {
+				errs.WriteByte(',')
+			}
+			errs.Write(value)
+		}, "errors")
+	}
+
+	merged := &bytes.Buffer{}
+	merged.WriteString(`{"data":`)
+	if hasData {
+		merged.WriteByte('{')
+		merged.Write(data.Bytes())
+		merged.WriteByte('}')
+	} else {
+		merged.Write(literal.NULL)
+	}
+	if errs.Len() != 0 {
+		merged.WriteString(`,"errors":[`)
+		merged.Write(errs.Bytes())
+		merged.WriteByte(']')
+	}
+	merged.WriteByte('}')
+	return merged.Bytes()
+}
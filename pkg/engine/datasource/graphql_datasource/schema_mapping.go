@@ -0,0 +1,383 @@
+package graphql_datasource
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astimport"
+	"github.com/wundergraph/graphql-go-tools/pkg/astnormalization"
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+)
+
+var ErrSchemaMappingRequiresUpstreamSchema = errors.New("mapped upstream schemas require the upstream schema")
+
+// SchemaMappingConfiguration defines the gateway schema as a transformed view of the upstream schema,
+// of which types and fields are renamed, fields are hidden and nested fields are flattened.
+// The gateway schema contains the mapped upstream schema, see MapSchema,
+// while Configuration.UpstreamSchema has to be the original upstream schema.
+// Upstream queries are rewritten to the upstream schema, and the __typename values of the responses are mapped, automatically.
+// The mappings refer to the names of the upstream schema.
+type SchemaMappingConfiguration struct {
+	Types  []TypeMapping
+	Fields []FieldMapping
+}
+
+// TypeMapping renames a type of the upstream schema, root operation types and built-in scalars can't be renamed
+type TypeMapping struct {
+	TypeName string
+	RenameTo string
+}
+
+// FieldMapping renames, hides or flattens a field of an object or interface type of the upstream schema
+type FieldMapping struct {
+	TypeName  string
+	FieldName string
+	// RenameTo is the name of the field in the gateway schema
+	RenameTo string
+	// Hidden removes the field from the gateway schema
+	Hidden bool
+	// Path flattens the nested field at the path into a new field of the type named FieldName,
+	// e.g. FieldName city and Path []string{"address", "city"} for User.city resolving User.address.city of the upstream.
+	// The fields of the path but the last must return objects, not lists, the arguments of the last field are kept.
+	Path []string
+}
+
+func (m *SchemaMappingConfiguration) IsEnabled() bool {
+	return len(m.Types) != 0 || len(m.Fields) != 0
+}
+
+// gatewayTypeName maps a type name of the upstream schema to the type name of the gateway schema
+func (m *SchemaMappingConfiguration) gatewayTypeName(typeName string) string {
+	for i := range m.Types {
+		if m.Types[i].TypeName == typeName {
+			return m.Types[i].RenameTo
+		}
+	}
+	return typeName
+}
+
+// upstreamTypeName maps a type name of the gateway schema to the type name of the upstream schema
+func (m *SchemaMappingConfiguration) upstreamTypeName(typeName string) string {
+	for i := range m.Types {
+		if m.Types[i].RenameTo == typeName {
+			return m.Types[i].TypeName
+		}
+	}
+	return typeName
+}
+
+// upstreamFieldPath returns the path of the upstream fields of a renamed or flattened field of the gateway schema, otherwise nil
+func (m *SchemaMappingConfiguration) upstreamFieldPath(typeName, fieldName string) []string {
+	upstreamTypeName := m.upstreamTypeName(typeName)
+	for i := range m.Fields {
+		mapping := &m.Fields[i]
+		if mapping.TypeName != upstreamTypeName || mapping.Hidden {
+			continue
+		}
+		if len(mapping.Path) != 0 && mapping.FieldName == fieldName {
+			return mapping.Path
+		}
+		if len(mapping.Path) == 0 && mapping.RenameTo == fieldName {
+			return []string{mapping.FieldName}
+		}
+	}
+	return nil
+}
+
+// MapSchema returns the gateway schema of the upstream schema with the mapped types and fields.
+// Types which aren't reachable from the root fields anymore, e.g. types used by hidden fields only, are removed.
+// The returned schema can be merged with the schemas of other upstreams.
+func MapSchema(upstreamSchema string, mapping SchemaMappingConfiguration) (string, error) {
+	doc, report := astparser.ParseGraphqlDocumentString(upstreamSchema)
+	if report.HasErrors() {
+		return "", report
+	}
+	astnormalization.NormalizeDefinition(&doc, &report)
+	if report.HasErrors() {
+		return "", report
+	}
+
+	// fields are flattened first, as their paths refer to the fields of the upstream schema which might be renamed or hidden
+	for _, flattened := range []bool{true, false} {
+		for i := range mapping.Fields {
+			if (len(mapping.Fields[i].Path) != 0) != flattened {
+				continue
+			}
+			if err := mapSchemaField(&doc, &mapping.Fields[i]); err != nil {
+				return "", err
+			}
+		}
+	}
+	query, mutation, subscription := doc.Index.RootOperationTypeNames()
+	for _, typeMapping := range mapping.Types {
+		if isBuiltInScalar(typeMapping.TypeName) ||
+			typeMapping.TypeName == query.String() || typeMapping.TypeName == mutation.String() || typeMapping.TypeName == subscription.String() {
+			return "", fmt.Errorf("schema mapping: type %s can't be renamed", typeMapping.TypeName)
+		}
+		node, ok := doc.Index.FirstNonExtensionNodeByNameStr(typeMapping.TypeName)
+		if !ok {
+			return "", fmt.Errorf("schema mapping: type %s is not defined", typeMapping.TypeName)
+		}
+		if node.Kind == ast.NodeKindDirectiveDefinition {
+			return "", fmt.Errorf("schema mapping: type %s can't be renamed", typeMapping.TypeName)
+		}
+		if typeMapping.RenameTo == "" {
+			return "", fmt.Errorf("schema mapping: type %s is renamed without name", typeMapping.TypeName)
+		}
+	}
+	for _, typeMapping := range mapping.Types {
+		renameNamespacedType(&doc, typeMapping.TypeName, typeMapping.RenameTo)
+	}
+
+	// the index of the renamed types is rebuilt by parsing the mapped schema
+	printed, err := astprinter.PrintString(&doc, nil)
+	if err != nil {
+		return "", err
+	}
+	mapped, report := astparser.ParseGraphqlDocumentString(printed)
+	if report.HasErrors() {
+		return "", report
+	}
+
+	var rootFields []astimport.RootFields
+	for _, rootTypeName := range []ast.ByteSlice{query, mutation, subscription} {
+		node, ok := mapped.Index.FirstNonExtensionNodeByNameBytes(rootTypeName)
+		if !ok || node.Kind != ast.NodeKindObjectTypeDefinition {
+			continue
+		}
+		root := astimport.RootFields{TypeName: rootTypeName.String()}
+		for _, ref := range mapped.NodeFieldDefinitions(node) {
+			root.FieldNames = append(root.FieldNames, mapped.FieldDefinitionNameString(ref))
+		}
+		rootFields = append(rootFields, root)
+	}
+	gateway := ast.NewDocument()
+	if err = (&astimport.Importer{}).ImportSchemaSubset(&mapped, gateway, rootFields); err != nil {
+		return "", err
+	}
+	return astprinter.PrintStringIndent(gateway, nil, "  ")
+}
+
+func mapSchemaField(doc *ast.Document, mapping *FieldMapping) error {
+	node, ok := doc.Index.FirstNonExtensionNodeByNameStr(mapping.TypeName)
+	if !ok || (node.Kind != ast.NodeKindObjectTypeDefinition && node.Kind != ast.NodeKindInterfaceTypeDefinition) {
+		return fmt.Errorf("schema mapping: type %s is not an object or interface type", mapping.TypeName)
+	}
+
+	if len(mapping.Path) != 0 {
+		if _, exists := doc.NodeFieldDefinitionByName(node, []byte(mapping.FieldName)); exists {
+			return fmt.Errorf("schema mapping: flattened field %s.%s is already defined", mapping.TypeName, mapping.FieldName)
+		}
+		leaf, err := flattenedFieldDefinition(doc, node, mapping)
+		if err != nil {
+			return err
+		}
+		definition := doc.FieldDefinitions[leaf]
+		definition.Name = doc.Input.AppendInputString(mapping.FieldName)
+		definition.Description = ast.Description{}
+		ref := doc.AddFieldDefinition(definition)
+		switch node.Kind {
+		case ast.NodeKindObjectTypeDefinition:
+			doc.ObjectTypeDefinitions[node.Ref].FieldsDefinition.Refs = append(doc.ObjectTypeDefinitions[node.Ref].FieldsDefinition.Refs, ref)
+		case ast.NodeKindInterfaceTypeDefinition:
+			doc.InterfaceTypeDefinitions[node.Ref].FieldsDefinition.Refs = append(doc.InterfaceTypeDefinitions[node.Ref].FieldsDefinition.Refs, ref)
+		}
+		return nil
+	}
+
+	ref, exists := doc.NodeFieldDefinitionByName(node, []byte(mapping.FieldName))
+	if !exists {
+		return fmt.Errorf("schema mapping: field %s.%s is not defined", mapping.TypeName, mapping.FieldName)
+	}
+	if mapping.Hidden {
+		removeFieldDefinition(doc, node, ref)
+		return nil
+	}
+	if mapping.RenameTo != "" {
+		doc.FieldDefinitions[ref].Name = doc.Input.AppendInputString(mapping.RenameTo)
+	}
+	return nil
+}
+
+// flattenedFieldDefinition returns the definition of the last field of the path of a flattened field
+func flattenedFieldDefinition(doc *ast.Document, node ast.Node, mapping *FieldMapping) (int, error) {
+	path := mapping.TypeName
+	for i, fieldName := range mapping.Path {
+		path += "." + fieldName
+		ref, exists := doc.NodeFieldDefinitionByName(node, []byte(fieldName))
+		if !exists {
+			return -1, fmt.Errorf("schema mapping: field %s is not defined", path)
+		}
+		if i == len(mapping.Path)-1 {
+			return ref, nil
+		}
+		typeRef := doc.FieldDefinitions[ref].Type
+		if doc.TypeIsNonNull(typeRef) {
+			typeRef = doc.Types[typeRef].OfType
+		}
+		if doc.Types[typeRef].TypeKind != ast.TypeKindNamed {
+			return -1, fmt.Errorf("schema mapping: field %s of the path of %s.%s returns a list", path, mapping.TypeName, mapping.FieldName)
+		}
+		node, exists = doc.Index.FirstNonExtensionNodeByNameBytes(doc.TypeNameBytes(typeRef))
+		if !exists || (node.Kind != ast.NodeKindObjectTypeDefinition && node.Kind != ast.NodeKindInterfaceTypeDefinition) {
+			return -1, fmt.Errorf("schema mapping: field %s of the path of %s.%s doesn't return an object", path, mapping.TypeName, mapping.FieldName)
+		}
+	}
+	return -1, fmt.Errorf("schema mapping: flattened field %s.%s has no path", mapping.TypeName, mapping.FieldName)
+}
+
+func removeFieldDefinition(doc *ast.Document, node ast.Node, ref int) {
+	remove := func(refs []int) []int {
+		kept := refs[:0]
+		for _, fieldRef := range refs {
+			if fieldRef != ref {
+				kept = append(kept, fieldRef)
+			}
+		}
+		return kept
+	}
+	switch node.Kind {
+	case ast.NodeKindObjectTypeDefinition:
+		doc.ObjectTypeDefinitions[node.Ref].FieldsDefinition.Refs = remove(doc.ObjectTypeDefinitions[node.Ref].FieldsDefinition.Refs)
+		doc.ObjectTypeDefinitions[node.Ref].HasFieldDefinitions = len(doc.ObjectTypeDefinitions[node.Ref].FieldsDefinition.Refs) != 0
+	case ast.NodeKindInterfaceTypeDefinition:
+		doc.InterfaceTypeDefinitions[node.Ref].FieldsDefinition.Refs = remove(doc.InterfaceTypeDefinitions[node.Ref].FieldsDefinition.Refs)
+		doc.InterfaceTypeDefinitions[node.Ref].HasFieldDefinitions = len(doc.InterfaceTypeDefinitions[node.Ref].FieldsDefinition.Refs) != 0
+	}
+}
+
+// schemaMappingFieldPath returns the path of the upstream fields of a mapped field of the gateway schema
+func (p *Planner) schemaMappingFieldPath(typeName, fieldName string) []string {
+	if !p.config.SchemaMapping.IsEnabled() {
+		return nil
+	}
+	if p.config.Namespace.TypePrefix != "" {
+		typeName = strings.TrimPrefix(typeName, p.config.Namespace.TypePrefix)
+	}
+	return p.config.SchemaMapping.upstreamFieldPath(typeName, fieldName)
+}
+
+// addMappedField adds the upstream fields of a renamed or flattened field,
+// the fields of the path of a flattened field are nested in each other and the first field is aliased to the response key of the field
+func (p *Planner) addMappedField(ref int, alias ast.Alias, path []string) {
+	if !alias.IsDefined && path[0] != p.visitor.Operation.FieldNameString(ref) {
+		alias.IsDefined = true
+		alias.Name = p.upstreamOperation.Input.AppendInputBytes(p.visitor.Operation.FieldNameBytes(ref))
+	}
+	if ref == p.rootFieldRef {
+		p.rootFieldName = path[0]
+	}
+
+	for i, fieldName := range path {
+		field := p.upstreamOperation.AddField(ast.Field{
+			Name: p.upstreamOperation.Input.AppendInputString(fieldName),
+		})
+		if i == 0 {
+			p.upstreamOperation.Fields[field.Ref].Alias = alias
+		}
+		p.upstreamOperation.AddSelection(p.nodes[len(p.nodes)-1].Ref, ast.Selection{
+			Kind: ast.SelectionKindField,
+			Ref:  field.Ref,
+		})
+		p.nodes = append(p.nodes, field)
+		if i == len(path)-1 {
+			break
+		}
+		set := p.upstreamOperation.AddSelectionSet()
+		p.upstreamOperation.Fields[field.Ref].HasSelections = true
+		p.upstreamOperation.Fields[field.Ref].SelectionSet = set.Ref
+		p.nodes = append(p.nodes, set)
+	}
+	if len(path) > 1 {
+		// the enclosing fields and selection sets are removed from the nodes with the field, see LeaveField
+		p.flattenedFieldNodes[ref] = 2 * (len(path) - 1)
+	}
+	p.addResponseValueMapping(ref)
+}
+
+// DownstreamResponseFieldPath returns the path of flattened fields in the upstream response, see plan.NestingDataSourcePlanner
+func (p *Planner) DownstreamResponseFieldPath(downstreamFieldRef int) (path []string, exists bool) {
+	typeName := p.visitor.Walker.EnclosingTypeDefinition.NameString(p.visitor.Definition)
+	upstreamPath := p.schemaMappingFieldPath(typeName, p.visitor.Operation.FieldNameUnsafeString(downstreamFieldRef))
+	if len(upstreamPath) < 2 {
+		return nil, false
+	}
+	return append([]string{p.visitor.Operation.FieldAliasOrNameString(downstreamFieldRef)}, upstreamPath[1:]...), true
+}
+
+func (p *Planner) schemaMappingResponse() *schemaMappingResponse {
+	if len(p.config.SchemaMapping.Types) == 0 {
+		return nil
+	}
+	return &schemaMappingResponse{
+		mapping: &p.config.SchemaMapping,
+	}
+}
+
+// schemaMappingResponse maps the __typename values of upstream responses to the type names of the gateway schema
+type schemaMappingResponse struct {
+	mapping *SchemaMappingConfiguration
+}
+
+func (s *schemaMappingResponse) mapTypeNames(response []byte) []byte {
+	data, dataType, _, err := jsonparser.Get(response, "data")
+	if err != nil || dataType != jsonparser.Object {
+		return response
+	}
+	buf := &bytes.Buffer{}
+	writeRenamedTypeNames(buf, data, dataType, func(typeName []byte) []byte {
+		return []byte(s.mapping.gatewayTypeName(string(typeName)))
+	})
+	out, _ := jsonparser.Set(response, buf.Bytes(), "data")
+	return out
+}
+
+// writeRenamedTypeNames writes the JSON value with the __typename values renamed by rename
+func writeRenamedTypeNames(buf *bytes.Buffer, value []byte, dataType jsonparser.ValueType, rename func(typeName []byte) []byte) {
+	switch dataType {
+	case jsonparser.Object:
+		buf.WriteByte('{')
+		first := true
+		_ = jsonparser.ObjectEach(value, func(key []byte, value []byte, dataType jsonparser.ValueType, _ int) error {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			buf.WriteString(strconv.Quote(string(key)))
+			buf.WriteByte(':')
+			if dataType == jsonparser.String && string(key) == "__typename" {
+				buf.WriteByte('"')
+				buf.Write(rename(value))
+				buf.WriteByte('"')
+				return nil
+			}
+			writeRenamedTypeNames(buf, value, dataType, rename)
+			return nil
+		})
+		buf.WriteByte('}')
+	case jsonparser.Array:
+		buf.WriteByte('[')
+		first := true
+		_, _ = jsonparser.ArrayEach(value, func(value []byte, dataType jsonparser.ValueType, _ int, _ error) {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			writeRenamedTypeNames(buf, value, dataType, rename)
+		})
+		buf.WriteByte(']')
+	case jsonparser.String:
+		buf.WriteByte('"')
+		buf.Write(value)
+		buf.WriteByte('"')
+	default:
+		buf.Write(value)
+	}
+}
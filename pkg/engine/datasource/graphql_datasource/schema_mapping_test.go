@@ -0,0 +1,144 @@
+package graphql_datasource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+)
+
+func TestMapSchema(t *testing.T) {
+	const upstreamSchema = `
+		type Query { users: [User] user(id: ID!): User }
+		interface Node { id: ID! }
+		type User implements Node { id: ID! name: String address: Address password: Secret friends: [User] }
+		type Address { street: String city(format: String): String }
+		type Secret { hash: String }
+	`
+
+	run := func(t *testing.T, mapping SchemaMappingConfiguration, expectedSchema string) {
+		t.Helper()
+		schema, err := MapSchema(upstreamSchema, mapping)
+		require.NoError(t, err)
+
+		expected, report := astparser.ParseGraphqlDocumentString(expectedSchema)
+		require.False(t, report.HasErrors(), report.Error())
+		expectedPrinted, err := astprinter.PrintStringIndent(&expected, nil, "  ")
+		require.NoError(t, err)
+		assert.Equal(t, expectedPrinted, schema)
+	}
+
+	t.Run("renames types and fields", func(t *testing.T) {
+		run(t, SchemaMappingConfiguration{
+			Types: []TypeMapping{
+				{TypeName: "User", RenameTo: "Person"},
+			},
+			Fields: []FieldMapping{
+				{TypeName: "User", FieldName: "name", RenameTo: "fullName"},
+				{TypeName: "Query", FieldName: "users", RenameTo: "people"},
+			},
+		}, `
+			schema { query: Query }
+			type Query { people: [Person] user(id: ID!): Person }
+			interface Node { id: ID! }
+			type Person implements Node { id: ID! fullName: String address: Address password: Secret friends: [Person] }
+			type Address { street: String city(format: String): String }
+			type Secret { hash: String }
+		`)
+	})
+
+	t.Run("hides fields and removes unreachable types", func(t *testing.T) {
+		run(t, SchemaMappingConfiguration{
+			Fields: []FieldMapping{
+				{TypeName: "User", FieldName: "password", Hidden: true},
+				{TypeName: "Query", FieldName: "user", Hidden: true},
+			},
+		}, `
+			schema { query: Query }
+			type Query { users: [User] }
+			interface Node { id: ID! }
+			type User implements Node { id: ID! name: String address: Address friends: [User] }
+			type Address { street: String city(format: String): String }
+		`)
+	})
+
+	t.Run("flattens nested fields", func(t *testing.T) {
+		run(t, SchemaMappingConfiguration{
+			Fields: []FieldMapping{
+				{TypeName: "User", FieldName: "city", Path: []string{"address", "city"}},
+				{TypeName: "User", FieldName: "address", Hidden: true},
+			},
+		}, `
+			schema { query: Query }
+			type Query { users: [User] user(id: ID!): User }
+			interface Node { id: ID! }
+			type User implements Node { id: ID! name: String password: Secret friends: [User] city(format: String): String }
+			type Secret { hash: String }
+		`)
+	})
+
+	t.Run("invalid mappings", func(t *testing.T) {
+		for _, tc := range []struct {
+			name          string
+			mapping       SchemaMappingConfiguration
+			expectedError string
+		}{
+			{
+				name:          "undefined type",
+				mapping:       SchemaMappingConfiguration{Types: []TypeMapping{{TypeName: "Product", RenameTo: "Item"}}},
+				expectedError: "schema mapping: type Product is not defined",
+			},
+			{
+				name:          "root type",
+				mapping:       SchemaMappingConfiguration{Types: []TypeMapping{{TypeName: "Query", RenameTo: "Root"}}},
+				expectedError: "schema mapping: type Query can't be renamed",
+			},
+			{
+				name:          "built-in scalar",
+				mapping:       SchemaMappingConfiguration{Types: []TypeMapping{{TypeName: "String", RenameTo: "Text"}}},
+				expectedError: "schema mapping: type String can't be renamed",
+			},
+			{
+				name:          "undefined field",
+				mapping:       SchemaMappingConfiguration{Fields: []FieldMapping{{TypeName: "User", FieldName: "email", Hidden: true}}},
+				expectedError: "schema mapping: field User.email is not defined",
+			},
+			{
+				name:          "path through a list",
+				mapping:       SchemaMappingConfiguration{Fields: []FieldMapping{{TypeName: "User", FieldName: "friendNames", Path: []string{"friends", "name"}}}},
+				expectedError: "schema mapping: field User.friends of the path of User.friendNames returns a list",
+			},
+			{
+				name:          "flattened field already defined",
+				mapping:       SchemaMappingConfiguration{Fields: []FieldMapping{{TypeName: "User", FieldName: "name", Path: []string{"address", "city"}}}},
+				expectedError: "schema mapping: flattened field User.name is already defined",
+			},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := MapSchema(upstreamSchema, tc.mapping)
+				assert.EqualError(t, err, tc.expectedError)
+			})
+		}
+	})
+}
+
+func TestSchemaMappingResponse(t *testing.T) {
+	mapping := &schemaMappingResponse{
+		mapping: &SchemaMappingConfiguration{
+			Types: []TypeMapping{{TypeName: "User", RenameTo: "Person"}},
+		},
+	}
+
+	t.Run("maps type names", func(t *testing.T) {
+		response := mapping.mapTypeNames([]byte(`{"data":{"users":[{"__typename":"User","name":"Jens"},{"__typename":"Admin","name":"Stefan"}]}}`))
+		assert.Equal(t, `{"data":{"users":[{"__typename":"Person","name":"Jens"},{"__typename":"Admin","name":"Stefan"}]}}`, string(response))
+	})
+
+	t.Run("keeps responses without data", func(t *testing.T) {
+		response := mapping.mapTypeNames([]byte(`{"errors":[{"message":"unavailable"}],"data":null}`))
+		assert.Equal(t, `{"errors":[{"message":"unavailable"}],"data":null}`, string(response))
+	})
+}
@@ -0,0 +1,88 @@
+package graphql_datasource
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/secrets"
+)
+
+const userAgentHeader = "User-Agent"
+
+// upstreamHeaders are the headers configured for an upstream,
+// which are sent independent of the client request and override forwarded client headers.
+// Secret values are looked up for each request, so they are neither stored in the plan nor in the fetch input.
+type upstreamHeaders struct {
+	static         http.Header
+	secretHeaders  map[string]string
+	secretProvider secrets.SecretProvider
+}
+
+func newUpstreamHeaders(config FetchConfiguration, secretProvider secrets.SecretProvider) upstreamHeaders {
+	headers := upstreamHeaders{
+		secretHeaders:  config.SecretHeaders,
+		secretProvider: secretProvider,
+	}
+	if len(config.StaticHeaders) != 0 || config.UserAgent != "" {
+		headers.static = config.StaticHeaders.Clone()
+		if headers.static == nil {
+			headers.static = make(http.Header)
+		}
+		if config.UserAgent != "" {
+			headers.static.Set(userAgentHeader, config.UserAgent)
+		}
+	}
+	if headers.secretProvider == nil {
+		headers.secretProvider = secrets.EnvProvider{}
+	}
+	return headers
+}
+
+func (u *upstreamHeaders) isEmpty() bool {
+	return len(u.static) == 0 && len(u.secretHeaders) == 0
+}
+
+// header returns the static headers and the secret headers with their current values
+func (u *upstreamHeaders) header(ctx context.Context) (http.Header, error) {
+	if len(u.secretHeaders) == 0 {
+		return u.static, nil
+	}
+	header := u.static.Clone()
+	if header == nil {
+		header = make(http.Header, len(u.secretHeaders))
+	}
+	for headerName, secretName := range u.secretHeaders {
+		value, err := u.secretProvider.Secret(ctx, secretName)
+		if err != nil {
+			// the error must not contain the secret value
+			return nil, fmt.Errorf("unable to resolve secret header %s: %w", headerName, err)
+		}
+		header.Set(headerName, value)
+	}
+	return header, nil
+}
+
+// apply sets the upstream headers on the header, overriding headers of the same name
+func (u *upstreamHeaders) apply(ctx context.Context, header http.Header) (http.Header, error) {
+	if u.isEmpty() {
+		return header, nil
+	}
+	upstream, err := u.header(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if header == nil {
+		header = make(http.Header, len(upstream))
+	}
+	for key, values := range upstream {
+		for existing := range header {
+			if strings.EqualFold(existing, key) {
+				delete(header, existing)
+			}
+		}
+		header[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+	return header, nil
+}
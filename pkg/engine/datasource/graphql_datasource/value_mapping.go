@@ -0,0 +1,275 @@
+package graphql_datasource
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strconv"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+)
+
+// ValueMappingConfiguration translates enum values and input fields between the gateway schema and the upstream schema,
+// e.g. when the gateway exposes an enum value ACTIVE which the upstream calls 1.
+// Arguments are translated when the upstream query is generated, enum values in the response are translated back.
+type ValueMappingConfiguration struct {
+	Enums       []EnumValueMapping
+	InputFields []InputFieldMapping
+}
+
+// EnumValueMapping maps the values of the gateway enum TypeName to the values of the upstream,
+// values without a mapping are passed through unchanged
+type EnumValueMapping struct {
+	TypeName string
+	// Values maps a gateway value to an upstream value, e.g. ACTIVE to 1
+	Values map[string]string
+}
+
+// InputFieldMapping renames the field FieldName of the gateway input object TypeName to UpstreamFieldName
+type InputFieldMapping struct {
+	TypeName          string
+	FieldName         string
+	UpstreamFieldName string
+}
+
+func (c *ValueMappingConfiguration) enumValues(typeName string) map[string]string {
+	for i := range c.Enums {
+		if c.Enums[i].TypeName == typeName {
+			return c.Enums[i].Values
+		}
+	}
+	return nil
+}
+
+func (c *ValueMappingConfiguration) upstreamFieldName(typeName, fieldName string) string {
+	for i := range c.InputFields {
+		if c.InputFields[i].TypeName == typeName && c.InputFields[i].FieldName == fieldName {
+			return c.InputFields[i].UpstreamFieldName
+		}
+	}
+	return ""
+}
+
+// valueMapping translates a JSON value, arrays are translated element-wise
+type valueMapping struct {
+	enumValues map[string]string
+	fields     map[string]valueMappingField
+}
+
+type valueMappingField struct {
+	name    string // name - holds the translated key of the field, the key is kept if empty
+	mapping *valueMapping
+}
+
+func (m *valueMapping) field(key string) *valueMapping {
+	if m.fields == nil {
+		m.fields = map[string]valueMappingField{}
+	}
+	field, ok := m.fields[key]
+	if !ok {
+		field.mapping = &valueMapping{}
+		m.fields[key] = field
+	}
+	return field.mapping
+}
+
+func (m *valueMapping) write(buf *bytes.Buffer, value []byte, dataType jsonparser.ValueType) {
+	switch dataType {
+	case jsonparser.Object:
+		buf.WriteByte('{')
+		first := true
+		_ = jsonparser.ObjectEach(value, func(key []byte, value []byte, dataType jsonparser.ValueType, _ int) error {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			field, ok := m.fields[string(key)]
+			if ok && field.name != "" {
+				buf.WriteString(strconv.Quote(field.name))
+			} else {
+				buf.WriteString(strconv.Quote(string(key)))
+			}
+			buf.WriteByte(':')
+			if ok && field.mapping != nil {
+				field.mapping.write(buf, value, dataType)
+				return nil
+			}
+			writeJSONValue(buf, value, dataType)
+			return nil
+		})
+		buf.WriteByte('}')
+	case jsonparser.Array:
+		buf.WriteByte('[')
+		first := true
+		_, _ = jsonparser.ArrayEach(value, func(value []byte, dataType jsonparser.ValueType, _ int, _ error) {
+			if !first {
+				buf.WriteByte(',')
+			}
+			first = false
+			m.write(buf, value, dataType)
+		})
+		buf.WriteByte(']')
+	case jsonparser.String:
+		if mapped, ok := m.enumValues[string(value)]; ok {
+			buf.WriteString(strconv.Quote(mapped))
+			return
+		}
+		writeJSONValue(buf, value, dataType)
+	default:
+		buf.Write(value)
+	}
+}
+
+func (m *valueMapping) mapJSON(data []byte) []byte {
+	value, dataType, _, err := jsonparser.Get(data)
+	if err != nil {
+		return data
+	}
+	buf := &bytes.Buffer{}
+	m.write(buf, value, dataType)
+	return buf.Bytes()
+}
+
+func writeJSONValue(buf *bytes.Buffer, value []byte, dataType jsonparser.ValueType) {
+	if dataType == jsonparser.String {
+		buf.WriteByte('"')
+		buf.Write(value)
+		buf.WriteByte('"')
+		return
+	}
+	buf.Write(value)
+}
+
+// valueMappingVariableRenderer translates the rendered variable to the upstream representation,
+// the wrapped renderer validates the variable against the gateway schema
+type valueMappingVariableRenderer struct {
+	resolve.VariableRenderer
+	mapping *valueMapping
+}
+
+func (r *valueMappingVariableRenderer) RenderVariable(ctx context.Context, data []byte, out io.Writer) error {
+	buf := &bytes.Buffer{}
+	if err := r.VariableRenderer.RenderVariable(ctx, data, buf); err != nil {
+		return err
+	}
+	_, err := out.Write(r.mapping.mapJSON(buf.Bytes()))
+	return err
+}
+
+// mapVariableRenderer wraps the renderer of a variable of the given type if its values have to be translated
+func (p *Planner) mapVariableRenderer(renderer resolve.VariableRenderer, typeDocument *ast.Document, typeRef int) resolve.VariableRenderer {
+	mapping := p.inputValueMapping(typeDocument.ResolveTypeNameString(typeRef))
+	if mapping == nil {
+		return renderer
+	}
+	return &valueMappingVariableRenderer{VariableRenderer: renderer, mapping: mapping}
+}
+
+// inputValueMapping returns the translation of the gateway input type or enum to the upstream, nil if there is nothing to translate
+func (p *Planner) inputValueMapping(typeName string) *valueMapping {
+	if len(p.config.ValueMapping.Enums) == 0 && len(p.config.ValueMapping.InputFields) == 0 {
+		return nil
+	}
+	if p.inputValueMappings == nil {
+		p.inputValueMappings = map[string]*valueMapping{}
+	}
+	if mapping, ok := p.inputValueMappings[typeName]; ok {
+		return mapping
+	}
+
+	node, ok := p.visitor.Definition.Index.FirstNonExtensionNodeByNameStr(typeName)
+	if !ok {
+		return nil
+	}
+	switch node.Kind {
+	case ast.NodeKindEnumTypeDefinition:
+		var mapping *valueMapping
+		if values := p.config.ValueMapping.enumValues(typeName); len(values) != 0 {
+			mapping = &valueMapping{enumValues: values}
+		}
+		p.inputValueMappings[typeName] = mapping
+		return mapping
+	case ast.NodeKindInputObjectTypeDefinition:
+		// the mapping is stored before walking the fields to support recursive input types
+		mapping := &valueMapping{fields: map[string]valueMappingField{}}
+		p.inputValueMappings[typeName] = mapping
+		for _, ref := range p.visitor.Definition.InputObjectTypeDefinitions[node.Ref].InputFieldsDefinition.Refs {
+			fieldName := p.visitor.Definition.InputValueDefinitionNameString(ref)
+			field := valueMappingField{
+				name:    p.config.ValueMapping.upstreamFieldName(typeName, fieldName),
+				mapping: p.inputValueMapping(p.visitor.Definition.ResolveTypeNameString(p.visitor.Definition.InputValueDefinitionType(ref))),
+			}
+			if field.name != "" || field.mapping != nil {
+				mapping.fields[fieldName] = field
+			}
+		}
+		if len(mapping.fields) == 0 {
+			p.inputValueMappings[typeName] = nil
+			return nil
+		}
+		return mapping
+	default:
+		p.inputValueMappings[typeName] = nil
+		return nil
+	}
+}
+
+// mapInlineValue translates a value of the upstream operation which was imported from an argument of the given type
+func (p *Planner) mapInlineValue(value ast.Value, mapping *valueMapping) {
+	if mapping == nil {
+		return
+	}
+	switch value.Kind {
+	case ast.ValueKindEnum:
+		if mapped, ok := mapping.enumValues[p.upstreamOperation.EnumValueNameString(value.Ref)]; ok {
+			p.upstreamOperation.EnumValues[value.Ref].Name = p.upstreamOperation.Input.AppendInputString(mapped)
+		}
+	case ast.ValueKindList:
+		for _, ref := range p.upstreamOperation.ListValues[value.Ref].Refs {
+			p.mapInlineValue(p.upstreamOperation.Values[ref], mapping)
+		}
+	case ast.ValueKindObject:
+		for _, ref := range p.upstreamOperation.ObjectValues[value.Ref].Refs {
+			field, ok := mapping.fields[p.upstreamOperation.ObjectFieldNameString(ref)]
+			if !ok {
+				continue
+			}
+			if field.name != "" {
+				p.upstreamOperation.ObjectFields[ref].Name = p.upstreamOperation.Input.AppendInputString(field.name)
+			}
+			p.mapInlineValue(p.upstreamOperation.ObjectFields[ref].Value, field.mapping)
+		}
+	}
+}
+
+// addResponseValueMapping translates the values of the field which was just added to the upstream operation back to the gateway representation
+func (p *Planner) addResponseValueMapping(ref int) {
+	if len(p.config.ValueMapping.Enums) == 0 {
+		return
+	}
+	definition, ok := p.visitor.Walker.FieldDefinition(ref)
+	if !ok {
+		return
+	}
+	values := p.config.ValueMapping.enumValues(p.visitor.Definition.ResolveTypeNameString(p.visitor.Definition.FieldDefinitionType(definition)))
+	if len(values) == 0 {
+		return
+	}
+
+	if p.responseValueMapping == nil {
+		p.responseValueMapping = &valueMapping{}
+	}
+	mapping := p.responseValueMapping.field("data")
+	for _, node := range p.nodes {
+		if node.Kind == ast.NodeKindField {
+			mapping = mapping.field(p.upstreamOperation.FieldAliasOrNameString(node.Ref))
+		}
+	}
+	mapping.enumValues = make(map[string]string, len(values))
+	for gatewayValue, upstreamValue := range values {
+		mapping.enumValues[upstreamValue] = gatewayValue
+	}
+}
@@ -0,0 +1,50 @@
+package graphql_datasource
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+)
+
+func TestValueMapping(t *testing.T) {
+	status := &valueMapping{enumValues: map[string]string{"ACTIVE": "1", "INACTIVE": "0"}}
+	filter := &valueMapping{fields: map[string]valueMappingField{
+		"status":       {mapping: status},
+		"nameContains": {name: "name_contains"},
+	}}
+	filter.fields["and"] = valueMappingField{mapping: filter}
+
+	t.Run("translates enum values and renames fields recursively", func(t *testing.T) {
+		out := filter.mapJSON([]byte(`{"status":"ACTIVE","nameContains":"J\"ens","and":[{"status":"INACTIVE","limit":10}]}`))
+		assert.Equal(t, `{"status":"1","name_contains":"J\"ens","and":[{"status":"0","limit":10}]}`, string(out))
+	})
+
+	t.Run("keeps values without a mapping", func(t *testing.T) {
+		assert.Equal(t, `["1","UNKNOWN",null]`, string(status.mapJSON([]byte(`["ACTIVE","UNKNOWN",null]`))))
+		assert.Equal(t, `null`, string(filter.mapJSON([]byte(`null`))))
+	})
+
+	t.Run("translates the response at the paths of the fields", func(t *testing.T) {
+		response := &valueMapping{}
+		response.field("data").field("users").field("current").enumValues = map[string]string{"1": "ACTIVE"}
+		out := response.mapJSON([]byte(`{"data":{"users":[{"current":"1","status":"1"},null]},"errors":[{"message":"1"}]}`))
+		assert.Equal(t, `{"data":{"users":[{"current":"ACTIVE","status":"1"},null]},"errors":[{"message":"1"}]}`, string(out))
+	})
+
+	t.Run("renders variables validated against the gateway schema", func(t *testing.T) {
+		renderer := &valueMappingVariableRenderer{
+			VariableRenderer: resolve.NewJSONVariableRendererWithValidation(`{"type":"string","enum":["ACTIVE","INACTIVE"]}`),
+			mapping:          status,
+		}
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, renderer.RenderVariable(context.Background(), []byte(`"ACTIVE"`), buf))
+		assert.Equal(t, `"1"`, buf.String())
+		assert.Error(t, renderer.RenderVariable(context.Background(), []byte(`"1"`), &bytes.Buffer{}))
+	})
+}
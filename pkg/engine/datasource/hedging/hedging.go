@@ -0,0 +1,111 @@
+// Package hedging sends a second attempt of an idempotent fetch if the first attempt didn't respond within a delay,
+// so that single slow requests, e.g. caused by a busy instance of the upstream, don't slow down the whole response.
+// The first successful attempt wins and the other attempt is cancelled.
+package hedging
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+)
+
+// Configuration is the hedging configuration of an upstream
+type Configuration struct {
+	// Delay is the duration after which the second attempt is sent if the first attempt didn't respond yet, 0 disables hedging.
+	// It should be about the p95 latency of the upstream, so that only slow fetches are hedged.
+	Delay time.Duration
+}
+
+func (c *Configuration) IsEnabled() bool {
+	return c.Delay > 0
+}
+
+// Upstream is the hedging of a single fetch
+type Upstream struct {
+	config Configuration
+}
+
+// NewUpstream returns the hedging of a fetch, idempotent is false for mutations and all fetches of a mutation.
+// It returns nil if hedging is disabled or the fetch isn't idempotent.
+func NewUpstream(config Configuration, idempotent bool) *Upstream {
+	if !config.IsEnabled() || !idempotent {
+		return nil
+	}
+	return &Upstream{
+		config: config,
+	}
+}
+
+type attempt struct {
+	statusCode int
+	err        error
+	response   bytes.Buffer
+}
+
+func (a *attempt) succeeded() bool {
+	return a.err == nil && a.statusCode < http.StatusInternalServerError
+}
+
+// Load sends the fetch and hedges it with a second attempt once the delay elapsed.
+// The response of the first attempt responding without error and without a 5xx status code is written,
+// if both attempts fail the failure of the attempt responding last is returned.
+// If the first attempt fails before the delay, no second attempt is sent, e.g. to leave retries to failover.
+// It loads the input as is if the fetch isn't hedged.
+func (u *Upstream) Load(ctx context.Context, input []byte, out io.Writer, load httpclient.LoadFunc) (statusCode int, err error) {
+	if u == nil {
+		return load(ctx, input, out)
+	}
+
+	results := make(chan *attempt, 2)
+	var cancels []context.CancelFunc
+	defer func() {
+		// cancels the attempt which lost
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}()
+	send := func(input []byte) {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		cancels = append(cancels, cancel)
+		go func() {
+			result := &attempt{}
+			result.statusCode, result.err = load(attemptCtx, input, &result.response)
+			results <- result
+		}()
+	}
+
+	// the attempts get their own inputs, as loading them may modify the input
+	send(append([]byte(nil), input...))
+	pending := 1
+
+	timer := time.NewTimer(u.config.Delay)
+	defer timer.Stop()
+
+	var last *attempt
+	for pending != 0 {
+		select {
+		case <-timer.C:
+			if len(cancels) == 1 {
+				send(append([]byte(nil), input...))
+				pending++
+			}
+		case result := <-results:
+			pending--
+			if result.succeeded() {
+				_, err = out.Write(result.response.Bytes())
+				return result.statusCode, err
+			}
+			last = result
+		}
+	}
+
+	if last.err != nil {
+		return last.statusCode, last.err
+	}
+	_, err = out.Write(last.response.Bytes())
+	return last.statusCode, err
+}
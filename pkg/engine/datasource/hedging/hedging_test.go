@@ -0,0 +1,145 @@
+package hedging
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+)
+
+func TestNewUpstream(t *testing.T) {
+	assert.NotNil(t, NewUpstream(Configuration{Delay: time.Millisecond}, true))
+	assert.Nil(t, NewUpstream(Configuration{Delay: time.Millisecond}, false))
+	assert.Nil(t, NewUpstream(Configuration{}, true))
+}
+
+func TestUpstream_Load(t *testing.T) {
+	const input = `{"method":"POST","url":"https://service/graphql","body":{"query":"{me}"}}`
+
+	type response struct {
+		delay      time.Duration
+		statusCode int
+		body       string
+		err        error
+	}
+
+	// load responds to the attempts in order, cancelled attempts return the error of the context
+	load := func(responses ...response) (httpclient.LoadFunc, func() []string) {
+		var (
+			mu       sync.Mutex
+			attempts int
+			results  []string
+		)
+		return func(ctx context.Context, input []byte, out io.Writer) (int, error) {
+				mu.Lock()
+				current := responses[attempts]
+				attempts++
+				mu.Unlock()
+
+				select {
+				case <-time.After(current.delay):
+				case <-ctx.Done():
+					mu.Lock()
+					results = append(results, "cancelled")
+					mu.Unlock()
+					return 0, ctx.Err()
+				}
+				mu.Lock()
+				results = append(results, current.body)
+				mu.Unlock()
+				_, _ = out.Write([]byte(current.body))
+				return current.statusCode, current.err
+			}, func() []string {
+				mu.Lock()
+				defer mu.Unlock()
+				return append([]string(nil), results...)
+			}
+	}
+
+	upstream := NewUpstream(Configuration{Delay: 20 * time.Millisecond}, true)
+
+	t.Run("doesn't hedge fast fetches", func(t *testing.T) {
+		loadFunc, results := load(response{statusCode: http.StatusOK, body: "first"})
+		out := &bytes.Buffer{}
+		statusCode, err := upstream.Load(context.Background(), []byte(input), out, loadFunc)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, "first", out.String())
+		time.Sleep(40 * time.Millisecond)
+		assert.Equal(t, []string{"first"}, results())
+	})
+
+	t.Run("the hedge wins over a slow first attempt which is cancelled", func(t *testing.T) {
+		loadFunc, results := load(
+			response{delay: time.Second, statusCode: http.StatusOK, body: "first"},
+			response{statusCode: http.StatusOK, body: "hedge"},
+		)
+		out := &bytes.Buffer{}
+		_, err := upstream.Load(context.Background(), []byte(input), out, loadFunc)
+		require.NoError(t, err)
+		assert.Equal(t, "hedge", out.String())
+		assert.Eventually(t, func() bool {
+			return len(results()) == 2
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, []string{"hedge", "cancelled"}, results())
+	})
+
+	t.Run("the first success wins over a failed hedge", func(t *testing.T) {
+		loadFunc, _ := load(
+			response{delay: 60 * time.Millisecond, statusCode: http.StatusOK, body: "first"},
+			response{statusCode: http.StatusServiceUnavailable, body: "hedge"},
+		)
+		out := &bytes.Buffer{}
+		statusCode, err := upstream.Load(context.Background(), []byte(input), out, loadFunc)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, statusCode)
+		assert.Equal(t, "first", out.String())
+	})
+
+	t.Run("returns the last failure if both attempts fail", func(t *testing.T) {
+		loadFunc, _ := load(
+			response{delay: 60 * time.Millisecond, body: "first", err: errors.New("first failed")},
+			response{statusCode: http.StatusBadGateway, body: "hedge"},
+		)
+		out := &bytes.Buffer{}
+		_, err := upstream.Load(context.Background(), []byte(input), out, loadFunc)
+		assert.EqualError(t, err, "first failed")
+		assert.Equal(t, "", out.String())
+
+		loadFunc, _ = load(
+			response{delay: 60 * time.Millisecond, body: "first", err: errors.New("first failed")},
+			response{delay: 80 * time.Millisecond, statusCode: http.StatusBadGateway, body: "hedge"},
+		)
+		out.Reset()
+		statusCode, err := upstream.Load(context.Background(), []byte(input), out, loadFunc)
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusBadGateway, statusCode)
+		assert.Equal(t, "hedge", out.String())
+	})
+
+	t.Run("doesn't hedge fetches failing before the delay", func(t *testing.T) {
+		loadFunc, results := load(response{body: "first", err: errors.New("first failed")})
+		_, err := upstream.Load(context.Background(), []byte(input), &bytes.Buffer{}, loadFunc)
+		assert.EqualError(t, err, "first failed")
+		time.Sleep(40 * time.Millisecond)
+		assert.Equal(t, []string{"first"}, results())
+	})
+
+	t.Run("loads the input as is if not hedged", func(t *testing.T) {
+		var disabled *Upstream
+		loadFunc, _ := load(response{delay: 40 * time.Millisecond, statusCode: http.StatusOK, body: "first"})
+		out := &bytes.Buffer{}
+		_, err := disabled.Load(context.Background(), []byte(input), out, loadFunc)
+		require.NoError(t, err)
+		assert.Equal(t, "first", out.String())
+	})
+}
@@ -0,0 +1,139 @@
+package httpclient
+
+import (
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	maxDecompressedSize ctxKey = "max_decompressed_size"
+)
+
+// ErrDecompressedSizeLimitExceeded is returned if a decompressed response exceeds the limit set by CtxSetMaxDecompressedSize
+var ErrDecompressedSizeLimitExceeded = errors.New("decompressed response size limit exceeded")
+
+// ContentDecoder returns a reader decompressing the body of a response with the Content-Encoding it's registered for
+type ContentDecoder func(body io.Reader) (io.ReadCloser, error)
+
+var (
+	contentDecodersMu sync.RWMutex
+	contentDecoders   = map[string]ContentDecoder{
+		"gzip": func(body io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(body)
+		},
+		"deflate": func(body io.Reader) (io.ReadCloser, error) {
+			return flate.NewReader(body), nil
+		},
+		"zstd": func(body io.Reader) (io.ReadCloser, error) {
+			decoder, err := zstd.NewReader(body, zstd.WithDecoderConcurrency(1))
+			if err != nil {
+				return nil, err
+			}
+			return decoder.IOReadCloser(), nil
+		},
+	}
+)
+
+// RegisterContentDecoder registers the decoder of the Content-Encoding, e.g. "br", replacing an existing decoder.
+// gzip, deflate and zstd are supported out of the box.
+func RegisterContentDecoder(encoding string, decoder ContentDecoder) {
+	contentDecodersMu.Lock()
+	defer contentDecodersMu.Unlock()
+	contentDecoders[strings.ToLower(encoding)] = decoder
+}
+
+func contentDecoder(encoding string) (ContentDecoder, bool) {
+	contentDecodersMu.RLock()
+	defer contentDecodersMu.RUnlock()
+	decoder, ok := contentDecoders[encoding]
+	return decoder, ok
+}
+
+// CtxSetMaxDecompressedSize limits the number of bytes read from each decompressed response, 0 disables the limit
+func CtxSetMaxDecompressedSize(ctx context.Context, bytes int64) context.Context {
+	return context.WithValue(ctx, maxDecompressedSize, bytes)
+}
+
+func CtxGetMaxDecompressedSize(ctx context.Context) int64 {
+	bytes, _ := ctx.Value(maxDecompressedSize).(int64)
+	return bytes
+}
+
+// respBodyReader decompresses the body of the response if the request accepted encodings,
+// multiple encodings are decoded in reverse order of the Content-Encoding header.
+func respBodyReader(req *http.Request, resp *http.Response) (io.ReadCloser, error) {
+	if req.Header.Get(AcceptEncodingHeader) == "" {
+		if resp.Uncompressed {
+			// transparently decompressed by the transport
+			return limitDecompressedSize(req.Context(), resp.Body), nil
+		}
+		return resp.Body, nil
+	}
+
+	encodings := strings.Split(resp.Header.Get(ContentEncodingHeader), ",")
+	var (
+		body       io.ReadCloser = resp.Body
+		compressed bool
+	)
+	for i := len(encodings) - 1; i >= 0; i-- {
+		encoding := strings.ToLower(strings.TrimSpace(encodings[i]))
+		if encoding == "" || encoding == "identity" {
+			continue
+		}
+		decoder, ok := contentDecoder(encoding)
+		if !ok {
+			return nil, fmt.Errorf("unsupported content encoding: %s", encoding)
+		}
+		decoded, err := decoder(body)
+		if err != nil {
+			return nil, err
+		}
+		body = decoded
+		compressed = true
+	}
+
+	if !compressed {
+		return body, nil
+	}
+	return limitDecompressedSize(req.Context(), body), nil
+}
+
+func limitDecompressedSize(ctx context.Context, body io.ReadCloser) io.ReadCloser {
+	limit := CtxGetMaxDecompressedSize(ctx)
+	if limit <= 0 {
+		return body
+	}
+	return &decompressedSizeLimiter{ReadCloser: body, remaining: limit}
+}
+
+// decompressedSizeLimiter fails reading a decompressed response once it exceeds the limit,
+// so that small compressed responses can't exhaust the memory
+type decompressedSizeLimiter struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (l *decompressedSizeLimiter) Read(p []byte) (n int, err error) {
+	if l.remaining < 0 {
+		return 0, ErrDecompressedSizeLimitExceeded
+	}
+	if int64(len(p)) > l.remaining+1 {
+		// read one more byte than the limit to tell a response of exactly the limit from a larger one
+		p = p[:l.remaining+1]
+	}
+	n, err = l.ReadCloser.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n, ErrDecompressedSizeLimitExceeded
+	}
+	return n, err
+}
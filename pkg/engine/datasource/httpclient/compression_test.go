@@ -0,0 +1,123 @@
+package httpclient
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpClientDo_Compression(t *testing.T) {
+	body := strings.Repeat(`{"foo":"bar"}`, 64)
+
+	compressed := func(t *testing.T, encoding string) []byte {
+		buf := &bytes.Buffer{}
+		var writer io.WriteCloser
+		switch encoding {
+		case "gzip":
+			writer = gzip.NewWriter(buf)
+		case "deflate":
+			writer, _ = flate.NewWriter(buf, flate.DefaultCompression)
+		case "zstd":
+			writer, _ = zstd.NewWriter(buf)
+		}
+		_, err := writer.Write([]byte(body))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+		return buf.Bytes()
+	}
+
+	newServer := func(t *testing.T, contentEncoding string, response []byte) *httptest.Server {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "gzip, deflate, zstd", r.Header.Get(AcceptEncodingHeader))
+			w.Header().Set(ContentEncodingHeader, contentEncoding)
+			_, _ = w.Write(response)
+		}))
+		t.Cleanup(server.Close)
+		return server
+	}
+
+	do := func(ctx context.Context, url string) (string, error) {
+		input := SetInputURL(nil, []byte(url))
+		input = SetInputMethod(input, []byte("GET"))
+		input = SetInputAcceptEncoding(input, []string{"gzip", "deflate", "zstd"})
+		out := &bytes.Buffer{}
+		err := Do(http.DefaultClient, ctx, input, out)
+		return out.String(), err
+	}
+
+	for _, encoding := range []string{"gzip", "deflate", "zstd"} {
+		t.Run(encoding, func(t *testing.T) {
+			server := newServer(t, encoding, compressed(t, encoding))
+			out, err := do(context.Background(), server.URL)
+			require.NoError(t, err)
+			assert.Equal(t, body, out)
+		})
+	}
+
+	t.Run("multiple encodings are decoded in reverse order", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		writer := gzip.NewWriter(buf)
+		_, err := writer.Write(compressed(t, "zstd"))
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		server := newServer(t, "zstd, gzip", buf.Bytes())
+		out, err := do(context.Background(), server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, body, out)
+	})
+
+	t.Run("uncompressed responses are passed through", func(t *testing.T) {
+		server := newServer(t, "", []byte(body))
+		out, err := do(CtxSetMaxDecompressedSize(context.Background(), 10), server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, body, out)
+	})
+
+	t.Run("unsupported encodings fail", func(t *testing.T) {
+		server := newServer(t, "compress", []byte(body))
+		_, err := do(context.Background(), server.URL)
+		assert.EqualError(t, err, "unsupported content encoding: compress")
+	})
+
+	t.Run("registered decoders", func(t *testing.T) {
+		RegisterContentDecoder("reverse", func(body io.Reader) (io.ReadCloser, error) {
+			data, err := io.ReadAll(body)
+			for i, j := 0, len(data)-1; i < j; i, j = i+1, j-1 {
+				data[i], data[j] = data[j], data[i]
+			}
+			return io.NopCloser(bytes.NewReader(data)), err
+		})
+		defer func() {
+			contentDecodersMu.Lock()
+			delete(contentDecoders, "reverse")
+			contentDecodersMu.Unlock()
+		}()
+
+		server := newServer(t, "reverse", []byte(`}"rab":"oof"{`))
+		out, err := do(context.Background(), server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, `{"foo":"bar"}`, out)
+	})
+
+	t.Run("decompressed size limit", func(t *testing.T) {
+		server := newServer(t, "gzip", compressed(t, "gzip"))
+
+		out, err := do(CtxSetMaxDecompressedSize(context.Background(), int64(len(body))), server.URL)
+		require.NoError(t, err)
+		assert.Equal(t, body, out)
+
+		_, err = do(CtxSetMaxDecompressedSize(context.Background(), int64(len(body)-1)), server.URL)
+		assert.ErrorIs(t, err, ErrDecompressedSizeLimitExceeded)
+	})
+}
@@ -0,0 +1,53 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	responseFreshness ctxKey = "response_freshness"
+)
+
+// ResponseFreshness is the freshness of an upstream response. Do fills it from the headers of HTTP caches between the gateway
+// and the upstream, e.g. CDNs: the response is cached if it has an Age header or a cache status header like X-Cache: HIT.
+type ResponseFreshness struct {
+	// Cached is true if the response was served by a cache instead of the origin
+	Cached bool
+	// Age is the time since the response was fetched from the origin
+	Age time.Duration
+}
+
+// CtxSetResponseFreshness reports the freshness of the response of a fetch with the context to freshness
+func CtxSetResponseFreshness(ctx context.Context, freshness *ResponseFreshness) context.Context {
+	return context.WithValue(ctx, responseFreshness, freshness)
+}
+
+func CtxGetResponseFreshness(ctx context.Context) (freshness *ResponseFreshness, ok bool) {
+	freshness, ok = ctx.Value(responseFreshness).(*ResponseFreshness)
+	return freshness, ok
+}
+
+var cacheStatusHeaders = []string{"X-Cache", "X-Cache-Status", "CF-Cache-Status"}
+
+// setResponseFreshness reports the freshness of the response if it's requested by the context
+func setResponseFreshness(ctx context.Context, header http.Header) {
+	freshness, ok := CtxGetResponseFreshness(ctx)
+	if !ok {
+		return
+	}
+	if age, err := strconv.ParseInt(strings.TrimSpace(header.Get("Age")), 10, 64); err == nil && age >= 0 {
+		freshness.Cached = true
+		freshness.Age = time.Duration(age) * time.Second
+	}
+	for _, name := range cacheStatusHeaders {
+		status := strings.ToUpper(header.Get(name))
+		// e.g. "HIT", "HIT from proxy", "STALE" or "MISS"
+		if strings.HasPrefix(status, "HIT") || strings.HasPrefix(status, "STALE") {
+			freshness.Cached = true
+		}
+	}
+}
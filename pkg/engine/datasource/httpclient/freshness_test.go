@@ -0,0 +1,48 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpClientDo_ResponseFreshness(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for key, values := range header {
+			w.Header()[key] = values
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	freshness := func(t *testing.T, responseHeader http.Header) ResponseFreshness {
+		header = responseHeader
+		freshness := &ResponseFreshness{}
+		input := SetInputURL(nil, []byte(server.URL))
+		input = SetInputMethod(input, []byte("GET"))
+		require.NoError(t, Do(http.DefaultClient, CtxSetResponseFreshness(context.Background(), freshness), input, &bytes.Buffer{}))
+		return *freshness
+	}
+
+	t.Run("responses of the origin", func(t *testing.T) {
+		assert.Equal(t, ResponseFreshness{}, freshness(t, nil))
+		assert.Equal(t, ResponseFreshness{}, freshness(t, http.Header{"X-Cache": []string{"MISS"}}))
+	})
+
+	t.Run("responses with age", func(t *testing.T) {
+		assert.Equal(t, ResponseFreshness{Cached: true, Age: 42 * time.Second}, freshness(t, http.Header{"Age": []string{"42"}}))
+		assert.Equal(t, ResponseFreshness{}, freshness(t, http.Header{"Age": []string{"invalid"}}))
+	})
+
+	t.Run("responses with cache status", func(t *testing.T) {
+		assert.Equal(t, ResponseFreshness{Cached: true}, freshness(t, http.Header{"X-Cache": []string{"Hit from cloudfront"}}))
+		assert.Equal(t, ResponseFreshness{Cached: true, Age: 5 * time.Second}, freshness(t, http.Header{"Cf-Cache-Status": []string{"STALE"}, "Age": []string{"5"}}))
+	})
+}
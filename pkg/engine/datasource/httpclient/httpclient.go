@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"io"
+	"strings"
 
 	"github.com/buger/jsonparser"
 	bytetemplate "github.com/jensneuse/byte-template"
@@ -31,6 +32,7 @@ const (
 	SCHEME          = "scheme"
 	HOST            = "host"
 	UNNULLVARIABLES = "unnull_variables"
+	ACCEPTENCODING  = "accept_encoding"
 
 	removeUndefinedVariables ctxKey = "remove_undefined_variables"
 )
@@ -42,6 +44,7 @@ var (
 		{BODY},
 		{HEADER},
 		{QUERYPARAMS},
+		{ACCEPTENCODING},
 	}
 	subscriptionInputPaths = [][]string{
 		{URL},
@@ -202,7 +205,17 @@ func SetInputPath(input, path []byte) []byte {
 	return out
 }
 
-func requestInputParams(input []byte) (url, method, body, headers, queryParams []byte) {
+// SetInputAcceptEncoding sets the encodings accepted for the response, e.g. gzip and zstd.
+// The response is decoded with the ContentDecoder registered for its Content-Encoding.
+func SetInputAcceptEncoding(input []byte, encodings []string) []byte {
+	if len(encodings) == 0 {
+		return input
+	}
+	out, _ := sjson.SetBytes(input, ACCEPTENCODING, strings.Join(encodings, ", "))
+	return out
+}
+
+func requestInputParams(input []byte) (url, method, body, headers, queryParams, acceptEncoding []byte) {
 	jsonparser.EachKey(input, func(i int, bytes []byte, valueType jsonparser.ValueType, err error) {
 		switch i {
 		case 0:
@@ -215,6 +228,8 @@ func requestInputParams(input []byte) (url, method, body, headers, queryParams [
 			headers = bytes
 		case 4:
 			queryParams = bytes
+		case 5:
+			acceptEncoding = bytes
 		}
 	}, inputPaths...)
 	return
@@ -14,6 +14,7 @@ import (
 
 	"github.com/wundergraph/graphql-go-tools/internal/pkg/quotes"
 	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+	"github.com/wundergraph/graphql-go-tools/pkg/tracing"
 )
 
 func TestHttpClient(t *testing.T) {
@@ -174,6 +175,30 @@ func TestHttpClientDo(t *testing.T) {
 		t.Run("net", runTest(background, input, `ok`))
 	})
 
+	t.Run("trace context", func(t *testing.T) {
+		traceContext := tracing.TraceContext{
+			TraceID:      "4bf92f3577b34da6a3ce929d0e0e4736",
+			SpanID:       "00f067aa0ba902b7",
+			ParentSpanID: "b7ad6b7169203331",
+			Sampled:      true,
+		}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", r.Header.Get("traceparent"))
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", r.Header.Get("X-B3-TraceId"))
+			assert.Equal(t, "00f067aa0ba902b7", r.Header.Get("X-B3-SpanId"))
+			assert.Equal(t, "b7ad6b7169203331", r.Header.Get("X-B3-ParentSpanId"))
+			assert.Equal(t, "1", r.Header.Get("X-B3-Sampled"))
+			_, err := w.Write([]byte("ok"))
+			assert.NoError(t, err)
+		}))
+		defer server.Close()
+		var input []byte
+		input = SetInputMethod(input, []byte("GET"))
+		input = SetInputURL(input, []byte(server.URL))
+		input = SetInputHeader(input, []byte(`{"traceparent":["00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"]}`))
+		t.Run("net", runTest(tracing.NewContext(background, traceContext), input, `ok`))
+	})
+
 	t.Run("gzip", func(t *testing.T) {
 		body := []byte(`{"foo":"bar"}`)
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -196,3 +221,22 @@ func TestHttpClientDo(t *testing.T) {
 		t.Run("net", runTest(background, input, `ok`))
 	})
 }
+
+func TestNewRequest(t *testing.T) {
+	var input []byte
+	input = SetInputMethod(input, []byte("POST"))
+	input = SetInputURL(input, []byte("https://example.com/graphql"))
+	input = SetInputBody(input, []byte(`{"query":"{hello}"}`))
+	input = SetInputHeader(input, []byte(`{"X-Api-Key":["secret"]}`))
+	input = SetInputQueryParams(input, []byte(`[{"name":"foo","value":"bar"}]`))
+
+	request, err := NewRequest(context.Background(), input)
+	assert.NoError(t, err)
+	assert.Equal(t, "POST", request.Method)
+	assert.Equal(t, "https://example.com/graphql?foo=bar", request.URL.String())
+	assert.Equal(t, "secret", request.Header.Get("X-Api-Key"))
+	assert.Equal(t, "application/json", request.Header.Get("Content-Type"))
+	body, err := io.ReadAll(request.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"query":"{hello}"}`, string(body))
+}
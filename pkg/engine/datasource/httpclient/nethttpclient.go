@@ -2,8 +2,6 @@ package httpclient
 
 import (
 	"bytes"
-	"compress/flate"
-	"compress/gzip"
 	"context"
 	"io"
 	"net/http"
@@ -12,6 +10,7 @@ import (
 	"github.com/buger/jsonparser"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+	"github.com/wundergraph/graphql-go-tools/pkg/tracing"
 )
 
 const (
@@ -34,12 +33,77 @@ var (
 )
 
 func Do(client *http.Client, ctx context.Context, requestInput []byte, out io.Writer) (err error) {
+	return DoWithHeader(client, ctx, requestInput, nil, out)
+}
+
+// DoWithHeader works like Do but additionally sets the header on the request,
+// overriding headers of the request input with the same name.
+// It allows sending headers, e.g. credentials, which must not be part of the request input.
+func DoWithHeader(client *http.Client, ctx context.Context, requestInput []byte, header http.Header, out io.Writer) (err error) {
+	_, err = DoWithStatusCode(client, ctx, requestInput, header, out)
+	return err
+}
+
+// LoadFunc sends the httpclient input to the upstream and returns the status code of the response if it's known, otherwise 0.
+// It's the signature of the attempts wrapped by the failover, hedging and concurrency limits of upstreams.
+type LoadFunc func(ctx context.Context, input []byte, out io.Writer) (statusCode int, err error)
+
+// DoWithStatusCode works like DoWithHeader but additionally returns the status code of the response,
+// e.g. to fail over to another endpoint if the upstream is unavailable.
+func DoWithStatusCode(client *http.Client, ctx context.Context, requestInput []byte, header http.Header, out io.Writer) (statusCode int, err error) {
+	ctx, cancel, budget, hasBudget := withTimeoutBudget(ctx)
+	defer cancel()
+	if hasBudget && budget <= 0 {
+		// the response would arrive too late, so the upstream doesn't need to work on the request
+		return 0, context.DeadlineExceeded
+	}
+
+	request, err := NewRequest(ctx, requestInput)
+	if err != nil {
+		return 0, err
+	}
+
+	for key, values := range header {
+		request.Header.Del(key)
+		for _, value := range values {
+			request.Header.Add(key, value)
+		}
+	}
+
+	if hasBudget {
+		setTimeoutBudgetHeader(ctx, request.Header, budget)
+	}
+
+	if traceContext, ok := tracing.FromContext(ctx); ok {
+		traceContext.Inject(request.Header)
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+
+	setResponseFreshness(ctx, response.Header)
+
+	respReader, err := respBodyReader(request, response)
+	if err != nil {
+		return 0, err
+	}
+	defer respReader.Close()
+
+	_, err = io.Copy(out, respReader)
+	return response.StatusCode, err
+}
 
-	url, method, body, headers, queryParams := requestInputParams(requestInput)
+// NewRequest creates the request of the request input with its url, method, body, headers and query parameters,
+// like it's sent by Do, e.g. to inspect the request of a fetch without sending it.
+func NewRequest(ctx context.Context, requestInput []byte) (*http.Request, error) {
+	url, method, body, headers, queryParams, acceptEncoding := requestInputParams(requestInput)
 
 	request, err := http.NewRequestWithContext(ctx, string(method), string(url), bytes.NewReader(body))
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if headers != nil {
@@ -56,7 +120,7 @@ func Do(client *http.Client, ctx context.Context, requestInput []byte, out io.Wr
 			return err
 		})
 		if err != nil {
-			return err
+			return nil, err
 		}
 	}
 
@@ -85,40 +149,15 @@ func Do(client *http.Client, ctx context.Context, requestInput []byte, out io.Wr
 			}
 		})
 		if err != nil {
-			return err
+			return nil, err
 		}
 		request.URL.RawQuery = query.Encode()
 	}
 
 	request.Header.Add("accept", "application/json")
 	request.Header.Add("content-type", "application/json")
-
-	response, err := client.Do(request)
-	if err != nil {
-		return err
+	if len(acceptEncoding) != 0 && request.Header.Get(AcceptEncodingHeader) == "" {
+		request.Header.Set(AcceptEncodingHeader, string(acceptEncoding))
 	}
-	defer response.Body.Close()
-
-	respReader, err := respBodyReader(request, response)
-	if err != nil {
-		return err
-	}
-
-	_, err = io.Copy(out, respReader)
-	return
-}
-
-func respBodyReader(req *http.Request, resp *http.Response) (io.ReadCloser, error) {
-	if req.Header.Get(AcceptEncodingHeader) == "" {
-		return resp.Body, nil
-	}
-
-	switch resp.Header.Get(ContentEncodingHeader) {
-	case "gzip":
-		return gzip.NewReader(resp.Body)
-	case "deflate":
-		return flate.NewReader(resp.Body), nil
-	}
-
-	return resp.Body, nil
+	return request, nil
 }
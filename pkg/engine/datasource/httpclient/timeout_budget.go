@@ -0,0 +1,59 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	timeoutBudget ctxKey = "timeout_budget"
+)
+
+// TimeoutBudget propagates the remaining time of an operation to the upstreams of its fetches, so that upstreams can stop
+// working on requests whose response would arrive too late. The budget of a fetch is the time until the deadline of the
+// context minus the reserve, it's the deadline of the fetch and is sent in milliseconds in the header.
+// Operations without deadline have no budget.
+type TimeoutBudget struct {
+	// Header is the name of the header containing the budget in milliseconds, e.g. X-Timeout-Ms.
+	// No header is sent if it's empty, the budget is the deadline of the fetch nonetheless.
+	Header string
+	// Reserve is the time kept back for the operation, e.g. to resolve and write the response after the last fetch timed out
+	Reserve time.Duration
+}
+
+// CtxSetTimeoutBudget propagates the remaining time budget of the operation to each fetch, see TimeoutBudget
+func CtxSetTimeoutBudget(ctx context.Context, budget TimeoutBudget) context.Context {
+	return context.WithValue(ctx, timeoutBudget, budget)
+}
+
+func CtxGetTimeoutBudget(ctx context.Context) (budget TimeoutBudget, ok bool) {
+	budget, ok = ctx.Value(timeoutBudget).(TimeoutBudget)
+	return budget, ok
+}
+
+// withTimeoutBudget returns the context of a fetch with the deadline of its budget and the budget,
+// ok is false if the context has no budget
+func withTimeoutBudget(ctx context.Context) (fetchCtx context.Context, cancel context.CancelFunc, budget time.Duration, ok bool) {
+	config, ok := CtxGetTimeoutBudget(ctx)
+	if !ok {
+		return ctx, func() {}, 0, false
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return ctx, func() {}, 0, false
+	}
+	deadline = deadline.Add(-config.Reserve)
+	fetchCtx, cancel = context.WithDeadline(ctx, deadline)
+	return fetchCtx, cancel, time.Until(deadline), true
+}
+
+// setTimeoutBudgetHeader sets the budget of the fetch in milliseconds as header of the request
+func setTimeoutBudgetHeader(ctx context.Context, header http.Header, budget time.Duration) {
+	config, _ := CtxGetTimeoutBudget(ctx)
+	if config.Header == "" {
+		return
+	}
+	header.Set(config.Header, strconv.FormatInt(budget.Milliseconds(), 10))
+}
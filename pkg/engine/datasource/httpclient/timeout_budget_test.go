@@ -0,0 +1,81 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHttpClientDo_TimeoutBudget(t *testing.T) {
+	var (
+		requests int
+		header   string
+		deadline time.Time
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		header = r.Header.Get("X-Timeout-Ms")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		deadline, _ = req.Context().Deadline()
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+
+	do := func(ctx context.Context) error {
+		input := SetInputURL(nil, []byte(server.URL))
+		input = SetInputMethod(input, []byte("GET"))
+		input = SetInputHeader(input, []byte(`{"X-Timeout-Ms":["1"]}`))
+		return Do(client, ctx, input, &bytes.Buffer{})
+	}
+
+	t.Run("sends the remaining budget and uses it as deadline", func(t *testing.T) {
+		requests, header, deadline = 0, "", time.Time{}
+		operationDeadline := time.Now().Add(time.Minute)
+		ctx, cancel := context.WithDeadline(context.Background(), operationDeadline)
+		defer cancel()
+		ctx = CtxSetTimeoutBudget(ctx, TimeoutBudget{Header: "X-Timeout-Ms", Reserve: 10 * time.Second})
+
+		require.NoError(t, do(ctx))
+		assert.Equal(t, 1, requests)
+		budget, err := strconv.Atoi(header)
+		require.NoError(t, err)
+		assert.InDelta(t, 50000, budget, 1000)
+		assert.Equal(t, operationDeadline.Add(-10*time.Second), deadline)
+	})
+
+	t.Run("doesn't send requests without budget", func(t *testing.T) {
+		requests = 0
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		ctx = CtxSetTimeoutBudget(ctx, TimeoutBudget{Header: "X-Timeout-Ms", Reserve: 2 * time.Second})
+
+		assert.ErrorIs(t, do(ctx), context.DeadlineExceeded)
+		assert.Equal(t, 0, requests)
+	})
+
+	t.Run("operations without deadline have no budget", func(t *testing.T) {
+		requests, header, deadline = 0, "", time.Time{}
+		ctx := CtxSetTimeoutBudget(context.Background(), TimeoutBudget{Header: "X-Timeout-Ms"})
+
+		require.NoError(t, do(ctx))
+		assert.Equal(t, 1, requests)
+		assert.Equal(t, "1", header, "the header of the request input isn't overridden")
+		assert.True(t, deadline.IsZero())
+	})
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
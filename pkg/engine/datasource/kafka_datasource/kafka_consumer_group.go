@@ -7,7 +7,8 @@ import (
 
 	"github.com/Shopify/sarama"
 	"github.com/buger/jsonparser"
-	log "github.com/jensneuse/abstractlogger"
+
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 const consumerGroupRetryInterval = time.Second
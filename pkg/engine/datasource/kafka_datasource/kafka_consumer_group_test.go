@@ -10,9 +10,10 @@ import (
 
 	"github.com/Shopify/sarama"
 	"github.com/Shopify/sarama/mocks"
-	log "github.com/jensneuse/abstractlogger"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 const defaultPartition = 0
@@ -4,9 +4,8 @@ import (
 	"context"
 	"encoding/json"
 
-	"github.com/jensneuse/abstractlogger"
-
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 type Planner struct {
@@ -27,7 +26,7 @@ func (p *Planner) ConfigureSubscription() plan.SubscriptionConfiguration {
 	return plan.SubscriptionConfiguration{
 		Input: string(input),
 		DataSource: &SubscriptionSource{
-			client: NewKafkaConsumerGroupBridge(p.ctx, abstractlogger.NoopLogger),
+			client: NewKafkaConsumerGroupBridge(p.ctx, logging.NoopLogger),
 		},
 	}
 }
@@ -11,13 +11,13 @@ import (
 
 	"github.com/Shopify/sarama"
 	"github.com/buger/jsonparser"
-	"github.com/jensneuse/abstractlogger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasourcetesting"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 const (
@@ -224,7 +224,7 @@ func (f FailingSubscriptionClient) Subscribe(ctx context.Context, options GraphQ
 
 func TestKafkaDataSource_Subscription_Start(t *testing.T) {
 	newSubscriptionSource := func(ctx context.Context) SubscriptionSource {
-		subscriptionSource := SubscriptionSource{client: NewKafkaConsumerGroupBridge(ctx, abstractlogger.NoopLogger)}
+		subscriptionSource := SubscriptionSource{client: NewKafkaConsumerGroupBridge(ctx, logging.NoopLogger)}
 		return subscriptionSource
 	}
 
@@ -293,7 +293,7 @@ func TestKafkaConsumerGroupBridge_Subscribe(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	cg := NewKafkaConsumerGroupBridge(ctx, logger()) // use abstractlogger.NoopLogger if there is no available logger.
+	cg := NewKafkaConsumerGroupBridge(ctx, logger()) // use logging.NoopLogger if there is no available logger.
 
 	options := GraphQLSubscriptionOptions{
 		BrokerAddresses: []string{mockBroker.Addr()},
@@ -0,0 +1,207 @@
+// Package replay_datasource records the fetches of an operation into a bundle and replays them from the bundle,
+// e.g. to reproduce an incident of production locally without access to the upstreams.
+//
+// Bundles contain the inputs of the fetches as sent to the upstreams, including headers like authorization headers,
+// so they have to be treated like the credentials of the upstreams.
+package replay_datasource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+)
+
+var ErrFetchNotRecorded = errors.New("fetch is not recorded in the bundle")
+
+// Bundle holds the request of an operation and all fetches executed for it
+type Bundle struct {
+	Request BundleRequest   `json:"request"`
+	Fetches []RecordedFetch `json:"fetches"`
+}
+
+// BundleRequest is the client request of the operation, it's executed again to replay the bundle
+type BundleRequest struct {
+	OperationName string          `json:"operationName,omitempty"`
+	Query         string          `json:"query"`
+	Variables     json.RawMessage `json:"variables,omitempty"`
+	Header        http.Header     `json:"header,omitempty"`
+}
+
+// RecordedFetch is a fetch with the input sent to the data source and its response
+type RecordedFetch struct {
+	// DataSource is the identifier of the data source, e.g. graphql_datasource.Source
+	DataSource string `json:"dataSource"`
+	Input      string `json:"input"`
+	Response   string `json:"response"`
+	// Error is the error returned by the data source, empty if the fetch succeeded
+	Error string `json:"error,omitempty"`
+}
+
+// ReadBundle reads a bundle written by Recorder.WriteBundle
+func ReadBundle(reader io.Reader) (bundle Bundle, err error) {
+	err = json.NewDecoder(reader).Decode(&bundle)
+	return bundle, err
+}
+
+// Recorder is a resolve.FetchRecorder recording the fetches of one operation into a bundle,
+// see graphql.WithFetchRecorder to record the fetches of an execution
+type Recorder struct {
+	mu     sync.Mutex
+	bundle Bundle
+}
+
+func NewRecorder(request BundleRequest) *Recorder {
+	return &Recorder{
+		bundle: Bundle{Request: request},
+	}
+}
+
+func (r *Recorder) RecordFetch(dataSourceIdentifier string, input, response []byte, err error) {
+	fetch := RecordedFetch{
+		DataSource: dataSourceIdentifier,
+		Input:      string(input),
+		Response:   string(response),
+	}
+	if err != nil {
+		fetch.Error = err.Error()
+	}
+
+	r.mu.Lock()
+	r.bundle.Fetches = append(r.bundle.Fetches, fetch)
+	r.mu.Unlock()
+}
+
+// Bundle returns the request and the fetches recorded so far
+func (r *Recorder) Bundle() Bundle {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	bundle := r.bundle
+	bundle.Fetches = append([]RecordedFetch(nil), r.bundle.Fetches...)
+	return bundle
+}
+
+// WriteBundle writes the bundle as JSON, see ReadBundle
+func (r *Recorder) WriteBundle(writer io.Writer) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r.Bundle())
+}
+
+// Replay returns copies of the data sources which fetch from the bundle instead of their upstreams.
+// The fetches are still planned by the data sources, so the inputs match the recorded inputs as long as
+// the configuration of the data sources and the request are the same as when recording.
+// Fetches recorded multiple times with the same input are replayed in order, the last response is repeated once all are replayed.
+// Subscriptions aren't recorded and are still served by the upstreams.
+func Replay(dataSources []plan.DataSourceConfiguration, bundle Bundle) []plan.DataSourceConfiguration {
+	replayer := newReplayer(bundle)
+	replayed := make([]plan.DataSourceConfiguration, len(dataSources))
+	for i := range dataSources {
+		replayed[i] = dataSources[i]
+		replayed[i].Factory = &Factory{
+			Factory:  dataSources[i].Factory,
+			replayer: replayer,
+		}
+	}
+	return replayed
+}
+
+// Factory plans fetches with the wrapped Factory and replays them from the bundle, see Replay
+type Factory struct {
+	Factory  plan.PlannerFactory
+	replayer *replayer
+}
+
+func (f *Factory) Planner(ctx context.Context) plan.DataSourcePlanner {
+	return &Planner{
+		DataSourcePlanner: f.Factory.Planner(ctx),
+		replayer:          f.replayer,
+	}
+}
+
+type Planner struct {
+	plan.DataSourcePlanner
+	replayer *replayer
+}
+
+func (p *Planner) ConfigureFetch() plan.FetchConfiguration {
+	config := p.DataSourcePlanner.ConfigureFetch()
+	config.DataSource = &Source{
+		dataSource: dataSourceIdentifier(config.DataSource),
+		replayer:   p.replayer,
+	}
+	return config
+}
+
+// dataSourceIdentifier returns the identifier of the data source like resolve.SingleFetch.DataSourceIdentifier
+func dataSourceIdentifier(dataSource resolve.DataSource) string {
+	return strings.TrimPrefix(reflect.TypeOf(dataSource).String(), "*")
+}
+
+// Source responds to the fetches with the responses recorded in the bundle
+type Source struct {
+	dataSource string
+	replayer   *replayer
+}
+
+func (s *Source) Load(ctx context.Context, input []byte, w io.Writer) (err error) {
+	fetch, ok := s.replayer.next(s.dataSource, string(input))
+	if !ok {
+		return fmt.Errorf("%w: %s %s", ErrFetchNotRecorded, s.dataSource, input)
+	}
+	if _, err = io.WriteString(w, fetch.Response); err != nil {
+		return err
+	}
+	if fetch.Error != "" {
+		return errors.New(fetch.Error)
+	}
+	return nil
+}
+
+type replayKey struct {
+	dataSource string
+	input      string
+}
+
+type replayer struct {
+	mu       sync.Mutex
+	fetches  map[replayKey][]RecordedFetch
+	replayed map[replayKey]int
+}
+
+func newReplayer(bundle Bundle) *replayer {
+	r := &replayer{
+		fetches:  make(map[replayKey][]RecordedFetch, len(bundle.Fetches)),
+		replayed: make(map[replayKey]int, len(bundle.Fetches)),
+	}
+	for _, fetch := range bundle.Fetches {
+		key := replayKey{dataSource: fetch.DataSource, input: fetch.Input}
+		r.fetches[key] = append(r.fetches[key], fetch)
+	}
+	return r
+}
+
+func (r *replayer) next(dataSource, input string) (RecordedFetch, bool) {
+	key := replayKey{dataSource: dataSource, input: input}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fetches := r.fetches[key]
+	if len(fetches) == 0 {
+		return RecordedFetch{}, false
+	}
+	i := r.replayed[key]
+	if i >= len(fetches) {
+		return fetches[len(fetches)-1], true
+	}
+	r.replayed[key] = i + 1
+	return fetches[i], true
+}
@@ -0,0 +1,69 @@
+package replay_datasource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder(BundleRequest{Query: `{ hello }`})
+	input, response := []byte(`{"a":1}`), []byte(`{"data":1}`)
+	recorder.RecordFetch("graphql_datasource.Source", input, response, nil)
+	// the buffers of the fetcher are reused after recording
+	copy(input, `{"b":2}`)
+	recorder.RecordFetch("rest_datasource.Source", []byte(`{"c":3}`), nil, errors.New("timeout"))
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, recorder.WriteBundle(buf))
+	bundle, err := ReadBundle(buf)
+	require.NoError(t, err)
+	assert.Equal(t, Bundle{
+		Request: BundleRequest{Query: `{ hello }`},
+		Fetches: []RecordedFetch{
+			{DataSource: "graphql_datasource.Source", Input: `{"a":1}`, Response: `{"data":1}`},
+			{DataSource: "rest_datasource.Source", Input: `{"c":3}`, Error: "timeout"},
+		},
+	}, bundle)
+}
+
+func TestSource_Load(t *testing.T) {
+	replayer := newReplayer(Bundle{
+		Fetches: []RecordedFetch{
+			{DataSource: "graphql_datasource.Source", Input: `{"a":1}`, Response: `first`},
+			{DataSource: "graphql_datasource.Source", Input: `{"a":1}`, Response: `second`},
+			{DataSource: "rest_datasource.Source", Input: `{"a":1}`, Response: `rest`, Error: "timeout"},
+		},
+	})
+	graphqlSource := &Source{dataSource: "graphql_datasource.Source", replayer: replayer}
+	restSource := &Source{dataSource: "rest_datasource.Source", replayer: replayer}
+
+	load := func(source *Source, input string) (string, error) {
+		buf := &bytes.Buffer{}
+		err := source.Load(context.Background(), []byte(input), buf)
+		return buf.String(), err
+	}
+
+	t.Run("replays fetches with the same input in order", func(t *testing.T) {
+		for _, expected := range []string{"first", "second", "second"} {
+			out, err := load(graphqlSource, `{"a":1}`)
+			require.NoError(t, err)
+			assert.Equal(t, expected, out)
+		}
+	})
+
+	t.Run("replays errors", func(t *testing.T) {
+		out, err := load(restSource, `{"a":1}`)
+		assert.EqualError(t, err, "timeout")
+		assert.Equal(t, "rest", out)
+	})
+
+	t.Run("fails fetches which aren't recorded", func(t *testing.T) {
+		_, err := load(graphqlSource, `{"a":2}`)
+		assert.ErrorIs(t, err, ErrFetchNotRecorded)
+	})
+}
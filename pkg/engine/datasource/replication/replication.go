@@ -0,0 +1,170 @@
+// Package replication routes the fetches of datasources with replicated upstreams,
+// which have a primary for writes and read replicas, e.g. databases exposed via GraphQL or REST.
+// Mutations and all fetches of a mutation are sent to the primary, queries are sent to the replicas.
+// To read its own writes despite replication lag, a client is routed to the primary
+// for a stickiness window after its last mutation.
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+)
+
+type ctxKey struct{}
+
+// WithClientIdentity sets the identity of the client of the request, e.g. the user id,
+// which the stickiness to the primary after a mutation is keyed by.
+func WithClientIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, identity)
+}
+
+// ClientIdentity returns the identity of the client set by WithClientIdentity
+func ClientIdentity(ctx context.Context) string {
+	identity, _ := ctx.Value(ctxKey{}).(string)
+	return identity
+}
+
+// Configuration is the replication configuration of an upstream, the fetch URL of the datasource is the primary.
+type Configuration struct {
+	// ReplicaURLs are the base URLs of the read replicas, e.g. https://replica-1.example.com,
+	// of which scheme and host replace the ones of the fetch URL. Replicas are used round robin.
+	ReplicaURLs []string
+	// StickinessWindow is the duration the queries of a client are sent to the primary after its last mutation.
+	// Clients are identified by WithClientIdentity, 0 disables stickiness.
+	StickinessWindow time.Duration
+}
+
+func (c *Configuration) IsEnabled() bool {
+	return len(c.ReplicaURLs) != 0
+}
+
+type stickinessKey struct {
+	host     string
+	identity string
+}
+
+// Router decides whether a fetch is sent to the primary or a replica.
+// It keeps track of the last mutations of clients and should be shared by all fetches of a datasource factory.
+type Router struct {
+	mu     sync.Mutex
+	writes map[stickinessKey]time.Time
+	next   uint64
+	now    func() time.Time
+}
+
+func NewRouter() *Router {
+	return &Router{
+		writes: map[stickinessKey]time.Time{},
+		now:    time.Now,
+	}
+}
+
+// Upstream returns the routing of a fetch, write is true for mutations and all fetches of a mutation.
+// It returns nil if the upstream is not replicated.
+func (r *Router) Upstream(config Configuration, write bool) *Upstream {
+	if r == nil || !config.IsEnabled() {
+		return nil
+	}
+	return &Upstream{
+		router: r,
+		config: config,
+		write:  write,
+	}
+}
+
+// Route returns the URL the fetch of fetchURL is sent to
+func (r *Router) Route(ctx context.Context, config Configuration, fetchURL string, write bool) (string, error) {
+	if !config.IsEnabled() {
+		return fetchURL, nil
+	}
+	primary, err := url.Parse(fetchURL)
+	if err != nil {
+		return "", err
+	}
+	key := stickinessKey{host: primary.Host, identity: ClientIdentity(ctx)}
+
+	if write {
+		r.recordWrite(key, config.StickinessWindow)
+		return fetchURL, nil
+	}
+	if r.isSticky(key) {
+		return fetchURL, nil
+	}
+
+	replicaURL := config.ReplicaURLs[(atomic.AddUint64(&r.next, 1)-1)%uint64(len(config.ReplicaURLs))]
+	replica, err := url.Parse(replicaURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid replica url %s: %w", replicaURL, err)
+	}
+	primary.Scheme = replica.Scheme
+	primary.Host = replica.Host
+	return primary.String(), nil
+}
+
+func (r *Router) recordWrite(key stickinessKey, window time.Duration) {
+	if key.identity == "" || window <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	// writes are rare compared to reads, so expired entries are removed on writes
+	for existing, until := range r.writes {
+		if !now.Before(until) {
+			delete(r.writes, existing)
+		}
+	}
+	r.writes[key] = now.Add(window)
+}
+
+func (r *Router) isSticky(key stickinessKey) bool {
+	if key.identity == "" {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	until, ok := r.writes[key]
+	return ok && r.now().Before(until)
+}
+
+// Upstream is the routing of a single fetch of a replicated upstream
+type Upstream struct {
+	router *Router
+	config Configuration
+	write  bool
+}
+
+// RouteInput replaces the URL of the httpclient input with the URL the fetch is routed to.
+// It returns the input as is if the upstream isn't replicated.
+func (u *Upstream) RouteInput(ctx context.Context, input []byte) ([]byte, error) {
+	if u == nil {
+		return input, nil
+	}
+	fetchURL, err := jsonparser.GetString(input, httpclient.URL)
+	if err != nil {
+		return input, nil
+	}
+	routedURL, err := u.router.Route(ctx, u.config, fetchURL, u.write)
+	if err != nil {
+		return nil, err
+	}
+	if routedURL == fetchURL {
+		return input, nil
+	}
+	value, err := json.Marshal(routedURL)
+	if err != nil {
+		return nil, err
+	}
+	return jsonparser.Set(input, value, httpclient.URL)
+}
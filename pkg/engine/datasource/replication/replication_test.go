@@ -0,0 +1,107 @@
+package replication
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_Route(t *testing.T) {
+	config := Configuration{
+		ReplicaURLs:      []string{"https://replica-1.service", "http://replica-2.service:8080"},
+		StickinessWindow: time.Minute,
+	}
+
+	newRouter := func() (*Router, *time.Time) {
+		router := NewRouter()
+		now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		router.now = func() time.Time {
+			return now
+		}
+		return router, &now
+	}
+
+	route := func(t *testing.T, router *Router, ctx context.Context, write bool) string {
+		t.Helper()
+		routed, err := router.Route(ctx, config, "https://primary.service/users/1?fields=name", write)
+		require.NoError(t, err)
+		return routed
+	}
+
+	t.Run("queries are sent to the replicas round robin", func(t *testing.T) {
+		router, _ := newRouter()
+		assert.Equal(t, "https://replica-1.service/users/1?fields=name", route(t, router, context.Background(), false))
+		assert.Equal(t, "http://replica-2.service:8080/users/1?fields=name", route(t, router, context.Background(), false))
+		assert.Equal(t, "https://replica-1.service/users/1?fields=name", route(t, router, context.Background(), false))
+	})
+
+	t.Run("mutations are sent to the primary", func(t *testing.T) {
+		router, _ := newRouter()
+		assert.Equal(t, "https://primary.service/users/1?fields=name", route(t, router, context.Background(), true))
+	})
+
+	t.Run("queries of a client are sent to the primary within the stickiness window after its mutation", func(t *testing.T) {
+		router, now := newRouter()
+		client := WithClientIdentity(context.Background(), "client-1")
+		otherClient := WithClientIdentity(context.Background(), "client-2")
+
+		assert.Equal(t, "https://primary.service/users/1?fields=name", route(t, router, client, true))
+		assert.Equal(t, "https://primary.service/users/1?fields=name", route(t, router, client, false))
+		assert.Equal(t, "https://replica-1.service/users/1?fields=name", route(t, router, otherClient, false))
+
+		*now = now.Add(59 * time.Second)
+		assert.Equal(t, "https://primary.service/users/1?fields=name", route(t, router, client, false))
+
+		*now = now.Add(time.Second)
+		assert.Equal(t, "http://replica-2.service:8080/users/1?fields=name", route(t, router, client, false))
+
+		route(t, router, otherClient, true)
+		assert.Len(t, router.writes, 1, "expired stickiness is removed")
+	})
+
+	t.Run("stickiness of one primary doesn't apply to others", func(t *testing.T) {
+		router, _ := newRouter()
+		client := WithClientIdentity(context.Background(), "client-1")
+		route(t, router, client, true)
+
+		routed, err := router.Route(client, config, "https://other-primary.service/users", false)
+		require.NoError(t, err)
+		assert.Equal(t, "https://replica-1.service/users", routed)
+	})
+
+	t.Run("not replicated upstream", func(t *testing.T) {
+		router, _ := newRouter()
+		routed, err := router.Route(context.Background(), Configuration{}, "https://primary.service/users", false)
+		require.NoError(t, err)
+		assert.Equal(t, "https://primary.service/users", routed)
+		assert.Nil(t, router.Upstream(Configuration{}, false))
+	})
+}
+
+func TestUpstream_RouteInput(t *testing.T) {
+	router := NewRouter()
+	config := Configuration{ReplicaURLs: []string{"https://replica.service"}}
+	input := []byte(`{"method":"POST","url":"https://primary.service/graphql","body":{"query":"{me{id}}"}}`)
+
+	t.Run("read", func(t *testing.T) {
+		routed, err := router.Upstream(config, false).RouteInput(context.Background(), input)
+		require.NoError(t, err)
+		assert.Equal(t, `{"method":"POST","url":"https://replica.service/graphql","body":{"query":"{me{id}}"}}`, string(routed))
+	})
+
+	t.Run("write", func(t *testing.T) {
+		routed, err := router.Upstream(config, true).RouteInput(context.Background(), input)
+		require.NoError(t, err)
+		assert.Equal(t, string(input), string(routed))
+	})
+
+	t.Run("not replicated upstream", func(t *testing.T) {
+		var upstream *Upstream
+		routed, err := upstream.RouteInput(context.Background(), input)
+		require.NoError(t, err)
+		assert.Equal(t, string(input), string(routed))
+	})
+}
@@ -0,0 +1,226 @@
+package rest_datasource
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+	"github.com/wundergraph/graphql-go-tools/pkg/fastbuffer"
+	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+	"github.com/wundergraph/graphql-go-tools/pkg/pool"
+)
+
+const (
+	representationInputKey  = "representation"
+	representationsSelector = "representations"
+)
+
+// BatchConfiguration configures an endpoint resolving the entities of multiple representations with a single request,
+// e.g. https://example.com/users?ids={{ .representations.id }}. It's only used for the Entities of the DataSource.
+// {{ .representations.<keyField> }} renders the key field of all representations of the batch as comma separated list,
+// {{ .representations }} renders the representations as JSON array of objects containing the key fields, e.g. for request bodies.
+// Representations are only sent once per batch, even if multiple objects have the same key.
+// The endpoint has to respond with a JSON array of entities which are assigned to the representations by their key fields,
+// entities missing in the response resolve to null. The key fields are compared by value, so "1" matches 1.
+// Batching requires the data loader to be enabled, otherwise each representation is sent as batch of its own.
+type BatchConfiguration struct {
+	URL    string
+	Method string
+	Header http.Header
+	Query  []QueryConfiguration
+	Body   string
+	// ResponsePath is the path of the array of entities in the response, e.g. ["data", "users"], empty for a top level array
+	ResponsePath []string
+}
+
+// representationInput is the input of a single entity in a batch containing the key fields of the entity as JSON object,
+// the key fields are rendered from the enclosing object
+func representationInput(keyFields []string, variables *resolve.Variables) string {
+	buf := &strings.Builder{}
+	buf.WriteString(`{"` + representationInputKey + `":{`)
+	for i, keyField := range keyFields {
+		if i != 0 {
+			buf.WriteString(",")
+		}
+		name, _ := variables.AddVariable(&resolve.ObjectVariable{
+			Path:     []string{keyField},
+			Renderer: resolve.NewJSONVariableRenderer(),
+		})
+		fmt.Fprintf(buf, "%q:%s", keyField, name)
+	}
+	buf.WriteString("}}")
+	return buf.String()
+}
+
+type BatchFactory struct {
+	config          BatchConfiguration
+	keyFields       []string
+	acceptEncodings []string
+}
+
+func (b *BatchFactory) CreateBatch(inputs [][]byte) (resolve.DataSourceBatch, error) {
+	batch := &Batch{
+		keyFields:     b.keyFields,
+		responsePath:  b.config.ResponsePath,
+		resultIndices: make(map[string][]int, len(inputs)),
+		batchSize:     len(inputs),
+	}
+
+	var representations [][]byte
+	for i := range inputs {
+		representation, dataType, _, err := jsonparser.Get(inputs[i], representationInputKey)
+		if err != nil || dataType != jsonparser.Object {
+			continue
+		}
+		key, ok := batch.entityKey(representation)
+		if !ok {
+			// a key field of the object is null, the entity resolves to null
+			continue
+		}
+		if _, exists := batch.resultIndices[key]; !exists {
+			representations = append(representations, representation)
+		}
+		batch.resultIndices[key] = append(batch.resultIndices[key], i)
+	}
+
+	input := httpclient.SetInputURL(nil, []byte(b.render(b.config.URL, representations, false)))
+	input = httpclient.SetInputMethod(input, []byte(b.config.Method))
+	input = httpclient.SetInputBody(input, []byte(b.render(b.config.Body, representations, true)))
+	input = httpclient.SetInputAcceptEncoding(input, b.acceptEncodings)
+
+	if len(b.config.Header) != 0 {
+		header := make(http.Header, len(b.config.Header))
+		for name, values := range b.config.Header {
+			for _, value := range values {
+				header[name] = append(header[name], b.render(value, representations, false))
+			}
+		}
+		headerJson, err := json.Marshal(header)
+		if err != nil {
+			return nil, err
+		}
+		input = httpclient.SetInputHeader(input, headerJson)
+	}
+
+	if len(b.config.Query) != 0 {
+		query := make([]QueryConfiguration, len(b.config.Query))
+		for i := range b.config.Query {
+			query[i] = QueryConfiguration{Name: b.config.Query[i].Name, Value: b.render(b.config.Query[i].Value, representations, false)}
+		}
+		queryJson, err := json.Marshal(query)
+		if err != nil {
+			return nil, err
+		}
+		input = httpclient.SetInputQueryParams(input, queryJson)
+	}
+
+	batch.input = pool.FastBuffer.Get()
+	batch.input.WriteBytes(input)
+	return batch, nil
+}
+
+// render replaces the representations selectors of the template, inJSON renders the key fields as JSON array instead of a comma separated list
+func (b *BatchFactory) render(template string, representations [][]byte, inJSON bool) string {
+	return selectorRegex.ReplaceAllStringFunc(template, func(s string) string {
+		selector := strings.TrimPrefix(selectorRegex.FindStringSubmatch(s)[1], ".")
+		elements := strings.Split(selector, ".")
+		if elements[0] != representationsSelector || len(elements) > 2 {
+			return s
+		}
+		if len(elements) == 1 {
+			return "[" + string(bytes.Join(representations, literal.COMMA)) + "]"
+		}
+		values := make([][]byte, 0, len(representations))
+		for _, representation := range representations {
+			value, dataType, offset, err := jsonparser.Get(representation, elements[1])
+			if err != nil {
+				continue
+			}
+			if inJSON && dataType == jsonparser.String {
+				value = representation[offset-len(value)-2 : offset]
+			}
+			values = append(values, value)
+		}
+		if inJSON {
+			return "[" + string(bytes.Join(values, literal.COMMA)) + "]"
+		}
+		return string(bytes.Join(values, literal.COMMA))
+	})
+}
+
+type Batch struct {
+	input        *fastbuffer.FastBuffer
+	keyFields    []string
+	responsePath []string
+	// resultIndices are the indices of the inputs by the key of their entity
+	resultIndices map[string][]int
+	batchSize     int
+}
+
+func (b *Batch) Input() *fastbuffer.FastBuffer {
+	return b.input
+}
+
+func (b *Batch) Demultiplex(responseBufPair *resolve.BufPair, bufPairs []*resolve.BufPair) (err error) {
+	defer pool.FastBuffer.Put(b.input)
+
+	if b.batchSize != len(bufPairs) {
+		return fmt.Errorf("expected %d buf pairs", b.batchSize)
+	}
+
+	if responseBufPair.HasData() {
+		entities, dataType, _, err := jsonparser.Get(responseBufPair.Data.Bytes(), b.responsePath...)
+		if err != nil || dataType != jsonparser.Array {
+			return fmt.Errorf("batch response is not an array of entities")
+		}
+		_, err = jsonparser.ArrayEach(entities, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+			if dataType != jsonparser.Object {
+				return
+			}
+			key, ok := b.entityKey(value)
+			if !ok {
+				return
+			}
+			for _, i := range b.resultIndices[key] {
+				if bufPairs[i].Data.Len() == 0 {
+					bufPairs[i].Data.WriteBytes(value)
+				}
+			}
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := range bufPairs {
+		if bufPairs[i].Data.Len() == 0 {
+			bufPairs[i].Data.WriteBytes(literal.NULL)
+		}
+	}
+
+	if responseBufPair.HasErrors() {
+		bufPairs[0].Errors.WriteBytes(responseBufPair.Errors.Bytes())
+	}
+
+	return nil
+}
+
+// entityKey returns the values of the key fields of a representation or entity, strings are compared without quotes
+func (b *Batch) entityKey(data []byte) (key string, ok bool) {
+	buf := &strings.Builder{}
+	for _, keyField := range b.keyFields {
+		value, dataType, _, err := jsonparser.Get(data, keyField)
+		if err != nil || dataType == jsonparser.Null {
+			return "", false
+		}
+		buf.Write(value)
+		buf.WriteByte(0)
+	}
+	return buf.String(), true
+}
@@ -0,0 +1,91 @@
+package rest_datasource
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+)
+
+func TestBatchFactory(t *testing.T) {
+	inputs := [][]byte{
+		[]byte(`{"representation":{"id":"1"}}`),
+		[]byte(`{"representation":{"id":"2"}}`),
+		[]byte(`{"representation":{"id":"1"}}`),
+		[]byte(`{"representation":{"id":null}}`),
+		[]byte(`{"representation":{"id":"3"}}`),
+	}
+
+	newBufPairs := func() []*resolve.BufPair {
+		bufPairs := make([]*resolve.BufPair, len(inputs))
+		for i := range bufPairs {
+			bufPairs[i] = resolve.NewBufPair()
+		}
+		return bufPairs
+	}
+
+	response := func(data string) *resolve.BufPair {
+		bufPair := resolve.NewBufPair()
+		bufPair.Data.WriteString(data)
+		return bufPair
+	}
+
+	t.Run("renders the key fields as comma separated list", func(t *testing.T) {
+		factory := &BatchFactory{
+			config: BatchConfiguration{
+				URL:    "https://example.com/users?ids={{ .representations.id }}",
+				Method: "GET",
+				Query:  []QueryConfiguration{{Name: "ids", Value: "{{ .representations.id }}"}},
+			},
+			keyFields: []string{"id"},
+		}
+		batch, err := factory.CreateBatch(inputs)
+		require.NoError(t, err)
+		assert.Equal(t, `{"query_params":[{"name":"ids","value":"1,2,3"}],"method":"GET","url":"https://example.com/users?ids=1,2,3"}`, batch.Input().String())
+	})
+
+	t.Run("renders the representations into the body", func(t *testing.T) {
+		factory := &BatchFactory{
+			config: BatchConfiguration{
+				URL:    "https://example.com/users",
+				Method: "POST",
+				Body:   `{"ids":{{ .representations.id }},"representations":{{ .representations }}}`,
+			},
+			keyFields: []string{"id"},
+		}
+		batch, err := factory.CreateBatch(inputs)
+		require.NoError(t, err)
+		assert.Equal(t, `{"body":{"ids":["1","2","3"],"representations":[{"id":"1"},{"id":"2"},{"id":"3"}]},"method":"POST","url":"https://example.com/users"}`, batch.Input().String())
+	})
+
+	t.Run("assigns the entities to the representations by key", func(t *testing.T) {
+		factory := &BatchFactory{
+			config:    BatchConfiguration{ResponsePath: []string{"users"}},
+			keyFields: []string{"id"},
+		}
+		batch, err := factory.CreateBatch(inputs)
+		require.NoError(t, err)
+
+		bufPairs := newBufPairs()
+		err = batch.Demultiplex(response(`{"users":[{"id":2,"name":"Two"},{"id":"1","name":"One"}]}`), bufPairs)
+		require.NoError(t, err)
+
+		results := make([]string, len(bufPairs))
+		for i := range bufPairs {
+			results[i] = bufPairs[i].Data.String()
+		}
+		assert.Equal(t, []string{`{"id":"1","name":"One"}`, `{"id":2,"name":"Two"}`, `{"id":"1","name":"One"}`, `null`, `null`}, results)
+	})
+
+	t.Run("fails for a response without entities", func(t *testing.T) {
+		factory := &BatchFactory{
+			config:    BatchConfiguration{ResponsePath: []string{"users"}},
+			keyFields: []string{"id"},
+		}
+		batch, err := factory.CreateBatch(inputs)
+		require.NoError(t, err)
+		assert.Error(t, batch.Demultiplex(response(`{"users":null}`), newBufPairs()))
+	})
+}
@@ -10,17 +10,29 @@ import (
 	"strings"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/concurrency"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/failover"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/hedging"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/replication"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
 	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
 )
 
 type Planner struct {
 	client              *http.Client
+	replicaRouter       *replication.Router
+	failoverBalancer    *failover.Balancer
+	concurrencyLimiters *concurrency.Limiters
 	v                   *plan.Visitor
 	config              Configuration
+	dataSourceConfig    plan.DataSourceConfiguration
 	rootField           int
 	operationDefinition int
+	enteredField        bool
+	// entityKeyFields are the key fields of the entity resolved by the fetch, see plan.DataSourceConfiguration.Entities
+	entityKeyFields []string
 }
 
 func (p *Planner) DownstreamResponseFieldAlias(_ int) (alias string, exists bool) {
@@ -41,11 +53,31 @@ func (p *Planner) EnterOperationDefinition(ref int) {
 
 type Factory struct {
 	Client *http.Client
+	// ReplicaRouter routes the fetches of upstreams with FetchConfiguration.Replication
+	// and keeps track of the stickiness of clients to the primaries, it's created if not set
+	ReplicaRouter *replication.Router
+	// FailoverBalancer chooses the endpoints of upstreams with FetchConfiguration.Failover
+	// and keeps track of the circuits of the endpoints, it's created if not set
+	FailoverBalancer *failover.Balancer
+	// ConcurrencyLimiters keeps track of the limits of upstreams with FetchConfiguration.Concurrency, it's created if not set
+	ConcurrencyLimiters *concurrency.Limiters
 }
 
 func (f *Factory) Planner(ctx context.Context) plan.DataSourcePlanner {
+	if f.ReplicaRouter == nil {
+		f.ReplicaRouter = replication.NewRouter()
+	}
+	if f.FailoverBalancer == nil {
+		f.FailoverBalancer = failover.NewBalancer()
+	}
+	if f.ConcurrencyLimiters == nil {
+		f.ConcurrencyLimiters = concurrency.NewLimiters()
+	}
 	return &Planner{
-		client: f.Client,
+		client:              f.Client,
+		replicaRouter:       f.ReplicaRouter,
+		failoverBalancer:    f.FailoverBalancer,
+		concurrencyLimiters: f.ConcurrencyLimiters,
 	}
 }
 
@@ -70,6 +102,26 @@ type FetchConfiguration struct {
 	Header http.Header
 	Query  []QueryConfiguration
 	Body   string
+	// Replication configures read replicas of the upstream, URL is the primary.
+	// Queries are sent to the replicas, mutations including all their fetches are sent to the primary.
+	Replication replication.Configuration
+	// Failover configures multiple endpoints of the upstream, of which scheme and host replace the ones of URL.
+	// Fetches fail over to the next endpoint on errors, 5xx responses and open circuits. It can't be combined with Replication.
+	// Mutations and all fetches of a mutation only fail over if dialing the endpoint failed, so that they aren't executed twice.
+	Failover failover.Configuration
+	// Hedging sends a second attempt of queries which didn't respond within a delay, the first success wins.
+	// Mutations and all fetches of a mutation aren't hedged.
+	Hedging hedging.Configuration
+	// Concurrency limits the concurrent requests to the upstream, the limit adapts to the latency and the errors of the upstream.
+	// Requests of failover endpoints are limited each, hedged attempts count as a single request.
+	Concurrency concurrency.Configuration
+	// Batch configures an endpoint resolving multiple entities with a single request, it's used instead of URL for the Entities of the DataSource.
+	// In the templates of entity fetches {{ .representation.<keyField> }} is an alias of {{ .object.<keyField> }},
+	// e.g. https://example.com/users/{{ .representation.id }}
+	Batch *BatchConfiguration
+	// AcceptEncodings are the encodings accepted for compressed responses, e.g. gzip and zstd, see httpclient.RegisterContentDecoder.
+	// If empty, the http client requests and decompresses gzip transparently.
+	AcceptEncodings []string
 }
 
 type QueryConfiguration struct {
@@ -79,20 +131,50 @@ type QueryConfiguration struct {
 
 func (p *Planner) Register(visitor *plan.Visitor, configuration plan.DataSourceConfiguration, isNested bool) error {
 	p.v = visitor
+	p.dataSourceConfig = configuration
 	visitor.Walker.RegisterEnterFieldVisitor(p)
 	visitor.Walker.RegisterEnterOperationVisitor(p)
-	return json.Unmarshal(configuration.Custom, &p.config)
+	if err := json.Unmarshal(configuration.Custom, &p.config); err != nil {
+		return err
+	}
+	p.config.Fetch.replaceRepresentationSelectors()
+	if p.config.Fetch.Failover.IsEnabled() && p.config.Fetch.Replication.IsEnabled() {
+		return failover.ErrCombinedWithReplication
+	}
+	return nil
 }
 
 func (p *Planner) EnterField(ref int) {
+	if !p.enteredField {
+		p.enteredField = true
+		p.entityKeyFields, _ = p.dataSourceConfig.EntityKeyFields(p.v.Walker.EnclosingTypeDefinition.NameString(p.v.Definition))
+	}
 	p.rootField = ref
 }
 
+// replaceRepresentationSelectors replaces the {{ .representation.<keyField> }} aliases with the object selector
+func (f *FetchConfiguration) replaceRepresentationSelectors() {
+	replace := func(s string) string {
+		return strings.ReplaceAll(s, representationSelector, objectSelector)
+	}
+	f.URL = replace(f.URL)
+	f.Body = replace(f.Body)
+	for i := range f.Query {
+		f.Query[i].Value = replace(f.Query[i].Value)
+	}
+	for name := range f.Header {
+		for i := range f.Header[name] {
+			f.Header[name][i] = replace(f.Header[name][i])
+		}
+	}
+}
+
 func (p *Planner) configureInput() []byte {
 
 	input := httpclient.SetInputURL(nil, []byte(p.config.Fetch.URL))
 	input = httpclient.SetInputMethod(input, []byte(p.config.Fetch.Method))
 	input = httpclient.SetInputBody(input, []byte(p.config.Fetch.Body))
+	input = httpclient.SetInputAcceptEncoding(input, p.config.Fetch.AcceptEncodings)
 
 	header, err := json.Marshal(p.config.Fetch.Header)
 	if err == nil && len(header) != 0 && !bytes.Equal(header, literal.NULL) {
@@ -108,12 +190,31 @@ func (p *Planner) configureInput() []byte {
 }
 
 func (p *Planner) ConfigureFetch() plan.FetchConfiguration {
+	source := &Source{
+		client:   p.client,
+		upstream: p.replicaRouter.Upstream(p.config.Fetch.Replication, p.isMutation()),
+		failover: p.failoverBalancer.Upstream(p.config.Fetch.Failover, !p.isMutation()),
+		hedging:  hedging.NewUpstream(p.config.Fetch.Hedging, !p.isMutation()),
+		limit:    p.concurrencyLimiters.Upstream(p.concurrencyConfiguration()),
+	}
+	if p.config.Fetch.Batch != nil && len(p.entityKeyFields) != 0 {
+		var variables resolve.Variables
+		return plan.FetchConfiguration{
+			Input:                representationInput(p.entityKeyFields, &variables),
+			Variables:            variables,
+			DataSource:           source,
+			DisallowSingleFlight: true,
+			BatchConfig: plan.BatchConfig{
+				AllowBatch:   true,
+				BatchFactory: &BatchFactory{config: *p.config.Fetch.Batch, keyFields: p.entityKeyFields, acceptEncodings: p.config.Fetch.AcceptEncodings},
+			},
+		}
+	}
+
 	input := p.configureInput()
 	return plan.FetchConfiguration{
-		Input: string(input),
-		DataSource: &Source{
-			client: p.client,
-		},
+		Input:                string(input),
+		DataSource:           source,
 		DisallowSingleFlight: p.config.Fetch.Method != "GET",
 		DisableDataLoader:    true,
 	}
@@ -127,6 +228,12 @@ var (
 	selectorRegex = regexp.MustCompile(`{{\s(.*?)\s}}`)
 )
 
+const (
+	// representationSelector is an alias of the object selector in the templates of entity fetches
+	representationSelector = "{{ .representation."
+	objectSelector         = "{{ .object."
+)
+
 func (p *Planner) prepareQueryParams(field int, query []QueryConfiguration) []QueryConfiguration {
 	out := make([]QueryConfiguration, 0, len(query))
 Next:
@@ -163,10 +270,38 @@ Next:
 	return out
 }
 
+// isMutation returns true if the operation is a mutation, so that all fetches are sent to the primary of replicated upstreams
+func (p *Planner) isMutation() bool {
+	return p.v.Operation.OperationDefinitions[p.operationDefinition].OperationType == ast.OperationTypeMutation
+}
+
+// concurrencyConfiguration returns the concurrency limit of the upstream, upstreams are identified by their URL unless they're named
+func (p *Planner) concurrencyConfiguration() concurrency.Configuration {
+	config := p.config.Fetch.Concurrency
+	if config.Name == "" {
+		config.Name = p.config.Fetch.URL
+	}
+	return config
+}
+
 type Source struct {
-	client *http.Client
+	client   *http.Client
+	upstream *replication.Upstream
+	failover *failover.Upstream
+	hedging  *hedging.Upstream
+	limit    *concurrency.Upstream
 }
 
 func (s *Source) Load(ctx context.Context, input []byte, w io.Writer) (err error) {
-	return httpclient.Do(s.client, ctx, input, w)
+	input, err = s.upstream.RouteInput(ctx, input)
+	if err != nil {
+		return err
+	}
+	return s.failover.Load(ctx, input, w, func(ctx context.Context, input []byte, out io.Writer) (int, error) {
+		return s.limit.Load(ctx, input, out, func(ctx context.Context, input []byte, out io.Writer) (int, error) {
+			return s.hedging.Load(ctx, input, out, func(ctx context.Context, input []byte, out io.Writer) (int, error) {
+				return httpclient.DoWithStatusCode(s.client, ctx, input, nil, out)
+			})
+		})
+	})
 }
@@ -247,6 +247,166 @@ func TestFastHttpJsonDataSourcePlanning(t *testing.T) {
 			DisableResolveFieldPositions: true,
 		},
 	))
+	t.Run("entity fields resolved with a single fetch templated with the key fields", datasourcetesting.RunTest(`
+		type Query {
+			reviews: [Review]
+		}
+
+		type Review {
+			body: String
+			author: User
+		}
+
+		type User {
+			id: ID!
+			name: String
+			email: String
+		}
+	`, `
+		query {
+			reviews {
+				body
+				author {
+					name
+					email
+				}
+			}
+		}
+	`, "",
+		&plan.SynchronousResponsePlan{
+			Response: &resolve.GraphQLResponse{
+				Data: &resolve.Object{
+					Fetch: &resolve.SingleFetch{
+						BufferId:             0,
+						Input:                `{"method":"GET","url":"https://example.com/reviews"}`,
+						DataSource:           &Source{},
+						DataSourceIdentifier: []byte("rest_datasource.Source"),
+						DisableDataLoader:    true,
+					},
+					Fields: []*resolve.Field{
+						{
+							BufferID:  0,
+							HasBuffer: true,
+							Name:      []byte("reviews"),
+							Value: &resolve.Array{
+								Nullable: true,
+								Item: &resolve.Object{
+									Nullable: true,
+									Fields: []*resolve.Field{
+										{
+											Name: []byte("body"),
+											Value: &resolve.String{
+												Path:     []string{"body"},
+												Nullable: true,
+											},
+										},
+										{
+											Name: []byte("author"),
+											Value: &resolve.Object{
+												Path:     []string{"author"},
+												Nullable: true,
+												Fetch: &resolve.SingleFetch{
+													BufferId:   1,
+													Input:      `{"method":"GET","url":"https://example.com/users/$$0$$"}`,
+													DataSource: &Source{},
+													Variables: resolve.NewVariables(
+														&resolve.ObjectVariable{
+															Path:     []string{"id"},
+															Renderer: resolve.NewPlainVariableRenderer(),
+														},
+													),
+													DataSourceIdentifier: []byte("rest_datasource.Source"),
+													DisableDataLoader:    true,
+												},
+												Fields: []*resolve.Field{
+													{
+														HasBuffer: true,
+														BufferID:  1,
+														Name:      []byte("name"),
+														Value: &resolve.String{
+															Path:     []string{"name"},
+															Nullable: true,
+														},
+													},
+													{
+														HasBuffer: true,
+														BufferID:  1,
+														Name:      []byte("email"),
+														Value: &resolve.String{
+															Path:     []string{"email"},
+															Nullable: true,
+														},
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		plan.Configuration{
+			DataSources: []plan.DataSourceConfiguration{
+				{
+					RootNodes: []plan.TypeField{
+						{
+							TypeName:   "Query",
+							FieldNames: []string{"reviews"},
+						},
+					},
+					ChildNodes: []plan.TypeField{
+						{
+							TypeName:   "Review",
+							FieldNames: []string{"body", "author"},
+						},
+						{
+							TypeName:   "User",
+							FieldNames: []string{"id"},
+						},
+					},
+					Custom: ConfigJSON(Configuration{
+						Fetch: FetchConfiguration{
+							URL:    "https://example.com/reviews",
+							Method: "GET",
+						},
+					}),
+					Factory: &Factory{},
+				},
+				{
+					RootNodes: []plan.TypeField{
+						{
+							TypeName:   "User",
+							FieldNames: []string{"name", "email"},
+						},
+					},
+					Entities: []plan.EntityConfiguration{
+						{
+							TypeName:  "User",
+							KeyFields: []string{"id"},
+						},
+					},
+					Custom: ConfigJSON(Configuration{
+						Fetch: FetchConfiguration{
+							URL:    "https://example.com/users/{{ .object.id }}",
+							Method: "GET",
+						},
+					}),
+					Factory: &Factory{},
+				},
+			},
+			Fields: []plan.FieldConfiguration{
+				{
+					TypeName:              "Query",
+					FieldName:             "reviews",
+					DisableDefaultMapping: true,
+				},
+			},
+			DisableResolveFieldPositions: true,
+		},
+	))
 	t.Run("get request with argument", datasourcetesting.RunTest(schema, argumentOperation, "ArgumentQuery",
 		&plan.SynchronousResponsePlan{
 			Response: &resolve.GraphQLResponse{
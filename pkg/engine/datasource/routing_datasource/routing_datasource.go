@@ -0,0 +1,326 @@
+// Package routing_datasource routes a field to one of multiple datasources by the value of one of its arguments,
+// e.g. the field with the argument region: EU to the upstream in the EU and region: US to the upstream in the US.
+//
+// All sources are planned for the same fields, the route is selected per request when the fetch is loaded,
+// so that the plan of the operation is cached independently of the value of the argument.
+// The responses of all sources are processed like the response of the first source, so the sources should be of the same kind.
+// The inputs of the sources have to be JSON, like the inputs of the graphql and rest datasources.
+package routing_datasource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+)
+
+var (
+	ErrNoRoutes   = errors.New("routing datasource has no routes")
+	ErrNoArgument = errors.New("routing datasource has no argument")
+	ErrNoRoute    = errors.New("no route matches the value of the argument")
+)
+
+var variableRegex = regexp.MustCompile(`\$\$(\d+)\$\$`)
+
+// Configuration configures the argument the route of a fetch is selected by
+type Configuration struct {
+	// Argument is the dot delimited path of the argument of the field, e.g. "region",
+	// or "filter.region" for a field of an input object argument.
+	Argument string `json:"argument"`
+}
+
+func ConfigJSON(config Configuration) json.RawMessage {
+	out, _ := json.Marshal(config)
+	return out
+}
+
+// SourceConfiguration is one of the datasources of the routes, Custom is its custom configuration,
+// e.g. graphql_datasource.ConfigJson(...)
+type SourceConfiguration struct {
+	Factory plan.PlannerFactory
+	Custom  json.RawMessage
+}
+
+// Route routes the values of the argument to the source. Values are compared with the argument rendered as plain value,
+// i.e. strings and enum values without quotes, e.g. EU, numbers and booleans in their JSON representation, e.g. 1 or true.
+type Route struct {
+	Values []string
+	Source SourceConfiguration
+}
+
+type Factory struct {
+	Routes []Route
+	// Default is the source of the values no route matches, including omitted and null arguments.
+	// Without default, the fetches of these values fail with ErrNoRoute.
+	Default *SourceConfiguration
+}
+
+func (f *Factory) sources() []SourceConfiguration {
+	sources := make([]SourceConfiguration, 0, len(f.Routes)+1)
+	for i := range f.Routes {
+		sources = append(sources, f.Routes[i].Source)
+	}
+	if f.Default != nil {
+		sources = append(sources, *f.Default)
+	}
+	return sources
+}
+
+func (f *Factory) Planner(ctx context.Context) plan.DataSourcePlanner {
+	sources := f.sources()
+	planners := make([]plan.DataSourcePlanner, len(sources))
+	for i := range sources {
+		planners[i] = sources[i].Factory.Planner(ctx)
+	}
+	routes := make(map[string]int)
+	for i := len(f.Routes) - 1; i >= 0; i-- {
+		for _, value := range f.Routes[i].Values {
+			// the first route of a value wins
+			routes[value] = i
+		}
+	}
+	defaultRoute := -1
+	if f.Default != nil {
+		defaultRoute = len(f.Routes)
+	}
+	return &Planner{
+		sources:      sources,
+		planners:     planners,
+		routes:       routes,
+		defaultRoute: defaultRoute,
+	}
+}
+
+type Planner struct {
+	v            *plan.Visitor
+	sources      []SourceConfiguration
+	planners     []plan.DataSourcePlanner
+	routes       map[string]int
+	defaultRoute int
+	config       Configuration
+	rootField    int
+}
+
+func (p *Planner) DelegatePlanners() []plan.DataSourcePlanner {
+	return p.planners
+}
+
+// DownstreamResponseFieldAlias is delegated to the first source, as all sources plan the same fields
+func (p *Planner) DownstreamResponseFieldAlias(downstreamFieldRef int) (alias string, exists bool) {
+	if len(p.planners) == 0 {
+		return
+	}
+	return p.planners[0].DownstreamResponseFieldAlias(downstreamFieldRef)
+}
+
+func (p *Planner) DataSourcePlanningBehavior() plan.DataSourcePlanningBehavior {
+	if len(p.planners) == 0 {
+		return plan.DataSourcePlanningBehavior{}
+	}
+	return p.planners[0].DataSourcePlanningBehavior()
+}
+
+func (p *Planner) Register(visitor *plan.Visitor, configuration plan.DataSourceConfiguration, isNested bool) error {
+	if len(p.planners) == 0 {
+		return ErrNoRoutes
+	}
+	if len(configuration.Custom) != 0 {
+		if err := json.Unmarshal(configuration.Custom, &p.config); err != nil {
+			return err
+		}
+	}
+	if p.config.Argument == "" {
+		return ErrNoArgument
+	}
+	p.v = visitor
+	p.rootField = -1
+	visitor.Walker.RegisterEnterFieldVisitor(p)
+	for i := range p.planners {
+		sourceConfiguration := configuration
+		sourceConfiguration.Factory = p.sources[i].Factory
+		sourceConfiguration.Custom = p.sources[i].Custom
+		if err := p.planners[i].Register(visitor, sourceConfiguration, isNested); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *Planner) EnterField(ref int) {
+	if p.rootField == -1 {
+		p.rootField = ref
+	}
+}
+
+// ConfigureFetch combines the fetches of the sources into one fetch with the value of the argument and the inputs of the sources.
+// Batching of the sources isn't supported.
+func (p *Planner) ConfigureFetch() plan.FetchConfiguration {
+	source := &Source{
+		sources:      make([]resolve.DataSource, len(p.planners)),
+		routes:       p.routes,
+		defaultRoute: p.defaultRoute,
+	}
+	config := plan.FetchConfiguration{
+		DataSource:        source,
+		DisableDataLoader: true,
+	}
+
+	inputs := make([]string, len(p.planners))
+	for i := range p.planners {
+		fetch := p.planners[i].ConfigureFetch()
+		source.sources[i] = fetch.DataSource
+		if i == 0 {
+			config.ProcessResponseConfig = fetch.ProcessResponseConfig
+		}
+		config.DisallowSingleFlight = config.DisallowSingleFlight || fetch.DisallowSingleFlight
+		inputs[i] = addVariables(fetch.Input, fetch.Variables, &config.Variables)
+	}
+	config.Input = `{"route":` + p.route(&config.Variables) + `,"inputs":[` + strings.Join(inputs, ",") + `]}`
+	return config
+}
+
+// route returns the value of the argument of the root field as JSON value,
+// arguments passed as variables are added to the variables of the fetch and rendered when the fetch is loaded.
+// Omitted arguments are rendered as null.
+func (p *Planner) route(variables *resolve.Variables) string {
+	if p.rootField == -1 {
+		return "null"
+	}
+	operation := p.v.Operation
+	path := strings.Split(p.config.Argument, ".")
+	arg, exists := operation.FieldArgument(p.rootField, []byte(path[0]))
+	if !exists {
+		return "null"
+	}
+	value := operation.ArgumentValue(arg)
+	for i := 1; ; i++ {
+		if value.Kind == ast.ValueKindVariable {
+			variablePath := append([]string{operation.VariableValueNameString(value.Ref)}, path[i:]...)
+			name, _ := variables.AddVariable(&resolve.ContextVariable{
+				Path:     variablePath,
+				Renderer: resolve.NewJSONVariableRenderer(),
+			})
+			return name
+		}
+		if i == len(path) {
+			break
+		}
+		if value.Kind != ast.ValueKindObject {
+			return "null"
+		}
+		found := false
+		for _, ref := range operation.ObjectValues[value.Ref].Refs {
+			if operation.ObjectFieldNameString(ref) == path[i] {
+				value, found = operation.ObjectFieldValue(ref), true
+				break
+			}
+		}
+		if !found {
+			return "null"
+		}
+	}
+	switch value.Kind {
+	case ast.ValueKindEnum, ast.ValueKindString:
+		content, _ := json.Marshal(operation.ValueContentString(value))
+		return string(content)
+	case ast.ValueKindInteger, ast.ValueKindFloat:
+		return operation.ValueContentString(value)
+	case ast.ValueKindBoolean:
+		return strconv.FormatBool(bool(operation.BooleanValue(value.Ref)))
+	default:
+		return "null"
+	}
+}
+
+// addVariables adds the variables of a source to the variables of the routes and renames them in the input of the source
+func addVariables(input string, sourceVariables resolve.Variables, variables *resolve.Variables) string {
+	if len(sourceVariables) == 0 {
+		return input
+	}
+	names := make([]string, len(sourceVariables))
+	for i := range sourceVariables {
+		names[i], _ = variables.AddVariable(sourceVariables[i])
+	}
+	return variableRegex.ReplaceAllStringFunc(input, func(variable string) string {
+		i, err := strconv.Atoi(variableRegex.FindStringSubmatch(variable)[1])
+		if err != nil || i >= len(names) {
+			return variable
+		}
+		return names[i]
+	})
+}
+
+// ConfigureSubscription returns an empty configuration, subscriptions aren't routed
+func (p *Planner) ConfigureSubscription() plan.SubscriptionConfiguration {
+	return plan.SubscriptionConfiguration{}
+}
+
+// Source loads the source of the route matching the value of the argument
+type Source struct {
+	sources      []resolve.DataSource
+	routes       map[string]int
+	defaultRoute int
+}
+
+func (s *Source) Load(ctx context.Context, input []byte, w io.Writer) (err error) {
+	route, err := routeValue(input)
+	if err != nil {
+		return err
+	}
+	i, ok := s.routes[route]
+	if !ok {
+		if s.defaultRoute == -1 {
+			return fmt.Errorf("%w: %q", ErrNoRoute, route)
+		}
+		i = s.defaultRoute
+	}
+
+	var inputs [][]byte
+	_, err = jsonparser.ArrayEach(input, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		inputs = append(inputs, rawValue(value, dataType))
+	}, "inputs")
+	if err != nil {
+		return err
+	}
+	if len(inputs) != len(s.sources) {
+		return fmt.Errorf("routing datasource expects %d inputs, got %d", len(s.sources), len(inputs))
+	}
+	return s.sources[i].Load(ctx, inputs[i], w)
+}
+
+// routeValue returns the value of the argument as plain value, strings without quotes and null as empty value
+func routeValue(input []byte) (string, error) {
+	value, dataType, _, err := jsonparser.Get(input, "route")
+	switch {
+	case err == jsonparser.KeyPathNotFoundError || dataType == jsonparser.Null:
+		return "", nil
+	case err != nil:
+		return "", err
+	case dataType == jsonparser.String:
+		return jsonparser.ParseString(value)
+	default:
+		return string(value), nil
+	}
+}
+
+func rawValue(value []byte, dataType jsonparser.ValueType) []byte {
+	switch dataType {
+	case jsonparser.String:
+		return append(append([]byte{'"'}, value...), '"')
+	case jsonparser.Null:
+		return literal.NULL
+	default:
+		return value
+	}
+}
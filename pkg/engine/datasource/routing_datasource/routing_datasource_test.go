@@ -0,0 +1,83 @@
+package routing_datasource
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+)
+
+type fakeSource struct {
+	response string
+	input    []byte
+}
+
+func (f *fakeSource) Load(ctx context.Context, input []byte, w io.Writer) error {
+	f.input = append([]byte(nil), input...)
+	_, err := io.WriteString(w, f.response)
+	return err
+}
+
+func TestSource_Load(t *testing.T) {
+	newSource := func(defaultRoute int) (*Source, []*fakeSource) {
+		sources := []*fakeSource{{response: `{"data":"eu"}`}, {response: `{"data":"us"}`}, {response: `{"data":"global"}`}}
+		return &Source{
+			sources:      []resolve.DataSource{sources[0], sources[1], sources[2]},
+			routes:       map[string]int{"EU": 0, "US": 1, "CA": 1, "49": 0},
+			defaultRoute: defaultRoute,
+		}, sources
+	}
+
+	load := func(source *Source, input string) (string, error) {
+		buf := &bytes.Buffer{}
+		err := source.Load(context.Background(), []byte(input), buf)
+		return buf.String(), err
+	}
+
+	t.Run("loads the source of the route", func(t *testing.T) {
+		source, sources := newSource(2)
+		out, err := load(source, `{"route":"CA","inputs":[{"url":"eu"},{"url":"us"},{"url":"global"}]}`)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":"us"}`, out)
+		assert.Equal(t, `{"url":"us"}`, string(sources[1].input))
+		assert.Nil(t, sources[0].input)
+		assert.Nil(t, sources[2].input)
+	})
+
+	t.Run("loads the source of the route of a number", func(t *testing.T) {
+		source, _ := newSource(2)
+		out, err := load(source, `{"route":49,"inputs":[{"url":"eu"},{"url":"us"},{"url":"global"}]}`)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":"eu"}`, out)
+	})
+
+	t.Run("loads the default source for values without route", func(t *testing.T) {
+		source, sources := newSource(2)
+		out, err := load(source, `{"route":"APAC","inputs":[{"url":"eu"},{"url":"us"},"global"]}`)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":"global"}`, out)
+		assert.Equal(t, `"global"`, string(sources[2].input))
+
+		out, err = load(source, `{"route":null,"inputs":[{"url":"eu"},{"url":"us"},"global"]}`)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":"global"}`, out)
+	})
+
+	t.Run("fails for values without route and default", func(t *testing.T) {
+		source, _ := newSource(-1)
+		_, err := load(source, `{"route":"APAC","inputs":[{"url":"eu"},{"url":"us"},{"url":"global"}]}`)
+		assert.ErrorIs(t, err, ErrNoRoute)
+		assert.EqualError(t, err, `no route matches the value of the argument: "APAC"`)
+	})
+
+	t.Run("fails for missing inputs", func(t *testing.T) {
+		source, _ := newSource(2)
+		_, err := load(source, `{"route":"EU","inputs":[{"url":"eu"}]}`)
+		assert.EqualError(t, err, "routing datasource expects 3 inputs, got 1")
+	})
+}
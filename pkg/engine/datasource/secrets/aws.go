@@ -0,0 +1,37 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/buger/jsonparser"
+)
+
+// SecretsManagerClient returns the SecretString of a secret of AWS Secrets Manager.
+// It's implemented by a small adapter around the GetSecretValue operation of the AWS SDK,
+// which keeps the SDK and its credential chain out of the dependencies of this module.
+type SecretsManagerClient interface {
+	GetSecretValue(ctx context.Context, secretID string) (secretString string, err error)
+}
+
+// AWSSecretsManagerProvider reads secrets from AWS Secrets Manager.
+// Secrets are referenced as "secret-id" or "secret-id#key", where key selects a value of a JSON secret.
+type AWSSecretsManagerProvider struct {
+	Client SecretsManagerClient
+}
+
+func (a *AWSSecretsManagerProvider) Secret(ctx context.Context, name string) (string, error) {
+	secretID, key := splitKey(name)
+	secretString, err := a.Client.GetSecretValue(ctx, secretID)
+	if err != nil {
+		return "", err
+	}
+	if key == "" {
+		return secretString, nil
+	}
+	value, err := jsonparser.GetString([]byte(secretString), key)
+	if err != nil {
+		return "", fmt.Errorf("%w: key %s of aws secret %s", ErrSecretNotFound, key, secretID)
+	}
+	return value, nil
+}
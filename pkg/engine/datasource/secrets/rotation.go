@@ -0,0 +1,65 @@
+package secrets
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type cachedSecret struct {
+	value     string
+	fetchedAt time.Time
+}
+
+// RotatingProvider caches the secrets of a provider and looks them up again once RefreshInterval has passed,
+// so that rotated secrets are picked up without restarting the engine and without a lookup per request.
+// If a refresh fails, the previous value is used until the secret can be looked up again.
+type RotatingProvider struct {
+	provider        SecretProvider
+	refreshInterval time.Duration
+
+	mu      sync.Mutex
+	secrets map[string]cachedSecret
+	now     func() time.Time
+}
+
+func NewRotatingProvider(provider SecretProvider, refreshInterval time.Duration) *RotatingProvider {
+	return &RotatingProvider{
+		provider:        provider,
+		refreshInterval: refreshInterval,
+		secrets:         map[string]cachedSecret{},
+		now:             time.Now,
+	}
+}
+
+func (r *RotatingProvider) Secret(ctx context.Context, name string) (string, error) {
+	r.mu.Lock()
+	cached, ok := r.secrets[name]
+	r.mu.Unlock()
+
+	now := r.now()
+	if ok && now.Sub(cached.fetchedAt) < r.refreshInterval {
+		return cached.value, nil
+	}
+
+	value, err := r.provider.Secret(ctx, name)
+	if err != nil {
+		if ok {
+			return cached.value, nil
+		}
+		return "", err
+	}
+
+	r.mu.Lock()
+	r.secrets[name] = cachedSecret{value: value, fetchedAt: now}
+	r.mu.Unlock()
+	return value, nil
+}
+
+// Invalidate forces the next lookup of the secret to use the provider,
+// e.g. after the upstream rejected the credential because it was rotated
+func (r *RotatingProvider) Invalidate(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.secrets, name)
+}
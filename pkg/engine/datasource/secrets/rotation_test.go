@@ -0,0 +1,60 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingProvider(t *testing.T) {
+	var (
+		value   = "first"
+		err     error
+		lookups int
+	)
+	provider := NewRotatingProvider(SecretProviderFunc(func(_ context.Context, _ string) (string, error) {
+		lookups++
+		return value, err
+	}), time.Minute)
+
+	now := time.Now()
+	provider.now = func() time.Time {
+		return now
+	}
+
+	secret := func(t *testing.T) string {
+		t.Helper()
+		secret, err := provider.Secret(context.Background(), "api_key")
+		require.NoError(t, err)
+		return secret
+	}
+
+	assert.Equal(t, "first", secret(t))
+	assert.Equal(t, 1, lookups)
+
+	value = "rotated"
+	assert.Equal(t, "first", secret(t), "cached value is used within the refresh interval")
+	assert.Equal(t, 1, lookups)
+
+	now = now.Add(time.Minute)
+	assert.Equal(t, "rotated", secret(t), "rotated value is used after the refresh interval")
+	assert.Equal(t, 2, lookups)
+
+	now = now.Add(time.Minute)
+	err = errors.New("provider unavailable")
+	assert.Equal(t, "rotated", secret(t), "previous value is used if the refresh fails")
+
+	err = nil
+	value = "invalidated"
+	provider.Invalidate("api_key")
+	assert.Equal(t, "invalidated", secret(t))
+
+	_, lookupErr := NewRotatingProvider(SecretProviderFunc(func(_ context.Context, _ string) (string, error) {
+		return "", ErrSecretNotFound
+	}), time.Minute).Secret(context.Background(), "api_key")
+	assert.ErrorIs(t, lookupErr, ErrSecretNotFound)
+}
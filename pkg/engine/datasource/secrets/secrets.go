@@ -0,0 +1,89 @@
+// Package secrets provides credentials of datasources, e.g. API keys of upstreams,
+// so that they don't have to be stored in the (serialized) engine configuration.
+// The configuration only references secrets by name, the values are looked up by a SecretProvider when they are used.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider looks up the current value of a secret by its name.
+// Implementations must be safe for concurrent use.
+type SecretProvider interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// SecretProviderFunc allows using a function as SecretProvider
+type SecretProviderFunc func(ctx context.Context, name string) (string, error)
+
+func (f SecretProviderFunc) Secret(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+// EnvProvider reads secrets from environment variables named Prefix + name
+type EnvProvider struct {
+	Prefix string
+}
+
+func (e EnvProvider) Secret(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(e.Prefix + name)
+	if !ok {
+		return "", fmt.Errorf("%w: environment variable %s is not set", ErrSecretNotFound, e.Prefix+name)
+	}
+	return value, nil
+}
+
+// FileProvider reads secrets from files named like the secret within Dir, e.g. secrets mounted by Kubernetes.
+// Files are read on each lookup, so rotated secrets are picked up immediately.
+// A trailing newline is removed from the value.
+type FileProvider struct {
+	Dir string
+}
+
+func (f FileProvider) Secret(_ context.Context, name string) (string, error) {
+	if name == "" || strings.Contains(name, "..") || filepath.IsAbs(name) {
+		return "", fmt.Errorf("invalid secret name: %s", name)
+	}
+	content, err := os.ReadFile(filepath.Join(f.Dir, name))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%w: file %s does not exist", ErrSecretNotFound, name)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+// Mux dispatches lookups of secrets referenced as "scheme:name" to the provider registered for the scheme,
+// e.g. "env:API_KEY" or "vault:upstreams/products#api_key".
+// References without a scheme are looked up by the Default provider if set.
+type Mux struct {
+	Providers map[string]SecretProvider
+	Default   SecretProvider
+}
+
+func (m *Mux) Secret(ctx context.Context, reference string) (string, error) {
+	scheme, name, hasScheme := strings.Cut(reference, ":")
+	if hasScheme {
+		if provider, ok := m.Providers[scheme]; ok {
+			return provider.Secret(ctx, name)
+		}
+	}
+	if m.Default == nil {
+		return "", fmt.Errorf("no secret provider for reference %s", reference)
+	}
+	return m.Default.Secret(ctx, reference)
+}
+
+// splitKey splits a secret name of the format "name#key" into the name and the key within the secret
+func splitKey(name string) (secretName, key string) {
+	secretName, key, _ = strings.Cut(name, "#")
+	return secretName, key
+}
@@ -0,0 +1,110 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvProvider(t *testing.T) {
+	t.Setenv("UPSTREAM_API_KEY", "secret")
+	provider := EnvProvider{Prefix: "UPSTREAM_"}
+
+	value, err := provider.Secret(context.Background(), "API_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", value)
+
+	_, err = provider.Secret(context.Background(), "NOT_EXISTING")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "api_key"), []byte("secret\n"), 0600))
+	provider := FileProvider{Dir: dir}
+
+	t.Run("reads the secret without trailing newline", func(t *testing.T) {
+		value, err := provider.Secret(context.Background(), "api_key")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", value)
+	})
+
+	t.Run("picks up the rotated secret", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "api_key"), []byte("rotated"), 0600))
+		value, err := provider.Secret(context.Background(), "api_key")
+		require.NoError(t, err)
+		assert.Equal(t, "rotated", value)
+	})
+
+	t.Run("not existing secret", func(t *testing.T) {
+		_, err := provider.Secret(context.Background(), "not_existing")
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+	})
+
+	t.Run("rejects names outside of the directory", func(t *testing.T) {
+		_, err := provider.Secret(context.Background(), "../api_key")
+		assert.Error(t, err)
+	})
+}
+
+type fakeSecretsManagerClient map[string]string
+
+func (f fakeSecretsManagerClient) GetSecretValue(_ context.Context, secretID string) (string, error) {
+	secretString, ok := f[secretID]
+	if !ok {
+		return "", errors.New("ResourceNotFoundException")
+	}
+	return secretString, nil
+}
+
+func TestAWSSecretsManagerProvider(t *testing.T) {
+	provider := &AWSSecretsManagerProvider{
+		Client: fakeSecretsManagerClient{
+			"products/api-key":     "secret",
+			"products/credentials": `{"username":"products","password":"secret"}`,
+		},
+	}
+
+	value, err := provider.Secret(context.Background(), "products/api-key")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", value)
+
+	value, err = provider.Secret(context.Background(), "products/credentials#password")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", value)
+
+	_, err = provider.Secret(context.Background(), "products/credentials#token")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+
+	_, err = provider.Secret(context.Background(), "not-existing")
+	assert.Error(t, err)
+}
+
+func TestMux(t *testing.T) {
+	mux := &Mux{
+		Providers: map[string]SecretProvider{
+			"static": SecretProviderFunc(func(_ context.Context, name string) (string, error) {
+				return "static " + name, nil
+			}),
+		},
+	}
+
+	value, err := mux.Secret(context.Background(), "static:api_key")
+	require.NoError(t, err)
+	assert.Equal(t, "static api_key", value)
+
+	_, err = mux.Secret(context.Background(), "api_key")
+	assert.EqualError(t, err, "no secret provider for reference api_key")
+
+	mux.Default = SecretProviderFunc(func(_ context.Context, name string) (string, error) {
+		return "default " + name, nil
+	})
+	value, err = mux.Secret(context.Background(), "api_key")
+	require.NoError(t, err)
+	assert.Equal(t, "default api_key", value)
+}
@@ -0,0 +1,94 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/buger/jsonparser"
+)
+
+const (
+	defaultVaultMountPath = "secret"
+	defaultSecretKey      = "value"
+	vaultTokenHeader      = "X-Vault-Token"
+)
+
+// VaultProvider reads secrets from the KV version 2 secrets engine of HashiCorp Vault.
+// Secrets are referenced as "path#key", e.g. "upstreams/products#api_key".
+// Without a key, the key "value" is used.
+type VaultProvider struct {
+	// Address is the address of the Vault server, e.g. https://vault.example.com:8200
+	Address string
+	// Token authenticates the requests to Vault.
+	// TokenProvider takes precedence, e.g. to read a token which is rotated by a Vault agent from a file.
+	Token         string
+	TokenProvider SecretProvider
+	TokenName     string
+	// MountPath is the path the KV secrets engine is mounted at, defaults to "secret"
+	MountPath  string
+	Namespace  string
+	HTTPClient *http.Client
+}
+
+func (v *VaultProvider) Secret(ctx context.Context, name string) (string, error) {
+	path, key := splitKey(name)
+	if key == "" {
+		key = defaultSecretKey
+	}
+	mountPath := v.MountPath
+	if mountPath == "" {
+		mountPath = defaultVaultMountPath
+	}
+
+	token, err := v.token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/data/%s", strings.TrimRight(v.Address, "/"), strings.Trim(mountPath, "/"), strings.TrimLeft(path, "/"))
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set(vaultTokenHeader, token)
+	if v.Namespace != "" {
+		request.Header.Set("X-Vault-Namespace", v.Namespace)
+	}
+
+	client := v.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return "", fmt.Errorf("%w: vault secret %s", ErrSecretNotFound, path)
+	case response.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("vault secret %s: unexpected status code %d", path, response.StatusCode)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return "", err
+	}
+	value, err := jsonparser.GetString(body, "data", "data", key)
+	if err != nil {
+		return "", fmt.Errorf("%w: key %s of vault secret %s", ErrSecretNotFound, key, path)
+	}
+	return value, nil
+}
+
+func (v *VaultProvider) token(ctx context.Context) (string, error) {
+	if v.TokenProvider == nil {
+		return v.Token, nil
+	}
+	return v.TokenProvider.Secret(ctx, v.TokenName)
+}
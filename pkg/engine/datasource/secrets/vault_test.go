@@ -0,0 +1,69 @@
+package secrets
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultProvider(t *testing.T) {
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(vaultTokenHeader) != "vault-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/kv/data/upstreams/products":
+			_, _ = w.Write([]byte(`{"data":{"data":{"value":"secret","api_key":"key"},"metadata":{"version":2}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vault.Close()
+
+	provider := &VaultProvider{
+		Address:   vault.URL,
+		Token:     "vault-token",
+		MountPath: "kv",
+	}
+
+	t.Run("default key", func(t *testing.T) {
+		value, err := provider.Secret(context.Background(), "upstreams/products")
+		require.NoError(t, err)
+		assert.Equal(t, "secret", value)
+	})
+
+	t.Run("key", func(t *testing.T) {
+		value, err := provider.Secret(context.Background(), "upstreams/products#api_key")
+		require.NoError(t, err)
+		assert.Equal(t, "key", value)
+	})
+
+	t.Run("not existing key", func(t *testing.T) {
+		_, err := provider.Secret(context.Background(), "upstreams/products#password")
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+	})
+
+	t.Run("not existing secret", func(t *testing.T) {
+		_, err := provider.Secret(context.Background(), "upstreams/reviews")
+		assert.ErrorIs(t, err, ErrSecretNotFound)
+	})
+
+	t.Run("token from provider", func(t *testing.T) {
+		tokenProvider := &VaultProvider{
+			Address:   vault.URL,
+			MountPath: "kv",
+			TokenProvider: SecretProviderFunc(func(_ context.Context, name string) (string, error) {
+				assert.Equal(t, "token", name)
+				return "invalid-token", nil
+			}),
+			TokenName: "token",
+		}
+		_, err := tokenProvider.Secret(context.Background(), "upstreams/products")
+		assert.EqualError(t, err, "vault secret upstreams/products: unexpected status code 403")
+	})
+}
@@ -0,0 +1,442 @@
+// Package union_datasource resolves a field by fanning out to multiple datasources and merging their results,
+// e.g. to combine the search results of several backends into one list.
+//
+// All sources are planned for the same fields, so their responses should have the same shape.
+// Lists of the responses are concatenated in the order of the sources. If the responses are objects,
+// the lists of the same keys are concatenated and other values are taken from the first source returning them.
+// The inputs of the sources have to be JSON, like the inputs of the graphql and rest datasources.
+package union_datasource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+)
+
+var (
+	ErrNoSources      = errors.New("union datasource has no sources")
+	ErrAllSourcesFail = errors.New("all sources of the union failed")
+)
+
+var variableRegex = regexp.MustCompile(`\$\$(\d+)\$\$`)
+
+// Configuration configures how the lists of the sources are merged
+type Configuration struct {
+	// DedupKey is the dot delimited path of the key of list items, e.g. "id" or "product.upc".
+	// Items with the key of a previous item are removed, so the first source wins. Items without the key are kept.
+	// The field of the key has to be selected by the operation.
+	DedupKey string `json:"dedup_key,omitempty"`
+	// SortBy is the dot delimited path of the field the merged lists are sorted by, numbers are sorted numerically,
+	// other values lexically and items without the field last. The field has to be selected by the operation.
+	SortBy string `json:"sort_by,omitempty"`
+	// SortDescending sorts the merged lists in descending order
+	SortDescending bool `json:"sort_descending,omitempty"`
+}
+
+func ConfigJSON(config Configuration) json.RawMessage {
+	out, _ := json.Marshal(config)
+	return out
+}
+
+// SourceConfiguration is one of the datasources of the union, Custom is its custom configuration,
+// e.g. graphql_datasource.ConfigJson(...)
+type SourceConfiguration struct {
+	Factory plan.PlannerFactory
+	Custom  json.RawMessage
+}
+
+type Factory struct {
+	Sources []SourceConfiguration
+}
+
+func (f *Factory) Planner(ctx context.Context) plan.DataSourcePlanner {
+	planners := make([]plan.DataSourcePlanner, len(f.Sources))
+	for i := range f.Sources {
+		planners[i] = f.Sources[i].Factory.Planner(ctx)
+	}
+	return &Planner{
+		sources:  f.Sources,
+		planners: planners,
+	}
+}
+
+type Planner struct {
+	sources  []SourceConfiguration
+	planners []plan.DataSourcePlanner
+	config   Configuration
+}
+
+func (p *Planner) DelegatePlanners() []plan.DataSourcePlanner {
+	return p.planners
+}
+
+// DownstreamResponseFieldAlias is delegated to the first source, as all sources plan the same fields
+func (p *Planner) DownstreamResponseFieldAlias(downstreamFieldRef int) (alias string, exists bool) {
+	if len(p.planners) == 0 {
+		return
+	}
+	return p.planners[0].DownstreamResponseFieldAlias(downstreamFieldRef)
+}
+
+func (p *Planner) DataSourcePlanningBehavior() plan.DataSourcePlanningBehavior {
+	if len(p.planners) == 0 {
+		return plan.DataSourcePlanningBehavior{}
+	}
+	return p.planners[0].DataSourcePlanningBehavior()
+}
+
+func (p *Planner) Register(visitor *plan.Visitor, configuration plan.DataSourceConfiguration, isNested bool) error {
+	if len(p.planners) == 0 {
+		return ErrNoSources
+	}
+	if len(configuration.Custom) != 0 {
+		if err := json.Unmarshal(configuration.Custom, &p.config); err != nil {
+			return err
+		}
+	}
+	for i := range p.planners {
+		sourceConfiguration := configuration
+		sourceConfiguration.Factory = p.sources[i].Factory
+		sourceConfiguration.Custom = p.sources[i].Custom
+		if err := p.planners[i].Register(visitor, sourceConfiguration, isNested); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConfigureFetch combines the fetches of the sources into one fetch with a JSON array of their inputs.
+// Batching of the sources isn't supported, each fetch of the union loads all sources.
+func (p *Planner) ConfigureFetch() plan.FetchConfiguration {
+	source := &Source{
+		config:          p.config,
+		sources:         make([]resolve.DataSource, len(p.planners)),
+		responseConfigs: make([]resolve.ProcessResponseConfig, len(p.planners)),
+	}
+	config := plan.FetchConfiguration{
+		DataSource:        source,
+		DisableDataLoader: true,
+		ProcessResponseConfig: resolve.ProcessResponseConfig{
+			ExtractGraphqlResponse: true,
+		},
+	}
+
+	inputs := make([]string, len(p.planners))
+	for i := range p.planners {
+		fetch := p.planners[i].ConfigureFetch()
+		source.sources[i] = fetch.DataSource
+		source.responseConfigs[i] = fetch.ProcessResponseConfig
+		config.DisallowSingleFlight = config.DisallowSingleFlight || fetch.DisallowSingleFlight
+		inputs[i] = addVariables(fetch.Input, fetch.Variables, &config.Variables)
+	}
+	config.Input = "[" + strings.Join(inputs, ",") + "]"
+	return config
+}
+
+// addVariables adds the variables of a source to the variables of the union and renames them in the input of the source
+func addVariables(input string, sourceVariables resolve.Variables, variables *resolve.Variables) string {
+	if len(sourceVariables) == 0 {
+		return input
+	}
+	names := make([]string, len(sourceVariables))
+	for i := range sourceVariables {
+		names[i], _ = variables.AddVariable(sourceVariables[i])
+	}
+	return variableRegex.ReplaceAllStringFunc(input, func(variable string) string {
+		i, err := strconv.Atoi(variableRegex.FindStringSubmatch(variable)[1])
+		if err != nil || i >= len(names) {
+			return variable
+		}
+		return names[i]
+	})
+}
+
+// ConfigureSubscription returns an empty configuration, subscriptions can't be merged from multiple sources
+func (p *Planner) ConfigureSubscription() plan.SubscriptionConfiguration {
+	return plan.SubscriptionConfiguration{}
+}
+
+// Source loads all sources concurrently and merges their responses into one GraphQL response.
+// Errors of the sources are added to the errors of the response, the fetch only fails if all sources fail.
+type Source struct {
+	config          Configuration
+	sources         []resolve.DataSource
+	responseConfigs []resolve.ProcessResponseConfig
+}
+
+type sourceResult struct {
+	data   []byte
+	errors [][]byte
+	err    error
+}
+
+func (s *Source) Load(ctx context.Context, input []byte, w io.Writer) (err error) {
+	var inputs [][]byte
+	_, err = jsonparser.ArrayEach(input, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		inputs = append(inputs, rawValue(value, dataType))
+	})
+	if err != nil {
+		return err
+	}
+	if len(inputs) != len(s.sources) {
+		return fmt.Errorf("union datasource expects %d inputs, got %d", len(s.sources), len(inputs))
+	}
+
+	results := make([]sourceResult, len(s.sources))
+	wg := &sync.WaitGroup{}
+	for i := range s.sources {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = s.load(ctx, i, inputs[i])
+		}(i)
+	}
+	wg.Wait()
+
+	var (
+		data       [][]byte
+		errs       [][]byte
+		firstErr   error
+		failedLoad int
+	)
+	for i := range results {
+		if results[i].err != nil {
+			failedLoad++
+			if firstErr == nil {
+				firstErr = results[i].err
+			}
+			errs = append(errs, sourceError(i, results[i].err))
+			continue
+		}
+		errs = append(errs, results[i].errors...)
+		if len(results[i].data) != 0 && !bytes.Equal(results[i].data, literal.NULL) {
+			data = append(data, results[i].data)
+		}
+	}
+	if failedLoad == len(results) {
+		return fmt.Errorf("%w: %v", ErrAllSourcesFail, firstErr)
+	}
+
+	out := &bytes.Buffer{}
+	out.WriteString(`{"data":`)
+	out.Write(s.merge(data))
+	if len(errs) != 0 {
+		out.WriteString(`,"errors":[`)
+		out.Write(bytes.Join(errs, literal.COMMA))
+		out.WriteString(`]`)
+	}
+	out.WriteString(`}`)
+	_, err = w.Write(out.Bytes())
+	return err
+}
+
+func (s *Source) load(ctx context.Context, i int, input []byte) sourceResult {
+	buf := &bytes.Buffer{}
+	if err := s.sources[i].Load(ctx, input, buf); err != nil {
+		return sourceResult{err: err}
+	}
+	response := buf.Bytes()
+	if !s.responseConfigs[i].ExtractGraphqlResponse {
+		return sourceResult{data: response}
+	}
+
+	var result sourceResult
+	if data, dataType, _, err := jsonparser.Get(response, "data"); err == nil && dataType != jsonparser.Null {
+		result.data = data
+		if s.responseConfigs[i].ExtractFederationEntities {
+			result.data, _, _, _ = jsonparser.Get(data, "_entities")
+		}
+	}
+	_, _ = jsonparser.ArrayEach(response, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		if dataType == jsonparser.Object {
+			result.errors = append(result.errors, value)
+		}
+	}, "errors")
+	return result
+}
+
+func sourceError(i int, err error) []byte {
+	message, _ := json.Marshal(fmt.Sprintf("failed to load source %d of union: %s", i, err))
+	return []byte(`{"message":` + string(message) + `}`)
+}
+
+// merge merges the data of the sources, lists are concatenated, objects are merged by their keys
+func (s *Source) merge(data [][]byte) []byte {
+	if len(data) == 0 {
+		return literal.NULL
+	}
+	_, dataType, _, err := jsonparser.Get(data[0])
+	if err != nil {
+		return data[0]
+	}
+	switch dataType {
+	case jsonparser.Array:
+		return s.mergeLists(data)
+	case jsonparser.Object:
+		return s.mergeObjects(data)
+	default:
+		return data[0]
+	}
+}
+
+func (s *Source) mergeObjects(objects [][]byte) []byte {
+	var keys []string
+	values := map[string][][]byte{}
+	for _, object := range objects {
+		_ = jsonparser.ObjectEach(object, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
+			value = rawValue(value, dataType)
+			k := string(key)
+			if _, ok := values[k]; !ok {
+				keys = append(keys, k)
+			}
+			values[k] = append(values[k], value)
+			return nil
+		})
+	}
+
+	out := &bytes.Buffer{}
+	out.WriteString("{")
+	for i, key := range keys {
+		if i != 0 {
+			out.WriteString(",")
+		}
+		name, _ := json.Marshal(key)
+		out.Write(name)
+		out.WriteString(":")
+		out.Write(s.mergeValues(values[key]))
+	}
+	out.WriteString("}")
+	return out.Bytes()
+}
+
+// mergeValues concatenates the values if all of them are lists, otherwise it returns the first value which isn't null
+func (s *Source) mergeValues(values [][]byte) []byte {
+	var lists [][]byte
+	for _, value := range values {
+		if bytes.Equal(value, literal.NULL) {
+			continue
+		}
+		if _, dataType, _, err := jsonparser.Get(value); err != nil || dataType != jsonparser.Array {
+			return value
+		}
+		lists = append(lists, value)
+	}
+	if len(lists) == 0 {
+		return literal.NULL
+	}
+	return s.mergeLists(lists)
+}
+
+func (s *Source) mergeLists(lists [][]byte) []byte {
+	var items [][]byte
+	for _, list := range lists {
+		_, _ = jsonparser.ArrayEach(list, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+			items = append(items, rawValue(value, dataType))
+		})
+	}
+	if s.config.DedupKey != "" {
+		items = dedup(items, strings.Split(s.config.DedupKey, "."))
+	}
+	if s.config.SortBy != "" {
+		sortItems(items, strings.Split(s.config.SortBy, "."), s.config.SortDescending)
+	}
+
+	out := &bytes.Buffer{}
+	out.WriteString("[")
+	out.Write(bytes.Join(items, literal.COMMA))
+	out.WriteString("]")
+	return out.Bytes()
+}
+
+// rawValue adds the quotes to strings, which are stripped by jsonparser, the content of strings stays escaped
+func rawValue(value []byte, dataType jsonparser.ValueType) []byte {
+	switch dataType {
+	case jsonparser.String:
+		return append(append([]byte{'"'}, value...), '"')
+	case jsonparser.Null:
+		return literal.NULL
+	default:
+		return value
+	}
+}
+
+func dedup(items [][]byte, path []string) [][]byte {
+	seen := make(map[string]struct{}, len(items))
+	deduplicated := items[:0]
+	for _, item := range items {
+		value, dataType, _, err := jsonparser.Get(item, path...)
+		if err != nil || dataType == jsonparser.Null {
+			deduplicated = append(deduplicated, item)
+			continue
+		}
+		key := dataType.String() + ":" + string(value)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		deduplicated = append(deduplicated, item)
+	}
+	return deduplicated
+}
+
+type sortValue struct {
+	exists   bool
+	isNumber bool
+	number   float64
+	value    []byte
+}
+
+func sortItems(items [][]byte, path []string, descending bool) {
+	values := make([]sortValue, len(items))
+	for i := range items {
+		value, dataType, _, err := jsonparser.Get(items[i], path...)
+		if err != nil || dataType == jsonparser.Null {
+			continue
+		}
+		values[i] = sortValue{exists: true, value: value}
+		if dataType == jsonparser.Number {
+			if number, err := strconv.ParseFloat(string(value), 64); err == nil {
+				values[i].isNumber = true
+				values[i].number = number
+			}
+		}
+	}
+
+	indexes := make([]int, len(items))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		a, b := values[indexes[i]], values[indexes[j]]
+		if !a.exists || !b.exists {
+			return a.exists && !b.exists
+		}
+		if descending {
+			a, b = b, a
+		}
+		if a.isNumber && b.isNumber {
+			return a.number < b.number
+		}
+		return bytes.Compare(a.value, b.value) < 0
+	})
+
+	sorted := make([][]byte, len(items))
+	for i, index := range indexes {
+		sorted[i] = items[index]
+	}
+	copy(items, sorted)
+}
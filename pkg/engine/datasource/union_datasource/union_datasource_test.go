@@ -0,0 +1,130 @@
+package union_datasource
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+)
+
+type fakeSource struct {
+	response string
+	err      error
+	input    []byte
+}
+
+func (f *fakeSource) Load(ctx context.Context, input []byte, w io.Writer) error {
+	f.input = append([]byte(nil), input...)
+	if f.err != nil {
+		return f.err
+	}
+	_, err := io.WriteString(w, f.response)
+	return err
+}
+
+func TestSource_Load(t *testing.T) {
+	graphqlResponse := resolve.ProcessResponseConfig{ExtractGraphqlResponse: true}
+
+	load := func(t *testing.T, source *Source, input string) string {
+		buf := &bytes.Buffer{}
+		require.NoError(t, source.Load(context.Background(), []byte(input), buf))
+		return buf.String()
+	}
+
+	t.Run("concatenates the lists of the sources", func(t *testing.T) {
+		first := &fakeSource{response: `{"data":{"search":[{"id":"1"},{"id":"2"}],"total":2}}`}
+		second := &fakeSource{response: `{"data":{"search":[{"id":"3"}],"total":1}}`}
+		source := &Source{
+			sources:         []resolve.DataSource{first, second},
+			responseConfigs: []resolve.ProcessResponseConfig{graphqlResponse, graphqlResponse},
+		}
+		out := load(t, source, `[{"url":"first"},{"url":"second"}]`)
+		assert.Equal(t, `{"data":{"search":[{"id":"1"},{"id":"2"},{"id":"3"}],"total":2}}`, out)
+		assert.Equal(t, `{"url":"first"}`, string(first.input))
+		assert.Equal(t, `{"url":"second"}`, string(second.input))
+	})
+
+	t.Run("concatenates lists of sources without graphql responses", func(t *testing.T) {
+		source := &Source{
+			sources: []resolve.DataSource{
+				&fakeSource{response: `[{"name":"a\"b"}]`},
+				&fakeSource{response: `[{"name":"c"}]`},
+			},
+			responseConfigs: []resolve.ProcessResponseConfig{{}, {}},
+		}
+		out := load(t, source, `[{},{}]`)
+		assert.Equal(t, `{"data":[{"name":"a\"b"},{"name":"c"}]}`, out)
+	})
+
+	t.Run("removes duplicates and sorts", func(t *testing.T) {
+		source := &Source{
+			config: Configuration{DedupKey: "id", SortBy: "score", SortDescending: true},
+			sources: []resolve.DataSource{
+				&fakeSource{response: `{"data":{"search":[{"id":"1","score":2},{"id":"2","score":10}]}}`},
+				&fakeSource{response: `{"data":{"search":[{"id":"2","score":1},{"id":"3","score":5},{"id":"4"}]}}`},
+			},
+			responseConfigs: []resolve.ProcessResponseConfig{graphqlResponse, graphqlResponse},
+		}
+		out := load(t, source, `[{},{}]`)
+		assert.Equal(t, `{"data":{"search":[{"id":"2","score":10},{"id":"3","score":5},{"id":"1","score":2},{"id":"4"}]}}`, out)
+	})
+
+	t.Run("sorts strings ascending by nested field", func(t *testing.T) {
+		source := &Source{
+			config: Configuration{SortBy: "product.name"},
+			sources: []resolve.DataSource{
+				&fakeSource{response: `{"data":{"search":[{"product":{"name":"b"}},{"product":{"name":"c"}}]}}`},
+				&fakeSource{response: `{"data":{"search":[{"product":{"name":"a"}}]}}`},
+			},
+			responseConfigs: []resolve.ProcessResponseConfig{graphqlResponse, graphqlResponse},
+		}
+		out := load(t, source, `[{},{}]`)
+		assert.Equal(t, `{"data":{"search":[{"product":{"name":"a"}},{"product":{"name":"b"}},{"product":{"name":"c"}}]}}`, out)
+	})
+
+	t.Run("adds errors of sources to the response", func(t *testing.T) {
+		source := &Source{
+			sources: []resolve.DataSource{
+				&fakeSource{err: errors.New("timeout")},
+				&fakeSource{response: `{"data":{"search":[{"id":"1"}]},"errors":[{"message":"partial"}]}`},
+				&fakeSource{response: `{"data":null}`},
+			},
+			responseConfigs: []resolve.ProcessResponseConfig{graphqlResponse, graphqlResponse, graphqlResponse},
+		}
+		out := load(t, source, `[{},{},{}]`)
+		assert.Equal(t, `{"data":{"search":[{"id":"1"}]},"errors":[{"message":"failed to load source 0 of union: timeout"},{"message":"partial"}]}`, out)
+	})
+
+	t.Run("fails if all sources fail", func(t *testing.T) {
+		source := &Source{
+			sources: []resolve.DataSource{
+				&fakeSource{err: errors.New("timeout")},
+				&fakeSource{err: errors.New("refused")},
+			},
+			responseConfigs: []resolve.ProcessResponseConfig{graphqlResponse, graphqlResponse},
+		}
+		err := source.Load(context.Background(), []byte(`[{},{}]`), &bytes.Buffer{})
+		assert.ErrorIs(t, err, ErrAllSourcesFail)
+	})
+}
+
+func TestAddVariables(t *testing.T) {
+	var variables resolve.Variables
+	first := addVariables(`{"body":{"q":$$0$$}}`, resolve.Variables{
+		&resolve.ContextVariable{Path: []string{"q"}},
+	}, &variables)
+	second := addVariables(`{"body":{"limit":$$0$$,"q":$$1$$}}`, resolve.Variables{
+		&resolve.ContextVariable{Path: []string{"limit"}},
+		&resolve.ContextVariable{Path: []string{"q"}},
+	}, &variables)
+
+	assert.Equal(t, `{"body":{"q":$$0$$}}`, first)
+	assert.Equal(t, `{"body":{"limit":$$1$$,"q":$$0$$}}`, second)
+	assert.Len(t, variables, 2)
+}
@@ -0,0 +1,83 @@
+package plan
+
+import (
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+)
+
+// abstractTypeIndex indexes the interfaces implemented by the types of the definition and the possible types of its interfaces.
+// Both are looked up for every field of the operation while planning, see plannerConfiguration.hasAbstractChildNode,
+// so the index is built once per plan on first use instead of scanning the definition for every field.
+type abstractTypeIndex struct {
+	definition *ast.Document
+	built      bool
+	// interfaces are the names of the interfaces implemented by an object or interface type, including its extensions
+	interfaces map[string][]string
+	// possibleTypes are the names of the object types implementing an interface, in the order of the schema
+	possibleTypes map[string][]string
+	// interfaceTypes are the names of the interface type definitions
+	interfaceTypes map[string]struct{}
+}
+
+func newAbstractTypeIndex() *abstractTypeIndex {
+	return &abstractTypeIndex{
+		interfaces:     map[string][]string{},
+		possibleTypes:  map[string][]string{},
+		interfaceTypes: map[string]struct{}{},
+	}
+}
+
+// reset drops the index of the previous plan, the index of the definition is built on first use
+func (a *abstractTypeIndex) reset(definition *ast.Document) {
+	a.definition = definition
+	a.built = false
+}
+
+func (a *abstractTypeIndex) build() {
+	if a.built {
+		return
+	}
+	a.built = true
+
+	for name := range a.interfaces {
+		delete(a.interfaces, name)
+	}
+	for name := range a.possibleTypes {
+		delete(a.possibleTypes, name)
+	}
+	for name := range a.interfaceTypes {
+		delete(a.interfaceTypes, name)
+	}
+
+	for _, node := range a.definition.RootNodes {
+		switch node.Kind {
+		case ast.NodeKindInterfaceTypeDefinition:
+			a.interfaceTypes[a.definition.InterfaceTypeDefinitionNameString(node.Ref)] = struct{}{}
+		case ast.NodeKindObjectTypeDefinition, ast.NodeKindObjectTypeExtension, ast.NodeKindInterfaceTypeExtension:
+		default:
+			continue
+		}
+		typeName := a.definition.NodeNameString(node)
+		for _, ref := range a.definition.NodeInterfaceRefs(node) {
+			interfaceName := a.definition.ResolveTypeNameString(ref)
+			a.interfaces[typeName] = append(a.interfaces[typeName], interfaceName)
+			if node.Kind == ast.NodeKindObjectTypeDefinition {
+				a.possibleTypes[interfaceName] = append(a.possibleTypes[interfaceName], typeName)
+			}
+		}
+	}
+}
+
+// implementedInterfaces returns the names of the interfaces implemented by the type
+func (a *abstractTypeIndex) implementedInterfaces(typeName string) []string {
+	a.build()
+	return a.interfaces[typeName]
+}
+
+// interfacePossibleTypes returns the names of the object types implementing the interface, nil if the type is not an interface
+func (a *abstractTypeIndex) interfacePossibleTypes(typeName string) []string {
+	a.build()
+	if _, ok := a.interfaceTypes[typeName]; !ok {
+		return nil
+	}
+	return a.possibleTypes[typeName]
+}
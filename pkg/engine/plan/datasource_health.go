@@ -0,0 +1,27 @@
+package plan
+
+import "time"
+
+// DataSourceHealth reports the health of the DataSources of a Configuration
+type DataSourceHealth interface {
+	// IsHealthy returns false if the DataSource at the index of Configuration.DataSources is known to be unhealthy
+	IsHealthy(dataSource int) bool
+}
+
+// HealthCheckConfiguration configures a periodic health check of the upstream of a DataSource.
+// The upstream is pinged with a GET request to the URL, or sent the Query as GraphQL request if it's set.
+// It's healthy if it responds with a 2xx status code and, for a Query, without errors.
+type HealthCheckConfiguration struct {
+	// Name identifies the DataSource in the health status, defaults to the URL
+	Name string
+	// URL is the URL of the health check
+	URL string
+	// Query is a minimal GraphQL query, e.g. "{__typename}", sent via POST to the URL
+	Query string
+	// Interval is the time between two health checks, defaults to 10 seconds
+	Interval time.Duration
+	// Timeout is the maximum duration of a health check, defaults to the Interval
+	Timeout time.Duration
+	// FailureThreshold is the number of consecutive failed health checks after which the DataSource is unhealthy, defaults to 1
+	FailureThreshold int
+}
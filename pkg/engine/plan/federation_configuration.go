@@ -0,0 +1,208 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+)
+
+// Subgraph is a federation subgraph, see GenerateFederationConfiguration
+type Subgraph struct {
+	// Name identifies the subgraph in the generated configuration, it defaults to the URL
+	Name string `json:"name" yaml:"name"`
+	// URL is the GraphQL endpoint of the subgraph
+	URL string `json:"url" yaml:"url"`
+	// SDL is the federation SDL of the subgraph, as returned by its _service field
+	SDL string `json:"sdl" yaml:"sdl"`
+}
+
+// FederationConfiguration is the configuration of the data sources and fields of federation subgraphs,
+// generated from their SDLs by GenerateFederationConfiguration. It can be serialized to JSON and YAML,
+// so that a gateway can be configured without hand-assembling the nodes and field configurations of each subgraph.
+type FederationConfiguration struct {
+	DataSources []FederationDataSource `json:"dataSources" yaml:"dataSources"`
+	Fields      []FederationField      `json:"fields,omitempty" yaml:"fields,omitempty"`
+}
+
+// FederationDataSource is the configuration of the data source of a subgraph, see DataSourceConfiguration
+type FederationDataSource struct {
+	Name       string                `json:"name" yaml:"name"`
+	URL        string                `json:"url" yaml:"url"`
+	ServiceSDL string                `json:"serviceSDL" yaml:"serviceSDL"`
+	RootNodes  []FederationTypeField `json:"rootNodes" yaml:"rootNodes"`
+	ChildNodes []FederationTypeField `json:"childNodes,omitempty" yaml:"childNodes,omitempty"`
+}
+
+// FederationTypeField is the serializable form of a TypeField
+type FederationTypeField struct {
+	TypeName   string   `json:"typeName" yaml:"typeName"`
+	FieldNames []string `json:"fieldNames" yaml:"fieldNames"`
+}
+
+// FederationField is the configuration of a field of the subgraphs, see FieldConfiguration
+type FederationField struct {
+	TypeName  string `json:"typeName" yaml:"typeName"`
+	FieldName string `json:"fieldName" yaml:"fieldName"`
+	// Arguments are the names of the arguments of the field, they're passed to the subgraph like in the operation
+	Arguments []string `json:"arguments,omitempty" yaml:"arguments,omitempty"`
+	// RequiresFields are the fields to fetch before the field, the key of the entity and the fields of @requires,
+	// see FieldConfiguration.RequiresFields
+	RequiresFields []string `json:"requiresFields,omitempty" yaml:"requiresFields,omitempty"`
+	// Requires is the field set of the @requires directive of the field, see FieldDependency.Requires
+	Requires string `json:"requires,omitempty" yaml:"requires,omitempty"`
+	// Provides is the field set of the @provides directive of the field, see FieldDependency.Provides
+	Provides string `json:"provides,omitempty" yaml:"provides,omitempty"`
+}
+
+// GenerateFederationConfiguration generates the configuration of the data sources and fields of the subgraphs from their SDLs:
+// the root and child nodes of each subgraph, see LocalTypeFieldExtractor, the field dependencies declared by the federation directives,
+// see FieldDependencyExtractor, and the arguments of the fields.
+// The fields of all subgraphs are merged into one list, as fields are configured for the whole graph.
+func GenerateFederationConfiguration(subgraphs ...Subgraph) (*FederationConfiguration, error) {
+	config := &FederationConfiguration{
+		DataSources: make([]FederationDataSource, 0, len(subgraphs)),
+	}
+	fields := map[string]int{}
+	for _, subgraph := range subgraphs {
+		name := subgraph.Name
+		if name == "" {
+			name = subgraph.URL
+		}
+		document, report := astparser.ParseGraphqlDocumentString(subgraph.SDL)
+		if report.HasErrors() {
+			return nil, fmt.Errorf("subgraph %s: %w", name, report)
+		}
+
+		rootNodes, childNodes := NewLocalTypeFieldExtractor(&document).GetAllNodesWithReport(&report)
+		if report.HasErrors() {
+			return nil, fmt.Errorf("subgraph %s: %w", name, report)
+		}
+		config.DataSources = append(config.DataSources, FederationDataSource{
+			Name:       name,
+			URL:        subgraph.URL,
+			ServiceSDL: subgraph.SDL,
+			RootNodes:  federationTypeFields(rootNodes),
+			ChildNodes: federationTypeFields(childNodes),
+		})
+
+		dependencies, err := NewFieldDependencyExtractor(&document).GetAllFieldDependencies()
+		if err != nil {
+			return nil, fmt.Errorf("subgraph %s: %w", name, err)
+		}
+		for i := range dependencies {
+			field := config.field(fields, dependencies[i].TypeName, dependencies[i].FieldName)
+			if len(field.RequiresFields) == 0 {
+				field.RequiresFields = dependencies[i].RequiresFields()
+			}
+			if field.Requires == "" {
+				field.Requires = dependencies[i].Requires.String()
+			}
+			if field.Provides == "" {
+				field.Provides = dependencies[i].Provides.String()
+			}
+		}
+		config.addArguments(fields, &document)
+	}
+	return config, nil
+}
+
+// DataSourceConfiguration returns the configuration of the data source of the subgraph
+// with the factory and custom configuration of the data source, e.g. of a graphql_datasource with federation enabled.
+func (d *FederationDataSource) DataSourceConfiguration(factory PlannerFactory, custom json.RawMessage) DataSourceConfiguration {
+	return DataSourceConfiguration{
+		RootNodes:  typeFields(d.RootNodes),
+		ChildNodes: typeFields(d.ChildNodes),
+		Factory:    factory,
+		Custom:     custom,
+	}
+}
+
+// FieldConfigurations returns the configurations of the fields of the subgraphs
+func (c *FederationConfiguration) FieldConfigurations() FieldConfigurations {
+	if len(c.Fields) == 0 {
+		return nil
+	}
+	fieldConfigs := make(FieldConfigurations, 0, len(c.Fields))
+	for _, field := range c.Fields {
+		fieldConfig := FieldConfiguration{
+			TypeName:       field.TypeName,
+			FieldName:      field.FieldName,
+			RequiresFields: field.RequiresFields,
+		}
+		for _, argumentName := range field.Arguments {
+			fieldConfig.Arguments = append(fieldConfig.Arguments, ArgumentConfiguration{
+				Name:       argumentName,
+				SourceType: FieldArgumentSource,
+			})
+		}
+		fieldConfigs = append(fieldConfigs, fieldConfig)
+	}
+	return fieldConfigs
+}
+
+// field returns the field of the configuration, it's added if it doesn't exist yet
+func (c *FederationConfiguration) field(fields map[string]int, typeName, fieldName string) *FederationField {
+	key := typeName + "." + fieldName
+	i, ok := fields[key]
+	if !ok {
+		i = len(c.Fields)
+		fields[key] = i
+		c.Fields = append(c.Fields, FederationField{TypeName: typeName, FieldName: fieldName})
+	}
+	return &c.Fields[i]
+}
+
+func (c *FederationConfiguration) addArguments(fields map[string]int, document *ast.Document) {
+	for _, node := range document.RootNodes {
+		if node.Kind != ast.NodeKindObjectTypeDefinition && node.Kind != ast.NodeKindObjectTypeExtension {
+			continue
+		}
+		typeName := document.NodeNameString(node)
+		if strings.HasPrefix(typeName, "__") {
+			continue
+		}
+		for _, ref := range document.NodeFieldDefinitions(node) {
+			if !document.FieldDefinitions[ref].HasArgumentsDefinitions {
+				continue
+			}
+			fieldName := document.FieldDefinitionNameString(ref)
+			if strings.HasPrefix(fieldName, "__") {
+				continue
+			}
+			field := c.field(fields, typeName, fieldName)
+			for _, argumentRef := range document.FieldDefinitions[ref].ArgumentsDefinition.Refs {
+				argumentName := document.InputValueDefinitionNameString(argumentRef)
+				if !slices.Contains(field.Arguments, argumentName) {
+					field.Arguments = append(field.Arguments, argumentName)
+				}
+			}
+		}
+	}
+}
+
+func federationTypeFields(typeFields []TypeField) []FederationTypeField {
+	if len(typeFields) == 0 {
+		return nil
+	}
+	out := make([]FederationTypeField, len(typeFields))
+	for i := range typeFields {
+		out[i] = FederationTypeField{TypeName: typeFields[i].TypeName, FieldNames: typeFields[i].FieldNames}
+	}
+	return out
+}
+
+func typeFields(federationTypeFields []FederationTypeField) []TypeField {
+	if len(federationTypeFields) == 0 {
+		return nil
+	}
+	out := make([]TypeField, len(federationTypeFields))
+	for i := range federationTypeFields {
+		out[i] = TypeField{TypeName: federationTypeFields[i].TypeName, FieldNames: federationTypeFields[i].FieldNames}
+	}
+	return out
+}
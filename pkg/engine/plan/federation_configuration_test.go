@@ -0,0 +1,115 @@
+package plan
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateFederationConfiguration(t *testing.T) {
+	accounts := Subgraph{
+		Name: "accounts",
+		URL:  "http://accounts/graphql",
+		SDL: `
+			extend type Query {
+				me: User
+				user(id: ID!): User
+			}
+
+			type User @key(fields: "id") {
+				id: ID!
+				username: String!
+			}`,
+	}
+	reviews := Subgraph{
+		URL: "http://reviews/graphql",
+		SDL: `
+			type Review {
+				body: String!
+				author: User! @provides(fields: "username")
+			}
+
+			extend type User @key(fields: "id") {
+				id: ID! @external
+				username: String! @external
+				reviews(first: Int): [Review]
+			}`,
+	}
+
+	config, err := GenerateFederationConfiguration(accounts, reviews)
+	require.NoError(t, err)
+	assert.Equal(t, &FederationConfiguration{
+		DataSources: []FederationDataSource{
+			{
+				Name:       "accounts",
+				URL:        "http://accounts/graphql",
+				ServiceSDL: accounts.SDL,
+				RootNodes: []FederationTypeField{
+					{TypeName: "Query", FieldNames: []string{"me", "user"}},
+					{TypeName: "User", FieldNames: []string{"id", "username"}},
+				},
+				ChildNodes: []FederationTypeField{
+					{TypeName: "User", FieldNames: []string{"id", "username"}},
+				},
+			},
+			{
+				Name:       "http://reviews/graphql",
+				URL:        "http://reviews/graphql",
+				ServiceSDL: reviews.SDL,
+				RootNodes: []FederationTypeField{
+					{TypeName: "User", FieldNames: []string{"reviews"}},
+				},
+				ChildNodes: []FederationTypeField{
+					{TypeName: "Review", FieldNames: []string{"body", "author"}},
+					{TypeName: "User", FieldNames: []string{"reviews", "id", "username"}},
+				},
+			},
+		},
+		Fields: []FederationField{
+			{TypeName: "User", FieldName: "username", RequiresFields: []string{"id"}},
+			{TypeName: "Query", FieldName: "user", Arguments: []string{"id"}},
+			{TypeName: "Review", FieldName: "author", Provides: "username"},
+			{TypeName: "User", FieldName: "reviews", Arguments: []string{"first"}, RequiresFields: []string{"id"}},
+		},
+	}, config)
+
+	t.Run("configurations", func(t *testing.T) {
+		assert.Equal(t, FieldConfigurations{
+			{TypeName: "User", FieldName: "username", RequiresFields: []string{"id"}},
+			{TypeName: "Query", FieldName: "user", Arguments: ArgumentsConfigurations{{Name: "id", SourceType: FieldArgumentSource}}},
+			{TypeName: "Review", FieldName: "author"},
+			{TypeName: "User", FieldName: "reviews", Arguments: ArgumentsConfigurations{{Name: "first", SourceType: FieldArgumentSource}}, RequiresFields: []string{"id"}},
+		}, config.FieldConfigurations())
+
+		custom := json.RawMessage(`{"fetch":{"url":"http://reviews/graphql"}}`)
+		assert.Equal(t, DataSourceConfiguration{
+			RootNodes: []TypeField{{TypeName: "User", FieldNames: []string{"reviews"}}},
+			ChildNodes: []TypeField{
+				{TypeName: "Review", FieldNames: []string{"body", "author"}},
+				{TypeName: "User", FieldNames: []string{"reviews", "id", "username"}},
+			},
+			Custom: custom,
+		}, config.DataSources[1].DataSourceConfiguration(nil, custom))
+	})
+
+	t.Run("serializes to JSON", func(t *testing.T) {
+		data, err := json.Marshal(config)
+		require.NoError(t, err)
+		var decoded FederationConfiguration
+		require.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, config, &decoded)
+		assert.Contains(t, string(data), `{"typeName":"Review","fieldName":"author","provides":"username"}`)
+	})
+
+	t.Run("invalid SDL", func(t *testing.T) {
+		_, err := GenerateFederationConfiguration(Subgraph{Name: "broken", SDL: `type Query {`})
+		assert.ErrorContains(t, err, "subgraph broken: ")
+	})
+
+	t.Run("undefined root operation type", func(t *testing.T) {
+		_, err := GenerateFederationConfiguration(Subgraph{Name: "broken", SDL: `schema { query: RootQuery } type Query { me: String }`})
+		assert.ErrorContains(t, err, "subgraph broken: external: the query root operation type named 'RootQuery' is undefined")
+	})
+}
@@ -0,0 +1,191 @@
+package plan
+
+import (
+	"fmt"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+)
+
+// FieldDependency describes which fields a field of a federation subgraph depends on,
+// as declared by the @key, @requires and @provides directives of the subgraph SDL.
+type FieldDependency struct {
+	TypeName  string
+	FieldName string
+	// Keys are the field sets of the @key directives of the enclosing entity.
+	// The fields of one of the keys have to be fetched before the field to load it via the _entities field of the subgraph.
+	// It's empty if the enclosing type isn't an entity.
+	Keys []FieldSet
+	// Requires are the fields of the enclosing type which have to be fetched from other subgraphs before the field,
+	// they're sent along with the key in the representation of the entity.
+	Requires FieldSet
+	// Provides are the @external fields of the type of the field which the subgraph resolves along with the field,
+	// so that they don't have to be fetched from other subgraphs.
+	Provides FieldSet
+}
+
+// RequiresFields returns the names of the top level fields of the first key and of Requires,
+// the fields to fetch before the field in the form of FieldConfiguration.RequiresFields.
+func (d *FieldDependency) RequiresFields() []string {
+	var requiresFields []string
+	if len(d.Keys) != 0 {
+		requiresFields = append(requiresFields, d.Keys[0].FieldNames()...)
+	}
+	return append(requiresFields, d.Requires.FieldNames()...)
+}
+
+// FieldDependencyExtractor extracts the dependencies of the fields of a federation subgraph SDL,
+// see FieldDependency. In contrast to RequiredFieldExtractor it parses nested field sets,
+// e.g. `@requires(fields: "organization { id }")`, and considers all keys of an entity and @provides.
+type FieldDependencyExtractor struct {
+	document *ast.Document
+}
+
+func NewFieldDependencyExtractor(document *ast.Document) *FieldDependencyExtractor {
+	return &FieldDependencyExtractor{
+		document: document,
+	}
+}
+
+// GetAllFieldDependencies returns the dependencies of all fields of object and interface types and their extensions in the order of the document.
+// Fields without dependencies are omitted, as are @external fields, which aren't resolved by the subgraph,
+// and key fields of entities, which are part of the representation of the entity.
+func (f *FieldDependencyExtractor) GetAllFieldDependencies() ([]FieldDependency, error) {
+	var (
+		dependencies []FieldDependency
+		keysByType   = map[string][]FieldSet{}
+	)
+	for _, node := range f.document.RootNodes {
+		if !isEntityNodeKind(node.Kind) {
+			continue
+		}
+		typeName := f.document.NodeNameString(node)
+		keys, ok := keysByType[typeName]
+		if !ok {
+			var err error
+			if keys, err = f.entityKeys(typeName, map[string]struct{}{}); err != nil {
+				return nil, err
+			}
+			keysByType[typeName] = keys
+		}
+		keyFields := make(map[string]struct{})
+		for i := range keys {
+			for _, name := range keys[i].FieldNames() {
+				keyFields[name] = struct{}{}
+			}
+		}
+
+		for _, ref := range f.document.NodeFieldDefinitions(node) {
+			if f.document.FieldDefinitionHasNamedDirective(ref, federationExternalDirectiveName) {
+				continue
+			}
+			fieldName := f.document.FieldDefinitionNameString(ref)
+			if _, isKeyField := keyFields[fieldName]; isKeyField {
+				continue
+			}
+			directiveRefs := f.document.FieldDefinitions[ref].Directives.Refs
+			requires, err := f.fieldSetOfDirective(directiveRefs, federationRequireDirectiveName)
+			if err != nil {
+				return nil, fmt.Errorf("@%s of field %s.%s: %w", federationRequireDirectiveName, typeName, fieldName, err)
+			}
+			provides, err := f.fieldSetOfDirective(directiveRefs, federationProvidesDirectiveName)
+			if err != nil {
+				return nil, fmt.Errorf("@%s of field %s.%s: %w", federationProvidesDirectiveName, typeName, fieldName, err)
+			}
+			if len(keys) == 0 && len(requires) == 0 && len(provides) == 0 {
+				continue
+			}
+			dependencies = append(dependencies, FieldDependency{
+				TypeName:  typeName,
+				FieldName: fieldName,
+				Keys:      keys,
+				Requires:  requires,
+				Provides:  provides,
+			})
+		}
+	}
+	return dependencies, nil
+}
+
+// entityKeys returns the keys of all definitions and extensions of the type,
+// e.g. a local "extend type User" of the entity "type User @key(fields: "id")" has the keys of the entity.
+// Types without keys which implement an interface entity have the keys of the interface,
+// e.g. "type Book implements Media" of the entity "interface Media @key(fields: "id")".
+func (f *FieldDependencyExtractor) entityKeys(typeName string, seenTypes map[string]struct{}) ([]FieldSet, error) {
+	if _, exists := seenTypes[typeName]; exists {
+		return nil, nil
+	}
+	seenTypes[typeName] = struct{}{}
+
+	var keys []FieldSet
+	seen := make(map[string]struct{})
+	nodes, _ := f.document.NodesByNameStr(typeName)
+	for _, node := range nodes {
+		if !isEntityNodeKind(node.Kind) {
+			continue
+		}
+		for _, directiveRef := range f.document.NodeDirectives(node) {
+			if f.document.DirectiveNameString(directiveRef) != FederationKeyDirectiveName {
+				continue
+			}
+			fields, ok := f.fieldsArgument(directiveRef)
+			if !ok {
+				continue
+			}
+			key, err := ParseFieldSet(fields)
+			if err != nil {
+				return nil, fmt.Errorf("@%s of type %s: %w", FederationKeyDirectiveName, typeName, err)
+			}
+			if _, exists := seen[key.String()]; exists {
+				continue
+			}
+			seen[key.String()] = struct{}{}
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) != 0 {
+		return keys, nil
+	}
+
+	for _, node := range nodes {
+		if !isEntityNodeKind(node.Kind) {
+			continue
+		}
+		for _, interfaceRef := range f.document.NodeInterfaceRefs(node) {
+			interfaceKeys, err := f.entityKeys(f.document.ResolveTypeNameString(interfaceRef), seenTypes)
+			if err != nil {
+				return nil, err
+			}
+			if len(interfaceKeys) != 0 {
+				return interfaceKeys, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (f *FieldDependencyExtractor) fieldSetOfDirective(directiveRefs []int, directiveName string) (FieldSet, error) {
+	for _, directiveRef := range directiveRefs {
+		if f.document.DirectiveNameString(directiveRef) != directiveName {
+			continue
+		}
+		fields, ok := f.fieldsArgument(directiveRef)
+		if !ok {
+			continue
+		}
+		return ParseFieldSet(fields)
+	}
+	return nil, nil
+}
+
+func (f *FieldDependencyExtractor) fieldsArgument(directiveRef int) (string, bool) {
+	return directiveFieldsArgument(f.document, directiveRef)
+}
+
+// directiveFieldsArgument returns the fields argument of a federation directive if it's a string
+func directiveFieldsArgument(document *ast.Document, directiveRef int) (string, bool) {
+	value, exists := document.DirectiveArgumentValueByName(directiveRef, fieldsArgumentNameBytes)
+	if !exists || value.Kind != ast.ValueKindString {
+		return "", false
+	}
+	return document.StringValueContentString(value.Ref), true
+}
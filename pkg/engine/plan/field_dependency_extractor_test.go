@@ -0,0 +1,139 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeparser"
+)
+
+func TestFieldDependencyExtractor_GetAllFieldDependencies(t *testing.T) {
+	run := func(t *testing.T, SDL string, expected []FieldDependency) {
+		t.Helper()
+
+		document := unsafeparser.ParseGraphqlDocumentString(SDL)
+		got, err := NewFieldDependencyExtractor(&document).GetAllFieldDependencies()
+		require.NoError(t, err)
+		assert.Equal(t, expected, got)
+	}
+
+	t.Run("non Entity objects", func(t *testing.T) {
+		run(t, `
+		type Query {
+			topReviews: [Review!]!
+		}
+
+		type Review {
+			body: String!
+			author: User! @provides(fields: "username address { city }")
+		}
+		`, []FieldDependency{
+			{
+				TypeName:  "Review",
+				FieldName: "author",
+				Provides:  FieldSet{{Name: "username"}, {Name: "address", FieldSet: FieldSet{{Name: "city"}}}},
+			},
+		})
+	})
+
+	t.Run("Entity with multiple keys", func(t *testing.T) {
+		run(t, `
+		type Product @key(fields: "upc") @key(fields: "sku organization { id }") {
+			upc: String!
+			sku: String!
+			organization: Organization!
+			name: String
+		}
+		`, []FieldDependency{
+			{
+				TypeName:  "Product",
+				FieldName: "name",
+				Keys: []FieldSet{
+					{{Name: "upc"}},
+					{{Name: "sku"}, {Name: "organization", FieldSet: FieldSet{{Name: "id"}}}},
+				},
+			},
+		})
+	})
+
+	t.Run("Entity extension with requires", func(t *testing.T) {
+		run(t, `
+		extend type User @key(fields: "id") {
+			id: ID! @external
+			address: Address! @external
+			weight: Float! @external
+			shippingEstimate: Int @requires(fields: "weight address { country }")
+			reviews: [Review!]! @provides(fields: "body")
+		}
+		`, []FieldDependency{
+			{
+				TypeName:  "User",
+				FieldName: "shippingEstimate",
+				Keys:      []FieldSet{{{Name: "id"}}},
+				Requires:  FieldSet{{Name: "weight"}, {Name: "address", FieldSet: FieldSet{{Name: "country"}}}},
+			},
+			{
+				TypeName:  "User",
+				FieldName: "reviews",
+				Keys:      []FieldSet{{{Name: "id"}}},
+				Provides:  FieldSet{{Name: "body"}},
+			},
+		})
+	})
+
+	t.Run("Entity with local object extension", func(t *testing.T) {
+		run(t, `
+		type User @key(fields: "id") {
+			id: ID!
+			name: String!
+		}
+
+		extend type User @key(fields: "id") {
+			age: Int!
+		}
+		`, []FieldDependency{
+			{TypeName: "User", FieldName: "name", Keys: []FieldSet{{{Name: "id"}}}},
+			{TypeName: "User", FieldName: "age", Keys: []FieldSet{{{Name: "id"}}}},
+		})
+	})
+
+	t.Run("interface Entity with possible types", func(t *testing.T) {
+		run(t, `
+		interface Media @key(fields: "id") {
+			id: ID!
+			title: String
+		}
+
+		type Book implements Media {
+			id: ID!
+			title: String
+		}
+		`, []FieldDependency{
+			{TypeName: "Media", FieldName: "title", Keys: []FieldSet{{{Name: "id"}}}},
+			{TypeName: "Book", FieldName: "title", Keys: []FieldSet{{{Name: "id"}}}},
+		})
+	})
+
+	t.Run("invalid field set", func(t *testing.T) {
+		document := unsafeparser.ParseGraphqlDocumentString(`
+		extend type User @key(fields: "id") {
+			id: ID! @external
+			address: Address! @external
+			shippingEstimate: Int @requires(fields: "address { country")
+		}
+		`)
+		_, err := NewFieldDependencyExtractor(&document).GetAllFieldDependencies()
+		assert.EqualError(t, err, `@requires of field User.shippingEstimate: invalid field set "address { country": missing closing brace`)
+	})
+}
+
+func TestFieldDependency_RequiresFields(t *testing.T) {
+	dependency := FieldDependency{
+		Keys:     []FieldSet{{{Name: "sku"}, {Name: "organization", FieldSet: FieldSet{{Name: "id"}}}}, {{Name: "upc"}}},
+		Requires: FieldSet{{Name: "weight"}},
+	}
+	assert.Equal(t, []string{"sku", "organization", "weight"}, dependency.RequiresFields())
+	assert.Empty(t, (&FieldDependency{}).RequiresFields())
+}
@@ -0,0 +1,151 @@
+package plan
+
+import (
+	"fmt"
+	"strings"
+)
+
+const federationProvidesDirectiveName = "provides"
+
+// FieldSet is a parsed federation field set, the fields argument of the @key, @requires and @provides directives,
+// e.g. `id organization { id }`
+type FieldSet []FieldSetField
+
+// FieldSetField is a field of a FieldSet
+type FieldSetField struct {
+	Name string
+	// FieldSet are the fields selected on the value of the field, e.g. id for `organization { id }`.
+	// It's empty for scalar fields.
+	FieldSet FieldSet
+}
+
+// ParseFieldSet parses the fields argument of a federation directive.
+// Fields are separated by whitespace or commas, the fields of objects are selected in braces.
+// Aliases, arguments and fragments are not supported.
+func ParseFieldSet(fields string) (FieldSet, error) {
+	parser := fieldSetParser{input: fields}
+	fieldSet, err := parser.parseFieldSet(0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field set %q: %w", fields, err)
+	}
+	return fieldSet, nil
+}
+
+// FieldNames returns the names of the top level fields of the field set
+func (f FieldSet) FieldNames() []string {
+	if len(f) == 0 {
+		return nil
+	}
+	names := make([]string, len(f))
+	for i := range f {
+		names[i] = f[i].Name
+	}
+	return names
+}
+
+// String renders the field set in the form of the fields argument, e.g. `id organization {id}`
+func (f FieldSet) String() string {
+	builder := &strings.Builder{}
+	f.write(builder)
+	return builder.String()
+}
+
+func (f FieldSet) write(builder *strings.Builder) {
+	for i := range f {
+		if i != 0 {
+			builder.WriteByte(' ')
+		}
+		builder.WriteString(f[i].Name)
+		if len(f[i].FieldSet) != 0 {
+			builder.WriteString(" {")
+			f[i].FieldSet.write(builder)
+			builder.WriteByte('}')
+		}
+	}
+}
+
+type fieldSetParser struct {
+	input    string
+	position int
+}
+
+func (p *fieldSetParser) parseFieldSet(depth int) (FieldSet, error) {
+	var fieldSet FieldSet
+	for {
+		p.skipIgnored()
+		if p.position == len(p.input) {
+			if depth != 0 {
+				return nil, fmt.Errorf("missing closing brace")
+			}
+			break
+		}
+		char := p.input[p.position]
+		switch {
+		case char == '}':
+			if depth == 0 {
+				return nil, fmt.Errorf("unexpected closing brace at position %d", p.position)
+			}
+			p.position++
+			if len(fieldSet) == 0 {
+				return nil, fmt.Errorf("empty selection at position %d", p.position-1)
+			}
+			return fieldSet, nil
+		case char == '{':
+			if len(fieldSet) == 0 || len(fieldSet[len(fieldSet)-1].FieldSet) != 0 {
+				return nil, fmt.Errorf("unexpected opening brace at position %d", p.position)
+			}
+			p.position++
+			nested, err := p.parseFieldSet(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			fieldSet[len(fieldSet)-1].FieldSet = nested
+		case isFieldSetNameStart(char):
+			start := p.position
+			for p.position < len(p.input) && isFieldSetNameContinue(p.input[p.position]) {
+				p.position++
+			}
+			fieldSet = append(fieldSet, FieldSetField{Name: p.input[start:p.position]})
+		default:
+			return nil, fmt.Errorf("unexpected character %q at position %d", char, p.position)
+		}
+	}
+	if len(fieldSet) == 0 {
+		return nil, fmt.Errorf("empty field set")
+	}
+	return fieldSet, nil
+}
+
+// skipIgnored skips whitespace, commas and comments, the ignored tokens of GraphQL
+func (p *fieldSetParser) skipIgnored() {
+	for p.position < len(p.input) {
+		switch p.input[p.position] {
+		case ' ', '\t', '\n', '\r', ',':
+			p.position++
+		case '#':
+			for p.position < len(p.input) && p.input[p.position] != '\n' {
+				p.position++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func isFieldSetNameStart(char byte) bool {
+	return char == '_' || (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+}
+
+func isFieldSetNameContinue(char byte) bool {
+	return isFieldSetNameStart(char) || (char >= '0' && char <= '9')
+}
+
+// fieldSetFieldNames returns the top level field names of the fields argument,
+// it falls back to splitting the argument by spaces if it's not a valid field set
+func fieldSetFieldNames(fields string) []string {
+	fieldSet, err := ParseFieldSet(fields)
+	if err != nil {
+		return strings.Split(fields, " ")
+	}
+	return fieldSet.FieldNames()
+}
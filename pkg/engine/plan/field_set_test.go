@@ -0,0 +1,49 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFieldSet(t *testing.T) {
+	t.Run("fields", func(t *testing.T) {
+		fieldSet, err := ParseFieldSet("id  name,\n\tupc")
+		require.NoError(t, err)
+		assert.Equal(t, FieldSet{{Name: "id"}, {Name: "name"}, {Name: "upc"}}, fieldSet)
+		assert.Equal(t, []string{"id", "name", "upc"}, fieldSet.FieldNames())
+		assert.Equal(t, "id name upc", fieldSet.String())
+	})
+
+	t.Run("nested fields", func(t *testing.T) {
+		fieldSet, err := ParseFieldSet("id organization { id address{ city country } } sku")
+		require.NoError(t, err)
+		assert.Equal(t, FieldSet{
+			{Name: "id"},
+			{Name: "organization", FieldSet: FieldSet{
+				{Name: "id"},
+				{Name: "address", FieldSet: FieldSet{{Name: "city"}, {Name: "country"}}},
+			}},
+			{Name: "sku"},
+		}, fieldSet)
+		assert.Equal(t, []string{"id", "organization", "sku"}, fieldSet.FieldNames())
+		assert.Equal(t, "id organization {id address {city country}} sku", fieldSet.String())
+	})
+
+	t.Run("invalid field sets", func(t *testing.T) {
+		for _, fields := range []string{
+			"",
+			"{ id }",
+			"organization { id",
+			"organization { id } }",
+			"organization { }",
+			"organization { id } { name }",
+			"alias: id",
+			"... on User { id }",
+		} {
+			_, err := ParseFieldSet(fields)
+			assert.Error(t, err, fields)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package plan
 
 import (
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
 )
 
 const FederationKeyDirectiveName = "key"
@@ -16,12 +17,15 @@ const (
 // root operation types (usually Query, Mutation and Schema--though these types
 // can be configured via the schema keyword) plus "entities" as defined by the
 // Apollo federation specification. In short, entities are types with a @key
-// directive. Child nodes are field types recursively accessible via a root
+// directive. Interfaces with a @key directive are entities as well, as are
+// their possible types, which can be loaded by the key of the interface.
+// Child nodes are field types recursively accessible via a root
 // node. Nodes are either object or interface definitions or extensions. Root
 // nodes only include "local" fields; they don't include fields that have the
 // @external directive.
 type LocalTypeFieldExtractor struct {
 	document               *ast.Document
+	report                 *operationreport.Report
 	queryTypeName          string
 	mutationTypeName       string
 	subscriptionTypeName   string
@@ -89,7 +93,17 @@ func (r *rootNodeNamesMap) asSlice() []string {
 // GetAllNodes returns all root and child nodes in the document associated with
 // the LocalTypeFieldExtractor. See LocalTypeFieldExtractor for a detailed
 // explanation of what root and child nodes are.
+// Problems of the document are ignored, see GetAllNodesWithReport.
 func (e *LocalTypeFieldExtractor) GetAllNodes() ([]TypeField, []TypeField) {
+	return e.GetAllNodesWithReport(&operationreport.Report{})
+}
+
+// GetAllNodesWithReport returns all root and child nodes like GetAllNodes and
+// adds the problems of the document which cause nodes to be missing or wrong
+// to the report: root operation types named in the schema definition which
+// are undefined, and invalid fields arguments of the @key, @requires and
+// @provides directives. The nodes are returned even if the report has errors.
+func (e *LocalTypeFieldExtractor) GetAllNodesWithReport(report *operationreport.Report) ([]TypeField, []TypeField) {
 	// The strategy for the extractor is as follows:
 	//
 	// 1. Loop over each node in the document and collect information into
@@ -112,6 +126,7 @@ func (e *LocalTypeFieldExtractor) GetAllNodes() ([]TypeField, []TypeField) {
 	//    union members--since it ISN'T possible to select directly from a
 	//    union; union selection sets MUST contain fragments.
 
+	e.report = report
 	e.nodeInfoMap = make(map[string]*nodeInformation, len(e.document.RootNodes))
 	e.possibleInterfaceTypes = map[string][]string{}
 	e.rootNodeNames = newRootNodeNamesMap()
@@ -120,9 +135,16 @@ func (e *LocalTypeFieldExtractor) GetAllNodes() ([]TypeField, []TypeField) {
 	// 1. Loop over each node in the document (see description above).
 	e.collectNodeInformation()
 
+	// Root operation types which are named in the schema definition but
+	// undefined would silently be missing from the root nodes.
+	e.validateRootOperationTypes()
+
 	// Record the concrete types for each interface.
 	e.assignConcreteTypesToInterfaces()
 
+	// The possible types of interface entities are entities as well.
+	e.collectPossibleTypesOfInterfaceEntities()
+
 	// Make sure that root and child node slices are cleared
 	e.resetRootAndChildNodes()
 
@@ -139,20 +161,10 @@ func (e *LocalTypeFieldExtractor) GetAllNodes() ([]TypeField, []TypeField) {
 }
 
 func (e *LocalTypeFieldExtractor) overrideRootOperationTypeNames() {
-	indexedQueryTypeName := string(e.document.Index.QueryTypeName)
-	if indexedQueryTypeName != "" && indexedQueryTypeName != e.queryTypeName {
-		e.queryTypeName = indexedQueryTypeName
-	}
-
-	indexedMutationTypeName := string(e.document.Index.MutationTypeName)
-	if indexedMutationTypeName != "" && indexedMutationTypeName != e.mutationTypeName {
-		e.mutationTypeName = indexedMutationTypeName
-	}
-
-	indexedSubscriptionTypeName := string(e.document.Index.SubscriptionTypeName)
-	if indexedSubscriptionTypeName != "" && indexedSubscriptionTypeName != e.subscriptionTypeName {
-		e.subscriptionTypeName = indexedSubscriptionTypeName
-	}
+	queryTypeName, mutationTypeName, subscriptionTypeName := e.document.Index.RootOperationTypeNames()
+	e.queryTypeName = queryTypeName.String()
+	e.mutationTypeName = mutationTypeName.String()
+	e.subscriptionTypeName = subscriptionTypeName.String()
 }
 
 func (e *LocalTypeFieldExtractor) collectNodeInformation() {
@@ -161,18 +173,17 @@ func (e *LocalTypeFieldExtractor) collectNodeInformation() {
 
 		switch astNode.Kind {
 		case ast.NodeKindObjectTypeDefinition, ast.NodeKindObjectTypeExtension:
-			for _, ref := range e.document.NodeInterfaceRefs(astNode) {
-				interfaceName := e.document.ResolveTypeNameString(ref)
-				// The document doesn't provide a way to directly look up the
-				// types that implement an interface, so instead we track the
-				// interfaces implemented for each type and after all nodes
-				// have been processed record the concrete types for each
-				// interface.
-				e.possibleInterfaceTypes[interfaceName] = append(
-					e.possibleInterfaceTypes[interfaceName], nodeInfo.typeName)
-			}
+			e.collectImplementedInterfaces(astNode, nodeInfo)
+			e.validateFieldSetDirectives(e.document.NodeDirectives(astNode), nodeInfo.typeName, FederationKeyDirectiveName)
 		case ast.NodeKindInterfaceTypeDefinition, ast.NodeKindInterfaceTypeExtension:
 			nodeInfo.isInterface = true
+			e.validateFieldSetDirectives(e.document.NodeDirectives(astNode), nodeInfo.typeName, FederationKeyDirectiveName)
+			// Interfaces may implement other interfaces, e.g. `interface Image
+			// implements Node & Resource`. Fragments on the implementing
+			// interface are possible wherever the implemented interface is
+			// returned, so the implementing interface is also recorded as a
+			// possible type.
+			e.collectImplementedInterfaces(astNode, nodeInfo)
 		case ast.NodeKindUnionTypeDefinition, ast.NodeKindUnionTypeExtension:
 			for _, ref := range e.document.NodeUnionMemberRefs(astNode) {
 				// Local union extensions are disjoint. For details, see the GraphQL
@@ -196,6 +207,19 @@ func (e *LocalTypeFieldExtractor) collectNodeInformation() {
 	}
 }
 
+func (e *LocalTypeFieldExtractor) collectImplementedInterfaces(node ast.Node, nodeInfo *nodeInformation) {
+	for _, ref := range e.document.NodeInterfaceRefs(node) {
+		interfaceName := e.document.ResolveTypeNameString(ref)
+		// The document doesn't provide a way to directly look up the
+		// types that implement an interface, so instead we track the
+		// interfaces implemented for each type and after all nodes
+		// have been processed record the concrete types for each
+		// interface.
+		e.possibleInterfaceTypes[interfaceName] = append(
+			e.possibleInterfaceTypes[interfaceName], nodeInfo.typeName)
+	}
+}
+
 func (e *LocalTypeFieldExtractor) getNodeInfo(node ast.Node) *nodeInformation {
 	typeName := e.document.NodeNameString(node)
 	nodeInfo, ok := e.nodeInfoMap[typeName]
@@ -216,11 +240,10 @@ func (e *LocalTypeFieldExtractor) getNodeInfo(node ast.Node) *nodeInformation {
 }
 
 func (e *LocalTypeFieldExtractor) isRootNode(nodeInfo *nodeInformation) bool {
-	isFederationEntity := nodeInfo.hasKeyDirective && !nodeInfo.isInterface
 	return nodeInfo.typeName == e.queryTypeName ||
 		nodeInfo.typeName == e.mutationTypeName ||
 		nodeInfo.typeName == e.subscriptionTypeName ||
-		isFederationEntity
+		nodeInfo.hasKeyDirective
 }
 
 func (e *LocalTypeFieldExtractor) collectFieldDefinitions(node ast.Node, nodeInfo *nodeInformation) {
@@ -234,6 +257,10 @@ func (e *LocalTypeFieldExtractor) collectFieldDefinitions(node ast.Node, nodeInf
 			nodeInfo.localFieldRefs = append(nodeInfo.localFieldRefs, ref)
 		}
 
+		coordinate := nodeInfo.typeName + "." + e.document.FieldDefinitionNameString(ref)
+		e.validateFieldSetDirectives(e.document.FieldDefinitions[ref].Directives.Refs, coordinate,
+			federationRequireDirectiveName, federationProvidesDirectiveName)
+
 		requiredFields := requiredFieldsByRequiresDirective(e.document, ref)
 		for _, field := range requiredFields {
 			nodeInfo.requiredFields[field] = struct{}{}
@@ -241,6 +268,54 @@ func (e *LocalTypeFieldExtractor) collectFieldDefinitions(node ast.Node, nodeInf
 	}
 }
 
+// validateFieldSetDirectives reports the directives with one of the given
+// names whose fields argument isn't a valid field set. Otherwise, the
+// required fields would silently fall back to splitting the argument by
+// spaces, see fieldSetFieldNames.
+func (e *LocalTypeFieldExtractor) validateFieldSetDirectives(directiveRefs []int, coordinate string, directiveNames ...string) {
+	for _, directiveRef := range directiveRefs {
+		directiveName := e.document.DirectiveNameString(directiveRef)
+		isFieldSetDirective := false
+		for _, name := range directiveNames {
+			if directiveName == name {
+				isFieldSetDirective = true
+				break
+			}
+		}
+		if !isFieldSetDirective {
+			continue
+		}
+		fields, ok := directiveFieldsArgument(e.document, directiveRef)
+		if !ok {
+			continue
+		}
+		if _, err := ParseFieldSet(fields); err != nil {
+			e.report.AddExternalError(operationreport.ErrInvalidFieldSetOfDirective(directiveName, coordinate, err))
+		}
+	}
+}
+
+func (e *LocalTypeFieldExtractor) validateRootOperationTypes() {
+	rootOperationTypes := []struct {
+		operationType string
+		typeName      ast.ByteSlice
+	}{
+		{operationType: "query", typeName: e.document.Index.QueryTypeName},
+		{operationType: "mutation", typeName: e.document.Index.MutationTypeName},
+		{operationType: "subscription", typeName: e.document.Index.SubscriptionTypeName},
+	}
+	for _, rootOperationType := range rootOperationTypes {
+		// Only explicitly named root operation types are checked; a subgraph
+		// doesn't have to define e.g. a Mutation type.
+		if len(rootOperationType.typeName) == 0 {
+			continue
+		}
+		if _, ok := e.nodeInfoMap[rootOperationType.typeName.String()]; !ok {
+			e.report.AddExternalError(operationreport.ErrRootOperationTypeUndefined(rootOperationType.operationType, rootOperationType.typeName.String()))
+		}
+	}
+}
+
 func (e *LocalTypeFieldExtractor) assignConcreteTypesToInterfaces() {
 	for interfaceName, concreteTypeNames := range e.possibleInterfaceTypes {
 		if nodeInfo, ok := e.nodeInfoMap[interfaceName]; ok {
@@ -249,16 +324,59 @@ func (e *LocalTypeFieldExtractor) assignConcreteTypesToInterfaces() {
 	}
 }
 
+// collectPossibleTypesOfInterfaceEntities records the possible types of
+// interfaces with a @key directive as root nodes, even if they don't have a
+// @key directive themselves, e.g. `type Book implements Media` of the entity
+// `interface Media @key(fields: "id")`.
+func (e *LocalTypeFieldExtractor) collectPossibleTypesOfInterfaceEntities() {
+	for _, typeName := range e.rootNodeNames.asSlice() {
+		nodeInfo := e.nodeInfoMap[typeName]
+		if nodeInfo.isInterface && nodeInfo.hasKeyDirective {
+			e.appendPossibleTypesAsRootNodes(nodeInfo)
+		}
+	}
+}
+
+func (e *LocalTypeFieldExtractor) appendPossibleTypesAsRootNodes(nodeInfo *nodeInformation) {
+	for _, name := range nodeInfo.concreteTypeNames {
+		possibleType, ok := e.nodeInfoMap[name]
+		if !ok || possibleType.isRoot {
+			continue
+		}
+		possibleType.isRoot = true
+		e.rootNodeNames.append(name)
+		// Possible types of interfaces may be interfaces themselves.
+		e.appendPossibleTypesAsRootNodes(possibleType)
+	}
+}
+
 // pushChildIfNotAlreadyProcessed pushes a child type onto the queue if it
 // hasn't already been processed. Only types with node info are pushed onto
 // the queue. Recall that node info is limited to object types, interfaces
-// and union members above.
-func (e *LocalTypeFieldExtractor) pushChildIfNotAlreadyProcessed(typeName string) {
-	if _, ok := e.childrenSeen[typeName]; !ok {
-		if _, ok := e.nodeInfoMap[typeName]; ok {
-			e.childrenToProcess = append(e.childrenToProcess, typeName)
+// and union members above. It returns false if the type has already been seen.
+func (e *LocalTypeFieldExtractor) pushChildIfNotAlreadyProcessed(typeName string) bool {
+	if _, ok := e.childrenSeen[typeName]; ok {
+		return false
+	}
+	if _, ok := e.nodeInfoMap[typeName]; ok {
+		e.childrenToProcess = append(e.childrenToProcess, typeName)
+	}
+	e.childrenSeen[typeName] = struct{}{}
+	return true
+}
+
+// pushChildWithPossibleTypes pushes node info for the type as well as--in the
+// case of abstract types--node info for each possible type. Possible types of
+// interfaces may be interfaces themselves, so their possible types are pushed
+// as well.
+func (e *LocalTypeFieldExtractor) pushChildWithPossibleTypes(typeName string) {
+	if !e.pushChildIfNotAlreadyProcessed(typeName) {
+		return
+	}
+	if nodeInfo, ok := e.nodeInfoMap[typeName]; ok {
+		for _, name := range nodeInfo.concreteTypeNames {
+			e.pushChildWithPossibleTypes(name)
 		}
-		e.childrenSeen[typeName] = struct{}{}
 	}
 }
 
@@ -267,12 +385,7 @@ func (e *LocalTypeFieldExtractor) pushChildIfNotAlreadyProcessed(typeName string
 func (e *LocalTypeFieldExtractor) processFieldRef(ref int) string {
 	fieldType := e.document.FieldDefinitionType(ref)
 	fieldTypeName := e.document.ResolveTypeNameString(fieldType)
-	e.pushChildIfNotAlreadyProcessed(fieldTypeName)
-	if nodeInfo, ok := e.nodeInfoMap[fieldTypeName]; ok {
-		for _, name := range nodeInfo.concreteTypeNames {
-			e.pushChildIfNotAlreadyProcessed(name)
-		}
-	}
+	e.pushChildWithPossibleTypes(fieldTypeName)
 	return e.document.FieldDefinitionNameString(ref)
 }
 
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
 )
 
 func sortNodesAndFields(nodes []TypeField) {
@@ -61,6 +62,33 @@ func TestLocalTypeFieldExtractor_GetAllNodes(t *testing.T) {
 				{TypeName: "User", FieldNames: []string{"id"}},
 			})
 	})
+	t.Run("custom root operation type names", func(t *testing.T) {
+		run(t, `
+			schema {
+				query: RootQuery
+				mutation: RootMutation
+			}
+
+			type RootQuery {
+				query: Query
+			}
+
+			type RootMutation {
+				addUser(id: ID!): Query
+			}
+
+			type Query {
+				id: ID!
+			}
+		`,
+			[]TypeField{
+				{TypeName: "RootMutation", FieldNames: []string{"addUser"}},
+				{TypeName: "RootQuery", FieldNames: []string{"query"}},
+			},
+			[]TypeField{
+				{TypeName: "Query", FieldNames: []string{"id"}},
+			})
+	})
 	t.Run("orphan pair", func(t *testing.T) {
 		run(t, `
 			extend type Query {
@@ -242,9 +270,6 @@ func TestLocalTypeFieldExtractor_GetAllNodes(t *testing.T) {
 				user: User
 			}
 
-			# A key directive on an interface is allowed, but it doesn't make
-			# the interface a root node. Entity queries can only be made for
-			# concrete types.
 			interface Communication @key(fields: "id") {
 				id: ID!
 				comment: String!
@@ -253,6 +278,7 @@ func TestLocalTypeFieldExtractor_GetAllNodes(t *testing.T) {
 		`,
 			[]TypeField{
 				{TypeName: "Comment", FieldNames: []string{"comment", "id", "user"}},
+				{TypeName: "Communication", FieldNames: []string{"comment", "id", "user"}},
 				{TypeName: "Query", FieldNames: []string{"communication", "me", "user"}},
 				{TypeName: "Review", FieldNames: []string{"comment", "id", "rating", "user"}},
 			},
@@ -264,8 +290,6 @@ func TestLocalTypeFieldExtractor_GetAllNodes(t *testing.T) {
 			})
 	})
 	t.Run("extended interface", func(t *testing.T) {
-		t.Log("Bug: The concrete types that implement an interface should also be included")
-
 		run(t, `
 			extend type Query {
 				me: User
@@ -299,6 +323,7 @@ func TestLocalTypeFieldExtractor_GetAllNodes(t *testing.T) {
 		`,
 			[]TypeField{
 				{TypeName: "Comment", FieldNames: []string{"comment", "user"}},
+				{TypeName: "Communication", FieldNames: []string{"comment", "user"}},
 				{TypeName: "Query", FieldNames: []string{"communication", "me", "user"}},
 				{TypeName: "Review", FieldNames: []string{"comment", "rating", "user"}},
 			},
@@ -309,6 +334,36 @@ func TestLocalTypeFieldExtractor_GetAllNodes(t *testing.T) {
 				{TypeName: "User", FieldNames: []string{"communications", "id"}},
 			})
 	})
+	t.Run("possible types of interface with key directive", func(t *testing.T) {
+		run(t, `
+			extend interface Media @key(fields: "id") {
+				id: ID! @external
+				rating: Int
+			}
+
+			interface Video implements Media {
+				id: ID!
+				rating: Int
+			}
+
+			extend type Book implements Media {
+				id: ID! @external
+				rating: Int
+			}
+
+			type Movie implements Video & Media {
+				id: ID!
+				rating: Int
+			}
+		`,
+			[]TypeField{
+				{TypeName: "Book", FieldNames: []string{"rating"}},
+				{TypeName: "Media", FieldNames: []string{"rating"}},
+				{TypeName: "Movie", FieldNames: []string{"id", "rating"}},
+				{TypeName: "Video", FieldNames: []string{"id", "rating"}},
+			},
+			[]TypeField{})
+	})
 	t.Run("union", func(t *testing.T) {
 		run(t, `
 			extend type Query {
@@ -732,6 +787,101 @@ func TestLocalTypeFieldExtractor_GetAllNodes(t *testing.T) {
 				{TypeName: "Human", FieldNames: []string{"friends", "height", "name"}},
 			})
 	})
+	t.Run("interfaces implementing interfaces", func(t *testing.T) {
+		run(t, `
+			extend type Query {
+				node(id: ID!): Node
+			}
+
+			interface Node {
+				id: ID!
+			}
+
+			interface Character implements Node {
+				id: ID!
+				name: String!
+			}
+
+			type Human implements Character & Node {
+				id: ID!
+				name: String!
+				height: String!
+			}
+
+			type Droid implements Character {
+				id: ID!
+				name: String!
+				primaryFunction: String!
+			}
+		`,
+			[]TypeField{
+				{TypeName: "Query", FieldNames: []string{"node"}},
+			},
+			[]TypeField{
+				{TypeName: "Character", FieldNames: []string{"id", "name"}},
+				{TypeName: "Droid", FieldNames: []string{"id", "name", "primaryFunction"}},
+				{TypeName: "Human", FieldNames: []string{"height", "id", "name"}},
+				{TypeName: "Node", FieldNames: []string{"id"}},
+			})
+	})
+}
+
+func TestLocalTypeFieldExtractor_GetAllNodesWithReport(t *testing.T) {
+	run := func(t *testing.T, SDL string, expectedErrors ...string) {
+		t.Helper()
+
+		document := unsafeparser.ParseGraphqlDocumentString(SDL)
+		report := operationreport.Report{}
+		NewLocalTypeFieldExtractor(&document).GetAllNodesWithReport(&report)
+
+		var gotErrors []string
+		for _, externalError := range report.ExternalErrors {
+			gotErrors = append(gotErrors, externalError.Message)
+		}
+		assert.Equal(t, expectedErrors, gotErrors)
+	}
+
+	t.Run("valid document", func(t *testing.T) {
+		run(t, `
+			schema { query: Query }
+			extend type Query { me: User }
+			type User @key(fields: "id organization { id }") {
+				id: ID!
+				organization: Organization! @external
+				reviews: [Review] @requires(fields: "organization { id }")
+			}
+			type Organization { id: ID! }
+			type Review { body: String! author: User! @provides(fields: "id") }
+		`)
+	})
+
+	t.Run("undefined root operation types", func(t *testing.T) {
+		run(t, `
+			schema { query: RootQuery mutation: RootMutation }
+			type Query { me: User }
+			type User { id: ID! }
+		`,
+			"the query root operation type named 'RootQuery' is undefined",
+			"the mutation root operation type named 'RootMutation' is undefined",
+		)
+	})
+
+	t.Run("invalid field sets", func(t *testing.T) {
+		run(t, `
+			extend type Query { me: User }
+			type User @key(fields: "id {") {
+				id: ID!
+				organization: Organization! @external
+				reviews: [Review] @requires(fields: "organization { }")
+			}
+			type Organization { id: ID! }
+			type Review { body: String! author: User! @provides(fields: "") }
+		`,
+			`the fields argument of the @key directive of 'User' is invalid: invalid field set "id {": missing closing brace`,
+			`the fields argument of the @requires directive of 'User.reviews' is invalid: invalid field set "organization { }": empty selection at position 15`,
+			`the fields argument of the @provides directive of 'Review.author' is invalid: invalid field set "": empty field set`,
+		)
+	})
 }
 
 func BenchmarkGetAllNodes(b *testing.B) {
@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"reflect"
 	"regexp"
 	"strings"
 
+	"golang.org/x/exp/slices"
+
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
 	"github.com/wundergraph/graphql-go-tools/pkg/astimport"
 	"github.com/wundergraph/graphql-go-tools/pkg/astvisitor"
@@ -25,6 +28,8 @@ type Planner struct {
 	planningVisitor       *Visitor
 	requiredFieldsWalker  *astvisitor.Walker
 	requiredFieldsVisitor *requiredFieldsVisitor
+	skipIncludeWalker     *astvisitor.Walker
+	skipIncludeVisitor    *skipIncludeFoldingVisitor
 }
 
 type Configuration struct {
@@ -36,6 +41,37 @@ type Configuration struct {
 	// This setting removes position information from all fields
 	// In production, this should be set to false so that error messages are easier to understand
 	DisableResolveFieldPositions bool
+	// FoldSkipIncludeVariables removes selections skipped by @skip/@include directives with variables at planning time
+	// using the variable values (or default values) of the planned operation, so that they are not fetched from upstreams.
+	// The resulting plan is only valid for these variable values,
+	// so plans must not be cached by the operation alone but also by the values of these variables.
+	// Directives with literal values are always folded.
+	FoldSkipIncludeVariables bool
+	// DataSourceHealth reports the health of the DataSources, unhealthy Optional DataSources are excluded from plans.
+	// Plans depend on the health of the DataSources, so they must be invalidated once it changes.
+	DataSourceHealth DataSourceHealth
+	// ResolvedDirectives are the names of the executable directives which are applied to the values of fields
+	// when they are resolved, their arguments are rendered with the variables of the request, see resolve.FieldDirective
+	ResolvedDirectives []string
+	// FeatureFlags are the feature flags enabled for the planned operation, DataSources with a disabled FeatureFlag are excluded from plans.
+	// The resulting plan is only valid for these feature flags, see SetFeatureFlags.
+	FeatureFlags resolve.FeatureFlags
+}
+
+// isDisabledDataSource returns true for DataSources of which the FeatureFlag isn't enabled, see FeatureFlags
+func (c *Configuration) isDisabledDataSource(dataSource int) bool {
+	featureFlag := c.DataSources[dataSource].FeatureFlag
+	return featureFlag != "" && !c.FeatureFlags.Enabled(featureFlag)
+}
+
+// IsResolvedDirective returns true if the directive is applied when fields are resolved, see ResolvedDirectives
+func (c *Configuration) IsResolvedDirective(directiveName string) bool {
+	for i := range c.ResolvedDirectives {
+		if c.ResolvedDirectives[i] == directiveName {
+			return true
+		}
+	}
+	return false
 }
 
 type DirectiveConfigurations []DirectiveConfiguration
@@ -66,25 +102,38 @@ type DirectiveConfiguration struct {
 
 type TypeConfigurations []TypeConfiguration
 
+// RenameTypeNameOnMatchStr returns the RenameTo of the configuration of the type or the type name itself.
+// Configurations without RenameTo don't rename the type, as a type might be configured for its TypeNameResolver only.
 func (t *TypeConfigurations) RenameTypeNameOnMatchStr(typeName string) string {
 	for i := range *t {
-		if (*t)[i].TypeName == typeName {
+		if (*t)[i].TypeName == typeName && (*t)[i].RenameTo != "" {
 			return (*t)[i].RenameTo
 		}
 	}
 	return typeName
 }
 
+// RenameTypeNameOnMatchBytes is the []byte variant of RenameTypeNameOnMatchStr
 func (t *TypeConfigurations) RenameTypeNameOnMatchBytes(typeName []byte) []byte {
 	str := string(typeName)
 	for i := range *t {
-		if (*t)[i].TypeName == str {
+		if (*t)[i].TypeName == str && (*t)[i].RenameTo != "" {
 			return []byte((*t)[i].RenameTo)
 		}
 	}
 	return typeName
 }
 
+// TypeNameResolver returns the configured resolve.TypeNameResolver for the abstract type or nil
+func (t *TypeConfigurations) TypeNameResolver(typeName string) resolve.TypeNameResolver {
+	for i := range *t {
+		if (*t)[i].TypeName == typeName && (*t)[i].TypeNameResolver != nil {
+			return (*t)[i].TypeNameResolver
+		}
+	}
+	return nil
+}
+
 type TypeConfiguration struct {
 	TypeName string
 	// RenameTo modifies the TypeName
@@ -93,7 +142,12 @@ type TypeConfiguration struct {
 	// the upstream Operation can be rewritten to { ... on Human { height }}
 	// by setting RenameTo to Human
 	// This way, Types can be suffixed / renamed in downstream Schemas while keeping the contract with the upstream ok
+	// If RenameTo is empty, the type keeps its name
 	RenameTo string
+	// TypeNameResolver determines the concrete type of values of an abstract type (interface or union)
+	// if the upstream response doesn't contain a __typename field, e.g. when the datasource is a REST API.
+	// It's ignored for object types.
+	TypeNameResolver resolve.TypeNameResolver
 }
 
 type FieldConfigurations []FieldConfiguration
@@ -113,6 +167,9 @@ type FieldConfiguration struct {
 	// DisableDefaultMapping - instructs planner whether to use path mapping coming from Path field
 	DisableDefaultMapping bool
 	// Path - represents a json path to lookup for a field value in response json
+	// Segments can select items of arrays, e.g. data.items[0].id is []string{"data", "items[0]", "id"},
+	// the wildcard "[*]" and slices like "[1:3]" return the values of the rest of the path in the selected items as a list,
+	// e.g. []string{"results[*]", "node"} for a list field
 	Path           []string
 	Arguments      ArgumentsConfigurations
 	RequiresFields []string
@@ -121,6 +178,73 @@ type FieldConfiguration struct {
 	// e.g. {"response":"{\"foo\":\"bar\"}"} will be returned as {"foo":"bar"} when path is "response"
 	// This way, it is possible to resolve a JSON string as part of the response without extra String encoding of the JSON
 	UnescapeResponseJson bool
+	// SkipFetch skips the fetch of a DataSource for the field if one of the conditions is met,
+	// e.g. to not call an expensive DataSource for an optional enrichment unless an argument is provided.
+	// It only applies to root fields of a DataSource, which are then always planned as a separate fetch.
+	SkipFetch *SkipFetchConfiguration
+	// Optional marks the fetch of the field as non critical: if it fails, the fields resolved by the fetch are null
+	// and the errors are added as warnings to the extensions of the response, see DataSourceConfiguration.Optional.
+	// It only applies to root fields of a DataSource, which are then always planned as a separate fetch.
+	Optional bool
+	// Fallback is the JSON value of the nullable field if it resolves to null,
+	// e.g. because the upstream returned null or an error for it or an Optional fetch failed.
+	// It takes precedence over the @fallback(value:) directive on the field definition, see FallbackDirectiveName,
+	// and has the same restrictions: the field must be of a scalar or enum type and the value valid for the type.
+	Fallback json.RawMessage
+	// Computed resolves the field from an expression over its sibling fields instead of a DataSource,
+	// it must not be a root or child node of a DataSource
+	Computed *ComputedFieldConfiguration
+}
+
+// ComputedFieldConfiguration derives the value of a field from its sibling fields,
+// e.g. "{{ .firstName }} {{ .lastName }}" for a fullName field.
+// The sibling fields referenced by the expression are requested from the DataSources, like RequiresFields,
+// so they don't need to be selected. Only the values of scalar fields can be referenced this way.
+// Fields of type String, ID or an enum are rendered as string, the expression of other types has to render a valid JSON value.
+// The field is null if one of the referenced fields is null.
+type ComputedFieldConfiguration struct {
+	Expression string
+}
+
+// RequiredFields returns the names of the sibling fields referenced by the expression
+func (c *ComputedFieldConfiguration) RequiredFields() []string {
+	var fields []string
+	for _, selector := range selectorRegex.FindAllStringSubmatch(c.Expression, -1) {
+		fieldName := strings.Split(selector[1], ".")[0]
+		if fieldName == "" || slices.Contains(fields, fieldName) {
+			continue
+		}
+		fields = append(fields, fieldName)
+	}
+	return fields
+}
+
+// FallbackDirectiveName is the name of the directive supplying the Fallback of a field in the schema,
+// e.g. `rating: Float @fallback(value: 0)`. The directive has to be declared by the schema
+// with an argument of a custom scalar, so that values of any type can be passed:
+//
+//	scalar FallbackValue
+//	directive @fallback(value: FallbackValue!) on FIELD_DEFINITION
+//
+// The value is rendered as JSON, e.g. @fallback(value: []) for a list.
+// Fallbacks are only supported for fields of scalar and enum types, or lists of them,
+// and the value has to be valid for the type of the field.
+const FallbackDirectiveName = "fallback"
+
+type SkipFetchConfiguration struct {
+	// Conditions - the fetch is skipped if any of the conditions is met
+	Conditions []SkipFetchCondition
+	// DefaultValue is the JSON value of the field if the fetch is skipped, null if empty.
+	// Non-null fields require a DefaultValue, the field is rejected at planning time otherwise.
+	DefaultValue json.RawMessage
+}
+
+type SkipFetchCondition struct {
+	// ArgumentName is the name of the field argument the condition applies to
+	ArgumentName string
+	// Equals skips the fetch if the argument equals the JSON value
+	// If empty, the fetch is skipped if the argument is absent or null
+	Equals json.RawMessage
 }
 
 type ArgumentsConfigurations []ArgumentConfiguration
@@ -163,9 +287,39 @@ type DataSourceConfiguration struct {
 	// They are always required for the Graphql datasources cause each field could have it's own datasource
 	// For any single point datasource like HTTP/REST or GRPC we could not request less fields, as we always get a full response
 	ChildNodes []TypeField
+	// Entities - defines the types for which the DataSource resolves all of its root fields on the same object with a single fetch
+	// e.g. a REST endpoint returning a user, templated with the key fields of the entity: https://example.com/users/{{ .object.id }}
+	// This allows non GraphQL DataSources to resolve fields of an entity owned by another DataSource, like a federated subgraph.
+	// The key fields are added to the selection set of the enclosing object, so that they are available to the fetch.
+	Entities   []EntityConfiguration
 	Directives DirectiveConfigurations
 	Factory    PlannerFactory
 	Custom     json.RawMessage
+	// Optional DataSources are excluded from plans while they are unhealthy according to the DataSourceHealth of the Configuration,
+	// their fetches are skipped and their fields resolve to the SkipFetch.DefaultValue of their FieldConfiguration or null.
+	// Required (non optional) DataSources are always planned, but make the engine unready while they are unhealthy.
+	// Failed fetches of Optional DataSources don't fail the response, their fields resolve to null
+	// and the errors are added as warnings to the extensions of the response.
+	Optional bool
+	// HealthCheck configures periodic health checks of the upstream of the DataSource
+	HealthCheck *HealthCheckConfiguration
+	// FeatureFlag restricts the DataSource to operations planned with the feature flag enabled, see Configuration.FeatureFlags.
+	// The first DataSource with a root node is planned, so a new DataSource is rolled out by listing it before the DataSource it replaces.
+	FeatureFlag string
+}
+
+type EntityConfiguration struct {
+	TypeName  string
+	KeyFields []string
+}
+
+func (d *DataSourceConfiguration) EntityKeyFields(typeName string) (keyFields []string, ok bool) {
+	for i := range d.Entities {
+		if d.Entities[i].TypeName == typeName {
+			return d.Entities[i].KeyFields, true
+		}
+	}
+	return nil, false
 }
 
 func (d *DataSourceConfiguration) HasRootNode(typeName, fieldName string) bool {
@@ -225,12 +379,25 @@ func NewPlanner(ctx context.Context, config Configuration) *Planner {
 	requiredFieldsWalker.RegisterEnterOperationVisitor(requiredFieldsV)
 	requiredFieldsWalker.RegisterEnterFieldVisitor(requiredFieldsV)
 
+	// skip/include folding
+
+	skipIncludeWalker := astvisitor.NewWalker(48)
+	skipIncludeV := &skipIncludeFoldingVisitor{
+		walker: &skipIncludeWalker,
+	}
+
+	skipIncludeWalker.RegisterEnterDocumentVisitor(skipIncludeV)
+	skipIncludeWalker.RegisterEnterDirectiveVisitor(skipIncludeV)
+
 	// configuration
 
+	abstractTypes := newAbstractTypeIndex()
+
 	configurationWalker := astvisitor.NewWalker(48)
 	configVisitor := &configurationVisitor{
-		walker: &configurationWalker,
-		ctx:    ctx,
+		walker:        &configurationWalker,
+		abstractTypes: abstractTypes,
+		ctx:           ctx,
 	}
 
 	configurationWalker.RegisterEnterDocumentVisitor(configVisitor)
@@ -245,6 +412,7 @@ func NewPlanner(ctx context.Context, config Configuration) *Planner {
 		Walker:                       &planningWalker,
 		fieldConfigs:                 map[int]*FieldConfiguration{},
 		disableResolveFieldPositions: config.DisableResolveFieldPositions,
+		abstractTypes:                abstractTypes,
 	}
 
 	p := &Planner{
@@ -255,6 +423,8 @@ func NewPlanner(ctx context.Context, config Configuration) *Planner {
 		planningVisitor:       planningVisitor,
 		requiredFieldsWalker:  &requiredFieldsWalker,
 		requiredFieldsVisitor: requiredFieldsV,
+		skipIncludeWalker:     &skipIncludeWalker,
+		skipIncludeVisitor:    skipIncludeV,
 	}
 
 	return p
@@ -264,6 +434,11 @@ func (p *Planner) SetConfig(config Configuration) {
 	p.config = config
 }
 
+// SetFeatureFlags sets the feature flags enabled for the operations planned next, see Configuration.FeatureFlags
+func (p *Planner) SetFeatureFlags(flags resolve.FeatureFlags) {
+	p.config.FeatureFlags = flags
+}
+
 func (p *Planner) Plan(operation, definition *ast.Document, operationName string, report *operationreport.Report) (plan Plan) {
 
 	// make a copy of the config as the pre-processor modifies it
@@ -277,12 +452,22 @@ func (p *Planner) Plan(operation, definition *ast.Document, operationName string
 		return
 	}
 
+	// remove selections which are skipped regardless of the response,
+	// the folded selections are put back once the operation is planned as the operation is owned by the caller
+
+	p.foldSkipInclude(&config, operation, definition, report)
+	defer p.skipIncludeVisitor.restore()
+	if report.HasErrors() {
+		return
+	}
+
 	// pre-process required fields
 
 	p.preProcessRequiredFields(&config, operation, definition, report)
 
 	// find planning paths
 
+	p.configurationVisitor.abstractTypes.reset(definition)
 	p.configurationVisitor.config = config
 	p.configurationWalker.Walk(operation, definition, report)
 
@@ -308,7 +493,9 @@ func (p *Planner) Plan(operation, definition *ast.Document, operationName string
 		isNested := p.planningVisitor.planners[key].isNestedPlanner()
 		err := p.planningVisitor.planners[key].planner.Register(p.planningVisitor, config, isNested)
 		if err != nil {
-			p.planningWalker.StopWithInternalErr(err)
+			// the walker isn't walking yet, so the error is reported directly
+			report.AddInternalError(err)
+			return
 		}
 	}
 
@@ -342,6 +529,11 @@ func (p *Planner) selectOperation(operation *ast.Document, operationName string,
 	p.planningVisitor.OperationName = operationName
 }
 
+func (p *Planner) foldSkipInclude(config *Configuration, operation, definition *ast.Document, report *operationreport.Report) {
+	p.skipIncludeVisitor.foldVariables = config.FoldSkipIncludeVariables
+	p.skipIncludeWalker.Walk(operation, definition, report)
+}
+
 func (p *Planner) preProcessRequiredFields(config *Configuration, operation, definition *ast.Document, report *operationreport.Report) {
 	if !p.hasRequiredFields(config) {
 		return
@@ -359,6 +551,11 @@ func (p *Planner) hasRequiredFields(config *Configuration) bool {
 			return true
 		}
 	}
+	for i := range config.DataSources {
+		if len(config.DataSources[i].Entities) != 0 {
+			return true
+		}
+	}
 	return false
 }
 
@@ -381,6 +578,7 @@ type Visitor struct {
 	exportedVariables            map[string]struct{}
 	skipIncludeFields            map[int]skipIncludeField
 	disableResolveFieldPositions bool
+	abstractTypes                *abstractTypeIndex
 }
 
 type skipIncludeField struct {
@@ -403,6 +601,11 @@ type objectFetchConfiguration struct {
 	isSubscription     bool
 	fieldRef           int
 	fieldDefinitionRef int
+	// onTypeNames contains the member types of the abstract parent the fetch is responsible for
+	// nil means the fetch is applicable to all objects
+	onTypeNames    [][]byte
+	skipConditions resolve.SkipConditions
+	optional       bool
 }
 
 func (v *Visitor) AllowVisitor(kind astvisitor.VisitorKind, ref int, visitor interface{}) bool {
@@ -418,13 +621,23 @@ func (v *Visitor) AllowVisitor(kind astvisitor.VisitorKind, ref int, visitor int
 	if !strings.Contains(path, ".") {
 		return true
 	}
-	for _, config := range v.planners {
-		if config.planner == visitor && config.hasPath(path) {
+	for i := range v.planners {
+		config := &v.planners[i]
+		if config.isPlannerOf(visitor) && config.hasPath(path) {
 			switch kind {
 			case astvisitor.EnterField, astvisitor.LeaveField:
-				return config.shouldWalkFieldsOnPath(path)
+				return config.shouldWalkFieldsOnPath(path) && v.isFieldOfPlanner(config, v.Walker.Path.DotDelimitedString(), v.Walker.EnclosingTypeDefinition.NameString(v.Definition), ref)
 			case astvisitor.EnterSelectionSet, astvisitor.LeaveSelectionSet:
-				return !config.isExitPath(path)
+				if config.isExitPath(path) {
+					return false
+				}
+				ancestor := v.Walker.Ancestors[len(v.Walker.Ancestors)-1]
+				if ancestor.Kind == ast.NodeKindInlineFragment {
+					return v.isInlineFragmentOfPlanner(config, path, ancestor.Ref)
+				}
+				return true
+			case astvisitor.EnterInlineFragment, astvisitor.LeaveInlineFragment:
+				return v.isInlineFragmentOfPlanner(config, path, ref)
 			default:
 				return true
 			}
@@ -433,6 +646,66 @@ func (v *Visitor) AllowVisitor(kind astvisitor.VisitorKind, ref int, visitor int
 	return false
 }
 
+// isAbstractRootPath returns true if the path is the parent path of a nested planner with an abstract parent type,
+// e.g. the items of a list of unions. The planner walks into the inline fragments but not into the fields on this path.
+func (p *plannerConfiguration) isAbstractRootPath(path string) bool {
+	return p.hasPath(path) && !p.shouldWalkFieldsOnPath(path)
+}
+
+// isFieldOfPlanner returns false for fields directly below an abstract root path
+// which the data source of the planner doesn't provide for the enclosing type.
+// Members of an abstract type might be resolved by different data sources,
+// so fields sharing the same path in sibling inline fragments must only be walked by their own planner.
+func (v *Visitor) isFieldOfPlanner(config *plannerConfiguration, parentPath, enclosingTypeName string, fieldRef int) bool {
+	if !config.isAbstractRootPath(parentPath) {
+		return true
+	}
+	fieldName := v.Operation.FieldNameString(fieldRef)
+	if fieldName == "__typename" {
+		return true
+	}
+	return config.hasRootNode(enclosingTypeName, fieldName) || config.hasAbstractChildNode(v.abstractTypes, enclosingTypeName, fieldName)
+}
+
+// isInlineFragmentOfPlanner returns false for inline fragments on an abstract root path which don't contain any field of the planner,
+// so that a planner doesn't render inline fragments on types resolved by other data sources.
+func (v *Visitor) isInlineFragmentOfPlanner(config *plannerConfiguration, path string, inlineFragmentRef int) bool {
+	if !config.isAbstractRootPath(path) {
+		return true
+	}
+	enclosingTypeName := v.Walker.EnclosingTypeDefinition.NameString(v.Definition)
+	return v.inlineFragmentHasFieldOfPlanner(config, path, enclosingTypeName, inlineFragmentRef)
+}
+
+func (v *Visitor) inlineFragmentHasFieldOfPlanner(config *plannerConfiguration, path, enclosingTypeName string, inlineFragmentRef int) bool {
+	inlineFragment := v.Operation.InlineFragments[inlineFragmentRef]
+	if !inlineFragment.HasSelections {
+		return false
+	}
+	if typeCondition := v.Operation.InlineFragmentTypeConditionNameString(inlineFragmentRef); typeCondition != "" {
+		enclosingTypeName = typeCondition
+	}
+	for _, selectionRef := range v.Operation.SelectionSets[inlineFragment.SelectionSet].SelectionRefs {
+		selection := v.Operation.Selections[selectionRef]
+		switch selection.Kind {
+		case ast.SelectionKindField:
+			fieldName := v.Operation.FieldNameString(selection.Ref)
+			if fieldName == "__typename" {
+				continue
+			}
+			fieldPath := path + "." + v.Operation.FieldAliasOrNameString(selection.Ref)
+			if config.hasPath(fieldPath) && v.isFieldOfPlanner(config, path, enclosingTypeName, selection.Ref) {
+				return true
+			}
+		case ast.SelectionKindInlineFragment:
+			if v.inlineFragmentHasFieldOfPlanner(config, path, enclosingTypeName, selection.Ref) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func (v *Visitor) currentFullPath() string {
 	path := v.Walker.Path.DotDelimitedString()
 	if v.Walker.CurrentKind == ast.NodeKindField {
@@ -521,20 +794,34 @@ func (v *Visitor) EnterField(ref int) {
 	fieldName := v.Operation.FieldNameBytes(ref)
 	fieldAliasOrName := v.Operation.FieldAliasOrNameBytes(ref)
 	if bytes.Equal(fieldName, literal.TYPENAME) {
+		var value resolve.Node = &resolve.String{
+			Nullable:   false,
+			Path:       []string{"__typename"},
+			IsTypeName: true,
+		}
+		enclosingTypeName := v.Walker.EnclosingTypeDefinition.NameBytes(v.Definition)
+		if hasStaticTypeName(v.Definition, enclosingTypeName) {
+			value = &resolve.Computed{
+				Segments:       []resolve.ComputedSegment{{Data: enclosingTypeName}},
+				RenderAsString: true,
+			}
+		}
+		onTypeName, onTypeNames := v.resolveOnTypeNames()
 		v.currentField = &resolve.Field{
-			Name: fieldAliasOrName,
-			Value: &resolve.String{
-				Nullable:   false,
-				Path:       []string{"__typename"},
-				IsTypeName: true,
-			},
-			OnTypeName:              v.resolveOnTypeName(),
+			Name:                    fieldAliasOrName,
+			Value:                   value,
+			OnTypeName:              onTypeName,
+			OnTypeNames:             onTypeNames,
 			Position:                v.resolveFieldPosition(ref),
 			SkipDirectiveDefined:    skip,
 			SkipVariableName:        skipVariableName,
 			IncludeDirectiveDefined: include,
 			IncludeVariableName:     includeVariableName,
 		}
+		if existing := v.typeNameFieldWithSameCondition(v.currentField); existing != nil {
+			v.currentField = existing
+			return
+		}
 		*v.currentFields[len(v.currentFields)-1].fields = append(*v.currentFields[len(v.currentFields)-1].fields, v.currentField)
 		return
 	}
@@ -566,20 +853,36 @@ func (v *Visitor) EnterField(ref int) {
 	}
 
 	path := v.resolveFieldPath(ref)
-	fieldDefinitionType := v.Definition.FieldDefinitionType(fieldDefinition)
+	fieldDefinitionType, nullable := v.resolveFieldNullability(ref, v.Definition.FieldDefinitionType(fieldDefinition))
 	bufferID, hasBuffer := v.fieldBuffers[ref]
 
+	var fetchSkippedValue []byte
+	if hasFetchConfig {
+		// unless the client changed the nullability of the field, the type of the definition is returned as is
+		resolvesNullable := nullable && v.Definition.Types[fieldDefinitionType].TypeKind != ast.TypeKindNonNull
+		fetchSkippedValue = v.fetchSkippedValue(ref, resolvesNullable, v.fetchConfigurations[i].skipConditions)
+	}
+	var fallbackValue []byte
+	if nullable {
+		fallbackValue = v.fallbackValue(ref, fieldDefinition)
+	}
+	onTypeName, onTypeNames := v.resolveOnTypeNames()
+
 	v.currentField = &resolve.Field{
 		Name:                    fieldAliasOrName,
-		Value:                   v.resolveFieldValue(ref, fieldDefinitionType, true, path),
+		Value:                   v.resolveFieldValue(ref, fieldDefinitionType, nullable, path),
 		HasBuffer:               hasBuffer,
 		BufferID:                bufferID,
-		OnTypeName:              v.resolveOnTypeName(),
+		OnTypeName:              onTypeName,
+		OnTypeNames:             onTypeNames,
 		Position:                v.resolveFieldPosition(ref),
 		SkipDirectiveDefined:    skip,
 		SkipVariableName:        skipVariableName,
 		IncludeDirectiveDefined: include,
 		IncludeVariableName:     includeVariableName,
+		FetchSkippedValue:       fetchSkippedValue,
+		FallbackValue:           fallbackValue,
+		Directives:              v.resolveFieldDirectives(ref),
 	}
 
 	*v.currentFields[len(v.currentFields)-1].fields = append(*v.currentFields[len(v.currentFields)-1].fields, v.currentField)
@@ -593,6 +896,234 @@ func (v *Visitor) EnterField(ref int) {
 	v.fieldConfigs[ref] = fieldConfig
 }
 
+// resolveFieldNullability applies the client controlled nullability designator of the field to the type of the field,
+// the designator replaces the nullability of the type in the schema, the nullability of list items is kept
+func (v *Visitor) resolveFieldNullability(fieldRef, typeRef int) (fieldType int, nullable bool) {
+	switch v.Operation.Fields[fieldRef].Nullability {
+	case ast.NullabilityRequired:
+		nullable = false
+	case ast.NullabilityOptional:
+		nullable = true
+	default:
+		return typeRef, true
+	}
+	if v.Definition.Types[typeRef].TypeKind == ast.TypeKindNonNull {
+		typeRef = v.Definition.Types[typeRef].OfType
+	}
+	return typeRef, nullable
+}
+
+// resolveFieldDirectives returns the directives of the field which are applied when the field is resolved,
+// see Configuration.ResolvedDirectives
+func (v *Visitor) resolveFieldDirectives(ref int) []resolve.FieldDirective {
+	if len(v.Config.ResolvedDirectives) == 0 || !v.Operation.Fields[ref].HasDirectives {
+		return nil
+	}
+	var directives []resolve.FieldDirective
+	for _, directiveRef := range v.Operation.Fields[ref].Directives.Refs {
+		directiveName := v.Operation.DirectiveNameString(directiveRef)
+		if !v.Config.IsResolvedDirective(directiveName) {
+			continue
+		}
+		segments := []resolve.TemplateSegment{staticSegment("{")}
+		for i, argumentRef := range v.Operation.Directives[directiveRef].Arguments.Refs {
+			if i != 0 {
+				segments = append(segments, staticSegment(","))
+			}
+			segments = append(segments, staticSegment(`"`+v.Operation.ArgumentNameString(argumentRef)+`":`))
+			segments = v.appendValueSegments(segments, v.Operation.ArgumentValue(argumentRef))
+		}
+		directives = append(directives, resolve.FieldDirective{
+			Name: directiveName,
+			Arguments: resolve.InputTemplate{
+				Segments: append(segments, staticSegment("}")),
+			},
+		})
+	}
+	return directives
+}
+
+// appendValueSegments appends the template segments rendering the value as JSON,
+// variables, also nested in list and object values, are rendered from the variables of the request
+func (v *Visitor) appendValueSegments(segments []resolve.TemplateSegment, value ast.Value) []resolve.TemplateSegment {
+	switch value.Kind {
+	case ast.ValueKindVariable:
+		variable := &resolve.ContextVariable{
+			Path:     []string{v.Operation.VariableValueNameString(value.Ref)},
+			Renderer: resolve.NewJSONVariableRenderer(),
+		}
+		return append(segments, variable.TemplateSegment())
+	case ast.ValueKindList:
+		segments = append(segments, staticSegment("["))
+		for i, ref := range v.Operation.ListValues[value.Ref].Refs {
+			if i != 0 {
+				segments = append(segments, staticSegment(","))
+			}
+			segments = v.appendValueSegments(segments, v.Operation.Values[ref])
+		}
+		return append(segments, staticSegment("]"))
+	case ast.ValueKindObject:
+		segments = append(segments, staticSegment("{"))
+		for i, ref := range v.Operation.ObjectValues[value.Ref].Refs {
+			if i != 0 {
+				segments = append(segments, staticSegment(","))
+			}
+			segments = append(segments, staticSegment(`"`+v.Operation.ObjectFieldNameString(ref)+`":`))
+			segments = v.appendValueSegments(segments, v.Operation.ObjectFieldValue(ref))
+		}
+		return append(segments, staticSegment("}"))
+	default:
+		data, err := v.Operation.ValueToJSON(value)
+		if err != nil {
+			v.Walker.StopWithInternalErr(err)
+			return segments
+		}
+		return append(segments, resolve.TemplateSegment{
+			SegmentType: resolve.StaticSegmentType,
+			Data:        data,
+		})
+	}
+}
+
+func staticSegment(data string) resolve.TemplateSegment {
+	return resolve.TemplateSegment{
+		SegmentType: resolve.StaticSegmentType,
+		Data:        []byte(data),
+	}
+}
+
+// fetchSkippedValue returns the SkipFetch.DefaultValue of the root field of a fetch, nil if the fetch is never skipped.
+// Non-null fields without a DefaultValue would resolve to null if the fetch is skipped,
+// so their configuration is rejected regardless of whether the planned operation meets the conditions.
+func (v *Visitor) fetchSkippedValue(ref int, nullable bool, skipConditions resolve.SkipConditions) []byte {
+	typeName := v.Walker.EnclosingTypeDefinition.NameString(v.Definition)
+	fieldName := v.Operation.FieldNameString(ref)
+	fieldConfig := v.Config.Fields.ForTypeField(typeName, fieldName)
+	if fieldConfig == nil || fieldConfig.SkipFetch == nil {
+		return nil
+	}
+	if !nullable && len(fieldConfig.SkipFetch.DefaultValue) == 0 {
+		v.Walker.StopWithInternalErr(fmt.Errorf("invalid skip fetch configuration of field %s.%s: non-null fields require a DefaultValue", typeName, fieldName))
+		return nil
+	}
+	if len(skipConditions) == 0 {
+		return nil
+	}
+	return fieldConfig.SkipFetch.DefaultValue
+}
+
+// fallbackValue returns the Fallback of the FieldConfiguration or the JSON value of the @fallback directive of the field definition
+func (v *Visitor) fallbackValue(ref, fieldDefinition int) []byte {
+	typeName := v.Walker.EnclosingTypeDefinition.NameString(v.Definition)
+	fieldName := v.Operation.FieldNameString(ref)
+	fallback, err := v.fallbackValueOfDefinition(typeName, fieldName, fieldDefinition)
+	if err == nil && len(fallback) != 0 {
+		err = v.validateFallbackValue(fallback, v.Definition.FieldDefinitionType(fieldDefinition))
+	}
+	if err != nil {
+		v.Walker.StopWithInternalErr(fmt.Errorf("invalid fallback of field %s.%s: %w", typeName, fieldName, err))
+		return nil
+	}
+	return fallback
+}
+
+func (v *Visitor) fallbackValueOfDefinition(typeName, fieldName string, fieldDefinition int) ([]byte, error) {
+	fieldConfig := v.Config.Fields.ForTypeField(typeName, fieldName)
+	if fieldConfig != nil && len(fieldConfig.Fallback) != 0 {
+		return fieldConfig.Fallback, nil
+	}
+	directive, ok := v.Definition.FieldDefinitionDirectiveByName(fieldDefinition, []byte(FallbackDirectiveName))
+	if !ok {
+		return nil, nil
+	}
+	value, ok := v.Definition.DirectiveArgumentValueByName(directive, []byte("value"))
+	if !ok {
+		return nil, nil
+	}
+	return v.Definition.ValueToJSON(value)
+}
+
+// validateFallbackValue returns an error if the JSON value isn't valid for the type,
+// fallbacks are rendered as they are, so they are limited to scalars and enums
+func (v *Visitor) validateFallbackValue(value []byte, typeRef int) error {
+	var decoded interface{}
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(&decoded); err != nil {
+		return err
+	}
+	return v.validateFallbackValueOfType(decoded, typeRef)
+}
+
+func (v *Visitor) validateFallbackValueOfType(value interface{}, typeRef int) error {
+	graphqlType := v.Definition.Types[typeRef]
+	if graphqlType.TypeKind == ast.TypeKindNonNull {
+		if value == nil {
+			return errors.New("null for a non-null type")
+		}
+		return v.validateFallbackValueOfType(value, graphqlType.OfType)
+	}
+	if value == nil {
+		return nil
+	}
+	if graphqlType.TypeKind == ast.TypeKindList {
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%v is not a list", value)
+		}
+		for _, item := range items {
+			if err := v.validateFallbackValueOfType(item, graphqlType.OfType); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	typeName := v.Definition.TypeNameString(typeRef)
+	var valid bool
+	switch typeName {
+	case "String":
+		_, valid = value.(string)
+	case "ID":
+		switch id := value.(type) {
+		case string:
+			valid = true
+		case json.Number:
+			_, err := id.Int64()
+			valid = err == nil
+		}
+	case "Int":
+		number, ok := value.(json.Number)
+		if ok {
+			_, err := number.Int64()
+			valid = err == nil
+		}
+	case "Float":
+		_, valid = value.(json.Number)
+	case "Boolean":
+		_, valid = value.(bool)
+	default:
+		node, ok := v.Definition.Index.FirstNonExtensionNodeByNameStr(typeName)
+		if !ok {
+			return fmt.Errorf("unknown type %s", typeName)
+		}
+		switch node.Kind {
+		case ast.NodeKindScalarTypeDefinition:
+			// custom scalars accept any value
+			return nil
+		case ast.NodeKindEnumTypeDefinition:
+			enumValue, ok := value.(string)
+			valid = ok && v.Definition.EnumTypeDefinitionContainsEnumValue(node.Ref, []byte(enumValue))
+		default:
+			return fmt.Errorf("fallbacks are only supported for scalar and enum types, not for %s", typeName)
+		}
+	}
+	if !valid {
+		return fmt.Errorf("%v is not a valid %s", value, typeName)
+	}
+	return nil
+}
+
 func (v *Visitor) resolveFieldPosition(ref int) resolve.Position {
 	if v.disableResolveFieldPositions {
 		return resolve.Position{}
@@ -647,16 +1178,99 @@ func (v *Visitor) resolveInclude(directiveRefs []int) (bool, string) {
 	return false, ""
 }
 
-func (v *Visitor) resolveOnTypeName() []byte {
+// resolveOnTypeNames returns the type condition of the enclosing inline fragment of a field.
+// The type condition of a fragment on an abstract type is the list of its possible types,
+// as the __typename of the objects is always the name of an object type.
+func (v *Visitor) resolveOnTypeNames() (onTypeName []byte, onTypeNames [][]byte) {
 	if len(v.Walker.Ancestors) < 2 {
-		return nil
+		return nil, nil
 	}
 	inlineFragment := v.Walker.Ancestors[len(v.Walker.Ancestors)-2]
 	if inlineFragment.Kind != ast.NodeKindInlineFragment {
-		return nil
+		return nil, nil
 	}
 	typeName := v.Operation.InlineFragmentTypeConditionName(inlineFragment.Ref)
-	return v.Config.Types.RenameTypeNameOnMatchBytes(typeName)
+	typeCondition, ok := v.Definition.Index.FirstNonExtensionNodeByNameBytes(typeName)
+	if !ok || !typeCondition.Kind.IsAbstractType() {
+		return v.Config.Types.RenameTypeNameOnMatchBytes(typeName), nil
+	}
+	possibleTypeNames := v.possibleTypeNames(typeCondition)
+	onTypeNames = make([][]byte, 0, len(possibleTypeNames))
+	for i := range possibleTypeNames {
+		onTypeNames = append(onTypeNames, v.Config.Types.RenameTypeNameOnMatchBytes(possibleTypeNames[i]))
+	}
+	return nil, onTypeNames
+}
+
+// possibleTypeNames returns the names of the object types implementing the interface or being members of the union, in the order of the schema
+func (v *Visitor) possibleTypeNames(abstractType ast.Node) (typeNames [][]byte) {
+	return possibleTypeNames(v.Definition, abstractType)
+}
+
+func possibleTypeNames(definition *ast.Document, abstractType ast.Node) (typeNames [][]byte) {
+	switch abstractType.Kind {
+	case ast.NodeKindInterfaceTypeDefinition:
+		interfaceName := definition.InterfaceTypeDefinitionNameBytes(abstractType.Ref)
+		for i := range definition.ObjectTypeDefinitions {
+			if definition.ObjectTypeDefinitionImplementsInterface(i, interfaceName) {
+				typeNames = append(typeNames, definition.ObjectTypeDefinitionNameBytes(i))
+			}
+		}
+	case ast.NodeKindUnionTypeDefinition:
+		for _, ref := range definition.NodeUnionMemberRefs(abstractType) {
+			typeNames = append(typeNames, definition.TypeNameBytes(ref))
+		}
+	}
+	return typeNames
+}
+
+// typeNameFieldWithSameCondition returns the __typename field with the same response key of the current object
+// which is resolved for at least the same objects as the given field, e.g. if __typename is selected on the object
+// and in a fragment of it, so that the __typename is rendered once
+func (v *Visitor) typeNameFieldWithSameCondition(field *resolve.Field) *resolve.Field {
+	if field.SkipDirectiveDefined || field.IncludeDirectiveDefined {
+		return nil
+	}
+	fields := *v.currentFields[len(v.currentFields)-1].fields
+	for _, existing := range fields {
+		if !bytes.Equal(existing.Name, field.Name) || existing.SkipDirectiveDefined || existing.IncludeDirectiveDefined {
+			continue
+		}
+		if !isTypeNameValue(existing.Value) {
+			continue
+		}
+		if existing.OnTypeName == nil && len(existing.OnTypeNames) == 0 {
+			return existing
+		}
+		if field.OnTypeName == nil && len(field.OnTypeNames) == 0 {
+			// the field is resolved for all objects, so the type condition of the existing field is dropped
+			existing.OnTypeName, existing.OnTypeNames = nil, nil
+			return existing
+		}
+		if bytes.Equal(existing.OnTypeName, field.OnTypeName) && reflect.DeepEqual(existing.OnTypeNames, field.OnTypeNames) {
+			return existing
+		}
+	}
+	return nil
+}
+
+func isTypeNameValue(value resolve.Node) bool {
+	switch value := value.(type) {
+	case *resolve.String:
+		return value.IsTypeName
+	case *resolve.Computed:
+		return len(value.Segments) == 1 && value.Segments[0].Path == nil
+	}
+	return false
+}
+
+// hasStaticTypeName returns true if the __typename of the type is known at plan time, so it isn't fetched from a data source.
+// These are the query, mutation and subscription types of the schema and the introspection types, e.g. __Type.
+func hasStaticTypeName(definition *ast.Document, typeName []byte) bool {
+	return bytes.HasPrefix(typeName, []byte("__")) ||
+		bytes.Equal(typeName, definition.Index.QueryTypeName) ||
+		bytes.Equal(typeName, definition.Index.MutationTypeName) ||
+		bytes.Equal(typeName, definition.Index.SubscriptionTypeName)
 }
 
 func (v *Visitor) LeaveField(ref int) {
@@ -684,6 +1298,49 @@ func (v *Visitor) skipField(ref int) bool {
 	return false
 }
 
+// resolveComputedFieldValue compiles the expression of a computed field into segments of literals and paths,
+// the paths of the referenced sibling fields are mapped like the paths of the fields themselves
+func (v *Visitor) resolveComputedFieldValue(computed *ComputedFieldConfiguration, enclosingTypeName string, typeRef int, nullable bool) resolve.Node {
+	if v.Definition.Types[typeRef].TypeKind == ast.TypeKindNonNull {
+		typeRef = v.Definition.Types[typeRef].OfType
+		nullable = false
+	}
+	renderAsString := false
+	if v.Definition.Types[typeRef].TypeKind == ast.TypeKindNamed {
+		typeName := v.Definition.ResolveTypeNameString(typeRef)
+		typeDefinitionNode, _ := v.Definition.Index.FirstNonExtensionNodeByNameStr(typeName)
+		renderAsString = typeName == "String" || typeName == "ID" || typeDefinitionNode.Kind == ast.NodeKindEnumTypeDefinition
+	}
+
+	value := &resolve.Computed{
+		Nullable:       nullable,
+		RenderAsString: renderAsString,
+	}
+	addLiteral := func(data string) {
+		if data == "" {
+			return
+		}
+		if renderAsString {
+			quoted, _ := json.Marshal(data)
+			data = string(quoted[1 : len(quoted)-1])
+		}
+		value.Segments = append(value.Segments, resolve.ComputedSegment{Data: []byte(data)})
+	}
+
+	expression, end := computed.Expression, 0
+	for _, location := range selectorRegex.FindAllStringSubmatchIndex(expression, -1) {
+		addLiteral(expression[end:location[0]])
+		path := strings.Split(expression[location[2]:location[3]], ".")
+		end = location[1]
+		if fieldConfig := v.Config.Fields.ForTypeField(enclosingTypeName, path[0]); fieldConfig != nil && !fieldConfig.DisableDefaultMapping && len(fieldConfig.Path) != 0 {
+			path = append(splitPathSelectors(fieldConfig.Path), path[1:]...)
+		}
+		value.Segments = append(value.Segments, resolve.ComputedSegment{Path: path})
+	}
+	addLiteral(expression[end:])
+	return value
+}
+
 func (v *Visitor) resolveFieldValue(fieldRef, typeRef int, nullable bool, path []string) resolve.Node {
 	ofType := v.Definition.Types[typeRef].OfType
 
@@ -693,6 +1350,9 @@ func (v *Visitor) resolveFieldValue(fieldRef, typeRef int, nullable bool, path [
 	unescapeResponseJson := false
 	if fieldConfig != nil {
 		unescapeResponseJson = fieldConfig.UnescapeResponseJson
+		if fieldConfig.Computed != nil {
+			return v.resolveComputedFieldValue(fieldConfig.Computed, enclosingTypeName, typeRef, nullable)
+		}
 	}
 
 	switch v.Definition.Types[typeRef].TypeKind {
@@ -707,7 +1367,8 @@ func (v *Visitor) resolveFieldValue(fieldRef, typeRef int, nullable bool, path [
 		}
 	case ast.TypeKindNamed:
 		typeName := v.Definition.ResolveTypeNameString(typeRef)
-		typeDefinitionNode, ok := v.Definition.Index.FirstNodeByNameStr(typeName)
+		// extensions might be registered before the type definition, so we skip them to get the actual kind of the type
+		typeDefinitionNode, ok := v.Definition.Index.FirstNonExtensionNodeByNameStr(typeName)
 		if !ok {
 			return &resolve.Null{}
 		}
@@ -761,6 +1422,9 @@ func (v *Visitor) resolveFieldValue(fieldRef, typeRef int, nullable bool, path [
 				Fields:               []*resolve.Field{},
 				UnescapeResponseJson: unescapeResponseJson,
 			}
+			if typeDefinitionNode.Kind.IsAbstractType() {
+				object.TypeNameResolver = v.Config.Types.TypeNameResolver(typeName)
+			}
 			v.objects = append(v.objects, object)
 			v.Walker.Defer(func() {
 				v.currentFields = append(v.currentFields, objectFields{
@@ -909,6 +1573,11 @@ func (v *Visitor) resolveFieldPath(ref int) []string {
 	if config.planner != nil {
 		aliasOverride = config.planner.DataSourcePlanningBehavior().OverrideFieldPathFromAlias
 	}
+	if nesting, ok := config.planner.(NestingDataSourcePlanner); ok && aliasOverride {
+		if path, exists := nesting.DownstreamResponseFieldPath(ref); exists {
+			return path
+		}
+	}
 
 	for i := range v.Config.Fields {
 		if v.Config.Fields[i].TypeName == typeName && v.Config.Fields[i].FieldName == fieldName {
@@ -925,7 +1594,7 @@ func (v *Visitor) resolveFieldPath(ref int) []string {
 				return nil
 			}
 			if len(v.Config.Fields[i].Path) != 0 {
-				return v.Config.Fields[i].Path
+				return splitPathSelectors(v.Config.Fields[i].Path)
 			}
 			return []string{fieldName}
 		}
@@ -938,6 +1607,37 @@ func (v *Visitor) resolveFieldPath(ref int) []string {
 	return []string{fieldName}
 }
 
+// splitPathSelectors splits the array selectors of the path segments into segments of their own,
+// e.g. "items[0]" into "items" and "[0]", so that paths can be configured like data.items[0].id or results[*].node
+func splitPathSelectors(path []string) []string {
+	split := make([]string, 0, len(path))
+	for _, segment := range path {
+		split = append(split, splitSegmentSelectors(segment)...)
+	}
+	return split
+}
+
+// splitSegmentSelectors returns the key and the selectors of the segment, segments not ending with selectors aren't split
+func splitSegmentSelectors(segment string) []string {
+	bracket := strings.IndexByte(segment, '[')
+	if bracket == -1 || segment[len(segment)-1] != ']' {
+		return []string{segment}
+	}
+	var split []string
+	if bracket != 0 {
+		split = append(split, segment[:bracket])
+	}
+	for selectors := segment[bracket:]; len(selectors) != 0; {
+		end := strings.IndexByte(selectors, ']')
+		if selectors[0] != '[' || end == -1 {
+			return []string{segment}
+		}
+		split = append(split, selectors[:end+1])
+		selectors = selectors[end+1:]
+	}
+	return split
+}
+
 func (v *Visitor) EnterDocument(operation, definition *ast.Document) {
 	v.Operation, v.Definition = operation, definition
 	v.fieldConfigs = map[int]*FieldConfiguration{}
@@ -987,7 +1687,7 @@ func (v *Visitor) currentOrParentPlannerConfiguration() plannerConfiguration {
 }
 
 func (v *Visitor) isCurrentOrParentPath(currentPath string, parentPath string) bool {
-	return strings.HasPrefix(currentPath, parentPath)
+	return isPathOrChildPath(currentPath, parentPath)
 }
 
 func (v *Visitor) pathDeepness(path string) int {
@@ -1047,7 +1747,7 @@ func (v *Visitor) resolveInputTemplates(config objectFetchConfiguration, input *
 			}
 			variableTypeRef := v.Operation.VariableDefinitions[variableDefinition].Type
 			typeName := v.Operation.ResolveTypeNameBytes(v.Operation.VariableDefinitions[variableDefinition].Type)
-			node, exists := v.Definition.Index.FirstNodeByNameBytes(typeName)
+			node, exists := v.Definition.Index.FirstNonExtensionNodeByNameBytes(typeName)
 			if !exists {
 				break
 			}
@@ -1100,6 +1800,15 @@ func (v *Visitor) resolveInputTemplates(config objectFetchConfiguration, input *
 
 			variableName, _ = variables.AddVariable(variable)
 		case "request":
+			if len(path) < 2 {
+				break
+			}
+			if path[0] == "extensions" {
+				variableName, _ = variables.AddVariable(&resolve.RequestExtensionVariable{
+					Path: append([]string(nil), path[1:]...),
+				})
+				break
+			}
 			if len(path) != 2 {
 				break
 			}
@@ -1109,6 +1818,10 @@ func (v *Visitor) resolveInputTemplates(config objectFetchConfiguration, input *
 				variableName, _ = variables.AddVariable(&resolve.HeaderVariable{
 					Path: []string{key},
 				})
+			case "flags":
+				variableName, _ = variables.AddVariable(&resolve.FeatureFlagVariable{
+					Path: []string{path[1]},
+				})
 			}
 		}
 		return variableName
@@ -1137,7 +1850,7 @@ func (v *Visitor) renderJSONValueTemplate(value ast.Value, variables *resolve.Va
 			fieldName := v.Operation.Input.ByteSlice(v.Operation.ObjectFields[ref].Name)
 			fieldValue := v.Operation.ObjectFields[ref].Value
 			typeName := v.Definition.ResolveTypeNameString(v.Definition.InputValueDefinitions[inputValueDefinition].Type)
-			typeDefinitionNode, ok := v.Definition.Index.FirstNodeByNameStr(typeName)
+			typeDefinitionNode, ok := v.Definition.Index.FirstNonExtensionNodeByNameStr(typeName)
 			if !ok {
 				continue
 			}
@@ -1174,6 +1887,7 @@ func (v *Visitor) configureSubscription(config objectFetchConfiguration) {
 	subscription := config.planner.ConfigureSubscription()
 	config.trigger.Variables = subscription.Variables
 	config.trigger.Source = subscription.DataSource
+	config.trigger.Coalescing = subscription.Coalescing
 	v.resolveInputTemplates(config, &subscription.Input, &config.trigger.Variables)
 	config.trigger.Input = []byte(subscription.Input)
 }
@@ -1195,6 +1909,18 @@ func (v *Visitor) configureObjectFetch(config objectFetchConfiguration) {
 		config.object.Fetch = fetch
 		return
 	}
+	if v.isMutationRootObject(config.object) {
+		// the root fields of a mutation are executed one after another in the order of the operation
+		switch existing := config.object.Fetch.(type) {
+		case *resolve.SerialFetch:
+			existing.Fetches = append(existing.Fetches, fetch)
+		default:
+			config.object.Fetch = &resolve.SerialFetch{
+				Fetches: []resolve.Fetch{existing, fetch},
+			}
+		}
+		return
+	}
 	switch existing := config.object.Fetch.(type) {
 	case *resolve.SingleFetch:
 		copyOfExisting := *existing
@@ -1213,6 +1939,14 @@ func (v *Visitor) configureObjectFetch(config objectFetchConfiguration) {
 	}
 }
 
+func (v *Visitor) isMutationRootObject(object *resolve.Object) bool {
+	if v.Operation.OperationDefinitions[v.operationDefinition].OperationType != ast.OperationTypeMutation {
+		return false
+	}
+	plan, ok := v.plan.(*SynchronousResponsePlan)
+	return ok && plan.Response.Data == object
+}
+
 func (v *Visitor) configureFetch(internal objectFetchConfiguration, external FetchConfiguration) resolve.Fetch {
 	dataSourceType := reflect.TypeOf(external.DataSource).String()
 	dataSourceType = strings.TrimPrefix(dataSourceType, "*")
@@ -1227,6 +1961,9 @@ func (v *Visitor) configureFetch(internal objectFetchConfiguration, external Fet
 		ProcessResponseConfig:                 external.ProcessResponseConfig,
 		DisableDataLoader:                     external.DisableDataLoader,
 		SetTemplateOutputToNullOnVariableNull: external.SetTemplateOutputToNullOnVariableNull,
+		OnTypeNames:                           internal.onTypeNames,
+		SkipConditions:                        internal.skipConditions,
+		Optional:                              internal.optional,
 	}
 
 	// if a field depends on an exported variable, data loader needs to be disabled
@@ -1358,6 +2095,25 @@ type SubscriptionConfiguration struct {
 	Input      string
 	Variables  resolve.Variables
 	DataSource resolve.SubscriptionDataSource
+	// Coalescing limits the rate of the events sent to the client, see resolve.SubscriptionCoalescing
+	Coalescing resolve.SubscriptionCoalescing
+}
+
+// DelegatingDataSourcePlanner is a DataSourcePlanner which delegates the planning to other planners,
+// e.g. to fan out a field to multiple datasources.
+// The delegates register themselves on the walker of the Visitor on Register and are walked like the DataSourcePlanner itself.
+type DelegatingDataSourcePlanner interface {
+	DataSourcePlanner
+	DelegatePlanners() []DataSourcePlanner
+}
+
+// NestingDataSourcePlanner is a DataSourcePlanner which selects fields nested in other fields of the upstream response,
+// e.g. to flatten a nested field of the upstream schema into a field of the gateway schema.
+// It's only used with OverrideFieldPathFromAlias and takes precedence over DownstreamResponseFieldAlias.
+type NestingDataSourcePlanner interface {
+	DataSourcePlanner
+	// DownstreamResponseFieldPath returns the path of the field in the upstream response
+	DownstreamResponseFieldPath(downstreamFieldRef int) (path []string, exists bool)
 }
 
 type FetchConfiguration struct {
@@ -1391,8 +2147,12 @@ type configurationVisitor struct {
 	fetches               []objectFetchConfiguration
 	currentBufferId       int
 	fieldBuffers          map[int]int
+	// lastMutationPlanner is the planner of the previous root field of a mutation,
+	// only adjacent root fields are merged into the same planner to keep the order of execution
+	lastMutationPlanner int
 
 	parentTypeNodes []ast.Node
+	abstractTypes   *abstractTypeIndex
 
 	ctx context.Context
 }
@@ -1411,6 +2171,26 @@ type plannerConfiguration struct {
 	paths                   []pathConfiguration
 	dataSourceConfiguration DataSourceConfiguration
 	bufferID                int
+	// requiresSeparateFetch is true if the root field of the planner has a SkipFetch configuration or is Optional
+	// other root fields must not be merged into the planner, because they would be skipped or nulled as well
+	requiresSeparateFetch bool
+}
+
+// isPlannerOf returns true if the visitor is the planner or one of its delegates, see DelegatingDataSourcePlanner
+func (p *plannerConfiguration) isPlannerOf(visitor interface{}) bool {
+	if p.planner == visitor {
+		return true
+	}
+	delegating, ok := p.planner.(DelegatingDataSourcePlanner)
+	if !ok {
+		return false
+	}
+	for _, delegate := range delegating.DelegatePlanners() {
+		if delegate == visitor {
+			return true
+		}
+	}
+	return false
 }
 
 // isNestedPlanner returns true in case the planner is not directly attached to the Operation root
@@ -1471,13 +2251,28 @@ func (p *plannerConfiguration) hasPathPrefix(prefix string) bool {
 		if p.paths[i].path == prefix {
 			continue
 		}
-		if strings.HasPrefix(p.paths[i].path, prefix) {
+		if isPathOrChildPath(p.paths[i].path, prefix) {
 			return true
 		}
 	}
 	return false
 }
 
+// isPathOrChildPath returns true if path equals parent or is nested below it.
+// Paths are compared segment wise, so "query.me" is not considered a child of "query.m",
+// which would otherwise mix up sibling fields with aliases sharing a prefix.
+func isPathOrChildPath(path, parent string) bool {
+	if !strings.HasPrefix(path, parent) {
+		return false
+	}
+	return len(path) == len(parent) || path[len(parent)] == '.'
+}
+
+func (p *plannerConfiguration) isEntity(typeName string) bool {
+	_, ok := p.dataSourceConfiguration.EntityKeyFields(typeName)
+	return ok
+}
+
 func (p *plannerConfiguration) hasParent(parent string) bool {
 	return p.parentPath == parent
 }
@@ -1496,6 +2291,33 @@ func (p *plannerConfiguration) hasChildNode(typeName, fieldName string) bool {
 	return false
 }
 
+// hasAbstractChildNode returns true if the data source provides the field of the type as child node
+// or via the possible types of abstract types:
+// fields of object and interface types are provided if they are child nodes of an interface implemented by the type,
+// e.g. fields selected in inline fragments on the object type, and fields of interfaces are provided
+// if they are child nodes of all object types implementing the interface.
+// This way the child nodes of data sources may be configured either on the interfaces or on their implementing types.
+func (p *plannerConfiguration) hasAbstractChildNode(abstractTypes *abstractTypeIndex, typeName, fieldName string) bool {
+	if p.hasChildNode(typeName, fieldName) {
+		return true
+	}
+	for _, interfaceName := range abstractTypes.implementedInterfaces(typeName) {
+		if p.hasChildNode(interfaceName, fieldName) {
+			return true
+		}
+	}
+	possibleTypeNames := abstractTypes.interfacePossibleTypes(typeName)
+	if len(possibleTypeNames) == 0 {
+		return false
+	}
+	for i := range possibleTypeNames {
+		if !p.hasChildNode(possibleTypeNames[i], fieldName) {
+			return false
+		}
+	}
+	return true
+}
+
 func (p *plannerConfiguration) hasRootNode(typeName, fieldName string) bool {
 	for i := range p.dataSourceConfiguration.RootNodes {
 		if typeName != p.dataSourceConfiguration.RootNodes[i].TypeName {
@@ -1539,16 +2361,32 @@ func (c *configurationVisitor) EnterField(ref int) {
 	if root.Kind != ast.NodeKindOperationDefinition {
 		return
 	}
+	if fieldName == "__typename" && hasStaticTypeName(c.definition, []byte(typeName)) {
+		// the __typename is resolved by the planner, see Visitor.EnterField
+		return
+	}
+	if fieldAliasOrName == "__typename" {
+		// the __typename is fetched together with its enclosing object if possible
+		for i, plannerConfig := range c.planners {
+			if plannerConfig.hasPath(parent) && plannerConfig.planner.DataSourcePlanningBehavior().IncludeTypeNameFields {
+				c.planners[i].paths = append(c.planners[i].paths, pathConfiguration{path: current, shouldWalkFields: true})
+				return
+			}
+		}
+	}
 	isSubscription := c.isSubscription(root.Ref, current)
+	isMutationRootField := c.isMutationRootField(root.Ref, current)
 	for i, plannerConfig := range c.planners {
 		planningBehaviour := plannerConfig.planner.DataSourcePlanningBehavior()
-		if plannerConfig.hasParent(parent) && plannerConfig.hasRootNode(typeName, fieldName) && planningBehaviour.MergeAliasedRootNodes {
+		if plannerConfig.hasParent(parent) && plannerConfig.hasRootNode(typeName, fieldName) && (planningBehaviour.MergeAliasedRootNodes || plannerConfig.isEntity(typeName)) &&
+			!plannerConfig.requiresSeparateFetch && !c.requiresSeparateFetch(typeName, fieldName) && (!isMutationRootField || c.lastMutationPlanner == i) {
 			// same parent + root node = root sibling
 			c.planners[i].paths = append(c.planners[i].paths, pathConfiguration{path: current, shouldWalkFields: true})
 			c.fieldBuffers[ref] = plannerConfig.bufferID
+			c.addFetchOnTypeName(plannerConfig.planner)
 			return
 		}
-		if plannerConfig.hasPath(parent) && plannerConfig.hasChildNode(typeName, fieldName) {
+		if plannerConfig.hasPath(parent) && plannerConfig.hasAbstractChildNode(c.abstractTypes, typeName, fieldName) {
 			// has parent path + has child node = child
 			c.planners[i].paths = append(c.planners[i].paths, pathConfiguration{path: current, shouldWalkFields: true})
 			return
@@ -1559,7 +2397,7 @@ func (c *configurationVisitor) EnterField(ref int) {
 		}
 	}
 	for i, config := range c.config.DataSources {
-		if config.HasRootNode(typeName, fieldName) {
+		if config.HasRootNode(typeName, fieldName) && !c.config.isDisabledDataSource(i) {
 			var (
 				bufferID int
 			)
@@ -1593,23 +2431,114 @@ func (c *configurationVisitor) EnterField(ref int) {
 				planner:                 planner,
 				paths:                   paths,
 				dataSourceConfiguration: config,
+				requiresSeparateFetch:   c.requiresSeparateFetch(typeName, fieldName),
 			})
+			if isMutationRootField {
+				c.lastMutationPlanner = len(c.planners) - 1
+			}
 			fieldDefinition, ok := c.walker.FieldDefinition(ref)
 			if !ok {
 				continue
 			}
-			c.fetches = append(c.fetches, objectFetchConfiguration{
+			fetchConfiguration := objectFetchConfiguration{
 				bufferID:           bufferID,
 				planner:            planner,
 				isSubscription:     isSubscription,
 				fieldRef:           ref,
 				fieldDefinitionRef: fieldDefinition,
-			})
+			}
+			if isParentAbstract && !c.walker.EnclosingTypeDefinition.Kind.IsAbstractType() {
+				fetchConfiguration.onTypeNames = [][]byte{c.onTypeName(typeName)}
+			}
+			fetchConfiguration.skipConditions = c.skipFetchConditions(ref, typeName, fieldName)
+			fetchConfiguration.optional = config.Optional || c.isOptionalField(typeName, fieldName)
+			if c.isExcludedDataSource(i) {
+				// a condition without variable is always met, so the fetch is skipped
+				fetchConfiguration.skipConditions = append(fetchConfiguration.skipConditions, resolve.SkipCondition{})
+			}
+			c.fetches = append(c.fetches, fetchConfiguration)
+			return
+		}
+	}
+}
+
+// isExcludedDataSource returns true for Optional DataSources which are currently unhealthy
+func (c *configurationVisitor) isExcludedDataSource(dataSource int) bool {
+	return c.config.DataSources[dataSource].Optional && c.config.DataSourceHealth != nil && !c.config.DataSourceHealth.IsHealthy(dataSource)
+}
+
+func (c *configurationVisitor) requiresSeparateFetch(typeName, fieldName string) bool {
+	fieldConfig := c.config.Fields.ForTypeField(typeName, fieldName)
+	return fieldConfig != nil && (fieldConfig.SkipFetch != nil || fieldConfig.Optional)
+}
+
+func (c *configurationVisitor) isOptionalField(typeName, fieldName string) bool {
+	fieldConfig := c.config.Fields.ForTypeField(typeName, fieldName)
+	return fieldConfig != nil && fieldConfig.Optional
+}
+
+// skipFetchConditions resolves the SkipFetch conditions of a root field to conditions on the variables of the operation.
+// Conditions on literal or missing arguments are evaluated at planning time.
+func (c *configurationVisitor) skipFetchConditions(ref int, typeName, fieldName string) resolve.SkipConditions {
+	fieldConfig := c.config.Fields.ForTypeField(typeName, fieldName)
+	if fieldConfig == nil || fieldConfig.SkipFetch == nil {
+		return nil
+	}
+	var conditions resolve.SkipConditions
+	for _, condition := range fieldConfig.SkipFetch.Conditions {
+		skipCondition := resolve.SkipCondition{
+			Equals: condition.Equals,
+		}
+		argument, ok := c.operation.FieldArgument(ref, []byte(condition.ArgumentName))
+		if !ok {
+			if skipCondition.MatchesValue(nil) {
+				conditions = append(conditions, resolve.SkipCondition{})
+			}
+			continue
+		}
+		value := c.operation.ArgumentValue(argument)
+		if value.Kind == ast.ValueKindVariable {
+			skipCondition.VariableName = c.operation.VariableValueNameString(value.Ref)
+			conditions = append(conditions, skipCondition)
+			continue
+		}
+		literalValue, err := c.operation.ValueToJSON(value)
+		if err == nil && skipCondition.MatchesValue(literalValue) {
+			conditions = append(conditions, resolve.SkipCondition{})
+		}
+	}
+	return conditions
+}
+
+// addFetchOnTypeName adds the enclosing type of a root sibling to the type names of the planner's fetch.
+// Members of an abstract type might be resolved by different fetches,
+// so each fetch has to skip objects of types it's not responsible for.
+func (c *configurationVisitor) addFetchOnTypeName(planner DataSourcePlanner) {
+	for i := range c.fetches {
+		if c.fetches[i].planner != planner || c.fetches[i].onTypeNames == nil {
+			continue
+		}
+		if c.walker.EnclosingTypeDefinition.Kind.IsAbstractType() {
+			// the field is selected on the abstract type itself, so the fetch is applicable to all members
+			c.fetches[i].onTypeNames = nil
 			return
 		}
+		typeName := c.onTypeName(c.walker.EnclosingTypeDefinition.NameString(c.definition))
+		for _, existing := range c.fetches[i].onTypeNames {
+			if bytes.Equal(existing, typeName) {
+				return
+			}
+		}
+		c.fetches[i].onTypeNames = append(c.fetches[i].onTypeNames, typeName)
+		return
 	}
 }
 
+// onTypeName returns the type name as returned by the upstream
+func (c *configurationVisitor) onTypeName(typeName string) []byte {
+	return []byte(c.config.Types.RenameTypeNameOnMatchStr(typeName))
+}
+
 func (c *configurationVisitor) isParentTypeNodeAbstractType() bool {
 	if len(c.parentTypeNodes) < 2 {
 		return false
@@ -1633,6 +2562,7 @@ func (c *configurationVisitor) LeaveField(ref int) {
 func (c *configurationVisitor) EnterDocument(operation, definition *ast.Document) {
 	c.operation, c.definition = operation, definition
 	c.currentBufferId = -1
+	c.lastMutationPlanner = -1
 	c.parentTypeNodes = c.parentTypeNodes[:0]
 	if c.planners == nil {
 		c.planners = make([]plannerConfiguration, 0, 8)
@@ -1653,6 +2583,10 @@ func (c *configurationVisitor) EnterDocument(operation, definition *ast.Document
 	}
 }
 
+func (c *configurationVisitor) isMutationRootField(root int, path string) bool {
+	return c.operation.OperationDefinitions[root].OperationType == ast.OperationTypeMutation && strings.Count(path, ".") == 1
+}
+
 func (c *configurationVisitor) isSubscription(root int, path string) bool {
 	rootOperationType := c.operation.OperationDefinitions[root].OperationType
 	if rootOperationType != ast.OperationTypeSubscription {
@@ -1676,19 +2610,31 @@ func (r *requiredFieldsVisitor) EnterDocument(_, _ *ast.Document) {
 func (r *requiredFieldsVisitor) EnterField(ref int) {
 	typeName := r.walker.EnclosingTypeDefinition.NameString(r.definition)
 	fieldName := r.operation.FieldNameUnsafeString(ref)
-	fieldConfig := r.config.Fields.ForTypeField(typeName, fieldName)
-	if fieldConfig == nil {
-		return
-	}
-	if len(fieldConfig.RequiresFields) == 0 {
-		return
-	}
 	selectionSet := r.walker.Ancestors[len(r.walker.Ancestors)-1]
 	if selectionSet.Kind != ast.NodeKindSelectionSet {
 		return
 	}
-	for i := range fieldConfig.RequiresFields {
-		r.handleRequiredField(selectionSet.Ref, fieldConfig.RequiresFields[i])
+	if fieldConfig := r.config.Fields.ForTypeField(typeName, fieldName); fieldConfig != nil {
+		for i := range fieldConfig.RequiresFields {
+			r.handleRequiredField(selectionSet.Ref, fieldConfig.RequiresFields[i])
+		}
+		if fieldConfig.Computed != nil {
+			for _, requiredField := range fieldConfig.Computed.RequiredFields() {
+				r.handleRequiredField(selectionSet.Ref, requiredField)
+			}
+		}
+	}
+	for i := range r.config.DataSources {
+		if !r.config.DataSources[i].HasRootNode(typeName, fieldName) || r.config.isDisabledDataSource(i) {
+			continue
+		}
+		keyFields, ok := r.config.DataSources[i].EntityKeyFields(typeName)
+		if !ok {
+			continue
+		}
+		for j := range keyFields {
+			r.handleRequiredField(selectionSet.Ref, keyFields[j])
+		}
 	}
 }
 
@@ -0,0 +1,236 @@
+package plan
+
+import (
+	"strings"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+)
+
+// FetchNode is a read-only description of a fetch of a plan, see WalkFetches.
+// It's a copy of the planned fetch, so that tools can analyze plans, e.g. to estimate their costs, review the data sources
+// accessed by operations or visualize them, without depending on the structs of the resolve package.
+type FetchNode struct {
+	// ID identifies the fetch within the plan, other fetches refer to it in DependsOn
+	ID int
+	// Path is the response path of the objects the fetch is executed for, e.g. /data/users/@/friends
+	// List items are represented by "@", fetches of the root object have the path /data.
+	// Fetches of the patches of streaming plans have an empty path.
+	Path string
+	// DataSource is the identifier of the data source, e.g. graphql_datasource.Source
+	DataSource string
+	// Batched fetches are executed once for all objects of the path instead of once per object
+	Batched bool
+	// Optional fetches don't fail the operation, see resolve.SingleFetch
+	Optional bool
+	// OnTypeNames restricts the fetch to objects of these types
+	OnTypeNames []string
+	// DependsOn are the IDs of the fetches which have to complete before the fetch is executed:
+	// the fetch loading the objects the fetch is executed for and, within serial fetches, the previous fetches
+	DependsOn []int
+	// Input is the template of the input of the fetch, see InputSegment
+	Input []InputSegment
+}
+
+// InputSegment is a segment of the input template of a fetch, either static data or a variable rendered into the input
+type InputSegment struct {
+	// Data is the static data of the segment, it's empty for variables
+	Data string
+	// Variable is the variable of the segment, it's nil for static data
+	Variable *InputVariable
+}
+
+const (
+	// InputVariableKindObject are values of the objects the fetch is executed for, e.g. the keys of entities
+	InputVariableKindObject = "object"
+	// InputVariableKindContext are the variables of the operation
+	InputVariableKindContext = "context"
+	// InputVariableKindHeader are the headers of the request
+	InputVariableKindHeader = "header"
+	// InputVariableKindFeatureFlag are the feature flags of the request
+	InputVariableKindFeatureFlag = "featureFlag"
+	// InputVariableKindRequestExtension are the extensions of the request
+	InputVariableKindRequestExtension = "requestExtension"
+)
+
+// InputVariable is a variable of the input template of a fetch
+type InputVariable struct {
+	// Kind is the source of the value of the variable, e.g. InputVariableKindObject
+	Kind string
+	// Path is the path of the value within its source, e.g. [id] for the id of an object
+	Path []string
+	// Renderer is the kind of the renderer of the value, e.g. resolve.VariableRendererKindJson
+	Renderer string
+}
+
+// WalkFetches calls fn for each fetch of the plan in the order of the plan, parents before their nested fetches.
+// Walking stops once fn returns false.
+// The input templates are set after the plan is processed by the postprocess package, like the plans executed by the engine.
+// The input of a plan which isn't processed yet is a single static segment containing the placeholders of its variables.
+func WalkFetches(p Plan, fn func(fetch FetchNode) bool) {
+	w := &fetchWalker{fn: fn}
+	switch planned := p.(type) {
+	case *SynchronousResponsePlan:
+		if planned.Response != nil {
+			w.walkNode(planned.Response.Data, []string{"data"}, nil)
+		}
+	case *StreamingResponsePlan:
+		if planned.Response == nil {
+			return
+		}
+		if planned.Response.InitialResponse != nil {
+			w.walkNode(planned.Response.InitialResponse.Data, []string{"data"}, nil)
+		}
+		for i := range planned.Response.Patches {
+			patch := planned.Response.Patches[i]
+			w.walkFetch(patch.Fetch, nil, nil)
+			w.walkNode(patch.Value, nil, fetchIDs(patch.Fetch, nil))
+		}
+	case *SubscriptionResponsePlan:
+		if planned.Response != nil && planned.Response.Response != nil {
+			w.walkNode(planned.Response.Response.Data, []string{"data"}, nil)
+		}
+	}
+}
+
+// Fetches returns all fetches of the plan in the order of WalkFetches
+func Fetches(p Plan) []FetchNode {
+	var fetches []FetchNode
+	WalkFetches(p, func(fetch FetchNode) bool {
+		fetches = append(fetches, fetch)
+		return true
+	})
+	return fetches
+}
+
+type fetchWalker struct {
+	fn      func(fetch FetchNode) bool
+	stopped bool
+}
+
+// walkNode walks the fetches of the node, dependsOn are the fetches which loaded the data of the node
+func (w *fetchWalker) walkNode(node resolve.Node, path []string, dependsOn []int) {
+	if w.stopped {
+		return
+	}
+	switch n := node.(type) {
+	case *resolve.Object:
+		w.walkFetch(n.Fetch, path, dependsOn)
+		for _, field := range n.Fields {
+			fieldDependsOn := dependsOn
+			if field.HasBuffer {
+				fieldDependsOn = []int{field.BufferID}
+			}
+			w.walkNode(field.Value, append(path[:len(path):len(path)], string(field.Name)), fieldDependsOn)
+		}
+	case *resolve.Array:
+		w.walkNode(n.Item, append(path[:len(path):len(path)], "@"), dependsOn)
+	}
+}
+
+func (w *fetchWalker) walkFetch(fetch resolve.Fetch, path []string, dependsOn []int) {
+	if w.stopped {
+		return
+	}
+	switch f := fetch.(type) {
+	case *resolve.SingleFetch:
+		w.visit(f, false, path, dependsOn)
+	case *resolve.BatchFetch:
+		w.visit(f.Fetch, true, path, dependsOn)
+	case *resolve.ParallelFetch:
+		for i := range f.Fetches {
+			w.walkFetch(f.Fetches[i], path, dependsOn)
+		}
+	case *resolve.SerialFetch:
+		previous := dependsOn
+		for i := range f.Fetches {
+			w.walkFetch(f.Fetches[i], path, previous)
+			previous = fetchIDs(f.Fetches[i], previous[:len(previous):len(previous)])
+		}
+	}
+}
+
+func (w *fetchWalker) visit(fetch *resolve.SingleFetch, batched bool, path []string, dependsOn []int) {
+	if fetch == nil {
+		return
+	}
+	node := FetchNode{
+		ID:         fetch.BufferId,
+		DataSource: string(fetch.DataSourceIdentifier),
+		Batched:    batched,
+		Optional:   fetch.Optional,
+		DependsOn:  append([]int(nil), dependsOn...),
+		Input:      inputSegments(fetch),
+	}
+	if path != nil {
+		node.Path = "/" + strings.Join(path, "/")
+	}
+	for i := range fetch.OnTypeNames {
+		node.OnTypeNames = append(node.OnTypeNames, string(fetch.OnTypeNames[i]))
+	}
+	if !w.fn(node) {
+		w.stopped = true
+	}
+}
+
+// fetchIDs appends the IDs of the single fetches of the fetch to ids
+func fetchIDs(fetch resolve.Fetch, ids []int) []int {
+	switch f := fetch.(type) {
+	case *resolve.SingleFetch:
+		ids = append(ids, f.BufferId)
+	case *resolve.BatchFetch:
+		if f.Fetch != nil {
+			ids = append(ids, f.Fetch.BufferId)
+		}
+	case *resolve.ParallelFetch:
+		for i := range f.Fetches {
+			ids = fetchIDs(f.Fetches[i], ids)
+		}
+	case *resolve.SerialFetch:
+		for i := range f.Fetches {
+			ids = fetchIDs(f.Fetches[i], ids)
+		}
+	}
+	return ids
+}
+
+func inputSegments(fetch *resolve.SingleFetch) []InputSegment {
+	if len(fetch.InputTemplate.Segments) == 0 {
+		if fetch.Input == "" {
+			return nil
+		}
+		return []InputSegment{{Data: fetch.Input}}
+	}
+	segments := make([]InputSegment, 0, len(fetch.InputTemplate.Segments))
+	for _, segment := range fetch.InputTemplate.Segments {
+		if segment.SegmentType != resolve.VariableSegmentType {
+			segments = append(segments, InputSegment{Data: string(segment.Data)})
+			continue
+		}
+		variable := &InputVariable{
+			Kind: inputVariableKind(segment.VariableKind),
+			Path: append([]string(nil), segment.VariableSourcePath...),
+		}
+		if segment.Renderer != nil {
+			variable.Renderer = segment.Renderer.GetKind()
+		}
+		segments = append(segments, InputSegment{Variable: variable})
+	}
+	return segments
+}
+
+func inputVariableKind(kind resolve.VariableKind) string {
+	switch kind {
+	case resolve.ObjectVariableKind:
+		return InputVariableKindObject
+	case resolve.ContextVariableKind:
+		return InputVariableKindContext
+	case resolve.HeaderVariableKind:
+		return InputVariableKindHeader
+	case resolve.FeatureFlagVariableKind:
+		return InputVariableKindFeatureFlag
+	case resolve.RequestExtensionVariableKind:
+		return InputVariableKindRequestExtension
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,102 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+)
+
+func TestWalkFetches(t *testing.T) {
+	entityInput := resolve.InputTemplate{
+		Segments: []resolve.TemplateSegment{
+			{SegmentType: resolve.StaticSegmentType, Data: []byte(`{"id":`)},
+			{SegmentType: resolve.VariableSegmentType, VariableKind: resolve.ObjectVariableKind, VariableSourcePath: []string{"id"}, Renderer: resolve.NewJSONVariableRenderer()},
+			{SegmentType: resolve.StaticSegmentType, Data: []byte(`}`)},
+		},
+	}
+	planned := &SynchronousResponsePlan{
+		Response: &resolve.GraphQLResponse{
+			Data: &resolve.Object{
+				Fetch: &resolve.SerialFetch{
+					Fetches: []resolve.Fetch{
+						&resolve.SingleFetch{BufferId: 0, DataSourceIdentifier: []byte("users"), Input: `{"method":"POST"}`},
+						&resolve.ParallelFetch{
+							Fetches: []resolve.Fetch{
+								&resolve.SingleFetch{BufferId: 1, DataSourceIdentifier: []byte("products")},
+								&resolve.SingleFetch{BufferId: 2, DataSourceIdentifier: []byte("reviews"), Optional: true},
+							},
+						},
+						&resolve.SingleFetch{BufferId: 3, DataSourceIdentifier: []byte("audit")},
+					},
+				},
+				Fields: []*resolve.Field{
+					{
+						Name:      []byte("users"),
+						HasBuffer: true,
+						BufferID:  0,
+						Value: &resolve.Array{
+							Item: &resolve.Object{
+								Fetch: &resolve.BatchFetch{
+									Fetch: &resolve.SingleFetch{BufferId: 4, DataSourceIdentifier: []byte("accounts"), InputTemplate: entityInput, OnTypeNames: [][]byte{[]byte("User")}},
+								},
+								Fields: []*resolve.Field{
+									{
+										Name:      []byte("account"),
+										HasBuffer: true,
+										BufferID:  4,
+										Value: &resolve.Object{
+											Fetch: &resolve.SingleFetch{BufferId: 5, DataSourceIdentifier: []byte("billing")},
+										},
+									},
+								},
+							},
+						},
+					},
+					{
+						Name:  []byte("static"),
+						Value: &resolve.String{},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("walks all fetches with their dependencies", func(t *testing.T) {
+		assert.Equal(t, []FetchNode{
+			{ID: 0, Path: "/data", DataSource: "users", Input: []InputSegment{{Data: `{"method":"POST"}`}}},
+			{ID: 1, Path: "/data", DataSource: "products", DependsOn: []int{0}},
+			{ID: 2, Path: "/data", DataSource: "reviews", Optional: true, DependsOn: []int{0}},
+			{ID: 3, Path: "/data", DataSource: "audit", DependsOn: []int{0, 1, 2}},
+			{
+				ID:          4,
+				Path:        "/data/users/@",
+				DataSource:  "accounts",
+				Batched:     true,
+				OnTypeNames: []string{"User"},
+				DependsOn:   []int{0},
+				Input: []InputSegment{
+					{Data: `{"id":`},
+					{Variable: &InputVariable{Kind: InputVariableKindObject, Path: []string{"id"}, Renderer: resolve.VariableRendererKindJson}},
+					{Data: `}`},
+				},
+			},
+			{ID: 5, Path: "/data/users/@/account", DataSource: "billing", DependsOn: []int{4}},
+		}, Fetches(planned))
+	})
+
+	t.Run("stops walking once the callback returns false", func(t *testing.T) {
+		var ids []int
+		WalkFetches(planned, func(fetch FetchNode) bool {
+			ids = append(ids, fetch.ID)
+			return fetch.ID != 1
+		})
+		assert.Equal(t, []int{0, 1}, ids)
+	})
+
+	t.Run("plans without fetches", func(t *testing.T) {
+		assert.Empty(t, Fetches(&SynchronousResponsePlan{Response: &resolve.GraphQLResponse{Data: &resolve.Object{}}}))
+		assert.Empty(t, Fetches(&SubscriptionResponsePlan{}))
+	})
+}
@@ -436,6 +436,46 @@ func TestPlanner_Plan(t *testing.T) {
 			))
 		})
 	})
+
+	t.Run("nested lists", test(`
+			schema {
+				query: Query
+			}
+
+			type Query {
+				matrix: [[Float!]]!
+			}
+		`, `
+			{
+				matrix
+			}
+		`, "",
+		&SynchronousResponsePlan{
+			FlushInterval: 0,
+			Response: &resolve.GraphQLResponse{
+				Data: &resolve.Object{
+					Fields: []*resolve.Field{
+						{
+							Name: []byte("matrix"),
+							Value: &resolve.Array{
+								Path:     []string{"matrix"},
+								Nullable: false,
+								Item: &resolve.Array{
+									Nullable: true,
+									Item: &resolve.Float{
+										Nullable: false,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		Configuration{
+			DisableResolveFieldPositions: true,
+		},
+	))
 }
 
 var expectedMyHeroPlan = &SynchronousResponsePlan{
@@ -574,3 +614,59 @@ type Startship {
     name: String!
     length: Float!
 }`
+
+func TestIsPathOrChildPath(t *testing.T) {
+	assert.True(t, isPathOrChildPath("query.me", "query.me"))
+	assert.True(t, isPathOrChildPath("query.me.id", "query.me"))
+	assert.False(t, isPathOrChildPath("query.me", "query.m"))
+	assert.False(t, isPathOrChildPath("query.me.id", "query.m"))
+	assert.False(t, isPathOrChildPath("query.m", "query.me"))
+}
+
+func TestSplitPathSelectors(t *testing.T) {
+	assert.Equal(t, []string{"data", "items", "[0]", "id"}, splitPathSelectors([]string{"data", "items[0]", "id"}))
+	assert.Equal(t, []string{"results", "[*]", "node"}, splitPathSelectors([]string{"results[*]", "node"}))
+	assert.Equal(t, []string{"matrix", "[1:3]", "[0]"}, splitPathSelectors([]string{"matrix[1:3][0]"}))
+	assert.Equal(t, []string{"[*]", "id"}, splitPathSelectors([]string{"[*]", "id"}))
+	assert.Equal(t, []string{"items[0", "a[b]c"}, splitPathSelectors([]string{"items[0", "a[b]c"}))
+}
+
+func TestPlannerConfiguration_HasAbstractChildNode(t *testing.T) {
+	definition := unsafeparser.ParseGraphqlDocumentString(`
+		extend type User implements Node @key(fields: "id")
+		extend interface Named @tag
+		type User { id: ID! name: String }
+		interface Node { id: ID! }
+		interface Named { name: String }
+		type Pet implements Named { name: String }
+	`)
+	config := plannerConfiguration{
+		dataSourceConfiguration: DataSourceConfiguration{
+			ChildNodes: []TypeField{
+				{TypeName: "Node", FieldNames: []string{"id"}},
+				{TypeName: "Pet", FieldNames: []string{"name"}},
+			},
+		},
+	}
+
+	abstractTypes := newAbstractTypeIndex()
+	abstractTypes.reset(&definition)
+
+	assert.True(t, config.hasAbstractChildNode(abstractTypes, "User", "id"))
+	assert.False(t, config.hasAbstractChildNode(abstractTypes, "User", "name"))
+	assert.True(t, config.hasAbstractChildNode(abstractTypes, "Named", "name"))
+	assert.False(t, config.hasAbstractChildNode(abstractTypes, "Node", "name"))
+}
+
+func TestTypeConfigurations_RenameTypeNameOnMatch(t *testing.T) {
+	types := TypeConfigurations{
+		{TypeName: "Human", RenameTo: "SWHuman"},
+		{TypeName: "Character", TypeNameResolver: resolve.TypeNameResolverFunc(func(data []byte) ([]byte, bool) { return nil, false })},
+	}
+
+	assert.Equal(t, "SWHuman", types.RenameTypeNameOnMatchStr("Human"))
+	assert.Equal(t, []byte("SWHuman"), types.RenameTypeNameOnMatchBytes([]byte("Human")))
+	assert.Equal(t, "Character", types.RenameTypeNameOnMatchStr("Character"), "configurations without RenameTo must not rename the type")
+	assert.Equal(t, []byte("Character"), types.RenameTypeNameOnMatchBytes([]byte("Character")))
+	assert.Equal(t, "Droid", types.RenameTypeNameOnMatchStr("Droid"))
+}
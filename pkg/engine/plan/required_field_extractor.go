@@ -1,8 +1,6 @@
 package plan
 
 import (
-	"strings"
-
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
 )
 
@@ -27,6 +25,8 @@ func (f *RequiredFieldExtractor) GetAllRequiredFields() FieldConfigurations {
 
 	f.addFieldsForObjectExtensionDefinitions(&fieldRequires)
 	f.addFieldsForObjectDefinitions(&fieldRequires)
+	f.addFieldsForInterfaceExtensionDefinitions(&fieldRequires)
+	f.addFieldsForInterfaceDefinitions(&fieldRequires)
 
 	return fieldRequires
 }
@@ -34,64 +34,93 @@ func (f *RequiredFieldExtractor) GetAllRequiredFields() FieldConfigurations {
 func (f *RequiredFieldExtractor) addFieldsForObjectExtensionDefinitions(fieldRequires *FieldConfigurations) {
 	for _, objectTypeExt := range f.document.ObjectTypeExtensions {
 		objectType := objectTypeExt.ObjectTypeDefinition
-		typeName := f.document.Input.ByteSliceString(objectType.Name)
-
-		primaryKeys, exists := f.primaryKeyFieldsIfObjectTypeIsEntity(objectType)
-		if !exists {
-			continue
-		}
+		f.addFieldsForExtension(fieldRequires, objectType.Name, objectType.Directives.Refs, objectType.FieldsDefinition.Refs)
+	}
+}
 
-		for _, fieldDefinitionRef := range objectType.FieldsDefinition.Refs {
-			if f.document.FieldDefinitionHasNamedDirective(fieldDefinitionRef, federationExternalDirectiveName) {
-				continue
-			}
+func (f *RequiredFieldExtractor) addFieldsForObjectDefinitions(fieldRequires *FieldConfigurations) {
+	for _, objectType := range f.document.ObjectTypeDefinitions {
+		f.addFieldsForDefinition(fieldRequires, objectType.Name, objectType.Directives.Refs, objectType.FieldsDefinition.Refs)
+	}
+}
 
-			fieldName := f.document.FieldDefinitionNameString(fieldDefinitionRef)
+func (f *RequiredFieldExtractor) addFieldsForInterfaceExtensionDefinitions(fieldRequires *FieldConfigurations) {
+	for _, interfaceTypeExt := range f.document.InterfaceTypeExtensions {
+		interfaceType := interfaceTypeExt.InterfaceTypeDefinition
+		f.addFieldsForExtension(fieldRequires, interfaceType.Name, interfaceType.Directives.Refs, interfaceType.FieldsDefinition.Refs)
+	}
+}
 
-			requiredFields := make([]string, len(primaryKeys))
-			copy(requiredFields, primaryKeys)
+func (f *RequiredFieldExtractor) addFieldsForInterfaceDefinitions(fieldRequires *FieldConfigurations) {
+	for _, interfaceType := range f.document.InterfaceTypeDefinitions {
+		f.addFieldsForDefinition(fieldRequires, interfaceType.Name, interfaceType.Directives.Refs, interfaceType.FieldsDefinition.Refs)
+	}
+}
 
-			requiredFieldsByRequiresDirective := requiredFieldsByRequiresDirective(f.document, fieldDefinitionRef)
-			requiredFields = append(requiredFields, requiredFieldsByRequiresDirective...)
+func (f *RequiredFieldExtractor) addFieldsForExtension(fieldRequires *FieldConfigurations, name ast.ByteSliceReference, directiveRefs, fieldDefinitionRefs []int) {
+	typeName := f.document.Input.ByteSliceString(name)
 
-			*fieldRequires = append(*fieldRequires, FieldConfiguration{
-				TypeName:       typeName,
-				FieldName:      fieldName,
-				RequiresFields: requiredFields,
-			})
-		}
+	primaryKeys, exists := f.primaryKeyFieldsIfTypeIsEntity(typeName, directiveRefs)
+	if !exists {
+		return
 	}
-}
 
-func (f *RequiredFieldExtractor) addFieldsForObjectDefinitions(fieldRequires *FieldConfigurations) {
-	for _, objectType := range f.document.ObjectTypeDefinitions {
-		typeName := f.document.Input.ByteSliceString(objectType.Name)
+	primaryKeysSet := make(map[string]struct{}, len(primaryKeys))
+	for _, val := range primaryKeys {
+		primaryKeysSet[val] = struct{}{}
+	}
 
-		primaryKeys, exists := f.primaryKeyFieldsIfObjectTypeIsEntity(objectType)
-		if !exists {
+	for _, fieldDefinitionRef := range fieldDefinitionRefs {
+		if f.document.FieldDefinitionHasNamedDirective(fieldDefinitionRef, federationExternalDirectiveName) {
 			continue
 		}
 
-		primaryKeysSet := make(map[string]struct{}, len(primaryKeys))
-		for _, val := range primaryKeys {
-			primaryKeysSet[val] = struct{}{}
+		fieldName := f.document.FieldDefinitionNameString(fieldDefinitionRef)
+		if _, exists := primaryKeysSet[fieldName]; exists { // Field is part of primary key, it couldn't have any required fields
+			continue
 		}
 
-		for _, fieldRef := range objectType.FieldsDefinition.Refs {
-			fieldName := f.document.FieldDefinitionNameString(fieldRef)
-			if _, exists := primaryKeysSet[fieldName]; exists { // Field is part of primary key, it couldn't have any required fields
-				continue
-			}
+		requiredFields := make([]string, len(primaryKeys))
+		copy(requiredFields, primaryKeys)
+
+		requiredFieldsByRequiresDirective := requiredFieldsByRequiresDirective(f.document, fieldDefinitionRef)
+		requiredFields = append(requiredFields, requiredFieldsByRequiresDirective...)
+
+		*fieldRequires = append(*fieldRequires, FieldConfiguration{
+			TypeName:       typeName,
+			FieldName:      fieldName,
+			RequiresFields: requiredFields,
+		})
+	}
+}
+
+func (f *RequiredFieldExtractor) addFieldsForDefinition(fieldRequires *FieldConfigurations, name ast.ByteSliceReference, directiveRefs, fieldDefinitionRefs []int) {
+	typeName := f.document.Input.ByteSliceString(name)
 
-			requiredFields := make([]string, len(primaryKeys))
-			copy(requiredFields, primaryKeys)
+	primaryKeys, exists := f.primaryKeyFieldsIfTypeIsEntity(typeName, directiveRefs)
+	if !exists {
+		return
+	}
+
+	primaryKeysSet := make(map[string]struct{}, len(primaryKeys))
+	for _, val := range primaryKeys {
+		primaryKeysSet[val] = struct{}{}
+	}
 
-			*fieldRequires = append(*fieldRequires, FieldConfiguration{
-				TypeName:       typeName,
-				FieldName:      fieldName,
-				RequiresFields: requiredFields,
-			})
+	for _, fieldRef := range fieldDefinitionRefs {
+		fieldName := f.document.FieldDefinitionNameString(fieldRef)
+		if _, exists := primaryKeysSet[fieldName]; exists { // Field is part of primary key, it couldn't have any required fields
+			continue
 		}
+
+		requiredFields := make([]string, len(primaryKeys))
+		copy(requiredFields, primaryKeys)
+
+		*fieldRequires = append(*fieldRequires, FieldConfiguration{
+			TypeName:       typeName,
+			FieldName:      fieldName,
+			RequiresFields: requiredFields,
+		})
 	}
 }
 
@@ -111,14 +140,69 @@ func requiredFieldsByRequiresDirective(document *ast.Document, fieldDefinitionRe
 
 		fieldsStr := document.StringValueContentString(value.Ref)
 
-		return strings.Split(fieldsStr, " ")
+		return fieldSetFieldNames(fieldsStr)
 	}
 
 	return nil
 }
 
-func (f *RequiredFieldExtractor) primaryKeyFieldsIfObjectTypeIsEntity(objectType ast.ObjectTypeDefinition) (keyFields []string, ok bool) {
-	for _, directiveRef := range objectType.Directives.Refs {
+// primaryKeyFieldsIfTypeIsEntity returns the primary keys of the object or interface type.
+// If the type itself has no @key directive, the other definitions and extensions of the same type are considered,
+// e.g. a local "extend type User" of the entity "type User @key(fields: "id")" is an entity as well.
+func (f *RequiredFieldExtractor) primaryKeyFieldsIfTypeIsEntity(typeName string, directiveRefs []int) (keyFields []string, ok bool) {
+	if keyFields, ok = f.primaryKeyFieldsByKeyDirective(directiveRefs); ok {
+		return keyFields, true
+	}
+
+	return f.primaryKeyFieldsByTypeName(typeName, map[string]struct{}{})
+}
+
+// primaryKeyFieldsByTypeName returns the primary keys of the definitions and extensions of the type.
+// Types without @key directive which implement an interface entity are loaded by the keys of the interface,
+// e.g. "type Book implements Media" of the entity "interface Media @key(fields: "id")".
+func (f *RequiredFieldExtractor) primaryKeyFieldsByTypeName(typeName string, seen map[string]struct{}) (keyFields []string, ok bool) {
+	if _, exists := seen[typeName]; exists {
+		return nil, false
+	}
+	seen[typeName] = struct{}{}
+
+	nodes, _ := f.document.NodesByNameStr(typeName)
+	for _, node := range nodes {
+		if !isEntityNodeKind(node.Kind) {
+			continue
+		}
+		if keyFields, ok = f.primaryKeyFieldsByKeyDirective(f.document.NodeDirectives(node)); ok {
+			return keyFields, true
+		}
+	}
+
+	for _, node := range nodes {
+		if !isEntityNodeKind(node.Kind) {
+			continue
+		}
+		for _, interfaceRef := range f.document.NodeInterfaceRefs(node) {
+			if keyFields, ok = f.primaryKeyFieldsByTypeName(f.document.ResolveTypeNameString(interfaceRef), seen); ok {
+				return keyFields, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// isEntityNodeKind returns true for the kinds of nodes which can be entities, object and interface types and their extensions
+func isEntityNodeKind(kind ast.NodeKind) bool {
+	switch kind {
+	case ast.NodeKindObjectTypeDefinition, ast.NodeKindObjectTypeExtension,
+		ast.NodeKindInterfaceTypeDefinition, ast.NodeKindInterfaceTypeExtension:
+		return true
+	default:
+		return false
+	}
+}
+
+func (f *RequiredFieldExtractor) primaryKeyFieldsByKeyDirective(directiveRefs []int) (keyFields []string, ok bool) {
+	for _, directiveRef := range directiveRefs {
 		if directiveName := f.document.DirectiveNameString(directiveRef); directiveName != FederationKeyDirectiveName {
 			continue
 		}
@@ -133,7 +217,7 @@ func (f *RequiredFieldExtractor) primaryKeyFieldsIfObjectTypeIsEntity(objectType
 
 		fieldsStr := f.document.StringValueContentString(value.Ref)
 
-		return strings.Split(fieldsStr, " "), true
+		return fieldSetFieldNames(fieldsStr), true
 	}
 
 	return nil, false
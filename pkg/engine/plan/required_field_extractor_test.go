@@ -61,6 +61,17 @@ func TestRequiredFieldExtractor_GetAllFieldRequires(t *testing.T) {
 			{TypeName: "Review", FieldName: "title", RequiresFields: []string{"id", "author"}},
 		})
 	})
+	t.Run("Entity with nested primary key", func(t *testing.T) {
+		run(t, `
+		type Review @key(fields: "id  author { id }"){
+			id: Int!
+			body: String!
+			author: User!
+		}
+		`, FieldConfigurations{
+			{TypeName: "Review", FieldName: "body", RequiresFields: []string{"id", "author"}},
+		})
+	})
 	t.Run("Entity object extension without non-primary external fields", func(t *testing.T) {
 		run(t, `
 		extend type Review @key(fields: "id"){
@@ -83,4 +94,48 @@ func TestRequiredFieldExtractor_GetAllFieldRequires(t *testing.T) {
 			{TypeName: "Review", FieldName: "slug", RequiresFields: []string{"id", "title", "author"}},
 		})
 	})
+	t.Run("Entity with local object extension", func(t *testing.T) {
+		run(t, `
+		type User @key(fields: "id"){
+			id: ID!
+			name: String!
+		}
+
+		extend type User {
+			id: ID!
+			age: Int!
+		}
+		`, FieldConfigurations{
+			{TypeName: "User", FieldName: "age", RequiresFields: []string{"id"}},
+			{TypeName: "User", FieldName: "name", RequiresFields: []string{"id"}},
+		})
+	})
+	t.Run("interface Entity with possible types", func(t *testing.T) {
+		run(t, `
+		extend interface Media @key(fields: "id") {
+			id: ID! @external
+			rating: Int
+		}
+
+		interface Media {
+			id: ID!
+			title: String
+		}
+
+		type Book implements Media {
+			id: ID!
+			title: String
+		}
+
+		extend type Movie implements Media {
+			id: ID! @external
+			rating: Int
+		}
+		`, FieldConfigurations{
+			{TypeName: "Movie", FieldName: "rating", RequiresFields: []string{"id"}},
+			{TypeName: "Book", FieldName: "title", RequiresFields: []string{"id"}},
+			{TypeName: "Media", FieldName: "rating", RequiresFields: []string{"id"}},
+			{TypeName: "Media", FieldName: "title", RequiresFields: []string{"id"}},
+		})
+	})
 }
@@ -0,0 +1,184 @@
+package plan
+
+import (
+	"bytes"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astvisitor"
+	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+)
+
+// skipIncludeFoldingVisitor removes selections which are skipped by @skip/@include directives with a value known at planning time
+// so that they are neither fetched from upstreams nor discarded during resolving.
+// Directives with literal values are always folded.
+// Directives with variables are only folded if Configuration.FoldSkipIncludeVariables is enabled,
+// as the resulting plan is only valid for the variable values of the planned operation.
+// The operation is owned by the caller, so the visitor doesn't modify the directive and selection lists in place
+// but replaces them with folded copies and keeps the original lists, which restore puts back after planning.
+type skipIncludeFoldingVisitor struct {
+	operation, definition *ast.Document
+	walker                *astvisitor.Walker
+	foldVariables         bool
+	folded                []foldedList
+}
+
+// foldedList is the original list of directive or selection refs of a node replaced by the folding visitor
+type foldedList struct {
+	node ast.Node
+	refs []int
+}
+
+func (s *skipIncludeFoldingVisitor) EnterDocument(operation, definition *ast.Document) {
+	s.operation, s.definition = operation, definition
+	s.folded = s.folded[:0]
+}
+
+// restore puts back the original directive and selection lists of the folded nodes
+func (s *skipIncludeFoldingVisitor) restore() {
+	for i := len(s.folded) - 1; i >= 0; i-- {
+		node, refs := s.folded[i].node, s.folded[i].refs
+		switch node.Kind {
+		case ast.NodeKindSelectionSet:
+			s.operation.SelectionSets[node.Ref].SelectionRefs = refs
+		case ast.NodeKindField:
+			s.operation.Fields[node.Ref].Directives.Refs = refs
+			s.operation.Fields[node.Ref].HasDirectives = len(refs) > 0
+		case ast.NodeKindInlineFragment:
+			s.operation.InlineFragments[node.Ref].Directives.Refs = refs
+			s.operation.InlineFragments[node.Ref].HasDirectives = len(refs) > 0
+		case ast.NodeKindFragmentSpread:
+			s.operation.FragmentSpreads[node.Ref].Directives.Refs = refs
+			s.operation.FragmentSpreads[node.Ref].HasDirectives = len(refs) > 0
+		}
+	}
+	s.folded = s.folded[:0]
+}
+
+func (s *skipIncludeFoldingVisitor) EnterDirective(ref int) {
+	name := s.operation.DirectiveNameBytes(ref)
+
+	var skipOnValue bool
+	switch {
+	case bytes.Equal(name, literal.SKIP):
+		skipOnValue = true
+	case bytes.Equal(name, literal.INCLUDE):
+		skipOnValue = false
+	default:
+		return
+	}
+
+	value, ok := s.directiveValue(ref)
+	if !ok {
+		return
+	}
+
+	node := s.walker.Ancestors[len(s.walker.Ancestors)-1]
+	if value != skipOnValue {
+		s.removeDirective(node, ref)
+		return
+	}
+	if len(s.walker.Ancestors) < 2 {
+		return
+	}
+	s.removeSelection(s.walker.Ancestors[len(s.walker.Ancestors)-2], node)
+}
+
+func (s *skipIncludeFoldingVisitor) removeDirective(node ast.Node, ref int) {
+	var directives *ast.DirectiveList
+	var hasDirectives *bool
+	switch node.Kind {
+	case ast.NodeKindField:
+		directives, hasDirectives = &s.operation.Fields[node.Ref].Directives, &s.operation.Fields[node.Ref].HasDirectives
+	case ast.NodeKindInlineFragment:
+		directives, hasDirectives = &s.operation.InlineFragments[node.Ref].Directives, &s.operation.InlineFragments[node.Ref].HasDirectives
+	case ast.NodeKindFragmentSpread:
+		directives, hasDirectives = &s.operation.FragmentSpreads[node.Ref].Directives, &s.operation.FragmentSpreads[node.Ref].HasDirectives
+	default:
+		return
+	}
+	s.folded = append(s.folded, foldedList{node: node, refs: directives.Refs})
+	directives.Refs = withoutRef(directives.Refs, func(directiveRef int) bool {
+		return directiveRef == ref
+	})
+	*hasDirectives = len(directives.Refs) > 0
+}
+
+func (s *skipIncludeFoldingVisitor) removeSelection(selectionSet, node ast.Node) {
+	if selectionSet.Kind != ast.NodeKindSelectionSet {
+		return
+	}
+	var selectionKind ast.SelectionKind
+	switch node.Kind {
+	case ast.NodeKindField:
+		selectionKind = ast.SelectionKindField
+	case ast.NodeKindInlineFragment:
+		selectionKind = ast.SelectionKindInlineFragment
+	case ast.NodeKindFragmentSpread:
+		selectionKind = ast.SelectionKindFragmentSpread
+	default:
+		return
+	}
+	selectionRefs := &s.operation.SelectionSets[selectionSet.Ref].SelectionRefs
+	s.folded = append(s.folded, foldedList{node: selectionSet, refs: *selectionRefs})
+	*selectionRefs = withoutRef(*selectionRefs, func(selectionRef int) bool {
+		return s.operation.Selections[selectionRef].Kind == selectionKind && s.operation.Selections[selectionRef].Ref == node.Ref
+	})
+}
+
+// withoutRef returns a copy of refs without the refs matching remove, refs itself stays untouched
+func withoutRef(refs []int, remove func(ref int) bool) []int {
+	out := make([]int, 0, len(refs))
+	for _, ref := range refs {
+		if !remove(ref) {
+			out = append(out, ref)
+		}
+	}
+	return out
+}
+
+// directiveValue returns the value of the "if" argument if it's a boolean literal
+// or a variable of which the value or default value is known
+func (s *skipIncludeFoldingVisitor) directiveValue(ref int) (value bool, ok bool) {
+	argumentValue, ok := s.operation.DirectiveArgumentValueByName(ref, literal.IF)
+	if !ok {
+		return false, false
+	}
+	switch argumentValue.Kind {
+	case ast.ValueKindBoolean:
+		return bool(s.operation.BooleanValue(argumentValue.Ref)), true
+	case ast.ValueKindVariable:
+		if !s.foldVariables {
+			return false, false
+		}
+		return s.variableValue(s.operation.VariableValueNameBytes(argumentValue.Ref))
+	default:
+		return false, false
+	}
+}
+
+func (s *skipIncludeFoldingVisitor) variableValue(variableName []byte) (value bool, ok bool) {
+	data, dataType, _, err := jsonparser.Get(s.operation.Input.Variables, string(variableName))
+	if err == nil {
+		if dataType != jsonparser.Boolean {
+			return false, false
+		}
+		value, err = jsonparser.ParseBoolean(data)
+		return value, err == nil
+	}
+
+	operationDefinition := s.walker.Ancestors[0]
+	if operationDefinition.Kind != ast.NodeKindOperationDefinition {
+		return false, false
+	}
+	variableDefinition, exists := s.operation.VariableDefinitionByNameAndOperation(operationDefinition.Ref, variableName)
+	if !exists || !s.operation.VariableDefinitionHasDefaultValue(variableDefinition) {
+		return false, false
+	}
+	defaultValue := s.operation.VariableDefinitionDefaultValue(variableDefinition)
+	if defaultValue.Kind != ast.ValueKindBoolean {
+		return false, false
+	}
+	return bool(s.operation.BooleanValue(defaultValue.Ref)), true
+}
@@ -0,0 +1,49 @@
+package plan
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeparser"
+	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeprinter"
+	"github.com/wundergraph/graphql-go-tools/pkg/astnormalization"
+	"github.com/wundergraph/graphql-go-tools/pkg/asttransform"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+func TestPlanner_Plan_SkipIncludeFolding(t *testing.T) {
+	t.Run("the planned operation is left untouched", func(t *testing.T) {
+		def := unsafeparser.ParseGraphqlDocumentString(testDefinition)
+		require.NoError(t, asttransform.MergeDefinitionWithBaseSchema(&def))
+
+		op := unsafeparser.ParseGraphqlDocumentString(`
+			query MyHero($skip: Boolean!, $include: Boolean!) {
+				hero {
+					name @skip(if: $skip)
+					... on Droid @include(if: $include) {
+						primaryFunction
+					}
+					friends @include(if: $include) {
+						name
+					}
+				}
+			}`)
+		op.Input.Variables = []byte(`{"skip":true,"include":true}`)
+
+		var report operationreport.Report
+		astnormalization.NewNormalizer(true, true).NormalizeOperation(&op, &def, &report)
+		require.False(t, report.HasErrors(), report.Error())
+		before := unsafeprinter.Print(&op, &def)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		p := NewPlanner(ctx, Configuration{FoldSkipIncludeVariables: true})
+		_ = p.Plan(&op, &def, "MyHero", &report)
+		require.False(t, report.HasErrors(), report.Error())
+
+		assert.Equal(t, before, unsafeprinter.Print(&op, &def))
+	})
+}
@@ -0,0 +1,68 @@
+package resolve
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/buger/jsonparser"
+	"github.com/tidwall/gjson"
+)
+
+var errInvalidComputedValue = errors.New("invalid computed value")
+
+// Computed is the value of a field rendered from the values of its sibling fields instead of being read from a response,
+// see plan.ComputedFieldConfiguration
+type Computed struct {
+	// Segments are rendered in order, the literal segments are JSON escaped if the value is rendered as string
+	Segments []ComputedSegment
+	Nullable bool
+	// RenderAsString renders the value as JSON string, otherwise the rendered value has to be a valid JSON value, e.g. a number
+	RenderAsString bool
+}
+
+// ComputedSegment is either a literal or the value at the Path of the data of the enclosing object
+type ComputedSegment struct {
+	Data []byte
+	Path []string
+}
+
+func (_ *Computed) NodeKind() NodeKind {
+	return NodeKindComputed
+}
+
+// resolveComputed renders the computed value, it's null if one of the values of the paths is missing or null
+func (r *Resolver) resolveComputed(ctx *Context, computed *Computed, data []byte, computedBuf *BufPair) error {
+	rendered := make([]byte, 0, 64)
+	for i := range computed.Segments {
+		if computed.Segments[i].Path == nil {
+			rendered = append(rendered, computed.Segments[i].Data...)
+			continue
+		}
+		value, valueType, err := ctx.getJSON(data, computed.Segments[i].Path)
+		if err != nil || valueType == jsonparser.Null || valueType == jsonparser.NotExist {
+			if !computed.Nullable {
+				return errNonNullableFieldValueIsNull
+			}
+			r.resolveNull(computedBuf.Data)
+			return nil
+		}
+		if computed.RenderAsString && (valueType == jsonparser.Object || valueType == jsonparser.Array) {
+			escaped, _ := json.Marshal(string(value))
+			value = escaped[1 : len(escaped)-1]
+		}
+		rendered = append(rendered, value...)
+	}
+
+	if computed.RenderAsString {
+		computedBuf.Data.WriteBytes(quote)
+		computedBuf.Data.WriteBytes(rendered)
+		computedBuf.Data.WriteBytes(quote)
+		return nil
+	}
+	if !gjson.ValidBytes(rendered) {
+		return fmt.Errorf("%w for path %s: %s", errInvalidComputedValue, string(ctx.path()), string(rendered))
+	}
+	computedBuf.Data.WriteBytes(rendered)
+	return nil
+}
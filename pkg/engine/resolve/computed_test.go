@@ -0,0 +1,76 @@
+package resolve
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/fastbuffer"
+)
+
+func TestResolver_ResolveComputed(t *testing.T) {
+	data := []byte(`{"name":"Jens \"JS\"","age":33,"tags":["a"],"nothing":null}`)
+	resolveComputed := func(t *testing.T, computed *Computed) (string, error) {
+		r := newResolver(context.Background(), false, false)
+		ctx := NewContext(context.Background())
+		buf := &BufPair{
+			Data:   fastbuffer.New(),
+			Errors: fastbuffer.New(),
+		}
+		err := r.resolveNode(ctx, computed, data, buf)
+		return buf.Data.String(), err
+	}
+
+	t.Run("renders string", func(t *testing.T) {
+		out, err := resolveComputed(t, &Computed{
+			RenderAsString: true,
+			Segments: []ComputedSegment{
+				{Path: []string{"name"}},
+				{Data: []byte(` is `)},
+				{Path: []string{"age"}},
+				{Data: []byte(` \"`)},
+				{Path: []string{"tags"}},
+				{Data: []byte(`\"`)},
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `"Jens \"JS\" is 33 \"[\"a\"]\""`, out)
+	})
+
+	t.Run("renders JSON value", func(t *testing.T) {
+		out, err := resolveComputed(t, &Computed{
+			Segments: []ComputedSegment{{Path: []string{"age"}}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `33`, out)
+	})
+
+	t.Run("invalid JSON value", func(t *testing.T) {
+		_, err := resolveComputed(t, &Computed{
+			Segments: []ComputedSegment{{Path: []string{"age"}}, {Data: []byte(` years`)}},
+		})
+		assert.ErrorIs(t, err, errInvalidComputedValue)
+	})
+
+	t.Run("null if a value is null or missing", func(t *testing.T) {
+		for _, path := range []string{"nothing", "unknown"} {
+			out, err := resolveComputed(t, &Computed{
+				Nullable:       true,
+				RenderAsString: true,
+				Segments:       []ComputedSegment{{Path: []string{"name"}}, {Path: []string{path}}},
+			})
+			require.NoError(t, err)
+			assert.Equal(t, `null`, out)
+		}
+	})
+
+	t.Run("non nullable value is null", func(t *testing.T) {
+		_, err := resolveComputed(t, &Computed{
+			RenderAsString: true,
+			Segments:       []ComputedSegment{{Path: []string{"nothing"}}},
+		})
+		assert.ErrorIs(t, err, errNonNullableFieldValueIsNull)
+	})
+}
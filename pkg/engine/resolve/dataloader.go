@@ -191,6 +191,7 @@ func (d *dataLoader) Load(ctx *Context, fetch *SingleFetch, responsePair *BufPai
 	if err != nil {
 		return err
 	}
+	fetchParams = applicableFetchParams(fetch, fetchParams)
 
 	if fetchResult, err = d.resolveSingleFetch(ctx, fetch, fetchParams); err != nil {
 		return err
@@ -226,6 +227,7 @@ func (d *dataLoader) LoadBatch(ctx *Context, batchFetch *BatchFetch, responsePai
 	if err != nil {
 		return err
 	}
+	fetchParams = applicableFetchParams(batchFetch.Fetch, fetchParams)
 
 	if fetchResult, err = d.resolveBatchFetch(ctx, batchFetch, fetchParams); err != nil {
 		return err
@@ -409,6 +411,21 @@ func (d *dataLoader) selectedDataForFetch(input [][]byte, path ...string) ([][]b
 	return d.selectedDataForFetch(temp, rest...)
 }
 
+// applicableFetchParams removes the siblings the fetch is not applicable to.
+// The resolver skips the fetch for these siblings, so they must not occupy a result slot.
+func applicableFetchParams(fetch *SingleFetch, fetchParams [][]byte) [][]byte {
+	if len(fetch.OnTypeNames) == 0 {
+		return fetchParams
+	}
+	applicable := make([][]byte, 0, len(fetchParams))
+	for i := range fetchParams {
+		if fetch.isApplicable(fetchParams[i]) {
+			applicable = append(applicable, fetchParams[i])
+		}
+	}
+	return applicable
+}
+
 func (d *dataLoader) getResultBufPair() (pair *BufPair) {
 	d.mu.Lock()
 	defer d.mu.Unlock()
@@ -0,0 +1,68 @@
+package resolve
+
+import (
+	"fmt"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/fastbuffer"
+)
+
+// FieldDirective is an executable directive on a field, e.g. @format(pattern: $pattern),
+// which is applied to the resolved value of the field by the DirectiveMiddleware registered for its name.
+type FieldDirective struct {
+	Name string
+	// Arguments renders the arguments of the directive as JSON object, variables are rendered from the variables of the request
+	Arguments InputTemplate
+}
+
+// ResolvedDirective is a FieldDirective with the arguments rendered for the current request
+type ResolvedDirective struct {
+	Name string
+	// Arguments is the JSON object of the arguments, e.g. {"pattern":"dd.mm.yyyy"}
+	Arguments []byte
+}
+
+// DirectiveMiddleware applies an executable directive to the resolved value of a field.
+// value is the JSON value of the field, the returned JSON value replaces it in the response.
+// Returning an error aborts the resolving of the response.
+type DirectiveMiddleware interface {
+	OnFieldDirective(ctx HookContext, directive ResolvedDirective, value []byte) ([]byte, error)
+}
+
+type DirectiveMiddlewareFunc func(ctx HookContext, directive ResolvedDirective, value []byte) ([]byte, error)
+
+func (f DirectiveMiddlewareFunc) OnFieldDirective(ctx HookContext, directive ResolvedDirective, value []byte) ([]byte, error) {
+	return f(ctx, directive, value)
+}
+
+// SetDirectiveMiddlewares sets the middlewares by directive name, fields with directives without middleware are resolved as is
+func (c *Context) SetDirectiveMiddlewares(middlewares map[string]DirectiveMiddleware) {
+	c.directiveMiddlewares = middlewares
+}
+
+// resolveFieldDirectives applies the middlewares of the directives to the resolved value of the field in fieldBuf
+func (r *Resolver) resolveFieldDirectives(ctx *Context, directives []FieldDirective, fieldBuf *BufPair) error {
+	if len(ctx.directiveMiddlewares) == 0 {
+		return nil
+	}
+	for i := range directives {
+		middleware, ok := ctx.directiveMiddlewares[directives[i].Name]
+		if !ok {
+			continue
+		}
+		arguments := fastbuffer.New()
+		if err := directives[i].Arguments.Render(ctx, nil, arguments); err != nil {
+			return err
+		}
+		resolved := ResolvedDirective{
+			Name:      directives[i].Name,
+			Arguments: arguments.Bytes(),
+		}
+		value, err := middleware.OnFieldDirective(HookContext{CurrentPath: ctx.path()}, resolved, fieldBuf.Data.Bytes())
+		if err != nil {
+			return fmt.Errorf("directive @%s: %w", directives[i].Name, err)
+		}
+		fieldBuf.Data.Reset()
+		fieldBuf.Data.WriteBytes(value)
+	}
+	return nil
+}
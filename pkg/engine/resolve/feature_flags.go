@@ -0,0 +1,34 @@
+package resolve
+
+import "sort"
+
+// FeatureFlags are the names of the feature flags enabled for a request, e.g. to roll out a new DataSource progressively.
+// They are consulted when the operation is planned and can be rendered into fetch inputs with {{ .request.flags.name }}.
+type FeatureFlags []string
+
+// Enabled returns true if the feature flag is enabled
+func (f FeatureFlags) Enabled(name string) bool {
+	for i := range f {
+		if f[i] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Enable returns the feature flags with the given flags enabled
+func (f FeatureFlags) Enable(names ...string) FeatureFlags {
+	for _, name := range names {
+		if !f.Enabled(name) {
+			f = append(f[:len(f):len(f)], name)
+		}
+	}
+	return f
+}
+
+// Sorted returns a sorted copy of the feature flags
+func (f FeatureFlags) Sorted() FeatureFlags {
+	sorted := append(FeatureFlags(nil), f...)
+	sort.Strings(sorted)
+	return sorted
+}
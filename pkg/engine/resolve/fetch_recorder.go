@@ -0,0 +1,42 @@
+package resolve
+
+// FetchRecorder records the fetches of a request, i.e. the input sent to the data source and its response,
+// e.g. to replay the fetches when reproducing an incident locally.
+// Fetches are recorded concurrently, fetches deduplicated by the single flight loader are recorded once.
+// The input and the response are only valid during the call and have to be copied.
+type FetchRecorder interface {
+	RecordFetch(dataSourceIdentifier string, input, response []byte, err error)
+}
+
+// SetFetchRecorder records the fetches of the request with the recorder
+func (c *Context) SetFetchRecorder(recorder FetchRecorder) {
+	c.fetchRecorder = recorder
+}
+
+// AddFetchRecorder records the fetches of the request with the recorder in addition to the already set recorders
+func (c *Context) AddFetchRecorder(recorder FetchRecorder) {
+	switch existing := c.fetchRecorder.(type) {
+	case nil:
+		c.fetchRecorder = recorder
+	case FetchRecorders:
+		c.fetchRecorder = append(existing[:len(existing):len(existing)], recorder)
+	default:
+		c.fetchRecorder = FetchRecorders{existing, recorder}
+	}
+}
+
+// FetchRecorders records the fetches with each of the recorders
+type FetchRecorders []FetchRecorder
+
+func (r FetchRecorders) RecordFetch(dataSourceIdentifier string, input, response []byte, err error) {
+	for i := range r {
+		r[i].RecordFetch(dataSourceIdentifier, input, response, err)
+	}
+}
+
+func (c *Context) recordUpstreamFetch(fetch *SingleFetch, input, response []byte, err error) {
+	if c.fetchRecorder == nil {
+		return
+	}
+	c.fetchRecorder.RecordFetch(string(fetch.DataSourceIdentifier), input, response, err)
+}
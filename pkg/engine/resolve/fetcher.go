@@ -6,6 +6,7 @@ import (
 
 	"github.com/cespare/xxhash/v2"
 
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
 	"github.com/wundergraph/graphql-go-tools/pkg/fastbuffer"
 	"github.com/wundergraph/graphql-go-tools/pkg/pool"
 )
@@ -56,7 +57,15 @@ func (f *Fetcher) Fetch(ctx *Context, fetch *SingleFetch, preparedInput *fastbuf
 	}
 
 	if !f.EnableSingleFlightLoader || fetch.DisallowSingleFlight {
-		err = fetch.DataSource.Load(ctx.Context, preparedInput.Bytes(), dataBuf)
+		loadCtx, freshness := ctx.loadContext()
+		err = fetch.DataSource.Load(loadCtx, preparedInput.Bytes(), dataBuf)
+		ctx.recordUpstreamFetch(fetch, preparedInput.Bytes(), dataBuf.Bytes(), err)
+		ctx.recordFreshness(fetch, freshness)
+		if err == nil {
+			if err = ctx.accountMemory(dataBuf.Len()); err != nil {
+				return err
+			}
+		}
 		extractResponse(dataBuf.Bytes(), buf, fetch.ProcessResponseConfig)
 
 		if ctx.afterFetchHook != nil {
@@ -82,6 +91,10 @@ func (f *Fetcher) Fetch(ctx *Context, fetch *SingleFetch, preparedInput *fastbuf
 		defer inflight.waitFree.Done()
 		f.inflightFetchMu.Unlock()
 		inflight.waitLoad.Wait()
+		ctx.recordFreshness(fetch, &inflight.freshness)
+		if err = ctx.accountMemory(inflight.bufPair.Data.Len() + inflight.bufPair.Errors.Len()); err != nil {
+			return err
+		}
 		if inflight.bufPair.HasData() {
 			if ctx.afterFetchHook != nil {
 				ctx.afterFetchHook.OnData(f.hookCtx(ctx), inflight.bufPair.Data.Bytes(), true)
@@ -103,9 +116,19 @@ func (f *Fetcher) Fetch(ctx *Context, fetch *SingleFetch, preparedInput *fastbuf
 
 	f.inflightFetchMu.Unlock()
 
-	err = fetch.DataSource.Load(ctx.Context, preparedInput.Bytes(), dataBuf)
+	loadCtx, freshness := ctx.loadContext()
+	err = fetch.DataSource.Load(loadCtx, preparedInput.Bytes(), dataBuf)
+	ctx.recordUpstreamFetch(fetch, preparedInput.Bytes(), dataBuf.Bytes(), err)
+	if freshness != nil {
+		inflight.freshness = *freshness
+	}
+	ctx.recordFreshness(fetch, freshness)
 	extractResponse(dataBuf.Bytes(), &inflight.bufPair, fetch.ProcessResponseConfig)
 	inflight.err = err
+	if err == nil {
+		// waiting fetches account the shared response with their own Context
+		err = ctx.accountMemory(dataBuf.Len())
+	}
 
 	if inflight.bufPair.HasData() {
 		if ctx.afterFetchHook != nil {
@@ -177,6 +200,7 @@ func (f *Fetcher) freeInflightFetch(inflightFetch *inflightFetch) {
 	inflightFetch.bufPair.Data.Reset()
 	inflightFetch.bufPair.Errors.Reset()
 	inflightFetch.err = nil
+	inflightFetch.freshness = httpclient.ResponseFreshness{}
 	f.inflightFetchPool.Put(inflightFetch)
 }
 
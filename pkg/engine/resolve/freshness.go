@@ -0,0 +1,133 @@
+package resolve
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+)
+
+var freshnessExtensionKey = []byte("freshness")
+
+type FreshnessSource string
+
+const (
+	FreshnessSourceOrigin FreshnessSource = "origin"
+	FreshnessSourceCache  FreshnessSource = "cache"
+)
+
+// Freshness is the freshness of the data a fetch loaded for the objects at a path of the response,
+// it tells clients and operators how stale the data of merged responses can be.
+type Freshness struct {
+	// Path is the response path of the objects the fetch was executed for, e.g. /data/users/@/friends
+	// List items are represented by "@"
+	Path string `json:"path"`
+	// DataSource is the identifier of the data source, e.g. graphql_datasource.Source
+	DataSource string `json:"dataSource"`
+	// Source is FreshnessSourceCache if any execution of the fetch was served by a cache, FreshnessSourceOrigin otherwise
+	Source FreshnessSource `json:"source"`
+	// Age is the age of the oldest cached response in seconds, it's 0 for data of the origin
+	Age int64 `json:"age"`
+}
+
+// freshnessTracker records the freshness of the responses of the fetches executed during one request
+type freshnessTracker struct {
+	mu      sync.Mutex
+	order   []*SingleFetch
+	fetches map[*SingleFetch]*Freshness
+}
+
+func (t *freshnessTracker) record(ctx *Context, fetch *SingleFetch, response httpclient.ResponseFreshness) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	freshness, ok := t.fetches[fetch]
+	if !ok {
+		freshness = &Freshness{
+			Path:       ctx.fetchPath(),
+			DataSource: string(fetch.DataSourceIdentifier),
+			Source:     FreshnessSourceOrigin,
+		}
+		t.fetches[fetch] = freshness
+		t.order = append(t.order, fetch)
+	}
+	if !response.Cached {
+		return
+	}
+	freshness.Source = FreshnessSourceCache
+	if age := int64(response.Age / time.Second); age > freshness.Age {
+		freshness.Age = age
+	}
+}
+
+func (t *freshnessTracker) freshness() []Freshness {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	freshness := make([]Freshness, 0, len(t.order))
+	for _, fetch := range t.order {
+		freshness = append(freshness, *t.fetches[fetch])
+	}
+	return freshness
+}
+
+// EnableFreshnessTracking records the freshness of the responses of all fetches executed while resolving the response,
+// so that it's reported by Freshness and in the extensions of the response per path and data source.
+// Responses of HTTP upstreams are cached if they have an Age or cache status header, see httpclient.ResponseFreshness.
+// Data sources with their own cache report cached responses with ReportCachedResponse.
+func (c *Context) EnableFreshnessTracking() {
+	c.freshnessTracker = &freshnessTracker{
+		fetches: map[*SingleFetch]*Freshness{},
+	}
+}
+
+// Freshness returns the freshness of the data of each fetch executed while resolving the response
+// It's empty if EnableFreshnessTracking wasn't called for the Context.
+func (c *Context) Freshness() []Freshness {
+	if c.freshnessTracker == nil {
+		return nil
+	}
+	return c.freshnessTracker.freshness()
+}
+
+// ReportCachedResponse reports that the response of DataSource.Load was served by a cache and how old it is.
+// ctx is the context passed to DataSource.Load, nothing is reported if freshness isn't tracked.
+func ReportCachedResponse(ctx context.Context, age time.Duration) {
+	freshness, ok := httpclient.CtxGetResponseFreshness(ctx)
+	if !ok {
+		return
+	}
+	freshness.Cached = true
+	freshness.Age = age
+}
+
+// loadContext returns the context passed to DataSource.Load and the freshness reported for the response,
+// the freshness is nil if it isn't tracked
+func (c *Context) loadContext() (context.Context, *httpclient.ResponseFreshness) {
+	if c.freshnessTracker == nil {
+		return c.Context, nil
+	}
+	freshness := &httpclient.ResponseFreshness{}
+	return httpclient.CtxSetResponseFreshness(c.Context, freshness), freshness
+}
+
+func (c *Context) recordFreshness(fetch *SingleFetch, freshness *httpclient.ResponseFreshness) {
+	if c.freshnessTracker == nil || freshness == nil {
+		return
+	}
+	c.freshnessTracker.record(c, fetch, *freshness)
+}
+
+// freshnessExtension returns the JSON value of the freshness response extension, nil if freshness isn't tracked
+func (c *Context) freshnessExtension() []byte {
+	if c.freshnessTracker == nil {
+		return nil
+	}
+	value, err := json.Marshal(c.Freshness())
+	if err != nil {
+		return nil
+	}
+	return value
+}
@@ -52,6 +52,10 @@ func (i *InputTemplate) Render(ctx *Context, data []byte, preparedInput *fastbuf
 				err = i.renderContextVariable(ctx, i.Segments[j], preparedInput, &undefinedVariables)
 			case HeaderVariableKind:
 				err = i.renderHeaderVariable(ctx, i.Segments[j].VariableSourcePath, preparedInput)
+			case FeatureFlagVariableKind:
+				err = i.renderFeatureFlagVariable(ctx, i.Segments[j].VariableSourcePath, preparedInput)
+			case RequestExtensionVariableKind:
+				err = i.renderRequestExtensionVariable(ctx, i.Segments[j].VariableSourcePath, preparedInput)
 			default:
 				err = fmt.Errorf("InputTemplate.Render: cannot resolve variable of kind: %d", i.Segments[j].VariableKind)
 			}
@@ -136,3 +140,27 @@ func (i *InputTemplate) renderHeaderVariable(ctx *Context, path []string, prepar
 	}
 	return nil
 }
+
+func (i *InputTemplate) renderFeatureFlagVariable(ctx *Context, path []string, preparedInput *fastbuffer.FastBuffer) error {
+	if len(path) != 1 {
+		return errFeatureFlagPathInvalid
+	}
+	if ctx.Request.FeatureFlags.Enabled(path[0]) {
+		preparedInput.WriteBytes(literal.TRUE)
+		return nil
+	}
+	preparedInput.WriteBytes(literal.FALSE)
+	return nil
+}
+
+func (i *InputTemplate) renderRequestExtensionVariable(ctx *Context, path []string, preparedInput *fastbuffer.FastBuffer) error {
+	if len(path) == 0 {
+		return errRequestExtensionPathInvalid
+	}
+	value, _, ok := ctx.Request.Extensions.Get(path...)
+	if !ok {
+		return nil
+	}
+	preparedInput.WriteBytes(value)
+	return nil
+}
@@ -257,6 +257,75 @@ func TestInputTemplate_Render(t *testing.T) {
 		})
 	})
 
+	t.Run("feature flag variable", func(t *testing.T) {
+		template := InputTemplate{
+			Segments: []TemplateSegment{
+				{
+					SegmentType: StaticSegmentType,
+					Data:        []byte(`{"a":`),
+				},
+				(&FeatureFlagVariable{Path: []string{"a"}}).TemplateSegment(),
+				{
+					SegmentType: StaticSegmentType,
+					Data:        []byte(`,"b":`),
+				},
+				(&FeatureFlagVariable{Path: []string{"b"}}).TemplateSegment(),
+				{
+					SegmentType: StaticSegmentType,
+					Data:        []byte(`}`),
+				},
+			},
+		}
+		ctx := &Context{
+			Variables: []byte(""),
+			Request: Request{
+				FeatureFlags: FeatureFlags{"b"},
+			},
+		}
+		buf := fastbuffer.New()
+		err := template.Render(ctx, nil, buf)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"a":false,"b":true}`, buf.String())
+	})
+
+	t.Run("request extension variable", func(t *testing.T) {
+		template := InputTemplate{
+			Segments: []TemplateSegment{
+				{
+					SegmentType: StaticSegmentType,
+					Data:        []byte(`{"hash":"`),
+				},
+				(&RequestExtensionVariable{Path: []string{"persistedQuery", "sha256Hash"}}).TemplateSegment(),
+				{
+					SegmentType: StaticSegmentType,
+					Data:        []byte(`","client":`),
+				},
+				(&RequestExtensionVariable{Path: []string{"client"}}).TemplateSegment(),
+				{
+					SegmentType: StaticSegmentType,
+					Data:        []byte(`,"missing":"`),
+				},
+				(&RequestExtensionVariable{Path: []string{"missing"}}).TemplateSegment(),
+				{
+					SegmentType: StaticSegmentType,
+					Data:        []byte(`"}`),
+				},
+			},
+		}
+		extensions, err := ParseRequestExtensions([]byte(`{"persistedQuery":{"version":1,"sha256Hash":"abc"},"client":{"name":"web"}}`))
+		assert.NoError(t, err)
+		ctx := &Context{
+			Variables: []byte(""),
+			Request: Request{
+				Extensions: extensions,
+			},
+		}
+		buf := fastbuffer.New()
+		err = template.Render(ctx, nil, buf)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"hash":"abc","client":{"name":"web"},"missing":""}`, buf.String())
+	})
+
 	t.Run("JSONVariableRenderer", func(t *testing.T) {
 		t.Run("missing value for context variable - renders segment to null", func(t *testing.T) {
 			template := InputTemplate{
@@ -0,0 +1,168 @@
+package resolve
+
+import (
+	"github.com/buger/jsonparser"
+)
+
+const (
+	// jsonIndexMinFields is the minimum number of fields of an object reading the same data to index the data
+	jsonIndexMinFields = 3
+	// jsonIndexMinSize is the minimum size of the data to index it, smaller objects are scanned faster than indexed
+	jsonIndexMinSize = 1024
+	// jsonIndexMaxKeys is the maximum number of keys a pooled index keeps for reuse
+	jsonIndexMaxKeys = 256
+)
+
+// jsonIndex holds the top level values of a JSON object scanned in a single pass.
+// Every field of an object looks up its path in the data of the object,
+// so without an index large objects are scanned from their start once per field.
+// The keys are kept when the index is reused, values of previously indexed objects are told apart by their generation,
+// so that indexing objects of the same shape, e.g. the items of an array, doesn't allocate.
+type jsonIndex struct {
+	data       []byte
+	values     map[string]*jsonIndexValue
+	generation uint64
+}
+
+type jsonIndexValue struct {
+	value      []byte
+	dataType   jsonparser.ValueType
+	generation uint64
+}
+
+func newJSONIndex() *jsonIndex {
+	return &jsonIndex{
+		values: make(map[string]*jsonIndexValue, 32),
+	}
+}
+
+// index scans the top level values of the object, it returns false if the data isn't an object which can be indexed
+func (j *jsonIndex) index(data []byte) bool {
+	j.data = data
+	j.generation++
+	err := jsonparser.ObjectEach(data, func(key []byte, value []byte, dataType jsonparser.ValueType, _ int) error {
+		indexed, exists := j.values[string(key)]
+		if !exists {
+			indexed = &jsonIndexValue{}
+			j.values[string(key)] = indexed
+		} else if indexed.generation == j.generation {
+			// like jsonparser.Get, the first of duplicate keys wins
+			return nil
+		}
+		indexed.value, indexed.dataType, indexed.generation = value, dataType, j.generation
+		return nil
+	})
+	return err == nil
+}
+
+func (j *jsonIndex) reset() {
+	j.data = nil
+	for _, indexed := range j.values {
+		indexed.value = nil
+	}
+	if len(j.values) > jsonIndexMaxKeys {
+		j.values = make(map[string]*jsonIndexValue, 32)
+	}
+}
+
+func (j *jsonIndex) indexes(data []byte) bool {
+	return len(data) != 0 && len(data) == len(j.data) && &data[0] == &j.data[0]
+}
+
+func (j *jsonIndex) get(path []string) (value []byte, dataType jsonparser.ValueType, err error) {
+	indexed, ok := j.values[path[0]]
+	if !ok || indexed.generation != j.generation {
+		return nil, jsonparser.NotExist, jsonparser.KeyPathNotFoundError
+	}
+	if len(path) == 1 {
+		return indexed.value, indexed.dataType, nil
+	}
+	if indexed.dataType != jsonparser.Object && indexed.dataType != jsonparser.Array {
+		return nil, jsonparser.NotExist, jsonparser.KeyPathNotFoundError
+	}
+	value, dataType, _, err = jsonparser.Get(indexed.value, path[1:]...)
+	return value, dataType, err
+}
+
+// getJSON returns the value at the path like jsonparser.Get, using the index of the data if it's indexed.
+// Paths containing selectors of multiple array items are resolved by selectJSON.
+func (c *Context) getJSON(data []byte, path []string) (value []byte, dataType jsonparser.ValueType, err error) {
+	if len(path) != 0 {
+		if selector := pathSelectorIndex(path); selector != -1 {
+			return c.selectJSON(data, path, selector)
+		}
+		for i := len(c.jsonIndexes) - 1; i >= 0; i-- {
+			if c.jsonIndexes[i].indexes(data) {
+				return c.jsonIndexes[i].get(path)
+			}
+		}
+	}
+	value, dataType, _, err = jsonparser.Get(data, path...)
+	return value, dataType, err
+}
+
+// indexObjectData indexes the data which is read by at least jsonIndexMinFields fields of the object.
+// It returns the number of indexes added to the context, which have to be removed with freeJSONIndexes once the object is resolved.
+func (r *Resolver) indexObjectData(ctx *Context, object *Object, data []byte, set *resultSet) (added int) {
+	if len(object.Fields) < jsonIndexMinFields {
+		return 0
+	}
+
+	var (
+		candidates [4][]byte
+		counts     [4]int
+		n          int
+	)
+	for i := range object.Fields {
+		fieldData := data
+		if object.Fields[i].HasBuffer {
+			if set == nil {
+				continue
+			}
+			buffer, ok := set.buffers[object.Fields[i].BufferID]
+			if !ok {
+				continue
+			}
+			fieldData = buffer.Data.Bytes()
+		}
+		if len(fieldData) < jsonIndexMinSize {
+			continue
+		}
+		j := 0
+		for j < n && !(len(candidates[j]) == len(fieldData) && &candidates[j][0] == &fieldData[0]) {
+			j++
+		}
+		if j == n {
+			if n == len(candidates) {
+				continue
+			}
+			candidates[n] = fieldData
+			n++
+		}
+		counts[j]++
+	}
+
+	for i := 0; i < n; i++ {
+		if counts[i] < jsonIndexMinFields {
+			continue
+		}
+		index := r.jsonIndexPool.Get().(*jsonIndex)
+		if !index.index(candidates[i]) {
+			index.reset()
+			r.jsonIndexPool.Put(index)
+			continue
+		}
+		ctx.jsonIndexes = append(ctx.jsonIndexes, index)
+		added++
+	}
+	return added
+}
+
+func (r *Resolver) freeJSONIndexes(ctx *Context, count int) {
+	for i := len(ctx.jsonIndexes) - count; i < len(ctx.jsonIndexes); i++ {
+		ctx.jsonIndexes[i].reset()
+		r.jsonIndexPool.Put(ctx.jsonIndexes[i])
+		ctx.jsonIndexes[i] = nil
+	}
+	ctx.jsonIndexes = ctx.jsonIndexes[:len(ctx.jsonIndexes)-count]
+}
@@ -0,0 +1,155 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/buger/jsonparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONIndex(t *testing.T) {
+	data := []byte(`{"id":1,"name":"Jens","empty":"","nothing":null,"active":true,"pets":[{"name":"Kitty"}],"address":{"city":"Berlin"},"name":"duplicate"}`)
+	paths := [][]string{
+		{"id"}, {"name"}, {"empty"}, {"nothing"}, {"active"}, {"pets"}, {"pets", "[0]", "name"},
+		{"address"}, {"address", "city"}, {"address", "street"}, {"name", "first"}, {"unknown"}, {},
+	}
+
+	ctx := NewContext(context.Background())
+	index := newJSONIndex()
+	require.True(t, index.index(data))
+	ctx.jsonIndexes = append(ctx.jsonIndexes, index)
+
+	for _, path := range paths {
+		t.Run(strings.Join(path, "."), func(t *testing.T) {
+			expectedValue, expectedDataType, _, expectedErr := jsonparser.Get(data, path...)
+			value, dataType, err := ctx.getJSON(data, path)
+			assert.Equal(t, string(expectedValue), string(value))
+			assert.Equal(t, expectedDataType, dataType)
+			assert.Equal(t, expectedErr != nil, err != nil)
+		})
+	}
+
+	t.Run("only the indexed data is looked up in the index", func(t *testing.T) {
+		address, _, err := ctx.getJSON(data, []string{"address"})
+		require.NoError(t, err)
+		city, _, err := ctx.getJSON(address, []string{"city"})
+		require.NoError(t, err)
+		assert.Equal(t, "Berlin", string(city))
+	})
+
+	t.Run("keys are indexed unescaped", func(t *testing.T) {
+		index := newJSONIndex()
+		require.True(t, index.index([]byte(`{"na\u006de":"Jens"}`)))
+		value, _, err := index.get([]string{"name"})
+		require.NoError(t, err)
+		assert.Equal(t, "Jens", string(value))
+	})
+
+	t.Run("reused indexes don't return values of previously indexed objects", func(t *testing.T) {
+		index := newJSONIndex()
+		require.True(t, index.index([]byte(`{"a":1,"b":2}`)))
+		index.reset()
+		require.True(t, index.index([]byte(`{"a":3}`)))
+
+		value, _, err := index.get([]string{"a"})
+		require.NoError(t, err)
+		assert.Equal(t, "3", string(value))
+		_, _, err = index.get([]string{"b"})
+		assert.Error(t, err)
+	})
+
+	t.Run("only objects are indexed", func(t *testing.T) {
+		assert.False(t, newJSONIndex().index([]byte(`[1,2,3]`)))
+	})
+}
+
+// largeEntitiesResponse returns a federated response of users with many fields and large values, ~3MB
+func largeEntitiesResponse(users int) ([]byte, *GraphQLResponse) {
+	const fields = 16
+	description := strings.Repeat("lorem ipsum ", 16)
+
+	buf := &bytes.Buffer{}
+	buf.WriteString(`{"users":[`)
+	for i := 0; i < users; i++ {
+		if i != 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString(`{"__typename":"User"`)
+		for j := 0; j < fields; j++ {
+			_, _ = fmt.Fprintf(buf, `,"field%d":"%s %d"`, j, description, i)
+		}
+		buf.WriteByte('}')
+	}
+	buf.WriteString(`]}`)
+
+	user := &Object{}
+	for j := fields - 1; j >= 0; j-- {
+		name := fmt.Sprintf("field%d", j)
+		user.Fields = append(user.Fields, &Field{
+			Name:  []byte(name),
+			Value: &String{Path: []string{name}},
+		})
+	}
+
+	return buf.Bytes(), &GraphQLResponse{
+		Data: &Object{
+			Fetch: &SingleFetch{
+				BufferId:   0,
+				DataSource: FakeDataSource(buf.String()),
+			},
+			Fields: []*Field{
+				{
+					Name:      []byte("users"),
+					HasBuffer: true,
+					BufferID:  0,
+					Value: &Array{
+						Path: []string{"users"},
+						Item: user,
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestResolver_ResolveGraphQLResponse_JSONIndex(t *testing.T) {
+	_, response := largeEntitiesResponse(3)
+
+	resolve := func(t *testing.T) string {
+		r := newResolver(context.Background(), false, false)
+		buf := &bytes.Buffer{}
+		require.NoError(t, r.ResolveGraphQLResponse(NewContext(context.Background()), response, nil, buf))
+		return buf.String()
+	}
+
+	indexed := resolve(t)
+	assert.Contains(t, indexed, `{"data":{"users":[{"field15":"lorem ipsum`)
+
+	// objects which are too small to be worth an index resolve to the same response
+	item := response.Data.(*Object).Fields[0].Value.(*Array).Item.(*Object)
+	item.Fields = item.Fields[:jsonIndexMinFields-1]
+	assert.Equal(t, 3, strings.Count(resolve(t), `"field14"`))
+	assert.Equal(t, 3, strings.Count(indexed, `"field14"`))
+}
+
+func BenchmarkResolver_LargeEntities(b *testing.B) {
+	data, response := largeEntitiesResponse(10_000)
+	r := newResolver(context.Background(), false, false)
+	buf := &bytes.Buffer{}
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		ctx := NewContext(context.Background())
+		if err := r.ResolveGraphQLResponse(ctx, response, nil, buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package resolve
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/buger/jsonparser"
+)
+
+// Response paths select array items with jsonparser segments, e.g. []string{"items", "[0]", "id"}.
+// In addition to the indices supported by jsonparser, paths can contain selectors returning multiple items of an array:
+// the wildcard "[*]" selects all items, the slice "[start:end]" the items from start to end (exclusive),
+// both bounds are optional and negative bounds count from the end of the array, e.g. "[-2:]" selects the last two items.
+// The rest of the path is looked up in each selected item, the values are returned as an array.
+// Items not containing the rest of the path are returned as null, so multiple selectors return nested arrays.
+
+// pathSelectorIndex returns the index of the first selector of the path or -1 if the path doesn't contain a selector
+func pathSelectorIndex(path []string) int {
+	for i := range path {
+		if isPathSelector(path[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+func isPathSelector(segment string) bool {
+	if len(segment) < 3 || segment[0] != '[' || segment[len(segment)-1] != ']' {
+		return false
+	}
+	return segment == "[*]" || strings.IndexByte(segment, ':') != -1
+}
+
+// pathSliceBounds returns the bounds of the items selected by the selector in an array of length items
+func pathSliceBounds(selector string, length int) (start, end int, ok bool) {
+	if selector == "[*]" {
+		return 0, length, true
+	}
+	bounds := strings.SplitN(selector[1:len(selector)-1], ":", 2)
+	bound := func(value string, defaultValue int) (int, bool) {
+		if value == "" {
+			return defaultValue, true
+		}
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, false
+		}
+		if i < 0 {
+			i += length
+		}
+		if i < 0 {
+			return 0, true
+		}
+		if i > length {
+			return length, true
+		}
+		return i, true
+	}
+	if start, ok = bound(bounds[0], 0); !ok {
+		return 0, 0, false
+	}
+	if end, ok = bound(bounds[1], length); !ok {
+		return 0, 0, false
+	}
+	if end < start {
+		end = start
+	}
+	return start, end, true
+}
+
+// selectJSON returns the array of the values at the rest of the path in the items selected by the selector at path[selector]
+func (c *Context) selectJSON(data []byte, path []string, selector int) (value []byte, dataType jsonparser.ValueType, err error) {
+	array, arrayType, err := c.getJSON(data, path[:selector])
+	if err != nil {
+		return nil, jsonparser.NotExist, err
+	}
+	if arrayType != jsonparser.Array {
+		return nil, jsonparser.NotExist, jsonparser.KeyPathNotFoundError
+	}
+
+	var items [][]byte
+	var itemTypes []jsonparser.ValueType
+	_, err = jsonparser.ArrayEach(array, func(item []byte, itemType jsonparser.ValueType, _ int, _ error) {
+		items = append(items, item)
+		itemTypes = append(itemTypes, itemType)
+	})
+	if err != nil {
+		return nil, jsonparser.NotExist, err
+	}
+	start, end, ok := pathSliceBounds(path[selector], len(items))
+	if !ok {
+		return nil, jsonparser.NotExist, jsonparser.KeyPathNotFoundError
+	}
+
+	rest := path[selector+1:]
+	value = append(make([]byte, 0, len(array)), lBrack...)
+	for i := start; i < end; i++ {
+		if i != start {
+			value = append(value, comma...)
+		}
+		item, itemType := items[i], itemTypes[i]
+		if len(rest) != 0 {
+			item, itemType, err = c.getJSON(item, rest)
+			if err != nil {
+				item, itemType = null, jsonparser.Null
+			}
+		}
+		if itemType == jsonparser.String {
+			value = append(value, quote...)
+			value = append(value, item...)
+			value = append(value, quote...)
+			continue
+		}
+		value = append(value, item...)
+	}
+	value = append(value, rBrack...)
+	return value, jsonparser.Array, nil
+}
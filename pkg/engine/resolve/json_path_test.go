@@ -0,0 +1,50 @@
+package resolve
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/buger/jsonparser"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext_getJSON_selectors(t *testing.T) {
+	data := []byte(`{"data":{"items":[{"id":1,"name":"a"},{"id":2,"name":"b"},{"id":3},{"id":4,"name":"d"}]},"matrix":[[1,2],[3,4]]}`)
+	ctx := NewContext(context.Background())
+
+	for _, tc := range []struct {
+		path     []string
+		expected string
+	}{
+		{[]string{"data", "items", "[0]", "id"}, `1`},
+		{[]string{"data", "items", "[*]", "id"}, `[1,2,3,4]`},
+		{[]string{"data", "items", "[*]", "name"}, `["a","b",null,"d"]`},
+		{[]string{"data", "items", "[1:3]", "id"}, `[2,3]`},
+		{[]string{"data", "items", "[:2]", "id"}, `[1,2]`},
+		{[]string{"data", "items", "[2:]", "id"}, `[3,4]`},
+		{[]string{"data", "items", "[-2:]", "id"}, `[3,4]`},
+		{[]string{"data", "items", "[3:1]", "id"}, `[]`},
+		{[]string{"data", "items", "[1:10]", "id"}, `[2,3,4]`},
+		{[]string{"data", "items", "[:1]"}, `[{"id":1,"name":"a"}]`},
+		{[]string{"matrix", "[*]", "[1]"}, `[2,4]`},
+		{[]string{"matrix", "[*]", "[*]"}, `[[1,2],[3,4]]`},
+	} {
+		t.Run(strings.Join(tc.path, "."), func(t *testing.T) {
+			value, dataType, err := ctx.getJSON(data, tc.path)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(value))
+			if strings.HasPrefix(tc.expected, "[") {
+				assert.Equal(t, jsonparser.Array, dataType)
+			}
+		})
+	}
+
+	t.Run("selectors of missing or non array values don't exist", func(t *testing.T) {
+		for _, path := range [][]string{{"data", "users", "[*]"}, {"data", "[*]"}, {"data", "items", "[a:b]"}} {
+			_, dataType, err := ctx.getJSON(data, path)
+			assert.Error(t, err)
+			assert.Equal(t, jsonparser.NotExist, dataType)
+		}
+	})
+}
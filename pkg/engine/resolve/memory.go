@@ -0,0 +1,51 @@
+package resolve
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrMemoryLimitExceeded is returned when resolving a response exceeds the memory limit of the Context,
+// the resolving is aborted to protect the process from running out of memory
+var ErrMemoryLimitExceeded = errors.New("resource exhausted: memory limit exceeded")
+
+// SetMemoryLimit limits the approximate number of bytes of the upstream responses and the resolved response per response,
+// 0 disables the limit. Fetches and resolving fail with ErrMemoryLimitExceeded once the limit is exceeded.
+func (c *Context) SetMemoryLimit(bytes int64) {
+	c.memoryLimit = bytes
+}
+
+// MemoryUsage returns the approximate number of bytes accounted for the last resolved response
+func (c *Context) MemoryUsage() int64 {
+	return atomic.LoadInt64(&c.memoryUsage)
+}
+
+// accountMemory adds bytes to the memory usage, fetches can run in parallel, so it's safe for concurrent use
+func (c *Context) accountMemory(bytes int) error {
+	if c.memoryLimit <= 0 || bytes <= 0 {
+		return nil
+	}
+	usage := atomic.AddInt64(&c.memoryUsage, int64(bytes))
+	if usage > c.memoryLimit {
+		return fmt.Errorf("%w: used %d of %d bytes", ErrMemoryLimitExceeded, usage, c.memoryLimit)
+	}
+	return nil
+}
+
+// accountResolved accounts the bytes written to the buffer since it had the length written
+// for the memory usage and the response size, unless resolving failed
+func (c *Context) accountResolved(buf *BufPair, written int, err error) error {
+	if err != nil {
+		return err
+	}
+	if err = c.accountMemory(buf.Data.Len() - written); err != nil {
+		return err
+	}
+	return c.accountResponseSize(buf.Data.Len() - written)
+}
+
+// resetMemoryUsage starts the accounting of a response, each subscription event is accounted on its own
+func (c *Context) resetMemoryUsage() {
+	atomic.StoreInt64(&c.memoryUsage, 0)
+}
@@ -0,0 +1,72 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_MemoryLimit(t *testing.T) {
+	response := func() *GraphQLResponse {
+		return &GraphQLResponse{
+			Data: &Object{
+				Fetch: &SingleFetch{
+					BufferId:   0,
+					DataSource: FakeDataSource(`{"name":"Jens"}`),
+				},
+				Fields: []*Field{
+					{
+						Name:      []byte("name"),
+						HasBuffer: true,
+						BufferID:  0,
+						Value: &String{
+							Path:     []string{"name"},
+							Nullable: true,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	resolve := func(t *testing.T, enableSingleFlight bool, limit int64) (*Context, string, error) {
+		rCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		r := newResolver(rCtx, enableSingleFlight, false)
+
+		ctx := NewContext(context.Background())
+		ctx.SetMemoryLimit(limit)
+		buf := &bytes.Buffer{}
+		err := r.ResolveGraphQLResponse(ctx, response(), nil, buf)
+		return ctx, buf.String(), err
+	}
+
+	t.Run("accounts the upstream response and the resolved values", func(t *testing.T) {
+		ctx, out, err := resolve(t, false, 1024)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"name":"Jens"}}`, out)
+		assert.Equal(t, int64(len(`{"name":"Jens"}`)+len(`"Jens"`)), ctx.MemoryUsage())
+	})
+
+	t.Run("aborts when the upstream response exceeds the limit", func(t *testing.T) {
+		for _, enableSingleFlight := range []bool{false, true} {
+			_, _, err := resolve(t, enableSingleFlight, 8)
+			assert.ErrorIs(t, err, ErrMemoryLimitExceeded)
+		}
+	})
+
+	t.Run("aborts when the resolved response exceeds the limit", func(t *testing.T) {
+		_, _, err := resolve(t, false, int64(len(`{"name":"Jens"}`)+1))
+		assert.ErrorIs(t, err, ErrMemoryLimitExceeded)
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		ctx, out, err := resolve(t, false, 0)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"name":"Jens"}}`, out)
+		assert.Equal(t, int64(0), ctx.MemoryUsage())
+	})
+}
@@ -0,0 +1,116 @@
+package resolve
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"github.com/buger/jsonparser"
+)
+
+var warningsExtensionKey = []byte("warnings")
+
+// ResponseWarning is a non critical error which occurred while resolving the response, e.g. the failure of an optional fetch.
+// Warnings are added to the extensions of the response instead of its errors.
+type ResponseWarning struct {
+	Message string `json:"message"`
+	// Path is the response path of the object the fetch was executed for, e.g. /data/users/0
+	Path string `json:"path"`
+	// DataSource is the identifier of the data source, e.g. graphql_datasource.Source
+	DataSource string `json:"dataSource"`
+}
+
+// responseWarnings collects the warnings of one request, it's shared by the clones of the Context
+// as optional fetches might fail concurrently, e.g. while resolving the items of a list
+type responseWarnings struct {
+	mu       sync.Mutex
+	warnings []ResponseWarning
+}
+
+func (w *responseWarnings) add(warning ResponseWarning) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	w.warnings = append(w.warnings, warning)
+	w.mu.Unlock()
+}
+
+func (w *responseWarnings) list() []ResponseWarning {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.warnings) == 0 {
+		return nil
+	}
+	warnings := make([]ResponseWarning, len(w.warnings))
+	copy(warnings, w.warnings)
+	return warnings
+}
+
+// Warnings returns the warnings which occurred while resolving the response, e.g. of optional fetches which failed
+func (c *Context) Warnings() []ResponseWarning {
+	return c.warnings.list()
+}
+
+// warningsExtension returns the JSON value of the warnings response extension, nil if there are no warnings
+func (c *Context) warningsExtension() []byte {
+	warnings := c.Warnings()
+	if len(warnings) == 0 {
+		return nil
+	}
+	value, err := json.Marshal(warnings)
+	if err != nil {
+		return nil
+	}
+	return value
+}
+
+// warningPath returns the current path, it doesn't use pooled buffers as optional fetches might fail concurrently
+func (c *Context) warningPath() string {
+	buf := &bytes.Buffer{}
+	buf.WriteString("/data")
+	for i := range c.pathElements {
+		if i == 0 && bytes.Equal(c.pathElements[0], literalData) {
+			continue
+		}
+		buf.WriteByte('/')
+		buf.Write(c.pathElements[i])
+	}
+	return buf.String()
+}
+
+// degradeOptionalFetch turns the failure of an optional fetch into warnings.
+// The buffer of the fetch is reset, so that its fields resolve to null and no error is added to the response.
+// Cancelled operations and exceeded memory limits still fail the response.
+func (r *Resolver) degradeOptionalFetch(ctx *Context, fetch *SingleFetch, buf *BufPair, err error) error {
+	if err != nil && (errors.Is(err, ErrMemoryLimitExceeded) || ctx.Context != nil && ctx.Err() != nil) {
+		return err
+	}
+	if err == nil && !buf.HasErrors() {
+		return nil
+	}
+
+	path := ctx.warningPath()
+	dataSource := string(fetch.DataSourceIdentifier)
+	if err != nil {
+		ctx.warnings.add(ResponseWarning{Message: err.Error(), Path: path, DataSource: dataSource})
+	}
+	if buf.HasErrors() {
+		upstreamErrors := make([]byte, 0, buf.Errors.Len()+2)
+		upstreamErrors = append(upstreamErrors, lBrack...)
+		upstreamErrors = append(upstreamErrors, buf.Errors.Bytes()...)
+		upstreamErrors = append(upstreamErrors, rBrack...)
+		_, _ = jsonparser.ArrayEach(upstreamErrors, func(value []byte, _ jsonparser.ValueType, _ int, _ error) {
+			message, _ := jsonparser.GetString(value, "message")
+			ctx.warnings.add(ResponseWarning{Message: message, Path: path, DataSource: dataSource})
+		})
+	}
+
+	buf.Data.Reset()
+	buf.Errors.Reset()
+	return nil
+}
@@ -0,0 +1,136 @@
+package resolve
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+var repeatedFetchesExtensionKey = []byte("repeatedFetches")
+
+// RepeatedFetch is a fetch which was executed multiple times during one request with different inputs,
+// e.g. once per item of a list to load an entity by its key.
+// This is the N+1 problem, which can be solved by enabling batching or the data loader for the data source.
+type RepeatedFetch struct {
+	// Path is the response path of the objects the fetch was executed for, e.g. /data/users/@/friends
+	// List items are represented by "@"
+	Path string `json:"path"`
+	// DataSource is the identifier of the data source, e.g. graphql_datasource.Source
+	DataSource string `json:"dataSource"`
+	// Count is the number of times the fetch was executed
+	Count int `json:"count"`
+}
+
+type executedFetch struct {
+	path       string
+	dataSource string
+	count      int
+	inputs     map[uint64]struct{}
+}
+
+// repeatedFetchDetector records the fetches executed during one request.
+// Fetches are identified by their plan, so executions of the same fetch only differ by their input.
+type repeatedFetchDetector struct {
+	mu      sync.Mutex
+	order   []*SingleFetch
+	fetches map[*SingleFetch]*executedFetch
+}
+
+func (d *repeatedFetchDetector) record(ctx *Context, fetch *SingleFetch, input []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	executed, ok := d.fetches[fetch]
+	if !ok {
+		executed = &executedFetch{
+			path:       ctx.fetchPath(),
+			dataSource: string(fetch.DataSourceIdentifier),
+			inputs:     map[uint64]struct{}{},
+		}
+		d.fetches[fetch] = executed
+		d.order = append(d.order, fetch)
+	}
+	executed.count++
+	executed.inputs[xxhash.Sum64(input)] = struct{}{}
+}
+
+func (d *repeatedFetchDetector) repeatedFetches() []RepeatedFetch {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var repeated []RepeatedFetch
+	for _, fetch := range d.order {
+		executed := d.fetches[fetch]
+		// identical inputs are a duplicate fetch, but not an N+1 problem which batching could solve
+		if executed.count < 2 || len(executed.inputs) < 2 {
+			continue
+		}
+		repeated = append(repeated, RepeatedFetch{
+			Path:       executed.path,
+			DataSource: executed.dataSource,
+			Count:      executed.count,
+		})
+	}
+	return repeated
+}
+
+// EnableRepeatedFetchDetection records all fetches executed while resolving the response,
+// so that fetches repeated with different inputs (N+1 fetches) are reported by RepeatedFetches
+// and in the extensions of the response.
+// Fetches deduplicated by the data loader are executed once and therefore not reported.
+func (c *Context) EnableRepeatedFetchDetection() {
+	c.repeatedFetchDetector = &repeatedFetchDetector{
+		fetches: map[*SingleFetch]*executedFetch{},
+	}
+}
+
+// RepeatedFetches returns the fetches which were repeated with different inputs while resolving the response
+// It's empty if EnableRepeatedFetchDetection wasn't called for the Context.
+func (c *Context) RepeatedFetches() []RepeatedFetch {
+	if c.repeatedFetchDetector == nil {
+		return nil
+	}
+	return c.repeatedFetchDetector.repeatedFetches()
+}
+
+func (c *Context) recordFetch(fetch *SingleFetch, input []byte) {
+	if c.repeatedFetchDetector == nil {
+		return
+	}
+	c.repeatedFetchDetector.record(c, fetch, input)
+}
+
+// fetchPath returns the current path with list indices replaced by "@",
+// so that the executions of a fetch for all items of a list share the same path
+func (c *Context) fetchPath() string {
+	buf := &bytes.Buffer{}
+	buf.WriteString("/data")
+	for i := range c.pathElements {
+		if i == 0 && bytes.Equal(c.pathElements[0], literalData) {
+			continue
+		}
+		buf.WriteByte('/')
+		if _, err := strconv.Atoi(string(c.pathElements[i])); err == nil {
+			buf.WriteByte('@')
+			continue
+		}
+		buf.Write(c.pathElements[i])
+	}
+	return buf.String()
+}
+
+// repeatedFetchesExtension returns the JSON value of the repeatedFetches response extension, nil if no fetch was repeated
+func (c *Context) repeatedFetchesExtension() []byte {
+	repeated := c.RepeatedFetches()
+	if len(repeated) == 0 {
+		return nil
+	}
+	value, err := json.Marshal(repeated)
+	if err != nil {
+		return nil
+	}
+	return value
+}
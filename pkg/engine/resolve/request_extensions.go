@@ -0,0 +1,84 @@
+package resolve
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+)
+
+// RequestExtensions are the extensions sent by the client with the request, e.g. the hash of an automatic persisted query.
+// They can be rendered into fetch inputs with {{ .request.extensions.path }}, e.g. {{ .request.extensions.persistedQuery.sha256Hash }}.
+type RequestExtensions struct {
+	// PersistedQuery is the automatic persisted query extension, nil if the client didn't send it
+	PersistedQuery *PersistedQueryExtension
+	// Tracing is true if the client asked for tracing information with "tracing": true
+	Tracing bool
+	// Custom holds all other extensions by their key
+	Custom map[string]json.RawMessage
+
+	raw []byte
+}
+
+// PersistedQueryExtension is the extension of automatic persisted queries, e.g. {"version":1,"sha256Hash":"..."}
+type PersistedQueryExtension struct {
+	Version    int    `json:"version"`
+	Sha256Hash string `json:"sha256Hash"`
+}
+
+// ParseRequestExtensions parses the extensions member of a request, empty and null extensions are valid
+func ParseRequestExtensions(data []byte) (extensions RequestExtensions, err error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, literal.NULL) {
+		return extensions, nil
+	}
+
+	var entries map[string]json.RawMessage
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return RequestExtensions{}, fmt.Errorf("invalid request extensions: %w", err)
+	}
+	for key, value := range entries {
+		if bytes.Equal(value, literal.NULL) {
+			continue
+		}
+		switch key {
+		case "persistedQuery":
+			persistedQuery := &PersistedQueryExtension{}
+			if err = json.Unmarshal(value, persistedQuery); err != nil {
+				return RequestExtensions{}, fmt.Errorf("invalid request extension persistedQuery: %w", err)
+			}
+			extensions.PersistedQuery = persistedQuery
+		case "tracing":
+			if err = json.Unmarshal(value, &extensions.Tracing); err != nil {
+				return RequestExtensions{}, fmt.Errorf("invalid request extension tracing: %w", err)
+			}
+		default:
+			if extensions.Custom == nil {
+				extensions.Custom = make(map[string]json.RawMessage, len(entries))
+			}
+			extensions.Custom[key] = value
+		}
+	}
+	extensions.raw = data
+	return extensions, nil
+}
+
+// Raw returns the extensions as sent by the client, nil if there are none
+func (e RequestExtensions) Raw() []byte {
+	return e.raw
+}
+
+// Get returns the JSON value of the extension at the path, e.g. Get("persistedQuery", "sha256Hash")
+func (e RequestExtensions) Get(path ...string) (value []byte, dataType jsonparser.ValueType, ok bool) {
+	if len(e.raw) == 0 {
+		return nil, jsonparser.NotExist, false
+	}
+	value, dataType, _, err := jsonparser.Get(e.raw, path...)
+	if err != nil || dataType == jsonparser.Null {
+		return nil, jsonparser.NotExist, false
+	}
+	return value, dataType, true
+}
@@ -0,0 +1,44 @@
+package resolve
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/buger/jsonparser"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRequestExtensions(t *testing.T) {
+	t.Run("parses persisted query, tracing and custom extensions", func(t *testing.T) {
+		extensions, err := ParseRequestExtensions([]byte(`{"persistedQuery":{"version":1,"sha256Hash":"abc"},"tracing":true,"client":{"name":"web"}}`))
+		require.NoError(t, err)
+		assert.Equal(t, &PersistedQueryExtension{Version: 1, Sha256Hash: "abc"}, extensions.PersistedQuery)
+		assert.True(t, extensions.Tracing)
+		assert.Equal(t, map[string]json.RawMessage{"client": json.RawMessage(`{"name":"web"}`)}, extensions.Custom)
+
+		value, dataType, ok := extensions.Get("client", "name")
+		assert.True(t, ok)
+		assert.Equal(t, jsonparser.String, dataType)
+		assert.Equal(t, "web", string(value))
+	})
+
+	t.Run("empty and null extensions", func(t *testing.T) {
+		for _, data := range []string{``, ` null `, `{}`, `{"persistedQuery":null}`} {
+			extensions, err := ParseRequestExtensions([]byte(data))
+			require.NoError(t, err, data)
+			assert.Nil(t, extensions.PersistedQuery, data)
+			assert.False(t, extensions.Tracing, data)
+			assert.Nil(t, extensions.Custom, data)
+			_, _, ok := extensions.Get("persistedQuery")
+			assert.False(t, ok, data)
+		}
+	})
+
+	t.Run("invalid extensions", func(t *testing.T) {
+		for _, data := range []string{`[]`, `{"persistedQuery":"abc"}`, `{"tracing":"yes"}`, `{"a":`} {
+			_, err := ParseRequestExtensions([]byte(data))
+			assert.Error(t, err, data)
+		}
+	})
+}
@@ -0,0 +1,205 @@
+package resolve
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/tracing"
+)
+
+// RequestValues are values of a request by their key, e.g. the claims of a JWT, with typed getters.
+// The getters return false if the key doesn't exist or the value has another type.
+// Numbers can be int, int64, float64 or json.Number values, so that values decoded from JSON can be used as is.
+type RequestValues map[string]interface{}
+
+func (v RequestValues) Value(key string) (interface{}, bool) {
+	value, ok := v[key]
+	return value, ok
+}
+
+func (v RequestValues) String(key string) (string, bool) {
+	value, ok := v[key].(string)
+	return value, ok
+}
+
+func (v RequestValues) Bool(key string) (bool, bool) {
+	value, ok := v[key].(bool)
+	return value, ok
+}
+
+// Int returns integer values and floats without fraction
+func (v RequestValues) Int(key string) (int64, bool) {
+	switch value := v[key].(type) {
+	case int:
+		return int64(value), true
+	case int64:
+		return value, true
+	case float64:
+		if value != math.Trunc(value) {
+			return 0, false
+		}
+		return int64(value), true
+	case json.Number:
+		i, err := value.Int64()
+		return i, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func (v RequestValues) Float(key string) (float64, bool) {
+	switch value := v[key].(type) {
+	case int:
+		return float64(value), true
+	case int64:
+		return float64(value), true
+	case float64:
+		return value, true
+	case json.Number:
+		f, err := value.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// Strings returns string slices and slices of which all items are strings, e.g. the "aud" claim decoded from JSON
+func (v RequestValues) Strings(key string) ([]string, bool) {
+	switch value := v[key].(type) {
+	case []string:
+		return value, true
+	case []interface{}:
+		values := make([]string, 0, len(value))
+		for i := range value {
+			item, ok := value[i].(string)
+			if !ok {
+				return nil, false
+			}
+			values = append(values, item)
+		}
+		return values, true
+	default:
+		return nil, false
+	}
+}
+
+type requestContextKey struct{}
+
+// RequestFromContext returns the request of a Context built or extended by a ContextBuilder,
+// so that data sources can access the metadata of the request from the context.Context passed to DataSource.Load
+func RequestFromContext(ctx context.Context) (*Request, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	request, ok := ctx.Value(requestContextKey{}).(*Request)
+	return request, ok
+}
+
+// TraceContext returns the trace context of the request, see tracing.NewContext
+func (c *Context) TraceContext() (tracing.TraceContext, bool) {
+	return tracing.FromContext(c.Context)
+}
+
+// ContextBuilder builds a Context with the metadata of a request: headers, claims, trace context, feature flags and arbitrary values.
+// The metadata is available to data sources with RequestFromContext.
+type ContextBuilder struct {
+	header       http.Header
+	claims       RequestValues
+	traceContext *tracing.TraceContext
+	featureFlags FeatureFlags
+	values       RequestValues
+}
+
+func NewContextBuilder() *ContextBuilder {
+	return &ContextBuilder{}
+}
+
+// WithHeader adds the values of the header to the header of the request
+func (b *ContextBuilder) WithHeader(header http.Header) *ContextBuilder {
+	if b.header == nil {
+		b.header = make(http.Header, len(header))
+	}
+	for key, values := range header {
+		for _, value := range values {
+			b.header.Add(key, value)
+		}
+	}
+	return b
+}
+
+// WithClaims adds the claims of the authenticated client, e.g. of a JWT
+func (b *ContextBuilder) WithClaims(claims map[string]interface{}) *ContextBuilder {
+	b.claims = mergeRequestValues(b.claims, claims)
+	return b
+}
+
+// WithTraceContext propagates the trace context to the upstream requests, see tracing.NewContext
+func (b *ContextBuilder) WithTraceContext(traceContext tracing.TraceContext) *ContextBuilder {
+	b.traceContext = &traceContext
+	return b
+}
+
+// WithFeatureFlags enables the feature flags for the request
+func (b *ContextBuilder) WithFeatureFlags(names ...string) *ContextBuilder {
+	b.featureFlags = b.featureFlags.Enable(names...)
+	return b
+}
+
+// WithValue sets an arbitrary value of the request, e.g. the tenant resolved by a middleware
+func (b *ContextBuilder) WithValue(key string, value interface{}) *ContextBuilder {
+	b.values = mergeRequestValues(b.values, map[string]interface{}{key: value})
+	return b
+}
+
+// Build returns a new Context for ctx with the metadata of the builder
+func (b *ContextBuilder) Build(ctx context.Context) *Context {
+	c := NewContext(ctx)
+	b.Apply(c)
+	return c
+}
+
+// Apply adds the metadata of the builder to the request of an existing Context,
+// the claims and values of the builder take precedence over the existing ones
+func (b *ContextBuilder) Apply(c *Context) {
+	if len(b.header) != 0 {
+		header := make(http.Header, len(c.Request.Header)+len(b.header))
+		for key, values := range c.Request.Header {
+			header[key] = append([]string(nil), values...)
+		}
+		for key, values := range b.header {
+			header[key] = append(header[key], values...)
+		}
+		c.Request.Header = header
+	}
+	if len(b.claims) != 0 {
+		c.Request.Claims = mergeRequestValues(mergeRequestValues(nil, c.Request.Claims), b.claims)
+	}
+	if len(b.values) != 0 {
+		c.Request.Values = mergeRequestValues(mergeRequestValues(nil, c.Request.Values), b.values)
+	}
+	if len(b.featureFlags) != 0 {
+		c.Request.FeatureFlags = c.Request.FeatureFlags.Enable(b.featureFlags...)
+	}
+	if c.Context == nil {
+		c.Context = context.Background()
+	}
+	if b.traceContext != nil {
+		c.Context = tracing.NewContext(c.Context, *b.traceContext)
+	}
+	if request, ok := RequestFromContext(c.Context); !ok || request != &c.Request {
+		c.Context = context.WithValue(c.Context, requestContextKey{}, &c.Request)
+	}
+}
+
+// mergeRequestValues returns values with the additional values, values is allocated if it's nil
+func mergeRequestValues(values RequestValues, additional map[string]interface{}) RequestValues {
+	if values == nil {
+		values = make(RequestValues, len(additional))
+	}
+	for key, value := range additional {
+		values[key] = value
+	}
+	return values
+}
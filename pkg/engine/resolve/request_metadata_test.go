@@ -0,0 +1,111 @@
+package resolve
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/tracing"
+)
+
+func TestRequestValues(t *testing.T) {
+	var claims RequestValues
+	require.NoError(t, json.Unmarshal([]byte(`{"sub":"user-1","admin":true,"exp":1700000000,"score":0.5,"aud":["gateway","users"],"mixed":["a",1]}`), &claims))
+
+	sub, ok := claims.String("sub")
+	assert.True(t, ok)
+	assert.Equal(t, "user-1", sub)
+	_, ok = claims.String("admin")
+	assert.False(t, ok)
+
+	admin, ok := claims.Bool("admin")
+	assert.True(t, ok)
+	assert.True(t, admin)
+
+	exp, ok := claims.Int("exp")
+	assert.True(t, ok)
+	assert.Equal(t, int64(1700000000), exp)
+	_, ok = claims.Int("score")
+	assert.False(t, ok, "floats with fraction aren't integers")
+
+	score, ok := claims.Float("score")
+	assert.True(t, ok)
+	assert.Equal(t, 0.5, score)
+
+	aud, ok := claims.Strings("aud")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"gateway", "users"}, aud)
+	_, ok = claims.Strings("mixed")
+	assert.False(t, ok)
+
+	_, ok = claims.Value("missing")
+	assert.False(t, ok)
+	_, ok = RequestValues(nil).String("sub")
+	assert.False(t, ok)
+
+	count, ok := RequestValues{"count": json.Number("3")}.Int("count")
+	assert.True(t, ok)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestContextBuilder(t *testing.T) {
+	traceContext := tracing.New()
+	builder := NewContextBuilder().
+		WithHeader(http.Header{"Authorization": []string{"Bearer token"}}).
+		WithClaims(map[string]interface{}{"sub": "user-1"}).
+		WithTraceContext(traceContext).
+		WithFeatureFlags("newUsers").
+		WithValue("tenant", "acme")
+
+	t.Run("builds a context with the metadata", func(t *testing.T) {
+		ctx := builder.Build(context.Background())
+		assert.Equal(t, "Bearer token", ctx.Request.Header.Get("Authorization"))
+		assert.Equal(t, RequestValues{"sub": "user-1"}, ctx.Request.Claims)
+		assert.Equal(t, RequestValues{"tenant": "acme"}, ctx.Request.Values)
+		assert.True(t, ctx.Request.FeatureFlags.Enabled("newUsers"))
+
+		actualTraceContext, ok := ctx.TraceContext()
+		assert.True(t, ok)
+		assert.Equal(t, traceContext, actualTraceContext)
+
+		request, ok := RequestFromContext(ctx.Context)
+		require.True(t, ok)
+		assert.Same(t, &ctx.Request, request)
+	})
+
+	t.Run("extends an existing context", func(t *testing.T) {
+		ctx := NewContext(context.Background())
+		ctx.Request.Header = http.Header{"X-Client": []string{"web"}}
+		ctx.Request.Values = RequestValues{"tenant": "other", "region": "eu"}
+		ctx.Request.FeatureFlags = FeatureFlags{"beta"}
+
+		builder.Apply(ctx)
+		assert.Equal(t, http.Header{"X-Client": []string{"web"}, "Authorization": []string{"Bearer token"}}, ctx.Request.Header)
+		assert.Equal(t, RequestValues{"tenant": "acme", "region": "eu"}, ctx.Request.Values)
+		assert.True(t, ctx.Request.FeatureFlags.Enabled("beta"))
+		assert.True(t, ctx.Request.FeatureFlags.Enabled("newUsers"))
+
+		request, ok := RequestFromContext(ctx.Context)
+		require.True(t, ok)
+		sub, _ := request.Claims.String("sub")
+		assert.Equal(t, "user-1", sub)
+	})
+
+	t.Run("contexts without builder have no request", func(t *testing.T) {
+		_, ok := RequestFromContext(context.Background())
+		assert.False(t, ok)
+		_, ok = NewContext(context.Background()).TraceContext()
+		assert.False(t, ok)
+	})
+
+	t.Run("free resets the metadata", func(t *testing.T) {
+		ctx := builder.Build(context.Background())
+		ctx.Free()
+		assert.Nil(t, ctx.Request.Claims)
+		assert.Nil(t, ctx.Request.Values)
+	})
+}
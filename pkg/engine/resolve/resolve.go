@@ -5,9 +5,11 @@ package resolve
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"reflect"
 	"strconv"
 	"sync"
 	"time"
@@ -18,9 +20,11 @@ import (
 	errors "golang.org/x/xerrors"
 
 	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafebytes"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
 	"github.com/wundergraph/graphql-go-tools/pkg/fastbuffer"
 	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
 	"github.com/wundergraph/graphql-go-tools/pkg/pool"
+	"github.com/wundergraph/graphql-go-tools/pkg/tracing"
 )
 
 var (
@@ -50,6 +54,8 @@ var (
 	errNonNullableFieldValueIsNull = errors.New("non Nullable field value is null")
 	errTypeNameSkipped             = errors.New("skipped because of __typename condition")
 	errHeaderPathInvalid           = errors.New("invalid header path: header variables must be of this format: .request.header.{{ key }} ")
+	errFeatureFlagPathInvalid      = errors.New("invalid feature flag path: feature flag variables must be of this format: .request.flags.{{ name }} ")
+	errRequestExtensionPathInvalid = errors.New("invalid request extension path: request extension variables must be of this format: .request.extensions.{{ path }} ")
 
 	ErrUnableToResolve = errors.New("unable to resolve operation")
 )
@@ -95,10 +101,12 @@ const (
 	NodeKindBoolean
 	NodeKindInteger
 	NodeKindFloat
+	NodeKindComputed
 
 	FetchKindSingle FetchKind = iota + 1
 	FetchKindParallel
 	FetchKindBatch
+	FetchKindSerial
 )
 
 type HookContext struct {
@@ -131,10 +139,30 @@ type Context struct {
 	afterFetchHook   AfterFetchHook
 	position         Position
 	RenameTypeNames  []RenameTypeName
+
+	repeatedFetchDetector *repeatedFetchDetector
+	freshnessTracker      *freshnessTracker
+	directiveMiddlewares  map[string]DirectiveMiddleware
+	memoryLimit           int64
+	memoryUsage           int64
+	maxResponseSize       int64
+	responseSize          *int64
+	jsonIndexes           []*jsonIndex
+	responseExtensions    []responseExtension
+	fetchRecorder         FetchRecorder
+	warnings              *responseWarnings
 }
 
 type Request struct {
 	Header http.Header
+	// FeatureFlags are the feature flags enabled for the request, they have to be set before the operation is planned
+	FeatureFlags FeatureFlags
+	// Extensions are the extensions sent by the client with the request
+	Extensions RequestExtensions
+	// Claims are the claims of the authenticated client, e.g. of a JWT, see ContextBuilder
+	Claims RequestValues
+	// Values are arbitrary values of the request, e.g. set by middlewares, see ContextBuilder
+	Values RequestValues
 }
 
 func NewContext(ctx context.Context) *Context {
@@ -149,6 +177,7 @@ func NewContext(ctx context.Context) *Context {
 		maxPatch:     -1,
 		position:     Position{},
 		dataLoader:   nil,
+		warnings:     &responseWarnings{},
 	}
 }
 
@@ -187,6 +216,12 @@ func (c *Context) Clone() Context {
 		beforeFetchHook: c.beforeFetchHook,
 		afterFetchHook:  c.afterFetchHook,
 		position:        c.position,
+
+		directiveMiddlewares: c.directiveMiddlewares,
+		memoryLimit:          c.memoryLimit,
+		maxResponseSize:      c.maxResponseSize,
+		responseSize:         c.responseSize,
+		warnings:             c.warnings,
 	}
 }
 
@@ -205,9 +240,23 @@ func (c *Context) Free() {
 	c.beforeFetchHook = nil
 	c.afterFetchHook = nil
 	c.Request.Header = nil
+	c.Request.FeatureFlags = nil
+	c.Request.Extensions = RequestExtensions{}
+	c.Request.Claims = nil
+	c.Request.Values = nil
 	c.position = Position{}
 	c.dataLoader = nil
 	c.RenameTypeNames = nil
+	c.repeatedFetchDetector = nil
+	c.freshnessTracker = nil
+	c.directiveMiddlewares = nil
+	c.memoryLimit = 0
+	c.memoryUsage = 0
+	c.jsonIndexes = c.jsonIndexes[:0]
+	c.responseExtensions = c.responseExtensions[:0]
+	c.fetchRecorder = nil
+	// the warnings might be shared with clones of the Context, so they are replaced instead of reset
+	c.warnings = &responseWarnings{}
 }
 
 func (c *Context) SetBeforeFetchHook(hook BeforeFetchHook) {
@@ -326,6 +375,7 @@ type Resolver struct {
 	bufPairSlicePool  sync.Pool
 	errChanPool       sync.Pool
 	hash64Pool        sync.Pool
+	jsonIndexPool     sync.Pool
 	dataloaderFactory *dataLoaderFactory
 	fetcher           *Fetcher
 }
@@ -335,6 +385,8 @@ type inflightFetch struct {
 	waitFree sync.WaitGroup
 	err      error
 	bufPair  BufPair
+	// freshness is the freshness of the shared response, it's recorded for the waiting fetches as well
+	freshness httpclient.ResponseFreshness
 }
 
 // New returns a new Resolver, ctx.Done() is used to cancel all active subscriptions & streams
@@ -345,6 +397,7 @@ func New(ctx context.Context, fetcher *Fetcher, enableDataLoader bool) *Resolver
 			New: func() interface{} {
 				return &resultSet{
 					buffers: make(map[int]*BufPair, 8),
+					skipped: make(map[int]bool, 8),
 				}
 			},
 		},
@@ -384,6 +437,11 @@ func New(ctx context.Context, fetcher *Fetcher, enableDataLoader bool) *Resolver
 				return xxhash.New()
 			},
 		},
+		jsonIndexPool: sync.Pool{
+			New: func() interface{} {
+				return newJSONIndex()
+			},
+		},
 		dataloaderFactory: newDataloaderFactory(fetcher),
 		fetcher:           fetcher,
 		dataLoaderEnabled: enableDataLoader,
@@ -403,13 +461,25 @@ func (r *Resolver) resolveNode(ctx *Context, node Node, data []byte, bufPair *Bu
 		r.resolveNull(bufPair.Data)
 		return
 	case *String:
-		return r.resolveString(ctx, n, data, bufPair)
+		written := bufPair.Data.Len()
+		err = r.resolveString(ctx, n, data, bufPair)
+		return ctx.accountResolved(bufPair, written, err)
 	case *Boolean:
-		return r.resolveBoolean(ctx, n, data, bufPair)
+		written := bufPair.Data.Len()
+		err = r.resolveBoolean(ctx, n, data, bufPair)
+		return ctx.accountResolved(bufPair, written, err)
 	case *Integer:
-		return r.resolveInteger(ctx, n, data, bufPair)
+		written := bufPair.Data.Len()
+		err = r.resolveInteger(ctx, n, data, bufPair)
+		return ctx.accountResolved(bufPair, written, err)
 	case *Float:
-		return r.resolveFloat(ctx, n, data, bufPair)
+		written := bufPair.Data.Len()
+		err = r.resolveFloat(ctx, n, data, bufPair)
+		return ctx.accountResolved(bufPair, written, err)
+	case *Computed:
+		written := bufPair.Data.Len()
+		err = r.resolveComputed(ctx, n, data, bufPair)
+		return ctx.accountResolved(bufPair, written, err)
 	case *EmptyObject:
 		r.resolveEmptyObject(bufPair.Data)
 		return
@@ -473,6 +543,9 @@ func extractResponse(responseData []byte, bufPair *BufPair, cfg ProcessResponseC
 }
 
 func (r *Resolver) ResolveGraphQLResponse(ctx *Context, response *GraphQLResponse, data []byte, writer io.Writer) (err error) {
+	if ctx.warnings == nil {
+		ctx.warnings = &responseWarnings{}
+	}
 
 	buf := r.getBufPair()
 	defer r.freeBufPair(buf)
@@ -486,6 +559,9 @@ func (r *Resolver) ResolveGraphQLResponse(ctx *Context, response *GraphQLRespons
 		ctx.lastFetchID = initialValueID
 	}
 
+	ctx.resetMemoryUsage()
+	ctx.resetResponseSize()
+
 	if r.dataLoaderEnabled {
 		ctx.dataLoader = r.dataloaderFactory.newDataLoader(responseBuf.Data.Bytes())
 		defer func() {
@@ -505,8 +581,9 @@ func (r *Resolver) ResolveGraphQLResponse(ctx *Context, response *GraphQLRespons
 	if responseBuf.Errors.Len() > 0 {
 		r.MergeBufPairErrors(responseBuf, buf)
 	}
+	addTraceIDToErrors(ctx, buf)
 
-	return writeGraphqlResponse(buf, writer, ignoreData)
+	return writeGraphqlResponseWithExtensions(buf, writer, ignoreData, ctx.responseExtensionsObject())
 }
 
 func writeAndFlush(writer FlushWriter, msg []byte) error {
@@ -518,6 +595,19 @@ func writeAndFlush(writer FlushWriter, msg []byte) error {
 	return nil
 }
 
+// writeAndFlushError writes a response with a single error, which carries the trace id if the request is traced
+func writeAndFlushError(ctx *Context, writer FlushWriter, message string) error {
+	buf := NewBufPair()
+	buf.WriteErr([]byte(message), nil, nil, nil)
+	addTraceIDToErrors(ctx, buf)
+
+	msg := make([]byte, 0, buf.Errors.Len()+13)
+	msg = append(msg, `{"errors":[`...)
+	msg = append(msg, buf.Errors.Bytes()...)
+	msg = append(msg, `]}`...)
+	return writeAndFlush(writer, msg)
+}
+
 func (r *Resolver) ResolveGraphQLSubscription(ctx *Context, subscription *GraphQLSubscription, writer FlushWriter) (err error) {
 
 	buf := r.getBufPair()
@@ -536,37 +626,69 @@ func (r *Resolver) ResolveGraphQLSubscription(ctx *Context, subscription *GraphQ
 
 	next := make(chan []byte)
 	if subscription.Trigger.Source == nil {
-		msg := []byte(`{"errors":[{"message":"no data source found"}]}`)
-		return writeAndFlush(writer, msg)
+		return writeAndFlushError(ctx, writer, "no data source found")
 	}
 
 	err = subscription.Trigger.Source.Start(c, subscriptionInput, next)
 	if err != nil {
 		if errors.Is(err, ErrUnableToResolve) {
-			msg := []byte(`{"errors":[{"message":"unable to resolve"}]}`)
-			return writeAndFlush(writer, msg)
+			return writeAndFlushError(ctx, writer, "unable to resolve")
 		}
 		return err
 	}
 
+	coalescing := &subscription.Trigger.Coalescing
+	var (
+		window  <-chan time.Time // window - is open while the events are coalesced into pending
+		pending []byte
+	)
+
 	for {
 		select {
 		case <-resolverDone:
 			return nil
-		default:
-			data, ok := <-next
+		case <-c.Done():
+			// the client has gone away, the deferred cancel stops the trigger
+			return nil
+		case <-window:
+			window = nil
+			if pending == nil {
+				continue
+			}
+			if err = r.resolveSubscriptionEvent(ctx, subscription, pending, writer); err != nil {
+				return err
+			}
+			pending = nil
+			window = time.After(coalescing.Window)
+		case data, ok := <-next:
 			if !ok {
+				if pending != nil {
+					return r.resolveSubscriptionEvent(ctx, subscription, pending, writer)
+				}
 				return nil
 			}
-			err = r.ResolveGraphQLResponse(ctx, subscription.Response, data, writer)
-			if err != nil {
+			if window != nil {
+				pending = coalescing.coalesce(pending, data)
+				continue
+			}
+			if err = r.resolveSubscriptionEvent(ctx, subscription, data, writer); err != nil {
 				return err
 			}
-			writer.Flush()
+			if coalescing.Window > 0 {
+				window = time.After(coalescing.Window)
+			}
 		}
 	}
 }
 
+func (r *Resolver) resolveSubscriptionEvent(ctx *Context, subscription *GraphQLSubscription, data []byte, writer FlushWriter) error {
+	if err := r.ResolveGraphQLResponse(ctx, subscription.Response, data, writer); err != nil {
+		return err
+	}
+	writer.Flush()
+	return nil
+}
+
 func (r *Resolver) ResolveGraphQLStreamingResponse(ctx *Context, response *GraphQLStreamingResponse, data []byte, writer FlushWriter) (err error) {
 
 	if err := r.validateContext(ctx); err != nil {
@@ -717,7 +839,7 @@ func (r *Resolver) resolveEmptyObject(b *fastbuffer.FastBuffer) {
 
 func (r *Resolver) resolveArray(ctx *Context, array *Array, data []byte, arrayBuf *BufPair) (err error) {
 	if len(array.Path) != 0 {
-		data, _, _, _ = jsonparser.Get(data, array.Path...)
+		data, _, _ = ctx.getJSON(data, array.Path)
 	}
 
 	if bytes.Equal(data, emptyArray) {
@@ -780,12 +902,17 @@ func (r *Resolver) resolveArraySynchronous(ctx *Context, array *Array, arrayItem
 
 		ctx.addIntegerPathElement(i)
 		err = r.resolveNode(ctx, array.Item, (*arrayItems)[i], itemBuf)
+		r.addNullArrayItemError(ctx, err, itemBuf)
 		ctx.removeLastPathElement()
 		if err != nil {
-			if errors.Is(err, errNonNullableFieldValueIsNull) && array.Nullable {
+			if errors.Is(err, errNonNullableFieldValueIsNull) {
 				arrayBuf.Data.Reset()
-				r.resolveNull(arrayBuf.Data)
-				return nil
+				r.MergeBufPairErrors(itemBuf, arrayBuf)
+				if array.Nullable {
+					r.resolveNull(arrayBuf.Data)
+					return nil
+				}
+				return
 			}
 			if errors.Is(err, errTypeNameSkipped) {
 				err = nil
@@ -804,6 +931,16 @@ func (r *Resolver) resolveArraySynchronous(ctx *Context, array *Array, arrayItem
 	return
 }
 
+// addNullArrayItemError reports a non-nullable array item which resolved to null at the path of the item,
+// e.g. ["matrix","1","0"] for a null value in a list of lists of non-null scalars.
+// Items which already reported an error themselves, e.g. objects with a non-nullable field being null, are skipped.
+func (r *Resolver) addNullArrayItemError(ctx *Context, err error, itemBuf *BufPair) {
+	if !errors.Is(err, errNonNullableFieldValueIsNull) || itemBuf.HasErrors() {
+		return
+	}
+	r.addResolveError(ctx, itemBuf)
+}
+
 func (r *Resolver) resolveArrayAsynchronous(ctx *Context, array *Array, arrayItems *[][]byte, arrayBuf *BufPair) (err error) {
 
 	arrayBuf.Data.WriteBytes(lBrack)
@@ -826,7 +963,9 @@ func (r *Resolver) resolveArrayAsynchronous(ctx *Context, array *Array, arrayIte
 		cloned := ctx.Clone()
 		go func(ctx Context, i int) {
 			ctx.addPathElement([]byte(strconv.Itoa(i)))
-			if e := r.resolveNode(&ctx, array.Item, itemData, itemBuf); e != nil && !errors.Is(e, errTypeNameSkipped) {
+			e := r.resolveNode(&ctx, array.Item, itemData, itemBuf)
+			r.addNullArrayItemError(&ctx, e, itemBuf)
+			if e != nil && !errors.Is(e, errTypeNameSkipped) {
 				select {
 				case errCh <- e:
 				default:
@@ -845,10 +984,15 @@ func (r *Resolver) resolveArrayAsynchronous(ctx *Context, array *Array, arrayIte
 	}
 
 	if err != nil {
-		if errors.Is(err, errNonNullableFieldValueIsNull) && array.Nullable {
+		if errors.Is(err, errNonNullableFieldValueIsNull) {
 			arrayBuf.Data.Reset()
-			r.resolveNull(arrayBuf.Data)
-			return nil
+			for i := range *bufSlice {
+				r.MergeBufPairErrors((*bufSlice)[i], arrayBuf)
+			}
+			if array.Nullable {
+				r.resolveNull(arrayBuf.Data)
+				return nil
+			}
 		}
 		return
 	}
@@ -880,7 +1024,7 @@ func (r *Resolver) exportField(ctx *Context, export *FieldExport, value []byte)
 }
 
 func (r *Resolver) resolveInteger(ctx *Context, integer *Integer, data []byte, integerBuf *BufPair) error {
-	value, dataType, _, err := jsonparser.Get(data, integer.Path...)
+	value, dataType, err := ctx.getJSON(data, integer.Path)
 	if err != nil || dataType != jsonparser.Number {
 		if !integer.Nullable {
 			return errNonNullableFieldValueIsNull
@@ -894,7 +1038,7 @@ func (r *Resolver) resolveInteger(ctx *Context, integer *Integer, data []byte, i
 }
 
 func (r *Resolver) resolveFloat(ctx *Context, floatValue *Float, data []byte, floatBuf *BufPair) error {
-	value, dataType, _, err := jsonparser.Get(data, floatValue.Path...)
+	value, dataType, err := ctx.getJSON(data, floatValue.Path)
 	if err != nil || dataType != jsonparser.Number {
 		if !floatValue.Nullable {
 			return errNonNullableFieldValueIsNull
@@ -908,7 +1052,7 @@ func (r *Resolver) resolveFloat(ctx *Context, floatValue *Float, data []byte, fl
 }
 
 func (r *Resolver) resolveBoolean(ctx *Context, boolean *Boolean, data []byte, booleanBuf *BufPair) error {
-	value, valueType, _, err := jsonparser.Get(data, boolean.Path...)
+	value, valueType, err := ctx.getJSON(data, boolean.Path)
 	if err != nil || valueType != jsonparser.Boolean {
 		if !boolean.Nullable {
 			return errNonNullableFieldValueIsNull
@@ -928,7 +1072,7 @@ func (r *Resolver) resolveString(ctx *Context, str *String, data []byte, stringB
 		err       error
 	)
 
-	value, valueType, _, err = jsonparser.Get(data, str.Path...)
+	value, valueType, err = ctx.getJSON(data, str.Path)
 	if err != nil || valueType != jsonparser.String {
 		if err == nil && str.UnescapeResponseJson {
 			switch valueType {
@@ -1036,9 +1180,69 @@ func (r *Resolver) addResolveError(ctx *Context, objectBuf *BufPair) {
 	objectBuf.WriteErr(unableToResolveMsg, locations.Bytes(), pathBytes, nil)
 }
 
+// addTraceIDToErrors adds the trace id of a traced request to the extensions of all errors of the buffer,
+// regardless of whether the error was raised by the resolver or returned by an upstream.
+// Errors are only extended right before they are written, as responses of single flight fetches are shared by requests with different traces.
+func addTraceIDToErrors(ctx *Context, buf *BufPair) {
+	if !buf.HasErrors() {
+		return
+	}
+	traceID := tracing.TraceIDFromContext(ctx.Context)
+	if traceID == "" {
+		return
+	}
+
+	errs := make([]byte, 0, buf.Errors.Len()+2)
+	errs = append(errs, lBrack...)
+	errs = append(errs, buf.Errors.Bytes()...)
+	errs = append(errs, rBrack...)
+	quotedTraceID := []byte(`"` + traceID + `"`)
+
+	buf.Errors.Reset()
+	_, _ = jsonparser.ArrayEach(errs, func(value []byte, dataType jsonparser.ValueType, _ int, _ error) {
+		if dataType == jsonparser.Object {
+			// the capacity is limited as jsonparser.Set might append to the value, which would overwrite the following errors
+			if extended, err := jsonparser.Set(value[:len(value):len(value)], quotedTraceID, "extensions", "traceId"); err == nil {
+				value = extended
+			}
+		}
+		if buf.HasErrors() {
+			buf.writeErrors(comma)
+		}
+		buf.writeErrors(value)
+	})
+}
+
+// injectTypeName adds the __typename field resolved by the TypeNameResolver to the data of an abstract type value
+// so that __typename selections and fragments on concrete types can be resolved as if the upstream returned it
+func (r *Resolver) injectTypeName(resolver TypeNameResolver, data []byte) []byte {
+	if _, _, _, err := jsonparser.Get(data, "__typename"); err == nil {
+		return data
+	}
+	typeName, ok := resolver.ResolveTypeName(data)
+	if !ok || len(typeName) == 0 {
+		return data
+	}
+	start := bytes.IndexByte(data, '{')
+	if start == -1 {
+		return data
+	}
+	// don't use jsonparser.Set as it would write into the buffer the data belongs to
+	rest := bytes.TrimLeft(data[start+1:], " \t\r\n")
+	withTypeName := make([]byte, 0, len(data)+len(typeName)+16)
+	withTypeName = append(withTypeName, data[:start+1]...)
+	withTypeName = append(withTypeName, `"__typename":"`...)
+	withTypeName = append(withTypeName, typeName...)
+	withTypeName = append(withTypeName, quote...)
+	if len(rest) != 0 && rest[0] != '}' {
+		withTypeName = append(withTypeName, comma...)
+	}
+	return append(withTypeName, rest...)
+}
+
 func (r *Resolver) resolveObject(ctx *Context, object *Object, data []byte, objectBuf *BufPair) (err error) {
 	if len(object.Path) != 0 {
-		data, _, _, _ = jsonparser.Get(data, object.Path...)
+		data, _, _ = ctx.getJSON(data, object.Path)
 
 		if len(data) == 0 || bytes.Equal(data, literal.NULL) {
 			// we will not traverse the children if the object is null
@@ -1061,19 +1265,41 @@ func (r *Resolver) resolveObject(ctx *Context, object *Object, data []byte, obje
 		data = bytes.ReplaceAll(data, []byte(`\"`), []byte(`"`))
 	}
 
-	var set *resultSet
+	if object.TypeNameResolver != nil {
+		data = r.injectTypeName(object.TypeNameResolver, data)
+	}
+
+	var (
+		set                *resultSet
+		serialFetch        *SerialFetch
+		pendingSerialFetch int
+	)
 	if object.Fetch != nil {
 		set = r.getResultSet()
 		defer r.freeResultSet(set)
-		err = r.resolveFetch(ctx, object.Fetch, data, set)
-		if err != nil {
-			return
-		}
-		for i := range set.buffers {
-			r.MergeBufPairErrors(set.buffers[i], objectBuf)
+		serialFetch, _ = object.Fetch.(*SerialFetch)
+		if serialFetch != nil {
+			// the fetches are resolved lazily before the first field of their buffer
+			defer func() {
+				if err == nil {
+					err = r.resolveSerialFetchesUntil(ctx, serialFetch, &pendingSerialFetch, -1, data, set, objectBuf)
+				}
+			}()
+		} else {
+			err = r.resolveFetch(ctx, object.Fetch, data, set)
+			if err != nil {
+				return
+			}
+			for i := range set.buffers {
+				r.MergeBufPairErrors(set.buffers[i], objectBuf)
+			}
 		}
 	}
 
+	if indexes := r.indexObjectData(ctx, object, data, set); indexes != 0 {
+		defer r.freeJSONIndexes(ctx, indexes)
+	}
+
 	fieldBuf := r.getBufPair()
 	defer r.freeBufPair(fieldBuf)
 
@@ -1101,7 +1327,15 @@ func (r *Resolver) resolveObject(ctx *Context, object *Object, data []byte, obje
 			}
 		}
 
-		var fieldData []byte
+		var (
+			fieldData    []byte
+			fetchSkipped bool
+		)
+		if serialFetch != nil && object.Fields[i].HasBuffer {
+			if err = r.resolveSerialFetchesUntil(ctx, serialFetch, &pendingSerialFetch, object.Fields[i].BufferID, data, set, objectBuf); err != nil {
+				return
+			}
+		}
 		if set != nil && object.Fields[i].HasBuffer {
 			buffer, ok := set.buffers[object.Fields[i].BufferID]
 			if ok {
@@ -1109,13 +1343,18 @@ func (r *Resolver) resolveObject(ctx *Context, object *Object, data []byte, obje
 				ctx.resetResponsePathElements()
 				ctx.lastFetchID = object.Fields[i].BufferID
 			}
+			fetchSkipped = set.skipped[object.Fields[i].BufferID]
 		} else {
 			fieldData = data
 		}
 
-		if object.Fields[i].OnTypeName != nil {
+		if object.Fields[i].hasTypeCondition() {
 			typeName, _, _, _ := jsonparser.Get(fieldData, "__typename")
-			if !bytes.Equal(typeName, object.Fields[i].OnTypeName) {
+			if typeName == nil && set != nil && object.Fields[i].HasBuffer {
+				// entity fetches of non GraphQL data sources don't return the __typename, it's part of the enclosing object
+				typeName, _, _, _ = jsonparser.Get(data, "__typename")
+			}
+			if !object.Fields[i].appliesToTypeName(typeName) {
 				typeNameSkip = true
 				// Restore the response elements that may have been reset above.
 				ctx.responseElements = responseElements
@@ -1124,19 +1363,41 @@ func (r *Resolver) resolveObject(ctx *Context, object *Object, data []byte, obje
 			}
 		}
 
-		if first {
-			objectBuf.Data.WriteBytes(lBrace)
+		if object.Fields[i].keyTemplate != nil {
+			objectBuf.Data.WriteBytes(object.Fields[i].keyTemplate)
 			first = false
 		} else {
-			objectBuf.Data.WriteBytes(comma)
+			if first {
+				objectBuf.Data.WriteBytes(lBrace)
+				first = false
+			} else {
+				objectBuf.Data.WriteBytes(comma)
+			}
+			objectBuf.Data.WriteBytes(quote)
+			objectBuf.Data.WriteBytes(object.Fields[i].Name)
+			objectBuf.Data.WriteBytes(quote)
+			objectBuf.Data.WriteBytes(colon)
+		}
+		if fetchSkipped && len(object.Fields[i].FetchSkippedValue) != 0 {
+			objectBuf.Data.WriteBytes(object.Fields[i].FetchSkippedValue)
+			ctx.responseElements = responseElements
+			ctx.lastFetchID = lastFetchID
+			continue
 		}
-		objectBuf.Data.WriteBytes(quote)
-		objectBuf.Data.WriteBytes(object.Fields[i].Name)
-		objectBuf.Data.WriteBytes(quote)
-		objectBuf.Data.WriteBytes(colon)
 		ctx.addPathElement(object.Fields[i].Name)
 		ctx.setPosition(object.Fields[i].Position)
-		err = r.resolveNode(ctx, object.Fields[i].Value, fieldData, fieldBuf)
+		// the field name is accounted with its quotes and the colon
+		err = ctx.accountResponseSize(len(object.Fields[i].Name) + 3)
+		if err == nil {
+			err = r.resolveNode(ctx, object.Fields[i].Value, fieldData, fieldBuf)
+		}
+		if err == nil && len(object.Fields[i].FallbackValue) != 0 && bytes.Equal(fieldBuf.Data.Bytes(), null) {
+			fieldBuf.Data.Reset()
+			fieldBuf.Data.WriteBytes(object.Fields[i].FallbackValue)
+		}
+		if err == nil && len(object.Fields[i].Directives) != 0 {
+			err = r.resolveFieldDirectives(ctx, object.Fields[i].Directives, fieldBuf)
+		}
 		ctx.removeLastPathElement()
 		ctx.responseElements = responseElements
 		ctx.lastFetchID = lastFetchID
@@ -1148,6 +1409,7 @@ func (r *Resolver) resolveObject(ctx *Context, object *Object, data []byte, obje
 			}
 			if errors.Is(err, errNonNullableFieldValueIsNull) {
 				objectBuf.Data.Reset()
+				arrayItemReported := fieldBuf.HasErrors()
 				r.MergeBufPairErrors(fieldBuf, objectBuf)
 
 				if object.Nullable {
@@ -1156,7 +1418,14 @@ func (r *Resolver) resolveObject(ctx *Context, object *Object, data []byte, obje
 				}
 
 				// if fied is of object type than we should not add resolve error here
-				if _, ok := object.Fields[i].Value.(*Object); !ok {
+				// the same applies to arrays which already reported the null item
+				switch object.Fields[i].Value.(type) {
+				case *Object:
+				case *Array:
+					if !arrayItemReported {
+						r.addResolveError(ctx, objectBuf)
+					}
+				default:
 					r.addResolveError(ctx, objectBuf)
 				}
 			}
@@ -1218,13 +1487,23 @@ func (r *Resolver) freeResultSet(set *resultSet) {
 		r.bufPairPool.Put(set.buffers[i])
 		delete(set.buffers, i)
 	}
+	for i := range set.skipped {
+		delete(set.skipped, i)
+	}
 	r.resultSetPool.Put(set)
 }
 
 func (r *Resolver) resolveFetch(ctx *Context, fetch Fetch, data []byte, set *resultSet) (err error) {
+	// don't start fetches for an operation which was cancelled, e.g. because the client disconnected
+	if ctx.Context != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
 
 	switch f := fetch.(type) {
 	case *SingleFetch:
+		if r.trySkipSingleFetch(ctx, f, data, set) {
+			return nil
+		}
 		preparedInput := r.getBufPair()
 		defer r.freeBufPair(preparedInput)
 		err = r.prepareSingleFetch(ctx, f, data, set, preparedInput.Data)
@@ -1233,6 +1512,9 @@ func (r *Resolver) resolveFetch(ctx *Context, fetch Fetch, data []byte, set *res
 		}
 		err = r.resolveSingleFetch(ctx, f, preparedInput.Data, set.buffers[f.BufferId])
 	case *BatchFetch:
+		if r.trySkipSingleFetch(ctx, f.Fetch, data, set) {
+			return nil
+		}
 		preparedInput := r.getBufPair()
 		defer r.freeBufPair(preparedInput)
 		err = r.prepareSingleFetch(ctx, f.Fetch, data, set, preparedInput.Data)
@@ -1242,10 +1524,46 @@ func (r *Resolver) resolveFetch(ctx *Context, fetch Fetch, data []byte, set *res
 		err = r.resolveBatchFetch(ctx, f, preparedInput.Data, set.buffers[f.Fetch.BufferId])
 	case *ParallelFetch:
 		err = r.resolveParallelFetch(ctx, f, data, set)
+	case *SerialFetch:
+		for i := range f.Fetches {
+			if err = r.resolveFetch(ctx, f.Fetches[i], data, set); err != nil {
+				return err
+			}
+		}
 	}
 	return
 }
 
+// resolveSerialFetchesUntil resolves the pending fetches of a SerialFetch up to and including the one filling the buffer
+// and merges their errors into the object, all pending fetches are resolved for a negative buffer
+func (r *Resolver) resolveSerialFetchesUntil(ctx *Context, fetch *SerialFetch, pending *int, bufferID int, data []byte, set *resultSet, objectBuf *BufPair) error {
+	for *pending < len(fetch.Fetches) {
+		next := fetch.Fetches[*pending]
+		*pending++
+		if err := r.resolveFetch(ctx, next, data, set); err != nil {
+			return err
+		}
+		nextBufferID := fetchBufferID(next)
+		if buf, ok := set.buffers[nextBufferID]; ok {
+			r.MergeBufPairErrors(buf, objectBuf)
+		}
+		if nextBufferID == bufferID {
+			return nil
+		}
+	}
+	return nil
+}
+
+func fetchBufferID(fetch Fetch) int {
+	switch f := fetch.(type) {
+	case *SingleFetch:
+		return f.BufferId
+	case *BatchFetch:
+		return f.Fetch.BufferId
+	}
+	return -1
+}
+
 func (r *Resolver) resolveParallelFetch(ctx *Context, fetch *ParallelFetch, data []byte, set *resultSet) (err error) {
 	preparedInputs := r.getBufPairSlice()
 	defer r.freeBufPairSlice(preparedInputs)
@@ -1259,6 +1577,10 @@ func (r *Resolver) resolveParallelFetch(ctx *Context, fetch *ParallelFetch, data
 		wg.Add(1)
 		switch f := fetch.Fetches[i].(type) {
 		case *SingleFetch:
+			if r.trySkipSingleFetch(ctx, f, data, set) {
+				wg.Done()
+				continue
+			}
 			preparedInput := r.getBufPair()
 			err = r.prepareSingleFetch(ctx, f, data, set, preparedInput.Data)
 			if err != nil {
@@ -1270,6 +1592,10 @@ func (r *Resolver) resolveParallelFetch(ctx *Context, fetch *ParallelFetch, data
 				return r.resolveSingleFetch(ctx, f, preparedInput.Data, buf)
 			})
 		case *BatchFetch:
+			if r.trySkipSingleFetch(ctx, f.Fetch, data, set) {
+				wg.Done()
+				continue
+			}
 			preparedInput := r.getBufPair()
 			err = r.prepareSingleFetch(ctx, f.Fetch, data, set, preparedInput.Data)
 			if err != nil {
@@ -1295,6 +1621,21 @@ func (r *Resolver) resolveParallelFetch(ctx *Context, fetch *ParallelFetch, data
 	return
 }
 
+// trySkipSingleFetch registers an empty buffer for a fetch which is not applicable to the current object
+// or whose skip conditions are met by the variables and returns true if the fetch was skipped
+func (r *Resolver) trySkipSingleFetch(ctx *Context, fetch *SingleFetch, data []byte, set *resultSet) bool {
+	if !fetch.isApplicable(data) {
+		set.buffers[fetch.BufferId] = r.getBufPair()
+		return true
+	}
+	if fetch.SkipConditions.AreMet(ctx.Variables) {
+		set.buffers[fetch.BufferId] = r.getBufPair()
+		set.skipped[fetch.BufferId] = true
+		return true
+	}
+	return false
+}
+
 func (r *Resolver) prepareSingleFetch(ctx *Context, fetch *SingleFetch, data []byte, set *resultSet, preparedInput *fastbuffer.FastBuffer) (err error) {
 	err = fetch.InputTemplate.Render(ctx, data, preparedInput)
 	buf := r.getBufPair()
@@ -1302,23 +1643,30 @@ func (r *Resolver) prepareSingleFetch(ctx *Context, fetch *SingleFetch, data []b
 	return
 }
 
-func (r *Resolver) resolveBatchFetch(ctx *Context, fetch *BatchFetch, preparedInput *fastbuffer.FastBuffer, buf *BufPair) error {
+func (r *Resolver) resolveBatchFetch(ctx *Context, fetch *BatchFetch, preparedInput *fastbuffer.FastBuffer, buf *BufPair) (err error) {
 	if r.dataLoaderEnabled {
-		return ctx.dataLoader.LoadBatch(ctx, fetch, buf)
+		err = ctx.dataLoader.LoadBatch(ctx, fetch, buf)
+	} else {
+		ctx.recordFetch(fetch.Fetch, preparedInput.Bytes())
+		err = r.fetcher.FetchBatch(ctx, fetch, []*fastbuffer.FastBuffer{preparedInput}, []*BufPair{buf})
 	}
-
-	if err := r.fetcher.FetchBatch(ctx, fetch, []*fastbuffer.FastBuffer{preparedInput}, []*BufPair{buf}); err != nil {
-		return err
+	if fetch.Fetch.Optional {
+		return r.degradeOptionalFetch(ctx, fetch.Fetch, buf, err)
 	}
-
-	return nil
+	return err
 }
 
-func (r *Resolver) resolveSingleFetch(ctx *Context, fetch *SingleFetch, preparedInput *fastbuffer.FastBuffer, buf *BufPair) error {
+func (r *Resolver) resolveSingleFetch(ctx *Context, fetch *SingleFetch, preparedInput *fastbuffer.FastBuffer, buf *BufPair) (err error) {
 	if r.dataLoaderEnabled && !fetch.DisableDataLoader {
-		return ctx.dataLoader.Load(ctx, fetch, buf)
+		err = ctx.dataLoader.Load(ctx, fetch, buf)
+	} else {
+		ctx.recordFetch(fetch, preparedInput.Bytes())
+		err = r.fetcher.Fetch(ctx, fetch, preparedInput, buf)
 	}
-	return r.fetcher.Fetch(ctx, fetch, preparedInput, buf)
+	if fetch.Optional {
+		return r.degradeOptionalFetch(ctx, fetch, buf, err)
+	}
+	return err
 }
 
 type Object struct {
@@ -1327,6 +1675,22 @@ type Object struct {
 	Fields               []*Field
 	Fetch                Fetch
 	UnescapeResponseJson bool `json:"unescape_response_json,omitempty"`
+	// TypeNameResolver is set on objects of abstract types (interfaces and unions)
+	// to determine the concrete type if the upstream response doesn't contain a __typename field
+	TypeNameResolver TypeNameResolver `json:"-"`
+}
+
+// TypeNameResolver resolves the concrete type name from the data of an abstract type value.
+// It's only consulted if the data doesn't contain a __typename field.
+type TypeNameResolver interface {
+	ResolveTypeName(data []byte) (typeName []byte, ok bool)
+}
+
+// TypeNameResolverFunc allows using an ordinary function as TypeNameResolver
+type TypeNameResolverFunc func(data []byte) (typeName []byte, ok bool)
+
+func (f TypeNameResolverFunc) ResolveTypeName(data []byte) (typeName []byte, ok bool) {
+	return f(data)
 }
 
 func (_ *Object) NodeKind() NodeKind {
@@ -1358,6 +1722,36 @@ type Field struct {
 	SkipVariableName        string
 	IncludeDirectiveDefined bool
 	IncludeVariableName     string
+	// FetchSkippedValue is the JSON value of the field if the fetch of its buffer was skipped because of its SkipConditions
+	// If it's empty, the field resolves to null
+	FetchSkippedValue []byte
+	// FallbackValue is the JSON value of the nullable field if it resolves to null.
+	// It's written as it is, the planner only sets it for fields of scalar and enum types after validating it.
+	FallbackValue []byte
+	// Directives are the executable directives applied to the resolved value, see DirectiveMiddleware
+	Directives []FieldDirective
+	// OnTypeNames are the possible types of an abstract type condition, e.g. of a fragment on an interface,
+	// the field is only resolved for objects with one of the given __typename values, like for OnTypeName
+	OnTypeNames [][]byte
+	// keyTemplate - holds the precompiled key of the field including the preceding punctuation, see Object.PrecompileTemplate
+	keyTemplate []byte
+}
+
+func (f *Field) hasTypeCondition() bool {
+	return f.OnTypeName != nil || len(f.OnTypeNames) != 0
+}
+
+// appliesToTypeName returns true if the type condition of the field is met by the __typename of the object
+func (f *Field) appliesToTypeName(typeName []byte) bool {
+	if f.OnTypeName != nil && bytes.Equal(typeName, f.OnTypeName) {
+		return true
+	}
+	for i := range f.OnTypeNames {
+		if bytes.Equal(typeName, f.OnTypeNames[i]) {
+			return true
+		}
+	}
+	return false
 }
 
 type Position struct {
@@ -1386,6 +1780,8 @@ func (_ *Null) NodeKind() NodeKind {
 
 type resultSet struct {
 	buffers map[int]*BufPair
+	// skipped contains the buffers of fetches which were skipped because of their SkipConditions
+	skipped map[int]bool
 }
 
 type SingleFetch struct {
@@ -1406,6 +1802,117 @@ type SingleFetch struct {
 	// This is the case, e.g. when using batching and one sibling is null, resulting in a null value for one batch item
 	// Returning null in this case tells the batch implementation to skip this item
 	SetTemplateOutputToNullOnVariableNull bool
+	// OnTypeNames restricts the fetch to objects with one of the given __typename values.
+	// It's set if the members of an abstract type are resolved by different data sources,
+	// e.g. a list of union members, so that each fetch skips the objects it's not responsible for.
+	OnTypeNames [][]byte
+	// SkipConditions skip the fetch if one of them is met by the variables of the request,
+	// e.g. to not call an expensive data source for an optional enrichment unless an argument is provided.
+	// Fields resolved from the buffer of a skipped fetch resolve to their FetchSkippedValue or null.
+	SkipConditions SkipConditions
+	// Optional fetches are non critical: if the fetch fails or the response contains errors,
+	// the fields resolved from its buffer resolve to null and the errors are added as warnings to the extensions of the response.
+	Optional bool
+}
+
+type SkipConditions []SkipCondition
+
+// AreMet returns true if any of the conditions is met by the variables
+func (s SkipConditions) AreMet(variables []byte) bool {
+	for i := range s {
+		if s[i].IsMet(variables) {
+			return true
+		}
+	}
+	return false
+}
+
+// SkipCondition is met if the variable is absent or null.
+// If Equals is set, it's met if the variable equals the JSON value of Equals instead.
+// An empty VariableName means that the condition was evaluated at planning time and is always met,
+// e.g. if the argument is not provided by the operation.
+type SkipCondition struct {
+	VariableName string
+	Equals       []byte
+}
+
+func (s *SkipCondition) IsMet(variables []byte) bool {
+	if s.VariableName == "" {
+		return true
+	}
+	value, dataType, offset, err := jsonparser.Get(variables, s.VariableName)
+	if err != nil || dataType == jsonparser.Null {
+		return s.MatchesValue(nil)
+	}
+	if dataType == jsonparser.String {
+		// jsonparser strips the quotes of strings, so we use the raw value to compare it to Equals
+		value = variables[offset-len(value)-2 : offset]
+	}
+	return s.MatchesValue(value)
+}
+
+// MatchesValue returns true if the JSON value meets the condition, nil stands for an absent or null value.
+// It's evaluated for every fetch, so scalars are compared without decoding them,
+// only objects and lists are decoded to compare them regardless of their formatting and key order.
+func (s *SkipCondition) MatchesValue(value []byte) bool {
+	if len(s.Equals) == 0 {
+		return value == nil || bytes.Equal(value, literal.NULL)
+	}
+	if value == nil {
+		value = literal.NULL
+	}
+	if bytes.Equal(s.Equals, value) {
+		return true
+	}
+	expected, expectedType, _, err := jsonparser.Get(s.Equals)
+	if err != nil {
+		return false
+	}
+	actual, actualType, _, err := jsonparser.Get(value)
+	if err != nil || expectedType != actualType {
+		return false
+	}
+	switch expectedType {
+	case jsonparser.Null:
+		return true
+	case jsonparser.Boolean:
+		return bytes.Equal(expected, actual)
+	case jsonparser.Number:
+		expectedNumber, expectedErr := jsonparser.ParseFloat(expected)
+		actualNumber, actualErr := jsonparser.ParseFloat(actual)
+		return expectedErr == nil && actualErr == nil && expectedNumber == actualNumber
+	case jsonparser.String:
+		// strings are compared unescaped, e.g. "\u0041" equals "A"
+		var expectedBuf, actualBuf [64]byte
+		expectedString, expectedErr := jsonparser.Unescape(expected, expectedBuf[:])
+		actualString, actualErr := jsonparser.Unescape(actual, actualBuf[:])
+		return expectedErr == nil && actualErr == nil && bytes.Equal(expectedString, actualString)
+	}
+	var expectedValue, actualValue interface{}
+	if err := json.Unmarshal(s.Equals, &expectedValue); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(value, &actualValue); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(expectedValue, actualValue)
+}
+
+// isApplicable returns false if the fetch is restricted to other types than the __typename of the data
+func (s *SingleFetch) isApplicable(data []byte) bool {
+	if len(s.OnTypeNames) == 0 {
+		return true
+	}
+	typeName, dataType, _, _ := jsonparser.Get(data, "__typename")
+	if dataType != jsonparser.String {
+		return true
+	}
+	for i := range s.OnTypeNames {
+		if bytes.Equal(typeName, s.OnTypeNames[i]) {
+			return true
+		}
+	}
+	return false
 }
 
 type ProcessResponseConfig struct {
@@ -1425,6 +1932,17 @@ func (_ *ParallelFetch) FetchKind() FetchKind {
 	return FetchKindParallel
 }
 
+// SerialFetch executes the fetches one after another, it's planned for the root fields of mutations spanning multiple data sources.
+// On an object, each fetch is started after the fields of the previous fetch are resolved including their nested fetches,
+// so the root fields are executed serially while the fetches within their results run in parallel.
+type SerialFetch struct {
+	Fetches []Fetch
+}
+
+func (_ *SerialFetch) FetchKind() FetchKind {
+	return FetchKindSerial
+}
+
 type BatchFetch struct {
 	Fetch        *SingleFetch
 	BatchFactory DataSourceBatchFactory
@@ -1511,6 +2029,8 @@ type GraphQLSubscriptionTrigger struct {
 	InputTemplate InputTemplate
 	Variables     Variables
 	Source        SubscriptionDataSource
+	// Coalescing limits the rate of the events sent to the client, see SubscriptionCoalescing
+	Coalescing SubscriptionCoalescing
 }
 
 type FlushWriter interface {
@@ -1681,6 +2201,10 @@ func (r *Resolver) freeWaitGroup(wg *sync.WaitGroup) {
 }
 
 func writeGraphqlResponse(buf *BufPair, writer io.Writer, ignoreData bool) (err error) {
+	return writeGraphqlResponseWithExtensions(buf, writer, ignoreData, nil)
+}
+
+func writeGraphqlResponseWithExtensions(buf *BufPair, writer io.Writer, ignoreData bool, extensions []byte) (err error) {
 	hasErrors := buf.Errors.Len() != 0
 	hasData := buf.Data.Len() != 0 && !ignoreData
 
@@ -1707,6 +2231,15 @@ func writeGraphqlResponse(buf *BufPair, writer io.Writer, ignoreData bool) (err
 	} else {
 		err = writeSafe(err, writer, literal.NULL)
 	}
+
+	if len(extensions) != 0 {
+		err = writeSafe(err, writer, comma)
+		err = writeSafe(err, writer, quote)
+		err = writeSafe(err, writer, literalExtensions)
+		err = writeSafe(err, writer, quote)
+		err = writeSafe(err, writer, colon)
+		err = writeSafe(err, writer, extensions)
+	}
 	err = writeSafe(err, writer, rBrace)
 
 	return err
@@ -3,6 +3,7 @@ package resolve
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/fastbuffer"
+	"github.com/wundergraph/graphql-go-tools/pkg/tracing"
 )
 
 type _fakeDataSource struct {
@@ -1223,6 +1225,48 @@ func TestResolver_ResolveNode(t *testing.T) {
 			}, Context{Context: context.Background()},
 			`{"pets":[{"name":"Woofie"}]}`
 	}))
+	t.Run("array response from data source without __typename resolved by type name resolver", testFn(false, false, func(t *testing.T, ctrl *gomock.Controller) (node Node, ctx Context, expectedOutput string) {
+		return &Object{
+				Fetch: &SingleFetch{
+					BufferId:   0,
+					DataSource: FakeDataSource(`[{"name":"Woofie","barks":true},{"name":"Mietzie"}]`),
+				},
+				Fields: []*Field{
+					{
+						BufferID:  0,
+						HasBuffer: true,
+						Name:      []byte("pets"),
+						Value: &Array{
+							Item: &Object{
+								TypeNameResolver: TypeNameResolverFunc(func(data []byte) ([]byte, bool) {
+									if bytes.Contains(data, []byte(`"barks"`)) {
+										return []byte("Dog"), true
+									}
+									return []byte("Cat"), true
+								}),
+								Fields: []*Field{
+									{
+										Name: []byte("__typename"),
+										Value: &String{
+											Path:       []string{"__typename"},
+											IsTypeName: true,
+										},
+									},
+									{
+										OnTypeName: []byte("Dog"),
+										Name:       []byte("name"),
+										Value: &String{
+											Path: []string{"name"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			}, Context{Context: context.Background()},
+			`{"pets":[{"__typename":"Dog","name":"Woofie"},{"__typename":"Cat"}]}`
+	}))
 	t.Run("non null object with field condition can be null", testFn(false, false, func(t *testing.T, ctrl *gomock.Controller) (node Node, ctx Context, expectedOutput string) {
 		return &Object{
 				Fetch: &SingleFetch{
@@ -1778,7 +1822,363 @@ func TestResolver_ResolveGraphQLResponse(t *testing.T) {
 			Data: &Object{
 				Nullable: true,
 			},
-		}, Context{Context: context.Background()}, `{"data":null}`
+		}, Context{Context: context.Background()}, `{"data":null}`
+	}))
+	t.Run("should not fetch when the context is cancelled", testFnWithError(false, false, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedErrorMessage string) {
+		mockDataSource := NewMockDataSource(ctrl)
+		cancelledCtx, cancel := context.WithCancel(context.Background())
+		cancel()
+		return &GraphQLResponse{
+			Data: &Object{
+				Fetch: &SingleFetch{
+					BufferId:   0,
+					DataSource: mockDataSource,
+				},
+				Fields: []*Field{
+					{
+						Name:      []byte("name"),
+						HasBuffer: true,
+						BufferID:  0,
+						Value: &String{
+							Path:     []string{"name"},
+							Nullable: true,
+						},
+					},
+				},
+			},
+		}, Context{Context: cancelledCtx}, context.Canceled.Error()
+	}))
+	t.Run("should skip fetches with met skip conditions", testFn(false, false, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
+		return &GraphQLResponse{
+			Data: &Object{
+				Fetch: &ParallelFetch{
+					Fetches: []Fetch{
+						&SingleFetch{
+							BufferId:   0,
+							DataSource: FakeDataSource(`{"hero":"Luke"}`),
+						},
+						&SingleFetch{
+							BufferId:   1,
+							DataSource: NewMockDataSource(ctrl),
+							SkipConditions: SkipConditions{
+								{
+									VariableName: "userId",
+								},
+							},
+						},
+						&SingleFetch{
+							BufferId:   2,
+							DataSource: NewMockDataSource(ctrl),
+							SkipConditions: SkipConditions{
+								{
+									VariableName: "mode",
+									Equals:       []byte(`"none"`),
+								},
+							},
+						},
+					},
+				},
+				Fields: []*Field{
+					{
+						Name:      []byte("hero"),
+						HasBuffer: true,
+						BufferID:  0,
+						Value: &String{
+							Path: []string{"hero"},
+						},
+					},
+					{
+						Name:      []byte("recommendations"),
+						HasBuffer: true,
+						BufferID:  1,
+						Value: &Array{
+							Path: []string{"recommendations"},
+							Item: &String{},
+						},
+						FetchSkippedValue: []byte(`[]`),
+					},
+					{
+						Name:      []byte("similar"),
+						HasBuffer: true,
+						BufferID:  2,
+						Value: &String{
+							Path:     []string{"similar"},
+							Nullable: true,
+						},
+					},
+				},
+			},
+		}, Context{Context: context.Background(), Variables: []byte(`{"userId":null,"mode":"none"}`)}, `{"data":{"hero":"Luke","recommendations":[],"similar":null}}`
+	}))
+	t.Run("should fetch if skip conditions are not met", testFn(false, false, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
+		return &GraphQLResponse{
+			Data: &Object{
+				Fetch: &SingleFetch{
+					BufferId:   0,
+					DataSource: FakeDataSource(`{"recommendations":["Leia"]}`),
+					SkipConditions: SkipConditions{
+						{
+							VariableName: "userId",
+						},
+						{
+							VariableName: "mode",
+							Equals:       []byte(`"none"`),
+						},
+					},
+				},
+				Fields: []*Field{
+					{
+						Name:      []byte("recommendations"),
+						HasBuffer: true,
+						BufferID:  0,
+						Value: &Array{
+							Path: []string{"recommendations"},
+							Item: &String{},
+						},
+						FetchSkippedValue: []byte(`[]`),
+					},
+				},
+			},
+		}, Context{Context: context.Background(), Variables: []byte(`{"userId":"1","mode":"fast"}`)}, `{"data":{"recommendations":["Leia"]}}`
+	}))
+	t.Run("should report fetches repeated with different inputs in the extensions", testFn(false, false, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
+		ctx = Context{Context: context.Background()}
+		ctx.EnableRepeatedFetchDetection()
+		return &GraphQLResponse{
+			Data: &Object{
+				Fetch: &SingleFetch{
+					BufferId:             0,
+					DataSource:           FakeDataSource(`{"users":[{"id":1},{"id":2},{"id":2}]}`),
+					DataSourceIdentifier: []byte("users"),
+				},
+				Fields: []*Field{
+					{
+						Name:      []byte("users"),
+						HasBuffer: true,
+						BufferID:  0,
+						Value: &Array{
+							Path: []string{"users"},
+							Item: &Object{
+								Fetch: &SingleFetch{
+									BufferId: 1,
+									InputTemplate: InputTemplate{
+										Segments: []TemplateSegment{
+											{
+												SegmentType: StaticSegmentType,
+												Data:        []byte(`{"id":`),
+											},
+											{
+												SegmentType:        VariableSegmentType,
+												VariableKind:       ObjectVariableKind,
+												VariableSourcePath: []string{"id"},
+												Renderer:           NewPlainVariableRenderer(),
+											},
+											{
+												SegmentType: StaticSegmentType,
+												Data:        []byte(`}`),
+											},
+										},
+									},
+									DataSource:           FakeDataSource(`{"name":"Jens"}`),
+									DataSourceIdentifier: []byte("accounts"),
+								},
+								Fields: []*Field{
+									{
+										Name:      []byte("name"),
+										HasBuffer: true,
+										BufferID:  1,
+										Value: &String{
+											Path: []string{"name"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}, ctx, `{"data":{"users":[{"name":"Jens"},{"name":"Jens"},{"name":"Jens"}]},"extensions":{"repeatedFetches":[{"path":"/data/users/@","dataSource":"accounts","count":3}]}}`
+	}))
+	t.Run("should report the freshness of the data of each fetch in the extensions", testFn(false, false, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
+		cachedDataSource := NewMockDataSource(ctrl)
+		cachedDataSource.EXPECT().
+			Load(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&bytes.Buffer{})).
+			DoAndReturn(func(ctx context.Context, input []byte, w io.Writer) (err error) {
+				// the account of user 2 was cached earlier than the one of user 1
+				age := 30 * time.Second
+				if string(input) == `{"id":2}` {
+					age = 2 * time.Minute
+				}
+				ReportCachedResponse(ctx, age)
+				_, err = w.Write([]byte(`{"name":"Jens"}`))
+				return
+			}).
+			Times(2)
+		ctx = Context{Context: context.Background()}
+		ctx.EnableFreshnessTracking()
+		return &GraphQLResponse{
+			Data: &Object{
+				Fetch: &SingleFetch{
+					BufferId:             0,
+					DataSource:           FakeDataSource(`{"users":[{"id":1},{"id":2}]}`),
+					DataSourceIdentifier: []byte("users"),
+				},
+				Fields: []*Field{
+					{
+						Name:      []byte("users"),
+						HasBuffer: true,
+						BufferID:  0,
+						Value: &Array{
+							Path: []string{"users"},
+							Item: &Object{
+								Fetch: &SingleFetch{
+									BufferId: 1,
+									InputTemplate: InputTemplate{
+										Segments: []TemplateSegment{
+											{
+												SegmentType: StaticSegmentType,
+												Data:        []byte(`{"id":`),
+											},
+											{
+												SegmentType:        VariableSegmentType,
+												VariableKind:       ObjectVariableKind,
+												VariableSourcePath: []string{"id"},
+												Renderer:           NewPlainVariableRenderer(),
+											},
+											{
+												SegmentType: StaticSegmentType,
+												Data:        []byte(`}`),
+											},
+										},
+									},
+									DataSource:           cachedDataSource,
+									DataSourceIdentifier: []byte("accounts"),
+								},
+								Fields: []*Field{
+									{
+										Name:      []byte("name"),
+										HasBuffer: true,
+										BufferID:  1,
+										Value: &String{
+											Path: []string{"name"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}, ctx, `{"data":{"users":[{"name":"Jens"},{"name":"Jens"}]},"extensions":{"freshness":[{"path":"/data","dataSource":"users","source":"origin","age":0},{"path":"/data/users/@","dataSource":"accounts","source":"cache","age":120}]}}`
+	}))
+	t.Run("should null the fields of failed optional fetches and report warnings", testFn(false, false, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
+		failingDataSource := NewMockDataSource(ctrl)
+		failingDataSource.EXPECT().
+			Load(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&bytes.Buffer{})).
+			Return(errors.New("connection refused"))
+		return &GraphQLResponse{
+			Data: &Object{
+				Fetch: &SerialFetch{
+					Fetches: []Fetch{
+						&SingleFetch{
+							BufferId:   0,
+							DataSource: FakeDataSource(`{"hero":"Luke"}`),
+						},
+						&SingleFetch{
+							BufferId:             1,
+							DataSource:           failingDataSource,
+							DataSourceIdentifier: []byte("recommendations"),
+							Optional:             true,
+						},
+						&SingleFetch{
+							BufferId:             2,
+							DataSource:           FakeDataSource(`{"errors":[{"message":"reviews unavailable"}],"data":{"reviews":["good"]}}`),
+							DataSourceIdentifier: []byte("reviews"),
+							ProcessResponseConfig: ProcessResponseConfig{
+								ExtractGraphqlResponse: true,
+							},
+							Optional: true,
+						},
+					},
+				},
+				Fields: []*Field{
+					{
+						Name:      []byte("hero"),
+						HasBuffer: true,
+						BufferID:  0,
+						Value: &String{
+							Path: []string{"hero"},
+						},
+					},
+					{
+						Name:      []byte("recommendations"),
+						HasBuffer: true,
+						BufferID:  1,
+						Value: &Array{
+							Path:     []string{"recommendations"},
+							Nullable: true,
+							Item:     &String{},
+						},
+					},
+					{
+						Name:      []byte("reviews"),
+						HasBuffer: true,
+						BufferID:  2,
+						Value: &Array{
+							Path:     []string{"reviews"},
+							Nullable: true,
+							Item:     &String{},
+						},
+					},
+				},
+			},
+		}, Context{Context: context.Background()}, `{"data":{"hero":"Luke","recommendations":null,"reviews":null},"extensions":{"warnings":[{"message":"connection refused","path":"/data","dataSource":"recommendations"},{"message":"reviews unavailable","path":"/data","dataSource":"reviews"}]}}`
+	}))
+	t.Run("should not report repeated fetches if the detection is not enabled", testFn(false, false, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
+		return &GraphQLResponse{
+			Data: &Object{
+				Fetch: &SingleFetch{
+					BufferId:   0,
+					DataSource: FakeDataSource(`{"users":[{"id":1},{"id":2}]}`),
+				},
+				Fields: []*Field{
+					{
+						Name:      []byte("users"),
+						HasBuffer: true,
+						BufferID:  0,
+						Value: &Array{
+							Path: []string{"users"},
+							Item: &Object{
+								Fetch: &SingleFetch{
+									BufferId: 1,
+									InputTemplate: InputTemplate{
+										Segments: []TemplateSegment{
+											{
+												SegmentType:        VariableSegmentType,
+												VariableKind:       ObjectVariableKind,
+												VariableSourcePath: []string{"id"},
+												Renderer:           NewPlainVariableRenderer(),
+											},
+										},
+									},
+									DataSource: FakeDataSource(`{"name":"Jens"}`),
+								},
+								Fields: []*Field{
+									{
+										Name:      []byte("name"),
+										HasBuffer: true,
+										BufferID:  1,
+										Value: &String{
+											Path: []string{"name"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}, Context{Context: context.Background()}, `{"data":{"users":[{"name":"Jens"},{"name":"Jens"}]}}`
 	}))
 	t.Run("__typename without renaming", testFn(false, false, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
 		return &GraphQLResponse{
@@ -1957,6 +2357,41 @@ func TestResolver_ResolveGraphQLResponse(t *testing.T) {
 			},
 		}, Context{Context: context.Background()}, `{"errors":[{"message":"unable to resolve","locations":[{"line":3,"column":4}],"path":["country"]}],"data":null}`
 	}))
+	t.Run("resolve error of traced request carries trace id", testFn(false, false, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
+		return &GraphQLResponse{
+			Data: &Object{
+				Nullable: false,
+				Fields: []*Field{
+					{
+						BufferID:  0,
+						HasBuffer: true,
+						Name:      []byte("country"),
+						Position: Position{
+							Line:   3,
+							Column: 4,
+						},
+						Value: &Object{
+							Nullable: false,
+							Path:     []string{"country"},
+							Fields: []*Field{
+								{
+									Name: []byte("name"),
+									Value: &String{
+										Nullable: true,
+										Path:     []string{"name"},
+									},
+									Position: Position{
+										Line:   4,
+										Column: 5,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}, Context{Context: tracing.NewContext(context.Background(), tracing.TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7"})}, `{"errors":[{"message":"unable to resolve","locations":[{"line":3,"column":4}],"path":["country"],"extensions":{"traceId":"4bf92f3577b34da6a3ce929d0e0e4736"}}],"data":null}`
+	}))
 	t.Run("fetch with simple error", testFn(true, false, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
 		mockDataSource := NewMockDataSource(ctrl)
 		mockDataSource.EXPECT().
@@ -1990,6 +2425,41 @@ func TestResolver_ResolveGraphQLResponse(t *testing.T) {
 			},
 		}, Context{Context: context.Background()}, `{"errors":[{"message":"errorMessage"}],"data":{"name":null}}`
 	}))
+	t.Run("fetch errors of traced request carry trace id", testFn(true, false, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
+		mockDataSource := NewMockDataSource(ctrl)
+		mockDataSource.EXPECT().
+			Load(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&bytes.Buffer{})).
+			DoAndReturn(func(ctx context.Context, input []byte, w io.Writer) (err error) {
+				pair := NewBufPair()
+				pair.WriteErr([]byte("errorMessage"), nil, nil, nil)
+				pair.WriteErr([]byte("errorMessage2"), nil, nil, []byte(`{"code":"NOT_FOUND"}`))
+				pair.WriteErr([]byte("errorMessage3"), nil, nil, []byte(`null`))
+				return writeGraphqlResponse(pair, w, false)
+			})
+		return &GraphQLResponse{
+			Data: &Object{
+				Nullable: false,
+				Fetch: &SingleFetch{
+					BufferId:   0,
+					DataSource: mockDataSource,
+					ProcessResponseConfig: ProcessResponseConfig{
+						ExtractGraphqlResponse: true,
+					},
+				},
+				Fields: []*Field{
+					{
+						HasBuffer: true,
+						BufferID:  0,
+						Name:      []byte("name"),
+						Value: &String{
+							Path:     []string{"name"},
+							Nullable: true,
+						},
+					},
+				},
+			},
+		}, Context{Context: tracing.NewContext(context.Background(), tracing.TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7"})}, `{"errors":[{"message":"errorMessage","extensions":{"traceId":"4bf92f3577b34da6a3ce929d0e0e4736"}},{"message":"errorMessage2","extensions":{"code":"NOT_FOUND","traceId":"4bf92f3577b34da6a3ce929d0e0e4736"}},{"message":"errorMessage3","extensions":{"traceId":"4bf92f3577b34da6a3ce929d0e0e4736"}}],"data":{"name":null}}`
+	}))
 	t.Run("nested fetch error for non-nullable field", testFn(true, false, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
 		mockDataSource := NewMockDataSource(ctrl)
 		mockDataSource.EXPECT().
@@ -2322,6 +2792,49 @@ func TestResolver_ResolveGraphQLResponse(t *testing.T) {
 			},
 		}, Context{Context: context.Background()}, `{"data":{"notNullableArray":[]}}`
 	}))
+	matrixResponse := func(data string, innerListNullable, outerListNullable bool) *GraphQLResponse {
+		return &GraphQLResponse{
+			Data: &Object{
+				Fetch: &SingleFetch{
+					BufferId:   0,
+					DataSource: FakeDataSource(data),
+				},
+				Fields: []*Field{
+					{
+						HasBuffer: true,
+						BufferID:  0,
+						Name:      []byte("matrix"),
+						Position: Position{
+							Line:   1,
+							Column: 3,
+						},
+						Value: &Array{
+							Path:     []string{"matrix"},
+							Nullable: outerListNullable,
+							Item: &Array{
+								Nullable: innerListNullable,
+								Item: &Float{
+									Nullable: false,
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	t.Run("nested arrays should resolve correctly", testFn(false, false, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
+		return matrixResponse(`{"matrix":[[1.5,2],[],[3]]}`, false, false), Context{Context: context.Background()}, `{"data":{"matrix":[[1.5,2],[],[3]]}}`
+	}))
+	t.Run("null item in nullable inner array should null the inner array only", testFn(false, false, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
+		return matrixResponse(`{"matrix":[[1.5,null],[3]]}`, true, false), Context{Context: context.Background()}, `{"errors":[{"message":"unable to resolve","locations":[{"line":1,"column":3}],"path":["matrix","0","1"]}],"data":{"matrix":[null,[3]]}}`
+	}))
+	t.Run("null non nullable inner array should null the nullable outer array", testFn(false, false, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
+		return matrixResponse(`{"matrix":[[1.5],null]}`, false, true), Context{Context: context.Background()}, `{"errors":[{"message":"unable to resolve","locations":[{"line":1,"column":3}],"path":["matrix","1"]}],"data":{"matrix":null}}`
+	}))
+	t.Run("null item in non nullable matrix should bubble up with a single error", testFn(false, false, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
+		return matrixResponse(`{"matrix":[[1.5],[null]]}`, false, false), Context{Context: context.Background()}, `{"errors":[{"message":"unable to resolve","locations":[{"line":1,"column":3}],"path":["matrix","1","0"]}],"data":null}`
+	}))
 	t.Run("when data null not nullable array should resolve to data null and errors", testFn(false, false, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
 		return &GraphQLResponse{
 			Data: &Object{
@@ -3056,6 +3569,186 @@ func TestResolver_ResolveGraphQLResponse(t *testing.T) {
 			},
 		}, Context{Context: context.Background(), Variables: nil}, `{"data":{"me":{"id":"1234","username":"Me","reviews":[{"body":"A highly effective form of birth control.","product":{"upc":"top-1","name":"Trilby"}},{"body":"Fedoras are one of the most fashionable hats around and can look great with a variety of outfits.","product":{"upc":"top-2","name":"Fedora"}}]}}}`
 	}))
+	t.Run("federation with union members resolved by different data sources", testFn(true, true, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
+		searchService := NewMockDataSource(ctrl)
+		searchService.EXPECT().
+			Load(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&bytes.Buffer{})).
+			DoAndReturn(func(ctx context.Context, input []byte, w io.Writer) (err error) {
+				actual := string(input)
+				expected := `{"method":"POST","url":"http://search.service","body":{"query":"{search {__typename ... on Cat {id} ... on Dog {id}}}"}}`
+				assert.Equal(t, expected, actual)
+				pair := NewBufPair()
+				pair.Data.WriteString(`{"search":[{"__typename":"Cat","id":"1"},{"__typename":"Dog","id":"2"},{"__typename":"Cat","id":"3"}]}`)
+				return writeGraphqlResponse(pair, w, false)
+			})
+
+		catBatchFactory := NewMockDataSourceBatchFactory(ctrl)
+		catBatchFactory.EXPECT().
+			CreateBatch([][]byte{
+				[]byte(`{"method":"POST","url":"http://cat.service","body":{"query":"query($representations: [_Any!]!){_entities(representations: $representations){__typename ... on Cat {name}}}","variables":{"representations":[{"id":"1","__typename":"Cat"}]}}}`),
+				[]byte(`{"method":"POST","url":"http://cat.service","body":{"query":"query($representations: [_Any!]!){_entities(representations: $representations){__typename ... on Cat {name}}}","variables":{"representations":[{"id":"3","__typename":"Cat"}]}}}`),
+			}).
+			Return(NewFakeDataSourceBatch(
+				`{"method":"POST","url":"http://cat.service","body":{"query":"query($representations: [_Any!]!){_entities(representations: $representations){__typename ... on Cat {name}}}","variables":{"representations":[{"id":"1","__typename":"Cat"},{"id":"3","__typename":"Cat"}]}}}`,
+				[]resultedBufPair{
+					{data: `{"__typename":"Cat","name":"Tom"}`},
+					{data: `{"__typename":"Cat","name":"Felix"}`},
+				}), nil)
+		catService := NewMockDataSource(ctrl)
+		catService.EXPECT().
+			Load(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&bytes.Buffer{})).
+			DoAndReturn(func(ctx context.Context, input []byte, w io.Writer) (err error) {
+				actual := string(input)
+				expected := `{"method":"POST","url":"http://cat.service","body":{"query":"query($representations: [_Any!]!){_entities(representations: $representations){__typename ... on Cat {name}}}","variables":{"representations":[{"id":"1","__typename":"Cat"},{"id":"3","__typename":"Cat"}]}}}`
+				assert.Equal(t, expected, actual)
+				pair := NewBufPair()
+				pair.Data.WriteString(`[{"__typename":"Cat","name":"Tom"},{"__typename":"Cat","name":"Felix"}]`)
+				return writeGraphqlResponse(pair, w, false)
+			})
+
+		dogBatchFactory := NewMockDataSourceBatchFactory(ctrl)
+		dogBatchFactory.EXPECT().
+			CreateBatch([][]byte{
+				[]byte(`{"method":"POST","url":"http://dog.service","body":{"query":"query($representations: [_Any!]!){_entities(representations: $representations){__typename ... on Dog {bark}}}","variables":{"representations":[{"id":"2","__typename":"Dog"}]}}}`),
+			}).
+			Return(NewFakeDataSourceBatch(
+				`{"method":"POST","url":"http://dog.service","body":{"query":"query($representations: [_Any!]!){_entities(representations: $representations){__typename ... on Dog {bark}}}","variables":{"representations":[{"id":"2","__typename":"Dog"}]}}}`,
+				[]resultedBufPair{
+					{data: `{"__typename":"Dog","bark":"Woof"}`},
+				}), nil)
+		dogService := NewMockDataSource(ctrl)
+		dogService.EXPECT().
+			Load(gomock.Any(), gomock.Any(), gomock.AssignableToTypeOf(&bytes.Buffer{})).
+			DoAndReturn(func(ctx context.Context, input []byte, w io.Writer) (err error) {
+				actual := string(input)
+				expected := `{"method":"POST","url":"http://dog.service","body":{"query":"query($representations: [_Any!]!){_entities(representations: $representations){__typename ... on Dog {bark}}}","variables":{"representations":[{"id":"2","__typename":"Dog"}]}}}`
+				assert.Equal(t, expected, actual)
+				pair := NewBufPair()
+				pair.Data.WriteString(`[{"__typename":"Dog","bark":"Woof"}]`)
+				return writeGraphqlResponse(pair, w, false)
+			})
+
+		return &GraphQLResponse{
+			Data: &Object{
+				Fetch: &SingleFetch{
+					BufferId: 0,
+					InputTemplate: InputTemplate{
+						Segments: []TemplateSegment{
+							{
+								Data:        []byte(`{"method":"POST","url":"http://search.service","body":{"query":"{search {__typename ... on Cat {id} ... on Dog {id}}}"}}`),
+								SegmentType: StaticSegmentType,
+							},
+						},
+					},
+					DataSource: searchService,
+					ProcessResponseConfig: ProcessResponseConfig{
+						ExtractGraphqlResponse: true,
+					},
+				},
+				Fields: []*Field{
+					{
+						HasBuffer: true,
+						BufferID:  0,
+						Name:      []byte("search"),
+						Value: &Array{
+							Path: []string{"search"},
+							Item: &Object{
+								Fetch: &ParallelFetch{
+									Fetches: []Fetch{
+										&BatchFetch{
+											Fetch: &SingleFetch{
+												BufferId: 1,
+												InputTemplate: InputTemplate{
+													Segments: []TemplateSegment{
+														{
+															Data:        []byte(`{"method":"POST","url":"http://cat.service","body":{"query":"query($representations: [_Any!]!){_entities(representations: $representations){__typename ... on Cat {name}}}","variables":{"representations":[{"id":`),
+															SegmentType: StaticSegmentType,
+														},
+														{
+															SegmentType:        VariableSegmentType,
+															VariableKind:       ObjectVariableKind,
+															VariableSourcePath: []string{"id"},
+															Renderer:           NewJSONVariableRendererWithValidation(`{"type":"string"}`),
+														},
+														{
+															Data:        []byte(`,"__typename":"Cat"}]}}}`),
+															SegmentType: StaticSegmentType,
+														},
+													},
+												},
+												DataSource: catService,
+												ProcessResponseConfig: ProcessResponseConfig{
+													ExtractGraphqlResponse: true,
+												},
+												OnTypeNames: [][]byte{[]byte("Cat")},
+											},
+											BatchFactory: catBatchFactory,
+										},
+										&BatchFetch{
+											Fetch: &SingleFetch{
+												BufferId: 2,
+												InputTemplate: InputTemplate{
+													Segments: []TemplateSegment{
+														{
+															Data:        []byte(`{"method":"POST","url":"http://dog.service","body":{"query":"query($representations: [_Any!]!){_entities(representations: $representations){__typename ... on Dog {bark}}}","variables":{"representations":[{"id":`),
+															SegmentType: StaticSegmentType,
+														},
+														{
+															SegmentType:        VariableSegmentType,
+															VariableKind:       ObjectVariableKind,
+															VariableSourcePath: []string{"id"},
+															Renderer:           NewJSONVariableRendererWithValidation(`{"type":"string"}`),
+														},
+														{
+															Data:        []byte(`,"__typename":"Dog"}]}}}`),
+															SegmentType: StaticSegmentType,
+														},
+													},
+												},
+												DataSource: dogService,
+												ProcessResponseConfig: ProcessResponseConfig{
+													ExtractGraphqlResponse: true,
+												},
+												OnTypeNames: [][]byte{[]byte("Dog")},
+											},
+											BatchFactory: dogBatchFactory,
+										},
+									},
+								},
+								Fields: []*Field{
+									{
+										Name: []byte("__typename"),
+										Value: &String{
+											Path:       []string{"__typename"},
+											IsTypeName: true,
+										},
+									},
+									{
+										HasBuffer:  true,
+										BufferID:   1,
+										Name:       []byte("name"),
+										OnTypeName: []byte("Cat"),
+										Value: &String{
+											Path: []string{"name"},
+										},
+									},
+									{
+										HasBuffer:  true,
+										BufferID:   2,
+										Name:       []byte("bark"),
+										OnTypeName: []byte("Dog"),
+										Value: &String{
+											Path: []string{"bark"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}, Context{Context: context.Background(), Variables: nil}, `{"data":{"search":[{"__typename":"Cat","name":"Tom"},{"__typename":"Dog","bark":"Woof"},{"__typename":"Cat","name":"Felix"}]}}`
+	}))
 	t.Run("federation with null response", testFn(true, true, func(t *testing.T, ctrl *gomock.Controller) (node *GraphQLResponse, ctx Context, expectedOutput string) {
 		userService := NewMockDataSource(ctrl)
 		userService.EXPECT().
@@ -3832,6 +4525,12 @@ func (f *_fakeStream) Start(ctx context.Context, input []byte, next chan<- []byt
 	return nil
 }
 
+type _silentStream struct{}
+
+func (_silentStream) Start(ctx context.Context, input []byte, next chan<- []byte) error {
+	return nil
+}
+
 func TestResolver_ResolveGraphQLSubscription(t *testing.T) {
 
 	setup := func(ctx context.Context, stream SubscriptionDataSource) (*Resolver, *GraphQLSubscription, *TestFlushWriter) {
@@ -3896,6 +4595,22 @@ func TestResolver_ResolveGraphQLSubscription(t *testing.T) {
 		assert.Equal(t, `{"errors":[{"message":"no data source found"}]}`, out.flushed[0])
 	})
 
+	t.Run("should return an error with the trace id if the data source of a traced request has not been defined", func(t *testing.T) {
+		c, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		resolver, plan, out := setup(c, nil)
+
+		ctx := Context{
+			Context: tracing.NewContext(c, tracing.TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7"}),
+		}
+
+		err := resolver.ResolveGraphQLSubscription(&ctx, plan, out)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(out.flushed))
+		assert.Equal(t, `{"errors":[{"message":"no data source found","extensions":{"traceId":"4bf92f3577b34da6a3ce929d0e0e4736"}}]}`, out.flushed[0])
+	})
+
 	t.Run("should successfully get result from upstream", func(t *testing.T) {
 		c, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -3917,6 +4632,23 @@ func TestResolver_ResolveGraphQLSubscription(t *testing.T) {
 		assert.Equal(t, `{"data":{"counter":1}}`, out.flushed[1])
 		assert.Equal(t, `{"data":{"counter":2}}`, out.flushed[2])
 	})
+
+	t.Run("should stop when the client context is done without waiting for the trigger", func(t *testing.T) {
+		c, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		resolver, plan, out := setup(c, _silentStream{})
+
+		clientCtx, clientCancel := context.WithCancel(context.Background())
+		ctx := Context{
+			Context: clientCtx,
+		}
+		time.AfterFunc(10*time.Millisecond, clientCancel)
+
+		err := resolver.ResolveGraphQLSubscription(&ctx, plan, out)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, len(out.flushed))
+	})
 }
 
 func BenchmarkResolver_ResolveNode(b *testing.B) {
@@ -4207,3 +4939,36 @@ func useTestJSONVariableRenderer() initTestVariableRenderer {
 		return NewJSONVariableRendererWithValidation(jsonSchema)
 	}
 }
+
+func TestSkipCondition_IsMet(t *testing.T) {
+	variables := []byte(`{"id":"1","limit":10,"filter":{"a":1,"b":[true]},"empty":null}`)
+
+	assert.True(t, (&SkipCondition{}).IsMet(variables))
+	assert.True(t, (&SkipCondition{VariableName: "missing"}).IsMet(variables))
+	assert.True(t, (&SkipCondition{VariableName: "empty"}).IsMet(variables))
+	assert.False(t, (&SkipCondition{VariableName: "id"}).IsMet(variables))
+
+	assert.True(t, (&SkipCondition{VariableName: "id", Equals: []byte(`"1"`)}).IsMet(variables))
+	assert.False(t, (&SkipCondition{VariableName: "id", Equals: []byte(`1`)}).IsMet(variables))
+	assert.True(t, (&SkipCondition{VariableName: "limit", Equals: []byte(`10`)}).IsMet(variables))
+	assert.True(t, (&SkipCondition{VariableName: "filter", Equals: []byte(`{"b":[true],"a":1}`)}).IsMet(variables))
+	assert.True(t, (&SkipCondition{VariableName: "missing", Equals: []byte(`null`)}).IsMet(variables))
+	assert.False(t, (&SkipCondition{VariableName: "missing", Equals: []byte(`"1"`)}).IsMet(variables))
+}
+
+func TestSkipCondition_MatchesValue(t *testing.T) {
+	assert.True(t, (&SkipCondition{}).MatchesValue(nil))
+	assert.True(t, (&SkipCondition{}).MatchesValue([]byte(`null`)))
+	assert.False(t, (&SkipCondition{}).MatchesValue([]byte(`1`)))
+
+	assert.True(t, (&SkipCondition{Equals: []byte(`10`)}).MatchesValue([]byte(`10.0`)))
+	assert.False(t, (&SkipCondition{Equals: []byte(`10`)}).MatchesValue([]byte(`11`)))
+	assert.True(t, (&SkipCondition{Equals: []byte(`"A"`)}).MatchesValue([]byte(`"\u0041"`)))
+	assert.False(t, (&SkipCondition{Equals: []byte(`"A"`)}).MatchesValue([]byte(`"B"`)))
+	assert.False(t, (&SkipCondition{Equals: []byte(`"true"`)}).MatchesValue([]byte(`true`)))
+	assert.True(t, (&SkipCondition{Equals: []byte(`false`)}).MatchesValue([]byte(` false`)))
+	assert.False(t, (&SkipCondition{Equals: []byte(`false`)}).MatchesValue([]byte(`true`)))
+	assert.True(t, (&SkipCondition{Equals: []byte(`null`)}).MatchesValue(nil))
+	assert.True(t, (&SkipCondition{Equals: []byte(`[1, 2]`)}).MatchesValue([]byte(`[1,2]`)))
+	assert.False(t, (&SkipCondition{Equals: []byte(`[1,2]`)}).MatchesValue([]byte(`[2,1]`)))
+}
@@ -0,0 +1,55 @@
+package resolve
+
+// responseExtension is an entry of the extensions of the response, the value is JSON
+type responseExtension struct {
+	key   string
+	value []byte
+}
+
+// SetResponseExtension adds the extension with the JSON value to the extensions of the response,
+// e.g. to tell clients the hash of the schema. An extension with the same key is replaced.
+func (c *Context) SetResponseExtension(key string, value []byte) {
+	for i := range c.responseExtensions {
+		if c.responseExtensions[i].key == key {
+			c.responseExtensions[i].value = value
+			return
+		}
+	}
+	c.responseExtensions = append(c.responseExtensions, responseExtension{key: key, value: value})
+}
+
+// responseExtensionsObject returns the extensions of the response as JSON object, nil if there are none
+func (c *Context) responseExtensionsObject() []byte {
+	extensions := c.responseExtensions
+	if repeatedFetches := c.repeatedFetchesExtension(); repeatedFetches != nil {
+		extensions = append(extensions[:len(extensions):len(extensions)], responseExtension{key: string(repeatedFetchesExtensionKey), value: repeatedFetches})
+	}
+	if freshness := c.freshnessExtension(); freshness != nil {
+		extensions = append(extensions[:len(extensions):len(extensions)], responseExtension{key: string(freshnessExtensionKey), value: freshness})
+	}
+	if warnings := c.warningsExtension(); warnings != nil {
+		extensions = append(extensions[:len(extensions):len(extensions)], responseExtension{key: string(warningsExtensionKey), value: warnings})
+	}
+	if len(extensions) == 0 {
+		return nil
+	}
+
+	object := make([]byte, 0, 64)
+	object = append(object, lBrace...)
+	for i := range extensions {
+		if i != 0 {
+			object = append(object, comma...)
+		}
+		object = appendResponseExtension(object, extensions[i].key, extensions[i].value)
+	}
+	object = append(object, rBrace...)
+	return object
+}
+
+func appendResponseExtension(extensions []byte, key string, value []byte) []byte {
+	extensions = append(extensions, quote...)
+	extensions = append(extensions, key...)
+	extensions = append(extensions, quote...)
+	extensions = append(extensions, colon...)
+	return append(extensions, value...)
+}
@@ -0,0 +1,20 @@
+package resolve
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext_SetResponseExtension(t *testing.T) {
+	ctx := NewContext(nil)
+	assert.Nil(t, ctx.responseExtensionsObject())
+
+	ctx.SetResponseExtension("schemaHash", []byte(`"a"`))
+	ctx.SetResponseExtension("cost", []byte(`1`))
+	ctx.SetResponseExtension("schemaHash", []byte(`"b"`))
+	assert.Equal(t, `{"schemaHash":"b","cost":1}`, string(ctx.responseExtensionsObject()))
+
+	ctx.Free()
+	assert.Nil(t, ctx.responseExtensionsObject())
+}
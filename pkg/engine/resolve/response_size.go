@@ -0,0 +1,67 @@
+package resolve
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrResponseSizeLimitExceeded is matched by errors.Is for a ResponseSizeLimitError
+var ErrResponseSizeLimitExceeded = errors.New("response size limit exceeded")
+
+// ResponseSizeLimitError is returned when the serialized response exceeds the max response size of the Context.
+// Rendering stops at the field exceeding the limit, no partial response is written.
+type ResponseSizeLimitError struct {
+	// Path is the path of the field at which the response got truncated, e.g. /data/users/42/name
+	Path string
+	// Size is the number of bytes rendered including the field at Path
+	Size int64
+	// Limit is the max response size
+	Limit int64
+}
+
+func (e *ResponseSizeLimitError) Error() string {
+	return fmt.Sprintf("%s at path %s: rendered %d of max %d bytes", ErrResponseSizeLimitExceeded, e.Path, e.Size, e.Limit)
+}
+
+func (e *ResponseSizeLimitError) Is(target error) bool {
+	return target == ErrResponseSizeLimitExceeded
+}
+
+// SetMaxResponseSize limits the number of bytes of the serialized data of each response, 0 disables the limit.
+// The size counts the rendered field names and values, so the written response is slightly larger because of the JSON syntax and the errors.
+// Each subscription event is limited on its own.
+func (c *Context) SetMaxResponseSize(bytes int64) {
+	c.maxResponseSize = bytes
+	if c.responseSize == nil {
+		// shared with the clones resolving array items concurrently
+		c.responseSize = new(int64)
+	}
+}
+
+// MaxResponseSize returns the limit set by SetMaxResponseSize, 0 if responses are unlimited
+func (c *Context) MaxResponseSize() int64 {
+	return c.maxResponseSize
+}
+
+// accountResponseSize adds the bytes rendered for the field at the current path
+func (c *Context) accountResponseSize(bytes int) error {
+	if c.maxResponseSize <= 0 || bytes <= 0 || c.responseSize == nil {
+		return nil
+	}
+	size := atomic.AddInt64(c.responseSize, int64(bytes))
+	if size > c.maxResponseSize {
+		return &ResponseSizeLimitError{
+			Path:  string(c.path()),
+			Size:  size,
+			Limit: c.maxResponseSize,
+		}
+	}
+	return nil
+}
+
+func (c *Context) resetResponseSize() {
+	if c.responseSize != nil {
+		atomic.StoreInt64(c.responseSize, 0)
+	}
+}
@@ -0,0 +1,87 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolver_MaxResponseSize(t *testing.T) {
+	response := func(resolveAsynchronous bool) *GraphQLResponse {
+		return &GraphQLResponse{
+			Data: &Object{
+				Fetch: &SingleFetch{
+					BufferId:   0,
+					DataSource: FakeDataSource(`{"users":[{"name":"Jens"},{"name":"Anna"}]}`),
+				},
+				Fields: []*Field{
+					{
+						Name:      []byte("users"),
+						HasBuffer: true,
+						BufferID:  0,
+						Value: &Array{
+							Path:                []string{"users"},
+							ResolveAsynchronous: resolveAsynchronous,
+							Item: &Object{
+								Fields: []*Field{
+									{
+										Name: []byte("name"),
+										Value: &String{
+											Path: []string{"name"},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	resolve := func(t *testing.T, resolveAsynchronous bool, maxResponseSize int64) (string, error) {
+		rCtx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		r := newResolver(rCtx, false, false)
+
+		ctx := NewContext(context.Background())
+		ctx.SetMaxResponseSize(maxResponseSize)
+		buf := &bytes.Buffer{}
+		err := r.ResolveGraphQLResponse(ctx, response(resolveAsynchronous), nil, buf)
+		return buf.String(), err
+	}
+
+	t.Run("renders responses within the limit", func(t *testing.T) {
+		out, err := resolve(t, false, 34)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"users":[{"name":"Jens"},{"name":"Anna"}]}}`, out)
+	})
+
+	t.Run("stops rendering at the path exceeding the limit", func(t *testing.T) {
+		out, err := resolve(t, false, 25)
+		assert.ErrorIs(t, err, ErrResponseSizeLimitExceeded)
+		assert.Equal(t, "", out)
+
+		var sizeErr *ResponseSizeLimitError
+		require.True(t, errors.As(err, &sizeErr))
+		assert.Equal(t, "/data/users/1/name", sizeErr.Path)
+		assert.Equal(t, int64(28), sizeErr.Size)
+		assert.Equal(t, int64(25), sizeErr.Limit)
+		assert.Equal(t, "response size limit exceeded at path /data/users/1/name: rendered 28 of max 25 bytes", err.Error())
+	})
+
+	t.Run("accounts array items resolved asynchronously", func(t *testing.T) {
+		_, err := resolve(t, true, 25)
+		assert.ErrorIs(t, err, ErrResponseSizeLimitExceeded)
+	})
+
+	t.Run("no limit", func(t *testing.T) {
+		out, err := resolve(t, false, 0)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"users":[{"name":"Jens"},{"name":"Anna"}]}}`, out)
+	})
+}
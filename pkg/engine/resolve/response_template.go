@@ -0,0 +1,35 @@
+package resolve
+
+// PrecompileTemplate precompiles the JSON skeleton of the object if its shape is fully determined at plan time,
+// i.e. none of its fields depends on @skip/@include variables or a type condition.
+// The key of each field is precompiled including the preceding brace or comma, e.g. `,"name":`,
+// so that resolving the object only splices in the values.
+// Nested objects are not precompiled, PrecompileTemplate has to be called for each of them.
+func (o *Object) PrecompileTemplate() {
+	for i := range o.Fields {
+		if o.Fields[i].SkipDirectiveDefined || o.Fields[i].IncludeDirectiveDefined || o.Fields[i].hasTypeCondition() {
+			o.resetTemplate()
+			return
+		}
+	}
+	for i := range o.Fields {
+		name := o.Fields[i].Name
+		template := make([]byte, 0, len(name)+4)
+		if i == 0 {
+			template = append(template, lBrace...)
+		} else {
+			template = append(template, comma...)
+		}
+		template = append(template, quote...)
+		template = append(template, name...)
+		template = append(template, quote...)
+		template = append(template, colon...)
+		o.Fields[i].keyTemplate = template
+	}
+}
+
+func (o *Object) resetTemplate() {
+	for i := range o.Fields {
+		o.Fields[i].keyTemplate = nil
+	}
+}
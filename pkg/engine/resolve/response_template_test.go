@@ -0,0 +1,74 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObject_PrecompileTemplate(t *testing.T) {
+	newObject := func() *Object {
+		return &Object{
+			Fetch: &SingleFetch{
+				BufferId:   0,
+				DataSource: FakeDataSource(`{"user":{"id":1,"name":null}}`),
+			},
+			Fields: []*Field{
+				{
+					Name:      []byte("id"),
+					HasBuffer: true,
+					BufferID:  0,
+					Value:     &Integer{Path: []string{"user", "id"}},
+				},
+				{
+					Name:      []byte("name"),
+					HasBuffer: true,
+					BufferID:  0,
+					Value:     &String{Path: []string{"user", "name"}, Nullable: true},
+				},
+			},
+		}
+	}
+
+	t.Run("precompiles the keys of objects with a static shape", func(t *testing.T) {
+		object := newObject()
+		object.PrecompileTemplate()
+		assert.Equal(t, []byte(`{"id":`), object.Fields[0].keyTemplate)
+		assert.Equal(t, []byte(`,"name":`), object.Fields[1].keyTemplate)
+	})
+
+	t.Run("doesn't precompile objects with conditional fields", func(t *testing.T) {
+		object := newObject()
+		object.PrecompileTemplate()
+		object.Fields[0].SkipDirectiveDefined = true
+		object.Fields[0].SkipVariableName = "skip"
+		object.PrecompileTemplate()
+		assert.Nil(t, object.Fields[0].keyTemplate)
+		assert.Nil(t, object.Fields[1].keyTemplate)
+
+		object = newObject()
+		object.Fields[1].OnTypeName = []byte("User")
+		object.PrecompileTemplate()
+		assert.Nil(t, object.Fields[0].keyTemplate)
+	})
+
+	t.Run("renders the same response as without a template", func(t *testing.T) {
+		resolve := func(t *testing.T, precompile bool) string {
+			object := newObject()
+			if precompile {
+				object.PrecompileTemplate()
+			}
+			r := newResolver(context.Background(), false, false)
+			buf := &bytes.Buffer{}
+			err := r.ResolveGraphQLResponse(NewContext(context.Background()), &GraphQLResponse{Data: object}, nil, buf)
+			require.NoError(t, err)
+			return buf.String()
+		}
+
+		assert.Equal(t, `{"data":{"id":1,"name":null}}`, resolve(t, true))
+		assert.Equal(t, resolve(t, false), resolve(t, true))
+	})
+}
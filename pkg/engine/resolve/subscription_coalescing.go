@@ -0,0 +1,54 @@
+package resolve
+
+import (
+	"time"
+
+	"github.com/buger/jsonparser"
+)
+
+type SubscriptionCoalescingMode string
+
+const (
+	// SubscriptionCoalescingLatest sends the latest event of a window, the other events are dropped
+	SubscriptionCoalescingLatest SubscriptionCoalescingMode = "latest"
+	// SubscriptionCoalescingMerge deep merges the objects of the events of a window, later values take precedence
+	SubscriptionCoalescingMerge SubscriptionCoalescingMode = "merge"
+)
+
+// SubscriptionCoalescing protects clients from event storms by sending at most one event of a subscription per Window.
+// The first event is sent immediately, the events received during the window are coalesced and sent once it elapsed.
+type SubscriptionCoalescing struct {
+	// Window is the minimum duration between two events sent to the client, 0 disables coalescing
+	Window time.Duration
+	// Mode defines how the events of a window are coalesced, defaults to SubscriptionCoalescingLatest
+	Mode SubscriptionCoalescingMode
+}
+
+// coalesce returns the event replacing the pending event and the next event
+func (c *SubscriptionCoalescing) coalesce(pending, next []byte) []byte {
+	if pending == nil || c.Mode != SubscriptionCoalescingMerge {
+		return append([]byte(nil), next...)
+	}
+	return mergeJSONObjects(pending, next)
+}
+
+func mergeJSONObjects(into, from []byte) []byte {
+	_, intoType, _, _ := jsonparser.Get(into)
+	_, fromType, _, _ := jsonparser.Get(from)
+	if intoType != jsonparser.Object || fromType != jsonparser.Object {
+		return append([]byte(nil), from...)
+	}
+	merged := append([]byte(nil), into...)
+	_ = jsonparser.ObjectEach(from, func(key []byte, value []byte, dataType jsonparser.ValueType, _ int) error {
+		if dataType == jsonparser.String {
+			// the value of strings is returned without the quotes
+			value = append(append([]byte{'"'}, value...), '"')
+		}
+		if existing, _, _, err := jsonparser.Get(merged, string(key)); err == nil {
+			value = mergeJSONObjects(existing, value)
+		}
+		merged, _ = jsonparser.Set(merged, value, string(key))
+		return nil
+	})
+	return merged
+}
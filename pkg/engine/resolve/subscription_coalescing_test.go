@@ -0,0 +1,106 @@
+package resolve
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// burstStream sends its events one after another, waiting pause after each event, and closes the channel after the last event
+type burstStream struct {
+	events []string
+	pause  time.Duration
+}
+
+func (b *burstStream) Start(ctx context.Context, input []byte, next chan<- []byte) error {
+	go func() {
+		for _, event := range b.events {
+			select {
+			case next <- []byte(event):
+			case <-ctx.Done():
+				return
+			}
+			time.Sleep(b.pause)
+		}
+		close(next)
+	}()
+	return nil
+}
+
+func TestResolver_ResolveGraphQLSubscription_Coalescing(t *testing.T) {
+	resolve := func(t *testing.T, stream SubscriptionDataSource, coalescing SubscriptionCoalescing) []string {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		plan := &GraphQLSubscription{
+			Trigger: GraphQLSubscriptionTrigger{
+				Source:     stream,
+				Coalescing: coalescing,
+			},
+			Response: &GraphQLResponse{
+				Data: &Object{
+					Fields: []*Field{
+						{
+							Name: []byte("counter"),
+							Value: &Object{Path: []string{"counter"}, Nullable: true, Fields: []*Field{
+								{Name: []byte("a"), Value: &Integer{Path: []string{"a"}, Nullable: true}},
+								{Name: []byte("b"), Value: &Integer{Path: []string{"b"}, Nullable: true}},
+							}},
+						},
+					},
+				},
+			},
+		}
+		out := &TestFlushWriter{buf: bytes.Buffer{}}
+		err := newResolver(ctx, false, false).ResolveGraphQLSubscription(&Context{Context: ctx}, plan, out)
+		assert.NoError(t, err)
+		return out.flushed
+	}
+
+	events := []string{
+		`{"data":{"counter":{"a":1}}}`,
+		`{"data":{"counter":{"a":2}}}`,
+		`{"data":{"counter":{"b":3}}}`,
+		`{"data":{"counter":{"a":4}}}`,
+	}
+
+	t.Run("sends all events without a window", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`{"data":{"counter":{"a":1,"b":null}}}`,
+			`{"data":{"counter":{"a":2,"b":null}}}`,
+			`{"data":{"counter":{"a":null,"b":3}}}`,
+			`{"data":{"counter":{"a":4,"b":null}}}`,
+		}, resolve(t, &burstStream{events: events}, SubscriptionCoalescing{}))
+	})
+
+	t.Run("sends the first and the latest event of a window", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`{"data":{"counter":{"a":1,"b":null}}}`,
+			`{"data":{"counter":{"a":4,"b":null}}}`,
+		}, resolve(t, &burstStream{events: events}, SubscriptionCoalescing{Window: time.Minute}))
+	})
+
+	t.Run("merges the events of a window", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`{"data":{"counter":{"a":1,"b":null}}}`,
+			`{"data":{"counter":{"a":4,"b":3}}}`,
+		}, resolve(t, &burstStream{events: events}, SubscriptionCoalescing{Window: time.Minute, Mode: SubscriptionCoalescingMerge}))
+	})
+
+	t.Run("sends the events of elapsed windows", func(t *testing.T) {
+		assert.Equal(t, []string{
+			`{"data":{"counter":{"a":1,"b":null}}}`,
+			`{"data":{"counter":{"a":2,"b":null}}}`,
+			`{"data":{"counter":{"a":null,"b":3}}}`,
+			`{"data":{"counter":{"a":4,"b":null}}}`,
+		}, resolve(t, &burstStream{events: events, pause: 20 * time.Millisecond}, SubscriptionCoalescing{Window: 5 * time.Millisecond}))
+	})
+}
+
+func TestMergeJSONObjects(t *testing.T) {
+	assert.Equal(t, `{"a":{"b":1,"c":"x"},"d":[2]}`, string(mergeJSONObjects([]byte(`{"a":{"b":0,"c":"x"},"d":[1]}`), []byte(`{"a":{"b":1},"d":[2]}`))))
+	assert.Equal(t, `{"a":"y"}`, string(mergeJSONObjects([]byte(`{"a":{"b":0}}`), []byte(`{"a":"y"}`))))
+}
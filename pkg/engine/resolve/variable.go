@@ -20,6 +20,8 @@ const (
 	ContextVariableKind VariableKind = iota + 1
 	ObjectVariableKind
 	HeaderVariableKind
+	FeatureFlagVariableKind
+	RequestExtensionVariableKind
 )
 
 const (
@@ -585,6 +587,79 @@ func (h *HeaderVariable) Equals(another Variable) bool {
 	return true
 }
 
+// FeatureFlagVariable renders true if the feature flag at Path is enabled for the request, false otherwise, see FeatureFlags
+type FeatureFlagVariable struct {
+	Path []string
+}
+
+func (f *FeatureFlagVariable) TemplateSegment() TemplateSegment {
+	return TemplateSegment{
+		SegmentType:        VariableSegmentType,
+		VariableKind:       FeatureFlagVariableKind,
+		VariableSourcePath: f.Path,
+	}
+}
+
+func (f *FeatureFlagVariable) GetVariableKind() VariableKind {
+	return FeatureFlagVariableKind
+}
+
+func (f *FeatureFlagVariable) Equals(another Variable) bool {
+	if another == nil {
+		return false
+	}
+	if another.GetVariableKind() != f.GetVariableKind() {
+		return false
+	}
+	anotherFeatureFlagVariable := another.(*FeatureFlagVariable)
+	if len(f.Path) != len(anotherFeatureFlagVariable.Path) {
+		return false
+	}
+	for i := range f.Path {
+		if f.Path[i] != anotherFeatureFlagVariable.Path[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// RequestExtensionVariable renders the request extension at Path, see RequestExtensions.
+// Strings are rendered without quotes like headers, other values as JSON, nothing is rendered if the extension is missing.
+type RequestExtensionVariable struct {
+	Path []string
+}
+
+func (r *RequestExtensionVariable) TemplateSegment() TemplateSegment {
+	return TemplateSegment{
+		SegmentType:        VariableSegmentType,
+		VariableKind:       RequestExtensionVariableKind,
+		VariableSourcePath: r.Path,
+	}
+}
+
+func (r *RequestExtensionVariable) GetVariableKind() VariableKind {
+	return RequestExtensionVariableKind
+}
+
+func (r *RequestExtensionVariable) Equals(another Variable) bool {
+	if another == nil {
+		return false
+	}
+	if another.GetVariableKind() != r.GetVariableKind() {
+		return false
+	}
+	anotherRequestExtensionVariable := another.(*RequestExtensionVariable)
+	if len(r.Path) != len(anotherRequestExtensionVariable.Path) {
+		return false
+	}
+	for i := range r.Path {
+		if r.Path[i] != anotherRequestExtensionVariable.Path[i] {
+			return false
+		}
+	}
+	return true
+}
+
 type Variable interface {
 	GetVariableKind() VariableKind
 	Equals(another Variable) bool
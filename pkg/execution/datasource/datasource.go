@@ -10,12 +10,11 @@ import (
 	"strings"
 	"time"
 
-	"github.com/jensneuse/abstractlogger"
-
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
 	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
 	"github.com/wundergraph/graphql-go-tools/pkg/asttransform"
 	"github.com/wundergraph/graphql-go-tools/pkg/astvisitor"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 var RootTypeName = []byte("root_type_name")
@@ -86,7 +85,7 @@ type PlannerFactoryFactory interface {
 }
 
 type BasePlanner struct {
-	Log                   abstractlogger.Logger
+	Log                   logging.Logger
 	Walker                *astvisitor.Walker   // nolint
 	Definition, Operation *ast.Document        // nolint
 	Args                  []Argument           // nolint
@@ -94,7 +93,7 @@ type BasePlanner struct {
 	Config                PlannerConfiguration // nolint
 }
 
-func NewBaseDataSourcePlanner(schema []byte, config PlannerConfiguration, logger abstractlogger.Logger) (*BasePlanner, error) {
+func NewBaseDataSourcePlanner(schema []byte, config PlannerConfiguration, logger logging.Logger) (*BasePlanner, error) {
 	definition, report := astparser.ParseGraphqlDocumentBytes(schema)
 	if report.HasErrors() {
 		return nil, report
@@ -10,13 +10,13 @@ import (
 
 	"github.com/buger/jsonparser"
 	"github.com/cespare/xxhash/v2"
-	log "github.com/jensneuse/abstractlogger"
 
 	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafebytes"
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
 	"github.com/wundergraph/graphql-go-tools/pkg/astimport"
 	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
 	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 var graphqlSchemes = []string{
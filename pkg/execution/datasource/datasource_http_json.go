@@ -11,13 +11,13 @@ import (
 	"strconv"
 
 	"github.com/buger/jsonparser"
-	log "github.com/jensneuse/abstractlogger"
 	"github.com/tidwall/gjson"
 	"github.com/tidwall/sjson"
 
 	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafebytes"
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
 	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 var httpJsonSchemes = []string{
@@ -12,10 +12,9 @@ import (
 	"text/template"
 	"time"
 
-	log "github.com/jensneuse/abstractlogger"
-
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
 	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 type HttpPollingStreamDataSourceConfiguration struct {
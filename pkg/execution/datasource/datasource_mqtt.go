@@ -8,10 +8,10 @@ import (
 	"time"
 
 	mqtt "github.com/eclipse/paho.mqtt.golang"
-	log "github.com/jensneuse/abstractlogger"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
 	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 type MQTTDataSourceConfig struct {
@@ -147,8 +147,8 @@ func (m *MQTTDataSource) Resolve(ctx context.Context, args ResolverArgs, out io.
 }
 
 func (m *MQTTDataSource) start(brokerAddr, clientID, topic string) {
-	mqtt.ERROR = m.log.LevelLogger(log.ErrorLevel)
-	mqtt.DEBUG = m.log.LevelLogger(log.DebugLevel)
+	mqtt.ERROR = log.NewLevelLogger(m.log, log.ErrorLevel)
+	mqtt.DEBUG = log.NewLevelLogger(m.log, log.DebugLevel)
 	opts := mqtt.NewClientOptions().AddBroker(brokerAddr).SetClientID(clientID)
 	opts.SetKeepAlive(5 * time.Second)
 	opts.SetResumeSubs(true)
@@ -7,10 +7,10 @@ import (
 	"sync"
 	"time"
 
-	log "github.com/jensneuse/abstractlogger"
 	"github.com/nats-io/nats.go"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 type NatsDataSourceConfig struct {
@@ -10,7 +10,6 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"github.com/jensneuse/abstractlogger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/tidwall/gjson"
@@ -20,6 +19,7 @@ import (
 	"github.com/wundergraph/graphql-go-tools/pkg/astnormalization"
 	"github.com/wundergraph/graphql-go-tools/pkg/execution/datasource"
 	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
 	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
 )
 
@@ -107,7 +107,7 @@ func TestHttpJsonDataSourcePlanner_Plan(t *testing.T) {
 							BufferName: "simpleType",
 							Source: &DataSourceInvocation{
 								DataSource: &datasource.HttpJsonDataSource{
-									Log:    abstractlogger.Noop{},
+									Log:    logging.Noop{},
 									Client: datasource.DefaultHttpClient(),
 								},
 								Args: []datasource.Argument{
@@ -213,7 +213,7 @@ func TestHttpJsonDataSourcePlanner_Plan(t *testing.T) {
 							BufferName: "listOfStrings",
 							Source: &DataSourceInvocation{
 								DataSource: &datasource.HttpJsonDataSource{
-									Log:    abstractlogger.Noop{},
+									Log:    logging.Noop{},
 									Client: datasource.DefaultHttpClient(),
 								},
 								Args: []datasource.Argument{
@@ -300,7 +300,7 @@ func TestHttpJsonDataSourcePlanner_Plan(t *testing.T) {
 							BufferName: "listOfObjects",
 							Source: &DataSourceInvocation{
 								DataSource: &datasource.HttpJsonDataSource{
-									Log:    abstractlogger.Noop{},
+									Log:    logging.Noop{},
 									Client: datasource.DefaultHttpClient(),
 								},
 								Args: []datasource.Argument{
@@ -409,7 +409,7 @@ func TestHttpJsonDataSourcePlanner_Plan(t *testing.T) {
 							BufferName: "unionType",
 							Source: &DataSourceInvocation{
 								DataSource: &datasource.HttpJsonDataSource{
-									Log:    abstractlogger.Noop{},
+									Log:    logging.Noop{},
 									Client: datasource.DefaultHttpClient(),
 								},
 								Args: []datasource.Argument{
@@ -559,7 +559,7 @@ func TestHttpJsonDataSourcePlanner_Plan(t *testing.T) {
 							BufferName: "interfaceType",
 							Source: &DataSourceInvocation{
 								DataSource: &datasource.HttpJsonDataSource{
-									Log:    abstractlogger.Noop{},
+									Log:    logging.Noop{},
 									Client: datasource.DefaultHttpClient(),
 								},
 								Args: []datasource.Argument{
@@ -681,7 +681,7 @@ func TestHttpJsonDataSource_Resolve(t *testing.T) {
 			}
 			buf := bytes.Buffer{}
 			source := &datasource.HttpJsonDataSource{
-				Log:    abstractlogger.Noop{},
+				Log:    logging.Noop{},
 				Client: datasource.DefaultHttpClient(),
 			}
 			args := ResolvedArgs{
@@ -763,7 +763,7 @@ func TestHttpJsonDataSource_WithPlanning(t *testing.T) {
 			}
 
 			plannerConfig := createPlannerConfigToUpstream(t, upstreamURLs, http.MethodPost, tc.typeFieldConfigs)
-			basePlanner, err := datasource.NewBaseDataSourcePlanner([]byte(tc.definition), plannerConfig, abstractlogger.NoopLogger)
+			basePlanner, err := datasource.NewBaseDataSourcePlanner([]byte(tc.definition), plannerConfig, logging.NoopLogger)
 			require.NoError(t, err)
 
 			var hooks datasource.Hooks
@@ -5,11 +5,11 @@ import (
 	"os"
 	"testing"
 
-	log "github.com/jensneuse/abstractlogger"
 	"github.com/jensneuse/pipeline/pkg/pipe"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/execution/datasource"
 	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 func TestPipelineDataSource_Resolve(t *testing.T) {
@@ -14,13 +14,13 @@ import (
 	"time"
 
 	"github.com/cespare/xxhash/v2"
-	log "github.com/jensneuse/abstractlogger"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
 	"github.com/wundergraph/graphql-go-tools/pkg/execution/datasource"
 	"github.com/wundergraph/graphql-go-tools/pkg/introspection"
 	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
 	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
 	"github.com/wundergraph/graphql-go-tools/pkg/testing/goldie"
 )
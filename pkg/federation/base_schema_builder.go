@@ -0,0 +1,79 @@
+package federation
+
+import (
+	"sync"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/asttransform"
+)
+
+// BaseSchemaBuilder builds the federation schemas of subgraphs, see BuildFederationSchema,
+// and parses them merged with the base schema (the built-in scalars, directives and introspection types).
+// Each schema is built once and shared by all callers, so that e.g. the planners of a subgraph
+// don't print, build and parse the schema of the subgraph for each of its fetches.
+// It's safe for concurrent use.
+type BaseSchemaBuilder struct {
+	mu      sync.Mutex
+	schemas map[baseSchemaKey]*builtBaseSchema
+}
+
+type baseSchemaKey struct {
+	upstreamSchema string
+	serviceSDL     string
+}
+
+type builtBaseSchema struct {
+	once     sync.Once
+	schema   string
+	document *ast.Document
+	err      error
+}
+
+func NewBaseSchemaBuilder() *BaseSchemaBuilder {
+	return &BaseSchemaBuilder{
+		schemas: map[baseSchemaKey]*builtBaseSchema{},
+	}
+}
+
+// FederationSchema returns the federation schema of the upstream schema of a subgraph with the given service SDL,
+// like BuildFederationSchema.
+func (b *BaseSchemaBuilder) FederationSchema(upstreamSchema, serviceSDL string) (string, error) {
+	built := b.build(upstreamSchema, serviceSDL)
+	return built.schema, built.err
+}
+
+// FederationSchemaDocument returns the parsed federation schema of the upstream schema of a subgraph, see FederationSchema,
+// merged with the base schema. The document is shared and must not be modified.
+func (b *BaseSchemaBuilder) FederationSchemaDocument(upstreamSchema, serviceSDL string) (*ast.Document, error) {
+	built := b.build(upstreamSchema, serviceSDL)
+	return built.document, built.err
+}
+
+func (b *BaseSchemaBuilder) build(upstreamSchema, serviceSDL string) *builtBaseSchema {
+	key := baseSchemaKey{upstreamSchema: upstreamSchema, serviceSDL: serviceSDL}
+	b.mu.Lock()
+	built, ok := b.schemas[key]
+	if !ok {
+		built = &builtBaseSchema{}
+		b.schemas[key] = built
+	}
+	b.mu.Unlock()
+
+	built.once.Do(func() {
+		built.schema, built.err = BuildFederationSchema(upstreamSchema, serviceSDL)
+		if built.err != nil {
+			return
+		}
+		document, report := astparser.ParseGraphqlDocumentString(built.schema)
+		if report.HasErrors() {
+			built.err = report
+			return
+		}
+		if built.err = asttransform.MergeDefinitionWithBaseSchema(&document); built.err != nil {
+			return
+		}
+		built.document = &document
+	})
+	return built
+}
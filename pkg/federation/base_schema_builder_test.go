@@ -0,0 +1,67 @@
+package federation
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+)
+
+func TestBaseSchemaBuilder(t *testing.T) {
+	t.Run("builds each federation schema once", func(t *testing.T) {
+		builder := NewBaseSchemaBuilder()
+
+		schema, err := builder.FederationSchema(baseSchema, serviceSDL)
+		require.NoError(t, err)
+		assert.Equal(t, federatedSchema, schema)
+
+		var (
+			wg        sync.WaitGroup
+			documents = make([]*ast.Document, 8)
+		)
+		for i := range documents {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				document, err := builder.FederationSchemaDocument(baseSchema, serviceSDL)
+				assert.NoError(t, err)
+				documents[i] = document
+			}(i)
+		}
+		wg.Wait()
+		for i := range documents {
+			assert.Same(t, documents[0], documents[i])
+		}
+
+		document, err := builder.FederationSchemaDocument(baseSchema, serviceSDL)
+		require.NoError(t, err)
+		_, hasEntities := document.Index.FirstNodeByNameStr("_Entity")
+		assert.True(t, hasEntities)
+		_, hasBaseSchema := document.Index.FirstNodeByNameStr("__Schema")
+		assert.True(t, hasBaseSchema)
+	})
+
+	t.Run("builds the schemas of different subgraphs separately", func(t *testing.T) {
+		builder := NewBaseSchemaBuilder()
+		products, err := builder.FederationSchemaDocument(`type Query { topProducts: [Product] } type Product { upc: String! }`, `extend type Query { topProducts: [Product] } type Product @key(fields: "upc") { upc: String! }`)
+		require.NoError(t, err)
+		users, err := builder.FederationSchemaDocument(`type Query { me: User } type User { id: ID! }`, `extend type Query { me: User } type User @key(fields: "id") { id: ID! }`)
+		require.NoError(t, err)
+		assert.NotSame(t, products, users)
+		_, hasProduct := products.Index.FirstNodeByNameStr("Product")
+		assert.True(t, hasProduct)
+		_, hasUser := users.Index.FirstNodeByNameStr("User")
+		assert.True(t, hasUser)
+	})
+
+	t.Run("invalid schema", func(t *testing.T) {
+		builder := NewBaseSchemaBuilder()
+		_, err := builder.FederationSchemaDocument(`type Query {`, `type User @key(fields: "id") { id: ID! }`)
+		assert.Error(t, err)
+		_, err = builder.FederationSchemaDocument(`type Query {`, `type User @key(fields: "id") { id: ID! }`)
+		assert.Error(t, err)
+	})
+}
@@ -25,29 +25,36 @@ func BuildFederationSchema(baseSchema, serviceSDL string) (string, error) {
 type schemaBuilder struct {
 }
 
-// BuildFederationSchema takes a baseSchema plus the service sdl and turns it into a fully compliant federation schema
+// BuildFederationSchema takes a baseSchema plus the service sdl and turns it into a fully compliant federation schema.
+// It returns an error if the service sdl or the base schema can't be parsed, instead of returning the base schema without the federation fields.
 func (s *schemaBuilder) buildFederationSchema(baseSchema, serviceSDL string) (string, error) {
-	unionTypes := s.entityUnionTypes(serviceSDL)
+	unionTypes, err := s.entityUnionTypes(serviceSDL)
+	if err != nil {
+		return "", fmt.Errorf("service sdl: %w", err)
+	}
 	if len(unionTypes) == 0 {
 		return baseSchema, nil
 	}
 	allUnionTypes := strings.Join(unionTypes, " | ")
 	federationExtension := fmt.Sprintf(federationTemplate, allUnionTypes)
 
-	baseSchema = s.extendQueryTypeWithFederationFields(baseSchema)
+	baseSchema, err = s.extendQueryTypeWithFederationFields(baseSchema)
+	if err != nil {
+		return "", fmt.Errorf("base schema: %w", err)
+	}
 
 	federatedSchema := baseSchema + federationExtension
 	return federatedSchema, nil
 }
 
-func (s *schemaBuilder) extendQueryTypeWithFederationFields(schema string) string {
+func (s *schemaBuilder) extendQueryTypeWithFederationFields(schema string) (string, error) {
 	doc := ast.NewDocument()
 	doc.Input.ResetInputString(schema)
 	parser := astparser.NewParser()
 	report := &operationreport.Report{}
 	parser.Parse(doc, report)
 	if report.HasErrors() {
-		return schema
+		return "", *report
 	}
 	queryTypeName := doc.Index.QueryTypeName.String()
 	if queryTypeName == "" {
@@ -57,14 +64,10 @@ func (s *schemaBuilder) extendQueryTypeWithFederationFields(schema string) strin
 		name := doc.ObjectTypeDefinitionNameString(i)
 		if name == queryTypeName {
 			s.extendQueryType(doc, i)
-			out, err := astprinter.PrintStringIndent(doc, nil, "  ")
-			if err != nil {
-				return schema
-			}
-			return out
+			return astprinter.PrintStringIndent(doc, nil, "  ")
 		}
 	}
-	return schema
+	return schema, nil
 }
 
 func (s *schemaBuilder) extendQueryType(doc *ast.Document, ref int) {
@@ -107,14 +110,14 @@ func (s *schemaBuilder) extendQueryType(doc *ast.Document, ref int) {
 // _entities(representations: [_Any!]!): [_Entity]!
 // _service: _Service!
 
-func (s *schemaBuilder) entityUnionTypes(serviceSDL string) []string {
+func (s *schemaBuilder) entityUnionTypes(serviceSDL string) ([]string, error) {
 	doc := ast.NewDocument()
 	doc.Input.ResetInputString(serviceSDL)
 	parser := astparser.NewParser()
 	report := &operationreport.Report{}
 	parser.Parse(doc, report)
 	if report.HasErrors() {
-		return nil
+		return nil, *report
 	}
 
 	walker := astvisitor.NewWalker(4)
@@ -124,9 +127,9 @@ func (s *schemaBuilder) entityUnionTypes(serviceSDL string) []string {
 	walker.RegisterEnterObjectTypeExtensionVisitor(visitor)
 	walker.Walk(doc, nil, report)
 	if report.HasErrors() {
-		return nil
+		return nil, *report
 	}
-	return visitor.entityUnionTypes
+	return visitor.entityUnionTypes, nil
 }
 
 type schemaBuilderVisitor struct {
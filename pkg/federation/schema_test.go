@@ -6,10 +6,41 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func TestBuildBaseSchemaDocument(t *testing.T) {
+	t.Run("interface entity extension", func(t *testing.T) {
+		accounts := `
+			extend type Query { node(id: ID!): Node }
+			interface Node @key(fields: "id") { id: ID! }
+			type User implements Node @key(fields: "id") { id: ID! name: String! }
+		`
+		reviews := `
+			type Review { body: String! }
+			extend interface Node @key(fields: "id") { id: ID! @external reviews: [Review] }
+			extend type User implements Node @key(fields: "id") { id: ID! @external reviews: [Review] }
+		`
+
+		actual, err := BuildBaseSchemaDocument(accounts, reviews)
+		assert.NoError(t, err)
+		assert.Equal(t, `type Query {node(id: ID!): Node} interface Node {id: ID! reviews: [Review]} type User implements Node {id: ID! name: String! reviews: [Review]} type Review {body: String!}`, actual)
+	})
+}
+
 func TestSchemaBuilder_BuildFederationSchema(t *testing.T) {
-	actual, err := BuildFederationSchema(baseSchema, serviceSDL)
-	assert.NoError(t, err)
-	assert.Equal(t, federatedSchema, actual)
+	t.Run("federation schema", func(t *testing.T) {
+		actual, err := BuildFederationSchema(baseSchema, serviceSDL)
+		assert.NoError(t, err)
+		assert.Equal(t, federatedSchema, actual)
+	})
+
+	t.Run("invalid service sdl", func(t *testing.T) {
+		_, err := BuildFederationSchema(baseSchema, `type User @key(fields: "id") { id: ID! `)
+		assert.ErrorContains(t, err, "service sdl: ")
+	})
+
+	t.Run("invalid base schema", func(t *testing.T) {
+		_, err := BuildFederationSchema(`type Query { me: User `, serviceSDL)
+		assert.ErrorContains(t, err, "base schema: ")
+	})
 }
 
 const serviceSDL = `extend type Query {topProducts(first: Int = 5): [Product]}type Product @key(fields: "upc") {upc: String!name: String! price: Int!} extend type Query {me: User} type User @key(fields: "id"){ id: ID! username: String!} type Review { body: String! author: User! @provides(fields: "username") product: Product! } extend type User @key(fields: "id") { id: ID! @external reviews: [Review] } extend type Product @key(fields: "upc") { upc: String! @external reviews: [Review] }`
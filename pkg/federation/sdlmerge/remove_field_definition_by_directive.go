@@ -24,6 +24,7 @@ type removeFieldDefinitionByDirective struct {
 func (r *removeFieldDefinitionByDirective) Register(walker *astvisitor.Walker) {
 	walker.RegisterEnterDocumentVisitor(r)
 	walker.RegisterLeaveObjectTypeDefinitionVisitor(r)
+	walker.RegisterLeaveInterfaceTypeDefinitionVisitor(r)
 }
 
 func (r *removeFieldDefinitionByDirective) EnterDocument(operation, _ *ast.Document) {
@@ -31,9 +32,20 @@ func (r *removeFieldDefinitionByDirective) EnterDocument(operation, _ *ast.Docum
 }
 
 func (r *removeFieldDefinitionByDirective) LeaveObjectTypeDefinition(ref int) {
-	var refsForDeletion []int
+	refsForDeletion := r.fieldRefsForDeletion(r.operation.ObjectTypeDefinitions[ref].FieldsDefinition.Refs)
+	// delete fields
+	r.operation.RemoveFieldDefinitionsFromObjectTypeDefinition(refsForDeletion, ref)
+}
+
+func (r *removeFieldDefinitionByDirective) LeaveInterfaceTypeDefinition(ref int) {
+	refsForDeletion := r.fieldRefsForDeletion(r.operation.InterfaceTypeDefinitions[ref].FieldsDefinition.Refs)
+	// delete fields
+	r.operation.RemoveFieldDefinitionsFromInterfaceTypeDefinition(refsForDeletion, ref)
+}
+
+func (r *removeFieldDefinitionByDirective) fieldRefsForDeletion(fieldRefs []int) (refsForDeletion []int) {
 	// select fields for deletion
-	for _, fieldRef := range r.operation.ObjectTypeDefinitions[ref].FieldsDefinition.Refs {
+	for _, fieldRef := range fieldRefs {
 		for _, directiveRef := range r.operation.FieldDefinitions[fieldRef].Directives.Refs {
 			directiveName := r.operation.DirectiveNameString(directiveRef)
 			if _, ok := r.directives[directiveName]; ok {
@@ -41,6 +53,5 @@ func (r *removeFieldDefinitionByDirective) LeaveObjectTypeDefinition(ref int) {
 			}
 		}
 	}
-	// delete fields
-	r.operation.RemoveFieldDefinitionsFromObjectTypeDefinition(refsForDeletion, ref)
+	return refsForDeletion
 }
@@ -25,4 +25,21 @@ func TestRemoveFieldDirective(t *testing.T) {
 				}
 			`)
 	})
+	t.Run("remove interface field with specified directive", func(t *testing.T) {
+		run(
+			t, newRemoveFieldDefinitions("forDelete"),
+			`
+				interface Pet {
+					id: ID!
+					id: ID! @forDelete
+					name: String @notForDelete
+				}
+			`,
+			`
+				interface Pet {
+					id: ID!
+					name: String @notForDelete
+				}
+			`)
+	})
 }
@@ -37,6 +37,11 @@ func MergeAST(ast *ast.Document) error {
 }
 
 func MergeSDLs(SDLs ...string) (string, error) {
+	return MergeSDLsWithOptions(Options{}, SDLs...)
+}
+
+// MergeSDLsWithOptions works like MergeSDLs but resolves conflicting shared types with the ConflictStrategy of the options
+func MergeSDLsWithOptions(options Options, SDLs ...string) (string, error) {
 	rawDocs := make([]string, 0, len(SDLs)+1)
 	rawDocs = append(rawDocs, rootOperationTypeDefinitions)
 	rawDocs = append(rawDocs, SDLs...)
@@ -46,6 +51,9 @@ func MergeSDLs(SDLs ...string) (string, error) {
 	if normalizationError := normalizeSubgraphs(rawDocs[1:]); normalizationError != nil {
 		return "", normalizationError
 	}
+	if conflictError := resolveTypeConflicts(rawDocs[1:], options); conflictError != nil {
+		return "", conflictError
+	}
 
 	doc, report := astparser.ParseGraphqlDocumentString(strings.Join(rawDocs, "\n"))
 	if report.HasErrors() {
@@ -122,12 +122,12 @@ func TestMergeSDLs(t *testing.T) {
 	))
 
 	t.Run("Non-identical duplicate enums should return an error", runMergeTestAndExpectError(
-		nonIdenticalSharedTypeErrorMessage("Satisfaction"),
+		conflictingSharedTypeErrorMessage("Satisfaction", "the value 'DEVASTATED' is defined in subgraph 'subgraph2' but not in subgraph 'subgraph1'"),
 		productSchema, negativeTestingLikeSchema,
 	))
 
 	t.Run("Non-identical duplicate unions should return an error", runMergeTestAndExpectError(
-		nonIdenticalSharedTypeErrorMessage("AlphaNumeric"),
+		conflictingSharedTypeErrorMessage("AlphaNumeric", "the member 'Int' is defined in subgraph 'subgraph1' but not in subgraph 'subgraph2'"),
 		accountSchema, negativeTestingReviewSchema,
 	))
 
@@ -637,6 +637,10 @@ func nonIdenticalSharedTypeErrorMessage(typeName string) string {
 	return fmt.Sprintf("the shared type named '%s' must be identical in any subgraphs to federate", typeName)
 }
 
+func conflictingSharedTypeErrorMessage(typeName, conflict string) string {
+	return fmt.Sprintf("%s: %s", nonIdenticalSharedTypeErrorMessage(typeName), conflict)
+}
+
 func sharedTypeExtensionErrorMessage(typeName string) string {
 	return fmt.Sprintf("the type named '%s' cannot be extended because it is a shared type", typeName)
 }
@@ -0,0 +1,397 @@
+package sdlmerge
+
+import (
+	"fmt"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+// extendsDirectiveName marks definitions which are normalized to extensions of the type
+const extendsDirectiveName = "extends"
+
+// ConflictStrategy decides how shared types, which are defined with the same name but a different shape
+// in multiple subgraphs, are merged. Entities and root operation types are not affected.
+type ConflictStrategy int
+
+const (
+	// ConflictStrategyError fails the merge with an error describing the difference of the definitions
+	ConflictStrategyError ConflictStrategy = iota
+	// ConflictStrategyNamespace prefixes the conflicting type and all its references in every subgraph
+	// but the first one defining it with the name of the subgraph, e.g. Reviews_User.
+	// Datasources of the renamed types have to be configured with the prefixed names.
+	ConflictStrategyNamespace
+	// ConflictStrategyPreferFirst keeps the definition of the first subgraph and drops conflicting definitions
+	ConflictStrategyPreferFirst
+	// ConflictStrategyMerge merges the fields, enum values and union members of all definitions.
+	// Fields defined with different types and types of different kinds are still an error.
+	ConflictStrategyMerge
+)
+
+// Options configure MergeSDLsWithOptions
+type Options struct {
+	ConflictStrategy ConflictStrategy
+	// SubgraphNames are the names of the subgraphs in the order of the SDLs, used for namespaces and in errors.
+	// Subgraphs without name are named subgraph1, subgraph2, ...
+	SubgraphNames []string
+}
+
+func (o Options) subgraphName(subgraph int) string {
+	if subgraph < len(o.SubgraphNames) && o.SubgraphNames[subgraph] != "" {
+		return o.SubgraphNames[subgraph]
+	}
+	return fmt.Sprintf("subgraph%d", subgraph+1)
+}
+
+// typeShape is the comparable shape of a shared type definition
+type typeShape struct {
+	subgraph int
+	kind     ast.NodeKind
+	// members maps field names to their printed types, enum values and union members to empty strings
+	members map[string]string
+	order   []string
+}
+
+type subgraphDocument struct {
+	document ast.Document
+	changed  bool
+}
+
+// resolveTypeConflicts applies the conflict strategy to the shared types of the normalized subgraphs
+func resolveTypeConflicts(subgraphs []string, options Options) error {
+	documents := make([]*subgraphDocument, len(subgraphs))
+	for i := range subgraphs {
+		doc, report := astparser.ParseGraphqlDocumentString(subgraphs[i])
+		if report.HasErrors() {
+			return fmt.Errorf(parseDocumentError, report)
+		}
+		documents[i] = &subgraphDocument{document: doc}
+	}
+
+	entities := make(map[string]struct{})
+	for _, subgraph := range documents {
+		for _, node := range subgraph.document.RootNodes {
+			if subgraph.document.NodeHasDirectiveByNameString(node, plan.FederationKeyDirectiveName) {
+				entities[subgraph.document.NodeNameString(node)] = struct{}{}
+			}
+		}
+	}
+
+	shapes := make(map[string]*typeShape)
+	for i, subgraph := range documents {
+		doc := &subgraph.document
+		var renames []string
+		for r := 0; r < len(doc.RootNodes); r++ {
+			node := doc.RootNodes[r]
+			if !isSharedTypeDefinition(node.Kind) || doc.NodeHasDirectiveByNameString(node, extendsDirectiveName) {
+				continue
+			}
+			name := doc.NodeNameString(node)
+			if _, isEntity := entities[name]; isEntity || ast.IsRootType([]byte(name)) {
+				continue
+			}
+			shape := newTypeShape(doc, node, i)
+			first, exists := shapes[name]
+			if !exists {
+				shapes[name] = shape
+				continue
+			}
+			conflict := first.difference(shape, options)
+			if conflict == "" {
+				continue
+			}
+
+			switch options.ConflictStrategy {
+			case ConflictStrategyNamespace:
+				renames = append(renames, name)
+			case ConflictStrategyPreferFirst:
+				doc.RemoveRootNode(node)
+				r--
+				subgraph.changed = true
+			case ConflictStrategyMerge:
+				if conflict = first.mergeConflict(shape, options); conflict != "" {
+					return typeConflictError(name, conflict)
+				}
+				if !mergeIntoExtension(doc, r, first, shape) {
+					doc.RemoveRootNode(node)
+					r--
+				}
+				first.merge(shape)
+				subgraph.changed = true
+			default:
+				return typeConflictError(name, conflict)
+			}
+		}
+		for _, name := range renames {
+			renameType(doc, name, options.subgraphName(i)+"_"+name)
+			subgraph.changed = true
+		}
+	}
+
+	for i, subgraph := range documents {
+		if !subgraph.changed {
+			continue
+		}
+		out, err := astprinter.PrintString(&subgraph.document, nil)
+		if err != nil {
+			return fmt.Errorf("stringify schema: %w", err)
+		}
+		subgraphs[i] = out
+	}
+	return nil
+}
+
+func typeConflictError(name, conflict string) error {
+	report := operationreport.Report{}
+	report.AddExternalError(operationreport.ErrSharedTypesConflict(name, conflict))
+	return fmt.Errorf("resolve type conflicts: %w", report)
+}
+
+func isSharedTypeDefinition(kind ast.NodeKind) bool {
+	switch kind {
+	case ast.NodeKindObjectTypeDefinition,
+		ast.NodeKindInterfaceTypeDefinition,
+		ast.NodeKindInputObjectTypeDefinition,
+		ast.NodeKindEnumTypeDefinition,
+		ast.NodeKindUnionTypeDefinition,
+		ast.NodeKindScalarTypeDefinition:
+		return true
+	}
+	return false
+}
+
+func newTypeShape(doc *ast.Document, node ast.Node, subgraph int) *typeShape {
+	shape := &typeShape{
+		subgraph: subgraph,
+		kind:     node.Kind,
+		members:  make(map[string]string),
+	}
+	add := func(name, fieldType string) {
+		shape.members[name] = fieldType
+		shape.order = append(shape.order, name)
+	}
+	switch node.Kind {
+	case ast.NodeKindObjectTypeDefinition, ast.NodeKindInterfaceTypeDefinition:
+		for _, ref := range doc.NodeFieldDefinitions(node) {
+			fieldType, _ := doc.PrintTypeBytes(doc.FieldDefinitions[ref].Type, nil)
+			add(doc.FieldDefinitionNameString(ref), string(fieldType))
+		}
+	case ast.NodeKindInputObjectTypeDefinition:
+		for _, ref := range doc.NodeInputFieldDefinitions(node) {
+			fieldType, _ := doc.PrintTypeBytes(doc.InputValueDefinitions[ref].Type, nil)
+			add(doc.InputValueDefinitionNameString(ref), string(fieldType))
+		}
+	case ast.NodeKindEnumTypeDefinition:
+		for _, ref := range doc.EnumTypeDefinitions[node.Ref].EnumValuesDefinition.Refs {
+			add(doc.EnumValueDefinitionNameString(ref), "")
+		}
+	case ast.NodeKindUnionTypeDefinition:
+		for _, ref := range doc.UnionTypeDefinitions[node.Ref].UnionMemberTypes.Refs {
+			add(doc.TypeNameString(ref), "")
+		}
+	}
+	return shape
+}
+
+// difference describes the first difference of the shapes or returns an empty string if they are identical
+func (t *typeShape) difference(other *typeShape, options Options) string {
+	if conflict := t.mergeConflict(other, options); conflict != "" {
+		return conflict
+	}
+	if missing := missingMember(t, other); missing != "" {
+		return fmt.Sprintf("the %s '%s' is defined in subgraph '%s' but not in subgraph '%s'",
+			t.memberKind(), missing, options.subgraphName(t.subgraph), options.subgraphName(other.subgraph))
+	}
+	if missing := missingMember(other, t); missing != "" {
+		return fmt.Sprintf("the %s '%s' is defined in subgraph '%s' but not in subgraph '%s'",
+			t.memberKind(), missing, options.subgraphName(other.subgraph), options.subgraphName(t.subgraph))
+	}
+	return ""
+}
+
+// mergeConflict describes why the shapes can't be merged or returns an empty string if they can
+func (t *typeShape) mergeConflict(other *typeShape, options Options) string {
+	if t.kind != other.kind {
+		return fmt.Sprintf("it is defined as %s in subgraph '%s' but as %s in subgraph '%s'",
+			kindName(t.kind), options.subgraphName(t.subgraph), kindName(other.kind), options.subgraphName(other.subgraph))
+	}
+	for _, name := range other.order {
+		fieldType, exists := t.members[name]
+		if exists && fieldType != other.members[name] {
+			return fmt.Sprintf("the field '%s' is of type '%s' in subgraph '%s' but of type '%s' in subgraph '%s'",
+				name, fieldType, options.subgraphName(t.subgraph), other.members[name], options.subgraphName(other.subgraph))
+		}
+	}
+	return ""
+}
+
+func (t *typeShape) merge(other *typeShape) {
+	for _, name := range other.order {
+		if _, exists := t.members[name]; !exists {
+			t.members[name] = other.members[name]
+			t.order = append(t.order, name)
+		}
+	}
+}
+
+func kindName(kind ast.NodeKind) string {
+	switch kind {
+	case ast.NodeKindObjectTypeDefinition:
+		return "object"
+	case ast.NodeKindInterfaceTypeDefinition:
+		return "interface"
+	case ast.NodeKindInputObjectTypeDefinition:
+		return "input"
+	case ast.NodeKindEnumTypeDefinition:
+		return "enum"
+	case ast.NodeKindUnionTypeDefinition:
+		return "union"
+	default:
+		return "scalar"
+	}
+}
+
+func (t *typeShape) memberKind() string {
+	switch t.kind {
+	case ast.NodeKindEnumTypeDefinition:
+		return "value"
+	case ast.NodeKindUnionTypeDefinition:
+		return "member"
+	default:
+		return "field"
+	}
+}
+
+// missingMember returns the first member of the shape which the other shape doesn't have
+func missingMember(shape, other *typeShape) string {
+	for _, name := range shape.order {
+		if _, exists := other.members[name]; !exists {
+			return name
+		}
+	}
+	return ""
+}
+
+// mergeIntoExtension replaces the definition at the root node index with an extension of the members
+// which the first definition doesn't have, so that the extension is merged into the first definition.
+// It returns false if there are no such members.
+func mergeIntoExtension(doc *ast.Document, rootNode int, first, shape *typeShape) bool {
+	node := doc.RootNodes[rootNode]
+	missing := func(refs []int, name func(ref int) string) []int {
+		var out []int
+		for _, ref := range refs {
+			if _, exists := first.members[name(ref)]; !exists {
+				out = append(out, ref)
+			}
+		}
+		return out
+	}
+
+	var extension ast.Node
+	switch node.Kind {
+	case ast.NodeKindObjectTypeDefinition:
+		definition := doc.ObjectTypeDefinitions[node.Ref]
+		definition.FieldsDefinition.Refs = missing(definition.FieldsDefinition.Refs, doc.FieldDefinitionNameString)
+		if len(definition.FieldsDefinition.Refs) == 0 {
+			return false
+		}
+		definition.Description.IsDefined = false
+		definition.Directives.Refs, definition.HasDirectives = nil, false
+		doc.ObjectTypeExtensions = append(doc.ObjectTypeExtensions, ast.ObjectTypeExtension{ObjectTypeDefinition: definition})
+		extension = ast.Node{Kind: ast.NodeKindObjectTypeExtension, Ref: len(doc.ObjectTypeExtensions) - 1}
+	case ast.NodeKindInterfaceTypeDefinition:
+		definition := doc.InterfaceTypeDefinitions[node.Ref]
+		definition.FieldsDefinition.Refs = missing(definition.FieldsDefinition.Refs, doc.FieldDefinitionNameString)
+		if len(definition.FieldsDefinition.Refs) == 0 {
+			return false
+		}
+		definition.Description.IsDefined = false
+		definition.Directives.Refs, definition.HasDirectives = nil, false
+		doc.InterfaceTypeExtensions = append(doc.InterfaceTypeExtensions, ast.InterfaceTypeExtension{InterfaceTypeDefinition: definition})
+		extension = ast.Node{Kind: ast.NodeKindInterfaceTypeExtension, Ref: len(doc.InterfaceTypeExtensions) - 1}
+	case ast.NodeKindInputObjectTypeDefinition:
+		definition := doc.InputObjectTypeDefinitions[node.Ref]
+		definition.InputFieldsDefinition.Refs = missing(definition.InputFieldsDefinition.Refs, doc.InputValueDefinitionNameString)
+		if len(definition.InputFieldsDefinition.Refs) == 0 {
+			return false
+		}
+		definition.Description.IsDefined = false
+		definition.Directives.Refs, definition.HasDirectives = nil, false
+		doc.InputObjectTypeExtensions = append(doc.InputObjectTypeExtensions, ast.InputObjectTypeExtension{InputObjectTypeDefinition: definition})
+		extension = ast.Node{Kind: ast.NodeKindInputObjectTypeExtension, Ref: len(doc.InputObjectTypeExtensions) - 1}
+	case ast.NodeKindEnumTypeDefinition:
+		definition := doc.EnumTypeDefinitions[node.Ref]
+		definition.EnumValuesDefinition.Refs = missing(definition.EnumValuesDefinition.Refs, doc.EnumValueDefinitionNameString)
+		if len(definition.EnumValuesDefinition.Refs) == 0 {
+			return false
+		}
+		definition.Description.IsDefined = false
+		definition.Directives.Refs, definition.HasDirectives = nil, false
+		doc.EnumTypeExtensions = append(doc.EnumTypeExtensions, ast.EnumTypeExtension{EnumTypeDefinition: definition})
+		extension = ast.Node{Kind: ast.NodeKindEnumTypeExtension, Ref: len(doc.EnumTypeExtensions) - 1}
+	case ast.NodeKindUnionTypeDefinition:
+		definition := doc.UnionTypeDefinitions[node.Ref]
+		definition.UnionMemberTypes.Refs = missing(definition.UnionMemberTypes.Refs, doc.TypeNameString)
+		if len(definition.UnionMemberTypes.Refs) == 0 {
+			return false
+		}
+		definition.Description.IsDefined = false
+		definition.Directives.Refs, definition.HasDirectives = nil, false
+		doc.UnionTypeExtensions = append(doc.UnionTypeExtensions, ast.UnionTypeExtension{UnionTypeDefinition: definition})
+		extension = ast.Node{Kind: ast.NodeKindUnionTypeExtension, Ref: len(doc.UnionTypeExtensions) - 1}
+	default:
+		return false
+	}
+	doc.RootNodes[rootNode] = extension
+	return true
+}
+
+// renameType renames the definitions, extensions and all references of the type in the document
+func renameType(doc *ast.Document, name, newName string) {
+	renamed := doc.Input.AppendInputString(newName)
+	for _, node := range doc.RootNodes {
+		if doc.NodeNameString(node) != name {
+			continue
+		}
+		switch node.Kind {
+		case ast.NodeKindObjectTypeDefinition:
+			doc.ObjectTypeDefinitions[node.Ref].Name = renamed
+		case ast.NodeKindObjectTypeExtension:
+			doc.ObjectTypeExtensions[node.Ref].Name = renamed
+		case ast.NodeKindInterfaceTypeDefinition:
+			doc.InterfaceTypeDefinitions[node.Ref].Name = renamed
+		case ast.NodeKindInterfaceTypeExtension:
+			doc.InterfaceTypeExtensions[node.Ref].Name = renamed
+		case ast.NodeKindInputObjectTypeDefinition:
+			doc.InputObjectTypeDefinitions[node.Ref].Name = renamed
+		case ast.NodeKindEnumTypeDefinition:
+			doc.EnumTypeDefinitions[node.Ref].Name = renamed
+		case ast.NodeKindEnumTypeExtension:
+			doc.EnumTypeExtensions[node.Ref].Name = renamed
+		case ast.NodeKindUnionTypeDefinition:
+			doc.UnionTypeDefinitions[node.Ref].Name = renamed
+		case ast.NodeKindUnionTypeExtension:
+			doc.UnionTypeExtensions[node.Ref].Name = renamed
+		case ast.NodeKindScalarTypeDefinition:
+			doc.ScalarTypeDefinitions[node.Ref].Name = renamed
+		}
+	}
+	for i := range doc.InputObjectTypeExtensions {
+		if doc.InputObjectTypeExtensionNameString(i) == name {
+			doc.InputObjectTypeExtensions[i].Name = renamed
+		}
+	}
+	for i := range doc.ScalarTypeExtensions {
+		if doc.ScalarTypeExtensionNameString(i) == name {
+			doc.ScalarTypeExtensions[i].Name = renamed
+		}
+	}
+	for i := range doc.Types {
+		if doc.Types[i].TypeKind == ast.TypeKindNamed && doc.TypeNameString(i) == name {
+			doc.Types[i].Name = renamed
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package sdlmerge
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+func TestMergeSDLsWithOptions(t *testing.T) {
+	const accounts = `
+		type Query {
+			address: Address
+			status: Status
+		}
+
+		type Address {
+			street: String!
+		}
+
+		enum Status {
+			ACTIVE
+		}
+	`
+	const locations = `
+		type Query {
+			location: Address
+		}
+
+		"""
+		The address of a location
+		"""
+		type Address {
+			street: String!
+			city: String
+		}
+
+		enum Status {
+			ACTIVE
+			INACTIVE
+		}
+
+		union Place = Address
+	`
+
+	runMergeTest := func(strategy ConflictStrategy, expectedSchema string, sdls ...string) func(t *testing.T) {
+		return func(t *testing.T) {
+			got, err := MergeSDLsWithOptions(Options{ConflictStrategy: strategy, SubgraphNames: []string{"accounts", "locations"}}, sdls...)
+			require.NoError(t, err)
+
+			expectedOutputDocument := unsafeparser.ParseGraphqlDocumentString(expectedSchema)
+			want := mustString(astprinter.PrintString(&expectedOutputDocument, nil))
+			assert.Equal(t, want, got)
+		}
+	}
+
+	runMergeTestAndExpectError := func(strategy ConflictStrategy, expectedError string, sdls ...string) func(t *testing.T) {
+		return func(t *testing.T) {
+			_, err := MergeSDLsWithOptions(Options{ConflictStrategy: strategy}, sdls...)
+			actual, _ := operationreport.ExternalErrorMessage(err, testFormatExternalErrorMessage)
+			assert.Equal(t, expectedError, actual)
+		}
+	}
+
+	t.Run("error", func(t *testing.T) {
+		_, err := MergeSDLsWithOptions(Options{SubgraphNames: []string{"accounts", "locations"}}, accounts, locations)
+		actual, _ := operationreport.ExternalErrorMessage(err, testFormatExternalErrorMessage)
+		assert.Equal(t, conflictingSharedTypeErrorMessage("Address", "the field 'city' is defined in subgraph 'locations' but not in subgraph 'accounts'"), actual)
+	})
+
+	t.Run("namespace", runMergeTest(ConflictStrategyNamespace, `
+		type Query {
+			address: Address
+			status: Status
+			location: locations_Address
+		}
+
+		type Address {
+			street: String!
+		}
+
+		enum Status {
+			ACTIVE
+		}
+
+		"""
+		The address of a location
+		"""
+		type locations_Address {
+			street: String!
+			city: String
+		}
+
+		enum locations_Status {
+			ACTIVE
+			INACTIVE
+		}
+
+		union Place = locations_Address
+	`, accounts, locations))
+
+	t.Run("prefer first", runMergeTest(ConflictStrategyPreferFirst, `
+		type Query {
+			address: Address
+			status: Status
+			location: Address
+		}
+
+		type Address {
+			street: String!
+		}
+
+		enum Status {
+			ACTIVE
+		}
+
+		union Place = Address
+	`, accounts, locations))
+
+	t.Run("merge", runMergeTest(ConflictStrategyMerge, `
+		type Query {
+			address: Address
+			status: Status
+			location: Address
+		}
+
+		type Address {
+			street: String!
+			city: String
+		}
+
+		enum Status {
+			ACTIVE
+			INACTIVE
+		}
+
+		union Place = Address
+	`, accounts, locations))
+
+	t.Run("merge of identical and subset definitions", runMergeTest(ConflictStrategyMerge, `
+		type Query {
+			address: Address
+			status: Status
+			location: Address
+			home: Address
+		}
+
+		type Address {
+			street: String!
+			city: String
+		}
+
+		enum Status {
+			ACTIVE
+			INACTIVE
+		}
+
+		union Place = Address
+	`, accounts, locations, `
+		type Query {
+			home: Address
+		}
+
+		type Address {
+			street: String!
+		}
+	`))
+
+	t.Run("merge of fields with different types returns an error", runMergeTestAndExpectError(ConflictStrategyMerge,
+		conflictingSharedTypeErrorMessage("Address", "the field 'street' is of type 'String!' in subgraph 'subgraph1' but of type 'String' in subgraph 'subgraph2'"),
+		accounts, `
+			type Query {
+				location: Address
+			}
+
+			type Address {
+				street: String
+			}
+		`,
+	))
+
+	t.Run("merge of different kinds returns an error", runMergeTestAndExpectError(ConflictStrategyMerge,
+		conflictingSharedTypeErrorMessage("Status", "it is defined as enum in subgraph 'subgraph1' but as object in subgraph 'subgraph2'"),
+		accounts, `
+			type Query {
+				location: Status
+			}
+
+			type Status {
+				active: Boolean
+			}
+		`,
+	))
+}
@@ -0,0 +1,88 @@
+package graphql
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestExecutionEngineV2_AbstractChildNodes(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			hero: Character
+		}
+
+		interface Character {
+			name: String!
+		}
+
+		type Human implements Character {
+			name: String!
+			height: String!
+		}
+
+		type Droid implements Character {
+			name: String!
+			primaryFunction: String!
+		}`)
+	require.NoError(t, err)
+
+	execute := func(t *testing.T, childNodes []plan.TypeField, query string) (response, upstreamQuery string) {
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes:  []plan.TypeField{{TypeName: "Query", FieldNames: []string{"hero"}}},
+				ChildNodes: childNodes,
+				Factory: &graphql_datasource.Factory{
+					HTTPClient: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							body, _ := ioutil.ReadAll(req.Body)
+							upstreamQuery = string(body)
+							return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"hero":{"__typename":"Human","name":"Luke","height":"1.72"}}}`))}
+						}),
+					},
+				},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch: graphql_datasource.FetchConfiguration{
+						URL:    "https://heroes/graphql",
+						Method: "POST",
+					},
+				}),
+			},
+		})
+		engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+
+		resultWriter := NewEngineResultWriter()
+		require.NoError(t, engine.Execute(context.Background(), &Request{Query: query}, &resultWriter))
+		return resultWriter.String(), upstreamQuery
+	}
+
+	t.Run("fields of interface child nodes selected in inline fragments on implementing types", func(t *testing.T) {
+		response, upstreamQuery := execute(t, []plan.TypeField{
+			{TypeName: "Character", FieldNames: []string{"name"}},
+			{TypeName: "Human", FieldNames: []string{"height"}},
+			{TypeName: "Droid", FieldNames: []string{"primaryFunction"}},
+		}, `{ hero { ... on Human { name height } } }`)
+		assert.Equal(t, `{"query":"{hero {__typename ... on Human {name height}}}"}`, upstreamQuery)
+		assert.Equal(t, `{"data":{"hero":{"name":"Luke","height":"1.72"}}}`, response)
+	})
+
+	t.Run("fields of interfaces provided by all implementing types", func(t *testing.T) {
+		response, upstreamQuery := execute(t, []plan.TypeField{
+			{TypeName: "Human", FieldNames: []string{"name", "height"}},
+			{TypeName: "Droid", FieldNames: []string{"name", "primaryFunction"}},
+		}, `{ hero { name } }`)
+		assert.Equal(t, `{"query":"{hero {__typename name}}"}`, upstreamQuery)
+		assert.Equal(t, `{"data":{"hero":{"name":"Luke"}}}`, response)
+	})
+}
@@ -0,0 +1,137 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	ErrInvalidAdmissionControlConcurrency = errors.New("admission control concurrency must be greater than 0")
+	ErrAdmissionQueueFull                 = errors.New("too many operations are waiting to be prepared")
+	ErrAdmissionQueueTimeout              = errors.New("operation waited too long to be prepared")
+)
+
+// AdmissionControlConfig bounds the number of operations which are parsed, normalized, validated and planned concurrently.
+// Operations exceeding the concurrency wait in a queue, once the queue is full operations are rejected,
+// so that traffic spikes don't increase the latency of the operations in flight.
+// Resolving the responses of prepared operations isn't limited.
+type AdmissionControlConfig struct {
+	// MaxConcurrency is the maximum number of operations prepared concurrently
+	MaxConcurrency int
+	// MaxQueueSize is the maximum number of operations waiting to be prepared, 0 rejects operations once MaxConcurrency is reached
+	MaxQueueSize int
+	// MaxQueueWait is the maximum duration an operation waits in the queue, 0 waits until the context of the operation is done
+	MaxQueueWait time.Duration
+}
+
+// AdmissionControlStats contains the current state and the rejections of the admission control
+type AdmissionControlStats struct {
+	InFlight int
+	Queued   int
+	Rejected uint64
+}
+
+// AdmissionRejectedError is returned by Execute if the operation was rejected by the admission control.
+// Clients should retry later, the HTTP status code is 429 Too Many Requests.
+type AdmissionRejectedError struct {
+	// Reason is ErrAdmissionQueueFull or ErrAdmissionQueueTimeout
+	Reason error
+}
+
+func (e *AdmissionRejectedError) Error() string {
+	return "operation rejected: " + e.Reason.Error()
+}
+
+func (e *AdmissionRejectedError) Unwrap() error {
+	return e.Reason
+}
+
+func (e *AdmissionRejectedError) StatusCode() int {
+	return http.StatusTooManyRequests
+}
+
+type admissionController struct {
+	config   AdmissionControlConfig
+	slots    chan struct{}
+	queued   int64
+	rejected uint64
+}
+
+func newAdmissionController(config *AdmissionControlConfig) (*admissionController, error) {
+	if config == nil {
+		return nil, nil
+	}
+	if config.MaxConcurrency <= 0 {
+		return nil, ErrInvalidAdmissionControlConcurrency
+	}
+	return &admissionController{
+		config: *config,
+		slots:  make(chan struct{}, config.MaxConcurrency),
+	}, nil
+}
+
+// acquire waits for a free slot, the returned release func frees the slot and may be called multiple times
+func (a *admissionController) acquire(ctx context.Context) (release func(), err error) {
+	select {
+	case a.slots <- struct{}{}:
+		return a.releaseFunc(), nil
+	default:
+	}
+
+	if atomic.AddInt64(&a.queued, 1) > int64(a.config.MaxQueueSize) {
+		atomic.AddInt64(&a.queued, -1)
+		return nil, a.reject(ErrAdmissionQueueFull)
+	}
+	defer atomic.AddInt64(&a.queued, -1)
+
+	var timeout <-chan time.Time
+	if a.config.MaxQueueWait > 0 {
+		timer := time.NewTimer(a.config.MaxQueueWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case a.slots <- struct{}{}:
+		return a.releaseFunc(), nil
+	case <-timeout:
+		return nil, a.reject(ErrAdmissionQueueTimeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (a *admissionController) releaseFunc() func() {
+	released := false
+	return func() {
+		if released {
+			return
+		}
+		released = true
+		<-a.slots
+	}
+}
+
+func (a *admissionController) reject(reason error) error {
+	atomic.AddUint64(&a.rejected, 1)
+	return &AdmissionRejectedError{Reason: reason}
+}
+
+func (a *admissionController) stats() AdmissionControlStats {
+	return AdmissionControlStats{
+		InFlight: len(a.slots),
+		Queued:   int(atomic.LoadInt64(&a.queued)),
+		Rejected: atomic.LoadUint64(&a.rejected),
+	}
+}
+
+// AdmissionControlStats returns the stats of the admission control, see EngineV2Configuration.SetAdmissionControl
+func (e *ExecutionEngineV2) AdmissionControlStats() AdmissionControlStats {
+	if e.admission == nil {
+		return AdmissionControlStats{}
+	}
+	return e.admission.stats()
+}
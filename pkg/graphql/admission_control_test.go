@@ -0,0 +1,134 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestAdmissionController(t *testing.T) {
+	t.Run("invalid concurrency", func(t *testing.T) {
+		_, err := newAdmissionController(&AdmissionControlConfig{})
+		assert.Equal(t, ErrInvalidAdmissionControlConcurrency, err)
+	})
+
+	t.Run("rejects operations once the queue is full", func(t *testing.T) {
+		controller, err := newAdmissionController(&AdmissionControlConfig{MaxConcurrency: 1})
+		require.NoError(t, err)
+
+		release, err := controller.acquire(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, AdmissionControlStats{InFlight: 1}, controller.stats())
+
+		_, err = controller.acquire(context.Background())
+		assert.ErrorIs(t, err, ErrAdmissionQueueFull)
+		var rejected *AdmissionRejectedError
+		require.True(t, errors.As(err, &rejected))
+		assert.Equal(t, http.StatusTooManyRequests, rejected.StatusCode())
+
+		release()
+		release()
+		assert.Equal(t, AdmissionControlStats{Rejected: 1}, controller.stats())
+
+		release, err = controller.acquire(context.Background())
+		require.NoError(t, err)
+		release()
+	})
+
+	t.Run("queued operations wait for a free slot", func(t *testing.T) {
+		controller, err := newAdmissionController(&AdmissionControlConfig{MaxConcurrency: 1, MaxQueueSize: 1})
+		require.NoError(t, err)
+
+		release, err := controller.acquire(context.Background())
+		require.NoError(t, err)
+
+		acquired := make(chan error)
+		go func() {
+			queuedRelease, err := controller.acquire(context.Background())
+			if err == nil {
+				queuedRelease()
+			}
+			acquired <- err
+		}()
+
+		assert.Eventually(t, func() bool {
+			return controller.stats().Queued == 1
+		}, time.Second, time.Millisecond)
+		release()
+		assert.NoError(t, <-acquired)
+		assert.Equal(t, AdmissionControlStats{}, controller.stats())
+	})
+
+	t.Run("queued operations time out", func(t *testing.T) {
+		controller, err := newAdmissionController(&AdmissionControlConfig{MaxConcurrency: 1, MaxQueueSize: 1, MaxQueueWait: time.Millisecond})
+		require.NoError(t, err)
+
+		release, err := controller.acquire(context.Background())
+		require.NoError(t, err)
+		defer release()
+
+		_, err = controller.acquire(context.Background())
+		assert.ErrorIs(t, err, ErrAdmissionQueueTimeout)
+	})
+
+	t.Run("queued operations are cancelled with their context", func(t *testing.T) {
+		controller, err := newAdmissionController(&AdmissionControlConfig{MaxConcurrency: 1, MaxQueueSize: 1})
+		require.NoError(t, err)
+
+		release, err := controller.acquire(context.Background())
+		require.NoError(t, err)
+		defer release()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = controller.acquire(ctx)
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, uint64(0), controller.stats().Rejected)
+	})
+}
+
+func TestExecutionEngineV2_AdmissionControl(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			hello: String
+		}`)
+	require.NoError(t, err)
+
+	preparing := make(chan struct{})
+	proceed := make(chan struct{})
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetAdmissionControl(AdmissionControlConfig{MaxConcurrency: 1})
+	engineConf.AddOperationTransform(OperationTransformFunc(func(ctx context.Context, request *Request, operation, definition *ast.Document) error {
+		preparing <- struct{}{}
+		<-proceed
+		return nil
+	}))
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	executed := make(chan error)
+	go func() {
+		resultWriter := NewEngineResultWriter()
+		executed <- engine.Execute(context.Background(), &Request{Query: `{ __typename }`}, &resultWriter)
+	}()
+	<-preparing
+
+	resultWriter := NewEngineResultWriter()
+	err = engine.Execute(context.Background(), &Request{Query: `{ __typename }`}, &resultWriter)
+	assert.ErrorIs(t, err, ErrAdmissionQueueFull)
+	assert.Equal(t, AdmissionControlStats{InFlight: 1, Rejected: 1}, engine.AdmissionControlStats())
+
+	close(proceed)
+	assert.NoError(t, <-executed)
+	assert.Equal(t, AdmissionControlStats{Rejected: 1}, engine.AdmissionControlStats())
+}
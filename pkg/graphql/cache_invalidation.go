@@ -0,0 +1,131 @@
+package graphql
+
+import (
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+)
+
+// CacheInvalidationEvent describes the cached data which is stale after a mutation was executed.
+type CacheInvalidationEvent struct {
+	// Tags are the entity tags to invalidate, e.g. "User:1"
+	Tags []string
+	// OperationFamilies are the root fields of the queries to invalidate, e.g. "Query.users",
+	// for data which is not identified by entities, like lists or search results
+	OperationFamilies []string
+}
+
+// MutationInvalidationHook inspects executed mutations and their responses
+// and returns the events to invalidate the caches with.
+// It's only called for mutations which were resolved without errors.
+type MutationInvalidationHook interface {
+	OnMutation(operation *Request, response []byte) []CacheInvalidationEvent
+}
+
+type MutationInvalidationHookFunc func(operation *Request, response []byte) []CacheInvalidationEvent
+
+func (f MutationInvalidationHookFunc) OnMutation(operation *Request, response []byte) []CacheInvalidationEvent {
+	return f(operation, response)
+}
+
+// CacheInvalidator consumes the invalidation events emitted for mutations, e.g. the ResponseCache.
+type CacheInvalidator interface {
+	Invalidate(event CacheInvalidationEvent)
+}
+
+// operationFamilies returns the root fields selected by the operation, e.g. "Query.users".
+// The root operation type names are the ones of the schema, e.g. "RootQuery.users" for schema { query: RootQuery }.
+func operationFamilies(operation *Request, definition *ast.Document) []string {
+	classification, err := operation.Classify()
+	if err != nil || len(classification.RootFields) == 0 {
+		return nil
+	}
+
+	queryTypeName, mutationTypeName, subscriptionTypeName := definition.Index.RootOperationTypeNames()
+	var typeName string
+	switch classification.OperationType {
+	case OperationTypeQuery:
+		typeName = queryTypeName.String()
+	case OperationTypeMutation:
+		typeName = mutationTypeName.String()
+	default:
+		typeName = subscriptionTypeName.String()
+	}
+
+	families := make([]string, 0, len(classification.RootFields))
+	for _, fieldName := range classification.RootFields {
+		families = append(families, typeName+"."+fieldName)
+	}
+	return families
+}
+
+func containsString(values []string, value string) bool {
+	for i := range values {
+		if values[i] == value {
+			return true
+		}
+	}
+	return false
+}
+
+// responseRecorder passes the response to the writer of the request and keeps a copy of it,
+// so that it can be cached or inspected for invalidation once it's complete
+type responseRecorder struct {
+	writer   resolve.FlushWriter
+	response []byte
+}
+
+func (r *responseRecorder) Write(p []byte) (n int, err error) {
+	r.response = append(r.response, p...)
+	return r.writer.Write(p)
+}
+
+func (r *responseRecorder) Flush() {
+	r.writer.Flush()
+}
+
+// recordsResponse returns true if the response of the operation is cached or used to invalidate caches
+func (e *ExecutionEngineV2) recordsResponse(operationType OperationType) bool {
+	switch operationType {
+	case OperationTypeQuery:
+		return e.config.responseCache != nil
+	case OperationTypeMutation:
+		return e.config.responseCache != nil || e.config.mutationInvalidationHook != nil
+	default:
+		return false
+	}
+}
+
+func (e *ExecutionEngineV2) completeResponse(operation *Request, operationType OperationType, cacheKey uint64, response []byte) {
+	switch operationType {
+	case OperationTypeQuery:
+		e.config.responseCache.store(operation, &e.config.schema.document, cacheKey, response)
+	case OperationTypeMutation:
+		e.invalidateCaches(operation, response)
+	}
+}
+
+// invalidateCaches passes the invalidation events of a mutation to the response cache and all cache invalidators
+func (e *ExecutionEngineV2) invalidateCaches(operation *Request, response []byte) {
+	if responseHasErrors(response) {
+		return
+	}
+
+	var events []CacheInvalidationEvent
+	if e.config.responseCache != nil {
+		if event, ok := e.config.responseCache.mutationInvalidationEvent(response); ok {
+			events = append(events, event)
+		}
+	}
+	if e.config.mutationInvalidationHook != nil {
+		events = append(events, e.config.mutationInvalidationHook.OnMutation(operation, response)...)
+	}
+
+	for _, event := range events {
+		if e.config.responseCache != nil {
+			e.config.responseCache.Invalidate(event)
+		}
+		for _, invalidator := range e.config.cacheInvalidators {
+			invalidator.Invalidate(event)
+		}
+	}
+}
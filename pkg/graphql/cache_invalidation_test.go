@@ -0,0 +1,35 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOperationFamilies(t *testing.T) {
+	schema, err := NewSchemaFromString(`type Query { users: [User] me: User } type Mutation { renameUser(id: Int): User } type User { id: Int }`)
+	require.NoError(t, err)
+
+	families := func(t *testing.T, query, operationName string) []string {
+		t.Helper()
+		operation := Request{Query: query, OperationName: operationName}
+		report := operation.parseQueryOnce()
+		require.False(t, report.HasErrors())
+		return operationFamilies(&operation, &schema.document)
+	}
+
+	assert.Equal(t, []string{"Query.users", "Query.me"}, families(t, `{ users { id } me { id } all: users { id } }`, ""))
+	assert.Equal(t, []string{"Mutation.renameUser"}, families(t, `query Users { users { id } } mutation Rename { renameUser(id: 1) { id } }`, "Rename"))
+	assert.Empty(t, families(t, `query Users { users { id } }`, "Other"))
+
+	t.Run("root operation types of the schema", func(t *testing.T) {
+		schema, err := NewSchemaFromString(`schema { query: RootQuery mutation: RootMutation } type RootQuery { users: [Int] } type RootMutation { reset: Int }`)
+		require.NoError(t, err)
+
+		operation := Request{Query: `{ users }`}
+		assert.Equal(t, []string{"RootQuery.users"}, operationFamilies(&operation, &schema.document))
+		operation = Request{Query: `mutation { reset }`}
+		assert.Equal(t, []string{"RootMutation.reset"}, operationFamilies(&operation, &schema.document))
+	})
+}
@@ -72,6 +72,7 @@ func NewFederationEngineConfigFactory(dataSourceConfigs []graphqlDataSource.Conf
 		batchFactory:              batchFactory,
 		subscriptionClientFactory: options.subscriptionClientFactory,
 		subscriptionType:          options.subscriptionType,
+		schemaBuilder:             federation.NewBaseSchemaBuilder(),
 	}
 }
 
@@ -84,6 +85,8 @@ type FederationEngineConfigFactory struct {
 	batchFactory              resolve.DataSourceBatchFactory
 	subscriptionClientFactory graphqlDataSource.GraphQLSubscriptionClientFactory
 	subscriptionType          SubscriptionType
+	// schemaBuilder builds the federation schemas of the subgraphs, it's shared by their data sources
+	schemaBuilder *federation.BaseSchemaBuilder
 }
 
 func (f *FederationEngineConfigFactory) SetMergedSchemaFromString(mergedSchema string) (err error) {
@@ -169,6 +172,7 @@ func (f *FederationEngineConfigFactory) engineConfigDataSources() (planDataSourc
 			f.httpClient,
 			WithDataSourceV2GeneratorSubscriptionConfiguration(f.streamingClient, f.subscriptionType),
 			WithDataSourceV2GeneratorSubscriptionClientFactory(f.subscriptionClientFactory),
+			WithDataSourceV2GeneratorSchemaBuilder(f.schemaBuilder),
 		)
 		if err != nil {
 			return nil, err
@@ -12,6 +12,7 @@ import (
 	graphqlDataSource "github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+	"github.com/wundergraph/graphql-go-tools/pkg/federation"
 )
 
 func TestEngineConfigV2Factory_EngineV2Configuration(t *testing.T) {
@@ -41,6 +42,9 @@ func TestEngineConfigV2Factory_EngineV2Configuration(t *testing.T) {
 		config, err := engineConfigV2Factory.EngineV2Configuration()
 		assert.NoError(t, err)
 		assert.Equal(t, expectedConfigFactory(t, printedBaseSchema), config)
+		for _, dataSource := range config.DataSources() {
+			assert.Same(t, engineConfigV2Factory.schemaBuilder, dataSource.Factory.(*graphqlDataSource.Factory).SchemaBuilder)
+		}
 	}
 
 	httpClient := &http.Client{}
@@ -84,6 +88,7 @@ func TestEngineConfigV2Factory_EngineV2Configuration(t *testing.T) {
 			require.NoError(t, err)
 
 			conf := NewEngineV2Configuration(schema)
+			schemaBuilder := federation.NewBaseSchemaBuilder()
 			conf.SetFieldConfigurations(plan.FieldConfigurations{
 				{
 					TypeName:       "User",
@@ -154,6 +159,7 @@ func TestEngineConfigV2Factory_EngineV2Configuration(t *testing.T) {
 						StreamingClient:    streamingClient,
 						BatchFactory:       batchFactory,
 						SubscriptionClient: mockSubscriptionClient,
+						SchemaBuilder:      schemaBuilder,
 					},
 				},
 				{
@@ -187,6 +193,7 @@ func TestEngineConfigV2Factory_EngineV2Configuration(t *testing.T) {
 						StreamingClient:    streamingClient,
 						BatchFactory:       batchFactory,
 						SubscriptionClient: mockSubscriptionClient,
+						SchemaBuilder:      schemaBuilder,
 					},
 				},
 				{
@@ -219,6 +226,7 @@ func TestEngineConfigV2Factory_EngineV2Configuration(t *testing.T) {
 						StreamingClient:    streamingClient,
 						BatchFactory:       batchFactory,
 						SubscriptionClient: mockSubscriptionClient,
+						SchemaBuilder:      schemaBuilder,
 					},
 					Custom: graphqlDataSource.ConfigJson(graphqlDataSource.Configuration{
 						Fetch: graphqlDataSource.FetchConfiguration{
@@ -0,0 +1,523 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafebytes"
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+	"github.com/wundergraph/graphql-go-tools/pkg/fastbuffer"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+	"github.com/wundergraph/graphql-go-tools/pkg/postprocess"
+)
+
+// ErrContractViolation is returned by ContractTestCase.Run if the response of the upstream doesn't match the contract of the test case
+var ErrContractViolation = errors.New("upstream response violates the contract")
+
+// ContractTestCase is the contract of a query root field of a data source, see ExecutionEngineV2.GenerateContractTests
+type ContractTestCase struct {
+	// Name is the coordinate of the root field, e.g. Query.user
+	Name string
+	// DataSource is the identifier of the data source, e.g. graphql_datasource.Source
+	DataSource string
+	TypeName   string
+	FieldName  string
+	// Operation is the operation of the gateway which fetches the root field from the data source
+	Operation string
+	// Request is the request the gateway sends to the upstream for the Operation
+	Request ContractTestRequest
+	// Response is a minimal valid response of the upstream for the Request
+	Response []byte
+
+	// graphqlResponse is true if the upstream responds with a GraphQL response, i.e. the data is extracted from its data field
+	graphqlResponse bool
+	// response is the object of the fetch in the plan of the Operation, the response of the upstream must be resolvable to it
+	response *resolve.Object
+}
+
+// ContractTestRequest is the request of a ContractTestCase to the upstream
+type ContractTestRequest struct {
+	Method string
+	URL    string
+	Header http.Header
+	Body   []byte
+}
+
+// Run sends the Request of the test case to the upstream and verifies that the response matches the shape of the Response,
+// i.e. non-null fields aren't null and the values have the types of their fields, and that a GraphQL upstream doesn't respond with errors.
+// The violations of the contract are returned as ErrContractViolation, other errors are returned if the upstream couldn't be reached.
+func (c *ContractTestCase) Run(ctx context.Context, client *http.Client) error {
+	input := httpclient.SetInputURL(nil, []byte(c.Request.URL))
+	input = httpclient.SetInputMethod(input, []byte(c.Request.Method))
+	input = httpclient.SetInputBody(input, c.Request.Body)
+
+	buf := &bytes.Buffer{}
+	statusCode, err := httpclient.DoWithStatusCode(client, ctx, input, c.Request.Header, buf)
+	if err != nil {
+		return err
+	}
+
+	verifier := contractVerifier{}
+	if statusCode < http.StatusOK || statusCode >= http.StatusMultipleChoices {
+		verifier.violation("", "unexpected status code %d", statusCode)
+		return verifier.err()
+	}
+
+	data, dataType, _, _ := jsonparser.Get(buf.Bytes())
+	if c.graphqlResponse {
+		if upstreamErrors, errorsType, _, _ := jsonparser.Get(buf.Bytes(), "errors"); errorsType == jsonparser.Array && !bytes.Equal(upstreamErrors, []byte("[]")) {
+			verifier.violation("", "upstream responded with errors: %s", string(upstreamErrors))
+			return verifier.err()
+		}
+		data, dataType, _, _ = jsonparser.Get(buf.Bytes(), "data")
+	}
+	verifier.verifyNode(c.response, data, dataType, "")
+	return verifier.err()
+}
+
+// GenerateContractTests generates a contract test case for each query root field of the data sources which fetch via HTTP,
+// so that the configuration of the data sources can be verified against the real upstreams with ContractTestCase.Run before deploying the gateway.
+// The operation of a test case selects the scalar fields of the type of the root field which are resolved by the same data source,
+// required arguments are set to placeholder values, e.g. 0 or an empty string.
+// Mutation and subscription root fields aren't generated, as running them against the upstreams would have side effects.
+func (e *ExecutionEngineV2) GenerateContractTests(ctx context.Context) ([]ContractTestCase, error) {
+	definition := &e.config.schema.document
+	queryTypeName := definition.Index.QueryTypeName.String()
+	queryType, ok := definition.Index.FirstNodeByNameStr(queryTypeName)
+	if !ok {
+		return nil, nil
+	}
+
+	var (
+		testCases []ContractTestCase
+		generated = map[string]bool{}
+	)
+	for i := range e.config.plannerConfig.DataSources {
+		dataSource := &e.config.plannerConfig.DataSources[i]
+		for j := range dataSource.RootNodes {
+			if dataSource.RootNodes[j].TypeName != queryTypeName {
+				continue
+			}
+			for _, fieldName := range dataSource.RootNodes[j].FieldNames {
+				// only the first data source of a root field with the same feature flag is planned
+				key := dataSource.FeatureFlag + "." + fieldName
+				if strings.HasPrefix(fieldName, "__") || generated[key] {
+					continue
+				}
+				generated[key] = true
+
+				fieldDefinition, ok := definition.NodeFieldDefinitionByName(queryType, unsafebytes.StringToBytes(fieldName))
+				if !ok {
+					continue
+				}
+				testCase, ok, err := e.generateContractTest(ctx, dataSource, queryTypeName, fieldName, fieldDefinition)
+				if err != nil {
+					return nil, fmt.Errorf("contract test of %s.%s: %w", queryTypeName, fieldName, err)
+				}
+				if ok {
+					testCases = append(testCases, testCase)
+				}
+			}
+		}
+	}
+	return testCases, nil
+}
+
+func (e *ExecutionEngineV2) generateContractTest(ctx context.Context, dataSource *plan.DataSourceConfiguration, typeName, fieldName string, fieldDefinition int) (testCase ContractTestCase, ok bool, err error) {
+	operation := &Request{
+		Query: contractTestOperation(&e.config.schema.document, dataSource, fieldName, fieldDefinition),
+	}
+	query := operation.Query
+	if err = e.prepareOperation(ctx, operation, e.config.schema); err != nil {
+		return ContractTestCase{}, false, err
+	}
+
+	var featureFlags resolve.FeatureFlags
+	if dataSource.FeatureFlag != "" {
+		featureFlags = featureFlags.Enable(dataSource.FeatureFlag)
+	}
+
+	var report operationreport.Report
+	e.plannerMu.Lock()
+	e.planner.SetFeatureFlags(featureFlags)
+	planned := e.planner.Plan(&operation.document, &e.config.schema.document, "", &report)
+	e.plannerMu.Unlock()
+	if report.HasErrors() {
+		return ContractTestCase{}, false, report
+	}
+
+	syncPlan, isSyncPlan := postprocess.DefaultProcessor().Process(planned).(*plan.SynchronousResponsePlan)
+	if !isSyncPlan {
+		return ContractTestCase{}, false, nil
+	}
+	response, isObject := syncPlan.Response.Data.(*resolve.Object)
+	if !isObject {
+		return ContractTestCase{}, false, nil
+	}
+	fetch := firstSingleFetch(response.Fetch)
+	if fetch == nil {
+		return ContractTestCase{}, false, nil
+	}
+
+	resolveCtx := resolve.NewContext(ctx)
+	resolveCtx.Variables = operation.Variables
+	input := fastbuffer.New()
+	if err = fetch.InputTemplate.Render(resolveCtx, nil, input); err != nil {
+		return ContractTestCase{}, false, err
+	}
+	if _, err = jsonparser.GetString(input.Bytes(), httpclient.URL); err != nil {
+		// the data source doesn't fetch via HTTP
+		return ContractTestCase{}, false, nil
+	}
+
+	request, err := httpclient.NewRequest(ctx, input.Bytes())
+	if err != nil {
+		return ContractTestCase{}, false, err
+	}
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		return ContractTestCase{}, false, err
+	}
+
+	testCase = ContractTestCase{
+		Name:       typeName + "." + fieldName,
+		DataSource: string(fetch.DataSourceIdentifier),
+		TypeName:   typeName,
+		FieldName:  fieldName,
+		Operation:  query,
+		Request: ContractTestRequest{
+			Method: request.Method,
+			URL:    request.URL.String(),
+			Header: request.Header,
+			Body:   body,
+		},
+		graphqlResponse: fetch.ProcessResponseConfig.ExtractGraphqlResponse,
+		response:        response,
+	}
+	testCase.Response = minimalContractResponse(response)
+	if testCase.graphqlResponse {
+		testCase.Response, _ = jsonparser.Set([]byte(`{}`), testCase.Response, "data")
+	}
+	return testCase, true, nil
+}
+
+// contractTestOperation returns the operation of the root field with placeholder values for its required arguments,
+// selecting the scalar fields of its type which are resolved by the data source, or __typename if there are none
+func contractTestOperation(definition *ast.Document, dataSource *plan.DataSourceConfiguration, fieldName string, fieldDefinition int) string {
+	buf := &strings.Builder{}
+	buf.WriteString("query ContractTest { ")
+	buf.WriteString(fieldName)
+	writeRequiredArguments(buf, definition, definition.FieldDefinitionArgumentsDefinitions(fieldDefinition))
+
+	fieldType := definition.FieldDefinitionTypeNode(fieldDefinition)
+	switch fieldType.Kind {
+	case ast.NodeKindObjectTypeDefinition, ast.NodeKindInterfaceTypeDefinition, ast.NodeKindUnionTypeDefinition:
+		typeName := fieldType.NameString(definition)
+		buf.WriteString(" {")
+		selected := false
+		for _, ref := range definition.NodeFieldDefinitions(fieldType) {
+			childFieldName := definition.FieldDefinitionNameString(ref)
+			if !hasTypeField(dataSource.ChildNodes, typeName, childFieldName) && !hasTypeField(dataSource.RootNodes, typeName, childFieldName) {
+				continue
+			}
+			childType := definition.FieldDefinitionType(ref)
+			if !definition.TypeIsScalar(childType, definition) && !definition.TypeIsEnum(childType, definition) {
+				continue
+			}
+			if hasRequiredArguments(definition, definition.FieldDefinitionArgumentsDefinitions(ref)) {
+				continue
+			}
+			buf.WriteString(" ")
+			buf.WriteString(childFieldName)
+			selected = true
+		}
+		if !selected {
+			buf.WriteString(" __typename")
+		}
+		buf.WriteString(" }")
+	}
+	buf.WriteString(" }")
+	return buf.String()
+}
+
+func writeRequiredArguments(buf *strings.Builder, definition *ast.Document, inputValueDefinitions []int) {
+	if !hasRequiredArguments(definition, inputValueDefinitions) {
+		return
+	}
+	buf.WriteString("(")
+	writeRequiredInputValues(buf, definition, inputValueDefinitions)
+	buf.WriteString(")")
+}
+
+func writeRequiredInputValues(buf *strings.Builder, definition *ast.Document, inputValueDefinitions []int) {
+	written := 0
+	for _, ref := range inputValueDefinitions {
+		if !isRequiredInputValue(definition, ref) {
+			continue
+		}
+		if written > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(definition.InputValueDefinitionNameString(ref))
+		buf.WriteString(": ")
+		writePlaceholderValue(buf, definition, definition.InputValueDefinitionType(ref))
+		written++
+	}
+}
+
+// writePlaceholderValue writes the zero value of the type, lists are empty and input objects have their required fields
+func writePlaceholderValue(buf *strings.Builder, definition *ast.Document, typeRef int) {
+	switch definition.Types[typeRef].TypeKind {
+	case ast.TypeKindNonNull:
+		writePlaceholderValue(buf, definition, definition.Types[typeRef].OfType)
+		return
+	case ast.TypeKindList:
+		buf.WriteString("[]")
+		return
+	}
+
+	switch typeName := definition.TypeNameString(typeRef); typeName {
+	case "Int", "Float":
+		buf.WriteString("0")
+	case "Boolean":
+		buf.WriteString("false")
+	default:
+		node, _ := definition.Index.FirstNodeByNameStr(typeName)
+		switch node.Kind {
+		case ast.NodeKindEnumTypeDefinition:
+			if values := definition.EnumTypeDefinitions[node.Ref].EnumValuesDefinition.Refs; len(values) > 0 {
+				buf.WriteString(definition.EnumValueDefinitionNameString(values[0]))
+				return
+			}
+			buf.WriteString("null")
+		case ast.NodeKindInputObjectTypeDefinition:
+			buf.WriteString("{")
+			writeRequiredInputValues(buf, definition, definition.NodeInputFieldDefinitions(node))
+			buf.WriteString("}")
+		default:
+			buf.WriteString(`""`)
+		}
+	}
+}
+
+func hasRequiredArguments(definition *ast.Document, inputValueDefinitions []int) bool {
+	for _, ref := range inputValueDefinitions {
+		if isRequiredInputValue(definition, ref) {
+			return true
+		}
+	}
+	return false
+}
+
+func isRequiredInputValue(definition *ast.Document, inputValueDefinition int) bool {
+	return definition.TypeIsNonNull(definition.InputValueDefinitionType(inputValueDefinition)) &&
+		!definition.InputValueDefinitionHasDefaultValue(inputValueDefinition)
+}
+
+func hasTypeField(typeFields []plan.TypeField, typeName, fieldName string) bool {
+	for i := range typeFields {
+		if typeFields[i].TypeName != typeName {
+			continue
+		}
+		for j := range typeFields[i].FieldNames {
+			if typeFields[i].FieldNames[j] == fieldName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func firstSingleFetch(fetch resolve.Fetch) *resolve.SingleFetch {
+	switch f := fetch.(type) {
+	case *resolve.SingleFetch:
+		return f
+	case *resolve.ParallelFetch:
+		for i := range f.Fetches {
+			if singleFetch := firstSingleFetch(f.Fetches[i]); singleFetch != nil {
+				return singleFetch
+			}
+		}
+	case *resolve.SerialFetch:
+		for i := range f.Fetches {
+			if singleFetch := firstSingleFetch(f.Fetches[i]); singleFetch != nil {
+				return singleFetch
+			}
+		}
+	}
+	return nil
+}
+
+// minimalContractResponse renders the data of the object with all of its fields, so that the response shows the shape of the selections.
+// Lists have one item, nullable scalars are null and non-null scalars have their zero value.
+func minimalContractResponse(object *resolve.Object) []byte {
+	data := []byte(`{}`)
+	writeMinimalFields(&data, object)
+	return data
+}
+
+func writeMinimalFields(data *[]byte, object *resolve.Object) {
+	for _, field := range object.Fields {
+		if child, ok := field.Value.(*resolve.Object); ok && len(child.Path) == 0 {
+			// objects without path resolve their fields from the data of the enclosing object
+			writeMinimalFields(data, child)
+			continue
+		}
+		path := nodePath(field.Value)
+		if len(path) == 0 {
+			continue
+		}
+		*data, _ = jsonparser.Set(*data, minimalValue(field.Value), path...)
+	}
+}
+
+func minimalValue(node resolve.Node) []byte {
+	switch n := node.(type) {
+	case *resolve.Object:
+		return minimalContractResponse(n)
+	case *resolve.Array:
+		return append(append([]byte("["), minimalValue(n.Item)...), ']')
+	case *resolve.EmptyObject:
+		return []byte(`{}`)
+	case *resolve.EmptyArray:
+		return []byte(`[]`)
+	case *resolve.String:
+		if n.Nullable {
+			return []byte(`null`)
+		}
+		return []byte(`""`)
+	case *resolve.Boolean:
+		if n.Nullable {
+			return []byte(`null`)
+		}
+		return []byte(`false`)
+	case *resolve.Integer:
+		if n.Nullable {
+			return []byte(`null`)
+		}
+		return []byte(`0`)
+	case *resolve.Float:
+		if n.Nullable {
+			return []byte(`null`)
+		}
+		return []byte(`0`)
+	default:
+		return []byte(`null`)
+	}
+}
+
+// nodePath returns the path of the value of a node in the data of its enclosing object,
+// nodes which aren't resolved from the data, e.g. computed values, have no path
+func nodePath(node resolve.Node) []string {
+	switch n := node.(type) {
+	case *resolve.Object:
+		return n.Path
+	case *resolve.Array:
+		return n.Path
+	case *resolve.String:
+		return n.Path
+	case *resolve.Boolean:
+		return n.Path
+	case *resolve.Integer:
+		return n.Path
+	case *resolve.Float:
+		return n.Path
+	default:
+		return nil
+	}
+}
+
+// contractVerifier verifies the data of an upstream response against the nodes of a plan like they are resolved
+type contractVerifier struct {
+	violations []string
+}
+
+func (v *contractVerifier) violation(path, format string, args ...interface{}) {
+	if path != "" {
+		format = path + ": " + format
+	}
+	v.violations = append(v.violations, fmt.Sprintf(format, args...))
+}
+
+func (v *contractVerifier) err() error {
+	if len(v.violations) == 0 {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrContractViolation, strings.Join(v.violations, ", "))
+}
+
+func (v *contractVerifier) verifyFields(object *resolve.Object, data []byte, path string) {
+	for _, field := range object.Fields {
+		if child, ok := field.Value.(*resolve.Object); ok && len(child.Path) == 0 {
+			v.verifyFields(child, data, path)
+			continue
+		}
+		fieldPath := nodePath(field.Value)
+		if len(fieldPath) == 0 {
+			continue
+		}
+		value, dataType, _, _ := jsonparser.Get(data, fieldPath...)
+		v.verifyNode(field.Value, value, dataType, joinContractPath(path, strings.Join(fieldPath, ".")))
+	}
+}
+
+func (v *contractVerifier) verifyNode(node resolve.Node, value []byte, dataType jsonparser.ValueType, path string) {
+	switch n := node.(type) {
+	case *resolve.Object:
+		if v.verifyType(path, dataType, jsonparser.Object, n.Nullable, "object") {
+			v.verifyFields(n, value, path)
+		}
+	case *resolve.Array:
+		if !v.verifyType(path, dataType, jsonparser.Array, n.Nullable, "list") {
+			return
+		}
+		index := 0
+		_, _ = jsonparser.ArrayEach(value, func(item []byte, itemType jsonparser.ValueType, _ int, _ error) {
+			v.verifyNode(n.Item, item, itemType, fmt.Sprintf("%s.%d", path, index))
+			index++
+		})
+	case *resolve.String:
+		if n.UnescapeResponseJson && dataType != jsonparser.Null && dataType != jsonparser.NotExist {
+			return
+		}
+		v.verifyType(path, dataType, jsonparser.String, n.Nullable, "string")
+	case *resolve.Boolean:
+		v.verifyType(path, dataType, jsonparser.Boolean, n.Nullable, "boolean")
+	case *resolve.Integer:
+		v.verifyType(path, dataType, jsonparser.Number, n.Nullable, "number")
+	case *resolve.Float:
+		v.verifyType(path, dataType, jsonparser.Number, n.Nullable, "number")
+	}
+}
+
+// verifyType returns true if the value has the expected type, null values are violations of non-null nodes
+func (v *contractVerifier) verifyType(path string, dataType, expected jsonparser.ValueType, nullable bool, name string) bool {
+	switch dataType {
+	case expected:
+		return true
+	case jsonparser.Null, jsonparser.NotExist:
+		if !nullable {
+			v.violation(path, "non-null %s is null", name)
+		}
+	default:
+		v.violation(path, "expected %s, got %s", name, dataType)
+	}
+	return false
+}
+
+func joinContractPath(path, fieldPath string) string {
+	if path == "" {
+		return fieldPath
+	}
+	return path + "." + fieldPath
+}
@@ -0,0 +1,176 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestExecutionEngineV2_GenerateContractTests(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			user(id: ID!, verbose: Boolean): User
+			users(filter: UserFilter!): [User!]!
+			hello: String!
+			node: Node
+		}
+
+		type Mutation {
+			deleteUser(id: ID!): User
+		}
+
+		input UserFilter {
+			status: Status!
+			limit: Int! = 10
+			name: String
+		}
+
+		enum Status { ACTIVE INACTIVE }
+
+		interface Node { id: ID! }
+
+		type User implements Node {
+			id: ID!
+			name: String
+			age: Int
+			friends: [User]
+			reviews: [Review]
+		}
+
+		type Review { body: String }`)
+	require.NoError(t, err)
+
+	var upstreamResponse string
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(upstreamResponse))
+	}))
+	defer upstream.Close()
+
+	dataSource := func(url string, rootNodes, childNodes []plan.TypeField) plan.DataSourceConfiguration {
+		return plan.DataSourceConfiguration{
+			RootNodes:  rootNodes,
+			ChildNodes: childNodes,
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: upstream.Client(),
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    url,
+					Method: http.MethodPost,
+					Header: http.Header{"X-Api-Key": []string{"secret"}},
+				},
+			}),
+		}
+	}
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		dataSource(upstream.URL+"/users",
+			[]plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"user", "users", "node"}},
+				{TypeName: "Mutation", FieldNames: []string{"deleteUser"}},
+			},
+			[]plan.TypeField{
+				{TypeName: "User", FieldNames: []string{"id", "name", "friends"}},
+				{TypeName: "Node", FieldNames: []string{"id"}},
+			},
+		),
+		dataSource(upstream.URL+"/hello",
+			[]plan.TypeField{{TypeName: "Query", FieldNames: []string{"hello", "user"}}},
+			nil,
+		),
+		dataSource(upstream.URL+"/reviews",
+			nil,
+			[]plan.TypeField{
+				{TypeName: "User", FieldNames: []string{"age", "reviews"}},
+				{TypeName: "Review", FieldNames: []string{"body"}},
+			},
+		),
+	})
+	engineConf.SetFieldConfigurations([]plan.FieldConfiguration{
+		{
+			TypeName:  "Query",
+			FieldName: "user",
+			Arguments: []plan.ArgumentConfiguration{
+				{Name: "id", SourceType: plan.FieldArgumentSource},
+				{Name: "verbose", SourceType: plan.FieldArgumentSource},
+			},
+		},
+		{
+			TypeName:  "Query",
+			FieldName: "users",
+			Arguments: []plan.ArgumentConfiguration{
+				{Name: "filter", SourceType: plan.FieldArgumentSource},
+			},
+		},
+	})
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	testCases, err := engine.GenerateContractTests(context.Background())
+	require.NoError(t, err)
+	require.Len(t, testCases, 4)
+
+	t.Run("generates the request and a minimal response of each query root field", func(t *testing.T) {
+		user := testCases[0]
+		assert.Equal(t, "Query.user", user.Name)
+		assert.Equal(t, "graphql_datasource.Source", user.DataSource)
+		assert.Equal(t, "Query", user.TypeName)
+		assert.Equal(t, "user", user.FieldName)
+		assert.Equal(t, `query ContractTest { user(id: "") { id name } }`, user.Operation)
+		assert.Equal(t, http.MethodPost, user.Request.Method)
+		assert.Equal(t, upstream.URL+"/users", user.Request.URL)
+		assert.Equal(t, "secret", user.Request.Header.Get("X-Api-Key"))
+		assert.Equal(t, `{"query":"query($a: ID!){user(id: $a){id name}}","variables":{"a":""}}`, string(user.Request.Body))
+		assert.Equal(t, `{"data":{"user":{"id":"","name":null}}}`, string(user.Response))
+
+		users := testCases[1]
+		assert.Equal(t, `query ContractTest { users(filter: {status: ACTIVE}) { id name } }`, users.Operation)
+		assert.Equal(t, `{"data":{"users":[{"id":"","name":null}]}}`, string(users.Response))
+
+		node := testCases[2]
+		assert.Equal(t, `query ContractTest { node { id } }`, node.Operation)
+		assert.Equal(t, `{"data":{"node":{"id":""}}}`, string(node.Response))
+
+		hello := testCases[3]
+		assert.Equal(t, "Query.hello", hello.Name)
+		assert.Equal(t, upstream.URL+"/hello", hello.Request.URL)
+		assert.Equal(t, `{"data":{"hello":""}}`, string(hello.Response))
+	})
+
+	t.Run("runs the test cases against the upstream", func(t *testing.T) {
+		user := testCases[0]
+
+		upstreamResponse = `{"data":{"user":{"id":"1","name":"Jens"}}}`
+		assert.NoError(t, user.Run(context.Background(), upstream.Client()))
+
+		upstreamResponse = `{"data":{"user":null}}`
+		assert.NoError(t, user.Run(context.Background(), upstream.Client()))
+
+		upstreamResponse = `{"data":{"user":{"id":null,"name":1}}}`
+		err := user.Run(context.Background(), upstream.Client())
+		assert.ErrorIs(t, err, ErrContractViolation)
+		assert.EqualError(t, err, "upstream response violates the contract: user.id: non-null string is null, user.name: expected string, got number")
+
+		upstreamResponse = `{"errors":[{"message":"unknown field"}]}`
+		err = user.Run(context.Background(), upstream.Client())
+		assert.EqualError(t, err, `upstream response violates the contract: upstream responded with errors: [{"message":"unknown field"}]`)
+
+		users := testCases[1]
+		upstreamResponse = `{"data":{"users":[{"id":"1","name":"Jens"},{"name":"Yaml"}]}}`
+		err = users.Run(context.Background(), upstream.Client())
+		assert.EqualError(t, err, "upstream response violates the contract: users.1.id: non-null string is null")
+
+		upstreamResponse = `{"data":{}}`
+		err = users.Run(context.Background(), upstream.Client())
+		assert.EqualError(t, err, "upstream response violates the contract: users: non-null list is null")
+	})
+}
@@ -5,9 +5,13 @@ import (
 	"net/http"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astnormalization"
 	graphqlDataSource "github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+	"github.com/wundergraph/graphql-go-tools/pkg/federation"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
 )
 
 const (
@@ -18,7 +22,39 @@ type EngineV2Configuration struct {
 	schema                   *Schema
 	plannerConfig            plan.Configuration
 	websocketBeforeStartHook WebsocketBeforeStartHook
+	abandonedOperationHook   AbandonedOperationHook
 	dataLoaderConfig         dataLoaderConfig
+	introspectionFastPath    bool
+	repeatedFetchDetection   bool
+	freshnessExtension       bool
+	schemaHashExtension      bool
+	responseShapeLearning    bool
+	responseCache            *ResponseCache
+	mutationInvalidationHook MutationInvalidationHook
+	cacheInvalidators        []CacheInvalidator
+	fragmentRegistry         *astnormalization.FragmentRegistry
+	schemaViews              []SchemaViewConfig
+	schemaViewSelector       SchemaViewSelector
+	healthCheckClient        *http.Client
+	healthCheckHook          HealthCheckHook
+	operationTransforms      []OperationTransform
+	fragmentArguments        bool
+	clientNullability        bool
+	directiveMiddlewares     map[string]resolve.DirectiveMiddleware
+	planCacheStore           PlanCacheStore
+	planCacheStoreVersion    string
+	planCacheAdmission       *PlanCacheAdmissionConfig
+	admissionControl         *AdmissionControlConfig
+	operationConcurrency     *OperationConcurrencyConfig
+	memoryLimit              int64
+	maxResponseSize          int64
+	responseSizeLimitHook    ResponseSizeLimitHook
+	subgraph                 *SubgraphConfig
+	featureFlagRollouts      []FeatureFlagRollout
+	variableInjection        *VariableInjectionConfig
+	timeoutBudget            *httpclient.TimeoutBudget
+	partialPlanCache         *PartialPlanCacheConfig
+	introspectionCache       *IntrospectionCache
 }
 
 func NewEngineV2Configuration(schema *Schema) EngineV2Configuration {
@@ -73,15 +109,232 @@ func (e *EngineV2Configuration) EnableSingleFlight(enable bool) {
 	e.dataLoaderConfig.EnableSingleFlightLoader = enable
 }
 
+// EnableIntrospectionFastPath answers operations selecting nothing but __schema and __type
+// from the introspection JSON of the schema, which is generated once, instead of planning and resolving them.
+// Contrary to the resolved introspection, kind specific fields which don't apply to a type are null as defined by the spec,
+// e.g. the inputFields of an object type. It's disabled by default.
+func (e *EngineV2Configuration) EnableIntrospectionFastPath(enable bool) {
+	e.introspectionFastPath = enable
+}
+
+// SetIntrospectionCache caches the responses of the operations answered by the introspection fast path
+// and of the introspection operations of schema views, see IntrospectionCache.
+// The cache can be shared with the engines of following schema versions, which invalidate the responses of the previous schemas.
+func (e *EngineV2Configuration) SetIntrospectionCache(cache *IntrospectionCache) {
+	e.introspectionCache = cache
+}
+
+// EnableSkipIncludeFolding removes selections skipped by @skip/@include directives with variables at planning time,
+// so that they are not fetched from upstreams. Plans are cached per value of these variables.
+func (e *EngineV2Configuration) EnableSkipIncludeFolding(enable bool) {
+	e.plannerConfig.FoldSkipIncludeVariables = enable
+}
+
+// EnableRepeatedFetchDetection is a debug mode which detects fetches repeated with different inputs within one operation,
+// e.g. a fetch per item of a list (N+1 fetches). The path, data source and count of these fetches are logged as warning
+// and added to the extensions of the response, so that batching can be enabled for the affected data sources.
+func (e *EngineV2Configuration) EnableRepeatedFetchDetection(enable bool) {
+	e.repeatedFetchDetection = enable
+}
+
+// EnableFreshnessExtension adds the freshness of the data of each fetch to the extensions of the response: the path, data source,
+// whether it was served by a cache or the origin and the age of cached data. Responses of HTTP upstreams are cached if they have
+// an Age or cache status header, other data sources can report cached responses with resolve.ReportCachedResponse.
+func (e *EngineV2Configuration) EnableFreshnessExtension(enable bool) {
+	e.freshnessExtension = enable
+}
+
+// EnableResponseShapeLearning is a development mode which records the shapes of the responses of GraphQL upstreams per field
+// and diffs them against the types of the schema, e.g. to debug field mappings. Unexpected nulls, extra and missing fields,
+// invalid scalar values and values of the wrong shape are reported by ExecutionEngineV2.ResponseShapeReport.
+// Each upstream query is parsed again, so it shouldn't be enabled in production.
+func (e *EngineV2Configuration) EnableResponseShapeLearning(enable bool) {
+	e.responseShapeLearning = enable
+}
+
+// EnableSchemaHashExtension adds the hash of the schema the operation was executed against to the extensions of the response,
+// e.g. {"extensions":{"schemaHash":"..."}}, so that clients can detect schema changes. See Schema.HashString.
+func (e *EngineV2Configuration) EnableSchemaHashExtension(enable bool) {
+	e.schemaHashExtension = enable
+}
+
+// SetResponseCache caches the responses of queries in the response cache, see ResponseCache.
+// Cached responses are written without planning and resolving the operation again.
+func (e *EngineV2Configuration) SetResponseCache(cache *ResponseCache) {
+	e.responseCache = cache
+}
+
+// SetMutationInvalidationHook sets the hook which returns the cache invalidation events of executed mutations.
+// The events are passed to the response cache and all cache invalidators.
+func (e *EngineV2Configuration) SetMutationInvalidationHook(hook MutationInvalidationHook) {
+	e.mutationInvalidationHook = hook
+}
+
+// AddCacheInvalidator adds a consumer of the cache invalidation events of mutations, e.g. a cache shared with other services
+func (e *EngineV2Configuration) AddCacheInvalidator(invalidator CacheInvalidator) {
+	e.cacheInvalidators = append(e.cacheInvalidators, invalidator)
+}
+
+// SetFragmentRegistry allows operations to spread the fragments of the registry without defining them.
+// The registered fragments are injected into operations on normalization, before they are validated.
+func (e *EngineV2Configuration) SetFragmentRegistry(registry *astnormalization.FragmentRegistry) {
+	e.fragmentRegistry = registry
+}
+
+// AddSchemaView adds a view of the schema, which is generated once when the engine is created.
+// Requests for which the SchemaViewSelector returns the name of the view are normalized, validated
+// and introspected against the view instead of the complete schema.
+func (e *EngineV2Configuration) AddSchemaView(config SchemaViewConfig) {
+	e.schemaViews = append(e.schemaViews, config)
+}
+
+// SetSchemaViewSelector sets the selector which selects the schema view per request, see AddSchemaView
+func (e *EngineV2Configuration) SetSchemaViewSelector(selector SchemaViewSelector) {
+	e.schemaViewSelector = selector
+}
+
+// SetHealthCheckClient sets the client for the health checks of the data sources, see plan.HealthCheckConfiguration.
+// It defaults to http.DefaultClient.
+func (e *EngineV2Configuration) SetHealthCheckClient(client *http.Client) {
+	e.healthCheckClient = client
+}
+
+// SetHealthCheckHook sets the hook which is called once the health of a data source changes
+func (e *EngineV2Configuration) SetHealthCheckHook(hook HealthCheckHook) {
+	e.healthCheckHook = hook
+}
+
+// AddOperationTransform adds a transform of the parsed operations, see OperationTransform.
+// Transforms are applied in the order they were added, before the operations are normalized.
+func (e *EngineV2Configuration) AddOperationTransform(transform OperationTransform) {
+	e.operationTransforms = append(e.operationTransforms, transform)
+}
+
+// EnableFragmentArguments enables the experimental support of fragment arguments (parameterized fragments),
+// e.g. fragment picture($size: Int = 50) on User { profilePic(size: $size) } spread as ...picture(size: 100).
+// The arguments are substituted when the fragments are inlined on normalization. Requests parsed before execution aren't affected.
+func (e *EngineV2Configuration) EnableFragmentArguments(enable bool) {
+	e.fragmentArguments = enable
+}
+
+// SetPlanCacheStore persists the plan cache in the store, e.g. a FilePlanCacheStore, see PlanCacheStore.
+// The plans of the stored operations are compiled when the engine is created.
+// version identifies the configuration of the data sources, entries stored with another version or schema are ignored.
+// Plans with folded skip/include directives aren't persisted, see EnableSkipIncludeFolding.
+func (e *EngineV2Configuration) SetPlanCacheStore(store PlanCacheStore, version string) {
+	e.planCacheStore = store
+	e.planCacheStoreVersion = version
+}
+
+// SetPlanCacheAdmission only admits plans above the planning cost into the plan cache, see PlanCacheAdmissionConfig.
+// The usage of the cached plans is reported by ExecutionEngineV2.PlanCacheEntries.
+func (e *EngineV2Configuration) SetPlanCacheAdmission(config PlanCacheAdmissionConfig) {
+	e.planCacheAdmission = &config
+}
+
+// SetVariableInjection injects server-side variables into all requests, see VariableInjectionConfig
+func (e *EngineV2Configuration) SetVariableInjection(config VariableInjectionConfig) {
+	e.variableInjection = &config
+}
+
+// SetPartialPlanCache caches the plans of the root fields of queries, grouped by the data source resolving them,
+// so that queries sharing root fields with identical selection sets, e.g. from shared fragments, only plan the root fields
+// which weren't planned before. It reduces the planning latency of the plan cache misses of large operations.
+// Root fields of the same data source are only reused together, as they are fetched with a single request.
+func (e *EngineV2Configuration) SetPartialPlanCache(config PartialPlanCacheConfig) {
+	e.partialPlanCache = &config
+}
+
+// SetAdmissionControl bounds the number of operations prepared concurrently, see AdmissionControlConfig.
+// Rejected operations fail with an AdmissionRejectedError.
+func (e *EngineV2Configuration) SetAdmissionControl(config AdmissionControlConfig) {
+	e.admissionControl = &config
+}
+
+// SetOperationConcurrency serializes mutations with the same concurrency key, see OperationConcurrencyConfig.
+// Operations waiting longer than the MaxWait fail with an OperationConcurrencyTimeoutError.
+func (e *EngineV2Configuration) SetOperationConcurrency(config OperationConcurrencyConfig) {
+	e.operationConcurrency = &config
+}
+
+// SetMemoryLimit limits the approximate number of bytes of the upstream responses and the resolved response of each request,
+// 0 disables the limit. Requests exceeding the limit are aborted with resolve.ErrMemoryLimitExceeded.
+// The limit of a single request can be overridden with WithMemoryLimit.
+func (e *EngineV2Configuration) SetMemoryLimit(bytes int64) {
+	e.memoryLimit = bytes
+}
+
+// SetMaxResponseSize limits the number of bytes of the serialized data of each response, 0 disables the limit.
+// Rendering of responses exceeding the limit stops and Execute returns a *resolve.ResponseSizeLimitError with the path of the truncation,
+// the exceeded limits are counted, see ExecutionEngineV2.TruncatedResponses and SetResponseSizeLimitHook.
+// Compressed upstream responses fail once their decompressed size exceeds the limit.
+// The limit of a single request can be overridden with WithMaxResponseSize.
+func (e *EngineV2Configuration) SetMaxResponseSize(bytes int64) {
+	e.maxResponseSize = bytes
+}
+
+// SetTimeoutBudget propagates the remaining time until the deadline of the context of each operation to the HTTP upstreams of its fetches,
+// as deadline of the fetches and optionally as header, see httpclient.TimeoutBudget. Fetches are not sent once the budget is exhausted.
+func (e *EngineV2Configuration) SetTimeoutBudget(budget httpclient.TimeoutBudget) {
+	e.timeoutBudget = &budget
+}
+
+// SetResponseSizeLimitHook - sets the hook which will be called for responses exceeding the max response size, e.g. to record a metric
+func (e *EngineV2Configuration) SetResponseSizeLimitHook(hook ResponseSizeLimitHook) {
+	e.responseSizeLimitHook = hook
+}
+
+// SetFeatureFlagRollouts enables each feature flag for a random percentage of the requests.
+// The enabled feature flags select the DataSources with a plan.DataSourceConfiguration.FeatureFlag when operations are planned,
+// and are rendered into fetch inputs with {{ .request.flags.name }}. Flags of single requests are enabled with WithFeatureFlags.
+func (e *EngineV2Configuration) SetFeatureFlagRollouts(rollouts ...FeatureFlagRollout) {
+	e.featureFlagRollouts = rollouts
+}
+
+// EnableSubgraph lets the engine serve as a subgraph of an Apollo Federation compatible gateway.
+// The query type is extended with _service { sdl } returning the SDL of the schema with the @key directives of the entities
+// and _entities(representations: [_Any!]!) resolving the entities from their representations with the configured data sources.
+// Gateways can be layered this way, e.g. an engine federating REST APIs can be a subgraph of another gateway.
+func (e *EngineV2Configuration) EnableSubgraph(config SubgraphConfig) {
+	e.subgraph = &config
+}
+
+// AddDirectiveMiddleware adds the middleware of an executable directive on fields, e.g. @format(pattern: $pattern).
+// The directive is not forwarded to upstreams but applied to the resolved values of the fields,
+// with the arguments rendered from the variables of each request, see resolve.DirectiveMiddleware.
+func (e *EngineV2Configuration) AddDirectiveMiddleware(directiveName string, middleware resolve.DirectiveMiddleware) {
+	if e.directiveMiddlewares == nil {
+		e.directiveMiddlewares = map[string]resolve.DirectiveMiddleware{}
+	}
+	if _, exists := e.directiveMiddlewares[directiveName]; !exists {
+		e.plannerConfig.ResolvedDirectives = append(e.plannerConfig.ResolvedDirectives, directiveName)
+	}
+	e.directiveMiddlewares[directiveName] = middleware
+}
+
+// EnableClientControlledNullability enables the experimental support of client controlled nullability,
+// e.g. { user(id: 1)! { name? } }. A field marked with ! is non-null, so a null value is an error propagated to the parent,
+// while a field marked with ? is nullable, so errors of the field and its children result in null for the field.
+// Requests parsed before execution aren't affected.
+func (e *EngineV2Configuration) EnableClientControlledNullability(enable bool) {
+	e.clientNullability = enable
+}
+
 // SetWebsocketBeforeStartHook - sets before start hook which will be called before processing any operation sent over websockets
 func (e *EngineV2Configuration) SetWebsocketBeforeStartHook(hook WebsocketBeforeStartHook) {
 	e.websocketBeforeStartHook = hook
 }
 
+// SetAbandonedOperationHook - sets the hook which will be called for operations cancelled before they were resolved, e.g. on client disconnect
+func (e *EngineV2Configuration) SetAbandonedOperationHook(hook AbandonedOperationHook) {
+	e.abandonedOperationHook = hook
+}
+
 type dataSourceV2GeneratorOptions struct {
 	streamingClient           *http.Client
 	subscriptionType          SubscriptionType
 	subscriptionClientFactory graphqlDataSource.GraphQLSubscriptionClientFactory
+	schemaBuilder             *federation.BaseSchemaBuilder
 }
 
 type DataSourceV2GeneratorOption func(options *dataSourceV2GeneratorOptions)
@@ -99,6 +352,14 @@ func WithDataSourceV2GeneratorSubscriptionClientFactory(factory graphqlDataSourc
 	}
 }
 
+// WithDataSourceV2GeneratorSchemaBuilder shares the builder of the federation schemas of the upstreams between data sources,
+// see graphql_datasource.Factory.SchemaBuilder.
+func WithDataSourceV2GeneratorSchemaBuilder(schemaBuilder *federation.BaseSchemaBuilder) DataSourceV2GeneratorOption {
+	return func(options *dataSourceV2GeneratorOptions) {
+		options.schemaBuilder = schemaBuilder
+	}
+}
+
 type graphqlDataSourceV2Generator struct {
 	document *ast.Document
 }
@@ -112,7 +373,11 @@ func newGraphQLDataSourceV2Generator(document *ast.Document) *graphqlDataSourceV
 func (d *graphqlDataSourceV2Generator) Generate(config graphqlDataSource.Configuration, batchFactory resolve.DataSourceBatchFactory, httpClient *http.Client, options ...DataSourceV2GeneratorOption) (plan.DataSourceConfiguration, error) {
 	var planDataSource plan.DataSourceConfiguration
 	extractor := plan.NewLocalTypeFieldExtractor(d.document)
-	planDataSource.RootNodes, planDataSource.ChildNodes = extractor.GetAllNodes()
+	var report operationreport.Report
+	planDataSource.RootNodes, planDataSource.ChildNodes = extractor.GetAllNodesWithReport(&report)
+	if report.HasErrors() {
+		return plan.DataSourceConfiguration{}, report
+	}
 
 	definedOptions := &dataSourceV2GeneratorOptions{
 		streamingClient:           &http.Client{Timeout: 0},
@@ -128,6 +393,7 @@ func (d *graphqlDataSourceV2Generator) Generate(config graphqlDataSource.Configu
 		HTTPClient:      httpClient,
 		StreamingClient: definedOptions.streamingClient,
 		BatchFactory:    batchFactory,
+		SchemaBuilder:   definedOptions.schemaBuilder,
 	}
 
 	subscriptionClient, err := d.generateSubscriptionClient(httpClient, definedOptions)
@@ -166,6 +166,18 @@ func TestGraphQLDataSourceV2Generator_Generate(t *testing.T) {
 		assert.Equal(t, expectedDataSourceFactory, dataSource.Factory)
 	})
 
+	t.Run("with invalid schema", func(t *testing.T) {
+		invalidDoc, report := astparser.ParseGraphqlDocumentString(`schema { query: RootQuery } type Query { me: String }`)
+		require.False(t, report.HasErrors())
+
+		_, err := newGraphQLDataSourceV2Generator(&invalidDoc).Generate(
+			graphqlDataSource.Configuration{},
+			batchFactory,
+			client,
+			WithDataSourceV2GeneratorSubscriptionClientFactory(&MockSubscriptionClientFactory{}),
+		)
+		assert.EqualError(t, err, "external: the query root operation type named 'RootQuery' is undefined, locations: [], path: []")
+	})
 }
 
 func TestGraphqlFieldConfigurationsV2Generator_Generate(t *testing.T) {
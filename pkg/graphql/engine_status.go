@@ -0,0 +1,32 @@
+package graphql
+
+import (
+	"time"
+)
+
+// EngineStatus is the operational status of the engine, see ExecutionEngineV2.Status
+type EngineStatus struct {
+	// SchemaHash is the hash of the schema the engine executes operations against, see Schema.HashString
+	SchemaHash string
+	// LoadedAt is the time the engine was created with the schema.
+	// Engines are recreated to reload the schema, so it's the time of the last reload.
+	LoadedAt time.Time
+	// ReadinessErr is the error returned by ExecutionEngineV2.Readiness, nil if the engine is ready
+	ReadinessErr  error
+	PlanCache     PlanCacheStats
+	DataSources   []DataSourceHealthStatus
+	Subscriptions SubscriptionStats
+}
+
+// Status returns the operational status of the engine: the active schema, the readiness, the plan cache,
+// the health of the DataSources and the subscriptions, e.g. to expose it to operators with a status endpoint.
+func (e *ExecutionEngineV2) Status() EngineStatus {
+	return EngineStatus{
+		SchemaHash:    e.config.schema.HashString(),
+		LoadedAt:      e.loadedAt,
+		ReadinessErr:  e.Readiness(),
+		PlanCache:     e.PlanCacheStats(),
+		DataSources:   e.HealthStatus(),
+		Subscriptions: e.SubscriptionStats(),
+	}
+}
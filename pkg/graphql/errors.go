@@ -39,6 +39,7 @@ func RequestErrorsFromError(err error) RequestErrors {
 				Path: ErrorPath{
 					astPath: externalError.Path,
 				},
+				Extensions: requestErrorExtensionsFromExternalError(externalError),
 			})
 		}
 		return errors
@@ -67,9 +68,10 @@ func RequestErrorsFromOperationReport(report operationreport.Report) (errors Req
 		}
 
 		validationError := RequestError{
-			Message:   externalError.Message,
-			Path:      ErrorPath{astPath: externalError.Path},
-			Locations: locations,
+			Message:    externalError.Message,
+			Path:       ErrorPath{astPath: externalError.Path},
+			Locations:  locations,
+			Extensions: requestErrorExtensionsFromExternalError(externalError),
 		}
 
 		errors = append(errors, validationError)
@@ -111,33 +113,49 @@ func (o RequestErrors) ErrorByIndex(i int) error {
 }
 
 type RequestError struct {
-	Message   string                   `json:"message"`
-	Locations []graphqlerrors.Location `json:"locations,omitempty"`
-	Path      ErrorPath                `json:"path"`
+	Message    string                   `json:"message"`
+	Locations  []graphqlerrors.Location `json:"locations,omitempty"`
+	Path       ErrorPath                `json:"path"`
+	Extensions *RequestErrorExtensions  `json:"extensions,omitempty"`
 }
 
-func (o RequestError) MarshalJSON() ([]byte, error) {
-	if o.Path.Len() == 0 {
-		return json.Marshal(struct {
-			Message   string                   `json:"message"`
-			Locations []graphqlerrors.Location `json:"locations,omitempty"`
-		}{
-			Message:   o.Message,
-			Locations: o.Locations,
-		})
+// RequestErrorExtensions are the extensions of a RequestError
+type RequestErrorExtensions struct {
+	// Suggestions are the names of the schema similar to a misspelled name of the operation,
+	// e.g. the fields of the type of an unknown field
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+func requestErrorExtensionsFromExternalError(externalError operationreport.ExternalError) *RequestErrorExtensions {
+	if len(externalError.Suggestions) == 0 {
+		return nil
 	}
-	path, err := o.Path.MarshalJSON()
-	if err != nil {
-		return nil, err
+	return &RequestErrorExtensions{
+		Suggestions: externalError.Suggestions,
+	}
+}
+
+func (o RequestError) MarshalJSON() ([]byte, error) {
+	var (
+		path json.RawMessage
+		err  error
+	)
+	if o.Path.Len() != 0 {
+		path, err = o.Path.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
 	}
 	return json.Marshal(struct {
-		Message   string                   `json:"message"`
-		Locations []graphqlerrors.Location `json:"locations,omitempty"`
-		Path      json.RawMessage          `json:"path"`
+		Message    string                   `json:"message"`
+		Locations  []graphqlerrors.Location `json:"locations,omitempty"`
+		Path       json.RawMessage          `json:"path,omitempty"`
+		Extensions *RequestErrorExtensions  `json:"extensions,omitempty"`
 	}{
-		Message:   o.Message,
-		Locations: o.Locations,
-		Path:      path,
+		Message:    o.Message,
+		Locations:  o.Locations,
+		Path:       path,
+		Extensions: o.Extensions,
 	})
 }
 
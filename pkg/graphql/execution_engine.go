@@ -9,10 +9,9 @@ import (
 	"net/http"
 	"sync"
 
-	"github.com/jensneuse/abstractlogger"
-
 	"github.com/wundergraph/graphql-go-tools/pkg/execution"
 	"github.com/wundergraph/graphql-go-tools/pkg/execution/datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
 	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
 )
 
@@ -33,13 +32,13 @@ type ExecutionOptions struct {
 }
 
 type ExecutionEngine struct {
-	logger       abstractlogger.Logger
+	logger       logging.Logger
 	basePlanner  *datasource.BasePlanner
 	executorPool *sync.Pool
 	schema       *Schema
 }
 
-func NewExecutionEngine(logger abstractlogger.Logger, schema *Schema, plannerConfig datasource.PlannerConfiguration) (*ExecutionEngine, error) {
+func NewExecutionEngine(logger logging.Logger, schema *Schema, plannerConfig datasource.PlannerConfiguration) (*ExecutionEngine, error) {
 	executorPool := sync.Pool{
 		New: func() interface{} {
 			return execution.NewExecutor(nil)
@@ -10,11 +10,11 @@ import (
 	"sync"
 	"testing"
 
-	"github.com/jensneuse/abstractlogger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/execution/datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
 	"github.com/wundergraph/graphql-go-tools/pkg/starwars"
 )
 
@@ -72,7 +72,7 @@ func TestExecutionEngine_ExecuteWithOptions(t *testing.T) {
 			extraVariablesBytes, err := json.Marshal(extraVariables)
 			require.NoError(t, err)
 
-			engine, err := NewExecutionEngine(abstractlogger.NoopLogger, tc.schema, tc.plannerConfig)
+			engine, err := NewExecutionEngine(logging.NoopLogger, tc.schema, tc.plannerConfig)
 			assert.NoError(t, err)
 
 			switch tc.plannerConfig.TypeFieldConfigurations[0].DataSource.Name {
@@ -434,7 +434,7 @@ func TestExampleExecutionEngine_Concatenation(t *testing.T) {
 		},
 	}
 
-	engine, err := NewExecutionEngine(abstractlogger.NoopLogger, schema, plannerConfig)
+	engine, err := NewExecutionEngine(logging.NoopLogger, schema, plannerConfig)
 	assert.NoError(t, err)
 	err = engine.AddHttpJsonDataSource("HttpJsonDataSource")
 	assert.NoError(t, err)
@@ -475,7 +475,7 @@ func BenchmarkExecutionEngine(b *testing.B) {
 				},
 			},
 		}
-		engine, err := NewExecutionEngine(abstractlogger.NoopLogger, schema, plannerConfig)
+		engine, err := NewExecutionEngine(logging.NoopLogger, schema, plannerConfig)
 		assert.NoError(b, err)
 		assert.NoError(b, engine.AddDataSource("HelloDataSource", datasource.StaticDataSourcePlannerFactoryFactory{}))
 		return engine
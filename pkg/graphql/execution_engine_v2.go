@@ -6,13 +6,16 @@ import (
 	"compress/gzip"
 	"context"
 	"errors"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/buger/jsonparser"
 	lru "github.com/hashicorp/golang-lru"
-	"github.com/jensneuse/abstractlogger"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/introspection_datasource"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
@@ -20,11 +23,17 @@ import (
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
 	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
 	"github.com/wundergraph/graphql-go-tools/pkg/pool"
 	"github.com/wundergraph/graphql-go-tools/pkg/postprocess"
+	"github.com/wundergraph/graphql-go-tools/pkg/tracing"
 )
 
+// schemaHashExtensionKey is the key of the response extension carrying the schema hash, see EngineV2Configuration.EnableSchemaHashExtension
+const schemaHashExtensionKey = "schemaHash"
+
 type EngineResultWriter struct {
 	buf           *bytes.Buffer
 	flushCallback func(data []byte)
@@ -107,6 +116,8 @@ func (e *EngineResultWriter) AsHTTPResponse(status int, headers http.Header) *ht
 type internalExecutionContext struct {
 	resolveContext *resolve.Context
 	postProcessor  *postprocess.Processor
+	// client identifies the client of the operation, see WithClient
+	client string
 }
 
 func newInternalExecutionContext() *internalExecutionContext {
@@ -136,22 +147,60 @@ func (e *internalExecutionContext) setVariables(variables []byte) {
 
 func (e *internalExecutionContext) reset() {
 	e.resolveContext.Free()
+	e.client = ""
 }
 
 type ExecutionEngineV2 struct {
-	logger                       abstractlogger.Logger
+	logger                       logging.Logger
 	config                       EngineV2Configuration
 	planner                      *plan.Planner
 	plannerMu                    sync.Mutex
 	resolver                     *resolve.Resolver
 	internalExecutionContextPool sync.Pool
 	executionPlanCache           *lru.Cache
+	planCacheTracker             *planCacheTracker
+	introspectionResolver        *introspectionResolver
+	schemaViews                  map[string]*schemaView
+	healthChecker                *healthChecker
+	abandonedOperations          uint64
+	truncatedResponses           uint64
+	// persistedPlans are the cache keys of the plans stored in the PlanCacheStore
+	persistedPlans   map[uint64]struct{}
+	persistedPlansMu sync.RWMutex
+	admission        *admissionController
+	// operationConcurrency serializes conflicting mutations, see EngineV2Configuration.SetOperationConcurrency
+	operationConcurrency *operationConcurrency
+	subscriptions        subscriptionRegistry
+	// partialPlans caches the plans of the root fields of queries, see EngineV2Configuration.SetPartialPlanCache
+	partialPlans *partialPlanCache
+	// responseShapes learns the shapes of the upstream responses, see EngineV2Configuration.EnableResponseShapeLearning
+	responseShapes *responseShapeLearner
+	// loadedAt is the time the engine was created with its schema, see ExecutionEngineV2.Status
+	loadedAt time.Time
 }
 
 type WebsocketBeforeStartHook interface {
 	OnBeforeStart(reqCtx context.Context, operation *Request) error
 }
 
+// AbandonedOperationHook is called for queries and mutations which were cancelled before their response was resolved,
+// e.g. because the client disconnected. err is the error returned by Execute, if any.
+type AbandonedOperationHook interface {
+	OnOperationAbandoned(ctx context.Context, operation *Request, err error)
+}
+
+// ResponseSizeLimitHook is called for responses which exceeded the max response size and weren't written,
+// err contains the path at which the response got truncated.
+type ResponseSizeLimitHook interface {
+	OnResponseSizeLimitExceeded(ctx context.Context, operation *Request, err *resolve.ResponseSizeLimitError)
+}
+
+type ResponseSizeLimitHookFunc func(ctx context.Context, operation *Request, err *resolve.ResponseSizeLimitError)
+
+func (f ResponseSizeLimitHookFunc) OnResponseSizeLimitExceeded(ctx context.Context, operation *Request, err *resolve.ResponseSizeLimitError) {
+	f(ctx, operation, err)
+}
+
 type ExecutionOptionsV2 func(ctx *internalExecutionContext)
 
 func WithBeforeFetchHook(hook resolve.BeforeFetchHook) ExecutionOptionsV2 {
@@ -193,13 +242,69 @@ func WithAdditionalHttpHeaders(headers http.Header, excludeByKeys ...string) Exe
 	}
 }
 
-func NewExecutionEngineV2(ctx context.Context, logger abstractlogger.Logger, engineConfig EngineV2Configuration) (*ExecutionEngineV2, error) {
-	executionPlanCache, err := lru.New(1024)
+// WithTraceContext propagates the trace context to all upstream requests and subscription connections of the operation.
+// The trace id is added to the extensions of errors created by the engine.
+// Use tracing.FromHeaderOrNew to extract the trace context from the client request.
+func WithTraceContext(traceContext tracing.TraceContext) ExecutionOptionsV2 {
+	return func(ctx *internalExecutionContext) {
+		ctx.resolveContext.Context = tracing.NewContext(ctx.resolveContext.Context, traceContext)
+	}
+}
+
+// WithRequestMetadata adds the headers, claims, trace context, feature flags and values of the builder to the request,
+// data sources can access them with resolve.RequestFromContext
+func WithRequestMetadata(builder *resolve.ContextBuilder) ExecutionOptionsV2 {
+	return func(ctx *internalExecutionContext) {
+		builder.Apply(ctx.resolveContext)
+	}
+}
+
+// WithMemoryLimit overrides the memory limit of the engine for the request, see EngineV2Configuration.SetMemoryLimit
+func WithMemoryLimit(bytes int64) ExecutionOptionsV2 {
+	return func(ctx *internalExecutionContext) {
+		ctx.resolveContext.SetMemoryLimit(bytes)
+	}
+}
+
+// WithMaxResponseSize overrides the max response size of the engine for the request, see EngineV2Configuration.SetMaxResponseSize
+func WithMaxResponseSize(bytes int64) ExecutionOptionsV2 {
+	return func(ctx *internalExecutionContext) {
+		ctx.resolveContext.SetMaxResponseSize(bytes)
+	}
+}
+
+// WithFetchRecorder records all fetches of the operation with the recorder, e.g. a replay_datasource.Recorder
+// to reproduce the operation locally with replay_datasource.Replay
+func WithFetchRecorder(recorder resolve.FetchRecorder) ExecutionOptionsV2 {
+	return func(ctx *internalExecutionContext) {
+		ctx.resolveContext.SetFetchRecorder(recorder)
+	}
+}
+
+func NewExecutionEngineV2(ctx context.Context, logger logging.Logger, engineConfig EngineV2Configuration) (*ExecutionEngineV2, error) {
+	planCacheTracker := newPlanCacheTracker()
+	executionPlanCache, err := lru.NewWithEvict(1024, planCacheTracker.evict)
 	if err != nil {
 		return nil, err
 	}
 	fetcher := resolve.NewFetcher(engineConfig.dataLoaderConfig.EnableSingleFlightLoader)
 
+	if engineConfig.subgraph != nil {
+		subgraph, err := newSubgraph(engineConfig.schema, *engineConfig.subgraph, engineConfig.plannerConfig.DataSources)
+		if err != nil {
+			return nil, err
+		}
+		engineConfig.schema = subgraph.schema
+		for i := range subgraph.dataSources {
+			engineConfig.AddDataSource(subgraph.dataSources[i])
+		}
+		for i := range subgraph.fields {
+			engineConfig.AddFieldConfiguration(subgraph.fields[i])
+		}
+	}
+
+	engineConfig.plannerConfig.DataSources = detectUpstreamCapabilities(ctx, logger, engineConfig.plannerConfig.DataSources)
+
 	introspectionCfg, err := introspection_datasource.NewIntrospectionConfigFactory(&engineConfig.schema.document)
 	if err != nil {
 		return nil, err
@@ -210,7 +315,48 @@ func NewExecutionEngineV2(ctx context.Context, logger abstractlogger.Logger, eng
 		engineConfig.AddFieldConfiguration(fieldCfg)
 	}
 
-	return &ExecutionEngineV2{
+	var introspectionResolver *introspectionResolver
+	if engineConfig.introspectionFastPath {
+		introspectionResolver, err = newIntrospectionResolver(&engineConfig.schema.document)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	schemaViews, err := newSchemaViews(engineConfig.schema, engineConfig.schemaViews)
+	if err != nil {
+		return nil, err
+	}
+
+	if engineConfig.introspectionCache != nil {
+		schemaHashes := []uint64{engineConfig.schema.Hash()}
+		for _, view := range schemaViews {
+			schemaHashes = append(schemaHashes, view.schema.Hash())
+		}
+		engineConfig.introspectionCache.useSchemas(schemaHashes...)
+	}
+
+	admission, err := newAdmissionController(engineConfig.admissionControl)
+	if err != nil {
+		return nil, err
+	}
+
+	operationConcurrency, err := newOperationConcurrency(engineConfig.operationConcurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	partialPlans, err := newPartialPlanCache(engineConfig.partialPlanCache)
+	if err != nil {
+		return nil, err
+	}
+
+	healthChecker := newHealthChecker(engineConfig.plannerConfig.DataSources, engineConfig.healthCheckClient, engineConfig.healthCheckHook)
+	if healthChecker != nil {
+		engineConfig.plannerConfig.DataSourceHealth = healthChecker
+	}
+
+	engine := &ExecutionEngineV2{
 		logger:   logger,
 		config:   engineConfig,
 		planner:  plan.NewPlanner(ctx, engineConfig.plannerConfig),
@@ -220,92 +366,372 @@ func NewExecutionEngineV2(ctx context.Context, logger abstractlogger.Logger, eng
 				return newInternalExecutionContext()
 			},
 		},
-		executionPlanCache: executionPlanCache,
-	}, nil
+		executionPlanCache:    executionPlanCache,
+		planCacheTracker:      planCacheTracker,
+		introspectionResolver: introspectionResolver,
+		schemaViews:           schemaViews,
+		healthChecker:         healthChecker,
+		persistedPlans:        map[uint64]struct{}{},
+		admission:             admission,
+		operationConcurrency:  operationConcurrency,
+		partialPlans:          partialPlans,
+		loadedAt:              time.Now(),
+	}
+
+	if engineConfig.responseShapeLearning {
+		engine.responseShapes = newResponseShapeLearner(engineConfig.schema)
+	}
+
+	if healthChecker != nil {
+		healthChecker.onChange = engine.purgeExecutionPlans
+		healthChecker.logger = logger
+		healthChecker.start(ctx)
+	}
+
+	if engine.persistsPlans() {
+		engine.loadPersistedPlans()
+	}
+
+	return engine, nil
 }
 
 func (e *ExecutionEngineV2) Execute(ctx context.Context, operation *Request, writer resolve.FlushWriter, options ...ExecutionOptionsV2) error {
-	if !operation.IsNormalized() {
-		result, err := operation.Normalize(e.config.schema)
-		if err != nil {
+	if e.config.fragmentArguments {
+		operation.fragmentArguments = true
+	}
+	if e.config.clientNullability {
+		operation.clientNullability = true
+	}
+
+	// preparing the operation, i.e. parsing, normalizing, validating and planning it, is bounded by the admission control
+	release := func() {}
+	if e.admission != nil {
+		var err error
+		if release, err = e.admission.acquire(ctx); err != nil {
 			return err
 		}
+		defer release()
+	}
 
-		if !result.Successful {
-			return result.Errors
+	schema, view, err := e.selectSchemaView(ctx, operation)
+	if err != nil {
+		return err
+	}
+
+	if err = e.prepareOperation(ctx, operation, schema); err != nil {
+		return err
+	}
+
+	if view != nil {
+		// the introspection data source would introspect the complete schema
+		isIntrospection, _ := operation.IsIntrospectionQuery()
+		if isIntrospection {
+			return e.resolveIntrospection(schema, view.introspectionResolver, operation, writer)
+		}
+		if selectsIntrospection(operation) {
+			return ErrSchemaViewMixedIntrospection
+		}
+	} else if e.introspectionResolver != nil {
+		if isIntrospection, _ := operation.IsIntrospectionQuery(); isIntrospection {
+			return e.resolveIntrospection(schema, e.introspectionResolver, operation, writer)
 		}
 	}
 
-	result, err := operation.ValidateForSchema(e.config.schema)
+	operationType, err := operation.OperationType()
 	if err != nil {
 		return err
 	}
-	if !result.Valid {
-		return result.Errors
+
+	extensions, err := operation.ParsedExtensions()
+	if err != nil {
+		return err
 	}
 
 	execContext := e.getExecutionCtx()
 	defer e.putExecutionCtx(execContext)
 
 	execContext.prepare(ctx, operation.Variables, operation.request)
+	execContext.resolveContext.Request.Extensions = extensions
+
+	if len(e.config.directiveMiddlewares) != 0 {
+		execContext.resolveContext.SetDirectiveMiddlewares(e.config.directiveMiddlewares)
+	}
+	execContext.resolveContext.SetMemoryLimit(e.config.memoryLimit)
+	execContext.resolveContext.SetMaxResponseSize(e.config.maxResponseSize)
+	execContext.resolveContext.Request.FeatureFlags = rollOutFeatureFlags(execContext.resolveContext.Request.FeatureFlags, e.config.featureFlagRollouts)
 
 	for i := range options {
 		options[i](execContext)
 	}
 
+	// the cache key is computed once the options are applied, as they may add request headers or feature flags, e.g. WithAdditionalHttpHeaders
+	var cacheKey uint64
+	if e.config.responseCache != nil && operationType == OperationTypeQuery {
+		var hit bool
+		cacheKey, hit, err = e.config.responseCache.lookup(operation, execContext.resolveContext.Request.Header, execContext.resolveContext.Request.FeatureFlags, writer)
+		if err != nil || hit {
+			return err
+		}
+	}
+
+	var recorder *responseRecorder
+	if e.recordsResponse(operationType) {
+		recorder = &responseRecorder{writer: writer}
+		writer = recorder
+	}
+
+	if e.responseShapes != nil {
+		execContext.resolveContext.AddFetchRecorder(e.responseShapes)
+	}
+
+	if maxResponseSize := execContext.resolveContext.MaxResponseSize(); maxResponseSize > 0 {
+		execContext.resolveContext.Context = httpclient.CtxSetMaxDecompressedSize(execContext.resolveContext.Context, maxResponseSize)
+	}
+
+	if e.config.timeoutBudget != nil {
+		execContext.resolveContext.Context = httpclient.CtxSetTimeoutBudget(execContext.resolveContext.Context, *e.config.timeoutBudget)
+	}
+
+	if e.config.repeatedFetchDetection {
+		execContext.resolveContext.EnableRepeatedFetchDetection()
+	}
+
+	if e.config.freshnessExtension {
+		execContext.resolveContext.EnableFreshnessTracking()
+	}
+
+	if e.config.schemaHashExtension {
+		execContext.resolveContext.SetResponseExtension(schemaHashExtensionKey, []byte(strconv.Quote(schema.HashString())))
+	}
+
 	var report operationreport.Report
 	cachedPlan := e.getCachedPlan(execContext, &operation.document, &e.config.schema.document, operation.OperationName, &report)
 	if report.HasErrors() {
 		return report
 	}
+	release()
+
+	// conflicting mutations are serialized until their responses are resolved
+	if e.operationConcurrency != nil && operationType == OperationTypeMutation {
+		if keys := e.operationConcurrency.keys(operation); len(keys) != 0 {
+			releaseKeys, err := e.operationConcurrency.acquire(ctx, keys)
+			if err != nil {
+				return err
+			}
+			defer releaseKeys()
+		}
+	}
 
 	switch p := cachedPlan.(type) {
 	case *plan.SynchronousResponsePlan:
 		err = e.resolver.ResolveGraphQLResponse(execContext.resolveContext, p.Response, nil, writer)
+		if ctx.Err() != nil {
+			e.operationAbandoned(ctx, operation, err)
+		}
+		e.logRepeatedFetches(operation, execContext.resolveContext.RepeatedFetches())
+		if recorder != nil && err == nil && ctx.Err() == nil {
+			e.completeResponse(operation, operationType, cacheKey, recorder.response)
+		}
 	case *plan.SubscriptionResponsePlan:
-		err = e.resolver.ResolveGraphQLSubscription(execContext.resolveContext, p.Response, writer)
+		subscription := e.subscriptions.start(operation.OperationName, execContext.client, writer)
+		err = e.resolver.ResolveGraphQLSubscription(execContext.resolveContext, p.Response, subscription)
+		e.subscriptions.done(subscription, err)
 	default:
 		return errors.New("execution of operation is not possible")
 	}
 
+	var sizeErr *resolve.ResponseSizeLimitError
+	if errors.As(err, &sizeErr) {
+		e.responseTruncated(ctx, operation, sizeErr)
+	}
+
 	return err
 }
 
-func (e *ExecutionEngineV2) getCachedPlan(ctx *internalExecutionContext, operation, definition *ast.Document, operationName string, report *operationreport.Report) plan.Plan {
+// resolveIntrospection writes the response of the introspection operation, from the introspection cache if it's set
+func (e *ExecutionEngineV2) resolveIntrospection(schema *Schema, resolver *introspectionResolver, operation *Request, writer io.Writer) error {
+	if e.config.introspectionCache != nil {
+		return e.config.introspectionCache.resolve(schema, resolver, operation, writer)
+	}
+	return resolver.Resolve(&operation.document, operation.OperationName, writer)
+}
 
-	hash := pool.Hash64.Get()
-	hash.Reset()
-	defer pool.Hash64.Put(hash)
-	err := astprinter.Print(operation, definition, hash)
+// prepareOperation injects the server-side variables of the operation, transforms, normalizes and validates it for the schema
+func (e *ExecutionEngineV2) prepareOperation(ctx context.Context, operation *Request, schema *Schema) error {
+	if err := e.injectVariables(ctx, operation); err != nil {
+		return err
+	}
+
+	if err := e.transformOperation(ctx, operation, schema); err != nil {
+		return err
+	}
+
+	if !operation.IsNormalized() {
+		result, err := operation.NormalizeWithFragmentRegistry(schema, e.config.fragmentRegistry)
+		if err != nil {
+			return err
+		}
+
+		if !result.Successful {
+			return result.Errors
+		}
+	}
+
+	result, err := operation.ValidateForSchema(schema)
 	if err != nil {
-		report.AddInternalError(err)
-		return nil
+		return err
+	}
+	if !result.Valid {
+		return result.Errors
 	}
+	return nil
+}
 
-	cacheKey := hash.Sum64()
+func (e *ExecutionEngineV2) getCachedPlan(ctx *internalExecutionContext, operation, definition *ast.Document, operationName string, report *operationreport.Report) plan.Plan {
+	featureFlags := plannedFeatureFlags(e.config.plannerConfig.DataSources, ctx.resolveContext.Request.FeatureFlags)
+	cacheKey, ok := e.planCacheKey(operation, definition, featureFlags, report)
+	if !ok {
+		return nil
+	}
 
 	if cached, ok := e.executionPlanCache.Get(cacheKey); ok {
 		if p, ok := cached.(plan.Plan); ok {
+			e.planCacheTracker.hit(cacheKey)
 			return p
 		}
 	}
+	e.planCacheTracker.miss()
+
+	var planCacheEntry []byte
+	// persisted plans are loaded without feature flags
+	if e.persistsPlans() && len(featureFlags) == 0 {
+		// the operation is printed before planning, as the planner modifies it
+		var err error
+		planCacheEntry, err = e.newPlanCacheEntry(operation, definition, operationName)
+		if err != nil {
+			report.AddInternalError(err)
+			return nil
+		}
+	}
+
+	// the planner adds fields to the operation, so the cost is taken before planning
+	planningCost := len(operation.Fields)
+
+	p, cached := e.planAndCache(ctx, cacheKey, operation, definition, operationName, featureFlags, planningCost, report)
+	if cached && planCacheEntry != nil {
+		// the plan is persisted after releasing the planner, so that a slow store doesn't block planning other operations
+		e.persistPlan(cacheKey, planCacheEntry)
+	}
+	return p
+}
 
+// planAndCache plans the operation and adds the plan to the plan cache if it's admitted
+func (e *ExecutionEngineV2) planAndCache(ctx *internalExecutionContext, cacheKey uint64, operation, definition *ast.Document, operationName string, featureFlags resolve.FeatureFlags, planningCost int, report *operationreport.Report) (p plan.Plan, cached bool) {
 	e.plannerMu.Lock()
 	defer e.plannerMu.Unlock()
-	planResult := e.planner.Plan(operation, definition, operationName, report)
+	e.planner.SetFeatureFlags(featureFlags)
+	planResult := e.planOperation(operation, definition, operationName, featureFlags, report)
 	if report.HasErrors() {
-		return nil
+		return nil, false
 	}
 
-	p := ctx.postProcessor.Process(planResult)
+	p = ctx.postProcessor.Process(planResult)
+	if !e.admitsPlan(cacheKey, planningCost) {
+		e.planCacheTracker.reject()
+		return p, false
+	}
+	e.planCacheTracker.admit(cacheKey, operationName, planningCost)
 	e.executionPlanCache.Add(cacheKey, p)
-	return p
+	return p, true
+}
+
+func (e *ExecutionEngineV2) planCacheKey(operation, definition *ast.Document, featureFlags resolve.FeatureFlags, report *operationreport.Report) (uint64, bool) {
+	hash := pool.Hash64.Get()
+	hash.Reset()
+	defer pool.Hash64.Put(hash)
+	err := astprinter.Print(operation, definition, hash)
+	if err != nil {
+		report.AddInternalError(err)
+		return 0, false
+	}
+
+	if e.config.plannerConfig.FoldSkipIncludeVariables {
+		writeSkipIncludeVariables(operation, hash)
+	}
+
+	for i := range featureFlags {
+		_, _ = hash.Write([]byte(featureFlags[i]))
+		_, _ = hash.Write([]byte(";"))
+	}
+
+	return hash.Sum64(), true
+}
+
+// writeSkipIncludeVariables writes the values of all variables used by @skip/@include directives,
+// as plans with folded skip/include directives are only valid for these values
+func writeSkipIncludeVariables(operation *ast.Document, w io.Writer) {
+	for ref := range operation.Directives {
+		name := operation.DirectiveNameBytes(ref)
+		if !bytes.Equal(name, literal.SKIP) && !bytes.Equal(name, literal.INCLUDE) {
+			continue
+		}
+		value, ok := operation.DirectiveArgumentValueByName(ref, literal.IF)
+		if !ok || value.Kind != ast.ValueKindVariable {
+			continue
+		}
+		variableName := operation.VariableValueNameString(value.Ref)
+		variableValue, _, _, _ := jsonparser.Get(operation.Input.Variables, variableName)
+		_, _ = w.Write([]byte(variableName))
+		_, _ = w.Write([]byte(":"))
+		_, _ = w.Write(variableValue)
+		_, _ = w.Write([]byte(";"))
+	}
 }
 
 func (e *ExecutionEngineV2) GetWebsocketBeforeStartHook() WebsocketBeforeStartHook {
 	return e.config.websocketBeforeStartHook
 }
 
+// AbandonedOperations returns the number of queries and mutations which were cancelled before their response was resolved
+func (e *ExecutionEngineV2) AbandonedOperations() uint64 {
+	return atomic.LoadUint64(&e.abandonedOperations)
+}
+
+func (e *ExecutionEngineV2) operationAbandoned(ctx context.Context, operation *Request, err error) {
+	atomic.AddUint64(&e.abandonedOperations, 1)
+	if e.config.abandonedOperationHook != nil {
+		e.config.abandonedOperationHook.OnOperationAbandoned(ctx, operation, err)
+	}
+}
+
+// TruncatedResponses returns the number of responses which exceeded the max response size
+func (e *ExecutionEngineV2) TruncatedResponses() uint64 {
+	return atomic.LoadUint64(&e.truncatedResponses)
+}
+
+func (e *ExecutionEngineV2) responseTruncated(ctx context.Context, operation *Request, err *resolve.ResponseSizeLimitError) {
+	atomic.AddUint64(&e.truncatedResponses, 1)
+	e.logger.Warn("ExecutionEngineV2.Execute: response size limit exceeded",
+		logging.String("operationName", operation.OperationName),
+		logging.String("path", err.Path),
+		logging.Int("limit", int(err.Limit)),
+	)
+	if e.config.responseSizeLimitHook != nil {
+		e.config.responseSizeLimitHook.OnResponseSizeLimitExceeded(ctx, operation, err)
+	}
+}
+
+func (e *ExecutionEngineV2) logRepeatedFetches(operation *Request, repeatedFetches []resolve.RepeatedFetch) {
+	for i := range repeatedFetches {
+		e.logger.Warn("ExecutionEngineV2.Execute: repeated fetch detected, consider enabling batching for the data source",
+			logging.String("operationName", operation.OperationName),
+			logging.String("path", repeatedFetches[i].Path),
+			logging.String("dataSource", repeatedFetches[i].DataSource),
+			logging.Int("count", repeatedFetches[i].Count),
+		)
+	}
+}
+
 func (e *ExecutionEngineV2) getExecutionCtx() *internalExecutionContext {
 	return e.internalExecutionContextPool.Get().(*internalExecutionContext)
 }
@@ -1,27 +1,42 @@
 package graphql
 
 import (
+	"bytes"
 	"compress/flate"
 	"compress/gzip"
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
-	"github.com/jensneuse/abstractlogger"
+	"github.com/klauspost/compress/zstd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/failover"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/hedging"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/replay_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/replication"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/rest_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/routing_datasource"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/staticdatasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/union_datasource"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
 	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
 	"github.com/wundergraph/graphql-go-tools/pkg/starwars"
 	"github.com/wundergraph/graphql-go-tools/pkg/testing/federationtesting"
@@ -176,7 +191,7 @@ func TestExecutionEngineV2_Execute(t *testing.T) {
 			engineConf.SetFieldConfigurations(testCase.fields)
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
-			engine, err := NewExecutionEngineV2(ctx, abstractlogger.Noop{}, engineConf)
+			engine, err := NewExecutionEngineV2(ctx, logging.Noop{}, engineConf)
 			require.NoError(t, err)
 
 			operation := testCase.operation(t)
@@ -699,6 +714,182 @@ func TestExecutionEngineV2_Execute(t *testing.T) {
 		},
 	))
 
+	t.Run("execute operation with entity fields of a rest data source keyed by a federated graphql data source", runWithoutError(
+		ExecutionEngineV2TestCase{
+			schema: func(t *testing.T) *Schema {
+				t.Helper()
+				parseSchema, err := NewSchemaFromString(`
+					type Query {
+						reviews: [Review]
+					}
+
+					type Review {
+						body: String!
+						author: User
+					}
+
+					type User {
+						id: ID!
+						name: String!
+						email: String!
+					}`)
+				require.NoError(t, err)
+				return parseSchema
+			}(t),
+			operation: func(t *testing.T) Request {
+				return Request{
+					Query: `{ reviews { body author { name email } } }`,
+				}
+			},
+			dataSources: []plan.DataSourceConfiguration{
+				{
+					RootNodes: []plan.TypeField{
+						{TypeName: "Query", FieldNames: []string{"reviews"}},
+						{TypeName: "User", FieldNames: []string{"id"}},
+					},
+					ChildNodes: []plan.TypeField{
+						{TypeName: "Review", FieldNames: []string{"body", "author"}},
+						{TypeName: "User", FieldNames: []string{"id"}},
+					},
+					Factory: &graphql_datasource.Factory{
+						HTTPClient: testNetHttpClient(t, roundTripperTestCase{
+							expectedHost:     "reviews.service",
+							expectedPath:     "",
+							expectedBody:     `{"query":"{reviews {body author {id}}}"}`,
+							sendResponseBody: `{"data":{"reviews":[{"body":"A highly effective form of birth control.","author":{"id":"1"}}]}}`,
+							sendStatusCode:   200,
+						}),
+					},
+					Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+						Fetch: graphql_datasource.FetchConfiguration{
+							URL: "https://reviews.service",
+						},
+						Federation: graphql_datasource.FederationConfiguration{
+							Enabled:    true,
+							ServiceSDL: `type Query { reviews: [Review] } type Review { body: String! author: User } extend type User @key(fields: "id") { id: ID! @external }`,
+						},
+					}),
+				},
+				{
+					RootNodes: []plan.TypeField{
+						{TypeName: "User", FieldNames: []string{"name", "email"}},
+					},
+					Entities: []plan.EntityConfiguration{
+						{TypeName: "User", KeyFields: []string{"id"}},
+					},
+					Factory: &rest_datasource.Factory{
+						Client: testNetHttpClient(t, roundTripperTestCase{
+							expectedHost:     "users.service",
+							expectedPath:     "/users/1",
+							sendResponseBody: `{"id":"1","name":"Me","email":"me@example.com"}`,
+							sendStatusCode:   200,
+						}),
+					},
+					Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+						Fetch: rest_datasource.FetchConfiguration{
+							URL:    "https://users.service/users/{{ .object.id }}",
+							Method: "GET",
+						},
+					}),
+				},
+			},
+			expectedResponse: `{"data":{"reviews":[{"body":"A highly effective form of birth control.","author":{"name":"Me","email":"me@example.com"}}]}}`,
+		},
+	))
+
+	t.Run("execute operation with extension fields of a federated graphql data source on an entity of a rest data source", runWithoutError(
+		ExecutionEngineV2TestCase{
+			schema: func(t *testing.T) *Schema {
+				t.Helper()
+				parseSchema, err := NewSchemaFromString(`
+					type Query {
+						me: User
+					}
+
+					type Review {
+						body: String!
+					}
+
+					type User {
+						id: ID!
+						name: String!
+						reviews: [Review]
+					}`)
+				require.NoError(t, err)
+				return parseSchema
+			}(t),
+			operation: func(t *testing.T) Request {
+				return Request{
+					Query: `{ me { name reviews { body } } }`,
+				}
+			},
+			dataSources: []plan.DataSourceConfiguration{
+				{
+					RootNodes: []plan.TypeField{
+						{TypeName: "Query", FieldNames: []string{"me"}},
+					},
+					ChildNodes: []plan.TypeField{
+						{TypeName: "User", FieldNames: []string{"id", "name"}},
+					},
+					Factory: &rest_datasource.Factory{
+						Client: testNetHttpClient(t, roundTripperTestCase{
+							expectedHost:     "users.service",
+							expectedPath:     "/me",
+							sendResponseBody: `{"id":"1","name":"Me"}`,
+							sendStatusCode:   200,
+						}),
+					},
+					Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+						Fetch: rest_datasource.FetchConfiguration{
+							URL:    "https://users.service/me",
+							Method: "GET",
+						},
+					}),
+				},
+				{
+					RootNodes: []plan.TypeField{
+						{TypeName: "User", FieldNames: []string{"reviews"}},
+					},
+					ChildNodes: []plan.TypeField{
+						{TypeName: "Review", FieldNames: []string{"body"}},
+					},
+					Factory: &graphql_datasource.Factory{
+						BatchFactory: graphql_datasource.NewBatchFactory(),
+						HTTPClient: testNetHttpClient(t, roundTripperTestCase{
+							expectedHost:     "reviews.service",
+							expectedPath:     "",
+							expectedBody:     `{"query":"query($representations: [_Any!]!){_entities(representations: $representations){__typename ... on User {reviews {body}}}}","variables":{"representations":[{"id":"1","__typename":"User"}]}}`,
+							sendResponseBody: `{"data":{"_entities":[{"__typename":"User","reviews":[{"body":"A highly effective form of birth control."}]}]}}`,
+							sendStatusCode:   200,
+						}),
+					},
+					Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+						Fetch: graphql_datasource.FetchConfiguration{
+							URL: "https://reviews.service",
+						},
+						Federation: graphql_datasource.FederationConfiguration{
+							Enabled:    true,
+							ServiceSDL: `extend type User @key(fields: "id") { id: ID! @external reviews: [Review] } type Review { body: String! }`,
+						},
+					}),
+				},
+			},
+			fields: []plan.FieldConfiguration{
+				{
+					TypeName:              "Query",
+					FieldName:             "me",
+					DisableDefaultMapping: true,
+				},
+				{
+					TypeName:       "User",
+					FieldName:      "reviews",
+					RequiresFields: []string{"id"},
+				},
+			},
+			expectedResponse: `{"data":{"me":{"name":"Me","reviews":[{"body":"A highly effective form of birth control."}]}}}`,
+		},
+	))
+
 	t.Run("execute simple hero operation with graphql data source", runWithoutError(
 		ExecutionEngineV2TestCase{
 			schema:    starwarsSchema(t),
@@ -1751,6 +1942,58 @@ func (a *afterFetchHook) OnError(_ resolve.HookContext, output []byte, _ bool) {
 	a.err += string(output)
 }
 
+func TestExecutionEngineV2_FederationInterfaceEntities(t *testing.T) {
+	var upstreamQueries []string
+	client := &http.Client{
+		Transport: testRoundTripper(func(req *http.Request) *http.Response {
+			body, _ := ioutil.ReadAll(req.Body)
+			upstreamQueries = append(upstreamQueries, string(body))
+			response := `{"data":{"media":[{"__typename":"Book","id":"1","title":"Dune"}]}}`
+			if req.URL.Host == "ratings.service" {
+				response = `{"data":{"_entities":[{"__typename":"Book","rating":5}]}}`
+			}
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(response))}
+		}),
+	}
+
+	engineConfigFactory := NewFederationEngineConfigFactory([]graphql_datasource.Configuration{
+		{
+			Fetch: graphql_datasource.FetchConfiguration{URL: "https://media.service"},
+			Federation: graphql_datasource.FederationConfiguration{
+				Enabled: true,
+				ServiceSDL: `
+					type Query { media: [Media] }
+					interface Media @key(fields: "id") { id: ID! title: String }
+					type Book implements Media @key(fields: "id") { id: ID! title: String }`,
+			},
+		},
+		{
+			Fetch: graphql_datasource.FetchConfiguration{URL: "https://ratings.service"},
+			Federation: graphql_datasource.FederationConfiguration{
+				Enabled: true,
+				ServiceSDL: `
+					extend interface Media @key(fields: "id") { id: ID! @external rating: Int }
+					extend type Book implements Media @key(fields: "id") { id: ID! @external rating: Int }`,
+			},
+		},
+	}, graphql_datasource.NewBatchFactory(), WithFederationHttpClient(client))
+	engineConf, err := engineConfigFactory.EngineV2Configuration()
+	require.NoError(t, err)
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	resultWriter := NewEngineResultWriter()
+	err = engine.Execute(context.Background(), &Request{Query: `{ media { title rating } }`}, &resultWriter)
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"data":{"media":[{"title":"Dune","rating":5}]}}`, resultWriter.String())
+	assert.Equal(t, []string{
+		`{"query":"{media {__typename title id}}"}`,
+		// the representation has the typename of the concrete type of the interface entity
+		`{"query":"query($representations: [_Any!]!){_entities(representations: $representations){__typename ... on Media {rating}}}","variables":{"representations":[{"id":"1","__typename":"Book"}]}}`,
+	}, upstreamQueries)
+}
+
 func TestExecutionWithOptions(t *testing.T) {
 
 	closer := make(chan struct{})
@@ -1801,7 +2044,7 @@ func TestExecutionWithOptions(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	engine, err := NewExecutionEngineV2(ctx, abstractlogger.Noop{}, engineConf)
+	engine, err := NewExecutionEngineV2(ctx, logging.Noop{}, engineConf)
 	require.NoError(t, err)
 
 	before := &beforeFetchHook{}
@@ -1817,110 +2060,2745 @@ func TestExecutionWithOptions(t *testing.T) {
 	assert.NoError(t, err)
 }
 
-func TestExecutionEngineV2_GetCachedPlan(t *testing.T) {
-	schema, err := NewSchemaFromString(testSubscriptionDefinition)
-	require.NoError(t, err)
-
-	gqlRequest := Request{
-		OperationName: "LastRegisteredUser",
-		Variables:     nil,
-		Query:         testSubscriptionLastRegisteredUserOperation,
-	}
-
-	validationResult, err := gqlRequest.ValidateForSchema(schema)
-	require.NoError(t, err)
-	require.True(t, validationResult.Valid)
+type abandonedOperationHook struct {
+	abandoned []string
+	err       error
+}
 
-	normalizationResult, err := gqlRequest.Normalize(schema)
-	require.NoError(t, err)
-	require.True(t, normalizationResult.Successful)
+func (a *abandonedOperationHook) OnOperationAbandoned(_ context.Context, operation *Request, err error) {
+	a.abandoned = append(a.abandoned, operation.Query)
+	a.err = err
+}
 
-	differentGqlRequest := Request{
-		OperationName: "LiveUserCount",
-		Variables:     nil,
-		Query:         testSubscriptionLiveUserCountOperation,
-	}
+func TestExecutionEngineV2_RepeatedFetchDetection(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			users: [User]
+		}
 
-	validationResult, err = differentGqlRequest.ValidateForSchema(schema)
-	require.NoError(t, err)
-	require.True(t, validationResult.Valid)
+		type User {
+			id: ID!
+			address: Address
+		}
 
-	normalizationResult, err = differentGqlRequest.Normalize(schema)
+		type Address {
+			city: String
+		}`)
 	require.NoError(t, err)
-	require.True(t, normalizationResult.Successful)
 
-	engineConfig := NewEngineV2Configuration(schema)
-	engineConfig.SetDataSources([]plan.DataSourceConfiguration{
-		{
-			RootNodes: []plan.TypeField{
-				{
-					TypeName:   "Subscription",
-					FieldNames: []string{"lastRegisteredUser", "liveUserCount"},
+	newEngine := func(t *testing.T, enableDetection bool) *ExecutionEngineV2 {
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{
+					{TypeName: "Query", FieldNames: []string{"users"}},
 				},
-			},
-			ChildNodes: []plan.TypeField{
-				{
-					TypeName:   "User",
-					FieldNames: []string{"id", "username", "email"},
+				ChildNodes: []plan.TypeField{
+					{TypeName: "User", FieldNames: []string{"id"}},
 				},
+				Factory: &rest_datasource.Factory{
+					Client: testNetHttpClient(t, roundTripperTestCase{
+						expectedHost:     "users.service",
+						expectedPath:     "/users",
+						sendResponseBody: `[{"id":"1"},{"id":"2"}]`,
+						sendStatusCode:   200,
+					}),
+				},
+				Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+					Fetch: rest_datasource.FetchConfiguration{
+						URL:    "https://users.service/users",
+						Method: "GET",
+					},
+				}),
 			},
-			Factory: &graphql_datasource.Factory{},
-			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
-				Subscription: graphql_datasource.SubscriptionConfiguration{
-					URL: "http://localhost:8080",
+			{
+				RootNodes: []plan.TypeField{
+					{TypeName: "User", FieldNames: []string{"address"}},
 				},
-			}),
-		},
-	})
-
-	engine, err := NewExecutionEngineV2(context.Background(), abstractlogger.NoopLogger, engineConfig)
-	require.NoError(t, err)
-
-	t.Run("should reuse cached plan", func(t *testing.T) {
-		t.Cleanup(engine.executionPlanCache.Purge)
-		require.Equal(t, 0, engine.executionPlanCache.Len())
+				ChildNodes: []plan.TypeField{
+					{TypeName: "Address", FieldNames: []string{"city"}},
+				},
+				Factory: &rest_datasource.Factory{
+					Client: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							body := fmt.Sprintf(`{"city":"City of %s"}`, strings.TrimPrefix(req.URL.Path, "/addresses/"))
+							return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(body))}
+						}),
+					},
+				},
+				Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+					Fetch: rest_datasource.FetchConfiguration{
+						URL:    "https://addresses.service/addresses/{{ .object.id }}",
+						Method: "GET",
+					},
+				}),
+			},
+		})
+		engineConf.SetFieldConfigurations(plan.FieldConfigurations{
+			{
+				TypeName:              "Query",
+				FieldName:             "users",
+				DisableDefaultMapping: true,
+			},
+			{
+				TypeName:              "User",
+				FieldName:             "address",
+				DisableDefaultMapping: true,
+				RequiresFields:        []string{"id"},
+			},
+		})
+		engineConf.EnableRepeatedFetchDetection(enableDetection)
 
-		firstInternalExecCtx := newInternalExecutionContext()
-		firstInternalExecCtx.resolveContext.Request.Header = http.Header{
-			http.CanonicalHeaderKey("Authorization"): []string{"123abc"},
-		}
+		engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine
+	}
 
-		report := operationreport.Report{}
-		cachedPlan := engine.getCachedPlan(firstInternalExecCtx, &gqlRequest.document, &schema.document, gqlRequest.OperationName, &report)
-		_, oldestCachedPlan, _ := engine.executionPlanCache.GetOldest()
-		assert.False(t, report.HasErrors())
-		assert.Equal(t, 1, engine.executionPlanCache.Len())
-		assert.Equal(t, cachedPlan, oldestCachedPlan.(*plan.SubscriptionResponsePlan))
+	execute := func(t *testing.T, engine *ExecutionEngineV2) string {
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: `{ users { address { city } } }`}, &resultWriter)
+		require.NoError(t, err)
+		return resultWriter.String()
+	}
 
-		secondInternalExecCtx := newInternalExecutionContext()
-		secondInternalExecCtx.resolveContext.Request.Header = http.Header{
-			http.CanonicalHeaderKey("Authorization"): []string{"123abc"},
-		}
+	t.Run("repeated fetches are added to the extensions", func(t *testing.T) {
+		assert.Equal(t,
+			`{"data":{"users":[{"address":{"city":"City of 1"}},{"address":{"city":"City of 2"}}]},"extensions":{"repeatedFetches":[{"path":"/data/users/@","dataSource":"rest_datasource.Source","count":2}]}}`,
+			execute(t, newEngine(t, true)),
+		)
+	})
 
-		cachedPlan = engine.getCachedPlan(secondInternalExecCtx, &gqlRequest.document, &schema.document, gqlRequest.OperationName, &report)
-		_, oldestCachedPlan, _ = engine.executionPlanCache.GetOldest()
-		assert.False(t, report.HasErrors())
-		assert.Equal(t, 1, engine.executionPlanCache.Len())
-		assert.Equal(t, cachedPlan, oldestCachedPlan.(*plan.SubscriptionResponsePlan))
+	t.Run("repeated fetches are not detected by default", func(t *testing.T) {
+		assert.Equal(t,
+			`{"data":{"users":[{"address":{"city":"City of 1"}},{"address":{"city":"City of 2"}}]}}`,
+			execute(t, newEngine(t, false)),
+		)
 	})
+}
 
-	t.Run("should create new plan and cache it", func(t *testing.T) {
-		t.Cleanup(engine.executionPlanCache.Purge)
-		require.Equal(t, 0, engine.executionPlanCache.Len())
+func TestExecutionEngineV2_FreshnessExtension(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			users: [User]
+		}
 
-		firstInternalExecCtx := newInternalExecutionContext()
-		firstInternalExecCtx.resolveContext.Request.Header = http.Header{
-			http.CanonicalHeaderKey("Authorization"): []string{"123abc"},
+		type User {
+			id: ID!
+			address: Address
 		}
 
-		report := operationreport.Report{}
-		cachedPlan := engine.getCachedPlan(firstInternalExecCtx, &gqlRequest.document, &schema.document, gqlRequest.OperationName, &report)
-		_, oldestCachedPlan, _ := engine.executionPlanCache.GetOldest()
-		assert.False(t, report.HasErrors())
-		assert.Equal(t, 1, engine.executionPlanCache.Len())
-		assert.Equal(t, cachedPlan, oldestCachedPlan.(*plan.SubscriptionResponsePlan))
+		type Address {
+			city: String
+		}`)
+	require.NoError(t, err)
 
-		secondInternalExecCtx := newInternalExecutionContext()
+	newEngine := func(t *testing.T, enableFreshness bool) *ExecutionEngineV2 {
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{
+					{TypeName: "Query", FieldNames: []string{"users"}},
+				},
+				ChildNodes: []plan.TypeField{
+					{TypeName: "User", FieldNames: []string{"id"}},
+				},
+				Factory: &rest_datasource.Factory{
+					Client: testNetHttpClient(t, roundTripperTestCase{
+						expectedHost:     "users.service",
+						expectedPath:     "/users",
+						sendResponseBody: `[{"id":"1"},{"id":"2"}]`,
+						sendStatusCode:   200,
+					}),
+				},
+				Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+					Fetch: rest_datasource.FetchConfiguration{
+						URL:    "https://users.service/users",
+						Method: "GET",
+					},
+				}),
+			},
+			{
+				RootNodes: []plan.TypeField{
+					{TypeName: "User", FieldNames: []string{"address"}},
+				},
+				ChildNodes: []plan.TypeField{
+					{TypeName: "Address", FieldNames: []string{"city"}},
+				},
+				Factory: &rest_datasource.Factory{
+					Client: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							id := strings.TrimPrefix(req.URL.Path, "/addresses/")
+							header := http.Header{}
+							// the address of user 2 is served by a CDN
+							if id == "2" {
+								header.Set("Age", "60")
+								header.Set("X-Cache", "HIT")
+							}
+							body := fmt.Sprintf(`{"city":"City of %s"}`, id)
+							return &http.Response{StatusCode: 200, Header: header, Body: ioutil.NopCloser(strings.NewReader(body))}
+						}),
+					},
+				},
+				Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+					Fetch: rest_datasource.FetchConfiguration{
+						URL:    "https://addresses.service/addresses/{{ .object.id }}",
+						Method: "GET",
+					},
+				}),
+			},
+		})
+		engineConf.SetFieldConfigurations(plan.FieldConfigurations{
+			{
+				TypeName:              "Query",
+				FieldName:             "users",
+				DisableDefaultMapping: true,
+			},
+			{
+				TypeName:              "User",
+				FieldName:             "address",
+				DisableDefaultMapping: true,
+				RequiresFields:        []string{"id"},
+			},
+		})
+		engineConf.EnableFreshnessExtension(enableFreshness)
+
+		engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine
+	}
+
+	execute := func(t *testing.T, engine *ExecutionEngineV2) string {
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: `{ users { address { city } } }`}, &resultWriter)
+		require.NoError(t, err)
+		return resultWriter.String()
+	}
+
+	t.Run("freshness of cached and origin data is added to the extensions", func(t *testing.T) {
+		assert.Equal(t,
+			`{"data":{"users":[{"address":{"city":"City of 1"}},{"address":{"city":"City of 2"}}]},"extensions":{"freshness":[{"path":"/data","dataSource":"rest_datasource.Source","source":"origin","age":0},{"path":"/data/users/@","dataSource":"rest_datasource.Source","source":"cache","age":60}]}}`,
+			execute(t, newEngine(t, true)),
+		)
+	})
+
+	t.Run("freshness is not added by default", func(t *testing.T) {
+		assert.Equal(t,
+			`{"data":{"users":[{"address":{"city":"City of 1"}},{"address":{"city":"City of 2"}}]}}`,
+			execute(t, newEngine(t, false)),
+		)
+	})
+}
+
+func TestExecutionEngineV2_SchemaHashExtension(t *testing.T) {
+	schema, err := NewSchemaFromString(`type Query { hello: String }`)
+	require.NoError(t, err)
+
+	execute := func(t *testing.T, enable bool) string {
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{{TypeName: "Query", FieldNames: []string{"hello"}}},
+				Factory: &graphql_datasource.Factory{
+					HTTPClient: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"hello":"world"}}`))}
+						}),
+					},
+				},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch: graphql_datasource.FetchConfiguration{URL: "https://upstream/graphql", Method: "POST"},
+				}),
+			},
+		})
+		engineConf.EnableSchemaHashExtension(enable)
+
+		engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+
+		resultWriter := NewEngineResultWriter()
+		require.NoError(t, engine.Execute(context.Background(), &Request{Query: `{ hello }`}, &resultWriter))
+		return resultWriter.String()
+	}
+
+	t.Run("the schema hash is added to the extensions", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"hello":"world"},"extensions":{"schemaHash":"`+schema.HashString()+`"}}`, execute(t, true))
+	})
+
+	t.Run("the schema hash is not added by default", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"hello":"world"}}`, execute(t, false))
+	})
+}
+
+func TestExecutionEngineV2_ResponseCache(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			user(id: ID!): User
+		}
+
+		type Mutation {
+			renameUser(id: ID!, name: String!): User
+		}
+
+		type User {
+			id: ID!
+			name: String
+		}`)
+	require.NoError(t, err)
+
+	var (
+		fetches int
+		names   = map[string]string{"1": "Jens", "2": "Stefan"}
+	)
+	client := &http.Client{
+		Transport: testRoundTripper(func(req *http.Request) *http.Response {
+			fetches++
+			// mutations rename the user with the path /users/{id}/{name}
+			segments := strings.Split(strings.TrimPrefix(req.URL.Path, "/users/"), "/")
+			id := segments[0]
+			if len(segments) == 2 {
+				names[id] = segments[1]
+			}
+			body := fmt.Sprintf(`{"__typename":"User","id":"%s","name":"%s"}`, id, names[id])
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(body))}
+		}),
+	}
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"user"}},
+			},
+			ChildNodes: []plan.TypeField{
+				{TypeName: "User", FieldNames: []string{"id", "name"}},
+			},
+			Factory: &rest_datasource.Factory{Client: client},
+			Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+				Fetch: rest_datasource.FetchConfiguration{
+					URL:    "https://users.service/users/{{ .arguments.id }}",
+					Method: "GET",
+				},
+			}),
+		},
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Mutation", FieldNames: []string{"renameUser"}},
+			},
+			ChildNodes: []plan.TypeField{
+				{TypeName: "User", FieldNames: []string{"id", "name"}},
+			},
+			Factory: &rest_datasource.Factory{Client: client},
+			Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+				Fetch: rest_datasource.FetchConfiguration{
+					URL:    "https://users.service/users/{{ .arguments.id }}/{{ .arguments.name }}",
+					Method: "POST",
+				},
+			}),
+		},
+	})
+	engineConf.SetFieldConfigurations(plan.FieldConfigurations{
+		{
+			TypeName:              "Query",
+			FieldName:             "user",
+			DisableDefaultMapping: true,
+			Arguments: []plan.ArgumentConfiguration{
+				{Name: "id", SourceType: plan.FieldArgumentSource},
+			},
+		},
+		{
+			TypeName:              "Mutation",
+			FieldName:             "renameUser",
+			DisableDefaultMapping: true,
+			Arguments: []plan.ArgumentConfiguration{
+				{Name: "id", SourceType: plan.FieldArgumentSource},
+				{Name: "name", SourceType: plan.FieldArgumentSource},
+			},
+		},
+	})
+
+	backend, err := NewInMemoryResponseCacheBackend(InMemoryResponseCacheBackendConfig{MaxSize: 10})
+	require.NoError(t, err)
+	cache := NewResponseCache(backend, ResponseCacheConfig{
+		VaryHeaders:     []string{"Authorization"},
+		PurgeOnMutation: true,
+	})
+	engineConf.SetResponseCache(cache)
+
+	var invalidateFamilies bool
+	engineConf.SetMutationInvalidationHook(MutationInvalidationHookFunc(func(operation *Request, response []byte) []CacheInvalidationEvent {
+		if !invalidateFamilies {
+			return nil
+		}
+		return []CacheInvalidationEvent{{OperationFamilies: []string{"Query.user"}}}
+	}))
+	invalidator := &cacheInvalidator{}
+	engineConf.AddCacheInvalidator(invalidator)
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	execute := func(t *testing.T, query string, authorization string) string {
+		t.Helper()
+		operation := Request{Query: query}
+		operation.SetHeader(http.Header{"Authorization": []string{authorization}})
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &operation, &resultWriter)
+		require.NoError(t, err)
+		return resultWriter.String()
+	}
+
+	userQuery := func(id string) string {
+		return fmt.Sprintf(`{ user(id: "%s") { __typename id name } }`, id)
+	}
+
+	t.Run("query responses are cached", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"user":{"__typename":"User","id":"1","name":"Jens"}}}`, execute(t, userQuery("1"), "a"))
+		assert.Equal(t, `{"data":{"user":{"__typename":"User","id":"1","name":"Jens"}}}`, execute(t, userQuery("1"), "a"))
+		assert.Equal(t, 1, fetches)
+
+		assert.Equal(t, `{"data":{"user":{"__typename":"User","id":"2","name":"Stefan"}}}`, execute(t, userQuery("2"), "a"))
+		assert.Equal(t, 2, fetches, "arguments are part of the cache key")
+
+		assert.Equal(t, `{"data":{"user":{"__typename":"User","id":"1","name":"Jens"}}}`, execute(t, userQuery("1"), "b"))
+		assert.Equal(t, 3, fetches, "vary headers are part of the cache key")
+	})
+
+	t.Run("mutation purges the responses containing the mutated entity", func(t *testing.T) {
+		fetches = 0
+		assert.Equal(t,
+			`{"data":{"renameUser":{"__typename":"User","id":"1","name":"Dustin"}}}`,
+			execute(t, `mutation { renameUser(id: "1", name: "Dustin") { __typename id name } }`, "a"),
+		)
+		assert.Equal(t, 1, fetches, "mutations are not cached")
+
+		assert.Equal(t, `{"data":{"user":{"__typename":"User","id":"1","name":"Dustin"}}}`, execute(t, userQuery("1"), "a"))
+		assert.Equal(t, `{"data":{"user":{"__typename":"User","id":"1","name":"Dustin"}}}`, execute(t, userQuery("1"), "b"))
+		assert.Equal(t, 3, fetches)
+
+		assert.Equal(t, `{"data":{"user":{"__typename":"User","id":"2","name":"Stefan"}}}`, execute(t, userQuery("2"), "a"))
+		assert.Equal(t, 3, fetches, "responses of other entities are still cached")
+	})
+
+	t.Run("purge tags", func(t *testing.T) {
+		fetches = 0
+		assert.Equal(t, 1, cache.PurgeTags("User:2"))
+		assert.Equal(t, `{"data":{"user":{"__typename":"User","id":"2","name":"Stefan"}}}`, execute(t, userQuery("2"), "a"))
+		assert.Equal(t, 1, fetches)
+	})
+
+	t.Run("vary headers of additional http headers are part of the cache key", func(t *testing.T) {
+		fetches = 0
+		executeWithAdditionalHeaders := func(t *testing.T, authorization string) string {
+			t.Helper()
+			operation := Request{Query: userQuery("3")}
+			resultWriter := NewEngineResultWriter()
+			err := engine.Execute(context.Background(), &operation, &resultWriter,
+				WithAdditionalHttpHeaders(http.Header{"Authorization": []string{authorization}}))
+			require.NoError(t, err)
+			return resultWriter.String()
+		}
+
+		names["3"] = "Sergiy"
+		assert.Equal(t, `{"data":{"user":{"__typename":"User","id":"3","name":"Sergiy"}}}`, executeWithAdditionalHeaders(t, "a"))
+		names["3"] = "Nithin"
+		assert.Equal(t, `{"data":{"user":{"__typename":"User","id":"3","name":"Nithin"}}}`, executeWithAdditionalHeaders(t, "b"))
+		assert.Equal(t, 2, fetches, "responses of other users are not shared")
+
+		assert.Equal(t, `{"data":{"user":{"__typename":"User","id":"3","name":"Sergiy"}}}`, executeWithAdditionalHeaders(t, "a"))
+		assert.Equal(t, 2, fetches)
+	})
+
+	t.Run("mutation invalidation hook invalidates operation families", func(t *testing.T) {
+		fetches = 0
+		invalidateFamilies = true
+		invalidator.events = nil
+		execute(t, `mutation { renameUser(id: "2", name: "Yuri") { __typename id name } }`, "a")
+
+		assert.Equal(t, []CacheInvalidationEvent{
+			{Tags: []string{"User:2"}},
+			{OperationFamilies: []string{"Query.user"}},
+		}, invalidator.events)
+
+		assert.Equal(t, `{"data":{"user":{"__typename":"User","id":"1","name":"Dustin"}}}`, execute(t, userQuery("1"), "a"))
+		assert.Equal(t, 2, fetches, "responses of all user queries are invalidated")
+	})
+}
+
+type cacheInvalidator struct {
+	events []CacheInvalidationEvent
+}
+
+func (c *cacheInvalidator) Invalidate(event CacheInvalidationEvent) {
+	c.events = append(c.events, event)
+}
+
+func TestExecutionEngineV2_ReplicatedUpstream(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			hello: String
+		}
+
+		type Mutation {
+			setHello(hello: String!): String
+		}`)
+	require.NoError(t, err)
+
+	var hosts []string
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"hello"}},
+				{TypeName: "Mutation", FieldNames: []string{"setHello"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						hosts = append(hosts, req.URL.Host)
+						body := `{"data":{"hello":"world"}}`
+						if req.URL.Host == "primary.service" {
+							body = `{"data":{"hello":"world","setHello":"world"}}`
+						}
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(body))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    "https://primary.service/graphql",
+					Method: "POST",
+					Replication: replication.Configuration{
+						ReplicaURLs:      []string{"https://replica.service"},
+						StickinessWindow: time.Minute,
+					},
+				},
+			}),
+		},
+	})
+	engineConf.SetFieldConfigurations(plan.FieldConfigurations{
+		{
+			TypeName:  "Mutation",
+			FieldName: "setHello",
+			Arguments: []plan.ArgumentConfiguration{
+				{Name: "hello", SourceType: plan.FieldArgumentSource},
+			},
+		},
+	})
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	execute := func(t *testing.T, ctx context.Context, query string) {
+		t.Helper()
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(ctx, &Request{Query: query}, &resultWriter)
+		require.NoError(t, err)
+	}
+
+	client := replication.WithClientIdentity(context.Background(), "client-1")
+	otherClient := replication.WithClientIdentity(context.Background(), "client-2")
+
+	execute(t, client, `{ hello }`)
+	execute(t, client, `mutation { setHello(hello: "world") }`)
+	execute(t, client, `{ hello }`)
+	execute(t, otherClient, `{ hello }`)
+
+	assert.Equal(t, []string{"replica.service", "primary.service", "primary.service", "replica.service"}, hosts)
+}
+
+func TestExecutionEngineV2_FailoverUpstream(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			hello: String
+		}`)
+	require.NoError(t, err)
+
+	var hosts []string
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"hello"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						hosts = append(hosts, req.URL.Host)
+						if req.URL.Host == "eu.service" {
+							return &http.Response{StatusCode: 503, Body: ioutil.NopCloser(strings.NewReader(`unavailable`))}
+						}
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"hello":"world"}}`))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    "https://upstream/graphql",
+					Method: "POST",
+					Failover: failover.Configuration{
+						Endpoints:        []failover.Endpoint{{URL: "https://eu.service"}, {URL: "https://us.service"}},
+						FailureThreshold: 1,
+					},
+				},
+			}),
+		},
+	})
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		resultWriter := NewEngineResultWriter()
+		err = engine.Execute(context.Background(), &Request{Query: `{ hello }`}, &resultWriter)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"world"}}`, resultWriter.String())
+	}
+
+	assert.Equal(t, []string{"eu.service", "us.service", "us.service"}, hosts, "the circuit of the failed endpoint is open")
+
+	t.Run("can't be combined with replication", func(t *testing.T) {
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{
+					{TypeName: "Query", FieldNames: []string{"hello"}},
+				},
+				Factory: &graphql_datasource.Factory{HTTPClient: http.DefaultClient},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch: graphql_datasource.FetchConfiguration{
+						URL:         "https://upstream/graphql",
+						Method:      "POST",
+						Failover:    failover.Configuration{Endpoints: []failover.Endpoint{{URL: "https://eu.service"}}},
+						Replication: replication.Configuration{ReplicaURLs: []string{"https://replica.service"}},
+					},
+				}),
+			},
+		})
+
+		engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+
+		resultWriter := NewEngineResultWriter()
+		err = engine.Execute(context.Background(), &Request{Query: `{ hello }`}, &resultWriter)
+		assert.EqualError(t, err, "internal: "+failover.ErrCombinedWithReplication.Error())
+	})
+}
+
+func TestExecutionEngineV2_HedgedUpstream(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			hello: String
+		}
+		type Mutation {
+			hello: String
+		}`)
+	require.NoError(t, err)
+
+	var (
+		mu       sync.Mutex
+		attempts int
+	)
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"hello"}},
+				{TypeName: "Mutation", FieldNames: []string{"hello"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						mu.Lock()
+						attempts++
+						attempt := attempts
+						mu.Unlock()
+						if attempt == 1 {
+							// the first attempt is slow and cancelled once the hedge responded
+							select {
+							case <-req.Context().Done():
+							case <-time.After(100 * time.Millisecond):
+							}
+							return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"hello":"slow"}}`))}
+						}
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"hello":"hedge"}}`))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:     "https://upstream/graphql",
+					Method:  "POST",
+					Hedging: hedging.Configuration{Delay: 10 * time.Millisecond},
+				},
+			}),
+		},
+	})
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	t.Run("queries are hedged", func(t *testing.T) {
+		resultWriter := NewEngineResultWriter()
+		err = engine.Execute(context.Background(), &Request{Query: `{ hello }`}, &resultWriter)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"hedge"}}`, resultWriter.String())
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("mutations aren't hedged", func(t *testing.T) {
+		mu.Lock()
+		attempts = 0
+		mu.Unlock()
+
+		resultWriter := NewEngineResultWriter()
+		err = engine.Execute(context.Background(), &Request{Query: `mutation { hello }`}, &resultWriter)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"slow"}}`, resultWriter.String())
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func TestExecutionEngineV2_RequestExtensions(t *testing.T) {
+	schema, err := NewSchemaFromString(`type Query { hash: String }`)
+	require.NoError(t, err)
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{{TypeName: "Query", FieldNames: []string{"hash"}}},
+			Factory: &rest_datasource.Factory{
+				Client: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"hash":"` + req.URL.Query().Get("hash") + `"}`))}
+					}),
+				},
+			},
+			Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+				Fetch: rest_datasource.FetchConfiguration{URL: "https://upstream/?hash={{ .request.extensions.persistedQuery.sha256Hash }}", Method: "GET"},
+			}),
+		},
+	})
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	t.Run("renders extensions into fetch inputs", func(t *testing.T) {
+		resultWriter := NewEngineResultWriter()
+		err = engine.Execute(context.Background(), &Request{
+			Query:      `{ hash }`,
+			Extensions: []byte(`{"persistedQuery":{"version":1,"sha256Hash":"abc"}}`),
+		}, &resultWriter)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hash":"abc"}}`, resultWriter.String())
+	})
+
+	t.Run("renders missing extensions empty", func(t *testing.T) {
+		resultWriter := NewEngineResultWriter()
+		err = engine.Execute(context.Background(), &Request{Query: `{ hash }`}, &resultWriter)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hash":""}}`, resultWriter.String())
+	})
+
+	t.Run("invalid extensions", func(t *testing.T) {
+		resultWriter := NewEngineResultWriter()
+		err = engine.Execute(context.Background(), &Request{
+			Query:      `{ hash }`,
+			Extensions: []byte(`{"persistedQuery":"abc"}`),
+		}, &resultWriter)
+		assert.Error(t, err)
+	})
+}
+
+func TestExecutionEngineV2_RecordAndReplayFetches(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			users: [User]
+		}
+
+		type User {
+			id: ID!
+			address: Address
+		}
+
+		type Address {
+			city: String
+		}`)
+	require.NoError(t, err)
+
+	var upstreamCalls int64
+	upstream := &http.Client{
+		Transport: testRoundTripper(func(req *http.Request) *http.Response {
+			atomic.AddInt64(&upstreamCalls, 1)
+			body := `[{"id":"1"},{"id":"2"}]`
+			if strings.HasPrefix(req.URL.Path, "/addresses/") {
+				body = fmt.Sprintf(`{"city":"City of %s"}`, strings.TrimPrefix(req.URL.Path, "/addresses/"))
+			}
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(body))}
+		}),
+	}
+	dataSources := []plan.DataSourceConfiguration{
+		{
+			RootNodes:  []plan.TypeField{{TypeName: "Query", FieldNames: []string{"users"}}},
+			ChildNodes: []plan.TypeField{{TypeName: "User", FieldNames: []string{"id"}}},
+			Factory:    &rest_datasource.Factory{Client: upstream},
+			Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+				Fetch: rest_datasource.FetchConfiguration{URL: "https://users.service/users", Method: "GET"},
+			}),
+		},
+		{
+			RootNodes:  []plan.TypeField{{TypeName: "User", FieldNames: []string{"address"}}},
+			ChildNodes: []plan.TypeField{{TypeName: "Address", FieldNames: []string{"city"}}},
+			Factory:    &rest_datasource.Factory{Client: upstream},
+			Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+				Fetch: rest_datasource.FetchConfiguration{URL: "https://addresses.service/addresses/{{ .object.id }}", Method: "GET"},
+			}),
+		},
+	}
+	newEngine := func(t *testing.T, dataSources []plan.DataSourceConfiguration) *ExecutionEngineV2 {
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetDataSources(dataSources)
+		engineConf.SetFieldConfigurations(plan.FieldConfigurations{
+			{TypeName: "Query", FieldName: "users", DisableDefaultMapping: true},
+			{TypeName: "User", FieldName: "address", DisableDefaultMapping: true, RequiresFields: []string{"id"}},
+		})
+		engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine
+	}
+
+	query := `{ users { address { city } } }`
+	expected := `{"data":{"users":[{"address":{"city":"City of 1"}},{"address":{"city":"City of 2"}}]}}`
+
+	recorder := replay_datasource.NewRecorder(replay_datasource.BundleRequest{Query: query})
+	resultWriter := NewEngineResultWriter()
+	err = newEngine(t, dataSources).Execute(context.Background(), &Request{Query: query}, &resultWriter, WithFetchRecorder(recorder))
+	require.NoError(t, err)
+	assert.Equal(t, expected, resultWriter.String())
+
+	bundle := recorder.Bundle()
+	assert.Len(t, bundle.Fetches, 3)
+	assert.Equal(t, int64(3), atomic.LoadInt64(&upstreamCalls))
+
+	t.Run("replays the fetches without calling the upstreams", func(t *testing.T) {
+		resultWriter := NewEngineResultWriter()
+		err := newEngine(t, replay_datasource.Replay(dataSources, bundle)).Execute(context.Background(), &Request{Query: bundle.Request.Query}, &resultWriter)
+		require.NoError(t, err)
+		assert.Equal(t, expected, resultWriter.String())
+		assert.Equal(t, int64(3), atomic.LoadInt64(&upstreamCalls))
+	})
+}
+
+func TestExecutionEngineV2_UnionDataSource(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			search(term: String!): [Result]
+		}
+
+		type Result {
+			id: ID!
+			title: String
+			score: Int
+		}`)
+	require.NoError(t, err)
+
+	var (
+		mu               sync.Mutex
+		upstreamRequests = map[string]string{}
+	)
+	backend := func(response string) *graphql_datasource.Factory {
+		return &graphql_datasource.Factory{
+			HTTPClient: &http.Client{
+				Transport: testRoundTripper(func(req *http.Request) *http.Response {
+					body, _ := ioutil.ReadAll(req.Body)
+					mu.Lock()
+					upstreamRequests[req.URL.Host] = string(body)
+					mu.Unlock()
+					return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(response))}
+				}),
+			},
+		}
+	}
+	backendConfig := func(url string) json.RawMessage {
+		return graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+			Fetch: graphql_datasource.FetchConfiguration{URL: url, Method: "POST"},
+		})
+	}
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes:  []plan.TypeField{{TypeName: "Query", FieldNames: []string{"search"}}},
+			ChildNodes: []plan.TypeField{{TypeName: "Result", FieldNames: []string{"id", "title", "score"}}},
+			Factory: &union_datasource.Factory{
+				Sources: []union_datasource.SourceConfiguration{
+					{
+						Factory: backend(`{"data":{"search":[{"id":"1","title":"Docs","score":3},{"id":"2","title":"Blog","score":1}]}}`),
+						Custom:  backendConfig("https://docs.service/graphql"),
+					},
+					{
+						Factory: backend(`{"data":{"search":[{"id":"2","title":"Blog post","score":1},{"id":"3","title":"Forum","score":2}]}}`),
+						Custom:  backendConfig("https://forum.service/graphql"),
+					},
+				},
+			},
+			Custom: union_datasource.ConfigJSON(union_datasource.Configuration{
+				DedupKey:       "id",
+				SortBy:         "score",
+				SortDescending: true,
+			}),
+		},
+	})
+	engineConf.SetFieldConfigurations(plan.FieldConfigurations{
+		{
+			TypeName:  "Query",
+			FieldName: "search",
+			Arguments: []plan.ArgumentConfiguration{{Name: "term", SourceType: plan.FieldArgumentSource}},
+		},
+	})
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	resultWriter := NewEngineResultWriter()
+	err = engine.Execute(context.Background(), &Request{Query: `{ search(term: "graphql") { id title score } }`}, &resultWriter)
+	require.NoError(t, err)
+	assert.Equal(t, `{"data":{"search":[{"id":"1","title":"Docs","score":3},{"id":"3","title":"Forum","score":2},{"id":"2","title":"Blog","score":1}]}}`, resultWriter.String())
+	expectedRequest := `{"query":"query($a: String!){search(term: $a){id title score}}","variables":{"a":"graphql"}}`
+	assert.Equal(t, map[string]string{"docs.service": expectedRequest, "forum.service": expectedRequest}, upstreamRequests)
+}
+
+func TestExecutionEngineV2_RoutingDataSource(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			products(region: Region): [Product]
+		}
+
+		enum Region {
+			EU
+			US
+			APAC
+		}
+
+		type Product {
+			id: ID!
+			name: String
+		}`)
+	require.NoError(t, err)
+
+	var (
+		mu               sync.Mutex
+		upstreamRequests []string
+	)
+	backend := func(response string) *graphql_datasource.Factory {
+		return &graphql_datasource.Factory{
+			HTTPClient: &http.Client{
+				Transport: testRoundTripper(func(req *http.Request) *http.Response {
+					mu.Lock()
+					upstreamRequests = append(upstreamRequests, req.URL.Host)
+					mu.Unlock()
+					return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(response))}
+				}),
+			},
+		}
+	}
+	backendConfig := func(url string) json.RawMessage {
+		return graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+			Fetch: graphql_datasource.FetchConfiguration{URL: url, Method: "POST"},
+		})
+	}
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes:  []plan.TypeField{{TypeName: "Query", FieldNames: []string{"products"}}},
+			ChildNodes: []plan.TypeField{{TypeName: "Product", FieldNames: []string{"id", "name"}}},
+			Factory: &routing_datasource.Factory{
+				Routes: []routing_datasource.Route{
+					{
+						Values: []string{"EU"},
+						Source: routing_datasource.SourceConfiguration{
+							Factory: backend(`{"data":{"products":[{"id":"1","name":"Bratwurst"}]}}`),
+							Custom:  backendConfig("https://eu.products.service/graphql"),
+						},
+					},
+					{
+						Values: []string{"US"},
+						Source: routing_datasource.SourceConfiguration{
+							Factory: backend(`{"data":{"products":[{"id":"2","name":"Hot Dog"}]}}`),
+							Custom:  backendConfig("https://us.products.service/graphql"),
+						},
+					},
+				},
+				Default: &routing_datasource.SourceConfiguration{
+					Factory: backend(`{"data":{"products":[]}}`),
+					Custom:  backendConfig("https://products.service/graphql"),
+				},
+			},
+			Custom: routing_datasource.ConfigJSON(routing_datasource.Configuration{Argument: "region"}),
+		},
+	})
+	engineConf.SetFieldConfigurations(plan.FieldConfigurations{
+		{
+			TypeName:  "Query",
+			FieldName: "products",
+			Arguments: []plan.ArgumentConfiguration{{Name: "region", SourceType: plan.FieldArgumentSource}},
+		},
+	})
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	execute := func(t *testing.T, request *Request) string {
+		mu.Lock()
+		upstreamRequests = nil
+		mu.Unlock()
+		resultWriter := NewEngineResultWriter()
+		require.NoError(t, engine.Execute(context.Background(), request, &resultWriter))
+		return resultWriter.String()
+	}
+
+	const query = `query Products($region: Region) { products(region: $region) { id name } }`
+
+	t.Run("routes by the value of the variable", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"products":[{"id":"1","name":"Bratwurst"}]}}`, execute(t, &Request{Query: query, Variables: []byte(`{"region":"EU"}`)}))
+		assert.Equal(t, []string{"eu.products.service"}, upstreamRequests)
+
+		assert.Equal(t, `{"data":{"products":[{"id":"2","name":"Hot Dog"}]}}`, execute(t, &Request{Query: query, Variables: []byte(`{"region":"US"}`)}))
+		assert.Equal(t, []string{"us.products.service"}, upstreamRequests)
+	})
+
+	t.Run("routes by the value of the inline argument", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"products":[{"id":"2","name":"Hot Dog"}]}}`, execute(t, &Request{Query: `{ products(region: US) { id name } }`}))
+		assert.Equal(t, []string{"us.products.service"}, upstreamRequests)
+	})
+
+	t.Run("routes values without route to the default", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"products":[]}}`, execute(t, &Request{Query: query, Variables: []byte(`{"region":"APAC"}`)}))
+		assert.Equal(t, []string{"products.service"}, upstreamRequests)
+
+		assert.Equal(t, `{"data":{"products":[]}}`, execute(t, &Request{Query: query}))
+		assert.Equal(t, []string{"products.service"}, upstreamRequests)
+	})
+}
+
+func TestExecutionEngineV2_ComputedFields(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			user: User
+		}
+
+		type User {
+			firstName: String
+			lastName: String
+			age: Int
+			fullName: String!
+			label: String
+			years: Int
+		}`)
+	require.NoError(t, err)
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes:  []plan.TypeField{{TypeName: "Query", FieldNames: []string{"user"}}},
+			ChildNodes: []plan.TypeField{{TypeName: "User", FieldNames: []string{"firstName", "lastName", "age"}}},
+			Factory: &rest_datasource.Factory{
+				Client: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						body := `{"first_name":"Ada","lastName":"Love\"lace","age":36}`
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(body))}
+					}),
+				},
+			},
+			Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+				Fetch: rest_datasource.FetchConfiguration{URL: "https://users.service/user", Method: "GET"},
+			}),
+		},
+	})
+	engineConf.SetFieldConfigurations(plan.FieldConfigurations{
+		{TypeName: "Query", FieldName: "user", DisableDefaultMapping: true},
+		{TypeName: "User", FieldName: "firstName", Path: []string{"first_name"}},
+		{TypeName: "User", FieldName: "fullName", Computed: &plan.ComputedFieldConfiguration{Expression: `{{ .firstName }} {{ .lastName }}`}},
+		{TypeName: "User", FieldName: "label", Computed: &plan.ComputedFieldConfiguration{Expression: `"{{ .firstName }}" ({{ .age }})`}},
+		{TypeName: "User", FieldName: "years", Computed: &plan.ComputedFieldConfiguration{Expression: `{{ .age }}`}},
+	})
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	execute := func(t *testing.T, query string) string {
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: query}, &resultWriter)
+		require.NoError(t, err)
+		return resultWriter.String()
+	}
+
+	t.Run("computes fields from siblings which aren't selected", func(t *testing.T) {
+		assert.Equal(t,
+			`{"data":{"user":{"fullName":"Ada Love\"lace","label":"\"Ada\" (36)","years":36}}}`,
+			execute(t, `{ user { fullName label years } }`),
+		)
+	})
+
+	t.Run("computes fields next to selected siblings", func(t *testing.T) {
+		assert.Equal(t,
+			`{"data":{"user":{"age":36,"first":"Ada","fullName":"Ada Love\"lace"}}}`,
+			execute(t, `{ user { age first: firstName fullName } }`),
+		)
+	})
+}
+
+func TestExecutionEngineV2_OptionalFetches(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			user: User
+			trending: [String]
+			recommendations: [String]
+		}
+
+		type User {
+			name: String
+		}`)
+	require.NoError(t, err)
+
+	recommendations := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	// the upstream of the optional data source refuses connections
+	recommendations.Close()
+
+	var upstreamQueries []string
+	var mu sync.Mutex
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes:  []plan.TypeField{{TypeName: "Query", FieldNames: []string{"user", "trending"}}},
+			ChildNodes: []plan.TypeField{{TypeName: "User", FieldNames: []string{"name"}}},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						body, _ := ioutil.ReadAll(req.Body)
+						mu.Lock()
+						upstreamQueries = append(upstreamQueries, string(body))
+						mu.Unlock()
+						response := `{"data":{"user":{"name":"Ada"}}}`
+						if strings.Contains(string(body), "trending") {
+							response = `{"errors":[{"message":"trending unavailable"}],"data":{"trending":["graphql"]}}`
+						}
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(response))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{URL: "https://users.service"},
+			}),
+		},
+		{
+			RootNodes: []plan.TypeField{{TypeName: "Query", FieldNames: []string{"recommendations"}}},
+			Factory: &rest_datasource.Factory{
+				Client: http.DefaultClient,
+			},
+			Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+				Fetch: rest_datasource.FetchConfiguration{URL: recommendations.URL, Method: "GET"},
+			}),
+			Optional: true,
+		},
+	})
+	engineConf.SetFieldConfigurations(plan.FieldConfigurations{
+		{TypeName: "Query", FieldName: "trending", Optional: true},
+		{TypeName: "Query", FieldName: "recommendations", DisableDefaultMapping: true},
+	})
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	resultWriter := NewEngineResultWriter()
+	err = engine.Execute(context.Background(), &Request{Query: `{ user { name } trending recommendations }`}, &resultWriter)
+	require.NoError(t, err)
+
+	var response struct {
+		Data       json.RawMessage   `json:"data"`
+		Errors     []json.RawMessage `json:"errors"`
+		Extensions struct {
+			Warnings []resolve.ResponseWarning `json:"warnings"`
+		} `json:"extensions"`
+	}
+	require.NoError(t, json.Unmarshal(resultWriter.Bytes(), &response))
+	assert.Equal(t, `{"user":{"name":"Ada"},"trending":null,"recommendations":null}`, string(response.Data))
+	assert.Empty(t, response.Errors)
+
+	// the optional root field is fetched separately, so that its failure doesn't null its siblings
+	assert.ElementsMatch(t, []string{`{"query":"{user {name}}"}`, `{"query":"{trending}"}`}, upstreamQueries)
+
+	warnings := response.Extensions.Warnings
+	require.Len(t, warnings, 2)
+	sort.Slice(warnings, func(i, j int) bool {
+		return warnings[i].DataSource < warnings[j].DataSource
+	})
+	assert.Equal(t, resolve.ResponseWarning{Message: "trending unavailable", Path: "/data", DataSource: "graphql_datasource.Source"}, warnings[0])
+	assert.Equal(t, "/data", warnings[1].Path)
+	assert.Equal(t, "rest_datasource.Source", warnings[1].DataSource)
+	assert.Contains(t, warnings[1].Message, "connection refused")
+}
+
+func TestExecutionEngineV2_FallbackValues(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		scalar FallbackValue
+		directive @fallback(value: FallbackValue!) on FIELD_DEFINITION
+
+		type Query {
+			user: User
+		}
+
+		type User {
+			name: String
+			nickname: String
+			rating: Float @fallback(value: 0)
+			tags: [String] @fallback(value: [])
+		}`)
+	require.NoError(t, err)
+
+	var upstreamResponse string
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes:  []plan.TypeField{{TypeName: "Query", FieldNames: []string{"user"}}},
+			ChildNodes: []plan.TypeField{{TypeName: "User", FieldNames: []string{"name", "nickname", "rating", "tags"}}},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(upstreamResponse))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{URL: "https://users.service"},
+			}),
+		},
+	})
+	engineConf.SetFieldConfigurations(plan.FieldConfigurations{
+		{TypeName: "User", FieldName: "nickname", Fallback: []byte(`"anonymous"`)},
+	})
+	engineConf.EnableClientControlledNullability(true)
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	execute := func(t *testing.T, query string) string {
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: query}, &resultWriter)
+		require.NoError(t, err)
+		return resultWriter.String()
+	}
+
+	t.Run("renders fallbacks for null values", func(t *testing.T) {
+		upstreamResponse = `{"data":{"user":{"name":null,"nickname":null,"rating":null,"tags":null}}}`
+		assert.Equal(t,
+			`{"data":{"user":{"name":null,"nickname":"anonymous","rating":0,"tags":[]}}}`,
+			execute(t, `{ user { name nickname rating tags } }`),
+		)
+	})
+
+	t.Run("renders fallbacks for fields with upstream errors", func(t *testing.T) {
+		upstreamResponse = `{"errors":[{"message":"ratings unavailable","path":["user","rating"]}],"data":{"user":{"name":"Ada","nickname":"Ace","rating":null,"tags":["a"]}}}`
+		assert.Equal(t,
+			`{"errors":[{"message":"ratings unavailable","path":["user","rating"]}],"data":{"user":{"name":"Ada","nickname":"Ace","rating":0,"tags":["a"]}}}`,
+			execute(t, `{ user { name nickname rating tags } }`),
+		)
+	})
+
+	t.Run("doesn't render fallbacks for fields made non nullable by the client", func(t *testing.T) {
+		upstreamResponse = `{"data":{"user":{"rating":null}}}`
+		assert.Equal(t,
+			`{"data":{"user":null}}`,
+			execute(t, `{ user { rating! } }`),
+		)
+	})
+
+	t.Run("rejects fallbacks which aren't valid for the type of the field", func(t *testing.T) {
+		newInvalidEngine := func(t *testing.T, schemaSDL string, fields plan.FieldConfigurations) *ExecutionEngineV2 {
+			schema, err := NewSchemaFromString(schemaSDL)
+			require.NoError(t, err)
+			engineConf := NewEngineV2Configuration(schema)
+			engineConf.SetDataSources([]plan.DataSourceConfiguration{
+				{
+					RootNodes:  []plan.TypeField{{TypeName: "Query", FieldNames: []string{"user"}}},
+					ChildNodes: []plan.TypeField{{TypeName: "User", FieldNames: []string{"rating", "role"}}},
+					Factory:    &graphql_datasource.Factory{HTTPClient: http.DefaultClient},
+					Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+						Fetch: graphql_datasource.FetchConfiguration{URL: "https://users.service"},
+					}),
+				},
+			})
+			engineConf.SetFieldConfigurations(fields)
+			engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+			require.NoError(t, err)
+			return engine
+		}
+		executeWithError := func(t *testing.T, engine *ExecutionEngineV2, query string) error {
+			resultWriter := NewEngineResultWriter()
+			return engine.Execute(context.Background(), &Request{Query: query}, &resultWriter)
+		}
+
+		t.Run("value of another type", func(t *testing.T) {
+			engine := newInvalidEngine(t, `
+				scalar FallbackValue
+				directive @fallback(value: FallbackValue!) on FIELD_DEFINITION
+				type Query { user: User }
+				type User { rating: Float @fallback(value: "high") role: Role }
+				enum Role { ADMIN USER }`, nil)
+			assert.ErrorContains(t, executeWithError(t, engine, `{ user { rating } }`), "invalid fallback of field User.rating: high is not a valid Float")
+		})
+
+		t.Run("unknown enum value", func(t *testing.T) {
+			engine := newInvalidEngine(t, `
+				type Query { user: User }
+				type User { rating: Float role: Role }
+				enum Role { ADMIN USER }`, plan.FieldConfigurations{{TypeName: "User", FieldName: "role", Fallback: []byte(`"GUEST"`)}})
+			assert.ErrorContains(t, executeWithError(t, engine, `{ user { role } }`), "invalid fallback of field User.role: GUEST is not a valid Role")
+		})
+
+		t.Run("field of an object type", func(t *testing.T) {
+			engine := newInvalidEngine(t, `
+				type Query { user: User }
+				type User { rating: Float role: Role }
+				enum Role { ADMIN USER }`, plan.FieldConfigurations{{TypeName: "Query", FieldName: "user", Fallback: []byte(`{}`)}})
+			assert.ErrorContains(t, executeWithError(t, engine, `{ user { rating } }`), "fallbacks are only supported for scalar and enum types, not for User")
+		})
+	})
+}
+
+func TestExecutionEngineV2_SkipFetch(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			recommendations(userId: String): [String]
+			score(userId: String): Int!
+		}`)
+	require.NoError(t, err)
+
+	newEngine := func(t *testing.T, skipFetch *plan.SkipFetchConfiguration) *ExecutionEngineV2 {
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{{TypeName: "Query", FieldNames: []string{"recommendations", "score"}}},
+				Factory: &graphql_datasource.Factory{
+					HTTPClient: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"score":1}}`))}
+						}),
+					},
+				},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch: graphql_datasource.FetchConfiguration{URL: "https://recommendations.service"},
+				}),
+			},
+		})
+		engineConf.SetFieldConfigurations(plan.FieldConfigurations{
+			{
+				TypeName:  "Query",
+				FieldName: "score",
+				Arguments: []plan.ArgumentConfiguration{{Name: "userId", SourceType: plan.FieldArgumentSource}},
+				SkipFetch: skipFetch,
+			},
+		})
+		engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine
+	}
+
+	execute := func(engine *ExecutionEngineV2, query, variables string) (string, error) {
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: query, Variables: []byte(variables)}, &resultWriter)
+		return resultWriter.String(), err
+	}
+
+	t.Run("non-null field resolves to the default value if the fetch is skipped", func(t *testing.T) {
+		engine := newEngine(t, &plan.SkipFetchConfiguration{
+			Conditions:   []plan.SkipFetchCondition{{ArgumentName: "userId"}},
+			DefaultValue: []byte(`0`),
+		})
+		response, err := execute(engine, `query Score($userId: String) { score(userId: $userId) }`, `{}`)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"score":0}}`, response)
+
+		response, err = execute(engine, `query Score($userId: String) { score(userId: $userId) }`, `{"userId":"1"}`)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"score":1}}`, response)
+	})
+
+	t.Run("rejects non-null fields without default value", func(t *testing.T) {
+		engine := newEngine(t, &plan.SkipFetchConfiguration{
+			Conditions: []plan.SkipFetchCondition{{ArgumentName: "userId"}},
+		})
+		_, err := execute(engine, `{ score(userId: "1") }`, ``)
+		assert.ErrorContains(t, err, "invalid skip fetch configuration of field Query.score: non-null fields require a DefaultValue")
+	})
+}
+
+func TestExecutionEngineV2_NamespacedUpstream(t *testing.T) {
+	upstreamSchema := `
+		type Query { users: [Node] }
+		interface Node { id: ID! }
+		type User implements Node { id: ID! name: String }`
+	namespace := graphql_datasource.NamespaceConfiguration{FieldName: "users_service", TypePrefix: "Users_"}
+	namespacedSchema, err := graphql_datasource.NamespaceSchema(upstreamSchema, namespace)
+	require.NoError(t, err)
+	schema, err := NewSchemaFromString(namespacedSchema)
+	require.NoError(t, err)
+
+	var upstreamQueries []string
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"users_service"}},
+			},
+			ChildNodes: []plan.TypeField{
+				{TypeName: "Users_Query", FieldNames: []string{"users"}},
+				{TypeName: "Users_Node", FieldNames: []string{"id"}},
+				{TypeName: "Users_User", FieldNames: []string{"id", "name"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						body, _ := ioutil.ReadAll(req.Body)
+						upstreamQueries = append(upstreamQueries, string(body))
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"users":[{"__typename":"User","id":"1","name":"Jens"}]}}`))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    "https://users.service/graphql",
+					Method: "POST",
+				},
+				UpstreamSchema: upstreamSchema,
+				Namespace:      namespace,
+			}),
+		},
+	})
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	resultWriter := NewEngineResultWriter()
+	err = engine.Execute(context.Background(), &Request{Query: `{ users_service { __typename users { ... on Users_Node { __typename id } ... on Users_User { name } } } }`}, &resultWriter)
+	require.NoError(t, err)
+	assert.Equal(t, `{"data":{"users_service":{"__typename":"Users_Query","users":[{"__typename":"Users_User","id":"1","name":"Jens"}]}}}`, resultWriter.String())
+	assert.Equal(t, []string{`{"query":"{__typename users {__typename id ... on User {name}}}"}`}, upstreamQueries)
+}
+
+func TestExecutionEngineV2_MappedUpstream(t *testing.T) {
+	upstreamSchema := `
+		type Query { users: [User] }
+		type User { id: ID! name: String address: Address password: String }
+		type Address { city: String }`
+	mapping := graphql_datasource.SchemaMappingConfiguration{
+		Types: []graphql_datasource.TypeMapping{
+			{TypeName: "User", RenameTo: "Person"},
+		},
+		Fields: []graphql_datasource.FieldMapping{
+			{TypeName: "Query", FieldName: "users", RenameTo: "people"},
+			{TypeName: "User", FieldName: "name", RenameTo: "fullName"},
+			{TypeName: "User", FieldName: "password", Hidden: true},
+			{TypeName: "User", FieldName: "city", Path: []string{"address", "city"}},
+			{TypeName: "User", FieldName: "address", Hidden: true},
+		},
+	}
+	mappedSchema, err := graphql_datasource.MapSchema(upstreamSchema, mapping)
+	require.NoError(t, err)
+	schema, err := NewSchemaFromString(mappedSchema)
+	require.NoError(t, err)
+
+	var upstreamQueries []string
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"people"}},
+			},
+			ChildNodes: []plan.TypeField{
+				{TypeName: "Person", FieldNames: []string{"id", "fullName", "city"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						body, _ := ioutil.ReadAll(req.Body)
+						upstreamQueries = append(upstreamQueries, string(body))
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"people":[{"__typename":"User","id":"1","fullName":"Jens","city":{"city":"Berlin"}}]}}`))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    "https://users.service/graphql",
+					Method: "POST",
+				},
+				UpstreamSchema: upstreamSchema,
+				SchemaMapping:  mapping,
+			}),
+		},
+	})
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	resultWriter := NewEngineResultWriter()
+	err = engine.Execute(context.Background(), &Request{Query: `{ people { __typename id fullName city } }`}, &resultWriter)
+	require.NoError(t, err)
+	assert.Equal(t, `{"data":{"people":[{"__typename":"Person","id":"1","fullName":"Jens","city":"Berlin"}]}}`, resultWriter.String())
+	assert.Equal(t, []string{`{"query":"{people: users {__typename id fullName: name city: address {city}}}"}`}, upstreamQueries)
+}
+
+func TestExecutionEngineV2_RequestMetadata(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			me: String
+		}`)
+	require.NoError(t, err)
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"me"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						request, ok := resolve.RequestFromContext(req.Context())
+						require.True(t, ok)
+						sub, _ := request.Claims.String("sub")
+						tenant, _ := request.Values.String("tenant")
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"me":"` + tenant + `:` + sub + `"}}`))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    "https://upstream/graphql",
+					Method: "POST",
+				},
+			}),
+		},
+	})
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	metadata := resolve.NewContextBuilder().
+		WithClaims(map[string]interface{}{"sub": "user-1"}).
+		WithValue("tenant", "acme")
+	resultWriter := NewEngineResultWriter()
+	err = engine.Execute(context.Background(), &Request{Query: `{ me }`}, &resultWriter, WithRequestMetadata(metadata))
+	require.NoError(t, err)
+	assert.Equal(t, `{"data":{"me":"acme:user-1"}}`, resultWriter.String())
+}
+
+func TestExecutionEngineV2_OperationTransforms(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			hello(tenant: String): String
+		}`)
+	require.NoError(t, err)
+
+	var upstreamQueries []string
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"hello"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						body, _ := ioutil.ReadAll(req.Body)
+						upstreamQueries = append(upstreamQueries, string(body))
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"hello":"world"}}`))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    "https://upstream/graphql",
+					Method: "POST",
+				},
+			}),
+		},
+	})
+	engineConf.AddFieldConfiguration(plan.FieldConfiguration{
+		TypeName:  "Query",
+		FieldName: "hello",
+		Arguments: []plan.ArgumentConfiguration{
+			{Name: "tenant", SourceType: plan.FieldArgumentSource},
+		},
+	})
+	engineConf.AddOperationTransform(OperationTransformFunc(func(ctx context.Context, request *Request, operation, definition *ast.Document) error {
+		if request.Header().Get("X-Tenant") == "" {
+			return errors.New("missing tenant")
+		}
+		for i := range operation.Fields {
+			if operation.FieldNameString(i) == "hello" {
+				value := ast.Value{Kind: ast.ValueKindString, Ref: operation.ImportStringValue([]byte(request.Header().Get("X-Tenant")), false)}
+				operation.AddArgumentToField(i, operation.ImportArgument("tenant", value))
+			}
+		}
+		return nil
+	}))
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	t.Run("transforms the operation before planning", func(t *testing.T) {
+		request := &Request{Query: `{ hello }`}
+		request.SetHeader(http.Header{"X-Tenant": []string{"acme"}})
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), request, &resultWriter)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"world"}}`, resultWriter.String())
+		assert.Equal(t, []string{`{"query":"query($a: String){hello(tenant: $a)}","variables":{"a":"acme"}}`}, upstreamQueries)
+	})
+
+	t.Run("aborts the execution on errors", func(t *testing.T) {
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: `{ hello }`}, &resultWriter)
+		assert.EqualError(t, err, "missing tenant")
+	})
+}
+
+func TestExecutionEngineV2_FragmentArguments(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			hello(name: String): String
+		}`)
+	require.NoError(t, err)
+
+	newEngine := func(t *testing.T, fragmentArguments bool) (*ExecutionEngineV2, *[]string) {
+		var upstreamQueries []string
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{
+					{TypeName: "Query", FieldNames: []string{"hello"}},
+				},
+				Factory: &graphql_datasource.Factory{
+					HTTPClient: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							body, _ := ioutil.ReadAll(req.Body)
+							upstreamQueries = append(upstreamQueries, string(body))
+							return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"hello":"Hello Jens"}}`))}
+						}),
+					},
+				},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch: graphql_datasource.FetchConfiguration{
+						URL:    "https://upstream/graphql",
+						Method: "POST",
+					},
+				}),
+			},
+		})
+		engineConf.AddFieldConfiguration(plan.FieldConfiguration{
+			TypeName:  "Query",
+			FieldName: "hello",
+			Arguments: []plan.ArgumentConfiguration{
+				{Name: "name", SourceType: plan.FieldArgumentSource},
+			},
+		})
+		engineConf.EnableFragmentArguments(fragmentArguments)
+
+		engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine, &upstreamQueries
+	}
+
+	const query = `{ ...greeting(name: "Jens") } fragment greeting($name: String = "World") on Query { hello(name: $name) }`
+
+	t.Run("substitutes the fragment arguments", func(t *testing.T) {
+		engine, upstreamQueries := newEngine(t, true)
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: query}, &resultWriter)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"Hello Jens"}}`, resultWriter.String())
+		assert.Equal(t, []string{`{"query":"query($a: String){hello(name: $a)}","variables":{"a":"Jens"}}`}, *upstreamQueries)
+	})
+
+	t.Run("fragment arguments are disabled by default", func(t *testing.T) {
+		engine, upstreamQueries := newEngine(t, false)
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: query}, &resultWriter)
+		assert.Error(t, err)
+		assert.Empty(t, *upstreamQueries)
+	})
+}
+
+func TestExecutionEngineV2_ClientControlledNullability(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			user: User
+		}
+		type User {
+			id: ID!
+			name: String
+		}`)
+	require.NoError(t, err)
+
+	var upstreamQueries []string
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"user"}},
+			},
+			ChildNodes: []plan.TypeField{
+				{TypeName: "User", FieldNames: []string{"id", "name"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						body, _ := ioutil.ReadAll(req.Body)
+						upstreamQueries = append(upstreamQueries, string(body))
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"user":{"id":null,"name":null}}}`))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    "https://upstream/graphql",
+					Method: "POST",
+				},
+			}),
+		},
+	})
+	engineConf.EnableClientControlledNullability(true)
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	execute := func(query string) (string, error) {
+		upstreamQueries = nil
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: query}, &resultWriter)
+		return resultWriter.String(), err
+	}
+
+	t.Run("required field propagates null to the parent", func(t *testing.T) {
+		response, err := execute(`{ user { name! } }`)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"user":null}}`, response)
+		assert.Equal(t, []string{`{"query":"{user {name}}"}`}, upstreamQueries)
+	})
+
+	t.Run("optional field is null instead of propagating the error", func(t *testing.T) {
+		response, err := execute(`{ user { id? } }`)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"user":{"id":null}}}`, response)
+		assert.Equal(t, []string{`{"query":"{user {id}}"}`}, upstreamQueries)
+	})
+
+	t.Run("fields with differing designators can't be merged", func(t *testing.T) {
+		_, err := execute(`{ user { name! name } }`)
+		assert.Error(t, err)
+	})
+
+	t.Run("designators are disabled by default", func(t *testing.T) {
+		engineConf.EnableClientControlledNullability(false)
+		defaultEngine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+		resultWriter := NewEngineResultWriter()
+		err = defaultEngine.Execute(context.Background(), &Request{Query: `{ user { name! } }`}, &resultWriter)
+		assert.Error(t, err)
+	})
+}
+
+func TestExecutionEngineV2_DirectiveArgumentsFromVariables(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		directive @format(prefix: String, options: FormatOptions) on FIELD
+		directive @trace(options: TraceOptions) on FIELD
+		input FormatOptions {
+			uppercase: Boolean
+		}
+		input TraceOptions {
+			sampled: Boolean
+		}
+		type Query {
+			hello: String
+		}`)
+	require.NoError(t, err)
+
+	var upstreamQueries []string
+	var resolvedArguments []string
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"hello"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						body, _ := ioutil.ReadAll(req.Body)
+						upstreamQueries = append(upstreamQueries, string(body))
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"hello":"Hello"}}`))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    "https://upstream/graphql",
+					Method: "POST",
+				},
+			}),
+		},
+	})
+	engineConf.AddDirectiveMiddleware("format", resolve.DirectiveMiddlewareFunc(func(ctx resolve.HookContext, directive resolve.ResolvedDirective, value []byte) ([]byte, error) {
+		resolvedArguments = append(resolvedArguments, string(directive.Arguments))
+		var arguments struct {
+			Prefix  string `json:"prefix"`
+			Options struct {
+				Uppercase bool `json:"uppercase"`
+			} `json:"options"`
+		}
+		if err := json.Unmarshal(directive.Arguments, &arguments); err != nil {
+			return nil, err
+		}
+		var hello string
+		if err := json.Unmarshal(value, &hello); err != nil {
+			return nil, err
+		}
+		hello = arguments.Prefix + hello
+		if arguments.Options.Uppercase {
+			hello = strings.ToUpper(hello)
+		}
+		return json.Marshal(hello)
+	}))
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	execute := func(query, variables string) (string, error) {
+		upstreamQueries, resolvedArguments = nil, nil
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: query, Variables: []byte(variables)}, &resultWriter)
+		return resultWriter.String(), err
+	}
+
+	t.Run("directive middleware receives the arguments of each request", func(t *testing.T) {
+		const query = `query($prefix: String, $uppercase: Boolean) { hello @format(prefix: $prefix, options: {uppercase: $uppercase}) }`
+
+		response, err := execute(query, `{"prefix":"Well, ","uppercase":false}`)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"Well, Hello"}}`, response)
+		assert.Equal(t, []string{`{"prefix":"Well, ","options":{"uppercase":false}}`}, resolvedArguments)
+		assert.Equal(t, []string{`{"query":"{hello}"}`}, upstreamQueries)
+
+		response, err = execute(query, `{"prefix":"So, ","uppercase":true}`)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"SO, HELLO"}}`, response)
+		assert.Equal(t, []string{`{"prefix":"So, ","options":{"uppercase":true}}`}, resolvedArguments)
+	})
+
+	t.Run("skip and include are evaluated with the variables of each request", func(t *testing.T) {
+		const query = `query($skip: Boolean!) { hello @skip(if: $skip) }`
+
+		response, err := execute(query, `{"skip":true}`)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{}}`, response)
+
+		response, err = execute(query, `{"skip":false}`)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"Hello"}}`, response)
+	})
+
+	t.Run("forwards variables nested in the arguments of upstream directives", func(t *testing.T) {
+		response, err := execute(`query($sampled: Boolean) { hello @trace(options: {sampled: $sampled}) }`, `{"sampled":true}`)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"Hello"}}`, response)
+		assert.Equal(t, []string{`{"query":"query($sampled: Boolean){hello @trace(options: {sampled: $sampled})}","variables":{"sampled":true}}`}, upstreamQueries)
+	})
+}
+
+func TestExecutionEngineV2_MemoryLimit(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			hello: String
+		}`)
+	require.NoError(t, err)
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"hello"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"hello":"` + strings.Repeat("a", 1024) + `"}}`))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    "https://upstream/graphql",
+					Method: "POST",
+				},
+			}),
+		},
+	})
+	engineConf.SetMemoryLimit(512)
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	t.Run("aborts requests exceeding the limit", func(t *testing.T) {
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: `{ hello }`}, &resultWriter)
+		assert.ErrorIs(t, err, resolve.ErrMemoryLimitExceeded)
+	})
+
+	t.Run("limit can be overridden per request", func(t *testing.T) {
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: `{ hello }`}, &resultWriter, WithMemoryLimit(4096))
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"`+strings.Repeat("a", 1024)+`"}}`, resultWriter.String())
+	})
+}
+
+func TestExecutionEngineV2_MaxResponseSize(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			hello: String
+		}`)
+	require.NoError(t, err)
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"hello"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"hello":"` + strings.Repeat("a", 1024) + `"}}`))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    "https://upstream/graphql",
+					Method: "POST",
+				},
+			}),
+		},
+	})
+	engineConf.SetMaxResponseSize(512)
+
+	var truncatedPaths []string
+	engineConf.SetResponseSizeLimitHook(ResponseSizeLimitHookFunc(func(ctx context.Context, operation *Request, err *resolve.ResponseSizeLimitError) {
+		truncatedPaths = append(truncatedPaths, err.Path)
+	}))
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	t.Run("stops rendering responses exceeding the limit", func(t *testing.T) {
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: `{ hello }`}, &resultWriter)
+		assert.ErrorIs(t, err, resolve.ErrResponseSizeLimitExceeded)
+		assert.Equal(t, "", resultWriter.String())
+		assert.Equal(t, []string{"/data/hello"}, truncatedPaths)
+		assert.Equal(t, uint64(1), engine.TruncatedResponses())
+	})
+
+	t.Run("limit can be overridden per request", func(t *testing.T) {
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: `{ hello }`}, &resultWriter, WithMaxResponseSize(0))
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"`+strings.Repeat("a", 1024)+`"}}`, resultWriter.String())
+		assert.Equal(t, uint64(1), engine.TruncatedResponses())
+	})
+}
+
+func TestExecutionEngineV2_TimeoutBudget(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			hello: String
+		}`)
+	require.NoError(t, err)
+
+	var budgets []string
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"hello"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						budgets = append(budgets, req.Header.Get("X-Timeout-Ms"))
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"hello":"world"}}`))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    "https://upstream/graphql",
+					Method: "POST",
+				},
+			}),
+		},
+	})
+	engineConf.SetTimeoutBudget(httpclient.TimeoutBudget{Header: "X-Timeout-Ms", Reserve: time.Second})
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	t.Run("propagates the remaining budget to upstreams", func(t *testing.T) {
+		budgets = nil
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(ctx, &Request{Query: `{ hello }`}, &resultWriter)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"world"}}`, resultWriter.String())
+		require.Len(t, budgets, 1)
+		budget, err := strconv.Atoi(budgets[0])
+		require.NoError(t, err)
+		assert.InDelta(t, 59000, budget, 1000)
+	})
+
+	t.Run("doesn't fetch once the budget is exhausted", func(t *testing.T) {
+		budgets = nil
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+
+		resultWriter := NewEngineResultWriter()
+		_ = engine.Execute(ctx, &Request{Query: `{ hello }`}, &resultWriter)
+		assert.Empty(t, budgets)
+	})
+
+	t.Run("operations without deadline have no budget", func(t *testing.T) {
+		budgets = nil
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: `{ hello }`}, &resultWriter)
+		require.NoError(t, err)
+		assert.Equal(t, []string{""}, budgets)
+	})
+}
+
+func TestExecutionEngineV2_CompressedUpstreamResponses(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			hello: String
+		}`)
+	require.NoError(t, err)
+
+	response := `{"data":{"hello":"` + strings.Repeat("a", 1024) + `"}}`
+	compressed := &bytes.Buffer{}
+	encoder, err := zstd.NewWriter(compressed)
+	require.NoError(t, err)
+	_, err = encoder.Write([]byte(response))
+	require.NoError(t, err)
+	require.NoError(t, encoder.Close())
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"hello"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						assert.Equal(t, "zstd", req.Header.Get(httpclient.AcceptEncodingHeader))
+						return &http.Response{
+							StatusCode: 200,
+							Header:     http.Header{httpclient.ContentEncodingHeader: []string{"zstd"}},
+							Body:       ioutil.NopCloser(bytes.NewReader(compressed.Bytes())),
+						}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:             "https://upstream/graphql",
+					Method:          "POST",
+					AcceptEncodings: []string{"zstd"},
+				},
+			}),
+		},
+	})
+	engineConf.SetMaxResponseSize(2048)
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	t.Run("decompresses upstream responses", func(t *testing.T) {
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: `{ hello }`}, &resultWriter)
+		require.NoError(t, err)
+		assert.Equal(t, response, resultWriter.String())
+	})
+
+	t.Run("decompressed responses are limited to the max response size", func(t *testing.T) {
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: `{ hello }`}, &resultWriter, WithMaxResponseSize(512))
+		assert.ErrorIs(t, err, httpclient.ErrDecompressedSizeLimitExceeded)
+	})
+}
+
+func TestExecutionEngineV2_RESTEntities(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			users: [User]
+		}
+		type User {
+			id: ID!
+			name: String
+		}`)
+	require.NoError(t, err)
+
+	newEngine := func(t *testing.T, fetch rest_datasource.FetchConfiguration, requests *[]string) *ExecutionEngineV2 {
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.EnableDataLoader(true)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes:  []plan.TypeField{{TypeName: "Query", FieldNames: []string{"users"}}},
+				ChildNodes: []plan.TypeField{{TypeName: "User", FieldNames: []string{"id"}}},
+				Factory: &graphql_datasource.Factory{
+					HTTPClient: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"users":[{"id":"1"},{"id":"2"},{"id":"1"}]}}`))}
+						}),
+					},
+				},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch: graphql_datasource.FetchConfiguration{
+						URL:    "https://users.graphql",
+						Method: "POST",
+					},
+				}),
+			},
+			{
+				RootNodes: []plan.TypeField{{TypeName: "User", FieldNames: []string{"name"}}},
+				Entities:  []plan.EntityConfiguration{{TypeName: "User", KeyFields: []string{"id"}}},
+				Factory: &rest_datasource.Factory{
+					Client: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							*requests = append(*requests, req.URL.String())
+							if req.URL.Path == "/users" {
+								return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"users":[{"id":2,"name":"Two"},{"id":1,"name":"One"}]}`))}
+							}
+							id := strings.TrimPrefix(req.URL.Path, "/users/")
+							return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"id":"` + id + `","name":"User ` + id + `"}`))}
+						}),
+					},
+				},
+				Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{Fetch: fetch}),
+			},
+		})
+		engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine
+	}
+
+	t.Run("templates the representation into the url", func(t *testing.T) {
+		var requests []string
+		engine := newEngine(t, rest_datasource.FetchConfiguration{
+			URL:    "https://users.service/users/{{ .representation.id }}",
+			Method: "GET",
+		}, &requests)
+
+		resultWriter := NewEngineResultWriter()
+		require.NoError(t, engine.Execute(context.Background(), &Request{Query: `{ users { id name } }`}, &resultWriter))
+		assert.Equal(t, `{"data":{"users":[{"id":"1","name":"User 1"},{"id":"2","name":"User 2"},{"id":"1","name":"User 1"}]}}`, resultWriter.String())
+		assert.ElementsMatch(t, []string{"https://users.service/users/1", "https://users.service/users/2", "https://users.service/users/1"}, requests)
+	})
+
+	t.Run("resolves all representations with a single batch request", func(t *testing.T) {
+		var requests []string
+		engine := newEngine(t, rest_datasource.FetchConfiguration{
+			URL:    "https://users.service/users/{{ .representation.id }}",
+			Method: "GET",
+			Batch: &rest_datasource.BatchConfiguration{
+				URL:          "https://users.service/users",
+				Method:       "GET",
+				Query:        []rest_datasource.QueryConfiguration{{Name: "ids", Value: "{{ .representations.id }}"}},
+				ResponsePath: []string{"users"},
+			},
+		}, &requests)
+
+		resultWriter := NewEngineResultWriter()
+		require.NoError(t, engine.Execute(context.Background(), &Request{Query: `{ users { id name } }`}, &resultWriter))
+		assert.Equal(t, `{"data":{"users":[{"id":"1","name":"One"},{"id":"2","name":"Two"},{"id":"1","name":"One"}]}}`, resultWriter.String())
+		assert.Equal(t, []string{"https://users.service/users?ids=1%2C2"}, requests)
+	})
+}
+
+func TestExecutionEngineV2_MutationsAcrossDataSources(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		schema { query: Query mutation: Mutation }
+		type Query {
+			hello: String
+		}
+		type Mutation {
+			first: Result
+			second: Result
+			third: Result
+		}
+		type Result {
+			id: ID!
+			details: String
+		}`)
+	require.NoError(t, err)
+
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	mutationRoundTripper := func(upstream string) testRoundTripper {
+		return func(req *http.Request) *http.Response {
+			body, _ := ioutil.ReadAll(req.Body)
+			var fields []string
+			for _, field := range []string{"first", "second", "third"} {
+				if strings.Contains(string(body), field) {
+					fields = append(fields, `"`+field+`":{"id":"`+field+`"}`)
+					record(upstream + " " + field)
+				}
+			}
+			// give fetches started concurrently the chance to overtake this one
+			time.Sleep(5 * time.Millisecond)
+			return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{` + strings.Join(fields, ",") + `}}`))}
+		}
+	}
+
+	mutationDataSource := func(upstream string, fieldNames ...string) plan.DataSourceConfiguration {
+		return plan.DataSourceConfiguration{
+			RootNodes:  []plan.TypeField{{TypeName: "Mutation", FieldNames: fieldNames}},
+			ChildNodes: []plan.TypeField{{TypeName: "Result", FieldNames: []string{"id"}}},
+			Factory:    &graphql_datasource.Factory{HTTPClient: &http.Client{Transport: mutationRoundTripper(upstream)}},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{URL: "https://" + upstream, Method: "POST"},
+			}),
+		}
+	}
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		mutationDataSource("one", "first", "third"),
+		mutationDataSource("two", "second"),
+		{
+			RootNodes: []plan.TypeField{{TypeName: "Result", FieldNames: []string{"details"}}},
+			Entities:  []plan.EntityConfiguration{{TypeName: "Result", KeyFields: []string{"id"}}},
+			Factory: &rest_datasource.Factory{
+				Client: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						record("details " + strings.TrimPrefix(req.URL.Path, "/"))
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"details":"of ` + strings.TrimPrefix(req.URL.Path, "/") + `"}`))}
+					}),
+				},
+			},
+			Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+				Fetch: rest_datasource.FetchConfiguration{URL: "https://details/{{ .representation.id }}", Method: "GET"},
+			}),
+		},
+	})
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	t.Run("executes the root fields serially including their nested fetches", func(t *testing.T) {
+		events = nil
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: `mutation { first { id details } second { id details } third { id details } }`}, &resultWriter)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"first":{"id":"first","details":"of first"},"second":{"id":"second","details":"of second"},"third":{"id":"third","details":"of third"}}}`, resultWriter.String())
+		assert.Equal(t, []string{"one first", "details first", "two second", "details second", "one third", "details third"}, events)
+	})
+
+	t.Run("merges adjacent root fields of the same data source", func(t *testing.T) {
+		events = nil
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: `mutation { first { id } third { id } second { id } }`}, &resultWriter)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"first":{"id":"first"},"third":{"id":"third"},"second":{"id":"second"}}}`, resultWriter.String())
+		assert.Equal(t, []string{"one first", "one third", "two second"}, events)
+	})
+}
+
+func TestExecutionEngineV2_ValueMapping(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			users(filter: UserFilter): [User]
+		}
+		input UserFilter {
+			status: Status
+			nameContains: String
+		}
+		enum Status { ACTIVE INACTIVE }
+		type User {
+			name: String
+			status: Status
+			history: [Status]
+		}`)
+	require.NoError(t, err)
+
+	var upstreamBody string
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes:  []plan.TypeField{{TypeName: "Query", FieldNames: []string{"users"}}},
+			ChildNodes: []plan.TypeField{{TypeName: "User", FieldNames: []string{"name", "status", "history"}}},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						body, _ := ioutil.ReadAll(req.Body)
+						upstreamBody = string(body)
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(
+							`{"data":{"users":[{"name":"Jens","current":"STATUS_ACTIVE","history":["STATUS_INACTIVE","STATUS_ACTIVE"]}]}}`,
+						))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{URL: "https://users.service", Method: "POST"},
+				ValueMapping: graphql_datasource.ValueMappingConfiguration{
+					Enums: []graphql_datasource.EnumValueMapping{
+						{TypeName: "Status", Values: map[string]string{"ACTIVE": "STATUS_ACTIVE", "INACTIVE": "STATUS_INACTIVE"}},
+					},
+					InputFields: []graphql_datasource.InputFieldMapping{
+						{TypeName: "UserFilter", FieldName: "nameContains", UpstreamFieldName: "name_contains"},
+					},
+				},
+			}),
+		},
+	})
+	engineConf.SetFieldConfigurations([]plan.FieldConfiguration{
+		{
+			TypeName:  "Query",
+			FieldName: "users",
+			Arguments: []plan.ArgumentConfiguration{{Name: "filter", SourceType: plan.FieldArgumentSource}},
+		},
+	})
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	resultWriter := NewEngineResultWriter()
+	err = engine.Execute(context.Background(), &Request{Query: `{ users(filter: {status: ACTIVE, nameContains: "J"}) { name current: status history } }`}, &resultWriter)
+	require.NoError(t, err)
+	assert.Equal(t, `{"data":{"users":[{"name":"Jens","current":"ACTIVE","history":["INACTIVE","ACTIVE"]}]}}`, resultWriter.String())
+	assert.Contains(t, upstreamBody, `{"status":"STATUS_ACTIVE","name_contains":"J"}`)
+}
+
+func TestExecutionEngineV2_InjectArgumentDefaults(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			users(status: Status = ACTIVE, first: Int = 10): [User]
+		}
+		enum Status { ACTIVE INACTIVE }
+		type User {
+			name: String
+		}`)
+	require.NoError(t, err)
+
+	var upstreamBody string
+	newEngine := func(t *testing.T, injectArgumentDefaults bool) *ExecutionEngineV2 {
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes:  []plan.TypeField{{TypeName: "Query", FieldNames: []string{"users"}}},
+				ChildNodes: []plan.TypeField{{TypeName: "User", FieldNames: []string{"name"}}},
+				Factory: &graphql_datasource.Factory{
+					HTTPClient: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							body, _ := ioutil.ReadAll(req.Body)
+							upstreamBody = string(body)
+							return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"users":[{"name":"Jens"}]}}`))}
+						}),
+					},
+				},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch: graphql_datasource.FetchConfiguration{URL: "https://users.service", Method: "POST"},
+					// the upstream has a different default for the status and none for first
+					UpstreamSchema: `
+						type Query { users(status: Status = STATUS_INACTIVE, first: Int): [User] }
+						enum Status { STATUS_ACTIVE STATUS_INACTIVE }
+						type User { name: String }`,
+					ValueMapping: graphql_datasource.ValueMappingConfiguration{
+						Enums: []graphql_datasource.EnumValueMapping{
+							{TypeName: "Status", Values: map[string]string{"ACTIVE": "STATUS_ACTIVE", "INACTIVE": "STATUS_INACTIVE"}},
+						},
+					},
+					InjectArgumentDefaults: injectArgumentDefaults,
+				}),
+			},
+		})
+		engineConf.SetFieldConfigurations([]plan.FieldConfiguration{
+			{
+				TypeName:  "Query",
+				FieldName: "users",
+				Arguments: []plan.ArgumentConfiguration{
+					{Name: "status", SourceType: plan.FieldArgumentSource},
+					{Name: "first", SourceType: plan.FieldArgumentSource},
+				},
+			},
+		})
+		engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine
+	}
+
+	execute := func(t *testing.T, engine *ExecutionEngineV2, request *Request) {
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), request, &resultWriter)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"users":[{"name":"Jens"}]}}`, resultWriter.String())
+	}
+
+	t.Run("sets the argument defaults as defaults of the upstream variables", func(t *testing.T) {
+		engine := newEngine(t, true)
+		execute(t, engine, &Request{Query: `query Users($status: Status, $first: Int) { users(status: $status, first: $first) { name } }`})
+		assert.Equal(t, `{"query":"query($status: Status = STATUS_ACTIVE, $first: Int = 10){users(status: $status, first: $first){name}}","variables":{}}`, upstreamBody)
+
+		execute(t, engine, &Request{
+			Query:     `query Users($status: Status, $first: Int) { users(status: $status, first: $first) { name } }`,
+			Variables: []byte(`{"status":"INACTIVE","first":5}`),
+		})
+		assert.Equal(t, `{"query":"query($status: Status = STATUS_ACTIVE, $first: Int = 10){users(status: $status, first: $first){name}}","variables":{"first":5,"status":"STATUS_INACTIVE"}}`, upstreamBody)
+	})
+
+	t.Run("sends the defaults of the operation as variables", func(t *testing.T) {
+		execute(t, newEngine(t, true), &Request{Query: `query Users($first: Int = 20) { users(first: $first) { name } }`})
+		assert.Equal(t, `{"query":"query($a: Status = STATUS_ACTIVE, $first: Int = 10){users(status: $a, first: $first){name}}","variables":{"first":20,"a":"STATUS_ACTIVE"}}`, upstreamBody)
+	})
+
+	t.Run("leaves the defaults to the upstream by default", func(t *testing.T) {
+		execute(t, newEngine(t, false), &Request{Query: `query Users($status: Status, $first: Int) { users(status: $status, first: $first) { name } }`})
+		assert.Equal(t, `{"query":"query($status: Status, $first: Int){users(status: $status, first: $first){name}}","variables":{}}`, upstreamBody)
+	})
+}
+
+func TestExecutionEngineV2_SchemaViews(t *testing.T) {
+	schema, err := NewSchemaFromString(schemaViewTestSchema)
+	require.NoError(t, err)
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.AddSchemaView(SchemaViewConfig{Name: "public", ExcludeDirectives: []string{"internal"}})
+	engineConf.SetSchemaViewSelector(SchemaViewSelectorFunc(func(ctx context.Context, operation *Request) string {
+		if operation.Header().Get("X-Role") == "admin" {
+			return ""
+		}
+		return "public"
+	}))
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	execute := func(role, query string) (string, error) {
+		request := &Request{Query: query}
+		request.SetHeader(http.Header{"X-Role": []string{role}})
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), request, &resultWriter)
+		return resultWriter.String(), err
+	}
+
+	t.Run("validates against the selected view", func(t *testing.T) {
+		_, err := execute("user", `{ secret }`)
+		assert.EqualError(t, err, "field: secret not defined on type: Query, locations: [], path: [query,secret]")
+
+		_, err = execute("admin", `{ secret }`)
+		assert.NoError(t, err)
+	})
+
+	t.Run("introspects the selected view", func(t *testing.T) {
+		response, err := execute("user", `{ __type(name: "Query") { fields { name } } }`)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"__type":{"fields":[{"name":"hello"},{"name":"users"}]}}}`, response)
+
+		response, err = execute("admin", `{ __type(name: "Query") { fields { name } } }`)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"__type":{"fields":[{"name":"hello"},{"name":"secret"},{"name":"users"},{"name":"admin"}]}}}`, response)
+	})
+
+	t.Run("rejects introspection mixed with other root fields in a view", func(t *testing.T) {
+		_, err := execute("user", `{ hello __schema { queryType { name } } }`)
+		assert.ErrorIs(t, err, ErrSchemaViewMixedIntrospection)
+	})
+
+	t.Run("unknown views are an error", func(t *testing.T) {
+		conf := NewEngineV2Configuration(schema)
+		conf.SetSchemaViewSelector(SchemaViewSelectorFunc(func(ctx context.Context, operation *Request) string {
+			return "unknown"
+		}))
+		engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, conf)
+		require.NoError(t, err)
+
+		resultWriter := NewEngineResultWriter()
+		err = engine.Execute(context.Background(), &Request{Query: `{ hello }`}, &resultWriter)
+		assert.ErrorIs(t, err, ErrSchemaViewNotFound)
+	})
+}
+
+func TestExecutionEngineV2_HealthChecks(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			hello: String
+			world: String
+		}`)
+	require.NoError(t, err)
+
+	dataSource := func(fieldName, url string, optional bool) plan.DataSourceConfiguration {
+		return plan.DataSourceConfiguration{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{fieldName}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						body := fmt.Sprintf(`{"data":{"%s":"%s"}}`, fieldName, fieldName)
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(body))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    url,
+					Method: "POST",
+				},
+			}),
+			Optional:    optional,
+			HealthCheck: &plan.HealthCheckConfiguration{URL: url, Query: "{ __typename }", Interval: time.Hour},
+		}
+	}
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		dataSource("hello", "https://hello.service/graphql", false),
+		dataSource("world", "https://world.service/graphql", true),
+	})
+	engineConf.SetHealthCheckClient(&http.Client{
+		Transport: testRoundTripper(func(req *http.Request) *http.Response {
+			statusCode := 200
+			if req.URL.Host == "world.service" {
+				statusCode = 503
+			}
+			return &http.Response{StatusCode: statusCode, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"__typename":"Query"}}`))}
+		}),
+	})
+	changes := make(chan DataSourceHealthStatus, 2)
+	engineConf.SetHealthCheckHook(HealthCheckHookFunc(func(status DataSourceHealthStatus) {
+		changes <- status
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	engine, err := NewExecutionEngineV2(ctx, logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-changes:
+		case <-time.After(time.Second):
+			t.Fatal("health checks did not complete")
+		}
+	}
+
+	assert.NoError(t, engine.Readiness())
+	statuses := engine.HealthStatus()
+	require.Len(t, statuses, 2)
+	assert.True(t, statuses[0].Healthy)
+	assert.False(t, statuses[1].Healthy)
+	assert.True(t, statuses[1].Optional)
+
+	resultWriter := NewEngineResultWriter()
+	err = engine.Execute(context.Background(), &Request{Query: `{ hello world }`}, &resultWriter)
+	require.NoError(t, err)
+	assert.Equal(t, `{"data":{"hello":"hello","world":null}}`, resultWriter.String(), "unhealthy optional data sources are excluded")
+}
+
+func TestExecutionEngineV2_AbandonedOperations(t *testing.T) {
+	schema := starwarsSchema(t)
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{
+					TypeName:   "Query",
+					FieldNames: []string{"hero"},
+				},
+			},
+			ChildNodes: []plan.TypeField{
+				{
+					TypeName:   "Character",
+					FieldNames: []string{"name"},
+				},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: testNetHttpClient(t, roundTripperTestCase{
+					expectedHost:     "example.com",
+					expectedPath:     "/",
+					expectedBody:     "",
+					sendResponseBody: `{"data":{"hero":{"name":"Luke Skywalker"}}}`,
+					sendStatusCode:   200,
+				}),
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    "https://example.com/",
+					Method: "GET",
+				},
+			}),
+		},
+	})
+	hook := &abandonedOperationHook{}
+	engineConf.SetAbandonedOperationHook(hook)
+
+	engineCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	engine, err := NewExecutionEngineV2(engineCtx, logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	t.Run("completed operation is not abandoned", func(t *testing.T) {
+		operation := loadStarWarsQuery(starwars.FileSimpleHeroQuery, nil)(t)
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &operation, &resultWriter)
+		require.NoError(t, err)
+
+		assert.Equal(t, `{"data":{"hero":{"name":"Luke Skywalker"}}}`, resultWriter.String())
+		assert.Equal(t, uint64(0), engine.AbandonedOperations())
+		assert.Len(t, hook.abandoned, 0)
+	})
+
+	t.Run("operation of disconnected client is abandoned without fetching", func(t *testing.T) {
+		clientCtx, clientCancel := context.WithCancel(context.Background())
+		clientCancel()
+
+		before := &beforeFetchHook{}
+		operation := loadStarWarsQuery(starwars.FileSimpleHeroQuery, nil)(t)
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(clientCtx, &operation, &resultWriter, WithBeforeFetchHook(before))
+		assert.ErrorIs(t, err, context.Canceled)
+
+		assert.Equal(t, "", before.input)
+		assert.Equal(t, uint64(1), engine.AbandonedOperations())
+		assert.Equal(t, []string{operation.Query}, hook.abandoned)
+		assert.ErrorIs(t, hook.err, context.Canceled)
+	})
+}
+
+func TestExecutionEngineV2_GetCachedPlan(t *testing.T) {
+	schema, err := NewSchemaFromString(testSubscriptionDefinition)
+	require.NoError(t, err)
+
+	gqlRequest := Request{
+		OperationName: "LastRegisteredUser",
+		Variables:     nil,
+		Query:         testSubscriptionLastRegisteredUserOperation,
+	}
+
+	validationResult, err := gqlRequest.ValidateForSchema(schema)
+	require.NoError(t, err)
+	require.True(t, validationResult.Valid)
+
+	normalizationResult, err := gqlRequest.Normalize(schema)
+	require.NoError(t, err)
+	require.True(t, normalizationResult.Successful)
+
+	differentGqlRequest := Request{
+		OperationName: "LiveUserCount",
+		Variables:     nil,
+		Query:         testSubscriptionLiveUserCountOperation,
+	}
+
+	validationResult, err = differentGqlRequest.ValidateForSchema(schema)
+	require.NoError(t, err)
+	require.True(t, validationResult.Valid)
+
+	normalizationResult, err = differentGqlRequest.Normalize(schema)
+	require.NoError(t, err)
+	require.True(t, normalizationResult.Successful)
+
+	engineConfig := NewEngineV2Configuration(schema)
+	engineConfig.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{
+					TypeName:   "Subscription",
+					FieldNames: []string{"lastRegisteredUser", "liveUserCount"},
+				},
+			},
+			ChildNodes: []plan.TypeField{
+				{
+					TypeName:   "User",
+					FieldNames: []string{"id", "username", "email"},
+				},
+			},
+			Factory: &graphql_datasource.Factory{},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Subscription: graphql_datasource.SubscriptionConfiguration{
+					URL: "http://localhost:8080",
+				},
+			}),
+		},
+	})
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.NoopLogger, engineConfig)
+	require.NoError(t, err)
+
+	t.Run("should reuse cached plan", func(t *testing.T) {
+		t.Cleanup(engine.executionPlanCache.Purge)
+		require.Equal(t, 0, engine.executionPlanCache.Len())
+
+		firstInternalExecCtx := newInternalExecutionContext()
+		firstInternalExecCtx.resolveContext.Request.Header = http.Header{
+			http.CanonicalHeaderKey("Authorization"): []string{"123abc"},
+		}
+
+		report := operationreport.Report{}
+		cachedPlan := engine.getCachedPlan(firstInternalExecCtx, &gqlRequest.document, &schema.document, gqlRequest.OperationName, &report)
+		_, oldestCachedPlan, _ := engine.executionPlanCache.GetOldest()
+		assert.False(t, report.HasErrors())
+		assert.Equal(t, 1, engine.executionPlanCache.Len())
+		assert.Equal(t, cachedPlan, oldestCachedPlan.(*plan.SubscriptionResponsePlan))
+
+		secondInternalExecCtx := newInternalExecutionContext()
+		secondInternalExecCtx.resolveContext.Request.Header = http.Header{
+			http.CanonicalHeaderKey("Authorization"): []string{"123abc"},
+		}
+
+		cachedPlan = engine.getCachedPlan(secondInternalExecCtx, &gqlRequest.document, &schema.document, gqlRequest.OperationName, &report)
+		_, oldestCachedPlan, _ = engine.executionPlanCache.GetOldest()
+		assert.False(t, report.HasErrors())
+		assert.Equal(t, 1, engine.executionPlanCache.Len())
+		assert.Equal(t, cachedPlan, oldestCachedPlan.(*plan.SubscriptionResponsePlan))
+	})
+
+	t.Run("should create new plan and cache it", func(t *testing.T) {
+		t.Cleanup(engine.executionPlanCache.Purge)
+		require.Equal(t, 0, engine.executionPlanCache.Len())
+
+		firstInternalExecCtx := newInternalExecutionContext()
+		firstInternalExecCtx.resolveContext.Request.Header = http.Header{
+			http.CanonicalHeaderKey("Authorization"): []string{"123abc"},
+		}
+
+		report := operationreport.Report{}
+		cachedPlan := engine.getCachedPlan(firstInternalExecCtx, &gqlRequest.document, &schema.document, gqlRequest.OperationName, &report)
+		_, oldestCachedPlan, _ := engine.executionPlanCache.GetOldest()
+		assert.False(t, report.HasErrors())
+		assert.Equal(t, 1, engine.executionPlanCache.Len())
+		assert.Equal(t, cachedPlan, oldestCachedPlan.(*plan.SubscriptionResponsePlan))
+
+		secondInternalExecCtx := newInternalExecutionContext()
 		secondInternalExecCtx.resolveContext.Request.Header = http.Header{
 			http.CanonicalHeaderKey("Authorization"): []string{"xyz098"},
 		}
@@ -1933,6 +4811,67 @@ func TestExecutionEngineV2_GetCachedPlan(t *testing.T) {
 	})
 }
 
+func TestExecutionEngineV2_GetCachedPlan_SkipIncludeFolding(t *testing.T) {
+	schema, err := NewSchemaFromString(`schema { query: Query } type Query { hello: String world: String }`)
+	require.NoError(t, err)
+
+	normalizedRequest := func(variables string) Request {
+		request := Request{
+			OperationName: "Greeting",
+			Variables:     []byte(variables),
+			Query:         `query Greeting($skipWorld: Boolean!) { hello world @skip(if: $skipWorld) }`,
+		}
+		normalizationResult, err := request.Normalize(schema)
+		require.NoError(t, err)
+		require.True(t, normalizationResult.Successful)
+		return request
+	}
+
+	engineConfig := NewEngineV2Configuration(schema)
+	engineConfig.EnableSkipIncludeFolding(true)
+	engineConfig.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{
+					TypeName:   "Query",
+					FieldNames: []string{"hello", "world"},
+				},
+			},
+			Factory: &graphql_datasource.Factory{},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL: "http://localhost:8080",
+				},
+			}),
+		},
+	})
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.NoopLogger, engineConfig)
+	require.NoError(t, err)
+
+	upstreamInput := func(p plan.Plan) string {
+		return string(p.(*plan.SynchronousResponsePlan).Response.Data.(*resolve.Object).Fetch.(*resolve.SingleFetch).InputTemplate.Segments[0].Data)
+	}
+
+	report := operationreport.Report{}
+	skipRequest := normalizedRequest(`{"skipWorld":true}`)
+	skipPlan := engine.getCachedPlan(newInternalExecutionContext(), &skipRequest.document, &schema.document, skipRequest.OperationName, &report)
+	require.False(t, report.HasErrors())
+	assert.Equal(t, `{"method":"POST","url":"http://localhost:8080","body":{"query":"{hello}"}}`, upstreamInput(skipPlan))
+
+	includeRequest := normalizedRequest(`{"skipWorld":false}`)
+	includePlan := engine.getCachedPlan(newInternalExecutionContext(), &includeRequest.document, &schema.document, includeRequest.OperationName, &report)
+	require.False(t, report.HasErrors())
+	assert.Equal(t, `{"method":"POST","url":"http://localhost:8080","body":{"query":"{hello world}"}}`, upstreamInput(includePlan))
+	assert.Equal(t, 2, engine.executionPlanCache.Len())
+
+	secondSkipRequest := normalizedRequest(`{"skipWorld":true}`)
+	cachedPlan := engine.getCachedPlan(newInternalExecutionContext(), &secondSkipRequest.document, &schema.document, secondSkipRequest.OperationName, &report)
+	require.False(t, report.HasErrors())
+	assert.Equal(t, 2, engine.executionPlanCache.Len())
+	assert.Equal(t, skipPlan, cachedPlan)
+}
+
 func BenchmarkExecutionEngineV2(b *testing.B) {
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -1967,7 +4906,7 @@ func BenchmarkExecutionEngineV2(b *testing.B) {
 			},
 		})
 
-		engine, err := NewExecutionEngineV2(ctx, abstractlogger.NoopLogger, engineConf)
+		engine, err := NewExecutionEngineV2(ctx, logging.NoopLogger, engineConf)
 		require.NoError(b, err)
 
 		return engine
@@ -2265,7 +5204,7 @@ func newFederationEngine(ctx context.Context, setup *federationSetup, enableData
 	engineConfig.SetFieldConfigurations(fieldConfigs)
 	engineConfig.EnableDataLoader(enableDataLoader)
 
-	engine, err = NewExecutionEngineV2(ctx, abstractlogger.Noop{}, engineConfig)
+	engine, err = NewExecutionEngineV2(ctx, logging.Noop{}, engineConfig)
 	if err != nil {
 		return
 	}
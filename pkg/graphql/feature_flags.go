@@ -0,0 +1,44 @@
+package graphql
+
+import (
+	"math/rand"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+)
+
+// FeatureFlagRollout enables the feature flag Name for a random Percentage of the requests,
+// e.g. to route a share of the traffic to a new DataSource with plan.DataSourceConfiguration.FeatureFlag
+type FeatureFlagRollout struct {
+	Name string
+	// Percentage of the requests with the feature flag enabled, from 0 to 100
+	Percentage int
+}
+
+// WithFeatureFlags enables the feature flags for the request in addition to the flags of the rollouts, see EngineV2Configuration.SetFeatureFlagRollouts
+func WithFeatureFlags(flags ...string) ExecutionOptionsV2 {
+	return func(ctx *internalExecutionContext) {
+		ctx.resolveContext.Request.FeatureFlags = ctx.resolveContext.Request.FeatureFlags.Enable(flags...)
+	}
+}
+
+func rollOutFeatureFlags(flags resolve.FeatureFlags, rollouts []FeatureFlagRollout) resolve.FeatureFlags {
+	for i := range rollouts {
+		if rollouts[i].Percentage > 0 && rand.Intn(100) < rollouts[i].Percentage {
+			flags = flags.Enable(rollouts[i].Name)
+		}
+	}
+	return flags
+}
+
+// plannedFeatureFlags returns the sorted feature flags of the data sources which are enabled,
+// plans depend on these flags only
+func plannedFeatureFlags(dataSources []plan.DataSourceConfiguration, flags resolve.FeatureFlags) resolve.FeatureFlags {
+	var planned resolve.FeatureFlags
+	for i := range dataSources {
+		if dataSources[i].FeatureFlag != "" && flags.Enabled(dataSources[i].FeatureFlag) {
+			planned = planned.Enable(dataSources[i].FeatureFlag)
+		}
+	}
+	return planned.Sorted()
+}
@@ -0,0 +1,111 @@
+package graphql
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/rest_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestExecutionEngineV2_FeatureFlags(t *testing.T) {
+	schema, err := NewSchemaFromString(`type Query { hello: String beta: String }`)
+	require.NoError(t, err)
+
+	helloDataSource := func(upstream, featureFlag string) plan.DataSourceConfiguration {
+		return plan.DataSourceConfiguration{
+			RootNodes: []plan.TypeField{{TypeName: "Query", FieldNames: []string{"hello"}}},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"hello":"` + upstream + `"}}`))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{URL: "https://" + upstream, Method: "POST"},
+			}),
+			FeatureFlag: featureFlag,
+		}
+	}
+
+	newEngineConfiguration := func(rollouts ...FeatureFlagRollout) EngineV2Configuration {
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			helloDataSource("new", "new-hello"),
+			helloDataSource("old", ""),
+			{
+				RootNodes: []plan.TypeField{{TypeName: "Query", FieldNames: []string{"beta"}}},
+				Factory: &rest_datasource.Factory{
+					Client: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"beta":"` + req.URL.Query().Get("beta") + `"}`))}
+						}),
+					},
+				},
+				Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+					Fetch: rest_datasource.FetchConfiguration{URL: "https://beta/?beta={{ .request.flags.beta }}", Method: "GET"},
+				}),
+			},
+		})
+		engineConf.SetFeatureFlagRollouts(rollouts...)
+		return engineConf
+	}
+
+	newEngineFromConfiguration := func(t *testing.T, engineConf EngineV2Configuration) *ExecutionEngineV2 {
+		engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine
+	}
+
+	newEngine := func(t *testing.T, rollouts ...FeatureFlagRollout) *ExecutionEngineV2 {
+		return newEngineFromConfiguration(t, newEngineConfiguration(rollouts...))
+	}
+
+	execute := func(t *testing.T, engine *ExecutionEngineV2, query string, options ...ExecutionOptionsV2) string {
+		resultWriter := NewEngineResultWriter()
+		require.NoError(t, engine.Execute(context.Background(), &Request{Query: query}, &resultWriter, options...))
+		return resultWriter.String()
+	}
+
+	t.Run("plans the data source of an enabled feature flag", func(t *testing.T) {
+		engine := newEngine(t)
+		assert.Equal(t, `{"data":{"hello":"old"}}`, execute(t, engine, `{ hello }`))
+		assert.Equal(t, `{"data":{"hello":"new"}}`, execute(t, engine, `{ hello }`, WithFeatureFlags("new-hello")))
+		assert.Equal(t, `{"data":{"hello":"old"}}`, execute(t, engine, `{ hello }`, WithFeatureFlags("unrelated")))
+	})
+
+	t.Run("rolls out feature flags to a percentage of the requests", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"hello":"new"}}`, execute(t, newEngine(t, FeatureFlagRollout{Name: "new-hello", Percentage: 100}), `{ hello }`))
+		assert.Equal(t, `{"data":{"hello":"old"}}`, execute(t, newEngine(t, FeatureFlagRollout{Name: "new-hello", Percentage: 0}), `{ hello }`))
+	})
+
+	t.Run("renders feature flags into fetch inputs", func(t *testing.T) {
+		engine := newEngine(t)
+		assert.Equal(t, `{"data":{"beta":"false"}}`, execute(t, engine, `{ beta }`))
+		assert.Equal(t, `{"data":{"beta":"true"}}`, execute(t, engine, `{ beta }`, WithFeatureFlags("beta")))
+	})
+
+	t.Run("caches the responses of feature flag variants separately", func(t *testing.T) {
+		engineConf := newEngineConfiguration()
+		backend, err := NewInMemoryResponseCacheBackend(InMemoryResponseCacheBackendConfig{MaxSize: 10})
+		require.NoError(t, err)
+		engineConf.SetResponseCache(NewResponseCache(backend, ResponseCacheConfig{}))
+		engine := newEngineFromConfiguration(t, engineConf)
+
+		assert.Equal(t, `{"data":{"hello":"old"}}`, execute(t, engine, `{ hello }`))
+		assert.Equal(t, `{"data":{"hello":"new"}}`, execute(t, engine, `{ hello }`, WithFeatureFlags("new-hello")))
+		assert.Equal(t, `{"data":{"hello":"old"}}`, execute(t, engine, `{ hello }`))
+		assert.Equal(t, `{"data":{"beta":"true"}}`, execute(t, engine, `{ beta }`, WithFeatureFlags("beta", "new-hello")))
+		assert.Equal(t, `{"data":{"beta":"true"}}`, execute(t, engine, `{ beta }`, WithFeatureFlags("new-hello", "beta")))
+		assert.Equal(t, `{"data":{"beta":"false"}}`, execute(t, engine, `{ beta }`))
+	})
+}
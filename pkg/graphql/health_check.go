@@ -0,0 +1,287 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+const (
+	defaultHealthCheckInterval         = 10 * time.Second
+	defaultHealthCheckFailureThreshold = 1
+	maxHealthCheckResponseSize         = 1 << 20
+)
+
+var ErrNotReady = errors.New("engine is not ready")
+
+// DataSourceHealthStatus is the result of the latest health checks of a DataSource
+type DataSourceHealthStatus struct {
+	// Name is the HealthCheckConfiguration.Name or URL of the DataSource
+	Name string
+	// Optional is true for Optional DataSources, which are excluded from plans while they are unhealthy
+	Optional bool
+	// Checked is false until the first health check of the DataSource completed
+	Checked bool
+	Healthy bool
+	// Err is the error of the latest failed health check
+	Err       error
+	CheckedAt time.Time
+}
+
+// HealthCheckHook is called once the health of a DataSource changes and after its first health check
+type HealthCheckHook interface {
+	OnHealthChange(status DataSourceHealthStatus)
+}
+
+type HealthCheckHookFunc func(status DataSourceHealthStatus)
+
+func (f HealthCheckHookFunc) OnHealthChange(status DataSourceHealthStatus) {
+	f(status)
+}
+
+type dataSourceHealthCheck struct {
+	dataSource int
+	config     plan.HealthCheckConfiguration
+	status     DataSourceHealthStatus
+	failures   int
+}
+
+// healthChecker periodically checks the health of all DataSources with a HealthCheckConfiguration
+type healthChecker struct {
+	client *http.Client
+	hook   HealthCheckHook
+	// onChange is called after the health of a DataSource changed, e.g. to invalidate cached plans
+	onChange func()
+	logger   logging.Logger
+	now      func() time.Time
+
+	mu     sync.RWMutex
+	checks map[int]*dataSourceHealthCheck
+}
+
+func newHealthChecker(dataSources []plan.DataSourceConfiguration, client *http.Client, hook HealthCheckHook) *healthChecker {
+	checks := map[int]*dataSourceHealthCheck{}
+	for i := range dataSources {
+		if dataSources[i].HealthCheck == nil {
+			continue
+		}
+		config := *dataSources[i].HealthCheck
+		if config.Name == "" {
+			config.Name = config.URL
+		}
+		if config.Interval <= 0 {
+			config.Interval = defaultHealthCheckInterval
+		}
+		if config.Timeout <= 0 {
+			config.Timeout = config.Interval
+		}
+		if config.FailureThreshold <= 0 {
+			config.FailureThreshold = defaultHealthCheckFailureThreshold
+		}
+		checks[i] = &dataSourceHealthCheck{
+			dataSource: i,
+			config:     config,
+			status: DataSourceHealthStatus{
+				Name:     config.Name,
+				Optional: dataSources[i].Optional,
+			},
+		}
+	}
+	if len(checks) == 0 {
+		return nil
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &healthChecker{
+		client: client,
+		hook:   hook,
+		logger: logging.NoopLogger,
+		now:    time.Now,
+		checks: checks,
+	}
+}
+
+// start checks the health of all DataSources right away and then in their interval until the context is done
+func (h *healthChecker) start(ctx context.Context) {
+	for dataSource := range h.checks {
+		go h.run(ctx, dataSource)
+	}
+}
+
+func (h *healthChecker) run(ctx context.Context, dataSource int) {
+	h.check(ctx, dataSource)
+
+	ticker := time.NewTicker(h.checks[dataSource].config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.check(ctx, dataSource)
+		}
+	}
+}
+
+func (h *healthChecker) check(ctx context.Context, dataSource int) {
+	check := h.checks[dataSource]
+	err := h.ping(ctx, check.config)
+	if ctx.Err() != nil {
+		return
+	}
+
+	h.mu.Lock()
+	previous := check.status
+	if err != nil {
+		check.failures++
+		check.status.Err = err
+	} else {
+		check.failures = 0
+		check.status.Err = nil
+	}
+	check.status.Healthy = check.failures < check.config.FailureThreshold
+	check.status.Checked = true
+	check.status.CheckedAt = h.now()
+	status := check.status
+	h.mu.Unlock()
+
+	if previous.Checked && previous.Healthy == status.Healthy {
+		return
+	}
+	if !status.Healthy {
+		h.logger.Warn("ExecutionEngineV2: data source is unhealthy",
+			logging.String("dataSource", status.Name),
+			logging.Bool("optional", status.Optional),
+			logging.Error(status.Err),
+		)
+	} else if previous.Checked {
+		h.logger.Info("ExecutionEngineV2: data source recovered", logging.String("dataSource", status.Name))
+	}
+	if h.onChange != nil {
+		h.onChange()
+	}
+	if h.hook != nil {
+		h.hook.OnHealthChange(status)
+	}
+}
+
+// ping sends a GET request to the URL of the health check, or the query as GraphQL request if it's set
+func (h *healthChecker) ping(ctx context.Context, config plan.HealthCheckConfiguration) error {
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	method, body := http.MethodGet, io.Reader(nil)
+	if config.Query != "" {
+		query, err := json.Marshal(struct {
+			Query string `json:"query"`
+		}{Query: config.Query})
+		if err != nil {
+			return err
+		}
+		method, body = http.MethodPost, bytes.NewReader(query)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, config.URL, body)
+	if err != nil {
+		return err
+	}
+	if config.Query != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	response, err := ioutil.ReadAll(io.LimitReader(res.Body, maxHealthCheckResponseSize))
+	if err != nil {
+		return err
+	}
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("unexpected status code: %d", res.StatusCode)
+	}
+	if config.Query != "" && responseHasErrors(response) {
+		return fmt.Errorf("health check query failed: %s", response)
+	}
+	return nil
+}
+
+// IsHealthy returns false for DataSources whose latest health checks failed.
+// DataSources without health check or before their first health check are considered healthy.
+func (h *healthChecker) IsHealthy(dataSource int) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	check, ok := h.checks[dataSource]
+	return !ok || !check.status.Checked || check.status.Healthy
+}
+
+func (h *healthChecker) statuses() []DataSourceHealthStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	dataSources := make([]int, 0, len(h.checks))
+	for dataSource := range h.checks {
+		dataSources = append(dataSources, dataSource)
+	}
+	sort.Ints(dataSources)
+	statuses := make([]DataSourceHealthStatus, 0, len(dataSources))
+	for _, dataSource := range dataSources {
+		statuses = append(statuses, h.checks[dataSource].status)
+	}
+	return statuses
+}
+
+// readiness returns an error unless all required DataSources with a health check are healthy
+func (h *healthChecker) readiness() error {
+	for _, status := range h.statuses() {
+		if status.Optional {
+			continue
+		}
+		if !status.Checked {
+			return fmt.Errorf("%w: health check of data source %s is pending", ErrNotReady, status.Name)
+		}
+		if !status.Healthy {
+			return fmt.Errorf("%w: data source %s is unhealthy: %v", ErrNotReady, status.Name, status.Err)
+		}
+	}
+	return nil
+}
+
+// Readiness returns nil if all required DataSources with a HealthCheckConfiguration are healthy,
+// otherwise an error wrapping ErrNotReady, e.g. to answer the readiness probe of Kubernetes.
+// Optional DataSources don't affect the readiness, they are excluded from plans while they are unhealthy instead.
+func (e *ExecutionEngineV2) Readiness() error {
+	if e.healthChecker == nil {
+		return nil
+	}
+	return e.healthChecker.readiness()
+}
+
+// HealthStatus returns the health of all DataSources with a HealthCheckConfiguration
+func (e *ExecutionEngineV2) HealthStatus() []DataSourceHealthStatus {
+	if e.healthChecker == nil {
+		return nil
+	}
+	return e.healthChecker.statuses()
+}
+
+// purgeExecutionPlans removes all cached plans, because they depend on the health of the DataSources
+func (e *ExecutionEngineV2) purgeExecutionPlans() {
+	e.plannerMu.Lock()
+	defer e.plannerMu.Unlock()
+	e.executionPlanCache.Purge()
+	if e.partialPlans != nil {
+		e.partialPlans.cache.Purge()
+	}
+}
@@ -0,0 +1,156 @@
+package graphql
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestHealthChecker(t *testing.T) {
+	type response struct {
+		statusCode int
+		body       string
+	}
+
+	newChecker := func(t *testing.T, responses map[string]*response, requests *[]*http.Request, hook HealthCheckHook) *healthChecker {
+		t.Helper()
+		client := &http.Client{
+			Transport: testRoundTripper(func(req *http.Request) *http.Response {
+				*requests = append(*requests, req)
+				res := responses[req.URL.Host]
+				return &http.Response{StatusCode: res.statusCode, Body: ioutil.NopCloser(strings.NewReader(res.body))}
+			}),
+		}
+		checker := newHealthChecker([]plan.DataSourceConfiguration{
+			{},
+			{HealthCheck: &plan.HealthCheckConfiguration{URL: "http://rest.service/health", FailureThreshold: 2}},
+			{HealthCheck: &plan.HealthCheckConfiguration{Name: "graphql", URL: "http://graphql.service/graphql", Query: "{ __typename }"}, Optional: true},
+		}, client, hook)
+		require.NotNil(t, checker)
+		checker.now = func() time.Time {
+			return time.Unix(0, 0)
+		}
+		return checker
+	}
+
+	t.Run("no health checks", func(t *testing.T) {
+		assert.Nil(t, newHealthChecker([]plan.DataSourceConfiguration{{}}, nil, nil))
+	})
+
+	t.Run("ping", func(t *testing.T) {
+		var requests []*http.Request
+		checker := newChecker(t, map[string]*response{
+			"rest.service":    {statusCode: 200},
+			"graphql.service": {statusCode: 200, body: `{"data":{"__typename":"Query"}}`},
+		}, &requests, nil)
+
+		checker.check(context.Background(), 1)
+		checker.check(context.Background(), 2)
+
+		require.Len(t, requests, 2)
+		assert.Equal(t, http.MethodGet, requests[0].Method)
+		assert.Equal(t, http.MethodPost, requests[1].Method)
+		assert.Equal(t, "application/json", requests[1].Header.Get("Content-Type"))
+		body, err := ioutil.ReadAll(requests[1].Body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"query":"{ __typename }"}`, string(body))
+
+		assert.Equal(t, []DataSourceHealthStatus{
+			{Name: "http://rest.service/health", Checked: true, Healthy: true, CheckedAt: time.Unix(0, 0)},
+			{Name: "graphql", Optional: true, Checked: true, Healthy: true, CheckedAt: time.Unix(0, 0)},
+		}, checker.statuses())
+		assert.NoError(t, checker.readiness())
+	})
+
+	t.Run("failed query is unhealthy", func(t *testing.T) {
+		var requests []*http.Request
+		checker := newChecker(t, map[string]*response{
+			"graphql.service": {statusCode: 200, body: `{"errors":[{"message":"unavailable"}]}`},
+		}, &requests, nil)
+
+		assert.True(t, checker.IsHealthy(2), "data sources are healthy before their first check")
+		checker.check(context.Background(), 2)
+		assert.False(t, checker.IsHealthy(2))
+		assert.True(t, checker.IsHealthy(0), "data sources without health check are always healthy")
+	})
+
+	t.Run("failure threshold and readiness", func(t *testing.T) {
+		var requests []*http.Request
+		responses := map[string]*response{
+			"rest.service":    {statusCode: 200},
+			"graphql.service": {statusCode: 503},
+		}
+		var changes []DataSourceHealthStatus
+		checker := newChecker(t, responses, &requests, HealthCheckHookFunc(func(status DataSourceHealthStatus) {
+			changes = append(changes, status)
+		}))
+
+		err := checker.readiness()
+		assert.ErrorIs(t, err, ErrNotReady)
+		assert.EqualError(t, err, "engine is not ready: health check of data source http://rest.service/health is pending")
+
+		checker.check(context.Background(), 1)
+		checker.check(context.Background(), 2)
+		assert.NoError(t, checker.readiness(), "optional data sources don't affect the readiness")
+		assert.False(t, checker.IsHealthy(2))
+
+		responses["rest.service"].statusCode = 500
+		checker.check(context.Background(), 1)
+		assert.True(t, checker.IsHealthy(1), "the failure threshold isn't reached yet")
+		assert.NoError(t, checker.readiness())
+
+		checker.check(context.Background(), 1)
+		assert.False(t, checker.IsHealthy(1))
+		assert.EqualError(t, checker.readiness(), "engine is not ready: data source http://rest.service/health is unhealthy: unexpected status code: 500")
+
+		responses["rest.service"].statusCode = 200
+		checker.check(context.Background(), 1)
+		assert.NoError(t, checker.readiness())
+
+		names := make([]string, 0, len(changes))
+		healthy := make([]bool, 0, len(changes))
+		for _, change := range changes {
+			names = append(names, change.Name)
+			healthy = append(healthy, change.Healthy)
+		}
+		assert.Equal(t, []string{"http://rest.service/health", "graphql", "http://rest.service/health", "http://rest.service/health"}, names)
+		assert.Equal(t, []bool{true, false, false, true}, healthy)
+	})
+
+	t.Run("logs health changes", func(t *testing.T) {
+		var requests []*http.Request
+		responses := map[string]*response{
+			"rest.service": {statusCode: 500},
+		}
+		core, logs := observer.New(zapcore.DebugLevel)
+		checker := newChecker(t, responses, &requests, nil)
+		checker.logger = logging.NewZapLogger(zap.New(core), logging.InfoLevel)
+
+		checker.check(context.Background(), 1)
+		checker.check(context.Background(), 1)
+		responses["rest.service"].statusCode = 200
+		checker.check(context.Background(), 1)
+
+		entries := logs.AllUntimed()
+		require.Len(t, entries, 2)
+		assert.Equal(t, "ExecutionEngineV2: data source is unhealthy", entries[0].Message)
+		assert.Equal(t, map[string]interface{}{
+			"dataSource": "http://rest.service/health",
+			"optional":   false,
+			"error":      "unexpected status code: 500",
+		}, entries[0].ContextMap())
+		assert.Equal(t, "ExecutionEngineV2: data source recovered", entries[1].Message)
+	})
+}
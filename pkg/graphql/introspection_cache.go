@@ -0,0 +1,138 @@
+package graphql
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"sync/atomic"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+	"github.com/wundergraph/graphql-go-tools/pkg/pool"
+)
+
+const defaultIntrospectionCacheSize = 256
+
+// IntrospectionCacheConfig configures the cache of introspection responses, see NewIntrospectionCache
+type IntrospectionCacheConfig struct {
+	// MaxSize is the maximum number of cached responses, it defaults to 256
+	MaxSize int
+}
+
+// IntrospectionCacheStats are the counters of the introspection cache
+type IntrospectionCacheStats struct {
+	Size   int
+	Hits   uint64
+	Misses uint64
+}
+
+// IntrospectionCache caches the rendered responses of introspection operations per schema hash,
+// so that tools introspecting the schema over and over don't pay for resolving the introspection each time.
+// Schema views have their own schema, their responses are cached separately from the responses of the complete schema.
+//
+// The cache can be shared by the engines of subsequent schema versions, e.g. when the engine is recreated on a hot reload,
+// creating an engine with a new schema removes the responses of the schemas of the previous engine.
+type IntrospectionCache struct {
+	cache        *lru.Cache
+	mu           sync.RWMutex
+	schemas      map[uint64]struct{}
+	hits, misses uint64
+}
+
+type introspectionCacheKey struct {
+	schema    uint64
+	operation uint64
+}
+
+func NewIntrospectionCache(config IntrospectionCacheConfig) *IntrospectionCache {
+	size := config.MaxSize
+	if size <= 0 {
+		size = defaultIntrospectionCacheSize
+	}
+	// lru.New only fails for sizes below 1
+	cache, _ := lru.New(size)
+	return &IntrospectionCache{
+		cache:   cache,
+		schemas: map[uint64]struct{}{},
+	}
+}
+
+// Stats returns the counters of the cache
+func (c *IntrospectionCache) Stats() IntrospectionCacheStats {
+	return IntrospectionCacheStats{
+		Size:   c.cache.Len(),
+		Hits:   atomic.LoadUint64(&c.hits),
+		Misses: atomic.LoadUint64(&c.misses),
+	}
+}
+
+// Purge removes all cached responses
+func (c *IntrospectionCache) Purge() {
+	c.cache.Purge()
+}
+
+// useSchemas removes the cached responses of all schemas but the schemas of a new engine,
+// responses of the schemas of previous engines which are still resolving operations aren't cached anymore
+func (c *IntrospectionCache) useSchemas(hashes ...uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	schemas := make(map[uint64]struct{}, len(hashes))
+	for _, hash := range hashes {
+		schemas[hash] = struct{}{}
+	}
+	c.schemas = schemas
+	for _, key := range c.cache.Keys() {
+		if _, ok := schemas[key.(introspectionCacheKey).schema]; !ok {
+			c.cache.Remove(key)
+		}
+	}
+}
+
+func (c *IntrospectionCache) usesSchema(hash uint64) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, ok := c.schemas[hash]
+	return ok
+}
+
+// resolve writes the cached response of the introspection operation, cache misses are resolved by the resolver of the schema
+func (c *IntrospectionCache) resolve(schema *Schema, resolver *introspectionResolver, operation *Request, w io.Writer) error {
+	key, err := c.key(schema, operation)
+	if err != nil {
+		return err
+	}
+	if response, ok := c.cache.Get(key); ok {
+		atomic.AddUint64(&c.hits, 1)
+		_, err = w.Write(response.([]byte))
+		return err
+	}
+	atomic.AddUint64(&c.misses, 1)
+
+	buf := &bytes.Buffer{}
+	if err = resolver.Resolve(&operation.document, operation.OperationName, buf); err != nil {
+		return err
+	}
+	if c.usesSchema(key.schema) {
+		c.cache.Add(key, buf.Bytes())
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// key identifies the response by the schema and the normalized operation including its variables,
+// e.g. the name of the type of __type(name: $name)
+func (c *IntrospectionCache) key(schema *Schema, operation *Request) (introspectionCacheKey, error) {
+	hash := pool.Hash64.Get()
+	hash.Reset()
+	defer pool.Hash64.Put(hash)
+	if err := astprinter.Print(&operation.document, nil, hash); err != nil {
+		return introspectionCacheKey{}, err
+	}
+	_, _ = hash.Write([]byte(operation.OperationName))
+	_, _ = hash.Write(operation.document.Input.Variables)
+	return introspectionCacheKey{
+		schema:    schema.Hash(),
+		operation: hash.Sum64(),
+	}, nil
+}
@@ -0,0 +1,100 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestIntrospectionCache(t *testing.T) {
+	newEngine := func(t *testing.T, cache *IntrospectionCache, schemaSDL string) *ExecutionEngineV2 {
+		schema, err := NewSchemaFromString(schemaSDL)
+		require.NoError(t, err)
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.AddSchemaView(SchemaViewConfig{Name: "public", ExcludeDirectives: []string{"internal"}})
+		engineConf.SetSchemaViewSelector(SchemaViewSelectorFunc(func(ctx context.Context, operation *Request) string {
+			if operation.Header().Get("X-Role") == "admin" {
+				return ""
+			}
+			return "public"
+		}))
+		engineConf.EnableIntrospectionFastPath(true)
+		engineConf.SetIntrospectionCache(cache)
+		engine, err := NewExecutionEngineV2(context.Background(), logging.NoopLogger, engineConf)
+		require.NoError(t, err)
+		return engine
+	}
+
+	execute := func(t *testing.T, engine *ExecutionEngineV2, role string, request Request) string {
+		request.SetHeader(http.Header{"X-Role": []string{role}})
+		resultWriter := NewEngineResultWriter()
+		require.NoError(t, engine.Execute(context.Background(), &request, &resultWriter))
+		return resultWriter.String()
+	}
+
+	const (
+		queryFields    = `{ __type(name: "Query") { fields { name } } }`
+		publicFields   = `{"data":{"__type":{"fields":[{"name":"hello"},{"name":"users"}]}}}`
+		adminFields    = `{"data":{"__type":{"fields":[{"name":"hello"},{"name":"secret"},{"name":"users"},{"name":"admin"}]}}}`
+		typeByName     = `query TypeByName($name: String!) { __type(name: $name) { name } }`
+		typeByNameUser = `{"data":{"__type":{"name":"User"}}}`
+		typeByNameRole = `{"data":{"__type":{"name":"Role"}}}`
+	)
+
+	t.Run("caches the responses per schema view", func(t *testing.T) {
+		cache := NewIntrospectionCache(IntrospectionCacheConfig{})
+		engine := newEngine(t, cache, schemaViewTestSchema)
+
+		assert.Equal(t, publicFields, execute(t, engine, "user", Request{Query: queryFields}))
+		assert.Equal(t, adminFields, execute(t, engine, "admin", Request{Query: queryFields}))
+		assert.Equal(t, IntrospectionCacheStats{Size: 2, Misses: 2}, cache.Stats())
+
+		assert.Equal(t, publicFields, execute(t, engine, "user", Request{Query: queryFields}))
+		assert.Equal(t, adminFields, execute(t, engine, "admin", Request{Query: queryFields}))
+		assert.Equal(t, IntrospectionCacheStats{Size: 2, Hits: 2, Misses: 2}, cache.Stats())
+	})
+
+	t.Run("caches the responses per variables", func(t *testing.T) {
+		cache := NewIntrospectionCache(IntrospectionCacheConfig{})
+		engine := newEngine(t, cache, schemaViewTestSchema)
+
+		request := func(variables string) Request {
+			return Request{OperationName: "TypeByName", Query: typeByName, Variables: []byte(variables)}
+		}
+		assert.Equal(t, typeByNameUser, execute(t, engine, "user", request(`{"name":"User"}`)))
+		assert.Equal(t, typeByNameRole, execute(t, engine, "user", request(`{"name":"Role"}`)))
+		assert.Equal(t, typeByNameUser, execute(t, engine, "user", request(`{"name":"User"}`)))
+		assert.Equal(t, IntrospectionCacheStats{Size: 2, Hits: 1, Misses: 2}, cache.Stats())
+	})
+
+	t.Run("invalidates the responses of previous schemas", func(t *testing.T) {
+		cache := NewIntrospectionCache(IntrospectionCacheConfig{})
+		previous := newEngine(t, cache, schemaViewTestSchema)
+		assert.Equal(t, publicFields, execute(t, previous, "user", Request{Query: queryFields}))
+		assert.Equal(t, adminFields, execute(t, previous, "admin", Request{Query: queryFields}))
+
+		reloaded := newEngine(t, cache, schemaViewTestSchema+` extend type Query { goodbye: String }`)
+		assert.Equal(t, 0, cache.Stats().Size)
+
+		assert.Equal(t, `{"data":{"__type":{"fields":[{"name":"hello"},{"name":"users"},{"name":"goodbye"}]}}}`, execute(t, reloaded, "user", Request{Query: queryFields}))
+		assert.Equal(t, 1, cache.Stats().Size)
+
+		assert.Equal(t, publicFields, execute(t, previous, "user", Request{Query: queryFields}))
+		assert.Equal(t, 1, cache.Stats().Size, "responses of the previous schema aren't cached anymore")
+	})
+
+	t.Run("keeps the responses of an unchanged schema", func(t *testing.T) {
+		cache := NewIntrospectionCache(IntrospectionCacheConfig{})
+		previous := newEngine(t, cache, schemaViewTestSchema)
+		execute(t, previous, "user", Request{Query: queryFields})
+
+		reloaded := newEngine(t, cache, schemaViewTestSchema)
+		assert.Equal(t, publicFields, execute(t, reloaded, "user", Request{Query: queryFields}))
+		assert.Equal(t, IntrospectionCacheStats{Size: 1, Hits: 1, Misses: 1}, cache.Stats())
+	})
+}
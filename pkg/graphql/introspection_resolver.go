@@ -0,0 +1,340 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/introspection"
+	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+const (
+	introspectionSchemaTypeName = "__Schema"
+	introspectionTypeTypeName   = "__Type"
+)
+
+// introspectionResolver answers pure introspection operations from the introspection JSON of the schema,
+// which is generated once, instead of planning and resolving them like any other operation.
+type introspectionResolver struct {
+	definition *ast.Document
+	schema     []byte
+	types      map[string][]byte
+}
+
+func newIntrospectionResolver(definition *ast.Document) (*introspectionResolver, error) {
+	var (
+		data   introspection.Data
+		report operationreport.Report
+	)
+	introspection.NewGenerator().Generate(definition, &report, &data)
+	if report.HasErrors() {
+		return nil, report
+	}
+
+	schema, err := json.Marshal(data.Schema)
+	if err != nil {
+		return nil, err
+	}
+
+	types := make(map[string][]byte, len(data.Schema.Types))
+	for i := range data.Schema.Types {
+		fullType, err := json.Marshal(data.Schema.Types[i])
+		if err != nil {
+			return nil, err
+		}
+		types[data.Schema.Types[i].Name] = fullType
+	}
+
+	return &introspectionResolver{
+		definition: definition,
+		schema:     schema,
+		types:      types,
+	}, nil
+}
+
+// Resolve writes the response of the introspection operation.
+// The operation must be normalized and valid and select nothing but introspection fields.
+func (i *introspectionResolver) Resolve(operation *ast.Document, operationName string, w io.Writer) error {
+	operationDefinition, ok := i.operationDefinition(operation, operationName)
+	if !ok {
+		_, err := w.Write([]byte(`{"data":null}`))
+		return err
+	}
+
+	resolution := introspectionResolution{
+		resolver:  i,
+		operation: operation,
+		buf:       &bytes.Buffer{},
+	}
+	resolution.buf.WriteString(`{"data":`)
+	resolution.writeSelectionSet(operation.OperationDefinitions[operationDefinition].SelectionSet, i.definition.Index.QueryTypeName.String(), nil)
+	resolution.buf.WriteString(`}`)
+
+	_, err := w.Write(resolution.buf.Bytes())
+	return err
+}
+
+func (i *introspectionResolver) operationDefinition(operation *ast.Document, operationName string) (ref int, ok bool) {
+	for _, rootNode := range operation.RootNodes {
+		if rootNode.Kind != ast.NodeKindOperationDefinition {
+			continue
+		}
+		if operationName != "" && operation.OperationDefinitionNameString(rootNode.Ref) != operationName {
+			continue
+		}
+		return rootNode.Ref, true
+	}
+	return ast.InvalidRef, false
+}
+
+// fieldTypeName returns the name of the unwrapped type of the field of the introspection type
+func (i *introspectionResolver) fieldTypeName(typeName, fieldName string) string {
+	node, ok := i.definition.Index.FirstNodeByNameStr(typeName)
+	if !ok {
+		return ""
+	}
+	fieldDefinition, ok := i.definition.NodeFieldDefinitionByName(node, []byte(fieldName))
+	if !ok {
+		return ""
+	}
+	return i.definition.ResolveTypeNameString(i.definition.FieldDefinitionType(fieldDefinition))
+}
+
+// fullType returns the complete introspection data of a type reference to a named type,
+// e.g. to resolve the fields of the query type or of the type of a field
+func (i *introspectionResolver) fullType(typeRef []byte) []byte {
+	name, err := jsonparser.GetString(typeRef, "name")
+	if err != nil {
+		return typeRef
+	}
+	if fullType, ok := i.types[name]; ok {
+		return fullType
+	}
+	return typeRef
+}
+
+type introspectionResolution struct {
+	resolver  *introspectionResolver
+	operation *ast.Document
+	buf       *bytes.Buffer
+}
+
+func (r *introspectionResolution) writeSelectionSet(selectionSet int, typeName string, data []byte) {
+	r.buf.WriteByte('{')
+	first := true
+	r.writeSelections(selectionSet, typeName, data, &first)
+	r.buf.WriteByte('}')
+}
+
+func (r *introspectionResolution) writeSelections(selectionSet int, typeName string, data []byte, first *bool) {
+	for _, selectionRef := range r.operation.SelectionSets[selectionSet].SelectionRefs {
+		selection := r.operation.Selections[selectionRef]
+		switch selection.Kind {
+		case ast.SelectionKindField:
+			if !r.isIncluded(r.operation.Fields[selection.Ref].Directives.Refs) {
+				continue
+			}
+			if !*first {
+				r.buf.WriteByte(',')
+			}
+			*first = false
+			r.buf.WriteByte('"')
+			r.buf.WriteString(r.operation.FieldAliasOrNameString(selection.Ref))
+			r.buf.WriteString(`":`)
+			r.writeField(selection.Ref, typeName, data)
+		case ast.SelectionKindInlineFragment:
+			inlineFragment := r.operation.InlineFragments[selection.Ref]
+			if !inlineFragment.HasSelections || !r.isIncluded(inlineFragment.Directives.Refs) {
+				continue
+			}
+			typeCondition := r.operation.InlineFragmentTypeConditionNameString(selection.Ref)
+			if typeCondition != "" && typeCondition != typeName {
+				continue
+			}
+			r.writeSelections(inlineFragment.SelectionSet, typeName, data, first)
+		case ast.SelectionKindFragmentSpread:
+			if !r.isIncluded(r.operation.FragmentSpreads[selection.Ref].Directives.Refs) {
+				continue
+			}
+			fragmentDefinition, ok := r.operation.FragmentDefinitionRef(r.operation.FragmentSpreadNameBytes(selection.Ref))
+			if !ok || string(r.operation.FragmentDefinitionTypeName(fragmentDefinition)) != typeName {
+				continue
+			}
+			r.writeSelections(r.operation.FragmentDefinitions[fragmentDefinition].SelectionSet, typeName, data, first)
+		}
+	}
+}
+
+func (r *introspectionResolution) writeField(fieldRef int, typeName string, data []byte) {
+	fieldName := r.operation.FieldNameString(fieldRef)
+	if fieldName == "__typename" {
+		r.buf.WriteByte('"')
+		r.buf.WriteString(typeName)
+		r.buf.WriteByte('"')
+		return
+	}
+
+	var (
+		value         []byte
+		valueType     jsonparser.ValueType
+		valueTypeName string
+	)
+
+	switch {
+	case data == nil && fieldName == "__schema":
+		value, valueType, valueTypeName = r.resolver.schema, jsonparser.Object, introspectionSchemaTypeName
+	case data == nil && fieldName == "__type":
+		valueTypeName = introspectionTypeTypeName
+		name, nameType := r.argumentValue(fieldRef, "name")
+		if fullType, ok := r.resolver.types[string(name)]; ok && nameType == jsonparser.String {
+			value, valueType = fullType, jsonparser.Object
+		}
+	default:
+		valueTypeName = r.resolver.fieldTypeName(typeName, fieldName)
+		var err error
+		value, valueType, _, err = jsonparser.Get(data, fieldName)
+		if err != nil || (typeName == introspectionTypeTypeName && !kindHasList(data, fieldName)) {
+			valueType = jsonparser.Null
+		}
+		if valueType == jsonparser.Array && r.excludesDeprecated(fieldRef, fieldName) {
+			value = withoutDeprecated(value)
+		}
+	}
+
+	r.writeValue(fieldRef, valueTypeName, value, valueType)
+}
+
+func (r *introspectionResolution) writeValue(fieldRef int, typeName string, value []byte, valueType jsonparser.ValueType) {
+	switch valueType {
+	case jsonparser.Null, jsonparser.NotExist, jsonparser.Unknown:
+		r.buf.Write(literal.NULL)
+	case jsonparser.String:
+		r.buf.WriteByte('"')
+		r.buf.Write(value)
+		r.buf.WriteByte('"')
+	case jsonparser.Array:
+		r.buf.WriteByte('[')
+		first := true
+		_, _ = jsonparser.ArrayEach(value, func(item []byte, itemType jsonparser.ValueType, _ int, _ error) {
+			if !first {
+				r.buf.WriteByte(',')
+			}
+			first = false
+			r.writeValue(fieldRef, typeName, item, itemType)
+		})
+		r.buf.WriteByte(']')
+	case jsonparser.Object:
+		if !r.operation.FieldHasSelections(fieldRef) {
+			r.buf.Write(value)
+			return
+		}
+		if typeName == introspectionTypeTypeName {
+			value = r.resolver.fullType(value)
+		}
+		r.writeSelectionSet(r.operation.Fields[fieldRef].SelectionSet, typeName, value)
+	default:
+		r.buf.Write(value)
+	}
+}
+
+// excludesDeprecated returns true if deprecated items of the field must be omitted, which is the default for fields and enum values
+func (r *introspectionResolution) excludesDeprecated(fieldRef int, fieldName string) bool {
+	if fieldName != "fields" && fieldName != "enumValues" {
+		return false
+	}
+	value, valueType := r.argumentValue(fieldRef, "includeDeprecated")
+	if valueType != jsonparser.Boolean {
+		return true
+	}
+	includeDeprecated, err := jsonparser.ParseBoolean(value)
+	return err != nil || !includeDeprecated
+}
+
+// argumentValue returns the value of the argument, resolving variables from the variables of the operation
+func (r *introspectionResolution) argumentValue(fieldRef int, argumentName string) ([]byte, jsonparser.ValueType) {
+	argument, ok := r.operation.FieldArgument(fieldRef, []byte(argumentName))
+	if !ok {
+		return nil, jsonparser.NotExist
+	}
+	argumentValue := r.operation.ArgumentValue(argument)
+	if argumentValue.Kind == ast.ValueKindVariable {
+		value, valueType, _, err := jsonparser.Get(r.operation.Input.Variables, r.operation.VariableValueNameString(argumentValue.Ref))
+		if err != nil {
+			return nil, jsonparser.NotExist
+		}
+		return value, valueType
+	}
+	valueJSON, err := r.operation.ValueToJSON(argumentValue)
+	if err != nil {
+		return nil, jsonparser.NotExist
+	}
+	value, valueType, _, err := jsonparser.Get(valueJSON)
+	if err != nil {
+		return nil, jsonparser.NotExist
+	}
+	return value, valueType
+}
+
+func (r *introspectionResolution) isIncluded(directiveRefs []int) bool {
+	for _, directive := range directiveRefs {
+		name := r.operation.DirectiveNameBytes(directive)
+		isSkip := bytes.Equal(name, literal.SKIP)
+		if !isSkip && !bytes.Equal(name, literal.INCLUDE) {
+			continue
+		}
+		value, ok := r.operation.DirectiveArgumentValueByName(directive, literal.IF)
+		if !ok {
+			continue
+		}
+		var condition bool
+		switch value.Kind {
+		case ast.ValueKindBoolean:
+			condition = bool(r.operation.BooleanValue(value.Ref))
+		case ast.ValueKindVariable:
+			condition, _ = jsonparser.GetBoolean(r.operation.Input.Variables, r.operation.VariableValueNameString(value.Ref))
+		default:
+			continue
+		}
+		if condition == isSkip {
+			return false
+		}
+	}
+	return true
+}
+
+// kindHasList returns false if the kind of the type doesn't allow the list, e.g. the fields of a scalar, which are null
+func kindHasList(fullType []byte, fieldName string) bool {
+	kind, _ := jsonparser.GetString(fullType, "kind")
+	switch fieldName {
+	case "fields", "interfaces":
+		return kind == "OBJECT" || kind == "INTERFACE"
+	case "possibleTypes":
+		return kind == "INTERFACE" || kind == "UNION"
+	case "inputFields":
+		return kind == "INPUT_OBJECT"
+	case "enumValues":
+		return kind == "ENUM"
+	default:
+		return true
+	}
+}
+
+func withoutDeprecated(items []byte) []byte {
+	filtered := make([]byte, 0, len(items))
+	filtered = append(filtered, '[')
+	_, _ = jsonparser.ArrayEach(items, func(item []byte, itemType jsonparser.ValueType, _ int, _ error) {
+		if isDeprecated, _ := jsonparser.GetBoolean(item, "isDeprecated"); isDeprecated {
+			return
+		}
+		if len(filtered) > 1 {
+			filtered = append(filtered, ',')
+		}
+		filtered = append(filtered, item...)
+	})
+	return append(filtered, ']')
+}
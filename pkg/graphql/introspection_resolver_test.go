@@ -0,0 +1,115 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+	"github.com/wundergraph/graphql-go-tools/pkg/starwars"
+)
+
+func TestIntrospectionFastPath(t *testing.T) {
+	schema := starwarsSchema(t)
+
+	newEngine := func(t *testing.T, fastPath bool) *ExecutionEngineV2 {
+		engineConfig := NewEngineV2Configuration(schema)
+		engineConfig.EnableIntrospectionFastPath(fastPath)
+		engine, err := NewExecutionEngineV2(context.Background(), logging.NoopLogger, engineConfig)
+		require.NoError(t, err)
+		return engine
+	}
+
+	execute := func(t *testing.T, engine *ExecutionEngineV2, request Request) string {
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &request, &resultWriter)
+		require.NoError(t, err)
+		return resultWriter.String()
+	}
+
+	fastPathEngine := newEngine(t, true)
+	pipelineEngine := newEngine(t, false)
+
+	t.Run("responds like the planner and resolver", func(t *testing.T) {
+		requests := map[string]func(t *testing.T) Request{
+			"type with deprecated fields": func(t *testing.T) Request {
+				return Request{Query: `{ __type(name: "Query") { name fields(includeDeprecated: true) { name isDeprecated } } }`}
+			},
+			"type without deprecated enum values": func(t *testing.T) Request {
+				return Request{Query: `{ __type(name: "Episode") { name enumValues { name } fields { name } } }`}
+			},
+			"aliases and multiple root fields": func(t *testing.T) Request {
+				return Request{Query: `{ h: __type(name: "Human") { kind fields { name } } d: __type(name: "Droid") { n: name } }`}
+			},
+		}
+
+		for name, request := range requests {
+			t.Run(name, func(t *testing.T) {
+				assert.Equal(t, execute(t, pipelineEngine, request(t)), execute(t, fastPathEngine, request(t)))
+			})
+		}
+	})
+
+	t.Run("full introspection query", func(t *testing.T) {
+		request := requestForQuery(t, starwars.FileIntrospectionQuery)
+		response := execute(t, fastPathEngine, request)
+		assert.True(t, json.Valid([]byte(response)))
+		assert.NotContains(t, response, `"errors"`)
+	})
+
+	t.Run("kind specific fields are null if they don't apply to the kind", func(t *testing.T) {
+		const selection = `kind fields { name } inputFields { name } interfaces { name } possibleTypes { name } enumValues { name }`
+		expectedTypes := map[string]string{
+			"String":       `{"kind":"SCALAR","fields":null,"inputFields":null,"interfaces":null,"possibleTypes":null,"enumValues":null}`,
+			"Human":        `{"kind":"OBJECT","fields":[{"name":"name"},{"name":"friends"}],"inputFields":null,"interfaces":[{"name":"Character"}],"possibleTypes":null,"enumValues":null}`,
+			"Character":    `{"kind":"INTERFACE","fields":[{"name":"name"},{"name":"friends"}],"inputFields":null,"interfaces":[],"possibleTypes":[{"name":"Human"},{"name":"Droid"}],"enumValues":null}`,
+			"Episode":      `{"kind":"ENUM","fields":null,"inputFields":null,"interfaces":null,"possibleTypes":null,"enumValues":[{"name":"NEWHOPE"},{"name":"EMPIRE"}]}`,
+			"SearchResult": `{"kind":"UNION","fields":null,"inputFields":null,"interfaces":null,"possibleTypes":[{"name":"Human"},{"name":"Droid"},{"name":"Starship"}],"enumValues":null}`,
+			"ReviewInput":  `{"kind":"INPUT_OBJECT","fields":null,"inputFields":[{"name":"stars"},{"name":"commentary"}],"interfaces":null,"possibleTypes":null,"enumValues":null}`,
+		}
+
+		for typeName, expectedType := range expectedTypes {
+			t.Run(typeName, func(t *testing.T) {
+				response := execute(t, fastPathEngine, Request{
+					Query: `{ __type(name: "` + typeName + `") { ` + selection + ` } }`,
+				})
+				assert.Equal(t, `{"data":{"__type":`+expectedType+`}}`, response)
+			})
+		}
+	})
+
+	t.Run("not existing type", func(t *testing.T) {
+		response := execute(t, fastPathEngine, Request{
+			Query: `{ __type(name: "NotExisting") { name } }`,
+		})
+		assert.Equal(t, `{"data":{"__type":null}}`, response)
+	})
+
+	t.Run("type name variable", func(t *testing.T) {
+		response := execute(t, fastPathEngine, Request{
+			OperationName: "TypeByName",
+			Query:         `query TypeByName($name: String!, $deprecated: Boolean!) { __type(name: $name) { name fields(includeDeprecated: $deprecated) { name } } }`,
+			Variables:     []byte(`{"name":"Query","deprecated":true}`),
+		})
+		assert.Equal(t, `{"data":{"__type":{"name":"Query","fields":[{"name":"hero"},{"name":"droid"},{"name":"search"}]}}}`, response)
+	})
+
+	t.Run("__typename and skip", func(t *testing.T) {
+		response := execute(t, fastPathEngine, Request{
+			OperationName: "Introspection",
+			Query:         `query Introspection($skipKind: Boolean!) { __schema { __typename queryType { __typename name } } __type(name: "Droid") { name kind @skip(if: $skipKind) } }`,
+			Variables:     []byte(`{"skipKind":true}`),
+		})
+		assert.Equal(t, `{"data":{"__schema":{"__typename":"__Schema","queryType":{"__typename":"__Type","name":"Query"}},"__type":{"name":"Droid"}}}`, response)
+	})
+
+	t.Run("resolves type references to the full type", func(t *testing.T) {
+		response := execute(t, fastPathEngine, Request{
+			Query: `{ __schema { queryType { name kind fields { name } } } }`,
+		})
+		assert.Equal(t, `{"data":{"__schema":{"queryType":{"name":"Query","kind":"OBJECT","fields":[{"name":"droid"},{"name":"search"}]}}}}`, response)
+	})
+}
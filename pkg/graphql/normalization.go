@@ -11,6 +11,12 @@ type NormalizationResult struct {
 }
 
 func (r *Request) Normalize(schema *Schema) (result NormalizationResult, err error) {
+	return r.NormalizeWithFragmentRegistry(schema, nil)
+}
+
+// NormalizeWithFragmentRegistry normalizes the request after injecting the registered fragments it spreads without defining them.
+// A nil registry doesn't inject any fragments.
+func (r *Request) NormalizeWithFragmentRegistry(schema *Schema, registry *astnormalization.FragmentRegistry) (result NormalizationResult, err error) {
 	if schema == nil {
 		return NormalizationResult{Successful: false, Errors: nil}, ErrNilSchema
 	}
@@ -26,6 +32,7 @@ func (r *Request) Normalize(schema *Schema) (result NormalizationResult, err err
 		astnormalization.WithExtractVariables(),
 		astnormalization.WithRemoveFragmentDefinitions(),
 		astnormalization.WithRemoveUnusedVariables(),
+		astnormalization.WithFragmentRegistry(registry),
 	)
 
 	if r.OperationName != "" {
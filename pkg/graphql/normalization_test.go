@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeprinter"
+	"github.com/wundergraph/graphql-go-tools/pkg/astnormalization"
 	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
 	"github.com/wundergraph/graphql-go-tools/pkg/starwars"
 )
@@ -49,6 +50,32 @@ func TestRequest_Normalize(t *testing.T) {
 		assert.Equal(t, normalizedOperation, op)
 	})
 
+	t.Run("should successfully normalize request with registered fragments", func(t *testing.T) {
+		registry := astnormalization.NewFragmentRegistry()
+		require.NoError(t, registry.Register(`fragment HeroName on Character { name }`))
+
+		schema := starwarsSchema(t)
+		request := Request{
+			Query: `query Hero { hero { ...HeroName } }`,
+		}
+
+		result, err := request.NormalizeWithFragmentRegistry(schema, registry)
+		assert.NoError(t, err)
+		assert.True(t, result.Successful)
+
+		validation, err := request.ValidateForSchema(schema)
+		assert.NoError(t, err)
+		assert.True(t, validation.Valid)
+
+		normalizedOperation := `query Hero {
+    hero {
+        name
+    }
+}`
+		op := unsafeprinter.PrettyPrint(&request.document, nil)
+		assert.Equal(t, normalizedOperation, op)
+	})
+
 	runNormalizationWithSchema := func(t *testing.T, schema *Schema, request *Request, expectedVars string, expectedNormalizedOperation string) {
 		t.Helper()
 
@@ -0,0 +1,174 @@
+package graphql
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+// OperationAnalysis describes the costs of an operation without executing it, see ExecutionEngineV2.AnalyzeOperation
+type OperationAnalysis struct {
+	OperationName string
+	OperationType OperationType
+	NodeCount     int
+	Complexity    int
+	Depth         int
+	// Fields are the costs of the root fields of the operation
+	Fields []FieldComplexityResult
+	// DataSources are the data sources the operation would fetch from, in the order of their first fetch
+	DataSources []OperationDataSource
+}
+
+// OperationDataSource is a data source fetched by an operation
+type OperationDataSource struct {
+	// DataSource is the identifier of the data source, e.g. graphql_datasource.Source
+	DataSource string
+	// URL is the URL the data source fetches from, empty for data sources which don't fetch by URL
+	URL string
+	// Fetches is the number of fetches of the data source in the plan of the operation.
+	// Fetches nested in lists are executed for each item or batch but counted once.
+	Fetches int
+}
+
+// AnalyzeOperation prepares the operation like Execute, i.e. it's normalized and validated with its variables for the schema selected for the request,
+// and returns its depth, complexity, the costs of its root fields and the data sources it would fetch from, without executing it,
+// e.g. to check operations in CI or to budget operations on the client. Introspection queries don't fetch from any data source.
+// The complexity is calculated by the operation_complexity package, integer arguments and variables of arguments with
+// the @nodeCountMultiply directive multiply the costs of the selections of their field.
+// The operation and its variables are modified like by Execute, so the request must not be executed afterwards.
+func (e *ExecutionEngineV2) AnalyzeOperation(ctx context.Context, operation *Request) (OperationAnalysis, error) {
+	if e.config.fragmentArguments {
+		operation.fragmentArguments = true
+	}
+	if e.config.clientNullability {
+		operation.clientNullability = true
+	}
+
+	schema, _, err := e.selectSchemaView(ctx, operation)
+	if err != nil {
+		return OperationAnalysis{}, err
+	}
+	if err = e.prepareOperation(ctx, operation, schema); err != nil {
+		return OperationAnalysis{}, err
+	}
+
+	operationType, err := operation.OperationType()
+	if err != nil {
+		return OperationAnalysis{}, err
+	}
+
+	// the complexity is calculated before planning, as the planner adds fields to the operation
+	complexity, err := operation.CalculateComplexity(DefaultComplexityCalculator, schema)
+	if err != nil {
+		return OperationAnalysis{}, err
+	}
+	if complexity.Errors != nil && complexity.Errors.Count() > 0 {
+		return OperationAnalysis{}, complexity.Errors
+	}
+
+	analysis := OperationAnalysis{
+		OperationName: operation.OperationName,
+		OperationType: operationType,
+		NodeCount:     complexity.NodeCount,
+		Complexity:    complexity.Complexity,
+		Depth:         complexity.Depth,
+		Fields:        complexity.PerRootField,
+	}
+
+	if isIntrospection, _ := operation.IsIntrospectionQuery(); isIntrospection {
+		return analysis, nil
+	}
+
+	var report operationreport.Report
+	e.plannerMu.Lock()
+	e.planner.SetFeatureFlags(nil)
+	planned := e.planner.Plan(&operation.document, &e.config.schema.document, operation.OperationName, &report)
+	e.plannerMu.Unlock()
+	if report.HasErrors() {
+		return OperationAnalysis{}, report
+	}
+
+	dataSources := &operationDataSources{}
+	dataSources.collect(planned)
+	analysis.DataSources = dataSources.dataSources
+	return analysis, nil
+}
+
+// operationDataSources collects the data sources of the fetches of a plan, which isn't post processed yet
+type operationDataSources struct {
+	dataSources []OperationDataSource
+}
+
+func (o *operationDataSources) collect(planned plan.Plan) {
+	switch p := planned.(type) {
+	case *plan.SynchronousResponsePlan:
+		o.traverseNode(p.Response.Data)
+	case *plan.StreamingResponsePlan:
+		o.traverseNode(p.Response.InitialResponse.Data)
+		for i := range p.Response.Patches {
+			o.traverseFetch(p.Response.Patches[i].Fetch)
+			o.traverseNode(p.Response.Patches[i].Value)
+		}
+	case *plan.SubscriptionResponsePlan:
+		if p.Response.Trigger.Source != nil {
+			o.add(dataSourceIdentifier(p.Response.Trigger.Source), p.Response.Trigger.Input)
+		}
+		o.traverseNode(p.Response.Response.Data)
+	}
+}
+
+func (o *operationDataSources) traverseNode(node resolve.Node) {
+	switch n := node.(type) {
+	case *resolve.Object:
+		o.traverseFetch(n.Fetch)
+		for i := range n.Fields {
+			o.traverseNode(n.Fields[i].Value)
+		}
+	case *resolve.Array:
+		o.traverseNode(n.Item)
+	}
+}
+
+func (o *operationDataSources) traverseFetch(fetch resolve.Fetch) {
+	switch f := fetch.(type) {
+	case *resolve.SingleFetch:
+		o.add(string(f.DataSourceIdentifier), []byte(f.Input))
+	case *resolve.BatchFetch:
+		o.traverseFetch(f.Fetch)
+	case *resolve.ParallelFetch:
+		for i := range f.Fetches {
+			o.traverseFetch(f.Fetches[i])
+		}
+	case *resolve.SerialFetch:
+		for i := range f.Fetches {
+			o.traverseFetch(f.Fetches[i])
+		}
+	}
+}
+
+func (o *operationDataSources) add(dataSource string, input []byte) {
+	url, _ := jsonparser.GetString(input, httpclient.URL)
+	for i := range o.dataSources {
+		if o.dataSources[i].DataSource == dataSource && o.dataSources[i].URL == url {
+			o.dataSources[i].Fetches++
+			return
+		}
+	}
+	o.dataSources = append(o.dataSources, OperationDataSource{
+		DataSource: dataSource,
+		URL:        url,
+		Fetches:    1,
+	})
+}
+
+// dataSourceIdentifier returns the identifier of a data source like the planner sets it on fetches
+func dataSourceIdentifier(dataSource interface{}) string {
+	return strings.TrimPrefix(reflect.TypeOf(dataSource).String(), "*")
+}
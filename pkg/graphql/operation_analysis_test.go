@@ -0,0 +1,132 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestExecutionEngineV2_AnalyzeOperation(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		directive @nodeCountMultiply on ARGUMENT_DEFINITION
+
+		type Query {
+			users(first: Int! @nodeCountMultiply): [User]
+			hello: String
+		}
+
+		type User {
+			id: ID
+			name: String
+			reviews: [Review]
+		}
+
+		type Review {
+			body: String
+		}`)
+	require.NoError(t, err)
+
+	client := &http.Client{
+		Transport: testRoundTripper(func(req *http.Request) *http.Response {
+			t.Errorf("unexpected request to %s, the operation must not be executed", req.URL)
+			return &http.Response{StatusCode: http.StatusInternalServerError, Body: http.NoBody}
+		}),
+	}
+	dataSource := func(url string, rootNodes, childNodes []plan.TypeField) plan.DataSourceConfiguration {
+		return plan.DataSourceConfiguration{
+			RootNodes:  rootNodes,
+			ChildNodes: childNodes,
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: client,
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    url,
+					Method: http.MethodPost,
+				},
+			}),
+		}
+	}
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		dataSource("https://users.service/graphql",
+			[]plan.TypeField{{TypeName: "Query", FieldNames: []string{"users"}}},
+			[]plan.TypeField{
+				{TypeName: "User", FieldNames: []string{"id", "name", "reviews"}},
+				{TypeName: "Review", FieldNames: []string{"body"}},
+			},
+		),
+		dataSource("https://hello.service/graphql",
+			[]plan.TypeField{{TypeName: "Query", FieldNames: []string{"hello"}}},
+			nil,
+		),
+	})
+	engineConf.SetFieldConfigurations([]plan.FieldConfiguration{
+		{
+			TypeName:  "Query",
+			FieldName: "users",
+			Arguments: []plan.ArgumentConfiguration{
+				{Name: "first", SourceType: plan.FieldArgumentSource},
+			},
+		},
+	})
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	t.Run("returns the costs and data sources of the operation", func(t *testing.T) {
+		analysis, err := engine.AnalyzeOperation(context.Background(), &Request{
+			OperationName: "Users",
+			Query:         `query Users($first: Int!) { users(first: $first) { id name reviews { body } } hello }`,
+			Variables:     []byte(`{"first":10}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, OperationAnalysis{
+			OperationName: "Users",
+			OperationType: OperationTypeQuery,
+			NodeCount:     20,
+			Complexity:    11,
+			Depth:         3,
+			Fields: []FieldComplexityResult{
+				{TypeName: "Query", FieldName: "users", NodeCount: 20, Complexity: 11, Depth: 2},
+				{TypeName: "Query", FieldName: "hello"},
+			},
+			DataSources: []OperationDataSource{
+				{DataSource: "graphql_datasource.Source", URL: "https://users.service/graphql", Fetches: 1},
+				{DataSource: "graphql_datasource.Source", URL: "https://hello.service/graphql", Fetches: 1},
+			},
+		}, analysis)
+	})
+
+	t.Run("multiplies the costs with the variables", func(t *testing.T) {
+		analysis, err := engine.AnalyzeOperation(context.Background(), &Request{
+			Query:     `query Users($first: Int!) { users(first: $first) { id } }`,
+			Variables: []byte(`{"first":100}`),
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 100, analysis.NodeCount)
+	})
+
+	t.Run("doesn't fetch introspection queries", func(t *testing.T) {
+		analysis, err := engine.AnalyzeOperation(context.Background(), &Request{
+			Query: `{ __schema { queryType { name } } }`,
+		})
+		require.NoError(t, err)
+		assert.Empty(t, analysis.DataSources)
+	})
+
+	t.Run("returns validation errors", func(t *testing.T) {
+		_, err := engine.AnalyzeOperation(context.Background(), &Request{
+			Query: `{ users(first: 1) { age } }`,
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `field: age not defined on type: User`)
+	})
+}
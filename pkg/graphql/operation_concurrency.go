@@ -0,0 +1,294 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/lexer/literal"
+)
+
+var (
+	ErrInvalidOperationConcurrencyKey = errors.New("operation concurrency key requires a name, field name or operation name")
+	ErrOperationConcurrencyTimeout    = errors.New("operation waited too long for a conflicting operation")
+)
+
+// OperationConcurrencyConfig serializes mutations with the same concurrency key,
+// e.g. mutations of the same resource, so that they don't race at the upstreams, while mutations with different keys run concurrently.
+// Operations wait for the conflicting operations after they were planned, until their response is resolved.
+type OperationConcurrencyConfig struct {
+	Keys []OperationConcurrencyKey
+	// MaxWait is the maximum duration an operation waits for the conflicting operations, 0 waits until the context of the operation is done
+	MaxWait time.Duration
+}
+
+// OperationConcurrencyKey derives a concurrency key from the arguments of a mutation root field or the variables of an operation.
+// Operations can have multiple keys, they wait for all operations sharing any of them.
+type OperationConcurrencyKey struct {
+	// Name groups the keys of conflicting fields or operations, e.g. "user" for the fields updateUser and deleteUser,
+	// it defaults to the field name or the operation name
+	Name string
+	// OperationName restricts the key to operations with the name, empty matches all mutations
+	OperationName string
+	// FieldName is the mutation root field of which arguments form the key.
+	// If it's empty, the key is formed by the variables of the operation.
+	FieldName string
+	// Arguments are the dot separated paths of the arguments or variables forming the key, e.g. "id" or "input.id".
+	// Without arguments all matching operations are serialized.
+	Arguments []string
+}
+
+func (k *OperationConcurrencyKey) name() string {
+	switch {
+	case k.Name != "":
+		return k.Name
+	case k.FieldName != "":
+		return k.FieldName
+	default:
+		return k.OperationName
+	}
+}
+
+// OperationConcurrencyTimeoutError is returned by Execute if the operation waited longer than the MaxWait for a conflicting operation.
+// Clients should retry later, the HTTP status code is 409 Conflict.
+type OperationConcurrencyTimeoutError struct {
+	// Key is the concurrency key the operation waited for
+	Key string
+}
+
+func (e *OperationConcurrencyTimeoutError) Error() string {
+	return ErrOperationConcurrencyTimeout.Error() + ": " + e.Key
+}
+
+func (e *OperationConcurrencyTimeoutError) Unwrap() error {
+	return ErrOperationConcurrencyTimeout
+}
+
+func (e *OperationConcurrencyTimeoutError) StatusCode() int {
+	return http.StatusConflict
+}
+
+type operationConcurrency struct {
+	config OperationConcurrencyConfig
+	mu     sync.Mutex
+	locks  map[string]*keyLock
+}
+
+// keyLock is held by a single operation, refs counts the operation holding it and the operations waiting for it
+type keyLock struct {
+	held chan struct{}
+	refs int
+}
+
+func newOperationConcurrency(config *OperationConcurrencyConfig) (*operationConcurrency, error) {
+	if config == nil || len(config.Keys) == 0 {
+		return nil, nil
+	}
+	for i := range config.Keys {
+		if config.Keys[i].name() == "" {
+			return nil, ErrInvalidOperationConcurrencyKey
+		}
+	}
+	return &operationConcurrency{
+		config: *config,
+		locks:  map[string]*keyLock{},
+	}, nil
+}
+
+// keys returns the sorted concurrency keys of the normalized operation
+func (o *operationConcurrency) keys(operation *Request) []string {
+	operationRef := ast.InvalidRef
+	for _, rootNode := range operation.document.RootNodes {
+		if rootNode.Kind != ast.NodeKindOperationDefinition {
+			continue
+		}
+		if operation.OperationName != "" && operation.document.OperationDefinitionNameString(rootNode.Ref) != operation.OperationName {
+			continue
+		}
+		operationRef = rootNode.Ref
+		break
+	}
+	if operationRef == ast.InvalidRef || operation.document.OperationDefinitions[operationRef].OperationType != ast.OperationTypeMutation {
+		return nil
+	}
+	operationName := operation.document.OperationDefinitionNameString(operationRef)
+
+	var keys []string
+	for i := range o.config.Keys {
+		key := &o.config.Keys[i]
+		if key.OperationName != "" && key.OperationName != operationName {
+			continue
+		}
+		if key.FieldName == "" {
+			keys = appendUniqueString(keys, concurrencyKey(key, func(path []string) ([]byte, jsonparser.ValueType) {
+				value, dataType, _, err := jsonparser.Get(operation.Variables, path...)
+				if err != nil {
+					return nil, jsonparser.NotExist
+				}
+				return value, dataType
+			}))
+			continue
+		}
+		operationDefinition := operation.document.OperationDefinitions[operationRef]
+		if !operationDefinition.HasSelections {
+			continue
+		}
+		for _, selectionRef := range operation.document.SelectionSets[operationDefinition.SelectionSet].SelectionRefs {
+			selection := operation.document.Selections[selectionRef]
+			if selection.Kind != ast.SelectionKindField || operation.document.FieldNameString(selection.Ref) != key.FieldName {
+				continue
+			}
+			keys = appendUniqueString(keys, concurrencyKey(key, func(path []string) ([]byte, jsonparser.ValueType) {
+				return fieldArgumentJSON(operation, selection.Ref, path)
+			}))
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// concurrencyKey returns the key formed by the values of the arguments, lookup returns the value at the path of an argument or variable.
+// Strings are written without quotes, so that IDs passed as strings and integers form the same key.
+func concurrencyKey(key *OperationConcurrencyKey, lookup func(path []string) ([]byte, jsonparser.ValueType)) string {
+	builder := strings.Builder{}
+	builder.WriteString(key.name())
+	if len(key.Arguments) == 0 {
+		return builder.String()
+	}
+	builder.WriteByte('(')
+	for i, argument := range key.Arguments {
+		if i != 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteString(argument)
+		builder.WriteByte(':')
+		value, dataType := lookup(strings.Split(argument, "."))
+		if dataType == jsonparser.NotExist {
+			builder.Write(literal.NULL)
+			continue
+		}
+		builder.Write(value)
+	}
+	builder.WriteByte(')')
+	return builder.String()
+}
+
+// fieldArgumentJSON returns the value at the path of an argument of the field,
+// variables, e.g. the arguments extracted by the normalization, are looked up in the variables of the operation
+func fieldArgumentJSON(operation *Request, field int, path []string) ([]byte, jsonparser.ValueType) {
+	ref, exists := operation.document.FieldArgument(field, []byte(path[0]))
+	if !exists {
+		return nil, jsonparser.NotExist
+	}
+	argumentValue := operation.document.ArgumentValue(ref)
+	path = path[1:]
+	for len(path) != 0 && argumentValue.Kind == ast.ValueKindObject {
+		objectField := ast.InvalidRef
+		for _, ref := range operation.document.ObjectValues[argumentValue.Ref].Refs {
+			if operation.document.ObjectFieldNameString(ref) == path[0] {
+				objectField = ref
+				break
+			}
+		}
+		if objectField == ast.InvalidRef {
+			return nil, jsonparser.NotExist
+		}
+		argumentValue = operation.document.ObjectFieldValue(objectField)
+		path = path[1:]
+	}
+
+	var value []byte
+	var err error
+	if argumentValue.Kind == ast.ValueKindVariable {
+		value, _, _, err = jsonparser.Get(operation.Variables, operation.document.VariableValueNameString(argumentValue.Ref))
+	} else {
+		value, err = operation.document.ValueToJSON(argumentValue)
+	}
+	if err != nil {
+		return nil, jsonparser.NotExist
+	}
+	value, dataType, _, err := jsonparser.Get(value, path...)
+	if err != nil {
+		return nil, jsonparser.NotExist
+	}
+	return value, dataType
+}
+
+func appendUniqueString(values []string, value string) []string {
+	if containsString(values, value) {
+		return values
+	}
+	return append(values, value)
+}
+
+// acquire waits until none of the keys is held by another operation, the returned release func frees the keys.
+// Keys are acquired in their sorted order, so that operations with overlapping keys don't deadlock.
+func (o *operationConcurrency) acquire(ctx context.Context, keys []string) (release func(), err error) {
+	var timeout <-chan time.Time
+	if o.config.MaxWait > 0 {
+		timer := time.NewTimer(o.config.MaxWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	acquired := make([]string, 0, len(keys))
+	release = func() {
+		for _, key := range acquired {
+			o.release(key)
+		}
+	}
+	for _, key := range keys {
+		lock := o.lock(key)
+		select {
+		case lock.held <- struct{}{}:
+			acquired = append(acquired, key)
+			continue
+		case <-timeout:
+			err = &OperationConcurrencyTimeoutError{Key: key}
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		o.unref(key)
+		release()
+		return nil, err
+	}
+	return release, nil
+}
+
+func (o *operationConcurrency) lock(key string) *keyLock {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	lock, ok := o.locks[key]
+	if !ok {
+		lock = &keyLock{held: make(chan struct{}, 1)}
+		o.locks[key] = lock
+	}
+	lock.refs++
+	return lock
+}
+
+func (o *operationConcurrency) release(key string) {
+	o.mu.Lock()
+	lock := o.locks[key]
+	o.mu.Unlock()
+	<-lock.held
+	o.unref(key)
+}
+
+// unref removes the lock of a key once no operation holds or waits for it
+func (o *operationConcurrency) unref(key string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	lock := o.locks[key]
+	lock.refs--
+	if lock.refs == 0 {
+		delete(o.locks, key)
+	}
+}
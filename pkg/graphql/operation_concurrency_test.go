@@ -0,0 +1,228 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+const operationConcurrencySchema = `
+	type Query { user(id: ID!): User }
+	type Mutation {
+		updateUser(id: ID!, name: String): User
+		deleteUser(id: ID!): Boolean
+		updateUserAddress(input: AddressInput!): User
+	}
+	input AddressInput { userID: ID! city: String }
+	type User { id: ID! name: String }`
+
+func TestOperationConcurrency_Keys(t *testing.T) {
+	schema, err := NewSchemaFromString(operationConcurrencySchema)
+	require.NoError(t, err)
+
+	concurrency, err := newOperationConcurrency(&OperationConcurrencyConfig{
+		Keys: []OperationConcurrencyKey{
+			{Name: "user", FieldName: "updateUser", Arguments: []string{"id"}},
+			{Name: "user", FieldName: "deleteUser", Arguments: []string{"id"}},
+			{Name: "user", FieldName: "updateUserAddress", Arguments: []string{"input.userID"}},
+			{OperationName: "Import"},
+			{Name: "tenant", OperationName: "Rename", Arguments: []string{"tenant"}},
+		},
+	})
+	require.NoError(t, err)
+
+	keys := func(t *testing.T, request Request) []string {
+		t.Helper()
+		result, err := request.Normalize(schema)
+		require.NoError(t, err)
+		require.True(t, result.Successful)
+		return concurrency.keys(&request)
+	}
+
+	t.Run("inline arguments and variables form the same key", func(t *testing.T) {
+		assert.Equal(t, []string{`user(id:1)`}, keys(t, Request{Query: `mutation { updateUser(id: "1", name: "Jens") { id } }`}))
+		assert.Equal(t, []string{`user(id:1)`}, keys(t, Request{Query: `mutation ($id: ID!) { deleteUser(id: $id) }`, Variables: []byte(`{"id":"1"}`)}))
+		assert.Equal(t, []string{`user(id:1)`}, keys(t, Request{Query: `mutation { deleteUser(id: 1) }`}))
+	})
+
+	t.Run("nested arguments", func(t *testing.T) {
+		assert.Equal(t, []string{`user(input.userID:2)`}, keys(t, Request{Query: `mutation { updateUserAddress(input: {userID: "2", city: "Berlin"}) { id } }`}))
+		assert.Equal(t, []string{`user(input.userID:2)`}, keys(t, Request{
+			Query:     `mutation ($userID: ID!, $city: String) { updateUserAddress(input: {userID: $userID, city: $city}) { id } }`,
+			Variables: []byte(`{"userID":"2","city":"Berlin"}`),
+		}))
+	})
+
+	t.Run("multiple root fields", func(t *testing.T) {
+		assert.Equal(t, []string{`user(id:1)`, `user(id:2)`}, keys(t, Request{Query: `mutation { a: updateUser(id: "2") { id } b: deleteUser(id: "1") c: updateUser(id: "1") { id } }`}))
+	})
+
+	t.Run("operation names and variables", func(t *testing.T) {
+		assert.Equal(t, []string{"Import", `user(id:1)`}, keys(t, Request{OperationName: "Import", Query: `mutation Import { deleteUser(id: "1") }`}))
+		assert.Equal(t, []string{`tenant(tenant:acme)`, `user(input.userID:1)`}, keys(t, Request{
+			OperationName: "Rename",
+			Query:         `mutation Rename($tenant: String) { updateUserAddress(input: {userID: "1", city: $tenant}) { id } }`,
+			Variables:     []byte(`{"tenant":"acme"}`),
+		}))
+		assert.Equal(t, []string{`tenant(tenant:null)`, `user(input.userID:1)`}, keys(t, Request{
+			OperationName: "Rename",
+			Query:         `mutation Rename { updateUserAddress(input: {userID: "1"}) { id } }`,
+		}))
+	})
+
+	t.Run("queries have no keys", func(t *testing.T) {
+		assert.Empty(t, keys(t, Request{Query: `{ user(id: "1") { id } }`}))
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		_, err := newOperationConcurrency(&OperationConcurrencyConfig{Keys: []OperationConcurrencyKey{{Arguments: []string{"id"}}}})
+		assert.Equal(t, ErrInvalidOperationConcurrencyKey, err)
+	})
+}
+
+func TestOperationConcurrency_Acquire(t *testing.T) {
+	newConcurrency := func(t *testing.T, maxWait time.Duration) *operationConcurrency {
+		concurrency, err := newOperationConcurrency(&OperationConcurrencyConfig{
+			Keys:    []OperationConcurrencyKey{{FieldName: "updateUser"}},
+			MaxWait: maxWait,
+		})
+		require.NoError(t, err)
+		return concurrency
+	}
+
+	t.Run("operations with the same key wait for each other", func(t *testing.T) {
+		concurrency := newConcurrency(t, 0)
+		release, err := concurrency.acquire(context.Background(), []string{"a", "b"})
+		require.NoError(t, err)
+
+		unrelated, err := concurrency.acquire(context.Background(), []string{"c"})
+		require.NoError(t, err)
+		unrelated()
+
+		acquired := make(chan error)
+		go func() {
+			release, err := concurrency.acquire(context.Background(), []string{"b"})
+			if err == nil {
+				release()
+			}
+			acquired <- err
+		}()
+		select {
+		case <-acquired:
+			t.Fatal("acquired a held key")
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		release()
+		require.NoError(t, <-acquired)
+		assert.Empty(t, concurrency.locks)
+	})
+
+	t.Run("waiting operations time out", func(t *testing.T) {
+		concurrency := newConcurrency(t, time.Millisecond)
+		release, err := concurrency.acquire(context.Background(), []string{"b"})
+		require.NoError(t, err)
+
+		_, err = concurrency.acquire(context.Background(), []string{"a", "b"})
+		assert.ErrorIs(t, err, ErrOperationConcurrencyTimeout)
+		var timeout *OperationConcurrencyTimeoutError
+		require.True(t, errors.As(err, &timeout))
+		assert.Equal(t, "b", timeout.Key)
+		assert.Equal(t, http.StatusConflict, timeout.StatusCode())
+
+		release()
+		assert.Empty(t, concurrency.locks, "the acquired keys are released")
+	})
+
+	t.Run("waiting operations are cancelled with their context", func(t *testing.T) {
+		concurrency := newConcurrency(t, 0)
+		release, err := concurrency.acquire(context.Background(), []string{"a"})
+		require.NoError(t, err)
+		defer release()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = concurrency.acquire(ctx, []string{"a"})
+		assert.Equal(t, context.Canceled, err)
+	})
+}
+
+func TestExecutionEngineV2_OperationConcurrency(t *testing.T) {
+	schema, err := NewSchemaFromString(operationConcurrencySchema)
+	require.NoError(t, err)
+
+	fetching := make(chan struct{})
+	proceed := make(chan struct{})
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes: []plan.TypeField{
+				{TypeName: "Mutation", FieldNames: []string{"updateUser"}},
+			},
+			ChildNodes: []plan.TypeField{
+				{TypeName: "User", FieldNames: []string{"id", "name"}},
+			},
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						body, _ := ioutil.ReadAll(req.Body)
+						if strings.Contains(string(body), `"1"`) {
+							fetching <- struct{}{}
+							<-proceed
+						}
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"updateUser":{"id":"1"}}}`))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    "https://users.service/graphql",
+					Method: "POST",
+				},
+			}),
+		},
+	})
+	engineConf.AddFieldConfiguration(plan.FieldConfiguration{
+		TypeName:  "Mutation",
+		FieldName: "updateUser",
+		Arguments: []plan.ArgumentConfiguration{
+			{Name: "id", SourceType: plan.FieldArgumentSource},
+		},
+	})
+	engineConf.SetOperationConcurrency(OperationConcurrencyConfig{
+		Keys:    []OperationConcurrencyKey{{FieldName: "updateUser", Arguments: []string{"id"}}},
+		MaxWait: 10 * time.Millisecond,
+	})
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	execute := func(id string) error {
+		resultWriter := NewEngineResultWriter()
+		return engine.Execute(context.Background(), &Request{Query: `mutation { updateUser(id: "` + id + `") { id } }`}, &resultWriter)
+	}
+
+	executed := make(chan error)
+	go func() {
+		executed <- execute("1")
+	}()
+	<-fetching
+
+	assert.NoError(t, execute("2"), "unrelated mutations run concurrently")
+	assert.ErrorIs(t, execute("1"), ErrOperationConcurrencyTimeout)
+
+	close(proceed)
+	assert.NoError(t, <-executed)
+}
@@ -0,0 +1,46 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+// PlanOperation prepares the operation like Execute and returns the plan it would be executed with, without executing it,
+// e.g. to inspect its fetches with plan.WalkFetches for cost estimations, security reviews or visualizations.
+// The options are applied like by Execute, e.g. WithFeatureFlags selects the plan for the feature flags.
+// The plan is taken from the plan cache and cached like by Execute, so it's shared with the executions of the operation
+// and must not be modified. The operation and its variables are modified like by Execute, so the request must not be executed afterwards.
+func (e *ExecutionEngineV2) PlanOperation(ctx context.Context, operation *Request, options ...ExecutionOptionsV2) (plan.Plan, error) {
+	if e.config.fragmentArguments {
+		operation.fragmentArguments = true
+	}
+	if e.config.clientNullability {
+		operation.clientNullability = true
+	}
+
+	schema, _, err := e.selectSchemaView(ctx, operation)
+	if err != nil {
+		return nil, err
+	}
+	if err = e.prepareOperation(ctx, operation, schema); err != nil {
+		return nil, err
+	}
+
+	execContext := e.getExecutionCtx()
+	defer e.putExecutionCtx(execContext)
+
+	execContext.prepare(ctx, operation.Variables, operation.request)
+	execContext.resolveContext.Request.FeatureFlags = rollOutFeatureFlags(execContext.resolveContext.Request.FeatureFlags, e.config.featureFlagRollouts)
+	for i := range options {
+		options[i](execContext)
+	}
+
+	var report operationreport.Report
+	cachedPlan := e.getCachedPlan(execContext, &operation.document, &e.config.schema.document, operation.OperationName, &report)
+	if report.HasErrors() {
+		return nil, report
+	}
+	return cachedPlan, nil
+}
@@ -0,0 +1,97 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/rest_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestExecutionEngineV2_PlanOperation(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			users: [User]
+		}
+
+		type User {
+			id: ID!
+			address: Address
+		}
+
+		type Address {
+			city: String
+		}`)
+	require.NoError(t, err)
+
+	client := &http.Client{
+		Transport: testRoundTripper(func(req *http.Request) *http.Response {
+			t.Errorf("unexpected request to %s, the operation must not be executed", req.URL)
+			return &http.Response{StatusCode: http.StatusInternalServerError, Body: http.NoBody}
+		}),
+	}
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		{
+			RootNodes:  []plan.TypeField{{TypeName: "Query", FieldNames: []string{"users"}}},
+			ChildNodes: []plan.TypeField{{TypeName: "User", FieldNames: []string{"id"}}},
+			Factory:    &rest_datasource.Factory{Client: client},
+			Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+				Fetch: rest_datasource.FetchConfiguration{URL: "https://users.service/users", Method: "GET"},
+			}),
+		},
+		{
+			RootNodes:  []plan.TypeField{{TypeName: "User", FieldNames: []string{"address"}}},
+			ChildNodes: []plan.TypeField{{TypeName: "Address", FieldNames: []string{"city"}}},
+			Factory:    &rest_datasource.Factory{Client: client},
+			Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+				Fetch: rest_datasource.FetchConfiguration{URL: "https://addresses.service/addresses/{{ .object.id }}", Method: "GET"},
+			}),
+		},
+	})
+	engineConf.SetFieldConfigurations(plan.FieldConfigurations{
+		{TypeName: "Query", FieldName: "users", DisableDefaultMapping: true},
+		{TypeName: "User", FieldName: "address", DisableDefaultMapping: true, RequiresFields: []string{"id"}},
+	})
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	t.Run("returns the plan of the operation", func(t *testing.T) {
+		planned, err := engine.PlanOperation(context.Background(), &Request{Query: `{ users { address { city } } }`})
+		require.NoError(t, err)
+
+		fetches := plan.Fetches(planned)
+		require.Len(t, fetches, 2)
+		assert.Equal(t, "/data", fetches[0].Path)
+		assert.Equal(t, "rest_datasource.Source", fetches[0].DataSource)
+		assert.Empty(t, fetches[0].DependsOn)
+
+		assert.Equal(t, "/data/users/@", fetches[1].Path)
+		assert.Equal(t, "rest_datasource.Source", fetches[1].DataSource)
+		assert.Equal(t, []int{fetches[0].ID}, fetches[1].DependsOn)
+		var variables []plan.InputVariable
+		for _, segment := range fetches[1].Input {
+			if segment.Variable != nil {
+				variables = append(variables, *segment.Variable)
+			}
+		}
+		require.Len(t, variables, 1)
+		assert.Equal(t, plan.InputVariableKindObject, variables[0].Kind)
+		assert.Equal(t, []string{"id"}, variables[0].Path)
+
+		assert.Equal(t, 1, engine.PlanCacheStats().Size)
+		cached, err := engine.PlanOperation(context.Background(), &Request{Query: `{ users { address { city } } }`})
+		require.NoError(t, err)
+		assert.Same(t, planned, cached)
+	})
+
+	t.Run("invalid operation", func(t *testing.T) {
+		_, err := engine.PlanOperation(context.Background(), &Request{Query: `{ users { name } }`})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,44 @@
+package graphql
+
+import (
+	"context"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+)
+
+// OperationTransform rewrites the parsed operation of a request before it is normalized, validated and planned,
+// e.g. to add the fields required for caching, to remove disallowed selections or to add tenant filters to arguments.
+// The definition is the schema or schema view the operation is executed against.
+// An error aborts the execution of the request and is returned by the engine.
+type OperationTransform interface {
+	TransformOperation(ctx context.Context, request *Request, operation, definition *ast.Document) error
+}
+
+type OperationTransformFunc func(ctx context.Context, request *Request, operation, definition *ast.Document) error
+
+func (f OperationTransformFunc) TransformOperation(ctx context.Context, request *Request, operation, definition *ast.Document) error {
+	return f(ctx, request, operation, definition)
+}
+
+// transformOperation applies the operation transforms in the order they were added
+func (e *ExecutionEngineV2) transformOperation(ctx context.Context, operation *Request, schema *Schema) error {
+	if len(e.config.operationTransforms) == 0 {
+		return nil
+	}
+
+	report := operation.parseQueryOnce()
+	if report.HasErrors() {
+		return report
+	}
+
+	for _, transform := range e.config.operationTransforms {
+		if err := transform.TransformOperation(ctx, operation, &operation.document, &schema.document); err != nil {
+			return err
+		}
+	}
+
+	// the transformed operation has to be normalized and validated again, even if the request was already
+	operation.isNormalized = false
+	operation.validForSchema = nil
+	return nil
+}
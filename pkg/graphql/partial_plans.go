@@ -0,0 +1,403 @@
+package graphql
+
+import (
+	"bytes"
+	"regexp"
+	"sync/atomic"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+	"github.com/wundergraph/graphql-go-tools/pkg/pool"
+)
+
+const defaultPartialPlanCacheSize = 1024
+
+// PartialPlanCacheConfig configures the reuse of the plans of root fields across queries, see EngineV2Configuration.SetPartialPlanCache
+type PartialPlanCacheConfig struct {
+	// MaxSize is the maximum number of cached partial plans, it defaults to 1024
+	MaxSize int
+}
+
+// PartialPlanCacheStats are the counters of the partial plan cache, see ExecutionEngineV2.PartialPlanCacheStats
+type PartialPlanCacheStats struct {
+	Size   int
+	Hits   uint64
+	Misses uint64
+}
+
+// partialPlanCache caches the plans of the root fields of queries which are resolved by the same data source,
+// so that queries sharing root fields with identical normalized selection sets, e.g. from shared fragments,
+// only plan the root fields which weren't planned before.
+// Partial plans are cached before post-processing, queries copy them with unique buffer ids.
+type partialPlanCache struct {
+	cache        *lru.Cache
+	hits, misses uint64
+}
+
+func newPartialPlanCache(config *PartialPlanCacheConfig) (*partialPlanCache, error) {
+	if config == nil {
+		return nil, nil
+	}
+	size := config.MaxSize
+	if size <= 0 {
+		size = defaultPartialPlanCacheSize
+	}
+	cache, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &partialPlanCache{cache: cache}, nil
+}
+
+// PartialPlanCacheStats returns the counters of the partial plan cache, see EngineV2Configuration.SetPartialPlanCache
+func (e *ExecutionEngineV2) PartialPlanCacheStats() PartialPlanCacheStats {
+	if e.partialPlans == nil {
+		return PartialPlanCacheStats{}
+	}
+	return PartialPlanCacheStats{
+		Size:   e.partialPlans.cache.Len(),
+		Hits:   atomic.LoadUint64(&e.partialPlans.hits),
+		Misses: atomic.LoadUint64(&e.partialPlans.misses),
+	}
+}
+
+// planOperation plans the operation, queries are planned from the partial plans of their root fields if the partial plan cache is enabled.
+// It must be called with the plannerMu locked.
+func (e *ExecutionEngineV2) planOperation(operation, definition *ast.Document, operationName string, featureFlags resolve.FeatureFlags, report *operationreport.Report) plan.Plan {
+	if e.partialPlans != nil {
+		if p, ok := e.planFromPartialPlans(operation, definition, operationName, featureFlags, report); ok || report.HasErrors() {
+			return p
+		}
+	}
+	return e.planner.Plan(operation, definition, operationName, report)
+}
+
+// partialPlanUnit are the root fields of a query which are planned together, as they are resolved by the same data source
+type partialPlanUnit struct {
+	selections []int
+}
+
+// planFromPartialPlans plans each unit of root fields of the query on its own, reusing the cached plans of units planned before,
+// and merges the plans of the units. ok is false if the operation can't be planned partially, e.g. because it's a mutation.
+func (e *ExecutionEngineV2) planFromPartialPlans(operation, definition *ast.Document, operationName string, featureFlags resolve.FeatureFlags, report *operationreport.Report) (p plan.Plan, ok bool) {
+	operationRef, ok := selectedOperationDefinition(operation, operationName)
+	if !ok || operation.OperationDefinitions[operationRef].OperationType != ast.OperationTypeQuery {
+		return nil, false
+	}
+
+	operationDefinition := &operation.OperationDefinitions[operationRef]
+	rootSelectionSet := &operation.SelectionSets[operationDefinition.SelectionSet]
+	units, ok := e.partialPlanUnits(operation, definition, rootSelectionSet.SelectionRefs)
+	if !ok {
+		return nil, false
+	}
+
+	// the units are planned as the only selections of the only operation of the document, the document is restored afterwards
+	rootNodes, rootSelections := operation.RootNodes, rootSelectionSet.SelectionRefs
+	variableDefinitions, hasVariableDefinitions := operationDefinition.VariableDefinitions.Refs, operationDefinition.HasVariableDefinitions
+	// planners may remove directives of the operation, e.g. @removeNullVariables, so every unit is planned with a copy of them
+	directives, hasDirectives := append([]int(nil), operationDefinition.Directives.Refs...), operationDefinition.HasDirectives
+	defer func() {
+		operation.RootNodes = rootNodes
+		// the selection set might have been reallocated by the planner
+		operation.SelectionSets[operationDefinition.SelectionSet].SelectionRefs = rootSelections
+		operationDefinition.VariableDefinitions.Refs, operationDefinition.HasVariableDefinitions = variableDefinitions, hasVariableDefinitions
+		operationDefinition.Directives.Refs, operationDefinition.HasDirectives = directives, hasDirectives
+	}()
+	operation.RootNodes = []ast.Node{{Kind: ast.NodeKindOperationDefinition, Ref: operationRef}}
+
+	unitPlans := make([]*plan.SynchronousResponsePlan, 0, len(units))
+	for _, unit := range units {
+		operation.SelectionSets[operationDefinition.SelectionSet].SelectionRefs = unit.selections
+		operationDefinition.Directives.Refs = append([]int(nil), directives...)
+		operationDefinition.HasDirectives = hasDirectives
+		operationDefinition.VariableDefinitions.Refs = nil
+		printed, err := astprinter.PrintString(operation, definition)
+		if err != nil {
+			report.AddInternalError(err)
+			return nil, false
+		}
+		operationDefinition.VariableDefinitions.Refs = usedVariableDefinitions(operation, variableDefinitions, printed)
+		operationDefinition.HasVariableDefinitions = len(operationDefinition.VariableDefinitions.Refs) != 0
+
+		unitPlan, ok := e.partialPlan(operation, definition, operationName, featureFlags, report)
+		if !ok {
+			return nil, false
+		}
+		unitPlans = append(unitPlans, unitPlan)
+	}
+
+	return mergePartialPlans(rootSelections, units, unitPlans)
+}
+
+// partialPlan returns the cached plan of the unit selected by the operation or plans it
+func (e *ExecutionEngineV2) partialPlan(operation, definition *ast.Document, operationName string, featureFlags resolve.FeatureFlags, report *operationreport.Report) (*plan.SynchronousResponsePlan, bool) {
+	cacheKey, ok := e.partialPlanCacheKey(operation, definition, featureFlags, report)
+	if !ok {
+		return nil, false
+	}
+	if cached, ok := e.partialPlans.cache.Get(cacheKey); ok {
+		atomic.AddUint64(&e.partialPlans.hits, 1)
+		return cached.(*plan.SynchronousResponsePlan), true
+	}
+	atomic.AddUint64(&e.partialPlans.misses, 1)
+
+	planned, ok := e.planner.Plan(operation, definition, operationName, report).(*plan.SynchronousResponsePlan)
+	if report.HasErrors() || !ok {
+		return nil, false
+	}
+	if _, ok := planned.Response.Data.(*resolve.Object); !ok {
+		return nil, false
+	}
+	e.partialPlans.cache.Add(cacheKey, planned)
+	return planned, true
+}
+
+// partialPlanCacheKey is the hash of the unit printed without operation name, the feature flags and the values of skip/include variables
+func (e *ExecutionEngineV2) partialPlanCacheKey(operation, definition *ast.Document, featureFlags resolve.FeatureFlags, report *operationreport.Report) (uint64, bool) {
+	operationDefinition := &operation.OperationDefinitions[operation.RootNodes[0].Ref]
+	name := operationDefinition.Name
+	operationDefinition.Name = ast.ByteSliceReference{}
+	defer func() {
+		operationDefinition.Name = name
+	}()
+
+	hash := pool.Hash64.Get()
+	hash.Reset()
+	defer pool.Hash64.Put(hash)
+	if err := astprinter.Print(operation, definition, hash); err != nil {
+		report.AddInternalError(err)
+		return 0, false
+	}
+	if e.config.plannerConfig.FoldSkipIncludeVariables {
+		writeSkipIncludeVariables(operation, hash)
+	}
+	for i := range featureFlags {
+		_, _ = hash.Write([]byte(featureFlags[i]))
+		_, _ = hash.Write([]byte(";"))
+	}
+	return hash.Sum64(), true
+}
+
+// partialPlanUnits groups the root fields by the first data source with the root field,
+// as root fields of the same data source are fetched with a single request.
+// ok is false if the selections aren't fields only.
+func (e *ExecutionEngineV2) partialPlanUnits(operation, definition *ast.Document, selections []int) (units []partialPlanUnit, ok bool) {
+	typeName := string(definition.Index.QueryTypeName)
+	unitOfDataSource := map[int]int{}
+	for _, selection := range selections {
+		if operation.Selections[selection].Kind != ast.SelectionKindField {
+			return nil, false
+		}
+		fieldName := operation.FieldNameString(operation.Selections[selection].Ref)
+		dataSource := -1
+		for i := range e.config.plannerConfig.DataSources {
+			if e.config.plannerConfig.DataSources[i].HasRootNode(typeName, fieldName) {
+				dataSource = i
+				break
+			}
+		}
+		unit, exists := unitOfDataSource[dataSource]
+		if !exists {
+			unit = len(units)
+			unitOfDataSource[dataSource] = unit
+			units = append(units, partialPlanUnit{})
+		}
+		units[unit].selections = append(units[unit].selections, selection)
+	}
+	return units, len(units) != 0
+}
+
+// mergePartialPlans merges the plans of the units into the plan of the query, with the root fields in the order of the query
+// and the root fetches of the units fetched in parallel. ok is false if the root fields of a plan don't match its unit,
+// e.g. because fields were removed by skip/include folding.
+func mergePartialPlans(selections []int, units []partialPlanUnit, unitPlans []*plan.SynchronousResponsePlan) (p plan.Plan, ok bool) {
+	fieldOfSelection := make(map[int]*resolve.Field, len(selections))
+	var (
+		fetches      []resolve.Fetch
+		nextBufferID int
+	)
+	root := &resolve.Object{}
+	for i, unitPlan := range unitPlans {
+		unitRoot := unitPlan.Response.Data.(*resolve.Object)
+		if len(unitRoot.Fields) != len(units[i].selections) {
+			return nil, false
+		}
+		renumbering := &bufferIDRenumbering{offset: nextBufferID, next: nextBufferID}
+		copied := renumbering.node(unitRoot).(*resolve.Object)
+		nextBufferID = renumbering.next
+
+		for j, selection := range units[i].selections {
+			fieldOfSelection[selection] = copied.Fields[j]
+		}
+		switch fetch := copied.Fetch.(type) {
+		case nil:
+		case *resolve.ParallelFetch:
+			fetches = append(fetches, fetch.Fetches...)
+		default:
+			fetches = append(fetches, fetch)
+		}
+		root.Nullable, root.Path, root.UnescapeResponseJson, root.TypeNameResolver = copied.Nullable, copied.Path, copied.UnescapeResponseJson, copied.TypeNameResolver
+	}
+
+	root.Fields = make([]*resolve.Field, 0, len(selections))
+	for _, selection := range selections {
+		root.Fields = append(root.Fields, fieldOfSelection[selection])
+	}
+	switch len(fetches) {
+	case 0:
+	case 1:
+		root.Fetch = fetches[0]
+	default:
+		root.Fetch = &resolve.ParallelFetch{Fetches: fetches}
+	}
+
+	return &plan.SynchronousResponsePlan{
+		Response: &resolve.GraphQLResponse{
+			Data:            root,
+			RenameTypeNames: unitPlans[0].Response.RenameTypeNames,
+		},
+		FlushInterval: unitPlans[0].FlushInterval,
+	}, true
+}
+
+// selectedOperationDefinition returns the operation definition selected by the operation name like the planner
+func selectedOperationDefinition(operation *ast.Document, operationName string) (ref int, ok bool) {
+	for _, node := range operation.RootNodes {
+		if node.Kind != ast.NodeKindOperationDefinition {
+			continue
+		}
+		if operationName == "" || operation.OperationDefinitionNameString(node.Ref) == operationName {
+			if ok {
+				// the operation name is required to select one of multiple operations
+				return 0, false
+			}
+			ref, ok = node.Ref, true
+		}
+	}
+	return ref, ok
+}
+
+var variableNameSuffix = regexp.MustCompile(`^[_0-9A-Za-z]`)
+
+// usedVariableDefinitions returns the variable definitions used by the printed operation.
+// Variables mentioned in string values are considered used, which only prevents reusing the plan.
+func usedVariableDefinitions(operation *ast.Document, variableDefinitions []int, printed string) []int {
+	used := make([]int, 0, len(variableDefinitions))
+	printedBytes := []byte(printed)
+	for _, ref := range variableDefinitions {
+		variable := []byte("$" + operation.VariableDefinitionNameString(ref))
+		for rest := printedBytes; ; {
+			i := bytes.Index(rest, variable)
+			if i == -1 {
+				break
+			}
+			rest = rest[i+len(variable):]
+			if !variableNameSuffix.Match(rest) {
+				used = append(used, ref)
+				break
+			}
+		}
+	}
+	return used
+}
+
+// bufferIDRenumbering copies the nodes of a cached partial plan with unique buffer ids,
+// the buffer ids of the copy start at the offset
+type bufferIDRenumbering struct {
+	offset int
+	// next is the first buffer id which isn't used by the copied nodes
+	next int
+}
+
+func (r *bufferIDRenumbering) bufferID(id int) int {
+	renumbered := r.offset + id
+	if renumbered >= r.next {
+		r.next = renumbered + 1
+	}
+	return renumbered
+}
+
+func (r *bufferIDRenumbering) node(node resolve.Node) resolve.Node {
+	switch n := node.(type) {
+	case *resolve.Object:
+		object := *n
+		object.Fetch = r.fetch(n.Fetch)
+		object.Fields = make([]*resolve.Field, len(n.Fields))
+		for i := range n.Fields {
+			field := *n.Fields[i]
+			if field.HasBuffer {
+				field.BufferID = r.bufferID(field.BufferID)
+			}
+			field.Value = r.node(field.Value)
+			object.Fields[i] = &field
+		}
+		return &object
+	case *resolve.Array:
+		array := *n
+		array.Item = r.node(n.Item)
+		return &array
+	case *resolve.String:
+		value := *n
+		return &value
+	case *resolve.Boolean:
+		value := *n
+		return &value
+	case *resolve.Integer:
+		value := *n
+		return &value
+	case *resolve.Float:
+		value := *n
+		return &value
+	case *resolve.Null:
+		value := *n
+		return &value
+	case *resolve.EmptyObject:
+		value := *n
+		return &value
+	case *resolve.EmptyArray:
+		value := *n
+		return &value
+	case *resolve.Computed:
+		value := *n
+		return &value
+	default:
+		return node
+	}
+}
+
+func (r *bufferIDRenumbering) fetch(fetch resolve.Fetch) resolve.Fetch {
+	switch f := fetch.(type) {
+	case *resolve.SingleFetch:
+		return r.singleFetch(f)
+	case *resolve.BatchFetch:
+		batchFetch := *f
+		batchFetch.Fetch = r.singleFetch(f.Fetch)
+		return &batchFetch
+	case *resolve.ParallelFetch:
+		return &resolve.ParallelFetch{Fetches: r.fetches(f.Fetches)}
+	case *resolve.SerialFetch:
+		return &resolve.SerialFetch{Fetches: r.fetches(f.Fetches)}
+	default:
+		return fetch
+	}
+}
+
+func (r *bufferIDRenumbering) fetches(fetches []resolve.Fetch) []resolve.Fetch {
+	copied := make([]resolve.Fetch, len(fetches))
+	for i := range fetches {
+		copied[i] = r.fetch(fetches[i])
+	}
+	return copied
+}
+
+func (r *bufferIDRenumbering) singleFetch(fetch *resolve.SingleFetch) *resolve.SingleFetch {
+	copied := *fetch
+	copied.BufferId = r.bufferID(fetch.BufferId)
+	return &copied
+}
@@ -0,0 +1,138 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestExecutionEngineV2_PartialPlanCache(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			user(id: ID): User
+			me: User
+			topProducts: [Product]
+			product(upc: String): Product
+		}
+
+		type Mutation {
+			addProduct(upc: String): Product
+		}
+
+		type User {
+			id: ID
+			name: String
+		}
+
+		type Product {
+			upc: String
+		}`)
+	require.NoError(t, err)
+
+	var (
+		mu              sync.Mutex
+		upstreamQueries []string
+	)
+	dataSource := func(url string, rootNodes []plan.TypeField, childNodes []plan.TypeField, response string) plan.DataSourceConfiguration {
+		return plan.DataSourceConfiguration{
+			RootNodes:  rootNodes,
+			ChildNodes: childNodes,
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						body, _ := ioutil.ReadAll(req.Body)
+						mu.Lock()
+						upstreamQueries = append(upstreamQueries, string(body))
+						mu.Unlock()
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(response))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    url,
+					Method: "POST",
+				},
+			}),
+		}
+	}
+
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		dataSource("https://users/graphql",
+			[]plan.TypeField{{TypeName: "Query", FieldNames: []string{"user", "me"}}},
+			[]plan.TypeField{{TypeName: "User", FieldNames: []string{"id", "name"}}},
+			`{"data":{"user":{"id":"1","name":"Jens"},"me":{"id":"2","name":"Jannik"}}}`,
+		),
+		dataSource("https://products/graphql",
+			[]plan.TypeField{{TypeName: "Query", FieldNames: []string{"topProducts", "product"}}, {TypeName: "Mutation", FieldNames: []string{"addProduct"}}},
+			[]plan.TypeField{{TypeName: "Product", FieldNames: []string{"upc"}}},
+			`{"data":{"topProducts":[{"upc":"top-1"},{"upc":"top-2"}],"product":{"upc":"1"},"addProduct":{"upc":"new"}}}`,
+		),
+	})
+	engineConf.SetFieldConfigurations(plan.FieldConfigurations{
+		{TypeName: "Query", FieldName: "user", Arguments: []plan.ArgumentConfiguration{{Name: "id", SourceType: plan.FieldArgumentSource}}},
+		{TypeName: "Query", FieldName: "product", Arguments: []plan.ArgumentConfiguration{{Name: "upc", SourceType: plan.FieldArgumentSource}}},
+		{TypeName: "Mutation", FieldName: "addProduct", Arguments: []plan.ArgumentConfiguration{{Name: "upc", SourceType: plan.FieldArgumentSource}}},
+	})
+	engineConf.SetPartialPlanCache(PartialPlanCacheConfig{})
+
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	execute := func(t *testing.T, query, operationName, variables string) (string, []string) {
+		upstreamQueries = nil
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: query, OperationName: operationName, Variables: json.RawMessage(variables)}, &resultWriter)
+		require.NoError(t, err)
+		sort.Strings(upstreamQueries)
+		return resultWriter.String(), upstreamQueries
+	}
+
+	t.Run("plans the root fields of each data source once", func(t *testing.T) {
+		response, queries := execute(t, `query Users($id: ID) { user(id: $id) { id name } me { name } topProducts { upc } }`, "Users", `{"id":"1"}`)
+		assert.Equal(t, `{"data":{"user":{"id":"1","name":"Jens"},"me":{"name":"Jannik"},"topProducts":[{"upc":"top-1"},{"upc":"top-2"}]}}`, response)
+		assert.Equal(t, []string{
+			`{"query":"query($id: ID){user(id: $id){id name} me {name}}","variables":{"id":"1"}}`,
+			`{"query":"{topProducts {upc}}"}`,
+		}, queries)
+		assert.Equal(t, PartialPlanCacheStats{Size: 2, Misses: 2}, engine.PartialPlanCacheStats())
+	})
+
+	t.Run("reuses the plans of root fields of other queries", func(t *testing.T) {
+		response, queries := execute(t, `query Products($upc: String, $id: ID) { product(upc: $upc) { upc } user(id: $id) { id name } me { name } }`, "Products", `{"id":"1","upc":"1"}`)
+		assert.Equal(t, `{"data":{"product":{"upc":"1"},"user":{"id":"1","name":"Jens"},"me":{"name":"Jannik"}}}`, response)
+		assert.Equal(t, []string{
+			`{"query":"query($id: ID){user(id: $id){id name} me {name}}","variables":{"id":"1"}}`,
+			`{"query":"query($upc: String){product(upc: $upc){upc}}","variables":{"upc":"1"}}`,
+		}, queries)
+		assert.Equal(t, PartialPlanCacheStats{Size: 3, Hits: 1, Misses: 3}, engine.PartialPlanCacheStats())
+	})
+
+	t.Run("plans mutations completely", func(t *testing.T) {
+		response, _ := execute(t, `mutation Add { addProduct(upc: "new") { upc } }`, "Add", "")
+		assert.Equal(t, `{"data":{"addProduct":{"upc":"new"}}}`, response)
+		assert.Equal(t, PartialPlanCacheStats{Size: 3, Hits: 1, Misses: 3}, engine.PartialPlanCacheStats())
+	})
+
+	t.Run("plans the directives of the operation for each data source", func(t *testing.T) {
+		response, queries := execute(t, `query Nulls($id: ID, $upc: String) @removeNullVariables { user(id: $id) { id } product(upc: $upc) { upc } }`, "Nulls", `{"id":null,"upc":null}`)
+		assert.Equal(t, `{"data":{"user":{"id":"1"},"product":{"upc":"1"}}}`, response)
+		assert.Equal(t, []string{
+			`{"query":"query($id: ID){user(id: $id){id}}","variables":{}}`,
+			`{"query":"query($upc: String){product(upc: $upc){upc}}","variables":{}}`,
+		}, queries)
+	})
+}
@@ -0,0 +1,372 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+)
+
+var (
+	defaultPersistedOperationCodeExtensions = []string{".js", ".jsx", ".ts", ".tsx"}
+	defaultPersistedOperationTemplateTags   = []string{"gql", "graphql"}
+)
+
+// PersistedOperationManifest contains the persisted operations of clients, the canonical operations by their hash.
+// It's the format of SchemaBundle.PersistedOperations and can be stored in a PersistedOperationStore.
+type PersistedOperationManifest map[string]string
+
+// Store stores the operations of the manifest in the store
+func (m PersistedOperationManifest) Store(store PersistedOperationStore) {
+	for hash, operation := range m {
+		store.Set(hash, operation)
+	}
+}
+
+// Write writes the manifest as JSON object with the operations by their hash
+func (m PersistedOperationManifest) Write(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]string(m))
+}
+
+// CanonicalPersistedOperation returns the canonical form of an operation document: it's printed without comments and insignificant whitespace,
+// the operations in their order followed by the fragments sorted by their name.
+// Semantically equal documents which only differ in formatting or the order of their fragments have the same canonical form.
+func CanonicalPersistedOperation(document string) (string, error) {
+	doc, report := astparser.ParseGraphqlDocumentString(document)
+	if report.HasErrors() {
+		return "", report
+	}
+	var operations, fragments []ast.Node
+	for _, node := range doc.RootNodes {
+		switch node.Kind {
+		case ast.NodeKindOperationDefinition:
+			operations = append(operations, node)
+		case ast.NodeKindFragmentDefinition:
+			fragments = append(fragments, node)
+		}
+	}
+	sort.SliceStable(fragments, func(i, j int) bool {
+		return doc.FragmentDefinitionNameString(fragments[i].Ref) < doc.FragmentDefinitionNameString(fragments[j].Ref)
+	})
+
+	// the root nodes are printed one by one, so that they are separated the same way regardless of their kind
+	printed := make([]string, 0, len(operations)+len(fragments))
+	for _, node := range append(operations, fragments...) {
+		doc.RootNodes = []ast.Node{node}
+		definition, err := astprinter.PrintString(&doc, nil)
+		if err != nil {
+			return "", err
+		}
+		printed = append(printed, definition)
+	}
+	return strings.Join(printed, " "), nil
+}
+
+// PersistedOperationHash returns the hash of the canonical form of the operation document, the hex encoded sha256 hash.
+// It's used by ExtractPersistedOperations, so gateways can hash operations the same way at runtime, e.g. to verify a manifest.
+func PersistedOperationHash(document string) (string, error) {
+	canonical, err := CanonicalPersistedOperation(document)
+	if err != nil {
+		return "", err
+	}
+	return persistedOperationHash(canonical), nil
+}
+
+func persistedOperationHash(canonical string) string {
+	hash := sha256.Sum256([]byte(canonical))
+	return hex.EncodeToString(hash[:])
+}
+
+type PersistedOperationExtractionConfig struct {
+	// Schema validates the extracted operations, the operations aren't validated if it's nil
+	Schema *Schema
+	// CodeExtensions are the extensions of the client code files of which tagged template literals are extracted,
+	// it defaults to .js, .jsx, .ts and .tsx. Files with the extension .graphql or .gql are always read as documents.
+	CodeExtensions []string
+	// TemplateTags are the tags of the template literals containing operations and fragments, e.g. gql`query { me }`,
+	// it defaults to gql and graphql. Interpolations, e.g. of fragments with ${Fragment}, are removed, as fragments are shared across all files.
+	TemplateTags []string
+}
+
+// ExtractPersistedOperations scans the directory for .graphql and .gql files and the tagged template literals of client code files,
+// and returns the manifest of all operations found.
+// Each operation is persisted as standalone document with the fragments it uses, which can be defined in any of the files.
+// The operations are persisted in their canonical form by the hash of it, see CanonicalPersistedOperation and PersistedOperationHash.
+func ExtractPersistedOperations(dir string, config PersistedOperationExtractionConfig) (PersistedOperationManifest, error) {
+	if len(config.CodeExtensions) == 0 {
+		config.CodeExtensions = defaultPersistedOperationCodeExtensions
+	}
+	if len(config.TemplateTags) == 0 {
+		config.TemplateTags = defaultPersistedOperationTemplateTags
+	}
+
+	extraction := &persistedOperationExtraction{
+		fragments: map[string]extractedDefinition{},
+	}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == "node_modules" || (strings.HasPrefix(entry.Name(), ".") && path != dir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		extension := filepath.Ext(path)
+		isDocument := extension == ".graphql" || extension == ".gql"
+		if !isDocument && !containsString(config.CodeExtensions, extension) {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		documents := []string{string(content)}
+		if !isDocument {
+			documents = extractTemplateLiterals(string(content), config.TemplateTags)
+		}
+		for _, document := range documents {
+			if err = extraction.add(path, document); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return extraction.manifest(config.Schema)
+}
+
+// extractedDefinition is a printed operation or fragment definition and the fragments it spreads
+type extractedDefinition struct {
+	file      string
+	name      string
+	printed   string
+	fragments []string
+}
+
+type persistedOperationExtraction struct {
+	operations []extractedDefinition
+	fragments  map[string]extractedDefinition
+}
+
+func (e *persistedOperationExtraction) add(file, document string) error {
+	doc, report := astparser.ParseGraphqlDocumentString(document)
+	if report.HasErrors() {
+		return report
+	}
+	rootNodes := doc.RootNodes
+	for _, node := range rootNodes {
+		var selectionSet int
+		definition := extractedDefinition{file: file}
+		switch node.Kind {
+		case ast.NodeKindOperationDefinition:
+			definition.name = doc.OperationDefinitionNameString(node.Ref)
+			selectionSet = doc.OperationDefinitions[node.Ref].SelectionSet
+		case ast.NodeKindFragmentDefinition:
+			definition.name = doc.FragmentDefinitionNameString(node.Ref)
+			selectionSet = doc.FragmentDefinitions[node.Ref].SelectionSet
+		default:
+			continue
+		}
+		doc.RootNodes = []ast.Node{node}
+		printed, err := astprinter.PrintString(&doc, nil)
+		if err != nil {
+			return err
+		}
+		definition.printed = printed
+		definition.fragments = spreadFragmentNames(&doc, selectionSet, nil)
+
+		if node.Kind == ast.NodeKindOperationDefinition {
+			e.operations = append(e.operations, definition)
+			continue
+		}
+		if existing, ok := e.fragments[definition.name]; ok && existing.printed != definition.printed {
+			return fmt.Errorf("fragment %s is already defined differently in %s", definition.name, existing.file)
+		}
+		e.fragments[definition.name] = definition
+	}
+	return nil
+}
+
+// spreadFragmentNames returns the names of the fragments spread in the selection set
+func spreadFragmentNames(doc *ast.Document, selectionSet int, names []string) []string {
+	for _, ref := range doc.SelectionSets[selectionSet].SelectionRefs {
+		selection := doc.Selections[ref]
+		switch selection.Kind {
+		case ast.SelectionKindField:
+			if doc.Fields[selection.Ref].HasSelections {
+				names = spreadFragmentNames(doc, doc.Fields[selection.Ref].SelectionSet, names)
+			}
+		case ast.SelectionKindInlineFragment:
+			names = spreadFragmentNames(doc, doc.InlineFragments[selection.Ref].SelectionSet, names)
+		case ast.SelectionKindFragmentSpread:
+			names = appendUniqueString(names, doc.FragmentSpreadNameString(selection.Ref))
+		}
+	}
+	return names
+}
+
+// usedFragments returns the fragments used by the definition, including the fragments used by the fragments
+func (e *persistedOperationExtraction) usedFragments(definition extractedDefinition, used []string) ([]string, error) {
+	for _, name := range definition.fragments {
+		if containsString(used, name) {
+			continue
+		}
+		fragment, ok := e.fragments[name]
+		if !ok {
+			return nil, fmt.Errorf("%s: fragment %s of %s is not defined", definition.file, name, definition.displayName())
+		}
+		var err error
+		if used, err = e.usedFragments(fragment, append(used, name)); err != nil {
+			return nil, err
+		}
+	}
+	return used, nil
+}
+
+func (d *extractedDefinition) displayName() string {
+	if d.name == "" {
+		return "anonymous operation"
+	}
+	return "operation " + d.name
+}
+
+func (e *persistedOperationExtraction) manifest(schema *Schema) (PersistedOperationManifest, error) {
+	manifest := PersistedOperationManifest{}
+	for _, operation := range e.operations {
+		fragments, err := e.usedFragments(operation, nil)
+		if err != nil {
+			return nil, err
+		}
+		document := operation.printed
+		for _, name := range fragments {
+			document += " " + e.fragments[name].printed
+		}
+		canonical, err := CanonicalPersistedOperation(document)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", operation.file, operation.displayName(), err)
+		}
+		if schema != nil {
+			if err = validatePersistedOperation(schema, canonical); err != nil {
+				return nil, fmt.Errorf("%s: %s: %w", operation.file, operation.displayName(), err)
+			}
+		}
+		manifest[persistedOperationHash(canonical)] = canonical
+	}
+	return manifest, nil
+}
+
+func validatePersistedOperation(schema *Schema, operation string) error {
+	request := Request{Query: operation}
+	normalization, err := request.Normalize(schema)
+	if err != nil {
+		return err
+	}
+	if !normalization.Successful {
+		return normalization.Errors
+	}
+	result, err := request.ValidateForSchema(schema)
+	if err != nil {
+		return err
+	}
+	if !result.Valid {
+		return result.Errors
+	}
+	return nil
+}
+
+// extractTemplateLiterals returns the content of the template literals tagged with one of the tags, without interpolations
+func extractTemplateLiterals(code string, tags []string) []string {
+	var literals []string
+	for i := 0; i < len(code); i++ {
+		tag := templateTagAt(code, i, tags)
+		if tag == "" {
+			continue
+		}
+		start := i + len(tag)
+		for start < len(code) && (code[start] == ' ' || code[start] == '\t') {
+			start++
+		}
+		if start >= len(code) || code[start] != '`' {
+			continue
+		}
+		literal, end := templateLiteral(code, start+1)
+		literals = append(literals, literal)
+		i = end
+	}
+	return literals
+}
+
+// templateTagAt returns the tag starting at i if it's not part of a longer identifier
+func templateTagAt(code string, i int, tags []string) string {
+	if i > 0 && isIdentifierByte(code[i-1]) {
+		return ""
+	}
+	for _, tag := range tags {
+		if strings.HasPrefix(code[i:], tag) && (i+len(tag) == len(code) || !isIdentifierByte(code[i+len(tag)])) {
+			return tag
+		}
+	}
+	return ""
+}
+
+func isIdentifierByte(b byte) bool {
+	return b == '_' || b == '$' || b == '.' || (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// templateLiteral returns the content of the template literal starting at i and the index of its closing backtick
+func templateLiteral(code string, i int) (string, int) {
+	builder := strings.Builder{}
+	for ; i < len(code); i++ {
+		switch code[i] {
+		case '\\':
+			if i+1 < len(code) {
+				i++
+				builder.WriteByte(code[i])
+			}
+		case '`':
+			return builder.String(), i
+		case '$':
+			if i+1 < len(code) && code[i+1] == '{' {
+				i = skipInterpolation(code, i+2)
+				continue
+			}
+			builder.WriteByte(code[i])
+		default:
+			builder.WriteByte(code[i])
+		}
+	}
+	return builder.String(), i
+}
+
+// skipInterpolation returns the index of the closing brace of the interpolation starting at i
+func skipInterpolation(code string, i int) int {
+	depth := 1
+	for ; i < len(code); i++ {
+		switch code[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return i
+}
@@ -0,0 +1,120 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalPersistedOperation(t *testing.T) {
+	canonical, err := CanonicalPersistedOperation(`
+		# fetches the hero
+		query Hero($episode: Episode) {
+			hero(episode: $episode) { ...character friends { ...name } }
+		}
+		fragment name on Character { name }
+		fragment character on Character { id ...name }`)
+	require.NoError(t, err)
+	assert.Equal(t, `query Hero($episode: Episode){hero(episode: $episode){...character friends {...name}}} fragment character on Character {id ...name} fragment name on Character {name}`, canonical)
+
+	reordered, err := CanonicalPersistedOperation(`fragment character on Character { id ...name } fragment name on Character { name }
+		query Hero($episode: Episode) { hero(episode: $episode) { ...character friends { ...name } } }`)
+	require.NoError(t, err)
+	assert.Equal(t, canonical, reordered)
+
+	hash, err := PersistedOperationHash(reordered)
+	require.NoError(t, err)
+	assert.Equal(t, persistedOperationHash(canonical), hash)
+	assert.Len(t, hash, 64)
+
+	_, err = CanonicalPersistedOperation(`query {`)
+	assert.Error(t, err)
+}
+
+func TestExtractPersistedOperations(t *testing.T) {
+	writeFiles := func(t *testing.T, files map[string]string) string {
+		dir := t.TempDir()
+		for name, content := range files {
+			path := filepath.Join(dir, name)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+			require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		}
+		return dir
+	}
+	schema := starwarsSchema(t)
+
+	t.Run("extracts operations of documents and template literals", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			"fragments.graphql": `fragment name on Character { name }`,
+			"src/hero.ts": "import { gql } from '@apollo/client'\n" +
+				"const NAME = gql`fragment unused on Character { id }`\n" +
+				"export const HERO = gql`\n  query Hero {\n    hero { ...name }\n  }\n  ${NAME}\n`\n",
+			"src/droid.jsx":                  "const query = graphql`query Droid($id: ID!) { droid(id: $id) { name } }`; const other = notgql`{ hero { id } }`",
+			"src/readme.md":                  "gql`{ invalid`",
+			"node_modules/lib/index.js":      "gql`{ invalid`",
+			"src/queries/search.gql":         `query Search { search(name: "r2") { ... on Droid { primaryFunction } } }`,
+			"src/queries/hero_again.graphql": "query Hero { hero { ...name } }",
+		})
+
+		manifest, err := ExtractPersistedOperations(dir, PersistedOperationExtractionConfig{Schema: schema})
+		require.NoError(t, err)
+
+		operations := []string{
+			`query Hero {hero {...name}} fragment name on Character {name}`,
+			`query Droid($id: ID!){droid(id: $id){name}}`,
+			`query Search {search(name: "r2"){... on Droid {primaryFunction}}}`,
+		}
+		expected := PersistedOperationManifest{}
+		for _, operation := range operations {
+			hash, err := PersistedOperationHash(operation)
+			require.NoError(t, err)
+			expected[hash] = operation
+		}
+		assert.Equal(t, expected, manifest)
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, manifest.Write(buf))
+		var written map[string]string
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &written))
+		assert.Equal(t, map[string]string(expected), written)
+
+		store, err := NewInMemoryPersistedOperationStore(InMemoryPersistedOperationStoreConfig{MaxSize: 10})
+		require.NoError(t, err)
+		manifest.Store(store)
+		assert.Equal(t, 3, store.Stats().Size)
+	})
+
+	t.Run("undefined fragment", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			"hero.graphql": `query Hero { hero { ...name } }`,
+		})
+		_, err := ExtractPersistedOperations(dir, PersistedOperationExtractionConfig{})
+		assert.EqualError(t, err, filepath.Join(dir, "hero.graphql")+": fragment name of operation Hero is not defined")
+	})
+
+	t.Run("conflicting fragments", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			"a.graphql": `fragment name on Character { name }`,
+			"b.graphql": `fragment name on Character { id }`,
+		})
+		_, err := ExtractPersistedOperations(dir, PersistedOperationExtractionConfig{})
+		assert.EqualError(t, err, filepath.Join(dir, "b.graphql")+": fragment name is already defined differently in "+filepath.Join(dir, "a.graphql"))
+	})
+
+	t.Run("invalid operations for the schema", func(t *testing.T) {
+		dir := writeFiles(t, map[string]string{
+			"hero.graphql": `query Hero { hero { age } }`,
+		})
+		_, err := ExtractPersistedOperations(dir, PersistedOperationExtractionConfig{})
+		assert.NoError(t, err, "operations aren't validated without schema")
+
+		_, err = ExtractPersistedOperations(dir, PersistedOperationExtractionConfig{Schema: schema})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), filepath.Join(dir, "hero.graphql")+": operation Hero: ")
+	})
+}
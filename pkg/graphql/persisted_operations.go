@@ -0,0 +1,194 @@
+package graphql
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/golang-lru/simplelru"
+)
+
+var ErrInvalidPersistedOperationStoreSize = errors.New("persisted operation store size must be greater than 0")
+
+// PersistedOperationStore stores operations (e.g. automatic persisted queries) by their hash
+type PersistedOperationStore interface {
+	// Get returns the operation stored for the hash and marks it as used
+	Get(hash string) (operation string, ok bool)
+	// Set stores the operation, evicting the least recently used operation if the store is full
+	Set(hash, operation string)
+	// Delete removes the operation from the store
+	Delete(hash string)
+	// Stats returns usage statistics of the store
+	Stats() PersistedOperationStoreStats
+}
+
+// PersistedOperationStoreStats contains the usage statistics of a PersistedOperationStore
+type PersistedOperationStoreStats struct {
+	Size        int
+	Hits        uint64
+	Misses      uint64
+	Evictions   uint64
+	Expirations uint64
+}
+
+// PersistedOperationUsage contains the usage statistics of a single persisted operation
+type PersistedOperationUsage struct {
+	Hash     string
+	Hits     uint64
+	StoredAt time.Time
+	LastUsed time.Time
+}
+
+type InMemoryPersistedOperationStoreConfig struct {
+	// MaxSize is the maximum number of stored operations, the least recently used operation is evicted once exceeded
+	MaxSize int
+	// TTL is the duration after which an operation which was not used is removed, 0 disables expiration
+	TTL time.Duration
+}
+
+type persistedOperation struct {
+	operation string
+	usage     PersistedOperationUsage
+}
+
+// InMemoryPersistedOperationStore is a PersistedOperationStore with LRU eviction and optional TTL based expiration.
+// Expired operations are removed lazily on access and by RemoveExpired, which can be called periodically.
+type InMemoryPersistedOperationStore struct {
+	mu    sync.Mutex
+	ttl   time.Duration
+	cache *simplelru.LRU
+	stats PersistedOperationStoreStats
+	now   func() time.Time
+}
+
+func NewInMemoryPersistedOperationStore(config InMemoryPersistedOperationStoreConfig) (*InMemoryPersistedOperationStore, error) {
+	if config.MaxSize <= 0 {
+		return nil, ErrInvalidPersistedOperationStoreSize
+	}
+
+	store := &InMemoryPersistedOperationStore{
+		ttl: config.TTL,
+		now: time.Now,
+	}
+
+	cache, err := simplelru.NewLRU(config.MaxSize, nil)
+	if err != nil {
+		return nil, err
+	}
+	store.cache = cache
+
+	return store, nil
+}
+
+func (s *InMemoryPersistedOperationStore) Get(hash string) (operation string, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.cache.Get(hash)
+	if !ok {
+		s.stats.Misses++
+		return "", false
+	}
+
+	stored := value.(*persistedOperation)
+	now := s.now()
+	if s.isExpired(stored, now) {
+		s.cache.Remove(hash)
+		s.stats.Expirations++
+		s.stats.Misses++
+		return "", false
+	}
+
+	stored.usage.Hits++
+	stored.usage.LastUsed = now
+	s.stats.Hits++
+
+	return stored.operation, true
+}
+
+func (s *InMemoryPersistedOperationStore) Set(hash, operation string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if value, ok := s.cache.Get(hash); ok {
+		stored := value.(*persistedOperation)
+		stored.operation = operation
+		stored.usage.LastUsed = now
+		return
+	}
+
+	evicted := s.cache.Add(hash, &persistedOperation{
+		operation: operation,
+		usage: PersistedOperationUsage{
+			Hash:     hash,
+			StoredAt: now,
+			LastUsed: now,
+		},
+	})
+	if evicted {
+		s.stats.Evictions++
+	}
+}
+
+func (s *InMemoryPersistedOperationStore) Delete(hash string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cache.Remove(hash)
+}
+
+func (s *InMemoryPersistedOperationStore) Stats() PersistedOperationStoreStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats
+	stats.Size = s.cache.Len()
+	return stats
+}
+
+// Usage returns the usage statistics of the operation without marking it as used
+func (s *InMemoryPersistedOperationStore) Usage(hash string) (PersistedOperationUsage, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.cache.Peek(hash)
+	if !ok {
+		return PersistedOperationUsage{}, false
+	}
+	return value.(*persistedOperation).usage, true
+}
+
+// RemoveExpired removes all operations which were not used within the TTL and returns the number of removed operations
+func (s *InMemoryPersistedOperationStore) RemoveExpired() int {
+	if s.ttl <= 0 {
+		return 0
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	removed := 0
+	// keys are ordered from oldest to newest usage, so the first non expired operation ends the search
+	for _, key := range s.cache.Keys() {
+		value, ok := s.cache.Peek(key)
+		if !ok {
+			continue
+		}
+		if !s.isExpired(value.(*persistedOperation), now) {
+			break
+		}
+		s.cache.Remove(key)
+		removed++
+	}
+
+	s.stats.Expirations += uint64(removed)
+	return removed
+}
+
+func (s *InMemoryPersistedOperationStore) isExpired(stored *persistedOperation, now time.Time) bool {
+	return s.ttl > 0 && now.Sub(stored.usage.LastUsed) > s.ttl
+}
+
+var _ PersistedOperationStore = (*InMemoryPersistedOperationStore)(nil)
@@ -0,0 +1,92 @@
+package graphql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryPersistedOperationStore(t *testing.T) {
+	newStore := func(t *testing.T, config InMemoryPersistedOperationStoreConfig) (*InMemoryPersistedOperationStore, *time.Time) {
+		store, err := NewInMemoryPersistedOperationStore(config)
+		require.NoError(t, err)
+		now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		store.now = func() time.Time {
+			return now
+		}
+		return store, &now
+	}
+
+	t.Run("invalid size", func(t *testing.T) {
+		_, err := NewInMemoryPersistedOperationStore(InMemoryPersistedOperationStoreConfig{})
+		assert.Equal(t, ErrInvalidPersistedOperationStoreSize, err)
+	})
+
+	t.Run("get and set", func(t *testing.T) {
+		store, _ := newStore(t, InMemoryPersistedOperationStoreConfig{MaxSize: 2})
+		store.Set("a", "{ a }")
+
+		operation, ok := store.Get("a")
+		assert.True(t, ok)
+		assert.Equal(t, "{ a }", operation)
+
+		_, ok = store.Get("b")
+		assert.False(t, ok)
+
+		assert.Equal(t, PersistedOperationStoreStats{Size: 1, Hits: 1, Misses: 1}, store.Stats())
+
+		store.Delete("a")
+		_, ok = store.Get("a")
+		assert.False(t, ok)
+		assert.Equal(t, 0, store.Stats().Size)
+	})
+
+	t.Run("evicts least recently used operation", func(t *testing.T) {
+		store, _ := newStore(t, InMemoryPersistedOperationStoreConfig{MaxSize: 2})
+		store.Set("a", "{ a }")
+		store.Set("b", "{ b }")
+		_, _ = store.Get("a")
+		store.Set("c", "{ c }")
+
+		_, ok := store.Get("b")
+		assert.False(t, ok)
+		_, ok = store.Get("a")
+		assert.True(t, ok)
+		_, ok = store.Get("c")
+		assert.True(t, ok)
+
+		assert.Equal(t, PersistedOperationStoreStats{Size: 2, Hits: 3, Misses: 1, Evictions: 1}, store.Stats())
+	})
+
+	t.Run("expires unused operations", func(t *testing.T) {
+		store, now := newStore(t, InMemoryPersistedOperationStoreConfig{MaxSize: 10, TTL: time.Minute})
+		store.Set("a", "{ a }")
+		store.Set("b", "{ b }")
+		store.Set("c", "{ c }")
+
+		*now = now.Add(45 * time.Second)
+		_, ok := store.Get("b")
+		assert.True(t, ok)
+
+		*now = now.Add(30 * time.Second)
+		_, ok = store.Get("a")
+		assert.False(t, ok, "a was not used within the ttl")
+
+		assert.Equal(t, 1, store.RemoveExpired())
+		_, ok = store.Usage("c")
+		assert.False(t, ok)
+
+		usage, ok := store.Usage("b")
+		assert.True(t, ok)
+		assert.Equal(t, PersistedOperationUsage{
+			Hash:     "b",
+			Hits:     1,
+			StoredAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			LastUsed: time.Date(2022, 1, 1, 0, 0, 45, 0, time.UTC),
+		}, usage)
+
+		assert.Equal(t, PersistedOperationStoreStats{Size: 1, Hits: 1, Misses: 1, Expirations: 2}, store.Stats())
+	})
+}
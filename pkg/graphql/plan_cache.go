@@ -0,0 +1,151 @@
+package graphql
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// PlanCacheAdmissionConfig configures which plans are admitted into the plan cache,
+// so that the cache isn't churned by one-off ad hoc operations which are cheap to plan again.
+// Plans loaded from the PlanCacheStore are always admitted.
+type PlanCacheAdmissionConfig struct {
+	// MinPlanningCost is the minimum planning cost of admitted plans,
+	// the planning cost is the number of fields of the normalized operation.
+	// Operations below the cost are planned on each request.
+	MinPlanningCost int
+}
+
+// PlanCacheStats are the counters of the plan cache, see ExecutionEngineV2.PlanCacheStats
+type PlanCacheStats struct {
+	Size   int
+	Hits   uint64
+	Misses uint64
+	// Rejected is the number of plans which weren't admitted into the cache because of their planning cost
+	Rejected uint64
+}
+
+// PlanCacheEntryStats are the usage statistics of a cached plan, see ExecutionEngineV2.PlanCacheEntries
+type PlanCacheEntryStats struct {
+	Key           uint64
+	OperationName string
+	PlanningCost  int
+	Hits          uint64
+	CachedAt      time.Time
+	LastUsed      time.Time
+	// HitRate is the number of hits per second since the plan was cached
+	HitRate float64
+}
+
+// PlanCacheStats returns the counters of the plan cache
+func (e *ExecutionEngineV2) PlanCacheStats() PlanCacheStats {
+	return e.planCacheTracker.stats(e.executionPlanCache.Len())
+}
+
+// PlanCacheEntries returns the usage statistics of the cached plans, ordered by their hits
+func (e *ExecutionEngineV2) PlanCacheEntries() []PlanCacheEntryStats {
+	return e.planCacheTracker.entries(time.Now())
+}
+
+// planCacheTracker tracks the usage of the plan cache, the entries are removed when their plans are evicted from the cache
+type planCacheTracker struct {
+	mu                     sync.Mutex
+	cached                 map[uint64]*PlanCacheEntryStats
+	hits, misses, rejected uint64
+}
+
+func newPlanCacheTracker() *planCacheTracker {
+	return &planCacheTracker{
+		cached: map[uint64]*PlanCacheEntryStats{},
+	}
+}
+
+func (t *planCacheTracker) hit(key uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.hits++
+	if entry, ok := t.cached[key]; ok {
+		entry.Hits++
+		entry.LastUsed = time.Now()
+	}
+}
+
+func (t *planCacheTracker) miss() {
+	t.mu.Lock()
+	t.misses++
+	t.mu.Unlock()
+}
+
+func (t *planCacheTracker) reject() {
+	t.mu.Lock()
+	t.rejected++
+	t.mu.Unlock()
+}
+
+func (t *planCacheTracker) admit(key uint64, operationName string, planningCost int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	t.cached[key] = &PlanCacheEntryStats{
+		Key:           key,
+		OperationName: operationName,
+		PlanningCost:  planningCost,
+		CachedAt:      now,
+		LastUsed:      now,
+	}
+}
+
+// evict is the eviction callback of the plan cache
+func (t *planCacheTracker) evict(key interface{}, _ interface{}) {
+	cacheKey, ok := key.(uint64)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	delete(t.cached, cacheKey)
+	t.mu.Unlock()
+}
+
+func (t *planCacheTracker) stats(size int) PlanCacheStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return PlanCacheStats{
+		Size:     size,
+		Hits:     t.hits,
+		Misses:   t.misses,
+		Rejected: t.rejected,
+	}
+}
+
+func (t *planCacheTracker) entries(now time.Time) []PlanCacheEntryStats {
+	t.mu.Lock()
+	entries := make([]PlanCacheEntryStats, 0, len(t.cached))
+	for _, entry := range t.cached {
+		stats := *entry
+		if age := now.Sub(stats.CachedAt).Seconds(); age > 0 {
+			stats.HitRate = float64(stats.Hits) / age
+		}
+		entries = append(entries, stats)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Hits != entries[j].Hits {
+			return entries[i].Hits > entries[j].Hits
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
+
+// admitsPlan returns true if the plan of the operation is admitted into the plan cache, see PlanCacheAdmissionConfig.
+// Persisted plans are always admitted.
+func (e *ExecutionEngineV2) admitsPlan(cacheKey uint64, planningCost int) bool {
+	if e.config.planCacheAdmission == nil {
+		return true
+	}
+	if e.isPersistedPlan(cacheKey) {
+		return true
+	}
+	return planningCost >= e.config.planCacheAdmission.MinPlanningCost
+}
@@ -0,0 +1,194 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+// planCacheEntryVersion is the version of the serialization of plan cache entries,
+// entries of other versions are ignored
+const planCacheEntryVersion = 1
+
+const planCacheFileExtension = ".plan.json"
+
+var ErrPlanCacheEntryVersionMismatch = errors.New("plan cache entry was stored by a different version")
+
+// PlanCacheStore persists the entries of the plan cache, e.g. on disk or in a key-value store,
+// so that restarted engines compile the plans of the previously planned operations on startup
+// instead of compiling them on the first requests.
+// Plans reference the data sources of the engine, so the entries contain the normalized operations of the plans.
+// Implementations must be safe for concurrent use.
+type PlanCacheStore interface {
+	// Load returns all stored entries
+	Load() (entries [][]byte, err error)
+	// Store stores the entry of the plan with the cache key, replacing an existing entry
+	Store(key uint64, entry []byte) error
+}
+
+type planCacheEntry struct {
+	Version int `json:"version"`
+	// ConfigVersion is the version of the engine configuration set with EngineV2Configuration.SetPlanCacheStore
+	ConfigVersion string `json:"configVersion,omitempty"`
+	SchemaHash    uint64 `json:"schemaHash"`
+	OperationName string `json:"operationName,omitempty"`
+	Operation     string `json:"operation"`
+}
+
+func (e *ExecutionEngineV2) newPlanCacheEntry(operation, definition *ast.Document, operationName string) ([]byte, error) {
+	printed, err := astprinter.PrintString(operation, definition)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(planCacheEntry{
+		Version:       planCacheEntryVersion,
+		ConfigVersion: e.config.planCacheStoreVersion,
+		SchemaHash:    e.config.schema.Hash(),
+		OperationName: operationName,
+		Operation:     printed,
+	})
+}
+
+// parsePlanCacheEntry returns the normalized operation of the entry,
+// entries stored for a different schema or configuration are a version mismatch
+func (e *ExecutionEngineV2) parsePlanCacheEntry(data []byte) (operation ast.Document, operationName string, err error) {
+	var entry planCacheEntry
+	if err = json.Unmarshal(data, &entry); err != nil {
+		return operation, "", err
+	}
+	if entry.Version != planCacheEntryVersion || entry.ConfigVersion != e.config.planCacheStoreVersion || entry.SchemaHash != e.config.schema.Hash() {
+		return operation, "", ErrPlanCacheEntryVersionMismatch
+	}
+
+	parser := astparser.NewParser()
+	if e.config.clientNullability {
+		parser.EnableClientControlledNullability()
+	}
+	operation = *ast.NewDocument()
+	operation.Input.ResetInputString(entry.Operation)
+	report := operationreport.Report{}
+	parser.Parse(&operation, &report)
+	if report.HasErrors() {
+		return operation, "", report
+	}
+	return operation, entry.OperationName, nil
+}
+
+// loadPersistedPlans compiles the plans of the entries of the PlanCacheStore,
+// entries which can't be planned anymore are skipped
+func (e *ExecutionEngineV2) loadPersistedPlans() {
+	entries, err := e.config.planCacheStore.Load()
+	if err != nil {
+		e.logger.Error("ExecutionEngineV2.loadPersistedPlans: loading plan cache entries failed", logging.Error(err))
+		return
+	}
+
+	execContext := e.getExecutionCtx()
+	defer e.putExecutionCtx(execContext)
+
+	for i := range entries {
+		operation, operationName, err := e.parsePlanCacheEntry(entries[i])
+		if err != nil {
+			e.logger.Debug("ExecutionEngineV2.loadPersistedPlans: skipping plan cache entry", logging.Error(err))
+			continue
+		}
+		report := operationreport.Report{}
+		if cacheKey, ok := e.planCacheKey(&operation, &e.config.schema.document, nil, &report); ok {
+			e.addPersistedPlan(cacheKey)
+		}
+		e.getCachedPlan(execContext, &operation, &e.config.schema.document, operationName, &report)
+		if report.HasErrors() {
+			e.logger.Debug("ExecutionEngineV2.loadPersistedPlans: planning plan cache entry failed", logging.Error(report))
+		}
+	}
+}
+
+// persistPlan stores the entry of a newly compiled plan if it is not stored yet.
+// It's called without holding the plannerMu, so concurrent requests might store the same entry twice,
+// which is fine as stores replace existing entries.
+func (e *ExecutionEngineV2) persistPlan(cacheKey uint64, entry []byte) {
+	if e.isPersistedPlan(cacheKey) {
+		return
+	}
+	if err := e.config.planCacheStore.Store(cacheKey, entry); err != nil {
+		e.logger.Error("ExecutionEngineV2.persistPlan: storing plan cache entry failed", logging.Error(err))
+		return
+	}
+	e.addPersistedPlan(cacheKey)
+}
+
+func (e *ExecutionEngineV2) isPersistedPlan(cacheKey uint64) bool {
+	e.persistedPlansMu.RLock()
+	defer e.persistedPlansMu.RUnlock()
+	_, ok := e.persistedPlans[cacheKey]
+	return ok
+}
+
+func (e *ExecutionEngineV2) addPersistedPlan(cacheKey uint64) {
+	e.persistedPlansMu.Lock()
+	defer e.persistedPlansMu.Unlock()
+	e.persistedPlans[cacheKey] = struct{}{}
+}
+
+func (e *ExecutionEngineV2) persistsPlans() bool {
+	// plans with folded skip/include directives depend on the variables, which must not be persisted
+	return e.config.planCacheStore != nil && !e.config.plannerConfig.FoldSkipIncludeVariables
+}
+
+// FilePlanCacheStore is a PlanCacheStore storing each entry in a file of a directory
+type FilePlanCacheStore struct {
+	dir string
+}
+
+// NewFilePlanCacheStore returns a FilePlanCacheStore storing the entries in dir, which is created if it doesn't exist
+func NewFilePlanCacheStore(dir string) (*FilePlanCacheStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FilePlanCacheStore{dir: dir}, nil
+}
+
+func (s *FilePlanCacheStore) Load() (entries [][]byte, err error) {
+	files, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), planCacheFileExtension) {
+			continue
+		}
+		entry, err := os.ReadFile(filepath.Join(s.dir, file.Name()))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// Store writes the entry to a temporary file which is renamed, so that concurrent loads never read partial entries
+func (s *FilePlanCacheStore) Store(key uint64, entry []byte) error {
+	file, err := os.CreateTemp(s.dir, "plan-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = file.Write(entry); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return err
+	}
+	if err = file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), filepath.Join(s.dir, strconv.FormatUint(key, 16)+planCacheFileExtension))
+}
@@ -0,0 +1,148 @@
+package graphql
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestFilePlanCacheStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "plans")
+	store, err := NewFilePlanCacheStore(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, store.Store(1, []byte(`{"version":1}`)))
+	require.NoError(t, store.Store(2, []byte(`{"version":2}`)))
+	require.NoError(t, store.Store(1, []byte(`{"version":3}`)))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("not an entry"), 0o644))
+
+	entries, err := store.Load()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.ElementsMatch(t, []string{`{"version":3}`, `{"version":2}`}, []string{string(entries[0]), string(entries[1])})
+}
+
+func TestExecutionEngineV2_PlanCacheStore(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			hello(name: String): String
+		}`)
+	require.NoError(t, err)
+
+	newEngine := func(t *testing.T, store PlanCacheStore, version string) *ExecutionEngineV2 {
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{
+					{TypeName: "Query", FieldNames: []string{"hello"}},
+				},
+				Factory: &graphql_datasource.Factory{
+					HTTPClient: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"hello":"Hello Jens"}}`))}
+						}),
+					},
+				},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch: graphql_datasource.FetchConfiguration{
+						URL:    "https://upstream/graphql",
+						Method: "POST",
+					},
+				}),
+			},
+		})
+		engineConf.AddFieldConfiguration(plan.FieldConfiguration{
+			TypeName:  "Query",
+			FieldName: "hello",
+			Arguments: []plan.ArgumentConfiguration{
+				{Name: "name", SourceType: plan.FieldArgumentSource},
+			},
+		})
+		engineConf.SetPlanCacheStore(store, version)
+
+		engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine
+	}
+
+	execute := func(t *testing.T, engine *ExecutionEngineV2) {
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: `query Hello { hello(name: "Jens") }`, OperationName: "Hello"}, &resultWriter)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"Hello Jens"}}`, resultWriter.String())
+	}
+
+	store, err := NewFilePlanCacheStore(t.TempDir())
+	require.NoError(t, err)
+
+	engine := newEngine(t, store, "v1")
+	assert.Equal(t, 0, engine.executionPlanCache.Len())
+	execute(t, engine)
+	entries, err := store.Load()
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	t.Run("compiles the stored plans on startup", func(t *testing.T) {
+		restarted := newEngine(t, store, "v1")
+		assert.Equal(t, 1, restarted.executionPlanCache.Len())
+		execute(t, restarted)
+		assert.Equal(t, 1, restarted.executionPlanCache.Len())
+
+		entries, err := store.Load()
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("ignores entries of other versions", func(t *testing.T) {
+		restarted := newEngine(t, store, "v2")
+		assert.Equal(t, 0, restarted.executionPlanCache.Len())
+	})
+
+	t.Run("ignores invalid entries", func(t *testing.T) {
+		require.NoError(t, store.Store(42, []byte(`{"version":1,"configVersion":"v1","schemaHash":`+strconv.FormatUint(schema.Hash(), 10)+`,"operation":"{"}`)))
+		restarted := newEngine(t, store, "v1")
+		assert.Equal(t, 1, restarted.executionPlanCache.Len())
+	})
+
+	t.Run("stores plans without holding the planner", func(t *testing.T) {
+		store := &plannerLockRecordingStore{}
+		engine := newEngine(t, store, "v1")
+		store.engine = engine
+		execute(t, engine)
+		assert.Equal(t, 1, store.stored)
+		assert.False(t, store.plannerLocked)
+	})
+}
+
+// plannerLockRecordingStore records whether the planner of the engine was locked while storing an entry
+type plannerLockRecordingStore struct {
+	engine        *ExecutionEngineV2
+	stored        int
+	plannerLocked bool
+}
+
+func (s *plannerLockRecordingStore) Load() ([][]byte, error) {
+	return nil, nil
+}
+
+func (s *plannerLockRecordingStore) Store(_ uint64, _ []byte) error {
+	s.stored++
+	if !s.engine.plannerMu.TryLock() {
+		s.plannerLocked = true
+		return nil
+	}
+	s.engine.plannerMu.Unlock()
+	return nil
+}
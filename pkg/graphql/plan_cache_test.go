@@ -0,0 +1,129 @@
+package graphql
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestExecutionEngineV2_PlanCacheAdmission(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			hello: String
+			user: User
+		}
+
+		type User {
+			id: ID
+			name: String
+		}`)
+	require.NoError(t, err)
+
+	newEngine := func(t *testing.T, store PlanCacheStore, admission *PlanCacheAdmissionConfig) *ExecutionEngineV2 {
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{
+					{TypeName: "Query", FieldNames: []string{"hello", "user"}},
+				},
+				ChildNodes: []plan.TypeField{
+					{TypeName: "User", FieldNames: []string{"id", "name"}},
+				},
+				Factory: &graphql_datasource.Factory{
+					HTTPClient: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"hello":"world","user":{"id":"1","name":"Jens"}}}`))}
+						}),
+					},
+				},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch: graphql_datasource.FetchConfiguration{
+						URL:    "https://upstream/graphql",
+						Method: "POST",
+					},
+				}),
+			},
+		})
+		if store != nil {
+			engineConf.SetPlanCacheStore(store, "v1")
+		}
+		if admission != nil {
+			engineConf.SetPlanCacheAdmission(*admission)
+		}
+
+		engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine
+	}
+
+	execute := func(t *testing.T, engine *ExecutionEngineV2, query, operationName string) {
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: query, OperationName: operationName}, &resultWriter)
+		require.NoError(t, err)
+	}
+
+	t.Run("only admits plans above the planning cost", func(t *testing.T) {
+		engine := newEngine(t, nil, &PlanCacheAdmissionConfig{MinPlanningCost: 3})
+
+		execute(t, engine, `query Hello { hello }`, "Hello")
+		execute(t, engine, `query Hello { hello }`, "Hello")
+		assert.Equal(t, PlanCacheStats{Size: 0, Misses: 2, Rejected: 2}, engine.PlanCacheStats())
+
+		execute(t, engine, `query User { user { id name } }`, "User")
+		execute(t, engine, `query User { user { id name } }`, "User")
+		execute(t, engine, `query User { user { id name } }`, "User")
+		assert.Equal(t, PlanCacheStats{Size: 1, Hits: 2, Misses: 3, Rejected: 2}, engine.PlanCacheStats())
+
+		entries := engine.PlanCacheEntries()
+		require.Len(t, entries, 1)
+		assert.Equal(t, "User", entries[0].OperationName)
+		assert.Equal(t, 3, entries[0].PlanningCost)
+		assert.Equal(t, uint64(2), entries[0].Hits)
+		assert.Greater(t, entries[0].HitRate, float64(0))
+		assert.False(t, entries[0].LastUsed.Before(entries[0].CachedAt))
+	})
+
+	t.Run("admits all plans without admission config", func(t *testing.T) {
+		engine := newEngine(t, nil, nil)
+
+		execute(t, engine, `query Hello { hello }`, "Hello")
+		execute(t, engine, `query Hello { hello }`, "Hello")
+		assert.Equal(t, PlanCacheStats{Size: 1, Hits: 1, Misses: 1}, engine.PlanCacheStats())
+	})
+
+	t.Run("removes the entries of evicted plans", func(t *testing.T) {
+		engine := newEngine(t, nil, nil)
+
+		execute(t, engine, `query Hello { hello }`, "Hello")
+		require.Len(t, engine.PlanCacheEntries(), 1)
+		engine.executionPlanCache.Purge()
+		assert.Empty(t, engine.PlanCacheEntries())
+	})
+
+	t.Run("always admits persisted plans", func(t *testing.T) {
+		store, err := NewFilePlanCacheStore(t.TempDir())
+		require.NoError(t, err)
+		execute(t, newEngine(t, store, nil), `query Hello { hello }`, "Hello")
+
+		restarted := newEngine(t, store, &PlanCacheAdmissionConfig{MinPlanningCost: 3})
+		assert.Equal(t, 1, restarted.PlanCacheStats().Size)
+		execute(t, restarted, `query Hello { hello }`, "Hello")
+		assert.Equal(t, uint64(1), restarted.PlanCacheStats().Hits)
+
+		// rejected plans aren't persisted
+		restarted.executionPlanCache.Purge()
+		execute(t, restarted, `query Other { hello }`, "Other")
+		entries, err := store.Load()
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+}
@@ -37,11 +37,19 @@ type Request struct {
 	OperationName string          `json:"operationName"`
 	Variables     json.RawMessage `json:"variables"`
 	Query         string          `json:"query"`
+	Extensions    json.RawMessage `json:"extensions,omitempty"`
 
 	document     ast.Document
 	isParsed     bool
 	isNormalized bool
 	request      resolve.Request
+	// fragmentArguments enables parsing the experimental fragment arguments, see astparser.Parser.EnableFragmentArguments
+	fragmentArguments bool
+	// clientNullability enables parsing the experimental client controlled nullability designators,
+	// see astparser.Parser.EnableClientControlledNullability
+	clientNullability bool
+	// variablesInjected is true once the server-side variables were injected, see VariableInjectionConfig
+	variablesInjected bool
 
 	validForSchema map[uint64]ValidationResult
 }
@@ -68,6 +76,17 @@ func (r *Request) SetHeader(header http.Header) {
 	r.request.Header = header
 }
 
+// Header returns the headers of the client request, e.g. to identify the client
+func (r *Request) Header() http.Header {
+	return r.request.Header
+}
+
+// ParsedExtensions parses the extensions of the request, e.g. to look up the hash of an automatic persisted query in a middleware.
+// The extensions are available to data sources on the resolve.Context of the execution.
+func (r *Request) ParsedExtensions() (resolve.RequestExtensions, error) {
+	return resolve.ParseRequestExtensions(r.Extensions)
+}
+
 func (r *Request) CalculateComplexity(complexityCalculator ComplexityCalculator, schema *Schema) (ComplexityResult, error) {
 	if schema == nil {
 		return ComplexityResult{}, ErrNilSchema
@@ -103,7 +122,20 @@ func (r *Request) parseQueryOnce() (report operationreport.Report) {
 		return report
 	}
 
-	r.document, report = astparser.ParseGraphqlDocumentString(r.Query)
+	if r.fragmentArguments || r.clientNullability {
+		parser := astparser.NewParser()
+		if r.fragmentArguments {
+			parser.EnableFragmentArguments()
+		}
+		if r.clientNullability {
+			parser.EnableClientControlledNullability()
+		}
+		r.document = *ast.NewDocument()
+		r.document.Input.ResetInputString(r.Query)
+		parser.Parse(&r.document, &report)
+	} else {
+		r.document, report = astparser.ParseGraphqlDocumentString(r.Query)
+	}
 	if !report.HasErrors() {
 		// If the given query has problems, and we failed to parse it,
 		// we shouldn't mark it as parsed. It can be misleading for
@@ -200,3 +232,89 @@ func (r *Request) OperationType() (OperationType, error) {
 
 	return OperationTypeUnknown, nil
 }
+
+// OperationClassification describes a request for routing layers, e.g. to split read and write traffic.
+type OperationClassification struct {
+	// OperationType is the type of the operation selected by the operation name
+	OperationType OperationType
+	// ReadOnly is true if the selected operation is a query
+	ReadOnly bool
+	// ContainsMutation is true if any operation of the document is a mutation,
+	// including operations which are not selected by the operation name
+	ContainsMutation bool
+	// RootFields are the names of the top level fields of the selected operation,
+	// including the fields of fragments on the root type, without duplicates
+	RootFields []string
+}
+
+// Classify returns the classification of the request. It only parses the request, it's neither normalized nor validated.
+func (r *Request) Classify() (OperationClassification, error) {
+	var classification OperationClassification
+
+	report := r.parseQueryOnce()
+	if report.HasErrors() {
+		return classification, report
+	}
+
+	operationDefinitionRef := ast.InvalidRef
+	for _, rootNode := range r.document.RootNodes {
+		if rootNode.Kind != ast.NodeKindOperationDefinition {
+			continue
+		}
+
+		if r.document.OperationDefinitions[rootNode.Ref].OperationType == ast.OperationTypeMutation {
+			classification.ContainsMutation = true
+		}
+
+		if operationDefinitionRef != ast.InvalidRef {
+			continue
+		}
+		if r.OperationName != "" && r.document.OperationDefinitionNameString(rootNode.Ref) != r.OperationName {
+			continue
+		}
+		operationDefinitionRef = rootNode.Ref
+	}
+
+	if operationDefinitionRef == ast.InvalidRef {
+		return classification, nil
+	}
+
+	operationDefinition := r.document.OperationDefinitions[operationDefinitionRef]
+	classification.OperationType = OperationType(operationDefinition.OperationType)
+	classification.ReadOnly = operationDefinition.OperationType == ast.OperationTypeQuery
+	if operationDefinition.HasSelections {
+		classification.RootFields = r.rootFieldNames(operationDefinition.SelectionSet, nil, map[string]struct{}{})
+	}
+
+	return classification, nil
+}
+
+func (r *Request) rootFieldNames(selectionSet int, fieldNames []string, visitedFragments map[string]struct{}) []string {
+	for _, selectionRef := range r.document.SelectionSets[selectionSet].SelectionRefs {
+		selection := r.document.Selections[selectionRef]
+		switch selection.Kind {
+		case ast.SelectionKindField:
+			fieldName := r.document.FieldNameString(selection.Ref)
+			if !containsString(fieldNames, fieldName) {
+				fieldNames = append(fieldNames, fieldName)
+			}
+		case ast.SelectionKindInlineFragment:
+			if !r.document.InlineFragments[selection.Ref].HasSelections {
+				continue
+			}
+			fieldNames = r.rootFieldNames(r.document.InlineFragments[selection.Ref].SelectionSet, fieldNames, visitedFragments)
+		case ast.SelectionKindFragmentSpread:
+			fragmentName := r.document.FragmentSpreadNameString(selection.Ref)
+			if _, ok := visitedFragments[fragmentName]; ok {
+				continue
+			}
+			visitedFragments[fragmentName] = struct{}{}
+			fragmentRef, ok := r.document.FragmentDefinitionRef(r.document.FragmentSpreadNameBytes(selection.Ref))
+			if !ok || !r.document.FragmentDefinitions[fragmentRef].HasSelections {
+				continue
+			}
+			fieldNames = r.rootFieldNames(r.document.FragmentDefinitions[fragmentRef].SelectionSet, fieldNames, visitedFragments)
+		}
+	}
+	return fieldNames
+}
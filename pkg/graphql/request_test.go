@@ -6,7 +6,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
 	"github.com/wundergraph/graphql-go-tools/pkg/starwars"
 )
 
@@ -33,6 +35,21 @@ func TestUnmarshalRequest(t *testing.T) {
 		assert.Equal(t, "Hello", request.OperationName)
 		assert.Equal(t, "query Hello { hello }", request.Query)
 	})
+
+	t.Run("should unmarshal extensions", func(t *testing.T) {
+		requestBytes := []byte(`{"query": "{ hello }", "extensions": {"persistedQuery": {"version": 1, "sha256Hash": "abc"}, "tracing": true, "client": "web"}}`)
+		requestBuffer := bytes.NewBuffer(requestBytes)
+
+		var request Request
+		err := UnmarshalRequest(requestBuffer, &request)
+		require.NoError(t, err)
+
+		extensions, err := request.ParsedExtensions()
+		require.NoError(t, err)
+		assert.Equal(t, &resolve.PersistedQueryExtension{Version: 1, Sha256Hash: "abc"}, extensions.PersistedQuery)
+		assert.True(t, extensions.Tracing)
+		assert.Equal(t, `"web"`, string(extensions.Custom["client"]))
+	})
 }
 
 func TestRequest_Print(t *testing.T) {
@@ -262,6 +279,74 @@ func TestRequest_OperationType(t *testing.T) {
 	})
 }
 
+func TestRequest_Classify(t *testing.T) {
+	classify := func(t *testing.T, request Request) OperationClassification {
+		t.Helper()
+		classification, err := request.Classify()
+		require.NoError(t, err)
+		return classification
+	}
+
+	t.Run("query", func(t *testing.T) {
+		classification := classify(t, Request{
+			Query: "query Hello { hello ...Greetings ... on Query { hello goodbye } } fragment Greetings on Query { greeting ...Greetings }",
+		})
+		assert.Equal(t, OperationClassification{
+			OperationType: OperationTypeQuery,
+			ReadOnly:      true,
+			RootFields:    []string{"hello", "greeting", "goodbye"},
+		}, classification)
+	})
+
+	t.Run("mutation", func(t *testing.T) {
+		classification := classify(t, Request{
+			Query: "mutation { updateHello(hello: 1) { hello } }",
+		})
+		assert.Equal(t, OperationClassification{
+			OperationType:    OperationTypeMutation,
+			ContainsMutation: true,
+			RootFields:       []string{"updateHello"},
+		}, classification)
+	})
+
+	t.Run("query in a document with mutations", func(t *testing.T) {
+		classification := classify(t, Request{
+			OperationName: "HelloQuery",
+			Query:         "mutation HelloMutation { updateHello } query HelloQuery { hello } subscription HelloSubscription { hello }",
+		})
+		assert.Equal(t, OperationClassification{
+			OperationType:    OperationTypeQuery,
+			ReadOnly:         true,
+			ContainsMutation: true,
+			RootFields:       []string{"hello"},
+		}, classification)
+	})
+
+	t.Run("subscription", func(t *testing.T) {
+		classification := classify(t, Request{
+			OperationName: "HelloSubscription",
+			Query:         "query HelloQuery { hello } subscription HelloSubscription { helloUpdated }",
+		})
+		assert.Equal(t, OperationClassification{
+			OperationType: OperationTypeSubscription,
+			RootFields:    []string{"helloUpdated"},
+		}, classification)
+	})
+
+	t.Run("unknown operation name", func(t *testing.T) {
+		classification := classify(t, Request{
+			OperationName: "Other",
+			Query:         "query HelloQuery { hello }",
+		})
+		assert.Equal(t, OperationClassification{OperationType: OperationTypeUnknown}, classification)
+	})
+
+	t.Run("broken query", func(t *testing.T) {
+		_, err := (&Request{Query: "Broken Query"}).Classify()
+		assert.Error(t, err)
+	})
+}
+
 const namedIntrospectionQuery = `{"operationName":"IntrospectionQuery","variables":{},"query":"query IntrospectionQuery {\n  __schema {\n    queryType {\n      name\n    }\n    mutationType {\n      name\n    }\n    subscriptionType {\n      name\n    }\n    types {\n      ...FullType\n    }\n    directives {\n      name\n      description\n      locations\n      args {\n        ...InputValue\n      }\n    }\n  }\n}\n\nfragment FullType on __Type {\n  kind\n  name\n  description\n  fields(includeDeprecated: true) {\n    name\n    description\n    args {\n      ...InputValue\n    }\n    type {\n      ...TypeRef\n    }\n    isDeprecated\n    deprecationReason\n  }\n  inputFields {\n    ...InputValue\n  }\n  interfaces {\n    ...TypeRef\n  }\n  enumValues(includeDeprecated: true) {\n    name\n    description\n    isDeprecated\n    deprecationReason\n  }\n  possibleTypes {\n    ...TypeRef\n  }\n}\n\nfragment InputValue on __InputValue {\n  name\n  description\n  type {\n    ...TypeRef\n  }\n  defaultValue\n}\n\nfragment TypeRef on __Type {\n  kind\n  name\n  ofType {\n    kind\n    name\n    ofType {\n      kind\n      name\n      ofType {\n        kind\n        name\n        ofType {\n          kind\n          name\n          ofType {\n            kind\n            name\n            ofType {\n              kind\n              name\n              ofType {\n                kind\n                name\n              }\n            }\n          }\n        }\n      }\n    }\n  }\n}\n"}`
 const singleNamedIntrospectionQueryWithoutOperationName = `{"operationName":"","variables":{},"query":"query IntrospectionQuery {\n  __schema {\n    queryType {\n      name\n    }\n    mutationType {\n      name\n    }\n    subscriptionType {\n      name\n    }\n    types {\n      ...FullType\n    }\n    directives {\n      name\n      description\n      locations\n      args {\n        ...InputValue\n      }\n    }\n  }\n}\n\nfragment FullType on __Type {\n  kind\n  name\n  description\n  fields(includeDeprecated: true) {\n    name\n    description\n    args {\n      ...InputValue\n    }\n    type {\n      ...TypeRef\n    }\n    isDeprecated\n    deprecationReason\n  }\n  inputFields {\n    ...InputValue\n  }\n  interfaces {\n    ...TypeRef\n  }\n  enumValues(includeDeprecated: true) {\n    name\n    description\n    isDeprecated\n    deprecationReason\n  }\n  possibleTypes {\n    ...TypeRef\n  }\n}\n\nfragment InputValue on __InputValue {\n  name\n  description\n  type {\n    ...TypeRef\n  }\n  defaultValue\n}\n\nfragment TypeRef on __Type {\n  kind\n  name\n  ofType {\n    kind\n    name\n    ofType {\n      kind\n      name\n      ofType {\n        kind\n        name\n        ofType {\n          kind\n          name\n          ofType {\n            kind\n            name\n            ofType {\n              kind\n              name\n              ofType {\n                kind\n                name\n              }\n            }\n          }\n        }\n      }\n    }\n  }\n}\n"}`
 const silentIntrospectionQuery = `{"operationName":null,"variables":{},"query":"{\n  __schema {\n    queryType {\n      name\n    }\n    mutationType {\n      name\n    }\n    subscriptionType {\n      name\n    }\n    types {\n      ...FullType\n    }\n    directives {\n      name\n      description\n      locations\n      args {\n        ...InputValue\n      }\n    }\n  }\n}\n\nfragment FullType on __Type {\n  kind\n  name\n  description\n  fields(includeDeprecated: true) {\n    name\n    description\n    args {\n      ...InputValue\n    }\n    type {\n      ...TypeRef\n    }\n    isDeprecated\n    deprecationReason\n  }\n  inputFields {\n    ...InputValue\n  }\n  interfaces {\n    ...TypeRef\n  }\n  enumValues(includeDeprecated: true) {\n    name\n    description\n    isDeprecated\n    deprecationReason\n  }\n  possibleTypes {\n    ...TypeRef\n  }\n}\n\nfragment InputValue on __InputValue {\n  name\n  description\n  type {\n    ...TypeRef\n  }\n  defaultValue\n}\n\nfragment TypeRef on __Type {\n  kind\n  name\n  ofType {\n    kind\n    name\n    ofType {\n      kind\n      name\n      ofType {\n        kind\n        name\n        ofType {\n          kind\n          name\n          ofType {\n            kind\n            name\n            ofType {\n              kind\n              name\n              ofType {\n                kind\n                name\n              }\n            }\n          }\n        }\n      }\n    }\n  }\n}\n"}`
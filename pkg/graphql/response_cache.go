@@ -0,0 +1,295 @@
+package graphql
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/buger/jsonparser"
+	"github.com/cespare/xxhash/v2"
+	"github.com/hashicorp/golang-lru/simplelru"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+)
+
+var ErrInvalidResponseCacheSize = errors.New("response cache size must be greater than 0")
+
+const defaultEntityKeyField = "id"
+
+// ResponseCacheBackend stores cached responses together with their cache tags.
+// Implementations must be safe for concurrent use, e.g. to share a cache between multiple engine instances.
+type ResponseCacheBackend interface {
+	// Get returns the response stored for the key if it's not expired
+	Get(key uint64) (response []byte, ok bool)
+	// Set stores the response and its tags for the duration of ttl, 0 means without expiration
+	Set(key uint64, response []byte, tags []string, ttl time.Duration)
+	// PurgeTags removes all responses tagged with one of the tags and returns the number of removed responses
+	PurgeTags(tags ...string) int
+}
+
+// CacheTagExtractor returns the cache tags of a response.
+type CacheTagExtractor func(response []byte) []string
+
+type ResponseCacheConfig struct {
+	// TTL is the duration a response is cached, 0 caches responses until they are purged or evicted
+	TTL time.Duration
+	// VaryHeaders are the request headers which are part of the cache key,
+	// e.g. Authorization, so that responses of different users are cached separately
+	VaryHeaders []string
+	// TagExtractor returns the cache tags of a response, defaults to EntityCacheTagExtractor with the key field "id"
+	TagExtractor CacheTagExtractor
+	// PurgeOnMutation purges the tags extracted from the response of a mutation,
+	// so that cached responses containing the entities touched by the mutation are no longer used
+	PurgeOnMutation bool
+}
+
+// ResponseCache caches the responses of queries and tags them with the entities they contain
+// and their operation families, so that cached responses can be invalidated in a targeted way
+// with PurgeTags or by the CacheInvalidationEvents of mutations.
+// Only responses without errors are cached.
+type ResponseCache struct {
+	backend ResponseCacheBackend
+	config  ResponseCacheConfig
+}
+
+func NewResponseCache(backend ResponseCacheBackend, config ResponseCacheConfig) *ResponseCache {
+	if config.TagExtractor == nil {
+		config.TagExtractor = EntityCacheTagExtractor(defaultEntityKeyField)
+	}
+	return &ResponseCache{
+		backend: backend,
+		config:  config,
+	}
+}
+
+// PurgeTags removes all cached responses tagged with one of the tags, e.g. the entity "User:1" or the operation family "Query.users",
+// and returns the number of removed responses
+func (c *ResponseCache) PurgeTags(tags ...string) int {
+	return c.backend.PurgeTags(tags...)
+}
+
+// key hashes the operation, its variables, the enabled feature flags and the vary headers of the request headers,
+// which are the headers of the resolve context including the headers added by the execution options
+func (c *ResponseCache) key(operation *Request, header http.Header, featureFlags resolve.FeatureFlags) (uint64, error) {
+	hash := xxhash.New()
+	if err := astprinter.Print(&operation.document, nil, hash); err != nil {
+		return 0, err
+	}
+	_, _ = hash.WriteString(operation.OperationName)
+	_, _ = hash.Write(operation.Variables)
+	for _, flag := range featureFlags.Sorted() {
+		_, _ = hash.WriteString(flag)
+		_, _ = hash.WriteString(";")
+	}
+	for _, name := range c.config.VaryHeaders {
+		_, _ = hash.WriteString(name)
+		_, _ = hash.WriteString(":")
+		_, _ = hash.WriteString(strings.Join(header.Values(name), ","))
+		_, _ = hash.WriteString(";")
+	}
+	return hash.Sum64(), nil
+}
+
+// lookup writes the cached response of a query to the writer and returns the cache key of the query
+func (c *ResponseCache) lookup(operation *Request, header http.Header, featureFlags resolve.FeatureFlags, writer io.Writer) (key uint64, hit bool, err error) {
+	key, err = c.key(operation, header, featureFlags)
+	if err != nil {
+		return 0, false, err
+	}
+	response, ok := c.backend.Get(key)
+	if !ok {
+		return key, false, nil
+	}
+	_, err = writer.Write(response)
+	return key, true, err
+}
+
+// store caches the response of a query tagged with its entities and operation families
+func (c *ResponseCache) store(operation *Request, definition *ast.Document, key uint64, response []byte) {
+	if responseHasErrors(response) {
+		return
+	}
+	tags := append(c.config.TagExtractor(response), operationFamilies(operation, definition)...)
+	c.backend.Set(key, response, tags, c.config.TTL)
+}
+
+// Invalidate purges the cached responses tagged with the entity tags or operation families of the event
+func (c *ResponseCache) Invalidate(event CacheInvalidationEvent) {
+	tags := make([]string, 0, len(event.Tags)+len(event.OperationFamilies))
+	tags = append(tags, event.Tags...)
+	tags = append(tags, event.OperationFamilies...)
+	if len(tags) != 0 {
+		c.backend.PurgeTags(tags...)
+	}
+}
+
+// mutationInvalidationEvent returns the entity tags of a mutation response if PurgeOnMutation is enabled
+func (c *ResponseCache) mutationInvalidationEvent(response []byte) (event CacheInvalidationEvent, ok bool) {
+	if !c.config.PurgeOnMutation {
+		return event, false
+	}
+	return CacheInvalidationEvent{Tags: c.config.TagExtractor(response)}, true
+}
+
+func responseHasErrors(response []byte) bool {
+	_, dataType, _, err := jsonparser.Get(response, "errors")
+	return err == nil && dataType != jsonparser.Null
+}
+
+// EntityCacheTagExtractor tags a response with "typename:key" for every object
+// containing a __typename and one of the key fields, e.g. "User:1".
+// The operation needs to select __typename for objects which should be tagged.
+func EntityCacheTagExtractor(keyFields ...string) CacheTagExtractor {
+	return func(response []byte) []string {
+		data, dataType, _, err := jsonparser.Get(response, "data")
+		if err != nil || dataType == jsonparser.Null {
+			return nil
+		}
+		tags := map[string]struct{}{}
+		collectEntityCacheTags(data, dataType, keyFields, tags)
+
+		out := make([]string, 0, len(tags))
+		for tag := range tags {
+			out = append(out, tag)
+		}
+		sort.Strings(out)
+		return out
+	}
+}
+
+func collectEntityCacheTags(value []byte, dataType jsonparser.ValueType, keyFields []string, tags map[string]struct{}) {
+	switch dataType {
+	case jsonparser.Array:
+		_, _ = jsonparser.ArrayEach(value, func(item []byte, itemType jsonparser.ValueType, _ int, _ error) {
+			collectEntityCacheTags(item, itemType, keyFields, tags)
+		})
+	case jsonparser.Object:
+		if typeName, err := jsonparser.GetString(value, "__typename"); err == nil {
+			for _, keyField := range keyFields {
+				key, keyType, _, err := jsonparser.Get(value, keyField)
+				if err != nil || (keyType != jsonparser.String && keyType != jsonparser.Number) {
+					continue
+				}
+				tags[typeName+":"+string(key)] = struct{}{}
+			}
+		}
+		_ = jsonparser.ObjectEach(value, func(_ []byte, fieldValue []byte, fieldType jsonparser.ValueType, _ int) error {
+			collectEntityCacheTags(fieldValue, fieldType, keyFields, tags)
+			return nil
+		})
+	}
+}
+
+type InMemoryResponseCacheBackendConfig struct {
+	// MaxSize is the maximum number of cached responses, the least recently used response is evicted once exceeded
+	MaxSize int
+}
+
+type cachedResponse struct {
+	response  []byte
+	tags      []string
+	expiresAt time.Time
+}
+
+// InMemoryResponseCacheBackend is a ResponseCacheBackend with LRU eviction and an index of the keys per tag.
+type InMemoryResponseCacheBackend struct {
+	mu    sync.Mutex
+	cache *simplelru.LRU
+	tags  map[string]map[uint64]struct{}
+	now   func() time.Time
+}
+
+func NewInMemoryResponseCacheBackend(config InMemoryResponseCacheBackendConfig) (*InMemoryResponseCacheBackend, error) {
+	if config.MaxSize <= 0 {
+		return nil, ErrInvalidResponseCacheSize
+	}
+
+	backend := &InMemoryResponseCacheBackend{
+		tags: map[string]map[uint64]struct{}{},
+		now:  time.Now,
+	}
+
+	cache, err := simplelru.NewLRU(config.MaxSize, backend.onEvict)
+	if err != nil {
+		return nil, err
+	}
+	backend.cache = cache
+
+	return backend, nil
+}
+
+func (b *InMemoryResponseCacheBackend) Get(key uint64) (response []byte, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	value, ok := b.cache.Get(key)
+	if !ok {
+		return nil, false
+	}
+	cached := value.(*cachedResponse)
+	if !cached.expiresAt.IsZero() && !b.now().Before(cached.expiresAt) {
+		b.cache.Remove(key)
+		return nil, false
+	}
+	return cached.response, true
+}
+
+func (b *InMemoryResponseCacheBackend) Set(key uint64, response []byte, tags []string, ttl time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cached := &cachedResponse{
+		response: response,
+		tags:     tags,
+	}
+	if ttl > 0 {
+		cached.expiresAt = b.now().Add(ttl)
+	}
+
+	// replacing an existing response removes it from the index of its previous tags
+	b.cache.Remove(key)
+	b.cache.Add(key, cached)
+	for _, tag := range tags {
+		keys, ok := b.tags[tag]
+		if !ok {
+			keys = map[uint64]struct{}{}
+			b.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+}
+
+func (b *InMemoryResponseCacheBackend) PurgeTags(tags ...string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	removed := 0
+	for _, tag := range tags {
+		for key := range b.tags[tag] {
+			if b.cache.Remove(key) {
+				removed++
+			}
+		}
+	}
+	return removed
+}
+
+// onEvict removes the key of a removed or evicted response from the index of its tags
+func (b *InMemoryResponseCacheBackend) onEvict(key interface{}, value interface{}) {
+	cacheKey := key.(uint64)
+	for _, tag := range value.(*cachedResponse).tags {
+		keys := b.tags[tag]
+		delete(keys, cacheKey)
+		if len(keys) == 0 {
+			delete(b.tags, tag)
+		}
+	}
+}
+
+var _ ResponseCacheBackend = (*InMemoryResponseCacheBackend)(nil)
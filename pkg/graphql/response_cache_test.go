@@ -0,0 +1,101 @@
+package graphql
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryResponseCacheBackend(t *testing.T) {
+	newBackend := func(t *testing.T, maxSize int) (*InMemoryResponseCacheBackend, *time.Time) {
+		backend, err := NewInMemoryResponseCacheBackend(InMemoryResponseCacheBackendConfig{MaxSize: maxSize})
+		require.NoError(t, err)
+		now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		backend.now = func() time.Time {
+			return now
+		}
+		return backend, &now
+	}
+
+	t.Run("invalid size", func(t *testing.T) {
+		_, err := NewInMemoryResponseCacheBackend(InMemoryResponseCacheBackendConfig{})
+		assert.Equal(t, ErrInvalidResponseCacheSize, err)
+	})
+
+	t.Run("get and set", func(t *testing.T) {
+		backend, _ := newBackend(t, 2)
+		backend.Set(1, []byte(`{"data":{}}`), nil, 0)
+
+		response, ok := backend.Get(1)
+		assert.True(t, ok)
+		assert.Equal(t, `{"data":{}}`, string(response))
+
+		_, ok = backend.Get(2)
+		assert.False(t, ok)
+	})
+
+	t.Run("expires responses", func(t *testing.T) {
+		backend, now := newBackend(t, 2)
+		backend.Set(1, []byte(`{"data":{}}`), []string{"User:1"}, time.Minute)
+
+		*now = now.Add(59 * time.Second)
+		_, ok := backend.Get(1)
+		assert.True(t, ok)
+
+		*now = now.Add(time.Second)
+		_, ok = backend.Get(1)
+		assert.False(t, ok)
+		assert.Empty(t, backend.tags)
+	})
+
+	t.Run("purges tagged responses", func(t *testing.T) {
+		backend, _ := newBackend(t, 10)
+		backend.Set(1, []byte(`1`), []string{"User", "User:1"}, 0)
+		backend.Set(2, []byte(`2`), []string{"User", "User:2"}, 0)
+		backend.Set(3, []byte(`3`), []string{"Product", "Product:1"}, 0)
+
+		assert.Equal(t, 1, backend.PurgeTags("User:1"))
+		_, ok := backend.Get(1)
+		assert.False(t, ok)
+		_, ok = backend.Get(2)
+		assert.True(t, ok)
+
+		assert.Equal(t, 2, backend.PurgeTags("User", "Product:1"))
+		_, ok = backend.Get(2)
+		assert.False(t, ok)
+		_, ok = backend.Get(3)
+		assert.False(t, ok)
+		assert.Empty(t, backend.tags)
+
+		assert.Equal(t, 0, backend.PurgeTags("User"))
+	})
+
+	t.Run("evicted and replaced responses are removed from the tag index", func(t *testing.T) {
+		backend, _ := newBackend(t, 1)
+		backend.Set(1, []byte(`1`), []string{"User:1"}, 0)
+		backend.Set(1, []byte(`1`), []string{"User:2"}, 0)
+		assert.Equal(t, 0, backend.PurgeTags("User:1"))
+
+		backend.Set(2, []byte(`2`), []string{"User:3"}, 0)
+		assert.Equal(t, map[string]map[uint64]struct{}{"User:3": {2: {}}}, backend.tags)
+	})
+}
+
+func TestEntityCacheTagExtractor(t *testing.T) {
+	extract := EntityCacheTagExtractor("id", "upc")
+
+	t.Run("entities", func(t *testing.T) {
+		tags := extract([]byte(`{"data":{"me":{"__typename":"User","id":"1","reviews":[{"__typename":"Review","id":2,"product":{"__typename":"Product","upc":"top-1"}},{"__typename":"Review","id":3,"product":null}]}}}`))
+		assert.Equal(t, []string{"Product:top-1", "Review:2", "Review:3", "User:1"}, tags)
+	})
+
+	t.Run("objects without __typename are not tagged", func(t *testing.T) {
+		assert.Empty(t, extract([]byte(`{"data":{"me":{"id":"1"}}}`)))
+	})
+
+	t.Run("without data", func(t *testing.T) {
+		assert.Empty(t, extract([]byte(`{"errors":[{"message":"failed"}],"data":null}`)))
+	})
+}
@@ -0,0 +1,504 @@
+package graphql
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+)
+
+// ResponseShapeMismatchKind is the kind of difference between an upstream response and the schema, see ResponseShapeMismatch
+type ResponseShapeMismatchKind string
+
+const (
+	// ResponseShapeUnexpectedNull is a null value of a non-null field or list item
+	ResponseShapeUnexpectedNull ResponseShapeMismatchKind = "UNEXPECTED_NULL"
+	// ResponseShapeExtraField is a field of the response which wasn't selected by the upstream query
+	ResponseShapeExtraField ResponseShapeMismatchKind = "EXTRA_FIELD"
+	// ResponseShapeMissingField is a field selected by the upstream query which is missing in the response
+	ResponseShapeMissingField ResponseShapeMismatchKind = "MISSING_FIELD"
+	// ResponseShapeWrongScalar is a value which isn't valid for the scalar or enum type of the field, e.g. a string for an Int
+	ResponseShapeWrongScalar ResponseShapeMismatchKind = "WRONG_SCALAR"
+	// ResponseShapeWrongShape is a value which doesn't match the composite type of the field, e.g. an object for a list
+	ResponseShapeWrongShape ResponseShapeMismatchKind = "WRONG_SHAPE"
+)
+
+// ResponseShapeReport is the report of the response shape learning mode, see EngineV2Configuration.EnableResponseShapeLearning
+type ResponseShapeReport struct {
+	// Fetches is the number of analyzed upstream responses
+	Fetches int `json:"fetches"`
+	// Fields are the observed shapes of the fields, ordered by upstream, type and field name
+	Fields []ResponseFieldShape `json:"fields"`
+	// Mismatches are the differences between the responses and the schema, ordered by upstream, type, field name and kind
+	Mismatches []ResponseShapeMismatch `json:"mismatches"`
+}
+
+// ResponseFieldShape is the observed shape of a field in the responses of an upstream
+type ResponseFieldShape struct {
+	Upstream  string `json:"upstream"`
+	TypeName  string `json:"typeName"`
+	FieldName string `json:"fieldName"`
+	// Type is the type of the field in the schema, e.g. [String!], it's empty if the schema doesn't define the field
+	Type string `json:"type,omitempty"`
+	// Values is the number of observed values per JSON kind, i.e. null, string, number, boolean, object or array
+	Values map[string]int `json:"values"`
+}
+
+// ResponseShapeMismatch is a difference between the responses of an upstream and the schema
+type ResponseShapeMismatch struct {
+	Upstream  string                    `json:"upstream"`
+	TypeName  string                    `json:"typeName"`
+	FieldName string                    `json:"fieldName"`
+	Kind      ResponseShapeMismatchKind `json:"kind"`
+	// Expected is the type of the field in the schema, it's empty for extra fields
+	Expected string `json:"expected,omitempty"`
+	// Actual is the JSON kind of the value, it's empty for missing fields
+	Actual string `json:"actual,omitempty"`
+	// Count is the number of occurrences of the mismatch
+	Count int `json:"count"`
+	// Path is the path of the first occurrence in the upstream response, e.g. user.friends.0.name
+	Path string `json:"path"`
+}
+
+// ResponseShapeReport returns the report of the response shape learning mode,
+// the report is empty if the mode isn't enabled, see EngineV2Configuration.EnableResponseShapeLearning
+func (e *ExecutionEngineV2) ResponseShapeReport() ResponseShapeReport {
+	if e.responseShapes == nil {
+		return ResponseShapeReport{}
+	}
+	return e.responseShapes.report()
+}
+
+// ResetResponseShapes discards the shapes and mismatches learned so far, e.g. after fixing a mapping config
+func (e *ExecutionEngineV2) ResetResponseShapes() {
+	if e.responseShapes != nil {
+		e.responseShapes.reset()
+	}
+}
+
+type responseFieldKey struct {
+	upstream, typeName, fieldName string
+}
+
+type responseShapeMismatchKey struct {
+	responseFieldKey
+	kind             ResponseShapeMismatchKind
+	expected, actual string
+}
+
+// responseShapeLearner records the shapes of the responses of GraphQL upstreams and diffs them against the schema.
+// It's a resolve.FetchRecorder, the upstream queries are parsed from the fetch inputs.
+// Responses of data sources without a GraphQL query in their input, e.g. REST data sources, are ignored.
+type responseShapeLearner struct {
+	definition *ast.Document
+
+	mu         sync.Mutex
+	fetches    int
+	fields     map[responseFieldKey]*ResponseFieldShape
+	mismatches map[responseShapeMismatchKey]*ResponseShapeMismatch
+}
+
+func newResponseShapeLearner(schema *Schema) *responseShapeLearner {
+	learner := &responseShapeLearner{definition: &schema.document}
+	learner.reset()
+	return learner
+}
+
+func (l *responseShapeLearner) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fetches = 0
+	l.fields = map[responseFieldKey]*ResponseFieldShape{}
+	l.mismatches = map[responseShapeMismatchKey]*ResponseShapeMismatch{}
+}
+
+func (l *responseShapeLearner) report() ResponseShapeReport {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	report := ResponseShapeReport{
+		Fetches:    l.fetches,
+		Fields:     make([]ResponseFieldShape, 0, len(l.fields)),
+		Mismatches: make([]ResponseShapeMismatch, 0, len(l.mismatches)),
+	}
+	for _, field := range l.fields {
+		shape := *field
+		shape.Values = make(map[string]int, len(field.Values))
+		for kind, count := range field.Values {
+			shape.Values[kind] = count
+		}
+		report.Fields = append(report.Fields, shape)
+	}
+	for _, mismatch := range l.mismatches {
+		report.Mismatches = append(report.Mismatches, *mismatch)
+	}
+
+	sort.Slice(report.Fields, func(i, j int) bool {
+		return lessResponseField(report.Fields[i].Upstream, report.Fields[i].TypeName, report.Fields[i].FieldName,
+			report.Fields[j].Upstream, report.Fields[j].TypeName, report.Fields[j].FieldName)
+	})
+	sort.Slice(report.Mismatches, func(i, j int) bool {
+		left, right := report.Mismatches[i], report.Mismatches[j]
+		if left.Upstream == right.Upstream && left.TypeName == right.TypeName && left.FieldName == right.FieldName {
+			return left.Kind < right.Kind
+		}
+		return lessResponseField(left.Upstream, left.TypeName, left.FieldName, right.Upstream, right.TypeName, right.FieldName)
+	})
+	return report
+}
+
+func lessResponseField(leftUpstream, leftTypeName, leftFieldName, rightUpstream, rightTypeName, rightFieldName string) bool {
+	if leftUpstream != rightUpstream {
+		return leftUpstream < rightUpstream
+	}
+	if leftTypeName != rightTypeName {
+		return leftTypeName < rightTypeName
+	}
+	return leftFieldName < rightFieldName
+}
+
+// RecordFetch implements resolve.FetchRecorder
+func (l *responseShapeLearner) RecordFetch(dataSourceIdentifier string, input, response []byte, err error) {
+	if err != nil {
+		return
+	}
+	query, queryErr := jsonparser.GetString(input, "body", "query")
+	if queryErr != nil || query == "" {
+		return
+	}
+	upstream, urlErr := jsonparser.GetString(input, "url")
+	if urlErr != nil || upstream == "" {
+		upstream = dataSourceIdentifier
+	}
+	data, dataType, _, dataErr := jsonparser.Get(response, "data")
+	if dataErr != nil || dataType != jsonparser.Object {
+		return
+	}
+
+	operation, report := astparser.ParseGraphqlDocumentString(query)
+	if report.HasErrors() || len(operation.OperationDefinitions) == 0 {
+		return
+	}
+	operationDefinition := operation.OperationDefinitions[0]
+	rootTypeName := l.rootTypeName(operationDefinition.OperationType)
+	if rootTypeName == "" || !operationDefinition.HasSelections {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.fetches++
+	walker := responseShapeWalker{
+		learner:   l,
+		operation: &operation,
+		upstream:  upstream,
+	}
+	walker.walkObject(operationDefinition.SelectionSet, rootTypeName, data, nil)
+}
+
+func (l *responseShapeLearner) rootTypeName(operationType ast.OperationType) string {
+	switch operationType {
+	case ast.OperationTypeQuery:
+		return string(l.definition.Index.QueryTypeName)
+	case ast.OperationTypeMutation:
+		return string(l.definition.Index.MutationTypeName)
+	case ast.OperationTypeSubscription:
+		return string(l.definition.Index.SubscriptionTypeName)
+	default:
+		return ""
+	}
+}
+
+// responseShapeWalker walks the response of a fetch alongside the selections of its upstream query
+type responseShapeWalker struct {
+	learner   *responseShapeLearner
+	operation *ast.Document
+	upstream  string
+}
+
+func (w *responseShapeWalker) walkObject(selectionSet int, typeName string, object []byte, path []string) {
+	definition := w.learner.definition
+	if typeNameValue, err := jsonparser.GetString(object, "__typename"); err == nil {
+		if _, exists := definition.Index.FirstNodeByNameStr(typeNameValue); exists {
+			typeName = typeNameValue
+		}
+	}
+
+	fields := map[string]int{}
+	w.collectFields(selectionSet, typeName, fields)
+
+	seen := make(map[string]struct{}, len(fields))
+	_ = jsonparser.ObjectEach(object, func(key []byte, value []byte, dataType jsonparser.ValueType, offset int) error {
+		responseKey := string(key)
+		seen[responseKey] = struct{}{}
+		fieldPath := append(path[:len(path):len(path)], responseKey)
+		field, selected := fields[responseKey]
+		if !selected {
+			w.mismatch(typeName, responseKey, ResponseShapeExtraField, "", jsonKind(dataType), fieldPath)
+			return nil
+		}
+		fieldName := w.operation.FieldNameString(field)
+		if fieldName == "__typename" {
+			return nil
+		}
+		w.walkField(field, typeName, fieldName, value, dataType, fieldPath)
+		return nil
+	})
+
+	for responseKey, field := range fields {
+		if _, ok := seen[responseKey]; ok {
+			continue
+		}
+		fieldName := w.operation.FieldNameString(field)
+		expected := ""
+		if fieldType, ok := w.fieldType(typeName, fieldName); ok {
+			expected = w.printType(fieldType)
+		}
+		w.mismatch(typeName, fieldName, ResponseShapeMissingField, expected, "", append(path[:len(path):len(path)], responseKey))
+	}
+}
+
+// collectFields collects the fields of the selection set by their response key,
+// fragments are only collected if their type condition applies to the type of the object
+func (w *responseShapeWalker) collectFields(selectionSet int, typeName string, fields map[string]int) {
+	for _, selectionRef := range w.operation.SelectionSets[selectionSet].SelectionRefs {
+		selection := w.operation.Selections[selectionRef]
+		switch selection.Kind {
+		case ast.SelectionKindField:
+			fields[w.operation.FieldAliasOrNameString(selection.Ref)] = selection.Ref
+		case ast.SelectionKindInlineFragment:
+			inlineFragment := w.operation.InlineFragments[selection.Ref]
+			if !inlineFragment.HasSelections {
+				continue
+			}
+			if inlineFragment.TypeCondition.Type != -1 && !w.typeConditionApplies(w.operation.InlineFragmentTypeConditionNameString(selection.Ref), typeName) {
+				continue
+			}
+			w.collectFields(inlineFragment.SelectionSet, typeName, fields)
+		case ast.SelectionKindFragmentSpread:
+			fragment, exists := w.operation.FragmentDefinitionRef(w.operation.FragmentSpreadNameBytes(selection.Ref))
+			if !exists {
+				continue
+			}
+			typeCondition := w.operation.FragmentDefinitionTypeName(fragment)
+			if !w.typeConditionApplies(string(typeCondition), typeName) {
+				continue
+			}
+			w.collectFields(w.operation.FragmentDefinitions[fragment].SelectionSet, typeName, fields)
+		}
+	}
+}
+
+func (w *responseShapeWalker) typeConditionApplies(typeCondition, typeName string) bool {
+	if typeCondition == typeName {
+		return true
+	}
+	definition := w.learner.definition
+	node, exists := definition.Index.FirstNodeByNameStr(typeCondition)
+	if !exists {
+		return false
+	}
+	switch node.Kind {
+	case ast.NodeKindInterfaceTypeDefinition:
+		return definition.TypeDefinitionContainsImplementsInterface([]byte(typeName), []byte(typeCondition))
+	case ast.NodeKindUnionTypeDefinition:
+		typeNode, exists := definition.Index.FirstNodeByNameStr(typeName)
+		return exists && definition.NodeIsUnionMember(typeNode, node)
+	default:
+		return false
+	}
+}
+
+func (w *responseShapeWalker) fieldType(typeName, fieldName string) (typeRef int, ok bool) {
+	definition := w.learner.definition
+	node, exists := definition.Index.FirstNodeByNameStr(typeName)
+	if !exists {
+		return -1, false
+	}
+	fieldDefinition, exists := definition.NodeFieldDefinitionByName(node, []byte(fieldName))
+	if !exists {
+		return -1, false
+	}
+	return definition.FieldDefinitionType(fieldDefinition), true
+}
+
+func (w *responseShapeWalker) walkField(field int, typeName, fieldName string, value []byte, dataType jsonparser.ValueType, path []string) {
+	fieldType, ok := w.fieldType(typeName, fieldName)
+	w.observe(typeName, fieldName, fieldType, ok, dataType)
+	if !ok {
+		if fieldName == "_entities" && dataType == jsonparser.Array && w.operation.FieldHasSelections(field) {
+			// the entities of the federation _entities field are resolved by their __typename
+			w.walkEntities(w.operation.Fields[field].SelectionSet, value, path)
+		}
+		return
+	}
+	selectionSet := -1
+	if w.operation.FieldHasSelections(field) {
+		selectionSet = w.operation.Fields[field].SelectionSet
+	}
+	w.walkValue(typeName, fieldName, fieldType, fieldType, selectionSet, value, dataType, path)
+}
+
+func (w *responseShapeWalker) walkEntities(selectionSet int, entities []byte, path []string) {
+	i := 0
+	_, _ = jsonparser.ArrayEach(entities, func(value []byte, dataType jsonparser.ValueType, offset int, err error) {
+		if dataType == jsonparser.Object {
+			w.walkObject(selectionSet, "", value, append(path[:len(path):len(path)], strconv.Itoa(i)))
+		}
+		i++
+	})
+}
+
+// walkValue checks the value of a field against the type of the field, typeRef is the type at the current list depth
+func (w *responseShapeWalker) walkValue(typeName, fieldName string, fieldType, typeRef, selectionSet int, value []byte, dataType jsonparser.ValueType, path []string) {
+	definition := w.learner.definition
+	if dataType == jsonparser.Null {
+		if definition.TypeIsNonNull(typeRef) {
+			w.mismatch(typeName, fieldName, ResponseShapeUnexpectedNull, w.printType(fieldType), jsonKind(dataType), path)
+		}
+		return
+	}
+	if definition.TypeIsNonNull(typeRef) {
+		typeRef = definition.Types[typeRef].OfType
+	}
+
+	if definition.TypeIsList(typeRef) {
+		if dataType != jsonparser.Array {
+			w.mismatch(typeName, fieldName, ResponseShapeWrongShape, w.printType(fieldType), jsonKind(dataType), path)
+			return
+		}
+		itemType := definition.Types[typeRef].OfType
+		i := 0
+		_, _ = jsonparser.ArrayEach(value, func(item []byte, itemDataType jsonparser.ValueType, offset int, err error) {
+			w.walkValue(typeName, fieldName, fieldType, itemType, selectionSet, item, itemDataType, append(path[:len(path):len(path)], strconv.Itoa(i)))
+			i++
+		})
+		return
+	}
+
+	namedType := definition.ResolveTypeNameString(typeRef)
+	node, exists := definition.Index.FirstNodeByNameStr(namedType)
+	if !exists {
+		return
+	}
+	switch node.Kind {
+	case ast.NodeKindObjectTypeDefinition, ast.NodeKindInterfaceTypeDefinition, ast.NodeKindUnionTypeDefinition:
+		if dataType != jsonparser.Object {
+			w.mismatch(typeName, fieldName, ResponseShapeWrongShape, w.printType(fieldType), jsonKind(dataType), path)
+			return
+		}
+		if selectionSet != -1 {
+			w.walkObject(selectionSet, namedType, value, path)
+		}
+	case ast.NodeKindEnumTypeDefinition:
+		if dataType != jsonparser.String || !definition.EnumTypeDefinitionContainsEnumValue(node.Ref, value) {
+			w.mismatch(typeName, fieldName, ResponseShapeWrongScalar, w.printType(fieldType), jsonKind(dataType), path)
+		}
+	case ast.NodeKindScalarTypeDefinition:
+		if !scalarValueIsValid(namedType, value, dataType) {
+			w.mismatch(typeName, fieldName, ResponseShapeWrongScalar, w.printType(fieldType), jsonKind(dataType), path)
+		}
+	}
+}
+
+// scalarValueIsValid checks the values of the built-in scalars, the values of custom scalars are always valid
+func scalarValueIsValid(scalar string, value []byte, dataType jsonparser.ValueType) bool {
+	switch scalar {
+	case "String":
+		return dataType == jsonparser.String
+	case "Boolean":
+		return dataType == jsonparser.Boolean
+	case "Float":
+		return dataType == jsonparser.Number
+	case "Int":
+		if dataType != jsonparser.Number {
+			return false
+		}
+		_, err := strconv.ParseInt(string(value), 10, 32)
+		return err == nil
+	case "ID":
+		if dataType == jsonparser.String {
+			return true
+		}
+		if dataType != jsonparser.Number {
+			return false
+		}
+		_, err := strconv.ParseInt(string(value), 10, 64)
+		return err == nil
+	default:
+		return true
+	}
+}
+
+func (w *responseShapeWalker) observe(typeName, fieldName string, fieldType int, defined bool, dataType jsonparser.ValueType) {
+	key := responseFieldKey{upstream: w.upstream, typeName: typeName, fieldName: fieldName}
+	shape, ok := w.learner.fields[key]
+	if !ok {
+		shape = &ResponseFieldShape{
+			Upstream:  w.upstream,
+			TypeName:  typeName,
+			FieldName: fieldName,
+			Values:    map[string]int{},
+		}
+		if defined {
+			shape.Type = w.printType(fieldType)
+		}
+		w.learner.fields[key] = shape
+	}
+	shape.Values[jsonKind(dataType)]++
+}
+
+func (w *responseShapeWalker) mismatch(typeName, fieldName string, kind ResponseShapeMismatchKind, expected, actual string, path []string) {
+	key := responseShapeMismatchKey{
+		responseFieldKey: responseFieldKey{upstream: w.upstream, typeName: typeName, fieldName: fieldName},
+		kind:             kind,
+		expected:         expected,
+		actual:           actual,
+	}
+	mismatch, ok := w.learner.mismatches[key]
+	if !ok {
+		mismatch = &ResponseShapeMismatch{
+			Upstream:  w.upstream,
+			TypeName:  typeName,
+			FieldName: fieldName,
+			Kind:      kind,
+			Expected:  expected,
+			Actual:    actual,
+			Path:      strings.Join(path, "."),
+		}
+		w.learner.mismatches[key] = mismatch
+	}
+	mismatch.Count++
+}
+
+func (w *responseShapeWalker) printType(typeRef int) string {
+	printed, err := w.learner.definition.PrintTypeBytes(typeRef, nil)
+	if err != nil {
+		return ""
+	}
+	return string(printed)
+}
+
+func jsonKind(dataType jsonparser.ValueType) string {
+	switch dataType {
+	case jsonparser.Null:
+		return "null"
+	case jsonparser.String:
+		return "string"
+	case jsonparser.Number:
+		return "number"
+	case jsonparser.Boolean:
+		return "boolean"
+	case jsonparser.Object:
+		return "object"
+	case jsonparser.Array:
+		return "array"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,117 @@
+package graphql
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+type countingFetchRecorder struct {
+	fetches int
+}
+
+func (c *countingFetchRecorder) RecordFetch(dataSourceIdentifier string, input, response []byte, err error) {
+	c.fetches++
+}
+
+func TestExecutionEngineV2_ResponseShapeLearning(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			user: User
+			me: User
+		}
+
+		enum Role {
+			ADMIN
+			MEMBER
+		}
+
+		type User {
+			id: ID!
+			name: String!
+			age: Int
+			role: Role
+			friends: [User!]!
+		}`)
+	require.NoError(t, err)
+
+	newEngine := func(t *testing.T, enable bool) *ExecutionEngineV2 {
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{
+					{TypeName: "Query", FieldNames: []string{"user", "me"}},
+				},
+				ChildNodes: []plan.TypeField{
+					{TypeName: "User", FieldNames: []string{"id", "name", "age", "role", "friends"}},
+				},
+				Factory: &graphql_datasource.Factory{
+					HTTPClient: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(
+								`{"data":{"user":{"id":1,"name":null,"age":"42","role":"OWNER","friends":[{"id":"2","name":"Jannik","nickname":"J"},null],"extra":true}}}`,
+							))}
+						}),
+					},
+				},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch: graphql_datasource.FetchConfiguration{
+						URL:    "https://users/graphql",
+						Method: "POST",
+					},
+				}),
+			},
+		})
+		engineConf.EnableResponseShapeLearning(enable)
+
+		engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine
+	}
+
+	query := `{ user { id name age role friends { id name } } me { id } }`
+
+	t.Run("reports mismatches between the upstream responses and the schema", func(t *testing.T) {
+		engine := newEngine(t, true)
+		recorder := &countingFetchRecorder{}
+		for i := 0; i < 2; i++ {
+			resultWriter := NewEngineResultWriter()
+			_ = engine.Execute(context.Background(), &Request{Query: query}, &resultWriter, WithFetchRecorder(recorder))
+		}
+		assert.Equal(t, 2, recorder.fetches, "the fetches are recorded by the recorders of the request as well")
+
+		report := engine.ResponseShapeReport()
+		assert.Equal(t, 2, report.Fetches)
+		assert.Equal(t, []ResponseShapeMismatch{
+			{Upstream: "https://users/graphql", TypeName: "Query", FieldName: "me", Kind: ResponseShapeMissingField, Expected: "User", Count: 2, Path: "me"},
+			{Upstream: "https://users/graphql", TypeName: "User", FieldName: "age", Kind: ResponseShapeWrongScalar, Expected: "Int", Actual: "string", Count: 2, Path: "user.age"},
+			{Upstream: "https://users/graphql", TypeName: "User", FieldName: "extra", Kind: ResponseShapeExtraField, Actual: "boolean", Count: 2, Path: "user.extra"},
+			{Upstream: "https://users/graphql", TypeName: "User", FieldName: "friends", Kind: ResponseShapeUnexpectedNull, Expected: "[User!]!", Actual: "null", Count: 2, Path: "user.friends.1"},
+			{Upstream: "https://users/graphql", TypeName: "User", FieldName: "name", Kind: ResponseShapeUnexpectedNull, Expected: "String!", Actual: "null", Count: 2, Path: "user.name"},
+			{Upstream: "https://users/graphql", TypeName: "User", FieldName: "nickname", Kind: ResponseShapeExtraField, Actual: "string", Count: 2, Path: "user.friends.0.nickname"},
+			{Upstream: "https://users/graphql", TypeName: "User", FieldName: "role", Kind: ResponseShapeWrongScalar, Expected: "Role", Actual: "string", Count: 2, Path: "user.role"},
+		}, report.Mismatches)
+
+		assert.Contains(t, report.Fields, ResponseFieldShape{Upstream: "https://users/graphql", TypeName: "User", FieldName: "id", Type: "ID!", Values: map[string]int{"number": 2, "string": 2}})
+		assert.Contains(t, report.Fields, ResponseFieldShape{Upstream: "https://users/graphql", TypeName: "Query", FieldName: "user", Type: "User", Values: map[string]int{"object": 2}})
+
+		engine.ResetResponseShapes()
+		assert.Equal(t, ResponseShapeReport{Fields: []ResponseFieldShape{}, Mismatches: []ResponseShapeMismatch{}}, engine.ResponseShapeReport())
+	})
+
+	t.Run("learns nothing if disabled", func(t *testing.T) {
+		engine := newEngine(t, false)
+		resultWriter := NewEngineResultWriter()
+		_ = engine.Execute(context.Background(), &Request{Query: query}, &resultWriter)
+		assert.Equal(t, ResponseShapeReport{}, engine.ResponseShapeReport())
+	})
+}
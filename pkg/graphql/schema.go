@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"io"
 	"io/ioutil"
+	"strconv"
 	"strings"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
@@ -43,6 +44,11 @@ func (s *Schema) Hash() uint64 {
 	return s.hash
 }
 
+// HashString returns the hash of the schema as hex string, e.g. to let clients detect schema changes.
+func (s *Schema) HashString() string {
+	return strconv.FormatUint(s.hash, 16)
+}
+
 // calcHash calculates the hash of the schema.
 func (s *Schema) calcHash() error {
 	if s.hash != 0 {
@@ -171,11 +177,11 @@ func (s *Schema) SubscriptionTypeName() string {
 	return string(s.document.Index.SubscriptionTypeName)
 }
 
-func (s *Schema) Validate() (result ValidationResult, err error) {
+func (s *Schema) Validate(options ...SchemaValidationOption) (result ValidationResult, err error) {
 	var report operationreport.Report
 	var isValid bool
 
-	validator := astvalidation.DefaultDefinitionValidator()
+	validator := astvalidation.NewDefinitionValidatorFromRules(newSchemaValidationOptions(options).definitionRules()...)
 	validationState := validator.Validate(&s.document, &report)
 	if validationState == astvalidation.Valid {
 		isValid = true
@@ -0,0 +1,235 @@
+package graphql
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/cespare/xxhash/v2"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+)
+
+// SchemaBundleVersion is the version of the bundle format written by SchemaBundle.Save,
+// bundles of other versions can't be loaded
+const SchemaBundleVersion = 1
+
+var (
+	ErrSchemaBundleVersionMismatch = errors.New("schema bundle was saved with a different version of the bundle format")
+	ErrSchemaBundleHashMismatch    = errors.New("schema bundle doesn't match its hashes")
+	ErrSchemaBundleUnknownFactory  = errors.New("schema bundle data source has no factory")
+)
+
+// SchemaBundle is a versioned artifact containing everything a gateway needs to serve a schema:
+// the schema, the plan configuration of its data sources and fields and the persisted operations of its clients.
+// Deployment pipelines save a validated bundle once, gateways load it and create the engine with EngineConfiguration.
+type SchemaBundle struct {
+	// Version is the version of the bundle format, it's set by Save
+	Version  int                  `json:"version"`
+	Metadata SchemaBundleMetadata `json:"metadata"`
+	// Schema is the SDL of the schema
+	Schema      string                   `json:"schema"`
+	DataSources []BundleDataSource       `json:"dataSources"`
+	Fields      plan.FieldConfigurations `json:"fields,omitempty"`
+	Types       plan.TypeConfigurations  `json:"types,omitempty"`
+	// PersistedOperations is the persisted operation manifest, the operations by their hash
+	PersistedOperations map[string]string `json:"persistedOperations,omitempty"`
+}
+
+// SchemaBundleMetadata describes a bundle, the hashes are set by Save and verified by LoadSchemaBundle
+type SchemaBundleMetadata struct {
+	// CreatedAt is the time the bundle was saved, unless it's set before
+	CreatedAt time.Time `json:"createdAt"`
+	// SchemaHash is the hash of the normalized schema, see Schema.HashString
+	SchemaHash string `json:"schemaHash"`
+	// PlanConfigurationHash is the hash of the data sources, fields and types
+	PlanConfigurationHash string `json:"planConfigurationHash"`
+	// PersistedOperationsHash is the hash of the persisted operations
+	PersistedOperationsHash string `json:"persistedOperationsHash"`
+}
+
+// BundleDataSource is the serializable configuration of a plan.DataSourceConfiguration,
+// its factory is referenced by the kind, e.g. "graphql", and passed to SchemaBundle.EngineConfiguration
+type BundleDataSource struct {
+	Kind        string                         `json:"kind"`
+	RootNodes   []plan.TypeField               `json:"rootNodes"`
+	ChildNodes  []plan.TypeField               `json:"childNodes,omitempty"`
+	Entities    []plan.EntityConfiguration     `json:"entities,omitempty"`
+	Directives  plan.DirectiveConfigurations   `json:"directives,omitempty"`
+	Custom      json.RawMessage                `json:"custom,omitempty"`
+	Optional    bool                           `json:"optional,omitempty"`
+	HealthCheck *plan.HealthCheckConfiguration `json:"healthCheck,omitempty"`
+	FeatureFlag string                         `json:"featureFlag,omitempty"`
+}
+
+// LoadSchemaBundle reads a bundle saved by SchemaBundle.Save and verifies its version, hashes and content
+func LoadSchemaBundle(r io.Reader) (*SchemaBundle, error) {
+	bundle := &SchemaBundle{}
+	if err := json.NewDecoder(r).Decode(bundle); err != nil {
+		return nil, err
+	}
+	if bundle.Version != SchemaBundleVersion {
+		return nil, fmt.Errorf("%w: %d", ErrSchemaBundleVersionMismatch, bundle.Version)
+	}
+	for i := range bundle.Fields {
+		// fields without Fallback are saved with a null Fallback, which would replace the @fallback directive of the field
+		if string(bundle.Fields[i].Fallback) == "null" {
+			bundle.Fields[i].Fallback = nil
+		}
+	}
+	schema, err := bundle.validate()
+	if err != nil {
+		return nil, err
+	}
+	metadata, err := bundle.hashes(schema)
+	if err != nil {
+		return nil, err
+	}
+	if metadata.SchemaHash != bundle.Metadata.SchemaHash ||
+		metadata.PlanConfigurationHash != bundle.Metadata.PlanConfigurationHash ||
+		metadata.PersistedOperationsHash != bundle.Metadata.PersistedOperationsHash {
+		return nil, ErrSchemaBundleHashMismatch
+	}
+	return bundle, nil
+}
+
+// Save validates the bundle, sets its version and metadata and writes it to w
+func (b *SchemaBundle) Save(w io.Writer) error {
+	schema, err := b.validate()
+	if err != nil {
+		return err
+	}
+	metadata, err := b.hashes(schema)
+	if err != nil {
+		return err
+	}
+	metadata.CreatedAt = b.Metadata.CreatedAt
+	if metadata.CreatedAt.IsZero() {
+		metadata.CreatedAt = time.Now().UTC()
+	}
+	b.Version = SchemaBundleVersion
+	b.Metadata = metadata
+
+	out, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+// EngineConfiguration returns the engine configuration of the bundle with the factories of the data sources by their kind
+func (b *SchemaBundle) EngineConfiguration(factories map[string]plan.PlannerFactory) (EngineV2Configuration, error) {
+	schema, err := NewSchemaFromString(b.Schema)
+	if err != nil {
+		return EngineV2Configuration{}, err
+	}
+	config := NewEngineV2Configuration(schema)
+	for i := range b.DataSources {
+		dataSource := &b.DataSources[i]
+		factory, ok := factories[dataSource.Kind]
+		if !ok {
+			return EngineV2Configuration{}, fmt.Errorf("%w: %s", ErrSchemaBundleUnknownFactory, dataSource.Kind)
+		}
+		config.AddDataSource(plan.DataSourceConfiguration{
+			RootNodes:   dataSource.RootNodes,
+			ChildNodes:  dataSource.ChildNodes,
+			Entities:    dataSource.Entities,
+			Directives:  dataSource.Directives,
+			Factory:     factory,
+			Custom:      dataSource.Custom,
+			Optional:    dataSource.Optional,
+			HealthCheck: dataSource.HealthCheck,
+			FeatureFlag: dataSource.FeatureFlag,
+		})
+	}
+	config.SetFieldConfigurations(b.Fields)
+	config.plannerConfig.Types = b.Types
+	return config, nil
+}
+
+// StorePersistedOperations stores the persisted operations of the bundle in the store
+func (b *SchemaBundle) StorePersistedOperations(store PersistedOperationStore) {
+	for hash, operation := range b.PersistedOperations {
+		store.Set(hash, operation)
+	}
+}
+
+// validate returns the schema of the bundle if the nodes of the data sources and the persisted operations are valid for it
+func (b *SchemaBundle) validate() (*Schema, error) {
+	schema, err := NewSchemaFromString(b.Schema)
+	if err != nil {
+		return nil, err
+	}
+	for i := range b.DataSources {
+		if b.DataSources[i].Kind == "" {
+			return nil, fmt.Errorf("schema bundle data source %d has no kind", i)
+		}
+		if err := validateBundleNodes(schema, b.DataSources[i].RootNodes); err != nil {
+			return nil, fmt.Errorf("schema bundle data source %d: %w", i, err)
+		}
+		if err := validateBundleNodes(schema, b.DataSources[i].ChildNodes); err != nil {
+			return nil, fmt.Errorf("schema bundle data source %d: %w", i, err)
+		}
+	}
+	for hash, operation := range b.PersistedOperations {
+		request := Request{Query: operation}
+		normalization, err := request.Normalize(schema)
+		if err != nil {
+			return nil, fmt.Errorf("schema bundle persisted operation %s: %w", hash, err)
+		}
+		if !normalization.Successful {
+			return nil, fmt.Errorf("schema bundle persisted operation %s: %w", hash, normalization.Errors)
+		}
+		result, err := request.ValidateForSchema(schema)
+		if err != nil {
+			return nil, fmt.Errorf("schema bundle persisted operation %s: %w", hash, err)
+		}
+		if !result.Valid {
+			return nil, fmt.Errorf("schema bundle persisted operation %s: %w", hash, result.Errors)
+		}
+	}
+	return schema, nil
+}
+
+func validateBundleNodes(schema *Schema, nodes []plan.TypeField) error {
+	for _, node := range nodes {
+		typeNode, ok := schema.document.Index.FirstNonExtensionNodeByNameStr(node.TypeName)
+		if !ok {
+			return fmt.Errorf("type %s not defined in the schema", node.TypeName)
+		}
+		for _, fieldName := range node.FieldNames {
+			if fieldName == "__typename" {
+				continue
+			}
+			if _, ok := schema.document.NodeFieldDefinitionByName(typeNode, []byte(fieldName)); !ok {
+				return fmt.Errorf("field %s.%s not defined in the schema", node.TypeName, fieldName)
+			}
+		}
+	}
+	return nil
+}
+
+func (b *SchemaBundle) hashes(schema *Schema) (metadata SchemaBundleMetadata, err error) {
+	planConfiguration, err := json.Marshal(struct {
+		DataSources []BundleDataSource
+		Fields      plan.FieldConfigurations
+		Types       plan.TypeConfigurations
+	}{b.DataSources, b.Fields, b.Types})
+	if err != nil {
+		return metadata, err
+	}
+	// the keys of maps are marshalled in sorted order
+	persistedOperations, err := json.Marshal(b.PersistedOperations)
+	if err != nil {
+		return metadata, err
+	}
+	return SchemaBundleMetadata{
+		SchemaHash:              schema.HashString(),
+		PlanConfigurationHash:   strconv.FormatUint(xxhash.Sum64(planConfiguration), 16),
+		PersistedOperationsHash: strconv.FormatUint(xxhash.Sum64(persistedOperations), 16),
+	}, nil
+}
@@ -0,0 +1,144 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestSchemaBundle(t *testing.T) {
+	newBundle := func() *SchemaBundle {
+		return &SchemaBundle{
+			Metadata: SchemaBundleMetadata{CreatedAt: time.Date(2022, 10, 1, 12, 0, 0, 0, time.UTC)},
+			Schema:   `type Query { hello(name: String): String }`,
+			DataSources: []BundleDataSource{
+				{
+					Kind:      "graphql",
+					RootNodes: []plan.TypeField{{TypeName: "Query", FieldNames: []string{"hello"}}},
+					Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+						Fetch: graphql_datasource.FetchConfiguration{URL: "https://hello.service/graphql", Method: "POST"},
+					}),
+				},
+			},
+			Fields: plan.FieldConfigurations{
+				{
+					TypeName:  "Query",
+					FieldName: "hello",
+					Arguments: []plan.ArgumentConfiguration{{Name: "name", SourceType: plan.FieldArgumentSource}},
+				},
+			},
+			PersistedOperations: map[string]string{
+				"hello": `query Hello($name: String) { hello(name: $name) }`,
+			},
+		}
+	}
+
+	save := func(t *testing.T, bundle *SchemaBundle) []byte {
+		buf := &bytes.Buffer{}
+		require.NoError(t, bundle.Save(buf))
+		return buf.Bytes()
+	}
+
+	t.Run("loads the saved bundle", func(t *testing.T) {
+		saved := newBundle()
+		data := save(t, saved)
+		assert.Equal(t, SchemaBundleVersion, saved.Version)
+		assert.NotEmpty(t, saved.Metadata.SchemaHash)
+		assert.NotEmpty(t, saved.Metadata.PlanConfigurationHash)
+		assert.NotEmpty(t, saved.Metadata.PersistedOperationsHash)
+
+		loaded, err := LoadSchemaBundle(bytes.NewReader(data))
+		require.NoError(t, err)
+		assert.Equal(t, saved, loaded)
+	})
+
+	t.Run("sets the creation time", func(t *testing.T) {
+		bundle := newBundle()
+		bundle.Metadata.CreatedAt = time.Time{}
+		save(t, bundle)
+		assert.WithinDuration(t, time.Now(), bundle.Metadata.CreatedAt, time.Minute)
+	})
+
+	t.Run("creates the engine configuration", func(t *testing.T) {
+		loaded, err := LoadSchemaBundle(bytes.NewReader(save(t, newBundle())))
+		require.NoError(t, err)
+
+		var upstreamRequest string
+		engineConf, err := loaded.EngineConfiguration(map[string]plan.PlannerFactory{
+			"graphql": &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						body, _ := ioutil.ReadAll(req.Body)
+						upstreamRequest = req.URL.Host + " " + string(body)
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"hello":"Hello, Ada"}}`))}
+					}),
+				},
+			},
+		})
+		require.NoError(t, err)
+		engine, err := NewExecutionEngineV2(context.Background(), logging.NoopLogger, engineConf)
+		require.NoError(t, err)
+
+		store, err := NewInMemoryPersistedOperationStore(InMemoryPersistedOperationStoreConfig{MaxSize: 10})
+		require.NoError(t, err)
+		loaded.StorePersistedOperations(store)
+		query, ok := store.Get("hello")
+		require.True(t, ok)
+
+		resultWriter := NewEngineResultWriter()
+		err = engine.Execute(context.Background(), &Request{OperationName: "Hello", Query: query, Variables: []byte(`{"name":"Ada"}`)}, &resultWriter)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"Hello, Ada"}}`, resultWriter.String())
+		assert.Equal(t, `hello.service {"query":"query($name: String){hello(name: $name)}","variables":{"name":"Ada"}}`, upstreamRequest)
+	})
+
+	t.Run("fails for data sources without factory", func(t *testing.T) {
+		_, err := newBundle().EngineConfiguration(map[string]plan.PlannerFactory{})
+		assert.ErrorIs(t, err, ErrSchemaBundleUnknownFactory)
+		assert.EqualError(t, err, "schema bundle data source has no factory: graphql")
+	})
+
+	t.Run("rejects modified bundles", func(t *testing.T) {
+		data := save(t, newBundle())
+		modified := bytes.Replace(data, []byte(`hello(name: $name) }`), []byte(`hello }`), 1)
+		_, err := LoadSchemaBundle(bytes.NewReader(modified))
+		assert.ErrorIs(t, err, ErrSchemaBundleHashMismatch)
+	})
+
+	t.Run("rejects bundles of other versions", func(t *testing.T) {
+		data := save(t, newBundle())
+		modified := bytes.Replace(data, []byte(`"version":1`), []byte(`"version":2`), 1)
+		_, err := LoadSchemaBundle(bytes.NewReader(modified))
+		assert.ErrorIs(t, err, ErrSchemaBundleVersionMismatch)
+	})
+
+	t.Run("rejects invalid persisted operations", func(t *testing.T) {
+		bundle := newBundle()
+		bundle.PersistedOperations["goodbye"] = `{ goodbye }`
+		err := bundle.Save(&bytes.Buffer{})
+		assert.EqualError(t, err, "schema bundle persisted operation goodbye: field: goodbye not defined on type: Query, locations: [], path: [query,goodbye]")
+	})
+
+	t.Run("rejects nodes missing in the schema", func(t *testing.T) {
+		bundle := newBundle()
+		bundle.DataSources[0].RootNodes[0].FieldNames = append(bundle.DataSources[0].RootNodes[0].FieldNames, "goodbye")
+		err := bundle.Save(&bytes.Buffer{})
+		assert.EqualError(t, err, "schema bundle data source 0: field Query.goodbye not defined in the schema")
+
+		bundle = newBundle()
+		bundle.DataSources[0].ChildNodes = []plan.TypeField{{TypeName: "User", FieldNames: []string{"id"}}}
+		err = bundle.Save(&bytes.Buffer{})
+		assert.EqualError(t, err, "schema bundle data source 0: type User not defined in the schema")
+	})
+}
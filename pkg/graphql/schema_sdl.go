@@ -0,0 +1,67 @@
+package graphql
+
+import (
+	"strings"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+)
+
+// builtInDefinitions are the scalars and directives of the base schema, which every GraphQL server provides
+var builtInDefinitions = map[string]bool{
+	"Int":                 true,
+	"Float":               true,
+	"String":              true,
+	"Boolean":             true,
+	"ID":                  true,
+	"include":             true,
+	"skip":                true,
+	"deprecated":          true,
+	"removeNullVariables": true,
+}
+
+// PublicSDL returns the SDL of the schema as seen by clients, without the introspection types and fields
+// and without the built-in scalars and directives of the base schema, e.g. for tooling which prefers SDL over introspection.
+func (s *Schema) PublicSDL() (string, error) {
+	doc, report := astparser.ParseGraphqlDocumentBytes(s.rawSchema)
+	if report.HasErrors() {
+		return "", report
+	}
+
+	rootNodes := doc.RootNodes[:0]
+	for _, node := range doc.RootNodes {
+		if node.Kind != ast.NodeKindSchemaDefinition && isBaseSchemaName(doc.NodeNameString(node)) {
+			continue
+		}
+		switch node.Kind {
+		case ast.NodeKindObjectTypeDefinition:
+			removeIntrospectionFields(&doc, &doc.ObjectTypeDefinitions[node.Ref].FieldsDefinition)
+		case ast.NodeKindObjectTypeExtension:
+			removeIntrospectionFields(&doc, &doc.ObjectTypeExtensions[node.Ref].FieldsDefinition)
+		case ast.NodeKindInterfaceTypeDefinition:
+			removeIntrospectionFields(&doc, &doc.InterfaceTypeDefinitions[node.Ref].FieldsDefinition)
+		case ast.NodeKindInterfaceTypeExtension:
+			removeIntrospectionFields(&doc, &doc.InterfaceTypeExtensions[node.Ref].FieldsDefinition)
+		}
+		rootNodes = append(rootNodes, node)
+	}
+	doc.RootNodes = rootNodes
+
+	return astprinter.PrintStringIndent(&doc, nil, "  ")
+}
+
+func isBaseSchemaName(name string) bool {
+	return strings.HasPrefix(name, "__") || builtInDefinitions[name]
+}
+
+// removeIntrospectionFields removes __schema, __type and __typename, which are added to the types by the base schema
+func removeIntrospectionFields(doc *ast.Document, fields *ast.FieldDefinitionList) {
+	refs := fields.Refs[:0]
+	for _, ref := range fields.Refs {
+		if !strings.HasPrefix(doc.FieldDefinitionNameString(ref), "__") {
+			refs = append(refs, ref)
+		}
+	}
+	fields.Refs = refs
+}
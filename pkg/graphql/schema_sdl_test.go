@@ -0,0 +1,47 @@
+package graphql
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_PublicSDL(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			node(id: ID!): Node
+			users: [User] @deprecated
+		}
+		interface Node {
+			id: ID!
+		}
+		type User implements Node {
+			id: ID!
+			name: String
+		}`)
+	require.NoError(t, err)
+
+	sdl, err := schema.PublicSDL()
+	require.NoError(t, err)
+	assert.Equal(t, `schema {
+    query: Query
+}
+
+type Query {
+    node(id: ID!): Node
+    users: [User] @deprecated
+}
+
+interface Node {
+    id: ID!
+}
+
+type User implements Node {
+    id: ID!
+    name: String
+}`, sdl)
+
+	_, err = NewSchemaFromString(sdl)
+	assert.NoError(t, err)
+}
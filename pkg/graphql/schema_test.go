@@ -53,6 +53,19 @@ func TestNewSchemaFromString(t *testing.T) {
 	})
 }
 
+func TestSchema_HashString(t *testing.T) {
+	schema, err := NewSchemaFromString(`type Query { hello: String }`)
+	require.NoError(t, err)
+	same, err := NewSchemaFromString(`type Query { hello: String }`)
+	require.NoError(t, err)
+	other, err := NewSchemaFromString(`type Query { hello: String world: String }`)
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, schema.HashString())
+	assert.Equal(t, schema.HashString(), same.HashString())
+	assert.NotEqual(t, schema.HashString(), other.HashString())
+}
+
 func TestSchema_Normalize(t *testing.T) {
 	t.Run("should successfully normalize schema", func(t *testing.T) {
 		parsedSchema, err := NewSchemaFromString("type Query { me: String } extend type Query { you: String }")
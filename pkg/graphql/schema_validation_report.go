@@ -0,0 +1,406 @@
+package graphql
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astvalidation"
+	"github.com/wundergraph/graphql-go-tools/pkg/astvisitor"
+	"github.com/wundergraph/graphql-go-tools/pkg/graphqlerrors"
+	"github.com/wundergraph/graphql-go-tools/pkg/lexer/position"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+type SchemaValidationSeverity string
+
+const (
+	SchemaValidationSeverityError   SchemaValidationSeverity = "ERROR"
+	SchemaValidationSeverityWarning SchemaValidationSeverity = "WARNING"
+)
+
+const (
+	SchemaValidationCodePopulatedTypeBodies              = astvalidation.DefinitionRuleCodePopulatedTypeBodies
+	SchemaValidationCodeUniqueOperationTypes             = astvalidation.DefinitionRuleCodeUniqueOperationTypes
+	SchemaValidationCodeUniqueTypeNames                  = astvalidation.DefinitionRuleCodeUniqueTypeNames
+	SchemaValidationCodeUniqueFieldDefinitionNames       = astvalidation.DefinitionRuleCodeUniqueFieldDefinitionNames
+	SchemaValidationCodeUniqueEnumValueNames             = astvalidation.DefinitionRuleCodeUniqueEnumValueNames
+	SchemaValidationCodeUniqueUnionMemberTypes           = astvalidation.DefinitionRuleCodeUniqueUnionMemberTypes
+	SchemaValidationCodeKnownTypeNames                   = astvalidation.DefinitionRuleCodeKnownTypeNames
+	SchemaValidationCodeRequireDefinedTypesForExtensions = astvalidation.DefinitionRuleCodeRequireDefinedTypesForExtensions
+	SchemaValidationCodeImplementTransitiveInterfaces    = astvalidation.DefinitionRuleCodeImplementTransitiveInterfaces
+	SchemaValidationCodeImplementingTypesAreSupersets    = astvalidation.DefinitionRuleCodeImplementingTypesAreSupersets
+	SchemaValidationCodeRelayConnections                 = astvalidation.DefinitionRuleCodeRelayConnections
+	SchemaValidationCodeUnusedType                       = "UNUSED_TYPE"
+)
+
+// SchemaValidationIssue is a single finding of the schema validation.
+// Coordinate is the schema coordinate (e.g. "User", "User.name", "Query.user(id:)") of the offending definition
+// and stays empty if the finding can't be attributed to a single definition.
+type SchemaValidationIssue struct {
+	Severity   SchemaValidationSeverity `json:"severity"`
+	Code       string                   `json:"code"`
+	Message    string                   `json:"message"`
+	Coordinate string                   `json:"coordinate,omitempty"`
+	Locations  []graphqlerrors.Location `json:"locations,omitempty"`
+}
+
+// SchemaValidationReport is the structured result of Schema.ValidationReport.
+// Errors make the schema invalid, warnings don't.
+type SchemaValidationReport struct {
+	Valid    bool                    `json:"valid"`
+	Errors   []SchemaValidationIssue `json:"errors"`
+	Warnings []SchemaValidationIssue `json:"warnings"`
+}
+
+func (r SchemaValidationReport) HasWarnings() bool {
+	return len(r.Warnings) > 0
+}
+
+// WriteJSON writes the report as JSON, e.g. to be consumed by CI gates.
+func (r SchemaValidationReport) WriteJSON(writer io.Writer) error {
+	if r.Errors == nil {
+		r.Errors = []SchemaValidationIssue{}
+	}
+	if r.Warnings == nil {
+		r.Warnings = []SchemaValidationIssue{}
+	}
+	return json.NewEncoder(writer).Encode(r)
+}
+
+type schemaValidationOptions struct {
+	relayConnections bool
+}
+
+// SchemaValidationOption enables optional rules of Schema.Validate and Schema.ValidationReport
+type SchemaValidationOption func(options *schemaValidationOptions)
+
+// WithRelayConnectionValidation enforces the Relay cursor connections spec,
+// see astvalidation.RelayConnections for the shapes of the types and fields being validated.
+func WithRelayConnectionValidation() SchemaValidationOption {
+	return func(options *schemaValidationOptions) {
+		options.relayConnections = true
+	}
+}
+
+func newSchemaValidationOptions(options []SchemaValidationOption) schemaValidationOptions {
+	var opts schemaValidationOptions
+	for _, option := range options {
+		option(&opts)
+	}
+	return opts
+}
+
+// definitionRules returns the rules shared by Validate and ValidationReport
+func (o schemaValidationOptions) definitionRules() []astvalidation.DefinitionRule {
+	rules := astvalidation.DefaultDefinitionRules()
+	if o.relayConnections {
+		rules = append(rules, astvalidation.DefinitionRule{Code: SchemaValidationCodeRelayConnections, Rule: astvalidation.RelayConnections()})
+	}
+	return rules
+}
+
+// ValidationReport validates the schema with the same rules as Validate,
+// but returns every finding with a machine-readable code, the schema coordinate and the position.
+// In addition to errors, the report contains warnings which don't make the schema invalid, e.g. unused types.
+func (s *Schema) ValidationReport(options ...SchemaValidationOption) (report SchemaValidationReport, err error) {
+	rules := newSchemaValidationOptions(options).definitionRules()
+	for i := range rules {
+		issues, err := s.validateRule(rules[i])
+		if err != nil {
+			return SchemaValidationReport{}, err
+		}
+		report.Errors = append(report.Errors, issues...)
+	}
+
+	report.Warnings = s.unusedTypeWarnings()
+	report.Valid = len(report.Errors) == 0
+	return report, nil
+}
+
+// validateRule runs a single rule on its own walker so that every error can be attributed to the rule's code.
+func (s *Schema) validateRule(rule astvalidation.DefinitionRule) ([]SchemaValidationIssue, error) {
+	var report operationreport.Report
+	walker := astvisitor.NewWalker(48)
+	rule.Rule(&walker)
+
+	tracker := &schemaCoordinateTracker{
+		Walker:     &walker,
+		definition: &s.document,
+		report:     &report,
+	}
+	walker.RegisterEnterDocumentVisitor(tracker)
+	walker.RegisterLeaveDocumentVisitor(tracker)
+	walker.RegisterTypeSystemVisitor(tracker)
+
+	walker.Walk(&s.document, nil, &report)
+	if len(report.InternalErrors) > 0 {
+		return nil, report.InternalErrors[0]
+	}
+	// in case a rule stopped the walker, the last visited node is the offending one
+	tracker.track()
+
+	issues := make([]SchemaValidationIssue, 0, len(report.ExternalErrors))
+	for i := range report.ExternalErrors {
+		issue := SchemaValidationIssue{
+			Severity:  SchemaValidationSeverityError,
+			Code:      rule.Code,
+			Message:   report.ExternalErrors[i].Message,
+			Locations: report.ExternalErrors[i].Locations,
+		}
+		if i < len(tracker.coordinates) {
+			issue.Coordinate = tracker.coordinates[i].coordinate
+			if len(issue.Locations) == 0 && tracker.coordinates[i].hasPosition {
+				issue.Locations = locationsFromPosition(tracker.coordinates[i].position)
+			}
+		}
+		issues = append(issues, issue)
+	}
+
+	return issues, nil
+}
+
+func (s *Schema) unusedTypeWarnings() (warnings []SchemaValidationIssue) {
+	usedTypeNames := make(map[string]struct{}, len(s.document.Types))
+	for i := range s.document.Types {
+		if s.document.Types[i].TypeKind == ast.TypeKindNamed {
+			usedTypeNames[s.document.TypeNameString(i)] = struct{}{}
+		}
+	}
+
+	for _, node := range s.document.RootNodes {
+		switch node.Kind {
+		case ast.NodeKindObjectTypeDefinition,
+			ast.NodeKindInterfaceTypeDefinition,
+			ast.NodeKindUnionTypeDefinition,
+			ast.NodeKindEnumTypeDefinition,
+			ast.NodeKindInputObjectTypeDefinition,
+			ast.NodeKindScalarTypeDefinition:
+		default:
+			continue
+		}
+
+		typeName := s.document.NodeNameString(node)
+		if _, used := usedTypeNames[typeName]; used {
+			continue
+		}
+		if s.document.Index.IsRootOperationTypeNameString(typeName) || isReservedTypeName(typeName) {
+			continue
+		}
+
+		warning := SchemaValidationIssue{
+			Severity:   SchemaValidationSeverityWarning,
+			Code:       SchemaValidationCodeUnusedType,
+			Message:    "type '" + typeName + "' is defined but never used",
+			Coordinate: typeName,
+		}
+		if pos, ok := nodePosition(&s.document, node); ok {
+			warning.Locations = locationsFromPosition(pos)
+		}
+		warnings = append(warnings, warning)
+	}
+
+	return warnings
+}
+
+func isReservedTypeName(typeName string) bool {
+	switch typeName {
+	case "String", "Int", "Float", "Boolean", "ID":
+		return true
+	}
+	return len(typeName) > 1 && typeName[0] == '_' && typeName[1] == '_'
+}
+
+func locationsFromPosition(pos position.Position) []graphqlerrors.Location {
+	return []graphqlerrors.Location{
+		{
+			Line:   pos.LineStart,
+			Column: pos.CharStart,
+		},
+	}
+}
+
+// nodePosition returns the position of the keyword (or, for fields and arguments, the colon) of a definition
+func nodePosition(definition *ast.Document, node ast.Node) (position.Position, bool) {
+	switch node.Kind {
+	case ast.NodeKindObjectTypeDefinition:
+		return definition.ObjectTypeDefinitions[node.Ref].TypeLiteral, true
+	case ast.NodeKindObjectTypeExtension:
+		return definition.ObjectTypeExtensions[node.Ref].ExtendLiteral, true
+	case ast.NodeKindInterfaceTypeDefinition:
+		return definition.InterfaceTypeDefinitions[node.Ref].InterfaceLiteral, true
+	case ast.NodeKindInterfaceTypeExtension:
+		return definition.InterfaceTypeExtensions[node.Ref].ExtendLiteral, true
+	case ast.NodeKindUnionTypeDefinition:
+		return definition.UnionTypeDefinitions[node.Ref].UnionLiteral, true
+	case ast.NodeKindUnionTypeExtension:
+		return definition.UnionTypeExtensions[node.Ref].ExtendLiteral, true
+	case ast.NodeKindEnumTypeDefinition:
+		return definition.EnumTypeDefinitions[node.Ref].EnumLiteral, true
+	case ast.NodeKindEnumTypeExtension:
+		return definition.EnumTypeExtensions[node.Ref].ExtendLiteral, true
+	case ast.NodeKindInputObjectTypeDefinition:
+		return definition.InputObjectTypeDefinitions[node.Ref].InputLiteral, true
+	case ast.NodeKindInputObjectTypeExtension:
+		return definition.InputObjectTypeExtensions[node.Ref].ExtendLiteral, true
+	case ast.NodeKindScalarTypeDefinition:
+		return definition.ScalarTypeDefinitions[node.Ref].ScalarLiteral, true
+	case ast.NodeKindScalarTypeExtension:
+		return definition.ScalarTypeExtensions[node.Ref].ExtendLiteral, true
+	case ast.NodeKindDirectiveDefinition:
+		return definition.DirectiveDefinitions[node.Ref].DirectiveLiteral, true
+	case ast.NodeKindFieldDefinition:
+		return definition.FieldDefinitions[node.Ref].Colon, true
+	case ast.NodeKindInputValueDefinition:
+		return definition.InputValueDefinitions[node.Ref].Colon, true
+	case ast.NodeKindEnumValueDefinition:
+		return inputPosition(definition, definition.EnumValueDefinitions[node.Ref].EnumValue.Start), true
+	}
+	return position.Position{}, false
+}
+
+// inputPosition computes the position of a byte offset in the input,
+// it's used for nodes like enum values which only keep a reference into the input
+func inputPosition(definition *ast.Document, offset uint32) position.Position {
+	pos := position.Position{
+		LineStart: 1,
+		CharStart: 1,
+	}
+	input := definition.Input.RawBytes
+	for i := uint32(0); i < offset && int(i) < len(input); i++ {
+		if input[i] == '\n' {
+			pos.LineStart++
+			pos.CharStart = 1
+			continue
+		}
+		pos.CharStart++
+	}
+	pos.LineEnd = pos.LineStart
+	pos.CharEnd = pos.CharStart
+	return pos
+}
+
+type trackedCoordinate struct {
+	coordinate  string
+	position    position.Position
+	hasPosition bool
+}
+
+// schemaCoordinateTracker is registered after a validation rule.
+// Every time the walker visits a node it attributes all errors added since the last visit to the current node.
+type schemaCoordinateTracker struct {
+	*astvisitor.Walker
+	definition  *ast.Document
+	report      *operationreport.Report
+	coordinates []trackedCoordinate
+}
+
+func (t *schemaCoordinateTracker) track() {
+	if len(t.coordinates) >= len(t.report.ExternalErrors) {
+		return
+	}
+	current := ast.Node{Kind: t.CurrentKind, Ref: t.CurrentRef}
+	tracked := trackedCoordinate{
+		coordinate: t.coordinate(current),
+	}
+	tracked.position, tracked.hasPosition = nodePosition(t.definition, current)
+	for len(t.coordinates) < len(t.report.ExternalErrors) {
+		t.coordinates = append(t.coordinates, tracked)
+	}
+}
+
+func (t *schemaCoordinateTracker) trackWithoutCoordinate() {
+	for len(t.coordinates) < len(t.report.ExternalErrors) {
+		t.coordinates = append(t.coordinates, trackedCoordinate{})
+	}
+}
+
+func (t *schemaCoordinateTracker) enclosingName() string {
+	for i := len(t.Ancestors) - 1; i >= 0; i-- {
+		switch t.Ancestors[i].Kind {
+		case ast.NodeKindFieldDefinition:
+			return t.enclosingTypeName(i) + "." + t.definition.FieldDefinitionNameString(t.Ancestors[i].Ref)
+		case ast.NodeKindDirectiveDefinition:
+			return "@" + t.definition.DirectiveDefinitionNameString(t.Ancestors[i].Ref)
+		}
+	}
+	return t.enclosingTypeName(len(t.Ancestors))
+}
+
+func (t *schemaCoordinateTracker) enclosingTypeName(before int) string {
+	for i := before - 1; i >= 0; i-- {
+		name := t.definition.NodeNameString(t.Ancestors[i])
+		if name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+func (t *schemaCoordinateTracker) coordinate(node ast.Node) string {
+	switch node.Kind {
+	case ast.NodeKindDirectiveDefinition:
+		return "@" + t.definition.DirectiveDefinitionNameString(node.Ref)
+	case ast.NodeKindFieldDefinition:
+		return t.enclosingName() + "." + t.definition.FieldDefinitionNameString(node.Ref)
+	case ast.NodeKindEnumValueDefinition:
+		return t.enclosingName() + "." + t.definition.EnumValueDefinitionNameString(node.Ref)
+	case ast.NodeKindInputValueDefinition:
+		enclosing := t.enclosingName()
+		name := t.definition.InputValueDefinitionNameString(node.Ref)
+		if len(t.Ancestors) > 0 && t.Ancestors[len(t.Ancestors)-1].Kind == ast.NodeKindInputObjectTypeDefinition ||
+			len(t.Ancestors) > 0 && t.Ancestors[len(t.Ancestors)-1].Kind == ast.NodeKindInputObjectTypeExtension {
+			return enclosing + "." + name
+		}
+		return enclosing + "(" + name + ":)"
+	case ast.NodeKindUnknown:
+		return ""
+	}
+	return t.definition.NodeNameString(node)
+}
+
+func (t *schemaCoordinateTracker) EnterDocument(_, _ *ast.Document) { t.trackWithoutCoordinate() }
+func (t *schemaCoordinateTracker) LeaveDocument(_, _ *ast.Document) { t.trackWithoutCoordinate() }
+
+func (t *schemaCoordinateTracker) EnterObjectTypeDefinition(_ int)                { t.track() }
+func (t *schemaCoordinateTracker) LeaveObjectTypeDefinition(_ int)                { t.track() }
+func (t *schemaCoordinateTracker) EnterObjectTypeExtension(_ int)                 { t.track() }
+func (t *schemaCoordinateTracker) LeaveObjectTypeExtension(_ int)                 { t.track() }
+func (t *schemaCoordinateTracker) EnterFieldDefinition(_ int)                     { t.track() }
+func (t *schemaCoordinateTracker) LeaveFieldDefinition(_ int)                     { t.track() }
+func (t *schemaCoordinateTracker) EnterInputValueDefinition(_ int)                { t.track() }
+func (t *schemaCoordinateTracker) LeaveInputValueDefinition(_ int)                { t.track() }
+func (t *schemaCoordinateTracker) EnterInterfaceTypeDefinition(_ int)             { t.track() }
+func (t *schemaCoordinateTracker) LeaveInterfaceTypeDefinition(_ int)             { t.track() }
+func (t *schemaCoordinateTracker) EnterInterfaceTypeExtension(_ int)              { t.track() }
+func (t *schemaCoordinateTracker) LeaveInterfaceTypeExtension(_ int)              { t.track() }
+func (t *schemaCoordinateTracker) EnterScalarTypeDefinition(_ int)                { t.track() }
+func (t *schemaCoordinateTracker) LeaveScalarTypeDefinition(_ int)                { t.track() }
+func (t *schemaCoordinateTracker) EnterScalarTypeExtension(_ int)                 { t.track() }
+func (t *schemaCoordinateTracker) LeaveScalarTypeExtension(_ int)                 { t.track() }
+func (t *schemaCoordinateTracker) EnterUnionTypeDefinition(_ int)                 { t.track() }
+func (t *schemaCoordinateTracker) LeaveUnionTypeDefinition(_ int)                 { t.track() }
+func (t *schemaCoordinateTracker) EnterUnionTypeExtension(_ int)                  { t.track() }
+func (t *schemaCoordinateTracker) LeaveUnionTypeExtension(_ int)                  { t.track() }
+func (t *schemaCoordinateTracker) EnterUnionMemberType(_ int)                     { t.track() }
+func (t *schemaCoordinateTracker) LeaveUnionMemberType(_ int)                     { t.track() }
+func (t *schemaCoordinateTracker) EnterEnumTypeDefinition(_ int)                  { t.track() }
+func (t *schemaCoordinateTracker) LeaveEnumTypeDefinition(_ int)                  { t.track() }
+func (t *schemaCoordinateTracker) EnterEnumTypeExtension(_ int)                   { t.track() }
+func (t *schemaCoordinateTracker) LeaveEnumTypeExtension(_ int)                   { t.track() }
+func (t *schemaCoordinateTracker) EnterEnumValueDefinition(_ int)                 { t.track() }
+func (t *schemaCoordinateTracker) LeaveEnumValueDefinition(_ int)                 { t.track() }
+func (t *schemaCoordinateTracker) EnterInputObjectTypeDefinition(_ int)           { t.track() }
+func (t *schemaCoordinateTracker) LeaveInputObjectTypeDefinition(_ int)           { t.track() }
+func (t *schemaCoordinateTracker) EnterInputObjectTypeExtension(_ int)            { t.track() }
+func (t *schemaCoordinateTracker) LeaveInputObjectTypeExtension(_ int)            { t.track() }
+func (t *schemaCoordinateTracker) EnterDirectiveDefinition(_ int)                 { t.track() }
+func (t *schemaCoordinateTracker) LeaveDirectiveDefinition(_ int)                 { t.track() }
+func (t *schemaCoordinateTracker) EnterDirectiveLocation(_ ast.DirectiveLocation) { t.track() }
+func (t *schemaCoordinateTracker) LeaveDirectiveLocation(_ ast.DirectiveLocation) { t.track() }
+func (t *schemaCoordinateTracker) EnterSchemaDefinition(_ int)                    { t.trackWithoutCoordinate() }
+func (t *schemaCoordinateTracker) LeaveSchemaDefinition(_ int)                    { t.trackWithoutCoordinate() }
+func (t *schemaCoordinateTracker) EnterSchemaExtension(_ int)                     { t.trackWithoutCoordinate() }
+func (t *schemaCoordinateTracker) LeaveSchemaExtension(_ int)                     { t.trackWithoutCoordinate() }
+func (t *schemaCoordinateTracker) EnterRootOperationTypeDefinition(_ int)         { t.trackWithoutCoordinate() }
+func (t *schemaCoordinateTracker) LeaveRootOperationTypeDefinition(_ int)         { t.trackWithoutCoordinate() }
@@ -0,0 +1,115 @@
+package graphql
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/graphqlerrors"
+)
+
+func TestSchema_ValidationReport(t *testing.T) {
+	t.Run("valid schema without warnings", func(t *testing.T) {
+		schema, err := NewSchemaFromString("schema { query: Query } type Query { hello: String }")
+		require.NoError(t, err)
+
+		report, err := schema.ValidationReport()
+		require.NoError(t, err)
+		assert.True(t, report.Valid)
+		assert.Empty(t, report.Errors)
+		assert.False(t, report.HasWarnings())
+	})
+
+	t.Run("errors carry code and coordinate", func(t *testing.T) {
+		schema, err := NewSchemaFromString(`schema { query: Query }
+type Query {
+	hello: String
+	hello: Int
+}
+enum Role {
+	ADMIN
+	ADMIN
+}
+type Unused { role: Role }`)
+		require.NoError(t, err)
+
+		report, err := schema.ValidationReport()
+		require.NoError(t, err)
+		assert.False(t, report.Valid)
+		require.Len(t, report.Errors, 2)
+
+		assert.Equal(t, SchemaValidationSeverityError, report.Errors[0].Severity)
+		assert.Equal(t, SchemaValidationCodeUniqueFieldDefinitionNames, report.Errors[0].Code)
+		assert.Equal(t, "Query.hello", report.Errors[0].Coordinate)
+		assert.Equal(t, []graphqlerrors.Location{{Line: 4, Column: 7}}, report.Errors[0].Locations)
+
+		assert.Equal(t, SchemaValidationCodeUniqueEnumValueNames, report.Errors[1].Code)
+		assert.Equal(t, "Role.ADMIN", report.Errors[1].Coordinate)
+		assert.Equal(t, []graphqlerrors.Location{{Line: 8, Column: 2}}, report.Errors[1].Locations)
+
+		require.Len(t, report.Warnings, 1)
+		assert.Equal(t, SchemaValidationIssue{
+			Severity:   SchemaValidationSeverityWarning,
+			Code:       SchemaValidationCodeUnusedType,
+			Message:    "type 'Unused' is defined but never used",
+			Coordinate: "Unused",
+			Locations:  []graphqlerrors.Location{{Line: 10, Column: 1}},
+		}, report.Warnings[0])
+	})
+
+	t.Run("unknown types are reported as errors", func(t *testing.T) {
+		schema, err := NewSchemaFromString("schema { query: Query } type Query { user: User }")
+		require.NoError(t, err)
+
+		report, err := schema.ValidationReport()
+		require.NoError(t, err)
+		assert.False(t, report.Valid)
+		require.Len(t, report.Errors, 1)
+		assert.Equal(t, SchemaValidationCodeKnownTypeNames, report.Errors[0].Code)
+	})
+
+	t.Run("relay connections are only validated if enabled", func(t *testing.T) {
+		schema, err := NewSchemaFromString(`schema { query: Query }
+type Query { users: UserConnection }
+type UserConnection { edges: [UserEdge] pageInfo: PageInfo! }
+type UserEdge { node: String cursor: String! }
+type PageInfo { hasPreviousPage: Boolean! hasNextPage: Boolean! startCursor: String endCursor: String }`)
+		require.NoError(t, err)
+
+		report, err := schema.ValidationReport()
+		require.NoError(t, err)
+		assert.True(t, report.Valid)
+
+		report, err = schema.ValidationReport(WithRelayConnectionValidation())
+		require.NoError(t, err)
+		assert.False(t, report.Valid)
+		require.Len(t, report.Errors, 1)
+		assert.Equal(t, SchemaValidationCodeRelayConnections, report.Errors[0].Code)
+		assert.Equal(t, "Query.users", report.Errors[0].Coordinate)
+		assert.Equal(t, "'Query.users' violates the relay cursor connections spec: fields returning a connection must have the arguments first and after, last and before, or both", report.Errors[0].Message)
+
+		result, err := schema.Validate(WithRelayConnectionValidation())
+		require.NoError(t, err)
+		assert.False(t, result.Valid)
+	})
+
+	t.Run("write json", func(t *testing.T) {
+		schema, err := NewSchemaFromString("schema { query: Query } type Query { hello: String } scalar Unused")
+		require.NoError(t, err)
+
+		report, err := schema.ValidationReport()
+		require.NoError(t, err)
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, report.WriteJSON(buf))
+		assert.JSONEq(t, `{
+			"valid": true,
+			"errors": [],
+			"warnings": [
+				{"severity":"WARNING","code":"UNUSED_TYPE","message":"type 'Unused' is defined but never used","coordinate":"Unused","locations":[{"line":1,"column":54}]}
+			]
+		}`, buf.String())
+	})
+}
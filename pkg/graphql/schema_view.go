@@ -0,0 +1,355 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astnormalization"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+var (
+	ErrSchemaViewWithoutQueryType = errors.New("schema view hides the query type")
+	ErrSchemaViewNotFound         = errors.New("schema view not found")
+	// ErrSchemaViewMixedIntrospection is returned for operations selecting introspection fields together with other root fields
+	// in a schema view, because only pure introspection operations are answered from the introspection of the view.
+	ErrSchemaViewMixedIntrospection = errors.New("introspection fields can't be selected together with other root fields in a schema view")
+)
+
+// SchemaViewConfig configures a view of the schema which hides types and fields from a group of clients.
+// Fields referencing hidden types and types without any visible fields, members or values are hidden as well.
+type SchemaViewConfig struct {
+	// Name identifies the view, it's returned by the SchemaViewSelector to select the view for a request
+	Name string
+	// ExcludeDirectives hides all types, fields, arguments, input fields and enum values annotated with one of the directives,
+	// e.g. "internal" for @internal. The definitions of the directives are hidden as well.
+	ExcludeDirectives []string
+	// HiddenTypes are the names of the types which are hidden
+	HiddenTypes []string
+	// HiddenFields are the fields which are hidden, e.g. {TypeName: "Query", FieldNames: []string{"users"}}
+	HiddenFields []TypeFields
+}
+
+// SchemaViewSelector selects the schema view for a request based on the identity of the client,
+// e.g. a role from the headers of the request. An empty name selects the complete schema.
+type SchemaViewSelector interface {
+	SelectSchemaView(ctx context.Context, operation *Request) (viewName string)
+}
+
+type SchemaViewSelectorFunc func(ctx context.Context, operation *Request) (viewName string)
+
+func (f SchemaViewSelectorFunc) SelectSchemaView(ctx context.Context, operation *Request) (viewName string) {
+	return f(ctx, operation)
+}
+
+// NewSchemaView returns a new schema containing the types and fields of the schema which aren't hidden by the config
+func NewSchemaView(schema *Schema, config SchemaViewConfig) (*Schema, error) {
+	view, err := createSchema(schema.rawSchema, false)
+	if err != nil {
+		return nil, err
+	}
+
+	report := operationreport.Report{}
+	astnormalization.NormalizeDefinition(&view.document, &report)
+	if report.HasErrors() {
+		return nil, report
+	}
+
+	filter := newSchemaViewFilter(&view.document, config)
+	filter.filter()
+	if filter.hiddenTypes[view.document.Index.QueryTypeName.String()] {
+		return nil, ErrSchemaViewWithoutQueryType
+	}
+	filter.removeHiddenNodes()
+
+	buf := &bytes.Buffer{}
+	if err := astprinter.PrintIndent(&view.document, nil, []byte("  "), buf); err != nil {
+		return nil, err
+	}
+	return createSchema(buf.Bytes(), false)
+}
+
+type schemaViewFilter struct {
+	document          *ast.Document
+	excludeDirectives map[string]bool
+	hiddenTypes       map[string]bool
+	hiddenFields      map[string]map[string]bool
+}
+
+func newSchemaViewFilter(document *ast.Document, config SchemaViewConfig) *schemaViewFilter {
+	filter := &schemaViewFilter{
+		document:          document,
+		excludeDirectives: make(map[string]bool, len(config.ExcludeDirectives)),
+		hiddenTypes:       make(map[string]bool, len(config.HiddenTypes)),
+		hiddenFields:      make(map[string]map[string]bool, len(config.HiddenFields)),
+	}
+	for _, directive := range config.ExcludeDirectives {
+		filter.excludeDirectives[directive] = true
+	}
+	for _, typeName := range config.HiddenTypes {
+		filter.hiddenTypes[typeName] = true
+	}
+	for _, typeFields := range config.HiddenFields {
+		fields, ok := filter.hiddenFields[typeFields.TypeName]
+		if !ok {
+			fields = map[string]bool{}
+			filter.hiddenFields[typeFields.TypeName] = fields
+		}
+		for _, fieldName := range typeFields.FieldNames {
+			fields[fieldName] = true
+		}
+	}
+	return filter
+}
+
+// filter removes hidden elements from the document until hiding a type doesn't hide any other elements
+func (f *schemaViewFilter) filter() {
+	for _, node := range f.document.RootNodes {
+		if f.isExcluded(f.nodeDirectives(node)) {
+			f.hideType(f.document.NodeNameString(node))
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, node := range f.document.RootNodes {
+			typeName := f.document.NodeNameString(node)
+			if f.hiddenTypes[typeName] || strings.HasPrefix(typeName, "__") {
+				continue
+			}
+			if f.filterNode(node, typeName) {
+				f.hideType(typeName)
+				changed = true
+			}
+		}
+	}
+}
+
+func (f *schemaViewFilter) hideType(typeName string) {
+	f.hiddenTypes[typeName] = true
+}
+
+// filterNode removes the hidden elements of a type and reports whether the type has to be hidden itself
+func (f *schemaViewFilter) filterNode(node ast.Node, typeName string) (hide bool) {
+	switch node.Kind {
+	case ast.NodeKindObjectTypeDefinition:
+		definition := &f.document.ObjectTypeDefinitions[node.Ref]
+		definition.ImplementsInterfaces.Refs = f.filterTypes(definition.ImplementsInterfaces.Refs)
+		definition.FieldsDefinition.Refs = f.filterFields(typeName, definition.FieldsDefinition.Refs)
+		definition.HasFieldDefinitions = len(definition.FieldsDefinition.Refs) != 0
+		return !f.hasVisibleFields(definition.FieldsDefinition.Refs)
+	case ast.NodeKindInterfaceTypeDefinition:
+		definition := &f.document.InterfaceTypeDefinitions[node.Ref]
+		definition.ImplementsInterfaces.Refs = f.filterTypes(definition.ImplementsInterfaces.Refs)
+		definition.FieldsDefinition.Refs = f.filterFields(typeName, definition.FieldsDefinition.Refs)
+		definition.HasFieldDefinitions = len(definition.FieldsDefinition.Refs) != 0
+		return !f.hasVisibleFields(definition.FieldsDefinition.Refs)
+	case ast.NodeKindUnionTypeDefinition:
+		definition := &f.document.UnionTypeDefinitions[node.Ref]
+		definition.UnionMemberTypes.Refs = f.filterTypes(definition.UnionMemberTypes.Refs)
+		definition.HasUnionMemberTypes = len(definition.UnionMemberTypes.Refs) != 0
+		return !definition.HasUnionMemberTypes
+	case ast.NodeKindInputObjectTypeDefinition:
+		definition := &f.document.InputObjectTypeDefinitions[node.Ref]
+		var requiredFieldHidden bool
+		definition.InputFieldsDefinition.Refs, requiredFieldHidden = f.filterInputValues(typeName, definition.InputFieldsDefinition.Refs)
+		definition.HasInputFieldsDefinition = len(definition.InputFieldsDefinition.Refs) != 0
+		// input objects can't be created without their required fields
+		return requiredFieldHidden || !definition.HasInputFieldsDefinition
+	case ast.NodeKindEnumTypeDefinition:
+		definition := &f.document.EnumTypeDefinitions[node.Ref]
+		refs := definition.EnumValuesDefinition.Refs[:0]
+		for _, ref := range definition.EnumValuesDefinition.Refs {
+			if !f.isExcluded(f.document.EnumValueDefinitions[ref].Directives.Refs) {
+				refs = append(refs, ref)
+			}
+		}
+		definition.EnumValuesDefinition.Refs = refs
+		definition.HasEnumValuesDefinition = len(refs) != 0
+		return !definition.HasEnumValuesDefinition
+	}
+	return false
+}
+
+// hasVisibleFields reports whether one of the fields isn't an introspection field like __typename
+func (f *schemaViewFilter) hasVisibleFields(refs []int) bool {
+	for _, ref := range refs {
+		if !strings.HasPrefix(f.document.FieldDefinitionNameString(ref), "__") {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *schemaViewFilter) filterFields(typeName string, refs []int) []int {
+	filtered := refs[:0]
+	for _, ref := range refs {
+		fieldDefinition := &f.document.FieldDefinitions[ref]
+		if f.hiddenFields[typeName][f.document.FieldDefinitionNameString(ref)] ||
+			f.isExcluded(fieldDefinition.Directives.Refs) ||
+			f.hiddenTypes[f.document.ResolveTypeNameString(fieldDefinition.Type)] {
+			continue
+		}
+		arguments, requiredArgumentHidden := f.filterInputValues("", fieldDefinition.ArgumentsDefinition.Refs)
+		if requiredArgumentHidden {
+			continue
+		}
+		fieldDefinition.ArgumentsDefinition.Refs = arguments
+		fieldDefinition.HasArgumentsDefinitions = len(arguments) != 0
+		filtered = append(filtered, ref)
+	}
+	return filtered
+}
+
+// filterInputValues removes hidden arguments or input fields and reports whether a required one was removed
+func (f *schemaViewFilter) filterInputValues(typeName string, refs []int) (filtered []int, requiredHidden bool) {
+	filtered = refs[:0]
+	for _, ref := range refs {
+		inputValue := f.document.InputValueDefinitions[ref]
+		if f.hiddenFields[typeName][f.document.InputValueDefinitionNameString(ref)] ||
+			f.isExcluded(inputValue.Directives.Refs) ||
+			f.hiddenTypes[f.document.ResolveTypeNameString(inputValue.Type)] {
+			if f.document.TypeIsNonNull(inputValue.Type) && !inputValue.DefaultValue.IsDefined {
+				requiredHidden = true
+			}
+			continue
+		}
+		filtered = append(filtered, ref)
+	}
+	return filtered, requiredHidden
+}
+
+func (f *schemaViewFilter) filterTypes(refs []int) []int {
+	filtered := refs[:0]
+	for _, ref := range refs {
+		if !f.hiddenTypes[f.document.ResolveTypeNameString(ref)] {
+			filtered = append(filtered, ref)
+		}
+	}
+	return filtered
+}
+
+func (f *schemaViewFilter) isExcluded(directives []int) bool {
+	for _, ref := range directives {
+		if f.excludeDirectives[f.document.DirectiveNameString(ref)] {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *schemaViewFilter) nodeDirectives(node ast.Node) []int {
+	switch node.Kind {
+	case ast.NodeKindObjectTypeDefinition:
+		return f.document.ObjectTypeDefinitions[node.Ref].Directives.Refs
+	case ast.NodeKindInterfaceTypeDefinition:
+		return f.document.InterfaceTypeDefinitions[node.Ref].Directives.Refs
+	case ast.NodeKindUnionTypeDefinition:
+		return f.document.UnionTypeDefinitions[node.Ref].Directives.Refs
+	case ast.NodeKindInputObjectTypeDefinition:
+		return f.document.InputObjectTypeDefinitions[node.Ref].Directives.Refs
+	case ast.NodeKindEnumTypeDefinition:
+		return f.document.EnumTypeDefinitions[node.Ref].Directives.Refs
+	case ast.NodeKindScalarTypeDefinition:
+		return f.document.ScalarTypeDefinitions[node.Ref].Directives.Refs
+	}
+	return nil
+}
+
+// removeHiddenNodes removes the hidden types, the definitions of the excluded directives
+// and the root operation types of hidden mutation or subscription types
+func (f *schemaViewFilter) removeHiddenNodes() {
+	rootNodes := f.document.RootNodes[:0]
+	for _, node := range f.document.RootNodes {
+		switch node.Kind {
+		case ast.NodeKindDirectiveDefinition:
+			if f.excludeDirectives[f.document.DirectiveDefinitionNameString(node.Ref)] {
+				continue
+			}
+		case ast.NodeKindSchemaDefinition:
+			definition := &f.document.SchemaDefinitions[node.Ref]
+			refs := definition.RootOperationTypeDefinitions.Refs[:0]
+			for _, ref := range definition.RootOperationTypeDefinitions.Refs {
+				namedType := f.document.RootOperationTypeDefinitions[ref].NamedType
+				if !f.hiddenTypes[f.document.Input.ByteSliceString(namedType.Name)] {
+					refs = append(refs, ref)
+				}
+			}
+			definition.RootOperationTypeDefinitions.Refs = refs
+		default:
+			if f.hiddenTypes[f.document.NodeNameString(node)] {
+				continue
+			}
+		}
+		rootNodes = append(rootNodes, node)
+	}
+	f.document.RootNodes = rootNodes
+}
+
+// selectSchemaView returns the schema view selected for the operation, or the complete schema without a view
+// SelectSchema returns the schema the operation is executed against, i.e. the schema view selected for the operation
+// or the complete schema if no view is selected, e.g. to serve the schema of a client.
+func (e *ExecutionEngineV2) SelectSchema(ctx context.Context, operation *Request) (*Schema, error) {
+	schema, _, err := e.selectSchemaView(ctx, operation)
+	return schema, err
+}
+
+func (e *ExecutionEngineV2) selectSchemaView(ctx context.Context, operation *Request) (*Schema, *schemaView, error) {
+	if e.config.schemaViewSelector == nil {
+		return e.config.schema, nil, nil
+	}
+	name := e.config.schemaViewSelector.SelectSchemaView(ctx, operation)
+	if name == "" {
+		return e.config.schema, nil, nil
+	}
+	view, ok := e.schemaViews[name]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrSchemaViewNotFound, name)
+	}
+	return view.schema, view, nil
+}
+
+// selectsIntrospection reports whether the operation selects __schema or __type next to other root fields
+func selectsIntrospection(operation *Request) bool {
+	classification, err := operation.Classify()
+	if err != nil {
+		return false
+	}
+	return containsString(classification.RootFields, schemaIntrospectionFieldName) ||
+		containsString(classification.RootFields, typeIntrospectionFieldName)
+}
+
+type schemaView struct {
+	schema                *Schema
+	introspectionResolver *introspectionResolver
+}
+
+func newSchemaViews(schema *Schema, configs []SchemaViewConfig) (map[string]*schemaView, error) {
+	if len(configs) == 0 {
+		return nil, nil
+	}
+	views := make(map[string]*schemaView, len(configs))
+	for _, config := range configs {
+		if _, exists := views[config.Name]; exists || config.Name == "" {
+			return nil, fmt.Errorf("schema view name '%s' must be unique and not empty", config.Name)
+		}
+		viewSchema, err := NewSchemaView(schema, config)
+		if err != nil {
+			return nil, fmt.Errorf("schema view '%s': %w", config.Name, err)
+		}
+		introspectionResolver, err := newIntrospectionResolver(&viewSchema.document)
+		if err != nil {
+			return nil, fmt.Errorf("schema view '%s': %w", config.Name, err)
+		}
+		views[config.Name] = &schemaView{
+			schema:                viewSchema,
+			introspectionResolver: introspectionResolver,
+		}
+	}
+	return views, nil
+}
@@ -0,0 +1,84 @@
+package graphql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const schemaViewTestSchema = `
+	directive @internal on FIELD_DEFINITION | OBJECT | ENUM_VALUE | INPUT_FIELD_DEFINITION | ARGUMENT_DEFINITION
+	schema { query: Query mutation: Mutation }
+	type Query { hello: String secret: String @internal users(filter: UserFilter): [User] admin: Admin }
+	type Mutation { deleteUser(id: ID!): Boolean @internal }
+	type User implements Node { id: ID! name: String role: Role audit: Audit }
+	interface Node { id: ID! }
+	type Admin @internal { id: ID! }
+	type Audit { admin: Admin }
+	enum Role { USER ADMIN @internal }
+	input UserFilter { name: String internalOnly: Boolean @internal }
+	union SearchResult = User | Admin
+`
+
+func TestNewSchemaView(t *testing.T) {
+	schema, err := NewSchemaFromString(schemaViewTestSchema)
+	require.NoError(t, err)
+
+	t.Run("hides excluded and hidden elements and everything depending on them", func(t *testing.T) {
+		view, err := NewSchemaView(schema, SchemaViewConfig{
+			Name:              "public",
+			ExcludeDirectives: []string{"internal"},
+			HiddenFields:      []TypeFields{{TypeName: "User", FieldNames: []string{"name"}}},
+		})
+		require.NoError(t, err)
+
+		printed := string(view.Document())
+		printed = printed[:strings.Index(printed, `"The 'Int' scalar type`)]
+		assert.Equal(t, `schema {
+    query: Query
+}
+
+type Query {
+    hello: String
+    users(filter: UserFilter): [User]
+    __schema: __Schema!
+    __type(name: String!): __Type
+    __typename: String!
+}
+
+type User implements Node {
+    id: ID!
+    role: Role
+    __typename: String!
+}
+
+interface Node {
+    id: ID!
+    __typename: String!
+}
+
+enum Role {
+    USER
+}
+
+input UserFilter {
+    name: String
+}
+
+union SearchResult = User
+
+`, printed)
+		assert.NotContains(t, string(view.Document()), "@internal")
+		assert.Contains(t, string(schema.Document()), "secret", "the complete schema is not modified")
+	})
+
+	t.Run("hiding the query type is an error", func(t *testing.T) {
+		_, err := NewSchemaView(schema, SchemaViewConfig{
+			Name:         "none",
+			HiddenFields: []TypeFields{{TypeName: "Query", FieldNames: []string{"hello", "secret", "users", "admin"}}},
+		})
+		assert.ErrorIs(t, err, ErrSchemaViewWithoutQueryType)
+	})
+}
@@ -0,0 +1,175 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/astparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/astprinter"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/staticdatasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+)
+
+// SubgraphConfig configures the engine to serve as a subgraph of an Apollo Federation compatible gateway,
+// see EngineV2Configuration.EnableSubgraph
+type SubgraphConfig struct {
+	// Entities are the types which are resolvable by their key fields, they are annotated with @key(fields: "...") in the SDL of the subgraph.
+	// The fields of an entity are resolved by the data sources from the representations of the gateway like the fields of any other object,
+	// so each field of an entity has to be resolvable from its key fields, see plan.DataSourceConfiguration.Entities.
+	// Without entities, the entities of all data sources are used.
+	Entities []plan.EntityConfiguration
+}
+
+const subgraphFederationTypes = `
+scalar _Any
+scalar _FieldSet
+type _Service { sdl: String }
+directive @external on FIELD_DEFINITION
+directive @requires(fields: _FieldSet!) on FIELD_DEFINITION
+directive @provides(fields: _FieldSet!) on FIELD_DEFINITION
+directive @key(fields: _FieldSet!) on OBJECT | INTERFACE
+directive @extends on OBJECT | INTERFACE
+`
+
+// subgraph is the federation schema of the engine together with the configuration of the _service and _entities fields
+type subgraph struct {
+	sdl         string
+	schema      *Schema
+	dataSources []plan.DataSourceConfiguration
+	fields      plan.FieldConfigurations
+}
+
+// newSubgraph extends the schema with the _service and _entities fields.
+// _service returns the SDL of the schema with the @key directives of the entities.
+// _entities returns the representations as objects, the fields of the entities are then planned on the data sources
+// like fields of any other object, which is why the key fields are the child nodes of the _entities data source.
+func newSubgraph(schema *Schema, config SubgraphConfig, dataSources []plan.DataSourceConfiguration) (*subgraph, error) {
+	entities := config.Entities
+	if len(entities) == 0 {
+		entities = dataSourceEntities(dataSources)
+	}
+
+	doc, report := astparser.ParseGraphqlDocumentBytes(schema.rawInput)
+	if report.HasErrors() {
+		return nil, report
+	}
+
+	entityTypeNames := make([]string, 0, len(entities))
+	keyFields := make([]plan.TypeField, 0, len(entities))
+	for _, entity := range entities {
+		node, ok := doc.Index.FirstNodeByNameStr(entity.TypeName)
+		if !ok || node.Kind != ast.NodeKindObjectTypeDefinition {
+			return nil, fmt.Errorf("subgraph entity %s is not an object type", entity.TypeName)
+		}
+		if len(entity.KeyFields) == 0 {
+			return nil, fmt.Errorf("subgraph entity %s has no key fields", entity.TypeName)
+		}
+		addKeyDirective(&doc, node.Ref, entity.KeyFields)
+		entityTypeNames = append(entityTypeNames, entity.TypeName)
+		keyFields = append(keyFields, plan.TypeField{TypeName: entity.TypeName, FieldNames: entity.KeyFields})
+	}
+
+	sdl, err := astprinter.PrintStringIndent(&doc, nil, "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	queryTypeName := doc.Index.QueryTypeName.String()
+	if queryTypeName == "" {
+		queryTypeName = string(ast.DefaultQueryTypeName)
+	}
+	queryNode, ok := doc.Index.FirstNodeByNameStr(queryTypeName)
+	if !ok || queryNode.Kind != ast.NodeKindObjectTypeDefinition {
+		return nil, fmt.Errorf("subgraph requires the query type %s", queryTypeName)
+	}
+	rootFieldNames := []string{"_service"}
+	addQueryField(&doc, queryNode.Ref, "_service", doc.AddNonNullNamedType([]byte("_Service")), nil)
+	if len(entityTypeNames) != 0 {
+		rootFieldNames = append(rootFieldNames, "_entities")
+		representations := doc.ImportInputValueDefinition("representations", "",
+			doc.AddNonNullType(doc.AddListType(doc.AddNonNullNamedType([]byte("_Any")))), ast.DefaultValue{})
+		addQueryField(&doc, queryNode.Ref, "_entities", doc.AddNonNullType(doc.AddListType(doc.AddNamedType([]byte("_Entity")))), []int{representations})
+	}
+
+	federationSchema, err := astprinter.PrintStringIndent(&doc, nil, "  ")
+	if err != nil {
+		return nil, err
+	}
+	federationSchema += subgraphFederationTypes
+	if len(entityTypeNames) != 0 {
+		federationSchema += "union _Entity = " + strings.Join(entityTypeNames, " | ") + "\n"
+	}
+
+	s := &subgraph{
+		sdl: sdl,
+	}
+	if s.schema, err = NewSchemaFromString(federationSchema); err != nil {
+		return nil, err
+	}
+
+	serviceData, err := json.Marshal(map[string]string{"sdl": sdl})
+	if err != nil {
+		return nil, err
+	}
+	s.dataSources = append(s.dataSources, plan.DataSourceConfiguration{
+		RootNodes:  []plan.TypeField{{TypeName: queryTypeName, FieldNames: rootFieldNames[:1]}},
+		ChildNodes: []plan.TypeField{{TypeName: "_Service", FieldNames: []string{"sdl"}}},
+		Factory:    &staticdatasource.Factory{},
+		Custom:     staticdatasource.ConfigJSON(staticdatasource.Configuration{Data: string(serviceData)}),
+	})
+	s.fields = append(s.fields, plan.FieldConfiguration{TypeName: queryTypeName, FieldName: "_service", DisableDefaultMapping: true})
+
+	if len(entityTypeNames) != 0 {
+		s.dataSources = append(s.dataSources, plan.DataSourceConfiguration{
+			RootNodes:  []plan.TypeField{{TypeName: queryTypeName, FieldNames: rootFieldNames[1:]}},
+			ChildNodes: keyFields,
+			Factory:    &staticdatasource.Factory{},
+			Custom:     staticdatasource.ConfigJSON(staticdatasource.Configuration{Data: "{{ .arguments.representations }}"}),
+		})
+		s.fields = append(s.fields, plan.FieldConfiguration{
+			TypeName:              queryTypeName,
+			FieldName:             "_entities",
+			DisableDefaultMapping: true,
+			Arguments: plan.ArgumentsConfigurations{
+				{
+					Name:         "representations",
+					SourceType:   plan.FieldArgumentSource,
+					RenderConfig: plan.RenderArgumentAsJSONValue,
+				},
+			},
+		})
+	}
+
+	return s, nil
+}
+
+func dataSourceEntities(dataSources []plan.DataSourceConfiguration) []plan.EntityConfiguration {
+	var entities []plan.EntityConfiguration
+	seen := make(map[string]bool)
+	for i := range dataSources {
+		for _, entity := range dataSources[i].Entities {
+			if seen[entity.TypeName] {
+				continue
+			}
+			seen[entity.TypeName] = true
+			entities = append(entities, entity)
+		}
+	}
+	return entities
+}
+
+func addKeyDirective(doc *ast.Document, objectTypeDefinitionRef int, keyFields []string) {
+	fields := doc.ImportStringValue([]byte(strings.Join(keyFields, " ")), false)
+	argument := doc.ImportArgument("fields", ast.Value{Kind: ast.ValueKindString, Ref: fields})
+	directive := doc.ImportDirective("key", []int{argument})
+	doc.ObjectTypeDefinitions[objectTypeDefinitionRef].Directives.Refs = append(doc.ObjectTypeDefinitions[objectTypeDefinitionRef].Directives.Refs, directive)
+	doc.ObjectTypeDefinitions[objectTypeDefinitionRef].HasDirectives = true
+}
+
+func addQueryField(doc *ast.Document, objectTypeDefinitionRef int, name string, typeRef int, argumentRefs []int) {
+	field := doc.ImportFieldDefinition(name, "", typeRef, argumentRefs, nil)
+	doc.ObjectTypeDefinitions[objectTypeDefinitionRef].FieldsDefinition.Refs = append(doc.ObjectTypeDefinitions[objectTypeDefinitionRef].FieldsDefinition.Refs, field)
+	doc.ObjectTypeDefinitions[objectTypeDefinitionRef].HasFieldDefinitions = true
+}
@@ -0,0 +1,96 @@
+package graphql
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/rest_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/staticdatasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestExecutionEngineV2_Subgraph(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			me: User
+		}
+		type User {
+			id: ID!
+			name: String
+		}
+		type Product {
+			upc: String!
+		}`)
+	require.NoError(t, err)
+
+	newEngine := func(t *testing.T, config SubgraphConfig) (*ExecutionEngineV2, error) {
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes:  []plan.TypeField{{TypeName: "Query", FieldNames: []string{"me"}}},
+				ChildNodes: []plan.TypeField{{TypeName: "User", FieldNames: []string{"id"}}},
+				Factory:    &staticdatasource.Factory{},
+				Custom:     staticdatasource.ConfigJSON(staticdatasource.Configuration{Data: `{"me":{"id":"1"}}`}),
+			},
+			{
+				RootNodes: []plan.TypeField{{TypeName: "User", FieldNames: []string{"name"}}},
+				Entities:  []plan.EntityConfiguration{{TypeName: "User", KeyFields: []string{"id"}}},
+				Factory: &rest_datasource.Factory{
+					Client: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							id := strings.TrimPrefix(req.URL.Path, "/users/")
+							return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"id":"` + id + `","name":"User ` + id + `"}`))}
+						}),
+					},
+				},
+				Custom: rest_datasource.ConfigJSON(rest_datasource.Configuration{
+					Fetch: rest_datasource.FetchConfiguration{
+						URL:    "https://users.service/users/{{ .object.id }}",
+						Method: "GET",
+					},
+				}),
+			},
+		})
+		engineConf.EnableSubgraph(config)
+		return NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	}
+
+	engine, err := newEngine(t, SubgraphConfig{})
+	require.NoError(t, err)
+
+	execute := func(t *testing.T, operation *Request) string {
+		t.Helper()
+		resultWriter := NewEngineResultWriter()
+		require.NoError(t, engine.Execute(context.Background(), operation, &resultWriter))
+		return resultWriter.String()
+	}
+
+	t.Run("_service returns the sdl with the keys of the entities", func(t *testing.T) {
+		out := execute(t, &Request{Query: `{ _service { sdl } }`})
+		assert.Equal(t, `{"data":{"_service":{"sdl":"type Query {\n    me: User\n}\n\ntype User @key(fields: \"id\") {\n    id: ID!\n    name: String\n}\n\ntype Product {\n    upc: String!\n}"}}}`, out)
+	})
+
+	t.Run("_entities resolves the representations with the data sources", func(t *testing.T) {
+		out := execute(t, &Request{
+			Query:     `query($representations: [_Any!]!) { _entities(representations: $representations) { __typename ... on User { id name } } }`,
+			Variables: []byte(`{"representations":[{"__typename":"User","id":"1"},{"__typename":"User","id":"2"}]}`),
+		})
+		assert.Equal(t, `{"data":{"_entities":[{"__typename":"User","id":"1","name":"User 1"},{"__typename":"User","id":"2","name":"User 2"}]}}`, out)
+	})
+
+	t.Run("regular fields are resolved as before", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"me":{"id":"1","name":"User 1"}}}`, execute(t, &Request{Query: `{ me { id name } }`}))
+	})
+
+	t.Run("entities must be object types", func(t *testing.T) {
+		_, err := newEngine(t, SubgraphConfig{Entities: []plan.EntityConfiguration{{TypeName: "Unknown", KeyFields: []string{"id"}}}})
+		assert.EqualError(t, err, "subgraph entity Unknown is not an object type")
+	})
+}
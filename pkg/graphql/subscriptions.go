@@ -0,0 +1,158 @@
+package graphql
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+)
+
+// ActiveSubscription is the state of a subscription executed by the engine, see ExecutionEngineV2.ActiveSubscriptions
+type ActiveSubscription struct {
+	ID            uint64
+	OperationName string
+	// Client identifies the client of the subscription, see WithClient
+	Client          string
+	StartedAt       time.Time
+	Age             time.Duration
+	EventsDelivered uint64
+	BytesDelivered  uint64
+	// BufferedBytes is the size of the event which is resolved but not yet flushed to the client,
+	// it stays above 0 while a slow client blocks the delivery of the event
+	BufferedBytes int64
+}
+
+// SubscriptionStats are the aggregated counters of all subscriptions executed by the engine
+type SubscriptionStats struct {
+	Active          int
+	Started         uint64
+	Completed       uint64
+	Failed          uint64
+	EventsDelivered uint64
+	BytesDelivered  uint64
+}
+
+// WithClient identifies the client of the operation, e.g. by its remote address, it's listed in the ActiveSubscriptions
+func WithClient(client string) ExecutionOptionsV2 {
+	return func(ctx *internalExecutionContext) {
+		ctx.client = client
+	}
+}
+
+// ActiveSubscriptions returns the subscriptions currently executed by the engine, ordered by their start
+func (e *ExecutionEngineV2) ActiveSubscriptions() []ActiveSubscription {
+	return e.subscriptions.active(time.Now())
+}
+
+// SubscriptionStats returns the aggregated counters of the subscriptions executed by the engine
+func (e *ExecutionEngineV2) SubscriptionStats() SubscriptionStats {
+	return e.subscriptions.stats()
+}
+
+type subscriptionRegistry struct {
+	eventsDelivered, bytesDelivered uint64
+
+	mu                         sync.Mutex
+	nextID                     uint64
+	subscriptions              map[uint64]*subscriptionWriter
+	started, completed, failed uint64
+}
+
+// start registers the subscription, the returned writer counts the events delivered to the client
+func (r *subscriptionRegistry) start(operationName, client string, writer resolve.FlushWriter) *subscriptionWriter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.subscriptions == nil {
+		r.subscriptions = map[uint64]*subscriptionWriter{}
+	}
+	r.nextID++
+	r.started++
+	subscription := &subscriptionWriter{
+		writer:        writer,
+		registry:      r,
+		id:            r.nextID,
+		operationName: operationName,
+		client:        client,
+		startedAt:     time.Now(),
+	}
+	r.subscriptions[subscription.id] = subscription
+	return subscription
+}
+
+func (r *subscriptionRegistry) done(subscription *subscriptionWriter, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.subscriptions, subscription.id)
+	if err != nil {
+		r.failed++
+		return
+	}
+	r.completed++
+}
+
+func (r *subscriptionRegistry) active(now time.Time) []ActiveSubscription {
+	r.mu.Lock()
+	active := make([]ActiveSubscription, 0, len(r.subscriptions))
+	for _, subscription := range r.subscriptions {
+		active = append(active, ActiveSubscription{
+			ID:              subscription.id,
+			OperationName:   subscription.operationName,
+			Client:          subscription.client,
+			StartedAt:       subscription.startedAt,
+			Age:             now.Sub(subscription.startedAt),
+			EventsDelivered: atomic.LoadUint64(&subscription.eventsDelivered),
+			BytesDelivered:  atomic.LoadUint64(&subscription.bytesDelivered),
+			BufferedBytes:   atomic.LoadInt64(&subscription.buffered),
+		})
+	}
+	r.mu.Unlock()
+
+	sort.Slice(active, func(i, j int) bool {
+		return active[i].ID < active[j].ID
+	})
+	return active
+}
+
+func (r *subscriptionRegistry) stats() SubscriptionStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return SubscriptionStats{
+		Active:          len(r.subscriptions),
+		Started:         r.started,
+		Completed:       r.completed,
+		Failed:          r.failed,
+		EventsDelivered: atomic.LoadUint64(&r.eventsDelivered),
+		BytesDelivered:  atomic.LoadUint64(&r.bytesDelivered),
+	}
+}
+
+// subscriptionWriter counts the bytes written for an event until the event is flushed to the client
+type subscriptionWriter struct {
+	buffered        int64
+	eventsDelivered uint64
+	bytesDelivered  uint64
+
+	writer        resolve.FlushWriter
+	registry      *subscriptionRegistry
+	id            uint64
+	operationName string
+	client        string
+	startedAt     time.Time
+}
+
+func (s *subscriptionWriter) Write(p []byte) (n int, err error) {
+	n, err = s.writer.Write(p)
+	atomic.AddInt64(&s.buffered, int64(n))
+	return n, err
+}
+
+func (s *subscriptionWriter) Flush() {
+	s.writer.Flush()
+	delivered := uint64(atomic.SwapInt64(&s.buffered, 0))
+	atomic.AddUint64(&s.eventsDelivered, 1)
+	atomic.AddUint64(&s.bytesDelivered, delivered)
+	atomic.AddUint64(&s.registry.eventsDelivered, 1)
+	atomic.AddUint64(&s.registry.bytesDelivered, delivered)
+}
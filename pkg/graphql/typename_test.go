@@ -0,0 +1,143 @@
+package graphql
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestExecutionEngineV2_TypeName(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		schema {
+			query: Query
+			mutation: Mutation
+		}
+
+		type Query {
+			hello: String
+			pet: Pet
+		}
+
+		type Mutation {
+			like: Boolean
+		}
+
+		interface Node {
+			id: ID
+		}
+
+		type Dog implements Node {
+			id: ID
+			name: String
+		}
+
+		type Cat implements Node {
+			id: ID
+			lives: Int
+		}
+
+		interface Swimmer {
+			fins: Int
+		}
+
+		type Fish implements Swimmer {
+			fins: Int
+		}
+
+		union Pet = Dog | Cat | Fish`)
+	require.NoError(t, err)
+
+	dataSource := func(url string, rootNodes, childNodes []plan.TypeField, upstreamQuery *string, response string) plan.DataSourceConfiguration {
+		return plan.DataSourceConfiguration{
+			RootNodes:  rootNodes,
+			ChildNodes: childNodes,
+			Factory: &graphql_datasource.Factory{
+				HTTPClient: &http.Client{
+					Transport: testRoundTripper(func(req *http.Request) *http.Response {
+						body, _ := ioutil.ReadAll(req.Body)
+						*upstreamQuery = string(body)
+						return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(response))}
+					}),
+				},
+			},
+			Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+				Fetch: graphql_datasource.FetchConfiguration{
+					URL:    url,
+					Method: http.MethodPost,
+				},
+			}),
+		}
+	}
+
+	var helloQuery, petQuery string
+	engineConf := NewEngineV2Configuration(schema)
+	engineConf.SetDataSources([]plan.DataSourceConfiguration{
+		dataSource("https://hello.service/graphql",
+			[]plan.TypeField{
+				{TypeName: "Query", FieldNames: []string{"hello"}},
+				{TypeName: "Mutation", FieldNames: []string{"like"}},
+			},
+			nil, &helloQuery, `{"data":{"hello":"world","like":true}}`,
+		),
+		dataSource("https://pets.service/graphql",
+			[]plan.TypeField{{TypeName: "Query", FieldNames: []string{"pet"}}},
+			[]plan.TypeField{
+				{TypeName: "Node", FieldNames: []string{"id"}},
+				{TypeName: "Swimmer", FieldNames: []string{"fins"}},
+				{TypeName: "Dog", FieldNames: []string{"id", "name"}},
+				{TypeName: "Cat", FieldNames: []string{"id", "lives"}},
+				{TypeName: "Fish", FieldNames: []string{"fins"}},
+			},
+			&petQuery, `{"data":{"pet":{"__typename":"Dog","id":"1","name":"Rex"}}}`,
+		),
+	})
+	engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+	require.NoError(t, err)
+
+	execute := func(t *testing.T, query string) string {
+		helloQuery, petQuery = "", ""
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: query}, &resultWriter)
+		require.NoError(t, err)
+		return resultWriter.String()
+	}
+
+	t.Run("resolves the __typename of root types without fetching it", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"__typename":"Query"}}`, execute(t, `{ __typename }`))
+		assert.Empty(t, helloQuery)
+
+		assert.Equal(t, `{"data":{"__typename":"Query","hello":"world"}}`, execute(t, `{ __typename hello }`))
+		assert.Equal(t, `{"query":"{hello}"}`, helloQuery)
+
+		assert.Equal(t, `{"data":{"typeName":"Mutation","like":true}}`, execute(t, `mutation { typeName: __typename like }`))
+		assert.Equal(t, `{"query":"mutation{like}"}`, helloQuery)
+	})
+
+	t.Run("resolves __typename in fragments on abstract types", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"pet":{"__typename":"Dog","id":"1"}}}`, execute(t, `{ pet { ... on Node { __typename id } } }`))
+		assert.Equal(t, `{"data":{"pet":{"name":"Rex"}}}`, execute(t, `{ pet { ... on Dog { name } ... on Swimmer { __typename fins } } }`))
+	})
+
+	t.Run("renders the __typename once if it's selected on the object and in fragments", func(t *testing.T) {
+		assert.Equal(t, `{"data":{"pet":{"__typename":"Dog","name":"Rex"}}}`, execute(t, `{ pet { ... on Dog { __typename name } __typename } }`))
+		assert.Equal(t, `{"data":{"pet":{"__typename":"Dog","id":"1"}}}`, execute(t, `{ pet { __typename ... on Node { __typename id } } }`))
+	})
+
+	t.Run("resolves introspection and data fields of different data sources in the same operation", func(t *testing.T) {
+		assert.Equal(t,
+			`{"data":{"__typename":"Query","__schema":{"queryType":{"name":"Query"}},"__type":{"name":"Dog","__typename":"__Type"},"hello":"world","pet":{"__typename":"Dog","name":"Rex"}}}`,
+			execute(t, `{ __typename __schema { queryType { name } } __type(name: "Dog") { name __typename } hello pet { __typename ... on Dog { name } } }`),
+		)
+		assert.Equal(t, `{"query":"{hello}"}`, helloQuery)
+		assert.Equal(t, `{"query":"{pet {__typename ... on Dog {name}}}"}`, petQuery)
+	})
+}
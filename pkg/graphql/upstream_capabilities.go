@@ -0,0 +1,59 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+const upstreamCapabilitiesTimeout = 10 * time.Second
+
+// detectUpstreamCapabilities probes the upstreams of the GraphQL data sources with CapabilitiesConfiguration.Detect concurrently
+// and returns a copy of the data sources with the detected capabilities in their configuration.
+// Data sources of which the detection fails keep their configured behaviour.
+func detectUpstreamCapabilities(ctx context.Context, logger logging.Logger, dataSources []plan.DataSourceConfiguration) []plan.DataSourceConfiguration {
+	var detected []plan.DataSourceConfiguration
+	wg := &sync.WaitGroup{}
+	for i := range dataSources {
+		factory, ok := dataSources[i].Factory.(*graphql_datasource.Factory)
+		if !ok {
+			continue
+		}
+		var config graphql_datasource.Configuration
+		if err := json.Unmarshal(dataSources[i].Custom, &config); err != nil || !config.Capabilities.Detect {
+			continue
+		}
+		if detected == nil {
+			detected = append([]plan.DataSourceConfiguration(nil), dataSources...)
+		}
+
+		wg.Add(1)
+		go func(dataSource *plan.DataSourceConfiguration, factory *graphql_datasource.Factory, config graphql_datasource.Configuration) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, upstreamCapabilitiesTimeout)
+			defer cancel()
+
+			capabilities, err := graphql_datasource.DetectCapabilities(ctx, factory.HTTPClient, config)
+			if err != nil {
+				logger.Warn("ExecutionEngineV2: detection of upstream capabilities failed, using the configured capabilities",
+					logging.String("url", config.Fetch.URL),
+					logging.Error(err),
+				)
+				return
+			}
+			config.Capabilities.Detected = &capabilities
+			dataSource.Custom = graphql_datasource.ConfigJson(config)
+		}(&detected[i], factory, config)
+	}
+	wg.Wait()
+
+	if detected == nil {
+		return dataSources
+	}
+	return detected
+}
@@ -0,0 +1,107 @@
+package graphql
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestExecutionEngineV2_UpstreamCapabilities(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case strings.Contains(string(body), "__schema"):
+			_, _ = w.Write([]byte(`{"data":{"__schema":{"subscriptionType":null,"directives":[]}}}`))
+		case strings.Contains(string(body), "persistedQuery") && !strings.Contains(string(body), `"query"`):
+			requests = append(requests, "hash")
+			_, _ = w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotFound"}]}`))
+		case strings.HasPrefix(string(body), "["):
+			w.WriteHeader(http.StatusBadRequest)
+		default:
+			requests = append(requests, "query")
+			_, _ = w.Write([]byte(`{"data":{"hello":"world"}}`))
+		}
+	}))
+	defer upstream.Close()
+
+	schema, err := NewSchemaFromString(`type Query { hello: String }`)
+	require.NoError(t, err)
+
+	newEngine := func(t *testing.T, capabilities graphql_datasource.CapabilitiesConfiguration) *ExecutionEngineV2 {
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{{TypeName: "Query", FieldNames: []string{"hello"}}},
+				Factory:   &graphql_datasource.Factory{HTTPClient: http.DefaultClient},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch:        graphql_datasource.FetchConfiguration{URL: upstream.URL},
+					Capabilities: capabilities,
+				}),
+			},
+		})
+		engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine
+	}
+
+	execute := func(t *testing.T, engine *ExecutionEngineV2) []string {
+		mu.Lock()
+		requests = nil
+		mu.Unlock()
+
+		resultWriter := NewEngineResultWriter()
+		require.NoError(t, engine.Execute(context.Background(), &Request{Query: `{ hello }`}, &resultWriter))
+		assert.Equal(t, `{"data":{"hello":"world"}}`, resultWriter.String())
+
+		mu.Lock()
+		defer mu.Unlock()
+		return requests
+	}
+
+	t.Run("uses the detected capabilities", func(t *testing.T) {
+		engine := newEngine(t, graphql_datasource.CapabilitiesConfiguration{Detect: true})
+		assert.Equal(t, []string{"hash", "query"}, execute(t, engine))
+	})
+
+	t.Run("overrides take precedence over the detection", func(t *testing.T) {
+		disabled := false
+		engine := newEngine(t, graphql_datasource.CapabilitiesConfiguration{Detect: true, PersistedQueries: &disabled})
+		assert.Equal(t, []string{"query"}, execute(t, engine))
+	})
+
+	t.Run("creates the engine if the detection fails without changing the configuration", func(t *testing.T) {
+		engineConf := NewEngineV2Configuration(schema)
+		dataSources := []plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{{TypeName: "Query", FieldNames: []string{"hello"}}},
+				Factory:   &graphql_datasource.Factory{HTTPClient: http.DefaultClient},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch:        graphql_datasource.FetchConfiguration{URL: "http://127.0.0.1:0"},
+					Capabilities: graphql_datasource.CapabilitiesConfiguration{Detect: true},
+				}),
+			},
+		}
+		custom := string(dataSources[0].Custom)
+		engineConf.SetDataSources(dataSources)
+		_, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+		assert.Equal(t, custom, string(dataSources[0].Custom))
+	})
+}
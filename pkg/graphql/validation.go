@@ -3,6 +3,7 @@ package graphql
 import (
 	"github.com/wundergraph/graphql-go-tools/pkg/astvalidation"
 	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+	"github.com/wundergraph/graphql-go-tools/pkg/variablesvalidation"
 )
 
 type ValidationResult struct {
@@ -40,6 +41,23 @@ func (r *Request) ValidateForSchema(schema *Schema) (result ValidationResult, er
 	return result, err
 }
 
+// ValidateVariables validates the variables of the request against the variable definitions of the operation,
+// independent of the execution of the request, e.g. to reject invalid variables before they are sent to an upstream.
+// The errors contain the path of the invalid value within the variables.
+func (r *Request) ValidateVariables(schema *Schema) (result ValidationResult, err error) {
+	if schema == nil {
+		return ValidationResult{Valid: false, Errors: nil}, ErrNilSchema
+	}
+
+	report := r.parseQueryOnce()
+	if report.HasErrors() {
+		return operationValidationResultFromReport(report)
+	}
+
+	variablesvalidation.Validate(&r.document, &schema.document, []byte(r.OperationName), r.Variables, &report)
+	return operationValidationResultFromReport(report)
+}
+
 // ValidateRestrictedFields validates a request by checking if `restrictedFields` contains blocked fields.
 //
 // Deprecated: This function can only handle blocked fields. Use `ValidateFieldRestrictions` if you
@@ -54,6 +54,28 @@ func TestRequest_ValidateForSchema(t *testing.T) {
 		assert.Greater(t, result.Errors.Count(), 0)
 	})
 
+	t.Run("should suggest similar names of the schema when validation fails", func(t *testing.T) {
+		schema, err := NewSchemaFromString("schema { query: Query } type Query { hello(name: String): String help: String goodbye: String }")
+		require.NoError(t, err)
+
+		validate := func(query string) string {
+			request := Request{Query: query}
+			result, err := request.ValidateForSchema(schema)
+			require.NoError(t, err)
+			require.False(t, result.Valid)
+
+			buf := new(bytes.Buffer)
+			_, err = result.Errors.WriteResponse(buf)
+			require.NoError(t, err)
+			return buf.String()
+		}
+
+		assert.Equal(t, `{"errors":[{"message":"field: helo not defined on type: Query","path":["query"],"extensions":{"suggestions":["hello","help"]}}]}`,
+			validate(`query Hello { helo }`))
+		assert.Equal(t, `{"errors":[{"message":"Unknown argument \"nam\" on field \"Query.hello\".","locations":[{"line":1,"column":21}],"extensions":{"suggestions":["name"]}}]}`,
+			validate(`query Hello { hello(nam: "world") }`))
+	})
+
 	t.Run("should successfully validate even when schema definition is missing", func(t *testing.T) {
 		request := Request{
 			OperationName: "Hello",
@@ -96,6 +118,49 @@ func TestRequest_ValidateForSchema(t *testing.T) {
 	})
 }
 
+func TestRequest_ValidateVariables(t *testing.T) {
+	t.Run("should return error when schema is nil", func(t *testing.T) {
+		request := Request{
+			Query: `query Droid($id: ID!) { droid(id: $id) { name } }`,
+		}
+
+		result, err := request.ValidateVariables(nil)
+		assert.Error(t, err)
+		assert.Equal(t, ErrNilSchema, err)
+		assert.Equal(t, ValidationResult{Valid: false, Errors: nil}, result)
+	})
+
+	t.Run("should be valid for valid variables", func(t *testing.T) {
+		request := Request{
+			OperationName: "Droid",
+			Variables:     []byte(`{"id":"2000"}`),
+			Query:         `query Droid($id: ID!) { droid(id: $id) { name } }`,
+		}
+
+		result, err := request.ValidateVariables(starwarsSchema(t))
+		assert.NoError(t, err)
+		assert.True(t, result.Valid)
+	})
+
+	t.Run("should return errors with path for invalid variables", func(t *testing.T) {
+		request := Request{
+			OperationName: "CreateReview",
+			Variables:     []byte(`{"episode":"JEDI","review":{"stars":"5"}}`),
+			Query:         `mutation CreateReview($episode: Episode!, $review: ReviewInput!) { createReview(episode: $episode, review: $review) { stars } }`,
+		}
+
+		result, err := request.ValidateVariables(starwarsSchema(t))
+		assert.NoError(t, err)
+		assert.False(t, result.Valid)
+		require.Equal(t, 1, result.Errors.Count())
+
+		buf := &bytes.Buffer{}
+		_, err = result.Errors.WriteResponse(buf)
+		require.NoError(t, err)
+		assert.Equal(t, `{"errors":[{"message":"Variable \"$review\" got invalid value \"5\" at \"review.stars\"; Int cannot represent non-integer value: \"5\"","locations":[{"line":1,"column":43}],"path":["review","stars"]}]}`, buf.String())
+	})
+}
+
 func TestRequest_ValidateRestrictedFields(t *testing.T) {
 	t.Run("should return error when schema is nil", func(t *testing.T) {
 		request := Request{}
@@ -0,0 +1,93 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/buger/jsonparser"
+)
+
+// VariableConflictPolicy determines how injected variables are handled which are set by the request as well
+type VariableConflictPolicy int
+
+const (
+	// VariableConflictOverride replaces the value of the request with the injected value
+	VariableConflictOverride VariableConflictPolicy = iota
+	// VariableConflictError rejects requests which set an injected variable
+	VariableConflictError
+	// VariableConflictKeepRequest keeps the value of the request, so that the injected value is the default of the variable
+	VariableConflictKeepRequest
+)
+
+// VariableInjector returns the variables injected into a request, e.g. the tenant id or the locale of the authenticated client
+// from the request context. Values are JSON encoded, e.g. json.RawMessage(`"en-US"`).
+// An error aborts the execution of the request and is returned by the engine.
+type VariableInjector interface {
+	InjectVariables(ctx context.Context, request *Request) (map[string]json.RawMessage, error)
+}
+
+type VariableInjectorFunc func(ctx context.Context, request *Request) (map[string]json.RawMessage, error)
+
+func (f VariableInjectorFunc) InjectVariables(ctx context.Context, request *Request) (map[string]json.RawMessage, error) {
+	return f(ctx, request)
+}
+
+// VariableInjectionConfig injects server-side variables into requests before they are transformed, normalized, validated and executed.
+// Injected variables which aren't defined by the operation are ignored like all unused variables.
+type VariableInjectionConfig struct {
+	Injector VariableInjector
+	// OnConflict determines how variables set by the request as well are handled, they are overridden by default
+	OnConflict VariableConflictPolicy
+}
+
+// injectVariables sets the injected variables of the request once, see VariableInjectionConfig
+func (e *ExecutionEngineV2) injectVariables(ctx context.Context, operation *Request) error {
+	config := e.config.variableInjection
+	if config == nil || config.Injector == nil || operation.variablesInjected {
+		return nil
+	}
+
+	injected, err := config.Injector.InjectVariables(ctx, operation)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(injected))
+	for name := range injected {
+		names = append(names, name)
+	}
+	// the variables are injected in a stable order, as their JSON is part of cache keys
+	sort.Strings(names)
+
+	variables := operation.Variables
+	if len(variables) == 0 || string(variables) == "null" {
+		variables = []byte("{}")
+	}
+
+	for _, name := range names {
+		value := injected[name]
+		if !json.Valid(value) {
+			return fmt.Errorf("injected variable %q is not valid JSON", name)
+		}
+
+		if _, _, _, err := jsonparser.Get(variables, name); err == nil {
+			switch config.OnConflict {
+			case VariableConflictError:
+				return RequestErrors{{Message: fmt.Sprintf("variable \"$%s\" is set by the server and must not be set by the request", name)}}
+			case VariableConflictKeepRequest:
+				continue
+			}
+		}
+
+		variables, err = jsonparser.Set(variables, value, name)
+		if err != nil {
+			return err
+		}
+	}
+
+	operation.Variables = variables
+	operation.variablesInjected = true
+	return nil
+}
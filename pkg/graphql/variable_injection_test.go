@@ -0,0 +1,129 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+type tenantContextKey struct{}
+
+func TestExecutionEngineV2_VariableInjection(t *testing.T) {
+	schema, err := NewSchemaFromString(`
+		type Query {
+			hello(tenant: String!, locale: String): String
+		}`)
+	require.NoError(t, err)
+
+	newEngine := func(t *testing.T, onConflict VariableConflictPolicy) (*ExecutionEngineV2, *[]string) {
+		var upstreamQueries []string
+		engineConf := NewEngineV2Configuration(schema)
+		engineConf.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{
+					{TypeName: "Query", FieldNames: []string{"hello"}},
+				},
+				Factory: &graphql_datasource.Factory{
+					HTTPClient: &http.Client{
+						Transport: testRoundTripper(func(req *http.Request) *http.Response {
+							body, _ := ioutil.ReadAll(req.Body)
+							upstreamQueries = append(upstreamQueries, string(body))
+							return &http.Response{StatusCode: 200, Body: ioutil.NopCloser(strings.NewReader(`{"data":{"hello":"world"}}`))}
+						}),
+					},
+				},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch: graphql_datasource.FetchConfiguration{
+						URL:    "https://upstream/graphql",
+						Method: "POST",
+					},
+				}),
+			},
+		})
+		engineConf.AddFieldConfiguration(plan.FieldConfiguration{
+			TypeName:  "Query",
+			FieldName: "hello",
+			Arguments: []plan.ArgumentConfiguration{
+				{Name: "tenant", SourceType: plan.FieldArgumentSource},
+				{Name: "locale", SourceType: plan.FieldArgumentSource},
+			},
+		})
+		engineConf.SetVariableInjection(VariableInjectionConfig{
+			Injector: VariableInjectorFunc(func(ctx context.Context, request *Request) (map[string]json.RawMessage, error) {
+				tenant, ok := ctx.Value(tenantContextKey{}).(string)
+				if !ok {
+					return nil, errors.New("missing tenant")
+				}
+				return map[string]json.RawMessage{
+					"tenant": json.RawMessage(`"` + tenant + `"`),
+					"locale": json.RawMessage(`"en-US"`),
+				}, nil
+			}),
+			OnConflict: onConflict,
+		})
+
+		engine, err := NewExecutionEngineV2(context.Background(), logging.Noop{}, engineConf)
+		require.NoError(t, err)
+		return engine, &upstreamQueries
+	}
+
+	execute := func(engine *ExecutionEngineV2, request *Request) (string, error) {
+		ctx := context.WithValue(context.Background(), tenantContextKey{}, "acme")
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(ctx, request, &resultWriter)
+		return resultWriter.String(), err
+	}
+
+	query := `query Hello($tenant: String!, $locale: String) { hello(tenant: $tenant, locale: $locale) }`
+
+	t.Run("injects variables into the request", func(t *testing.T) {
+		engine, upstreamQueries := newEngine(t, VariableConflictOverride)
+		response, err := execute(engine, &Request{Query: query})
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"hello":"world"}}`, response)
+		assert.Equal(t, []string{`{"query":"query($tenant: String!, $locale: String){hello(tenant: $tenant, locale: $locale)}","variables":{"locale":"en-US","tenant":"acme"}}`}, *upstreamQueries)
+	})
+
+	t.Run("overrides variables of the request", func(t *testing.T) {
+		engine, upstreamQueries := newEngine(t, VariableConflictOverride)
+		_, err := execute(engine, &Request{Query: query, Variables: json.RawMessage(`{"tenant":"other","locale":"de-DE"}`)})
+		require.NoError(t, err)
+		assert.Equal(t, []string{`{"query":"query($tenant: String!, $locale: String){hello(tenant: $tenant, locale: $locale)}","variables":{"locale":"en-US","tenant":"acme"}}`}, *upstreamQueries)
+	})
+
+	t.Run("keeps variables of the request", func(t *testing.T) {
+		engine, upstreamQueries := newEngine(t, VariableConflictKeepRequest)
+		_, err := execute(engine, &Request{Query: query, Variables: json.RawMessage(`{"locale":"de-DE"}`)})
+		require.NoError(t, err)
+		assert.Equal(t, []string{`{"query":"query($tenant: String!, $locale: String){hello(tenant: $tenant, locale: $locale)}","variables":{"locale":"de-DE","tenant":"acme"}}`}, *upstreamQueries)
+	})
+
+	t.Run("rejects requests setting injected variables", func(t *testing.T) {
+		engine, upstreamQueries := newEngine(t, VariableConflictError)
+		_, err := execute(engine, &Request{Query: query, Variables: json.RawMessage(`{"tenant":"other"}`)})
+		assert.EqualError(t, err, `variable "$tenant" is set by the server and must not be set by the request, locations: [], path: []`)
+		assert.Empty(t, *upstreamQueries)
+
+		_, err = execute(engine, &Request{Query: query})
+		assert.NoError(t, err)
+	})
+
+	t.Run("aborts the execution on injector errors", func(t *testing.T) {
+		engine, upstreamQueries := newEngine(t, VariableConflictOverride)
+		resultWriter := NewEngineResultWriter()
+		err := engine.Execute(context.Background(), &Request{Query: query}, &resultWriter)
+		assert.EqualError(t, err, "missing tenant")
+		assert.Empty(t, *upstreamQueries)
+	})
+}
@@ -0,0 +1,248 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	"github.com/wundergraph/graphql-go-tools/pkg/graphqlerrors"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+// ErrorClass groups errors which lead to the same HTTP status code and error extension code
+type ErrorClass int
+
+const (
+	ErrorClassInternal ErrorClass = iota
+	ErrorClassBadRequest
+	ErrorClassValidation
+	ErrorClassAuthentication
+	ErrorClassAuthorization
+	ErrorClassComplexity
+	ErrorClassUpstreamUnavailable
+	ErrorClassTooManyRequests
+	ErrorClassMethodNotAllowed
+)
+
+const (
+	internalErrorMessage            = "internal server error"
+	upstreamUnavailableErrorMessage = "upstream unavailable"
+)
+
+// ErrorMapping defines the HTTP status code and the "code" error extension responded for an ErrorClass
+type ErrorMapping struct {
+	StatusCode int
+	Code       string
+}
+
+// ErrorMappings maps error classes to the response of the handler.
+// Classes without a mapping fall back to the mapping of ErrorClassInternal.
+type ErrorMappings map[ErrorClass]ErrorMapping
+
+// DefaultErrorMappings returns the mappings used by the GraphQLHTTPRequestHandler unless configured otherwise
+func DefaultErrorMappings() ErrorMappings {
+	return ErrorMappings{
+		ErrorClassInternal:            {StatusCode: http.StatusInternalServerError, Code: "INTERNAL_SERVER_ERROR"},
+		ErrorClassBadRequest:          {StatusCode: http.StatusBadRequest, Code: "BAD_REQUEST"},
+		ErrorClassValidation:          {StatusCode: http.StatusBadRequest, Code: "GRAPHQL_VALIDATION_FAILED"},
+		ErrorClassAuthentication:      {StatusCode: http.StatusUnauthorized, Code: "UNAUTHENTICATED"},
+		ErrorClassAuthorization:       {StatusCode: http.StatusForbidden, Code: "FORBIDDEN"},
+		ErrorClassComplexity:          {StatusCode: http.StatusBadRequest, Code: "COMPLEXITY_LIMIT_EXCEEDED"},
+		ErrorClassUpstreamUnavailable: {StatusCode: http.StatusServiceUnavailable, Code: "UPSTREAM_UNAVAILABLE"},
+		ErrorClassTooManyRequests:     {StatusCode: http.StatusTooManyRequests, Code: "TOO_MANY_REQUESTS"},
+		ErrorClassMethodNotAllowed:    {StatusCode: http.StatusMethodNotAllowed, Code: "METHOD_NOT_ALLOWED"},
+	}
+}
+
+func (e ErrorMappings) mapping(class ErrorClass) ErrorMapping {
+	if mapping, ok := e[class]; ok {
+		return mapping
+	}
+	if mapping, ok := e[ErrorClassInternal]; ok {
+		return mapping
+	}
+	return DefaultErrorMappings()[ErrorClassInternal]
+}
+
+// ClassifiedError is implemented by errors which know their ErrorClass
+type ClassifiedError interface {
+	error
+	ErrorClass() ErrorClass
+}
+
+type classifiedError struct {
+	class ErrorClass
+	err   error
+}
+
+// NewClassifiedError attaches the class to the error, e.g. to respond with 401 to a failed authentication
+func NewClassifiedError(class ErrorClass, err error) error {
+	return &classifiedError{class: class, err: err}
+}
+
+func (c *classifiedError) Error() string {
+	return c.err.Error()
+}
+
+func (c *classifiedError) Unwrap() error {
+	return c.err
+}
+
+func (c *classifiedError) ErrorClass() ErrorClass {
+	return c.class
+}
+
+// statusCodeError is implemented by errors which know their HTTP status code,
+// e.g. graphql.AdmissionRejectedError of operations rejected by the admission control of the engine
+type statusCodeError interface {
+	error
+	StatusCode() int
+}
+
+// ErrorClassifier determines the ErrorClass of an error
+type ErrorClassifier func(err error) ErrorClass
+
+// ClassifyError is the default ErrorClassifier.
+// Errors implementing ClassifiedError keep their class, reports with external errors are validation errors,
+// malformed JSON is a bad request, errors with the status code 429 are too many requests
+// and network errors are treated as unavailable upstreams.
+func ClassifyError(err error) ErrorClass {
+	var classified ClassifiedError
+	if errors.As(err, &classified) {
+		return classified.ErrorClass()
+	}
+
+	var withStatusCode statusCodeError
+	if errors.As(err, &withStatusCode) && withStatusCode.StatusCode() == http.StatusTooManyRequests {
+		return ErrorClassTooManyRequests
+	}
+
+	var report operationreport.Report
+	if errors.As(err, &report) {
+		if len(report.ExternalErrors) > 0 {
+			return ErrorClassValidation
+		}
+		return ErrorClassInternal
+	}
+
+	var requestErrors graphql.RequestErrors
+	if errors.As(err, &requestErrors) && len(requestErrors) > 0 {
+		return ErrorClassValidation
+	}
+
+	var syntaxError *json.SyntaxError
+	var unmarshalTypeError *json.UnmarshalTypeError
+	if errors.As(err, &syntaxError) || errors.As(err, &unmarshalTypeError) {
+		return ErrorClassBadRequest
+	}
+
+	var netError net.Error
+	if errors.As(err, &netError) {
+		return ErrorClassUpstreamUnavailable
+	}
+
+	return ErrorClassInternal
+}
+
+type responseErrorExtensions struct {
+	Code        string   `json:"code"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+type responseError struct {
+	Message    string                   `json:"message"`
+	Locations  []graphqlerrors.Location `json:"locations,omitempty"`
+	Extensions responseErrorExtensions  `json:"extensions"`
+}
+
+type errorResponse struct {
+	Errors []responseError `json:"errors"`
+}
+
+// writeError responds with the status code mapped from the class of the error
+// and a GraphQL response containing the error(s) with the mapped code as extension.
+func (g *GraphQLHTTPRequestHandler) writeError(w http.ResponseWriter, err error) {
+	writeErrorResponse(w, err, g.errorClassifier, g.errorMappings)
+}
+
+// writeRequestError responds like writeError to errors of reading and parsing the request, e.g. an unreadable body.
+// These errors are bad requests, as before errors were classified, unless the classifier determines another class
+// than ErrorClassInternal or the error is explicitly internal, i.e. classified as such or a report with internal errors.
+func (g *GraphQLHTTPRequestHandler) writeRequestError(w http.ResponseWriter, err error) {
+	writeErrorResponse(w, err, requestErrorClassifier(g.errorClassifier), g.errorMappings)
+}
+
+func requestErrorClassifier(classifier ErrorClassifier) ErrorClassifier {
+	return func(err error) ErrorClass {
+		class := classifier(err)
+		if class != ErrorClassInternal {
+			return class
+		}
+		var (
+			classified ClassifiedError
+			report     operationreport.Report
+		)
+		if errors.As(err, &classified) || errors.As(err, &report) {
+			return class
+		}
+		return ErrorClassBadRequest
+	}
+}
+
+func writeErrorResponse(w http.ResponseWriter, err error, classifier ErrorClassifier, mappings ErrorMappings) {
+	mapping, response := newErrorResponse(err, classifier, mappings)
+
+	w.Header().Add(httpHeaderContentType, httpContentTypeApplicationJson)
+	w.WriteHeader(mapping.StatusCode)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// newErrorResponse returns the mapping of the class of the error and a GraphQL response containing the error(s)
+// with the mapped code as extension.
+// Messages of internal and upstream errors are not exposed to the client as they might contain infrastructure details.
+func newErrorResponse(err error, classifier ErrorClassifier, mappings ErrorMappings) (ErrorMapping, errorResponse) {
+	class := classifier(err)
+	mapping := mappings.mapping(class)
+
+	response := errorResponse{}
+	extensions := responseErrorExtensions{Code: mapping.Code}
+
+	var (
+		report        operationreport.Report
+		requestErrors graphql.RequestErrors
+	)
+	switch {
+	case class == ErrorClassInternal:
+		response.Errors = append(response.Errors, responseError{Message: internalErrorMessage, Extensions: extensions})
+	case class == ErrorClassUpstreamUnavailable:
+		response.Errors = append(response.Errors, responseError{Message: upstreamUnavailableErrorMessage, Extensions: extensions})
+	case errors.As(err, &report) && len(report.ExternalErrors) > 0:
+		for _, externalError := range report.ExternalErrors {
+			errorExtensions := extensions
+			errorExtensions.Suggestions = externalError.Suggestions
+			response.Errors = append(response.Errors, responseError{
+				Message:    externalError.Message,
+				Locations:  externalError.Locations,
+				Extensions: errorExtensions,
+			})
+		}
+	case errors.As(err, &requestErrors) && len(requestErrors) > 0:
+		for _, requestError := range requestErrors {
+			errorExtensions := extensions
+			if requestError.Extensions != nil {
+				errorExtensions.Suggestions = requestError.Extensions.Suggestions
+			}
+			response.Errors = append(response.Errors, responseError{
+				Message:    requestError.Message,
+				Locations:  requestError.Locations,
+				Extensions: errorExtensions,
+			})
+		}
+	default:
+		response.Errors = append(response.Errors, responseError{Message: err.Error(), Extensions: extensions})
+	}
+
+	return mapping, response
+}
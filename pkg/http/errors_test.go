@@ -0,0 +1,105 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	"github.com/wundergraph/graphql-go-tools/pkg/graphqlerrors"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+type statusCodeTestError struct {
+	statusCode int
+}
+
+func (s statusCodeTestError) Error() string {
+	return http.StatusText(s.statusCode)
+}
+
+func (s statusCodeTestError) StatusCode() int {
+	return s.statusCode
+}
+
+func TestClassifyError(t *testing.T) {
+	var syntaxErr error = &json.SyntaxError{}
+	validationReport := operationreport.Report{ExternalErrors: []operationreport.ExternalError{{Message: "field: foo not defined on type: Query"}}}
+	internalReport := operationreport.Report{InternalErrors: []error{errors.New("planning failed")}}
+
+	assert.Equal(t, ErrorClassValidation, ClassifyError(validationReport))
+	assert.Equal(t, ErrorClassInternal, ClassifyError(internalReport))
+	assert.Equal(t, ErrorClassValidation, ClassifyError(graphql.RequestErrors{{Message: "field: foo not defined on type: Query"}}))
+	assert.Equal(t, ErrorClassBadRequest, ClassifyError(fmt.Errorf("decode: %w", syntaxErr)))
+	assert.Equal(t, ErrorClassUpstreamUnavailable, ClassifyError(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.Equal(t, ErrorClassAuthentication, ClassifyError(fmt.Errorf("hook: %w", NewClassifiedError(ErrorClassAuthentication, errors.New("invalid token")))))
+	assert.Equal(t, ErrorClassTooManyRequests, ClassifyError(fmt.Errorf("execute: %w", statusCodeTestError{statusCode: http.StatusTooManyRequests})))
+	assert.Equal(t, ErrorClassInternal, ClassifyError(statusCodeTestError{statusCode: http.StatusTeapot}))
+	assert.Equal(t, ErrorClassInternal, ClassifyError(errors.New("unknown")))
+}
+
+func TestGraphQLHTTPRequestHandler_WriteError(t *testing.T) {
+	run := func(handler *GraphQLHTTPRequestHandler, err error, expectedStatusCode int, expectedBody string) func(t *testing.T) {
+		return func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			handler.writeError(recorder, err)
+			assert.Equal(t, expectedStatusCode, recorder.Code)
+			assert.Equal(t, httpContentTypeApplicationJson, recorder.Header().Get(httpHeaderContentType))
+			assert.JSONEq(t, expectedBody, recorder.Body.String())
+		}
+	}
+
+	handler := NewGraphqlHTTPHandlerFunc(nil, nil, nil).(*GraphQLHTTPRequestHandler)
+
+	t.Run("validation errors", run(handler, operationreport.Report{ExternalErrors: []operationreport.ExternalError{
+		{Message: "field: foo not defined on type: Query", Locations: []graphqlerrors.Location{{Line: 1, Column: 3}}},
+		{Message: "field: bar not defined on type: Query", Suggestions: []string{"baz"}},
+	}}, http.StatusBadRequest, `{"errors":[
+		{"message":"field: foo not defined on type: Query","locations":[{"line":1,"column":3}],"extensions":{"code":"GRAPHQL_VALIDATION_FAILED"}},
+		{"message":"field: bar not defined on type: Query","extensions":{"code":"GRAPHQL_VALIDATION_FAILED","suggestions":["baz"]}}
+	]}`))
+	t.Run("internal errors are not exposed", run(handler, errors.New("secret"), http.StatusInternalServerError,
+		`{"errors":[{"message":"internal server error","extensions":{"code":"INTERNAL_SERVER_ERROR"}}]}`))
+	t.Run("classified error", run(handler, NewClassifiedError(ErrorClassAuthorization, errors.New("not allowed")), http.StatusForbidden,
+		`{"errors":[{"message":"not allowed","extensions":{"code":"FORBIDDEN"}}]}`))
+
+	t.Run("custom mapping and classifier", run(NewGraphqlHTTPHandlerFunc(nil, nil, nil,
+		WithErrorMapping(ErrorClassComplexity, ErrorMapping{StatusCode: http.StatusTooManyRequests, Code: "TOO_COMPLEX"}),
+		WithErrorClassifier(func(err error) ErrorClass {
+			return ErrorClassComplexity
+		}),
+	).(*GraphQLHTTPRequestHandler), errors.New("query too complex"), http.StatusTooManyRequests,
+		`{"errors":[{"message":"query too complex","extensions":{"code":"TOO_COMPLEX"}}]}`))
+
+	t.Run("missing mapping falls back to internal", run(&GraphQLHTTPRequestHandler{
+		errorMappings:   ErrorMappings{ErrorClassInternal: {StatusCode: http.StatusBadGateway, Code: "FAILED"}},
+		errorClassifier: ClassifyError,
+	}, &net.OpError{Op: "dial", Err: errors.New("connection refused")}, http.StatusBadGateway,
+		`{"errors":[{"message":"upstream unavailable","extensions":{"code":"FAILED"}}]}`))
+}
+
+func TestGraphQLHTTPRequestHandler_WriteRequestError(t *testing.T) {
+	run := func(err error, expectedStatusCode int, expectedBody string) func(t *testing.T) {
+		return func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			NewGraphqlHTTPHandlerFunc(nil, nil, nil).(*GraphQLHTTPRequestHandler).writeRequestError(recorder, err)
+			assert.Equal(t, expectedStatusCode, recorder.Code)
+			assert.JSONEq(t, expectedBody, recorder.Body.String())
+		}
+	}
+
+	t.Run("unclassified errors are bad requests", run(errors.New("unexpected EOF"), http.StatusBadRequest,
+		`{"errors":[{"message":"unexpected EOF","extensions":{"code":"BAD_REQUEST"}}]}`))
+	t.Run("classified errors keep their class", run(NewClassifiedError(ErrorClassAuthentication, errors.New("invalid token")), http.StatusUnauthorized,
+		`{"errors":[{"message":"invalid token","extensions":{"code":"UNAUTHENTICATED"}}]}`))
+	t.Run("internal errors stay internal", run(NewClassifiedError(ErrorClassInternal, errors.New("secret")), http.StatusInternalServerError,
+		`{"errors":[{"message":"internal server error","extensions":{"code":"INTERNAL_SERVER_ERROR"}}]}`))
+	t.Run("reports with internal errors stay internal", run(operationreport.Report{InternalErrors: []error{errors.New("secret")}}, http.StatusInternalServerError,
+		`{"errors":[{"message":"internal server error","extensions":{"code":"INTERNAL_SERVER_ERROR"}}]}`))
+}
@@ -6,20 +6,55 @@ import (
 	"net/http"
 
 	"github.com/gobwas/ws"
-	log "github.com/jensneuse/abstractlogger"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/execution"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 const (
 	httpHeaderUpgrade string = "Upgrade"
 )
 
-func NewGraphqlHTTPHandlerFunc(executionHandler *execution.Handler, logger log.Logger, upgrader *ws.HTTPUpgrader) http.Handler {
+type handlerOptions struct {
+	errorMappings   ErrorMappings
+	errorClassifier ErrorClassifier
+	websocketPolicy websocketPolicy
+	websocketWriter *WebsocketWriterConfig
+}
+
+type HandlerOption func(options *handlerOptions)
+
+// WithErrorMapping overrides the HTTP status code and error extension code responded for the error class
+func WithErrorMapping(class ErrorClass, mapping ErrorMapping) HandlerOption {
+	return func(options *handlerOptions) {
+		options.errorMappings[class] = mapping
+	}
+}
+
+// WithErrorClassifier replaces ClassifyError to determine the class of errors
+func WithErrorClassifier(classifier ErrorClassifier) HandlerOption {
+	return func(options *handlerOptions) {
+		options.errorClassifier = classifier
+	}
+}
+
+func NewGraphqlHTTPHandlerFunc(executionHandler *execution.Handler, logger log.Logger, upgrader *ws.HTTPUpgrader, options ...HandlerOption) http.Handler {
+	opts := handlerOptions{
+		errorMappings:   DefaultErrorMappings(),
+		errorClassifier: ClassifyError,
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+
 	return &GraphQLHTTPRequestHandler{
 		log:              logger,
 		executionHandler: executionHandler,
 		wsUpgrader:       upgrader,
+		errorMappings:    opts.errorMappings,
+		errorClassifier:  opts.errorClassifier,
+		websocketPolicy:  opts.websocketPolicy,
+		websocketWriter:  opts.websocketWriter,
 	}
 }
 
@@ -27,17 +62,29 @@ type GraphQLHTTPRequestHandler struct {
 	log              log.Logger
 	executionHandler *execution.Handler
 	wsUpgrader       *ws.HTTPUpgrader
+	errorMappings    ErrorMappings
+	errorClassifier  ErrorClassifier
+	websocketPolicy  websocketPolicy
+	websocketWriter  *WebsocketWriterConfig
 }
 
 func (g *GraphQLHTTPRequestHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	isUpgrade := g.isWebsocketUpgrade(r)
 	if isUpgrade {
+		if err := g.websocketPolicy.check(r); err != nil {
+			g.log.Debug("GraphQLHTTPRequestHandler.ServeHTTP: websocket upgrade rejected",
+				log.String("origin", r.Header.Get(httpHeaderOrigin)),
+				log.Error(err),
+			)
+			g.writeError(w, err)
+			return
+		}
 		err := g.upgradeWithNewGoroutine(w, r)
 		if err != nil {
 			g.log.Error("GraphQLHTTPRequestHandler.ServeHTTP",
 				log.Error(err),
 			)
-			w.WriteHeader(http.StatusBadRequest)
+			g.writeRequestError(w, err)
 		}
 		return
 	}
@@ -13,10 +13,10 @@ import (
 
 	"github.com/gobwas/ws"
 	"github.com/gobwas/ws/wsutil"
-	"github.com/jensneuse/abstractlogger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
 	"github.com/wundergraph/graphql-go-tools/pkg/starwars"
 	"github.com/wundergraph/graphql-go-tools/pkg/subscription"
 )
@@ -24,7 +24,7 @@ import (
 func TestGraphQLHTTPRequestHandler_ServeHTTP(t *testing.T) {
 	starwars.SetRelativePathToStarWarsPackage("../starwars")
 
-	handler := NewGraphqlHTTPHandlerFunc(starwars.NewExecutionHandler(t), abstractlogger.NoopLogger, &ws.DefaultHTTPUpgrader)
+	handler := NewGraphqlHTTPHandlerFunc(starwars.NewExecutionHandler(t), logging.NoopLogger, &ws.DefaultHTTPUpgrader)
 	server := httptest.NewServer(handler)
 	defer server.Close()
 
@@ -43,6 +43,10 @@ func TestGraphQLHTTPRequestHandler_ServeHTTP(t *testing.T) {
 			require.NoError(t, err)
 
 			assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+			responseBodyBytes, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			assert.Contains(t, string(responseBodyBytes), `"extensions":{"code":"GRAPHQL_VALIDATION_FAILED"}`)
 		})
 
 		t.Run("should successfully handle query and return 200 OK", func(t *testing.T) {
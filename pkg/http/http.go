@@ -6,7 +6,7 @@ import (
 	"io/ioutil"
 	"net/http"
 
-	log "github.com/jensneuse/abstractlogger"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 const (
@@ -21,7 +21,7 @@ func (g *GraphQLHTTPRequestHandler) handleHTTP(w http.ResponseWriter, r *http.Re
 		g.log.Error("GraphQLHTTPRequestHandler.handleHTTP",
 			log.Error(err),
 		)
-		w.WriteHeader(http.StatusBadRequest)
+		g.writeRequestError(w, err)
 		return
 	}
 
@@ -31,7 +31,7 @@ func (g *GraphQLHTTPRequestHandler) handleHTTP(w http.ResponseWriter, r *http.Re
 		g.log.Error("executionHandler.Handle.json.Marshal(extra)",
 			log.Error(err),
 		)
-		w.WriteHeader(http.StatusBadRequest)
+		g.writeRequestError(w, err)
 		return
 	}
 
@@ -40,7 +40,7 @@ func (g *GraphQLHTTPRequestHandler) handleHTTP(w http.ResponseWriter, r *http.Re
 		g.log.Error("executionHandler.Handle",
 			log.Error(err),
 		)
-		w.WriteHeader(http.StatusBadRequest)
+		g.writeRequestError(w, err)
 		return
 	}
 	ctx.Context = r.Context()
@@ -50,7 +50,7 @@ func (g *GraphQLHTTPRequestHandler) handleHTTP(w http.ResponseWriter, r *http.Re
 		g.log.Error("executor.Execute",
 			log.Error(err),
 		)
-		w.WriteHeader(http.StatusInternalServerError)
+		g.writeError(w, err)
 		return
 	}
 
@@ -0,0 +1,131 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+// NewOperationAnalysisHandler returns a handler responding with the depth, complexity, costs of the root fields
+// and data sources of an operation as JSON without executing it, see graphql.ExecutionEngineV2.AnalyzeOperation,
+// e.g. to check the operations of clients in CI. Operations are accepted like by the GraphQLSSEHandler,
+// invalid operations are responded with the errors of the validation.
+func NewOperationAnalysisHandler(engine *graphql.ExecutionEngineV2, logger log.Logger, options ...HandlerOption) http.Handler {
+	opts := handlerOptions{
+		errorMappings:   DefaultErrorMappings(),
+		errorClassifier: ClassifyError,
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	return &OperationAnalysisHandler{
+		log:             logger,
+		engine:          engine,
+		errorMappings:   opts.errorMappings,
+		errorClassifier: opts.errorClassifier,
+	}
+}
+
+type OperationAnalysisHandler struct {
+	log             log.Logger
+	engine          *graphql.ExecutionEngineV2
+	errorMappings   ErrorMappings
+	errorClassifier ErrorClassifier
+}
+
+type operationAnalysisResponse struct {
+	OperationName string                `json:"operationName,omitempty"`
+	OperationType string                `json:"operationType"`
+	Depth         int                   `json:"depth"`
+	NodeCount     int                   `json:"nodeCount"`
+	Complexity    int                   `json:"complexity"`
+	Fields        []fieldComplexity     `json:"fields"`
+	DataSources   []operationDataSource `json:"dataSources"`
+}
+
+type fieldComplexity struct {
+	TypeName   string `json:"typeName"`
+	FieldName  string `json:"fieldName"`
+	Alias      string `json:"alias,omitempty"`
+	Depth      int    `json:"depth"`
+	NodeCount  int    `json:"nodeCount"`
+	Complexity int    `json:"complexity"`
+}
+
+type operationDataSource struct {
+	DataSource string `json:"dataSource"`
+	URL        string `json:"url,omitempty"`
+	Fetches    int    `json:"fetches"`
+}
+
+func (o *OperationAnalysisHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	operation, err := operationFromRequest(r)
+	if err != nil {
+		o.log.Error("OperationAnalysisHandler.ServeHTTP",
+			log.Error(err),
+		)
+		writeErrorResponse(w, NewClassifiedError(ErrorClassBadRequest, err), o.errorClassifier, o.errorMappings)
+		return
+	}
+	operation.SetHeader(r.Header)
+
+	analysis, err := o.engine.AnalyzeOperation(r.Context(), operation)
+	if err != nil {
+		o.log.Error("OperationAnalysisHandler.ServeHTTP",
+			log.Error(err),
+		)
+		writeErrorResponse(w, err, o.errorClassifier, o.errorMappings)
+		return
+	}
+
+	response := operationAnalysisResponse{
+		OperationName: analysis.OperationName,
+		OperationType: operationTypeName(analysis.OperationType),
+		Depth:         analysis.Depth,
+		NodeCount:     analysis.NodeCount,
+		Complexity:    analysis.Complexity,
+		Fields:        make([]fieldComplexity, 0, len(analysis.Fields)),
+		DataSources:   make([]operationDataSource, 0, len(analysis.DataSources)),
+	}
+	for _, field := range analysis.Fields {
+		response.Fields = append(response.Fields, fieldComplexity{
+			TypeName:   field.TypeName,
+			FieldName:  field.FieldName,
+			Alias:      field.Alias,
+			Depth:      field.Depth,
+			NodeCount:  field.NodeCount,
+			Complexity: field.Complexity,
+		})
+	}
+	for _, dataSource := range analysis.DataSources {
+		response.DataSources = append(response.DataSources, operationDataSource{
+			DataSource: dataSource.DataSource,
+			URL:        dataSource.URL,
+			Fetches:    dataSource.Fetches,
+		})
+	}
+
+	w.Header().Set(httpHeaderContentType, httpContentTypeApplicationJson)
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		o.log.Error("OperationAnalysisHandler.ServeHTTP",
+			log.Error(err),
+		)
+	}
+}
+
+func operationTypeName(operationType graphql.OperationType) string {
+	switch operationType {
+	case graphql.OperationTypeQuery:
+		return "query"
+	case graphql.OperationTypeMutation:
+		return "mutation"
+	case graphql.OperationTypeSubscription:
+		return "subscription"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestOperationAnalysisHandler_ServeHTTP(t *testing.T) {
+	schema, err := graphql.NewSchemaFromString(`
+		type Query { user: User }
+		type User { id: ID name: String friends: [User] }`)
+	require.NoError(t, err)
+
+	engineConfig := graphql.NewEngineV2Configuration(schema)
+	engineConfig.AddDataSource(plan.DataSourceConfiguration{
+		RootNodes: []plan.TypeField{
+			{TypeName: "Query", FieldNames: []string{"user"}},
+		},
+		ChildNodes: []plan.TypeField{
+			{TypeName: "User", FieldNames: []string{"id", "name", "friends"}},
+		},
+		Factory: &graphql_datasource.Factory{
+			HTTPClient: http.DefaultClient,
+		},
+		Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+			Fetch: graphql_datasource.FetchConfiguration{
+				URL:    "https://users.service/graphql",
+				Method: http.MethodPost,
+			},
+		}),
+	})
+	engine, err := graphql.NewExecutionEngineV2(context.Background(), logging.NoopLogger, engineConfig)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(NewOperationAnalysisHandler(engine, logging.NoopLogger))
+	defer server.Close()
+
+	t.Run("responds with the analysis of the operation", func(t *testing.T) {
+		resp, err := http.Post(server.URL, httpContentTypeApplicationJson, strings.NewReader(`{"operationName":"User","query":"query User { user { id friends { name } } }"}`))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, httpContentTypeApplicationJson, resp.Header.Get(httpHeaderContentType))
+
+		var response operationAnalysisResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		assert.Equal(t, operationAnalysisResponse{
+			OperationName: "User",
+			OperationType: "query",
+			Depth:         3,
+			NodeCount:     2,
+			Complexity:    2,
+			Fields: []fieldComplexity{
+				{TypeName: "Query", FieldName: "user", Depth: 2, NodeCount: 2, Complexity: 2},
+			},
+			DataSources: []operationDataSource{
+				{DataSource: "graphql_datasource.Source", URL: "https://users.service/graphql", Fetches: 1},
+			},
+		}, response)
+	})
+
+	t.Run("accepts GET requests", func(t *testing.T) {
+		resp, err := http.Get(server.URL + "?query=" + url.QueryEscape(`{ user { name } }`))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("responds with the errors of invalid operations", func(t *testing.T) {
+		resp, err := http.Post(server.URL, httpContentTypeApplicationJson, strings.NewReader(`{"query":"{ user { age } }"}`))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		var response errorResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		require.Len(t, response.Errors, 1)
+		assert.Equal(t, "GRAPHQL_VALIDATION_FAILED", response.Errors[0].Extensions.Code)
+	})
+}
@@ -0,0 +1,107 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+const (
+	httpHeaderAccept       string = "Accept"
+	httpHeaderETag         string = "ETag"
+	httpHeaderIfNoneMatch  string = "If-None-Match"
+	httpHeaderCacheControl string = "Cache-Control"
+
+	httpContentTypeTextPlain string = "text/plain; charset=utf-8"
+
+	schemaFormatIntrospection = "introspection"
+)
+
+// NewGraphQLSchemaHandler returns a handler serving the schema as SDL, see graphql.Schema.PublicSDL, or as introspection result
+// if the request has the format=introspection query parameter or only accepts application/json.
+// The hash of the schema is sent as ETag, requests with a matching If-None-Match header are answered with 304 Not Modified,
+// so that clients can poll the handler to detect schema changes.
+func NewGraphQLSchemaHandler(schema *graphql.Schema, logger log.Logger) http.Handler {
+	return &GraphQLSchemaHandler{
+		log:    logger,
+		schema: schema,
+		etag:   schemaETag(schema),
+	}
+}
+
+type GraphQLSchemaHandler struct {
+	log    log.Logger
+	schema *graphql.Schema
+	etag   string
+	sdl    sdlCache
+}
+
+func (g *GraphQLSchemaHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set(httpHeaderETag, g.etag)
+	if etagMatches(r.Header.Get(httpHeaderIfNoneMatch), g.etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	if !g.servesIntrospection(r) {
+		sdl, err := g.sdl.get(g.schema)
+		if err != nil {
+			g.log.Error("GraphQLSchemaHandler.ServeHTTP",
+				log.Error(err),
+			)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		writeSDL(w, r, sdl)
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	if err := g.schema.IntrospectionResponse(buf); err != nil {
+		g.log.Error("GraphQLSchemaHandler.ServeHTTP",
+			log.Error(err),
+		)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(httpHeaderContentType, httpContentTypeApplicationJson)
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = buf.WriteTo(w)
+	}
+}
+
+func (g *GraphQLSchemaHandler) servesIntrospection(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return format == schemaFormatIntrospection
+	}
+	accept := r.Header.Get(httpHeaderAccept)
+	return strings.Contains(accept, httpContentTypeApplicationJson) && !strings.Contains(accept, "text/")
+}
+
+func schemaETag(schema *graphql.Schema) string {
+	return `"` + schema.HashString() + `"`
+}
+
+// etagMatches reports whether the If-None-Match header matches the etag, weak comparison is used as defined by RFC 7232
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
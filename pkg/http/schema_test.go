@@ -0,0 +1,77 @@
+package http
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestGraphQLSchemaHandler_ServeHTTP(t *testing.T) {
+	schema, err := graphql.NewSchemaFromString(`type Query { hello: String }`)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(NewGraphQLSchemaHandler(schema, logging.NoopLogger))
+	defer server.Close()
+
+	get := func(t *testing.T, url string, header http.Header) (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		for key := range header {
+			req.Header.Set(key, header.Get(key))
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(body)
+	}
+
+	etag := `"` + schema.HashString() + `"`
+
+	t.Run("serves the SDL with the schema hash as ETag", func(t *testing.T) {
+		resp, body := get(t, server.URL, nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, etag, resp.Header.Get("ETag"))
+		assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+		sdl, err := schema.PublicSDL()
+		require.NoError(t, err)
+		assert.Equal(t, sdl, body)
+	})
+
+	t.Run("serves the introspection result", func(t *testing.T) {
+		resp, body := get(t, server.URL+"?format=introspection", nil)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, etag, resp.Header.Get("ETag"))
+		assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		assert.Contains(t, body, `"__schema"`)
+
+		resp, body = get(t, server.URL, http.Header{"Accept": []string{"application/json"}})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, body, `"__schema"`)
+	})
+
+	t.Run("responds not modified if the ETag matches", func(t *testing.T) {
+		resp, body := get(t, server.URL, http.Header{"If-None-Match": []string{`"other", W/` + etag}})
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+		assert.Equal(t, etag, resp.Header.Get("ETag"))
+		assert.Empty(t, body)
+
+		resp, _ = get(t, server.URL, http.Header{"If-None-Match": []string{`"other"`}})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("only allows GET and HEAD", func(t *testing.T) {
+		resp, err := http.Post(server.URL, "application/json", nil)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+// NewGraphQLSDLHandler returns a handler serving the schema the engine executes the operations of the client against as SDL,
+// i.e. the schema view selected for the request by the graphql.SchemaViewSelector, see graphql.Schema.PublicSDL.
+// The view is selected with the headers of the request like for operations.
+// The hash of the served schema is sent as ETag, clients have to revalidate it with If-None-Match, which is answered with 304 Not Modified
+// as long as the schema didn't change.
+func NewGraphQLSDLHandler(engine *graphql.ExecutionEngineV2, logger log.Logger) http.Handler {
+	return &GraphQLSDLHandler{
+		log:    logger,
+		engine: engine,
+	}
+}
+
+type GraphQLSDLHandler struct {
+	log    log.Logger
+	engine *graphql.ExecutionEngineV2
+	sdl    sdlCache
+}
+
+func (g *GraphQLSDLHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	operation := &graphql.Request{}
+	operation.SetHeader(r.Header)
+	schema, err := g.engine.SelectSchema(r.Context(), operation)
+	if err != nil {
+		g.log.Error("GraphQLSDLHandler.ServeHTTP",
+			log.Error(err),
+		)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	etag := schemaETag(schema)
+	w.Header().Set(httpHeaderETag, etag)
+	w.Header().Set(httpHeaderCacheControl, "no-cache")
+	if etagMatches(r.Header.Get(httpHeaderIfNoneMatch), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	sdl, err := g.sdl.get(schema)
+	if err != nil {
+		g.log.Error("GraphQLSDLHandler.ServeHTTP",
+			log.Error(err),
+		)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	writeSDL(w, r, sdl)
+}
+
+func writeSDL(w http.ResponseWriter, r *http.Request, sdl string) {
+	w.Header().Set(httpHeaderContentType, httpContentTypeTextPlain)
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write([]byte(sdl))
+	}
+}
+
+// sdlCache caches the public SDL of the schemas, schemas don't change once they are created
+type sdlCache struct {
+	mu  sync.Mutex
+	sdl map[*graphql.Schema]string
+}
+
+func (c *sdlCache) get(schema *graphql.Schema) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if sdl, ok := c.sdl[schema]; ok {
+		return sdl, nil
+	}
+	sdl, err := schema.PublicSDL()
+	if err != nil {
+		return "", err
+	}
+	if c.sdl == nil {
+		c.sdl = make(map[*graphql.Schema]string)
+	}
+	c.sdl[schema] = sdl
+	return sdl, nil
+}
@@ -0,0 +1,80 @@
+package http
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestGraphQLSDLHandler_ServeHTTP(t *testing.T) {
+	schema, err := graphql.NewSchemaFromString(`
+		type Query {
+			hello: String
+			internal: String @internal
+		}
+		directive @internal on FIELD_DEFINITION`)
+	require.NoError(t, err)
+
+	engineConfig := graphql.NewEngineV2Configuration(schema)
+	engineConfig.AddSchemaView(graphql.SchemaViewConfig{Name: "public", ExcludeDirectives: []string{"internal"}})
+	engineConfig.SetSchemaViewSelector(graphql.SchemaViewSelectorFunc(func(ctx context.Context, operation *graphql.Request) string {
+		if operation.Header().Get("X-Role") == "admin" {
+			return ""
+		}
+		return "public"
+	}))
+	engine, err := graphql.NewExecutionEngineV2(context.Background(), logging.NoopLogger, engineConfig)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(NewGraphQLSDLHandler(engine, logging.NoopLogger))
+	defer server.Close()
+
+	get := func(t *testing.T, header http.Header) (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+		require.NoError(t, err)
+		req.Header = header
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(body)
+	}
+
+	t.Run("serves the SDL of the schema view selected for the request", func(t *testing.T) {
+		resp, publicSDL := get(t, http.Header{})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/plain; charset=utf-8", resp.Header.Get("Content-Type"))
+		assert.Equal(t, "no-cache", resp.Header.Get("Cache-Control"))
+		assert.Contains(t, publicSDL, "hello: String")
+		assert.NotContains(t, publicSDL, "internal")
+		assert.NotContains(t, publicSDL, "__schema")
+		publicETag := resp.Header.Get("ETag")
+
+		resp, adminSDL := get(t, http.Header{"X-Role": []string{"admin"}})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, adminSDL, "internal: String @internal")
+		assert.Equal(t, `"`+schema.HashString()+`"`, resp.Header.Get("ETag"))
+		assert.NotEqual(t, publicETag, resp.Header.Get("ETag"))
+	})
+
+	t.Run("responds not modified while the schema didn't change", func(t *testing.T) {
+		resp, _ := get(t, http.Header{})
+		etag := resp.Header.Get("ETag")
+
+		resp, body := get(t, http.Header{"If-None-Match": []string{etag}})
+		assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+		assert.Empty(t, body)
+
+		resp, _ = get(t, http.Header{"If-None-Match": []string{etag}, "X-Role": []string{"admin"}})
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
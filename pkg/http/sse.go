@@ -0,0 +1,191 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+const (
+	httpContentTypeEventStream string = "text/event-stream"
+
+	sseEventNext     = "next"
+	sseEventComplete = "complete"
+)
+
+var (
+	ErrSSEStreamingUnsupported = errors.New("response writer doesn't support streaming")
+	ErrSSEMethodNotAllowed     = errors.New("method not allowed, use GET or POST")
+	// ErrSSEMutationOverGet is returned for mutations sent as GET requests, which could be triggered cross-site by links or images
+	ErrSSEMutationOverGet = errors.New("mutations are only allowed as POST requests")
+)
+
+// NewGraphQLSSEHandler returns a handler serving operations of the engine as server-sent events,
+// following the distinct connections mode of the GraphQL over SSE protocol.
+// Each result is sent as a "next" event, the stream ends with a "complete" event once the operation is done,
+// e.g. when the upstream completed the subscription. Clients closing the connection cancel the operation and its upstream subscription.
+// The transport of the upstream is configured on the data sources, so that e.g. browsers are served with SSE
+// while the subscriptions of the subgraphs are consumed via graphql-ws.
+// Operations are accepted as GET requests with the query, operationName and variables parameters or as POST requests with a JSON body.
+// Mutations are only accepted as POST requests, GET requests with a mutation are rejected with 405 Method Not Allowed.
+func NewGraphQLSSEHandler(engine *graphql.ExecutionEngineV2, logger log.Logger, options ...HandlerOption) http.Handler {
+	opts := handlerOptions{
+		errorMappings:   DefaultErrorMappings(),
+		errorClassifier: ClassifyError,
+	}
+	for _, option := range options {
+		option(&opts)
+	}
+
+	return &GraphQLSSEHandler{
+		log:             logger,
+		engine:          engine,
+		errorMappings:   opts.errorMappings,
+		errorClassifier: opts.errorClassifier,
+	}
+}
+
+type GraphQLSSEHandler struct {
+	log             log.Logger
+	engine          *graphql.ExecutionEngineV2
+	errorMappings   ErrorMappings
+	errorClassifier ErrorClassifier
+}
+
+func (g *GraphQLSSEHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	operation, err := operationFromRequest(r)
+	if err == nil && r.Method == http.MethodGet {
+		err = rejectMutationOverGet(w, operation)
+	}
+	if err != nil {
+		g.log.Error("GraphQLSSEHandler.ServeHTTP",
+			log.Error(err),
+		)
+		if errors.Is(err, ErrSSEMethodNotAllowed) {
+			w.Header().Set("Allow", http.MethodGet+", "+http.MethodPost)
+		}
+		var classified ClassifiedError
+		if !errors.As(err, &classified) {
+			err = NewClassifiedError(ErrorClassBadRequest, err)
+		}
+		g.writeError(w, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		g.writeError(w, ErrSSEStreamingUnsupported)
+		return
+	}
+
+	writer := &sseWriter{
+		writer:  w,
+		flusher: flusher,
+	}
+	err = g.engine.Execute(r.Context(), operation, writer, graphql.WithAdditionalHttpHeaders(r.Header), graphql.WithClient(r.RemoteAddr))
+	if err != nil {
+		g.log.Error("GraphQLSSEHandler.ServeHTTP",
+			log.Error(err),
+		)
+		if !writer.started {
+			g.writeError(w, err)
+			return
+		}
+		_, response := newErrorResponse(err, g.errorClassifier, g.errorMappings)
+		writer.buf.Reset()
+		_ = json.NewEncoder(&writer.buf).Encode(response)
+	}
+	writer.Flush()
+	writer.writeEvent(sseEventComplete, nil)
+}
+
+// operationFromRequest reads the operation of GET requests from the query, operationName and variables parameters
+// and of POST requests from the JSON body
+func operationFromRequest(r *http.Request) (*graphql.Request, error) {
+	operation := &graphql.Request{}
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		operation.Query = query.Get("query")
+		operation.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if !json.Valid([]byte(variables)) {
+				return nil, errors.New("variables are not valid JSON")
+			}
+			operation.Variables = json.RawMessage(variables)
+		}
+		if operation.Query == "" {
+			return nil, graphql.ErrEmptyRequest
+		}
+		return operation, nil
+	case http.MethodPost:
+		if err := graphql.UnmarshalRequest(r.Body, operation); err != nil {
+			return nil, err
+		}
+		return operation, nil
+	default:
+		return nil, NewClassifiedError(ErrorClassMethodNotAllowed, ErrSSEMethodNotAllowed)
+	}
+}
+
+// rejectMutationOverGet only allows queries and subscriptions as GET requests,
+// so that mutations can't be triggered cross-site by links or images (CSRF)
+func rejectMutationOverGet(w http.ResponseWriter, operation *graphql.Request) error {
+	operationType, err := operation.OperationType()
+	if err != nil {
+		return err
+	}
+	if operationType == graphql.OperationTypeMutation {
+		w.Header().Set("Allow", http.MethodPost)
+		return NewClassifiedError(ErrorClassMethodNotAllowed, ErrSSEMutationOverGet)
+	}
+	return nil
+}
+
+func (g *GraphQLSSEHandler) writeError(w http.ResponseWriter, err error) {
+	writeErrorResponse(w, err, g.errorClassifier, g.errorMappings)
+}
+
+// sseWriter sends each flushed result of the engine as "next" event,
+// the headers of the event stream are written with the first event
+type sseWriter struct {
+	writer  http.ResponseWriter
+	flusher http.Flusher
+	buf     bytes.Buffer
+	started bool
+}
+
+func (s *sseWriter) Write(p []byte) (n int, err error) {
+	return s.buf.Write(p)
+}
+
+func (s *sseWriter) Flush() {
+	if s.buf.Len() == 0 {
+		return
+	}
+	s.writeEvent(sseEventNext, bytes.TrimSpace(s.buf.Bytes()))
+	s.buf.Reset()
+}
+
+func (s *sseWriter) writeEvent(event string, data []byte) {
+	if !s.started {
+		s.started = true
+		header := s.writer.Header()
+		header.Set(httpHeaderContentType, httpContentTypeEventStream)
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		s.writer.WriteHeader(http.StatusOK)
+	}
+	_, _ = s.writer.Write([]byte("event: " + event + "\n"))
+	if data != nil {
+		_, _ = s.writer.Write([]byte("data: "))
+		_, _ = s.writer.Write(data)
+		_, _ = s.writer.Write([]byte("\n"))
+	}
+	_, _ = s.writer.Write([]byte("\n"))
+	s.flusher.Flush()
+}
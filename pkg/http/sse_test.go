@@ -0,0 +1,164 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestGraphQLSSEHandler_ServeHTTP(t *testing.T) {
+	upstreamClosed := make(chan struct{}, 1)
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != "text/event-stream" {
+			_, _ = w.Write([]byte(`{"data":{"hello":"world"}}`))
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		_, _ = fmt.Fprintf(w, "data: %s\n\n", `{"data":{"messageAdded":"first"}}`)
+		flusher.Flush()
+		if strings.Contains(r.URL.Query().Get("query"), "infinite") {
+			<-r.Context().Done()
+			upstreamClosed <- struct{}{}
+			return
+		}
+		_, _ = fmt.Fprintf(w, "data: %s\n\n", `{"data":{"messageAdded":"second"}}`)
+		_, _ = fmt.Fprint(w, "event: complete\n\n")
+		flusher.Flush()
+	}))
+	defer upstream.Close()
+
+	schema, err := graphql.NewSchemaFromString(`
+		schema { query: Query mutation: Mutation subscription: Subscription }
+		type Query { hello: String }
+		type Mutation { setHello(hello: String!): String }
+		type Subscription { messageAdded: String infinite: String }`)
+	require.NoError(t, err)
+
+	engineConfig := graphql.NewEngineV2Configuration(schema)
+	engineConfig.AddDataSource(plan.DataSourceConfiguration{
+		RootNodes: []plan.TypeField{
+			{TypeName: "Query", FieldNames: []string{"hello"}},
+			{TypeName: "Subscription", FieldNames: []string{"messageAdded", "infinite"}},
+		},
+		Factory: &graphql_datasource.Factory{
+			HTTPClient:      http.DefaultClient,
+			StreamingClient: http.DefaultClient,
+		},
+		Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+			Fetch: graphql_datasource.FetchConfiguration{
+				URL:    upstream.URL,
+				Method: http.MethodPost,
+			},
+			Subscription: graphql_datasource.SubscriptionConfiguration{
+				URL:    upstream.URL,
+				UseSSE: true,
+			},
+		}),
+	})
+
+	engineCtx, cancelEngine := context.WithCancel(context.Background())
+	defer cancelEngine()
+	engine, err := graphql.NewExecutionEngineV2(engineCtx, logging.NoopLogger, engineConfig)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(NewGraphQLSSEHandler(engine, logging.NoopLogger))
+	defer server.Close()
+
+	get := func(t *testing.T, ctx context.Context, query string) *http.Response {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"?query="+url.QueryEscape(query), nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("should stream subscription events until the upstream completes", func(t *testing.T) {
+		resp := get(t, context.Background(), `subscription { messageAdded }`)
+		defer resp.Body.Close()
+
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "event: next\ndata: {\"data\":{\"messageAdded\":\"first\"}}\n\n"+
+			"event: next\ndata: {\"data\":{\"messageAdded\":\"second\"}}\n\n"+
+			"event: complete\n\n", string(body))
+	})
+
+	t.Run("should send a query result as single event", func(t *testing.T) {
+		resp, err := http.Post(server.URL, "application/json", strings.NewReader(`{"query":"{ hello }"}`))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, "event: next\ndata: {\"data\":{\"hello\":\"world\"}}\n\nevent: complete\n\n", string(body))
+	})
+
+	t.Run("should return 400 Bad Request for invalid requests", func(t *testing.T) {
+		resp := get(t, context.Background(), "")
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		assert.Equal(t, httpContentTypeApplicationJson, resp.Header.Get("Content-Type"))
+
+		resp = get(t, context.Background(), `subscription { notExisting }`)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("should return 405 Method Not Allowed for mutations over GET", func(t *testing.T) {
+		resp := get(t, context.Background(), `mutation { setHello(hello: "world") }`)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+		assert.Equal(t, http.MethodPost, resp.Header.Get("Allow"))
+		body, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+		assert.Equal(t, `{"errors":[{"message":"mutations are only allowed as POST requests","extensions":{"code":"METHOD_NOT_ALLOWED"}}]}`+"\n", string(body))
+
+		req, err := http.NewRequest(http.MethodDelete, server.URL, nil)
+		require.NoError(t, err)
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+		assert.Equal(t, "GET, POST", resp.Header.Get("Allow"))
+	})
+
+	t.Run("should cancel the upstream subscription when the client disconnects", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		resp := get(t, ctx, `subscription { infinite }`)
+		defer resp.Body.Close()
+
+		reader := bufio.NewReader(resp.Body)
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		assert.Equal(t, "event: next\n", line)
+
+		cancel()
+		select {
+		case <-upstreamClosed:
+		case <-time.After(time.Second):
+			t.Fatal("upstream subscription wasn't cancelled")
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+// NewHealthHandler returns a handler answering with 200 OK as long as the server handles requests,
+// e.g. for the liveness probe of Kubernetes. It doesn't depend on the upstreams, see NewReadinessHandler.
+func NewHealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowStatusMethod(w, r) {
+			return
+		}
+		writeStatusText(w, r, http.StatusOK, "ok")
+	})
+}
+
+// NewReadinessHandler returns a handler answering with 200 OK if the engine is ready and 503 Service Unavailable
+// with the reason otherwise, see graphql.ExecutionEngineV2.Readiness, e.g. for the readiness probe of Kubernetes.
+func NewReadinessHandler(engine *graphql.ExecutionEngineV2, logger log.Logger) http.Handler {
+	return &ReadinessHandler{
+		log:    logger,
+		engine: engine,
+	}
+}
+
+type ReadinessHandler struct {
+	log    log.Logger
+	engine *graphql.ExecutionEngineV2
+}
+
+func (h *ReadinessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !allowStatusMethod(w, r) {
+		return
+	}
+	if err := h.engine.Readiness(); err != nil {
+		h.log.Debug("ReadinessHandler.ServeHTTP: not ready",
+			log.Error(err),
+		)
+		writeStatusText(w, r, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	writeStatusText(w, r, http.StatusOK, "ready")
+}
+
+// NewEngineStatusHandler returns a handler serving the status of the engine as JSON, see graphql.ExecutionEngineV2.Status:
+// the hash of the active schema, the time it was loaded, the readiness, the plan cache stats, the health of the data sources
+// and the subscription counters. The status is responded with 200 OK regardless of the readiness.
+func NewEngineStatusHandler(engine *graphql.ExecutionEngineV2, logger log.Logger) http.Handler {
+	return &EngineStatusHandler{
+		log:    logger,
+		engine: engine,
+	}
+}
+
+type EngineStatusHandler struct {
+	log    log.Logger
+	engine *graphql.ExecutionEngineV2
+}
+
+type engineStatusResponse struct {
+	SchemaHash     string                  `json:"schemaHash"`
+	LoadedAt       time.Time               `json:"loadedAt"`
+	Ready          bool                    `json:"ready"`
+	ReadinessError string                  `json:"readinessError,omitempty"`
+	PlanCache      planCacheStatus         `json:"planCache"`
+	DataSources    []dataSourceHealth      `json:"dataSources"`
+	Subscriptions  subscriptionStatsStatus `json:"subscriptions"`
+}
+
+type planCacheStatus struct {
+	Size     int    `json:"size"`
+	Hits     uint64 `json:"hits"`
+	Misses   uint64 `json:"misses"`
+	Rejected uint64 `json:"rejected"`
+}
+
+type dataSourceHealth struct {
+	Name      string     `json:"name"`
+	Optional  bool       `json:"optional"`
+	Checked   bool       `json:"checked"`
+	Healthy   bool       `json:"healthy"`
+	Error     string     `json:"error,omitempty"`
+	CheckedAt *time.Time `json:"checkedAt,omitempty"`
+}
+
+type subscriptionStatsStatus struct {
+	Active          int    `json:"active"`
+	Started         uint64 `json:"started"`
+	Completed       uint64 `json:"completed"`
+	Failed          uint64 `json:"failed"`
+	EventsDelivered uint64 `json:"eventsDelivered"`
+	BytesDelivered  uint64 `json:"bytesDelivered"`
+}
+
+func (h *EngineStatusHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !allowStatusMethod(w, r) {
+		return
+	}
+
+	status := h.engine.Status()
+	response := engineStatusResponse{
+		SchemaHash: status.SchemaHash,
+		LoadedAt:   status.LoadedAt,
+		Ready:      status.ReadinessErr == nil,
+		PlanCache: planCacheStatus{
+			Size:     status.PlanCache.Size,
+			Hits:     status.PlanCache.Hits,
+			Misses:   status.PlanCache.Misses,
+			Rejected: status.PlanCache.Rejected,
+		},
+		DataSources: make([]dataSourceHealth, 0, len(status.DataSources)),
+		Subscriptions: subscriptionStatsStatus{
+			Active:          status.Subscriptions.Active,
+			Started:         status.Subscriptions.Started,
+			Completed:       status.Subscriptions.Completed,
+			Failed:          status.Subscriptions.Failed,
+			EventsDelivered: status.Subscriptions.EventsDelivered,
+			BytesDelivered:  status.Subscriptions.BytesDelivered,
+		},
+	}
+	if status.ReadinessErr != nil {
+		response.ReadinessError = status.ReadinessErr.Error()
+	}
+	for _, dataSource := range status.DataSources {
+		health := dataSourceHealth{
+			Name:     dataSource.Name,
+			Optional: dataSource.Optional,
+			Checked:  dataSource.Checked,
+			Healthy:  dataSource.Healthy,
+		}
+		if dataSource.Err != nil {
+			health.Error = dataSource.Err.Error()
+		}
+		if dataSource.Checked {
+			checkedAt := dataSource.CheckedAt
+			health.CheckedAt = &checkedAt
+		}
+		response.DataSources = append(response.DataSources, health)
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		h.log.Error("EngineStatusHandler.ServeHTTP",
+			log.Error(err),
+		)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(httpHeaderContentType, httpContentTypeApplicationJson)
+	w.Header().Set(httpHeaderCacheControl, "no-store")
+	w.WriteHeader(http.StatusOK)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write(body)
+	}
+}
+
+// allowStatusMethod answers requests with other methods than GET and HEAD with 405 Method Not Allowed
+func allowStatusMethod(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", "GET, HEAD")
+	w.WriteHeader(http.StatusMethodNotAllowed)
+	return false
+}
+
+func writeStatusText(w http.ResponseWriter, r *http.Request, statusCode int, text string) {
+	w.Header().Set(httpHeaderContentType, httpContentTypeTextPlain)
+	w.Header().Set(httpHeaderCacheControl, "no-store")
+	w.WriteHeader(statusCode)
+	if r.Method == http.MethodGet {
+		_, _ = w.Write([]byte(text))
+	}
+}
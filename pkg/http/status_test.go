@@ -0,0 +1,118 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+type statusRoundTripper func(req *http.Request) *http.Response
+
+func (s statusRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return s(req), nil
+}
+
+func TestStatusHandlers(t *testing.T) {
+	schema, err := graphql.NewSchemaFromString(`type Query { hello: String }`)
+	require.NoError(t, err)
+
+	newEngine := func(t *testing.T, healthStatusCode int) *graphql.ExecutionEngineV2 {
+		engineConfig := graphql.NewEngineV2Configuration(schema)
+		engineConfig.SetDataSources([]plan.DataSourceConfiguration{
+			{
+				RootNodes: []plan.TypeField{{TypeName: "Query", FieldNames: []string{"hello"}}},
+				Factory:   &graphql_datasource.Factory{},
+				Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+					Fetch: graphql_datasource.FetchConfiguration{URL: "https://hello.service/graphql", Method: "POST"},
+				}),
+				HealthCheck: &plan.HealthCheckConfiguration{Name: "hello", URL: "https://hello.service/graphql", Interval: time.Hour},
+			},
+		})
+		engineConfig.SetHealthCheckClient(&http.Client{
+			Transport: statusRoundTripper(func(req *http.Request) *http.Response {
+				return &http.Response{StatusCode: healthStatusCode, Body: ioutil.NopCloser(strings.NewReader(""))}
+			}),
+		})
+		checked := make(chan struct{}, 1)
+		engineConfig.SetHealthCheckHook(graphql.HealthCheckHookFunc(func(status graphql.DataSourceHealthStatus) {
+			checked <- struct{}{}
+		}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		engine, err := graphql.NewExecutionEngineV2(ctx, logging.NoopLogger, engineConfig)
+		require.NoError(t, err)
+		select {
+		case <-checked:
+		case <-time.After(time.Second):
+			t.Fatal("health check did not complete")
+		}
+		return engine
+	}
+
+	serve := func(t *testing.T, handler http.Handler, method string) (*httptest.ResponseRecorder, string) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(method, "/", nil))
+		return recorder, recorder.Body.String()
+	}
+
+	t.Run("health", func(t *testing.T) {
+		recorder, body := serve(t, NewHealthHandler(), http.MethodGet)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "ok", body)
+
+		recorder, body = serve(t, NewHealthHandler(), http.MethodHead)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Empty(t, body)
+
+		recorder, _ = serve(t, NewHealthHandler(), http.MethodPost)
+		assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+		assert.Equal(t, "GET, HEAD", recorder.Header().Get("Allow"))
+	})
+
+	t.Run("readiness", func(t *testing.T) {
+		recorder, body := serve(t, NewReadinessHandler(newEngine(t, http.StatusOK), logging.NoopLogger), http.MethodGet)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, "ready", body)
+
+		recorder, body = serve(t, NewReadinessHandler(newEngine(t, http.StatusServiceUnavailable), logging.NoopLogger), http.MethodGet)
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.Equal(t, "engine is not ready: data source hello is unhealthy: unexpected status code: 503", body)
+	})
+
+	t.Run("engine status", func(t *testing.T) {
+		engine := newEngine(t, http.StatusServiceUnavailable)
+		recorder, body := serve(t, NewEngineStatusHandler(engine, logging.NoopLogger), http.MethodGet)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, httpContentTypeApplicationJson, recorder.Header().Get(httpHeaderContentType))
+		assert.Equal(t, "no-store", recorder.Header().Get(httpHeaderCacheControl))
+
+		var status engineStatusResponse
+		require.NoError(t, json.Unmarshal([]byte(body), &status))
+		assert.Equal(t, schema.HashString(), status.SchemaHash)
+		assert.Equal(t, engine.Status().LoadedAt.UnixNano(), status.LoadedAt.UnixNano())
+		assert.False(t, status.Ready)
+		assert.Equal(t, "engine is not ready: data source hello is unhealthy: unexpected status code: 503", status.ReadinessError)
+		assert.Equal(t, planCacheStatus{}, status.PlanCache)
+		require.Len(t, status.DataSources, 1)
+		assert.Equal(t, "hello", status.DataSources[0].Name)
+		assert.True(t, status.DataSources[0].Checked)
+		assert.False(t, status.DataSources[0].Healthy)
+		assert.Equal(t, "unexpected status code: 503", status.DataSources[0].Error)
+		assert.NotNil(t, status.DataSources[0].CheckedAt)
+		assert.Equal(t, subscriptionStatsStatus{}, status.Subscriptions)
+	})
+}
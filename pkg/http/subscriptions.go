@@ -0,0 +1,92 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+// NewSubscriptionsHandler returns a handler listing the active subscriptions of the engine and their aggregated counters as JSON,
+// e.g. to operate fleets of long-lived connections. It exposes the operation names and clients of the subscriptions,
+// so it should only be served on an admin endpoint.
+func NewSubscriptionsHandler(engine *graphql.ExecutionEngineV2, logger log.Logger) http.Handler {
+	return &SubscriptionsHandler{
+		log:    logger,
+		engine: engine,
+	}
+}
+
+type SubscriptionsHandler struct {
+	log    log.Logger
+	engine *graphql.ExecutionEngineV2
+}
+
+type subscriptionsResponse struct {
+	Stats         subscriptionStats    `json:"stats"`
+	Subscriptions []activeSubscription `json:"subscriptions"`
+}
+
+type subscriptionStats struct {
+	Active          int    `json:"active"`
+	Started         uint64 `json:"started"`
+	Completed       uint64 `json:"completed"`
+	Failed          uint64 `json:"failed"`
+	EventsDelivered uint64 `json:"eventsDelivered"`
+	BytesDelivered  uint64 `json:"bytesDelivered"`
+}
+
+type activeSubscription struct {
+	ID              uint64    `json:"id"`
+	OperationName   string    `json:"operationName,omitempty"`
+	Client          string    `json:"client,omitempty"`
+	StartedAt       time.Time `json:"startedAt"`
+	AgeSeconds      float64   `json:"ageSeconds"`
+	EventsDelivered uint64    `json:"eventsDelivered"`
+	BytesDelivered  uint64    `json:"bytesDelivered"`
+	BufferedBytes   int64     `json:"bufferedBytes"`
+}
+
+func (s *SubscriptionsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	stats := s.engine.SubscriptionStats()
+	response := subscriptionsResponse{
+		Stats: subscriptionStats{
+			Active:          stats.Active,
+			Started:         stats.Started,
+			Completed:       stats.Completed,
+			Failed:          stats.Failed,
+			EventsDelivered: stats.EventsDelivered,
+			BytesDelivered:  stats.BytesDelivered,
+		},
+		Subscriptions: []activeSubscription{},
+	}
+	for _, subscription := range s.engine.ActiveSubscriptions() {
+		response.Subscriptions = append(response.Subscriptions, activeSubscription{
+			ID:              subscription.ID,
+			OperationName:   subscription.OperationName,
+			Client:          subscription.Client,
+			StartedAt:       subscription.StartedAt,
+			AgeSeconds:      subscription.Age.Seconds(),
+			EventsDelivered: subscription.EventsDelivered,
+			BytesDelivered:  subscription.BytesDelivered,
+			BufferedBytes:   subscription.BufferedBytes,
+		})
+	}
+
+	w.Header().Set(httpHeaderContentType, httpContentTypeApplicationJson)
+	w.Header().Set(httpHeaderCacheControl, "no-store")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		s.log.Error("SubscriptionsHandler.ServeHTTP",
+			log.Error(err),
+		)
+	}
+}
@@ -0,0 +1,137 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+)
+
+func TestSubscriptionsHandler_ServeHTTP(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprintf(w, "data: %s\n\n", `{"data":{"messageAdded":"first"}}`)
+		flusher.Flush()
+		<-r.Context().Done()
+	}))
+	defer upstream.Close()
+
+	schema, err := graphql.NewSchemaFromString(`
+		schema { query: Query subscription: Subscription }
+		type Query { hello: String }
+		type Subscription { messageAdded: String }`)
+	require.NoError(t, err)
+
+	engineConfig := graphql.NewEngineV2Configuration(schema)
+	engineConfig.AddDataSource(plan.DataSourceConfiguration{
+		RootNodes: []plan.TypeField{
+			{TypeName: "Subscription", FieldNames: []string{"messageAdded"}},
+		},
+		Factory: &graphql_datasource.Factory{
+			HTTPClient:      http.DefaultClient,
+			StreamingClient: http.DefaultClient,
+		},
+		Custom: graphql_datasource.ConfigJson(graphql_datasource.Configuration{
+			Fetch: graphql_datasource.FetchConfiguration{
+				URL:    upstream.URL,
+				Method: http.MethodPost,
+			},
+			Subscription: graphql_datasource.SubscriptionConfiguration{
+				URL:    upstream.URL,
+				UseSSE: true,
+			},
+		}),
+	})
+
+	engineCtx, cancelEngine := context.WithCancel(context.Background())
+	defer cancelEngine()
+	engine, err := graphql.NewExecutionEngineV2(engineCtx, logging.NoopLogger, engineConfig)
+	require.NoError(t, err)
+
+	server := httptest.NewServer(NewGraphQLSSEHandler(engine, logging.NoopLogger))
+	defer server.Close()
+	admin := httptest.NewServer(NewSubscriptionsHandler(engine, logging.NoopLogger))
+	defer admin.Close()
+
+	subscriptions := func(t *testing.T) subscriptionsResponse {
+		t.Helper()
+		resp, err := http.Get(admin.URL)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, httpContentTypeApplicationJson, resp.Header.Get(httpHeaderContentType))
+
+		var response subscriptionsResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return response
+	}
+
+	assert.Equal(t, subscriptionsResponse{Subscriptions: []activeSubscription{}}, subscriptions(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	query := url.QueryEscape(`subscription OnMessage { messageAdded }`)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"?operationName=OnMessage&query="+query, nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "event: next\n", line)
+
+	t.Run("lists the active subscriptions", func(t *testing.T) {
+		// the event is counted once the flush to the client returned
+		assert.Eventually(t, func() bool {
+			return engine.SubscriptionStats().EventsDelivered == 1
+		}, time.Second, 10*time.Millisecond)
+
+		response := subscriptions(t)
+		require.Len(t, response.Subscriptions, 1)
+		subscription := response.Subscriptions[0]
+		assert.Equal(t, "OnMessage", subscription.OperationName)
+		assert.True(t, strings.HasPrefix(subscription.Client, "127.0.0.1:"))
+		assert.Equal(t, uint64(1), subscription.EventsDelivered)
+		assert.Equal(t, uint64(len(`{"data":{"messageAdded":"first"}}`)), subscription.BytesDelivered)
+		assert.Equal(t, int64(0), subscription.BufferedBytes)
+		assert.Greater(t, subscription.AgeSeconds, float64(0))
+		assert.Equal(t, subscriptionStats{Active: 1, Started: 1, EventsDelivered: 1, BytesDelivered: subscription.BytesDelivered}, response.Stats)
+	})
+
+	t.Run("counts subscriptions closed by the client", func(t *testing.T) {
+		cancel()
+		assert.Eventually(t, func() bool {
+			return engine.SubscriptionStats().Active == 0
+		}, time.Second, 10*time.Millisecond)
+
+		response := subscriptions(t)
+		assert.Empty(t, response.Subscriptions)
+		assert.Equal(t, uint64(1), response.Stats.Started)
+		assert.Equal(t, uint64(1), response.Stats.Completed)
+	})
+
+	t.Run("only allows GET", func(t *testing.T) {
+		resp, err := http.Post(admin.URL, httpContentTypeApplicationJson, nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+}
@@ -7,26 +7,42 @@ import (
 
 	"github.com/gobwas/ws"
 	"github.com/gobwas/ws/wsutil"
-	"github.com/jensneuse/abstractlogger"
 
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
 	"github.com/wundergraph/graphql-go-tools/pkg/subscription"
 )
 
 // WebsocketSubscriptionClient is an actual implementation of the subscritpion client interface.
 type WebsocketSubscriptionClient struct {
-	logger abstractlogger.Logger
+	logger logging.Logger
 	// clientConn holds the actual connection to the client.
 	clientConn net.Conn
 	// isClosedConnection indicates if the websocket connection is closed.
 	isClosedConnection bool
+	// writer writes the messages in batches, messages are written directly if it's nil.
+	writer *websocketWriter
+}
+
+type WebsocketSubscriptionClientOption func(client *WebsocketSubscriptionClient)
+
+// WithBatchedWrites queues the messages written to the client and writes them in batches by a goroutine of the client,
+// so that writing a message doesn't block on a slow client. See WebsocketWriterConfig.
+func WithBatchedWrites(config WebsocketWriterConfig) WebsocketSubscriptionClientOption {
+	return func(client *WebsocketSubscriptionClient) {
+		client.writer = newWebsocketWriter(client.clientConn, config)
+	}
 }
 
 // NewWebsocketSubscriptionClient will create a new websocket subscription client.
-func NewWebsocketSubscriptionClient(logger abstractlogger.Logger, clientConn net.Conn) *WebsocketSubscriptionClient {
-	return &WebsocketSubscriptionClient{
+func NewWebsocketSubscriptionClient(logger logging.Logger, clientConn net.Conn, options ...WebsocketSubscriptionClientOption) *WebsocketSubscriptionClient {
+	client := &WebsocketSubscriptionClient{
 		logger:     logger,
 		clientConn: clientConn,
 	}
+	for _, option := range options {
+		option(client)
+	}
+	return client
 }
 
 // ReadFromClient will read a subscription message from the websocket client.
@@ -41,9 +57,9 @@ func (w *WebsocketSubscriptionClient) ReadFromClient() (message *subscription.Me
 		}
 
 		w.logger.Error("http.WebsocketSubscriptionClient.ReadFromClient()",
-			abstractlogger.Error(err),
-			abstractlogger.ByteString("data", data),
-			abstractlogger.Any("opCode", opCode),
+			logging.Error(err),
+			logging.ByteString("data", data),
+			logging.Any("opCode", opCode),
 		)
 
 		w.isClosedConnectionError(err)
@@ -54,9 +70,9 @@ func (w *WebsocketSubscriptionClient) ReadFromClient() (message *subscription.Me
 	err = json.Unmarshal(data, &message)
 	if err != nil {
 		w.logger.Error("http.WebsocketSubscriptionClient.ReadFromClient()",
-			abstractlogger.Error(err),
-			abstractlogger.ByteString("data", data),
-			abstractlogger.Any("opCode", opCode),
+			logging.Error(err),
+			logging.ByteString("data", data),
+			logging.Any("opCode", opCode),
 		)
 
 		return nil, err
@@ -74,18 +90,26 @@ func (w *WebsocketSubscriptionClient) WriteToClient(message subscription.Message
 	messageBytes, err := json.Marshal(message)
 	if err != nil {
 		w.logger.Error("http.WebsocketSubscriptionClient.WriteToClient()",
-			abstractlogger.Error(err),
-			abstractlogger.Any("message", message),
+			logging.Error(err),
+			logging.Any("message", message),
 		)
 
 		return err
 	}
 
-	err = wsutil.WriteServerMessage(w.clientConn, ws.OpText, messageBytes)
+	if w.writer != nil {
+		err = w.writer.write(messageBytes)
+		if err != nil {
+			// the writer closed the connection of the slow or failed client
+			w.isClosedConnection = true
+		}
+	} else {
+		err = wsutil.WriteServerMessage(w.clientConn, ws.OpText, messageBytes)
+	}
 	if err != nil {
 		w.logger.Error("http.WebsocketSubscriptionClient.WriteToClient()",
-			abstractlogger.Error(err),
-			abstractlogger.ByteString("messageBytes", messageBytes),
+			logging.Error(err),
+			logging.ByteString("messageBytes", messageBytes),
 		)
 
 		return err
@@ -102,9 +126,18 @@ func (w *WebsocketSubscriptionClient) IsConnected() bool {
 // Disconnect will close the websocket connection.
 func (w *WebsocketSubscriptionClient) Disconnect() error {
 	w.logger.Debug("http.GraphQLHTTPRequestHandler.Disconnect()",
-		abstractlogger.String("message", "disconnecting client"),
+		logging.String("message", "disconnecting client"),
 	)
 	w.isClosedConnection = true
+	if w.writer != nil {
+		// the queued messages are written before the connection is closed, e.g. the error terminating the connection
+		if err := w.writer.close(); err != nil {
+			w.logger.Debug("http.WebsocketSubscriptionClient.Disconnect()",
+				logging.String("message", "could not write pending messages"),
+				logging.Error(err),
+			)
+		}
+	}
 	return w.clientConn.Close()
 }
 
@@ -122,24 +155,38 @@ func HandleWebsocketWithInitFunc(
 	errChan chan error,
 	conn net.Conn,
 	executorPool subscription.ExecutorPool,
-	logger abstractlogger.Logger,
+	logger logging.Logger,
 	initFunc subscription.WebsocketInitFunc,
+) {
+	HandleWebsocketWithClientOptions(done, errChan, conn, executorPool, logger, initFunc)
+}
+
+// HandleWebsocketWithClientOptions works like HandleWebsocketWithInitFunc but creates the client with the options,
+// e.g. WithBatchedWrites.
+func HandleWebsocketWithClientOptions(
+	done chan bool,
+	errChan chan error,
+	conn net.Conn,
+	executorPool subscription.ExecutorPool,
+	logger logging.Logger,
+	initFunc subscription.WebsocketInitFunc,
+	clientOptions ...WebsocketSubscriptionClientOption,
 ) {
 	defer func() {
 		if err := conn.Close(); err != nil {
 			logger.Error("http.HandleWebsocket()",
-				abstractlogger.String("message", "could not close connection to client"),
-				abstractlogger.Error(err),
+				logging.String("message", "could not close connection to client"),
+				logging.Error(err),
 			)
 		}
 	}()
 
-	websocketClient := NewWebsocketSubscriptionClient(logger, conn)
+	websocketClient := NewWebsocketSubscriptionClient(logger, conn, clientOptions...)
 	subscriptionHandler, err := subscription.NewHandlerWithInitFunc(logger, websocketClient, executorPool, initFunc)
 	if err != nil {
 		logger.Error("http.HandleWebsocket()",
-			abstractlogger.String("message", "could not create subscriptionHandler"),
-			abstractlogger.Error(err),
+			logging.String("message", "could not create subscriptionHandler"),
+			logging.Error(err),
 		)
 
 		errChan <- err
@@ -150,7 +197,7 @@ func HandleWebsocketWithInitFunc(
 	subscriptionHandler.Handle(context.Background()) // Blocking
 }
 
-func HandleWebsocket(done chan bool, errChan chan error, conn net.Conn, executorPool subscription.ExecutorPool, logger abstractlogger.Logger) {
+func HandleWebsocket(done chan bool, errChan chan error, conn net.Conn, executorPool subscription.ExecutorPool, logger logging.Logger) {
 	HandleWebsocketWithInitFunc(done, errChan, conn, executorPool, logger, nil)
 }
 
@@ -160,11 +207,15 @@ func (g *GraphQLHTTPRequestHandler) handleWebsocket(conn net.Conn) {
 	errChan := make(chan error)
 
 	executorPool := subscription.NewExecutorV1Pool(g.executionHandler)
-	go HandleWebsocket(done, errChan, conn, executorPool, g.log)
+	var clientOptions []WebsocketSubscriptionClientOption
+	if g.websocketWriter != nil {
+		clientOptions = append(clientOptions, WithBatchedWrites(*g.websocketWriter))
+	}
+	go HandleWebsocketWithClientOptions(done, errChan, conn, executorPool, g.log, nil, clientOptions...)
 	select {
 	case err := <-errChan:
 		g.log.Error("http.GraphQLHTTPRequestHandler.handleWebsocket()",
-			abstractlogger.Error(err),
+			logging.Error(err),
 		)
 	case <-done:
 	}
@@ -0,0 +1,121 @@
+package http
+
+import (
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	httpHeaderOrigin string = "Origin"
+)
+
+var ErrWebsocketOriginNotAllowed = errors.New("origin is not allowed")
+
+// OriginCheck returns true if the WebSocket connection of the request is accepted for its Origin header
+type OriginCheck func(r *http.Request) bool
+
+// ClientCertificateCheck inspects the verified TLS client certificates of a WebSocket connection, the leaf certificate first.
+// It's called without certificates if the client didn't present a certificate or the connection isn't encrypted,
+// returning an error rejects the connection.
+type ClientCertificateCheck func(certificates []*x509.Certificate) error
+
+// WebsocketUpgradeHook is called with the request before the WebSocket upgrade, returning an error rejects the connection,
+// e.g. to authenticate the credentials of the request before the subscription protocol is started.
+type WebsocketUpgradeHook func(r *http.Request) error
+
+// websocketPolicy are the checks of a WebSocket upgrade request, rejected requests aren't upgraded
+type websocketPolicy struct {
+	checkOrigin            OriginCheck
+	checkClientCertificate ClientCertificateCheck
+	upgradeHooks           []WebsocketUpgradeHook
+}
+
+// WithWebsocketOriginCheck rejects WebSocket connections for which the check returns false with 403 Forbidden,
+// e.g. to protect browser clients against cross-site WebSocket hijacking. All origins are accepted unless configured.
+func WithWebsocketOriginCheck(check OriginCheck) HandlerOption {
+	return func(options *handlerOptions) {
+		options.websocketPolicy.checkOrigin = check
+	}
+}
+
+// WithWebsocketAllowedOrigins only accepts WebSocket connections from the origins, e.g. https://example.com, see AllowedOrigins
+func WithWebsocketAllowedOrigins(origins ...string) HandlerOption {
+	return WithWebsocketOriginCheck(AllowedOrigins(origins...))
+}
+
+// WithWebsocketClientCertificateCheck rejects WebSocket connections whose TLS client certificates are rejected by the check.
+// Errors are responded with 403 Forbidden unless they are classified, e.g. with NewClassifiedError.
+func WithWebsocketClientCertificateCheck(check ClientCertificateCheck) HandlerOption {
+	return func(options *handlerOptions) {
+		options.websocketPolicy.checkClientCertificate = check
+	}
+}
+
+// WithWebsocketUpgradeHook adds a hook which is called before the WebSocket upgrade, after the origin and client certificate checks.
+// Hooks are called in the order they are added. Errors are responded with 403 Forbidden unless they are classified,
+// e.g. NewClassifiedError(ErrorClassAuthentication, err) responds with 401 Unauthorized.
+func WithWebsocketUpgradeHook(hook WebsocketUpgradeHook) HandlerOption {
+	return func(options *handlerOptions) {
+		options.websocketPolicy.upgradeHooks = append(options.websocketPolicy.upgradeHooks, hook)
+	}
+}
+
+// AllowedOrigins returns an OriginCheck accepting the origins, compared case-insensitively with the scheme, host and port of the Origin header.
+// The origin * accepts all origins. Requests without Origin header are accepted,
+// as only browsers send the header and other clients can't be hijacked.
+func AllowedOrigins(origins ...string) OriginCheck {
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		allowed[normalizeOrigin(origin)] = true
+	}
+	return func(r *http.Request) bool {
+		origin := r.Header.Get(httpHeaderOrigin)
+		if origin == "" || allowed["*"] {
+			return true
+		}
+		return allowed[normalizeOrigin(origin)]
+	}
+}
+
+func normalizeOrigin(origin string) string {
+	origin = strings.ToLower(strings.TrimSpace(origin))
+	parsed, err := url.Parse(origin)
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return origin
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// check returns an error if the WebSocket upgrade of the request is rejected
+func (p *websocketPolicy) check(r *http.Request) error {
+	if p.checkOrigin != nil && !p.checkOrigin(r) {
+		return NewClassifiedError(ErrorClassAuthorization, ErrWebsocketOriginNotAllowed)
+	}
+	if p.checkClientCertificate != nil {
+		var certificates []*x509.Certificate
+		if r.TLS != nil {
+			certificates = r.TLS.PeerCertificates
+		}
+		if err := p.checkClientCertificate(certificates); err != nil {
+			return classifyWebsocketRejection(err)
+		}
+	}
+	for _, hook := range p.upgradeHooks {
+		if err := hook(r); err != nil {
+			return classifyWebsocketRejection(err)
+		}
+	}
+	return nil
+}
+
+// classifyWebsocketRejection classifies the rejections of checks as authorization errors unless they are classified already
+func classifyWebsocketRejection(err error) error {
+	var classified ClassifiedError
+	if errors.As(err, &classified) {
+		return err
+	}
+	return NewClassifiedError(ErrorClassAuthorization, err)
+}
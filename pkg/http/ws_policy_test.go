@@ -0,0 +1,126 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gobwas/ws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
+	"github.com/wundergraph/graphql-go-tools/pkg/starwars"
+)
+
+func TestGraphQLHTTPRequestHandler_WebsocketPolicy(t *testing.T) {
+	upgradeRequest := func(origin string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(httpHeaderUpgrade, "websocket")
+		if origin != "" {
+			req.Header.Set(httpHeaderOrigin, origin)
+		}
+		return req
+	}
+	serve := func(handler http.Handler, req *http.Request) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	t.Run("rejects origins which aren't allowed", func(t *testing.T) {
+		handler := NewGraphqlHTTPHandlerFunc(nil, logging.NoopLogger, &ws.DefaultHTTPUpgrader, WithWebsocketAllowedOrigins("https://example.com"))
+
+		recorder := serve(handler, upgradeRequest("https://evil.com"))
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		assert.Equal(t, `{"errors":[{"message":"origin is not allowed","extensions":{"code":"FORBIDDEN"}}]}`+"\n", recorder.Body.String())
+	})
+
+	t.Run("rejects connections by their client certificates", func(t *testing.T) {
+		var inspected []*x509.Certificate
+		handler := NewGraphqlHTTPHandlerFunc(nil, logging.NoopLogger, &ws.DefaultHTTPUpgrader, WithWebsocketClientCertificateCheck(func(certificates []*x509.Certificate) error {
+			inspected = certificates
+			if len(certificates) == 0 || certificates[0].Subject.CommonName != "trusted" {
+				return errors.New("untrusted client")
+			}
+			return nil
+		}))
+
+		recorder := serve(handler, upgradeRequest(""))
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		assert.Empty(t, inspected)
+
+		req := upgradeRequest("")
+		req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{{Subject: pkix.Name{CommonName: "other"}}}}
+		recorder = serve(handler, req)
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		require.Len(t, inspected, 1)
+		assert.Equal(t, "other", inspected[0].Subject.CommonName)
+	})
+
+	t.Run("rejects connections by upgrade hooks", func(t *testing.T) {
+		var called []string
+		handler := NewGraphqlHTTPHandlerFunc(nil, logging.NoopLogger, &ws.DefaultHTTPUpgrader,
+			WithWebsocketUpgradeHook(func(r *http.Request) error {
+				called = append(called, "first")
+				return nil
+			}),
+			WithWebsocketUpgradeHook(func(r *http.Request) error {
+				called = append(called, "second")
+				if r.Header.Get("Authorization") == "" {
+					return NewClassifiedError(ErrorClassAuthentication, errors.New("missing credentials"))
+				}
+				return nil
+			}),
+		)
+
+		recorder := serve(handler, upgradeRequest(""))
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+		assert.Equal(t, `{"errors":[{"message":"missing credentials","extensions":{"code":"UNAUTHENTICATED"}}]}`+"\n", recorder.Body.String())
+		assert.Equal(t, []string{"first", "second"}, called)
+	})
+
+	t.Run("upgrades accepted connections", func(t *testing.T) {
+		starwars.SetRelativePathToStarWarsPackage("../starwars")
+		handler := NewGraphqlHTTPHandlerFunc(starwars.NewExecutionHandler(t), logging.NoopLogger, &ws.DefaultHTTPUpgrader,
+			WithWebsocketAllowedOrigins("https://Example.com:443/"),
+			WithWebsocketUpgradeHook(func(r *http.Request) error {
+				return nil
+			}),
+		)
+		server := httptest.NewServer(handler)
+		defer server.Close()
+
+		dialer := ws.Dialer{Header: ws.HandshakeHeaderHTTP(http.Header{httpHeaderOrigin: []string{"https://example.com:443"}})}
+		conn, _, _, err := dialer.Dial(context.Background(), fmt.Sprintf("ws://%s", server.Listener.Addr().String()))
+		require.NoError(t, err)
+		assert.NoError(t, conn.Close())
+	})
+}
+
+func TestAllowedOrigins(t *testing.T) {
+	request := func(origin string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if origin != "" {
+			req.Header.Set(httpHeaderOrigin, origin)
+		}
+		return req
+	}
+
+	check := AllowedOrigins("https://example.com", "http://localhost:3000")
+	assert.True(t, check(request("https://example.com")))
+	assert.True(t, check(request("HTTPS://EXAMPLE.COM")))
+	assert.True(t, check(request("http://localhost:3000")))
+	assert.True(t, check(request("")), "requests without origin are accepted")
+	assert.False(t, check(request("http://example.com")))
+	assert.False(t, check(request("http://localhost:3001")))
+	assert.False(t, check(request("null")))
+
+	assert.True(t, AllowedOrigins("*")(request("https://any.com")))
+}
@@ -4,22 +4,23 @@ import (
 	"encoding/json"
 	"errors"
 	"net"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/gobwas/ws"
 	"github.com/gobwas/ws/wsutil"
-	"github.com/jensneuse/abstractlogger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
 	"github.com/wundergraph/graphql-go-tools/pkg/subscription"
 )
 
 func TestWebsocketSubscriptionClient_WriteToClient(t *testing.T) {
 	connToServer, connToClient := net.Pipe()
 
-	websocketClient := NewWebsocketSubscriptionClient(abstractlogger.NoopLogger, connToClient)
+	websocketClient := NewWebsocketSubscriptionClient(logging.NoopLogger, connToClient)
 
 	t.Run("should write successfully to client", func(t *testing.T) {
 		messageToClient := subscription.Message{
@@ -60,7 +61,7 @@ func TestWebsocketSubscriptionClient_WriteToClient(t *testing.T) {
 func TestWebsocketSubscriptionClient_ReadFromClient(t *testing.T) {
 	t.Run("should successfully read from client", func(t *testing.T) {
 		connToServer, connToClient := net.Pipe()
-		websocketClient := NewWebsocketSubscriptionClient(abstractlogger.NoopLogger, connToClient)
+		websocketClient := NewWebsocketSubscriptionClient(logging.NoopLogger, connToClient)
 
 		messageToServer := &subscription.Message{
 			Id:      "1",
@@ -86,7 +87,7 @@ func TestWebsocketSubscriptionClient_ReadFromClient(t *testing.T) {
 
 func TestWebsocketSubscriptionClient_IsConnected(t *testing.T) {
 	_, connToClient := net.Pipe()
-	websocketClient := NewWebsocketSubscriptionClient(abstractlogger.NoopLogger, connToClient)
+	websocketClient := NewWebsocketSubscriptionClient(logging.NoopLogger, connToClient)
 
 	t.Run("should return true when a connection is established", func(t *testing.T) {
 		isConnected := websocketClient.IsConnected()
@@ -106,7 +107,7 @@ func TestWebsocketSubscriptionClient_IsConnected(t *testing.T) {
 
 func TestWebsocketSubscriptionClient_Disconnect(t *testing.T) {
 	_, connToClient := net.Pipe()
-	websocketClient := NewWebsocketSubscriptionClient(abstractlogger.NoopLogger, connToClient)
+	websocketClient := NewWebsocketSubscriptionClient(logging.NoopLogger, connToClient)
 
 	t.Run("should disconnect and indicate a closed connection", func(t *testing.T) {
 		err := websocketClient.Disconnect()
@@ -117,7 +118,7 @@ func TestWebsocketSubscriptionClient_Disconnect(t *testing.T) {
 
 func TestWebsocketSubscriptionClient_isClosedConnectionError(t *testing.T) {
 	_, connToClient := net.Pipe()
-	websocketClient := NewWebsocketSubscriptionClient(abstractlogger.NoopLogger, connToClient)
+	websocketClient := NewWebsocketSubscriptionClient(logging.NoopLogger, connToClient)
 
 	t.Run("should not close connection when it is not a closed connection error", func(t *testing.T) {
 		isClosedConnectionError := websocketClient.isClosedConnectionError(errors.New("no closed connection err"))
@@ -129,3 +130,141 @@ func TestWebsocketSubscriptionClient_isClosedConnectionError(t *testing.T) {
 		assert.True(t, isClosedConnectionError)
 	})
 }
+
+// countingConn counts the writes to the connection
+type countingConn struct {
+	net.Conn
+	mu     sync.Mutex
+	writes int
+}
+
+func (c *countingConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	c.writes++
+	c.mu.Unlock()
+	return c.Conn.Write(b)
+}
+
+func (c *countingConn) Writes() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.writes
+}
+
+func TestWebsocketSubscriptionClient_BatchedWrites(t *testing.T) {
+	message := func(id string) subscription.Message {
+		return subscription.Message{Id: id, Type: subscription.MessageTypeData, Payload: []byte(`{"data":null}`)}
+	}
+	readMessage := func(t *testing.T, conn net.Conn) subscription.Message {
+		data, opCode, err := wsutil.ReadServerData(conn)
+		require.NoError(t, err)
+		require.Equal(t, ws.OpText, opCode)
+		var message subscription.Message
+		require.NoError(t, json.Unmarshal(data, &message))
+		return message
+	}
+
+	t.Run("should write the messages queued within the flush interval at once", func(t *testing.T) {
+		connToServer, connToClient := net.Pipe()
+		conn := &countingConn{Conn: connToClient}
+		websocketClient := NewWebsocketSubscriptionClient(logging.NoopLogger, conn, WithBatchedWrites(WebsocketWriterConfig{
+			FlushInterval: 50 * time.Millisecond,
+		}))
+
+		for _, id := range []string{"1", "2", "3"} {
+			require.NoError(t, websocketClient.WriteToClient(message(id)))
+		}
+		for _, id := range []string{"1", "2", "3"} {
+			assert.Equal(t, message(id), readMessage(t, connToServer))
+		}
+		assert.Equal(t, 1, conn.Writes())
+	})
+
+	t.Run("should limit the size of batches", func(t *testing.T) {
+		connToServer, connToClient := net.Pipe()
+		conn := &countingConn{Conn: connToClient}
+		websocketClient := NewWebsocketSubscriptionClient(logging.NoopLogger, conn, WithBatchedWrites(WebsocketWriterConfig{
+			FlushInterval: 50 * time.Millisecond,
+			MaxBatchSize:  2,
+		}))
+
+		for _, id := range []string{"1", "2", "3"} {
+			require.NoError(t, websocketClient.WriteToClient(message(id)))
+		}
+		for _, id := range []string{"1", "2", "3"} {
+			assert.Equal(t, message(id), readMessage(t, connToServer))
+		}
+		assert.Equal(t, 2, conn.Writes())
+	})
+
+	t.Run("should fail writes to slow clients instead of blocking", func(t *testing.T) {
+		_, connToClient := net.Pipe()
+		websocketClient := NewWebsocketSubscriptionClient(logging.NoopLogger, connToClient, WithBatchedWrites(WebsocketWriterConfig{
+			MaxPendingMessages: 1,
+		}))
+
+		var err error
+		for i := 0; i < 10 && err == nil; i++ {
+			err = websocketClient.WriteToClient(message("1"))
+		}
+		assert.ErrorIs(t, err, ErrSlowWebsocketClient)
+		assert.False(t, websocketClient.IsConnected())
+	})
+
+	t.Run("should close the connection if a batch isn't read before the write timeout", func(t *testing.T) {
+		connToServer, connToClient := net.Pipe()
+		websocketClient := NewWebsocketSubscriptionClient(logging.NoopLogger, connToClient, WithBatchedWrites(WebsocketWriterConfig{
+			WriteTimeout: 10 * time.Millisecond,
+		}))
+
+		require.NoError(t, websocketClient.WriteToClient(message("1")))
+		assert.Eventually(t, func() bool {
+			err := websocketClient.WriteToClient(message("2"))
+			var netErr net.Error
+			return errors.As(err, &netErr) && netErr.Timeout()
+		}, time.Second, 5*time.Millisecond)
+		assert.False(t, websocketClient.IsConnected())
+
+		_, _, err := wsutil.ReadServerData(connToServer)
+		assert.Error(t, err)
+	})
+
+	t.Run("should write the pending messages on disconnect", func(t *testing.T) {
+		connToServer, connToClient := net.Pipe()
+		websocketClient := NewWebsocketSubscriptionClient(logging.NoopLogger, connToClient, WithBatchedWrites(WebsocketWriterConfig{
+			FlushInterval: time.Second,
+		}))
+
+		require.NoError(t, websocketClient.WriteToClient(message("1")))
+		require.NoError(t, websocketClient.WriteToClient(message("2")))
+		disconnected := make(chan error)
+		go func() {
+			disconnected <- websocketClient.Disconnect()
+		}()
+
+		assert.Equal(t, message("1"), readMessage(t, connToServer))
+		assert.Equal(t, message("2"), readMessage(t, connToServer))
+		assert.NoError(t, <-disconnected)
+		assert.False(t, websocketClient.IsConnected())
+	})
+
+	t.Run("should close the connection on disconnect if the pending messages aren't read before the close timeout", func(t *testing.T) {
+		_, connToClient := net.Pipe()
+		websocketClient := NewWebsocketSubscriptionClient(logging.NoopLogger, connToClient, WithBatchedWrites(WebsocketWriterConfig{
+			CloseTimeout: 10 * time.Millisecond,
+		}))
+
+		require.NoError(t, websocketClient.WriteToClient(message("1")))
+		disconnected := make(chan error)
+		go func() {
+			disconnected <- websocketClient.Disconnect()
+		}()
+
+		select {
+		case <-disconnected:
+		case <-time.After(time.Second):
+			assert.Fail(t, "disconnect blocked on the client which doesn't read")
+		}
+		assert.False(t, websocketClient.IsConnected())
+	})
+}
@@ -0,0 +1,196 @@
+package http
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/gobwas/ws"
+)
+
+const (
+	defaultWebsocketMaxBatchSize       = 16
+	defaultWebsocketMaxPendingMessages = 256
+	defaultWebsocketCloseTimeout       = 5 * time.Second
+)
+
+// ErrSlowWebsocketClient is returned for messages written to a client which doesn't read the messages written before fast enough,
+// the connection of the client is closed instead of blocking the subscriptions writing to it.
+var ErrSlowWebsocketClient = errors.New("websocket client is too slow: too many pending messages")
+
+// ErrWebsocketCloseTimeout is returned on close if the queued messages aren't written within the close timeout.
+var ErrWebsocketCloseTimeout = errors.New("websocket client is too slow: pending messages not written before close timeout")
+
+// WebsocketWriterConfig configures the batched writes of the messages of a WebSocket client, see WithWebsocketWriter.
+// Messages are queued and written by a goroutine of the client, so that subscriptions don't block on slow clients.
+type WebsocketWriterConfig struct {
+	// FlushInterval is the time the writer waits for more messages to write them to the connection at once.
+	// The queued messages are written without waiting if it's 0.
+	FlushInterval time.Duration
+	// MaxBatchSize is the maximum number of messages written to the connection at once, it defaults to 16
+	MaxBatchSize int
+	// MaxPendingMessages is the maximum number of queued messages, it defaults to 256.
+	// Writing another message fails with ErrSlowWebsocketClient and closes the connection.
+	MaxPendingMessages int
+	// WriteTimeout is the write deadline of each batch, the connection is closed if the client doesn't read a batch in time.
+	// Batches are written without deadline if it's 0.
+	WriteTimeout time.Duration
+	// CloseTimeout is the time the queued messages are written for on close, it defaults to 5 seconds.
+	// The connection is closed afterwards, so that closing doesn't block on clients which don't read.
+	CloseTimeout time.Duration
+}
+
+// WithWebsocketWriter writes the messages of WebSocket clients in batches without blocking subscriptions on slow clients,
+// see WebsocketWriterConfig. Messages are written directly to the connection unless configured.
+func WithWebsocketWriter(config WebsocketWriterConfig) HandlerOption {
+	return func(options *handlerOptions) {
+		options.websocketWriter = &config
+	}
+}
+
+// websocketWriter queues the messages of a client and writes them in batches to the connection
+type websocketWriter struct {
+	conn     net.Conn
+	config   WebsocketWriterConfig
+	messages chan []byte
+	// done is closed once all queued messages are written or writing failed
+	done chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+	err    error
+}
+
+func newWebsocketWriter(conn net.Conn, config WebsocketWriterConfig) *websocketWriter {
+	if config.MaxBatchSize <= 0 {
+		config.MaxBatchSize = defaultWebsocketMaxBatchSize
+	}
+	if config.MaxPendingMessages <= 0 {
+		config.MaxPendingMessages = defaultWebsocketMaxPendingMessages
+	}
+	if config.CloseTimeout <= 0 {
+		config.CloseTimeout = defaultWebsocketCloseTimeout
+	}
+	w := &websocketWriter{
+		conn:     conn,
+		config:   config,
+		messages: make(chan []byte, config.MaxPendingMessages),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// write queues the message without blocking, it returns the error of a previous write if writing failed
+func (w *websocketWriter) write(message []byte) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	if w.closed {
+		return nil
+	}
+	select {
+	case w.messages <- message:
+		return nil
+	default:
+		w.failLocked(ErrSlowWebsocketClient)
+		return w.err
+	}
+}
+
+// close writes the queued messages within the close timeout and stops the writer, it returns the error if writing failed
+func (w *websocketWriter) close() error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.messages)
+	}
+	w.mu.Unlock()
+
+	timer := time.NewTimer(w.config.CloseTimeout)
+	defer timer.Stop()
+	select {
+	case <-w.done:
+	case <-timer.C:
+		// closing the connection unblocks the pending write
+		w.mu.Lock()
+		w.failLocked(ErrWebsocketCloseTimeout)
+		w.mu.Unlock()
+		<-w.done
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+func (w *websocketWriter) run() {
+	defer close(w.done)
+	batch := &bytes.Buffer{}
+	for message := range w.messages {
+		batch.Reset()
+		_ = ws.WriteFrame(batch, ws.NewTextFrame(message))
+		w.collect(batch)
+		if err := w.flush(batch.Bytes()); err != nil {
+			w.mu.Lock()
+			w.failLocked(err)
+			w.mu.Unlock()
+			return
+		}
+	}
+}
+
+// collect adds the messages queued within the flush interval to the batch, up to the max batch size
+func (w *websocketWriter) collect(batch *bytes.Buffer) {
+	var flush <-chan time.Time
+	if w.config.FlushInterval > 0 {
+		timer := time.NewTimer(w.config.FlushInterval)
+		defer timer.Stop()
+		flush = timer.C
+	}
+	for size := 1; size < w.config.MaxBatchSize; size++ {
+		var (
+			message []byte
+			ok      bool
+		)
+		if flush == nil {
+			select {
+			case message, ok = <-w.messages:
+			default:
+				return
+			}
+		} else {
+			select {
+			case message, ok = <-w.messages:
+			case <-flush:
+				return
+			}
+		}
+		if !ok {
+			return
+		}
+		_ = ws.WriteFrame(batch, ws.NewTextFrame(message))
+	}
+}
+
+func (w *websocketWriter) flush(batch []byte) error {
+	if w.config.WriteTimeout > 0 {
+		if err := w.conn.SetWriteDeadline(time.Now().Add(w.config.WriteTimeout)); err != nil {
+			return err
+		}
+	}
+	_, err := w.conn.Write(batch)
+	return err
+}
+
+// failLocked records the first error and closes the connection, so that reads of the client fail as well
+func (w *websocketWriter) failLocked(err error) {
+	if w.err != nil {
+		return
+	}
+	w.err = err
+	_ = w.conn.Close()
+}
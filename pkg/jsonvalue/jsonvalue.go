@@ -0,0 +1,238 @@
+// Package jsonvalue contains helpers for data source authors to build the JSON responses of their data sources.
+// A Value distinguishes absent values, which are omitted from objects, from null values,
+// an Object keeps the order of its fields and the scalar constructors follow the serialization rules of the GraphQL scalars.
+package jsonvalue
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+var (
+	// ErrIntOutOfRange is returned for integers which can't be represented by the GraphQL Int type, i.e. a signed 32-bit integer
+	ErrIntOutOfRange = errors.New("jsonvalue: Int cannot represent a value outside of the signed 32-bit range")
+	// ErrNonFiniteFloat is returned for NaN and infinite floats which can't be represented by the GraphQL Float type
+	ErrNonFiniteFloat = errors.New("jsonvalue: Float cannot represent a non-finite value")
+	// ErrInvalidJSON is returned by Raw for invalid JSON
+	ErrInvalidJSON = errors.New("jsonvalue: invalid JSON")
+)
+
+var (
+	null      = []byte("null")
+	trueJSON  = []byte("true")
+	falseJSON = []byte("false")
+)
+
+// Value is a serialized JSON value. The zero value is absent, i.e. fields with absent values are omitted from objects
+// while fields with Null values are rendered as null.
+type Value struct {
+	raw []byte
+}
+
+// Absent returns the absent value, it's the same as the zero value
+func Absent() Value {
+	return Value{}
+}
+
+// Null returns the JSON null value
+func Null() Value {
+	return Value{raw: null}
+}
+
+// Raw returns the value of already serialized JSON, e.g. a part of an upstream response.
+// It returns ErrInvalidJSON if the input isn't valid JSON. The input is copied.
+func Raw(input []byte) (Value, error) {
+	if !json.Valid(input) {
+		return Value{}, ErrInvalidJSON
+	}
+	return Value{raw: append([]byte(nil), input...)}, nil
+}
+
+// String returns the JSON string of the GraphQL String type, invalid UTF-8 is replaced by the replacement character
+func String(s string) Value {
+	return Value{raw: appendString(make([]byte, 0, len(s)+2), s)}
+}
+
+// ID returns the value of the GraphQL ID type, which is always serialized as a string
+func ID(s string) Value {
+	return String(s)
+}
+
+// IntID returns the value of the GraphQL ID type for a numeric identifier, which is serialized as a string
+func IntID(v int64) Value {
+	return String(strconv.FormatInt(v, 10))
+}
+
+// Bool returns the value of the GraphQL Boolean type
+func Bool(b bool) Value {
+	if b {
+		return Value{raw: trueJSON}
+	}
+	return Value{raw: falseJSON}
+}
+
+// Int returns the value of the GraphQL Int type
+func Int(v int32) Value {
+	return Value{raw: strconv.AppendInt(nil, int64(v), 10)}
+}
+
+// Int64 returns the value of the GraphQL Int type, it returns ErrIntOutOfRange for values outside of the 32-bit range.
+// Use a custom scalar, e.g. BigInt, for larger integers.
+func Int64(v int64) (Value, error) {
+	if v < math.MinInt32 || v > math.MaxInt32 {
+		return Value{}, ErrIntOutOfRange
+	}
+	return Int(int32(v)), nil
+}
+
+// Float returns the value of the GraphQL Float type, it returns ErrNonFiniteFloat for NaN and infinite values.
+// Floats are formatted like encoding/json, i.e. without exponent for values between 1e-6 and 1e21.
+func Float(v float64) (Value, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return Value{}, ErrNonFiniteFloat
+	}
+	abs := math.Abs(v)
+	format := byte('f')
+	if abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	raw := strconv.AppendFloat(nil, v, format, -1, 64)
+	if format == 'e' {
+		// clean up e-09 to e-9 like encoding/json
+		if n := len(raw); n >= 4 && raw[n-4] == 'e' && raw[n-3] == '-' && raw[n-2] == '0' {
+			raw[n-2] = raw[n-1]
+			raw = raw[:n-1]
+		}
+	}
+	return Value{raw: raw}, nil
+}
+
+// NullableString returns the String of s or Null if s is nil
+func NullableString(s *string) Value {
+	if s == nil {
+		return Null()
+	}
+	return String(*s)
+}
+
+// NullableBool returns the Bool of b or Null if b is nil
+func NullableBool(b *bool) Value {
+	if b == nil {
+		return Null()
+	}
+	return Bool(*b)
+}
+
+// NullableInt returns the Int of v or Null if v is nil
+func NullableInt(v *int32) Value {
+	if v == nil {
+		return Null()
+	}
+	return Int(*v)
+}
+
+// NullableFloat returns the Float of v or Null if v is nil
+func NullableFloat(v *float64) (Value, error) {
+	if v == nil {
+		return Null(), nil
+	}
+	return Float(*v)
+}
+
+// Array returns the JSON array of the values, absent values are rendered as null
+func Array(values ...Value) Value {
+	raw := append(make([]byte, 0, 2+len(values)*8), '[')
+	for i := range values {
+		if i != 0 {
+			raw = append(raw, ',')
+		}
+		raw = values[i].appendJSON(raw)
+	}
+	return Value{raw: append(raw, ']')}
+}
+
+// IsAbsent returns true for the absent value
+func (v Value) IsAbsent() bool {
+	return v.raw == nil
+}
+
+// IsNull returns true for the null value
+func (v Value) IsNull() bool {
+	return string(v.raw) == "null"
+}
+
+// Bytes returns the serialized JSON of the value, it's nil for the absent value.
+// The returned slice must not be modified.
+func (v Value) Bytes() []byte {
+	return v.raw
+}
+
+// MarshalJSON implements json.Marshaler, the absent value is marshalled as null
+func (v Value) MarshalJSON() ([]byte, error) {
+	return v.appendJSON(nil), nil
+}
+
+func (v Value) appendJSON(dst []byte) []byte {
+	if v.raw == nil {
+		return append(dst, null...)
+	}
+	return append(dst, v.raw...)
+}
+
+const hex = "0123456789abcdef"
+
+// appendString appends the quoted JSON string of s, escaping quotes, backslashes, control characters
+// and the line separators U+2028 and U+2029 like encoding/json
+func appendString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				dst = append(dst, '\\', c)
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
@@ -0,0 +1,111 @@
+package jsonvalue
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValue(t *testing.T) {
+	t.Run("absent and null", func(t *testing.T) {
+		assert.True(t, Absent().IsAbsent())
+		assert.True(t, Value{}.IsAbsent())
+		assert.False(t, Absent().IsNull())
+		assert.True(t, Null().IsNull())
+		assert.False(t, Null().IsAbsent())
+		assert.Nil(t, Absent().Bytes())
+		assert.Equal(t, "null", string(Null().Bytes()))
+	})
+
+	t.Run("String escapes like encoding/json", func(t *testing.T) {
+		for _, input := range []string{"", "hello", `quote " and \ backslash`, "tab\tnew line\nreturn\r", "control \x00\x01\x1f", "unicode ä 😀", "line separators \u2028\u2029", "<html>&</html>", "invalid \xff utf-8"} {
+			buf := &bytes.Buffer{}
+			encoder := json.NewEncoder(buf)
+			encoder.SetEscapeHTML(false)
+			require.NoError(t, encoder.Encode(input))
+
+			var expected, actual string
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &expected))
+			require.NoError(t, json.Unmarshal(String(input).Bytes(), &actual))
+			assert.Equal(t, expected, actual, input)
+		}
+		assert.Equal(t, `"a\"b\\c\nd\u0001\u2028"`, string(String("a\"b\\c\nd\x01\u2028").Bytes()))
+	})
+
+	t.Run("ID is serialized as string", func(t *testing.T) {
+		assert.Equal(t, `"1"`, string(ID("1").Bytes()))
+		assert.Equal(t, `"42"`, string(IntID(42).Bytes()))
+	})
+
+	t.Run("Int is a 32-bit integer", func(t *testing.T) {
+		assert.Equal(t, "-7", string(Int(-7).Bytes()))
+
+		value, err := Int64(math.MaxInt32)
+		require.NoError(t, err)
+		assert.Equal(t, "2147483647", string(value.Bytes()))
+
+		_, err = Int64(math.MaxInt32 + 1)
+		assert.ErrorIs(t, err, ErrIntOutOfRange)
+		_, err = Int64(math.MinInt32 - 1)
+		assert.ErrorIs(t, err, ErrIntOutOfRange)
+	})
+
+	t.Run("Float is formatted like encoding/json", func(t *testing.T) {
+		for _, input := range []float64{0, 1, -1.5, 3.14159, 1e20, 1e21, 1.5e-7, 0.000001, 123456789.125, math.MaxFloat64, math.SmallestNonzeroFloat64} {
+			expected, err := json.Marshal(input)
+			require.NoError(t, err)
+			value, err := Float(input)
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), string(value.Bytes()))
+		}
+
+		for _, input := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+			_, err := Float(input)
+			assert.ErrorIs(t, err, ErrNonFiniteFloat)
+		}
+	})
+
+	t.Run("nullable values", func(t *testing.T) {
+		s, b, i, f := "s", true, int32(1), 1.5
+		assert.Equal(t, `"s"`, string(NullableString(&s).Bytes()))
+		assert.True(t, NullableString(nil).IsNull())
+		assert.Equal(t, "true", string(NullableBool(&b).Bytes()))
+		assert.True(t, NullableBool(nil).IsNull())
+		assert.Equal(t, "1", string(NullableInt(&i).Bytes()))
+		assert.True(t, NullableInt(nil).IsNull())
+
+		value, err := NullableFloat(&f)
+		require.NoError(t, err)
+		assert.Equal(t, "1.5", string(value.Bytes()))
+		value, err = NullableFloat(nil)
+		require.NoError(t, err)
+		assert.True(t, value.IsNull())
+	})
+
+	t.Run("Raw", func(t *testing.T) {
+		value, err := Raw([]byte(`{"a":[1,2]}`))
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":[1,2]}`, string(value.Bytes()))
+
+		_, err = Raw([]byte(`{"a":`))
+		assert.ErrorIs(t, err, ErrInvalidJSON)
+	})
+
+	t.Run("Array renders absent values as null", func(t *testing.T) {
+		assert.Equal(t, `[]`, string(Array().Bytes()))
+		assert.Equal(t, `[1,null,null,"a"]`, string(Array(Int(1), Null(), Absent(), String("a")).Bytes()))
+	})
+
+	t.Run("MarshalJSON", func(t *testing.T) {
+		out, err := json.Marshal(struct {
+			A Value `json:"a"`
+			B Value `json:"b"`
+		}{A: Bool(false)})
+		require.NoError(t, err)
+		assert.Equal(t, `{"a":false,"b":null}`, string(out))
+	})
+}
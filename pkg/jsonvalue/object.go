@@ -0,0 +1,85 @@
+package jsonvalue
+
+import (
+	"io"
+)
+
+// Object is a JSON object which keeps the order in which its fields were set, e.g. the order of the selected fields.
+// Fields with absent values are omitted. The zero value is an empty object ready to use.
+type Object struct {
+	keys   []string
+	values []Value
+}
+
+// Set sets the value of the field, fields which are set again keep their position.
+// Setting an absent value removes the field.
+func (o *Object) Set(key string, value Value) {
+	for i := range o.keys {
+		if o.keys[i] != key {
+			continue
+		}
+		if value.IsAbsent() {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			o.values = append(o.values[:i], o.values[i+1:]...)
+			return
+		}
+		o.values[i] = value
+		return
+	}
+	if value.IsAbsent() {
+		return
+	}
+	o.keys = append(o.keys, key)
+	o.values = append(o.values, value)
+}
+
+// Get returns the value of the field, it's absent if the field isn't set
+func (o *Object) Get(key string) Value {
+	for i := range o.keys {
+		if o.keys[i] == key {
+			return o.values[i]
+		}
+	}
+	return Value{}
+}
+
+// Len returns the number of fields
+func (o *Object) Len() int {
+	return len(o.keys)
+}
+
+// Reset removes all fields, so that the object can be reused
+func (o *Object) Reset() {
+	o.keys = o.keys[:0]
+	o.values = o.values[:0]
+}
+
+// AppendJSON appends the serialized object to dst
+func (o *Object) AppendJSON(dst []byte) []byte {
+	dst = append(dst, '{')
+	for i := range o.keys {
+		if i != 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendString(dst, o.keys[i])
+		dst = append(dst, ':')
+		dst = append(dst, o.values[i].raw...)
+	}
+	return append(dst, '}')
+}
+
+// Value returns the serialized object, e.g. to nest it in another object or array
+func (o *Object) Value() Value {
+	return Value{raw: o.AppendJSON(nil)}
+}
+
+// WriteTo writes the serialized object to w, e.g. to the writer of the Load method of a data source
+func (o *Object) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(o.AppendJSON(nil))
+	return int64(n), err
+}
+
+// MarshalJSON implements json.Marshaler
+func (o *Object) MarshalJSON() ([]byte, error) {
+	return o.AppendJSON(nil), nil
+}
@@ -0,0 +1,64 @@
+package jsonvalue
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObject(t *testing.T) {
+	t.Run("keeps the order and omits absent fields", func(t *testing.T) {
+		var friend Object
+		friend.Set("name", String("Jannik"))
+
+		var user Object
+		user.Set("name", String("Jens"))
+		user.Set("id", ID("1"))
+		user.Set("nickname", Null())
+		user.Set("age", Absent())
+		user.Set("friends", Array(friend.Value()))
+
+		assert.Equal(t, `{"name":"Jens","id":"1","nickname":null,"friends":[{"name":"Jannik"}]}`, string(user.AppendJSON(nil)))
+		assert.Equal(t, 4, user.Len())
+		assert.Equal(t, `"1"`, string(user.Get("id").Bytes()))
+		assert.True(t, user.Get("age").IsAbsent())
+	})
+
+	t.Run("fields which are set again keep their position", func(t *testing.T) {
+		var object Object
+		object.Set("a", Int(1))
+		object.Set("b", Int(2))
+		object.Set("a", Int(3))
+		assert.Equal(t, `{"a":3,"b":2}`, string(object.AppendJSON(nil)))
+
+		object.Set("a", Absent())
+		assert.Equal(t, `{"b":2}`, string(object.AppendJSON(nil)))
+
+		object.Reset()
+		assert.Equal(t, `{}`, string(object.AppendJSON(nil)))
+	})
+
+	t.Run("keys are escaped", func(t *testing.T) {
+		var object Object
+		object.Set(`a"b`, Bool(true))
+		assert.Equal(t, `{"a\"b":true}`, string(object.AppendJSON(nil)))
+	})
+
+	t.Run("writes and marshals the object", func(t *testing.T) {
+		var object Object
+		object.Set("data", Null())
+
+		buf := &bytes.Buffer{}
+		n, err := object.WriteTo(buf)
+		require.NoError(t, err)
+		assert.Equal(t, int64(13), n)
+		assert.Equal(t, `{"data":null}`, buf.String())
+
+		out, err := json.Marshal(&object)
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":null}`, string(out))
+	})
+}
@@ -39,4 +39,5 @@ const (
 	RBRACK
 	LBRACE
 	RBRACE
+	QUESTIONMARK
 )
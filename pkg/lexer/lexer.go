@@ -72,6 +72,8 @@ func (l *Lexer) matchSingleRuneToken(r byte, tok *token.Token) bool {
 		tok.Keyword = keyword.COLON
 	case runes.BANG:
 		tok.Keyword = keyword.BANG
+	case runes.QUESTIONMARK:
+		tok.Keyword = keyword.QUESTIONMARK
 	case runes.LPAREN:
 		tok.Keyword = keyword.LPAREN
 	case runes.RPAREN:
@@ -282,6 +282,9 @@ func TestLexer_Peek_Read(t *testing.T) {
 	t.Run("read bang", func(t *testing.T) {
 		run("!", mustRead(keyword.BANG, "!"))
 	})
+	t.Run("read question mark", func(t *testing.T) {
+		run("?", mustRead(keyword.QUESTIONMARK, "?"))
+	})
 	t.Run("read bracket open", func(t *testing.T) {
 		run("(", mustRead(keyword.LPAREN, "("))
 	})
@@ -10,6 +10,7 @@ var (
 	TAB            = []byte("	")
 	SPACE          = []byte(" ")
 	QUOTE          = []byte("\"")
+	QUESTIONMARK   = []byte("?")
 	COMMA          = []byte(",")
 	AT             = []byte("@")
 	DOLLAR         = []byte("$")
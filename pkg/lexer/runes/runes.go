@@ -12,6 +12,7 @@ const (
 	COMMA          = ','
 	HASHTAG        = '#'
 	QUOTE          = '"'
+	QUESTIONMARK   = '?'
 	BACKSLASH      = '\\'
 	DOT            = '.'
 	EXPONENT_LOWER = 'e'
@@ -0,0 +1,78 @@
+package logging
+
+import (
+	"time"
+
+	"github.com/jensneuse/abstractlogger"
+)
+
+// NewAbstractLogger returns a Logger which logs to an abstractlogger.Logger,
+// the logger which was used by the packages before, so that existing loggers can still be used
+func NewAbstractLogger(logger abstractlogger.Logger) Logger {
+	return &abstractLogger{
+		l: logger,
+	}
+}
+
+type abstractLogger struct {
+	l abstractlogger.Logger
+	// with are the fields added by With
+	with []Field
+}
+
+func (a *abstractLogger) Debug(msg string, fields ...Field) {
+	a.l.Debug(msg, a.fields(fields)...)
+}
+
+func (a *abstractLogger) Info(msg string, fields ...Field) {
+	a.l.Info(msg, a.fields(fields)...)
+}
+
+func (a *abstractLogger) Warn(msg string, fields ...Field) {
+	a.l.Warn(msg, a.fields(fields)...)
+}
+
+func (a *abstractLogger) Error(msg string, fields ...Field) {
+	a.l.Error(msg, a.fields(fields)...)
+}
+
+func (a *abstractLogger) With(fields ...Field) Logger {
+	with := make([]Field, 0, len(a.with)+len(fields))
+	with = append(append(with, a.with...), fields...)
+	return &abstractLogger{
+		l:    a.l,
+		with: with,
+	}
+}
+
+func (a *abstractLogger) fields(fields []Field) []abstractlogger.Field {
+	out := make([]abstractlogger.Field, 0, len(a.with)+len(fields))
+	for i := range a.with {
+		out = append(out, abstractLoggerField(a.with[i]))
+	}
+	for i := range fields {
+		out = append(out, abstractLoggerField(fields[i]))
+	}
+	return out
+}
+
+func abstractLoggerField(field Field) abstractlogger.Field {
+	switch value := field.Value.(type) {
+	case string:
+		return abstractlogger.String(field.Key, value)
+	case []string:
+		return abstractlogger.Strings(field.Key, value)
+	case int:
+		return abstractlogger.Int(field.Key, value)
+	case int64:
+		return abstractlogger.Int(field.Key, int(value))
+	case bool:
+		return abstractlogger.Bool(field.Key, value)
+	case time.Duration:
+		return abstractlogger.String(field.Key, value.String())
+	case error:
+		return abstractlogger.NamedError(field.Key, value)
+	default:
+		return abstractlogger.Any(field.Key, value)
+	}
+}
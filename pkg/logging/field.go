@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"time"
+)
+
+// Field is a structured field of a log entry.
+// The Value is a string, []string, int, int64, bool, time.Duration or error for the constructors of the package,
+// adapters log other values with the Any field of their backend.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Error is the error of a log entry with the key error
+func Error(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+func NamedError(key string, err error) Field {
+	return Field{Key: key, Value: err}
+}
+
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Strings(key string, value []string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// ByteString is a string field of a byte slice, e.g. of a JSON document
+func ByteString(key string, value []byte) Field {
+	return Field{Key: key, Value: string(value)}
+}
+
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Int64(key string, value int64) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Bool(key string, value bool) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Value: value}
+}
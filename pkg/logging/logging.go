@@ -0,0 +1,101 @@
+// Package logging is the logging interface of the packages of graphql-go-tools.
+//
+// Logger is implemented by adapters for popular logging backends, e.g. NewZapLogger, NewLogrusLogger and NewSlogLogger,
+// so that the operational events of the engine, data sources, subscriptions and handlers are logged to the logger of the application.
+package logging
+
+import (
+	"fmt"
+)
+
+// Logger is a leveled logger with structured fields
+type Logger interface {
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+	// With returns a logger which adds the fields to all of its log entries
+	With(fields ...Field) Logger
+}
+
+// Level is the severity of a log entry
+type Level int
+
+const (
+	DebugLevel Level = iota + 1
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+)
+
+func (l Level) String() string {
+	switch l {
+	case DebugLevel:
+		return "debug"
+	case InfoLevel:
+		return "info"
+	case WarnLevel:
+		return "warn"
+	case ErrorLevel:
+		return "error"
+	default:
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+}
+
+// enabled returns true if entries of the level are logged by a logger with the minimum level
+func (l Level) enabled(level Level) bool {
+	return level >= l
+}
+
+// NoopLogger satisfies the Logger interface while doing nothing
+var NoopLogger Logger = Noop{}
+
+type Noop struct{}
+
+func (Noop) Debug(msg string, fields ...Field) {}
+
+func (Noop) Info(msg string, fields ...Field) {}
+
+func (Noop) Warn(msg string, fields ...Field) {}
+
+func (Noop) Error(msg string, fields ...Field) {}
+
+func (n Noop) With(fields ...Field) Logger {
+	return n
+}
+
+// LevelLogger logs with the Println and Printf methods of the standard library log package at a fixed level,
+// e.g. for libraries which accept a printf style logger
+type LevelLogger struct {
+	logger Logger
+	level  Level
+}
+
+func NewLevelLogger(logger Logger, level Level) *LevelLogger {
+	return &LevelLogger{
+		logger: logger,
+		level:  level,
+	}
+}
+
+func (l *LevelLogger) Println(v ...interface{}) {
+	l.log(fmt.Sprint(v...))
+}
+
+func (l *LevelLogger) Printf(format string, v ...interface{}) {
+	l.log(fmt.Sprintf(format, v...))
+}
+
+func (l *LevelLogger) log(msg string) {
+	switch l.level {
+	case DebugLevel:
+		l.logger.Debug(msg)
+	case InfoLevel:
+		l.logger.Info(msg)
+	case WarnLevel:
+		l.logger.Warn(msg)
+	default:
+		l.logger.Error(msg)
+	}
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestZapLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := NewZapLogger(zap.New(core), InfoLevel)
+
+	logger.Debug("skipped")
+	logger.With(String("component", "engine")).Warn("fetch failed", Int("attempt", 2), Error(errors.New("timeout")))
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 1)
+	assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+	assert.Equal(t, "fetch failed", entries[0].Message)
+	assert.Equal(t, map[string]interface{}{
+		"component": "engine",
+		"attempt":   int64(2),
+		"error":     "timeout",
+	}, entries[0].ContextMap())
+}
+
+func TestLogrusLogger(t *testing.T) {
+	logrusLogger, hook := logrustest.NewNullLogger()
+	logrusLogger.SetLevel(logrus.DebugLevel)
+	logger := NewLogrusLogger(logrusLogger, InfoLevel)
+
+	logger.Debug("skipped")
+	logger.With(String("component", "engine")).Error("fetch failed", Int("attempt", 2))
+
+	require.Len(t, hook.AllEntries(), 1)
+	entry := hook.LastEntry()
+	assert.Equal(t, logrus.ErrorLevel, entry.Level)
+	assert.Equal(t, "fetch failed", entry.Message)
+	assert.Equal(t, logrus.Fields{"component": "engine", "attempt": 2}, entry.Data)
+}
+
+func TestLevelLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	levelLogger := NewLevelLogger(NewZapLogger(zap.New(core), DebugLevel), ErrorLevel)
+
+	levelLogger.Printf("connection lost: %s", "broker")
+	levelLogger.Println("reconnecting")
+
+	entries := logs.AllUntimed()
+	require.Len(t, entries, 2)
+	assert.Equal(t, zapcore.ErrorLevel, entries[0].Level)
+	assert.Equal(t, "connection lost: broker", entries[0].Message)
+	assert.Equal(t, "reconnecting", entries[1].Message)
+}
+
+func TestNoopLogger(t *testing.T) {
+	assert.NotPanics(t, func() {
+		NoopLogger.With(String("component", "engine")).Error("fetch failed", Error(errors.New("timeout")))
+	})
+}
@@ -0,0 +1,61 @@
+package logging
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// NewLogrusLogger returns a Logger which logs the entries of the level and above to the logrus logger
+func NewLogrusLogger(logger logrus.FieldLogger, level Level) Logger {
+	return &logrusLogger{
+		l:     logger,
+		level: level,
+	}
+}
+
+type logrusLogger struct {
+	l     logrus.FieldLogger
+	level Level
+}
+
+func (l *logrusLogger) Debug(msg string, fields ...Field) {
+	if !l.level.enabled(DebugLevel) {
+		return
+	}
+	l.l.WithFields(logrusFields(fields)).Debug(msg)
+}
+
+func (l *logrusLogger) Info(msg string, fields ...Field) {
+	if !l.level.enabled(InfoLevel) {
+		return
+	}
+	l.l.WithFields(logrusFields(fields)).Info(msg)
+}
+
+func (l *logrusLogger) Warn(msg string, fields ...Field) {
+	if !l.level.enabled(WarnLevel) {
+		return
+	}
+	l.l.WithFields(logrusFields(fields)).Warn(msg)
+}
+
+func (l *logrusLogger) Error(msg string, fields ...Field) {
+	if !l.level.enabled(ErrorLevel) {
+		return
+	}
+	l.l.WithFields(logrusFields(fields)).Error(msg)
+}
+
+func (l *logrusLogger) With(fields ...Field) Logger {
+	return &logrusLogger{
+		l:     l.l.WithFields(logrusFields(fields)),
+		level: l.level,
+	}
+}
+
+func logrusFields(fields []Field) logrus.Fields {
+	out := make(logrus.Fields, len(fields))
+	for i := range fields {
+		out[fields[i].Key] = fields[i].Value
+	}
+	return out
+}
@@ -0,0 +1,59 @@
+//go:build go1.21
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+// NewSlogLogger returns a Logger which logs the entries of the level and above to the slog logger
+func NewSlogLogger(logger *slog.Logger, level Level) Logger {
+	return &slogLogger{
+		l:     logger,
+		level: level,
+	}
+}
+
+type slogLogger struct {
+	l     *slog.Logger
+	level Level
+}
+
+func (s *slogLogger) Debug(msg string, fields ...Field) {
+	s.log(DebugLevel, slog.LevelDebug, msg, fields)
+}
+
+func (s *slogLogger) Info(msg string, fields ...Field) {
+	s.log(InfoLevel, slog.LevelInfo, msg, fields)
+}
+
+func (s *slogLogger) Warn(msg string, fields ...Field) {
+	s.log(WarnLevel, slog.LevelWarn, msg, fields)
+}
+
+func (s *slogLogger) Error(msg string, fields ...Field) {
+	s.log(ErrorLevel, slog.LevelError, msg, fields)
+}
+
+func (s *slogLogger) With(fields ...Field) Logger {
+	return &slogLogger{
+		l:     slog.New(s.l.Handler().WithAttrs(slogAttrs(fields))),
+		level: s.level,
+	}
+}
+
+func (s *slogLogger) log(level Level, slogLevel slog.Level, msg string, fields []Field) {
+	if !s.level.enabled(level) {
+		return
+	}
+	s.l.LogAttrs(context.Background(), slogLevel, msg, slogAttrs(fields)...)
+}
+
+func slogAttrs(fields []Field) []slog.Attr {
+	out := make([]slog.Attr, len(fields))
+	for i := range fields {
+		out[i] = slog.Any(fields[i].Key, fields[i].Value)
+	}
+	return out
+}
@@ -0,0 +1,31 @@
+//go:build go1.21
+
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogLogger(t *testing.T) {
+	buf := &bytes.Buffer{}
+	handler := slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	})
+	logger := NewSlogLogger(slog.New(handler), InfoLevel)
+
+	logger.Debug("skipped")
+	logger.With(String("component", "engine")).Warn("fetch failed", Int("attempt", 2), Error(errors.New("timeout")))
+
+	assert.Equal(t, `{"level":"WARN","msg":"fetch failed","component":"engine","attempt":2,"error":"timeout"}`+"\n", buf.String())
+}
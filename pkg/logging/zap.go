@@ -0,0 +1,84 @@
+package logging
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// NewZapLogger returns a Logger which logs the entries of the level and above to the zap logger
+func NewZapLogger(logger *zap.Logger, level Level) Logger {
+	return &zapLogger{
+		l:     logger,
+		level: level,
+	}
+}
+
+type zapLogger struct {
+	l     *zap.Logger
+	level Level
+}
+
+func (z *zapLogger) Debug(msg string, fields ...Field) {
+	if !z.level.enabled(DebugLevel) {
+		return
+	}
+	z.l.Debug(msg, zapFields(fields)...)
+}
+
+func (z *zapLogger) Info(msg string, fields ...Field) {
+	if !z.level.enabled(InfoLevel) {
+		return
+	}
+	z.l.Info(msg, zapFields(fields)...)
+}
+
+func (z *zapLogger) Warn(msg string, fields ...Field) {
+	if !z.level.enabled(WarnLevel) {
+		return
+	}
+	z.l.Warn(msg, zapFields(fields)...)
+}
+
+func (z *zapLogger) Error(msg string, fields ...Field) {
+	if !z.level.enabled(ErrorLevel) {
+		return
+	}
+	z.l.Error(msg, zapFields(fields)...)
+}
+
+func (z *zapLogger) With(fields ...Field) Logger {
+	return &zapLogger{
+		l:     z.l.With(zapFields(fields)...),
+		level: z.level,
+	}
+}
+
+func zapFields(fields []Field) []zap.Field {
+	out := make([]zap.Field, len(fields))
+	for i := range fields {
+		out[i] = zapField(fields[i])
+	}
+	return out
+}
+
+func zapField(field Field) zap.Field {
+	switch value := field.Value.(type) {
+	case string:
+		return zap.String(field.Key, value)
+	case []string:
+		return zap.Strings(field.Key, value)
+	case int:
+		return zap.Int(field.Key, value)
+	case int64:
+		return zap.Int64(field.Key, value)
+	case bool:
+		return zap.Bool(field.Key, value)
+	case time.Duration:
+		return zap.Duration(field.Key, value)
+	case error:
+		return zap.NamedError(field.Key, value)
+	default:
+		return zap.Any(field.Key, value)
+	}
+}
@@ -14,7 +14,7 @@
 	- directive @nodeCountSkip on FIELD
 
 	nodeCountMultiply:
-	Indicates that the Int value the directive is applied on should be used as a Node multiplier
+	Indicates that the Int value the directive is applied on should be used as a Node multiplier, the value might be passed as variable
 
 	nodeCountSkip:
 	Indicates that the algorithm should skip this Node. This is useful to whitelist certain query paths, e.g. for introspection.
@@ -22,6 +22,8 @@
 package operation_complexity
 
 import (
+	"github.com/buger/jsonparser"
+
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
 	"github.com/wundergraph/graphql-go-tools/pkg/astvisitor"
 	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
@@ -168,12 +170,23 @@ func (c *complexityVisitor) EnterArgument(ref int) {
 	}
 
 	value := c.operation.ArgumentValue(ref)
-	if value.Kind == ast.ValueKindInteger {
+	switch value.Kind {
+	case ast.ValueKindInteger:
 		multi := c.operation.IntValueAsInt32(value.Ref)
 		c.multipliers = append(c.multipliers, multiplier{
 			fieldRef: c.Ancestors[len(c.Ancestors)-1].Ref,
 			multi:    int(multi),
 		})
+	case ast.ValueKindVariable:
+		// normalized operations pass the arguments as variables
+		multi, err := jsonparser.GetInt(c.operation.Input.Variables, c.operation.VariableValueNameString(value.Ref))
+		if err != nil {
+			return
+		}
+		c.multipliers = append(c.multipliers, multiplier{
+			fieldRef: c.Ancestors[len(c.Ancestors)-1].Ref,
+			multi:    int(multi),
+		})
 	}
 }
 
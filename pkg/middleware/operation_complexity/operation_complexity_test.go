@@ -447,6 +447,25 @@ func TestCalculateOperationComplexity(t *testing.T) {
 			},
 		)
 	})
+	t.Run("multiple users with the multiplier as variable", func(t *testing.T) {
+		def := unsafeparser.ParseGraphqlDocumentString(testDefinition)
+		op := unsafeparser.ParseGraphqlDocumentString(`
+				query Users($first: Int!) {
+				  users(first: $first) {
+					id
+					address {
+					  city
+					}
+				  }
+				}`)
+		op.Input.Variables = []byte(`{"first":10}`)
+		report := operationreport.Report{}
+
+		astnormalization.NormalizeOperation(&op, &def, &report)
+		globalComplexityResult, _ := CalculateOperationComplexity(&op, &def, &report)
+		require.False(t, report.HasErrors(), report.Error())
+		assert.Equal(t, OperationStats{NodeCount: 20, Complexity: 11, Depth: 3}, globalComplexityResult)
+	})
 	t.Run("introspection query", func(t *testing.T) {
 		run(t, testDefinition, introspectionQuery,
 			OperationStats{
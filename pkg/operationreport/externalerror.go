@@ -2,6 +2,7 @@ package operationreport
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
 	"github.com/wundergraph/graphql-go-tools/pkg/graphqlerrors"
@@ -27,12 +28,19 @@ const (
 	UnknownFieldOfInputObjectErrMsg         = `Field "%s" is not defined by type "%s".`
 	DuplicatedFieldInputObjectErrMsg        = `There can be only one input field named "%s".`
 	ValueIsNotAnInputObjectTypeErrMsg       = `Expected value of type "%s", found %s.`
+	VariableValueNotProvidedErrMsg          = `Variable "$%s" of required type "%s" was not provided.`
+	VariableValueNullErrMsg                 = `Variable "$%s" of non-null type "%s" must not be null.`
+	InvalidVariableValueErrMsg              = `Variable "$%s" got invalid value %s; %s`
+	InvalidVariableValueAtPathErrMsg        = `Variable "$%s" got invalid value %s at "%s"; %s`
+	VariablesNotAnObjectErrMsg              = `Variables must be a JSON object, found %s.`
 )
 
 type ExternalError struct {
 	Message   string                   `json:"message"`
 	Path      ast.Path                 `json:"path"`
 	Locations []graphqlerrors.Location `json:"locations"`
+	// Suggestions are the names similar to a misspelled name of the operation, e.g. of an unknown field, see WithSuggestions
+	Suggestions []string `json:"suggestions,omitempty"`
 }
 
 func LocationsFromPosition(position position.Position) []graphqlerrors.Location {
@@ -326,6 +334,56 @@ func ErrVariableOfTypeIsNoValidInputValue(variableName, ofTypeName ast.ByteSlice
 	return err
 }
 
+func ErrVariableValueNotProvided(variableName, typeName ast.ByteSlice, position position.Position) (err ExternalError) {
+	err.Message = fmt.Sprintf(VariableValueNotProvidedErrMsg, variableName, typeName)
+	err.Locations = LocationsFromPosition(position)
+
+	return err
+}
+
+func ErrVariableValueMustNotBeNull(variableName, typeName ast.ByteSlice, position position.Position) (err ExternalError) {
+	err.Message = fmt.Sprintf(VariableValueNullErrMsg, variableName, typeName)
+	err.Locations = LocationsFromPosition(position)
+
+	return err
+}
+
+// ErrInvalidVariableValue reports a variable value which can't be coerced to the type of the variable,
+// path starts with the variable name and points to the invalid value within the variable value
+func ErrInvalidVariableValue(variableName ast.ByteSlice, value, reason string, path ast.Path, position position.Position) (err ExternalError) {
+	if len(path) > 1 {
+		err.Message = fmt.Sprintf(InvalidVariableValueAtPathErrMsg, variableName, value, variableValuePath(path), reason)
+	} else {
+		err.Message = fmt.Sprintf(InvalidVariableValueErrMsg, variableName, value, reason)
+	}
+	err.Path = path
+	err.Locations = LocationsFromPosition(position)
+
+	return err
+}
+
+func ErrVariablesNotAnObject(value string) (err ExternalError) {
+	err.Message = fmt.Sprintf(VariablesNotAnObjectErrMsg, value)
+	return err
+}
+
+// variableValuePath prints a path within a variable value, e.g. input.items[0].name
+func variableValuePath(path ast.Path) string {
+	out := ""
+	for i := range path {
+		switch path[i].Kind {
+		case ast.ArrayIndex:
+			out += "[" + strconv.Itoa(path[i].ArrayIndex) + "]"
+		case ast.FieldName:
+			if i != 0 {
+				out += "."
+			}
+			out += string(path[i].FieldName)
+		}
+	}
+	return out
+}
+
 func ErrArgumentMustBeUnique(argName ast.ByteSlice) (err ExternalError) {
 	err.Message = fmt.Sprintf("argument: %s must be unique", argName)
 	return err
@@ -376,6 +434,16 @@ func ErrFragmentDefinitionMustBeUnique(fragmentName ast.ByteSlice) (err External
 	return err
 }
 
+func ErrFragmentArgumentNotDefined(argName, fragmentName ast.ByteSlice) (err ExternalError) {
+	err.Message = fmt.Sprintf("argument: %s is not defined on fragment: %s", argName, fragmentName)
+	return err
+}
+
+func ErrFragmentArgumentRequired(argName, fragmentName ast.ByteSlice) (err ExternalError) {
+	err.Message = fmt.Sprintf("argument: %s is required on fragment: %s but missing", argName, fragmentName)
+	return err
+}
+
 func ErrDirectiveUndefined(directiveName ast.ByteSlice) (err ExternalError) {
 	err.Message = fmt.Sprintf("directive: %s undefined", directiveName)
 	return err
@@ -441,6 +509,11 @@ func ErrSharedTypesMustBeIdenticalToFederate(typeName string) (err ExternalError
 	return err
 }
 
+func ErrSharedTypesConflict(typeName, conflict string) (err ExternalError) {
+	err.Message = fmt.Sprintf("the shared type named '%s' must be identical in any subgraphs to federate: %s", typeName, conflict)
+	return err
+}
+
 func ErrEntitiesMustNotBeDuplicated(typeName string) (err ExternalError) {
 	err.Message = fmt.Sprintf("the entity named '%s' is defined in the subgraph(s) more than once", typeName)
 	return err
@@ -470,3 +543,18 @@ func ErrExtensionWithKeyDirectiveMustExtendEntity(typeName string) (err External
 	err.Message = fmt.Sprintf("the extension named '%s' has a key directive but there is no entity of the same name", typeName)
 	return err
 }
+
+func ErrRelayConnectionSpecViolation(coordinate, violation string) (err ExternalError) {
+	err.Message = fmt.Sprintf("'%s' violates the relay cursor connections spec: %s", coordinate, violation)
+	return err
+}
+
+func ErrRootOperationTypeUndefined(operationType, typeName string) (err ExternalError) {
+	err.Message = fmt.Sprintf("the %s root operation type named '%s' is undefined", operationType, typeName)
+	return err
+}
+
+func ErrInvalidFieldSetOfDirective(directiveName, coordinate string, reason error) (err ExternalError) {
+	err.Message = fmt.Sprintf("the fields argument of the @%s directive of '%s' is invalid: %s", directiveName, coordinate, reason)
+	return err
+}
@@ -0,0 +1,122 @@
+package operationreport
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is the maximum number of suggestions of an error, like graphql-js lists at most 5 suggestions
+const maxSuggestions = 5
+
+// WithSuggestions sets the options which are similar to the misspelled input as suggestions of the error, see SuggestionList
+func (e ExternalError) WithSuggestions(input string, options []string) ExternalError {
+	suggestions := SuggestionList(input, options)
+	if len(suggestions) == 0 {
+		return e
+	}
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	e.Suggestions = suggestions
+	return e
+}
+
+// SuggestionList returns the options which are similar to the input, the most similar options first.
+// Like the suggestionList of graphql-js, options are similar if their case-insensitive edit distance (optimal string alignment)
+// is at most 40% of the length of the input, options differing only in case are the most similar.
+func SuggestionList(input string, options []string) []string {
+	threshold := len(input)*4/10 + 1
+	inputLower := strings.ToLower(input)
+
+	distances := make(map[string]int, len(options))
+	suggestions := make([]string, 0, len(options))
+	for _, option := range options {
+		if _, ok := distances[option]; ok {
+			continue
+		}
+		distance, ok := lexicalDistance(input, inputLower, option, threshold)
+		if !ok {
+			continue
+		}
+		distances[option] = distance
+		suggestions = append(suggestions, option)
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if distances[suggestions[i]] != distances[suggestions[j]] {
+			return distances[suggestions[i]] < distances[suggestions[j]]
+		}
+		return suggestions[i] < suggestions[j]
+	})
+	return suggestions
+}
+
+// lexicalDistance returns the edit distance of the option to the input, false if it's above the threshold
+func lexicalDistance(input, inputLower, option string, threshold int) (int, bool) {
+	if input == option {
+		return 0, true
+	}
+	optionLower := strings.ToLower(option)
+	if inputLower == optionLower {
+		return 1, true
+	}
+
+	a, b := []rune(optionLower), []rune(inputLower)
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	if len(a)-len(b) > threshold {
+		return 0, false
+	}
+
+	rows := [3][]int{}
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+	}
+	for j := 0; j <= len(b); j++ {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		upRow := rows[(i-1)%3]
+		currentRow := rows[i%3]
+		currentRow[0] = i
+		smallestCell := i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			currentCell := minInt(upRow[j]+1, currentRow[j-1]+1, upRow[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				// transposition
+				doubleDiagonalCell := rows[(i-2)%3][j-2]
+				currentCell = minInt(currentCell, doubleDiagonalCell+1)
+			}
+			if currentCell < smallestCell {
+				smallestCell = currentCell
+			}
+			currentRow[j] = currentCell
+		}
+		// early exit, as distances only grow with the following rows
+		if smallestCell > threshold {
+			return 0, false
+		}
+	}
+
+	distance := rows[len(a)%3][len(b)]
+	if distance > threshold {
+		return 0, false
+	}
+	return distance, true
+}
+
+func minInt(first int, others ...int) int {
+	out := first
+	for _, other := range others {
+		if other < out {
+			out = other
+		}
+	}
+	return out
+}
@@ -0,0 +1,35 @@
+package operationreport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuggestionList(t *testing.T) {
+	t.Run("returns similar options, the most similar first", func(t *testing.T) {
+		assert.Equal(t, []string{"name", "names", "nme"}, SuggestionList("nam", []string{"nme", "names", "name", "id", "friends"}))
+	})
+	t.Run("options differing in case are the most similar", func(t *testing.T) {
+		assert.Equal(t, []string{"FRIENDS", "friend"}, SuggestionList("friends", []string{"friend", "FRIENDS"}))
+	})
+	t.Run("transpositions are a single edit", func(t *testing.T) {
+		assert.Equal(t, []string{"hero"}, SuggestionList("hreo", []string{"hero"}))
+	})
+	t.Run("returns no options for dissimilar input", func(t *testing.T) {
+		assert.Empty(t, SuggestionList("droid", []string{"human", "starship"}))
+		assert.Empty(t, SuggestionList("a", []string{"bcd"}))
+	})
+	t.Run("options are returned once", func(t *testing.T) {
+		assert.Equal(t, []string{"hero"}, SuggestionList("her", []string{"hero", "hero"}))
+	})
+}
+
+func TestExternalError_WithSuggestions(t *testing.T) {
+	err := ErrFieldUndefinedOnType([]byte("fiel"), []byte("Query")).
+		WithSuggestions("fiel", []string{"field1", "field2", "field3", "field4", "field5", "field6", "other"})
+
+	assert.Equal(t, "field: fiel not defined on type: Query", err.Message)
+	assert.Equal(t, []string{"field1", "field2", "field3", "field4", "field5"}, err.Suggestions)
+	assert.Nil(t, ErrTypeUndefined([]byte("Foo")).WithSuggestions("Foo", []string{"Query"}).Suggestions)
+}
@@ -52,6 +52,10 @@ func (d *ProcessDataSource) traverseFetch(fetch resolve.Fetch) {
 		for i := range f.Fetches {
 			d.traverseFetch(f.Fetches[i])
 		}
+	case *resolve.SerialFetch:
+		for i := range f.Fetches {
+			d.traverseFetch(f.Fetches[i])
+		}
 	}
 }
 
@@ -190,6 +190,8 @@ func TestDefaultProcessor_Process(t *testing.T) {
 	processor := DefaultProcessor()
 	actual := processor.Process(original)
 
+	// the response templates are precompiled last
+	(&ProcessResponseTemplate{}).Process(expected)
 	assert.Equal(t, expected, actual)
 }
 
@@ -433,5 +435,6 @@ func TestDefaultProcessor_Federation(t *testing.T) {
 
 	processor := DefaultProcessor()
 	actual := processor.Process(pre)
+	(&ProcessResponseTemplate{}).Process(expected)
 	assert.Equal(t, expected, actual)
 }
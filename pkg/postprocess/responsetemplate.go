@@ -0,0 +1,37 @@
+package postprocess
+
+import (
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
+	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
+)
+
+// ProcessResponseTemplate precompiles the JSON skeleton of all objects of the plan with a static shape,
+// see resolve.Object.PrecompileTemplate. It has to run after all post processors changing the fields of objects.
+type ProcessResponseTemplate struct{}
+
+func (p *ProcessResponseTemplate) Process(pre plan.Plan) plan.Plan {
+	switch t := pre.(type) {
+	case *plan.SynchronousResponsePlan:
+		p.traverseNode(t.Response.Data)
+	case *plan.StreamingResponsePlan:
+		p.traverseNode(t.Response.InitialResponse.Data)
+		for i := range t.Response.Patches {
+			p.traverseNode(t.Response.Patches[i].Value)
+		}
+	case *plan.SubscriptionResponsePlan:
+		p.traverseNode(t.Response.Response.Data)
+	}
+	return pre
+}
+
+func (p *ProcessResponseTemplate) traverseNode(node resolve.Node) {
+	switch n := node.(type) {
+	case *resolve.Object:
+		n.PrecompileTemplate()
+		for i := range n.Fields {
+			p.traverseNode(n.Fields[i].Value)
+		}
+	case *resolve.Array:
+		p.traverseNode(n.Item)
+	}
+}
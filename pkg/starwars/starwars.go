@@ -6,11 +6,11 @@ import (
 	"path"
 	"testing"
 
-	"github.com/jensneuse/abstractlogger"
 	"github.com/stretchr/testify/require"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/execution"
 	"github.com/wundergraph/graphql-go-tools/pkg/execution/datasource"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 type QueryVariables map[string]interface{}
@@ -48,7 +48,7 @@ func SetRelativePathToStarWarsPackage(path string) {
 }
 
 func NewExecutionHandler(t *testing.T) *execution.Handler {
-	base, err := datasource.NewBaseDataSourcePlanner(Schema(t), datasource.PlannerConfiguration{}, abstractlogger.NoopLogger)
+	base, err := datasource.NewBaseDataSourcePlanner(Schema(t), datasource.PlannerConfiguration{}, logging.NoopLogger)
 	require.NoError(t, err)
 	executionHandler := execution.NewHandler(base, nil)
 	return executionHandler
@@ -3,6 +3,8 @@ package subscription
 import (
 	"context"
 	"net/http"
+	"sync"
+	"time"
 )
 
 type InitialHttpRequestContext struct {
@@ -17,27 +19,157 @@ func NewInitialHttpRequestContext(r *http.Request) *InitialHttpRequestContext {
 	}
 }
 
-type subscriptionCancellations map[string]context.CancelFunc
+// connectionContext is the context of a websocket connection.
+// It's derived from the context returned by the init func for the latest connection_init or connection_update message,
+// so that running operations see refreshed values, e.g. an updated token, and are cancelled together with it,
+// e.g. when the deadline of a token set by the init func expires.
+// It's cancelled together with the connection as well and falls back to the values of the connection.
+type connectionContext struct {
+	parent context.Context
+	done   chan struct{}
 
-func (sc subscriptionCancellations) AddWithParent(id string, parent context.Context) context.Context {
+	mu     sync.RWMutex
+	values context.Context
+	err    error
+	// unwatch stops watching the values of the previous init func result
+	unwatch chan struct{}
+}
+
+func newConnectionContext(parent context.Context, values context.Context) *connectionContext {
+	c := &connectionContext{
+		parent: parent,
+		done:   make(chan struct{}),
+	}
+	c.refresh(values)
+	return c
+}
+
+func (c *connectionContext) Deadline() (deadline time.Time, ok bool) {
+	deadline, ok = c.parent.Deadline()
+	c.mu.RLock()
+	valuesDeadline, valuesOk := c.values.Deadline()
+	c.mu.RUnlock()
+	if valuesOk && (!ok || valuesDeadline.Before(deadline)) {
+		return valuesDeadline, true
+	}
+	return deadline, ok
+}
+
+func (c *connectionContext) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *connectionContext) Err() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.err
+}
+
+func (c *connectionContext) Value(key interface{}) interface{} {
+	c.mu.RLock()
+	values := c.values
+	c.mu.RUnlock()
+	if value := values.Value(key); value != nil {
+		return value
+	}
+	return c.parent.Value(key)
+}
+
+// refresh replaces the values of the connection, values must not be derived from the connection context itself
+func (c *connectionContext) refresh(values context.Context) {
+	unwatch := make(chan struct{})
+
+	c.mu.Lock()
+	if c.unwatch != nil {
+		close(c.unwatch)
+	}
+	c.values = values
+	c.unwatch = unwatch
+	// contexts which are already done cancel the connection context right away instead of eventually
+	if err := c.parent.Err(); err != nil {
+		c.cancelLocked(err)
+	} else if err := values.Err(); err != nil {
+		c.cancelLocked(err)
+	}
+	c.mu.Unlock()
+
+	go func() {
+		select {
+		case <-c.parent.Done():
+			c.cancel(c.parent.Err())
+		case <-values.Done():
+			c.cancelWatched(unwatch, values.Err())
+		case <-unwatch:
+		case <-c.done:
+		}
+	}()
+}
+
+func (c *connectionContext) cancel(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cancelLocked(err)
+}
+
+// cancelWatched cancels the connection context unless the watched values were refreshed in the meantime
+func (c *connectionContext) cancelWatched(unwatch chan struct{}, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.unwatch != unwatch {
+		return
+	}
+	c.cancelLocked(err)
+}
+
+func (c *connectionContext) cancelLocked(err error) {
+	if c.err != nil {
+		return
+	}
+	c.err = err
+	close(c.done)
+}
+
+// subscriptionCancellations holds the cancellation funcs of the active subscriptions,
+// subscriptions completed by their upstream remove themselves concurrently to the handler
+type subscriptionCancellations struct {
+	mu            sync.Mutex
+	cancellations map[string]context.CancelFunc
+}
+
+func (sc *subscriptionCancellations) AddWithParent(id string, parent context.Context) context.Context {
 	ctx, cancelFunc := context.WithCancel(parent)
-	sc[id] = cancelFunc
+	sc.mu.Lock()
+	if sc.cancellations == nil {
+		sc.cancellations = map[string]context.CancelFunc{}
+	}
+	sc.cancellations[id] = cancelFunc
+	sc.mu.Unlock()
 	return ctx
 }
 
-func (sc subscriptionCancellations) Cancel(id string) (ok bool) {
-	cancelFunc, ok := sc[id]
+func (sc *subscriptionCancellations) Cancel(id string) (ok bool) {
+	sc.mu.Lock()
+	cancelFunc, ok := sc.cancellations[id]
+	delete(sc.cancellations, id)
+	sc.mu.Unlock()
 	if !ok {
 		return false
 	}
 
 	cancelFunc()
-	delete(sc, id)
 	return true
 }
 
-func (sc subscriptionCancellations) CancelAll() {
-	for _, cancelFunc := range sc {
+func (sc *subscriptionCancellations) CancelAll() {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	for _, cancelFunc := range sc.cancellations {
 		cancelFunc()
 	}
 }
+
+func (sc *subscriptionCancellations) Len() int {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return len(sc.cancellations)
+}
@@ -23,20 +23,82 @@ func TestNewInitialHttpRequestContext(t *testing.T) {
 	assert.Equal(t, req, initialReqCtx.Request)
 }
 
+func TestConnectionContext(t *testing.T) {
+	type key struct{}
+	type parentKey struct{}
+
+	t.Run("values are refreshed and fall back to the connection", func(t *testing.T) {
+		ctx, cancelFn := context.WithCancel(context.WithValue(context.Background(), parentKey{}, "parent"))
+		connectionCtx := newConnectionContext(ctx, context.WithValue(context.Background(), key{}, "initial"))
+		operationCtx, operationCancelFn := context.WithCancel(connectionCtx)
+		defer operationCancelFn()
+
+		assert.Equal(t, "initial", operationCtx.Value(key{}))
+		assert.Equal(t, "parent", operationCtx.Value(parentKey{}))
+
+		connectionCtx.refresh(context.WithValue(ctx, key{}, "refreshed"))
+		assert.Equal(t, "refreshed", operationCtx.Value(key{}), "running operations see refreshed values")
+
+		cancelFn()
+		assert.Eventually(t, func() bool {
+			<-operationCtx.Done()
+			return true
+		}, time.Second, 5*time.Millisecond)
+		assert.ErrorIs(t, connectionCtx.Err(), context.Canceled)
+	})
+
+	t.Run("is cancelled together with the context of the init func", func(t *testing.T) {
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+		deadline := time.Now().Add(50 * time.Millisecond)
+		valuesCtx, valuesCancelFn := context.WithDeadline(ctx, deadline)
+		defer valuesCancelFn()
+
+		connectionCtx := newConnectionContext(ctx, valuesCtx)
+		operationCtx, operationCancelFn := context.WithCancel(connectionCtx)
+		defer operationCancelFn()
+
+		gotDeadline, ok := operationCtx.Deadline()
+		assert.True(t, ok)
+		assert.Equal(t, deadline, gotDeadline)
+
+		assert.Eventually(t, func() bool {
+			<-operationCtx.Done()
+			return true
+		}, time.Second, 5*time.Millisecond)
+		assert.ErrorIs(t, connectionCtx.Err(), context.DeadlineExceeded)
+	})
+
+	t.Run("refreshed values replace the previous context of the init func", func(t *testing.T) {
+		ctx, cancelFn := context.WithCancel(context.Background())
+		defer cancelFn()
+		previousCtx, previousCancelFn := context.WithCancel(ctx)
+
+		connectionCtx := newConnectionContext(ctx, previousCtx)
+		connectionCtx.refresh(ctx)
+		previousCancelFn()
+
+		time.Sleep(10 * time.Millisecond)
+		assert.NoError(t, connectionCtx.Err())
+		_, ok := connectionCtx.Deadline()
+		assert.False(t, ok)
+	})
+}
+
 func TestSubscriptionCancellations(t *testing.T) {
 	cancellations := subscriptionCancellations{}
 	var ctx context.Context
 
 	t.Run("should add a cancellation func to map", func(t *testing.T) {
-		require.Equal(t, 0, len(cancellations))
+		require.Equal(t, 0, cancellations.Len())
 
 		ctx = cancellations.AddWithParent("1", context.Background())
-		assert.Equal(t, 1, len(cancellations))
+		assert.Equal(t, 1, cancellations.Len())
 		assert.NotNil(t, ctx)
 	})
 
 	t.Run("should execute cancellation from map", func(t *testing.T) {
-		require.Equal(t, 1, len(cancellations))
+		require.Equal(t, 1, cancellations.Len())
 		ctxTestFunc := func() bool {
 			<-ctx.Done()
 			return true
@@ -45,6 +107,6 @@ func TestSubscriptionCancellations(t *testing.T) {
 		ok := cancellations.Cancel("1")
 		assert.Eventually(t, ctxTestFunc, time.Second, 5*time.Millisecond)
 		assert.True(t, ok)
-		assert.Equal(t, 0, len(cancellations))
+		assert.Equal(t, 0, cancellations.Len())
 	})
 }
@@ -61,7 +61,7 @@ func (e *ExecutorV2) Execute(writer resolve.FlushWriter) error {
 	options := make([]graphql.ExecutionOptionsV2, 0)
 	switch ctx := e.reqCtx.(type) {
 	case *InitialHttpRequestContext:
-		options = append(options, graphql.WithAdditionalHttpHeaders(ctx.Request.Header))
+		options = append(options, graphql.WithAdditionalHttpHeaders(ctx.Request.Header), graphql.WithClient(ctx.Request.RemoteAddr))
 	}
 
 	return e.engine.Execute(e.context, e.operation, writer, options...)
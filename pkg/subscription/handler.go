@@ -4,18 +4,19 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"sync"
 	"time"
 
-	"github.com/jensneuse/abstractlogger"
-
 	"github.com/wundergraph/graphql-go-tools/pkg/ast"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/resolve"
 	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 const (
 	MessageTypeConnectionInit      = "connection_init"
+	MessageTypeConnectionUpdate    = "connection_update"
 	MessageTypeConnectionAck       = "connection_ack"
 	MessageTypeConnectionError     = "connection_error"
 	MessageTypeConnectionTerminate = "connection_terminate"
@@ -65,11 +66,12 @@ type Executor interface {
 
 // WebsocketInitFunc is called when the server receives connection init message from the client.
 // This can be used to check initial payload to see whether to accept the websocket connection.
+// It's called again for connection update messages, e.g. with a refreshed token, to re-validate the connection.
 type WebsocketInitFunc func(ctx context.Context, initPayload InitPayload) (context.Context, error)
 
 // Handler is the actual subscription handler which will keep track on how to handle messages coming from the client.
 type Handler struct {
-	logger abstractlogger.Logger
+	logger logging.Logger
 	// client will hold the subscription client implementation.
 	client Client
 	// keepAliveInterval is the actual interval on which the server send keep alive messages to the client.
@@ -84,10 +86,12 @@ type Handler struct {
 	bufferPool *sync.Pool
 	// initFunc will check initial payload to see whether to accept the websocket connection.
 	initFunc WebsocketInitFunc
+	// connectionCtx holds the context of the initialized connection, which is updated by connection_update messages.
+	connectionCtx *connectionContext
 }
 
 func NewHandlerWithInitFunc(
-	logger abstractlogger.Logger,
+	logger logging.Logger,
 	client Client,
 	executorPool ExecutorPool,
 	initFunc WebsocketInitFunc,
@@ -107,7 +111,6 @@ func NewHandlerWithInitFunc(
 		client:                     client,
 		keepAliveInterval:          keepAliveInterval,
 		subscriptionUpdateInterval: subscriptionUpdateInterval,
-		subCancellations:           subscriptionCancellations{},
 		executorPool:               executorPool,
 		bufferPool: &sync.Pool{
 			New: func() interface{} {
@@ -120,20 +123,23 @@ func NewHandlerWithInitFunc(
 }
 
 // NewHandler creates a new subscription handler.
-func NewHandler(logger abstractlogger.Logger, client Client, executorPool ExecutorPool) (*Handler, error) {
+func NewHandler(logger logging.Logger, client Client, executorPool ExecutorPool) (*Handler, error) {
 	return NewHandlerWithInitFunc(logger, client, executorPool, nil)
 }
 
 // Handle will handle the subscription connection.
 func (h *Handler) Handle(ctx context.Context) {
+	// queries and mutations in flight are cancelled together with the subscriptions once the connection is gone
+	ctx, cancel := context.WithCancel(ctx)
 	defer func() {
 		h.subCancellations.CancelAll()
+		cancel()
 	}()
 
 	for {
 		if !h.client.IsConnected() {
 			h.logger.Debug("subscription.Handler.Handle()",
-				abstractlogger.String("message", "client has disconnected"),
+				logging.String("message", "client has disconnected"),
 			)
 
 			return
@@ -142,8 +148,8 @@ func (h *Handler) Handle(ctx context.Context) {
 		message, err := h.client.ReadFromClient()
 		if err != nil {
 			h.logger.Error("subscription.Handler.Handle()",
-				abstractlogger.Error(err),
-				abstractlogger.Any("message", message),
+				logging.Error(err),
+				logging.Any("message", message),
 			)
 
 			h.handleConnectionError("could not read message from client")
@@ -157,6 +163,11 @@ func (h *Handler) Handle(ctx context.Context) {
 				}
 
 				go h.handleKeepAlive(ctx)
+			case MessageTypeConnectionUpdate:
+				if err = h.handleUpdate(message.Payload); err != nil {
+					h.terminateConnection("failed to update the websocket connection")
+					return
+				}
 			case MessageTypeStart:
 				h.handleStart(ctx, message.Id, message.Payload)
 			case MessageTypeStop:
@@ -188,29 +199,59 @@ func (h *Handler) ChangeSubscriptionUpdateInterval(d time.Duration) {
 
 // handleInit will handle an init message.
 func (h *Handler) handleInit(ctx context.Context, payload []byte) (extendedCtx context.Context, err error) {
-	if h.initFunc != nil {
-		var initPayload InitPayload
-		// decode initial payload
-		if len(payload) > 0 {
-			initPayload = payload
-		}
-		// check initial payload to see whether to accept the websocket connection
-		if extendedCtx, err = h.initFunc(ctx, initPayload); err != nil {
-			return extendedCtx, err
-		}
-	} else {
-		extendedCtx = ctx
+	extendedCtx, err = h.checkPayload(ctx, payload)
+	if err != nil {
+		return extendedCtx, err
 	}
+	h.connectionCtx = newConnectionContext(ctx, extendedCtx)
 
 	ackMessage := Message{
 		Type: MessageTypeConnectionAck,
 	}
 
 	if err = h.client.WriteToClient(ackMessage); err != nil {
-		return extendedCtx, err
+		return h.connectionCtx, err
+	}
+
+	return h.connectionCtx, nil
+}
+
+// handleUpdate will handle an update message, which re-sends the payload of the init message, e.g. with a refreshed token.
+// The context of the connection is updated without restarting running operations.
+func (h *Handler) handleUpdate(payload []byte) error {
+	if h.connectionCtx == nil {
+		return errors.New("connection is not initialized")
+	}
+
+	// the values are derived from the context the connection was initialized with, not from the current values
+	extendedCtx, err := h.checkPayload(h.connectionCtx.parent, payload)
+	if err != nil {
+		h.logger.Error("subscription.Handler.handleUpdate()",
+			logging.Error(err),
+		)
+		return err
+	}
+	h.connectionCtx.refresh(extendedCtx)
+
+	ackMessage := Message{
+		Type: MessageTypeConnectionAck,
 	}
 
-	return extendedCtx, nil
+	return h.client.WriteToClient(ackMessage)
+}
+
+// checkPayload will check the payload of an init or update message to see whether to accept the websocket connection.
+func (h *Handler) checkPayload(ctx context.Context, payload []byte) (context.Context, error) {
+	if h.initFunc == nil {
+		return ctx, nil
+	}
+
+	var initPayload InitPayload
+	// decode initial payload
+	if len(payload) > 0 {
+		initPayload = payload
+	}
+	return h.initFunc(ctx, initPayload)
 }
 
 // handleStart will handle s start message.
@@ -218,7 +259,7 @@ func (h *Handler) handleStart(ctx context.Context, id string, payload []byte) {
 	executor, err := h.executorPool.Get(payload)
 	if err != nil {
 		h.logger.Error("subscription.Handler.handleStart()",
-			abstractlogger.Error(err),
+			logging.Error(err),
 		)
 
 		h.handleError(id, graphql.RequestErrorsFromError(err))
@@ -258,7 +299,7 @@ func (h *Handler) handleNonSubscriptionOperation(ctx context.Context, id string,
 		err := h.executorPool.Put(executor)
 		if err != nil {
 			h.logger.Error("subscription.Handle.handleNonSubscriptionOperation()",
-				abstractlogger.Error(err),
+				logging.Error(err),
 			)
 		}
 	}()
@@ -273,7 +314,7 @@ func (h *Handler) handleNonSubscriptionOperation(ctx context.Context, id string,
 	err := executor.Execute(buf)
 	if err != nil {
 		h.logger.Error("subscription.Handle.handleNonSubscriptionOperation()",
-			abstractlogger.Error(err),
+			logging.Error(err),
 		)
 
 		h.handleError(id, graphql.RequestErrorsFromError(err))
@@ -281,7 +322,7 @@ func (h *Handler) handleNonSubscriptionOperation(ctx context.Context, id string,
 	}
 
 	h.logger.Debug("subscription.Handle.handleNonSubscriptionOperation()",
-		abstractlogger.ByteString("execution_result", buf.Bytes()),
+		logging.ByteString("execution_result", buf.Bytes()),
 	)
 
 	h.sendData(id, buf.Bytes())
@@ -294,7 +335,7 @@ func (h *Handler) startSubscription(ctx context.Context, id string, executor Exe
 		err := h.executorPool.Put(executor)
 		if err != nil {
 			h.logger.Error("subscription.Handle.startSubscription()",
-				abstractlogger.Error(err),
+				logging.Error(err),
 			)
 		}
 	}()
@@ -305,7 +346,16 @@ func (h *Handler) startSubscription(ctx context.Context, id string, executor Exe
 
 	defer h.bufferPool.Put(buf)
 
-	h.executeSubscription(buf, id, executor)
+	ok := h.executeSubscription(buf, id, executor)
+
+	// subscriptions of the engine v2 stream the events of the upstream, regardless of its transport,
+	// until the upstream completes the subscription, which completes the subscription of the client
+	if _, streaming := executor.(*ExecutorV2); streaming && ok {
+		if ctx.Err() == nil && h.subCancellations.Cancel(id) {
+			h.sendComplete(id)
+		}
+		return
+	}
 
 	for {
 		buf.Reset()
@@ -319,11 +369,11 @@ func (h *Handler) startSubscription(ctx context.Context, id string, executor Exe
 
 }
 
-// executeSubscription will keep execution the subscription until it ends.
-func (h *Handler) executeSubscription(buf *graphql.EngineResultWriter, id string, executor Executor) {
+// executeSubscription will keep execution the subscription until it ends and reports whether it ended without an error.
+func (h *Handler) executeSubscription(buf *graphql.EngineResultWriter, id string, executor Executor) (ok bool) {
 	buf.SetFlushCallback(func(data []byte) {
 		h.logger.Debug("subscription.Handle.executeSubscription()",
-			abstractlogger.ByteString("execution_result", data),
+			logging.ByteString("execution_result", data),
 		)
 		h.sendData(id, data)
 	})
@@ -332,20 +382,21 @@ func (h *Handler) executeSubscription(buf *graphql.EngineResultWriter, id string
 	err := executor.Execute(buf)
 	if err != nil {
 		h.logger.Error("subscription.Handle.executeSubscription()",
-			abstractlogger.Error(err),
+			logging.Error(err),
 		)
 
 		h.handleError(id, graphql.RequestErrorsFromError(err))
-		return
+		return false
 	}
 
 	if buf.Len() > 0 {
 		data := buf.Bytes()
 		h.logger.Debug("subscription.Handle.executeSubscription()",
-			abstractlogger.ByteString("execution_result", data),
+			logging.ByteString("execution_result", data),
 		)
 		h.sendData(id, data)
 	}
+	return true
 }
 
 // handleStop will handle a stop message,
@@ -365,7 +416,7 @@ func (h *Handler) sendData(id string, responseData []byte) {
 	err := h.client.WriteToClient(dataMessage)
 	if err != nil {
 		h.logger.Error("subscription.Handler.sendData()",
-			abstractlogger.Error(err),
+			logging.Error(err),
 		)
 	}
 }
@@ -382,7 +433,7 @@ func (h *Handler) sendComplete(id string) {
 	err := h.client.WriteToClient(completeMessage)
 	if err != nil {
 		h.logger.Error("subscription.Handler.sendComplete()",
-			abstractlogger.Error(err),
+			logging.Error(err),
 		)
 	}
 }
@@ -392,7 +443,7 @@ func (h *Handler) handleConnectionTerminate() {
 	err := h.client.Disconnect()
 	if err != nil {
 		h.logger.Error("subscription.Handler.handleConnectionTerminate()",
-			abstractlogger.Error(err),
+			logging.Error(err),
 		)
 	}
 }
@@ -418,7 +469,7 @@ func (h *Handler) sendKeepAlive() {
 	err := h.client.WriteToClient(keepAliveMessage)
 	if err != nil {
 		h.logger.Error("subscription.Handler.sendKeepAlive()",
-			abstractlogger.Error(err),
+			logging.Error(err),
 		)
 	}
 }
@@ -427,8 +478,8 @@ func (h *Handler) terminateConnection(reason interface{}) {
 	payloadBytes, err := json.Marshal(reason)
 	if err != nil {
 		h.logger.Error("subscription.Handler.terminateConnection()",
-			abstractlogger.Error(err),
-			abstractlogger.Any("errorPayload", reason),
+			logging.Error(err),
+			logging.Any("errorPayload", reason),
 		)
 	}
 
@@ -440,13 +491,13 @@ func (h *Handler) terminateConnection(reason interface{}) {
 	err = h.client.WriteToClient(connectionErrorMessage)
 	if err != nil {
 		h.logger.Error("subscription.Handler.terminateConnection()",
-			abstractlogger.Error(err),
+			logging.Error(err),
 		)
 
 		err := h.client.Disconnect()
 		if err != nil {
 			h.logger.Error("subscription.Handler.terminateConnection()",
-				abstractlogger.Error(err),
+				logging.Error(err),
 			)
 		}
 	}
@@ -457,8 +508,8 @@ func (h *Handler) handleConnectionError(errorPayload interface{}) {
 	payloadBytes, err := json.Marshal(errorPayload)
 	if err != nil {
 		h.logger.Error("subscription.Handler.handleConnectionError()",
-			abstractlogger.Error(err),
-			abstractlogger.Any("errorPayload", errorPayload),
+			logging.Error(err),
+			logging.Any("errorPayload", errorPayload),
 		)
 	}
 
@@ -470,13 +521,13 @@ func (h *Handler) handleConnectionError(errorPayload interface{}) {
 	err = h.client.WriteToClient(connectionErrorMessage)
 	if err != nil {
 		h.logger.Error("subscription.Handler.handleConnectionError()",
-			abstractlogger.Error(err),
+			logging.Error(err),
 		)
 
 		err := h.client.Disconnect()
 		if err != nil {
 			h.logger.Error("subscription.Handler.handleError()",
-				abstractlogger.Error(err),
+				logging.Error(err),
 			)
 		}
 	}
@@ -487,8 +538,8 @@ func (h *Handler) handleError(id string, errors graphql.RequestErrors) {
 	payloadBytes, err := json.Marshal(errors)
 	if err != nil {
 		h.logger.Error("subscription.Handler.handleError()",
-			abstractlogger.Error(err),
-			abstractlogger.Any("errors", errors),
+			logging.Error(err),
+			logging.Any("errors", errors),
 		)
 	}
 
@@ -501,12 +552,12 @@ func (h *Handler) handleError(id string, errors graphql.RequestErrors) {
 	err = h.client.WriteToClient(errorMessage)
 	if err != nil {
 		h.logger.Error("subscription.Handler.handleError()",
-			abstractlogger.Error(err),
+			logging.Error(err),
 		)
 	}
 }
 
 // ActiveSubscriptions will return the actual number of active subscriptions for that client.
 func (h *Handler) ActiveSubscriptions() int {
-	return len(h.subCancellations)
+	return h.subCancellations.Len()
 }
@@ -11,7 +11,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/jensneuse/abstractlogger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -19,6 +18,7 @@ import (
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/httpclient"
 	"github.com/wundergraph/graphql-go-tools/pkg/engine/plan"
 	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
 	"github.com/wundergraph/graphql-go-tools/pkg/starwars"
 	"github.com/wundergraph/graphql-go-tools/pkg/testing/subscriptiontesting"
 )
@@ -385,6 +385,59 @@ func TestHandler_Handle(t *testing.T) {
 			})
 		})
 
+		t.Run("connection_update", func(t *testing.T) {
+			type authorizationKey struct{}
+
+			executorPool, _ := setupEngineV2(t, ctx, chatServer.URL)
+			subscriptionHandler, client, handlerRoutine := setupSubscriptionHandlerWithInitFuncTest(t, executorPool, func(ctx context.Context, initPayload InitPayload) (context.Context, error) {
+				if initPayload.Authorization() == "expired" {
+					return nil, errors.New("token expired")
+				}
+				return context.WithValue(ctx, authorizationKey{}, initPayload.Authorization()), nil
+			})
+
+			handle := func(t *testing.T, message *Message) []Message {
+				t.Helper()
+				client.reset()
+				client.messageToServer = message
+				client.send()
+
+				ctx, cancelFunc := context.WithCancel(context.Background())
+				cancelFunc()
+				require.Eventually(t, handlerRoutine(ctx), 1*time.Second, 5*time.Millisecond)
+				return client.readFromServer()
+			}
+
+			t.Run("should not update a connection which is not initialized", func(t *testing.T) {
+				messagesFromServer := handle(t, &Message{Type: MessageTypeConnectionUpdate, Payload: []byte(`{"Authorization": "123"}`)})
+				assert.Contains(t, messagesFromServer, Message{
+					Type:    MessageTypeConnectionTerminate,
+					Payload: jsonizePayload(t, "failed to update the websocket connection"),
+				})
+			})
+
+			t.Run("should update the connection context and respond with ack", func(t *testing.T) {
+				client.reconnect()
+				messagesFromServer := handle(t, &Message{Type: MessageTypeConnectionInit, Payload: []byte(`{"Authorization": "123"}`)})
+				assert.Contains(t, messagesFromServer, Message{Type: MessageTypeConnectionAck})
+				connectionCtx := subscriptionHandler.connectionCtx
+				assert.Equal(t, "123", connectionCtx.Value(authorizationKey{}))
+
+				messagesFromServer = handle(t, &Message{Type: MessageTypeConnectionUpdate, Payload: []byte(`{"Authorization": "456"}`)})
+				assert.Equal(t, []Message{{Type: MessageTypeConnectionAck}}, messagesFromServer)
+				assert.Equal(t, "456", connectionCtx.Value(authorizationKey{}))
+			})
+
+			t.Run("should terminate the connection when the update is not valid", func(t *testing.T) {
+				messagesFromServer := handle(t, &Message{Type: MessageTypeConnectionUpdate, Payload: []byte(`{"Authorization": "expired"}`)})
+				assert.Contains(t, messagesFromServer, Message{
+					Type:    MessageTypeConnectionTerminate,
+					Payload: jsonizePayload(t, "failed to update the websocket connection"),
+				})
+				assert.Equal(t, "456", subscriptionHandler.connectionCtx.Value(authorizationKey{}))
+			})
+		})
+
 		t.Run("connection_keep_alive", func(t *testing.T) {
 			executorPool, _ := setupEngineV2(t, ctx, chatServer.URL)
 			subscriptionHandler, client, handlerRoutine := setupSubscriptionHandlerTest(t, executorPool)
@@ -816,7 +869,7 @@ func setupEngineV2(t *testing.T, ctx context.Context, chatServerURL string) (*Ex
 
 	initCtx := NewInitialHttpRequestContext(req)
 
-	engine, err := graphql.NewExecutionEngineV2(initCtx, abstractlogger.NoopLogger, engineConf)
+	engine, err := graphql.NewExecutionEngineV2(initCtx, logging.NoopLogger, engineConf)
 	require.NoError(t, err)
 
 	executorPool := NewExecutorV2Pool(engine, hookHolder.reqCtx)
@@ -836,7 +889,7 @@ func setupSubscriptionHandlerWithInitFuncTest(
 	client = newMockClient()
 
 	var err error
-	subscriptionHandler, err = NewHandlerWithInitFunc(abstractlogger.NoopLogger, client, executorPool, initFunc)
+	subscriptionHandler, err = NewHandlerWithInitFunc(logging.NoopLogger, client, executorPool, initFunc)
 	require.NoError(t, err)
 
 	routine = func(ctx context.Context) func() bool {
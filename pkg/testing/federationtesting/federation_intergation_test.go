@@ -10,9 +10,9 @@ import (
 	"testing"
 	"time"
 
-	"github.com/jensneuse/abstractlogger"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/wundergraph/graphql-go-tools/pkg/logging"
 	accounts "github.com/wundergraph/graphql-go-tools/pkg/testing/federationtesting/accounts/graph"
 	"github.com/wundergraph/graphql-go-tools/pkg/testing/federationtesting/gateway"
 	products "github.com/wundergraph/graphql-go-tools/pkg/testing/federationtesting/products/graph"
@@ -32,7 +32,7 @@ func newFederationSetup() *federationSetup {
 		{Name: "reviews", URL: reviewsUpstreamServer.URL},
 	}, httpClient)
 
-	gtw := gateway.Handler(abstractlogger.NoopLogger, poller, httpClient)
+	gtw := gateway.Handler(logging.NoopLogger, poller, httpClient)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
 	defer cancel()
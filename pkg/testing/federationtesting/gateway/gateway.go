@@ -5,10 +5,9 @@ import (
 	"net/http"
 	"sync"
 
-	log "github.com/jensneuse/abstractlogger"
-
 	graphqlDataSource "github.com/wundergraph/graphql-go-tools/pkg/engine/datasource/graphql_datasource"
 	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 type DataSourceObserver interface {
@@ -5,9 +5,8 @@ import (
 	"bytes"
 	"net/http"
 
-	log "github.com/jensneuse/abstractlogger"
-
 	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
 )
 
 const (
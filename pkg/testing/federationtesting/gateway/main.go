@@ -5,11 +5,10 @@ import (
 	"time"
 
 	"github.com/gobwas/ws"
-	log "github.com/jensneuse/abstractlogger"
-
-	http2 "github.com/wundergraph/graphql-go-tools/pkg/testing/federationtesting/gateway/http"
 
 	"github.com/wundergraph/graphql-go-tools/pkg/graphql"
+	log "github.com/wundergraph/graphql-go-tools/pkg/logging"
+	http2 "github.com/wundergraph/graphql-go-tools/pkg/testing/federationtesting/gateway/http"
 )
 
 func NewDatasource(serviceConfig []ServiceConfig, httpClient *http.Client) *DatasourcePollerPoller {
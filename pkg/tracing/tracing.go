@@ -0,0 +1,214 @@
+// Package tracing extracts, generates and propagates request scoped trace ids.
+//
+// Incoming requests may carry a W3C Trace Context (traceparent) or B3 (single or multi header) trace.
+// If none is present a new trace is started. The resulting TraceContext is stored in the context.Context
+// of the request, injected into all upstream requests and exposed as "traceId" in error extensions.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+)
+
+const (
+	TraceParentHeader    = "traceparent"
+	B3Header             = "b3"
+	B3TraceIDHeader      = "X-B3-TraceId"
+	B3SpanIDHeader       = "X-B3-SpanId"
+	B3ParentSpanIDHeader = "X-B3-ParentSpanId"
+	B3SampledHeader      = "X-B3-Sampled"
+	B3FlagsHeader        = "X-B3-Flags"
+
+	traceParentVersion = "00"
+	traceIDLength      = 32
+	spanIDLength       = 16
+)
+
+type contextKey struct{}
+
+// TraceContext identifies the trace a request belongs to
+type TraceContext struct {
+	// TraceID is the 32 character lower case hex id of the whole trace
+	TraceID string
+	// SpanID is the 16 character lower case hex id of the span of this request, used as parent id for upstream requests
+	SpanID string
+	// ParentSpanID is the span id of the caller, empty if the trace was started by this request
+	ParentSpanID string
+	Sampled      bool
+}
+
+// New starts a new trace
+func New() TraceContext {
+	return TraceContext{
+		TraceID: randomHexID(traceIDLength),
+		SpanID:  randomHexID(spanIDLength),
+	}
+}
+
+// FromHeader extracts the trace of the caller from the header and starts a new span for this request.
+// W3C traceparent takes precedence over B3. ok is false if the header contains no valid trace.
+func FromHeader(header http.Header) (traceContext TraceContext, ok bool) {
+	if traceContext, ok = fromTraceParent(header.Get(TraceParentHeader)); ok {
+		return traceContext, true
+	}
+	if traceContext, ok = fromB3SingleHeader(header.Get(B3Header)); ok {
+		return traceContext, true
+	}
+	return fromB3MultiHeader(header)
+}
+
+// FromHeaderOrNew extracts the trace from the header or starts a new trace if the header contains none
+func FromHeaderOrNew(header http.Header) TraceContext {
+	if traceContext, ok := FromHeader(header); ok {
+		return traceContext
+	}
+	return New()
+}
+
+// NewContext returns a copy of ctx carrying the trace context
+func NewContext(ctx context.Context, traceContext TraceContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, traceContext)
+}
+
+// FromContext returns the trace context stored in ctx
+func FromContext(ctx context.Context) (TraceContext, bool) {
+	if ctx == nil {
+		return TraceContext{}, false
+	}
+	traceContext, ok := ctx.Value(contextKey{}).(TraceContext)
+	return traceContext, ok
+}
+
+// TraceIDFromContext returns the trace id stored in ctx or an empty string
+func TraceIDFromContext(ctx context.Context) string {
+	traceContext, _ := FromContext(ctx)
+	return traceContext.TraceID
+}
+
+// TraceParent renders the trace context as W3C traceparent header value
+func (t TraceContext) TraceParent() string {
+	flags := "00"
+	if t.Sampled {
+		flags = "01"
+	}
+	return traceParentVersion + "-" + t.TraceID + "-" + t.SpanID + "-" + flags
+}
+
+// Inject sets the W3C and B3 headers on the header of an upstream request.
+// Existing trace headers are overwritten so that upstreams always see the span of this request as parent.
+func (t TraceContext) Inject(header http.Header) {
+	header.Set(TraceParentHeader, t.TraceParent())
+	header.Del(B3Header)
+	header.Set(B3TraceIDHeader, t.TraceID)
+	header.Set(B3SpanIDHeader, t.SpanID)
+	if t.ParentSpanID != "" {
+		header.Set(B3ParentSpanIDHeader, t.ParentSpanID)
+	} else {
+		header.Del(B3ParentSpanIDHeader)
+	}
+	if t.Sampled {
+		header.Set(B3SampledHeader, "1")
+	} else {
+		header.Set(B3SampledHeader, "0")
+	}
+	header.Del(B3FlagsHeader)
+}
+
+func fromTraceParent(value string) (TraceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 {
+		return TraceContext{}, false
+	}
+	version, traceID, parentID, flags := parts[0], parts[1], parts[2], parts[3]
+	if !isLowerHex(version, 2) || version == "ff" || (version == traceParentVersion && len(parts) != 4) {
+		return TraceContext{}, false
+	}
+	if !isHexID(traceID, traceIDLength) || !isHexID(parentID, spanIDLength) || !isLowerHex(flags, 2) {
+		return TraceContext{}, false
+	}
+	flagBytes, _ := hex.DecodeString(flags)
+
+	return childOf(traceID, parentID, flagBytes[0]&1 == 1), true
+}
+
+// fromB3SingleHeader parses the b3 header of the format {TraceId}-{SpanId}-{SamplingState}-{ParentSpanId}
+// where sampling state and parent span id are optional
+func fromB3SingleHeader(value string) (TraceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 2 || len(parts) > 4 {
+		return TraceContext{}, false
+	}
+	traceID, ok := normalizeB3TraceID(parts[0])
+	if !ok || !isHexID(parts[1], spanIDLength) {
+		return TraceContext{}, false
+	}
+	sampled := false
+	if len(parts) > 2 {
+		sampled = parts[2] == "1" || parts[2] == "d"
+	}
+
+	return childOf(traceID, parts[1], sampled), true
+}
+
+func fromB3MultiHeader(header http.Header) (TraceContext, bool) {
+	traceID, ok := normalizeB3TraceID(header.Get(B3TraceIDHeader))
+	if !ok {
+		return TraceContext{}, false
+	}
+	spanID := header.Get(B3SpanIDHeader)
+	if !isHexID(spanID, spanIDLength) {
+		return TraceContext{}, false
+	}
+	sampled := header.Get(B3SampledHeader)
+
+	return childOf(traceID, spanID, sampled == "1" || sampled == "true" || header.Get(B3FlagsHeader) == "1"), true
+}
+
+// normalizeB3TraceID left pads 64 bit B3 trace ids so that they are valid W3C trace ids
+func normalizeB3TraceID(traceID string) (string, bool) {
+	if len(traceID) == spanIDLength {
+		traceID = strings.Repeat("0", traceIDLength-spanIDLength) + traceID
+	}
+	return traceID, isHexID(traceID, traceIDLength)
+}
+
+func childOf(traceID, parentSpanID string, sampled bool) TraceContext {
+	return TraceContext{
+		TraceID:      traceID,
+		SpanID:       randomHexID(spanIDLength),
+		ParentSpanID: parentSpanID,
+		Sampled:      sampled,
+	}
+}
+
+// isHexID returns true if id is a lower case hex string of the given length which is not all zeros
+func isHexID(id string, length int) bool {
+	return isLowerHex(id, length) && strings.Trim(id, "0") != ""
+}
+
+func isLowerHex(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func randomHexID(length int) string {
+	id := make([]byte, length/2)
+	for {
+		_, _ = rand.Read(id)
+		encoded := hex.EncodeToString(id)
+		if isHexID(encoded, length) {
+			return encoded
+		}
+	}
+}
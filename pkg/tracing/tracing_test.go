@@ -0,0 +1,91 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromHeader(t *testing.T) {
+	run := func(header http.Header, expectedTraceID, expectedParentSpanID string, expectedSampled, expectedOk bool) func(t *testing.T) {
+		return func(t *testing.T) {
+			traceContext, ok := FromHeader(header)
+			assert.Equal(t, expectedOk, ok)
+			if !expectedOk {
+				return
+			}
+			assert.Equal(t, expectedTraceID, traceContext.TraceID)
+			assert.Equal(t, expectedParentSpanID, traceContext.ParentSpanID)
+			assert.Equal(t, expectedSampled, traceContext.Sampled)
+			assert.Len(t, traceContext.SpanID, spanIDLength)
+			assert.NotEqual(t, expectedParentSpanID, traceContext.SpanID)
+		}
+	}
+
+	t.Run("traceparent", run(http.Header{
+		"Traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+	}, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true, true))
+	t.Run("traceparent not sampled", run(http.Header{
+		"Traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+	}, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", false, true))
+	t.Run("traceparent takes precedence over b3", run(http.Header{
+		"Traceparent":  []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		"X-B3-Traceid": []string{"80f198ee56343ba864fe8b2a57d3eff7"},
+		"X-B3-Spanid":  []string{"e457b5a2e4d86bd1"},
+	}, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7", true, true))
+	t.Run("invalid traceparent falls back to b3", run(http.Header{
+		"Traceparent":  []string{"00-00000000000000000000000000000000-00f067aa0ba902b7-01"},
+		"X-B3-Traceid": []string{"80f198ee56343ba864fe8b2a57d3eff7"},
+		"X-B3-Spanid":  []string{"e457b5a2e4d86bd1"},
+	}, "80f198ee56343ba864fe8b2a57d3eff7", "e457b5a2e4d86bd1", false, true))
+	t.Run("b3 single header", run(http.Header{
+		"B3": []string{"80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1-1-05e3ac9a4f6e3b90"},
+	}, "80f198ee56343ba864fe8b2a57d3eff7", "e457b5a2e4d86bd1", true, true))
+	t.Run("b3 single header with sampling state only", run(http.Header{
+		"B3": []string{"1"},
+	}, "", "", false, false))
+	t.Run("b3 multi header with 64 bit trace id", run(http.Header{
+		"X-B3-Traceid": []string{"a3ce929d0e0e4736"},
+		"X-B3-Spanid":  []string{"e457b5a2e4d86bd1"},
+		"X-B3-Sampled": []string{"1"},
+	}, "0000000000000000a3ce929d0e0e4736", "e457b5a2e4d86bd1", true, true))
+	t.Run("no trace", run(http.Header{}, "", "", false, false))
+}
+
+func TestFromHeaderOrNew(t *testing.T) {
+	traceContext := FromHeaderOrNew(http.Header{})
+	assert.True(t, isHexID(traceContext.TraceID, traceIDLength))
+	assert.True(t, isHexID(traceContext.SpanID, spanIDLength))
+	assert.Empty(t, traceContext.ParentSpanID)
+}
+
+func TestTraceContext_Inject(t *testing.T) {
+	header := http.Header{}
+	header.Set(B3Header, "80f198ee56343ba864fe8b2a57d3eff7-e457b5a2e4d86bd1")
+	header.Set(B3ParentSpanIDHeader, "05e3ac9a4f6e3b90")
+
+	TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7"}.Inject(header)
+
+	assert.Equal(t, http.Header{
+		"Traceparent":  []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+		"X-B3-Traceid": []string{"4bf92f3577b34da6a3ce929d0e0e4736"},
+		"X-B3-Spanid":  []string{"00f067aa0ba902b7"},
+		"X-B3-Sampled": []string{"0"},
+	}, header)
+
+	injected, ok := FromHeader(header)
+	assert.True(t, ok)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", injected.TraceID)
+	assert.Equal(t, "00f067aa0ba902b7", injected.ParentSpanID)
+}
+
+func TestContext(t *testing.T) {
+	_, ok := FromContext(context.Background())
+	assert.False(t, ok)
+	assert.Empty(t, TraceIDFromContext(context.Background()))
+
+	ctx := NewContext(context.Background(), TraceContext{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736"})
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", TraceIDFromContext(ctx))
+}
@@ -0,0 +1,271 @@
+// Package variablesvalidation validates the variables of an operation against the variable definitions of the operation
+// and the input types of the schema, independent of the execution of the operation.
+//
+// Gateways can use it to reject requests with variables which can't be coerced to the types of their variables
+// before the operation is planned or sent to an upstream.
+package variablesvalidation
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/buger/jsonparser"
+
+	"github.com/wundergraph/graphql-go-tools/pkg/ast"
+	"github.com/wundergraph/graphql-go-tools/pkg/lexer/position"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+// Validate validates the variables JSON against the variable definitions of the operation with the operationName.
+// The operation name can be empty if the document contains a single operation.
+// Every value which can't be coerced to the type of its variable is added to the report as an external error
+// with the path of the value within the variables, e.g. [input,items,0,name].
+func Validate(operation, definition *ast.Document, operationName, variables []byte, report *operationreport.Report) {
+	operationDefinition, ok := findOperationDefinition(operation, operationName, report)
+	if !ok {
+		return
+	}
+
+	if len(variables) == 0 {
+		variables = []byte("{}")
+	}
+	value, dataType, _, err := jsonparser.Get(variables)
+	if err != nil {
+		report.AddExternalError(operationreport.ErrVariablesNotAnObject(string(variables)))
+		return
+	}
+	if dataType == jsonparser.Null {
+		value = []byte("{}")
+	} else if dataType != jsonparser.Object {
+		report.AddExternalError(operationreport.ErrVariablesNotAnObject(string(value)))
+		return
+	}
+
+	v := &validator{
+		operation:  operation,
+		definition: definition,
+		report:     report,
+	}
+	for _, ref := range operation.OperationDefinitions[operationDefinition].VariableDefinitions.Refs {
+		v.validateVariable(ref, value)
+	}
+}
+
+func findOperationDefinition(operation *ast.Document, operationName []byte, report *operationreport.Report) (ref int, ok bool) {
+	ref = ast.InvalidRef
+	for _, node := range operation.RootNodes {
+		if node.Kind != ast.NodeKindOperationDefinition {
+			continue
+		}
+		if len(operationName) == 0 {
+			if ref != ast.InvalidRef {
+				report.AddExternalError(operationreport.ErrRequiredOperationNameIsMissing())
+				return ast.InvalidRef, false
+			}
+			ref = node.Ref
+			continue
+		}
+		if string(operation.OperationDefinitionNameBytes(node.Ref)) == string(operationName) {
+			return node.Ref, true
+		}
+	}
+	if ref == ast.InvalidRef {
+		if len(operationName) == 0 {
+			report.AddExternalError(operationreport.ErrDocumentDoesntContainExecutableOperation())
+		} else {
+			report.AddExternalError(operationreport.ErrOperationWithProvidedOperationNameNotFound(string(operationName)))
+		}
+		return ast.InvalidRef, false
+	}
+	return ref, true
+}
+
+type validator struct {
+	operation, definition *ast.Document
+	report                *operationreport.Report
+
+	variableDefinition int
+	path               ast.Path
+}
+
+func (v *validator) validateVariable(ref int, variables []byte) {
+	v.variableDefinition = ref
+	name := v.operation.VariableDefinitionNameBytes(ref)
+	v.path = ast.Path{{Kind: ast.FieldName, FieldName: name}}
+
+	typeRef := v.operation.VariableDefinitions[ref].Type
+	value, dataType, _, err := jsonparser.Get(variables, string(name))
+	if err == jsonparser.KeyPathNotFoundError {
+		if v.operation.TypeIsNonNull(typeRef) && !v.operation.VariableDefinitionHasDefaultValue(ref) {
+			v.report.AddExternalError(operationreport.ErrVariableValueNotProvided(name, v.printType(v.operation, typeRef), v.position()))
+		}
+		return
+	}
+	if err != nil {
+		v.invalid(string(variables), "invalid JSON")
+		return
+	}
+	if dataType == jsonparser.Null && v.operation.TypeIsNonNull(typeRef) {
+		v.report.AddExternalError(operationreport.ErrVariableValueMustNotBeNull(name, v.printType(v.operation, typeRef), v.position()))
+		return
+	}
+	v.validateValue(v.operation, typeRef, value, dataType)
+}
+
+// validateValue validates the value against the type of the document, which is the operation for the type of the variable
+// and the definition for the types of input object fields
+func (v *validator) validateValue(document *ast.Document, typeRef int, value []byte, dataType jsonparser.ValueType) {
+	switch document.Types[typeRef].TypeKind {
+	case ast.TypeKindNonNull:
+		if dataType == jsonparser.Null {
+			v.invalid("null", fmt.Sprintf(operationreport.NullValueErrMsg, v.printType(document, typeRef)))
+			return
+		}
+		v.validateValue(document, document.Types[typeRef].OfType, value, dataType)
+	case ast.TypeKindList:
+		if dataType == jsonparser.Null {
+			return
+		}
+		if dataType != jsonparser.Array {
+			// input coercion of lists accepts a single item as a list with one item
+			v.validateValue(document, document.Types[typeRef].OfType, value, dataType)
+			return
+		}
+		index := 0
+		_, _ = jsonparser.ArrayEach(value, func(item []byte, itemType jsonparser.ValueType, _ int, _ error) {
+			v.path = append(v.path, ast.PathItem{Kind: ast.ArrayIndex, ArrayIndex: index})
+			v.validateValue(document, document.Types[typeRef].OfType, item, itemType)
+			v.path = v.path[:len(v.path)-1]
+			index++
+		})
+	case ast.TypeKindNamed:
+		if dataType == jsonparser.Null {
+			return
+		}
+		v.validateNamedType(document.TypeNameBytes(typeRef), value, dataType)
+	}
+}
+
+func (v *validator) validateNamedType(typeName, value []byte, dataType jsonparser.ValueType) {
+	printed := printValue(value, dataType)
+	switch string(typeName) {
+	case "String":
+		if dataType != jsonparser.String {
+			v.invalid(printed, fmt.Sprintf(operationreport.NotStringErrMsg, "String", printed))
+		}
+		return
+	case "Int":
+		if !isInt32(value, dataType) {
+			if dataType == jsonparser.Number && isInteger(value) {
+				v.invalid(printed, fmt.Sprintf(operationreport.BigIntegerErrMsg, "Int", printed))
+				return
+			}
+			v.invalid(printed, fmt.Sprintf(operationreport.NotIntegerErrMsg, "Int", printed))
+		}
+		return
+	case "Float":
+		if dataType != jsonparser.Number {
+			v.invalid(printed, fmt.Sprintf(operationreport.NotFloatErrMsg, "Float", printed))
+		}
+		return
+	case "Boolean":
+		if dataType != jsonparser.Boolean {
+			v.invalid(printed, fmt.Sprintf(operationreport.NotBooleanErrMsg, "Boolean", printed))
+		}
+		return
+	case "ID":
+		if dataType != jsonparser.String && !(dataType == jsonparser.Number && isInteger(value)) {
+			v.invalid(printed, fmt.Sprintf(operationreport.NotIDErrMsg, "ID", printed))
+		}
+		return
+	}
+
+	node, ok := v.definition.Index.FirstNodeByNameBytes(typeName)
+	if !ok {
+		v.invalid(printed, fmt.Sprintf(operationreport.UnknownTypeErrMsg, string(typeName)))
+		return
+	}
+	switch node.Kind {
+	case ast.NodeKindEnumTypeDefinition:
+		if dataType != jsonparser.String {
+			v.invalid(printed, fmt.Sprintf(operationreport.NotEnumErrMsg, string(typeName), printed))
+			return
+		}
+		if !v.definition.EnumTypeDefinitionContainsEnumValue(node.Ref, value) {
+			v.invalid(printed, fmt.Sprintf(operationreport.NotAnEnumMemberErrMsg, string(value), string(typeName)))
+		}
+	case ast.NodeKindInputObjectTypeDefinition:
+		if dataType != jsonparser.Object {
+			v.invalid(printed, fmt.Sprintf(operationreport.ValueIsNotAnInputObjectTypeErrMsg, string(typeName), printed))
+			return
+		}
+		v.validateInputObject(node.Ref, typeName, value)
+	case ast.NodeKindScalarTypeDefinition:
+		// custom scalars accept any value
+	default:
+		v.invalid(printed, fmt.Sprintf(operationreport.VariableIsNotInputTypeErrMsg, string(v.operation.VariableDefinitionNameBytes(v.variableDefinition)), string(typeName)))
+	}
+}
+
+func (v *validator) validateInputObject(ref int, typeName, value []byte) {
+	fields := v.definition.InputObjectTypeDefinitions[ref].InputFieldsDefinition.Refs
+	for _, field := range fields {
+		fieldName := v.definition.InputValueDefinitionNameBytes(field)
+		fieldType := v.definition.InputValueDefinitionType(field)
+		fieldValue, fieldDataType, _, err := jsonparser.Get(value, string(fieldName))
+		if err != nil {
+			if v.definition.TypeIsNonNull(fieldType) && !v.definition.InputValueDefinitionHasDefaultValue(field) {
+				v.invalid(string(value), fmt.Sprintf(operationreport.MissingRequiredFieldOfInputObjectErrMsg, string(typeName), string(fieldName), string(v.printType(v.definition, fieldType))))
+			}
+			continue
+		}
+		v.path = append(v.path, ast.PathItem{Kind: ast.FieldName, FieldName: fieldName})
+		v.validateValue(v.definition, fieldType, fieldValue, fieldDataType)
+		v.path = v.path[:len(v.path)-1]
+	}
+
+	_ = jsonparser.ObjectEach(value, func(key []byte, _ []byte, _ jsonparser.ValueType, _ int) error {
+		if v.definition.InputObjectTypeDefinitionInputValueDefinitionByName(ref, key) == ast.InvalidRef {
+			v.invalid(string(value), fmt.Sprintf(operationreport.UnknownFieldOfInputObjectErrMsg, string(key), string(typeName)))
+		}
+		return nil
+	})
+}
+
+func (v *validator) invalid(value, reason string) {
+	path := make(ast.Path, len(v.path))
+	copy(path, v.path)
+	name := v.operation.VariableDefinitionNameBytes(v.variableDefinition)
+	v.report.AddExternalError(operationreport.ErrInvalidVariableValue(name, value, reason, path, v.position()))
+}
+
+func (v *validator) position() position.Position {
+	return v.operation.VariableDefinitions[v.variableDefinition].VariableValue.Position
+}
+
+func (v *validator) printType(document *ast.Document, typeRef int) ast.ByteSlice {
+	printed, _ := document.PrintTypeBytes(typeRef, nil)
+	return printed
+}
+
+// printValue prints the value as JSON, jsonparser returns strings without quotes but still escaped
+func printValue(value []byte, dataType jsonparser.ValueType) string {
+	if dataType == jsonparser.String {
+		return `"` + string(value) + `"`
+	}
+	return string(value)
+}
+
+func isInteger(value []byte) bool {
+	number, err := strconv.ParseFloat(string(value), 64)
+	return err == nil && number == math.Trunc(number) && !math.IsInf(number, 0)
+}
+
+func isInt32(value []byte, dataType jsonparser.ValueType) bool {
+	if dataType != jsonparser.Number || !isInteger(value) {
+		return false
+	}
+	number, _ := strconv.ParseFloat(string(value), 64)
+	return number >= math.MinInt32 && number <= math.MaxInt32
+}
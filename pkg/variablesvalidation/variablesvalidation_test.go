@@ -0,0 +1,143 @@
+package variablesvalidation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/wundergraph/graphql-go-tools/internal/pkg/unsafeparser"
+	"github.com/wundergraph/graphql-go-tools/pkg/asttransform"
+	"github.com/wundergraph/graphql-go-tools/pkg/operationreport"
+)
+
+const testDefinition = `
+	scalar JSON
+
+	enum Role { ADMIN USER }
+
+	input UserInput {
+		name: String!
+		age: Int
+		role: Role = USER
+		tags: [String!]
+		address: AddressInput
+		meta: JSON
+	}
+
+	input AddressInput {
+		city: String!
+	}
+
+	type Query {
+		users(ids: [ID!]!, limit: Int, ratio: Float, active: Boolean): [String]
+	}
+
+	type Mutation {
+		createUser(input: UserInput!): String
+		createUsers(inputs: [UserInput!]!): String
+	}
+
+	schema {
+		query: Query
+		mutation: Mutation
+	}
+`
+
+func TestValidate(t *testing.T) {
+	run := func(t *testing.T, operation, operationName, variables string, expectedErrors ...string) {
+		t.Helper()
+
+		definitionDocument := unsafeparser.ParseGraphqlDocumentString(testDefinition)
+		require.NoError(t, asttransform.MergeDefinitionWithBaseSchema(&definitionDocument))
+		operationDocument := unsafeparser.ParseGraphqlDocumentString(operation)
+
+		report := operationreport.Report{}
+		Validate(&operationDocument, &definitionDocument, []byte(operationName), []byte(variables), &report)
+
+		var messages []string
+		for _, externalError := range report.ExternalErrors {
+			messages = append(messages, externalError.Message)
+		}
+		assert.Equal(t, expectedErrors, messages)
+	}
+
+	const usersQuery = `query Users($ids: [ID!]!, $limit: Int, $ratio: Float, $active: Boolean) {
+		users(ids: $ids, limit: $limit, ratio: $ratio, active: $active)
+	}`
+	const createUserMutation = `mutation CreateUser($input: UserInput!) { createUser(input: $input) }`
+	const createUsersMutation = `mutation CreateUsers($inputs: [UserInput!]!) { createUsers(inputs: $inputs) }`
+
+	t.Run("valid scalars", func(t *testing.T) {
+		run(t, usersQuery, "", `{"ids":["1",2],"limit":10,"ratio":1,"active":true}`)
+	})
+
+	t.Run("single value is coerced to a list", func(t *testing.T) {
+		run(t, usersQuery, "Users", `{"ids":"1"}`)
+	})
+
+	t.Run("valid input object", func(t *testing.T) {
+		run(t, createUserMutation, "", `{"input":{"name":"Jens","age":30,"role":"ADMIN","tags":["a"],"address":{"city":"Berlin"},"meta":{"any":[1]}}}`)
+	})
+
+	t.Run("missing required variable", func(t *testing.T) {
+		run(t, usersQuery, "", `{}`,
+			`Variable "$ids" of required type "[ID!]!" was not provided.`,
+		)
+	})
+
+	t.Run("null for non-null variable", func(t *testing.T) {
+		run(t, usersQuery, "", `{"ids":null}`,
+			`Variable "$ids" of non-null type "[ID!]!" must not be null.`,
+		)
+	})
+
+	t.Run("invalid scalars", func(t *testing.T) {
+		run(t, usersQuery, "", `{"ids":["1",null,1.5],"limit":3000000000,"ratio":"1","active":1}`,
+			`Variable "$ids" got invalid value null at "ids[1]"; Expected value of type "ID!", found null.`,
+			`Variable "$ids" got invalid value 1.5 at "ids[2]"; ID cannot represent a non-string and non-integer value: 1.5`,
+			`Variable "$limit" got invalid value 3000000000; Int cannot represent non 32-bit signed integer value: 3000000000`,
+			`Variable "$ratio" got invalid value "1"; Float cannot represent non numeric value: "1"`,
+			`Variable "$active" got invalid value 1; Boolean cannot represent a non boolean value: 1`,
+		)
+	})
+
+	t.Run("invalid input object", func(t *testing.T) {
+		run(t, createUserMutation, "", `{"input":{"age":1.5,"role":"OWNER","address":{"city":1},"unknown":true}}`,
+			`Variable "$input" got invalid value {"age":1.5,"role":"OWNER","address":{"city":1},"unknown":true}; Field "UserInput.name" of required type "String!" was not provided.`,
+			`Variable "$input" got invalid value 1.5 at "input.age"; Int cannot represent non-integer value: 1.5`,
+			`Variable "$input" got invalid value "OWNER" at "input.role"; Value "OWNER" does not exist in "Role" enum.`,
+			`Variable "$input" got invalid value 1 at "input.address.city"; String cannot represent a non string value: 1`,
+			`Variable "$input" got invalid value {"age":1.5,"role":"OWNER","address":{"city":1},"unknown":true}; Field "unknown" is not defined by type "UserInput".`,
+		)
+	})
+
+	t.Run("invalid item of a list of input objects", func(t *testing.T) {
+		run(t, createUsersMutation, "", `{"inputs":[{"name":"a"},{"name":"b","tags":[1]},"c"]}`,
+			`Variable "$inputs" got invalid value 1 at "inputs[1].tags[0]"; String cannot represent a non string value: 1`,
+			`Variable "$inputs" got invalid value "c" at "inputs[2]"; Expected value of type "UserInput", found "c".`,
+		)
+	})
+
+	t.Run("operation selection", func(t *testing.T) {
+		run(t, usersQuery+createUserMutation, "CreateUser", `{"input":{"name":"a"}}`)
+		run(t, usersQuery+createUserMutation, "", `{}`, "operation name is required when providing multiple operations")
+		run(t, usersQuery, "Other", `{}`, "cannot find an operation with name: Other")
+	})
+
+	t.Run("variables must be an object", func(t *testing.T) {
+		run(t, usersQuery, "", `[]`, `Variables must be a JSON object, found [].`)
+	})
+
+	t.Run("error path", func(t *testing.T) {
+		definitionDocument := unsafeparser.ParseGraphqlDocumentString(testDefinition)
+		require.NoError(t, asttransform.MergeDefinitionWithBaseSchema(&definitionDocument))
+		operationDocument := unsafeparser.ParseGraphqlDocumentString(createUsersMutation)
+
+		report := operationreport.Report{}
+		Validate(&operationDocument, &definitionDocument, nil, []byte(`{"inputs":[{"name":"a","address":{"city":null}}]}`), &report)
+		require.Len(t, report.ExternalErrors, 1)
+		assert.Equal(t, "[inputs,0,address,city]", report.ExternalErrors[0].Path.String())
+		assert.Equal(t, uint32(1), report.ExternalErrors[0].Locations[0].Line)
+	})
+}